@@ -0,0 +1,224 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/mtreilly/arc-library/internal/library"
+	"github.com/spf13/cobra"
+	"github.com/yourorg/arc-sdk/config"
+	"github.com/yourorg/arc-sdk/output"
+)
+
+func newAddCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command {
+	var (
+		tags        []string
+		collection  string
+		docType     string
+		pdf         bool
+		email       string
+		extractText bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "add <identifier>",
+		Short: "Add a document by DOI, arXiv ID, ISBN, PMID, or URL",
+		Long: `Add a document from an identifier in one step. The identifier's format
+picks the resolver the metadata comes from:
+
+  DOI (10.1234/x, doi.org URL)        Crossref
+  arXiv ID or URL (2304.00067)        arXiv API
+  ISBN-10 or ISBN-13                  Open Library
+  PMID or PMC ID                      PubMed
+  bioRxiv/medRxiv DOI or URL          bioRxiv API
+  any other http(s) URL               the page itself, as with import
+
+With --pdf the paper's PDF is downloaded into the library root (or
+~/.local/share/arc/files): from arXiv for arXiv papers, otherwise the best
+open-access copy Unpaywall knows of, which needs a contact email (--email,
+ARC_LIBRARY_UNPAYWALL_EMAIL, or ARC_LIBRARY_CONTACT_EMAIL). Documents added without a file are tagged
+no-file.
+
+Examples:
+  arc-library add 10.1038/nature12373
+  arc-library add arXiv:1706.03762 --pdf -t transformers
+  arc-library add 978-0-262-03384-8 -c textbooks
+  arc-library add PMID:23193287 --pdf --email me@example.org`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			input := strings.TrimSpace(args[0])
+			rules, err := typeRules()
+			if err != nil {
+				return err
+			}
+			hooks, err := loadHooks()
+			if err != nil {
+				return err
+			}
+			root := library.LibraryRoot()
+
+			var doc *library.Document
+			source, id, parseErr := library.ParseIdentifier(input)
+			switch {
+			case parseErr == nil:
+				if existing, _ := store.GetDocumentBySourceID(source, id); existing != nil {
+					return fmt.Errorf("%s %s is already in the library: %s", source, id, existing.ID)
+				}
+				infof("Resolving %s %s...\n", source, id)
+				meta, err := library.ResolveIdentifier(source, id)
+				if err != nil {
+					return err
+				}
+				doc = &library.Document{Type: library.DocTypePaper}
+				if source == library.IDSourceISBN {
+					doc.Type = library.DocTypeBook
+				}
+				applyIdentifierMeta(doc, source, id, meta, true, true, true)
+
+				if pdf {
+					path, err := addDownloadPDF(doc, source, id, email)
+					if err != nil {
+						warnf("Warning: no PDF: %v\n", err)
+					}
+					doc.Path = library.StoredPath(path, root)
+				}
+				if doc.Path == "" {
+					doc.Tags = []string{library.NoFileTag}
+				}
+
+			case strings.HasPrefix(input, "http://") || strings.HasPrefix(input, "https://"):
+				existing, err := library.FindDocumentByURL(store, input)
+				if err != nil {
+					return err
+				}
+				if existing != nil {
+					return fmt.Errorf("%s is already in the library: %s", input, existing.ID)
+				}
+				dir, err := library.FilesDir()
+				if err != nil {
+					return err
+				}
+				infof("Fetching %s...\n", input)
+				if doc, err = library.ImportURL(input, dir); err != nil {
+					return err
+				}
+				doc.Path = library.StoredPath(doc.Path, root)
+
+			default:
+				return fmt.Errorf("%w, or an http(s) URL", parseErr)
+			}
+
+			for _, t := range tags {
+				if !slices.Contains(doc.Tags, t) {
+					doc.Tags = append(doc.Tags, t)
+				}
+			}
+			if docType != "" {
+				doc.Type = library.DocumentType(docType)
+			} else if t, ok := library.DetectDocumentType(doc, rules); ok {
+				doc.Type = t
+			}
+			if extractText && doc.Path != "" {
+				res := library.DefaultTextExtractor().Extract(library.DocumentPath(doc))
+				if err := applyExtraction(doc, res); err != nil {
+					warnf("Warning: text extraction failed: %v\n", err)
+				}
+			}
+
+			// The language picks the search tokenizer the document is indexed with
+			library.DetectDocumentLanguage(doc, false)
+
+			if err := hooks.PreImport(doc, os.Stderr); err != nil {
+				return err
+			}
+			if err := store.AddDocument(doc); err != nil {
+				return err
+			}
+
+			if collection != "" {
+				c, err := store.GetCollection(collection)
+				if err != nil {
+					return err
+				}
+				if c == nil {
+					c, err = store.CreateCollection(collection, "")
+					if err != nil {
+						return fmt.Errorf("create collection: %w", err)
+					}
+					infof("Created collection: %s\n", collection)
+				}
+				if err := store.AddToCollection(c.ID, doc.ID); err != nil {
+					return fmt.Errorf("add to collection: %w", err)
+				}
+			}
+
+			if err := hooks.PostImport(doc, os.Stderr); err != nil {
+				warnf("Warning: %v\n", err)
+			}
+
+			if jsonOutput(nil) {
+				return output.JSON(doc)
+			}
+			if quietOutput() {
+				printIDs(doc.ID)
+				return nil
+			}
+
+			fmt.Printf("Added: %s - %s\n", doc.ID, truncate(doc.Title, 60))
+			if len(doc.Authors) > 0 {
+				fmt.Printf("  Authors: %s\n", truncate(strings.Join(doc.Authors, ", "), 60))
+			}
+			if doc.Path != "" {
+				fmt.Printf("  File: %s\n", library.DocumentPath(doc))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&tags, "tag", "t", nil, "Tags to apply to the document")
+	cmd.Flags().StringVarP(&collection, "collection", "c", "", "Add the document to a collection, creating it if needed")
+	cmd.Flags().StringVar(&docType, "type", "", "Document type (default: detected, as with import)")
+	cmd.Flags().BoolVar(&pdf, "pdf", false, "Download an open-access PDF")
+	cmd.Flags().StringVar(&email, "email", cmp.Or(os.Getenv("ARC_LIBRARY_UNPAYWALL_EMAIL"), os.Getenv("ARC_LIBRARY_CONTACT_EMAIL")),
+		"Contact email for Unpaywall lookups")
+	cmd.Flags().BoolVarP(&extractText, "extract-text", "e", false, "Extract full text from the PDF (requires pdftotext)")
+
+	return cmd
+}
+
+// addDownloadPDF downloads an open-access PDF for a resolved document into
+// FilesDir and returns its path: arXiv's copy for arXiv papers, otherwise
+// Unpaywall's for the document's DOI.
+func addDownloadPDF(doc *library.Document, source, id, email string) (string, error) {
+	pdfURL, _ := doc.Meta["pdf_url"].(string)
+	if pdfURL == "" {
+		doi := metaDoi(doc)
+		if source == library.IDSourceDOI || source == library.IDSourceBioRxiv {
+			doi = id
+		}
+		if doi == "" {
+			return "", fmt.Errorf("%s %s has no DOI to look up an open-access copy", source, id)
+		}
+		infof("Looking up an open-access copy of %s...\n", doi)
+		var err error
+		if pdfURL, err = library.UnpaywallPDF(doi, email); err != nil {
+			return "", err
+		}
+		if pdfURL == "" {
+			return "", fmt.Errorf("no open-access copy of %s", doi)
+		}
+	}
+
+	dir, err := library.FilesDir()
+	if err != nil {
+		return "", err
+	}
+	infof("Downloading %s...\n", pdfURL)
+	return library.DownloadPDF(pdfURL, dir, strings.ReplaceAll(id, "/", "_")+".pdf")
+}
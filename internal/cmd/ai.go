@@ -4,16 +4,17 @@
 package cmd
 
 import (
+	"bufio"
 	"bytes"
 	"fmt"
-	"log"
+	"os"
 	"os/exec"
 	"strings"
-	"time"
 
 	"github.com/mtreilly/arc-library/internal/library"
 	"github.com/spf13/cobra"
 	"github.com/yourorg/arc-sdk/config"
+	"github.com/yourorg/arc-sdk/output"
 )
 
 func newAICmd(cfg *config.Config, store library.LibraryStore) *cobra.Command {
@@ -34,12 +35,14 @@ func newAISummaryCmd(store library.LibraryStore) *cobra.Command {
 	var (
 		length   int
 		storeRes bool
+		section  string
 	)
 
 	cmd := &cobra.Command{
-		Use:   "summary <document-id>",
-		Short: "Generate a summary of a document",
-		Args:  cobra.ExactArgs(1),
+		Use:               "summary <document-id>",
+		Short:             "Generate a summary of a document",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeFirst(completeDocuments(store)),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			docID := args[0]
 			doc, err := store.GetDocument(docID)
@@ -47,7 +50,7 @@ func newAISummaryCmd(store library.LibraryStore) *cobra.Command {
 				return fmt.Errorf("get document: %w", err)
 			}
 			if doc == nil {
-				return fmt.Errorf("document not found: %s", docID)
+				return fmt.Errorf("%w: %s", library.ErrDocumentNotFound, docID)
 			}
 
 			// Build context
@@ -59,11 +62,11 @@ func newAISummaryCmd(store library.LibraryStore) *cobra.Command {
 			if doc.Abstract != "" {
 				context.WriteString(fmt.Sprintf("Abstract: %s\n", doc.Abstract))
 			}
-			if doc.FullText != "" {
-				text := doc.FullText
-				if len(text) > 4000 {
-					text = text[:4000] + "... (truncated)"
-				}
+			text, err := aiFullText(store, doc, "", section, 4000)
+			if err != nil {
+				return err
+			}
+			if text != "" {
 				context.WriteString(fmt.Sprintf("\nFull Text:\n%s\n", text))
 			}
 
@@ -82,9 +85,11 @@ func newAISummaryCmd(store library.LibraryStore) *cobra.Command {
 				return fmt.Errorf("arc-ai failed: %w\nOutput: %s", err, out.String())
 			}
 
-			fmt.Println("=== AI Summary ===")
-			fmt.Println(out.String())
-			fmt.Println()
+			if !jsonOutput(nil) {
+				fmt.Println("=== AI Summary ===")
+				fmt.Println(out.String())
+				fmt.Println()
+			}
 
 			if storeRes {
 				if doc.Meta == nil {
@@ -94,23 +99,30 @@ func newAISummaryCmd(store library.LibraryStore) *cobra.Command {
 				if err := store.UpdateDocument(doc); err != nil {
 					return fmt.Errorf("store summary: %w", err)
 				}
-				fmt.Println("Summary stored in document metadata.")
+				infoln("Summary stored in document metadata.")
 			}
 
+			if jsonOutput(nil) {
+				return output.JSON(aiResult{DocumentID: doc.ID, Prompt: prompt, Response: out.String(), Stored: storeRes})
+			}
 			return nil
 		},
 	}
 
 	cmd.Flags().IntVarP(&length, "length", "l", 200, "Target summary length in words")
 	cmd.Flags().BoolVarP(&storeRes, "store", "s", false, "Store the summary in the document")
+	cmd.Flags().StringVar(&section, "section", "", "Only use this section of the full text (e.g. methods)")
 	return cmd
 }
 
 func newAIQnACmd(store library.LibraryStore) *cobra.Command {
+	var section string
+
 	cmd := &cobra.Command{
-		Use:   "qna <document-id> <question>",
-		Short: "Ask a question about a document",
-		Args:  cobra.MinimumNArgs(2),
+		Use:               "qna <document-id> <question>",
+		Short:             "Ask a question about a document",
+		Args:              cobra.MinimumNArgs(2),
+		ValidArgsFunction: completeFirst(completeDocuments(store)),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			docID := args[0]
 			question := strings.Join(args[1:], " ")
@@ -120,7 +132,7 @@ func newAIQnACmd(store library.LibraryStore) *cobra.Command {
 				return fmt.Errorf("get document: %w", err)
 			}
 			if doc == nil {
-				return fmt.Errorf("document not found: %s", docID)
+				return fmt.Errorf("%w: %s", library.ErrDocumentNotFound, docID)
 			}
 
 			// Build context
@@ -129,11 +141,11 @@ func newAIQnACmd(store library.LibraryStore) *cobra.Command {
 			if doc.Abstract != "" {
 				context.WriteString(fmt.Sprintf("Abstract: %s\n", doc.Abstract))
 			}
-			if doc.FullText != "" {
-				text := doc.FullText
-				if len(text) > 6000 {
-					text = text[:6000] + "... (truncated)"
-				}
+			text, err := aiFullText(store, doc, question, section, 6000)
+			if err != nil {
+				return err
+			}
+			if text != "" {
 				context.WriteString(fmt.Sprintf("\nFull Text:\n%s\n", text))
 			}
 
@@ -147,6 +159,10 @@ func newAIQnACmd(store library.LibraryStore) *cobra.Command {
 				return fmt.Errorf("arc-ai failed: %w\nOutput: %s", err, out.String())
 			}
 
+			if jsonOutput(nil) {
+				return output.JSON(aiResult{DocumentID: doc.ID, Prompt: question, Response: out.String()})
+			}
+
 			fmt.Println("=== AI Answer ===")
 			fmt.Println(out.String())
 			fmt.Println()
@@ -154,6 +170,7 @@ func newAIQnACmd(store library.LibraryStore) *cobra.Command {
 		},
 	}
 
+	cmd.Flags().StringVar(&section, "section", "", "Only use this section of the full text (e.g. methods)")
 	return cmd
 }
 
@@ -161,14 +178,35 @@ func newAIFlashcardsCmd(store library.LibraryStore) *cobra.Command {
 	var (
 		count    int
 		storeRes bool
+		yes      bool
+		cloze    bool
 		tags     []string
+		section  string
+		similar  float64
 	)
 
 	cmd := &cobra.Command{
 		Use:   "flashcards <document-id>",
 		Short: "Generate flashcards from a document using AI",
-		Long:  "Automatically generate Q&A flashcards from document content.",
-		Args:  cobra.ExactArgs(1),
+		Long: `Generate flashcards from document content. The model is asked for
+cards as JSON, and each card is checked before it can be stored: basic
+cards need a question and an answer, cloze cards (--cloze) a {{c1::...}}
+deletion. Cards that fail are listed as rejected.
+
+Cards whose question shares at least --similarity of its words with a
+card the document already has, or with another generated card, are
+skipped and listed, so generating again doesn't flood the deck. 0 keeps
+them all.
+
+With --store each card is shown to accept, edit, or reject before it is
+stored. --yes, a non-interactive input, or --json store every valid card
+without asking.
+
+Examples:
+  arc-library ai flashcards <doc-id> --store
+  arc-library ai flashcards <doc-id> --cloze -n 10 --store --yes`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeFirst(completeDocuments(store)),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			docID := args[0]
 			doc, err := store.GetDocument(docID)
@@ -176,7 +214,7 @@ func newAIFlashcardsCmd(store library.LibraryStore) *cobra.Command {
 				return fmt.Errorf("get document: %w", err)
 			}
 			if doc == nil {
-				return fmt.Errorf("document not found: %s", docID)
+				return fmt.Errorf("%w: %s", library.ErrDocumentNotFound, docID)
 			}
 
 			// Build context
@@ -188,86 +226,210 @@ func newAIFlashcardsCmd(store library.LibraryStore) *cobra.Command {
 			if doc.Abstract != "" {
 				context.WriteString(fmt.Sprintf("Abstract: %s\n", doc.Abstract))
 			}
-			if doc.FullText != "" {
-				text := doc.FullText
-				if len(text) > 8000 {
-					text = text[:8000] + "... (truncated)"
-				}
+			text, err := aiFullText(store, doc, "", section, 8000)
+			if err != nil {
+				return err
+			}
+			if text != "" {
 				context.WriteString(fmt.Sprintf("\nFull Text:\n%s\n", text))
 			}
 
-			prompt := fmt.Sprintf(`Generate %d flashcards from this document in the following format:
-
-Q: [question]
-A: [answer]
-
-Q: [question]
-A: [answer]
-
-Make the cards concise and focused on key concepts, definitions, and findings.`, count)
+			prompt := library.FlashcardPrompt(count, cloze)
 
 			aiCmd := exec.Command("arc-ai", "ask", prompt)
 			aiCmd.Stdin = strings.NewReader(context.String())
-			var out bytes.Buffer
+			var out, errOut bytes.Buffer
 			aiCmd.Stdout = &out
-			aiCmd.Stderr = &out
+			aiCmd.Stderr = &errOut
 			if err := aiCmd.Run(); err != nil {
-				return fmt.Errorf("arc-ai failed: %w\nOutput: %s", err, out.String())
+				return fmt.Errorf("arc-ai failed: %w\nOutput: %s%s", err, out.String(), errOut.String())
 			}
 
-			output := out.String()
-			fmt.Println("=== Generated Flashcards ===")
-			fmt.Println(output)
-			fmt.Println()
+			generated := out.String()
+			valid, rejected, err := library.ParseGeneratedCards(generated, cloze)
+			if err != nil {
+				return fmt.Errorf("read generated flashcards: %w\nResponse: %s", err, generated)
+			}
+			existing, err := store.ListFlashcards(&library.FlashcardListOptions{DocumentID: docID})
+			if err != nil {
+				return fmt.Errorf("list flashcards: %w", err)
+			}
+			valid, skipped := library.DedupeGeneratedCards(valid, existing, similar)
+
+			if !jsonOutput(nil) {
+				fmt.Printf("=== Generated Flashcards (%d) ===\n", len(valid))
+				for i, c := range valid {
+					fmt.Printf("\n%d. ", i+1)
+					printGeneratedCard(c)
+				}
+				for _, r := range rejected {
+					fmt.Printf("\nRejected (%s): %s%s\n", r.Reason, r.Card.Front, r.Card.Cloze)
+				}
+				for _, sk := range skipped {
+					fmt.Printf("\nSkipped, %.0f%% like %q: %s%s\n", sk.Similarity*100, sk.Similar, sk.Card.Front, sk.Card.Cloze)
+				}
+				fmt.Println()
+			}
 
-			// Parse and store if requested
+			var cards []*library.Flashcard
 			if storeRes {
-				cards := parseGeneratedFlashcards(output, docID, tags)
-				for _, card := range cards {
+				keep := valid
+				if !yes && !jsonOutput(nil) && stdinIsTerminal() {
+					keep = reviewGeneratedCards(bufio.NewReader(os.Stdin), valid)
+				}
+				for _, c := range keep {
+					card := c.Flashcard(docID, tags)
 					if err := store.AddFlashcard(card); err != nil {
-						log.Printf("Failed to add card: %v", err)
+						return fmt.Errorf("add flashcard: %w", err)
 					}
+					cards = append(cards, card)
+				}
+				infof("Added %d flashcards to library\n", len(cards))
+			} else {
+				for _, c := range valid {
+					cards = append(cards, c.Flashcard(docID, tags))
 				}
-				fmt.Printf("Added %d flashcards to library\n", len(cards))
 			}
 
+			if jsonOutput(nil) {
+				return output.JSON(aiResult{DocumentID: doc.ID, Prompt: prompt, Response: generated, Stored: storeRes,
+					Flashcards: nonNil(cards), Rejected: rejected, Skipped: skipped})
+			}
 			return nil
 		},
 	}
 
 	cmd.Flags().IntVarP(&count, "count", "n", 5, "Number of flashcards to generate")
-	cmd.Flags().BoolVarP(&storeRes, "store", "s", false, "Store generated flashcards")
+	cmd.Flags().BoolVarP(&storeRes, "store", "s", false, "Store generated flashcards, after reviewing each")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "With --store, store every valid card without reviewing")
+	cmd.Flags().BoolVar(&cloze, "cloze", false, "Generate cloze deletion cards")
+	cmd.Flags().Float64Var(&similar, "similarity", library.DefaultCardSimilarity, "Skip cards whose question overlaps an existing card's by this fraction of words (0-1; 0 keeps all)")
 	cmd.Flags().StringSliceVarP(&tags, "tag", "t", nil, "Tags for generated cards")
+	cmd.Flags().StringVar(&section, "section", "", "Only use this section of the full text (e.g. methods)")
 
 	return cmd
 }
 
-func parseGeneratedFlashcards(text, docID string, tags []string) []*library.Flashcard {
-	var cards []*library.Flashcard
-	lines := strings.Split(text, "\n")
-
-	var currentQ, currentA string
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "Q:") {
-			currentQ = strings.TrimSpace(strings.TrimPrefix(line, "Q:"))
-		} else if strings.HasPrefix(line, "A:") {
-			currentA = strings.TrimSpace(strings.TrimPrefix(line, "A:"))
-			if currentQ != "" && currentA != "" {
-				cards = append(cards, &library.Flashcard{
-					DocumentID: docID,
-					Type:       "basic",
-					Front:      currentQ,
-					Back:       currentA,
-					Tags:       tags,
-					DueAt:      time.Now().AddDate(0, 0, 1),
-					Interval:   0,
-					Ease:       2.5,
-				})
-				currentQ, currentA = "", ""
+// printGeneratedCard prints a generated card's question and answer.
+func printGeneratedCard(c library.GeneratedCard) {
+	if c.Type == "cloze" {
+		fmt.Printf("Cloze: %s\n", c.Cloze)
+		if c.Back != "" {
+			fmt.Printf("   Extra: %s\n", c.Back)
+		}
+		return
+	}
+	fmt.Printf("Q: %s\n   A: %s\n", c.Front, c.Back)
+}
+
+// reviewGeneratedCards asks about each card in turn whether to accept,
+// edit, or reject it, and returns the cards to store. An edited card is
+// asked about again.
+func reviewGeneratedCards(in *bufio.Reader, cards []library.GeneratedCard) []library.GeneratedCard {
+	var keep []library.GeneratedCard
+	for i := 0; i < len(cards); i++ {
+		c := cards[i]
+		fmt.Printf("\n[%d/%d] ", i+1, len(cards))
+		printGeneratedCard(c)
+		switch promptLine(in, "[a]ccept, [e]dit, [r]eject, accept [A]ll, [q]uit: ") {
+		case "a", "":
+			if err := c.Validate(); err != nil {
+				fmt.Printf("Not valid: %v; edit or reject it\n", err)
+				i--
+				continue
+			}
+			keep = append(keep, c)
+		case "A":
+			return append(keep, cards[i:]...)
+		case "e":
+			if c.Type == "cloze" {
+				c.Cloze = editField(in, "Cloze", c.Cloze)
+				c.Back = editField(in, "Extra", c.Back)
+			} else {
+				c.Front = editField(in, "Front", c.Front)
+				c.Back = editField(in, "Back", c.Back)
+			}
+			if err := c.Validate(); err != nil {
+				fmt.Printf("Not valid: %v\n", err)
 			}
+			cards[i] = c
+			i--
+		case "q":
+			return keep
 		}
 	}
+	return keep
+}
+
+// editField asks for a new value of a field, keeping the old one on enter.
+func editField(in *bufio.Reader, name, value string) string {
+	if v := promptLine(in, fmt.Sprintf("%s [%s]: ", name, value)); v != "" {
+		return v
+	}
+	return value
+}
+
+// aiKeyPoints asks arc-ai for the n most important points of a document
+// as question and answer cards, for "export --format quiz --key-points".
+func aiKeyPoints(store library.LibraryStore, doc *library.Document, n int) ([]library.GeneratedCard, error) {
+	var context strings.Builder
+	context.WriteString(fmt.Sprintf("Title: %s\n", doc.Title))
+	if doc.Abstract != "" {
+		context.WriteString(fmt.Sprintf("Abstract: %s\n", doc.Abstract))
+	}
+	text, err := aiFullText(store, doc, "", "", 8000)
+	if err != nil {
+		return nil, err
+	}
+	if text != "" {
+		context.WriteString(fmt.Sprintf("\nFull Text:\n%s\n", text))
+	}
+
+	prompt := fmt.Sprintf(`Select the %d most important points of this document that a reader
+should be able to recall, and write each as an exam question with a short answer.
+
+Reply with only a JSON array and nothing else, in this form:
+[{"type": "basic", "front": "question", "back": "answer"}]`, n)
+	aiCmd := exec.Command("arc-ai", "ask", prompt)
+	aiCmd.Stdin = strings.NewReader(context.String())
+	var out, errOut bytes.Buffer
+	aiCmd.Stdout = &out
+	aiCmd.Stderr = &errOut
+	if err := aiCmd.Run(); err != nil {
+		return nil, fmt.Errorf("arc-ai failed: %w\nOutput: %s%s", err, out.String(), errOut.String())
+	}
+	cards, _, err := library.ParseGeneratedCards(out.String(), false)
+	return cards, err
+}
+
+// aiFullText selects up to budget bytes of a document's full text for a
+// prompt, by section: the passages most relevant to query, or without one
+// the abstract, introduction, and conclusion first. References are left
+// out. A section restricts the choice to that section.
+func aiFullText(store library.LibraryStore, doc *library.Document, query, section string, budget int) (string, error) {
+	if doc.FullText == "" {
+		if section != "" {
+			return "", fmt.Errorf("document %s has no full text to take a section from", doc.ID)
+		}
+		return "", nil
+	}
+	secs, err := library.LoadDocumentSections(store, doc)
+	if err != nil {
+		return "", fmt.Errorf("load sections: %w", err)
+	}
+	if section != "" && secs.Find(section) == nil {
+		return "", fmt.Errorf("document %s has no %q section", doc.ID, section)
+	}
+	return library.SelectContext(doc.FullText, secs, query, section, budget), nil
+}
 
-	return cards
+// aiResult is the JSON schema for the "ai" subcommands.
+type aiResult struct {
+	DocumentID string                 `json:"document_id"`
+	Prompt     string                 `json:"prompt"`
+	Response   string                 `json:"response"`
+	Stored     bool                   `json:"stored"`
+	Flashcards []*library.Flashcard   `json:"flashcards,omitempty"`
+	Rejected   []library.RejectedCard `json:"rejected,omitempty"` // generated cards that failed validation
+	Skipped    []library.SkippedCard  `json:"skipped,omitempty"`  // generated cards too like existing ones
 }
@@ -5,6 +5,8 @@ package cmd
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/mtreilly/arc-library/internal/library"
@@ -15,7 +17,7 @@ import (
 func newAnnotateCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:     "annotate",
-		Aliases: []string{"ann", "note"},
+		Aliases: []string{"ann"},
 		Short:   "Manage document annotations",
 		Long:    `Add, list, and remove annotations on documents.`,
 	}
@@ -23,6 +25,7 @@ func newAnnotateCmd(cfg *config.Config, store library.LibraryStore) *cobra.Comma
 	cmd.AddCommand(newAnnotateAddCmd(store))
 	cmd.AddCommand(newAnnotateListCmd(store))
 	cmd.AddCommand(newAnnotateDeleteCmd(store))
+	cmd.AddCommand(newAnnotateColorsCmd())
 
 	return cmd
 }
@@ -31,6 +34,7 @@ func newAnnotateAddCmd(store library.LibraryStore) *cobra.Command {
 	var page int
 	var annType string
 	var color string
+	var replyTo string
 
 	cmd := &cobra.Command{
 		Use:   "add <document-id> <content>",
@@ -40,8 +44,13 @@ func newAnnotateAddCmd(store library.LibraryStore) *cobra.Command {
 Examples:
   arc-library annotate add 2304.00067 "Key insight about attention"
   arc-library annotate add 2304.00067 "Important formula" --page 5
-  arc-library annotate add 2304.00067 "TODO: follow up" --type bookmark`,
-		Args: cobra.ExactArgs(2),
+  arc-library annotate add 2304.00067 "TODO: follow up" --type bookmark
+
+--reply-to adds a follow-up thought or resolution to an earlier
+annotation; "annotate list" shows it under the annotation it answers:
+  arc-library annotate add 2304.00067 "Resolved: see section 3.2" --reply-to <annotation-id>`,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeFirst(completeDocuments(store)),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			documentID := args[0]
 			content := args[1]
@@ -57,7 +66,7 @@ Examples:
 				}
 			}
 			if document == nil {
-				return fmt.Errorf("document not found: %s", documentID)
+				return fmt.Errorf("%w: %s", library.ErrDocumentNotFound, documentID)
 			}
 
 			ann := &library.Annotation{
@@ -66,12 +75,27 @@ Examples:
 				Content: content,
 				Page:    page,
 				Color:   color,
+				ParentID: replyTo,
+			}
+			if err := library.LinkReply(store, ann); err != nil {
+				return err
 			}
 
+			// Annotations made while a session is open are linked to it;
+			// without the link they are matched by time instead
+			_ = library.LinkOpenSession(store, ann)
 			if err := store.AddAnnotation(ann); err != nil {
 				return fmt.Errorf("add annotation: %w", err)
 			}
 
+			if jsonOutput(nil) {
+				return output.JSON(ann)
+			}
+			if quietOutput() {
+				printIDs(ann.ID)
+				return nil
+			}
+
 			fmt.Printf("Added %s to %s", annType, truncate(document.Title, 40))
 			if page > 0 {
 				fmt.Printf(" (page %d)", page)
@@ -85,17 +109,32 @@ Examples:
 	cmd.Flags().IntVarP(&page, "page", "p", 0, "Page number")
 	cmd.Flags().StringVarP(&annType, "type", "t", "note", "Type: note, highlight, bookmark")
 	cmd.Flags().StringVarP(&color, "color", "c", "", "Highlight color")
+	cmd.Flags().StringVar(&replyTo, "reply-to", "", "Reply to this annotation")
 
 	return cmd
 }
 
 func newAnnotateListCmd(store library.LibraryStore) *cobra.Command {
-	var out output.OutputOptions
+	var (
+		sessionID string
+		filter    library.AnnotationFilter
+		out       output.OutputOptions
+	)
 
 	cmd := &cobra.Command{
 		Use:   "list <document-id>",
 		Short: "List annotations for a document",
-		Args:  cobra.ExactArgs(1),
+		Long: `List a document's annotations. With --session, only those made during
+that reading session (see "arc-library session show").
+
+--type and --color pick annotations of a type or color, and --meaning
+those whose color stands for a meaning set with "annotate colors set".
+
+Examples:
+  arc-library annotate list 2304.00067 --type highlight --color red
+  arc-library annotate list 2304.00067 --meaning definition`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeFirst(completeDocuments(store)),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := out.Resolve(); err != nil {
 				return err
@@ -114,13 +153,47 @@ func newAnnotateListCmd(store library.LibraryStore) *cobra.Command {
 				}
 			}
 			if document == nil {
-				return fmt.Errorf("document not found: %s", documentID)
+				return fmt.Errorf("%w: %s", library.ErrDocumentNotFound, documentID)
+			}
+
+			var annotations []*library.Annotation
+			if sessionID != "" {
+				session, err := library.FindSession(store, sessionID)
+				if err != nil {
+					return err
+				}
+				if session.DocumentID != document.ID {
+					return fmt.Errorf("session %s is not on %s", sessionID, document.ID)
+				}
+				annotations, err = library.SessionAnnotations(store, session)
+				if err != nil {
+					return err
+				}
+			} else {
+				annotations, err = store.GetAnnotations(document.ID)
+				if err != nil {
+					return err
+				}
 			}
 
-			annotations, err := store.GetAnnotations(document.ID)
+			meanings, err := loadColorMeanings()
 			if err != nil {
 				return err
 			}
+			if filter.Meaning != "" && len(meanings.Colors(filter.Meaning)) == 0 {
+				return fmt.Errorf("no color means %q (see \"annotate colors\")", filter.Meaning)
+			}
+			annotations = library.FilterAnnotations(annotations, filter, meanings)
+
+			if jsonOutput(&out) {
+				return output.JSON(nonNil(annotations))
+			}
+			if quietOutput() {
+				for _, a := range annotations {
+					printIDs(a.ID)
+				}
+				return nil
+			}
 
 			if len(annotations) == 0 {
 				fmt.Printf("No annotations for %s\n", truncate(document.Title, 50))
@@ -129,19 +202,24 @@ func newAnnotateListCmd(store library.LibraryStore) *cobra.Command {
 
 			fmt.Printf("Annotations for: %s\n\n", truncate(document.Title, 50))
 
-			if out.Is(output.OutputJSON) {
-				return output.JSON(annotations)
-			}
-
-			table := output.NewTable("Type", "Page", "Content", "Created")
-			for _, a := range annotations {
+			// Replies follow the annotation they answer, indented
+			threaded, depths := library.FlattenAnnotationThreads(library.BuildAnnotationThreads(annotations))
+			table := output.NewTable("Type", "Page", "Color", "Content", "Created")
+			for i, a := range threaded {
 				pageStr := "-"
 				if a.Page > 0 {
 					pageStr = fmt.Sprintf("%d", a.Page)
 				}
+				color := a.Color
+				if meaning := meanings.Meaning(a.Color); meaning != "" {
+					color += " (" + meaning + ")"
+				}
 				content := truncate(a.Content, 40)
+				if depths[i] > 0 {
+					content = strings.Repeat("  ", depths[i]-1) + "└ " + truncate(a.Content, max(40-2*depths[i], 10))
+				}
 				created := a.CreatedAt.Format("2006-01-02")
-				table.AddRow(a.Type, pageStr, content, created)
+				table.AddRow(a.Type, pageStr, color, content, created)
 			}
 			table.Render()
 
@@ -149,6 +227,10 @@ func newAnnotateListCmd(store library.LibraryStore) *cobra.Command {
 		},
 	}
 
+	cmd.Flags().StringVar(&sessionID, "session", "", "Only annotations made during this reading session")
+	cmd.Flags().StringVarP(&filter.Type, "type", "t", "", "Only annotations of this type: note, highlight, bookmark")
+	cmd.Flags().StringVarP(&filter.Color, "color", "c", "", "Only annotations of this color")
+	cmd.Flags().StringVarP(&filter.Meaning, "meaning", "m", "", "Only annotations whose color has this meaning")
 	out.AddOutputFlags(cmd, output.OutputTable)
 
 	return cmd
@@ -163,8 +245,115 @@ func newAnnotateDeleteCmd(store library.LibraryStore) *cobra.Command {
 			if err := store.DeleteAnnotation(args[0]); err != nil {
 				return err
 			}
-			fmt.Println("Annotation deleted.")
+			if jsonOutput(nil) {
+				return output.JSON(deleteResult{Kind: "annotation", ID: args[0], Deleted: true})
+			}
+			infoln("Annotation deleted.")
+			return nil
+		},
+	}
+}
+
+func newAnnotateColorsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "colors",
+		Short: "Show what annotation colors mean",
+		Long: `Show the meanings given to annotation colors, such as yellow for a
+definition and red for a disagreement. "annotate list --meaning" and
+"export --format markdown --group-by meaning" use them.
+
+The meanings are kept in $ARC_LIBRARY_COLOR_MEANINGS, or
+arc-library/color-meanings.yaml under the user config directory, as a
+map of color to meaning. Colors are names (yellow, red, green, blue,
+orange, purple, pink, gray) or hex codes; a name and its hex code are the
+same color.
+
+Examples:
+  arc-library annotate colors set yellow definition
+  arc-library annotate colors set red disagreement
+  arc-library annotate colors unset red`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			meanings, err := loadColorMeanings()
+			if err != nil {
+				return err
+			}
+			if jsonOutput(nil) {
+				return output.JSON(meanings)
+			}
+			if len(meanings) == 0 {
+				infoln("No color meanings. Add one with \"annotate colors set <color> <meaning>\".")
+				return nil
+			}
+			colors := make([]string, 0, len(meanings))
+			for color := range meanings {
+				colors = append(colors, color)
+			}
+			sort.Strings(colors)
+			table := output.NewTable("Color", "Meaning")
+			for _, color := range colors {
+				table.AddRow(library.ColorName(color), meanings[color])
+			}
+			table.Render()
 			return nil
 		},
 	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "set <color> <meaning>",
+		Short: "Give an annotation color a meaning",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return updateColorMeanings(args[0], args[1])
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "unset <color>",
+		Short: "Remove an annotation color's meaning",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return updateColorMeanings(args[0], "")
+		},
+	})
+
+	return cmd
+}
+
+// loadColorMeanings reads the user's color meanings, or none when there
+// is no config directory.
+func loadColorMeanings() (library.ColorMeanings, error) {
+	path, err := library.ColorMeaningsFile()
+	if err != nil {
+		return library.ColorMeanings{}, nil
+	}
+	return library.LoadColorMeanings(path)
+}
+
+// updateColorMeanings sets or, with an empty meaning, removes a color's
+// meaning in the user's color meanings file.
+func updateColorMeanings(color, meaning string) error {
+	if strings.TrimSpace(color) == "" {
+		return fmt.Errorf("color must not be empty")
+	}
+	path, err := library.ColorMeaningsFile()
+	if err != nil {
+		return err
+	}
+	meanings, err := library.LoadColorMeanings(path)
+	if err != nil {
+		return err
+	}
+	meanings.Set(color, meaning)
+	if err := library.SaveColorMeanings(path, meanings); err != nil {
+		return err
+	}
+	if jsonOutput(nil) {
+		return output.JSON(meanings)
+	}
+	if meaning == "" {
+		infof("%s has no meaning now\n", color)
+	} else {
+		infof("%s means %s\n", color, meaning)
+	}
+	return nil
 }
@@ -0,0 +1,59 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mtreilly/arc-library/internal/library"
+	"github.com/spf13/cobra"
+)
+
+// addArchivePolicy archives stale completed documents, when
+// ARC_LIBRARY_ARCHIVE_AFTER turns the policy on, after the setup of any
+// command that is not a dry run and may write. It runs at most once a day.
+func addArchivePolicy(root *cobra.Command, store library.LibraryStore) {
+	next := root.PersistentPreRunE
+	root.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if next != nil {
+			if err := next(cmd, args); err != nil {
+				return err
+			}
+		}
+		switch cmd.Name() {
+		case "undo", "completion", "help", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+			return nil
+		}
+		if dryRun() || readOnly() {
+			return nil
+		}
+		policy, err := library.ArchivePolicySetting()
+		if err != nil || policy.Months == 0 {
+			return err
+		}
+
+		check, err := library.ArchiveCheckPath()
+		if err != nil {
+			return nil
+		}
+		if fi, err := os.Stat(check); err == nil && time.Since(fi.ModTime()) < 24*time.Hour {
+			return nil
+		}
+		archived, err := policy.Apply(library.WithActor(store, "retention"), time.Now())
+		if err != nil {
+			warnf("Archiving completed documents: %v\n", err)
+			return nil
+		}
+		if len(archived) > 0 {
+			warnf("Archived %d completed document(s) untouched for %d months (see 'list --archived')\n", len(archived), policy.Months)
+		}
+		// Stamp the check even when nothing was archived
+		if err := os.MkdirAll(filepath.Dir(check), 0o755); err == nil {
+			_ = os.WriteFile(check, nil, 0o644)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/mtreilly/arc-library/internal/library"
+	"github.com/spf13/cobra"
+	"github.com/yourorg/arc-sdk/config"
+	"github.com/yourorg/arc-sdk/output"
+)
+
+func newAuditCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command {
+	var (
+		staleDays   int
+		maxFullText int
+		show        int
+		out         output.OutputOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Report on the library's hygiene",
+		Long: `Check every document for gaps worth fixing and report how many fail
+each check:
+
+  missing_abstract  no abstract (notes are skipped)
+  missing_authors   no authors (notes are skipped)
+  missing_file      the file is gone from disk, or a paper or book has none
+  untagged          no tags
+  stale_unread      still unread long after being added (--stale-days)
+  large_full_text   full text bigger than --max-full-text KB
+
+The table lists a few of the documents failing each check (--show). With
+--json the report has every document ID, and the time it was made, so
+reports can be kept to follow the library's hygiene over time. This is
+unrelated to the change log of "arc-library log".
+
+Examples:
+  arc-library audit
+  arc-library audit --stale-days 90 --show 10
+  arc-library audit --json > "health-$(date +%F).json"`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := out.Resolve(); err != nil {
+				return err
+			}
+
+			report, err := library.CheckHealth(store, library.HealthOptions{
+				StaleDays:   staleDays,
+				MaxFullText: maxFullText * 1024,
+			})
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput(&out) {
+				return output.JSON(report)
+			}
+			if quietOutput() {
+				seen := map[string]bool{}
+				for _, c := range report.Checks {
+					for _, id := range c.DocumentIDs {
+						if !seen[id] {
+							seen[id] = true
+							printIDs(id)
+						}
+					}
+				}
+				return nil
+			}
+
+			fmt.Printf("Library audit: %d document(s)\n\n", report.Documents)
+			table := output.NewTable("Check", "Documents", "Share", "Meaning")
+			for _, c := range report.Checks {
+				share := "-"
+				if report.Documents > 0 {
+					share = fmt.Sprintf("%.0f%%", float64(c.Count)*100/float64(report.Documents))
+				}
+				table.AddRow(c.Name, fmt.Sprintf("%d", c.Count), share, c.Description)
+			}
+			table.Render()
+
+			if show > 0 {
+				for _, c := range report.Checks {
+					if c.Count == 0 {
+						continue
+					}
+					fmt.Printf("\n%s:\n", c.Name)
+					for i, id := range c.DocumentIDs {
+						if i == show {
+							fmt.Printf("  ... and %d more\n", c.Count-show)
+							break
+						}
+						title := ""
+						if d, _ := store.GetDocument(id); d != nil {
+							title = truncate(d.Title, 60)
+						}
+						fmt.Printf("  %s  %s\n", id, title)
+					}
+				}
+			}
+
+			if report.Issues() == 0 {
+				fmt.Println("\nNo issues found.")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&staleDays, "stale-days", library.DefaultStaleUnreadDays, "Days after which an unread document is stale")
+	cmd.Flags().IntVar(&maxFullText, "max-full-text", library.DefaultMaxFullTextBytes/1024, "Full text size in KB above which a document is flagged")
+	cmd.Flags().IntVar(&show, "show", 3, "Documents to list per failed check (0 for none)")
+	out.AddOutputFlags(cmd, output.OutputTable)
+	return cmd
+}
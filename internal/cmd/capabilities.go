@@ -0,0 +1,50 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/mtreilly/arc-library/internal/library"
+	"github.com/spf13/cobra"
+)
+
+// unsupported is the error for a feature, such as "tasks", the storage
+// backend lacks.
+func unsupported(feature string) error {
+	return fmt.Errorf("%s are %w: use the SQL backend (unset ARC_LIBRARY_STORAGE)", feature, library.ErrBackendUnsupported)
+}
+
+// requireCapability makes cmds fail up front, before they change anything,
+// when the storage backend lacks the feature they need.
+func requireCapability(store library.LibraryStore, has func(library.Capabilities) bool, feature string, cmds ...*cobra.Command) {
+	for _, sub := range cmds {
+		run := sub.RunE
+		sub.RunE = func(cmd *cobra.Command, args []string) error {
+			if !has(store.Capabilities()) {
+				return unsupported(feature)
+			}
+			return run(cmd, args)
+		}
+	}
+}
+
+// doctorCapabilities warns of the features the storage backend lacks.
+func doctorCapabilities(caps library.Capabilities) []library.DoctorCheck {
+	var checks []library.DoctorCheck
+	for _, f := range []struct {
+		name string
+		has  bool
+	}{
+		{"tasks", caps.Tasks},
+		{"saved searches", caps.SavedSearches},
+	} {
+		c := library.DoctorCheck{Name: f.name, Status: library.DoctorOK, Detail: "supported by the storage backend"}
+		if !f.has {
+			c.Status, c.Detail, c.Fix = library.DoctorWarn, "not supported by the storage backend", "unset ARC_LIBRARY_STORAGE to use the SQL database"
+		}
+		checks = append(checks, c)
+	}
+	return checks
+}
@@ -5,6 +5,9 @@ package cmd
 
 import (
 	"fmt"
+	"net/url"
+	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/mtreilly/arc-library/internal/library"
@@ -26,6 +29,8 @@ func newCollectionCmd(cfg *config.Config, store library.LibraryStore) *cobra.Com
 	cmd.AddCommand(newCollectionAddCmd(store))
 	cmd.AddCommand(newCollectionRemoveCmd(store))
 	cmd.AddCommand(newCollectionDeleteCmd(store))
+	cmd.AddCommand(newCollectionPublishCmd(store, true))
+	cmd.AddCommand(newCollectionPublishCmd(store, false))
 
 	return cmd
 }
@@ -42,7 +47,7 @@ func newCollectionCreateCmd(store library.LibraryStore) *cobra.Command {
 
 			existing, _ := store.GetCollection(name)
 			if existing != nil {
-				return fmt.Errorf("collection %q already exists", name)
+				return fmt.Errorf("%w: %s", library.ErrCollectionExists, name)
 			}
 
 			c, err := store.CreateCollection(name, description)
@@ -50,6 +55,13 @@ func newCollectionCreateCmd(store library.LibraryStore) *cobra.Command {
 				return err
 			}
 
+			if jsonOutput(nil) {
+				return output.JSON(c)
+			}
+			if quietOutput() {
+				printIDs(c.ID)
+				return nil
+			}
 			fmt.Printf("Created collection: %s (id: %s)\n", c.Name, c.ID)
 			return nil
 		},
@@ -76,19 +88,29 @@ func newCollectionListCmd(store library.LibraryStore) *cobra.Command {
 				return err
 			}
 
-			if len(collections) == 0 {
-				fmt.Println("No collections found.")
+			if jsonOutput(&out) {
+				return output.JSON(nonNil(collections))
+			}
+			if quietOutput() {
+				for _, c := range collections {
+					printIDs(c.Name)
+				}
 				return nil
 			}
 
-			if out.Is(output.OutputJSON) {
-				return output.JSON(collections)
+			if len(collections) == 0 {
+				fmt.Println("No collections found.")
+				return nil
 			}
 
-			table := output.NewTable("Name", "Documents", "Description")
+			table := output.NewTable("Name", "Documents", "Public", "Description")
 			for _, c := range collections {
 				desc := truncate(c.Description, 40)
-				table.AddRow(c.Name, fmt.Sprintf("%d", len(c.DocumentIDs)), desc)
+				public := ""
+				if c.Public {
+					public = "yes"
+				}
+				table.AddRow(c.Name, fmt.Sprintf("%d", len(c.DocumentIDs)), public, desc)
 			}
 			table.Render()
 
@@ -105,9 +127,10 @@ func newCollectionShowCmd(store library.LibraryStore) *cobra.Command {
 	var out output.OutputOptions
 
 	cmd := &cobra.Command{
-		Use:   "show <name>",
-		Short: "Show documents in a collection",
-		Args:  cobra.ExactArgs(1),
+		Use:               "show <name>",
+		Short:             "Show documents in a collection",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeFirst(completeCollections(store)),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := out.Resolve(); err != nil {
 				return err
@@ -118,7 +141,22 @@ func newCollectionShowCmd(store library.LibraryStore) *cobra.Command {
 				return err
 			}
 			if c == nil {
-				return fmt.Errorf("collection not found: %s", args[0])
+				return fmt.Errorf("%w: %s", library.ErrCollectionNotFound, args[0])
+			}
+
+			if jsonOutput(&out) {
+				documents := []*library.Document{}
+				for _, id := range c.DocumentIDs {
+					p, _ := store.GetDocument(id)
+					if p != nil {
+						documents = append(documents, p)
+					}
+				}
+				return output.JSON(documents)
+			}
+			if quietOutput() {
+				printIDs(c.DocumentIDs...)
+				return nil
 			}
 
 			fmt.Printf("Collection: %s\n", c.Name)
@@ -131,17 +169,6 @@ func newCollectionShowCmd(store library.LibraryStore) *cobra.Command {
 				return nil
 			}
 
-			if out.Is(output.OutputJSON) {
-				var documents []*library.Document
-				for _, id := range c.DocumentIDs {
-					p, _ := store.GetDocument(id)
-					if p != nil {
-						documents = append(documents, p)
-					}
-				}
-				return output.JSON(documents)
-			}
-
 			table := output.NewTable("Source ID", "Title", "Tags")
 			for _, id := range c.DocumentIDs {
 				p, err := store.GetDocument(id)
@@ -167,9 +194,10 @@ func newCollectionShowCmd(store library.LibraryStore) *cobra.Command {
 
 func newCollectionAddCmd(store library.LibraryStore) *cobra.Command {
 	return &cobra.Command{
-		Use:   "add <collection> <document-id> [document-id...]",
-		Short: "Add documents to a collection",
-		Args:  cobra.MinimumNArgs(2),
+		Use:               "add <collection> <document-id> [document-id...]",
+		Short:             "Add documents to a collection",
+		Args:              cobra.MinimumNArgs(2),
+		ValidArgsFunction: completeFirstThen(completeCollections(store), completeDocuments(store)),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			collName := args[0]
 			documentIDs := args[1:]
@@ -179,10 +207,10 @@ func newCollectionAddCmd(store library.LibraryStore) *cobra.Command {
 				return err
 			}
 			if c == nil {
-				return fmt.Errorf("collection not found: %s", collName)
+				return fmt.Errorf("%w: %s", library.ErrCollectionNotFound, collName)
 			}
 
-			added := 0
+			result := membershipResult{Target: c.ID, Changed: []string{}}
 			for _, pid := range documentIDs {
 				document, _ := store.GetDocument(pid)
 				if document == nil {
@@ -193,19 +221,24 @@ func newCollectionAddCmd(store library.LibraryStore) *cobra.Command {
 					}
 				}
 				if document == nil {
-					fmt.Printf("Document not found: %s\n", pid)
+					warnf("Document not found: %s\n", pid)
+					result.NotFound = append(result.NotFound, pid)
 					continue
 				}
 
 				if err := store.AddToCollection(c.ID, document.ID); err != nil {
-					fmt.Printf("Failed to add %s: %v\n", pid, err)
+					warnf("Failed to add %s: %v\n", pid, err)
+					result.Failed = append(result.Failed, pid)
 					continue
 				}
-				fmt.Printf("Added: %s\n", truncate(document.Title, 50))
-				added++
+				infof("Added: %s\n", truncate(document.Title, 50))
+				result.Changed = append(result.Changed, document.ID)
 			}
 
-			fmt.Printf("\nAdded %d document(s) to %s.\n", added, c.Name)
+			if jsonOutput(nil) {
+				return output.JSON(result)
+			}
+			infof("\nAdded %d document(s) to %s.\n", len(result.Changed), c.Name)
 			return nil
 		},
 	}
@@ -213,9 +246,10 @@ func newCollectionAddCmd(store library.LibraryStore) *cobra.Command {
 
 func newCollectionRemoveCmd(store library.LibraryStore) *cobra.Command {
 	return &cobra.Command{
-		Use:   "remove <collection> <document-id> [document-id...]",
-		Short: "Remove documents from a collection",
-		Args:  cobra.MinimumNArgs(2),
+		Use:               "remove <collection> <document-id> [document-id...]",
+		Short:             "Remove documents from a collection",
+		Args:              cobra.MinimumNArgs(2),
+		ValidArgsFunction: completeFirstThen(completeCollections(store), completeDocuments(store)),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			collName := args[0]
 			documentIDs := args[1:]
@@ -225,10 +259,10 @@ func newCollectionRemoveCmd(store library.LibraryStore) *cobra.Command {
 				return err
 			}
 			if c == nil {
-				return fmt.Errorf("collection not found: %s", collName)
+				return fmt.Errorf("%w: %s", library.ErrCollectionNotFound, collName)
 			}
 
-			removed := 0
+			result := membershipResult{Target: c.ID, Changed: []string{}}
 			for _, pid := range documentIDs {
 				document, _ := store.GetDocument(pid)
 				if document == nil {
@@ -238,19 +272,24 @@ func newCollectionRemoveCmd(store library.LibraryStore) *cobra.Command {
 					}
 				}
 				if document == nil {
-					fmt.Printf("Document not found: %s\n", pid)
+					warnf("Document not found: %s\n", pid)
+					result.NotFound = append(result.NotFound, pid)
 					continue
 				}
 
 				if err := store.RemoveFromCollection(c.ID, document.ID); err != nil {
-					fmt.Printf("Failed to remove %s: %v\n", pid, err)
+					warnf("Failed to remove %s: %v\n", pid, err)
+					result.Failed = append(result.Failed, pid)
 					continue
 				}
-				fmt.Printf("Removed: %s\n", truncate(document.Title, 50))
-				removed++
+				infof("Removed: %s\n", truncate(document.Title, 50))
+				result.Changed = append(result.Changed, document.ID)
 			}
 
-			fmt.Printf("\nRemoved %d document(s) from %s.\n", removed, c.Name)
+			if jsonOutput(nil) {
+				return output.JSON(result)
+			}
+			infof("\nRemoved %d document(s) from %s.\n", len(result.Changed), c.Name)
 			return nil
 		},
 	}
@@ -260,27 +299,31 @@ func newCollectionDeleteCmd(store library.LibraryStore) *cobra.Command {
 	var force bool
 
 	cmd := &cobra.Command{
-		Use:   "delete <name>",
-		Short: "Delete a collection",
-		Args:  cobra.ExactArgs(1),
+		Use:               "delete <name>",
+		Short:             "Delete a collection",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeFirst(completeCollections(store)),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			c, err := store.GetCollection(args[0])
 			if err != nil {
 				return err
 			}
 			if c == nil {
-				return fmt.Errorf("collection not found: %s", args[0])
+				return fmt.Errorf("%w: %s", library.ErrCollectionNotFound, args[0])
 			}
 
 			if !force && len(c.DocumentIDs) > 0 {
 				return fmt.Errorf("collection %q has %d documents, use --force to delete", c.Name, len(c.DocumentIDs))
 			}
 
-			if err := store.DeleteCollection(c.ID); err != nil {
+			if _, err := library.DeleteCollectionUndoable(store, c.ID); err != nil {
 				return err
 			}
 
-			fmt.Printf("Deleted collection: %s\n", c.Name)
+			if jsonOutput(nil) {
+				return output.JSON(deleteResult{Kind: "collection", ID: c.ID, Deleted: true})
+			}
+			infof("Deleted collection: %s (arc-library undo to restore)\n", c.Name)
 			return nil
 		},
 	}
@@ -289,3 +332,89 @@ func newCollectionDeleteCmd(store library.LibraryStore) *cobra.Command {
 
 	return cmd
 }
+
+// collectionPublishResult is the JSON schema for "collection publish" and
+// "collection unpublish".
+type collectionPublishResult struct {
+	Collection string `json:"collection"`
+	Public     bool   `json:"public"`
+	FeedURL    string `json:"feed_url"`
+	PageURL    string `json:"page_url"`
+}
+
+func newCollectionPublishCmd(store library.LibraryStore, public bool) *cobra.Command {
+	var baseURL string
+
+	cmd := &cobra.Command{
+		Use:   "publish <name>",
+		Short: "Serve a collection publicly as a feed and reading list",
+		Long: `Publish a collection: "arc-library serve" then gives anyone its Atom
+feed at /feed/collection/<name>.xml and its reading list page at
+/list/<name>, listing each document's title, authors, abstract, and public
+link (web page, DOI, or arXiv) newest first. Files, notes, and tags are not
+shown. Published collections are served with --shares-only and without a
+token under --require-token.
+
+--base-url (or ARC_LIBRARY_SHARE_URL) is the address others reach the
+server at. "collection unpublish" makes the collection private again.
+
+Examples:
+  arc-library collection publish reading-group --base-url https://papers.example.org
+  arc-library collection unpublish reading-group`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeFirst(completeCollections(store)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := store.GetCollection(args[0])
+			if err != nil {
+				return err
+			}
+			if c == nil {
+				return fmt.Errorf("%w: %s", library.ErrCollectionNotFound, args[0])
+			}
+			if c.Public != public {
+				if err := store.SetCollectionPublic(c.ID, public); err != nil {
+					return err
+				}
+			}
+
+			base := strings.TrimSuffix(baseURL, "/")
+			result := collectionPublishResult{
+				Collection: c.Name,
+				Public:     public,
+				FeedURL:    base + "/feed/collection/" + url.PathEscape(c.Name) + ".xml",
+				PageURL:    base + "/list/" + url.PathEscape(c.Name),
+			}
+			if jsonOutput(nil) {
+				return output.JSON(result)
+			}
+			if quietOutput() {
+				if public {
+					fmt.Println(result.FeedURL)
+				}
+				return nil
+			}
+			if !public {
+				fmt.Printf("Collection %s is private again.\n", c.Name)
+				return nil
+			}
+			fmt.Printf("Published %s:\n", c.Name)
+			fmt.Printf("  Feed:         %s\n", result.FeedURL)
+			fmt.Printf("  Reading list: %s\n", result.PageURL)
+			return nil
+		},
+	}
+	if !public {
+		cmd.Use = "unpublish <name>"
+		cmd.Short = "Stop serving a collection publicly"
+		cmd.Long = `Make a published collection private again: its feed and reading list
+are then only served to those who may read the library.`
+	}
+
+	defaultBase := os.Getenv("ARC_LIBRARY_SHARE_URL")
+	if defaultBase == "" {
+		defaultBase = defaultShareBaseURL
+	}
+	cmd.Flags().StringVar(&baseURL, "base-url", defaultBase, "URL the serve process is reached at")
+
+	return cmd
+}
@@ -0,0 +1,218 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/mtreilly/arc-library/internal/library"
+	"github.com/spf13/cobra"
+)
+
+// completionFunc is the signature cobra expects for dynamic argument and flag completion.
+type completionFunc func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+func newCompletionCmd() *cobra.Command {
+	var noDesc bool
+
+	cmd := &cobra.Command{
+		Use:   "completion bash|zsh|fish",
+		Short: "Generate shell completion scripts",
+		Long: `Generate a completion script for your shell.
+
+Completions are dynamic: document IDs (with titles), collection names,
+tags, and saved-search names are read from the library as you type.
+
+Bash:
+  source <(arc-library completion bash)
+  # or persist it:
+  arc-library completion bash > /etc/bash_completion.d/arc-library
+
+Zsh:
+  arc-library completion zsh > "${fpath[1]}/_arc-library"
+
+Fish:
+  arc-library completion fish > ~/.config/fish/completions/arc-library.fish`,
+		Args:                  cobra.ExactArgs(1),
+		ValidArgs:             []string{"bash", "zsh", "fish"},
+		DisableFlagsInUseLine: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root := cmd.Root()
+			switch args[0] {
+			case "bash":
+				return root.GenBashCompletionV2(os.Stdout, !noDesc)
+			case "zsh":
+				if noDesc {
+					return root.GenZshCompletionNoDesc(os.Stdout)
+				}
+				return root.GenZshCompletion(os.Stdout)
+			case "fish":
+				return root.GenFishCompletion(os.Stdout, !noDesc)
+			default:
+				return fmt.Errorf("unsupported shell: %s (choose bash, zsh, fish)", args[0])
+			}
+		},
+	}
+
+	cmd.Flags().BoolVar(&noDesc, "no-descriptions", false, "Disable completion descriptions")
+
+	return cmd
+}
+
+// completionDocumentLimit caps how many documents completeDocuments loads,
+// so pressing tab stays fast in a large library.
+const completionDocumentLimit = 200
+
+// completeDocuments suggests document IDs, annotated with their titles.
+// Only the most recently updated documents are offered.
+func completeDocuments(store library.LibraryStore) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		docs, err := store.ListDocuments(&library.ListOptions{Limit: completionDocumentLimit})
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		var out []string
+		for _, d := range docs {
+			if strings.HasPrefix(d.ID, toComplete) {
+				out = append(out, d.ID+"\t"+truncate(d.Title, 60))
+			}
+			if d.SourceID != "" && d.SourceID != d.ID && strings.HasPrefix(d.SourceID, toComplete) {
+				out = append(out, d.SourceID+"\t"+truncate(d.Title, 60))
+			}
+		}
+		return out, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeCollections suggests collection names, annotated with their descriptions.
+func completeCollections(store library.LibraryStore) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		collections, err := store.ListCollections()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		var out []string
+		for _, c := range collections {
+			if !strings.HasPrefix(strings.ToLower(c.Name), strings.ToLower(toComplete)) {
+				continue
+			}
+			if c.Description != "" {
+				out = append(out, c.Name+"\t"+truncate(c.Description, 60))
+			} else {
+				out = append(out, c.Name)
+			}
+		}
+		return out, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeTags suggests existing tags, most used first.
+func completeTags(store library.LibraryStore) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		tags, err := store.ListTags()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		names := make([]string, 0, len(tags))
+		for tag := range tags {
+			if strings.HasPrefix(strings.ToLower(tag), strings.ToLower(toComplete)) {
+				names = append(names, tag)
+			}
+		}
+		sort.Slice(names, func(i, j int) bool {
+			if tags[names[i]] != tags[names[j]] {
+				return tags[names[i]] > tags[names[j]]
+			}
+			return names[i] < names[j]
+		})
+		out := make([]string, 0, len(names))
+		for _, name := range names {
+			out = append(out, fmt.Sprintf("%s\t%d document(s)", name, tags[name]))
+		}
+		return out, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeSavedSearches suggests saved-search names, annotated with their queries.
+func completeSavedSearches(store library.LibraryStore) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		// Backends without saved searches simply offer nothing
+		if !store.Capabilities().SavedSearches {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		searches, err := store.ListSavedSearches()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		var out []string
+		for _, ss := range searches {
+			if strings.HasPrefix(ss.Name, toComplete) {
+				out = append(out, ss.Name+"\t"+truncate(ss.Query, 60))
+			}
+		}
+		return out, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeFields suggests custom field names, annotated with their types.
+func completeFields(store library.LibraryStore) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		defs, err := store.ListFields()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		var out []string
+		for _, def := range defs {
+			if strings.HasPrefix(def.Name, strings.ToLower(toComplete)) {
+				out = append(out, def.Name+"\t"+string(def.Type))
+			}
+		}
+		return out, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeFirstThen completes the first positional argument with first and
+// every following argument with rest.
+func completeFirstThen(first, rest completionFunc) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return first(cmd, args, toComplete)
+		}
+		if rest == nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return rest(cmd, args, toComplete)
+	}
+}
+
+// completeFirst completes only the first positional argument.
+func completeFirst(first completionFunc) completionFunc {
+	return completeFirstThen(first, nil)
+}
+
+// registerFlagCompletions walks the command tree and attaches dynamic
+// completion to the flags that name library entities.
+func registerFlagCompletions(root *cobra.Command, store library.LibraryStore) {
+	flagCompletions := map[string]completionFunc{
+		"tag":        completeTags(store),
+		"collection": completeCollections(store),
+		"document":   completeDocuments(store),
+	}
+
+	var walk func(c *cobra.Command)
+	walk = func(c *cobra.Command) {
+		for name, fn := range flagCompletions {
+			if c.Flags().Lookup(name) != nil {
+				_ = c.RegisterFlagCompletionFunc(name, fn)
+			}
+		}
+		for _, sub := range c.Commands() {
+			walk(sub)
+		}
+	}
+	walk(root)
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/mtreilly/arc-library/internal/library"
+	"github.com/spf13/cobra"
+	"github.com/yourorg/arc-sdk/config"
+	"github.com/yourorg/arc-sdk/output"
+)
+
+func newDBCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "Maintain the library's storage",
+	}
+
+	cmd.AddCommand(newDBCompactCmd(store))
+
+	return cmd
+}
+
+func newDBCompactCmd(store library.LibraryStore) *cobra.Command {
+	var out output.OutputOptions
+
+	cmd := &cobra.Command{
+		Use:   "compact",
+		Short: "Store full text as configured and reclaim free space",
+		Long: `Full text is stored compressed with zstd, or with ARC_LIBRARY_FULLTEXT=external
+in plain text files under the data directory. Compact stores the full text
+of documents saved before compression or under the other setting the way
+the setting says, removes full text files no document refers to, and with
+the SQL backend vacuums the database so the file shrinks by the space
+freed. It reports the sizes before and after.
+
+Examples:
+  arc-library db compact
+  arc-library db compact -o json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := out.Resolve(); err != nil {
+				return err
+			}
+
+			stats, err := store.Compact()
+			if err != nil {
+				return fmt.Errorf("compact: %w", err)
+			}
+
+			if jsonOutput(&out) {
+				return output.JSON(stats)
+			}
+			if quietOutput() || dryRun() {
+				return nil
+			}
+
+			fmt.Printf("Re-stored the full text of %d document(s)\n", stats.Documents)
+			if stats.FullText > 0 {
+				fmt.Printf("Full text: %s, %s of it in storage (%.0f%%)\n",
+					formatBytes(stats.FullText), formatBytes(stats.StoredText), 100*float64(stats.StoredText)/float64(stats.FullText))
+			}
+			fmt.Printf("Storage:   %s -> %s, %s saved\n",
+				formatBytes(stats.SizeBefore), formatBytes(stats.SizeAfter), formatBytes(stats.Saved()))
+			if stats.FilesRemoved > 0 {
+				fmt.Printf("Removed %d full text file(s) no document refers to\n", stats.FilesRemoved)
+			}
+			return nil
+		},
+	}
+
+	out.AddOutputFlags(cmd, output.OutputTable)
+
+	return cmd
+}
+
+// formatBytes formats a size in bytes with a binary unit, as in 1.5 MiB.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit && n > -unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	f, exp := float64(n)/unit, 0
+	for f >= unit || f <= -unit {
+		f /= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", f, "KMGTPE"[exp])
+}
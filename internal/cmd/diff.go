@@ -0,0 +1,153 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mtreilly/arc-library/internal/library"
+	"github.com/spf13/cobra"
+	"github.com/yourorg/arc-sdk/config"
+	"github.com/yourorg/arc-sdk/output"
+)
+
+func newDiffCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command {
+	var (
+		since  string
+		entity string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "diff [<backup-a> <backup-b>]",
+		Short: "Show what changed between two backups, or since a date",
+		Long: `Show the documents, annotations, flashcards, collections, and links
+added, removed, and modified between two backups written by
+"export --format backup", the first taken before the second, either of
+them compressed with gzip or not. Records are matched by ID, and
+modifications list the fields that changed, so a migration or sync can
+be reviewed before the old copy is thrown away.
+
+With --since instead of backups, the changes are read from the audit log
+(see "log") for the library as it is now: a record added and removed
+again in that time is left out, and repeated updates are merged.
+
+Examples:
+  arc-library export --format backup -o before.json
+  arc-library diff before.json after.json
+  arc-library export --format backup | gzip > after.json.gz
+  arc-library diff before.json after.json.gz --entity annotation
+  arc-library diff --since 7d
+  arc-library diff --since 2025-01-01 --json`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if since != "" {
+				return cobra.NoArgs(cmd, args)
+			}
+			if len(args) != 2 {
+				return fmt.Errorf("requires two backups, or --since")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if entity != "" && !slices.Contains(library.DiffEntities, entity) {
+				return fmt.Errorf("unknown entity %q (use %s)", entity, strings.Join(library.DiffEntities, ", "))
+			}
+
+			var diff *library.LibraryDiff
+			if since != "" {
+				t, err := parseSince(since, time.Now())
+				if err != nil {
+					return err
+				}
+				entries, err := store.ListAuditEntries(&library.AuditListOptions{Since: t})
+				if err != nil {
+					return err
+				}
+				diff = library.DiffAuditLog(entries)
+			} else {
+				before, err := library.ReadLibraryBackup(args[0])
+				if err != nil {
+					return err
+				}
+				after, err := library.ReadLibraryBackup(args[1])
+				if err != nil {
+					return err
+				}
+				if diff, err = library.DiffBackups(before, after); err != nil {
+					return err
+				}
+			}
+			if entity != "" {
+				diff = filterDiff(diff, entity)
+			}
+
+			if jsonOutput(nil) {
+				return output.JSON(diff)
+			}
+			if quietOutput() {
+				for _, c := range diff.Changes {
+					fmt.Println(c.ID)
+				}
+				return nil
+			}
+			if len(diff.Changes) == 0 {
+				fmt.Println("No changes.")
+				return nil
+			}
+
+			table := output.NewTable("Change", "Entity", "ID", "Summary")
+			for _, c := range diff.Changes {
+				summary := c.Summary
+				if len(c.Fields) > 0 {
+					summary = strings.TrimSpace(summary + " [" + strings.Join(c.Fields, ", ") + "]")
+				}
+				table.AddRow(diffChangeLabel(c.Action), c.Entity, truncate(c.ID, 24), truncate(summary, 60))
+			}
+			table.Render()
+
+			fmt.Println()
+			for _, n := range diff.Counts {
+				if n.Added+n.Removed+n.Modified > 0 {
+					fmt.Printf("%ss: %d added, %d removed, %d modified\n", n.Entity, n.Added, n.Removed, n.Modified)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&since, "since", "", "Diff the audit log since a date (2025-01-31, last monday) or this long ago (e.g. 12h, 7d, 2w) instead of two backups")
+	cmd.Flags().StringVar(&entity, "entity", "", "Only changes to this kind of record: "+strings.Join(library.DiffEntities, ", "))
+	cmd.RegisterFlagCompletionFunc("entity", cobra.FixedCompletions(library.DiffEntities, cobra.ShellCompDirectiveNoFileComp))
+
+	return cmd
+}
+
+// filterDiff returns the part of diff about one kind of record.
+func filterDiff(diff *library.LibraryDiff, entity string) *library.LibraryDiff {
+	out := &library.LibraryDiff{Changes: []library.EntityChange{}, Counts: []library.DiffCount{}}
+	for _, c := range diff.Changes {
+		if c.Entity == entity {
+			out.Changes = append(out.Changes, c)
+		}
+	}
+	for _, n := range diff.Counts {
+		if n.Entity == entity {
+			out.Counts = append(out.Counts, n)
+		}
+	}
+	return out
+}
+
+// diffChangeLabel names a change as diff shows it.
+func diffChangeLabel(a library.AuditAction) string {
+	switch a {
+	case library.AuditCreate:
+		return "added"
+	case library.AuditDelete:
+		return "removed"
+	}
+	return "modified"
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mtreilly/arc-library/internal/library"
+	"github.com/spf13/cobra"
+	"github.com/yourorg/arc-sdk/config"
+	"github.com/yourorg/arc-sdk/output"
+)
+
+// digestResult is the JSON schema for "digest".
+type digestResult struct {
+	*library.Digest
+	Recipients []string `json:"recipients"`
+	Sent       bool     `json:"sent"`
+}
+
+func newDigestCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command {
+	var (
+		emails    []string
+		since     string
+		ahead     string
+		server    string
+		user      string
+		from      string
+		printOnly bool
+		skipEmpty bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "digest",
+		Short: "Email a summary of new documents and what is due",
+		Long: `Email a digest of the documents imported and annotations made since
+--since, the flashcards due now, and the open tasks due within --ahead
+(overdue ones included), as plain text and HTML.
+
+Mail goes through the SMTP server --smtp (or ARC_LIBRARY_SMTP) as
+host[:port], port 587 by default; port 465 uses TLS from the start, others
+STARTTLS when the server offers it. The user and password come from
+ARC_LIBRARY_SMTP_USER and ARC_LIBRARY_SMTP_PASSWORD, the sender from
+ARC_LIBRARY_SMTP_FROM or else the user, and the recipients from --email or
+ARC_LIBRARY_DIGEST_EMAIL.
+
+It sends once and exits, so schedule it with cron, e.g. every Monday at 8:
+  0 8 * * 1  arc-library digest --since 7d --skip-empty
+
+Examples:
+  arc-library digest --email me@example.com --since 7d
+  arc-library digest --since 1d --ahead 2d --skip-empty
+  arc-library digest --print                 # Show the email instead of sending it`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			now := library.Now()
+			start, err := parseSince(since, now)
+			if err != nil {
+				return err
+			}
+			window, err := library.ParseShareTTL(ahead)
+			if err != nil {
+				return fmt.Errorf("invalid --ahead %q (use e.g. 12h, 7d, 2w)", ahead)
+			}
+
+			var to []string
+			for _, e := range emails {
+				if e = strings.TrimSpace(e); e != "" {
+					to = append(to, e)
+				}
+			}
+			smtpCfg := &library.SMTPConfig{
+				Addr:     server,
+				User:     user,
+				Password: os.Getenv("ARC_LIBRARY_SMTP_PASSWORD"),
+				From:     from,
+			}
+			if smtpCfg.From == "" {
+				smtpCfg.From = user
+			}
+			if !printOnly {
+				switch {
+				case len(to) == 0:
+					return fmt.Errorf("--email is required (or set ARC_LIBRARY_DIGEST_EMAIL)")
+				case server == "":
+					return fmt.Errorf("--smtp is required (or set ARC_LIBRARY_SMTP)")
+				case smtpCfg.From == "":
+					return fmt.Errorf("no sender: set ARC_LIBRARY_SMTP_FROM or ARC_LIBRARY_SMTP_USER")
+				}
+				if !strings.Contains(server, ":") {
+					smtpCfg.Addr = server + ":587"
+				}
+			}
+
+			digest, err := library.ComputeDigest(store, start, now, window)
+			if err != nil {
+				return err
+			}
+			result := digestResult{Digest: digest, Recipients: nonNil(to)}
+			sender := smtpCfg.From
+			if sender == "" {
+				sender = "arc-library@localhost"
+			}
+			msg, err := digest.Message(sender, to)
+			if err != nil {
+				return fmt.Errorf("build digest: %w", err)
+			}
+
+			switch {
+			case printOnly:
+				if !jsonOutput(nil) {
+					_, err := os.Stdout.Write(msg)
+					return err
+				}
+			case skipEmpty && digest.Empty():
+				infof("Nothing to report; no digest sent\n")
+			default:
+				if err := smtpCfg.Send(to, msg); err != nil {
+					return err
+				}
+				result.Sent = true
+			}
+
+			if jsonOutput(nil) {
+				return output.JSON(result)
+			}
+			if result.Sent && !quietOutput() {
+				fmt.Printf("Sent %q to %s\n", digest.Subject(), strings.Join(to, ", "))
+			}
+			return nil
+		},
+	}
+
+	var defaultEmails []string
+	if env := os.Getenv("ARC_LIBRARY_DIGEST_EMAIL"); env != "" {
+		defaultEmails = strings.Split(env, ",")
+	}
+	cmd.Flags().StringSliceVar(&emails, "email", defaultEmails, "Recipient address (repeatable)")
+	cmd.Flags().StringVar(&since, "since", "7d", "Report activity since a date (2025-01-31, last monday) or for a period (e.g. 1d, 7d, 2w)")
+	cmd.Flags().StringVar(&ahead, "ahead", "7d", "Report tasks due within this period")
+	cmd.Flags().StringVar(&server, "smtp", os.Getenv("ARC_LIBRARY_SMTP"), "SMTP server as host[:port]")
+	cmd.Flags().StringVar(&user, "smtp-user", os.Getenv("ARC_LIBRARY_SMTP_USER"), "SMTP user name")
+	cmd.Flags().StringVar(&from, "from", os.Getenv("ARC_LIBRARY_SMTP_FROM"), "Sender address (default: the SMTP user)")
+	cmd.Flags().BoolVar(&printOnly, "print", false, "Write the email to stdout instead of sending it")
+	cmd.Flags().BoolVar(&skipEmpty, "skip-empty", false, "Send nothing when there is nothing to report")
+
+	return cmd
+}
@@ -0,0 +1,975 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mtreilly/arc-library/internal/library"
+	"github.com/spf13/cobra"
+	"github.com/yourorg/arc-sdk/config"
+	"github.com/yourorg/arc-sdk/output"
+)
+
+func newDocCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "doc",
+		Aliases: []string{"document"},
+		Short:   "Inspect individual documents",
+		Long: `Show details for a single document, including related annotations, sessions,
+flashcards, and tasks, link related documents, and browse or revert its
+metadata history. Deleting a document can be undone with "arc-library undo".`,
+	}
+
+	cmd.AddCommand(newDocShowCmd(store))
+	cmd.AddCommand(newDocLinkCmd(store))
+	cmd.AddCommand(newDocHistoryCmd(store))
+	cmd.AddCommand(newDocRevertCmd(store))
+	cmd.AddCommand(newDocThumbnailCmd(store))
+	cmd.AddCommand(newDocSectionsCmd(store))
+	cmd.AddCommand(newDocReferencesCmd(store))
+	cmd.AddCommand(newDocExtractCmd(store))
+	cmd.AddCommand(newDocReviewCmd(store))
+	cmd.AddCommand(newDocDeleteCmd(store))
+
+	return cmd
+}
+
+// lookupDocument finds the document an argument names: see
+// library.FindDocument.
+func lookupDocument(store library.LibraryStore, idOrQuery string) (*library.Document, error) {
+	return library.FindDocument(store, idOrQuery)
+}
+
+// docShowResult is the JSON schema for "doc show".
+type docShowResult struct {
+	*library.Document
+	Annotations int             `json:"annotation_count"`
+	Sessions    int             `json:"session_count"`
+	Flashcards  int             `json:"flashcard_count"`
+	OpenTasks   []*library.Task `json:"open_tasks"`
+	Links       []docLinkView   `json:"links"`
+}
+
+func newDocShowCmd(store library.LibraryStore) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "show <document-id>",
+		Short:             "Show a document and its related items",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeFirst(completeDocuments(store)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			doc, err := lookupDocument(store, args[0])
+			if err != nil {
+				return err
+			}
+
+			// Related counts are best-effort; a backend lacking one just reports zero
+			result := docShowResult{Document: doc}
+			if anns, err := store.GetAnnotations(doc.ID); err == nil {
+				result.Annotations = len(anns)
+			}
+			if sessions, err := store.ListSessions(doc.ID); err == nil {
+				result.Sessions = len(sessions)
+			}
+			if cards, err := store.ListFlashcards(&library.FlashcardListOptions{DocumentID: doc.ID}); err == nil {
+				result.Flashcards = len(cards)
+			}
+			result.OpenTasks = openDocumentTasks(store, doc.ID)
+			result.Links = []docLinkView{}
+			if links, err := documentLinkViews(store, doc.ID); err == nil {
+				result.Links = links
+			}
+
+			if jsonOutput(nil) {
+				return output.JSON(result)
+			}
+			if quietOutput() {
+				printIDs(doc.ID)
+				return nil
+			}
+
+			fmt.Printf("%s\n", doc.Title)
+			fmt.Printf("ID:          %s\n", doc.ID)
+			fmt.Printf("Type:        %s\n", doc.Type)
+			if doc.Source != "" {
+				source := doc.Source
+				if doc.SourceID != "" {
+					source += ": " + doc.SourceID
+				}
+				fmt.Printf("Source:      %s\n", source)
+			}
+			if authors := strings.TrimSpace(strings.Join(doc.Authors, ", ")); authors != "" {
+				fmt.Printf("Authors:     %s\n", authors)
+			}
+			if doc.Path != "" {
+				fmt.Printf("Path:        %s\n", library.DocumentPath(doc))
+			}
+			fmt.Printf("Status:      %s\n", documentStatus(doc))
+			if doc.Rating > 0 {
+				fmt.Printf("Rating:      %d/5\n", doc.Rating)
+			}
+			if len(doc.Tags) > 0 {
+				fmt.Printf("Tags:        %s\n", strings.Join(doc.Tags, ", "))
+			}
+			if defs, err := store.ListFields(); err == nil {
+				for _, def := range defs {
+					if v := fieldValue(doc, def); v != "" {
+						fmt.Printf("%-13s%s\n", def.Name+":", v)
+					}
+				}
+			}
+			fmt.Printf("Added:       %s\n", doc.CreatedAt.Format("2006-01-02"))
+			if !doc.ReadAt.IsZero() {
+				fmt.Printf("Read:        %s\n", doc.ReadAt.Format("2006-01-02"))
+			}
+			fmt.Printf("Annotations: %d\n", result.Annotations)
+			fmt.Printf("Sessions:    %d\n", result.Sessions)
+			fmt.Printf("Flashcards:  %d\n", result.Flashcards)
+			if review, err := store.GetDocumentReview(doc.ID); err == nil && review != nil {
+				fmt.Printf("Re-read:     %s\n", library.HomeDate(review.DueAt))
+			}
+			fmt.Printf("Open tasks:  %d\n", len(result.OpenTasks))
+			for _, t := range result.OpenTasks {
+				due := ""
+				if t.DueAt != nil {
+					due = " (due " + library.HomeDate(*t.DueAt) + ")"
+				}
+				fmt.Printf("  - %s%s\n", t.Description, due)
+			}
+			// Notes mentioning the document are listed apart from its other links
+			var links, referencedBy []docLinkView
+			for _, l := range result.Links {
+				if l.Relation == library.LinkReferences.Inverse() {
+					referencedBy = append(referencedBy, l)
+				} else {
+					links = append(links, l)
+				}
+			}
+			if len(links) > 0 {
+				fmt.Printf("Links:\n")
+				for _, l := range links {
+					fmt.Printf("  - %s %s\n", l.Relation, l.Title)
+				}
+			}
+			if len(referencedBy) > 0 {
+				fmt.Printf("Referenced by:\n")
+				for _, l := range referencedBy {
+					fmt.Printf("  - %s (%s)\n", l.Title, l.DocumentID)
+				}
+			}
+			if doc.Abstract != "" {
+				fmt.Printf("\n%s\n", doc.Abstract)
+			}
+			if doc.Type == library.DocTypeNote && doc.Notes != "" {
+				fmt.Printf("\n%s\n", doc.Notes)
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// openDocumentTasks returns the incomplete tasks linked to a document, or
+// none when the backend does not support tasks.
+func openDocumentTasks(store library.LibraryStore, documentID string) []*library.Task {
+	if !store.Capabilities().Tasks {
+		return []*library.Task{}
+	}
+	tasks, err := store.ListTasks(&library.TaskListOptions{DocumentID: documentID, Open: true})
+	if err != nil || tasks == nil {
+		return []*library.Task{}
+	}
+	return tasks
+}
+
+func newDocLinkCmd(store library.LibraryStore) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "link",
+		Short: "Connect related documents",
+		Long: `Record typed relations between documents, such as a published paper that
+supersedes its preprint or the parts of a multi-part series.
+
+Relations (read "<from> <relation> <to>"): supersedes, duplicate-of,
+part-of, responds-to, translation-of, cites, references. "doc references
+--link" adds cites links from documents' bibliographies, and "doc link
+notes" references links from the documents note documents mention.`,
+	}
+
+	cmd.AddCommand(newDocLinkAddCmd(store))
+	cmd.AddCommand(newDocLinkListCmd(store))
+	cmd.AddCommand(newDocLinkRemoveCmd(store))
+	cmd.AddCommand(newDocLinkNotesCmd(store))
+
+	return cmd
+}
+
+func newDocLinkAddCmd(store library.LibraryStore) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add <from-document> <relation> <to-document>",
+		Short: "Link two documents",
+		Example: `  arc-library doc link add <published-id> supersedes <preprint-id>
+  arc-library doc link add <chapter-id> part-of <book-id>`,
+		Args: cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			relation, err := library.ParseLinkRelation(args[1])
+			if err != nil {
+				return err
+			}
+			from, err := lookupDocument(store, args[0])
+			if err != nil {
+				return err
+			}
+			to, err := lookupDocument(store, args[2])
+			if err != nil {
+				return err
+			}
+			if from.ID == to.ID {
+				return fmt.Errorf("cannot link a document to itself")
+			}
+
+			link := &library.DocumentLink{FromID: from.ID, ToID: to.ID, Relation: relation}
+			if err := store.AddDocumentLink(link); err != nil {
+				return fmt.Errorf("add link: %w", err)
+			}
+
+			if jsonOutput(nil) {
+				return output.JSON(link)
+			}
+			if quietOutput() {
+				printIDs(link.ID)
+				return nil
+			}
+			fmt.Printf("Linked: %s %s %s\n", truncate(from.Title, 40), relation, truncate(to.Title, 40))
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// docLinkView is a link as seen from one document: Relation is inverted for
+// incoming links, and DocumentID/Title name the document at the other end.
+type docLinkView struct {
+	ID         string `json:"id"`
+	Relation   string `json:"relation"`
+	DocumentID string `json:"document_id"`
+	Title      string `json:"title"`
+}
+
+// documentLinkViews returns a document's links from its side.
+func documentLinkViews(store library.LibraryStore, documentID string) ([]docLinkView, error) {
+	links, err := store.ListDocumentLinks(documentID)
+	if err != nil {
+		return nil, err
+	}
+	views := make([]docLinkView, 0, len(links))
+	for _, l := range links {
+		v := docLinkView{ID: l.ID, Relation: string(l.Relation), DocumentID: l.ToID}
+		if l.ToID == documentID {
+			v.Relation = l.Relation.Inverse()
+			v.DocumentID = l.FromID
+		}
+		if other, _ := store.GetDocument(v.DocumentID); other != nil {
+			v.Title = other.Title
+		}
+		views = append(views, v)
+	}
+	return views, nil
+}
+
+func newDocLinkListCmd(store library.LibraryStore) *cobra.Command {
+	var out output.OutputOptions
+
+	cmd := &cobra.Command{
+		Use:               "list <document-id>",
+		Short:             "List a document's links",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeFirst(completeDocuments(store)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := out.Resolve(); err != nil {
+				return err
+			}
+			doc, err := lookupDocument(store, args[0])
+			if err != nil {
+				return err
+			}
+			views, err := documentLinkViews(store, doc.ID)
+			if err != nil {
+				return fmt.Errorf("list links: %w", err)
+			}
+
+			if jsonOutput(&out) {
+				return output.JSON(views)
+			}
+			if quietOutput() {
+				for _, v := range views {
+					printIDs(v.ID)
+				}
+				return nil
+			}
+
+			if len(views) == 0 {
+				fmt.Printf("No links for %s.\n", doc.Title)
+				return nil
+			}
+
+			fmt.Printf("%s\n\n", doc.Title)
+			table := output.NewTable("Link ID", "Relation", "Document", "Title")
+			for _, v := range views {
+				table.AddRow(v.ID, v.Relation, v.DocumentID, truncate(v.Title, 50))
+			}
+			table.Render()
+			return nil
+		},
+	}
+
+	out.AddOutputFlags(cmd, output.OutputTable)
+
+	return cmd
+}
+
+func newDocLinkRemoveCmd(store library.LibraryStore) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "remove <link-id>",
+		Aliases: []string{"rm"},
+		Short:   "Remove a link",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := store.DeleteDocumentLink(args[0]); err != nil {
+				return fmt.Errorf("remove link: %w", err)
+			}
+			if jsonOutput(nil) {
+				return output.JSON(deleteResult{Kind: "link", ID: args[0], Deleted: true})
+			}
+			infof("Link removed: %s\n", args[0])
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// docLinkNotesResult is the JSON schema for "doc link notes".
+type docLinkNotesResult struct {
+	Added   []*library.DocumentLink `json:"added"`
+	Removed []*library.DocumentLink `json:"removed"`
+}
+
+func newDocLinkNotesCmd(store library.LibraryStore) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "notes [note-id]",
+		Short: "Link notes to the documents they mention",
+		Long: `Record a references link from each note document to every document its
+text mentions, shown as "referenced by" on the other end, and remove those
+it no longer mentions. Without a note ID every note is checked, which
+picks up documents added since a note mentioned them.
+
+A note mentions a document by:
+  [[id]], [[citekey]], [[title]]   A wiki link; [[target|label]] also works
+  @citekey                         A citation, with the key BibTeX export uses
+  id                               The document ID on its own
+
+"arc-library note" links new notes as they are captured.`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeFirst(completeDocuments(store)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var notes []*library.Document
+			if len(args) == 1 {
+				doc, err := lookupDocument(store, args[0])
+				if err != nil {
+					return err
+				}
+				if doc.Type != library.DocTypeNote {
+					return fmt.Errorf("%s is a %s, not a note", doc.ID, doc.Type)
+				}
+				notes = append(notes, doc)
+			} else {
+				var err error
+				if notes, err = store.ListDocuments(&library.ListOptions{Type: string(library.DocTypeNote)}); err != nil {
+					return err
+				}
+			}
+
+			added, removed, err := library.SyncNoteLinks(store, notes)
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput(nil) {
+				return output.JSON(docLinkNotesResult{Added: nonNil(added), Removed: nonNil(removed)})
+			}
+			if quietOutput() {
+				for _, l := range added {
+					printIDs(l.ID)
+				}
+				return nil
+			}
+			fmt.Printf("Checked %d note(s): %d link(s) added, %d removed.\n", len(notes), len(added), len(removed))
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func newDocHistoryCmd(store library.LibraryStore) *cobra.Command {
+	var out output.OutputOptions
+
+	cmd := &cobra.Command{
+		Use:   "history <document-id>",
+		Short: "Show a document's metadata revisions",
+		Long: `Show every recorded change to a document's metadata, oldest first. Each
+update that changes a field becomes a numbered revision; revert with
+"doc revert <id> --to <rev>". Extracted full text is not versioned.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeFirst(completeDocuments(store)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := out.Resolve(); err != nil {
+				return err
+			}
+			doc, err := lookupDocument(store, args[0])
+			if err != nil {
+				return err
+			}
+			revs, err := store.ListDocumentRevisions(doc.ID)
+			if err != nil {
+				return fmt.Errorf("list revisions: %w", err)
+			}
+
+			if jsonOutput(&out) {
+				return output.JSON(nonNil(revs))
+			}
+			if quietOutput() {
+				for _, r := range revs {
+					printIDs(fmt.Sprintf("%d", r.Rev))
+				}
+				return nil
+			}
+
+			if len(revs) == 0 {
+				fmt.Printf("No recorded changes for %s.\n", doc.Title)
+				return nil
+			}
+
+			fmt.Printf("%s\n\n", doc.Title)
+			table := output.NewTable("Rev", "Date", "Field", "Old", "New")
+			for _, r := range revs {
+				for i, c := range r.Changes {
+					rev, date := "", ""
+					if i == 0 {
+						rev, date = fmt.Sprintf("%d", r.Rev), r.CreatedAt.Local().Format("2006-01-02 15:04")
+					}
+					table.AddRow(rev, date, c.Field, truncate(revisionValue(c.Old), 30), truncate(revisionValue(c.New), 30))
+				}
+			}
+			table.Render()
+			return nil
+		},
+	}
+
+	out.AddOutputFlags(cmd, output.OutputTable)
+
+	return cmd
+}
+
+// revisionValue renders a JSON field value for display: strings unquoted,
+// unset values as "-".
+func revisionValue(raw json.RawMessage) string {
+	var str string
+	if json.Unmarshal(raw, &str) == nil {
+		if str == "" {
+			return "-"
+		}
+		return str
+	}
+	switch v := string(raw); v {
+	case "", "null", "[]", "{}":
+		return "-"
+	default:
+		return v
+	}
+}
+
+func newDocRevertCmd(store library.LibraryStore) *cobra.Command {
+	var to int
+
+	cmd := &cobra.Command{
+		Use:   "revert <document-id> --to <rev>",
+		Short: "Restore a document's metadata to an earlier revision",
+		Long: `Restore a document's metadata as it was at a revision from "doc history";
+--to 0 restores it as it was before its first recorded change. The revert
+is recorded as a new revision, so it can be reverted in turn.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeFirst(completeDocuments(store)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !cmd.Flags().Changed("to") {
+				return fmt.Errorf("--to is required")
+			}
+			doc, err := lookupDocument(store, args[0])
+			if err != nil {
+				return err
+			}
+			revs, err := store.ListDocumentRevisions(doc.ID)
+			if err != nil {
+				return fmt.Errorf("list revisions: %w", err)
+			}
+			reverted, err := library.RevertDocument(doc, revs, to)
+			if err != nil {
+				return err
+			}
+			if err := store.UpdateDocument(reverted); err != nil {
+				return fmt.Errorf("update document: %w", err)
+			}
+			if reverted.Type == library.DocTypeNote {
+				// The note's text may mention other documents than before
+				if _, _, err := library.SyncNoteLinks(store, []*library.Document{reverted}); err != nil {
+					warnf("Warning: %v\n", err)
+				}
+			}
+
+			if jsonOutput(nil) {
+				return output.JSON(reverted)
+			}
+			infof("Reverted %s to revision %d\n", reverted.Title, to)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&to, "to", 0, "Revision to restore (0 = before the first change)")
+
+	return cmd
+}
+
+func newDocReviewCmd(store library.LibraryStore) *cobra.Command {
+	var (
+		quality int
+		stop    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "review <document-id>",
+		Short: "Schedule a document's next re-read",
+		Long: `Record that you re-read a document and schedule the next re-read, the
+way flashcards are scheduled (SM-2): rate how well it stuck from 0
+(forgotten) to 5 (knew it all), and the better it went the longer the
+wait. The first review starts the schedule; "arc-library due" lists the
+documents due again. --stop drops the document from the schedule.
+
+Examples:
+  arc-library doc review 1706.03762 --quality 4
+  arc-library doc review 1706.03762 --stop`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeFirst(completeDocuments(store)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			doc, err := lookupDocument(store, args[0])
+			if err != nil {
+				return err
+			}
+
+			if stop {
+				if err := store.DeleteDocumentReview(doc.ID); err != nil {
+					return fmt.Errorf("stop reviews: %w", err)
+				}
+				if jsonOutput(nil) {
+					return output.JSON(deleteResult{Kind: "review", ID: doc.ID, Deleted: true})
+				}
+				infof("Stopped reviewing %s\n", truncate(doc.Title, 60))
+				return nil
+			}
+
+			review, err := library.ReviewDocument(store, doc.ID, quality, library.Now())
+			if err != nil {
+				return fmt.Errorf("review document: %w", err)
+			}
+
+			if jsonOutput(nil) {
+				return output.JSON(review)
+			}
+			if quietOutput() {
+				return nil
+			}
+			fmt.Printf("Reviewed: %s\n", truncate(doc.Title, 60))
+			fmt.Printf("Quality: %d/5\n", quality)
+			fmt.Printf("Next re-read: %s (in %d days)\n", library.HomeDate(review.DueAt), review.Interval)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVarP(&quality, "quality", "q", 4, "How well it stuck, 0-5 (0=forgotten, 5=perfect)")
+	cmd.Flags().BoolVar(&stop, "stop", false, "Stop scheduling re-reads of the document")
+
+	return cmd
+}
+
+func newDocDeleteCmd(store library.LibraryStore) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "delete <document-id>",
+		Aliases:           []string{"rm"},
+		Short:             "Delete a document",
+		Long:              `Delete a document with its annotations, flashcards, collection memberships, and links. The file on disk is left alone, and "arc-library undo" restores the library records.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeFirst(completeDocuments(store)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			doc, err := lookupDocument(store, args[0])
+			if err != nil {
+				return err
+			}
+			if _, err := library.DeleteDocumentUndoable(store, doc.ID); err != nil {
+				return fmt.Errorf("delete document: %w", err)
+			}
+
+			if jsonOutput(nil) {
+				return output.JSON(deleteResult{Kind: "document", ID: doc.ID, Deleted: true})
+			}
+			infof("Deleted document: %s (arc-library undo to restore)\n", doc.Title)
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// thumbnailResult is the JSON schema for "doc thumbnail".
+type thumbnailResult struct {
+	DocumentID string `json:"document_id"`
+	Path       string `json:"path"`
+}
+
+func newDocThumbnailCmd(store library.LibraryStore) *cobra.Command {
+	var refresh bool
+
+	cmd := &cobra.Command{
+		Use:   "thumbnail <document-id>",
+		Short: "Generate a document's cover thumbnail",
+		Long: `Generate a cover thumbnail and print its path: the first page of a PDF
+(requires pdftoppm from poppler) or, for books with an ISBN (source "isbn"
+or an isbn metadata field), the cover from Open Library.
+
+Thumbnails are cached under $ARC_LIBRARY_CACHE_DIR/thumbnails, by default in
+the user cache directory, and served by "arc-library serve" at
+/api/document/<id>/thumbnail. Use --refresh to regenerate one.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeFirst(completeDocuments(store)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			doc, err := lookupDocument(store, args[0])
+			if err != nil {
+				return err
+			}
+			dir, err := library.ThumbnailDir()
+			if err != nil {
+				return err
+			}
+			path, err := library.Thumbnail(doc, dir, refresh)
+			if errors.Is(err, library.ErrNoThumbnail) {
+				return fmt.Errorf("no thumbnail for %s: not a PDF, and no cover found by ISBN", truncate(doc.Title, 40))
+			}
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput(nil) {
+				return output.JSON(thumbnailResult{DocumentID: doc.ID, Path: path})
+			}
+			fmt.Println(path)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&refresh, "refresh", false, "Regenerate the thumbnail, retrying documents without one")
+
+	return cmd
+}
+
+// sectionInfo is the JSON schema for each section listed by "doc sections".
+type sectionInfo struct {
+	library.Section
+	Chunks int `json:"chunks"`
+}
+
+// sectionText is the JSON schema for "doc sections <id> <section>".
+type sectionText struct {
+	DocumentID string `json:"document_id"`
+	library.Section
+	Text string `json:"text"`
+}
+
+func newDocSectionsCmd(store library.LibraryStore) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sections <document-id> [section]",
+		Short: "List the sections of a document's full text, or print one",
+		Long: `List the sections detected in a document's full text: the abstract,
+introduction, methods, results, conclusion, references, and other numbered
+headings, with their sizes. Given a section kind or heading, print that
+section's text instead.
+
+Sections are detected from the extracted text (see "import --extract-text"
+and "ocr") and recomputed when it changes.
+
+Examples:
+  arc-library doc sections 2304.00067
+  arc-library doc sections 2304.00067 methods`,
+		Args:              cobra.RangeArgs(1, 2),
+		ValidArgsFunction: completeFirst(completeDocuments(store)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			doc, err := lookupDocument(store, args[0])
+			if err != nil {
+				return err
+			}
+			if doc.FullText == "" {
+				return fmt.Errorf("%s has no full text; extract it with import --extract-text or ocr", truncate(doc.Title, 40))
+			}
+			secs, err := library.LoadDocumentSections(store, doc)
+			if err != nil {
+				return err
+			}
+
+			if len(args) == 2 {
+				sec := secs.Find(args[1])
+				if sec == nil {
+					return fmt.Errorf("no %q section in %s", args[1], truncate(doc.Title, 40))
+				}
+				text := strings.TrimSpace(doc.FullText[sec.Start:sec.End])
+				if jsonOutput(nil) {
+					return output.JSON(sectionText{DocumentID: doc.ID, Section: *sec, Text: text})
+				}
+				fmt.Println(text)
+				return nil
+			}
+
+			infos := make([]sectionInfo, len(secs.Sections))
+			for i, sec := range secs.Sections {
+				infos[i].Section = sec
+			}
+			for _, c := range secs.Chunks {
+				infos[c.Section].Chunks++
+			}
+			if jsonOutput(nil) {
+				return output.JSON(infos)
+			}
+
+			table := output.NewTable("#", "Kind", "Heading", "Size", "Chunks")
+			for i, info := range infos {
+				table.AddRow(fmt.Sprint(i+1), info.Kind, truncate(info.Heading, 40),
+					fmt.Sprintf("%d chars", info.End-info.Start), fmt.Sprint(info.Chunks))
+			}
+			table.Render()
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// referenceView is the JSON schema for each reference listed by
+// "doc references": the parsed entry and the library document it matched.
+type referenceView struct {
+	library.Reference
+	DocumentID string `json:"document_id,omitempty"`
+	MatchedBy  string `json:"matched_by,omitempty"` // doi, arxiv, or title
+}
+
+func newDocReferencesCmd(store library.LibraryStore) *cobra.Command {
+	var (
+		link bool
+		all  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "references [document-id]",
+		Short: "List a document's references and link the ones in the library",
+		Long: `Parse the references section of a document's full text into entries with
+authors, title, year, DOI, and arXiv ID, and match them against the library
+by DOI, arXiv ID, or title. References are parsed when first needed and
+again after the text changes.
+
+References GROBID extracted on import (see "import --grobid") are used
+as they are, and don't need the full text.
+
+With --link, each matched reference is recorded as a "cites" link from the
+document (shown as "cited-by" on the other end); existing links are kept.
+--all does this for every document with full text or GROBID references.
+
+Examples:
+  arc-library doc references 2304.00067
+  arc-library doc references 2304.00067 --link
+  arc-library doc references --all`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeFirst(completeDocuments(store)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if all == (len(args) == 1) {
+				return fmt.Errorf("give a document ID or --all")
+			}
+			docs, err := store.ListDocuments(nil)
+			if err != nil {
+				return fmt.Errorf("list documents: %w", err)
+			}
+			matcher := library.NewReferenceMatcher(docs)
+
+			if all {
+				links := []*library.DocumentLink{}
+				for _, doc := range docs {
+					if !hasReferences(store, doc) {
+						continue
+					}
+					views, err := documentReferenceViews(store, matcher, doc)
+					if err != nil {
+						return fmt.Errorf("%s: %w", doc.ID, err)
+					}
+					added, err := linkCitations(store, doc, views)
+					if err != nil {
+						return err
+					}
+					links = append(links, added...)
+				}
+				return printCitationLinks(store, links)
+			}
+
+			doc, err := lookupDocument(store, args[0])
+			if err != nil {
+				return err
+			}
+			if !hasReferences(store, doc) {
+				return fmt.Errorf("%s has no full text; extract it with import --extract-text or ocr", truncate(doc.Title, 40))
+			}
+			views, err := documentReferenceViews(store, matcher, doc)
+			if err != nil {
+				return err
+			}
+			if link {
+				links, err := linkCitations(store, doc, views)
+				if err != nil {
+					return err
+				}
+				infof("Linked %d citation(s).\n", len(links))
+			}
+
+			if jsonOutput(nil) {
+				return output.JSON(views)
+			}
+			if quietOutput() {
+				for _, v := range views {
+					if v.DocumentID != "" {
+						printIDs(v.DocumentID)
+					}
+				}
+				return nil
+			}
+			if len(views) == 0 {
+				fmt.Printf("No references found in %s.\n", truncate(doc.Title, 40))
+				return nil
+			}
+
+			table := output.NewTable("#", "Year", "Title", "In library")
+			matched := 0
+			for _, v := range views {
+				year, title := "", v.Title
+				if v.Year > 0 {
+					year = fmt.Sprint(v.Year)
+				}
+				if title == "" {
+					title = v.Raw
+				}
+				in := ""
+				if v.DocumentID != "" {
+					in = v.DocumentID + " (" + v.MatchedBy + ")"
+					matched++
+				}
+				table.AddRow(fmt.Sprint(v.Index), year, truncate(title, 50), in)
+			}
+			table.Render()
+			fmt.Printf("\n%d reference(s), %d in the library\n", len(views), matched)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&link, "link", false, "Add cites links to the referenced documents in the library")
+	cmd.Flags().BoolVar(&all, "all", false, "Link the references of every document with full text or GROBID references")
+
+	return cmd
+}
+
+// hasReferences reports whether doc has references to list: full text to
+// parse them from, or references GROBID parsed from the PDF.
+func hasReferences(store library.LibraryStore, doc *library.Document) bool {
+	if doc.FullText != "" {
+		return true
+	}
+	refs, _ := store.GetDocumentReferences(doc.ID)
+	return refs != nil && refs.Source == library.ReferencesSourceGrobid
+}
+
+// documentReferenceViews parses doc's references and matches them against
+// the library. A document citing itself is not a match.
+func documentReferenceViews(store library.LibraryStore, matcher *library.ReferenceMatcher, doc *library.Document) ([]referenceView, error) {
+	refs, err := library.LoadDocumentReferences(store, doc)
+	if err != nil {
+		return nil, err
+	}
+	views := make([]referenceView, len(refs.References))
+	for i, ref := range refs.References {
+		views[i].Reference = ref
+		if id, by := matcher.Match(ref); id != doc.ID {
+			views[i].DocumentID, views[i].MatchedBy = id, by
+		}
+	}
+	return views, nil
+}
+
+// linkCitations adds a cites link from doc to each matched reference that
+// isn't linked yet, and returns the new links.
+func linkCitations(store library.LibraryStore, doc *library.Document, views []referenceView) ([]*library.DocumentLink, error) {
+	existing, err := store.ListDocumentLinks(doc.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list links: %w", err)
+	}
+	cited := map[string]bool{}
+	for _, l := range existing {
+		if l.FromID == doc.ID && l.Relation == library.LinkCites {
+			cited[l.ToID] = true
+		}
+	}
+	var added []*library.DocumentLink
+	for _, v := range views {
+		if v.DocumentID == "" || cited[v.DocumentID] {
+			continue
+		}
+		l := &library.DocumentLink{FromID: doc.ID, ToID: v.DocumentID, Relation: library.LinkCites}
+		if err := store.AddDocumentLink(l); err != nil {
+			return nil, fmt.Errorf("add link: %w", err)
+		}
+		cited[v.DocumentID] = true
+		added = append(added, l)
+	}
+	return added, nil
+}
+
+// printCitationLinks reports the links made by "doc references --all".
+func printCitationLinks(store library.LibraryStore, links []*library.DocumentLink) error {
+	if jsonOutput(nil) {
+		return output.JSON(links)
+	}
+	if quietOutput() {
+		for _, l := range links {
+			printIDs(l.ID)
+		}
+		return nil
+	}
+	for _, l := range links {
+		from, to := l.FromID, l.ToID
+		if d, _ := store.GetDocument(l.FromID); d != nil {
+			from = d.Title
+		}
+		if d, _ := store.GetDocument(l.ToID); d != nil {
+			to = d.Title
+		}
+		fmt.Printf("Linked: %s cites %s\n", truncate(from, 40), truncate(to, 40))
+	}
+	fmt.Printf("%d citation link(s) added\n", len(links))
+	return nil
+}
@@ -0,0 +1,267 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mtreilly/arc-library/internal/library"
+	"github.com/spf13/cobra"
+	"github.com/yourorg/arc-sdk/config"
+	"github.com/yourorg/arc-sdk/output"
+)
+
+// doctorResult is the JSON schema for "doctor".
+type doctorResult struct {
+	Checks   []library.DoctorCheck `json:"checks"`
+	Warnings int                   `json:"warnings"`
+	Failures int                   `json:"failures"`
+}
+
+func newDoctorCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command {
+	var timeout time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the programs, network, storage, and settings arc-library needs",
+		Long: `Check the environment arc-library runs in and say how to fix what is
+wrong:
+
+- Programs: pdftotext and pdftoppm (poppler), tesseract and ocrmypdf for
+  OCR, and arc-ai for the AI commands, on the PATH
+- Network: whether Crossref and arXiv answer (skipped with --offline)
+- Storage: whether the database can be written, or created, and the file
+  and cache directories
+- Configuration: the ARC_LIBRARY_* environment variables and the hooks,
+  import mappings, and color meanings files
+
+A missing program or unreachable API only disables the features that
+need it and is a warning; a database that cannot be written or a
+setting that stops every command is a failure, and makes doctor exit
+with an error.
+
+Examples:
+  arc-library doctor
+  arc-library doctor --offline          # Skip the network checks
+  arc-library doctor --json | jq '.checks[] | select(.status != "ok")'`,
+		Args: cobra.NoArgs,
+		// Bad settings would stop the root's setup before doctor could
+		// report them
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error { return nil },
+		RunE: func(cmd *cobra.Command, args []string) error {
+			offline, _ := cmd.Flags().GetBool("offline")
+
+			var checks []library.DoctorCheck
+			add := func(group string, c library.DoctorCheck) {
+				c.Group = group
+				checks = append(checks, c)
+			}
+
+			for _, t := range library.DoctorTools {
+				add("programs", library.CheckTool(t))
+			}
+
+			for _, e := range library.DoctorEndpoints() {
+				if offline {
+					add("network", library.DoctorCheck{Name: e.Name, Status: library.DoctorSkip, Detail: "--offline"})
+					continue
+				}
+				add("network", library.CheckEndpoint(e, timeout))
+			}
+			if u := os.Getenv("ARC_LIBRARY_GROBID_URL"); u != "" && !offline {
+				c := library.CheckEndpoint(library.DoctorEndpoint{Name: "GROBID", URL: strings.TrimRight(u, "/") + "/api/isalive"}, timeout)
+				if c.Status != library.DoctorOK {
+					c.Fix = "start the GROBID server, or unset ARC_LIBRARY_GROBID_URL"
+				}
+				add("network", c)
+			}
+
+			for _, c := range doctorStorage() {
+				add("storage", c)
+			}
+			for _, c := range doctorCapabilities(store.Capabilities()) {
+				add("storage", c)
+			}
+			for _, c := range doctorConfig() {
+				add("config", c)
+			}
+
+			res := doctorResult{Checks: checks}
+			for _, c := range checks {
+				switch c.Status {
+				case library.DoctorWarn:
+					res.Warnings++
+				case library.DoctorFail:
+					res.Failures++
+				}
+			}
+
+			if jsonOutput(nil) {
+				if err := output.JSON(res); err != nil {
+					return err
+				}
+			} else if !quietOutput() {
+				printDoctorChecks(checks)
+			}
+			if res.Failures > 0 {
+				return fmt.Errorf("%d problem(s) stop arc-library from working", res.Failures)
+			}
+			if !jsonOutput(nil) && !quietOutput() {
+				if res.Warnings > 0 {
+					fmt.Printf("\n%d warning(s): the features above will not work until fixed.\n", res.Warnings)
+				} else {
+					fmt.Println("\nEverything looks good.")
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().DurationVar(&timeout, "timeout", 10*time.Second, "How long to wait for each API")
+
+	return cmd
+}
+
+// doctorStorage checks where the library keeps its data.
+func doctorStorage() []library.DoctorCheck {
+	var checks []library.DoctorCheck
+	switch storage := os.Getenv("ARC_LIBRARY_STORAGE"); storage {
+	case "", "sql":
+		checks = append(checks, library.CheckDatabaseFile(library.DatabasePath()))
+	case "kv":
+		checks = append(checks, library.DoctorCheck{Name: "database", Status: library.DoctorOK, Detail: "KV store in arc-sdk's SQLite database"})
+	case "memory":
+		checks = append(checks, library.DoctorCheck{Name: "database", Status: library.DoctorWarn,
+			Detail: "ARC_LIBRARY_STORAGE=memory keeps nothing between runs",
+			Fix:    "unset ARC_LIBRARY_STORAGE to use the SQL database"})
+	default:
+		checks = append(checks, library.DoctorCheck{Name: "database", Status: library.DoctorFail,
+			Detail: fmt.Sprintf("unknown ARC_LIBRARY_STORAGE %q", storage),
+			Fix:    "set ARC_LIBRARY_STORAGE to sql, kv, or memory, or unset it"})
+	}
+
+	if root := os.Getenv("ARC_LIBRARY_ROOT"); root != "" {
+		c := library.DoctorCheck{Name: "library root"}
+		if info, err := os.Stat(library.LibraryRoot()); err != nil || !info.IsDir() {
+			c.Status, c.Detail = library.DoctorFail, library.LibraryRoot()+" is not a directory"
+			c.Fix = "create it, mount the drive it is on, or correct ARC_LIBRARY_ROOT; relative paths resolve against it"
+		} else {
+			c.Status, c.Detail = library.DoctorOK, library.LibraryRoot()
+		}
+		checks = append(checks, c)
+	}
+	if dir, err := library.FilesDir(); err == nil {
+		checks = append(checks, library.CheckWritableDir("files", dir))
+	}
+	if dir, err := library.CacheDir(); err == nil {
+		checks = append(checks, library.CheckWritableDir("cache", dir))
+	}
+	if external, _ := library.ExternalFullText(); external {
+		if dir, err := library.FullTextDir(); err == nil {
+			checks = append(checks, library.CheckWritableDir("full text", dir))
+		}
+	}
+	return checks
+}
+
+// doctorConfig checks the settings every command or some commands read,
+// reporting the error each would stop with.
+func doctorConfig() []library.DoctorCheck {
+	check := func(name string, err error, ok, fix string) library.DoctorCheck {
+		if err != nil {
+			return library.DoctorCheck{Name: name, Status: library.DoctorFail, Detail: err.Error(), Fix: fix}
+		}
+		return library.DoctorCheck{Name: name, Status: library.DoctorOK, Detail: ok}
+	}
+	fileDetail := func(path string, err error) string {
+		if err != nil {
+			return "no config directory"
+		}
+		if _, err := os.Stat(path); err != nil {
+			return path + " (not present)"
+		}
+		return path
+	}
+
+	_, _, err := resolverSettings()
+	checks := []library.DoctorCheck{check("metadata resolvers", err, "ARC_LIBRARY_API_RATE, _RETRIES, _METADATA_TTL",
+		"correct or unset the variable; every command stops until then")}
+
+	srs, err := schedulerSettings()
+	if err == nil {
+		err = srs.Validate()
+	}
+	checks = append(checks, check("spaced repetition", err, "ARC_LIBRARY_SRS_*",
+		`correct or unset the variable; every command stops until then (see "flashcard settings")`))
+
+	loc, err := library.HomeTimezoneSetting()
+	ok := "ARC_LIBRARY_TIMEZONE unset, using the system's"
+	if err == nil && os.Getenv("ARC_LIBRARY_TIMEZONE") != "" {
+		ok = loc.String()
+	}
+	checks = append(checks, check("home timezone", err, ok,
+		"set ARC_LIBRARY_TIMEZONE to an IANA name such as Europe/Berlin, or unset it; every command stops until then"))
+
+	external, err := library.ExternalFullText()
+	ok = "stored in the database"
+	if external {
+		ok = "stored in files (ARC_LIBRARY_FULLTEXT=external)"
+	}
+	checks = append(checks, check("full text storage", err, ok,
+		`set ARC_LIBRARY_FULLTEXT to inline or external, or unset it; saving documents with full text fails until then`))
+
+	size, err := library.DocumentCacheSize()
+	ok = fmt.Sprintf("%d documents", size)
+	if size == 0 {
+		ok = "off"
+	}
+	checks = append(checks, check("document cache", err, ok,
+		fmt.Sprintf("set ARC_LIBRARY_CACHE_SIZE to a number of documents, 0 for none, or unset it; %d are cached until then", size)))
+
+	_, err = typeRules()
+	checks = append(checks, check("type rules", err, "ARC_LIBRARY_TYPE_RULES",
+		`use rules like "host:nature.com=paper,ext:.djvu=book"; imports stop until then`))
+
+	path, perr := library.HooksFile()
+	_, err = loadHooks()
+	checks = append(checks, check("hooks", err, fileDetail(path, perr), "fix the hooks file; imports and exports stop until then"))
+
+	path, perr = library.ImportMappingsFile()
+	if perr == nil {
+		_, err = library.LoadMetaMappings(path)
+	}
+	checks = append(checks, check("import mappings", err, fileDetail(path, perr), "fix the import mappings file; imports stop until then"))
+
+	path, perr = library.ColorMeaningsFile()
+	_, err = loadColorMeanings()
+	checks = append(checks, check("color meanings", err, fileDetail(path, perr), `fix the file, or set meanings with "annotate colors"`))
+
+	if f := globalOutput.logFormat; f != "text" && f != "json" {
+		checks = append(checks, check("log format", fmt.Errorf("unknown log format %q", f), "",
+			"set ARC_LIBRARY_LOG_FORMAT to text or json, or unset it"))
+	}
+	return checks
+}
+
+// printDoctorChecks lists the checks by group, with the fix under each
+// that needs one.
+func printDoctorChecks(checks []library.DoctorCheck) {
+	group := ""
+	for _, c := range checks {
+		if c.Group != group {
+			if group != "" {
+				fmt.Println()
+			}
+			group = c.Group
+			fmt.Println(strings.ToUpper(group[:1]) + group[1:])
+		}
+		fmt.Printf("  %-4s  %-18s %s\n", c.Status, c.Name, c.Detail)
+		if c.Fix != "" && c.Status != library.DoctorOK {
+			fmt.Printf("        %-18s fix: %s\n", "", c.Fix)
+		}
+	}
+}
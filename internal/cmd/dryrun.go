@@ -0,0 +1,64 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"log/slog"
+
+	"github.com/mtreilly/arc-library/internal/library"
+	"github.com/spf13/cobra"
+)
+
+// dryRunEnabled is set by the persistent --dry-run flag.
+var dryRunEnabled bool
+
+// dryRun reports whether --dry-run was given. The store already holds
+// writes back; commands check it to skip other effects, such as hooks and
+// downloads, and to word their output.
+func dryRun() bool {
+	return dryRunEnabled
+}
+
+// addDryRunFlag adds --dry-run, which makes s hold back every write for
+// the command and print each as it would have been made, on stderr so
+// --json output stays parseable.
+func addDryRunFlag(root *cobra.Command, s *library.DryRunStore) {
+	root.PersistentFlags().BoolVar(&dryRunEnabled, "dry-run", false,
+		"Show what would be written to the library (documents, metadata, tags, collections) without writing it")
+
+	next := root.PersistentPreRunE
+	root.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		s.Enabled = dryRunEnabled
+		if dryRunEnabled {
+			s.Report = func(c library.DryRunChange) {
+				if globalOutput.logFormat == "json" {
+					slog.Info("dry run", "action", c.Action, "entity", c.Entity, "id", c.ID, "summary", c.Summary)
+					return
+				}
+				if quietOutput() {
+					return
+				}
+				summary := c.Summary
+				if summary == "" {
+					summary = c.ID
+				}
+				warnf("[dry run] %s %s: %s\n", c.Action, c.Entity, summary)
+			}
+		}
+		if next != nil {
+			return next(cmd, args)
+		}
+		return nil
+	}
+	root.PersistentPostRun = func(cmd *cobra.Command, args []string) {
+		if !dryRunEnabled {
+			return
+		}
+		if globalOutput.logFormat == "json" {
+			slog.Info("dry run finished", "changes", len(s.HeldChanges()))
+			return
+		}
+		warnf("Dry run: %d change(s) not written\n", len(s.HeldChanges()))
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mtreilly/arc-library/internal/library"
+	"github.com/spf13/cobra"
+	"github.com/yourorg/arc-sdk/config"
+	"github.com/yourorg/arc-sdk/output"
+)
+
+// dueResult is the JSON schema for "due".
+type dueResult struct {
+	Documents  []library.DueDocument `json:"documents"`
+	Flashcards []*library.Flashcard  `json:"flashcards"`
+	Today      int                   `json:"today"`   // flashcards left for today under the study limits
+	Backlog    int                   `json:"backlog"` // due flashcards over the study limits
+}
+
+func newDueCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "due",
+		Short: "List documents due for re-reading and flashcards due for review",
+		Long: `List everything due today: documents scheduled for re-reading with
+"doc review", then flashcards due for review. With daily limits set by
+"flashcard study", due flashcards are split into those left for today and
+the backlog over the limits.
+
+Examples:
+  arc-library due
+  arc-library due --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			now := library.Now()
+			docs, err := library.DueDocuments(store, now)
+			if err != nil {
+				return fmt.Errorf("get due documents: %w", err)
+			}
+			cards, err := store.GetDueFlashcards(now)
+			if err != nil {
+				return fmt.Errorf("get due flashcards: %w", err)
+			}
+			plan, err := studyPlan(store, now)
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput(nil) {
+				return output.JSON(dueResult{Documents: nonNil(docs), Flashcards: nonNil(cards),
+					Today: len(plan.Cards()), Backlog: plan.Backlog})
+			}
+			if quietOutput() {
+				for _, d := range docs {
+					printIDs(d.Document.ID)
+				}
+				for _, c := range cards {
+					printIDs(c.ID)
+				}
+				return nil
+			}
+
+			if len(docs) == 0 && len(cards) == 0 {
+				fmt.Println("Nothing due today!")
+				return nil
+			}
+
+			dueDate := func(t time.Time) string {
+				s := library.HomeDate(t)
+				if library.Overdue(t, now) {
+					s += " (overdue)"
+				}
+				return s
+			}
+			if len(docs) > 0 {
+				fmt.Printf("%d document(s) to re-read:\n\n", len(docs))
+				table := output.NewTable("ID", "Title", "Reviews", "Interval", "Due")
+				for _, d := range docs {
+					table.AddRow(truncate(d.Document.ID, 8), truncate(d.Document.Title, 40),
+						fmt.Sprintf("%d", d.Review.Reviews), fmt.Sprintf("%d", d.Review.Interval), dueDate(d.Review.DueAt))
+				}
+				table.Render()
+				fmt.Printf("\nAfter re-reading: arc-library doc review <id> --quality <0-5>\n")
+			}
+			if len(cards) > 0 {
+				if len(docs) > 0 {
+					fmt.Println()
+				}
+				if plan.Backlog > 0 {
+					fmt.Printf("%d flashcard(s) to review: %d today, %d in the backlog\n\n", len(cards), len(plan.Cards()), plan.Backlog)
+				} else {
+					fmt.Printf("%d flashcard(s) to review:\n\n", len(cards))
+				}
+				table := output.NewTable("ID", "Document", "Front", "Interval", "Due")
+				for _, c := range cards {
+					docTitle := ""
+					if doc, _ := store.GetDocument(c.DocumentID); doc != nil {
+						docTitle = truncate(doc.Title, 20)
+					}
+					table.AddRow(truncate(c.ID, 8), docTitle, truncate(c.Front, 30), fmt.Sprintf("%d", c.Interval), dueDate(c.DueAt))
+				}
+				table.Render()
+				fmt.Printf("\nStudy with: arc-library flashcard study\n")
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// studyPlan plans today's flashcard study under the saved daily limits.
+func studyPlan(store library.LibraryStore, now time.Time) (*library.StudyPlan, error) {
+	path, err := library.StudyLimitsFile()
+	if err != nil {
+		return nil, err
+	}
+	limits, err := library.LoadStudyLimits(path)
+	if err != nil {
+		return nil, fmt.Errorf("load study limits: %w", err)
+	}
+	plan, err := library.PlanStudy(store, limits, now)
+	if err != nil {
+		return nil, fmt.Errorf("plan study: %w", err)
+	}
+	return plan, nil
+}
@@ -9,17 +9,24 @@ import (
 	"github.com/mtreilly/arc-library/internal/library"
 	"github.com/spf13/cobra"
 	"github.com/yourorg/arc-sdk/config"
+	"github.com/yourorg/arc-sdk/output"
 )
 
 func newDuplicatesCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command {
 	var (
-		threshold float64 // similarity threshold (0-1)
+		threshold     float64 // similarity threshold (0-1)
+		textThreshold float64 // full-text similarity threshold (0-1)
+		noText        bool
 	)
 
 	cmd := &cobra.Command{
 		Use:   "duplicates",
 		Short: "Detect duplicate or similar documents",
-		Long:  "Scan your library for potential duplicates by comparing titles and metadata.",
+		Long: `Scan your library for potential duplicates by comparing source IDs, titles,
+and full text. Full texts are compared with MinHash signatures over 5-word
+shingles, so a preprint and its camera-ready version, or a file downloaded
+twice under different names, are found even when their titles differ.
+Signatures are cached and only recomputed when a document's text changes.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			docs, err := store.ListDocuments(&library.ListOptions{})
 			if err != nil {
@@ -27,11 +34,15 @@ func newDuplicatesCmd(cfg *config.Config, store library.LibraryStore) *cobra.Com
 			}
 
 			if len(docs) < 2 {
-				fmt.Println("Not enough documents to compare.")
+				if jsonOutput(nil) {
+					return output.JSON([]duplicatePair{})
+				}
+				infoln("Not enough documents to compare.")
 				return nil
 			}
 
-			var duplicates []duplicatePair
+			duplicates := []duplicatePair{}
+			pairIndex := make(map[[2]string]int) // document IDs -> index in duplicates
 
 			// Compare each pair (O(n^2) but fine for moderate sized libraries)
 			for i := 0; i < len(docs); i++ {
@@ -46,6 +57,7 @@ func newDuplicatesCmd(cfg *config.Config, store library.LibraryStore) *cobra.Com
 					// Check DOI/source_id first (exact match is strong signal)
 					if (d1.Source == d2.Source && d1.SourceID != "" && d1.SourceID == d2.SourceID) ||
 						(metaDoi(d1) == metaDoi(d2) && metaDoi(d1) != "") {
+						pairIndex[[2]string{d1.ID, d2.ID}] = len(duplicates)
 						duplicates = append(duplicates, duplicatePair{
 							Doc1:     d1,
 							Doc2:     d2,
@@ -59,6 +71,7 @@ func newDuplicatesCmd(cfg *config.Config, store library.LibraryStore) *cobra.Com
 					sim := titleSimilarity(d1.Title, d2.Title)
 					if sim >= threshold {
 						reason := fmt.Sprintf("title similarity %.2f", sim)
+						pairIndex[[2]string{d1.ID, d2.ID}] = len(duplicates)
 						duplicates = append(duplicates, duplicatePair{
 							Doc1: d1,
 							Doc2: d2,
@@ -69,11 +82,57 @@ func newDuplicatesCmd(cfg *config.Config, store library.LibraryStore) *cobra.Com
 				}
 			}
 
+			// Full-text similarity adds pairs the titles missed and backs up the rest
+			if !noText {
+				sigs, computed, err := library.TextSignatures(store, docs)
+				if err != nil {
+					warnf("Skipping full-text comparison: %v\n", err)
+				} else {
+					if computed > 0 {
+						infof("Indexed full text of %d document(s)\n", computed)
+					}
+					byID := make(map[string]*library.Document, len(docs))
+					order := make(map[string]int, len(docs))
+					for i, d := range docs {
+						byID[d.ID] = d
+						order[d.ID] = i
+					}
+					for _, nd := range library.FindNearDuplicates(sigs, textThreshold) {
+						a, b := nd.A, nd.B
+						if order[a] > order[b] {
+							a, b = b, a
+						}
+						reason := fmt.Sprintf("text similarity %.2f", nd.Similarity)
+						if i, ok := pairIndex[[2]string{a, b}]; ok {
+							duplicates[i].Reason += ", " + reason
+							duplicates[i].Score = max(duplicates[i].Score, nd.Similarity)
+							continue
+						}
+						duplicates = append(duplicates, duplicatePair{
+							Doc1:   byID[a],
+							Doc2:   byID[b],
+							Score:  nd.Similarity,
+							Reason: reason,
+						})
+					}
+				}
+			}
+
 			// Sort by score descending
 			sort.Slice(duplicates, func(i, j int) bool {
 				return duplicates[i].Score > duplicates[j].Score
 			})
 
+			if jsonOutput(nil) {
+				return output.JSON(duplicates)
+			}
+			if quietOutput() {
+				for _, pair := range duplicates {
+					fmt.Printf("%s\t%s\n", pair.Doc1.ID, pair.Doc2.ID)
+				}
+				return nil
+			}
+
 			if len(duplicates) == 0 {
 				fmt.Printf("No duplicates found (threshold %.2f)\n", threshold)
 				return nil
@@ -92,6 +151,8 @@ func newDuplicatesCmd(cfg *config.Config, store library.LibraryStore) *cobra.Com
 	}
 
 	cmd.Flags().Float64VarP(&threshold, "threshold", "t", 0.7, "Similarity threshold (0-1, default 0.7)")
+	cmd.Flags().Float64Var(&textThreshold, "text-threshold", 0.5, "Full-text similarity threshold (0-1)")
+	cmd.Flags().BoolVar(&noText, "no-text", false, "Compare titles and IDs only, not full text")
 	return cmd
 }
 
@@ -116,11 +177,12 @@ func d2Summary(d *library.Document) string {
 	return d.Path
 }
 
+// duplicatePair is also the JSON schema for "duplicates".
 type duplicatePair struct {
-	Doc1   *library.Document
-	Doc2   *library.Document
-	Score  float64
-	Reason string
+	Doc1   *library.Document `json:"a"`
+	Doc2   *library.Document `json:"b"`
+	Score  float64           `json:"score"`
+	Reason string            `json:"reason"`
 }
 
 func titleSimilarity(a, b string) float64 {
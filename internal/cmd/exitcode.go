@@ -0,0 +1,88 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/mtreilly/arc-library/internal/library"
+)
+
+// Exit codes, for scripts to tell why a command failed.
+const (
+	ExitOK          = 0
+	ExitError       = 1 // any failure not listed below
+	ExitNotFound    = 3 // no such document, collection, or flashcard
+	ExitExists      = 4 // a collection of that name already exists
+	ExitUnsupported = 5 // the storage backend lacks the feature
+	ExitUnreadable  = 6 // a record could not be read, under --strict
+	ExitReadOnly    = 7 // a write to a library opened read-only, or locked by another process
+)
+
+// errorKinds maps the library's sentinel errors to an exit code, and to
+// the HTTP status and error code the web API answers with.
+var errorKinds = []struct {
+	err    error
+	code   string
+	exit   int
+	status int
+}{
+	{library.ErrDocumentNotFound, "document_not_found", ExitNotFound, http.StatusNotFound},
+	{library.ErrCollectionNotFound, "collection_not_found", ExitNotFound, http.StatusNotFound},
+	{library.ErrFlashcardNotFound, "flashcard_not_found", ExitNotFound, http.StatusNotFound},
+	{library.ErrCollectionExists, "collection_exists", ExitExists, http.StatusConflict},
+	{library.ErrBackendUnsupported, "backend_unsupported", ExitUnsupported, http.StatusNotImplemented},
+	{library.ErrReadOnly, "read_only", ExitReadOnly, http.StatusForbidden},
+}
+
+// errorKind returns the error code, exit code, and HTTP status for err,
+// or false when it wraps none of the library's sentinels.
+func errorKind(err error) (code string, exit, status int, ok bool) {
+	for _, k := range errorKinds {
+		if errors.Is(err, k.err) {
+			return k.code, k.exit, k.status, true
+		}
+	}
+	var re *library.ReadError
+	if errors.As(err, &re) {
+		return "unreadable", ExitUnreadable, http.StatusInternalServerError, true
+	}
+	return "", 0, 0, false
+}
+
+// ExitCode returns the exit code for a command that failed with err.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	if _, exit, _, ok := errorKind(err); ok {
+		return exit
+	}
+	return ExitError
+}
+
+// apiError answers an API request with err as JSON, {"error": message,
+// "code": code}. Errors wrapping the library's sentinels have their own
+// status and code, such as 404 and "document_not_found"; others are sent
+// with status, and a code named after it.
+func apiError(w http.ResponseWriter, err error, status int) {
+	code, _, s, ok := errorKind(err)
+	if ok {
+		status = s
+	} else {
+		code = strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_")
+	}
+	h := w.Header()
+	h.Del("Content-Length")
+	h.Set("Content-Type", "application/json")
+	h.Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(struct {
+		Error string `json:"error"`
+		Code  string `json:"code"`
+	}{err.Error(), code})
+}
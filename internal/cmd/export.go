@@ -5,37 +5,128 @@ package cmd
 
 import (
 	"bytes"
-	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
-	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/mtreilly/arc-library/internal/library"
 	"github.com/yourorg/arc-sdk/config"
+	"github.com/yourorg/arc-sdk/output"
 )
 
 func newExportCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command {
 	var (
-		format   string // "bibtex", "markdown", "json"
-		output   string // file path or "-" for stdout
+		format   string // an exporter name, see "formats list"
+		outFile  string // file path or "-" for stdout
 		tag      string
 		source   string
 		docType  string
 		collections []string
+		edgeKinds []string
+		groupBy  string
+		keyPoints int
+		splitBy  string
+		dir      string
+		merge    bool
+		filters  documentFilters
 	)
 
 	cmd := &cobra.Command{
 		Use:   "export",
 		Short: "Export library documents to various formats",
-		Long:  "Export your library to formats like BibTeX, Markdown, or JSON for use in other tools.",
+		Long: `Export your library to formats like BibTeX, Markdown, or JSON for use in other tools.
+
+The graph formats (graphml, dot, json-graph) export the documents with
+their authors, tags, collections, and links as a graph for Gephi, Graphviz,
+or other graph tools. --edges picks the edge kinds: author, tag,
+collection, a link relation (cites, supersedes, ...), or links for all
+relations; all of them by default.
+
+The quiz format turns the documents' flashcards into a printable Markdown
+quiz with an answer key, grouped by document or, with --group-by
+collection, by collection. --key-points N adds N questions per document on
+its most important points, chosen by arc-ai. The markdown format with
+--group-by meaning lists each document's annotations under what their
+colors mean (see "annotate colors"). With --split-by collection, tag, or
+type and --dir, it writes a directory per collection, tag, or type with a
+file per document and index.md files linking them, ready to publish or
+to open as a notes vault.
+
+With --merge, a bibtex export updates the --output file rather than
+replacing it: entries whose cite keys match an exported document are
+rewritten in place, documents not yet in the file are appended, and
+every other entry, comment, and @string is left as it is, so a
+manuscript's bibliography can be regenerated safely.
+
+Run "formats list" for every format and what it includes. The pre_export
+hooks in the hooks file run first and can stop the export.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			exporter := library.LookupExporter(format)
+			if exporter == nil {
+				return fmt.Errorf("unsupported format: %s (choose %s)", format, strings.Join(library.ExporterNames(), ", "))
+			}
+			hooks, err := loadHooks()
+			if err != nil {
+				return err
+			}
+			var exportOpts library.ExportOptions
+			if exporter.Format().Has(library.FormatGraph) {
+				var err error
+				if exportOpts.Edges, err = library.ParseGraphEdgeKinds(edgeKinds); err != nil {
+					return err
+				}
+			} else if len(edgeKinds) > 0 {
+				return fmt.Errorf("--edges applies to the graph formats (%s)", strings.Join(graphFormats(), ", "))
+			}
+			if format == "quiz" {
+				exportOpts.GroupBy = groupBy
+				if keyPoints > 0 {
+					exportOpts.KeyPoints = func(doc *library.Document) ([]library.GeneratedCard, error) {
+						return aiKeyPoints(store, doc, keyPoints)
+					}
+				}
+			} else if format == "markdown" && groupBy != "" {
+				if groupBy != "meaning" {
+					return fmt.Errorf("markdown groups annotations by meaning, not %q", groupBy)
+				}
+				exportOpts.GroupBy = groupBy
+				if exportOpts.ColorMeanings, err = loadColorMeanings(); err != nil {
+					return err
+				}
+			} else if groupBy != "" || keyPoints > 0 {
+				return fmt.Errorf("--group-by applies to the quiz and markdown formats, --key-points to quiz")
+			}
+
+			if splitBy != "" {
+				if format != "markdown" {
+					return fmt.Errorf("--split-by applies to the markdown format")
+				}
+				if dir == "" {
+					return fmt.Errorf("--split-by needs --dir for the output directory")
+				}
+			} else if dir != "" {
+				return fmt.Errorf("--dir applies with --split-by")
+			}
+			if merge {
+				if format != "bibtex" {
+					return fmt.Errorf("--merge applies to the bibtex format")
+				}
+				if outFile == "-" || outFile == "" {
+					return fmt.Errorf("--merge needs --output for the file to merge into")
+				}
+			}
+
 			// Get documents (apply filters)
-			docs, err := store.ListDocuments(&library.ListOptions{
+			opts := &library.ListOptions{
 				Tag:    tag,
 				Source: source,
 				Type:   docType,
-			})
+			}
+			if err := filters.apply(store, opts); err != nil {
+				return err
+			}
+			docs, err := store.ListDocuments(opts)
 			if err != nil {
 				return fmt.Errorf("list documents: %w", err)
 			}
@@ -59,294 +150,117 @@ func newExportCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command
 				docs = filtered
 			}
 
-			var outBytes []byte
+			file := outFile
+			if file == "-" {
+				file = ""
+			}
+			if splitBy != "" {
+				file = dir
+			}
+			if err := hooks.PreExport(format, file, len(docs), os.Stderr); err != nil {
+				return err
+			}
+
+			if splitBy != "" {
+				res, err := library.ExportMarkdownTree(store, docs, dir, splitBy, exportOpts)
+				if err != nil {
+					return fmt.Errorf("export %s: %w", format, err)
+				}
+				if jsonOutput(nil) {
+					return output.JSON(exportResult{Format: format, File: dir, Documents: len(docs), Groups: res.Groups, Files: res.Files})
+				}
+				infof("Exported %d document(s) in %d group(s) to %s\n", len(docs), res.Groups, dir)
+				return nil
+			}
 
-			switch format {
-			case "bibtex":
-				outBytes, err = exportBibTeX(docs)
-			case "markdown":
-				outBytes, err = exportMarkdown(docs, store)
-			case "json":
-				outBytes, err = exportJSON(docs)
-			case "ris":
-				outBytes, err = exportRIS(docs)
-			default:
-				return fmt.Errorf("unsupported format: %s (choose bibtex, markdown, json, ris)", format)
+			if merge {
+				existing, err := os.ReadFile(outFile)
+				if err != nil && !os.IsNotExist(err) {
+					return err
+				}
+				merged, res, err := library.MergeBibTeX(existing, docs)
+				if err != nil {
+					return fmt.Errorf("merge into %s: %w", outFile, err)
+				}
+				// Write then rename so an interrupted merge keeps the old file
+				tmp := outFile + ".tmp"
+				if err := os.WriteFile(tmp, merged, 0644); err != nil {
+					return fmt.Errorf("write %s: %w", outFile, err)
+				}
+				if err := os.Rename(tmp, outFile); err != nil {
+					return fmt.Errorf("write %s: %w", outFile, err)
+				}
+				if jsonOutput(nil) {
+					return output.JSON(exportResult{Format: format, File: outFile, Documents: len(docs), Merge: &res})
+				}
+				infof("Merged %d document(s) into %s: %d updated, %d added, %d other entries kept\n", len(docs), outFile, res.Updated, res.Added, res.Kept)
+				return nil
 			}
+
+			bar := newProgress("Exporting", len(docs))
+			exportOpts.OnProgress = func(done, total int) { bar.set(done, "") }
+			var buf bytes.Buffer
+			err = exporter.Export(&buf, store, docs, exportOpts)
+			bar.finish()
 			if err != nil {
 				return fmt.Errorf("export %s: %w", format, err)
 			}
+			outBytes := buf.Bytes()
 
-			if output == "-" || output == "" {
+			if outFile == "-" || outFile == "" {
 				fmt.Println(string(outBytes))
-			} else {
-				// Write to file (TODO)
-				return fmt.Errorf("file output not yet implemented")
+				return nil
 			}
 
+			if err := os.WriteFile(outFile, outBytes, 0644); err != nil {
+				return fmt.Errorf("write %s: %w", outFile, err)
+			}
+			if jsonOutput(nil) {
+				return output.JSON(exportResult{Format: format, File: outFile, Documents: len(docs)})
+			}
+			infof("Exported %d document(s) to %s\n", len(docs), outFile)
 			return nil
 		},
 	}
 
-	cmd.Flags().StringVarP(&format, "format", "f", "bibtex", "Export format: bibtex, markdown, json")
-	cmd.Flags().StringVarP(&output, "output", "o", "-", "Output file (default: stdout)")
+	cmd.Flags().StringVarP(&format, "format", "f", "bibtex", "Export format: "+strings.Join(library.ExporterNames(), ", "))
+	cmd.Flags().StringVarP(&outFile, "output", "o", "-", "Output file (default: stdout)")
 	cmd.Flags().StringVarP(&tag, "tag", "t", "", "Filter by tag")
 	cmd.Flags().StringVarP(&source, "source", "s", "", "Filter by source")
 	cmd.Flags().StringVarP(&docType, "type", "", "", "Filter by document type")
 	cmd.Flags().StringSliceVarP(&collections, "collection", "c", nil, "Filter by collection name (can be repeated)")
+	cmd.Flags().StringSliceVar(&edgeKinds, "edges", nil, "Graph edge kinds to include: author, tag, collection, links, or a link relation (default: all)")
+	cmd.Flags().StringVar(&groupBy, "group-by", "", "Quiz: group questions by document (default) or collection; markdown: group annotations by meaning")
+	cmd.Flags().IntVar(&keyPoints, "key-points", 0, "Quiz: add this many AI-selected key point questions per document")
+	cmd.Flags().StringVar(&splitBy, "split-by", "", "Markdown: write a directory per "+strings.Join(library.MarkdownSplits, ", ")+" into --dir")
+	cmd.Flags().StringVar(&dir, "dir", "", "Output directory for --split-by")
+	cmd.Flags().BoolVar(&merge, "merge", false, "BibTeX: update matching entries of --output and append new ones, keeping the rest")
+	filters.addFlags(cmd)
+	cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(library.ExporterNames(), cobra.ShellCompDirectiveNoFileComp))
+	cmd.RegisterFlagCompletionFunc("split-by", cobra.FixedCompletions(library.MarkdownSplits, cobra.ShellCompDirectiveNoFileComp))
 
 	return cmd
 }
 
-// exportBibTeX converts documents to BibTeX format.
-func exportBibTeX(docs []*library.Document) ([]byte, error) {
-	var buf bytes.Buffer
-
-	for _, doc := range docs {
-		// Generate a BibTeX entry type and key
-		entryType := "article" // default
-		if doc.Type == library.DocTypeBook {
-			entryType = "book"
-		} else if doc.Type == library.DocTypeOther {
-			entryType = "misc"
-		}
-
-		// Generate citation key from authors + year or source_id
-		key := "unknown"
-		if len(doc.Authors) > 0 {
-			author := doc.Authors[0]
-			parts := strings.Fields(author)
-			if len(parts) > 0 {
-				key = strings.ToLower(parts[0])
-			}
-		}
-		if doc.Source == "arxiv" && doc.SourceID != "" {
-			key = doc.SourceID
-		} else if doc.Source == "doi" && doc.SourceID != "" {
-			key = strings.ReplaceAll(doc.SourceID, "/", "_")
-		}
-		// Add year if available
-		if year, ok := doc.Meta["year"].(int); ok {
-			key = fmt.Sprintf("%s%d", key, year)
-		}
-
-		buf.WriteString(fmt.Sprintf("@%s{%s,\n", entryType, key))
-
-		// Title
-		if doc.Title != "" {
-			buf.WriteString(fmt.Sprintf("  title = {%s},\n", escapeBibTeX(doc.Title)))
-		}
-
-		// Authors
-		if len(doc.Authors) > 0 {
-			buf.WriteString(fmt.Sprintf("  author = {%s},\n", strings.Join(doc.Authors, " and ")))
-		}
-
-		// Abstract
-		if doc.Abstract != "" {
-			buf.WriteString(fmt.Sprintf("  abstract = {%s},\n", escapeBibTeX(doc.Abstract)))
-		}
-
-		// Year from Meta or timestamps
-		if year, ok := doc.Meta["year"].(int); ok {
-			buf.WriteString(fmt.Sprintf("  year = {%d},\n", year))
-		} else {
-			buf.WriteString(fmt.Sprintf("  year = {%d},\n", doc.CreatedAt.Year()))
-		}
-
-		// Journal / container
-		if journal, ok := doc.Meta["journal"].(string); ok {
-			buf.WriteString(fmt.Sprintf("  journal = {%s},\n", journal))
-		}
-
-		// URL
-		if url, ok := doc.Meta["url"].(string); ok {
-			buf.WriteString(fmt.Sprintf("  url = {%s},\n", url))
-		}
-
-		// arXiv ID
-		if doc.Source == "arxiv" && doc.SourceID != "" {
-			buf.WriteString(fmt.Sprintf("  eprint = {%s},\n", doc.SourceID))
-			buf.WriteString("  archivePrefix = {arXiv},\n")
-		}
-
-		// DOI
-		if doc.Source == "doi" && doc.SourceID != "" {
-			buf.WriteString(fmt.Sprintf("  doi = {%s},\n", doc.SourceID))
-		}
-
-		// Path (local file) - custom field
-		if doc.Path != "" {
-			buf.WriteString(fmt.Sprintf("  file = {%s},\n", doc.Path))
-		}
-
-		// Tags as keywords
-		if len(doc.Tags) > 0 {
-			buf.WriteString(fmt.Sprintf("  keywords = {%s}},\n", strings.Join(doc.Tags, ", ")))
-		} else {
-			// Remove trailing comma from previous line if no keywords
-			buf.Truncate(buf.Len() - 2) // remove ",\n"
-			buf.WriteString("\n")
-		}
-
-		buf.WriteString("\n")
-	}
-
-	return buf.Bytes(), nil
-}
-
-// escapeBibTeX escapes special characters for BibTeX.
-func escapeBibTeX(s string) string {
-	// Basic escaping: curly braces, quotes, backslashes, commas
-	s = strings.ReplaceAll(s, "\\", "\\\\")
-	s = strings.ReplaceAll(s, "{", "\\{")
-	s = strings.ReplaceAll(s, "}", "\\}")
-	s = strings.ReplaceAll(s, "\"", "\\\"")
-	return s
-}
-
-// exportMarkdown converts documents to a Markdown notes collection.
-func exportMarkdown(docs []*library.Document, store library.LibraryStore) ([]byte, error) {
-	var buf bytes.Buffer
-
-	buf.WriteString("# Library Export\n\n")
-	buf.WriteString(fmt.Sprintf("Generated: %s\n\n", time.Now().Format(time.RFC3339)))
-	buf.WriteString(fmt.Sprintf("Total documents: %d\n\n---\n\n", len(docs)))
-
-	for _, doc := range docs {
-		buf.WriteString(fmt.Sprintf("## %s\n\n", doc.Title))
-
-		// Metadata
-		buf.WriteString("**Type:** " + string(doc.Type) + "\n\n")
-		if len(doc.Authors) > 0 {
-			buf.WriteString("**Authors:** " + strings.Join(doc.Authors, ", ") + "\n\n")
-		}
-		if doc.Source != "" {
-			buf.WriteString(fmt.Sprintf("**Source:** %s %s\n\n", doc.Source, doc.SourceID))
-		}
-		if doc.Abstract != "" {
-			buf.WriteString("**Abstract**\n\n")
-			buf.WriteString(doc.Abstract + "\n\n")
-		}
-		if doc.FullText != "" {
-			buf.WriteString("**Full Text**\n\n")
-			buf.WriteString(doc.FullText[:min(2000, len(doc.FullText))] + "...\n\n")
-		}
-		if len(doc.Tags) > 0 {
-			buf.WriteString("**Tags:** " + strings.Join(doc.Tags, ", ") + "\n\n")
-		}
-		if doc.Notes != "" {
-			buf.WriteString("**Notes**\n\n")
-			buf.WriteString(doc.Notes + "\n\n")
-		}
-		if doc.Rating > 0 {
-			buf.WriteString(fmt.Sprintf("**Rating:** %d/5\n\n", doc.Rating))
-		}
-
-		// Annotations for this document
-		anns, _ := store.GetAnnotations(doc.ID)
-		if len(anns) > 0 {
-			buf.WriteString("### Annotations\n\n")
-			for _, a := range anns {
-				buf.WriteString(fmt.Sprintf("- [%s] %s\n", a.Type, a.Content))
-				if a.Page > 0 {
-					buf.WriteString(fmt.Sprintf("  (page %d)\n", a.Page))
-				}
-				buf.WriteString("\n")
-			}
-		}
-
-		buf.WriteString("---\n\n")
-	}
-
-	return buf.Bytes(), nil
-}
-
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
+// exportResult is the JSON schema for "export" when writing to a file.
+// When exporting to stdout the exported content itself is the output.
+type exportResult struct {
+	Format    string `json:"format"`
+	File      string `json:"file"` // the directory with --split-by
+	Documents int    `json:"documents"`
+	Groups    int    `json:"groups,omitempty"` // with --split-by
+	Files     int    `json:"files,omitempty"`
 
-// exportJSON just re-serializes documents (could add filtering/transformation)
-func exportJSON(docs []*library.Document) ([]byte, error) {
-	return json.MarshalIndent(docs, "", "  ")
+	Merge *library.BibTeXMergeResult `json:"merge,omitempty"` // with --merge
 }
 
-// exportRIS converts documents to RIS format (reference standard).
-func exportRIS(docs []*library.Document) ([]byte, error) {
-	var buf bytes.Buffer
-
-	for _, doc := range docs {
-		// RIS type
-		risType := "JOUR" // default journal article
-		if doc.Type == library.DocTypeBook {
-			risType = "BOOK"
-		} else if doc.Type == library.DocTypeOther {
-			risType = "GEN"
-		}
-		buf.WriteString(fmt.Sprintf("TY  - %s\n", risType))
-
-		// Title
-		if doc.Title != "" {
-			buf.WriteString(fmt.Sprintf("TI  - %s\n", doc.Title))
+// graphFormats returns the export formats that write a graph.
+func graphFormats() []string {
+	var names []string
+	for _, e := range library.Exporters() {
+		if e.Format().Has(library.FormatGraph) {
+			names = append(names, e.Format().Name)
 		}
-
-		// Authors
-		for _, author := range doc.Authors {
-			buf.WriteString(fmt.Sprintf("AU  - %s\n", author))
-		}
-
-		// Year
-		year := doc.CreatedAt.Year()
-		if y, ok := doc.Meta["year"].(int); ok {
-			year = y
-		}
-		if year > 0 {
-			buf.WriteString(fmt.Sprintf("PY  - %d\n", year))
-		}
-
-		// Journal / container
-		if journal, ok := doc.Meta["journal"].(string); ok && journal != "" {
-			buf.WriteString(fmt.Sprintf("JO  - %s\n", journal))
-		}
-
-		// Abstract
-		if doc.Abstract != "" {
-			buf.WriteString(fmt.Sprintf("AB  - %s\n", doc.Abstract))
-		}
-
-		// DOI
-		if doc.Source == "doi" && doc.SourceID != "" {
-			buf.WriteString(fmt.Sprintf("DO  - %s\n", doc.SourceID))
-		}
-
-		// arXiv ID
-		if doc.Source == "arxiv" && doc.SourceID != "" {
-			buf.WriteString(fmt.Sprintf("UR  - https://arxiv.org/abs/%s\n", doc.SourceID))
-		}
-
-		// URL if present in meta
-		if url, ok := doc.Meta["url"].(string); ok && url != "" {
-			buf.WriteString(fmt.Sprintf("UR  - %s\n", url))
-		}
-
-		// Local file
-		if doc.Path != "" {
-			buf.WriteString(fmt.Sprintf("L1  - %s\n", doc.Path))
-		}
-
-		// Tags as keywords
-		if len(doc.Tags) > 0 {
-			for _, tag := range doc.Tags {
-				buf.WriteString(fmt.Sprintf("KW  - %s\n", tag))
-			}
-		}
-
-		// End record
-		buf.WriteString("ER  - \n\n")
 	}
-
-	return buf.Bytes(), nil
+	return names
 }
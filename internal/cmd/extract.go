@@ -0,0 +1,174 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/mtreilly/arc-library/internal/library"
+	"github.com/spf13/cobra"
+	"github.com/yourorg/arc-sdk/output"
+)
+
+// extractFlags are the flags of commands that extract text from PDFs: how
+// many files at once and how long each may take.
+type extractFlags struct {
+	workers int
+	timeout time.Duration
+}
+
+func (f *extractFlags) addFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&f.workers, "extract-workers", max(1, runtime.NumCPU()/2), "Number of PDFs to extract text from in parallel")
+	cmd.Flags().DurationVar(&f.timeout, "extract-timeout", library.DefaultExtractTimeout, "Give up extracting a PDF's text after this long (0 for no limit)")
+}
+
+// apply makes text extraction use the flags' settings.
+func (f *extractFlags) apply() error {
+	if f.workers < 1 {
+		return fmt.Errorf("--extract-workers must be at least 1")
+	}
+	if f.timeout < 0 {
+		return fmt.Errorf("--extract-timeout must not be negative")
+	}
+	library.SetTextExtraction(f.workers, f.timeout)
+	return nil
+}
+
+// applyExtraction stores an extraction's outcome in doc: the text, and
+// the status in Meta. It returns why the extraction failed, if it did.
+func applyExtraction(doc *library.Document, res *library.TextExtraction) error {
+	res.Record(doc)
+	if res.Err == nil {
+		doc.FullText = res.Text
+	}
+	return res.Err
+}
+
+// docExtractResult is the JSON schema for "doc extract".
+type docExtractResult struct {
+	Extracted []string            `json:"extracted"` // document IDs
+	Failed    []docExtractFailure `json:"failed"`
+}
+
+type docExtractFailure struct {
+	DocumentID string `json:"document_id"`
+	Status     string `json:"status"` // failed or timeout
+	Error      string `json:"error"`
+}
+
+func newDocExtractCmd(store library.LibraryStore) *cobra.Command {
+	var (
+		failed  bool
+		extract extractFlags
+	)
+
+	cmd := &cobra.Command{
+		Use:   "extract [document-id...]",
+		Short: "Extract the full text of PDFs again",
+		Long: `Extract the full text of the given documents' PDFs with pdftotext, or with
+--failed of every document whose last extraction failed or timed out.
+
+Each extraction is recorded in the document's metadata: text_extraction
+is ok, failed, or timeout, text_extraction_ms how long it took, and
+text_extraction_error why it failed, so failures can be tried again,
+with a longer --extract-timeout if they timed out.
+
+Examples:
+  arc-library doc extract --failed --extract-timeout 10m
+  arc-library doc extract 2304.00067`,
+		ValidArgsFunction: completeDocuments(store),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if failed == (len(args) > 0) {
+				return fmt.Errorf("give document IDs or --failed")
+			}
+			if err := extract.apply(); err != nil {
+				return err
+			}
+
+			var docs []*library.Document
+			if failed {
+				all, err := store.ListDocuments(nil)
+				if err != nil {
+					return fmt.Errorf("list documents: %w", err)
+				}
+				for _, d := range all {
+					if library.ExtractionFailed(d) {
+						docs = append(docs, d)
+					}
+				}
+			} else {
+				for _, arg := range args {
+					doc, err := lookupDocument(store, arg)
+					if err != nil {
+						return err
+					}
+					if !strings.EqualFold(filepath.Ext(doc.Path), ".pdf") {
+						return fmt.Errorf("%s is not a PDF", truncate(doc.Title, 40))
+					}
+					docs = append(docs, doc)
+				}
+			}
+
+			result := docExtractResult{Extracted: []string{}, Failed: []docExtractFailure{}}
+			if len(docs) == 0 {
+				if jsonOutput(nil) {
+					return output.JSON(result)
+				}
+				infoln("No documents to extract.")
+				return nil
+			}
+
+			paths := make([]string, len(docs))
+			for i, d := range docs {
+				paths[i] = library.DocumentPath(d)
+			}
+			// Stop extracting ahead once done, as on an error
+			ctx, cancel := context.WithCancel(cmd.Context())
+			defer cancel()
+			extracted := library.DefaultTextExtractor().ExtractAll(ctx, paths)
+
+			bar := newProgress("Extracting", len(docs))
+			defer bar.finish()
+			for i, doc := range docs {
+				bar.set(i, truncate(doc.Title, 40))
+				res := extracted(paths[i])
+				if applyExtraction(doc, res) == nil {
+					library.DetectDocumentLanguage(doc, false)
+				}
+				doc.UpdatedAt = time.Now()
+				if err := store.UpdateDocument(doc); err != nil {
+					return fmt.Errorf("save %s: %w", doc.ID, err)
+				}
+				if res.Err != nil {
+					warnf("  %s: %v\n", truncate(doc.Title, 40), res.Err)
+					result.Failed = append(result.Failed, docExtractFailure{DocumentID: doc.ID, Status: res.Status(), Error: res.Err.Error()})
+					continue
+				}
+				result.Extracted = append(result.Extracted, doc.ID)
+				infof("  %s: %d characters in %s\n", truncate(doc.Title, 40), len(res.Text), res.Duration.Round(time.Millisecond))
+			}
+			bar.finish()
+
+			if jsonOutput(nil) {
+				return output.JSON(result)
+			}
+			if quietOutput() {
+				printIDs(result.Extracted...)
+				return nil
+			}
+			fmt.Printf("\nExtracted: %d, failed: %d\n", len(result.Extracted), len(result.Failed))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&failed, "failed", false, "Extract every document whose last extraction failed or timed out")
+	extract.addFlags(cmd)
+
+	return cmd
+}
@@ -0,0 +1,274 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/mtreilly/arc-library/internal/library"
+	"github.com/spf13/cobra"
+	"github.com/yourorg/arc-sdk/config"
+	"github.com/yourorg/arc-sdk/output"
+)
+
+func newFetchCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "fetch",
+		Short: "Import from researcher profiles and read-later services",
+	}
+
+	cmd.AddCommand(newFetchORCIDCmd(store))
+	cmd.AddCommand(newFetchReadwiseCmd(store))
+	cmd.AddCommand(newFetchOmnivoreCmd(store))
+
+	return cmd
+}
+
+// fetchResult is the JSON schema for "fetch orcid".
+type fetchResult struct {
+	Imported []*library.Document `json:"imported"`
+	Skipped  int                 `json:"skipped"` // already in the library
+}
+
+func newFetchORCIDCmd(store library.LibraryStore) *cobra.Command {
+	var (
+		tags   []string
+		dryRun bool
+		apiURL string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "orcid <id>",
+		Short: "Import the publications on an ORCID record",
+		Long: `Import the works listed on a public ORCID record as metadata-only
+documents tagged no-file, e.g. to build a reading list around an author or
+keep track of your own publications. Works already in the library, by DOI
+or from an earlier fetch, are skipped, so the command can be re-run to pick
+up new publications.
+
+The iD can be given bare or as an orcid.org URL.
+
+Examples:
+  arc-library fetch orcid 0000-0002-1825-0097
+  arc-library fetch orcid https://orcid.org/0000-0002-1825-0097 -t carberry
+  arc-library fetch orcid 0000-0002-1825-0097 --dry-run`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := library.NormalizeORCID(args[0])
+			if err != nil {
+				return err
+			}
+
+			infof("Fetching works for ORCID %s...\n", id)
+			docs, err := library.FetchORCIDWorks(apiURL, id)
+			if err != nil {
+				return err
+			}
+
+			existing, err := store.ListDocuments(nil)
+			if err != nil {
+				return err
+			}
+			known := make(map[string]bool, len(existing))
+			for _, d := range existing {
+				known[d.Source+":"+strings.ToLower(d.SourceID)] = true
+				if doi := metaDoi(d); doi != "" {
+					known["doi:"+strings.ToLower(doi)] = true
+				}
+			}
+
+			result := fetchResult{Imported: []*library.Document{}}
+			for _, doc := range docs {
+				key := doc.Source + ":" + strings.ToLower(doc.SourceID)
+				if known[key] {
+					result.Skipped++
+					continue
+				}
+				known[key] = true
+				for _, t := range tags {
+					if !slices.Contains(doc.Tags, t) {
+						doc.Tags = append(doc.Tags, t)
+					}
+				}
+				library.DetectDocumentLanguage(doc, false)
+				if !dryRun {
+					if err := store.AddDocument(doc); err != nil {
+						return fmt.Errorf("add %q: %w", doc.Title, err)
+					}
+				}
+				result.Imported = append(result.Imported, doc)
+			}
+
+			if jsonOutput(nil) {
+				return output.JSON(result)
+			}
+			if quietOutput() {
+				printIDs(documentIDs(result.Imported)...)
+				return nil
+			}
+
+			if len(result.Imported) > 0 {
+				table := output.NewTable("Year", "Type", "Title")
+				for _, d := range result.Imported {
+					year := ""
+					if y, ok := d.Meta["year"].(int); ok {
+						year = fmt.Sprint(y)
+					}
+					table.AddRow(year, string(d.Type), truncate(d.Title, 60))
+				}
+				table.Render()
+				fmt.Println()
+			}
+			verb := "Imported"
+			if dryRun {
+				verb = "Would import"
+			}
+			fmt.Printf("%s %d work(s); %d already in the library.\n", verb, len(result.Imported), result.Skipped)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&tags, "tag", "t", nil, "More tags to apply besides no-file")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "List the works without importing them")
+	cmd.Flags().StringVar(&apiURL, "api-url", library.ORCIDAPI, "ORCID public API base URL")
+	cmd.Flags().MarkHidden("api-url")
+
+	return cmd
+}
+
+func newFetchReadwiseCmd(store library.LibraryStore) *cobra.Command {
+	var (
+		token  string
+		file   string
+		tags   []string
+		dryRun bool
+		apiURL string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "readwise",
+		Short: "Import articles and highlights from Readwise Reader",
+		Long: `Import the documents saved in Readwise Reader as articles, with their
+highlights and the notes on them as annotations. Feed items you never saved
+are left out. Documents already in the library, matched by URL, are not
+added again; new tags and highlights are added to them instead, so the
+command can be re-run to pick up new highlights.
+
+The access token from https://readwise.io/access_token is read from --token
+or ARC_LIBRARY_READWISE_TOKEN. Alternatively --file imports a response of
+the Reader list API (https://readwise.io/api/v3/list/) saved to disk.
+
+Examples:
+  arc-library fetch readwise --token TOKEN --dry-run
+  arc-library fetch readwise -t reader        # With ARC_LIBRARY_READWISE_TOKEN set
+  arc-library fetch readwise --file reader.json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var items []*library.ReadLaterItem
+			if file != "" {
+				data, err := os.ReadFile(file)
+				if err != nil {
+					return err
+				}
+				if items, err = library.ParseReadwiseExport(data); err != nil {
+					return err
+				}
+			} else {
+				if token == "" {
+					return fmt.Errorf("--token is required (or set ARC_LIBRARY_READWISE_TOKEN, or use --file)")
+				}
+				client := library.NewReadwiseClient(token)
+				client.BaseURL = apiURL
+				infof("Fetching Readwise Reader documents...\n")
+				var err error
+				if items, err = client.FetchDocuments(); err != nil {
+					return err
+				}
+			}
+			return importReadLater(store, "readwise", items, tags, dryRun)
+		},
+	}
+
+	cmd.Flags().StringVar(&token, "token", os.Getenv("ARC_LIBRARY_READWISE_TOKEN"), "Readwise access token")
+	cmd.Flags().StringVar(&file, "file", "", "Import a saved list API response instead of calling the API")
+	cmd.Flags().StringSliceVarP(&tags, "tag", "t", nil, "Tags to apply to the imported documents")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "List what would be imported without importing it")
+	cmd.Flags().StringVar(&apiURL, "api-url", library.ReadwiseReaderAPI, "Readwise Reader API base URL")
+	cmd.Flags().MarkHidden("api-url")
+
+	return cmd
+}
+
+func newFetchOmnivoreCmd(store library.LibraryStore) *cobra.Command {
+	var (
+		tags   []string
+		dryRun bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "omnivore <export.zip|dir>",
+		Short: "Import articles and highlights from an Omnivore export",
+		Long: `Import the articles of an Omnivore export, the zip file of
+metadata_*.json and highlights/*.md files or the directory it was unpacked
+to, with their highlights and the notes on them as annotations. Articles
+already in the library, matched by URL, are not added again; new labels and
+highlights are added to them instead.
+
+Examples:
+  arc-library fetch omnivore ~/Downloads/omnivore-export.zip --dry-run
+  arc-library fetch omnivore ~/Downloads/omnivore-export -t omnivore`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			items, err := library.OpenOmnivoreExport(args[0])
+			if err != nil {
+				return err
+			}
+			return importReadLater(store, "omnivore", items, tags, dryRun)
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&tags, "tag", "t", nil, "Tags to apply to the imported documents")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "List what would be imported without importing it")
+
+	return cmd
+}
+
+// importReadLater imports read-later items and reports the result, for
+// "fetch readwise" and "fetch omnivore".
+func importReadLater(store library.LibraryStore, source string, items []*library.ReadLaterItem, tags []string, dryRun bool) error {
+	result, err := library.ImportReadLater(store, source, items, tags, dryRun)
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput(nil) {
+		return output.JSON(result)
+	}
+	if quietOutput() {
+		printIDs(documentIDs(result.Imported)...)
+		return nil
+	}
+
+	if len(result.Imported)+len(result.Updated) > 0 {
+		table := output.NewTable("", "Status", "Title")
+		for _, d := range result.Imported {
+			table.AddRow("new", string(d.Status), truncate(d.Title, 60))
+		}
+		for _, d := range result.Updated {
+			table.AddRow("updated", string(d.Status), truncate(d.Title, 60))
+		}
+		table.Render()
+		fmt.Println()
+	}
+	verb := "Imported"
+	if dryRun {
+		verb = "Would import"
+	}
+	fmt.Printf("%s %d document(s) and %d highlight(s) and note(s); updated %d, %d unchanged.\n",
+		verb, len(result.Imported), result.Highlights, len(result.Updated), result.Skipped)
+	return nil
+}
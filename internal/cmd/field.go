@@ -0,0 +1,342 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mtreilly/arc-library/internal/library"
+	"github.com/spf13/cobra"
+	"github.com/yourorg/arc-sdk/config"
+	"github.com/yourorg/arc-sdk/output"
+)
+
+func newFieldCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "field",
+		Short: "Define and set custom document fields",
+		Long: `Declare typed custom fields, such as a publication year or venue, and set
+them on documents. Values are validated against the field type, can be
+filtered with --where or inside a search query, and show up as columns in
+"arc-library list".
+
+Types: string, int, float, bool, date (stored as YYYY-MM-DD; "next friday" works too), enum(a,b,...).
+
+Examples:
+  arc-library field define year:int venue:string "stage:enum(draft,review,final)"
+  arc-library field set 2304.00067 year=2023 venue=NeurIPS
+  arc-library list --where year>=2020
+  arc-library search run "attention venue:NeurIPS"`,
+	}
+
+	cmd.AddCommand(newFieldDefineCmd(store))
+	cmd.AddCommand(newFieldListCmd(store))
+	cmd.AddCommand(newFieldRemoveCmd(store))
+	cmd.AddCommand(newFieldSetCmd(store))
+	cmd.AddCommand(newFieldUnsetCmd(store))
+
+	return cmd
+}
+
+func newFieldDefineCmd(store library.LibraryStore) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "define <name:type>...",
+		Short: "Define or redefine custom fields",
+		Long:  `Define custom fields. Redefining a field changes its type; values already set that no longer fit are reported but kept.`,
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var defs []*library.FieldDef
+			for _, arg := range args {
+				def, err := library.ParseFieldDef(arg)
+				if err != nil {
+					return err
+				}
+				defs = append(defs, def)
+			}
+
+			existing, err := store.ListFields()
+			if err != nil {
+				return fmt.Errorf("list fields: %w", err)
+			}
+			for _, def := range defs {
+				for _, old := range existing {
+					if old.Name == def.Name {
+						def.CreatedAt = old.CreatedAt
+					}
+				}
+				if err := store.DefineField(def); err != nil {
+					return fmt.Errorf("define field %s: %w", def.Name, err)
+				}
+				infof("Defined field %s\n", def)
+			}
+
+			// Values set before the field was (re)defined may not fit it
+			if docs, err := store.ListDocuments(nil); err == nil {
+				for _, def := range defs {
+					invalid := 0
+					for _, d := range docs {
+						if v, ok := d.Meta[def.Name]; ok && def.Validate(v) != nil {
+							invalid++
+						}
+					}
+					if invalid > 0 {
+						warnf("%d document(s) have a %s value that is not a valid %s\n", invalid, def.Name, def.Type)
+					}
+				}
+			}
+
+			if jsonOutput(nil) {
+				return output.JSON(defs)
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func newFieldListCmd(store library.LibraryStore) *cobra.Command {
+	var out output.OutputOptions
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List custom fields",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := out.Resolve(); err != nil {
+				return err
+			}
+
+			defs, err := store.ListFields()
+			if err != nil {
+				return fmt.Errorf("list fields: %w", err)
+			}
+
+			if jsonOutput(&out) {
+				return output.JSON(nonNil(defs))
+			}
+			if quietOutput() {
+				for _, def := range defs {
+					printIDs(def.Name)
+				}
+				return nil
+			}
+
+			if len(defs) == 0 {
+				fmt.Println("No custom fields defined.")
+				fmt.Println("Use 'arc-library field define name:type' to add one.")
+				return nil
+			}
+
+			docs, _ := store.ListDocuments(nil)
+			table := output.NewTable("Name", "Type", "Documents")
+			for _, def := range defs {
+				set := 0
+				for _, d := range docs {
+					if _, ok := d.Meta[def.Name]; ok {
+						set++
+					}
+				}
+				typ := string(def.Type)
+				if def.Type == library.FieldEnum {
+					typ = "enum(" + strings.Join(def.Values, ",") + ")"
+				}
+				table.AddRow(def.Name, typ, strconv.Itoa(set))
+			}
+			table.Render()
+			return nil
+		},
+	}
+
+	out.AddOutputFlags(cmd, output.OutputTable)
+
+	return cmd
+}
+
+func newFieldRemoveCmd(store library.LibraryStore) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "remove <name>",
+		Aliases:           []string{"rm"},
+		Short:             "Remove a custom field definition",
+		Long:              `Remove a custom field definition. Values already set stay in each document's metadata; use "field unset" to clear them.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeFirst(completeFields(store)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			def, err := lookupField(store, args[0])
+			if err != nil {
+				return err
+			}
+			if err := store.DeleteField(def.Name); err != nil {
+				return fmt.Errorf("remove field: %w", err)
+			}
+
+			if jsonOutput(nil) {
+				return output.JSON(deleteResult{Kind: "field", ID: def.Name, Deleted: true})
+			}
+			infof("Removed field %s\n", def.Name)
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func newFieldSetCmd(store library.LibraryStore) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "set <document-id> <name=value>...",
+		Short:             "Set custom fields on a document",
+		Example:           `  arc-library field set 2304.00067 year=2023 "venue=ICML 2023"`,
+		Args:              cobra.MinimumNArgs(2),
+		ValidArgsFunction: completeFirst(completeDocuments(store)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			doc, err := lookupDocument(store, args[0])
+			if err != nil {
+				return err
+			}
+			defs, err := store.ListFields()
+			if err != nil {
+				return fmt.Errorf("list fields: %w", err)
+			}
+
+			// Validate everything before changing anything
+			values := make(library.JSONMap)
+			for _, arg := range args[1:] {
+				name, value, ok := strings.Cut(arg, "=")
+				if !ok {
+					return fmt.Errorf("invalid assignment %q (use name=value)", arg)
+				}
+				def := fieldByName(defs, strings.ToLower(strings.TrimSpace(name)))
+				if def == nil {
+					return fmt.Errorf("field %q is not defined (see 'arc-library field define')", name)
+				}
+				v, err := def.Parse(value)
+				if err != nil {
+					return err
+				}
+				values[def.Name] = v
+			}
+
+			if doc.Meta == nil {
+				doc.Meta = make(library.JSONMap)
+			}
+			for name, v := range values {
+				doc.Meta[name] = v
+			}
+			doc.UpdatedAt = time.Now()
+			if err := store.UpdateDocument(doc); err != nil {
+				return fmt.Errorf("update document: %w", err)
+			}
+
+			if jsonOutput(nil) {
+				return output.JSON(doc)
+			}
+			for _, def := range defs {
+				if _, ok := values[def.Name]; ok {
+					infof("%s = %s\n", def.Name, fieldValue(doc, def))
+				}
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func newFieldUnsetCmd(store library.LibraryStore) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "unset <document-id> <name>...",
+		Short:             "Clear custom fields on a document",
+		Args:              cobra.MinimumNArgs(2),
+		ValidArgsFunction: completeFirstThen(completeDocuments(store), completeFields(store)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			doc, err := lookupDocument(store, args[0])
+			if err != nil {
+				return err
+			}
+
+			changed := false
+			for _, name := range args[1:] {
+				name = strings.ToLower(name)
+				if _, ok := doc.Meta[name]; ok {
+					delete(doc.Meta, name)
+					changed = true
+				} else {
+					warnf("%s is not set on this document\n", name)
+				}
+			}
+			if changed {
+				doc.UpdatedAt = time.Now()
+				if err := store.UpdateDocument(doc); err != nil {
+					return fmt.Errorf("update document: %w", err)
+				}
+			}
+
+			if jsonOutput(nil) {
+				return output.JSON(doc)
+			}
+			if changed {
+				infof("Cleared fields on %s\n", truncate(doc.Title, 40))
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// lookupField finds a defined field by name.
+func lookupField(store library.LibraryStore, name string) (*library.FieldDef, error) {
+	defs, err := store.ListFields()
+	if err != nil {
+		return nil, fmt.Errorf("list fields: %w", err)
+	}
+	if def := fieldByName(defs, strings.ToLower(name)); def != nil {
+		return def, nil
+	}
+	return nil, fmt.Errorf("field not found: %s", name)
+}
+
+func fieldByName(defs []*library.FieldDef, name string) *library.FieldDef {
+	for _, def := range defs {
+		if def.Name == name {
+			return def
+		}
+	}
+	return nil
+}
+
+// fieldColumns returns the defined fields set on at least one of docs, for
+// use as extra table columns. Backends without field support give none.
+func fieldColumns(store library.LibraryStore, docs []*library.Document) []*library.FieldDef {
+	defs, err := store.ListFields()
+	if err != nil {
+		return nil
+	}
+	var used []*library.FieldDef
+	for _, def := range defs {
+		for _, d := range docs {
+			if _, ok := d.Meta[def.Name]; ok {
+				used = append(used, def)
+				break
+			}
+		}
+	}
+	return used
+}
+
+// fieldValue formats a document's value for a field, or "" when unset.
+func fieldValue(doc *library.Document, def *library.FieldDef) string {
+	v, ok := doc.Meta[def.Name]
+	if !ok || v == nil {
+		return ""
+	}
+	// JSON decodes every number as float64; show integers without a fraction
+	if f, ok := v.(float64); ok && def.Type == library.FieldInt {
+		return strconv.FormatInt(int64(f), 10)
+	}
+	return fmt.Sprint(v)
+}
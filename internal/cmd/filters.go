@@ -0,0 +1,103 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mtreilly/arc-library/internal/library"
+	"github.com/spf13/cobra"
+)
+
+// documentFilters holds the metadata filter flags shared by list, search run and export.
+type documentFilters struct {
+	status        string
+	rating        int
+	author        string
+	language      string
+	createdAfter  string
+	createdBefore string
+	readAfter     string
+	readBefore    string
+	where         []string
+}
+
+func (f *documentFilters) addFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.status, "status", "", "Filter by reading status (unread, reading, completed, archived)")
+	cmd.Flags().IntVar(&f.rating, "rating", 0, "Only documents rated at least this (1-5)")
+	cmd.Flags().StringVar(&f.author, "author", "", "Filter by author (substring match)")
+	cmd.Flags().StringVar(&f.language, "language", "", "Filter by detected language (ISO 639-1 code, e.g. en, de)")
+	cmd.Flags().StringVar(&f.createdAfter, "created-after", "", "Only documents added on or after this date (e.g. 2025-01-31, last monday)")
+	cmd.Flags().StringVar(&f.createdBefore, "created-before", "", "Only documents added before this date (e.g. 2025-01-31, yesterday)")
+	cmd.Flags().StringVar(&f.readAfter, "read-after", "", "Only documents read on or after this date (e.g. 2025-01-31, 2 weeks ago)")
+	cmd.Flags().StringVar(&f.readBefore, "read-before", "", "Only documents read before this date (e.g. 2025-01-31, last month)")
+	cmd.Flags().StringArrayVar(&f.where, "where", nil, "Filter by custom field, e.g. year>=2020 or venue=NeurIPS (repeatable)")
+}
+
+// apply validates the flags and copies them into opts. The store is only
+// consulted for --where, to look up the custom field definitions.
+func (f *documentFilters) apply(store library.LibraryStore, opts *library.ListOptions) error {
+	if f.status != "" && !validReadingStatus(library.ReadingStatus(f.status)) {
+		return fmt.Errorf("invalid status %q (use unread, reading, completed, archived)", f.status)
+	}
+	if f.rating < 0 || f.rating > 5 {
+		return fmt.Errorf("rating must be between 1 and 5")
+	}
+	opts.Status = f.status
+	opts.MinRating = f.rating
+	opts.Author = f.author
+	opts.Language = f.language
+
+	dates := []struct {
+		flag  string
+		value string
+		dst   *time.Time
+	}{
+		{"created-after", f.createdAfter, &opts.CreatedAfter},
+		{"created-before", f.createdBefore, &opts.CreatedBefore},
+		{"read-after", f.readAfter, &opts.ReadAfter},
+		{"read-before", f.readBefore, &opts.ReadBefore},
+	}
+	for _, d := range dates {
+		if d.value == "" {
+			continue
+		}
+		t, err := parseFilterDate(d.value)
+		if err != nil {
+			return fmt.Errorf("--%s: %w", d.flag, err)
+		}
+		*d.dst = t
+	}
+
+	if len(f.where) > 0 {
+		defs, err := store.ListFields()
+		if err != nil {
+			return fmt.Errorf("list fields: %w", err)
+		}
+		for _, expr := range f.where {
+			filter, err := library.ParseFieldFilter(expr, defs)
+			if err != nil {
+				return fmt.Errorf("--where: %w", err)
+			}
+			opts.Fields = append(opts.Fields, *filter)
+		}
+	}
+	return nil
+}
+
+// parseFilterDate accepts a local calendar date (YYYY-MM-DD), an RFC 3339
+// timestamp, or anything else library.ParseDate reads, such as "last monday".
+func parseFilterDate(s string) (time.Time, error) {
+	return library.ParseDate(s, time.Now())
+}
+
+// validReadingStatus reports whether s is one of the known reading statuses.
+func validReadingStatus(s library.ReadingStatus) bool {
+	switch s {
+	case library.StatusUnread, library.StatusReading, library.StatusCompleted, library.StatusArchived:
+		return true
+	}
+	return false
+}
@@ -4,12 +4,18 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
+	"io"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/mtreilly/arc-library/internal/library"
+	"github.com/mtreilly/arc-library/internal/scheduler"
 	"github.com/yourorg/arc-sdk/config"
 	"github.com/yourorg/arc-sdk/output"
 )
@@ -26,7 +32,11 @@ func newFlashcardCmd(cfg *config.Config, store library.LibraryStore) *cobra.Comm
 	cmd.AddCommand(newFlashcardReviewCmd(store))
 	cmd.AddCommand(newFlashcardDeleteCmd(store))
 	cmd.AddCommand(newFlashcardDueCmd(store))
+	cmd.AddCommand(newFlashcardStudyCmd(store))
+	cmd.AddCommand(newFlashcardCoverageCmd(store))
 	cmd.AddCommand(newFlashcardExportCmd(store))
+	cmd.AddCommand(newFlashcardImportCmd(store))
+	cmd.AddCommand(newFlashcardSettingsCmd())
 
 	return cmd
 }
@@ -39,7 +49,7 @@ func newFlashcardAddCmd(store library.LibraryStore) *cobra.Command {
 		back   string
 		cloze  string
 		tags   []string
-		due    int // days from now
+		due    string
 		out    output.OutputOptions
 	)
 
@@ -63,7 +73,7 @@ func newFlashcardAddCmd(store library.LibraryStore) *cobra.Command {
 					return err
 				}
 				if doc == nil {
-					return fmt.Errorf("document not found: %s", docID)
+					return fmt.Errorf("%w: %s", library.ErrDocumentNotFound, docID)
 				}
 			}
 
@@ -78,21 +88,30 @@ func newFlashcardAddCmd(store library.LibraryStore) *cobra.Command {
 				UpdatedAt:  time.Now(),
 			}
 
-			// Set initial due date
-			if due == 0 {
-				due = 1 // default due tomorrow
+			// Set initial due date: a number of days, as before, or a date
+			if days, err := strconv.Atoi(due); err == nil {
+				if days <= 0 {
+					days = 1 // default due tomorrow
+				}
+				card.DueAt = library.DueDate(library.Now(), days)
+			} else if card.DueAt, err = library.ParseDate(due, library.Now()); err != nil {
+				return fmt.Errorf("invalid --due: %w", err)
 			}
-			card.DueAt = time.Now().AddDate(0, 0, due)
+			card.DueAt = card.DueAt.UTC()
 			card.Interval = 0
-			card.Ease = 2.5
+			card.Ease = scheduler.Current().InitialEase
 
 			if err := store.AddFlashcard(card); err != nil {
 				return fmt.Errorf("add flashcard: %w", err)
 			}
 
-			if out.Is(output.OutputJSON) {
+			if jsonOutput(&out) {
 				return output.JSON(card)
 			}
+			if quietOutput() {
+				printIDs(card.ID)
+				return nil
+			}
 
 			fmt.Printf("Flashcard created: %s\n", card.ID)
 			fmt.Printf("Front: %s\n", truncate(card.Front, 60))
@@ -101,7 +120,7 @@ func newFlashcardAddCmd(store library.LibraryStore) *cobra.Command {
 			} else {
 				fmt.Printf("Back: %s\n", truncate(card.Back, 60))
 			}
-			fmt.Printf("Due: %s\n", card.DueAt.Format("2006-01-02"))
+			fmt.Printf("Due: %s\n", library.HomeDate(card.DueAt))
 			return nil
 		},
 	}
@@ -112,7 +131,7 @@ func newFlashcardAddCmd(store library.LibraryStore) *cobra.Command {
 	cmd.Flags().StringVar(&back, "back", "", "Back side text (for basic cards)")
 	cmd.Flags().StringVar(&cloze, "cloze", "", "Cloze deletion text (e.g., 'The capital of France is {{c1::Paris}}')")
 	cmd.Flags().StringSliceVar(&tags, "tags", nil, "Tags")
-	cmd.Flags().IntVar(&due, "due", 1, "Days until due (default: 1)")
+	cmd.Flags().StringVar(&due, "due", "1", "Days until due, or a date such as friday or 2025-01-31")
 	out.AddOutputFlags(cmd, output.OutputJSON)
 
 	return cmd
@@ -148,8 +167,14 @@ func newFlashcardListCmd(store library.LibraryStore) *cobra.Command {
 				return fmt.Errorf("list flashcards: %w", err)
 			}
 
-			if out.Is(output.OutputJSON) {
-				return output.JSON(cards)
+			if jsonOutput(&out) {
+				return output.JSON(nonNil(cards))
+			}
+			if quietOutput() {
+				for _, c := range cards {
+					printIDs(c.ID)
+				}
+				return nil
 			}
 
 			if len(cards) == 0 {
@@ -162,8 +187,8 @@ func newFlashcardListCmd(store library.LibraryStore) *cobra.Command {
 				front := truncate(c.Front, 30)
 				dueStr := ""
 				if !c.DueAt.IsZero() {
-					dueStr = c.DueAt.Format("2006-01-02")
-					if c.DueAt.Before(time.Now()) {
+					dueStr = library.HomeDate(c.DueAt)
+					if library.Overdue(c.DueAt, library.Now()) {
 						dueStr += " (!)"
 					}
 				}
@@ -209,15 +234,18 @@ func newFlashcardReviewCmd(store library.LibraryStore) *cobra.Command {
 				return fmt.Errorf("review flashcard: %w", err)
 			}
 
-			if out.Is(output.OutputJSON) {
+			if jsonOutput(&out) {
 				return output.JSON(card)
 			}
+			if quietOutput() {
+				return nil
+			}
 
 			fmt.Printf("Flashcard reviewed: %s\n", card.ID)
 			fmt.Printf("Quality: %d/5\n", quality)
 			fmt.Printf("New interval: %d days\n", card.Interval)
 			fmt.Printf("New ease: %.2f\n", card.Ease)
-			fmt.Printf("Next due: %s\n", card.DueAt.Format("2006-01-02"))
+			fmt.Printf("Next due: %s\n", library.HomeDate(card.DueAt))
 			return nil
 		},
 	}
@@ -240,11 +268,14 @@ func newFlashcardDeleteCmd(store library.LibraryStore) *cobra.Command {
 			}
 
 			id := args[0]
-			if err := store.DeleteFlashcard(id); err != nil {
+			if _, err := library.DeleteFlashcardUndoable(store, id); err != nil {
 				return fmt.Errorf("delete flashcard: %w", err)
 			}
 
-			fmt.Printf("Flashcard deleted: %s\n", id)
+			if jsonOutput(&out) {
+				return output.JSON(deleteResult{Kind: "flashcard", ID: id, Deleted: true})
+			}
+			infof("Flashcard deleted: %s (arc-library undo to restore)\n", id)
 			return nil
 		},
 	}
@@ -268,7 +299,7 @@ func newFlashcardDueCmd(store library.LibraryStore) *cobra.Command {
 				return err
 			}
 
-			now := time.Now()
+			now := library.Now()
 			cards, err := store.GetDueFlashcards(now)
 			if err != nil {
 				return fmt.Errorf("get due flashcards: %w", err)
@@ -278,8 +309,14 @@ func newFlashcardDueCmd(store library.LibraryStore) *cobra.Command {
 				cards = cards[:limit]
 			}
 
-			if out.Is(output.OutputJSON) {
-				return output.JSON(cards)
+			if jsonOutput(&out) {
+				return output.JSON(nonNil(cards))
+			}
+			if quietOutput() {
+				for _, c := range cards {
+					printIDs(c.ID)
+				}
+				return nil
 			}
 
 			if len(cards) == 0 {
@@ -298,8 +335,8 @@ func newFlashcardDueCmd(store library.LibraryStore) *cobra.Command {
 					docTitle = truncate(doc.Title, 20)
 				}
 				front := truncate(c.Front, 30)
-				dueStr := c.DueAt.Format("2006-01-02")
-				if c.DueAt.Before(now) {
+				dueStr := library.HomeDate(c.DueAt)
+				if library.Overdue(c.DueAt, now) {
 					dueStr += " (overdue)"
 				}
 				table.AddRow(truncate(c.ID, 8), docTitle, front, fmt.Sprintf("%d", c.Interval), dueStr)
@@ -317,86 +354,486 @@ func newFlashcardDueCmd(store library.LibraryStore) *cobra.Command {
 	return cmd
 }
 
+func newFlashcardStudyCmd(store library.LibraryStore) *cobra.Command {
+	var (
+		maxNew    int
+		maxReview int
+		cram      string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "study",
+		Short: "Review today's flashcards one after another",
+		Long: `Show today's flashcards in turn, due reviews first and then new cards,
+and rate each one 0-5 as with "flashcard review".
+
+--max-new and --max-review cap how many new cards and reviews are studied a
+day, so a large backlog is spread over several days. The limits are
+remembered for later sessions and for "due"; 0 removes one. Cards already
+studied today count against them.
+
+--cram practices the cards matching tag:<tag>, doc:<id>, or all, due or
+not, without changing their schedule. Cards rated below 3 come back at the
+end of the session until recalled.
+
+With --json the day's plan is written instead of studying.
+
+Examples:
+  arc-library flashcard study
+  arc-library flashcard study --max-new 10 --max-review 100
+  arc-library flashcard study --cram tag:exam`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			in := bufio.NewReader(os.Stdin)
+			if cmd.Flags().Changed("cram") {
+				opts, err := library.ParseCramSelector(cram)
+				if err != nil {
+					return err
+				}
+				cards, err := store.ListFlashcards(opts)
+				if err != nil {
+					return fmt.Errorf("list flashcards: %w", err)
+				}
+				if jsonOutput(nil) {
+					return output.JSON(nonNil(cards))
+				}
+				if len(cards) == 0 {
+					infoln("No flashcards match.")
+					return nil
+				}
+				infof("Cramming %d card(s); their schedule is left unchanged.\n", len(cards))
+
+				queue, recalled := slices.Clone(cards), 0
+				err = studyCards(in, &queue, func(c *library.Flashcard, quality int) error {
+					if quality < 3 {
+						queue = append(queue, c)
+					} else {
+						recalled++
+					}
+					return nil
+				})
+				if err != nil {
+					return err
+				}
+				infof("\nRecalled %d of %d card(s).\n", recalled, len(cards))
+				return nil
+			}
+
+			path, err := library.StudyLimitsFile()
+			if err != nil {
+				return err
+			}
+			limits, err := library.LoadStudyLimits(path)
+			if err != nil {
+				return fmt.Errorf("load study limits: %w", err)
+			}
+			if cmd.Flags().Changed("max-new") || cmd.Flags().Changed("max-review") {
+				if maxNew < 0 || maxReview < 0 {
+					return fmt.Errorf("limits must not be negative")
+				}
+				if cmd.Flags().Changed("max-new") {
+					limits.MaxNew = maxNew
+				}
+				if cmd.Flags().Changed("max-review") {
+					limits.MaxReview = maxReview
+				}
+				if err := library.SaveStudyLimits(path, limits); err != nil {
+					return fmt.Errorf("save study limits: %w", err)
+				}
+			}
+
+			plan, err := library.PlanStudy(store, limits, library.Now())
+			if err != nil {
+				return fmt.Errorf("plan study: %w", err)
+			}
+			if jsonOutput(nil) {
+				plan.New, plan.Review = nonNil(plan.New), nonNil(plan.Review)
+				return output.JSON(plan)
+			}
+
+			cards := plan.Cards()
+			if len(cards) == 0 {
+				if plan.Backlog > 0 {
+					infof("Today's limits are reached; %d due card(s) are left for later days.\n", plan.Backlog)
+				} else {
+					infoln("No flashcards to study today!")
+				}
+				return nil
+			}
+
+			infof("%d review(s) and %d new card(s) today", len(plan.Review), len(plan.New))
+			if plan.Backlog > 0 {
+				infof(", %d more in the backlog", plan.Backlog)
+			}
+			infoln()
+
+			studied := 0
+			err = studyCards(in, &cards, func(c *library.Flashcard, quality int) error {
+				card, err := store.ReviewFlashcard(c.ID, quality)
+				if err != nil {
+					return fmt.Errorf("review flashcard: %w", err)
+				}
+				infof("Next due: %s\n", library.HomeDate(card.DueAt))
+				studied++
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			infof("\nStudied %d of %d card(s) for today.\n", studied, len(cards))
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&maxNew, "max-new", 0, "New cards to study a day, remembered for later sessions (0: no limit)")
+	cmd.Flags().IntVar(&maxReview, "max-review", 0, "Reviews to do a day, remembered for later sessions (0: no limit)")
+	cmd.Flags().StringVar(&cram, "cram", "", "Practice cards matching tag:<tag>, doc:<id>, or all without rescheduling them")
+	cmd.MarkFlagsMutuallyExclusive("cram", "max-new")
+	cmd.MarkFlagsMutuallyExclusive("cram", "max-review")
+
+	return cmd
+}
+
+// studyCards shows each card of *queue in turn, then its answer, and
+// passes the 0-5 rating given to rate. rate may append to *queue to show a
+// card again. It stops early on q or the end of input.
+func studyCards(in *bufio.Reader, queue *[]*library.Flashcard, rate func(*library.Flashcard, int) error) error {
+	for i := 0; i < len(*queue); i++ {
+		c := (*queue)[i]
+		fmt.Printf("\n[%d/%d] %s\n", i+1, len(*queue), c.Front)
+		if promptLine(in, "Show answer (enter, q to stop): ") == "q" {
+			return nil
+		}
+		if c.Type == "cloze" {
+			fmt.Println(c.Cloze)
+		} else {
+			fmt.Println(c.Back)
+		}
+		for {
+			fmt.Print("Quality 0-5 (q to stop): ")
+			line, err := in.ReadString('\n')
+			answer := strings.TrimSpace(line)
+			if answer == "q" || (err != nil && answer == "") {
+				return nil
+			}
+			quality, err := strconv.Atoi(answer)
+			if err != nil || quality < 0 || quality > 5 {
+				continue
+			}
+			if err := rate(c, quality); err != nil {
+				return err
+			}
+			break
+		}
+	}
+	return nil
+}
+
+func newFlashcardCoverageCmd(store library.LibraryStore) *cobra.Command {
+	var (
+		minAnnotations int
+		lagging        bool
+		out            output.OutputOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:   "coverage",
+		Short: "Show how many flashcards each annotated document has",
+		Long: `List the documents with annotations or flashcards, with how many of
+each they have. Documents with at least --min-annotations annotations but
+no flashcards are marked as lagging and listed first: reading there has
+not yet been turned into active recall.
+
+Examples:
+  arc-library flashcard coverage
+  arc-library flashcard coverage --lagging --min-annotations 10`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := out.Resolve(); err != nil {
+				return err
+			}
+
+			coverage, err := library.FlashcardCoverage(store, minAnnotations)
+			if err != nil {
+				return fmt.Errorf("flashcard coverage: %w", err)
+			}
+			if lagging {
+				coverage = slices.DeleteFunc(coverage, func(c library.DocumentCoverage) bool { return !c.Lagging })
+			}
+
+			if jsonOutput(&out) {
+				return output.JSON(nonNil(coverage))
+			}
+			if quietOutput() {
+				for _, c := range coverage {
+					printIDs(c.DocumentID)
+				}
+				return nil
+			}
+
+			if len(coverage) == 0 {
+				fmt.Println("No annotated documents.")
+				return nil
+			}
+
+			behind := 0
+			table := output.NewTable("ID", "Title", "Annotations", "Cards", "")
+			for _, c := range coverage {
+				mark := ""
+				if c.Lagging {
+					mark = "no cards (!)"
+					behind++
+				}
+				table.AddRow(truncate(c.DocumentID, 8), truncate(c.Title, 40), fmt.Sprintf("%d", c.Annotations), fmt.Sprintf("%d", c.Flashcards), mark)
+			}
+			table.Render()
+
+			fmt.Printf("\n%d document(s), %d heavily annotated without flashcards\n", len(coverage), behind)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&minAnnotations, "min-annotations", library.DefaultCoverageMinAnnotations, "Annotations from which a document without flashcards is lagging")
+	cmd.Flags().BoolVar(&lagging, "lagging", false, "Only list lagging documents")
+	out.AddOutputFlags(cmd, output.OutputTable)
+
+	return cmd
+}
+
 func newFlashcardExportCmd(store library.LibraryStore) *cobra.Command {
 	var (
 		format   string
-		output   string
+		outFile  string
 		deckName string
 		dueOnly  bool
 		docID    string
+		tag      string
 	)
 
 	cmd := &cobra.Command{
 		Use:   "export",
-		Short: "Export flashcards to Anki or other formats",
-		Long:  "Export flashcards as .apkg files for Anki or other formats",
+		Short: "Export flashcards to Anki, CSV/TSV, or Markdown",
+		Long: `Export flashcards as an Anki .apkg package, as a CSV or TSV table for
+spreadsheets and other flashcard apps, or as a Markdown list of questions
+and answers grouped by document, for sharing or embedding in notes.
+
+The table has the columns front, back, type, tags, document_id, document,
+due, interval, ease, and id, with a header row. Cloze cards show their
+cloze text as the front.
+
+The output file defaults to flashcards.apkg, .csv, .tsv, or .md by
+format; "-" writes CSV, TSV, and Markdown to stdout.
+
+Examples:
+  arc-library flashcard export
+  arc-library flashcard export --format csv --tag exam -o exam.csv
+  arc-library flashcard export --format md --document <doc-id> -o -`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if format != "anki" {
-				return fmt.Errorf("unsupported format: %s (only 'anki' supported)", format)
+			ext, ok := map[string]string{"anki": "apkg", "csv": "csv", "tsv": "tsv", "md": "md", "markdown": "md"}[format]
+			if !ok {
+				return fmt.Errorf("unsupported format: %s (use anki, csv, tsv, or md)", format)
 			}
-
-			// Get cards to export
-			opts := &library.FlashcardListOptions{}
-			if dueOnly {
-				opts.Due = true
+			if !cmd.Flags().Changed("output") {
+				outFile = "flashcards." + ext
 			}
-			if docID != "" {
-				opts.DocumentID = docID
+			if outFile == "-" && format == "anki" {
+				return fmt.Errorf("anki packages cannot be written to stdout")
 			}
 
-			var cards []*library.Flashcard
-			var err error
-
-			if dueOnly {
-				cards, err = store.GetDueFlashcards(time.Now())
-			} else {
-				cards, err = store.ListFlashcards(opts)
-			}
+			// Get cards to export
+			opts := &library.FlashcardListOptions{DocumentID: docID, Tag: tag, Due: dueOnly}
+			cards, err := store.ListFlashcards(opts)
 			if err != nil {
 				return fmt.Errorf("list flashcards: %w", err)
 			}
 
+			result := flashcardExportResult{Format: format, Cards: len(cards)}
+			if format == "anki" {
+				result.Deck = deckName
+			}
 			if len(cards) == 0 {
-				fmt.Println("No flashcards to export")
+				infoln("No flashcards to export")
+				if jsonOutput(nil) {
+					return output.JSON(result)
+				}
 				return nil
 			}
 
-			// Determine output file
-			if output == "" {
-				output = "flashcards.apkg"
+			write := func(w io.Writer) error {
+				switch ext {
+				case "csv":
+					return library.ExportFlashcardsCSV(w, store, cards, ',')
+				case "tsv":
+					return library.ExportFlashcardsCSV(w, store, cards, '\t')
+				case "md":
+					return library.ExportFlashcardsMarkdown(w, store, cards)
+				}
+				return library.NewAnkiExporter(deckName).ExportCards(cards, w)
 			}
 
-			// Create exporter
-			exporter := library.NewAnkiExporter(deckName)
+			if outFile == "-" {
+				if err := write(os.Stdout); err != nil {
+					return fmt.Errorf("export cards: %w", err)
+				}
+				return nil
+			}
 
 			// Create output file
-			file, err := os.Create(output)
+			file, err := os.Create(outFile)
 			if err != nil {
 				return fmt.Errorf("create output file: %w", err)
 			}
 			defer file.Close()
 
 			// Export
-			if err := exporter.ExportCards(cards, file); err != nil {
+			if err := write(file); err != nil {
 				return fmt.Errorf("export cards: %w", err)
 			}
 
-			fmt.Printf("Exported %d flashcards to %s\n", len(cards), output)
-			fmt.Printf("Deck name: %s\n", deckName)
-			fmt.Println("\nImport into Anki:")
-			fmt.Println("1. Open Anki")
-			fmt.Println("2. File > Import")
-			fmt.Printf("3. Select: %s\n", output)
+			result.File = outFile
+			if jsonOutput(nil) {
+				return output.JSON(result)
+			}
+
+			infof("Exported %d flashcards to %s\n", len(cards), outFile)
+			if format != "anki" {
+				return nil
+			}
+			infof("Deck name: %s\n", deckName)
+			infoln("\nImport into Anki:")
+			infoln("1. Open Anki")
+			infoln("2. File > Import")
+			infof("3. Select: %s\n", outFile)
 
 			return nil
 		},
 	}
 
-	cmd.Flags().StringVarP(&format, "format", "f", "anki", "Export format (anki)")
-	cmd.Flags().StringVarP(&output, "output", "o", "flashcards.apkg", "Output file")
+	cmd.Flags().StringVarP(&format, "format", "f", "anki", "Export format: anki, csv, tsv, or md")
+	cmd.Flags().StringVarP(&outFile, "output", "o", "flashcards.apkg", "Output file (default: flashcards.<format extension>; - for stdout)")
 	cmd.Flags().StringVarP(&deckName, "deck", "d", "Arc Library", "Anki deck name")
 	cmd.Flags().BoolVar(&dueOnly, "due", false, "Export only due cards")
 	cmd.Flags().StringVar(&docID, "document", "", "Export cards for specific document only")
+	cmd.Flags().StringVarP(&tag, "tag", "t", "", "Export cards with this tag only")
+	cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"anki", "csv", "tsv", "md"}, cobra.ShellCompDirectiveNoFileComp))
 
 	return cmd
 }
+
+// flashcardExportResult is the JSON schema for "flashcard export".
+type flashcardExportResult struct {
+	Format string `json:"format"`
+	File   string `json:"file,omitempty"`
+	Deck   string `json:"deck,omitempty"` // anki only
+	Cards  int    `json:"cards"`
+}
+
+func newFlashcardImportCmd(store library.LibraryStore) *cobra.Command {
+	var (
+		docID string
+		tags  []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "import <file.apkg>",
+		Short: "Import flashcards and their review history from Anki",
+		Long: `Import the cards of an Anki .apkg package with their interval, ease,
+and due date, and each card's review log, so scheduling and statistics
+continue where Anki left off. Anki's answer buttons become SM-2 quality
+scores: Again 1, Hard 3, Good 4, Easy 5. Manual reschedules are not
+reviews and are left out.
+
+Cards whose front and back are already in the library are skipped, so a
+package can be imported again after adding to the deck. Packages from
+recent Anki versions must be exported with "support older Anki versions".
+
+Examples:
+  arc-library flashcard import deck.apkg
+  arc-library flashcard import deck.apkg --document <doc-id> --tags anki`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if docID != "" {
+				doc, err := store.GetDocument(docID)
+				if err != nil {
+					return err
+				}
+				if doc == nil {
+					return fmt.Errorf("%w: %s", library.ErrDocumentNotFound, docID)
+				}
+			}
+
+			res, err := library.ImportAnki(store, args[0], library.AnkiImportOptions{DocumentID: docID, Tags: tags})
+			if err != nil {
+				return fmt.Errorf("import anki: %w", err)
+			}
+
+			if jsonOutput(nil) {
+				return output.JSON(res)
+			}
+			infof("Imported %d flashcard(s) with %d review(s)", res.Cards, res.Reviews)
+			if res.Skipped > 0 {
+				infof("; skipped %d already in the library", res.Skipped)
+			}
+			infoln()
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&docID, "document", "d", "", "Document the cards belong to")
+	cmd.Flags().StringSliceVar(&tags, "tags", nil, "Tags to add to every card")
+
+	return cmd
+}
+
+func newFlashcardSettingsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "settings",
+		Short: "Show the spaced repetition settings reviews are scheduled with",
+		Long: `Show the SM-2 settings flashcard and document reviews are scheduled
+with. They follow published SM-2 unless changed with these environment
+variables:
+
+  ARC_LIBRARY_SRS_INITIAL_EASE       Ease of a new card (2.5)
+  ARC_LIBRARY_SRS_MIN_EASE           Lowest ease (1.3)
+  ARC_LIBRARY_SRS_MAX_EASE           Highest ease (none)
+  ARC_LIBRARY_SRS_STEPS              Days to the first reviews and those after a lapse (1,6)
+  ARC_LIBRARY_SRS_INTERVAL_MODIFIER  Multiplies later intervals (1)
+  ARC_LIBRARY_SRS_FUZZ               Spreads later intervals by up to this fraction (0)
+
+Each flashcard review records the settings it was scheduled with, in the
+form shown as "params".`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := scheduler.Current()
+			if jsonOutput(nil) {
+				return output.JSON(flashcardSettingsResult{Config: cfg, Params: cfg.String()})
+			}
+			if quietOutput() {
+				fmt.Println(cfg.String())
+				return nil
+			}
+
+			maxEase := "none"
+			if cfg.MaxEase > 0 {
+				maxEase = fmt.Sprintf("%g", cfg.MaxEase)
+			}
+			steps := make([]string, len(cfg.LearnSteps))
+			for i, s := range cfg.LearnSteps {
+				steps[i] = fmt.Sprintf("%dd", s)
+			}
+			fmt.Printf("Initial ease:       %g\n", cfg.InitialEase)
+			fmt.Printf("Ease range:         %g - %s\n", cfg.MinEase, maxEase)
+			fmt.Printf("Learning steps:     %s\n", strings.Join(steps, ", "))
+			fmt.Printf("Interval modifier:  %g\n", cfg.IntervalModifier)
+			fmt.Printf("Fuzz:               ±%g%%\n", cfg.Fuzz*100)
+			fmt.Printf("Params:             %s\n", cfg.String())
+			return nil
+		},
+	}
+}
+
+// flashcardSettingsResult is the JSON schema for "flashcard settings".
+type flashcardSettingsResult struct {
+	scheduler.Config
+	Params string `json:"params"`
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"strings"
+
+	"github.com/mtreilly/arc-library/internal/library"
+	"github.com/spf13/cobra"
+	"github.com/yourorg/arc-sdk/config"
+	"github.com/yourorg/arc-sdk/output"
+)
+
+func newFormatsCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "formats",
+		Short: "Show the import and export formats",
+		Long: `Show the formats "import" reads and "export" writes.
+
+Capabilities:
+  tags         carries the documents' tags
+  files        refers to the documents' local files
+  annotations  includes notes and annotations
+  all-fields   every document field, lossless
+  graph        documents and their connections; takes --edges
+  directory    imports a directory as one document
+
+Mappings in the import mappings file (ARC_LIBRARY_IMPORT_MAPPINGS) are
+listed as importers under their names.
+
+Examples:
+  arc-library formats list
+  arc-library formats list --json`,
+	}
+
+	cmd.AddCommand(newFormatsListCmd())
+
+	return cmd
+}
+
+// formatsResult is the JSON schema for "formats list".
+type formatsResult struct {
+	Importers []library.Format `json:"importers"`
+	Exporters []library.Format `json:"exporters"`
+}
+
+func newFormatsListCmd() *cobra.Command {
+	var out output.OutputOptions
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the available importers and exporters",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := out.Resolve(); err != nil {
+				return err
+			}
+			if err := registerImportMappings(); err != nil {
+				return err
+			}
+
+			result := formatsResult{Importers: []library.Format{}, Exporters: []library.Format{}}
+			for _, i := range library.Importers() {
+				result.Importers = append(result.Importers, i.Format())
+			}
+			for _, e := range library.Exporters() {
+				result.Exporters = append(result.Exporters, e.Format())
+			}
+
+			if jsonOutput(&out) {
+				return output.JSON(result)
+			}
+			if quietOutput() {
+				for _, f := range result.Importers {
+					printIDs("import:" + f.Name)
+				}
+				for _, f := range result.Exporters {
+					printIDs("export:" + f.Name)
+				}
+				return nil
+			}
+
+			table := output.NewTable("Kind", "Name", "Extensions", "Capabilities", "Description")
+			add := func(kind string, formats []library.Format) {
+				for _, f := range formats {
+					table.AddRow(kind, f.Name, strings.Join(f.Extensions, " "), strings.Join(f.Capabilities, ", "), f.Description)
+				}
+			}
+			add("import", result.Importers)
+			add("export", result.Exporters)
+			table.Render()
+			return nil
+		},
+	}
+
+	out.AddOutputFlags(cmd, output.OutputTable)
+
+	return cmd
+}
@@ -4,15 +4,18 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/spf13/cobra"
 	"github.com/mtreilly/arc-library/internal/library"
 	"github.com/yourorg/arc-sdk/config"
-	"gopkg.in/yaml.v3"
+	"github.com/yourorg/arc-sdk/output"
 )
 
 func newImportCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command {
@@ -29,69 +32,153 @@ func newImportCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command
 		titleFlag   string
 		authorsFlag string
 		abstractFlag string
+		idFlag      string
+		grobidURL   string
+		extract     extractFlags
+		verifyTitle bool
 	)
 
 	cmd := &cobra.Command{
-		Use:   "import <path>",
+		Use:   "import [path]",
 		Short: "Import documents into the library",
 		Long: `Import documents from the filesystem into the library database.
 
 Supported sources:
-- Directory with meta.yaml (as created by arc-arxiv)
+- Directory with meta.yaml (as created by arc-arxiv), or with another
+  tool's metadata file described in the import mappings file
+  (ARC_LIBRARY_IMPORT_MAPPINGS, default
+  ~/.config/arc-library/import-mappings.yaml)
 - PDF file(s) with optional metadata flags
+- RIS file from Zotero, EndNote, or Mendeley (or "export --format ris"):
+  each record becomes a document of the record's type, skipping those
+  whose DOI or arXiv ID is already in the library
+- URL: PDFs are downloaded into the library root (or ~/.local/share/arc/files),
+  arXiv abstract pages fetch the paper, other pages become articles
+- Identifier (--id): a DOI, PMID, PMC ID, bioRxiv/medRxiv DOI, arXiv ID, or
+  ISBN, resolved through Crossref, PubMed, the bioRxiv or arXiv API, or Open
+  Library by its format (see also "add"). Without a path
+  the document is metadata only and tagged no-file; with a PDF it fills in
+  the PDF's metadata
+
+With --grobid (or ARC_LIBRARY_GROBID_URL), PDFs are sent to a GROBID server
+for their title, authors and affiliations, abstract, DOI, year, journal,
+and references, in place of the file name. --title, --authors, --abstract,
+and metadata resolved from --id or --doi take precedence. See
+"doc references" for the references.
+
+Without --type the document type is detected: ISBNs are books, YouTube and
+Vimeo links videos, GitHub and GitLab links repos, DOIs with a journal
+papers, and other web pages articles; anything else is a paper. Rules in
+ARC_LIBRARY_TYPE_RULES, such as "host:nature.com=paper,ext:.djvu=book",
+are tried first.
+
+The pre_import and post_import hooks in the hooks file (ARC_LIBRARY_HOOKS,
+default ~/.config/arc-library/hooks.yaml) run around storing each document.
 
 Examples:
   arc-library import ~/papers/2304.00067                    # Import meta directory
   arc-library import ~/papers/paper.pdf --title "My Paper" # Import single PDF
   arc-library import ~/papers --tag ml --collection proj    # Import all meta dirs with tags
-  arc-library import ~/papers --recursive --extract-text   # Import all PDFs with full text`,
-		Args: cobra.ExactArgs(1),
+  arc-library import ~/papers --recursive --extract-text   # Import all PDFs with full text
+  arc-library import https://arxiv.org/abs/2304.00067      # Download a paper
+  arc-library import ~/Downloads/zotero.ris --tag zotero   # Import references
+  arc-library import --id PMID:23193287                    # Metadata from PubMed
+  arc-library import paper.pdf --id 10.1101/2020.03.01.972935
+  arc-library import ~/papers --grobid http://localhost:8070
+
+To merge documents from another library's backup, see "import backup".`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			importPath := args[0]
+			if len(args) == 0 && idFlag == "" {
+				return fmt.Errorf("requires a path, a URL, or --id")
+			}
+			if err := extract.apply(); err != nil {
+				return err
+			}
+			if idFlag != "" && doiFlag != "" {
+				return fmt.Errorf("--id and --doi are mutually exclusive")
+			}
+			rules, err := typeRules()
+			if err != nil {
+				return err
+			}
+			if err := registerImportMappings(); err != nil {
+				return err
+			}
+			hooks, err := loadHooks()
+			if err != nil {
+				return err
+			}
+
+			// Resolve the identifier up front; it applies to a single document
+			var idSource, id string
+			var idMeta library.JSONMap
+			if idFlag != "" {
+				var err error
+				if idSource, id, err = library.ParseIdentifier(idFlag); err != nil {
+					return err
+				}
+				if existing, _ := store.GetDocumentBySourceID(idSource, id); existing != nil && len(args) == 0 {
+					return fmt.Errorf("%s %s is already in the library: %s", idSource, id, existing.ID)
+				}
+				infof("Resolving %s %s...\n", idSource, id)
+				if idMeta, err = library.ResolveIdentifier(idSource, id); err != nil {
+					return err
+				}
+			}
 
-			// Expand ~ to home directory
-			if strings.HasPrefix(importPath, "~") {
-				home, _ := os.UserHomeDir()
-				importPath = filepath.Join(home, importPath[1:])
+			importPath := ""
+			if len(args) > 0 {
+				importPath = args[0]
 			}
+			isURL := strings.HasPrefix(importPath, "http://") || strings.HasPrefix(importPath, "https://")
 
-			info, err := os.Stat(importPath)
-			if err != nil {
-				return fmt.Errorf("path not found: %s", importPath)
+			var info os.FileInfo
+			if !isURL && importPath != "" {
+				var err error
+				info, err = os.Stat(importPath)
+				if err != nil {
+					return fmt.Errorf("path not found: %s", importPath)
+				}
 			}
 
-			// Determine import mode
+			// Determine import mode: a URL or identifier, or files an
+			// importer reads (see "formats list")
 			var pathsToImport []string
-			var isPDFImport bool
-
-			if info.IsDir() {
-				// Check if this looks like a meta directory (has meta.yaml)
-				metaPath := filepath.Join(importPath, "meta.yaml")
-				if _, err := os.Stat(metaPath); err == nil {
-					// Single document directory
-					pathsToImport = []string{importPath}
-				} else {
-					// Scan directory for PDF files (non-recursive)
-					entries, err := os.ReadDir(importPath)
+			fileImporters := map[string]library.Importer{}
+
+			if importPath == "" || isURL {
+				pathsToImport = []string{importPath}
+			} else if imp := library.DetectImporter(importPath, info); imp != nil {
+				pathsToImport = []string{importPath}
+				fileImporters[importPath] = imp
+			} else if info.IsDir() {
+				// Scan the directory for importable files and metadata
+				// directories (non-recursive)
+				entries, err := os.ReadDir(importPath)
+				if err != nil {
+					return err
+				}
+				for _, e := range entries {
+					fi, err := e.Info()
 					if err != nil {
-						return err
-					}
-					for _, e := range entries {
-						if !e.IsDir() && strings.EqualFold(filepath.Ext(e.Name()), ".pdf") {
-							pathsToImport = append(pathsToImport, filepath.Join(importPath, e.Name()))
-						}
+						continue
 					}
-					if len(pathsToImport) == 0 {
-						return fmt.Errorf("no meta.yaml or PDF files found in %s", importPath)
+					p := filepath.Join(importPath, e.Name())
+					if imp := library.DetectImporter(p, fi); imp != nil {
+						pathsToImport = append(pathsToImport, p)
+						fileImporters[p] = imp
 					}
-					isPDFImport = true
 				}
-			} else if strings.EqualFold(filepath.Ext(importPath), ".pdf") {
-				// Single PDF file
-				pathsToImport = []string{importPath}
-				isPDFImport = true
+				if len(pathsToImport) == 0 {
+					return fmt.Errorf("no metadata directories or %s files found in %s", strings.Join(library.ImportExtensions(), ", "), importPath)
+				}
 			} else {
-				return fmt.Errorf("unsupported file type: %s (expected directory or .pdf)", importPath)
+				return fmt.Errorf("unsupported file type: %s (expected a directory or %s)", importPath, strings.Join(library.ImportExtensions(), ", "))
+			}
+
+			if idFlag != "" && len(pathsToImport) > 1 {
+				return fmt.Errorf("--id applies to a single document, but %s has %d files", importPath, len(pathsToImport))
 			}
 
 			// Get or create collection if specified
@@ -106,136 +193,257 @@ Examples:
 					if err != nil {
 						return fmt.Errorf("create collection: %w", err)
 					}
-					fmt.Printf("Created collection: %s\n", collection)
+					if !dryRun() {
+						infof("Created collection: %s\n", collection)
+					}
 				}
 				collectionID = c.ID
 			}
 
-			imported := 0
-			skipped := 0
+			result := importResult{
+				Imported: []*library.Document{},
+				Skipped:  []string{},
+				Failed:   []importFailure{},
+			}
 
-			for _, path := range pathsToImport {
-				// Check if already imported
-				existing, _ := store.GetDocumentByPath(path)
-				if existing != nil {
-					skipped++
-					continue
-				}
+			var grobid *library.GrobidClient
+			if grobidURL != "" {
+				grobid = library.NewGrobidClient(grobidURL)
+			}
 
-				var doc *library.Document
+			root := library.LibraryRoot()
 
-				if isPDFImport {
-					// PDF import
-					title := titleFlag
-					if title == "" {
-						// Use filename as title
-						title = filepath.Base(path)
-						title = strings.TrimSuffix(title, filepath.Ext(title))
+			// PDFs have their text extracted several at a time, ahead of
+			// the imports; files in the library already are skipped
+			var pdfs []string
+			if extractText {
+				for _, p := range pathsToImport {
+					if imp := fileImporters[p]; imp == nil || !strings.EqualFold(filepath.Ext(p), ".pdf") {
+						continue
 					}
+					if existing, _ := store.GetDocumentByPath(library.StoredPath(p, root)); existing == nil {
+						pdfs = append(pdfs, library.ResolvePath(library.StoredPath(p, root), root))
+					}
+				}
+			}
+			// Stop extracting ahead once done, as on an error
+			ctx, cancel := context.WithCancel(cmd.Context())
+			defer cancel()
+			extracted := library.DefaultTextExtractor().ExtractAll(ctx, pdfs)
+
+			bar := newProgress("Importing", len(pathsToImport))
+			defer bar.finish()
+			for i, path := range pathsToImport {
+				bar.set(i, filepath.Base(path))
+				// Check if already imported
+				if !isURL && path != "" {
+					existing, _ := store.GetDocumentByPath(library.StoredPath(path, root))
+					if existing != nil {
+						result.Skipped = append(result.Skipped, path)
+						continue
+					}
+				}
 
-					authors := strings.Split(authorsFlag, ",")
-					for i, a := range authors {
-						authors[i] = strings.TrimSpace(a)
+				var docs []*library.Document
+				if path == "" {
+					// Metadata only, from --id
+					docs = []*library.Document{{Tags: append([]string{library.NoFileTag}, tags...)}}
+				} else if isURL {
+					if dryRun() {
+						// Fetching saves the file; its metadata comes with it
+						infof("Would fetch: %s\n", path)
+						continue
+					}
+					var doc *library.Document
+					dir, err := library.FilesDir()
+					if err == nil {
+						infof("  Fetching %s...\n", path)
+						doc, err = library.ImportURL(path, dir)
+					}
+					if err != nil {
+						warnf("  Warning: could not import %s: %v\n", path, err)
+						result.Failed = append(result.Failed, importFailure{Path: path, Error: err.Error()})
+						continue
+					}
+					doc.Path = library.StoredPath(doc.Path, root)
+					doc.Tags = tags
+					if titleFlag != "" {
+						doc.Title = titleFlag
+					}
+					if authorsFlag != "" {
+						doc.Authors = splitAuthors(authorsFlag)
+					}
+					if abstractFlag != "" {
+						doc.Abstract = abstractFlag
+					}
+					if extractText && doc.Path != "" {
+						if err := applyExtraction(doc, extracted(library.DocumentPath(doc))); err != nil {
+							warnf("    Warning: text extraction failed: %v\n", err)
+						}
+					}
+					docs = []*library.Document{doc}
+				} else {
+					imported, err := fileImporters[path].Import(path)
+					if err != nil {
+						warnf("  Warning: could not import %s: %v\n", path, err)
+						result.Failed = append(result.Failed, importFailure{Path: path, Error: err.Error()})
+						continue
 					}
-					if len(authors) == 0 {
-						authors = []string{}
+					for _, doc := range imported {
+						if doc.Path != "" {
+							doc.Path = library.StoredPath(doc.Path, root)
+						}
+						doc.Tags = append(doc.Tags, tags...)
+						// A mapping may point into the directory at a file already
+						// imported, and a RIS record at a document already in the library
+						if doc.Path != "" {
+							if existing, _ := store.GetDocumentByPath(doc.Path); existing != nil {
+								result.Skipped = append(result.Skipped, library.DocumentPath(doc))
+								continue
+							}
+						}
+						if doc.SourceID != "" {
+							if existing, _ := store.GetDocumentBySourceID(doc.Source, doc.SourceID); existing != nil {
+								result.Skipped = append(result.Skipped, doc.Source+":"+doc.SourceID)
+								continue
+							}
+						}
+						docs = append(docs, doc)
 					}
+				}
 
-					doc = &library.Document{
-						Path:   path,
-						Source: sourceFlag,
-						Title:  title,
-						Authors: authors,
-						Abstract: abstractFlag,
-						Tags:   tags,
-						Type:   library.DocTypePaper, // default
+				for _, doc := range docs {
+					doiResolved := false
+
+					// PDFs take their metadata from the flags, the page, or a DOI;
+					// directory importers and reference records read theirs from
+					// the metadata file
+					imp := fileImporters[path]
+					fromMetadata := imp != nil && (imp.Format().Has(library.FormatDirectory) || imp.Format().Has(library.FormatMetadata))
+					if imp != nil && !fromMetadata && strings.EqualFold(filepath.Ext(doc.Path), ".pdf") {
+						if sourceFlag != "" {
+							doc.Source = sourceFlag
+						}
+						if titleFlag != "" {
+							doc.Title = titleFlag
+						}
+						if authorsFlag != "" {
+							doc.Authors = splitAuthors(authorsFlag)
+						}
+						if abstractFlag != "" {
+							doc.Abstract = abstractFlag
+						}
+
+						// If extractText flag, try to extract full text
+						if extractText {
+							infof("  Extracting text from %s...\n", filepath.Base(doc.Path))
+							if err := applyExtraction(doc, extracted(library.DocumentPath(doc))); err != nil {
+								warnf("    Warning: text extraction failed: %v\n", err)
+							}
+						}
+
+						// Without other metadata, read the title from the page
+						if titleFlag == "" && doiFlag == "" && idFlag == "" && grobid == nil {
+							applyPDFHeading(doc, library.DocumentPath(doc), doc.FullText, verifyTitle, true, authorsFlag == "", abstractFlag == "")
+						}
+
+						// A DOI given or, with --resolve-doi, found in the text or file name
+						if doiFlag != "" {
+							doc.Source = library.IDSourceDOI
+							doc.SourceID = strings.TrimPrefix(doiFlag, "doi:")
+						}
+						if resolveDOI && idFlag == "" {
+							doiResolved = resolveDocumentMetadata(doc, doiFlag, library.DocumentPath(doc), titleFlag == "", authorsFlag == "", abstractFlag == "")
+						}
 					}
 
-					// If extractText flag, try to extract full text
-					if extractText {
-						fmt.Printf("  Extracting text from %s...\n", filepath.Base(path))
-						text, err := library.PDFTextExtractor(path)
-						if err != nil {
-							fmt.Printf("    Warning: text extraction failed: %v\n", err)
+					var parsed *library.GrobidResult
+					if grobid != nil && !fromMetadata && strings.EqualFold(filepath.Ext(doc.Path), ".pdf") {
+						infof("  Parsing %s with GROBID...\n", filepath.Base(doc.Path))
+						var err error
+						if parsed, err = grobid.ProcessPDF(library.DocumentPath(doc)); err != nil {
+							warnf("    Warning: GROBID failed: %v\n", err)
 						} else {
-							doc.FullText = text
+							parsed.Apply(doc, titleFlag == "", authorsFlag == "" && !doiResolved, abstractFlag == "" && !doiResolved)
 						}
 					}
 
-					// If DOI provided, resolve metadata
-					if doiFlag != "" {
-						doc.Source = "doi"
-						doc.SourceID = strings.TrimPrefix(doiFlag, "doi:")
-						if resolveDOI {
-							fmt.Printf("  Resolving DOI %s...\n", doc.SourceID)
-							meta, err := library.DOIResolver(doc.SourceID)
-							if err != nil {
-								fmt.Printf("    Warning: DOI resolution failed: %v\n", err)
-							} else {
-								// Override/merge metadata from DOI
-								if doc.Title == "" {
-									if t, ok := meta["title"].(string); ok {
-										doc.Title = t
-									}
-								}
-								if len(doc.Authors) == 0 {
-									if a, ok := meta["authors"].([]string); ok {
-										doc.Authors = a
-									}
-								}
-								if doc.Abstract == "" {
-									if a, ok := meta["abstract"].(string); ok {
-										doc.Abstract = a
-									}
-								}
-								// Could also set year, journal etc. in Meta
-								doc.Meta = meta
-							}
+					if idMeta != nil {
+						applyIdentifierMeta(doc, idSource, id, idMeta, titleFlag == "", authorsFlag == "", abstractFlag == "")
+					}
+
+					// Set type if specified, else keep a reference record's type or
+					// detect it
+					recordTyped := imp != nil && imp.Format().Has(library.FormatMetadata) && doc.Type != ""
+					if docType != "" {
+						doc.Type = library.DocumentType(docType)
+					} else if t, ok := library.DetectDocumentType(doc, rules); ok && !recordTyped {
+						doc.Type = t
+					} else if doc.Type == "" {
+						doc.Type = library.DocTypePaper
+					}
+
+					// The language picks the search tokenizer the document is indexed with
+					library.DetectDocumentLanguage(doc, false)
+
+					// Hooks are scripts with effects of their own, so a dry run
+					// skips them
+					if !dryRun() {
+						if err := hooks.PreImport(doc, os.Stderr); err != nil {
+							warnf("  Skipped %s: %v\n", path, err)
+							result.Failed = append(result.Failed, importFailure{Path: path, Error: err.Error()})
+							continue
 						}
 					}
 
-				} else {
-					// Meta directory import (existing behavior)
-					metaPath := filepath.Join(path, "meta.yaml")
-					meta, err := readArxivMeta(metaPath)
-					if err != nil {
-						fmt.Printf("  Warning: could not read %s: %v\n", metaPath, err)
+					if err := store.AddDocument(doc); err != nil {
+						warnf("  Warning: could not import %s: %v\n", path, err)
+						result.Failed = append(result.Failed, importFailure{Path: path, Error: err.Error()})
 						continue
 					}
 
-					doc = &library.Document{
-						Path:     path,
-						Source:   meta.SourceType,
-						SourceID: meta.ArxivID,
-						Title:    meta.Title,
-						Authors:  extractAuthorNames(meta.Authors),
-						Abstract: meta.Abstract,
-						Tags:     tags,
+					if parsed != nil {
+						if err := store.SaveDocumentReferences(parsed.DocumentReferences(doc)); err != nil {
+							warnf("    Warning: could not save references: %v\n", err)
+						}
+					}
+
+					// Add to collection if specified
+					if collectionID != "" {
+						store.AddToCollection(collectionID, doc.ID)
 					}
-				}
 
-				// Set type if specified
-				if docType != "" {
-					doc.Type = library.DocumentType(docType)
-				} else if doc.Type == "" {
-					doc.Type = library.DocTypePaper
-				}
+					if !dryRun() {
+						if err := hooks.PostImport(doc, os.Stderr); err != nil {
+							warnf("    Warning: %v\n", err)
+						}
+					}
 
-				if err := store.AddDocument(doc); err != nil {
-					fmt.Printf("  Warning: could not import %s: %v\n", path, err)
-					continue
+					if dryRun() {
+						infof("Would import: %s - %s\n", doc.SourceID, truncate(doc.Title, 50))
+					} else {
+						infof("Imported: %s - %s\n", doc.SourceID, truncate(doc.Title, 50))
+					}
+					result.Imported = append(result.Imported, doc)
 				}
+			}
 
-				// Add to collection if specified
-				if collectionID != "" {
-					store.AddToCollection(collectionID, doc.ID)
-				}
+			bar.set(len(pathsToImport), "")
+			bar.finish()
 
-				fmt.Printf("Imported: %s - %s\n", doc.SourceID, truncate(doc.Title, 50))
-				imported++
+			if jsonOutput(nil) {
+				return output.JSON(result)
+			}
+			if quietOutput() {
+				printIDs(documentIDs(result.Imported)...)
+				return nil
 			}
 
-			fmt.Printf("\nImported %d document(s), skipped %d already in library.\n", imported, skipped)
+			if dryRun() {
+				fmt.Printf("\nWould import %d document(s), skip %d already in library.\n", len(result.Imported), len(result.Skipped))
+				return nil
+			}
+			fmt.Printf("\nImported %d document(s), skipped %d already in library.\n", len(result.Imported), len(result.Skipped))
 			return nil
 		},
 	}
@@ -245,48 +453,236 @@ Examples:
 
 	// PDF import specific flags
 	cmd.Flags().BoolVarP(&extractText, "extract-text", "e", false, "Extract full text from PDFs (requires pdftotext)")
-	cmd.Flags().BoolVarP(&resolveDOI, "resolve-doi", "r", false, "Resolve DOI metadata (Crossref)")
+	extract.addFlags(cmd)
+	cmd.Flags().BoolVarP(&resolveDOI, "resolve-doi", "r", false, "Resolve metadata from --doi, or from a DOI or arXiv ID found in the PDF's text or file name")
 	cmd.Flags().StringVar(&doiFlag, "doi", "", "DOI to assign to the document (e.g., 10.1234/5678)")
-	cmd.Flags().StringVar(&docType, "type", "", "Document type (paper, book, article, video, note, repo, other)")
+	cmd.Flags().StringVar(&docType, "type", "", "Document type (paper, book, article, video, note, repo, other; default: detected)")
 	cmd.Flags().StringVar(&sourceFlag, "source", "", "Source identifier (e.g., local, arxiv, url)")
-	cmd.Flags().StringVar(&titleFlag, "title", "", "Title for PDF import (default: filename)")
+	cmd.Flags().StringVar(&titleFlag, "title", "", "Title for PDF import (default: read from the first page, else the filename)")
+	cmd.Flags().BoolVar(&verifyTitle, "verify-title", true, "Look titles read from PDFs up on Crossref and arXiv and use the matching work's metadata")
 	cmd.Flags().StringVar(&authorsFlag, "authors", "", "Comma-separated list of authors")
 	cmd.Flags().StringVar(&abstractFlag, "abstract", "", "Abstract or summary")
+	cmd.Flags().StringVar(&grobidURL, "grobid", os.Getenv("ARC_LIBRARY_GROBID_URL"), "GROBID server URL to extract PDF metadata and references with")
+	cmd.Flags().StringVar(&idFlag, "id", "", "DOI, PMID, PMC ID, bioRxiv DOI, arXiv ID, or ISBN to resolve metadata from")
+
+	cmd.AddCommand(newImportBackupCmd(store))
 
 	return cmd
 }
 
-// arxivMeta matches the structure from arc-arxiv
-type arxivMeta struct {
-	ID         string       `yaml:"id"`
-	ArxivID    string       `yaml:"arxiv_id"`
-	Title      string       `yaml:"title"`
-	SourceType string       `yaml:"source_type"`
-	Authors    []arxivAuthor `yaml:"authors"`
-	Abstract   string       `yaml:"abstract"`
+func newImportBackupCmd(store library.LibraryStore) *cobra.Command {
+	var collections, tags []string
+
+	cmd := &cobra.Command{
+		Use:   "backup <archive>",
+		Short: "Merge documents from another library's backup",
+		Long: `Merge documents from a backup of another library, written there by
+"export --format backup", into this one, with their annotations,
+flashcards, collection memberships, and the links between them.
+
+--collection and --tag pick the backup's documents in those collections or
+with those tags; with both, a document must match each. Imported documents
+get new IDs here, and their annotations, flashcards, and links are mapped
+to them. Documents already in the library, by source ID, DOI, or identical
+full text, are not added again; their annotations and flashcards are
+merged into the library's copy, skipping those it already has. Documents
+join collections of the same name, which are created when missing.
+
+Examples:
+  arc-library import backup laptop.json --collection thesis
+  arc-library import backup lab.json.gz --tag ml --tag nlp
+  arc-library import backup laptop.json --collection thesis --tag ml --json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			b, err := library.ReadLibraryBackup(args[0])
+			if err != nil {
+				return err
+			}
+			opts := library.BackupImportOptions{Collections: collections, Tags: tags}
+			selected, err := library.SelectBackupDocuments(b, opts)
+			if err != nil {
+				return err
+			}
+			bar := newProgress("Importing", len(selected))
+			opts.OnProgress = func(done, total int) { bar.set(done, "") }
+			res, err := library.ImportBackup(store, b, opts)
+			bar.finish()
+			if err != nil {
+				if res != nil {
+					warnf("Stopped after importing %d document(s)\n", len(res.Documents))
+				}
+				return err
+			}
+
+			if jsonOutput(nil) {
+				return output.JSON(res)
+			}
+			if quietOutput() {
+				printIDs(documentIDs(res.Documents)...)
+				return nil
+			}
+			for _, doc := range res.Documents {
+				infof("Imported: %s - %s\n", doc.ID, truncate(doc.Title, 50))
+			}
+			for _, d := range res.Duplicates {
+				infof("Already in library (%s): %s - %s\n", d.Reason, d.DocumentID, truncate(d.Title, 50))
+			}
+			fmt.Printf("\nImported %d document(s), merged %d already in library; added %d annotation(s), %d flashcard(s), %d collection(s), %d link(s).\n",
+				len(res.Documents), len(res.Duplicates), res.Annotations, res.Flashcards, res.Collections, res.Links)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&collections, "collection", "c", nil, "Only documents in this backup collection, by name or ID (can be repeated)")
+	cmd.Flags().StringSliceVarP(&tags, "tag", "t", nil, "Only documents with this tag (can be repeated)")
+
+	return cmd
+}
+
+// importResult is the JSON schema for import-style commands.
+type importResult struct {
+	Imported []*library.Document `json:"imported"`
+	Skipped  []string            `json:"skipped"` // paths already in the library
+	Failed   []importFailure     `json:"failed"`
+}
+
+type importFailure struct {
+	Path  string `json:"path"`
+	Error string `json:"error"`
+}
+
+// applyIdentifierMeta sets a document's source to a resolved identifier and
+// fills in its metadata. The title, authors, and abstract are replaced only
+// where the set flag says no value was given on the command line.
+func applyIdentifierMeta(doc *library.Document, source, id string, meta library.JSONMap, setTitle, setAuthors, setAbstract bool) {
+	doc.Source, doc.SourceID = source, id
+	if t, ok := meta["title"].(string); ok && t != "" && (setTitle || doc.Title == "") {
+		doc.Title = t
+	}
+	if a, ok := meta["authors"].([]string); ok && len(a) > 0 && (setAuthors || len(doc.Authors) == 0) {
+		doc.Authors = a
+	}
+	if a, ok := meta["abstract"].(string); ok && a != "" && (setAbstract || doc.Abstract == "") {
+		doc.Abstract = a
+	}
+	if doc.Meta == nil {
+		doc.Meta = library.JSONMap{}
+	}
+	for k, v := range meta {
+		switch k {
+		case "title", "authors", "abstract":
+		case "doi":
+			// A DOI source already records it
+			if source != library.IDSourceDOI {
+				doc.Meta[k] = v
+			}
+		default:
+			doc.Meta[k] = v
+		}
+	}
 }
 
-type arxivAuthor struct {
-	Name        string `yaml:"name"`
-	Affiliation string `yaml:"affiliation,omitempty"`
+// resolveDocumentMetadata looks a document's identifier up, the given one
+// or one sniffed from its text and file name, and applies the metadata as
+// applyIdentifierMeta does. It reports whether metadata was applied;
+// failures are warnings.
+func resolveDocumentMetadata(doc *library.Document, given, path string, setTitle, setAuthors, setAbstract bool) bool {
+	match, err := library.ResolveMetadata(given, doc.FullText, path)
+	if match == nil {
+		if err != nil {
+			warnf("    Warning: %v\n", err)
+		}
+		return false
+	}
+	infof("  Resolving %s %s (%s, confidence %.1f)...\n", match.Source, match.ID, match.Where, match.Confidence)
+	if err != nil {
+		if !errors.Is(err, library.ErrOffline) {
+			warnf("    Warning: %s lookup failed: %v\n", match.Source, err)
+		}
+		return false
+	}
+	applyIdentifierMeta(doc, match.Source, match.ID, match.Meta, setTitle, setAuthors, setAbstract)
+	return true
 }
 
-func readArxivMeta(path string) (*arxivMeta, error) {
-	data, err := os.ReadFile(path)
+// applyPDFHeading fills in a PDF's title and authors from its first
+// page's layout, or failing that its title from the extracted text. With
+// verify the title is looked up on Crossref and arXiv, and the metadata of
+// a work with the same title is applied as if given with --id. The set
+// flags say which fields no value was given for. Failures leave the
+// document as it was.
+func applyPDFHeading(doc *library.Document, path, text string, verify, setTitle, setAuthors, setAbstract bool) {
+	var title string
+	if heading, err := library.ExtractPDFHeading(path); err == nil && heading.Title != "" {
+		title = heading.Title
+		if setAuthors && len(heading.Authors) > 0 {
+			doc.Authors = heading.Authors
+		}
+	} else {
+		title = library.GuessTitleFromText(text)
+	}
+	if title == "" {
+		return
+	}
+	if setTitle {
+		doc.Title = title
+	}
+	if !verify {
+		return
+	}
+
+	source, id, err := library.MatchTitle(title)
+	if err == nil && source != "" {
+		var meta library.JSONMap
+		if meta, err = library.ResolveIdentifier(source, id); err == nil {
+			infof("    Matched %s %s\n", source, id)
+			applyIdentifierMeta(doc, source, id, meta, setTitle, setAuthors, setAbstract)
+		}
+	}
+	if err != nil && !errors.Is(err, library.ErrOffline) {
+		warnf("    Warning: title lookup failed: %v\n", err)
+	}
+}
+
+// typeRules returns the document type detection rules, with those from
+// ARC_LIBRARY_TYPE_RULES first.
+func typeRules() ([]library.TypeRule, error) {
+	rules, err := library.ParseTypeRules(os.Getenv("ARC_LIBRARY_TYPE_RULES"))
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("ARC_LIBRARY_TYPE_RULES: %w", err)
 	}
-	var meta arxivMeta
-	if err := yaml.Unmarshal(data, &meta); err != nil {
-		return nil, err
+	return rules, nil
+}
+
+// loadHooks reads the scripting hooks from the hooks file (see
+// library.HooksFile); without a config directory there are none.
+func loadHooks() (*library.Hooks, error) {
+	path, err := library.HooksFile()
+	if err != nil {
+		return nil, nil
 	}
-	return &meta, nil
+	return library.LoadHooks(path)
 }
 
-func extractAuthorNames(authors []arxivAuthor) []string {
-	names := make([]string, 0, len(authors))
-	for _, a := range authors {
-		names = append(names, a.Name)
+// registerImportMappings registers the user's metadata mappings (see
+// library.ImportMappingsFile) as importers, once.
+var registerImportMappings = sync.OnceValue(func() error {
+	path, err := library.ImportMappingsFile()
+	if err != nil {
+		return nil
+	}
+	mappings, err := library.LoadMetaMappings(path)
+	if err != nil {
+		return err
+	}
+	return library.RegisterMetaMappings(mappings)
+})
+
+// splitAuthors splits a comma-separated --authors value.
+func splitAuthors(s string) []string {
+	authors := strings.Split(s, ",")
+	for i, a := range authors {
+		authors[i] = strings.TrimSpace(a)
 	}
-	return names
+	return authors
 }
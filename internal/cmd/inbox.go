@@ -0,0 +1,417 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/emersion/go-imap"
+	"github.com/emersion/go-imap/client"
+	"github.com/mtreilly/arc-library/internal/library"
+	"github.com/spf13/cobra"
+	"github.com/yourorg/arc-sdk/config"
+	"github.com/yourorg/arc-sdk/output"
+)
+
+func newInboxCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command {
+	var out output.OutputOptions
+	var weeks int
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "inbox",
+		Short: "Show unread documents that have been waiting too long",
+		Long: `List documents still unread more than N weeks after they were added,
+oldest first, so the reading backlog does not silently grow.
+
+Examples:
+  arc-library inbox             # Unread for more than 2 weeks
+  arc-library inbox --weeks 8   # Unread for more than 8 weeks
+  arc-library inbox email       # Import documents emailed to the library`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := out.Resolve(); err != nil {
+				return err
+			}
+			if weeks < 0 {
+				return fmt.Errorf("--weeks must not be negative")
+			}
+
+			now := time.Now()
+			docs, err := store.ListDocuments(&library.ListOptions{
+				Status:        string(library.StatusUnread),
+				CreatedBefore: now.AddDate(0, 0, -7*weeks),
+			})
+			if err != nil {
+				return err
+			}
+
+			sort.SliceStable(docs, func(i, j int) bool { return docs[i].CreatedAt.Before(docs[j].CreatedAt) })
+			total := len(docs)
+			if limit > 0 && len(docs) > limit {
+				docs = docs[:limit]
+			}
+
+			if jsonOutput(&out) {
+				return output.JSON(nonNil(docs))
+			}
+			if quietOutput() {
+				printIDs(documentIDs(docs)...)
+				return nil
+			}
+
+			if total == 0 {
+				fmt.Printf("Inbox zero: nothing unread for more than %d week(s).\n", weeks)
+				return nil
+			}
+
+			table := output.NewTable("Age", "Added", "Type", "Title")
+			for _, d := range docs {
+				age := int(now.Sub(d.CreatedAt).Hours() / 24)
+				table.AddRow(fmt.Sprintf("%dd", age), d.CreatedAt.Format("2006-01-02"), string(d.Type), truncate(d.Title, 50))
+			}
+			table.Render()
+
+			fmt.Printf("\n%d document(s) unread for more than %d week(s)\n", total, weeks)
+			return nil
+		},
+	}
+
+	out.AddOutputFlags(cmd, output.OutputTable)
+	cmd.Flags().IntVarP(&weeks, "weeks", "w", 2, "Minimum age in weeks")
+	cmd.Flags().IntVarP(&limit, "limit", "n", 0, "Limit number of results")
+
+	cmd.AddCommand(newInboxEmailCmd(store))
+
+	return cmd
+}
+
+// inboxTag is added to every document that arrives by email.
+const inboxTag = "inbox"
+
+func newInboxEmailCmd(store library.LibraryStore) *cobra.Command {
+	var (
+		server      string
+		user        string
+		mailbox     string
+		archive     string
+		interval    time.Duration
+		noTLS       bool
+		extractText bool
+		tags        []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "email",
+		Short: "Import documents emailed to a dedicated mailbox",
+		Long: `Poll an IMAP mailbox set aside as a "send to my library" address. PDF
+attachments are saved and imported; emails without PDFs have their links
+imported like "import <url>". Documents are tagged inbox. Processed emails
+are moved to the archive mailbox; emails with nothing to import are marked
+read and left in place. Emails whose imports all failed, for example while
+another command holds the library lock, stay unread and are tried again
+on the next poll. With --dry-run, nothing is saved or fetched and the
+mailbox is left as it is.
+
+The password is read from ARC_LIBRARY_IMAP_PASSWORD. The server and user
+default to ARC_LIBRARY_IMAP and ARC_LIBRARY_IMAP_USER. Files are saved
+under the library root's inbox/ directory (see "paths"), or
+~/.local/share/arc/files/inbox.
+
+Examples:
+  export ARC_LIBRARY_IMAP_PASSWORD=...
+  arc-library inbox email --imap imap.example.com --user library@example.com
+  arc-library inbox email --interval 5m --extract-text   # Keep polling`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if server == "" {
+				return fmt.Errorf("--imap is required (or set ARC_LIBRARY_IMAP)")
+			}
+			if user == "" {
+				return fmt.Errorf("--user is required (or set ARC_LIBRARY_IMAP_USER)")
+			}
+			password := os.Getenv("ARC_LIBRARY_IMAP_PASSWORD")
+			if password == "" {
+				return fmt.Errorf("ARC_LIBRARY_IMAP_PASSWORD is not set")
+			}
+			if !strings.Contains(server, ":") {
+				if noTLS {
+					server += ":143"
+				} else {
+					server += ":993"
+				}
+			}
+			filesDir, err := library.FilesDir()
+			if err != nil {
+				return err
+			}
+
+			poller := &emailPoller{
+				store:       store,
+				server:      server,
+				user:        user,
+				password:    password,
+				noTLS:       noTLS,
+				mailbox:     mailbox,
+				archive:     archive,
+				dir:         filepath.Join(filesDir, "inbox"),
+				tags:        append([]string{inboxTag}, slices.DeleteFunc(tags, func(t string) bool { return t == inboxTag })...),
+				extractText: extractText,
+			}
+
+			if interval <= 0 {
+				result, err := poller.poll()
+				if err != nil {
+					return err
+				}
+				if jsonOutput(nil) {
+					return output.JSON(result)
+				}
+				if quietOutput() {
+					printIDs(documentIDs(result.Imported)...)
+					return nil
+				}
+				fmt.Printf("Imported %d document(s) from %d email(s).\n", len(result.Imported), result.Messages)
+				return nil
+			}
+
+			infof("Polling %s/%s every %s (Ctrl+C to stop)\n", server, mailbox, interval)
+			for {
+				result, err := poller.poll()
+				if err != nil {
+					warnf("Poll failed: %v\n", err)
+				} else if result.Messages > 0 {
+					infof("Imported %d document(s) from %d email(s)\n", len(result.Imported), result.Messages)
+				}
+				time.Sleep(interval)
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&server, "imap", os.Getenv("ARC_LIBRARY_IMAP"), "IMAP server as host[:port]")
+	cmd.Flags().StringVar(&user, "user", os.Getenv("ARC_LIBRARY_IMAP_USER"), "IMAP user name")
+	cmd.Flags().StringVar(&mailbox, "mailbox", "INBOX", "Mailbox to import from")
+	cmd.Flags().StringVar(&archive, "archive", "Archive", "Mailbox to move processed emails to")
+	cmd.Flags().DurationVar(&interval, "interval", 0, "Keep polling at this interval (default: poll once)")
+	cmd.Flags().BoolVar(&noTLS, "no-tls", false, "Connect without TLS (for local servers)")
+	cmd.Flags().BoolVarP(&extractText, "extract-text", "e", false, "Extract full text from PDFs (requires pdftotext)")
+	cmd.Flags().StringSliceVarP(&tags, "tag", "t", nil, "More tags to apply besides inbox")
+
+	return cmd
+}
+
+// emailResult is the JSON schema for "inbox email".
+type emailResult struct {
+	Messages int                 `json:"messages"` // emails processed
+	Imported []*library.Document `json:"imported"`
+	Failed   []emailFailure      `json:"failed"`
+}
+
+type emailFailure struct {
+	Subject string `json:"subject"`
+	Item    string `json:"item"` // attachment or URL; "" for the whole email
+	Error   string `json:"error"`
+}
+
+// emailPoller imports the unread emails of an IMAP mailbox.
+type emailPoller struct {
+	store       library.LibraryStore
+	server      string
+	user        string
+	password    string
+	noTLS       bool
+	mailbox     string
+	archive     string
+	dir         string // where attachments and downloads are saved
+	tags        []string
+	extractText bool
+}
+
+// poll connects, imports every unread email, marks them read and archives
+// the ones that gave documents. A dry run leaves the mailbox untouched.
+func (p *emailPoller) poll() (*emailResult, error) {
+	var c *client.Client
+	var err error
+	if p.noTLS {
+		c, err = client.Dial(p.server)
+	} else {
+		c, err = client.DialTLS(p.server, nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("connect to %s: %w", p.server, err)
+	}
+	defer c.Logout()
+
+	if err := c.Login(p.user, p.password); err != nil {
+		return nil, fmt.Errorf("login: %w", err)
+	}
+	if _, err := c.Select(p.mailbox, false); err != nil {
+		return nil, fmt.Errorf("select %s: %w", p.mailbox, err)
+	}
+
+	criteria := imap.NewSearchCriteria()
+	criteria.WithoutFlags = []string{imap.SeenFlag}
+	uids, err := c.UidSearch(criteria)
+	if err != nil {
+		return nil, fmt.Errorf("search %s: %w", p.mailbox, err)
+	}
+	result := &emailResult{Imported: []*library.Document{}, Failed: []emailFailure{}}
+	if len(uids) == 0 {
+		return result, nil
+	}
+
+	// Fetch everything first; no other command can run during a fetch
+	set := new(imap.SeqSet)
+	set.AddNum(uids...)
+	section := &imap.BodySectionName{Peek: true}
+	messages := make(chan *imap.Message, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- c.UidFetch(set, []imap.FetchItem{section.FetchItem(), imap.FetchUid}, messages)
+	}()
+	raw := make(map[uint32][]byte)
+	for msg := range messages {
+		if body := msg.GetBody(section); body != nil {
+			raw[msg.Uid], _ = io.ReadAll(body)
+		}
+	}
+	if err := <-done; err != nil {
+		return nil, fmt.Errorf("fetch messages: %w", err)
+	}
+
+	handled, processed := new(imap.SeqSet), new(imap.SeqSet)
+	for _, uid := range uids {
+		data, ok := raw[uid]
+		if !ok {
+			continue
+		}
+		result.Messages++
+		docs, failed, done := p.importEmail(data)
+		result.Imported = append(result.Imported, docs...)
+		result.Failed = append(result.Failed, failed...)
+		if done {
+			handled.AddNum(uid)
+		}
+		if len(docs) > 0 {
+			processed.AddNum(uid)
+		}
+	}
+
+	if dryRun() {
+		// Leave the mailbox as it was, for the import done for real
+		return result, nil
+	}
+
+	// Mark emails read first, so one is never imported twice even if
+	// archiving fails
+	if !handled.Empty() {
+		flags := []interface{}{imap.SeenFlag}
+		if err := c.UidStore(handled, imap.FormatFlagsOp(imap.AddFlags, true), flags, nil); err != nil {
+			return result, fmt.Errorf("mark emails read: %w", err)
+		}
+	}
+	if !processed.Empty() {
+		err := c.UidMove(processed, p.archive)
+		if err != nil && c.Create(p.archive) == nil {
+			// The archive mailbox didn't exist yet
+			err = c.UidMove(processed, p.archive)
+		}
+		if err != nil {
+			warnf("Could not archive emails to %s: %v\n", p.archive, err)
+		}
+	}
+	return result, nil
+}
+
+// importEmail imports the PDFs attached to a raw email, or its links. It
+// reports the email done when it gave documents or never can: one whose
+// imports all failed, say while another process holds the library lock,
+// is left unread to be tried again.
+func (p *emailPoller) importEmail(data []byte) (docs []*library.Document, failed []emailFailure, done bool) {
+	email, err := library.ParseInboxEmail(bytes.NewReader(data))
+	if err != nil {
+		return nil, []emailFailure{{Error: err.Error()}}, true
+	}
+	if len(email.Attachments) == 0 && len(email.Links) == 0 {
+		return nil, []emailFailure{{Subject: email.Subject, Error: "no PDF attachments or links"}}, true
+	}
+
+	fail := func(item string, err error) {
+		warnf("  %s: %v\n", item, err)
+		failed = append(failed, emailFailure{Subject: email.Subject, Item: item, Error: err.Error()})
+	}
+	root := library.LibraryRoot()
+
+	for _, att := range email.Attachments {
+		path := filepath.Join(p.dir, att.Filename)
+		if !dryRun() {
+			var err error
+			if path, err = library.SaveFile(p.dir, att.Filename, bytes.NewReader(att.Data)); err != nil {
+				fail(att.Filename, err)
+				continue
+			}
+		}
+		doc := &library.Document{
+			Type:   library.DocTypePaper,
+			Path:   library.StoredPath(path, root),
+			Source: "email",
+			Title:  strings.TrimSuffix(filepath.Base(att.Filename), filepath.Ext(att.Filename)),
+		}
+		if err := p.add(doc, email); err != nil {
+			fail(att.Filename, err)
+			continue
+		}
+		docs = append(docs, doc)
+	}
+
+	for _, link := range email.Links {
+		if dryRun() {
+			// Fetching saves the file; its metadata comes with it
+			infof("Would fetch: %s\n", link)
+			continue
+		}
+		doc, err := library.ImportURL(link, p.dir)
+		if err != nil {
+			fail(link, err)
+			continue
+		}
+		doc.Path = library.StoredPath(doc.Path, root)
+		if err := p.add(doc, email); err != nil {
+			fail(link, err)
+			continue
+		}
+		docs = append(docs, doc)
+	}
+	return docs, failed, len(docs) > 0
+}
+
+// add tags and stores a document that arrived with email.
+func (p *emailPoller) add(doc *library.Document, email *library.InboxEmail) error {
+	doc.Tags = slices.Clone(p.tags)
+	if doc.Meta == nil {
+		doc.Meta = make(library.JSONMap)
+	}
+	doc.Meta["email_from"] = email.From
+	doc.Meta["email_subject"] = email.Subject
+	if p.extractText && doc.Path != "" && !dryRun() {
+		res := library.DefaultTextExtractor().Extract(library.DocumentPath(doc))
+		if err := applyExtraction(doc, res); err != nil {
+			warnf("    Warning: text extraction failed: %v\n", err)
+		}
+	}
+	library.DetectDocumentLanguage(doc, false)
+
+	if err := p.store.AddDocument(doc); err != nil {
+		return err
+	}
+	infof("Imported: %s (from %s)\n", truncate(doc.Title, 50), email.From)
+	return nil
+}
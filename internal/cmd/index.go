@@ -0,0 +1,67 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/mtreilly/arc-library/internal/library"
+	"github.com/spf13/cobra"
+	"github.com/yourorg/arc-sdk/config"
+	"github.com/yourorg/arc-sdk/output"
+)
+
+func newIndexCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "index",
+		Short: "Maintain the library's indexes",
+	}
+
+	cmd.AddCommand(newIndexRebuildCmd(store))
+
+	return cmd
+}
+
+func newIndexRebuildCmd(store library.LibraryStore) *cobra.Command {
+	var out output.OutputOptions
+
+	cmd := &cobra.Command{
+		Use:   "rebuild",
+		Short: "Rebuild the indexes from the documents",
+		Long: `With the KV backend, listing by tag, status, type, or source reads only the
+matching documents, found through an index of each. Libraries created
+before these indexes have none, and list by reading every document until
+they are built; rebuild builds them. It also rebuilds the path and source
+lookups and the counters behind stats, in case they no longer match the
+documents. With the SQL backend it runs REINDEX.
+
+Examples:
+  arc-library index rebuild
+  arc-library index rebuild -o json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := out.Resolve(); err != nil {
+				return err
+			}
+
+			stats, err := store.RebuildIndexes()
+			if err != nil {
+				return fmt.Errorf("rebuild indexes: %w", err)
+			}
+
+			if jsonOutput(&out) {
+				return output.JSON(stats)
+			}
+			if quietOutput() || dryRun() {
+				return nil
+			}
+			fmt.Printf("Rebuilt %d index(es) over %d document(s)\n", stats.Indexes, stats.Documents)
+			return nil
+		},
+	}
+
+	out.AddOutputFlags(cmd, output.OutputTable)
+
+	return cmd
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mtreilly/arc-library/internal/library"
+	"github.com/spf13/cobra"
+	"github.com/yourorg/arc-sdk/config"
+	"github.com/yourorg/arc-sdk/output"
+)
+
+func newJournalCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "journal",
+		Short: "Write library activity to daily notes",
+		Long: `Keep a record of library activity in the daily notes of Obsidian, Logseq,
+or any other Markdown notes app.`,
+	}
+
+	cmd.AddCommand(newJournalTodayCmd(store))
+
+	return cmd
+}
+
+// journalResult is the JSON schema for "journal today".
+type journalResult struct {
+	*library.DayActivity
+	Path string `json:"path,omitempty"` // the note written; empty with --print
+}
+
+func newJournalTodayCmd(store library.LibraryStore) *cobra.Command {
+	var (
+		dir       string
+		name      string
+		date      string
+		wikiLinks bool
+		printOnly bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "today",
+		Short: "Add today's library activity to the daily note",
+		Long: `Write today's library activity to the daily note in --dir (or
+ARC_LIBRARY_JOURNAL_DIR): documents added and finished, reading sessions,
+annotations, completed tasks, and flashcard reviews. The note is created
+if it doesn't exist. The activity goes into a "## Library" section between
+arc-library comment markers, so running the command again later in the day
+updates that section and leaves the rest of the note alone.
+
+--name is the note's file name as a Go time layout: 2006-01-02.md (the
+default, as in Obsidian) or 2006_01_02.md for Logseq's journals folder.
+
+Examples:
+  arc-library journal today --dir ~/vault/Daily
+  arc-library journal today --dir ~/logseq/journals --name 2006_01_02.md --wikilinks
+  arc-library journal today --date 2025-03-14 --print`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			day := time.Now()
+			if date != "" {
+				var err error
+				if day, err = library.ParseDate(date, time.Now()); err != nil {
+					return fmt.Errorf("invalid --date: %w", err)
+				}
+			}
+			if !printOnly && dir == "" {
+				return fmt.Errorf("no daily notes directory: set --dir or ARC_LIBRARY_JOURNAL_DIR, or use --print")
+			}
+
+			activity, err := library.ComputeDayActivity(store, day)
+			if err != nil {
+				return fmt.Errorf("gather activity: %w", err)
+			}
+			section := activity.Markdown(wikiLinks)
+
+			result := journalResult{DayActivity: activity}
+			if printOnly {
+				if jsonOutput(nil) {
+					return output.JSON(result)
+				}
+				fmt.Print(section)
+				return nil
+			}
+
+			// The shell has expanded --dir but not the setting it defaults to
+			if !cmd.Flags().Changed("dir") {
+				dir = library.ExpandPath(dir)
+			}
+			result.Path = library.DailyNotePath(dir, name, day)
+			if err := library.WriteDailyNote(result.Path, section); err != nil {
+				return fmt.Errorf("write daily note: %w", err)
+			}
+
+			if jsonOutput(nil) {
+				return output.JSON(result)
+			}
+			if quietOutput() {
+				return nil
+			}
+			fmt.Printf("Updated %s\n", result.Path)
+			fmt.Printf("  %d added, %d finished, %d session(s), %d annotation(s), %d task(s) done, %d card(s) reviewed\n",
+				len(activity.Added), len(activity.Finished), len(activity.Sessions), len(activity.Annotations),
+				len(activity.TasksCompleted), activity.CardsReviewed)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dir, "dir", os.Getenv("ARC_LIBRARY_JOURNAL_DIR"), "Daily notes directory")
+	cmd.Flags().StringVar(&name, "name", library.DefaultDailyNoteName, "Note file name, as a Go time layout")
+	cmd.Flags().StringVar(&date, "date", "", "Write the note for another day (e.g. 2025-03-14, yesterday)")
+	cmd.Flags().BoolVar(&wikiLinks, "wikilinks", false, "Write document titles as [[wiki links]]")
+	cmd.Flags().BoolVar(&printOnly, "print", false, "Print the section instead of writing the note")
+
+	return cmd
+}
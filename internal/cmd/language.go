@@ -0,0 +1,222 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/mtreilly/arc-library/internal/library"
+	"github.com/spf13/cobra"
+	"github.com/yourorg/arc-sdk/config"
+	"github.com/yourorg/arc-sdk/output"
+)
+
+func newLanguageCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "language",
+		Aliases: []string{"lang"},
+		Short:   "Detect and manage document languages",
+		Long: `Documents get a language (an ISO 639-1 code such as en, de, ja) detected
+from their text on import, stored as meta.language. Search indexes each
+document with a tokenizer suited to its language: English stemming,
+plain word matching for other languages, and character trigrams for
+Chinese, Japanese, Korean and Thai. Filter with --language on list,
+search run and export.
+
+Examples:
+  arc-library language list               # Documents per language
+  arc-library language detect --all       # Detect for documents without one
+  arc-library language set <doc-id> de    # Correct a wrong guess
+  arc-library list --language de`,
+	}
+
+	cmd.AddCommand(newLanguageListCmd(store))
+	cmd.AddCommand(newLanguageDetectCmd(store))
+	cmd.AddCommand(newLanguageSetCmd(store))
+
+	return cmd
+}
+
+func newLanguageListCmd(store library.LibraryStore) *cobra.Command {
+	var out output.OutputOptions
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Count documents per language",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := out.Resolve(); err != nil {
+				return err
+			}
+
+			docs, err := store.ListDocuments(nil)
+			if err != nil {
+				return fmt.Errorf("list documents: %w", err)
+			}
+			counts := make(map[string]int)
+			for _, d := range docs {
+				lang := library.DocumentLanguage(d)
+				if lang == "" {
+					lang = "unknown"
+				}
+				counts[lang]++
+			}
+
+			if jsonOutput(&out) {
+				return output.JSON(counts)
+			}
+			langs := make([]string, 0, len(counts))
+			for lang := range counts {
+				langs = append(langs, lang)
+			}
+			sort.Slice(langs, func(i, j int) bool {
+				if counts[langs[i]] != counts[langs[j]] {
+					return counts[langs[i]] > counts[langs[j]]
+				}
+				return langs[i] < langs[j]
+			})
+			if quietOutput() {
+				printIDs(langs...)
+				return nil
+			}
+
+			if len(langs) == 0 {
+				fmt.Println("No documents in library.")
+				return nil
+			}
+			table := output.NewTable("Language", "Documents")
+			for _, lang := range langs {
+				table.AddRow(lang, strconv.Itoa(counts[lang]))
+			}
+			table.Render()
+			return nil
+		},
+	}
+
+	out.AddOutputFlags(cmd, output.OutputTable)
+
+	return cmd
+}
+
+// languageResult is the JSON schema for "language detect" and "language set".
+type languageResult struct {
+	DocumentID string `json:"document_id"`
+	Language   string `json:"language"` // "" when undetected
+}
+
+func newLanguageDetectCmd(store library.LibraryStore) *cobra.Command {
+	var all bool
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:               "detect [document-id...]",
+		Short:             "Detect document languages",
+		Long:              `Detect and store the language of the given documents, or with --all of every document that has none yet. A recorded language is only replaced with --force.`,
+		ValidArgsFunction: completeDocuments(store),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if all == (len(args) > 0) {
+				return fmt.Errorf("give document IDs or --all")
+			}
+
+			var docs []*library.Document
+			if all {
+				list, err := store.ListDocuments(nil)
+				if err != nil {
+					return fmt.Errorf("list documents: %w", err)
+				}
+				docs = list
+			} else {
+				for _, arg := range args {
+					doc, err := lookupDocument(store, arg)
+					if err != nil {
+						return err
+					}
+					docs = append(docs, doc)
+				}
+			}
+
+			results := []languageResult{}
+			for _, doc := range docs {
+				before := library.DocumentLanguage(doc)
+				if before != "" && !force {
+					if !all {
+						results = append(results, languageResult{DocumentID: doc.ID, Language: before})
+						infof("%s: %s (already set; --force to redetect)\n", truncate(doc.Title, 40), before)
+					}
+					continue
+				}
+
+				lang := library.DetectDocumentLanguage(doc, true)
+				results = append(results, languageResult{DocumentID: doc.ID, Language: lang})
+				if lang == "" {
+					warnf("%s: not enough text to detect a language\n", truncate(doc.Title, 40))
+					continue
+				}
+				if lang != before {
+					doc.UpdatedAt = time.Now()
+					if err := store.UpdateDocument(doc); err != nil {
+						return fmt.Errorf("update document: %w", err)
+					}
+				}
+				infof("%s: %s\n", truncate(doc.Title, 40), lang)
+			}
+
+			if jsonOutput(nil) {
+				return output.JSON(results)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "Detect for every document without a language")
+	cmd.Flags().BoolVar(&force, "force", false, "Replace languages already recorded")
+
+	return cmd
+}
+
+var languageCodePattern = regexp.MustCompile(`^[a-z]{2,3}$`)
+
+func newLanguageSetCmd(store library.LibraryStore) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "set <document-id> <code>",
+		Short:             "Set a document's language",
+		Long:              `Set a document's language to an ISO 639-1 code such as en, de, or ja. Use "none" to clear it.`,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeFirst(completeDocuments(store)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			doc, err := lookupDocument(store, args[0])
+			if err != nil {
+				return err
+			}
+			lang := args[1]
+			if lang == "none" {
+				lang = ""
+			} else if !languageCodePattern.MatchString(lang) {
+				return fmt.Errorf("invalid language code %q (use ISO 639-1, e.g. en, de, ja)", lang)
+			}
+
+			library.SetDocumentLanguage(doc, lang)
+			doc.UpdatedAt = time.Now()
+			if err := store.UpdateDocument(doc); err != nil {
+				return fmt.Errorf("update document: %w", err)
+			}
+
+			if jsonOutput(nil) {
+				return output.JSON(languageResult{DocumentID: doc.ID, Language: lang})
+			}
+			if lang == "" {
+				infof("Cleared language of %s\n", truncate(doc.Title, 40))
+			} else {
+				infof("Set language of %s to %s\n", truncate(doc.Title, 40), lang)
+			}
+			return nil
+		},
+	}
+
+	return cmd
+}
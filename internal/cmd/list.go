@@ -5,6 +5,7 @@ package cmd
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -17,7 +18,11 @@ func newListCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command {
 	var out output.OutputOptions
 	var tag string
 	var source string
+	var docType string
+	var filters documentFilters
+	var sortBy string
 	var limit int
+	var archived bool
 
 	cmd := &cobra.Command{
 		Use:   "list",
@@ -28,16 +33,38 @@ Examples:
   arc-library list                  # List all documents
   arc-library list --tag ml         # Filter by tag
   arc-library list --source arxiv   # Filter by source
+  arc-library list --type book      # Filter by document type
+  arc-library list --status reading # Filter by reading status
+  arc-library list --archived       # Include archived documents
+  arc-library list --rating 4       # Rated 4 stars or better
+  arc-library list --author hinton  # Filter by author
+  arc-library list --read-after 2024-01-01
+  arc-library list --where year>=2020 # Filter by custom field
+  arc-library list --sort title     # Sort by title
   arc-library list --limit 20       # Limit results`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := out.Resolve(); err != nil {
 				return err
 			}
+			less, ok := documentSorters[sortBy]
+			if !ok {
+				return fmt.Errorf("invalid sort %q (use updated, created, title, rating)", sortBy)
+			}
 
 			opts := &library.ListOptions{
 				Tag:    tag,
 				Source: source,
-				Limit:  limit,
+				Type:   docType,
+
+				HideArchived: !archived,
+			}
+			if err := filters.apply(store, opts); err != nil {
+				return err
+			}
+			// Stores list, and limit, most recently updated first, so
+			// with another sort the limit is applied here after sorting.
+			if sortBy == "updated" {
+				opts.Limit = limit
 			}
 
 			documents, err := store.ListDocuments(opts)
@@ -45,30 +72,51 @@ Examples:
 				return err
 			}
 
+			sort.SliceStable(documents, func(i, j int) bool {
+				return less(documents[i], documents[j])
+			})
+			if limit > 0 && len(documents) > limit {
+				documents = documents[:limit]
+			}
+
+			if jsonOutput(&out) {
+				return output.JSON(nonNil(documents))
+			}
+			if quietOutput() {
+				printIDs(documentIDs(documents)...)
+				return nil
+			}
+
 			if len(documents) == 0 {
 				fmt.Println("No documents found in library.")
 				fmt.Println("Use 'arc-library import <path>' to add documents.")
 				return nil
 			}
 
-			if out.Is(output.OutputJSON) {
-				return output.JSON(documents)
+			// Custom fields in use get a column each
+			fields := fieldColumns(store, documents)
+			headers := []string{"Source ID", "Type", "Title", "Status", "Tags"}
+			for _, def := range fields {
+				headers = append(headers, def.Name)
 			}
-
-			table := output.NewTable("Source ID", "Title", "Tags")
-			for _, p := range documents {
+			table := output.NewTable(headers...)
+			for _, d := range documents {
 				tags := ""
-				if len(p.Tags) > 0 {
-					tags = strings.Join(p.Tags, ", ")
+				if len(d.Tags) > 0 {
+					tags = strings.Join(d.Tags, ", ")
 					if len(tags) > 25 {
 						tags = tags[:22] + "..."
 					}
 				}
-				sourceID := p.SourceID
+				sourceID := d.SourceID
 				if sourceID == "" {
-					sourceID = p.ID[:8]
+					sourceID = d.ID[:8]
 				}
-				table.AddRow(sourceID, truncate(p.Title, 45), tags)
+				row := []string{sourceID, string(d.Type), truncate(d.Title, 45), string(documentStatus(d)), tags}
+				for _, def := range fields {
+					row = append(row, truncate(fieldValue(d, def), 20))
+				}
+				table.AddRow(row...)
 			}
 			table.Render()
 
@@ -80,7 +128,30 @@ Examples:
 	out.AddOutputFlags(cmd, output.OutputTable)
 	cmd.Flags().StringVarP(&tag, "tag", "t", "", "Filter by tag")
 	cmd.Flags().StringVarP(&source, "source", "s", "", "Filter by source (arxiv, local)")
+	cmd.Flags().StringVar(&docType, "type", "", "Filter by document type (paper, book, article, video, note, repo, other)")
+	cmd.Flags().StringVar(&sortBy, "sort", "updated", "Sort by: updated, created, title, rating")
 	cmd.Flags().IntVarP(&limit, "limit", "n", 0, "Limit number of results")
+	cmd.Flags().BoolVar(&archived, "archived", false, "Include archived documents")
+	filters.addFlags(cmd)
 
 	return cmd
 }
+
+// documentSorters orders documents for the list --sort flag.
+// Dates and ratings sort newest/highest first; titles alphabetically.
+var documentSorters = map[string]func(a, b *library.Document) bool{
+	"updated": func(a, b *library.Document) bool { return a.UpdatedAt.After(b.UpdatedAt) },
+	"created": func(a, b *library.Document) bool { return a.CreatedAt.After(b.CreatedAt) },
+	"title": func(a, b *library.Document) bool {
+		return strings.ToLower(a.Title) < strings.ToLower(b.Title)
+	},
+	"rating": func(a, b *library.Document) bool { return a.Rating > b.Rating },
+}
+
+// documentStatus returns the reading status of d, treating an unset status as unread.
+func documentStatus(d *library.Document) library.ReadingStatus {
+	if d.Status == "" {
+		return library.StatusUnread
+	}
+	return d.Status
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mtreilly/arc-library/internal/library"
+	"github.com/spf13/cobra"
+	"github.com/yourorg/arc-sdk/config"
+	"github.com/yourorg/arc-sdk/output"
+)
+
+func newLogCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command {
+	var (
+		since    string
+		entity   string
+		actor    string
+		document string
+		limit    int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "log",
+		Short: "Show the audit log of library changes",
+		Long: `Show who changed what in the library, newest first. Every create,
+update, and delete of documents, tags, annotations, collections, links,
+flashcards, tasks, fields, shares, and tokens is recorded, with the
+document fields an update changed. Reading sessions and flashcard reviews
+are not.
+
+Changes are attributed to ARC_LIBRARY_ACTOR, or the OS user; changes made
+through the web server to the API token's name.
+
+Examples:
+  arc-library log --since 7d
+  arc-library log --document 2304.00067     # Who changed this document?
+  arc-library log --entity annotation --actor token:alice
+  arc-library log --since 2025-01-01 --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := &library.AuditListOptions{Entity: entity, Actor: actor, Limit: limit}
+			if since != "" {
+				t, err := parseSince(since, time.Now())
+				if err != nil {
+					return err
+				}
+				opts.Since = t
+			}
+			if document != "" {
+				doc, err := lookupDocument(store, document)
+				if err != nil {
+					// The document may be gone; its history is not
+					opts.DocumentID = document
+				} else {
+					opts.DocumentID = doc.ID
+				}
+			}
+			entries, err := store.ListAuditEntries(opts)
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput(nil) {
+				return output.JSON(nonNil(entries))
+			}
+			if quietOutput() {
+				for _, e := range entries {
+					fmt.Println(e.ID)
+				}
+				return nil
+			}
+			if len(entries) == 0 {
+				fmt.Println("No changes recorded.")
+				return nil
+			}
+			table := output.NewTable("Time", "Actor", "Action", "Entity", "ID", "Summary")
+			for _, e := range entries {
+				summary := e.Summary
+				if len(e.Fields) > 0 {
+					summary = strings.TrimSpace(summary + " [" + strings.Join(e.Fields, ", ") + "]")
+				}
+				table.AddRow(e.CreatedAt.Local().Format("2006-01-02 15:04"), e.Actor, string(e.Action), e.Entity, truncate(e.EntityID, 24), truncate(summary, 60))
+			}
+			table.Render()
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&since, "since", "", "Only changes since a date (2025-01-31, last monday) or this long ago (e.g. 12h, 7d, 2w)")
+	cmd.Flags().StringVar(&entity, "entity", "", "Only changes to this kind of record (document, annotation, collection, ...)")
+	cmd.Flags().StringVar(&actor, "actor", "", "Only changes by this actor")
+	cmd.Flags().StringVar(&document, "document", "", "Only changes to this document and what belongs to it")
+	cmd.Flags().IntVarP(&limit, "limit", "n", 100, "Maximum entries to show (0 for all)")
+
+	return cmd
+}
+
+// parseSince reads a --since value: a date, or a duration back from now.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if t, err := library.ParseDate(s, now); err == nil {
+		return t, nil
+	}
+	d, err := library.ParseShareTTL(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --since %q (use a date like 2025-01-31 or last monday, or e.g. 12h, 7d, 2w)", s)
+	}
+	return now.Add(-d), nil
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/mtreilly/arc-library/internal/library"
+	"github.com/spf13/cobra"
+	"github.com/yourorg/arc-sdk/config"
+	"github.com/yourorg/arc-sdk/output"
+)
+
+func newNoteCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command {
+	var (
+		tags       []string
+		collection string
+		title      string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "note [text]",
+		Short: "Capture a note as a document",
+		Long: `Capture a fleeting idea as a note document, with no file needed. The
+note's text is searchable like any document's, and its first line is the
+title unless --title gives one. Documents the note mentions, as
+[[id]], [[citekey]], [[title]], @citekey, or by ID, are linked from it
+and show it as "referenced by" (see "arc-library doc link notes").
+
+With no text, or "-", the note is read from standard input.
+
+Examples:
+  arc-library note "Attention could replace recurrence in the parser" -t idea
+  arc-library note "Ask about the dataset licence" -c inbox
+  pbpaste | arc-library note --title "Meeting notes" -t meeting`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			text := strings.Join(args, " ")
+			if len(args) == 0 || text == "-" {
+				data, err := io.ReadAll(os.Stdin)
+				if err != nil {
+					return fmt.Errorf("read note: %w", err)
+				}
+				text = string(data)
+			}
+			doc, err := library.NewNote(text)
+			if err != nil {
+				return err
+			}
+			if t := strings.TrimSpace(title); t != "" {
+				doc.Title = t
+			}
+			for _, t := range tags {
+				if !slices.Contains(doc.Tags, t) {
+					doc.Tags = append(doc.Tags, t)
+				}
+			}
+
+			// The language picks the search tokenizer the document is indexed with
+			library.DetectDocumentLanguage(doc, false)
+
+			if err := store.AddDocument(doc); err != nil {
+				return err
+			}
+
+			if collection != "" {
+				c, err := store.GetCollection(collection)
+				if err != nil {
+					return err
+				}
+				if c == nil {
+					c, err = store.CreateCollection(collection, "")
+					if err != nil {
+						return fmt.Errorf("create collection: %w", err)
+					}
+					infof("Created collection: %s\n", collection)
+				}
+				if err := store.AddToCollection(c.ID, doc.ID); err != nil {
+					return fmt.Errorf("add to collection: %w", err)
+				}
+			}
+
+			added, _, err := library.SyncNoteLinks(store, []*library.Document{doc})
+			if err != nil {
+				warnf("Warning: link mentioned documents: %v\n", err)
+			}
+
+			if jsonOutput(nil) {
+				return output.JSON(doc)
+			}
+			if quietOutput() {
+				printIDs(doc.ID)
+				return nil
+			}
+
+			fmt.Printf("Noted: %s - %s\n", doc.ID, truncate(doc.Title, 60))
+			for _, l := range added {
+				if to, _ := store.GetDocument(l.ToID); to != nil {
+					fmt.Printf("  References: %s\n", truncate(to.Title, 60))
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&tags, "tag", "t", nil, "Tags to apply to the note")
+	cmd.Flags().StringVarP(&collection, "collection", "c", "", "Add the note to a collection, creating it if needed")
+	cmd.Flags().StringVar(&title, "title", "", "Title (default: the first line)")
+
+	return cmd
+}
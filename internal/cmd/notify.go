@@ -0,0 +1,30 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+)
+
+// sendNotification posts a desktop notification using the platform's
+// notifier: notify-send on Linux/BSD and osascript on macOS.
+func sendNotification(title, body string) error {
+	var c *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", strconv.Quote(body), strconv.Quote(title))
+		c = exec.Command("osascript", "-e", script)
+	case "windows":
+		return fmt.Errorf("desktop notifications are not supported on windows")
+	default:
+		c = exec.Command("notify-send", title, body)
+	}
+	if out, err := c.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %v: %s", c.Path, err, out)
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/mtreilly/arc-library/internal/library"
+	"github.com/spf13/cobra"
+	"github.com/yourorg/arc-sdk/config"
+	"github.com/yourorg/arc-sdk/output"
+)
+
+// ocrResult is the JSON schema for "ocr".
+type ocrResult struct {
+	Processed []ocrDocument `json:"processed"`
+	Failed    []ocrFailure  `json:"failed"`
+}
+
+type ocrDocument struct {
+	DocumentID string  `json:"document_id"`
+	Title      string  `json:"title"`
+	Engine     string  `json:"engine"`
+	Words      int     `json:"words"`
+	Confidence float64 `json:"confidence"` // -1 when the engine reports none
+	Flagged    bool    `json:"flagged"`
+}
+
+type ocrFailure struct {
+	DocumentID string `json:"document_id"`
+	Error      string `json:"error"`
+}
+
+func newOCRCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command {
+	var allMissing bool
+	var engineFlag string
+	var lang string
+	var workers int
+	var minConfidence float64
+
+	cmd := &cobra.Command{
+		Use:   "ocr [document-id...]",
+		Short: "Recognize text in scanned PDFs",
+		Long: `Run OCR on PDFs without a text layer and store the recognized text as the
+document's full text, so search and AI commands can use it.
+
+The engine is tesseract (with pdftoppm from poppler) or ocrmypdf, whichever
+is installed; tesseract is preferred because it reports confidence.
+Documents whose mean word confidence falls below --min-confidence, or where
+nothing was recognized, are tagged "ocr-low-confidence" for review.
+
+Examples:
+  arc-library ocr 2304.00067                # OCR one document
+  arc-library ocr --all-missing-text        # Every PDF without full text
+  arc-library ocr --all-missing-text -w 8 --lang eng+deu
+  arc-library list --tag ocr-low-confidence # Check flagged results`,
+		ValidArgsFunction: completeDocuments(store),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if allMissing == (len(args) > 0) {
+				return fmt.Errorf("give document IDs or --all-missing-text")
+			}
+			if workers < 1 {
+				return fmt.Errorf("--workers must be at least 1")
+			}
+
+			engine := library.OCREngine(engineFlag)
+			if engineFlag == "auto" {
+				detected, err := library.DetectOCREngine()
+				if err != nil {
+					return err
+				}
+				engine = detected
+			}
+
+			var docs []*library.Document
+			if allMissing {
+				all, err := store.ListDocuments(nil)
+				if err != nil {
+					return fmt.Errorf("list documents: %w", err)
+				}
+				for _, d := range all {
+					if strings.TrimSpace(d.FullText) == "" && strings.EqualFold(filepath.Ext(d.Path), ".pdf") {
+						docs = append(docs, d)
+					}
+				}
+			} else {
+				for _, arg := range args {
+					doc, err := lookupDocument(store, arg)
+					if err != nil {
+						return err
+					}
+					docs = append(docs, doc)
+				}
+			}
+
+			result := ocrResult{Processed: []ocrDocument{}, Failed: []ocrFailure{}}
+			if len(docs) == 0 {
+				if jsonOutput(nil) {
+					return output.JSON(result)
+				}
+				infoln("No documents need OCR.")
+				return nil
+			}
+			infof("Running %s on %d document(s) with %d worker(s)...\n", engine, len(docs), min(workers, len(docs)))
+
+			type job struct {
+				doc *library.Document
+				res *library.OCRResult
+				err error
+			}
+			jobs := make(chan *library.Document)
+			done := make(chan job)
+			for range min(workers, len(docs)) {
+				go func() {
+					for doc := range jobs {
+						res, err := library.RunOCR(engine, library.DocumentPath(doc), lang)
+						done <- job{doc: doc, res: res, err: err}
+					}
+				}()
+			}
+			go func() {
+				for _, d := range docs {
+					jobs <- d
+				}
+				close(jobs)
+			}()
+
+			// Results are stored from this goroutine only, one at a time
+			bar := newProgress("OCR", len(docs))
+			defer bar.finish()
+			for i := range docs {
+				j := <-done
+				doc := j.doc
+				bar.set(i+1, truncate(doc.Title, 40))
+				if j.err != nil {
+					warnf("  %s: %v\n", truncate(doc.Title, 40), j.err)
+					result.Failed = append(result.Failed, ocrFailure{DocumentID: doc.ID, Error: j.err.Error()})
+					continue
+				}
+
+				flagged := library.ApplyOCR(doc, j.res, minConfidence)
+				library.DetectDocumentLanguage(doc, false)
+				doc.UpdatedAt = time.Now()
+				if err := store.UpdateDocument(doc); err != nil {
+					warnf("  %s: save: %v\n", truncate(doc.Title, 40), err)
+					result.Failed = append(result.Failed, ocrFailure{DocumentID: doc.ID, Error: err.Error()})
+					continue
+				}
+
+				result.Processed = append(result.Processed, ocrDocument{
+					DocumentID: doc.ID,
+					Title:      doc.Title,
+					Engine:     string(j.res.Engine),
+					Words:      j.res.Words,
+					Confidence: j.res.Confidence,
+					Flagged:    flagged,
+				})
+				conf := ""
+				if j.res.Confidence >= 0 {
+					conf = fmt.Sprintf(", confidence %.0f%%", j.res.Confidence)
+				}
+				if flagged {
+					warnf("  %s: %d words%s (flagged for review)\n", truncate(doc.Title, 40), j.res.Words, conf)
+				} else {
+					infof("  %s: %d words%s\n", truncate(doc.Title, 40), j.res.Words, conf)
+				}
+			}
+
+			bar.finish()
+
+			if jsonOutput(nil) {
+				return output.JSON(result)
+			}
+			flagged := 0
+			for _, p := range result.Processed {
+				if p.Flagged {
+					flagged++
+				}
+				if quietOutput() {
+					printIDs(p.DocumentID)
+				}
+			}
+			if quietOutput() {
+				return nil
+			}
+
+			fmt.Printf("\nOCR complete: %d processed, %d flagged, %d failed.\n", len(result.Processed), flagged, len(result.Failed))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&allMissing, "all-missing-text", false, "OCR every PDF that has no extracted text")
+	cmd.Flags().StringVar(&engineFlag, "engine", "auto", "OCR engine: auto, tesseract, ocrmypdf")
+	cmd.Flags().StringVar(&lang, "lang", "eng", "Tesseract language code(s), e.g. eng+deu")
+	cmd.Flags().IntVarP(&workers, "workers", "w", max(1, runtime.NumCPU()/2), "Number of documents to OCR in parallel")
+	cmd.Flags().Float64Var(&minConfidence, "min-confidence", library.DefaultOCRMinConfidence, "Flag results with lower mean word confidence (0-100)")
+
+	return cmd
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/mtreilly/arc-library/internal/library"
+	"github.com/spf13/cobra"
+	"github.com/yourorg/arc-sdk/output"
+)
+
+// globalOutput holds the persistent root-level output switches.
+// They are honored by every command in addition to per-command --output flags.
+var globalOutput struct {
+	json      bool
+	quiet     bool
+	logFormat string
+}
+
+func addGlobalOutputFlags(root *cobra.Command) {
+	root.PersistentFlags().BoolVar(&globalOutput.json, "json", false, "Emit machine-readable JSON (see README for schemas)")
+	root.PersistentFlags().BoolVar(&globalOutput.quiet, "quiet", false, "Suppress informational output; listings print IDs only")
+	root.MarkFlagsMutuallyExclusive("json", "quiet")
+}
+
+// addLogFormatFlag adds --log-format and, before any command runs, points
+// the log package, warnings, and progress at its format.
+func addLogFormatFlag(root *cobra.Command) {
+	root.PersistentFlags().StringVar(&globalOutput.logFormat, "log-format", cmp.Or(os.Getenv("ARC_LIBRARY_LOG_FORMAT"), "text"),
+		"Format of log lines, warnings, and progress on stderr: text, or json for one record per line")
+	root.RegisterFlagCompletionFunc("log-format", cobra.FixedCompletions([]string{"text", "json"}, cobra.ShellCompDirectiveNoFileComp))
+
+	next := root.PersistentPreRunE
+	root.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		switch globalOutput.logFormat {
+		case "text":
+			// Log lines go around a progress bar rather than through it
+			log.SetOutput(progressWriter{})
+		case "json":
+			// The log package writes through the default logger from here on
+			slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stderr, nil)))
+		default:
+			return fmt.Errorf("unknown --log-format %q (use text or json)", globalOutput.logFormat)
+		}
+		if next != nil {
+			return next(cmd, args)
+		}
+		return nil
+	}
+}
+
+// jsonOutput reports whether results should be written as JSON, either
+// because --json was given or the command's own output flag asks for it.
+// out may be nil for commands without an --output flag.
+func jsonOutput(out *output.OutputOptions) bool {
+	if globalOutput.json {
+		return true
+	}
+	return out != nil && out.Is(output.OutputJSON)
+}
+
+// quietOutput reports whether informational output is suppressed.
+func quietOutput() bool {
+	return globalOutput.quiet
+}
+
+// infof prints human-oriented progress or status text.
+// It is silent in --quiet and --json modes so stdout stays parseable.
+func infof(format string, a ...any) {
+	if globalOutput.quiet || globalOutput.json {
+		return
+	}
+	defer hideProgress()()
+	fmt.Printf(format, a...)
+}
+
+// infoln is the Println counterpart of infof.
+func infoln(a ...any) {
+	if globalOutput.quiet || globalOutput.json {
+		return
+	}
+	defer hideProgress()()
+	fmt.Println(a...)
+}
+
+// printIDs writes one identifier per line; used for --quiet listings.
+func printIDs(ids ...string) {
+	for _, id := range ids {
+		fmt.Println(id)
+	}
+}
+
+// deleteResult is the JSON schema for every delete-style command.
+type deleteResult struct {
+	Kind    string `json:"kind"`
+	ID      string `json:"id"`
+	Deleted bool   `json:"deleted"`
+}
+
+// membershipResult is the JSON schema for commands that add or remove
+// documents from a container (collection) or labels (tags) on a document.
+type membershipResult struct {
+	Target   string   `json:"target"`
+	Changed  []string `json:"changed"`
+	NotFound []string `json:"not_found,omitempty"`
+	Failed   []string `json:"failed,omitempty"`
+}
+
+// nonNil returns an empty slice for nil so JSON listings encode as [] rather than null.
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
+
+// documentIDs returns the IDs of docs in order.
+func documentIDs(docs []*library.Document) []string {
+	ids := make([]string, 0, len(docs))
+	for _, d := range docs {
+		ids = append(ids, d.ID)
+	}
+	return ids
+}
+
+// warnf prints a non-fatal warning to stderr, keeping stdout clean for results.
+// With --log-format json it is a warning record instead.
+func warnf(format string, a ...any) {
+	if globalOutput.logFormat == "json" {
+		slog.Warn(strings.TrimSpace(fmt.Sprintf(format, a...)))
+		return
+	}
+	defer hideProgress()()
+	fmt.Fprintf(os.Stderr, format, a...)
+}
@@ -0,0 +1,213 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mtreilly/arc-library/internal/library"
+	"github.com/spf13/cobra"
+	"github.com/yourorg/arc-sdk/config"
+	"github.com/yourorg/arc-sdk/output"
+)
+
+func newPathsCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "paths",
+		Short: "Check and migrate document file paths",
+		Long: `Set ARC_LIBRARY_ROOT to the directory holding your library files to make
+the library portable: files imported from under the root are stored with
+paths relative to it, so the root (database and files) can be moved to
+another machine or kept in a synced folder, and only ARC_LIBRARY_ROOT
+needs to change. Files outside the root keep absolute paths.
+
+Examples:
+  export ARC_LIBRARY_ROOT=~/Dropbox/library
+  arc-library paths relativize --dry-run   # Preview converting existing paths
+  arc-library paths relativize
+  arc-library paths check                  # List documents whose file is missing
+  arc-library paths rebase /old/home/papers ~/papers`,
+	}
+
+	cmd.AddCommand(newPathsCheckCmd(store))
+	cmd.AddCommand(newPathsRelativizeCmd(store))
+	cmd.AddCommand(newPathsRebaseCmd(store))
+
+	return cmd
+}
+
+// missingFile is the JSON schema for "paths check".
+type missingFile struct {
+	DocumentID string `json:"document_id"`
+	Path       string `json:"path"`     // as stored
+	Resolved   string `json:"resolved"` // where the file was looked for
+}
+
+func newPathsCheckCmd(store library.LibraryStore) *cobra.Command {
+	var out output.OutputOptions
+
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "List documents whose file is missing",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := out.Resolve(); err != nil {
+				return err
+			}
+
+			docs, err := store.ListDocuments(nil)
+			if err != nil {
+				return fmt.Errorf("list documents: %w", err)
+			}
+			missing := []missingFile{}
+			docsByID := make(map[string]*library.Document)
+			for _, doc := range docs {
+				if doc.Path == "" {
+					continue
+				}
+				resolved := library.DocumentPath(doc)
+				if _, err := os.Stat(resolved); err == nil {
+					continue
+				}
+				missing = append(missing, missingFile{DocumentID: doc.ID, Path: doc.Path, Resolved: resolved})
+				docsByID[doc.ID] = doc
+			}
+
+			if jsonOutput(&out) {
+				return output.JSON(missing)
+			}
+			if quietOutput() {
+				for _, m := range missing {
+					printIDs(m.DocumentID)
+				}
+				return nil
+			}
+
+			if len(missing) == 0 {
+				fmt.Println("All document files found.")
+				return nil
+			}
+			table := output.NewTable("ID", "Title", "Missing File")
+			for _, m := range missing {
+				table.AddRow(m.DocumentID[:8], truncate(docsByID[m.DocumentID].Title, 40), m.Resolved)
+			}
+			table.Render()
+			if !filepath.IsAbs(missing[0].Path) && library.LibraryRoot() == "" {
+				infoln("\nRelative paths need ARC_LIBRARY_ROOT set to the library root.")
+			}
+			return nil
+		},
+	}
+
+	out.AddOutputFlags(cmd, output.OutputTable)
+
+	return cmd
+}
+
+// pathChange is the JSON schema for "paths relativize" and "paths rebase".
+type pathChange struct {
+	DocumentID string `json:"document_id"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+}
+
+func newPathsRelativizeCmd(store library.LibraryStore) *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "relativize",
+		Short: "Store paths under the library root relative to it",
+		Long:  `Convert the absolute paths of documents whose files are under ARC_LIBRARY_ROOT to paths relative to it.`,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root := library.LibraryRoot()
+			if root == "" {
+				return fmt.Errorf("ARC_LIBRARY_ROOT is not set")
+			}
+			return rewritePaths(store, dryRun, func(path string) string {
+				if !filepath.IsAbs(path) {
+					return path
+				}
+				return library.StoredPath(path, root)
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the changes without saving them")
+
+	return cmd
+}
+
+func newPathsRebaseCmd(store library.LibraryStore) *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "rebase <old-dir> <new-dir>",
+		Short: "Point paths under one directory to another",
+		Long:  `Rewrite absolute document paths under old-dir to the same place under new-dir, after moving files without a library root. Rewritten paths under ARC_LIBRARY_ROOT are stored relative to it.`,
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			oldDir := filepath.Clean(args[0])
+			newDir := filepath.Clean(args[1])
+			root := library.LibraryRoot()
+			return rewritePaths(store, dryRun, func(path string) string {
+				rel, err := filepath.Rel(oldDir, path)
+				if !filepath.IsAbs(path) || err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+					return path
+				}
+				return library.StoredPath(filepath.Join(newDir, rel), root)
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the changes without saving them")
+
+	return cmd
+}
+
+// rewritePaths applies rewrite to every document path and saves the ones
+// that change.
+func rewritePaths(store library.LibraryStore, dryRun bool, rewrite func(string) string) error {
+	docs, err := store.ListDocuments(nil)
+	if err != nil {
+		return fmt.Errorf("list documents: %w", err)
+	}
+
+	changes := []pathChange{}
+	for _, doc := range docs {
+		if doc.Path == "" {
+			continue
+		}
+		to := rewrite(doc.Path)
+		if to == doc.Path {
+			continue
+		}
+		changes = append(changes, pathChange{DocumentID: doc.ID, From: doc.Path, To: to})
+		if dryRun {
+			continue
+		}
+		doc.Path = to
+		doc.UpdatedAt = time.Now()
+		if err := store.UpdateDocument(doc); err != nil {
+			return fmt.Errorf("update document: %w", err)
+		}
+	}
+
+	if jsonOutput(nil) {
+		return output.JSON(changes)
+	}
+	for _, c := range changes {
+		infof("%s: %s -> %s\n", c.DocumentID[:8], c.From, c.To)
+	}
+	if dryRun {
+		infof("Would update %d path(s)\n", len(changes))
+	} else {
+		infof("Updated %d path(s)\n", len(changes))
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"github.com/mtreilly/arc-library/internal/library"
+	"github.com/spf13/cobra"
+	"github.com/yourorg/arc-sdk/config"
+	"github.com/yourorg/arc-sdk/output"
+)
+
+// addPluginCmds adds a subcommand for every plugin on PATH whose name no
+// built-in command already has.
+func addPluginCmds(root *cobra.Command, store library.LibraryStore) {
+	for _, p := range library.DiscoverPlugins(os.Getenv("PATH")) {
+		if pluginShadowed(root, p.Name) {
+			continue
+		}
+		root.AddCommand(newPluginRunCmd(store, p))
+	}
+}
+
+// pluginShadowed reports whether a built-in command takes name.
+func pluginShadowed(root *cobra.Command, name string) bool {
+	for _, c := range root.Commands() {
+		if c.Annotations["plugin"] == "" && (c.Name() == name || c.HasAlias(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+func newPluginRunCmd(store library.LibraryStore, p library.Plugin) *cobra.Command {
+	return &cobra.Command{
+		Use:                p.Name,
+		Short:              fmt.Sprintf("Plugin (%s)", p.Path),
+		Annotations:        map[string]string{"plugin": p.Path},
+		DisableFlagParsing: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPlugin(store, p, args)
+		},
+	}
+}
+
+// runPlugin runs a plugin with the terminal and serves it the library
+// over JSON-RPC on two extra pipes: it writes requests to file descriptor
+// 3 and reads responses from 4. Its exit status becomes ours. Windows
+// can't pass a child extra file descriptors, so plugins don't run there.
+func runPlugin(store library.LibraryStore, p library.Plugin, args []string) error {
+	if runtime.GOOS == "windows" {
+		return fmt.Errorf("run plugin %s: plugins need file descriptors 3 and 4, which Windows can't pass on; run it on macOS or Linux, or use the web API", p.Name)
+	}
+	reqR, reqW, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	respR, respW, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+
+	self, _ := os.Executable()
+	c := exec.Command(p.Path, args...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	c.ExtraFiles = []*os.File{reqW, respR}
+	c.Env = append(os.Environ(),
+		"ARC_LIBRARY_PLUGIN="+p.Name,
+		"ARC_LIBRARY_BIN="+self,
+		"ARC_LIBRARY_RPC_WRITE_FD=3",
+		"ARC_LIBRARY_RPC_READ_FD=4",
+	)
+	if err := c.Start(); err != nil {
+		reqR.Close()
+		reqW.Close()
+		respR.Close()
+		respW.Close()
+		return fmt.Errorf("run plugin %s: %w", p.Name, err)
+	}
+	// The plugin holds its own ends now; ours would keep the pipes open
+	reqW.Close()
+	respR.Close()
+
+	served := make(chan error, 1)
+	go func() {
+		served <- library.ServePluginRPC(library.WithActor(store, "plugin:"+p.Name), reqR, respW)
+	}()
+	err = c.Wait()
+	// Anything the plugin left running still holds the pipe; stop reading
+	reqR.Close()
+	<-served
+	respW.Close()
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		os.Exit(exitErr.ExitCode())
+	}
+	return err
+}
+
+// pluginInfo is the JSON schema for "plugin list".
+type pluginInfo struct {
+	library.Plugin
+	Shadowed bool `json:"shadowed"` // a built-in command has the name
+}
+
+func newPluginCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plugin",
+		Short: "Show the plugins found on PATH",
+		Long: `Any executable on PATH named arc-library-<name> runs as
+"arc-library <name> [args...]", with its arguments passed through
+untouched. Plugins named like a built-in command are ignored.
+
+A plugin reaches the library through JSON-RPC 2.0 over two pipes: it
+writes one request per line to file descriptor 3 (ARC_LIBRARY_RPC_WRITE_FD)
+and reads one response per line from 4 (ARC_LIBRARY_RPC_READ_FD). Params
+are passed by name; "rpc.methods" lists the methods. Changes are recorded
+in the audit log as plugin:<name>. ARC_LIBRARY_BIN is the arc-library
+executable, for running other commands with --json. Plugins run only on
+Unix systems such as macOS and Linux, as Windows can't pass the pipes.
+
+Examples:
+  arc-library plugin list
+  echo '{"jsonrpc":"2.0","id":1,"method":"documents.list","params":{"tag":"ml"}}' >&3`,
+	}
+
+	cmd.AddCommand(newPluginListCmd())
+
+	return cmd
+}
+
+func newPluginListCmd() *cobra.Command {
+	var out output.OutputOptions
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the plugins found on PATH",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := out.Resolve(); err != nil {
+				return err
+			}
+
+			plugins := []pluginInfo{}
+			for _, p := range library.DiscoverPlugins(os.Getenv("PATH")) {
+				plugins = append(plugins, pluginInfo{Plugin: p, Shadowed: pluginShadowed(cmd.Root(), p.Name)})
+			}
+
+			if jsonOutput(&out) {
+				return output.JSON(plugins)
+			}
+			if quietOutput() {
+				for _, p := range plugins {
+					printIDs(p.Name)
+				}
+				return nil
+			}
+
+			if len(plugins) == 0 {
+				fmt.Printf("No plugins found. Put an executable named %s<name> on PATH.\n", library.PluginPrefix)
+				return nil
+			}
+			table := output.NewTable("Name", "Path", "Note")
+			for _, p := range plugins {
+				note := ""
+				if p.Shadowed {
+					note = "ignored: built-in command " + p.Name
+				}
+				table.AddRow(p.Name, p.Path, note)
+			}
+			table.Render()
+			return nil
+		},
+	}
+
+	out.AddOutputFlags(cmd, output.OutputTable)
+
+	return cmd
+}
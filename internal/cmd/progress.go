@@ -0,0 +1,228 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// progress reports how far a long-running command has got through its
+// items and when it should finish. On a terminal it is a bar on stderr,
+// redrawn in place; with --log-format json it is a "progress" record at
+// most once a second; otherwise it is a line on stderr every ten seconds,
+// for logs of scheduled runs. --quiet silences the bar and lines.
+type progress struct {
+	mu       sync.Mutex
+	label    string
+	total    int
+	done     int
+	item     string
+	start    time.Time
+	drawn    time.Time // when the bar was last drawn or logged
+	visible  bool      // the bar is on the terminal's last line
+	finished bool
+}
+
+// activeProgress is the bar on the terminal, if any, which infof and
+// warnf clear before printing and draw again after.
+var activeProgress *progress
+
+// Redraw at most this often; log at most once a second; write a line at
+// most every ten seconds.
+const (
+	progressRedraw = 100 * time.Millisecond
+	progressLog    = time.Second
+	progressLine   = 10 * time.Second
+)
+
+// newProgress starts reporting progress through total items. A single
+// item is not worth a bar, so for total < 2 it reports nothing.
+func newProgress(label string, total int) *progress {
+	p := &progress{label: label, total: total, start: time.Now()}
+	if total >= 2 && progressMode() == "bar" {
+		activeProgress = p
+	}
+	return p
+}
+
+// progressMode is how progress is reported: "bar", "log", "line", or ""
+// for not at all.
+func progressMode() string {
+	switch {
+	case globalOutput.logFormat == "json":
+		return "log"
+	case globalOutput.quiet:
+		return ""
+	case stderrIsTerminal():
+		return "bar"
+	}
+	return "line"
+}
+
+// stderrIsTerminal reports whether standard error is an interactive
+// terminal a bar can be redrawn on.
+func stderrIsTerminal() bool {
+	fi, err := os.Stderr.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0 && os.Getenv("TERM") != "dumb"
+}
+
+// set records that done items are finished and item is the one being
+// worked on, which may be "".
+func (p *progress) set(done int, item string) {
+	if p == nil || p.total < 2 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done, p.item = min(done, p.total), item
+	now := time.Now()
+	if p.drawn.IsZero() {
+		// The first report waits a period, so quick runs only report the end
+		p.drawn = now
+	}
+	switch progressMode() {
+	case "bar":
+		if now.Sub(p.drawn) >= progressRedraw {
+			p.draw()
+			p.drawn = now
+		}
+	case "log":
+		if now.Sub(p.drawn) >= progressLog {
+			p.log()
+			p.drawn = now
+		}
+	case "line":
+		if now.Sub(p.drawn) >= progressLine && p.done < p.total {
+			fmt.Fprintf(os.Stderr, "%s %d/%d (%d%%), ETA %s\n", p.label, p.done, p.total, 100*p.done/p.total, formatETA(p.eta()))
+			p.drawn = now
+		}
+	}
+}
+
+// finish reports the last item done and ends the bar's line. It is safe
+// to call more than once, as a deferred call after an explicit one.
+func (p *progress) finish() {
+	if p == nil || p.total < 2 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.finished {
+		return
+	}
+	p.finished = true
+	if activeProgress == p {
+		activeProgress = nil
+	}
+	elapsed := time.Since(p.start)
+	switch progressMode() {
+	case "bar":
+		if p.visible {
+			fmt.Fprint(os.Stderr, "\r\033[K")
+		}
+		fmt.Fprintf(os.Stderr, "%s %d/%d in %s\n", p.label, p.done, p.total, elapsed.Round(time.Second))
+	case "line":
+		if elapsed >= progressLine {
+			fmt.Fprintf(os.Stderr, "%s %d/%d in %s\n", p.label, p.done, p.total, elapsed.Round(time.Second))
+		}
+	case "log":
+		p.log()
+	}
+}
+
+// eta estimates the time left from the average time per item so far.
+func (p *progress) eta() time.Duration {
+	if p.done == 0 || p.done >= p.total {
+		return 0
+	}
+	perItem := time.Since(p.start) / time.Duration(p.done)
+	return perItem * time.Duration(p.total-p.done)
+}
+
+// draw writes the bar over the terminal's last line. Callers hold p.mu.
+func (p *progress) draw() {
+	const barWidth = 24
+	filled := barWidth * p.done / p.total
+	bar := strings.Repeat("=", filled)
+	if filled < barWidth {
+		bar += ">" + strings.Repeat(" ", barWidth-filled-1)
+	}
+	line := fmt.Sprintf("%s [%s] %d/%d %3d%%", p.label, bar, p.done, p.total, 100*p.done/p.total)
+	if p.done > 0 && p.done < p.total {
+		line += "  ETA " + formatETA(p.eta())
+	}
+	if p.item != "" {
+		line += "  " + p.item
+	}
+	fmt.Fprint(os.Stderr, "\r\033[K"+truncate(line, terminalWidth()-1))
+	p.visible = true
+}
+
+// log writes a progress record. Callers hold p.mu.
+func (p *progress) log() {
+	attrs := []any{"task", p.label, "done", p.done, "total", p.total, "elapsed_seconds", int(time.Since(p.start).Seconds())}
+	if eta := p.eta(); eta > 0 {
+		attrs = append(attrs, "eta_seconds", int(eta.Seconds()))
+	}
+	if p.item != "" {
+		attrs = append(attrs, "item", p.item)
+	}
+	slog.Info("progress", attrs...)
+}
+
+// hideProgress clears the bar, if one is showing, so a line can be
+// printed, and returns the function that draws it again below the line.
+func hideProgress() func() {
+	p := activeProgress
+	if p == nil {
+		return func() {}
+	}
+	p.mu.Lock()
+	if p.visible {
+		fmt.Fprint(os.Stderr, "\r\033[K")
+		p.visible = false
+	}
+	return func() {
+		if !p.finished {
+			p.draw()
+		}
+		p.mu.Unlock()
+	}
+}
+
+// progressWriter writes to stderr around the bar, for the log package.
+type progressWriter struct{}
+
+func (progressWriter) Write(b []byte) (int, error) {
+	defer hideProgress()()
+	return os.Stderr.Write(b)
+}
+
+// formatETA rounds a duration to what is worth reading in an estimate,
+// such as "3m12s" or "1h05m".
+func formatETA(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return d.Round(time.Second).String()
+	case d < time.Hour:
+		d = d.Round(time.Second)
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	}
+	d = d.Round(time.Minute)
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// terminalWidth is the width of the terminal from $COLUMNS, else 80.
+func terminalWidth() int {
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 20 {
+		return n
+	}
+	return 80
+}
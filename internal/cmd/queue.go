@@ -0,0 +1,381 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mtreilly/arc-library/internal/library"
+	"github.com/spf13/cobra"
+	"github.com/yourorg/arc-sdk/config"
+	"github.com/yourorg/arc-sdk/output"
+)
+
+func newQueueCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command {
+	var out output.OutputOptions
+	var boost []string
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "queue",
+		Short: "Answer \"what should I read next?\"",
+		Long: `Show the reading queue: documents you pushed, in order, followed by
+unread documents ranked by rating, open tasks linked to them (overdue and
+due-soon tasks first), tags boosted with --tag, and how long they have
+been waiting.
+
+Examples:
+  arc-library queue                   # Top 10 of the queue
+  arc-library queue next              # The one document to read now
+  arc-library queue push 2304.00067   # Pin a document to the end of the queue
+  arc-library queue pop               # Take the next document and mark it reading
+  arc-library queue next --tag thesis # Favour documents tagged "thesis"`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := out.Resolve(); err != nil {
+				return err
+			}
+
+			queue, err := loadReadingQueue(store, boost)
+			if err != nil {
+				return err
+			}
+			total := len(queue)
+			if limit > 0 && len(queue) > limit {
+				queue = queue[:limit]
+			}
+
+			if jsonOutput(&out) {
+				return output.JSON(queue)
+			}
+			if quietOutput() {
+				for _, e := range queue {
+					printIDs(e.Document.ID)
+				}
+				return nil
+			}
+
+			if total == 0 {
+				fmt.Println("Queue is empty: nothing unread.")
+				return nil
+			}
+
+			table := output.NewTable("#", "Type", "Title", "Why")
+			for i, e := range queue {
+				pos := fmt.Sprintf("%d", i+1)
+				if e.Pinned {
+					pos += "*"
+				}
+				table.AddRow(pos, string(e.Document.Type), truncate(e.Document.Title, 50), strings.Join(e.Reasons, ", "))
+			}
+			table.Render()
+
+			fmt.Printf("\n%d document(s) in queue", total)
+			if total > len(queue) {
+				fmt.Printf(", showing %d", len(queue))
+			}
+			fmt.Println(" (* = pushed)")
+			return nil
+		},
+	}
+
+	out.AddOutputFlags(cmd, output.OutputTable)
+	cmd.PersistentFlags().StringSliceVarP(&boost, "tag", "t", nil, "Rank documents with these tags higher")
+	cmd.Flags().IntVarP(&limit, "limit", "n", 10, "Limit number of results (0 for all)")
+
+	cmd.AddCommand(newQueueNextCmd(store, &boost))
+	cmd.AddCommand(newQueuePushCmd(store))
+	cmd.AddCommand(newQueuePopCmd(store, &boost))
+	cmd.AddCommand(newQueueRemoveCmd(store))
+	cmd.AddCommand(newQueueShuffleCmd(store, &boost))
+	cmd.AddCommand(newQueueClearCmd(store))
+
+	return cmd
+}
+
+// loadReadingQueue builds the full reading queue from the pinned order and
+// the library. Open tasks are best-effort: backends without task support
+// simply rank without them.
+func loadReadingQueue(store library.LibraryStore, boost []string) ([]library.QueueEntry, error) {
+	pinned, err := store.GetReadingQueue()
+	if err != nil {
+		return nil, fmt.Errorf("get queue: %w", err)
+	}
+	docs, err := store.ListDocuments(nil)
+	if err != nil {
+		return nil, fmt.Errorf("list documents: %w", err)
+	}
+	tasks, _ := store.ListTasks(&library.TaskListOptions{Open: true})
+
+	return library.BuildReadingQueue(pinned, docs, tasks, library.QueueOptions{BoostTags: boost}, library.Now()), nil
+}
+
+// queueResult is the JSON schema for commands that change the pinned order.
+type queueResult struct {
+	Pinned []string `json:"pinned"`
+}
+
+func newQueueNextCmd(store library.LibraryStore, boost *[]string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "next",
+		Short: "Show the next document to read",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			queue, err := loadReadingQueue(store, *boost)
+			if err != nil {
+				return err
+			}
+
+			if len(queue) == 0 {
+				if jsonOutput(nil) {
+					return output.JSON(nil)
+				}
+				infoln("Queue is empty: nothing unread.")
+				return nil
+			}
+			next := queue[0]
+
+			if jsonOutput(nil) {
+				return output.JSON(next)
+			}
+			if quietOutput() {
+				printIDs(next.Document.ID)
+				return nil
+			}
+			printQueueEntry(next)
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func printQueueEntry(e library.QueueEntry) {
+	fmt.Printf("%s\n", e.Document.Title)
+	fmt.Printf("ID:   %s\n", e.Document.ID)
+	if e.Document.Path != "" {
+		fmt.Printf("Path: %s\n", library.DocumentPath(e.Document))
+	}
+	why := strings.Join(e.Reasons, ", ")
+	if e.Pinned {
+		why = strings.TrimSuffix("pushed, "+why, ", ")
+	}
+	if why != "" {
+		fmt.Printf("Why:  %s\n", why)
+	}
+}
+
+func newQueuePushCmd(store library.LibraryStore) *cobra.Command {
+	var front bool
+
+	cmd := &cobra.Command{
+		Use:               "push <document-id>...",
+		Short:             "Pin documents to the queue",
+		Long:              `Pin documents to the end of the queue (or the front with --front), ahead of every suggested document. Pushing a pinned document again moves it.`,
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeDocuments(store),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var ids []string
+			for _, arg := range args {
+				doc, err := lookupDocument(store, arg)
+				if err != nil {
+					return err
+				}
+				ids = append(ids, doc.ID)
+			}
+
+			pinned, err := store.GetReadingQueue()
+			if err != nil {
+				return fmt.Errorf("get queue: %w", err)
+			}
+			pinned = slices.DeleteFunc(pinned, func(id string) bool { return slices.Contains(ids, id) })
+			if front {
+				pinned = append(ids, pinned...)
+			} else {
+				pinned = append(pinned, ids...)
+			}
+			if err := store.SetReadingQueue(pinned); err != nil {
+				return fmt.Errorf("save queue: %w", err)
+			}
+
+			if jsonOutput(nil) {
+				return output.JSON(queueResult{Pinned: pinned})
+			}
+			infof("Pushed %d document(s); %d pinned\n", len(ids), len(pinned))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&front, "front", false, "Pin to the front of the queue")
+
+	return cmd
+}
+
+func newQueuePopCmd(store library.LibraryStore, boost *[]string) *cobra.Command {
+	var keepStatus bool
+
+	cmd := &cobra.Command{
+		Use:   "pop",
+		Short: "Take the next document off the queue",
+		Long:  `Take the next document off the queue and mark it as reading, so it leaves the queue for good. Use --keep-status to only unpin it.`,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			queue, err := loadReadingQueue(store, *boost)
+			if err != nil {
+				return err
+			}
+			if len(queue) == 0 {
+				return fmt.Errorf("queue is empty")
+			}
+			next := queue[0]
+			doc := next.Document
+
+			pinned, err := store.GetReadingQueue()
+			if err != nil {
+				return fmt.Errorf("get queue: %w", err)
+			}
+			if i := slices.Index(pinned, doc.ID); i >= 0 {
+				if err := store.SetReadingQueue(slices.Delete(pinned, i, i+1)); err != nil {
+					return fmt.Errorf("save queue: %w", err)
+				}
+			}
+			if !keepStatus && doc.Status != library.StatusReading {
+				doc.Status = library.StatusReading
+				doc.UpdatedAt = time.Now()
+				if err := store.UpdateDocument(doc); err != nil {
+					return fmt.Errorf("update document: %w", err)
+				}
+			}
+
+			if jsonOutput(nil) {
+				return output.JSON(next)
+			}
+			if quietOutput() {
+				printIDs(doc.ID)
+				return nil
+			}
+			printQueueEntry(next)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&keepStatus, "keep-status", false, "Do not mark the document as reading")
+
+	return cmd
+}
+
+func newQueueRemoveCmd(store library.LibraryStore) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "remove <document-id>...",
+		Aliases:           []string{"rm"},
+		Short:             "Unpin documents from the queue",
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeDocuments(store),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var ids []string
+			for _, arg := range args {
+				doc, err := lookupDocument(store, arg)
+				if err != nil {
+					return err
+				}
+				ids = append(ids, doc.ID)
+			}
+
+			pinned, err := store.GetReadingQueue()
+			if err != nil {
+				return fmt.Errorf("get queue: %w", err)
+			}
+			before := len(pinned)
+			pinned = slices.DeleteFunc(pinned, func(id string) bool { return slices.Contains(ids, id) })
+			if err := store.SetReadingQueue(pinned); err != nil {
+				return fmt.Errorf("save queue: %w", err)
+			}
+
+			if jsonOutput(nil) {
+				return output.JSON(queueResult{Pinned: nonNil(pinned)})
+			}
+			infof("Unpinned %d document(s); %d pinned\n", before-len(pinned), len(pinned))
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func newQueueShuffleCmd(store library.LibraryStore, boost *[]string) *cobra.Command {
+	var limit int
+
+	cmd := &cobra.Command{
+		Use:   "shuffle",
+		Short: "Shuffle the top of the queue",
+		Long:  `Take the top N documents of the queue, pushed or suggested, and pin them in random order. Useful when several papers are equally pressing.`,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if limit <= 0 {
+				return fmt.Errorf("--limit must be positive")
+			}
+			queue, err := loadReadingQueue(store, *boost)
+			if err != nil {
+				return err
+			}
+			if len(queue) == 0 {
+				return fmt.Errorf("queue is empty")
+			}
+			if len(queue) > limit {
+				queue = queue[:limit]
+			}
+
+			shuffled := make([]string, len(queue))
+			for i, e := range queue {
+				shuffled[i] = e.Document.ID
+			}
+			rand.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+
+			// Pinned documents below the shuffled window keep their place after it
+			pinned, err := store.GetReadingQueue()
+			if err != nil {
+				return fmt.Errorf("get queue: %w", err)
+			}
+			rest := slices.DeleteFunc(pinned, func(id string) bool { return slices.Contains(shuffled, id) })
+			pinned = append(shuffled, rest...)
+			if err := store.SetReadingQueue(pinned); err != nil {
+				return fmt.Errorf("save queue: %w", err)
+			}
+
+			if jsonOutput(nil) {
+				return output.JSON(queueResult{Pinned: pinned})
+			}
+			infof("Shuffled %d document(s) to the front of the queue\n", len(shuffled))
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVarP(&limit, "limit", "n", 10, "Number of documents to shuffle")
+
+	return cmd
+}
+
+func newQueueClearCmd(store library.LibraryStore) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "clear",
+		Short: "Unpin every document",
+		Long:  `Unpin every document, leaving the queue to suggestions only.`,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := store.SetReadingQueue(nil); err != nil {
+				return fmt.Errorf("save queue: %w", err)
+			}
+			if jsonOutput(nil) {
+				return output.JSON(queueResult{Pinned: []string{}})
+			}
+			infoln("Queue cleared")
+			return nil
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,383 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mtreilly/arc-library/internal/library"
+	"github.com/mtreilly/arc-library/internal/scheduler"
+	"github.com/spf13/cobra"
+	"github.com/yourorg/arc-sdk/config"
+)
+
+func newReadCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command {
+	var fromStart bool
+
+	cmd := &cobra.Command{
+		Use:   "read <id>",
+		Short: "Read a document's extracted text in the terminal",
+		Long: `Page through a document's full text in the terminal, one paragraph
+at a time.
+
+The paragraph at the top of the screen is the current one, marked in the
+margin. Reading resumes where you last left the document; the positions
+are kept in arc-library/read-positions.json under the user config
+directory, or the file named by ARC_LIBRARY_READ_POSITIONS.
+
+When output is not a terminal the text is written out, one paragraph per
+block, for another pager or a file.
+
+Keys:
+  j/k, up/down        Scroll a line
+  space/b, pgdn/pgup  Scroll a page
+  n/p, }/{            Next or previous paragraph
+  g/G, home/end       Go to the start or the end
+  h                   Save the current paragraph as a highlight annotation
+  f                   Make a flashcard with the current paragraph as its answer
+  q, esc, ctrl+c      Quit, remembering the position
+
+Examples:
+  arc-library read 2304.00067
+  arc-library read 2304.00067 --from-start
+  arc-library read 2304.00067 | less`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeFirst(completeDocuments(store)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			doc, err := lookupDocument(store, args[0])
+			if err != nil {
+				return err
+			}
+			paras := library.SplitParagraphs(doc.FullText)
+			if len(paras) == 0 {
+				return fmt.Errorf("document %s has no full text to read", doc.ID)
+			}
+
+			if fi, err := os.Stdout.Stat(); err == nil && fi.Mode()&os.ModeCharDevice == 0 {
+				for i, p := range paras {
+					if i > 0 {
+						fmt.Println()
+					}
+					fmt.Println(p.Text)
+				}
+				return nil
+			}
+
+			positionsFile, err := library.ReadPositionsFile()
+			if err != nil {
+				return err
+			}
+			start := 0
+			if !fromStart {
+				positions, err := library.LoadReadPositions(positionsFile)
+				if err != nil {
+					// A damaged positions file should not stop the reading
+					warnf("warning: %v\n", err)
+				} else if pos, ok := positions[doc.ID]; ok {
+					start = library.ParagraphAt(paras, pos.Offset)
+				}
+			}
+
+			m := newReaderModel(store, doc, paras, start)
+			final, err := tea.NewProgram(m, tea.WithAltScreen()).Run()
+			if err != nil {
+				return err
+			}
+			current := paras[final.(*readerModel).current()]
+			if err := library.SaveReadPosition(positionsFile, doc.ID, library.ReadPosition{Offset: current.Offset, UpdatedAt: time.Now()}); err != nil {
+				return fmt.Errorf("save position: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&fromStart, "from-start", false, "Start at the beginning instead of the remembered position")
+
+	return cmd
+}
+
+// readerLine is one wrapped line on screen; para is -1 for the blank line
+// between paragraphs.
+type readerLine struct {
+	para int
+	text string
+}
+
+type readerModel struct {
+	store library.LibraryStore
+	doc   *library.Document
+	paras []library.Paragraph
+
+	highlighted map[int]bool // paragraph indexes with a highlight annotation
+	lines       []readerLine
+	firstLine   []int // first line of each paragraph
+	top         int   // first visible line
+	start       int   // paragraph to show first, until the lines are laid out
+
+	prompting bool
+	input     textinput.Model
+	status    string
+
+	width  int
+	height int
+}
+
+func newReaderModel(store library.LibraryStore, doc *library.Document, paras []library.Paragraph, start int) *readerModel {
+	ti := textinput.New()
+	ti.Prompt = "flashcard question: "
+	ti.CharLimit = 500
+
+	m := &readerModel{
+		store:       store,
+		doc:         doc,
+		paras:       paras,
+		highlighted: map[int]bool{},
+		start:       start,
+		input:       ti,
+	}
+	// Highlights are best-effort; without them the margin just lacks marks
+	annotations, _ := store.GetAnnotations(doc.ID)
+	for _, a := range annotations {
+		var span library.TextSpan
+		if a.Type == "highlight" && a.Position != "" && json.Unmarshal([]byte(a.Position), &span) == nil {
+			m.highlighted[library.ParagraphAt(paras, span.Offset)] = true
+		}
+	}
+	if start > 0 {
+		m.status = fmt.Sprintf("resumed at paragraph %d of %d", start+1, len(paras))
+	}
+	return m
+}
+
+// current returns the index of the paragraph at the top of the screen.
+func (m *readerModel) current() int {
+	if len(m.lines) == 0 {
+		return m.start
+	}
+	for i := m.top; i < len(m.lines); i++ {
+		if m.lines[i].para >= 0 {
+			return m.lines[i].para
+		}
+	}
+	return len(m.paras) - 1
+}
+
+// layout wraps the paragraphs to the window, keeping the current paragraph
+// at the top.
+func (m *readerModel) layout() {
+	current := m.current()
+	width := min(m.width-2, 96)
+	wrap := lipgloss.NewStyle().Width(max(width, 20))
+
+	m.lines = m.lines[:0]
+	m.firstLine = make([]int, len(m.paras))
+	for i, p := range m.paras {
+		if i > 0 {
+			m.lines = append(m.lines, readerLine{para: -1})
+		}
+		m.firstLine[i] = len(m.lines)
+		for _, line := range strings.Split(wrap.Render(p.Text), "\n") {
+			m.lines = append(m.lines, readerLine{para: i, text: strings.TrimRight(line, " ")})
+		}
+	}
+	m.top = m.firstLine[current]
+}
+
+// bodyHeight is the number of text lines on screen, leaving the header and
+// footer.
+func (m *readerModel) bodyHeight() int {
+	return max(m.height-2, 1)
+}
+
+// scrollTo moves the top line, stopping once the last paragraph is at the
+// top so every paragraph can become the current one.
+func (m *readerModel) scrollTo(top int) {
+	m.top = max(min(top, m.firstLine[len(m.paras)-1]), 0)
+}
+
+func (m *readerModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *readerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.layout()
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.prompting {
+			return m.updateInput(msg)
+		}
+		return m.updateKeys(msg)
+	}
+	return m, nil
+}
+
+func (m *readerModel) updateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.prompting = false
+		m.input.Blur()
+		m.status = ""
+		return m, nil
+	case tea.KeyEnter:
+		front := strings.TrimSpace(m.input.Value())
+		m.prompting = false
+		m.input.Blur()
+		if front == "" {
+			m.status = ""
+			return m, nil
+		}
+		now := time.Now()
+		card := &library.Flashcard{
+			DocumentID: m.doc.ID,
+			Type:       "basic",
+			Front:      front,
+			Back:       m.paras[m.current()].Text,
+			DueAt:      library.DueDate(library.Now(), 1),
+			Ease:       scheduler.Current().InitialEase,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+		if err := m.store.AddFlashcard(card); err != nil {
+			m.status = "error: " + err.Error()
+		} else {
+			m.status = "flashcard created: " + card.ID
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m *readerModel) updateKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if len(m.lines) == 0 {
+		if s := msg.String(); s == "q" || s == "esc" || s == "ctrl+c" {
+			return m, tea.Quit
+		}
+		return m, nil
+	}
+	page := max(m.bodyHeight()-1, 1)
+
+	switch msg.String() {
+	case "q", "esc", "ctrl+c":
+		return m, tea.Quit
+
+	case "j", "down":
+		m.scrollTo(m.top + 1)
+	case "k", "up":
+		m.scrollTo(m.top - 1)
+	case " ", "pgdown":
+		m.scrollTo(m.top + page)
+	case "b", "pgup":
+		m.scrollTo(m.top - page)
+	case "n", "}":
+		if next := m.current() + 1; next < len(m.paras) {
+			m.scrollTo(m.firstLine[next])
+		}
+	case "p", "{":
+		cur := m.current()
+		if m.top > m.firstLine[cur] {
+			// Partway into a paragraph, go back to its start first
+			m.scrollTo(m.firstLine[cur])
+		} else if cur > 0 {
+			m.scrollTo(m.firstLine[cur-1])
+		}
+	case "g", "home":
+		m.scrollTo(0)
+	case "G", "end":
+		m.scrollTo(len(m.lines))
+
+	case "h":
+		cur := m.current()
+		if m.highlighted[cur] {
+			m.status = "already highlighted"
+			return m, nil
+		}
+		p := m.paras[cur]
+		position, _ := json.Marshal(library.TextSpan{Offset: p.Offset, Length: p.Length})
+		ann := &library.Annotation{
+			DocumentID: m.doc.ID,
+			Type:       "highlight",
+			Content:    p.Text,
+			Page:       p.Page,
+			Position:   string(position),
+		}
+		_ = library.LinkOpenSession(m.store, ann)
+		if err := m.store.AddAnnotation(ann); err != nil {
+			m.status = "error: " + err.Error()
+			return m, nil
+		}
+		m.highlighted[cur] = true
+		m.status = fmt.Sprintf("highlighted paragraph %d", cur+1)
+
+	case "f":
+		m.prompting = true
+		m.input.SetValue("")
+		m.status = ""
+		return m, m.input.Focus()
+	}
+	return m, nil
+}
+
+var (
+	readerCurrentStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("69"))
+	readerHighlightStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("220"))
+)
+
+func (m *readerModel) View() string {
+	if m.width == 0 {
+		return "loading..."
+	}
+
+	cur := m.current()
+	header := m.doc.Title
+	if p := m.paras[cur].Page; p > 0 {
+		header += fmt.Sprintf(" · p.%d", p)
+	}
+	header += fmt.Sprintf(" · ¶ %d/%d · %d%%", cur+1, len(m.paras), (cur+1)*100/len(m.paras))
+
+	var b strings.Builder
+	b.WriteString(tuiHeadingStyle.Render(truncate(header, max(m.width, 4))) + "\n")
+	height := m.bodyHeight()
+	for i := m.top; i < m.top+height; i++ {
+		if i < len(m.lines) {
+			line := m.lines[i]
+			switch {
+			case line.para < 0:
+			case line.para == cur:
+				b.WriteString(readerCurrentStyle.Render("▌") + " ")
+			case m.highlighted[line.para]:
+				b.WriteString(readerHighlightStyle.Render("┃") + " ")
+			default:
+				b.WriteString("  ")
+			}
+			if line.para == cur && m.highlighted[cur] {
+				b.WriteString(readerHighlightStyle.Render(line.text))
+			} else {
+				b.WriteString(line.text)
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	footer := tuiDimStyle.Render("space/b page · n/p paragraph · h highlight · f flashcard · q quit")
+	if m.prompting {
+		footer = m.input.View()
+	} else if m.status != "" {
+		footer = m.status
+	}
+	return b.String() + footer
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/mtreilly/arc-library/internal/library"
+	"github.com/spf13/cobra"
+)
+
+// maxReadErrorsShown caps the unreadable records listed after a command;
+// the rest are counted.
+const maxReadErrorsShown = 10
+
+// addStrictFlag adds --strict, which fails a command on the first record
+// in the library it cannot read. Without it such records are skipped and,
+// once the command is done (see ReportReadErrors), listed on stderr.
+func addStrictFlag(root *cobra.Command) {
+	var strict bool
+	root.PersistentFlags().BoolVar(&strict, "strict", false,
+		"Fail on records in the library that cannot be read instead of skipping them")
+
+	next := root.PersistentPreRunE
+	root.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		library.SetStrictReads(strict)
+		if next != nil {
+			return next(cmd, args)
+		}
+		return nil
+	}
+}
+
+// ReportReadErrors warns of the records a command skipped because they
+// could not be read. It is called once the command returns, failed or
+// not; long-running commands also report them as they go, per request or
+// file.
+func ReportReadErrors() {
+	reportReadErrors(library.TakeReadErrors())
+}
+
+// reportingReadErrors wraps h to report the records each request could
+// not read once it is answered.
+func reportingReadErrors(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.ServeHTTP(w, r)
+		ReportReadErrors()
+	})
+}
+
+// reportReadErrors warns of errs, and of more records not listed there.
+func reportReadErrors(errs []*library.ReadError, more int) {
+	if len(errs) == 0 {
+		return
+	}
+	if globalOutput.logFormat == "json" {
+		for _, e := range errs {
+			slog.Warn("unreadable record", "entity", e.Entity, "id", e.ID, "error", e.Err.Error())
+		}
+		if more > 0 {
+			slog.Warn("unreadable records not listed", "count", more)
+		}
+		return
+	}
+	warnf("Warning: %d record(s) could not be read and were skipped (--strict fails on them):\n", len(errs)+more)
+	for i, e := range errs {
+		if i == maxReadErrorsShown {
+			break
+		}
+		warnf("  %v\n", e)
+	}
+	if n := len(errs) + more - maxReadErrorsShown; n > 0 {
+		warnf("  ... and %d more\n", n)
+	}
+}
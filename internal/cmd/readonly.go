@@ -0,0 +1,63 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"os"
+
+	"github.com/mtreilly/arc-library/internal/library"
+	"github.com/spf13/cobra"
+)
+
+// readOnlyState is the read-only store's state, set up by addReadOnlyFlag.
+var readOnlyState = &library.ReadOnly{}
+
+// readOnly reports whether --read-only was given. Commands check it to
+// skip writes they would make on the side, such as archiving.
+func readOnly() bool {
+	return readOnlyState.Enabled
+}
+
+// longRunning reports whether cmd mostly reads and runs until stopped:
+// serve, tui, watch, and inbox email with --interval. Such commands take
+// the library lock for each write rather than keep it.
+func longRunning(cmd *cobra.Command) bool {
+	switch cmd.Name() {
+	case "serve", "tui", "watch":
+		return true
+	case "email":
+		interval, err := cmd.Flags().GetDuration("interval")
+		return cmd.Parent() != nil && cmd.Parent().Name() == "inbox" && err == nil && interval > 0
+	}
+	return false
+}
+
+// addReadOnlyFlag adds --read-only, which makes s refuse every write, and
+// otherwise has s lock the library on the first write, so that only one
+// process writes to it at a time. Reads never take the lock. While
+// another process holds it, such as a long import, writes fail naming
+// the holder instead of waiting.
+func addReadOnlyFlag(root *cobra.Command, s *library.ReadOnlyStore) {
+	readOnlyState = s.ReadOnly
+	var flag bool
+	root.PersistentFlags().BoolVar(&flag, "read-only", os.Getenv("ARC_LIBRARY_READ_ONLY") != "",
+		"Never write to the library, e.g. to browse it or serve it while another command changes it")
+
+	next := root.PersistentPreRunE
+	root.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		switch {
+		case flag:
+			s.Enabled, s.Reason = true, "opened with --read-only"
+		case os.Getenv("ARC_LIBRARY_STORAGE") == "memory":
+			// Nothing outlives the process to protect
+		default:
+			s.LockPath = library.LockPath()
+			s.PerWrite = longRunning(cmd)
+		}
+		if next != nil {
+			return next(cmd, args)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/mtreilly/arc-library/internal/library"
+	"github.com/spf13/cobra"
+	"github.com/yourorg/arc-sdk/config"
+	"github.com/yourorg/arc-sdk/output"
+)
+
+func newRecentCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command {
+	var out output.OutputOptions
+	var days int
+	var read bool
+	var byMonth bool
+	var months int
+
+	cmd := &cobra.Command{
+		Use:   "recent",
+		Short: "Show recently added or recently read documents",
+		Long: `Review the library by date.
+
+By default lists documents added in the last N days. With --read, lists
+documents read in that window instead. With --by-month, summarises
+completed reads per month.
+
+Examples:
+  arc-library recent                  # Added in the last 7 days
+  arc-library recent --days 30 --read # Read in the last 30 days
+  arc-library recent --by-month       # Completed reads for the last 12 months`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := out.Resolve(); err != nil {
+				return err
+			}
+			if days <= 0 {
+				return fmt.Errorf("--days must be positive")
+			}
+
+			if byMonth {
+				return runCompletedByMonth(store, &out, months)
+			}
+
+			since := time.Now().AddDate(0, 0, -days)
+			opts := &library.ListOptions{CreatedAfter: since}
+			if read {
+				opts = &library.ListOptions{ReadAfter: since}
+			}
+			docs, err := store.ListDocuments(opts)
+			if err != nil {
+				return err
+			}
+			if read {
+				// Marked unread again since: not a recent read
+				docs = slices.DeleteFunc(docs, func(d *library.Document) bool { return documentStatus(d) == library.StatusUnread })
+			}
+
+			dateOf := func(d *library.Document) time.Time { return d.CreatedAt }
+			if read {
+				dateOf = func(d *library.Document) time.Time { return d.ReadAt }
+			}
+			sort.SliceStable(docs, func(i, j int) bool { return dateOf(docs[i]).After(dateOf(docs[j])) })
+
+			if jsonOutput(&out) {
+				return output.JSON(nonNil(docs))
+			}
+			if quietOutput() {
+				printIDs(documentIDs(docs)...)
+				return nil
+			}
+
+			verb := "added"
+			if read {
+				verb = "read"
+			}
+			if len(docs) == 0 {
+				fmt.Printf("No documents %s in the last %d day(s).\n", verb, days)
+				return nil
+			}
+
+			header := "Added"
+			if read {
+				header = "Read"
+			}
+			table := output.NewTable(header, "Type", "Title", "Status")
+			for _, d := range docs {
+				table.AddRow(dateOf(d).Format("2006-01-02"), string(d.Type), truncate(d.Title, 50), string(documentStatus(d)))
+			}
+			table.Render()
+
+			fmt.Printf("\n%d document(s) %s in the last %d day(s)\n", len(docs), verb, days)
+			return nil
+		},
+	}
+
+	out.AddOutputFlags(cmd, output.OutputTable)
+	cmd.Flags().IntVarP(&days, "days", "d", 7, "Look back this many days")
+	cmd.Flags().BoolVar(&read, "read", false, "Show documents read (instead of added) in the window")
+	cmd.Flags().BoolVar(&byMonth, "by-month", false, "Summarise completed reads per month")
+	cmd.Flags().IntVar(&months, "months", 12, "Months to include with --by-month")
+
+	return cmd
+}
+
+// monthCount is the JSON schema for one row of "recent --by-month".
+type monthCount struct {
+	Month       string   `json:"month"` // YYYY-MM
+	Completed   int      `json:"completed"`
+	DocumentIDs []string `json:"document_ids"`
+}
+
+// runCompletedByMonth groups completed documents by the month they were read,
+// newest month first, including months with no completions.
+func runCompletedByMonth(store library.LibraryStore, out *output.OutputOptions, months int) error {
+	if months <= 0 {
+		return fmt.Errorf("--months must be positive")
+	}
+
+	now := time.Now()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local).AddDate(0, -(months - 1), 0)
+	docs, err := store.ListDocuments(&library.ListOptions{
+		Status:    string(library.StatusCompleted),
+		ReadAfter: start,
+	})
+	if err != nil {
+		return err
+	}
+
+	rows := make([]monthCount, months)
+	index := make(map[string]int, months)
+	for i := range rows {
+		key := start.AddDate(0, months-1-i, 0).Format("2006-01")
+		rows[i] = monthCount{Month: key, DocumentIDs: []string{}}
+		index[key] = i
+	}
+	for _, d := range docs {
+		if i, ok := index[d.ReadAt.Local().Format("2006-01")]; ok {
+			rows[i].Completed++
+			rows[i].DocumentIDs = append(rows[i].DocumentIDs, d.ID)
+		}
+	}
+
+	if jsonOutput(out) {
+		return output.JSON(rows)
+	}
+	if quietOutput() {
+		printIDs(documentIDs(docs)...)
+		return nil
+	}
+
+	table := output.NewTable("Month", "Completed", "")
+	for _, r := range rows {
+		table.AddRow(r.Month, fmt.Sprintf("%d", r.Completed), bar(r.Completed))
+	}
+	table.Render()
+
+	fmt.Printf("\n%d document(s) completed in the last %d month(s)\n", len(docs), months)
+	return nil
+}
+
+// bar renders n as a simple text histogram bar, capped to keep tables narrow.
+func bar(n int) string {
+	const maxWidth = 40
+	if n > maxWidth {
+		n = maxWidth
+	}
+	b := make([]rune, n)
+	for i := range b {
+		b[i] = '█'
+	}
+	return string(b)
+}
@@ -0,0 +1,187 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mtreilly/arc-library/internal/library"
+	"github.com/spf13/cobra"
+	"github.com/yourorg/arc-sdk/config"
+	"github.com/yourorg/arc-sdk/output"
+)
+
+func newRefreshMetadataCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command {
+	var (
+		source string
+		all    bool
+		apply  bool
+		resume bool
+		batch  int
+		maxAge time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "refresh-metadata [document-id...]",
+		Short: "Re-resolve metadata of existing documents",
+		Long: `Look up the metadata of existing documents again, by their arXiv ID
+(--source arxiv) or DOI (--source doi), to pick up corrected titles, added
+abstracts, and publication details. Fields the lookup has no value for are
+left alone.
+
+Without --apply nothing is changed: the command reports what would change.
+Run it again with --apply to write the changes; they are recorded in each
+document's history and can be reverted with "doc revert".
+
+Lookups follow the API rate limits (see ARC_LIBRARY_API_RATE). Documents
+are processed in batches, saving a checkpoint after each; if an API keeps
+refusing requests or the run is interrupted, --resume continues after the
+last checkpoint. Cached responses older than --max-age are fetched again.
+
+Examples:
+  arc-library refresh-metadata --source arxiv --all
+  arc-library refresh-metadata --source arxiv --all --apply
+  arc-library refresh-metadata --source doi --all --apply --resume
+  arc-library refresh-metadata --source doi 10.1038/nature12373`,
+		ValidArgsFunction: completeDocuments(store),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if all == (len(args) > 0) {
+				return fmt.Errorf("give document IDs or --all")
+			}
+			var docs []*library.Document
+			if all {
+				var err error
+				if docs, err = store.ListDocuments(nil); err != nil {
+					return err
+				}
+			} else {
+				for _, arg := range args {
+					doc, err := lookupDocument(store, arg)
+					if err != nil {
+						return err
+					}
+					if library.RefreshIdentifier(doc, source) == "" {
+						warnf("%s has no %s identifier; skipping\n", doc.ID, source)
+					}
+					docs = append(docs, doc)
+				}
+			}
+
+			library.LimitMetadataAge(maxAge)
+			// The run knows how many documents are left to check; a resumed
+			// one, fewer than docs
+			var bar *progress
+			refresh := &library.MetadataRefresh{
+				Store:     store,
+				Source:    source,
+				Apply:     apply,
+				BatchSize: batch,
+				Resume:    resume,
+				OnProgress: func(done, total int) {
+					if bar == nil {
+						bar = newProgress("Checking", total)
+					}
+					bar.set(done, "")
+				},
+			}
+			// Only whole-library runs are worth resuming
+			if all {
+				path, err := library.RefreshCheckpointPath(source, apply)
+				if err != nil {
+					return err
+				}
+				refresh.Checkpoint = path
+			}
+			result, runErr := refresh.Run(docs)
+			bar.finish()
+			if result == nil {
+				return runErr
+			}
+			var rateLimited *library.RateLimitError
+			if errors.As(runErr, &rateLimited) {
+				warnf("Stopped with %d document(s) left: %v\n", result.Remaining, runErr)
+				warnf("Run the same command with --resume to continue\n")
+			} else if runErr != nil {
+				return runErr
+			}
+
+			if jsonOutput(nil) {
+				if err := output.JSON(result); err != nil {
+					return err
+				}
+				return runErr
+			}
+			if quietOutput() {
+				for _, d := range result.Documents {
+					if d.Error == "" {
+						fmt.Println(d.DocumentID)
+					}
+				}
+				return runErr
+			}
+			printRefreshResult(result)
+			return runErr
+		},
+	}
+
+	cmd.Flags().StringVar(&source, "source", "", "Identifier to look documents up by: arxiv or doi (required)")
+	cmd.Flags().BoolVar(&all, "all", false, "Refresh every document with such an identifier")
+	cmd.Flags().BoolVar(&apply, "apply", false, "Write the changes (default: only report them)")
+	cmd.Flags().BoolVar(&resume, "resume", false, "Continue an interrupted --all run from its checkpoint")
+	cmd.Flags().IntVar(&batch, "batch", library.DefaultRefreshBatch, "Documents per batch between checkpoints")
+	cmd.Flags().DurationVar(&maxAge, "max-age", 24*time.Hour, "Refetch cached responses older than this")
+	cmd.MarkFlagRequired("source")
+	cmd.RegisterFlagCompletionFunc("source", cobra.FixedCompletions(library.RefreshSources, cobra.ShellCompDirectiveNoFileComp))
+
+	return cmd
+}
+
+// printRefreshResult shows each changed document's field changes, then a
+// summary line.
+func printRefreshResult(result *library.RefreshResult) {
+	for _, d := range result.Documents {
+		fmt.Printf("%s  %s (%s)\n", d.DocumentID, truncate(d.Title, 60), d.Identifier)
+		if d.Error != "" {
+			fmt.Printf("  error: %s\n", d.Error)
+		}
+		for _, c := range d.Changes {
+			fmt.Printf("  %s: %s -> %s\n", c.Field, refreshValue(c.Old), refreshValue(c.New))
+		}
+	}
+
+	verb := "would change"
+	if result.Applied {
+		verb = "changed"
+	}
+	summary := fmt.Sprintf("Checked %d document(s): %d %s, %d failed", result.Checked, result.Changed, verb, result.Failed)
+	if result.Resumed {
+		summary += " (resumed)"
+	}
+	fmt.Println(summary)
+	if !result.Applied && result.Changed > 0 {
+		fmt.Println("Run again with --apply to write the changes.")
+	}
+}
+
+// refreshValue renders a changed value on one line.
+func refreshValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "(none)"
+	case string:
+		if v == "" {
+			return "(none)"
+		}
+		return fmt.Sprintf("%q", truncate(strings.Join(strings.Fields(v), " "), 70))
+	case []string:
+		if len(v) == 0 {
+			return "(none)"
+		}
+		return truncate(strings.Join(v, ", "), 70)
+	}
+	return truncate(fmt.Sprint(v), 70)
+}
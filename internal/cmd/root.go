@@ -4,8 +4,15 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/spf13/cobra"
 	"github.com/mtreilly/arc-library/internal/library"
+	"github.com/mtreilly/arc-library/internal/scheduler"
 	"github.com/yourorg/arc-sdk/config"
 )
 
@@ -25,21 +32,200 @@ arc-library provides tools to:
 - Search across your library`,
 	}
 
+	// Every command writes through the dry-run store, which holds writes
+	// back under --dry-run, and the read-only store, which refuses them
+	// under --read-only or while another process has the library locked
+	ro := library.NewReadOnlyStore(store)
+	dry := library.NewDryRunStore(ro)
+	store = dry
+
+	addGlobalOutputFlags(root)
+	addResolverFlags(root)
+	addSchedulerConfig(root)
+	addLogFormatFlag(root)
+	addDryRunFlag(root, dry)
+	addStrictFlag(root)
+	addReadOnlyFlag(root, ro)
+	addArchivePolicy(root, store)
+
 	root.AddCommand(newImportCmd(cfg, store))
+	root.AddCommand(newAddCmd(cfg, store))
+	root.AddCommand(newNoteCmd(cfg, store))
+	root.AddCommand(newFetchCmd(cfg, store))
 	root.AddCommand(newTagCmd(cfg, store))
+	root.AddCommand(newFieldCmd(cfg, store))
+	root.AddCommand(newLanguageCmd(cfg, store))
 	root.AddCommand(newCollectionCmd(cfg, store))
 	root.AddCommand(newListCmd(cfg, store))
+	root.AddCommand(newDocCmd(cfg, store))
 	root.AddCommand(newSearchCmd(cfg, store))
 	root.AddCommand(newAnnotateCmd(cfg, store))
 	root.AddCommand(newSessionCmd(cfg, store))
 	root.AddCommand(newStatsCmd(cfg, store))
+	root.AddCommand(newAuditCmd(cfg, store))
+	root.AddCommand(newRecentCmd(cfg, store))
+	root.AddCommand(newJournalCmd(cfg, store))
+	root.AddCommand(newLogCmd(cfg, store))
+	root.AddCommand(newDiffCmd(cfg, store))
+	root.AddCommand(newDigestCmd(cfg, store))
+	root.AddCommand(newShareCmd(cfg, store))
+	root.AddCommand(newTokenCmd(cfg, store))
+	root.AddCommand(newInboxCmd(cfg, store))
+	root.AddCommand(newQueueCmd(cfg, store))
 	root.AddCommand(newFlashcardCmd(cfg, store))
+	root.AddCommand(newDueCmd(cfg, store))
 	root.AddCommand(newExportCmd(cfg, store))
+	root.AddCommand(newFormatsCmd(cfg, store))
+	root.AddCommand(newPluginCmd(cfg, store))
 	root.AddCommand(newAICmd(cfg, store))
+	root.AddCommand(newOCRCmd(cfg, store))
+	root.AddCommand(newPathsCmd(cfg, store))
+	root.AddCommand(newDoctorCmd(cfg, store))
+	root.AddCommand(newDBCmd(cfg, store))
+	root.AddCommand(newIndexCmd(cfg, store))
+	root.AddCommand(newBenchCmd())
 	root.AddCommand(newDuplicatesCmd(cfg, store))
+	root.AddCommand(newRefreshMetadataCmd(cfg, store))
 	root.AddCommand(newWatchCmd(cfg, store))
+	root.AddCommand(newSyncCmd(cfg, store))
 	root.AddCommand(newTaskCmd(cfg, store))
 	root.AddCommand(newWebCmd(cfg, store))
+	root.AddCommand(newTUICmd(cfg, store))
+	root.AddCommand(newReadCmd(cfg, store))
+	root.AddCommand(newUndoCmd(cfg, store))
+	root.AddCommand(newCompletionCmd())
+	addPluginCmds(root, store)
+
+	// The explicit completion command replaces cobra's default one so that
+	// only the supported shells are advertised.
+	root.CompletionOptions.DisableDefaultCmd = true
+	registerFlagCompletions(root, store)
 
 	return root
 }
+
+// addResolverFlags adds --offline and, before any command runs, sets up
+// the metadata resolvers (DOI, PubMed, arXiv, ...) from the environment:
+// the on-disk cache they share, kept for ARC_LIBRARY_METADATA_TTL, and how
+// politely they query the APIs.
+func addResolverFlags(root *cobra.Command) {
+	var offline bool
+	root.PersistentFlags().BoolVar(&offline, "offline", os.Getenv("ARC_LIBRARY_OFFLINE") != "",
+		"Resolve metadata from the cache only and skip downloads")
+	root.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		opts, ttl, err := resolverSettings()
+		if err != nil {
+			return err
+		}
+		opts.OnRetry = func(host, status string, wait time.Duration) {
+			warnf("%s: %s, retrying in %s\n", host, status, wait.Round(time.Second))
+		}
+		library.SetResolverOptions(opts)
+
+		dir, err := library.MetadataCacheDir()
+		if err != nil {
+			if offline {
+				return err
+			}
+			// Resolve without a cache rather than fail the command
+			return nil
+		}
+		library.SetMetadataCache(&library.MetadataCache{Dir: dir, TTL: ttl, Offline: offline})
+		return nil
+	}
+}
+
+// resolverSettings reads the resolver options and the metadata cache's
+// TTL from the ARC_LIBRARY_* environment variables.
+func resolverSettings() (library.ResolverOptions, time.Duration, error) {
+	opts := library.ResolverOptions{
+		Email:   os.Getenv("ARC_LIBRARY_CONTACT_EMAIL"),
+		Rate:    library.DefaultResolverRate,
+		Retries: library.DefaultResolverRetries,
+	}
+	if s := os.Getenv("ARC_LIBRARY_API_RATE"); s != "" {
+		rate, err := strconv.ParseFloat(s, 64)
+		if err != nil || rate < 0 {
+			return opts, 0, fmt.Errorf("invalid ARC_LIBRARY_API_RATE %q (expected requests per second)", s)
+		}
+		opts.Rate = rate
+	}
+	if s := os.Getenv("ARC_LIBRARY_API_RETRIES"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return opts, 0, fmt.Errorf("invalid ARC_LIBRARY_API_RETRIES %q", s)
+		}
+		opts.Retries = n
+	}
+
+	ttl := library.DefaultMetadataTTL
+	if s := os.Getenv("ARC_LIBRARY_METADATA_TTL"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d < 0 {
+			return opts, 0, fmt.Errorf("invalid ARC_LIBRARY_METADATA_TTL %q (expected a duration like 720h)", s)
+		}
+		ttl = d
+	}
+	return opts, ttl, nil
+}
+
+// addSchedulerConfig tunes the SM-2 scheduling of flashcard and document
+// reviews from the ARC_LIBRARY_SRS_* environment variables, and sets the
+// home timezone due dates are reckoned in from ARC_LIBRARY_TIMEZONE,
+// before any command runs (see "flashcard settings").
+func addSchedulerConfig(root *cobra.Command) {
+	next := root.PersistentPreRunE
+	root.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		cfg, err := schedulerSettings()
+		if err != nil {
+			return err
+		}
+		if err := scheduler.Set(cfg); err != nil {
+			return fmt.Errorf("spaced repetition settings: %w", err)
+		}
+		loc, err := library.HomeTimezoneSetting()
+		if err != nil {
+			return err
+		}
+		library.SetHomeLocation(loc)
+		if next != nil {
+			return next(cmd, args)
+		}
+		return nil
+	}
+}
+
+// schedulerSettings reads the SM-2 settings from the ARC_LIBRARY_SRS_*
+// environment variables over the defaults.
+func schedulerSettings() (scheduler.Config, error) {
+	cfg := scheduler.Default()
+	for _, v := range []struct {
+		env, example string
+		value        *float64
+	}{
+		{"ARC_LIBRARY_SRS_INITIAL_EASE", "2.5", &cfg.InitialEase},
+		{"ARC_LIBRARY_SRS_MIN_EASE", "1.3", &cfg.MinEase},
+		{"ARC_LIBRARY_SRS_MAX_EASE", "2.5", &cfg.MaxEase},
+		{"ARC_LIBRARY_SRS_INTERVAL_MODIFIER", "0.8", &cfg.IntervalModifier},
+		{"ARC_LIBRARY_SRS_FUZZ", "0.05", &cfg.Fuzz},
+	} {
+		if s := os.Getenv(v.env); s != "" {
+			f, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return cfg, fmt.Errorf("invalid %s %q (expected a number like %s)", v.env, s, v.example)
+			}
+			*v.value = f
+		}
+	}
+	if s := os.Getenv("ARC_LIBRARY_SRS_STEPS"); s != "" {
+		cfg.LearnSteps = nil
+		for _, f := range strings.Split(s, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(f))
+			if err != nil {
+				return cfg, fmt.Errorf("invalid ARC_LIBRARY_SRS_STEPS %q (expected days like 1,6)", s)
+			}
+			cfg.LearnSteps = append(cfg.LearnSteps, n)
+		}
+	}
+	return cfg, nil
+}
@@ -21,9 +21,9 @@ func newSearchCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command
 	}
 
 	cmd.AddCommand(newSearchRunCmd(store))
-	cmd.AddCommand(newSearchSaveCmd(store))
-	cmd.AddCommand(newSearchListCmd(store))
-	cmd.AddCommand(newSearchDeleteCmd(store))
+	saved := []*cobra.Command{newSearchSaveCmd(store), newSearchListCmd(store), newSearchDeleteCmd(store)}
+	requireCapability(store, func(c library.Capabilities) bool { return c.SavedSearches }, "saved searches", saved...)
+	cmd.AddCommand(saved...)
 
 	return cmd
 }
@@ -33,14 +33,27 @@ func newSearchRunCmd(store library.LibraryStore) *cobra.Command {
 	var tag string
 	var source string
 	var docType string
+	var filters documentFilters
 	var limit int
+	var section string
 
 	cmd := &cobra.Command{
 		Use:   "run <query-or-saved-search>",
 		Short: "Search documents (or load a saved search)",
 		Long: `Search across document titles, abstracts, and notes.
-If the argument matches a saved search name, that search is loaded instead.`,
-		Args: cobra.ExactArgs(1),
+If the argument matches a saved search name, that search is loaded instead.
+
+Words of the form <field><op><value> naming a custom field (see "field
+define") filter on that field instead, with op one of = : != < <= > >=:
+
+  arc-library search run "attention year>=2020 venue:NeurIPS"
+
+--section only matches documents whose full text has that section (as
+listed by "doc sections") containing every word of the query:
+
+  arc-library search run dropout --section methods`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeFirst(completeSavedSearches(store)),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := out.Resolve(); err != nil {
 				return err
@@ -48,10 +61,13 @@ If the argument matches a saved search name, that search is loaded instead.`,
 
 			arg := args[0]
 
-			// Check if it's a saved search
-			saved, err := store.GetSavedSearch(arg)
-			if err != nil {
-				return err
+			// Check if it's a saved search, where the backend keeps them
+			var saved *library.SavedSearch
+			if store.Capabilities().SavedSearches {
+				var err error
+				if saved, err = store.GetSavedSearch(arg); err != nil {
+					return err
+				}
 			}
 
 			var opts *library.ListOptions
@@ -73,7 +89,7 @@ If the argument matches a saved search name, that search is loaded instead.`,
 				if docType != "" {
 					opts.Type = docType
 				}
-				fmt.Printf("Loaded saved search: %s\n\n", saved.Name)
+				infof("Loaded saved search: %s\n\n", saved.Name)
 			} else {
 				// Regular search
 				opts = &library.ListOptions{
@@ -84,11 +100,40 @@ If the argument matches a saved search name, that search is loaded instead.`,
 					Limit:  limit,
 				}
 			}
+			// Conditions on custom fields, such as year>=2020, can be mixed into the query
+			if defs, err := store.ListFields(); err == nil && len(defs) > 0 {
+				text, conds, err := library.SplitFieldQuery(opts.Search, defs)
+				if err != nil {
+					return err
+				}
+				opts.Search = text
+				opts.Fields = conds
+			}
+			if err := filters.apply(store, opts); err != nil {
+				return err
+			}
+			if section != "" {
+				// The limit applies after the section filter
+				opts.Limit = 0
+			}
 
 			documents, err := store.ListDocuments(opts)
 			if err != nil {
 				return err
 			}
+			if section != "" {
+				if documents, err = filterBySection(store, documents, section, opts.Search, limit); err != nil {
+					return err
+				}
+			}
+
+			if jsonOutput(&out) {
+				return output.JSON(nonNil(documents))
+			}
+			if quietOutput() {
+				printIDs(documentIDs(documents)...)
+				return nil
+			}
 
 			if len(documents) == 0 {
 				if saved != nil {
@@ -99,10 +144,6 @@ If the argument matches a saved search name, that search is loaded instead.`,
 				return nil
 			}
 
-			if out.Is(output.OutputJSON) {
-				return output.JSON(documents)
-			}
-
 			queryStr := arg
 			if saved != nil {
 				queryStr = saved.Query
@@ -135,10 +176,34 @@ If the argument matches a saved search name, that search is loaded instead.`,
 	cmd.Flags().StringVarP(&source, "source", "s", "", "Filter by source")
 	cmd.Flags().StringVar(&docType, "type", "", "Filter by document type")
 	cmd.Flags().IntVarP(&limit, "limit", "n", 50, "Limit number of results")
+	cmd.Flags().StringVar(&section, "section", "", "Only match the query within this section (e.g. methods, results)")
+	filters.addFlags(cmd)
 
 	return cmd
 }
 
+// filterBySection keeps the documents whose full text has the named
+// section containing every word of query, up to limit (0 for all).
+func filterBySection(store library.LibraryStore, docs []*library.Document, section, query string, limit int) ([]*library.Document, error) {
+	var kept []*library.Document
+	for _, d := range docs {
+		if d.FullText == "" {
+			continue
+		}
+		secs, err := library.LoadDocumentSections(store, d)
+		if err != nil {
+			return nil, fmt.Errorf("load sections of %s: %w", d.ID, err)
+		}
+		if sec := secs.Find(section); sec != nil && library.SectionContains(d.FullText, sec, query) {
+			kept = append(kept, d)
+			if limit > 0 && len(kept) >= limit {
+				break
+			}
+		}
+	}
+	return kept, nil
+}
+
 func newSearchSaveCmd(store library.LibraryStore) *cobra.Command {
 	var name string
 	var tag string
@@ -166,7 +231,7 @@ func newSearchSaveCmd(store library.LibraryStore) *cobra.Command {
 			// Check for existing
 			existing, _ := store.GetSavedSearch(name)
 			if existing != nil {
-				fmt.Printf("Updating existing saved search: %s\n", name)
+				infof("Updating existing saved search: %s\n", name)
 			}
 
 			ss := &library.SavedSearch{
@@ -182,6 +247,14 @@ func newSearchSaveCmd(store library.LibraryStore) *cobra.Command {
 				return fmt.Errorf("save search: %w", err)
 			}
 
+			if jsonOutput(nil) {
+				return output.JSON(ss)
+			}
+			if quietOutput() {
+				printIDs(ss.Name)
+				return nil
+			}
+
 			fmt.Printf("Search saved as: %s\n", name)
 			fmt.Printf("Query: %s\n", query)
 			if tag != "" {
@@ -226,8 +299,14 @@ func newSearchListCmd(store library.LibraryStore) *cobra.Command {
 				return fmt.Errorf("list searches: %w", err)
 			}
 
-			if out.Is(output.OutputJSON) {
-				return output.JSON(searches)
+			if jsonOutput(&out) {
+				return output.JSON(nonNil(searches))
+			}
+			if quietOutput() {
+				for _, ss := range searches {
+					printIDs(ss.Name)
+				}
+				return nil
 			}
 
 			if len(searches) == 0 {
@@ -276,9 +355,10 @@ func newSearchListCmd(store library.LibraryStore) *cobra.Command {
 
 func newSearchDeleteCmd(store library.LibraryStore) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "delete <name>",
-		Short: "Delete a saved search",
-		Args:  cobra.ExactArgs(1),
+		Use:               "delete <name>",
+		Short:             "Delete a saved search",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeFirst(completeSavedSearches(store)),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			name := args[0]
 
@@ -294,7 +374,10 @@ func newSearchDeleteCmd(store library.LibraryStore) *cobra.Command {
 				return fmt.Errorf("delete search: %w", err)
 			}
 
-			fmt.Printf("Deleted saved search: %s\n", name)
+			if jsonOutput(nil) {
+				return output.JSON(deleteResult{Kind: "saved_search", ID: ss.ID, Deleted: true})
+			}
+			infof("Deleted saved search: %s\n", name)
 			return nil
 		},
 	}
@@ -4,11 +4,16 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/mtreilly/arc-library/internal/library"
+	"github.com/mtreilly/arc-library/internal/scheduler"
 	"github.com/yourorg/arc-sdk/config"
 	"github.com/yourorg/arc-sdk/output"
 )
@@ -23,6 +28,7 @@ func newSessionCmd(cfg *config.Config, store library.LibraryStore) *cobra.Comman
 	cmd.AddCommand(newSessionStartCmd(store))
 	cmd.AddCommand(newSessionEndCmd(store))
 	cmd.AddCommand(newSessionListCmd(store))
+	cmd.AddCommand(newSessionShowCmd(store))
 
 	return cmd
 }
@@ -31,9 +37,10 @@ func newSessionStartCmd(store library.LibraryStore) *cobra.Command {
 	var out output.OutputOptions
 
 	cmd := &cobra.Command{
-		Use:   "start <document-id>",
-		Short: "Start a reading session for a document",
-		Args:  cobra.ExactArgs(1),
+		Use:               "start <document-id>",
+		Short:             "Start a reading session for a document",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeFirst(completeDocuments(store)),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := out.Resolve(); err != nil {
 				return err
@@ -47,7 +54,7 @@ func newSessionStartCmd(store library.LibraryStore) *cobra.Command {
 				return err
 			}
 			if doc == nil {
-				return fmt.Errorf("document not found: %s", docID)
+				return fmt.Errorf("%w: %s", library.ErrDocumentNotFound, docID)
 			}
 
 			session, err := store.StartSession(docID)
@@ -55,9 +62,13 @@ func newSessionStartCmd(store library.LibraryStore) *cobra.Command {
 				return fmt.Errorf("start session: %w", err)
 			}
 
-			if out.Is(output.OutputJSON) {
+			if jsonOutput(&out) {
 				return output.JSON(session)
 			}
+			if quietOutput() {
+				printIDs(session.ID)
+				return nil
+			}
 
 			fmt.Printf("Session started: %s\n", session.ID)
 			fmt.Printf("Document: %s - %s\n", docID, truncate(doc.Title, 50))
@@ -72,15 +83,28 @@ func newSessionStartCmd(store library.LibraryStore) *cobra.Command {
 
 func newSessionEndCmd(store library.LibraryStore) *cobra.Command {
 	var (
-		pages int
-		notes string
-		out   output.OutputOptions
+		pages      int
+		notes      string
+		flashcards bool
+		out        output.OutputOptions
 	)
 
 	cmd := &cobra.Command{
 		Use:   "end <session-id>",
 		Short: "End a reading session",
-		Args:  cobra.ExactArgs(1),
+		Long: `End a reading session, recording the pages read and notes.
+
+Annotations made while the session was open are linked to it. When the
+session has any and the terminal is interactive, you are asked whether to
+create flashcards from them; --flashcards skips the question. Each
+annotation is then shown in turn: type a question for the front, and the
+back defaults to the annotation's text. Enter on an empty question skips
+the annotation, and "q" stops.
+
+Examples:
+  arc-library session end <session-id> --pages 10 --notes "Read intro"
+  arc-library session end <session-id> --flashcards`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := out.Resolve(); err != nil {
 				return err
@@ -91,19 +115,46 @@ func newSessionEndCmd(store library.LibraryStore) *cobra.Command {
 				return fmt.Errorf("end session: %w", err)
 			}
 
-			fmt.Printf("Session ended: %s\n", sessionID)
+			if jsonOutput(nil) {
+				return output.JSON(sessionEndResult{ID: sessionID, PagesRead: pages, Notes: notes})
+			}
+
+			infof("Session ended: %s\n", sessionID)
 			if pages > 0 {
-				fmt.Printf("Pages read: %d\n", pages)
+				infof("Pages read: %d\n", pages)
 			}
 			if notes != "" {
-				fmt.Printf("Notes: %s\n", notes)
+				infof("Notes: %s\n", notes)
 			}
-			return nil
+
+			if quietOutput() || (!flashcards && !stdinIsTerminal()) {
+				return nil
+			}
+			session, err := library.FindSession(store, sessionID)
+			if err != nil {
+				return err
+			}
+			anns, err := library.SessionAnnotations(store, session)
+			if err != nil {
+				return fmt.Errorf("session annotations: %w", err)
+			}
+			if len(anns) == 0 {
+				return nil
+			}
+			in := bufio.NewReader(os.Stdin)
+			if !flashcards {
+				answer := promptLine(in, fmt.Sprintf("\nCreate flashcards from this session's %d annotation(s)? [y/N] ", len(anns)))
+				if a := strings.ToLower(answer); a != "y" && a != "yes" {
+					return nil
+				}
+			}
+			return sessionFlashcards(store, anns, in)
 		},
 	}
 
 	cmd.Flags().IntVarP(&pages, "pages", "p", 0, "Number of pages read")
 	cmd.Flags().StringVarP(&notes, "notes", "n", "", "Session notes")
+	cmd.Flags().BoolVar(&flashcards, "flashcards", false, "Create flashcards from the session's annotations without asking first")
 	out.AddOutputFlags(cmd, output.OutputJSON)
 	return cmd
 }
@@ -162,8 +213,14 @@ func newSessionListCmd(store library.LibraryStore) *cobra.Command {
 				sessions = sessions[:limit]
 			}
 
-			if out.Is(output.OutputJSON) {
-				return output.JSON(sessions)
+			if jsonOutput(&out) {
+				return output.JSON(nonNil(sessions))
+			}
+			if quietOutput() {
+				for _, s := range sessions {
+					printIDs(s.ID)
+				}
+				return nil
 			}
 
 			if len(sessions) == 0 {
@@ -193,3 +250,149 @@ func newSessionListCmd(store library.LibraryStore) *cobra.Command {
 	out.AddOutputFlags(cmd, output.OutputTable)
 	return cmd
 }
+
+func newSessionShowCmd(store library.LibraryStore) *cobra.Command {
+	var markdown bool
+
+	cmd := &cobra.Command{
+		Use:   "show <session-id>",
+		Short: "Show a reading session and what was annotated during it",
+		Long: `Show a reading session's document, duration, pages and notes, with
+the annotations made during it: those linked to the session when they
+were made, and unlinked ones on its document from within its time.
+
+--markdown writes the summary as Markdown, for a note app.
+
+Examples:
+  arc-library session show <session-id>
+  arc-library session show <session-id> --markdown >> reading-log.md`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sum, err := library.SummarizeSession(store, args[0])
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput(nil) {
+				sum.Annotations = nonNil(sum.Annotations)
+				return output.JSON(sum)
+			}
+			if quietOutput() {
+				for _, a := range sum.Annotations {
+					printIDs(a.ID)
+				}
+				return nil
+			}
+			if markdown {
+				fmt.Print(sum.Markdown())
+				return nil
+			}
+
+			s := sum.Session
+			fmt.Printf("Session: %s\n", s.ID)
+			fmt.Printf("Document: %s - %s\n", s.DocumentID, truncate(sum.Title, 50))
+			fmt.Printf("Started: %s\n", s.StartAt.Format("2006-01-02 15:04"))
+			if s.EndAt.IsZero() {
+				fmt.Println("Ended: (in progress)")
+			} else {
+				fmt.Printf("Ended: %s (%d min)\n", s.EndAt.Format("2006-01-02 15:04"), sum.Minutes)
+			}
+			if s.PagesRead > 0 {
+				fmt.Printf("Pages read: %d\n", s.PagesRead)
+			}
+			if s.Notes != "" {
+				fmt.Printf("Notes: %s\n", s.Notes)
+			}
+
+			if len(sum.Annotations) == 0 {
+				fmt.Println("\nNo annotations during this session.")
+				return nil
+			}
+			fmt.Println()
+			table := output.NewTable("Time", "Type", "Page", "Content")
+			for _, a := range sum.Annotations {
+				pageStr := "-"
+				if a.Page > 0 {
+					pageStr = fmt.Sprintf("%d", a.Page)
+				}
+				table.AddRow(a.CreatedAt.Format("15:04"), a.Type, pageStr, truncate(a.Content, 50))
+			}
+			table.Render()
+			fmt.Printf("\nTotal: %d annotation(s)\n", len(sum.Annotations))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&markdown, "markdown", false, "Write the summary as Markdown")
+	return cmd
+}
+
+// sessionEndResult is the JSON schema for "session end".
+type sessionEndResult struct {
+	ID        string `json:"id"`
+	PagesRead int    `json:"pages_read"`
+	Notes     string `json:"notes,omitempty"`
+}
+
+// stdinIsTerminal reports whether standard input is an interactive
+// terminal, for questions a script could not answer.
+func stdinIsTerminal() bool {
+	fi, err := os.Stdin.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// promptLine prints a prompt and reads the answer, trimmed. At the end of
+// the input the answer is empty.
+func promptLine(in *bufio.Reader, prompt string) string {
+	fmt.Print(prompt)
+	line, err := in.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return ""
+	}
+	return strings.TrimSpace(line)
+}
+
+// sessionFlashcards walks through a session's annotations, asking for a
+// question and answer for each, and creates basic flashcards from them,
+// due tomorrow like "flashcard add" makes them.
+func sessionFlashcards(store library.LibraryStore, anns []*library.Annotation, in *bufio.Reader) error {
+	created := 0
+	for i, a := range anns {
+		label := a.Type
+		if a.Page > 0 {
+			label += fmt.Sprintf(", p.%d", a.Page)
+		}
+		fmt.Printf("\n[%d/%d] (%s) %s\n", i+1, len(anns), label, a.Content)
+
+		front := promptLine(in, "Front (enter to skip, q to stop): ")
+		if front == "q" {
+			break
+		}
+		if front == "" {
+			continue
+		}
+		back := promptLine(in, "Back (enter for the annotation): ")
+		if back == "" {
+			back = a.Content
+		}
+
+		now := time.Now()
+		card := &library.Flashcard{
+			DocumentID: a.DocumentID,
+			Type:       "basic",
+			Front:      front,
+			Back:       back,
+			DueAt:      library.DueDate(library.Now(), 1),
+			Ease:       scheduler.Current().InitialEase,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+		if err := store.AddFlashcard(card); err != nil {
+			return fmt.Errorf("add flashcard: %w", err)
+		}
+		created++
+		fmt.Printf("Flashcard created: %s\n", card.ID)
+	}
+	fmt.Printf("\nCreated %d flashcard(s)\n", created)
+	return nil
+}
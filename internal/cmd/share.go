@@ -0,0 +1,223 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mtreilly/arc-library/internal/library"
+	"github.com/spf13/cobra"
+	"github.com/yourorg/arc-sdk/config"
+	"github.com/yourorg/arc-sdk/output"
+)
+
+// defaultShareBaseURL is where "serve" listens by default.
+const defaultShareBaseURL = "http://127.0.0.1:8080"
+
+// shareView is the JSON schema for "share" and "share list".
+type shareView struct {
+	*library.Share
+	URL     string `json:"url"`
+	Title   string `json:"title"`
+	Expired bool   `json:"expired"`
+}
+
+func newShareView(sh *library.Share, doc *library.Document, baseURL string, now time.Time) shareView {
+	v := shareView{Share: sh, URL: strings.TrimRight(baseURL, "/") + "/share/" + sh.Token, Expired: sh.Expired(now)}
+	if doc != nil {
+		v.Title = doc.Title
+	}
+	return v
+}
+
+func newShareCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command {
+	var (
+		ttl         string
+		includeFile bool
+		baseURL     string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "share <document-id>",
+		Short: "Create an expiring link to one document",
+		Long: `Create a link that shows one document's page, with its metadata and
+abstract, to anyone who has it, until it expires. With --file the page
+also offers the document's file for download. Nothing else in the library
+is reachable through the link.
+
+Links are served by "arc-library serve" at /share/<token>; run it with
+--shares-only to expose only shared documents. --base-url (or
+ARC_LIBRARY_SHARE_URL) is the address others reach that server at, e.g. a
+public hostname or tunnel.
+
+Examples:
+  arc-library share 2304.00067 --ttl 7d
+  arc-library share 2304.00067 --ttl 48h --file --base-url https://papers.example.org
+  arc-library share list
+  arc-library share revoke <token>`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeFirst(completeDocuments(store)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			d, err := library.ParseShareTTL(ttl)
+			if err != nil {
+				return err
+			}
+			doc, err := lookupDocument(store, args[0])
+			if err != nil {
+				return err
+			}
+			sh, err := library.NewShare(doc.ID, d, includeFile)
+			if err != nil {
+				return err
+			}
+			if err := store.CreateShare(sh); err != nil {
+				return fmt.Errorf("create share: %w", err)
+			}
+			view := newShareView(sh, doc, baseURL, time.Now())
+
+			if jsonOutput(nil) {
+				return output.JSON(view)
+			}
+			if quietOutput() {
+				fmt.Println(view.URL)
+				return nil
+			}
+			fmt.Printf("Shared %q until %s:\n", truncate(doc.Title, 60), sh.ExpiresAt.Format("2006-01-02 15:04"))
+			fmt.Printf("  %s\n", view.URL)
+			if includeFile {
+				if info, err := os.Stat(doc.Path); err != nil || !info.Mode().IsRegular() {
+					warnf("The document has no file to share; the page shows its metadata only\n")
+				}
+			}
+			return nil
+		},
+	}
+
+	defaultBase := os.Getenv("ARC_LIBRARY_SHARE_URL")
+	if defaultBase == "" {
+		defaultBase = defaultShareBaseURL
+	}
+	cmd.PersistentFlags().StringVar(&baseURL, "base-url", defaultBase, "URL the serve process is reached at")
+	cmd.Flags().StringVar(&ttl, "ttl", "7d", "How long the link works (e.g. 12h, 7d, 2w)")
+	cmd.Flags().BoolVar(&includeFile, "file", false, "Also let the link download the document's file")
+
+	cmd.AddCommand(newShareListCmd(store, &baseURL))
+	cmd.AddCommand(newShareRevokeCmd(store))
+
+	return cmd
+}
+
+func newShareListCmd(store library.LibraryStore, baseURL *string) *cobra.Command {
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List share links",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			shares, err := store.ListShares()
+			if err != nil {
+				return err
+			}
+			now := time.Now()
+			views := []shareView{}
+			for _, sh := range shares {
+				if sh.Expired(now) && !all {
+					continue
+				}
+				doc, _ := store.GetDocument(sh.DocumentID)
+				views = append(views, newShareView(sh, doc, *baseURL, now))
+			}
+
+			if jsonOutput(nil) {
+				return output.JSON(views)
+			}
+			if quietOutput() {
+				for _, v := range views {
+					fmt.Println(v.Token)
+				}
+				return nil
+			}
+			if len(views) == 0 {
+				fmt.Println("No active share links.")
+				return nil
+			}
+			table := output.NewTable("Token", "Title", "File", "Expires", "URL")
+			for _, v := range views {
+				file, expires := "", v.ExpiresAt.Format("2006-01-02 15:04")
+				if v.IncludeFile {
+					file = "yes"
+				}
+				if v.Expired {
+					expires += " (expired)"
+				}
+				table.AddRow(v.Token, truncate(v.Title, 40), file, expires, v.URL)
+			}
+			table.Render()
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "Include expired links")
+
+	return cmd
+}
+
+func newShareRevokeCmd(store library.LibraryStore) *cobra.Command {
+	var expired bool
+
+	cmd := &cobra.Command{
+		Use:   "revoke [token...]",
+		Short: "Revoke share links",
+		Long: `Revoke share links by token, so they stop working before they expire.
+With --expired, delete the links that have already expired.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tokens := args
+			if expired {
+				shares, err := store.ListShares()
+				if err != nil {
+					return err
+				}
+				now := time.Now()
+				for _, sh := range shares {
+					if sh.Expired(now) {
+						tokens = append(tokens, sh.Token)
+					}
+				}
+			} else if len(tokens) == 0 {
+				return fmt.Errorf("give the tokens to revoke, or --expired")
+			}
+
+			results := []deleteResult{}
+			for _, token := range tokens {
+				sh, err := store.GetShare(token)
+				if err != nil {
+					return err
+				}
+				if sh == nil {
+					return fmt.Errorf("share not found: %s", token)
+				}
+				if err := store.DeleteShare(token); err != nil {
+					return fmt.Errorf("revoke %s: %w", token, err)
+				}
+				results = append(results, deleteResult{Kind: "share", ID: token, Deleted: true})
+			}
+
+			if jsonOutput(nil) {
+				return output.JSON(results)
+			}
+			if !quietOutput() {
+				fmt.Printf("Revoked %d share link(s)\n", len(results))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&expired, "expired", false, "Delete all expired links")
+
+	return cmd
+}
@@ -24,16 +24,14 @@ func newStatsCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command
 				return err
 			}
 
-			// Get all documents (no filter)
-			docs, err := store.ListDocuments(nil)
+			// Count by type
+			typeCounts, err := store.CountDocumentsByType()
 			if err != nil {
 				return err
 			}
-
-			// Count by type
-			typeCounts := make(map[library.DocumentType]int)
-			for _, d := range docs {
-				typeCounts[d.Type]++
+			totalDocs := 0
+			for _, c := range typeCounts {
+				totalDocs += c
 			}
 
 			// Total tags
@@ -48,27 +46,19 @@ func newStatsCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command
 				return err
 			}
 
-			// Annotations count: sum over all documents
-			totalAnnotations := 0
-			for _, d := range docs {
-				anns, _ := store.GetAnnotations(d.ID)
-				totalAnnotations += len(anns)
+			totalAnnotations, err := store.CountAnnotations()
+			if err != nil {
+				return err
 			}
 
-			// Reading sessions total
-			totalSessions := 0
-			totalPagesRead := 0
-			for _, d := range docs {
-				sessions, _ := store.ListSessions(d.ID)
-				totalSessions += len(sessions)
-				for _, s := range sessions {
-					totalPagesRead += s.PagesRead
-				}
+			totalSessions, totalPagesRead, err := store.SessionTotals()
+			if err != nil {
+				return err
 			}
 
-			if out.Is(output.OutputJSON) {
+			if jsonOutput(&out) {
 				stats := map[string]any{
-					"documents":          len(docs),
+					"documents":          totalDocs,
 					"by_type":            typeCounts,
 					"tags":               tagCounts,
 					"collections":        len(collections),
@@ -78,10 +68,13 @@ func newStatsCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command
 				}
 				return output.JSON(stats)
 			}
+			if quietOutput() {
+				return nil
+			}
 
 			fmt.Printf("Library Statistics\n")
 			fmt.Printf("==================\n\n")
-			fmt.Printf("Documents:     %d\n", len(docs))
+			fmt.Printf("Documents:     %d\n", totalDocs)
 			fmt.Println("By type:")
 			for t, c := range typeCounts {
 				fmt.Printf("  %s: %d\n", t, c)
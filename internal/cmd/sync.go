@@ -0,0 +1,233 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mtreilly/arc-library/internal/library"
+	"github.com/spf13/cobra"
+	"github.com/yourorg/arc-sdk/config"
+	"github.com/yourorg/arc-sdk/output"
+)
+
+func newSyncCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Sync the library with other reference managers and bookmark services",
+	}
+
+	cmd.AddCommand(newSyncZoteroCmd(store))
+	cmd.AddCommand(newSyncRaindropCmd(store))
+
+	return cmd
+}
+
+func newSyncZoteroCmd(store library.LibraryStore) *cobra.Command {
+	var (
+		apiKey   string
+		lib      string
+		conflict string
+		apiURL   string
+		dryRun   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "zotero",
+		Short: "Two-way sync with a Zotero library",
+		Long: `Sync items, their tags, and collections with a Zotero library through the
+Zotero Web API, in both directions. Each run only fetches what changed in
+Zotero since the last one.
+
+Items new in Zotero are imported, or linked to the document with the same
+DOI, URL, or title. Documents new here are created in Zotero. Edits to the
+title, authors, abstract, URL, DOI, tags, and collection membership are
+merged field by field: a field changed on one side takes that change, and
+tags merge tag by tag. A field changed on both sides is a conflict, settled
+by --conflict:
+
+  newer    keep the side modified most recently (default)
+  local    keep this library's value
+  remote   keep Zotero's value
+
+Deletions are synced too. Documents deleted because their item was deleted
+in Zotero can be restored with "undo". Collections are matched by name;
+renames are not synced.
+
+Create an API key with library write access at
+https://www.zotero.org/settings/keys. It is read from --api-key or
+ARC_LIBRARY_ZOTERO_KEY.
+
+Examples:
+  arc-library sync zotero --api-key KEY --dry-run   # Preview the first sync
+  arc-library sync zotero                           # With ARC_LIBRARY_ZOTERO_KEY set
+  arc-library sync zotero --library groups/12345 --conflict remote`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if apiKey == "" {
+				return fmt.Errorf("--api-key is required (or set ARC_LIBRARY_ZOTERO_KEY)")
+			}
+			policy, err := library.ParseZoteroConflictPolicy(conflict)
+			if err != nil {
+				return err
+			}
+			if lib == "" {
+				if lib, err = library.ZoteroKeyLibrary(apiURL, apiKey); err != nil {
+					return err
+				}
+			} else if !strings.HasPrefix(lib, "users/") && !strings.HasPrefix(lib, "groups/") {
+				return fmt.Errorf("--library must be users/<id> or groups/<id>")
+			}
+
+			client := library.NewZoteroClient(apiKey, lib)
+			client.BaseURL = apiURL
+			infof("Syncing with Zotero %s...\n", lib)
+			sync := &library.ZoteroSync{Store: store, Client: client, Policy: policy, DryRun: dryRun}
+			result, err := sync.Run()
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput(nil) {
+				result.Conflicts = nonNil(result.Conflicts)
+				result.Failed = nonNil(result.Failed)
+				return output.JSON(result)
+			}
+			if quietOutput() {
+				return nil
+			}
+
+			for _, c := range result.Conflicts {
+				fmt.Printf("Conflict on %q (%s): kept %s\n", truncate(c.Title, 50), strings.Join(c.Fields, ", "), c.Kept)
+			}
+			for _, f := range result.Failed {
+				warnf("Failed: %s: %s\n", truncate(f.Title, 50), f.Error)
+			}
+			verb := "Synced"
+			if dryRun {
+				verb = "Would sync"
+			}
+			fmt.Printf("%s with Zotero %s (library version %d)\n", verb, lib, result.Version)
+			table := output.NewTable("", "Created", "Updated", "Deleted", "Collections +/-")
+			for _, row := range []struct {
+				name   string
+				counts library.ZoteroSyncCounts
+			}{{"Pulled", result.Pulled}, {"Pushed", result.Pushed}} {
+				c := row.counts
+				table.AddRow(row.name, fmt.Sprint(c.Created), fmt.Sprint(c.Updated), fmt.Sprint(c.Deleted),
+					fmt.Sprintf("+%d/-%d", c.CollectionsCreated, c.CollectionsDeleted))
+			}
+			table.Render()
+			if len(result.Failed) > 0 {
+				return fmt.Errorf("%d item(s) failed to sync", len(result.Failed))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&apiKey, "api-key", os.Getenv("ARC_LIBRARY_ZOTERO_KEY"), "Zotero API key")
+	cmd.Flags().StringVar(&lib, "library", "", "Zotero library as users/<id> or groups/<id> (default: the key's user library)")
+	cmd.Flags().StringVar(&conflict, "conflict", string(library.ZoteroPreferNewer), "Conflict policy: newer, local, or remote")
+	cmd.Flags().StringVar(&apiURL, "api-url", library.ZoteroAPI, "Zotero API base URL")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would change without changing either library")
+	cmd.Flags().MarkHidden("api-url")
+
+	return cmd
+}
+
+func newSyncRaindropCmd(store library.LibraryStore) *cobra.Command {
+	var (
+		token      string
+		collection string
+		tags       []string
+		push       bool
+		apiURL     string
+		dryRun     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "raindrop",
+		Short: "Pull bookmarks from Raindrop.io, optionally pushing articles back",
+		Long: `Import the bookmarks of a Raindrop.io collection as article documents, with
+their tags, excerpts as abstracts, notes, and highlights as annotations.
+Bookmarks already in the library, matched by URL, are not added again; new
+tags and highlights are added to them instead, so the command can be run
+regularly to pick up what you saved in Raindrop.
+
+--collection takes a collection's name or ID; "unsorted" is the Unsorted
+collection, and without it every bookmark is pulled. With --push, the
+library's articles that have a URL but no bookmark anywhere in Raindrop are
+saved to the collection (or to Unsorted), with their titles, abstracts, and
+tags.
+
+The token is a test token from the integrations page of the Raindrop
+settings (https://app.raindrop.io/settings/integrations), read from --token
+or ARC_LIBRARY_RAINDROP_TOKEN.
+
+Examples:
+  arc-library sync raindrop --collection Reading --dry-run
+  arc-library sync raindrop --collection Reading --push -t raindrop`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if token == "" {
+				return fmt.Errorf("--token is required (or set ARC_LIBRARY_RAINDROP_TOKEN)")
+			}
+			client := library.NewRaindropClient(token)
+			client.BaseURL = apiURL
+			infof("Syncing with Raindrop.io...\n")
+			sync := &library.RaindropSync{Store: store, Client: client, Collection: collection, Tags: tags, Push: push, DryRun: dryRun}
+			result, err := sync.Run()
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput(nil) {
+				return output.JSON(result)
+			}
+			if quietOutput() {
+				printIDs(documentIDs(result.Pulled.Imported)...)
+				return nil
+			}
+
+			pulled := result.Pulled
+			if len(pulled.Imported)+len(pulled.Updated)+len(result.Pushed) > 0 {
+				table := output.NewTable("", "Title")
+				for _, d := range pulled.Imported {
+					table.AddRow("pulled", truncate(d.Title, 60))
+				}
+				for _, d := range pulled.Updated {
+					table.AddRow("updated", truncate(d.Title, 60))
+				}
+				for _, d := range result.Pushed {
+					table.AddRow("pushed", truncate(d.Title, 60))
+				}
+				table.Render()
+				fmt.Println()
+			}
+			verb := "Synced"
+			if dryRun {
+				verb = "Would sync"
+			}
+			fmt.Printf("%s with Raindrop collection %q: %d pulled, %d updated, %d unchanged, %d highlight(s) and note(s)",
+				verb, result.Collection, len(pulled.Imported), len(pulled.Updated), pulled.Skipped, pulled.Highlights)
+			if push {
+				fmt.Printf(", %d pushed", len(result.Pushed))
+			}
+			fmt.Println(".")
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&token, "token", os.Getenv("ARC_LIBRARY_RAINDROP_TOKEN"), "Raindrop.io access token")
+	cmd.Flags().StringVar(&collection, "collection", "", "Raindrop collection name or ID (default: every bookmark)")
+	cmd.Flags().StringSliceVarP(&tags, "tag", "t", nil, "Tags to apply to pulled documents")
+	cmd.Flags().BoolVar(&push, "push", false, "Also save the library's articles missing from Raindrop")
+	cmd.Flags().StringVar(&apiURL, "api-url", library.RaindropAPI, "Raindrop.io API base URL")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would change without changing either side")
+	cmd.Flags().MarkHidden("api-url")
+
+	return cmd
+}
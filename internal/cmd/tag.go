@@ -6,6 +6,7 @@ package cmd
 import (
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/mtreilly/arc-library/internal/library"
@@ -29,9 +30,10 @@ func newTagCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command {
 
 func newTagAddCmd(store library.LibraryStore) *cobra.Command {
 	return &cobra.Command{
-		Use:   "add <document-id> <tag> [tag...]",
-		Short: "Add tags to a document",
-		Args:  cobra.MinimumNArgs(2),
+		Use:               "add <document-id> <tag> [tag...]",
+		Short:             "Add tags to a document",
+		Args:              cobra.MinimumNArgs(2),
+		ValidArgsFunction: completeFirstThen(completeDocuments(store), completeTags(store)),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			documentID := args[0]
 			tags := args[1:]
@@ -49,16 +51,26 @@ func newTagAddCmd(store library.LibraryStore) *cobra.Command {
 				}
 			}
 			if document == nil {
-				return fmt.Errorf("document not found: %s", documentID)
+				return fmt.Errorf("%w: %s", library.ErrDocumentNotFound, documentID)
 			}
 
-			for _, tag := range tags {
-				if err := store.AddTag(document.ID, tag); err != nil {
-					return fmt.Errorf("add tag %q: %w", tag, err)
+			summary := fmt.Sprintf("tag %s +%s", truncate(document.Title, 40), strings.Join(tags, " +"))
+			err = library.ChangeTagsUndoable(store, summary, []string{document.ID}, func() error {
+				for _, tag := range tags {
+					if err := store.AddTag(document.ID, tag); err != nil {
+						return fmt.Errorf("add tag %q: %w", tag, err)
+					}
+					infof("Added tag %q to %s\n", tag, truncate(document.Title, 40))
 				}
-				fmt.Printf("Added tag %q to %s\n", tag, truncate(document.Title, 40))
+				return nil
+			})
+			if err != nil {
+				return err
 			}
 
+			if jsonOutput(nil) {
+				return output.JSON(membershipResult{Target: document.ID, Changed: tags})
+			}
 			return nil
 		},
 	}
@@ -66,9 +78,10 @@ func newTagAddCmd(store library.LibraryStore) *cobra.Command {
 
 func newTagRemoveCmd(store library.LibraryStore) *cobra.Command {
 	return &cobra.Command{
-		Use:   "remove <document-id> <tag> [tag...]",
-		Short: "Remove tags from a document",
-		Args:  cobra.MinimumNArgs(2),
+		Use:               "remove <document-id> <tag> [tag...]",
+		Short:             "Remove tags from a document",
+		Args:              cobra.MinimumNArgs(2),
+		ValidArgsFunction: completeFirstThen(completeDocuments(store), completeTags(store)),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			documentID := args[0]
 			tags := args[1:]
@@ -84,16 +97,26 @@ func newTagRemoveCmd(store library.LibraryStore) *cobra.Command {
 				}
 			}
 			if document == nil {
-				return fmt.Errorf("document not found: %s", documentID)
+				return fmt.Errorf("%w: %s", library.ErrDocumentNotFound, documentID)
 			}
 
-			for _, tag := range tags {
-				if err := store.RemoveTag(document.ID, tag); err != nil {
-					return fmt.Errorf("remove tag %q: %w", tag, err)
+			summary := fmt.Sprintf("untag %s -%s", truncate(document.Title, 40), strings.Join(tags, " -"))
+			err = library.ChangeTagsUndoable(store, summary, []string{document.ID}, func() error {
+				for _, tag := range tags {
+					if err := store.RemoveTag(document.ID, tag); err != nil {
+						return fmt.Errorf("remove tag %q: %w", tag, err)
+					}
+					infof("Removed tag %q from %s\n", tag, truncate(document.Title, 40))
 				}
-				fmt.Printf("Removed tag %q from %s\n", tag, truncate(document.Title, 40))
+				return nil
+			})
+			if err != nil {
+				return err
 			}
 
+			if jsonOutput(nil) {
+				return output.JSON(membershipResult{Target: document.ID, Changed: tags})
+			}
 			return nil
 		},
 	}
@@ -115,13 +138,17 @@ func newTagListCmd(store library.LibraryStore) *cobra.Command {
 				return err
 			}
 
-			if len(tags) == 0 {
-				fmt.Println("No tags found.")
-				return nil
+			if jsonOutput(&out) {
+				// {} rather than null for a library without tags
+				if tags == nil {
+					tags = map[string]int{}
+				}
+				return output.JSON(tags)
 			}
 
-			if out.Is(output.OutputJSON) {
-				return output.JSON(tags)
+			if len(tags) == 0 {
+				infoln("No tags found.")
+				return nil
 			}
 
 			// Sort by count descending
@@ -137,6 +164,13 @@ func newTagListCmd(store library.LibraryStore) *cobra.Command {
 				return sorted[i].Count > sorted[j].Count
 			})
 
+			if quietOutput() {
+				for _, tc := range sorted {
+					printIDs(tc.Tag)
+				}
+				return nil
+			}
+
 			table := output.NewTable("Tag", "Documents")
 			for _, tc := range sorted {
 				table.AddRow(tc.Tag, fmt.Sprintf("%d", tc.Count))
@@ -5,8 +5,13 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/charmbracelet/lipgloss"
 	"github.com/mtreilly/arc-library/internal/library"
 	"github.com/spf13/cobra"
 	"github.com/yourorg/arc-sdk/config"
@@ -17,13 +22,22 @@ func newTaskCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "task",
 		Short: "Manage tasks and projects",
-		Long:  "Create and track tasks associated with document collections.",
+		Long: `Create and track tasks associated with collections or individual documents.
+
+Tasks move through a workflow of statuses, by default todo, doing, blocked,
+and done. Set ARC_LIBRARY_TASK_STATUSES to a comma-separated list to use
+your own columns, e.g. "todo,reading,writing,done"; todo and done are
+required.`,
 	}
 
 	cmd.AddCommand(newTaskAddCmd(store))
 	cmd.AddCommand(newTaskListCmd(store))
 	cmd.AddCommand(newTaskDoneCmd(store))
+	cmd.AddCommand(newTaskMoveCmd(store))
+	cmd.AddCommand(newTaskBoardCmd(store))
+	cmd.AddCommand(newTaskUpcomingCmd(store))
 	cmd.AddCommand(newTaskDeleteCmd(store))
+	requireCapability(store, func(c library.Capabilities) bool { return c.Tasks }, "tasks", cmd.Commands()...)
 
 	return cmd
 }
@@ -31,21 +45,37 @@ func newTaskCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command {
 func newTaskAddCmd(store library.LibraryStore) *cobra.Command {
 	var (
 		collection string
+		document   string
+		parent     string
 		due        string
 		priority   string
 		tags       []string
+		repeat     string
 	)
 
 	cmd := &cobra.Command{
 		Use:   "add <description>",
 		Short: "Add a new task",
-		Long:  "Create a task associated with a collection.",
+		Long: `Create a task associated with a collection or a single document.
+
+Recurring tasks (--repeat) are regenerated with the next due date when
+marked done. Rules: daily, weekly, biweekly, monthly, quarterly, yearly,
+or "every N days|weeks|months|years".
+
+Subtasks (--parent) break a task into steps; they inherit the parent's
+collection and document unless given their own, and the parent is
+completed automatically once every subtask is done.`,
 		Args:  cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			description := ""
 			if len(args) > 0 {
 				description = args[0]
 			}
+			if repeat != "" {
+				if _, err := library.ParseRecurrence(repeat); err != nil {
+					return err
+				}
+			}
 
 			// Verify collection exists
 			var collID string
@@ -60,26 +90,57 @@ func newTaskAddCmd(store library.LibraryStore) *cobra.Command {
 					if err != nil {
 						return fmt.Errorf("create collection: %w", err)
 					}
-					fmt.Printf("Created collection: %s\n", collection)
+					infof("Created collection: %s\n", collection)
 				}
 				collID = coll.ID
 			}
 
+			var docID string
+			if document != "" {
+				doc, err := lookupDocument(store, document)
+				if err != nil {
+					return err
+				}
+				docID = doc.ID
+			}
+
+			var parentID string
+			if parent != "" {
+				p, err := store.GetTask(parent)
+				if err != nil {
+					return fmt.Errorf("get parent task: %w", err)
+				}
+				if p == nil {
+					return fmt.Errorf("parent task not found: %s", parent)
+				}
+				parentID = p.ID
+				if collID == "" {
+					collID = p.CollectionID
+				}
+				if docID == "" {
+					docID = p.DocumentID
+				}
+			}
+
 			task := &library.Task{
 				Description:   description,
 				CollectionID:  collID,
-				Status:        "todo",
+				DocumentID:    docID,
+				ParentID:      parentID,
+				Status:        library.TaskTodo,
 				Priority:      priority,
 				Tags:          tags,
+				Repeat:        repeat,
 				CreatedAt:     time.Now(),
 				UpdatedAt:     time.Now(),
 			}
 
 			if due != "" {
-				dueTime, err := time.Parse("2006-01-02", due)
+				dueTime, err := library.ParseDate(due, library.Now())
 				if err != nil {
-					return fmt.Errorf("invalid due date (use YYYY-MM-DD): %w", err)
+					return fmt.Errorf("invalid --due: %w", err)
 				}
+				dueTime = dueTime.UTC()
 				task.DueAt = &dueTime
 			}
 
@@ -87,23 +148,43 @@ func newTaskAddCmd(store library.LibraryStore) *cobra.Command {
 				return fmt.Errorf("add task: %w", err)
 			}
 
+			if jsonOutput(nil) {
+				return output.JSON(task)
+			}
+			if quietOutput() {
+				printIDs(task.ID)
+				return nil
+			}
+
 			fmt.Printf("Task created: %s\n", task.ID)
 			fmt.Printf("Description: %s\n", task.Description)
 			if collection != "" {
 				fmt.Printf("Collection: %s\n", collection)
 			}
+			if docID != "" {
+				fmt.Printf("Document: %s\n", docID)
+			}
+			if parentID != "" {
+				fmt.Printf("Parent: %s\n", parentID)
+			}
 			if due != "" {
 				fmt.Printf("Due: %s\n", due)
 			}
+			if repeat != "" {
+				fmt.Printf("Repeats: %s\n", repeat)
+			}
 
 			return nil
 		},
 	}
 
 	cmd.Flags().StringVarP(&collection, "collection", "c", "", "Associate with collection")
-	cmd.Flags().StringVarP(&due, "due", "d", "", "Due date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&document, "document", "", "Associate with a document")
+	cmd.Flags().StringVar(&parent, "parent", "", "Create as a subtask of this task")
+	cmd.Flags().StringVarP(&due, "due", "d", "", "Due date, e.g. 2025-01-31, friday, in 2 weeks, or tomorrow 9am")
 	cmd.Flags().StringVarP(&priority, "priority", "p", "medium", "Priority (low/medium/high)")
 	cmd.Flags().StringSliceVarP(&tags, "tag", "t", nil, "Tags")
+	cmd.Flags().StringVarP(&repeat, "repeat", "r", "", `Repeat rule (e.g. weekly, "every 2 weeks")`)
 
 	return cmd
 }
@@ -111,14 +192,20 @@ func newTaskAddCmd(store library.LibraryStore) *cobra.Command {
 func newTaskListCmd(store library.LibraryStore) *cobra.Command {
 	var (
 		collection string
+		document   string
 		status     string
 		all        bool
+		tree       bool
 		out        output.OutputOptions
 	)
 
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List tasks",
+		Long: `List tasks, open ones only unless --all or --status is given.
+
+With --tree, subtasks are nested under their parent and each parent shows
+how many of its subtasks are done.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := out.Resolve(); err != nil {
 				return err
@@ -134,12 +221,18 @@ func newTaskListCmd(store library.LibraryStore) *cobra.Command {
 					opts.CollectionID = coll.ID
 				}
 			}
+			if document != "" {
+				doc, err := lookupDocument(store, document)
+				if err != nil {
+					return err
+				}
+				opts.DocumentID = doc.ID
+			}
 			if status != "" {
 				opts.Status = status
-			}
-			if !all {
+			} else if !all {
 				// Default: show only incomplete
-				opts.Status = "todo"
+				opts.Open = true
 			}
 
 			tasks, err := store.ListTasks(opts)
@@ -147,8 +240,35 @@ func newTaskListCmd(store library.LibraryStore) *cobra.Command {
 				return fmt.Errorf("list tasks: %w", err)
 			}
 
-			if out.Is(output.OutputJSON) {
-				return output.JSON(tasks)
+			if tree {
+				// Progress counts every subtask, not only those shown
+				allTasks, err := store.ListTasks(nil)
+				if err != nil {
+					return fmt.Errorf("list tasks: %w", err)
+				}
+				roots := library.BuildTaskTree(tasks, library.RollUpTasks(allTasks))
+				if jsonOutput(&out) {
+					return output.JSON(roots)
+				}
+				if !quietOutput() {
+					if len(tasks) == 0 {
+						fmt.Println("No tasks found.")
+						return nil
+					}
+					fmt.Printf("Tasks: %d\n\n", len(tasks))
+					printTaskTree(roots, "")
+					return nil
+				}
+			}
+
+			if jsonOutput(&out) {
+				return output.JSON(nonNil(tasks))
+			}
+			if quietOutput() {
+				for _, t := range tasks {
+					printIDs(t.ID)
+				}
+				return nil
 			}
 
 			if len(tasks) == 0 {
@@ -159,7 +279,7 @@ func newTaskListCmd(store library.LibraryStore) *cobra.Command {
 			// Group by status
 			fmt.Printf("Tasks: %d\n\n", len(tasks))
 
-			table := output.NewTable("ID", "Description", "Collection", "Due", "Priority")
+			table := output.NewTable("ID", "Description", "Collection / Document", "Due", "Priority")
 			for _, t := range tasks {
 				desc := truncate(t.Description, 40)
 				collName := ""
@@ -169,10 +289,18 @@ func newTaskListCmd(store library.LibraryStore) *cobra.Command {
 						collName = truncate(coll.Name, 15)
 					}
 				}
+				if t.DocumentID != "" {
+					if doc, _ := store.GetDocument(t.DocumentID); doc != nil {
+						if collName != "" {
+							collName += " / "
+						}
+						collName += truncate(doc.Title, 25)
+					}
+				}
 				dueStr := ""
 				if t.DueAt != nil {
-					dueStr = t.DueAt.Format("2006-01-02")
-					if t.DueAt.Before(time.Now()) {
+					dueStr = library.HomeDate(*t.DueAt)
+					if library.Overdue(*t.DueAt, library.Now()) {
 						dueStr += " (!)"
 					}
 				}
@@ -185,17 +313,68 @@ func newTaskListCmd(store library.LibraryStore) *cobra.Command {
 	}
 
 	cmd.Flags().StringVarP(&collection, "collection", "c", "", "Filter by collection")
-	cmd.Flags().StringVarP(&status, "status", "s", "", "Filter by status (todo/done)")
+	cmd.Flags().StringVar(&document, "document", "", "Filter by document")
+	cmd.Flags().StringVarP(&status, "status", "s", "", "Filter by status (e.g. todo, doing, done)")
 	cmd.Flags().BoolVarP(&all, "all", "a", false, "Show all tasks including completed")
+	cmd.Flags().BoolVar(&tree, "tree", false, "Nest subtasks under their parent tasks")
 	out.AddOutputFlags(cmd, output.OutputTable)
 
 	return cmd
 }
 
+// printTaskTree renders task nodes as an indented tree with box-drawing
+// connectors, e.g. "├── [x] Read paper (3/5)".
+func printTaskTree(nodes []*library.TaskNode, prefix string) {
+	for i, n := range nodes {
+		connector, childPrefix := "├── ", "│   "
+		if i == len(nodes)-1 {
+			connector, childPrefix = "└── ", "    "
+		}
+		if prefix == "" {
+			// Roots start flush left
+			connector, childPrefix = "", ""
+		}
+
+		check := " "
+		switch n.Status {
+		case library.TaskDone:
+			check = "x"
+		case library.TaskTodo:
+		default:
+			check = "~"
+		}
+		line := fmt.Sprintf("%s%s[%s] %s  %s", prefix, connector, check, n.ID, n.Description)
+		if n.Progress != nil {
+			line += fmt.Sprintf(" (%d/%d)", n.Progress.Done, n.Progress.Total)
+		}
+		if n.DueAt != nil {
+			line += "  due " + library.HomeDate(*n.DueAt)
+			if n.Status != library.TaskDone && library.Overdue(*n.DueAt, library.Now()) {
+				line += " (!)"
+			}
+		}
+		fmt.Println(line)
+
+		if len(n.Subtasks) > 0 {
+			if prefix == "" {
+				childPrefix = "  "
+			}
+			printTaskTree(n.Subtasks, prefix+childPrefix)
+		}
+	}
+}
+
 func newTaskDoneCmd(store library.LibraryStore) *cobra.Command {
+	var force bool
+
 	cmd := &cobra.Command{
 		Use:   "done <task-id>",
 		Short: "Mark a task as complete",
+		Long: `Mark a task as complete.
+
+A task with open subtasks is only completed with --force, which completes
+the subtasks too. Completing the last open subtask of a parent completes
+the parent as well.`,
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			taskID := args[0]
@@ -207,22 +386,457 @@ func newTaskDoneCmd(store library.LibraryStore) *cobra.Command {
 				return fmt.Errorf("task not found: %s", taskID)
 			}
 
-			task.Status = "done"
-			task.CompletedAt = &[]time.Time{time.Now()}[0]
-			task.UpdatedAt = time.Now()
+			result, err := markTaskDone(store, task, force)
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput(nil) {
+				return output.JSON(result)
+			}
+			infof("Task completed: %s\n", task.Description)
+			printTaskDoneResult(result)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "Also complete any open subtasks")
 
+	return cmd
+}
+
+// markTaskDone completes a task, scheduling its next occurrence if it
+// repeats. Open subtasks block completion unless force is set, in which case
+// they are completed too; parents left with no open subtasks are completed
+// by roll-up.
+func markTaskDone(store library.LibraryStore, task *library.Task, force bool) (*taskDoneResult, error) {
+	open, err := openSubtasks(store, task.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(open) > 0 && !force {
+		return nil, fmt.Errorf("task has %d open subtask(s); complete them first or use --force", len(open))
+	}
+
+	result := taskDoneResult{Task: task}
+	for len(open) > 0 {
+		sub := open[0]
+		open = open[1:]
+		more, err := openSubtasks(store, sub.ID)
+		if err != nil {
+			return nil, err
+		}
+		open = append(open, more...)
+		if _, err := completeTask(store, sub); err != nil {
+			return nil, err
+		}
+		result.Subtasks = append(result.Subtasks, sub)
+	}
+
+	next, err := completeTask(store, task)
+	if err != nil {
+		return nil, err
+	}
+	result.Next = next
+
+	// Roll completion up through parents whose subtasks are now all done
+	for parentID := task.ParentID; parentID != ""; {
+		parent, err := store.GetTask(parentID)
+		if err != nil || parent == nil || parent.Status == library.TaskDone {
+			break
+		}
+		siblings, err := openSubtasks(store, parent.ID)
+		if err != nil || len(siblings) > 0 {
+			break
+		}
+		if _, err := completeTask(store, parent); err != nil {
+			return nil, err
+		}
+		result.Parents = append(result.Parents, parent)
+		parentID = parent.ParentID
+	}
+
+	return &result, nil
+}
+
+// printTaskDoneResult reports the side effects of markTaskDone.
+func printTaskDoneResult(result *taskDoneResult) {
+	for _, sub := range result.Subtasks {
+		infof("Subtask completed: %s\n", sub.Description)
+	}
+	if result.Next != nil {
+		infof("Next occurrence: %s (due %s)\n", result.Next.ID, library.HomeDate(*result.Next.DueAt))
+	}
+	for _, parent := range result.Parents {
+		infof("All subtasks done, completed: %s\n", parent.Description)
+	}
+}
+
+// completeTask marks a task done and, if it repeats, adds and returns its
+// next occurrence.
+func completeTask(store library.LibraryStore, task *library.Task) (*library.Task, error) {
+	now := time.Now()
+	task.Status = library.TaskDone
+	task.CompletedAt = &now
+	task.UpdatedAt = now
+
+	if err := store.UpdateTask(task); err != nil {
+		return nil, fmt.Errorf("update task: %w", err)
+	}
+
+	next, err := library.NextTask(task, now.In(library.HomeLocation()))
+	if err != nil {
+		return nil, fmt.Errorf("schedule next occurrence: %w", err)
+	}
+	if next != nil {
+		if err := store.AddTask(next); err != nil {
+			return nil, fmt.Errorf("add next occurrence: %w", err)
+		}
+	}
+	return next, nil
+}
+
+// openSubtasks returns the incomplete direct subtasks of a task.
+func openSubtasks(store library.LibraryStore, taskID string) ([]*library.Task, error) {
+	tasks, err := store.ListTasks(&library.TaskListOptions{ParentID: taskID, Open: true})
+	if err != nil {
+		return nil, fmt.Errorf("list subtasks: %w", err)
+	}
+	return tasks, nil
+}
+
+// taskDoneResult is the JSON schema for "task done". Next is set when the
+// task repeats and a follow-up occurrence was created; Subtasks lists those
+// completed by --force and Parents those completed by roll-up.
+type taskDoneResult struct {
+	Task     *library.Task   `json:"task"`
+	Next     *library.Task   `json:"next,omitempty"`
+	Subtasks []*library.Task `json:"subtasks,omitempty"`
+	Parents  []*library.Task `json:"parents,omitempty"`
+}
+
+// taskStatuses returns the configured task workflow from
+// ARC_LIBRARY_TASK_STATUSES, or the default one.
+func taskStatuses() ([]string, error) {
+	statuses, err := library.ParseTaskStatuses(os.Getenv("ARC_LIBRARY_TASK_STATUSES"))
+	if err != nil {
+		return nil, fmt.Errorf("ARC_LIBRARY_TASK_STATUSES: %w", err)
+	}
+	return statuses, nil
+}
+
+func newTaskMoveCmd(store library.LibraryStore) *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "move <task-id> <status>",
+		Short: "Move a task to another workflow status",
+		Long: `Move a task to another status in the task workflow, e.g. from todo to
+doing or blocked. Moving to done behaves like "task done"; moving a done
+task back reopens it.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			statuses, err := taskStatuses()
+			if err != nil {
+				return err
+			}
+			status := strings.ToLower(args[1])
+			if !slices.Contains(statuses, status) {
+				return fmt.Errorf("unknown task status %q (use %s)", args[1], strings.Join(statuses, ", "))
+			}
+
+			task, err := store.GetTask(args[0])
+			if err != nil {
+				return fmt.Errorf("get task: %w", err)
+			}
+			if task == nil {
+				return fmt.Errorf("task not found: %s", args[0])
+			}
+			from := task.Status
+
+			if status == library.TaskDone {
+				if from == library.TaskDone {
+					return fmt.Errorf("task is already done")
+				}
+				result, err := markTaskDone(store, task, force)
+				if err != nil {
+					return err
+				}
+				if jsonOutput(nil) {
+					return output.JSON(result)
+				}
+				infof("Task moved: %s (%s → %s)\n", task.Description, from, status)
+				printTaskDoneResult(result)
+				return nil
+			}
+
+			task.Status = status
+			task.CompletedAt = nil
+			task.UpdatedAt = time.Now()
 			if err := store.UpdateTask(task); err != nil {
 				return fmt.Errorf("update task: %w", err)
 			}
 
-			fmt.Printf("Task completed: %s\n", task.Description)
+			if jsonOutput(nil) {
+				return output.JSON(taskDoneResult{Task: task})
+			}
+			infof("Task moved: %s (%s → %s)\n", task.Description, from, status)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "When moving to done, also complete any open subtasks")
+
+	return cmd
+}
+
+// boardColumn is the JSON schema for one column of "task board".
+type boardColumn struct {
+	Status string          `json:"status"`
+	Tasks  []*library.Task `json:"tasks"`
+}
+
+func newTaskBoardCmd(store library.LibraryStore) *cobra.Command {
+	var (
+		collection string
+		doneLimit  int
+		width      int
+		out        output.OutputOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:   "board",
+		Short: "Show tasks as a kanban board",
+		Long: `Show tasks grouped into one column per workflow status, in workflow
+order. Open columns list tasks by due date; the done column shows only the
+most recently completed tasks (--done-limit).
+
+Tasks whose status is not in the configured workflow get a column of their
+own before done, so nothing is hidden after changing the workflow.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := out.Resolve(); err != nil {
+				return err
+			}
+			statuses, err := taskStatuses()
+			if err != nil {
+				return err
+			}
+
+			opts := &library.TaskListOptions{}
+			if collection != "" {
+				coll, err := store.GetCollection(collection)
+				if err != nil {
+					return fmt.Errorf("get collection: %w", err)
+				}
+				if coll == nil {
+					return fmt.Errorf("%w: %s", library.ErrCollectionNotFound, collection)
+				}
+				opts.CollectionID = coll.ID
+			}
+			tasks, err := store.ListTasks(opts)
+			if err != nil {
+				return fmt.Errorf("list tasks: %w", err)
+			}
+
+			columns := buildBoard(statuses, tasks)
+			hidden := 0
+			for i := range columns {
+				if columns[i].Status == library.TaskDone && doneLimit >= 0 && len(columns[i].Tasks) > doneLimit {
+					hidden = len(columns[i].Tasks) - doneLimit
+					columns[i].Tasks = columns[i].Tasks[:doneLimit]
+				}
+			}
+
+			if jsonOutput(&out) {
+				return output.JSON(columns)
+			}
+			if quietOutput() {
+				for _, c := range columns {
+					for _, t := range c.Tasks {
+						printIDs(t.ID)
+					}
+				}
+				return nil
+			}
+
+			fmt.Println(renderBoard(columns, hidden, width))
 			return nil
 		},
 	}
 
+	cmd.Flags().StringVarP(&collection, "collection", "c", "", "Only tasks in this collection")
+	cmd.Flags().IntVar(&doneLimit, "done-limit", 5, "Most recently completed tasks to show (-1 for all)")
+	cmd.Flags().IntVar(&width, "width", 28, "Column width in characters")
+	out.AddOutputFlags(cmd, output.OutputTable)
+
 	return cmd
 }
 
+// buildBoard groups tasks into workflow columns, adding columns for unknown
+// statuses just before done. Open columns are sorted by due date (undated
+// last), the done column by most recently updated.
+func buildBoard(statuses []string, tasks []*library.Task) []boardColumn {
+	byStatus := make(map[string][]*library.Task)
+	var unknown []string
+	for _, t := range tasks {
+		if _, seen := byStatus[t.Status]; !seen && !slices.Contains(statuses, t.Status) {
+			unknown = append(unknown, t.Status)
+		}
+		byStatus[t.Status] = append(byStatus[t.Status], t)
+	}
+
+	sort.Strings(unknown)
+	done := slices.Index(statuses, library.TaskDone)
+	order := slices.Concat(statuses[:done], unknown, statuses[done:])
+	columns := make([]boardColumn, 0, len(order))
+	for _, s := range order {
+		columns = append(columns, boardColumn{Status: s, Tasks: nonNil(byStatus[s])})
+	}
+
+	for _, c := range columns {
+		tasks := c.Tasks
+		if c.Status == library.TaskDone {
+			sort.SliceStable(tasks, func(i, j int) bool { return tasks[i].UpdatedAt.After(tasks[j].UpdatedAt) })
+			continue
+		}
+		sort.SliceStable(tasks, func(i, j int) bool {
+			a, b := tasks[i].DueAt, tasks[j].DueAt
+			if a == nil || b == nil {
+				return a != nil
+			}
+			return a.Before(*b)
+		})
+	}
+	return columns
+}
+
+// renderBoard draws board columns side by side as bordered panes.
+func renderBoard(columns []boardColumn, hiddenDone, width int) string {
+	width = max(width, 12)
+	now := library.Now()
+
+	panes := make([]string, 0, len(columns))
+	for _, c := range columns {
+		count := len(c.Tasks)
+		if c.Status == library.TaskDone {
+			count += hiddenDone
+		}
+		lines := []string{tuiHeadingStyle.Render(fmt.Sprintf("%s (%d)", strings.ToUpper(c.Status), count)), ""}
+		for i, t := range c.Tasks {
+			if i > 0 {
+				lines = append(lines, "")
+			}
+			lines = append(lines, truncate(t.Description, width), tuiDimStyle.Render(t.ID))
+			if t.DueAt != nil && c.Status != library.TaskDone {
+				due := "due " + library.HomeDate(*t.DueAt)
+				if library.Overdue(*t.DueAt, now) {
+					due += " (!)"
+				}
+				lines = append(lines, tuiDimStyle.Render(due))
+			}
+		}
+		if c.Status == library.TaskDone && hiddenDone > 0 {
+			if len(c.Tasks) > 0 {
+				lines = append(lines, "")
+			}
+			lines = append(lines, tuiDimStyle.Render(fmt.Sprintf("+%d more", hiddenDone)))
+		}
+		if count == 0 {
+			lines = append(lines, tuiDimStyle.Render("(empty)"))
+		}
+		panes = append(panes, tuiPaneStyle.Width(width+2).Render(strings.Join(lines, "\n")))
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Top, panes...)
+}
+
+func newTaskUpcomingCmd(store library.LibraryStore) *cobra.Command {
+	var (
+		days   int
+		notify bool
+		out    output.OutputOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:   "upcoming",
+		Short: "List open tasks due soon",
+		Long: `List open tasks due within the next N days, including overdue ones,
+soonest first. With --notify, also post a desktop notification summarising
+them, e.g. from a cron job or login script.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := out.Resolve(); err != nil {
+				return err
+			}
+			if days < 0 {
+				return fmt.Errorf("--days must not be negative")
+			}
+
+			now := library.Now()
+			tasks, err := store.ListTasks(&library.TaskListOptions{
+				Open:      true,
+				DueBefore: library.DueDate(now, days+1),
+			})
+			if err != nil {
+				return fmt.Errorf("list tasks: %w", err)
+			}
+			sort.SliceStable(tasks, func(i, j int) bool { return tasks[i].DueAt.Before(*tasks[j].DueAt) })
+
+			if notify && len(tasks) > 0 {
+				if err := sendNotification("arc-library", upcomingSummary(tasks, now)); err != nil {
+					warnf("notification failed: %v\n", err)
+				}
+			}
+
+			if jsonOutput(&out) {
+				return output.JSON(nonNil(tasks))
+			}
+			if quietOutput() {
+				for _, t := range tasks {
+					printIDs(t.ID)
+				}
+				return nil
+			}
+
+			if len(tasks) == 0 {
+				fmt.Printf("No tasks due in the next %d day(s).\n", days)
+				return nil
+			}
+
+			table := output.NewTable("ID", "Description", "Due", "Repeats", "Priority")
+			for _, t := range tasks {
+				dueStr := library.HomeDate(*t.DueAt)
+				if library.Overdue(*t.DueAt, now) {
+					dueStr += " (!)"
+				}
+				table.AddRow(truncate(t.ID, 8), truncate(t.Description, 40), dueStr, t.Repeat, t.Priority)
+			}
+			table.Render()
+
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVarP(&days, "days", "d", 7, "Look ahead this many days")
+	cmd.Flags().BoolVar(&notify, "notify", false, "Post a desktop notification")
+	out.AddOutputFlags(cmd, output.OutputTable)
+
+	return cmd
+}
+
+// upcomingSummary is the notification text for "task upcoming --notify".
+func upcomingSummary(tasks []*library.Task, now time.Time) string {
+	overdue := 0
+	for _, t := range tasks {
+		if library.Overdue(*t.DueAt, now) {
+			overdue++
+		}
+	}
+	summary := fmt.Sprintf("%d task(s) due soon", len(tasks))
+	if overdue > 0 {
+		summary += fmt.Sprintf(", %d overdue", overdue)
+	}
+	return summary + ": " + tasks[0].Description
+}
+
 func newTaskDeleteCmd(store library.LibraryStore) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "delete <task-id>",
@@ -230,10 +844,29 @@ func newTaskDeleteCmd(store library.LibraryStore) *cobra.Command {
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			taskID := args[0]
+
+			// Subtasks move up to the deleted task's parent rather than dangling
+			if task, err := store.GetTask(taskID); err == nil && task != nil {
+				children, err := store.ListTasks(&library.TaskListOptions{ParentID: task.ID})
+				if err != nil {
+					return fmt.Errorf("list subtasks: %w", err)
+				}
+				for _, child := range children {
+					child.ParentID = task.ParentID
+					child.UpdatedAt = time.Now()
+					if err := store.UpdateTask(child); err != nil {
+						return fmt.Errorf("update subtask: %w", err)
+					}
+				}
+			}
+
 			if err := store.DeleteTask(taskID); err != nil {
 				return fmt.Errorf("delete task: %w", err)
 			}
-			fmt.Printf("Task deleted: %s\n", taskID)
+			if jsonOutput(nil) {
+				return output.JSON(deleteResult{Kind: "task", ID: taskID, Deleted: true})
+			}
+			infof("Task deleted: %s\n", taskID)
 			return nil
 		},
 	}
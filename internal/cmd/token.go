@@ -0,0 +1,144 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/mtreilly/arc-library/internal/library"
+	"github.com/spf13/cobra"
+	"github.com/yourorg/arc-sdk/config"
+	"github.com/yourorg/arc-sdk/output"
+)
+
+func newTokenCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "token",
+		Short: "Manage API tokens for the web server",
+		Long: `Manage the API tokens "arc-library serve --require-token" accepts. Each
+token has a scope:
+
+  read       browse documents, search, and stats
+  annotate   also add annotations
+  admin      also add documents through /api/clip
+
+Examples:
+  arc-library token create alice --scope annotate
+  arc-library token list
+  arc-library token revoke alice`,
+	}
+
+	cmd.AddCommand(newTokenCreateCmd(store))
+	cmd.AddCommand(newTokenListCmd(store))
+	cmd.AddCommand(newTokenRevokeCmd(store))
+
+	return cmd
+}
+
+// tokenCreateResult is the JSON schema for "token create".
+type tokenCreateResult struct {
+	*library.APIToken
+	Secret string `json:"secret"`
+}
+
+func newTokenCreateCmd(store library.LibraryStore) *cobra.Command {
+	var scope string
+
+	cmd := &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create an API token",
+		Long: `Create an API token named after who or what will use it. The token is
+printed once; only a hash of it is stored, so it cannot be shown again.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sc, err := library.ParseTokenScope(scope)
+			if err != nil {
+				return err
+			}
+			token, secret, err := library.NewAPIToken(args[0], sc)
+			if err != nil {
+				return err
+			}
+			if err := store.CreateAPIToken(token); err != nil {
+				return err
+			}
+
+			if jsonOutput(nil) {
+				return output.JSON(tokenCreateResult{APIToken: token, Secret: secret})
+			}
+			if quietOutput() {
+				fmt.Println(secret)
+				return nil
+			}
+			fmt.Printf("Created %s token %q (%s). Copy it now; it won't be shown again:\n\n  %s\n", token.Scope, token.Name, token.ID, secret)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&scope, "scope", string(library.ScopeRead), "Token scope: read, annotate, or admin")
+
+	return cmd
+}
+
+func newTokenListCmd(store library.LibraryStore) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List API tokens",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tokens, err := store.ListAPITokens()
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput(nil) {
+				return output.JSON(nonNil(tokens))
+			}
+			if quietOutput() {
+				for _, t := range tokens {
+					fmt.Println(t.ID)
+				}
+				return nil
+			}
+			if len(tokens) == 0 {
+				fmt.Println("No API tokens. Create one with \"arc-library token create <name>\".")
+				return nil
+			}
+			table := output.NewTable("ID", "Name", "Scope", "Created")
+			for _, t := range tokens {
+				table.AddRow(t.ID, t.Name, string(t.Scope), t.CreatedAt.Format("2006-01-02"))
+			}
+			table.Render()
+			return nil
+		},
+	}
+}
+
+func newTokenRevokeCmd(store library.LibraryStore) *cobra.Command {
+	return &cobra.Command{
+		Use:   "revoke <id-or-name>",
+		Short: "Revoke an API token",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			token, err := library.FindAPIToken(store, args[0])
+			if err != nil {
+				return err
+			}
+			if token == nil {
+				return fmt.Errorf("token not found: %s", args[0])
+			}
+			if err := store.DeleteAPIToken(token.ID); err != nil {
+				return err
+			}
+
+			if jsonOutput(nil) {
+				return output.JSON(deleteResult{Kind: "token", ID: token.ID, Deleted: true})
+			}
+			if !quietOutput() {
+				fmt.Printf("Revoked token %q (%s)\n", token.Name, token.ID)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,592 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mtreilly/arc-library/internal/library"
+	"github.com/spf13/cobra"
+	"github.com/yourorg/arc-sdk/config"
+)
+
+func newTUICmd(cfg *config.Config, store library.LibraryStore) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tui",
+		Short: "Browse the library in an interactive terminal UI",
+		Long: `Open a keyboard-driven browser for the library.
+
+The left pane lists collections and tags, the middle pane the matching
+documents, and the right pane a preview with notes and annotations.
+
+Keys:
+  tab / shift+tab   Switch pane
+  j/k, up/down      Move selection
+  enter             Apply the selected collection or tag filter
+  t                 Add a tag to the selected document
+  x                 Remove a tag from the selected document
+  s                 Cycle reading status (unread → reading → completed → archived)
+  o                 Open the document in the system reader
+  r                 Reload from the library
+  q, ctrl+c         Quit`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			m, err := newTUIModel(store)
+			if err != nil {
+				return err
+			}
+			_, err = tea.NewProgram(m, tea.WithAltScreen()).Run()
+			return err
+		},
+	}
+
+	return cmd
+}
+
+type tuiPane int
+
+const (
+	paneFilters tuiPane = iota
+	paneDocuments
+)
+
+type tuiFilterKind int
+
+const (
+	filterAll tuiFilterKind = iota
+	filterCollection
+	filterTag
+)
+
+// tuiFilter is one selectable entry in the left pane.
+type tuiFilter struct {
+	kind  tuiFilterKind
+	label string
+	value string // collection ID or tag name
+}
+
+type tuiInputMode int
+
+const (
+	inputNone tuiInputMode = iota
+	inputAddTag
+	inputRemoveTag
+)
+
+type tuiModel struct {
+	store library.LibraryStore
+
+	filters     []tuiFilter
+	filterIdx   int
+	activeIdx   int
+	docs        []*library.Document
+	docIdx      int
+	annotations []*library.Annotation
+
+	focus     tuiPane
+	inputMode tuiInputMode
+	input     textinput.Model
+	status    string
+
+	width  int
+	height int
+}
+
+func newTUIModel(store library.LibraryStore) (*tuiModel, error) {
+	ti := textinput.New()
+	ti.CharLimit = 64
+
+	m := &tuiModel{
+		store: store,
+		input: ti,
+		focus: paneDocuments,
+	}
+	if err := m.reload(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// reload re-reads filters and documents, keeping the current selection where possible.
+func (m *tuiModel) reload() error {
+	filters := []tuiFilter{{kind: filterAll, label: "All documents"}}
+
+	collections, err := m.store.ListCollections()
+	if err != nil {
+		return err
+	}
+	for _, c := range collections {
+		filters = append(filters, tuiFilter{kind: filterCollection, label: "◆ " + c.Name, value: c.ID})
+	}
+
+	tags, err := m.store.ListTags()
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(tags))
+	for t := range tags {
+		names = append(names, t)
+	}
+	sort.Strings(names)
+	for _, t := range names {
+		filters = append(filters, tuiFilter{kind: filterTag, label: fmt.Sprintf("# %s (%d)", t, tags[t]), value: t})
+	}
+
+	m.filters = filters
+	if m.activeIdx >= len(m.filters) {
+		m.activeIdx = 0
+	}
+	if m.filterIdx >= len(m.filters) {
+		m.filterIdx = len(m.filters) - 1
+	}
+
+	return m.loadDocuments()
+}
+
+func (m *tuiModel) loadDocuments() error {
+	var selected string
+	if doc := m.selected(); doc != nil {
+		selected = doc.ID
+	}
+
+	active := m.filters[m.activeIdx]
+	var docs []*library.Document
+	switch active.kind {
+	case filterTag:
+		var err error
+		docs, err = m.store.ListDocuments(&library.ListOptions{Tag: active.value})
+		if err != nil {
+			return err
+		}
+	case filterCollection:
+		coll, err := m.store.GetCollection(active.value)
+		if err != nil {
+			return err
+		}
+		if coll == nil {
+			return fmt.Errorf("%w: %s", library.ErrCollectionNotFound, active.value)
+		}
+		for _, id := range coll.DocumentIDs {
+			if doc, err := m.store.GetDocument(id); err == nil && doc != nil {
+				docs = append(docs, doc)
+			}
+		}
+	default:
+		var err error
+		docs, err = m.store.ListDocuments(&library.ListOptions{HideArchived: true})
+		if err != nil {
+			return err
+		}
+	}
+
+	m.docs = docs
+	m.docIdx = 0
+	for i, d := range docs {
+		if d.ID == selected {
+			m.docIdx = i
+			break
+		}
+	}
+	m.loadPreview()
+	return nil
+}
+
+func (m *tuiModel) loadPreview() {
+	m.annotations = nil
+	if doc := m.selected(); doc != nil {
+		// Annotations are best-effort; a failure just leaves the preview without them
+		m.annotations, _ = m.store.GetAnnotations(doc.ID)
+	}
+}
+
+func (m *tuiModel) selected() *library.Document {
+	if m.docIdx < 0 || m.docIdx >= len(m.docs) {
+		return nil
+	}
+	return m.docs[m.docIdx]
+}
+
+func (m *tuiModel) Init() tea.Cmd {
+	m.noteReadErrors()
+	return nil
+}
+
+// noteReadErrors shows in the status line how many records could not be
+// read since last time; warnings on stderr would garble the screen.
+func (m *tuiModel) noteReadErrors() {
+	if errs, more := library.TakeReadErrors(); len(errs) > 0 {
+		m.status = fmt.Sprintf("%d record(s) could not be read and were skipped (--strict fails on them)", len(errs)+more)
+	}
+}
+
+type tuiReaderDoneMsg struct{ err error }
+
+func (m *tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		return m, nil
+
+	case tuiReaderDoneMsg:
+		if msg.err != nil {
+			m.status = "open failed: " + msg.err.Error()
+		}
+		return m, nil
+
+	case tea.KeyMsg:
+		defer m.noteReadErrors()
+		if m.inputMode != inputNone {
+			return m.updateInput(msg)
+		}
+		return m.updateKeys(msg)
+	}
+	return m, nil
+}
+
+func (m *tuiModel) updateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.inputMode = inputNone
+		m.input.Blur()
+		m.status = ""
+		return m, nil
+	case tea.KeyEnter:
+		tag := strings.TrimSpace(m.input.Value())
+		mode := m.inputMode
+		m.inputMode = inputNone
+		m.input.Blur()
+		doc := m.selected()
+		if tag == "" || doc == nil {
+			return m, nil
+		}
+		var err error
+		if mode == inputAddTag {
+			err = library.ChangeTagsUndoable(m.store, fmt.Sprintf("tag %s +%s", truncate(doc.Title, 40), tag), []string{doc.ID}, func() error {
+				return m.store.AddTag(doc.ID, tag)
+			})
+			m.status = fmt.Sprintf("tagged %q", tag)
+		} else {
+			err = library.ChangeTagsUndoable(m.store, fmt.Sprintf("untag %s -%s", truncate(doc.Title, 40), tag), []string{doc.ID}, func() error {
+				return m.store.RemoveTag(doc.ID, tag)
+			})
+			m.status = fmt.Sprintf("removed tag %q", tag)
+		}
+		if err != nil {
+			m.status = "error: " + err.Error()
+			return m, nil
+		}
+		if err := m.reload(); err != nil {
+			m.status = "error: " + err.Error()
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m *tuiModel) updateKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+
+	case "tab", "shift+tab", "left", "right", "h", "l":
+		if m.focus == paneFilters {
+			m.focus = paneDocuments
+		} else {
+			m.focus = paneFilters
+		}
+
+	case "j", "down":
+		if m.focus == paneFilters {
+			if m.filterIdx < len(m.filters)-1 {
+				m.filterIdx++
+			}
+		} else if m.docIdx < len(m.docs)-1 {
+			m.docIdx++
+			m.loadPreview()
+		}
+
+	case "k", "up":
+		if m.focus == paneFilters {
+			if m.filterIdx > 0 {
+				m.filterIdx--
+			}
+		} else if m.docIdx > 0 {
+			m.docIdx--
+			m.loadPreview()
+		}
+
+	case "enter":
+		if m.focus == paneFilters {
+			m.activeIdx = m.filterIdx
+			if err := m.loadDocuments(); err != nil {
+				m.status = "error: " + err.Error()
+			}
+			m.focus = paneDocuments
+		}
+
+	case "t", "x":
+		if m.selected() == nil {
+			return m, nil
+		}
+		m.inputMode = inputAddTag
+		m.input.Prompt = "add tag: "
+		if msg.String() == "x" {
+			m.inputMode = inputRemoveTag
+			m.input.Prompt = "remove tag: "
+		}
+		m.input.SetValue("")
+		return m, m.input.Focus()
+
+	case "s":
+		doc := m.selected()
+		if doc == nil {
+			return m, nil
+		}
+		doc.Status = nextReadingStatus(doc.Status)
+		switch doc.Status {
+		case library.StatusCompleted:
+			// Completion date drives "recent --read" and "recent --by-month"
+			doc.ReadAt = time.Now()
+		case library.StatusUnread:
+			// Back in the inbox, and no longer among recent reads
+			doc.ReadAt = time.Time{}
+		}
+		if err := m.store.UpdateDocument(doc); err != nil {
+			m.status = "error: " + err.Error()
+		} else {
+			m.status = "status: " + string(doc.Status)
+		}
+
+	case "o":
+		doc := m.selected()
+		if doc == nil {
+			return m, nil
+		}
+		c, err := readerCommand(doc)
+		if err != nil {
+			m.status = err.Error()
+			return m, nil
+		}
+		return m, tea.ExecProcess(c, func(err error) tea.Msg { return tuiReaderDoneMsg{err} })
+
+	case "r":
+		if err := m.reload(); err != nil {
+			m.status = "error: " + err.Error()
+		} else {
+			m.status = "reloaded"
+		}
+	}
+	return m, nil
+}
+
+// nextReadingStatus cycles through the reading statuses; an unset status counts as unread.
+func nextReadingStatus(s library.ReadingStatus) library.ReadingStatus {
+	switch s {
+	case library.StatusUnread, "":
+		return library.StatusReading
+	case library.StatusReading:
+		return library.StatusCompleted
+	case library.StatusCompleted:
+		return library.StatusArchived
+	default:
+		return library.StatusUnread
+	}
+}
+
+// readerCommand builds the command that opens doc in the platform's default
+// viewer, preferring the local file and falling back to the arXiv page.
+func readerCommand(doc *library.Document) (*exec.Cmd, error) {
+	target := library.DocumentPath(doc)
+	if target == "" && doc.Source == "arxiv" && doc.SourceID != "" {
+		target = "https://arxiv.org/abs/" + doc.SourceID
+	}
+	if target == "" {
+		return nil, fmt.Errorf("document has no path or URL to open")
+	}
+
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", target), nil
+	case "windows":
+		return exec.Command("cmd", "/c", "start", "", target), nil
+	default:
+		return exec.Command("xdg-open", target), nil
+	}
+}
+
+var (
+	tuiPaneStyle     = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("240")).Padding(0, 1)
+	tuiFocusStyle    = tuiPaneStyle.BorderForeground(lipgloss.Color("69"))
+	tuiSelectedStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("69"))
+	tuiActiveStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("212"))
+	tuiDimStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	tuiHeadingStyle  = lipgloss.NewStyle().Bold(true)
+)
+
+func (m *tuiModel) View() string {
+	if m.width == 0 {
+		return "loading..."
+	}
+
+	// Borders and padding take 4 columns and 2 rows per pane
+	bodyHeight := m.height - 4
+	if bodyHeight < 3 {
+		bodyHeight = 3
+	}
+	filterWidth := max(m.width/5, 10)
+	docWidth := max(m.width*2/5, 10)
+	previewWidth := max(m.width-filterWidth-docWidth-12, 10)
+
+	filters := m.renderFilters(filterWidth, bodyHeight)
+	docs := m.renderDocuments(docWidth, bodyHeight)
+	preview := m.renderPreview(previewWidth, bodyHeight)
+
+	filterStyle, docStyle := tuiPaneStyle, tuiPaneStyle
+	if m.focus == paneFilters {
+		filterStyle = tuiFocusStyle
+	} else {
+		docStyle = tuiFocusStyle
+	}
+
+	panes := lipgloss.JoinHorizontal(lipgloss.Top,
+		filterStyle.Width(filterWidth).Height(bodyHeight).Render(filters),
+		docStyle.Width(docWidth).Height(bodyHeight).Render(docs),
+		tuiPaneStyle.Width(previewWidth).Height(bodyHeight).Render(preview),
+	)
+
+	footer := tuiDimStyle.Render("tab pane · enter filter · t tag · x untag · s status · o open · r reload · q quit")
+	if m.inputMode != inputNone {
+		footer = m.input.View()
+	} else if m.status != "" {
+		footer = m.status
+	}
+	return panes + "\n" + footer
+}
+
+// visibleWindow returns the [start, end) range of n items that keeps idx visible in height rows.
+func visibleWindow(n, idx, height int) (int, int) {
+	start := 0
+	if idx >= height {
+		start = idx - height + 1
+	}
+	end := start + height
+	if end > n {
+		end = n
+	}
+	return start, end
+}
+
+func (m *tuiModel) renderFilters(width, height int) string {
+	var b strings.Builder
+	start, end := visibleWindow(len(m.filters), m.filterIdx, height)
+	for i := start; i < end; i++ {
+		line := truncate(m.filters[i].label, width)
+		switch {
+		case i == m.filterIdx && m.focus == paneFilters:
+			line = tuiSelectedStyle.Render("> " + line)
+		case i == m.activeIdx:
+			line = tuiActiveStyle.Render("  " + line)
+		default:
+			line = "  " + line
+		}
+		b.WriteString(line + "\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func (m *tuiModel) renderDocuments(width, height int) string {
+	if len(m.docs) == 0 {
+		return tuiDimStyle.Render("No documents")
+	}
+	var b strings.Builder
+	start, end := visibleWindow(len(m.docs), m.docIdx, height)
+	for i := start; i < end; i++ {
+		d := m.docs[i]
+		marker := statusMarker(d.Status)
+		line := truncate(d.Title, width-4)
+		if i == m.docIdx {
+			b.WriteString(tuiSelectedStyle.Render(marker + " " + line))
+		} else {
+			b.WriteString(marker + " " + line)
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// statusMarker is the single-character reading-status indicator shown in the document list.
+func statusMarker(s library.ReadingStatus) string {
+	switch s {
+	case library.StatusReading:
+		return "◐"
+	case library.StatusCompleted:
+		return "●"
+	case library.StatusArchived:
+		return "▪"
+	default:
+		return "○"
+	}
+}
+
+func (m *tuiModel) renderPreview(width, height int) string {
+	doc := m.selected()
+	if doc == nil {
+		return ""
+	}
+
+	wrap := lipgloss.NewStyle().Width(width)
+	var b strings.Builder
+	b.WriteString(tuiHeadingStyle.Render(wrap.Render(doc.Title)) + "\n")
+	if len(doc.Authors) > 0 {
+		b.WriteString(wrap.Render(strings.Join(doc.Authors, ", ")) + "\n")
+	}
+
+	status := doc.Status
+	if status == "" {
+		status = library.StatusUnread
+	}
+	meta := fmt.Sprintf("%s · %s · %s", doc.Type, doc.Source, status)
+	if doc.SourceID != "" {
+		meta += " · " + doc.SourceID
+	}
+	b.WriteString(tuiDimStyle.Render(meta) + "\n")
+	if len(doc.Tags) > 0 {
+		b.WriteString(tuiActiveStyle.Render("# "+strings.Join(doc.Tags, " # ")) + "\n")
+	}
+
+	if doc.Abstract != "" {
+		b.WriteString("\n" + wrap.Render(truncate(doc.Abstract, 600)) + "\n")
+	}
+	if doc.Notes != "" {
+		b.WriteString("\n" + tuiHeadingStyle.Render("Notes") + "\n" + wrap.Render(doc.Notes) + "\n")
+	}
+	if len(m.annotations) > 0 {
+		b.WriteString("\n" + tuiHeadingStyle.Render(fmt.Sprintf("Annotations (%d)", len(m.annotations))) + "\n")
+		for _, a := range m.annotations {
+			line := fmt.Sprintf("[%s]", a.Type)
+			if a.Page > 0 {
+				line += fmt.Sprintf(" p.%d", a.Page)
+			}
+			line += " " + a.Content
+			b.WriteString(wrap.Render(line) + "\n")
+		}
+	}
+
+	// Clip to the pane so long previews do not push the layout
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	return strings.Join(lines, "\n")
+}
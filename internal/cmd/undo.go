@@ -0,0 +1,102 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/mtreilly/arc-library/internal/library"
+	"github.com/spf13/cobra"
+	"github.com/yourorg/arc-sdk/config"
+	"github.com/yourorg/arc-sdk/output"
+)
+
+func newUndoCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command {
+	var list bool
+	var skip bool
+
+	cmd := &cobra.Command{
+		Use:   "undo",
+		Short: "Reverse the last destructive operation",
+		Long: fmt.Sprintf(`Reverse the most recent destructive operation: deleting a document,
+collection, or flashcard, or adding or removing tags. Run it again to step
+further back. The journal keeps the last %d operations.
+
+Restored collections get a new ID; flashcards come back without their
+review log, and documents without their reading sessions. If an operation
+can no longer be reversed, --skip drops it from the journal.
+
+Examples:
+  arc-library undo          # Undo the last operation
+  arc-library undo --list   # Show what can be undone, newest first
+  arc-library undo --skip   # Discard the last entry without undoing it`, library.JournalLimit),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if list {
+				ops, err := store.ListOperations(0)
+				if err != nil {
+					return fmt.Errorf("list operations: %w", err)
+				}
+				if jsonOutput(nil) {
+					return output.JSON(nonNil(ops))
+				}
+				if quietOutput() {
+					for _, op := range ops {
+						printIDs(op.ID)
+					}
+					return nil
+				}
+				if len(ops) == 0 {
+					fmt.Println("Nothing to undo.")
+					return nil
+				}
+				table := output.NewTable("When", "Kind", "Operation")
+				for _, op := range ops {
+					table.AddRow(op.CreatedAt.Local().Format("2006-01-02 15:04"), string(op.Kind), truncate(op.Summary, 60))
+				}
+				table.Render()
+				return nil
+			}
+
+			if skip {
+				ops, err := store.ListOperations(1)
+				if err != nil {
+					return fmt.Errorf("list operations: %w", err)
+				}
+				if len(ops) == 0 {
+					infoln("Nothing to undo.")
+					return nil
+				}
+				if err := store.DeleteOperation(ops[0].ID); err != nil {
+					return fmt.Errorf("drop operation: %w", err)
+				}
+				if jsonOutput(nil) {
+					return output.JSON(ops[0])
+				}
+				infof("Dropped from journal: %s\n", ops[0].Summary)
+				return nil
+			}
+
+			op, err := library.Undo(store)
+			if err != nil {
+				return fmt.Errorf("%w (use --skip to drop it from the journal)", err)
+			}
+			if jsonOutput(nil) {
+				return output.JSON(op)
+			}
+			if op == nil {
+				infoln("Nothing to undo.")
+				return nil
+			}
+			infof("Undone: %s\n", op.Summary)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&list, "list", false, "List undoable operations instead of undoing")
+	cmd.Flags().BoolVar(&skip, "skip", false, "Drop the last operation from the journal without undoing it")
+	cmd.MarkFlagsMutuallyExclusive("list", "skip")
+
+	return cmd
+}
@@ -4,10 +4,14 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -16,74 +20,205 @@ import (
 	"github.com/mtreilly/arc-library/internal/library"
 	"github.com/spf13/cobra"
 	"github.com/yourorg/arc-sdk/config"
+	"github.com/yourorg/arc-sdk/output"
 )
 
 func newWatchCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command {
 	var (
-		dir           string
 		recursive     bool
 		extractText   bool
 		resolveDOI    bool
+		verifyTitle   bool
 		tags          []string
 		collection    string
 		debounceMs    int
+		retries       int
+		flagMissing   bool
 		oneShot       bool
+		ignore        []string
+		extract       extractFlags
 	)
 
 	cmd := &cobra.Command{
-		Use:   "watch [directory]",
-		Short: "Watch a folder for new PDFs and auto-import",
-		Long: `Monitor a directory for new PDF files and automatically import them into the library.
+		Use:   "watch [directory...]",
+		Short: "Watch folders for new PDFs and auto-import",
+		Long: `Monitor directories for new PDF files and automatically import them into the library.
+
+Without arguments, the directories in ARC_LIBRARY_WATCH_DIRS (separated like
+PATH) are watched, or ~/Downloads if that is unset. --ignore takes glob
+patterns matched against file and directory names and against paths
+relative to the watched directory; ignored directories are skipped
+entirely. With --recursive, directories created while watching are
+picked up too.
+
+Before importing, a file must have stopped growing, be openable for
+writing (not held by a downloader), and, for PDFs, end with its %%EOF
+trailer. Files that aren't ready or fail to import are retried with
+exponential backoff (2s, 4s, 8s, ... up to a minute) --retries times.
+
+Files already in the library are kept in sync: when one is replaced, its
+text is extracted again (if it had text or --extract-text is set); when
+one is moved within the watched directories, the document's path follows
+it. With --flag-missing, documents whose files are deleted or moved out
+are tagged "file-missing", and untagged if the file comes back.
 
 Examples:
   arc-library watch ~/Downloads/papers
+  arc-library watch ~/Downloads ~/Dropbox/papers --recursive --ignore ".git" --ignore "*draft*"
   arc-library watch ~/Dropbox --recursive --extract-text --tag "inbox"
   arc-library watch ~/Papers --collection "To Read" --one-shot`,
-		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// Determine watch directory
-			if len(args) > 0 {
-				dir = args[0]
+			if err := extract.apply(); err != nil {
+				return err
 			}
-			if dir == "" {
+
+			// Determine watch directories
+			dirs := args
+			if len(dirs) == 0 {
+				// The shell has expanded arguments but not the setting
+				for _, dir := range filepath.SplitList(os.Getenv("ARC_LIBRARY_WATCH_DIRS")) {
+					dirs = append(dirs, library.ExpandPath(dir))
+				}
+			}
+			if len(dirs) == 0 {
 				home, err := os.UserHomeDir()
 				if err != nil {
 					return fmt.Errorf("cannot determine home directory: %w", err)
 				}
-				dir = filepath.Join(home, "Downloads")
+				dirs = []string{filepath.Join(home, "Downloads")}
 			}
 
-			// Verify directory exists
-			info, err := os.Stat(dir)
-			if err != nil {
-				return fmt.Errorf("cannot access directory %s: %w", dir, err)
+			for _, pattern := range ignore {
+				if _, err := filepath.Match(pattern, ""); err != nil {
+					return fmt.Errorf("invalid ignore pattern %q: %w", pattern, err)
+				}
 			}
-			if !info.IsDir() {
-				return fmt.Errorf("%s is not a directory", dir)
+
+			// Detection rules and hooks are read per file; check them up front
+			if _, err := typeRules(); err != nil {
+				return err
+			}
+			if _, err := loadHooks(); err != nil {
+				return err
+			}
+
+			filter := &watchFilter{recursive: recursive, ignore: ignore}
+			for _, dir := range dirs {
+				// Verify directory exists
+				info, err := os.Stat(dir)
+				if err != nil {
+					return fmt.Errorf("cannot access directory %s: %w", dir, err)
+				}
+				if !info.IsDir() {
+					return fmt.Errorf("%s is not a directory", dir)
+				}
+				filter.roots = append(filter.roots, filepath.Clean(dir))
 			}
 
 			// One-shot: just process existing files
 			if oneShot {
-				return processExistingFiles(dir, recursive, store, extractText, resolveDOI, tags, collection)
+				return processExistingFiles(filter, store, extractText, resolveDOI, verifyTitle, tags, collection)
 			}
 
 			// Start watching
-			return watchDirectory(dir, recursive, store, extractText, resolveDOI, tags, collection, debounceMs)
+			opts := watchOptions{
+				debounce:    time.Duration(debounceMs) * time.Millisecond,
+				retries:     retries,
+				flagMissing: flagMissing,
+			}
+			return watchDirectories(filter, store, extractText, resolveDOI, verifyTitle, tags, collection, opts)
 		},
 	}
 
 	cmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "Watch subdirectories recursively")
 cmd.Flags().BoolVar(&extractText, "extract-text", false, "Extract full text from PDFs")
-	cmd.Flags().BoolVar(&resolveDOI, "resolve-doi", false, "Try to resolve DOI from PDF metadata")
+	cmd.Flags().BoolVar(&resolveDOI, "resolve-doi", false, "Resolve metadata from a DOI or arXiv ID found in the PDF's text or file name")
+	cmd.Flags().BoolVar(&verifyTitle, "verify-title", true, "Look titles read from PDFs up on Crossref and arXiv and use the matching work's metadata")
 	cmd.Flags().StringSliceVarP(&tags, "tag", "t", nil, "Tags to apply to imported documents")
 	cmd.Flags().StringVarP(&collection, "collection", "c", "", "Add imported documents to collection")
 	cmd.Flags().IntVar(&debounceMs, "debounce", 1000, "Debounce milliseconds for file events")
+	cmd.Flags().IntVar(&retries, "retries", 6, "Times to retry files that aren't ready or fail to import")
+	cmd.Flags().BoolVar(&flagMissing, "flag-missing", false, "Tag documents whose files are deleted or moved away with "+library.MissingFileTag)
 	cmd.Flags().BoolVar(&oneShot, "one-shot", false, "Process existing files and exit (don't watch)")
+	cmd.Flags().StringArrayVar(&ignore, "ignore", nil, "Glob pattern of files or directories to skip (repeatable)")
+	extract.addFlags(cmd)
 
 	return cmd
 }
 
-func watchDirectory(dir string, recursive bool, store library.LibraryStore, extractText, resolveDOI bool, tags []string, collection string, debounceMs int) error {
+// watchFilter decides which directories and files under the watch roots
+// are considered.
+type watchFilter struct {
+	roots     []string
+	recursive bool
+	ignore    []string
+}
+
+// ignored reports whether path matches an ignore pattern, by name or by
+// its path relative to the watch root containing it.
+func (f *watchFilter) ignored(path string) bool {
+	name := filepath.Base(path)
+	rel := ""
+	for _, root := range f.roots {
+		if r, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(r, "..") && len(r) > len(rel) {
+			rel = filepath.ToSlash(r)
+		}
+	}
+	for _, pattern := range f.ignore {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, rel); ok && rel != "." {
+			return true
+		}
+	}
+	return false
+}
+
+// walk calls fn for every PDF under root that isn't ignored, descending
+// into subdirectories when recursive.
+func (f *watchFilter) walk(root string, fn func(path string, info os.FileInfo) error) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != root && (!f.recursive || f.ignored(path)) {
+				return filepath.SkipDir
+			}
+			return fn(path, info)
+		}
+		if strings.EqualFold(filepath.Ext(path), ".pdf") && !f.ignored(path) {
+			return fn(path, info)
+		}
+		return nil
+	})
+}
+
+// watchBackoff is the first retry delay for a file; it doubles per attempt
+// up to watchMaxBackoff.
+const (
+	watchBackoff    = 2 * time.Second
+	watchMaxBackoff = time.Minute
+	// watchMoveWindow is how long after a file disappears a file with the
+	// same name showing up counts as the same file moved
+	watchMoveWindow = 30 * time.Second
+)
+
+// watchOptions configure how watchDirectories reacts to file events.
+type watchOptions struct {
+	debounce    time.Duration
+	retries     int
+	flagMissing bool
+}
+
+// goneFile is a library file that disappeared while watching.
+type goneFile struct {
+	storedPath string
+	at         time.Time
+}
+
+func watchDirectories(filter *watchFilter, store library.LibraryStore, extractText, resolveDOI, verifyTitle bool, tags []string, collection string, opts watchOptions) error {
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
 		return fmt.Errorf("create watcher: %w", err)
@@ -92,31 +227,158 @@ func watchDirectory(dir string, recursive bool, store library.LibraryStore, extr
 
 	// Track pending imports with debounce
 	pending := make(map[string]*time.Timer)
+	gone := make(map[string]goneFile) // by file name, to recognize moves
 	var pendingMu sync.Mutex
+	// Timers fire concurrently; touch the store from one at a time
+	var importMu sync.Mutex
+
+	debounce := opts.debounce
+	root := library.LibraryRoot()
+
+	// takeGone returns the stored path of a file with path's name that
+	// disappeared recently, or ""
+	takeGone := func(path string) string {
+		pendingMu.Lock()
+		defer pendingMu.Unlock()
+		g, ok := gone[filepath.Base(path)]
+		delete(gone, filepath.Base(path))
+		if !ok || time.Since(g.at) > watchMoveWindow {
+			return ""
+		}
+		return g.storedPath
+	}
 
-	// Add directories to watch
-	if recursive {
-		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return err
+	// handleFile imports a new file, refreshes one already in the library,
+	// or moves the document of a file that disappeared elsewhere. Its
+	// text, with --extract-text, has been extracted already
+	handleFile := func(path string, extracted *library.TextExtraction) error {
+		importMu.Lock()
+		defer importMu.Unlock()
+
+		stored := library.StoredPath(path, root)
+		if doc, err := store.GetDocumentByPath(stored); err == nil && doc != nil {
+			if extracted == nil && doc.FullText != "" {
+				extracted = library.DefaultTextExtractor().Extract(path)
+			}
+			return refreshFile(store, doc, path, stored, extracted)
+		}
+		if from := takeGone(path); from != "" {
+			if doc, err := store.GetDocumentByPath(from); err == nil && doc != nil {
+				log.Printf("Moved: %s -> %s", doc.Path, stored)
+				return refreshFile(store, doc, path, stored, nil)
 			}
-			if info.IsDir() {
-				if err := watcher.Add(path); err != nil {
-					log.Printf("Warning: cannot watch %s: %v", path, err)
-				} else {
-					log.Printf("Watching: %s", path)
+		}
+		return importFile(path, store, extracted, resolveDOI, verifyTitle, tags, collection)
+	}
+
+	// schedule handles path after delay; attempt counts retries
+	var schedule func(path string, attempt int, delay time.Duration)
+	schedule = func(path string, attempt int, delay time.Duration) {
+		// Debounce: reset timer if file is still being written
+		pendingMu.Lock()
+		defer pendingMu.Unlock()
+		if timer, exists := pending[path]; exists {
+			timer.Stop()
+		}
+		pending[path] = time.AfterFunc(delay, func() {
+			pendingMu.Lock()
+			delete(pending, path)
+			pendingMu.Unlock()
+
+			// Sample the size twice within one debounce interval
+			err := library.CheckFileReady(path, debounce/2, 2)
+			if err == nil {
+				// Extract before queueing for the store, so that a PDF
+				// pdftotext is stuck on holds up only itself, and only
+				// until it times out
+				var extracted *library.TextExtraction
+				if extractText {
+					extracted = library.DefaultTextExtractor().Extract(path)
+				}
+				err = handleFile(path, extracted)
+			}
+			ReportReadErrors()
+			switch {
+			case err == nil:
+				return
+			case errors.Is(err, fs.ErrNotExist):
+				log.Printf("Skipped %s: file was removed", path)
+			case attempt >= opts.retries:
+				log.Printf("Failed to import %s after %d attempt(s): %v", path, attempt+1, err)
+			default:
+				backoff := watchBackoff << attempt
+				if backoff > watchMaxBackoff || backoff <= 0 {
+					backoff = watchMaxBackoff
+				}
+				log.Printf("Not imported %s: %v; retrying in %s", path, err, backoff)
+				schedule(path, attempt+1, backoff)
+			}
+		})
+	}
+
+	// fileGone remembers a removed or renamed-away file so a matching
+	// create can be recognized as a move, and flags its document if no
+	// move shows up in time
+	fileGone := func(path string) {
+		if _, err := os.Stat(path); err == nil {
+			// Replaced in place; the create or write event handles it
+			return
+		}
+		stored := library.StoredPath(path, root)
+		pendingMu.Lock()
+		for name, g := range gone {
+			if time.Since(g.at) > watchMoveWindow {
+				delete(gone, name)
+			}
+		}
+		gone[filepath.Base(path)] = goneFile{storedPath: stored, at: time.Now()}
+		pendingMu.Unlock()
+
+		if !opts.flagMissing {
+			return
+		}
+		time.AfterFunc(watchMoveWindow, func() {
+			importMu.Lock()
+			defer importMu.Unlock()
+			doc, err := store.GetDocumentByPath(stored)
+			if err != nil || doc == nil {
+				return
+			}
+			if _, err := os.Stat(library.DocumentPath(doc)); err == nil {
+				return
+			}
+			if err := store.AddTag(doc.ID, library.MissingFileTag); err != nil {
+				log.Printf("Failed to flag %s: %v", doc.Title, err)
+				return
+			}
+			log.Printf("Flagged missing file: %s (%s)", doc.Title, path)
+		})
+	}
+
+	// addTree watches dir and, when recursive, its subdirectories; PDFs
+	// found on the way are imported if scheduleFiles is set
+	addTree := func(dir string, scheduleFiles bool) error {
+		return filter.walk(dir, func(path string, info os.FileInfo) error {
+			if !info.IsDir() {
+				if scheduleFiles {
+					schedule(path, 0, debounce)
 				}
+				return nil
+			}
+			if err := watcher.Add(path); err != nil {
+				log.Printf("Warning: cannot watch %s: %v", path, err)
+			} else {
+				log.Printf("Watching: %s", path)
 			}
 			return nil
 		})
-		if err != nil {
+	}
+
+	// Add directories to watch
+	for _, root := range filter.roots {
+		if err := addTree(root, false); err != nil {
 			return fmt.Errorf("walk directories: %w", err)
 		}
-	} else {
-		if err := watcher.Add(dir); err != nil {
-			return fmt.Errorf("watch directory: %w", err)
-		}
-		log.Printf("Watching: %s", dir)
 	}
 
 	log.Println("Press Ctrl+C to stop watching")
@@ -128,32 +390,35 @@ func watchDirectory(dir string, recursive bool, store library.LibraryStore, extr
 			if !ok {
 				return nil
 			}
-
-			// Only care about PDFs
-			if !strings.HasSuffix(strings.ToLower(event.Name), ".pdf") {
+			if filter.ignored(event.Name) {
 				continue
 			}
+			isPDF := strings.HasSuffix(strings.ToLower(event.Name), ".pdf")
 
-			// Only process on create or rename (new files)
-			if event.Op&(fsnotify.Create|fsnotify.Rename) == 0 {
+			// Renames are reported for the old name; the new name gets a create
+			if event.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+				if isPDF {
+					fileGone(event.Name)
+				}
 				continue
 			}
 
-			// Debounce: reset timer if file is still being written
-			pendingMu.Lock()
-			if timer, exists := pending[event.Name]; exists {
-				timer.Stop()
+			// New subdirectories aren't watched automatically; files moved
+			// in along with them exist before the watch starts
+			if event.Op&fsnotify.Create != 0 && filter.recursive {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if err := addTree(event.Name, true); err != nil {
+						log.Printf("Warning: cannot watch %s: %v", event.Name, err)
+					}
+					continue
+				}
 			}
-			pending[event.Name] = time.AfterFunc(time.Duration(debounceMs)*time.Millisecond, func() {
-				pendingMu.Lock()
-				delete(pending, event.Name)
-				pendingMu.Unlock()
 
-				if err := importFile(event.Name, store, extractText, resolveDOI, tags, collection); err != nil {
-					log.Printf("Failed to import %s: %v", event.Name, err)
-				}
-			})
-			pendingMu.Unlock()
+			// Only care about PDFs being created or written
+			if !isPDF || event.Op&(fsnotify.Create|fsnotify.Write) == 0 {
+				continue
+			}
+			schedule(event.Name, 0, debounce)
 
 		case err, ok := <-watcher.Errors:
 			if !ok {
@@ -164,53 +429,125 @@ func watchDirectory(dir string, recursive bool, store library.LibraryStore, extr
 	}
 }
 
-func processExistingFiles(dir string, recursive bool, store library.LibraryStore, extractText, resolveDOI bool, tags []string, collection string) error {
+// refreshFile updates the document of a file already in the library after
+// the file was replaced or moved to path (stored as stored): it records the
+// path, clears the missing-file flag and, given the file's text extracted
+// again, stores it.
+func refreshFile(store library.LibraryStore, doc *library.Document, path, stored string, extracted *library.TextExtraction) error {
+	changed := doc.Path != stored
+	doc.Path = stored
+	if slices.Contains(doc.Tags, library.MissingFileTag) {
+		doc.Tags = slices.DeleteFunc(doc.Tags, func(t string) bool { return t == library.MissingFileTag })
+		changed = true
+		log.Printf("Found missing file again: %s", doc.Title)
+	}
+	if extracted != nil {
+		before, _ := doc.Meta["text_extraction"].(string)
+		extracted.Record(doc)
+		changed = changed || extracted.Status() != before
+		if extracted.Err != nil {
+			log.Printf("Warning: text extraction failed for %s: %v", path, extracted.Err)
+		} else if extracted.Text != doc.FullText {
+			doc.FullText = extracted.Text
+			changed = true
+			log.Printf("Updated text: %s", doc.Title)
+		}
+	}
+	if !changed {
+		return nil
+	}
+	doc.UpdatedAt = time.Now()
+	return store.UpdateDocument(doc)
+}
+
+func processExistingFiles(filter *watchFilter, store library.LibraryStore, extractText, resolveDOI, verifyTitle bool, tags []string, collection string) error {
 	var files []string
+	seen := make(map[string]bool) // roots may overlap
 
-	walkFn := func(path string, info os.FileInfo, err error) error {
+	for _, root := range filter.roots {
+		err := filter.walk(root, func(path string, info os.FileInfo) error {
+			if !info.IsDir() && !seen[path] {
+				seen[path] = true
+				files = append(files, path)
+			}
+			return nil
+		})
 		if err != nil {
-			return err
-		}
-		if !info.IsDir() && strings.HasSuffix(strings.ToLower(path), ".pdf") {
-			files = append(files, path)
+			return fmt.Errorf("walk directory: %w", err)
 		}
-		if info.IsDir() && !recursive && path != dir {
-			return filepath.SkipDir
-		}
-		return nil
 	}
 
-	if err := filepath.Walk(dir, walkFn); err != nil {
-		return fmt.Errorf("walk directory: %w", err)
-	}
+	result := watchResult{Imported: []string{}, Failed: []importFailure{}}
 
 	if len(files) == 0 {
-		fmt.Println("No PDF files found")
+		if jsonOutput(nil) {
+			return output.JSON(result)
+		}
+		infoln("No PDF files found")
 		return nil
 	}
 
-	fmt.Printf("Found %d PDF file(s), importing...\n", len(files))
+	infof("Found %d PDF file(s), importing...\n", len(files))
 
-	imported := 0
-	failed := 0
-	for _, f := range files {
-		if err := importFile(f, store, extractText, resolveDOI, tags, collection); err != nil {
+	// Text is extracted several files at a time, ahead of the imports;
+	// files in the library already are skipped
+	var extracted func(string) *library.TextExtraction
+	toExtract := make(map[string]bool)
+	if extractText {
+		var todo []string
+		root := library.LibraryRoot()
+		for _, f := range files {
+			if doc, err := store.GetDocumentByPath(library.StoredPath(f, root)); err != nil || doc == nil {
+				todo = append(todo, f)
+				toExtract[f] = true
+			}
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		extracted = library.DefaultTextExtractor().ExtractAll(ctx, todo)
+	}
+
+	bar := newProgress("Importing", len(files))
+	for i, f := range files {
+		bar.set(i, filepath.Base(f))
+		err := library.CheckFileReady(f, 0, 0)
+		if err == nil {
+			var text *library.TextExtraction
+			if toExtract[f] {
+				text = extracted(f)
+			}
+			err = importFile(f, store, text, resolveDOI, verifyTitle, tags, collection)
+		}
+		if err != nil {
 			log.Printf("Failed: %s - %v", f, err)
-			failed++
+			result.Failed = append(result.Failed, importFailure{Path: f, Error: err.Error()})
 		} else {
-			imported++
+			result.Imported = append(result.Imported, f)
 		}
 	}
+	bar.set(len(files), "")
+	bar.finish()
 
-	fmt.Printf("\nImported: %d, Failed: %d\n", imported, failed)
+	if jsonOutput(nil) {
+		return output.JSON(result)
+	}
+	infof("\nImported: %d, Failed: %d\n", len(result.Imported), len(result.Failed))
 	return nil
 }
 
-func importFile(path string, store library.LibraryStore, extractText, resolveDOI bool, tags []string, collection string) error {
+// watchResult is the JSON schema for "watch --one-shot".
+type watchResult struct {
+	Imported []string        `json:"imported"` // paths
+	Failed   []importFailure `json:"failed"`
+}
+
+// importFile imports the PDF at path, with the text extracted from it if
+// extracted is set.
+func importFile(path string, store library.LibraryStore, extracted *library.TextExtraction, resolveDOI, verifyTitle bool, tags []string, collection string) error {
 	log.Printf("Importing: %s", path)
 
 	doc := &library.Document{
-		Path:      path,
+		Path:      library.StoredPath(path, library.LibraryRoot()),
 		Source:    "local",
 		Type:      library.DocTypePaper, // default
 		Title:     filepath.Base(path),
@@ -219,76 +556,45 @@ func importFile(path string, store library.LibraryStore, extractText, resolveDOI
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
+	// Stores differ on a second document with the same path; neither is an import
+	if existing, err := store.GetDocumentByPath(doc.Path); err == nil && existing != nil {
+		return fmt.Errorf("already in the library: %s", existing.ID)
+	}
 
-	// Try to extract text if requested
-	if extractText {
-		text, err := library.PDFTextExtractor(path)
-		if err == nil && text != "" {
-			doc.FullText = text
-			// Try to extract title from first line
-			lines := strings.Split(text, "\n")
-			for _, line := range lines {
-				line = strings.TrimSpace(line)
-				if len(line) > 10 && len(line) < 200 {
-					doc.Title = line
-					break
-				}
-			}
+	if extracted != nil {
+		extracted.Record(doc)
+		if extracted.Err != nil {
+			log.Printf("Warning: text extraction failed for %s: %v", path, extracted.Err)
+		} else {
+			doc.FullText = extracted.Text
 		}
 	}
+	applyPDFHeading(doc, path, doc.FullText, verifyTitle, true, true, true)
 
-	// Try to resolve DOI if requested
+	// Try to resolve a DOI or arXiv ID found in the text or file name
 	if resolveDOI {
-		// Try to find DOI in text or filename
-		doi := ""
-		if doc.FullText != "" {
-			// Simple DOI regex extraction
-			if idx := strings.Index(doc.FullText, "10."); idx != -1 {
-				// Extract potential DOI (simplified)
-				rest := doc.FullText[idx:]
-				if space := strings.IndexAny(rest, " \n\t"); space != -1 {
-					doi = rest[:space]
-				}
-			}
-		}
-		if doi == "" {
-			// Try from filename - common pattern: 10.xxxx in filename
-			base := filepath.Base(path)
-			if idx := strings.Index(base, "10."); idx != -1 {
-				doi = base[idx:]
-				if ext := strings.LastIndex(doi, "."); ext != -1 {
-					doi = doi[:ext]
-				}
-			}
-		}
-		if doi != "" {
-			meta, err := library.DOIResolver(doi)
-			if err == nil {
-				if title, ok := meta["title"].(string); ok {
-					doc.Title = title
-				}
-				if authors, ok := meta["authors"].([]string); ok {
-					doc.Authors = authors
-				}
-				if abstract, ok := meta["abstract"].(string); ok {
-					doc.Abstract = abstract
-				}
-				doc.Source = "doi"
-				doc.SourceID = doi
-				if doc.Meta == nil {
-					doc.Meta = make(map[string]any)
-				}
-				doc.Meta["doi"] = doi
-				if year, ok := meta["year"].(int); ok {
-					doc.Meta["year"] = year
-				}
-				if journal, ok := meta["journal"].(string); ok {
-					doc.Meta["journal"] = journal
-				}
-			}
-		}
+		resolveDocumentMetadata(doc, "", path, true, true, true)
+	}
+
+	rules, err := typeRules()
+	if err != nil {
+		return err
+	}
+	if t, ok := library.DetectDocumentType(doc, rules); ok {
+		doc.Type = t
 	}
+	library.DetectDocumentLanguage(doc, false)
 
+	hooks, err := loadHooks()
+	if err != nil {
+		return err
+	}
+	// A dry run skips the hooks, scripts with effects of their own
+	if !dryRun() {
+		if err := hooks.PreImport(doc, os.Stderr); err != nil {
+			return err
+		}
+	}
 	if err := store.AddDocument(doc); err != nil {
 		return fmt.Errorf("add document: %w", err)
 	}
@@ -311,6 +617,14 @@ func importFile(path string, store library.LibraryStore, extractText, resolveDOI
 		}
 	}
 
+	if dryRun() {
+		log.Printf("Would import: %s (%s)", doc.Title, doc.Type)
+		return nil
+	}
+	if err := hooks.PostImport(doc, os.Stderr); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+
 	log.Printf("Imported: %s (ID: %s)", doc.Title, doc.ID)
 	return nil
 }
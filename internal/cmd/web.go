@@ -4,11 +4,22 @@
 package cmd
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
+	"mime"
+	"net"
 	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/mtreilly/arc-library/internal/library"
 	"github.com/spf13/cobra"
@@ -17,34 +28,110 @@ import (
 
 func newWebCmd(cfg *config.Config, store library.LibraryStore) *cobra.Command {
 	var (
-		port   int
-		bind   string
-		noOpen bool
+		port         int
+		bind         string
+		noOpen       bool
+		clipToken    string
+		sharesOnly   bool
+		requireToken bool
+		graphql      bool
 	)
 
 	cmd := &cobra.Command{
 		Use:   "serve",
 		Short: "Start web UI server",
-		Long:  "Start a read-only web interface for browsing the library.",
+		Long: `Start a web interface for browsing the library.
+
+Pages and GET requests only read the library. Annotations can be added with
+POST /api/document/<id>/annotations and documents changed in bulk with
+POST /api/batch; these need Content-Type: application/json, and browsers
+may only send them from the server's own pages.
+
+The server also accepts pages pushed from a browser bookmarklet or extension
+at POST /api/clip, authenticated with a bearer token. Set the token with
+--clip-token or ARC_LIBRARY_CLIP_TOKEN; without one a random token is
+generated and printed at startup.
+
+With --require-token every other request needs an API token created with
+"arc-library token create", sent as "Authorization: Bearer <token>" or as
+?token= (which also sets a cookie, so the dashboard can be opened in a
+browser with /?token=<token>). Tokens with the read scope may browse and
+search; annotate may also add annotations and change tags, statuses, and
+collections through POST /api/batch; admin may also clip pages.
+
+With --graphql, read-only GraphQL queries are answered at /graphql, POSTed
+as {"query", "variables", "operationName"} or sent as ?query=. A GET
+without a query returns the schema.
+
+Documents shared with "arc-library share" are served at /share/<token>
+until their links expire. Collections published with "arc-library
+collection publish" are served to anyone as an Atom feed at
+/feed/collection/<name>.xml and a reading list page at /list/<name>; other
+collections are served there to those who may read the library. With
+--shares-only nothing else is served, so the server can be exposed to
+others without exposing the library.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			addr := fmt.Sprintf("%s:%d", bind, port)
 
-			http.HandleFunc("/", handleIndex(store))
-			http.HandleFunc("/api/documents", handleAPIDocuments(store))
-			http.HandleFunc("/api/search", handleAPISearch(store))
-			http.HandleFunc("/api/document/", handleAPIDocument(store))
-			http.HandleFunc("/document/", handleDocumentPage(store))
+			http.HandleFunc("/share/", handleSharePage(store))
+			if sharesOnly {
+				// Published collections are public too
+				http.HandleFunc("/feed/collection/", handleCollectionFeed(store, nil))
+				http.HandleFunc("/list/", handleReadingList(store, nil))
+				infof("Serving shared documents only on http://%s/share/\n", addr)
+				infoln("Press Ctrl+C to stop")
+				return http.ListenAndServe(addr, reportingReadErrors(http.DefaultServeMux))
+			}
+
+			auth := &apiAuth{store: store, required: requireToken}
+			http.HandleFunc("/feed/collection/", handleCollectionFeed(store, auth))
+			http.HandleFunc("/list/", handleReadingList(store, auth))
+			http.HandleFunc("/", auth.require(library.ScopeRead, handleIndex(store)))
+			http.HandleFunc("/api/documents", auth.require(library.ScopeRead, handleAPIDocuments(store)))
+			http.HandleFunc("/api/stats", auth.require(library.ScopeRead, handleAPIStats(store)))
+			http.HandleFunc("/api/search", auth.require(library.ScopeRead, handleAPISearch(store)))
+			http.HandleFunc("/api/document/", auth.requireByMethod(handleAPIDocument(store)))
+			http.HandleFunc("/api/batch", auth.require(library.ScopeAnnotate, handleAPIBatch(store)))
+			if graphql {
+				http.HandleFunc("/graphql", auth.require(library.ScopeRead, handleGraphQL(store)))
+			}
+			http.HandleFunc("/document/", auth.require(library.ScopeRead, handleDocumentPage(store)))
+
+			generated := clipToken == ""
+			if generated {
+				b := make([]byte, 16)
+				if _, err := rand.Read(b); err != nil {
+					return fmt.Errorf("generate clip token: %w", err)
+				}
+				clipToken = hex.EncodeToString(b)
+			}
+			http.HandleFunc("/api/clip", handleAPIClip(store, clipToken, auth))
 
-			fmt.Printf("Starting arc-library web server on http://%s\n", addr)
-			fmt.Println("Press Ctrl+C to stop")
+			infof("Starting arc-library web server on http://%s\n", addr)
+			if readOnly() {
+				infof("Serving read-only (%s); changes are refused\n", readOnlyState.Reason)
+			}
+			if requireToken {
+				infoln("API tokens required (see \"arc-library token list\")")
+			} else if !isLoopback(bind) {
+				warnf("Serving the library on %s without --require-token: anyone who can reach it can read it\n", bind)
+			}
+			if generated {
+				infof("Clip token: %s (set ARC_LIBRARY_CLIP_TOKEN to keep it across restarts)\n", clipToken)
+			}
+			infoln("Press Ctrl+C to stop")
 
-			return http.ListenAndServe(addr, nil)
+			return http.ListenAndServe(addr, reportingReadErrors(http.DefaultServeMux))
 		},
 	}
 
 	cmd.Flags().IntVarP(&port, "port", "p", 8080, "Port to serve on")
 	cmd.Flags().StringVarP(&bind, "bind", "b", "127.0.0.1", "Address to bind to")
 	cmd.Flags().BoolVar(&noOpen, "no-open", false, "Don't open browser automatically")
+	cmd.Flags().StringVar(&clipToken, "clip-token", os.Getenv("ARC_LIBRARY_CLIP_TOKEN"), "Token required by /api/clip (default: random)")
+	cmd.Flags().BoolVar(&sharesOnly, "shares-only", false, "Serve only shared documents (/share/<token>)")
+	cmd.Flags().BoolVar(&requireToken, "require-token", false, "Require an API token (see \"token create\") on every request")
+	cmd.Flags().BoolVar(&graphql, "graphql", false, "Serve read-only GraphQL queries at /graphql")
 
 	return cmd
 }
@@ -66,6 +153,15 @@ var indexTemplate = `<!DOCTYPE html>
 		.stat-value { font-size: 24px; font-weight: bold; color: #3498db; }
 		.stat-label { font-size: 12px; color: #666; text-transform: uppercase; }
 		.documents { display: grid; gap: 15px; }
+		.documents.grid { grid-template-columns: repeat(auto-fill, minmax(180px, 1fr)); }
+		.documents.grid .doc { padding: 12px; }
+		.documents.grid .doc-title { font-size: 14px; }
+		.documents.grid .doc-authors, .documents.grid .doc-abstract, .documents.grid .doc-tags { display: none; }
+		.doc-cover { display: none; }
+		.documents.grid .doc-cover { display: block; width: 100%; aspect-ratio: 3 / 4; object-fit: cover; background: #f0f0f0; border-radius: 4px; margin-bottom: 8px; }
+		.toolbar { display: flex; gap: 10px; margin-bottom: 20px; }
+		.toolbar .search-box { margin-bottom: 0; }
+		.view-toggle { padding: 0 16px; border: 2px solid #ddd; border-radius: 4px; background: white; cursor: pointer; font-size: 14px; }
 		.doc { background: white; border: 1px solid #e0e0e0; border-radius: 8px; padding: 20px; transition: box-shadow 0.2s; }
 		.doc:hover { box-shadow: 0 4px 12px rgba(0,0,0,0.1); }
 		.doc-title { font-size: 18px; font-weight: 600; margin-bottom: 8px; }
@@ -78,6 +174,14 @@ var indexTemplate = `<!DOCTYPE html>
 		.doc-abstract { color: #555; font-size: 14px; margin-top: 10px; line-height: 1.5; }
 		.loading { text-align: center; padding: 40px; color: #666; }
 		.error { background: #fee; color: #c33; padding: 20px; border-radius: 4px; margin: 20px 0; }
+		.charts { display: grid; grid-template-columns: repeat(auto-fit, minmax(260px, 1fr)); gap: 15px; margin-bottom: 20px; }
+		.chart { background: #f8f9fa; padding: 12px 16px; border-radius: 4px; }
+		.chart h3 { font-size: 12px; color: #666; text-transform: uppercase; margin-bottom: 8px; }
+		.bars { display: flex; align-items: flex-end; gap: 3px; height: 80px; }
+		.bar { flex: 1; background: #3498db; min-height: 1px; border-radius: 2px 2px 0 0; }
+		.hbar-row { display: flex; align-items: center; gap: 8px; font-size: 12px; margin-bottom: 4px; }
+		.hbar-label { width: 90px; overflow: hidden; text-overflow: ellipsis; white-space: nowrap; }
+		.hbar { background: #3498db; height: 10px; border-radius: 2px; }
 	</style>
 </head>
 <body>
@@ -92,9 +196,27 @@ var indexTemplate = `<!DOCTYPE html>
 			<div class="stat-value" id="stat-collections">-</div>
 			<div class="stat-label">Collections</div>
 		</div>
+		<div class="stat">
+			<div class="stat-value" id="stat-completion">-</div>
+			<div class="stat-label">Completed</div>
+		</div>
+		<div class="stat">
+			<div class="stat-value" id="stat-overdue">-</div>
+			<div class="stat-label">Cards overdue</div>
+		</div>
+	</div>
+
+	<div class="charts">
+		<div class="chart"><h3>Added per month</h3><div class="bars" id="chart-added"></div></div>
+		<div class="chart"><h3>Reading minutes per week</h3><div class="bars" id="chart-reading"></div></div>
+		<div class="chart"><h3>Flashcards due (14 days)</h3><div class="bars" id="chart-due"></div></div>
+		<div class="chart"><h3>Top tags</h3><div id="chart-tags"></div></div>
 	</div>
 
-	<input type="text" class="search-box" id="search" placeholder="Search documents...">
+	<div class="toolbar">
+		<input type="text" class="search-box" id="search" placeholder="Search documents...">
+		<button class="view-toggle" id="view-toggle">Grid</button>
+	</div>
 	
 	<div class="documents" id="documents">
 		<div class="loading">Loading documents...</div>
@@ -103,14 +225,40 @@ var indexTemplate = `<!DOCTYPE html>
 	<script>
 		async function loadStats() {
 			try {
-				const res = await fetch('/api/documents');
-				const docs = await res.json();
-				document.getElementById('stat-count').textContent = docs.length;
+				const res = await fetch('/api/stats');
+				const stats = await res.json();
+				document.getElementById('stat-count').textContent = stats.documents;
+				document.getElementById('stat-collections').textContent = stats.collections;
+				document.getElementById('stat-completion').textContent = Math.round(stats.completion_rate * 100) + '%';
+				document.getElementById('stat-overdue').textContent = stats.flashcards_overdue;
+				renderBars('chart-added', stats.added_per_month);
+				renderBars('chart-reading', stats.reading_per_week);
+				renderBars('chart-due', stats.flashcards_due);
+				renderTags('chart-tags', stats.top_tags);
 			} catch (e) {
 				console.error('Failed to load stats:', e);
 			}
 		}
 
+		function renderBars(id, series) {
+			const max = Math.max(1, ...series.map(function(p) { return p.count; }));
+			document.getElementById(id).innerHTML = series.map(function(p) {
+				return '<div class="bar" title="' + escapeHtml(p.key + ': ' + p.count) + '" style="height:' + (100 * p.count / max) + '%"></div>';
+			}).join('');
+		}
+
+		function renderTags(id, tags) {
+			if (tags.length === 0) {
+				document.getElementById(id).innerHTML = '<div class="stat-label">No tags yet</div>';
+				return;
+			}
+			const max = Math.max(1, ...tags.map(function(t) { return t.count; }));
+			document.getElementById(id).innerHTML = tags.map(function(t) {
+				return '<div class="hbar-row"><span class="hbar-label">' + escapeHtml(t.key) + '</span>' +
+					'<span class="hbar" style="width:' + (120 * t.count / max) + 'px"></span>' + t.count + '</div>';
+			}).join('');
+		}
+
 		async function loadDocuments(query = '') {
 			const container = document.getElementById('documents');
 			container.innerHTML = '<div class="loading">Loading...</div>';
@@ -127,6 +275,7 @@ var indexTemplate = `<!DOCTYPE html>
 				
 				container.innerHTML = docs.map(function(doc) {
 					var html = '<div class="doc">';
+					html += '<a href="/document/' + doc.id + '"><img class="doc-cover" loading="lazy" alt="" src="/api/document/' + doc.id + '/thumbnail" onerror="this.style.visibility=\'hidden\'"></a>';
 					html += '<div class="doc-title"><a href="/document/' + doc.id + '">' + escapeHtml(doc.title || 'Untitled') + '</a></div>';
 					html += '<div class="doc-meta">' + doc.type + ' · ' + doc.source;
 					if (doc.source_id) html += ': ' + doc.source_id;
@@ -161,10 +310,21 @@ var indexTemplate = `<!DOCTYPE html>
 			return div.innerHTML;
 		}
 		
+		function setView(view) {
+			document.getElementById('documents').classList.toggle('grid', view === 'grid');
+			document.getElementById('view-toggle').textContent = view === 'grid' ? 'List' : 'Grid';
+			localStorage.setItem('arc-library-view', view);
+		}
+
+		document.getElementById('view-toggle').addEventListener('click', function() {
+			setView(document.getElementById('documents').classList.contains('grid') ? 'list' : 'grid');
+		});
+
 		document.getElementById('search').addEventListener('input', function(e) {
 			loadDocuments(e.target.value);
 		});
 		
+		setView(localStorage.getItem('arc-library-view') || 'list');
 		loadStats();
 		loadDocuments();
 	</script>
@@ -185,9 +345,15 @@ func handleIndex(store library.LibraryStore) http.HandlerFunc {
 
 func handleAPIDocuments(store library.LibraryStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		docs, err := store.ListDocuments(&library.ListOptions{Limit: 100})
+		// Archived documents are listed on request, but always searched
+		opts := &library.ListOptions{Limit: 100, HideArchived: r.URL.Query().Get("archived") == ""}
+		if err := listOptionsFromQuery(store, r.URL.Query(), opts); err != nil {
+			apiError(w, err, http.StatusBadRequest)
+			return
+		}
+		docs, err := store.ListDocuments(opts)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			apiError(w, err, http.StatusInternalServerError)
 			return
 		}
 
@@ -196,6 +362,154 @@ func handleAPIDocuments(store library.LibraryStore) http.HandlerFunc {
 	}
 }
 
+// maxClipSize bounds a clip request body; selections are text, not pages.
+const maxClipSize = 1 << 20
+
+// handleAPIClip saves a page pushed by a bookmarklet or extension. Requests
+// come from other origins, so it answers CORS preflights, and every POST
+// must carry the clip token, or an API token with the admin scope, as
+// "Authorization: Bearer <token>" or ?token=.
+func handleAPIClip(store library.LibraryStore, token string, auth *apiAuth) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		h := w.Header()
+		h.Set("Access-Control-Allow-Origin", "*")
+		h.Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+		h.Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+		if r.Method == http.MethodOptions {
+			// Chrome asks before public pages may reach a local server
+			h.Set("Access-Control-Allow-Private-Network", "true")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if r.Method != http.MethodPost {
+			h.Set("Allow", "POST, OPTIONS")
+			apiError(w, errors.New("method not allowed"), http.StatusMethodNotAllowed)
+			return
+		}
+
+		got := r.URL.Query().Get("token")
+		if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+			got = strings.TrimPrefix(auth, "Bearer ")
+		}
+		actor := "clip"
+		if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			if !auth.allows(got, library.ScopeAdmin) {
+				apiError(w, errors.New("invalid or missing token"), http.StatusUnauthorized)
+				return
+			}
+			actor = requestActor(store, r)
+		}
+
+		var clip library.Clip
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxClipSize)).Decode(&clip); err != nil {
+			apiError(w, fmt.Errorf("invalid request: %w", err), http.StatusBadRequest)
+			return
+		}
+		result, err := library.SaveClip(library.WithActor(store, actor), &clip)
+		if err != nil {
+			apiError(w, err, http.StatusBadRequest)
+			return
+		}
+
+		h.Set("Content-Type", "application/json")
+		if result.Created {
+			w.WriteHeader(http.StatusCreated)
+		}
+		json.NewEncoder(w).Encode(result)
+	}
+}
+
+// maxBatchSize bounds a batch request body.
+const maxBatchSize = 4 << 20
+
+// handleAPIBatch applies a JSON array of operations (see
+// library.BatchOperation, and ApplyBatch for how far it is all or
+// nothing), so a multi-select change is one request.
+func handleAPIBatch(store library.LibraryStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			apiError(w, errors.New("method not allowed"), http.StatusMethodNotAllowed)
+			return
+		}
+		if !checkWriteRequest(w, r) {
+			return
+		}
+		var ops []library.BatchOperation
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBatchSize)).Decode(&ops); err != nil {
+			apiError(w, fmt.Errorf("invalid request: expected an array of operations: %w", err), http.StatusBadRequest)
+			return
+		}
+		if len(ops) == 0 {
+			apiError(w, errors.New("no operations"), http.StatusBadRequest)
+			return
+		}
+		result, err := library.ApplyBatch(library.WithActor(store, requestActor(store, r)), ops)
+		var batchErr *library.BatchError
+		if errors.As(err, &batchErr) {
+			apiError(w, err, http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			apiError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	}
+}
+
+// handleGraphQL answers GraphQL queries (see library.ExecuteGraphQL),
+// POSTed as JSON or sent as ?query=, and serves the schema to a GET
+// without one.
+func handleGraphQL(store library.LibraryStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req library.GraphQLRequest
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			q := r.URL.Query()
+			req.Query, req.OperationName = q.Get("query"), q.Get("operationName")
+			if req.Query == "" {
+				w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+				w.Write([]byte(library.GraphQLSchema()))
+				return
+			}
+			if v := q.Get("variables"); v != "" {
+				if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+					http.Error(w, "invalid variables: "+err.Error(), http.StatusBadRequest)
+					return
+				}
+			}
+		case http.MethodPost:
+			if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxClipSize)).Decode(&req); err != nil {
+				http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(library.ExecuteGraphQL(store, &req))
+	}
+}
+
+func handleAPIStats(store library.LibraryStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		stats, err := library.ComputeStats(store, library.Now())
+		if err != nil {
+			apiError(w, err, http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(stats)
+	}
+}
+
 func handleAPISearch(store library.LibraryStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		q := r.URL.Query().Get("q")
@@ -208,27 +522,94 @@ func handleAPISearch(store library.LibraryStore) http.HandlerFunc {
 			Search: q,
 			Limit:  50,
 		}
+		if err := listOptionsFromQuery(store, r.URL.Query(), opts); err != nil {
+			apiError(w, err, http.StatusBadRequest)
+			return
+		}
+		section := r.URL.Query().Get("section")
+		if section != "" {
+			opts.Limit = 0
+		}
 		docs, err := store.ListDocuments(opts)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			apiError(w, err, http.StatusInternalServerError)
 			return
 		}
+		if section != "" {
+			if docs, err = filterBySection(store, docs, section, opts.Search, 50); err != nil {
+				apiError(w, err, http.StatusInternalServerError)
+				return
+			}
+		}
 
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(docs)
 	}
 }
 
+// listOptionsFromQuery reads document filters from API query parameters:
+// tag, source, type, status, rating, author, language, created_after,
+// created_before, read_after, read_before and where (repeatable). Parameters mirror the CLI
+// filter flags.
+func listOptionsFromQuery(store library.LibraryStore, q url.Values, opts *library.ListOptions) error {
+	f := documentFilters{
+		status:        q.Get("status"),
+		author:        q.Get("author"),
+		language:      q.Get("language"),
+		createdAfter:  q.Get("created_after"),
+		createdBefore: q.Get("created_before"),
+		readAfter:     q.Get("read_after"),
+		readBefore:    q.Get("read_before"),
+		where:         q["where"],
+	}
+	if v := q.Get("rating"); v != "" {
+		rating, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid rating %q", v)
+		}
+		f.rating = rating
+	}
+	opts.Tag = q.Get("tag")
+	opts.Source = q.Get("source")
+	opts.Type = q.Get("type")
+	return f.apply(store, opts)
+}
+
 func handleAPIDocument(store library.LibraryStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		id := strings.TrimPrefix(r.URL.Path, "/api/document/")
+		if id, ok := strings.CutSuffix(id, "/thumbnail"); ok {
+			serveThumbnail(store, id, w, r)
+			return
+		}
+		if id, ok := strings.CutSuffix(id, "/sections"); ok {
+			serveSections(store, id, w, r)
+			return
+		}
+		if id, ok := strings.CutSuffix(id, "/annotations"); ok {
+			serveAnnotations(store, id, w, r)
+			return
+		}
+		if id, ok := strings.CutSuffix(id, "/file"); ok {
+			serveFile(store, id, w, r)
+			return
+		}
+		if id, ok := strings.CutSuffix(id, "/text"); ok {
+			serveFullText(store, id, w, r)
+			return
+		}
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET")
+			apiError(w, errors.New("method not allowed"), http.StatusMethodNotAllowed)
+			return
+		}
 		doc, err := store.GetDocument(id)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			apiError(w, err, http.StatusInternalServerError)
 			return
 		}
 		if doc == nil {
-			http.NotFound(w, r)
+			apiError(w, fmt.Errorf("%w: %s", library.ErrDocumentNotFound, id), http.StatusNotFound)
 			return
 		}
 
@@ -237,6 +618,267 @@ func handleAPIDocument(store library.LibraryStore) http.HandlerFunc {
 	}
 }
 
+// serveThumbnail serves a document's cover image, generating it into the
+// thumbnail cache on first request.
+func serveThumbnail(store library.LibraryStore, id string, w http.ResponseWriter, r *http.Request) {
+	doc, err := store.GetDocument(id)
+	if err != nil {
+		apiError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if doc == nil {
+		apiError(w, fmt.Errorf("%w: %s", library.ErrDocumentNotFound, id), http.StatusNotFound)
+		return
+	}
+	dir, err := library.ThumbnailDir()
+	if err != nil {
+		apiError(w, err, http.StatusInternalServerError)
+		return
+	}
+	path, err := library.Thumbnail(doc, dir, false)
+	if err != nil {
+		// Missing covers are routine; the page falls back to text only
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/jpeg")
+	w.Header().Set("Cache-Control", "max-age=86400")
+	http.ServeFile(w, r, path)
+}
+
+// serveFile serves a document's original file, with ranges for PDF
+// viewers; ?download=1 asks the browser to save it.
+func serveFile(store library.LibraryStore, id string, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET")
+		apiError(w, errors.New("method not allowed"), http.StatusMethodNotAllowed)
+		return
+	}
+	doc, err := store.GetDocument(id)
+	if err != nil {
+		apiError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if doc == nil {
+		apiError(w, fmt.Errorf("%w: %s", library.ErrDocumentNotFound, id), http.StatusNotFound)
+		return
+	}
+	if documentFileName(doc) == "" {
+		http.NotFound(w, r)
+		return
+	}
+	serveDocumentFile(w, r, doc, r.URL.Query().Get("download") != "")
+}
+
+// documentFileName is the name of a document's file, or "" when it has
+// none on disk (a directory, a URL, or a missing file).
+func documentFileName(doc *library.Document) string {
+	if doc.Path == "" {
+		return ""
+	}
+	path := library.DocumentPath(doc)
+	if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+		return ""
+	}
+	return filepath.Base(path)
+}
+
+// serveDocumentFile serves a document's file inline, or as an attachment.
+// http.ServeContent sets the type from the extension and answers Range and
+// conditional requests. Types that can run scripts are sandboxed away from
+// the library; PDFs are not, as browsers won't show them sandboxed.
+func serveDocumentFile(w http.ResponseWriter, r *http.Request, doc *library.Document, attachment bool) {
+	f, err := os.Open(library.DocumentPath(doc))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		apiError(w, err, http.StatusInternalServerError)
+		return
+	}
+	name := filepath.Base(f.Name())
+	disposition := "inline"
+	if attachment {
+		disposition = "attachment"
+	}
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": name}))
+	switch typ, _, _ := strings.Cut(mime.TypeByExtension(filepath.Ext(name)), ";"); typ {
+	case "text/html", "application/xhtml+xml", "image/svg+xml", "text/xml", "application/xml":
+		w.Header().Set("Content-Security-Policy", "sandbox")
+	}
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, name, info.ModTime(), f)
+}
+
+// serveFullText serves a document's stored full text as plain text;
+// ?download=1 saves it as <title>.txt.
+func serveFullText(store library.LibraryStore, id string, w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET")
+		apiError(w, errors.New("method not allowed"), http.StatusMethodNotAllowed)
+		return
+	}
+	doc, err := store.GetDocument(id)
+	if err != nil {
+		apiError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if doc == nil {
+		apiError(w, fmt.Errorf("%w: %s", library.ErrDocumentNotFound, id), http.StatusNotFound)
+		return
+	}
+	if doc.FullText == "" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.URL.Query().Get("download") != "" {
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": doc.Title + ".txt"}))
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	http.ServeContent(w, r, "", doc.UpdatedAt, strings.NewReader(doc.FullText))
+}
+
+// serveSections serves the sections of a document's full text with their
+// byte offsets, for jumping to a section.
+func serveSections(store library.LibraryStore, id string, w http.ResponseWriter, r *http.Request) {
+	doc, err := store.GetDocument(id)
+	if err != nil {
+		apiError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if doc == nil {
+		apiError(w, fmt.Errorf("%w: %s", library.ErrDocumentNotFound, id), http.StatusNotFound)
+		return
+	}
+	sections := []library.Section{}
+	if doc.FullText != "" {
+		secs, err := library.LoadDocumentSections(store, doc)
+		if err != nil {
+			apiError(w, err, http.StatusInternalServerError)
+			return
+		}
+		sections = secs.Sections
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sections)
+}
+
+// serveAnnotations lists a document's annotations, or adds one posted as
+// {"type", "content", "page", "color", "position", "parent_id"}. A
+// pdf.js selection posted as "selection" becomes the position.
+func serveAnnotations(store library.LibraryStore, id string, w http.ResponseWriter, r *http.Request) {
+	doc, err := store.GetDocument(id)
+	if err != nil {
+		apiError(w, err, http.StatusInternalServerError)
+		return
+	}
+	if doc == nil {
+		apiError(w, fmt.Errorf("%w: %s", library.ErrDocumentNotFound, id), http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		anns, err := store.GetAnnotations(doc.ID)
+		if err != nil {
+			apiError(w, err, http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(nonNil(anns))
+	case http.MethodPost:
+		if !checkWriteRequest(w, r) {
+			return
+		}
+		var req struct {
+			library.Annotation
+			Selection *library.PDFJSSelection `json:"selection"`
+		}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxClipSize)).Decode(&req); err != nil {
+			apiError(w, fmt.Errorf("invalid request: %w", err), http.StatusBadRequest)
+			return
+		}
+		ann := req.Annotation
+		if req.Selection != nil {
+			pos, err := library.PositionFromPDFJS(*req.Selection)
+			if err != nil {
+				apiError(w, fmt.Errorf("invalid selection: %w", err), http.StatusBadRequest)
+				return
+			}
+			ann.Position = pos.String()
+			if ann.Content == "" && pos.Quote != nil {
+				ann.Content = pos.Quote.Exact
+			}
+		}
+		if _, err := library.ParseAnnotationPosition(ann.Position); err != nil {
+			apiError(w, err, http.StatusBadRequest)
+			return
+		}
+		if ann.Type == "" {
+			ann.Type = "note"
+		}
+		if ann.Type != "highlight" && ann.Type != "note" && ann.Type != "bookmark" {
+			apiError(w, errors.New("type must be highlight, note, or bookmark"), http.StatusBadRequest)
+			return
+		}
+		ann.ID, ann.DocumentID, ann.CreatedAt = "", doc.ID, time.Time{}
+		if err := library.LinkReply(store, &ann); err != nil {
+			apiError(w, err, http.StatusBadRequest)
+			return
+		}
+		// Linking to an open reading session is best-effort, as in "annotate add"
+		_ = library.LinkOpenSession(store, &ann)
+		if err := library.WithActor(store, requestActor(store, r)).AddAnnotation(&ann); err != nil {
+			apiError(w, err, http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(ann)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		apiError(w, errors.New("method not allowed"), http.StatusMethodNotAllowed)
+	}
+}
+
+// pageSection is a section of the full text as rendered on the document
+// page.
+type pageSection struct {
+	Anchor string
+	Label  string
+	Text   string
+}
+
+// documentPageSections splits a document's full text into its sections
+// for the document page, or nil when there is at most one.
+func documentPageSections(store library.LibraryStore, doc *library.Document) []pageSection {
+	if doc.FullText == "" {
+		return nil
+	}
+	secs, err := library.LoadDocumentSections(store, doc)
+	if err != nil || len(secs.Sections) < 2 {
+		return nil
+	}
+	pages := make([]pageSection, len(secs.Sections))
+	for i, sec := range secs.Sections {
+		label := sec.Heading
+		if label == "" {
+			label = strings.ToUpper(sec.Kind[:1]) + sec.Kind[1:]
+		}
+		pages[i] = pageSection{
+			Anchor: fmt.Sprintf("section-%d", i+1),
+			Label:  label,
+			Text:   doc.FullText[sec.Start:sec.End],
+		}
+	}
+	return pages
+}
+
 func handleDocumentPage(store library.LibraryStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		id := strings.TrimPrefix(r.URL.Path, "/document/")
@@ -264,10 +906,27 @@ func handleDocumentPage(store library.LibraryStore) http.HandlerFunc {
 		.back { margin-bottom: 20px; }
 		.meta { color: #666; margin-bottom: 20px; }
 		.authors { font-style: italic; margin-bottom: 20px; }
+		.files { margin: 20px 0; }
 		.abstract { background: #f8f9fa; padding: 20px; border-radius: 8px; margin: 20px 0; }
 		.fulltext { white-space: pre-wrap; font-family: Georgia, serif; line-height: 1.8; color: #444; }
 		.tags { margin: 20px 0; }
 		.tag { display: inline-block; background: #e3f2fd; color: #1976d2; padding: 4px 12px; border-radius: 12px; font-size: 14px; margin-right: 8px; }
+		.tasks { background: #fff8e1; padding: 12px 20px; border-radius: 8px; margin: 20px 0; }
+		.tasks h2 { font-size: 14px; color: #666; text-transform: uppercase; margin-bottom: 6px; }
+		.tasks li { margin-left: 20px; }
+		.due { color: #999; font-size: 13px; }
+		.toc { position: sticky; top: 0; background: #fff; border-bottom: 1px solid #eee; padding: 8px 0; margin: 20px 0; font-size: 14px; }
+		.toc a { margin-right: 12px; white-space: nowrap; }
+		.section { scroll-margin-top: 48px; }
+		.notes { white-space: pre-wrap; margin: 20px 0; }
+		.backlinks { border-top: 1px solid #eee; margin-top: 30px; padding-top: 12px; }
+		.backlinks h2 { font-size: 14px; color: #666; text-transform: uppercase; margin-bottom: 6px; }
+		.backlinks li { margin-left: 20px; }
+		.annotations { margin: 20px 0; }
+		.annotations h2 { font-size: 14px; color: #666; text-transform: uppercase; margin-bottom: 6px; }
+		.annotation { border-left: 3px solid #ddd; padding: 4px 12px; margin: 8px 0; }
+		.annotation .replies { margin-left: 16px; }
+		.annotation .when { color: #999; font-size: 13px; }
 	</style>
 </head>
 <body>
@@ -282,19 +941,417 @@ func handleDocumentPage(store library.LibraryStore) http.HandlerFunc {
 		{{range .Tags}}<span class="tag">{{.}}</span>{{end}}
 	</div>
 	{{end}}
+	{{if .OpenTasks}}
+	<div class="tasks">
+		<h2>Open tasks ({{len .OpenTasks}})</h2>
+		<ul>
+		{{range .OpenTasks}}<li>{{.Description}}{{if .DueAt}} <span class="due">due {{homeDate .DueAt}}</span>{{end}}</li>{{end}}
+		</ul>
+	</div>
+	{{end}}
+	{{if or .FileName .FullText}}
+	<div class="files">
+		{{if .FileName}}<a href="/api/document/{{.ID}}/file">Open {{.FileName}}</a> · <a href="/api/document/{{.ID}}/file?download=1">Download</a>{{end}}
+		{{if and .FileName .FullText}} · {{end}}
+		{{if .FullText}}<a href="/api/document/{{.ID}}/text">Full text</a>{{end}}
+	</div>
+	{{end}}
 	{{if .Abstract}}
 	<div class="abstract">{{.Abstract}}</div>
 	{{end}}
-	{{if .FullText}}
+	{{if and .Notes (eq .Type "note")}}
+	<div class="notes">{{.Notes}}</div>
+	{{end}}
+	{{if .Threads}}
+	<div class="annotations">
+		<h2>Annotations</h2>
+		{{template "threads" .Threads}}
+	</div>
+	{{end}}
+	{{if .Sections}}
+	<nav class="toc">{{range .Sections}}<a href="#{{.Anchor}}">{{.Label}}</a>{{end}}</nav>
+	<div class="fulltext">{{range .Sections}}<div class="section" id="{{.Anchor}}">{{.Text}}</div>{{end}}</div>
+	{{else if .FullText}}
 	<div class="fulltext">{{.FullText}}</div>
 	{{end}}
+	{{if .ReferencedBy}}
+	<div class="backlinks">
+		<h2>Referenced by ({{len .ReferencedBy}})</h2>
+		<ul>
+		{{range .ReferencedBy}}<li><a href="/document/{{.DocumentID}}">{{.Title}}</a></li>{{end}}
+		</ul>
+	</div>
+	{{end}}
 </body>
-</html>`
+</html>
+{{define "threads"}}{{range .}}
+<div class="annotation"{{if .Color}} style="border-left-color: {{.Color}}"{{end}}>
+	<div>{{.Content}}</div>
+	<div class="when">{{.Type}}{{if .Page}} · page {{.Page}}{{end}} · {{.CreatedAt.Format "2006-01-02"}}</div>
+	{{if .Replies}}<div class="replies">{{template "threads" .Replies}}</div>{{end}}
+</div>
+{{end}}{{end}}`
 
 		funcs := template.FuncMap{
-			"join": strings.Join,
+			"join":     strings.Join,
+			"homeDate": func(t *time.Time) string { return library.HomeDate(*t) },
+		}
+		// Annotations are best-effort too, shown as threads of replies
+		var threads []*library.AnnotationNode
+		if anns, err := store.GetAnnotations(doc.ID); err == nil {
+			threads = library.BuildAnnotationThreads(anns)
 		}
+		// Backlinks are best-effort; without them the page just lacks the list
+		var referencedBy []docLinkView
+		if links, err := documentLinkViews(store, doc.ID); err == nil {
+			for _, l := range links {
+				if l.Relation == library.LinkReferences.Inverse() {
+					referencedBy = append(referencedBy, l)
+				}
+			}
+		}
+
 		t := template.Must(template.New("doc").Funcs(funcs).Parse(tmpl))
-		t.Execute(w, doc)
+		t.Execute(w, struct {
+			*library.Document
+			OpenTasks    []*library.Task
+			Sections     []pageSection
+			FileName     string
+			ReferencedBy []docLinkView
+			Threads      []*library.AnnotationNode
+		}{doc, openDocumentTasks(store, doc.ID), documentPageSections(store, doc), documentFileName(doc), referencedBy, threads})
+	}
+}
+
+var shareTemplate = template.Must(template.New("share").Funcs(template.FuncMap{"join": strings.Join}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+	<title>{{.Title}}</title>
+	<meta charset="UTF-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1.0">
+	<meta name="robots" content="noindex">
+	<style>
+		* { box-sizing: border-box; margin: 0; padding: 0; }
+		body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif; line-height: 1.6; color: #333; max-width: 900px; margin: 0 auto; padding: 20px; }
+		a { color: #3498db; }
+		h1 { margin: 20px 0; color: #2c3e50; }
+		.meta { color: #666; margin-bottom: 20px; }
+		.authors { font-style: italic; margin-bottom: 20px; }
+		.abstract { background: #f8f9fa; padding: 20px; border-radius: 8px; margin: 20px 0; }
+		.file { margin: 20px 0; }
+		.expires { color: #999; font-size: 13px; margin-top: 40px; }
+	</style>
+</head>
+<body>
+	<h1>{{.Title}}</h1>
+	<div class="meta">{{.Type}}{{if .Year}} · {{.Year}}{{end}}{{if .DOI}} · doi:{{.DOI}}{{end}}{{if .URL}} · <a href="{{.URL}}">{{.URL}}</a>{{end}}</div>
+	{{if .Authors}}
+	<div class="authors">{{join .Authors ", "}}</div>
+	{{end}}
+	{{if .Abstract}}
+	<div class="abstract">{{.Abstract}}</div>
+	{{end}}
+	{{if .FileURL}}
+	<div class="file"><a href="{{.FileURL}}">Download {{.FileName}}</a></div>
+	{{end}}
+	<div class="expires">Shared from Arc Library · link expires {{.ExpiresAt.Format "2006-01-02 15:04 MST"}}</div>
+</body>
+</html>`))
+
+// handleSharePage serves a shared document's page at /share/<token> and,
+// if the share includes it, the document's file at /share/<token>/file.
+// Unknown and expired tokens get a plain 404, revealing nothing.
+func handleSharePage(store library.LibraryStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.URL.Path, "/share/")
+		token, wantFile := strings.CutSuffix(token, "/file")
+		share, err := library.GetValidShare(store, token, time.Now())
+		if err != nil {
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		var doc *library.Document
+		if share != nil {
+			if doc, err = store.GetDocument(share.DocumentID); err != nil {
+				http.Error(w, "internal error", http.StatusInternalServerError)
+				return
+			}
+		}
+		if doc == nil {
+			http.NotFound(w, r)
+			return
+		}
+		// The token is in the URL; keep it out of Referer headers and indexes
+		w.Header().Set("Referrer-Policy", "no-referrer")
+		w.Header().Set("X-Robots-Tag", "noindex")
+
+		fileName := ""
+		if share.IncludeFile {
+			fileName = documentFileName(doc)
+		}
+		if wantFile {
+			if fileName == "" {
+				http.NotFound(w, r)
+				return
+			}
+			serveDocumentFile(w, r, doc, false)
+			return
+		}
+
+		page := struct {
+			*library.Document
+			Year      any
+			DOI       string
+			URL       string
+			FileURL   string
+			FileName  string
+			ExpiresAt time.Time
+		}{Document: doc, Year: doc.Meta["year"], DOI: metaDoi(doc), FileName: fileName, ExpiresAt: share.ExpiresAt}
+		if u, _ := doc.Meta["url"].(string); strings.HasPrefix(u, "http://") || strings.HasPrefix(u, "https://") {
+			page.URL = u
+		}
+		if fileName != "" {
+			page.FileURL = "/share/" + token + "/file"
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		shareTemplate.Execute(w, page)
+	}
+}
+
+var readingListTemplate = template.Must(template.New("list").Funcs(template.FuncMap{"join": strings.Join}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+	<title>{{.Collection.Name}}</title>
+	<meta charset="UTF-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1.0">
+	<link rel="alternate" type="application/atom+xml" title="{{.Collection.Name}}" href="{{.FeedURL}}">
+	<style>
+		* { box-sizing: border-box; margin: 0; padding: 0; }
+		body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif; line-height: 1.6; color: #333; max-width: 900px; margin: 0 auto; padding: 20px; }
+		a { color: #3498db; }
+		h1 { margin: 20px 0 10px; color: #2c3e50; }
+		.description { color: #666; margin-bottom: 10px; }
+		.feed { font-size: 14px; margin-bottom: 30px; }
+		.item { border-top: 1px solid #eee; padding: 15px 0; }
+		.item h2 { font-size: 18px; color: #2c3e50; }
+		.meta { color: #666; font-size: 14px; }
+		.authors { font-style: italic; }
+		.abstract { margin-top: 8px; font-size: 14px; }
+		.empty { color: #999; }
+	</style>
+</head>
+<body>
+	<h1>{{.Collection.Name}}</h1>
+	{{if .Collection.Description}}<div class="description">{{.Collection.Description}}</div>{{end}}
+	<div class="feed"><a href="{{.FeedURL}}">Subscribe (Atom feed)</a> · {{len .Items}} document(s)</div>
+	{{range .Items}}
+	<div class="item" id="{{.Document.ID}}">
+		<h2>{{if .URL}}<a href="{{.URL}}">{{.Document.Title}}</a>{{else}}{{.Document.Title}}{{end}}</h2>
+		{{if .Document.Authors}}<div class="authors">{{join .Document.Authors ", "}}</div>{{end}}
+		<div class="meta">{{.Document.Type}} · added {{.AddedAt.Format "2006-01-02"}}</div>
+		{{if .Document.Abstract}}<div class="abstract">{{.Document.Abstract}}</div>{{end}}
+	</div>
+	{{else}}
+	<div class="empty">Nothing on this list yet.</div>
+	{{end}}
+</body>
+</html>`))
+
+// publicCollection returns the collection named in the request path after
+// prefix, without suffix, if the request may see it: published ones are
+// public, others need read access. Otherwise it writes a 404, revealing
+// nothing, and returns nil.
+func publicCollection(store library.LibraryStore, auth *apiAuth, prefix, suffix string, w http.ResponseWriter, r *http.Request) *library.Collection {
+	name, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, prefix), suffix)
+	if !ok || name == "" {
+		http.NotFound(w, r)
+		return nil
+	}
+	c, err := store.GetCollection(name)
+	if err != nil {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return nil
+	}
+	if c == nil || !(c.Public || auth.permits(r, library.ScopeRead)) {
+		http.NotFound(w, r)
+		return nil
+	}
+	return c
+}
+
+// requestBaseURL is the scheme and host a request reached the server at.
+func requestBaseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// handleCollectionFeed serves a collection's latest documents as an Atom
+// feed at /feed/collection/<name>.xml.
+func handleCollectionFeed(store library.LibraryStore, auth *apiAuth) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		c := publicCollection(store, auth, "/feed/collection/", ".xml", w, r)
+		if c == nil {
+			return
+		}
+		items, err := library.CollectionReadingList(store, c, library.FeedLimit)
+		if err != nil {
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		base := requestBaseURL(r)
+		self := base + "/feed/collection/" + url.PathEscape(c.Name) + ".xml"
+		page := base + "/list/" + url.PathEscape(c.Name)
+		w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+		library.WriteCollectionAtom(w, c, items, self, page)
+	}
+}
+
+// handleReadingList serves a collection's reading list page at
+// /list/<name>.
+func handleReadingList(store library.LibraryStore, auth *apiAuth) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		c := publicCollection(store, auth, "/list/", "", w, r)
+		if c == nil {
+			return
+		}
+		items, err := library.CollectionReadingList(store, c, 0)
+		if err != nil {
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		readingListTemplate.Execute(w, struct {
+			Collection *library.Collection
+			Items      []library.ReadingListItem
+			FeedURL    string
+		}{c, items, "/feed/collection/" + url.PathEscape(c.Name) + ".xml"})
+	}
+}
+
+// apiTokenCookie carries an API token given as ?token=, so a browser keeps
+// sending it to the dashboard's API calls.
+const apiTokenCookie = "arc_library_token"
+
+// apiAuth enforces API token scopes on the server's routes.
+type apiAuth struct {
+	store    library.LibraryStore
+	required bool // without it every request is allowed, as before tokens existed
+}
+
+// requestToken is the token a request carries as a bearer token, ?token=,
+// or cookie.
+func requestToken(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimPrefix(auth, "Bearer ")
+	}
+	if t := r.URL.Query().Get("token"); t != "" {
+		return t
+	}
+	if c, err := r.Cookie(apiTokenCookie); err == nil {
+		return c.Value
+	}
+	return ""
+}
+
+// allows reports whether secret is an API token with the scope.
+func (a *apiAuth) allows(secret string, scope library.TokenScope) bool {
+	t, err := library.AuthenticateAPIToken(a.store, secret)
+	return err == nil && t != nil && t.Scope.Allows(scope)
+}
+
+// permits reports whether a request may do what needs the scope: always
+// when tokens aren't required, never without the library (a nil apiAuth).
+func (a *apiAuth) permits(r *http.Request, scope library.TokenScope) bool {
+	if a == nil {
+		return false
+	}
+	return !a.required || a.allows(requestToken(r), scope)
+}
+
+// require wraps h so that, when tokens are required, it only answers
+// requests carrying a token with the scope: 401 without a valid token and
+// 403 with one of too small a scope.
+func (a *apiAuth) require(scope library.TokenScope, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !a.required {
+			h(w, r)
+			return
+		}
+		secret := requestToken(r)
+		t, err := library.AuthenticateAPIToken(a.store, secret)
+		if err != nil {
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		if t == nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="arc-library"`)
+			http.Error(w, "invalid or missing API token", http.StatusUnauthorized)
+			return
+		}
+		if !t.Scope.Allows(scope) {
+			http.Error(w, fmt.Sprintf("token %q has scope %s; this needs %s", t.Name, t.Scope, scope), http.StatusForbidden)
+			return
+		}
+		if r.URL.Query().Get("token") == secret {
+			http.SetCookie(w, &http.Cookie{Name: apiTokenCookie, Value: secret, Path: "/", HttpOnly: true, SameSite: http.SameSiteStrictMode})
+		}
+		h(w, r)
+	}
+}
+
+// requireByMethod requires the read scope to read and annotate to write.
+func (a *apiAuth) requireByMethod(h http.HandlerFunc) http.HandlerFunc {
+	read, write := a.require(library.ScopeRead, h), a.require(library.ScopeAnnotate, h)
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			read(w, r)
+		} else {
+			write(w, r)
+		}
+	}
+}
+
+// requestActor names who makes a request's changes in the audit log: its
+// API token, or just "web" without one.
+func requestActor(store library.LibraryStore, r *http.Request) string {
+	if t, err := library.AuthenticateAPIToken(store, requestToken(r)); err == nil && t != nil {
+		return "token:" + t.Name
+	}
+	return "web"
+}
+
+// checkWriteRequest refuses a request that changes the library unless it
+// is JSON from the server's own origin, or from no browser origin at all.
+// A page elsewhere can make a browser send a form or text/plain POST with
+// its cookies, but not one with a JSON content type, nor one whose Origin
+// is this server, so the request can't be forged even when tokens aren't
+// required. It answers the refusal itself and reports whether to go on.
+func checkWriteRequest(w http.ResponseWriter, r *http.Request) bool {
+	if mt, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mt != "application/json" {
+		apiError(w, errors.New("Content-Type must be application/json"), http.StatusUnsupportedMediaType)
+		return false
+	}
+	if origin := r.Header.Get("Origin"); origin != "" {
+		u, err := url.Parse(origin)
+		if err != nil || !strings.EqualFold(u.Host, r.Host) {
+			apiError(w, fmt.Errorf("cross-origin request from %s refused", origin), http.StatusForbidden)
+			return false
+		}
+	} else if site := r.Header.Get("Sec-Fetch-Site"); site != "" && site != "same-origin" && site != "none" {
+		apiError(w, fmt.Errorf("%s request refused", site), http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// isLoopback reports whether bind only accepts local connections.
+func isLoopback(bind string) bool {
+	if bind == "localhost" {
+		return true
 	}
+	ip := net.ParseIP(bind)
+	return ip != nil && ip.IsLoopback()
 }
@@ -0,0 +1,259 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mtreilly/arc-library/internal/scheduler"
+)
+
+// GeneratedCard is a flashcard as a model proposes it, before it is
+// checked and stored.
+type GeneratedCard struct {
+	Type  string `json:"type"` // basic or cloze
+	Front string `json:"front,omitempty"`
+	Back  string `json:"back,omitempty"`
+	Cloze string `json:"cloze,omitempty"`
+}
+
+// RejectedCard is a generated card that failed validation.
+type RejectedCard struct {
+	Card   GeneratedCard `json:"card"`
+	Reason string        `json:"reason"`
+}
+
+// SkippedCard is a generated card left out as a near-duplicate of a card
+// already in the library, or of one generated before it.
+type SkippedCard struct {
+	Card        GeneratedCard `json:"card"`
+	Similar     string        `json:"similar"`                // the question it duplicates
+	FlashcardID string        `json:"flashcard_id,omitempty"` // the card it duplicates, if stored
+	Similarity  float64       `json:"similarity"`
+}
+
+// DefaultCardSimilarity is the word overlap from which DedupeGeneratedCards
+// takes two questions for the same.
+const DefaultCardSimilarity = 0.7
+
+// clozePattern matches an Anki cloze deletion, {{c1::answer}} or
+// {{c1::answer::hint}}.
+var clozePattern = regexp.MustCompile(`\{\{c\d+::[^}]+\}\}`)
+
+// FlashcardPrompt asks for count flashcards as a JSON array, of basic
+// question and answer cards or, with cloze, of cloze deletions.
+func FlashcardPrompt(count int, cloze bool) string {
+	if cloze {
+		return fmt.Sprintf(`Generate %d cloze deletion flashcards from this document.
+
+Reply with only a JSON array and nothing else, in this form:
+[{"type": "cloze", "cloze": "The Transformer relies entirely on {{c1::self-attention}}.", "back": "optional extra context"}]
+
+Each "cloze" is one sentence with one to three deletions written {{c1::text}}, {{c2::text}}.
+Focus on key concepts, definitions, and findings.`, count)
+	}
+	return fmt.Sprintf(`Generate %d flashcards from this document.
+
+Reply with only a JSON array and nothing else, in this form:
+[{"type": "basic", "front": "question", "back": "answer"}]
+
+Make the cards concise and focused on key concepts, definitions, and findings.`, count)
+}
+
+// Validate reports what is wrong with the card, if anything: a basic card
+// needs a question and an answer, a cloze card text with a deletion.
+func (c GeneratedCard) Validate() error {
+	switch c.Type {
+	case "basic":
+		if c.Front == "" || c.Back == "" {
+			return errors.New("basic card needs a front and a back")
+		}
+	case "cloze":
+		if !clozePattern.MatchString(c.Cloze) {
+			return errors.New("cloze card has no {{c1::...}} deletion")
+		}
+	default:
+		return fmt.Errorf("unknown card type %q", c.Type)
+	}
+	return nil
+}
+
+// Flashcard returns the card ready to store, due tomorrow like the cards
+// "flashcard add" makes.
+func (c GeneratedCard) Flashcard(docID string, tags []string) *Flashcard {
+	card := &Flashcard{
+		DocumentID: docID,
+		Type:       c.Type,
+		Front:      c.Front,
+		Back:       c.Back,
+		Cloze:      c.Cloze,
+		Tags:       tags,
+		DueAt:      DueDate(Now(), 1),
+		Ease:       scheduler.Current().InitialEase,
+	}
+	if c.Type == "cloze" && card.Front == "" {
+		card.Front = clozePattern.ReplaceAllString(c.Cloze, "[...]")
+	}
+	return card
+}
+
+// ParseGeneratedCards reads the cards out of a model's reply: the JSON
+// array asked for by FlashcardPrompt, also when wrapped in a code fence,
+// prose, or an object with a "cards" field. Replies in the older
+// "Q: ... A: ..." form are read too. Cards without a type take the
+// requested one. Cards failing Validate are returned as rejected.
+func ParseGeneratedCards(reply string, cloze bool) ([]GeneratedCard, []RejectedCard, error) {
+	defaultType := "basic"
+	if cloze {
+		defaultType = "cloze"
+	}
+
+	raw, err := generatedJSON(reply)
+	if err != nil {
+		raw = questionAnswerCards(reply)
+		if len(raw) == 0 {
+			return nil, nil, err
+		}
+	}
+
+	var cards []GeneratedCard
+	var rejected []RejectedCard
+	for _, c := range raw {
+		c.Type = strings.ToLower(strings.TrimSpace(c.Type))
+		if c.Type == "" {
+			c.Type = defaultType
+		}
+		c.Front, c.Back, c.Cloze = strings.TrimSpace(c.Front), strings.TrimSpace(c.Back), strings.TrimSpace(c.Cloze)
+		// A cloze card's text sometimes comes back as its front
+		if c.Type == "cloze" && c.Cloze == "" && clozePattern.MatchString(c.Front) {
+			c.Cloze, c.Front = c.Front, ""
+		}
+		if err := c.Validate(); err != nil {
+			rejected = append(rejected, RejectedCard{Card: c, Reason: err.Error()})
+			continue
+		}
+		cards = append(cards, c)
+	}
+	return cards, rejected, nil
+}
+
+// generatedJSON decodes the first JSON array or object in reply.
+func generatedJSON(reply string) ([]GeneratedCard, error) {
+	start := strings.IndexAny(reply, "[{")
+	if start < 0 {
+		return nil, errors.New("reply has no JSON")
+	}
+	dec := json.NewDecoder(strings.NewReader(reply[start:]))
+	var v json.RawMessage
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("reply is not valid JSON: %w", err)
+	}
+	var cards []GeneratedCard
+	if v[0] == '[' {
+		err := json.Unmarshal(v, &cards)
+		return cards, err
+	}
+	var wrapped struct {
+		Cards []GeneratedCard `json:"cards"`
+	}
+	if err := json.Unmarshal(v, &wrapped); err != nil {
+		return nil, err
+	}
+	if wrapped.Cards == nil {
+		return nil, errors.New(`reply JSON has no "cards"`)
+	}
+	return wrapped.Cards, nil
+}
+
+// questionAnswerCards reads "Q: ..." lines each followed by "A: ...".
+func questionAnswerCards(reply string) []GeneratedCard {
+	var cards []GeneratedCard
+	var q string
+	for _, line := range strings.Split(reply, "\n") {
+		line = strings.TrimSpace(line)
+		if rest, ok := strings.CutPrefix(line, "Q:"); ok {
+			q = strings.TrimSpace(rest)
+		} else if rest, ok := strings.CutPrefix(line, "A:"); ok && q != "" {
+			cards = append(cards, GeneratedCard{Type: "basic", Front: q, Back: strings.TrimSpace(rest)})
+			q = ""
+		}
+	}
+	return cards
+}
+
+// DedupeGeneratedCards leaves out the generated cards whose question
+// shares at least threshold of its words (Jaccard similarity, ignoring
+// case, punctuation, and cloze markup) with an existing card's or an
+// earlier generated card's, so generating again for a document doesn't
+// pile up copies. A threshold of 0 keeps every card.
+func DedupeGeneratedCards(cards []GeneratedCard, existing []*Flashcard, threshold float64) ([]GeneratedCard, []SkippedCard) {
+	if threshold <= 0 {
+		return cards, nil
+	}
+	type seenCard struct {
+		id, question string
+		words        []uint64
+	}
+	var seen []seenCard
+	for _, c := range existing {
+		q := c.Front
+		if c.Type == "cloze" && c.Cloze != "" {
+			q = c.Cloze
+		}
+		seen = append(seen, seenCard{c.ID, q, Shingles(clozeText(q), 1)})
+	}
+
+	var keep []GeneratedCard
+	var skipped []SkippedCard
+	for _, c := range cards {
+		q := c.Front
+		if c.Type == "cloze" {
+			q = c.Cloze
+		}
+		words := Shingles(clozeText(q), 1)
+		best, bestSim := -1, 0.0
+		for i, s := range seen {
+			if sim := jaccard(words, s.words); sim >= threshold && sim > bestSim {
+				best, bestSim = i, sim
+			}
+		}
+		if best >= 0 {
+			skipped = append(skipped, SkippedCard{Card: c, Similar: seen[best].question, FlashcardID: seen[best].id, Similarity: bestSim})
+			continue
+		}
+		keep = append(keep, c)
+		seen = append(seen, seenCard{"", q, words})
+	}
+	return keep, skipped
+}
+
+// clozeAnswerPattern captures the answer of a cloze deletion.
+var clozeAnswerPattern = regexp.MustCompile(`\{\{c\d+::([^}:]+)(?:::[^}]*)?\}\}`)
+
+// clozeText is text with its cloze deletions replaced by their answers.
+func clozeText(text string) string {
+	return clozeAnswerPattern.ReplaceAllString(text, "$1")
+}
+
+// jaccard is the Jaccard similarity of two sets of distinct hashes.
+func jaccard(a, b []uint64) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	in := make(map[uint64]bool, len(a))
+	for _, h := range a {
+		in[h] = true
+	}
+	shared := 0
+	for _, h := range b {
+		if in[h] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
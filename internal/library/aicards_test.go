@@ -0,0 +1,91 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseGeneratedCards(t *testing.T) {
+	reply := "Sure! Here are your flashcards:\n\n```json\n" + `[
+  {"type": "basic", "front": "What does attention replace?", "back": "Recurrence"},
+  {"front": "Missing answer"},
+  {"type": "cloze", "cloze": "Transformers use {{c1::multi-head}} attention."}
+]` + "\n```\nLet me know if you want more."
+
+	cards, rejected, err := ParseGeneratedCards(reply, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cards) != 2 || cards[0].Back != "Recurrence" || cards[1].Type != "cloze" {
+		t.Errorf("cards = %+v", cards)
+	}
+	if len(rejected) != 1 || rejected[0].Card.Type != "basic" || !strings.Contains(rejected[0].Reason, "back") {
+		t.Errorf("rejected = %+v", rejected)
+	}
+
+	card := cards[1].Flashcard("doc1", []string{"ai"})
+	if card.Front != "Transformers use [...] attention." || card.DocumentID != "doc1" || card.Ease == 0 {
+		t.Errorf("flashcard = %+v", card)
+	}
+}
+
+func TestParseGeneratedCardsCloze(t *testing.T) {
+	cards, rejected, err := ParseGeneratedCards(`{"cards": [
+		{"front": "Paris is the capital of {{c1::France}}."},
+		{"cloze": "No deletion here."}
+	]}`, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cards) != 1 || cards[0].Cloze != "Paris is the capital of {{c1::France}}." || cards[0].Front != "" {
+		t.Errorf("cards = %+v", cards)
+	}
+	if len(rejected) != 1 {
+		t.Errorf("rejected = %+v", rejected)
+	}
+}
+
+func TestParseGeneratedCardsQuestionAnswer(t *testing.T) {
+	cards, _, err := ParseGeneratedCards("Q: What is SM-2?\nA: A spaced repetition algorithm\n\nQ: Dangling", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cards) != 1 || cards[0].Type != "basic" || cards[0].Front != "What is SM-2?" {
+		t.Errorf("cards = %+v", cards)
+	}
+
+	if _, _, err := ParseGeneratedCards("I cannot help with that.", false); err == nil {
+		t.Error("reply without cards: want error")
+	}
+}
+
+func TestDedupeGeneratedCards(t *testing.T) {
+	existing := []*Flashcard{
+		{ID: "f1", Type: "basic", Front: "What does the Transformer replace recurrence with?", Back: "Attention"},
+		{ID: "f2", Type: "cloze", Front: "[...] is the capital of France", Cloze: "{{c1::Paris}} is the capital of France"},
+	}
+	cards := []GeneratedCard{
+		{Type: "basic", Front: "What does the Transformer replace recurrence with?", Back: "Self-attention"},
+		{Type: "cloze", Cloze: "{{c1::Paris}} is the capital of {{c2::France}}."},
+		{Type: "basic", Front: "How many layers does the encoder have?", Back: "6"},
+		{Type: "basic", Front: "How many layers does the encoder have", Back: "Six"},
+	}
+
+	keep, skipped := DedupeGeneratedCards(cards, existing, DefaultCardSimilarity)
+	if len(keep) != 1 || keep[0].Back != "6" {
+		t.Errorf("keep = %+v", keep)
+	}
+	if len(skipped) != 3 || skipped[0].FlashcardID != "f1" || skipped[1].FlashcardID != "f2" || skipped[2].FlashcardID != "" {
+		t.Errorf("skipped = %+v", skipped)
+	}
+	if skipped[0].Similarity != 1 {
+		t.Errorf("identical question similarity = %g", skipped[0].Similarity)
+	}
+
+	if keep, skipped := DedupeGeneratedCards(cards, existing, 0); len(keep) != 4 || skipped != nil {
+		t.Errorf("threshold 0: %d kept, %d skipped", len(keep), len(skipped))
+	}
+}
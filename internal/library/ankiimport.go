@@ -0,0 +1,332 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"archive/zip"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mtreilly/arc-library/internal/scheduler"
+)
+
+// AnkiQuality maps an Anki answer button (1 Again, 2 Hard, 3 Good,
+// 4 Easy) to the SM-2 quality score a review here would get: a lapse
+// fails, and the passing buttons rate the recall from hesitant to
+// effortless. Anything else, such as the 0 of a manual reschedule, is not
+// a review and maps to -1.
+func AnkiQuality(ease int) int {
+	switch ease {
+	case 1:
+		return 1
+	case 2:
+		return 3
+	case 3:
+		return 4
+	case 4:
+		return 5
+	}
+	return -1
+}
+
+// AnkiImportOptions control ImportAnki.
+type AnkiImportOptions struct {
+	DocumentID string   // document the cards belong to, if any
+	Tags       []string // added to every card
+	Now        time.Time
+}
+
+// AnkiImportResult is what ImportAnki did.
+type AnkiImportResult struct {
+	Cards   int `json:"cards"`
+	Reviews int `json:"reviews"`
+	Skipped int `json:"skipped"` // cards already in the library, by front and back
+}
+
+// ankiCard is a row of an Anki collection's cards table with its note.
+type ankiCard struct {
+	id, typ, due, ivl, factor int64
+	fields                    []string
+	tags                      string
+	cloze                     bool
+}
+
+// ankiReview is a row of an Anki collection's revlog.
+type ankiReview struct {
+	id, cardID, ease, lastIvl, factor, typ int64
+}
+
+// ImportAnki adds the cards of an Anki .apkg package as flashcards,
+// carrying over their interval, ease, and due date, and each card's
+// review history so statistics continue where Anki left off. Answer
+// buttons become quality scores as AnkiQuality maps them. Cards whose
+// front and back match a card already in the library are skipped, so a
+// package can be imported again after adding cards to it. Packages in
+// the compressed format of recent Anki versions (collection.anki21b) must
+// be exported with "support older Anki versions" ticked.
+func ImportAnki(s LibraryStore, path string, opts AnkiImportOptions) (*AnkiImportResult, error) {
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	tmpDir, err := os.MkdirTemp("", "anki-import-*")
+	if err != nil {
+		return nil, fmt.Errorf("create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	dbPath, err := extractAnkiCollection(path, tmpDir)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	crt, clozeModels, err := readAnkiCollection(db)
+	if err != nil {
+		return nil, err
+	}
+	cards, err := readAnkiCards(db, clozeModels)
+	if err != nil {
+		return nil, err
+	}
+	revlog, err := readAnkiRevlog(db)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.ListFlashcards(&FlashcardListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	for _, c := range existing {
+		seen[c.Front+"\x1f"+c.Back+"\x1f"+c.Cloze] = true
+	}
+
+	initialEase := scheduler.Current().InitialEase
+	res := &AnkiImportResult{}
+	var (
+		added        []*Flashcard
+		addedReviews [][]*FlashcardReview // of each added card
+	)
+	for _, ac := range cards {
+		card := ac.flashcard(crt, opts)
+		key := card.Front + "\x1f" + card.Back + "\x1f" + card.Cloze
+		if card.Front == "" || seen[key] {
+			res.Skipped++
+			continue
+		}
+		seen[key] = true
+
+		prevEase := initialEase
+		var reviews []*FlashcardReview
+		for _, r := range revlog[ac.id] {
+			at := time.UnixMilli(r.id)
+			if q := AnkiQuality(int(r.ease)); q >= 0 && r.typ != 4 {
+				reviews = append(reviews, &FlashcardReview{
+					ID:           fmt.Sprintf("review:anki:%d", r.id),
+					Quality:      q,
+					ReviewedAt:   at,
+					PrevInterval: int(max(r.lastIvl, 0)),
+					PrevEase:     prevEase,
+					Params:       "anki",
+				})
+				if at.After(card.LastReview) {
+					card.LastReview = at
+				}
+			}
+			if r.factor > 0 {
+				prevEase = float64(r.factor) / 1000
+			}
+		}
+
+		added = append(added, card)
+		addedReviews = append(addedReviews, reviews)
+	}
+
+	if err := s.AddFlashcards(added); err != nil {
+		return res, fmt.Errorf("add flashcards: %w", err)
+	}
+	res.Cards = len(added)
+	for i, card := range added {
+		for _, r := range addedReviews[i] {
+			r.FlashcardID = card.ID
+			if err := s.AddFlashcardReview(r); err != nil {
+				return res, fmt.Errorf("add review: %w", err)
+			}
+			res.Reviews++
+		}
+	}
+	return res, nil
+}
+
+// flashcard converts the card, scheduled as Anki had it: review cards
+// by their due day counted from the collection's creation crt, learning
+// cards by their due timestamp, and new cards due now.
+func (ac *ankiCard) flashcard(crt time.Time, opts AnkiImportOptions) *Flashcard {
+	card := &Flashcard{DocumentID: opts.DocumentID, Type: "basic", DueAt: opts.Now}
+	if len(ac.fields) > 0 {
+		card.Front = ankiFieldText(ac.fields[0])
+	}
+	if len(ac.fields) > 1 {
+		card.Back = ankiFieldText(ac.fields[1])
+	}
+	if ac.cloze {
+		card.Type = "cloze"
+		card.Cloze = card.Front
+	}
+	card.Tags = append(strings.Fields(ac.tags), opts.Tags...)
+
+	card.Ease = scheduler.Current().InitialEase
+	if ac.factor > 0 {
+		card.Ease = float64(ac.factor) / 1000
+	}
+	if ac.ivl > 0 {
+		card.Interval = int(ac.ivl)
+	}
+	switch ac.typ {
+	case 2: // review
+		card.DueAt = crt.AddDate(0, 0, int(ac.due))
+	case 1, 3: // learning, relearning
+		card.DueAt = time.Unix(ac.due, 0)
+	}
+	return card
+}
+
+// extractAnkiCollection writes the collection database of the package at
+// path into dir and returns its path.
+func extractAnkiCollection(path, dir string) (string, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return "", fmt.Errorf("open anki package: %w", err)
+	}
+	defer zr.Close()
+
+	var found *zip.File
+	for _, f := range zr.File {
+		switch f.Name {
+		case "collection.anki21":
+			found = f
+		case "collection.anki2":
+			if found == nil {
+				found = f
+			}
+		}
+	}
+	if found == nil {
+		for _, f := range zr.File {
+			if f.Name == "collection.anki21b" {
+				return "", fmt.Errorf("%s uses the compressed Anki format; export it with \"support older Anki versions\"", path)
+			}
+		}
+		return "", fmt.Errorf("%s: no Anki collection in package", path)
+	}
+
+	rc, err := found.Open()
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+	dbPath := filepath.Join(dir, "collection.anki2")
+	out, err := os.Create(dbPath)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(out, rc); err != nil {
+		out.Close()
+		return "", err
+	}
+	return dbPath, out.Close()
+}
+
+// readAnkiCollection returns when the collection was created, from which
+// review due days count, and the IDs of its cloze note types.
+func readAnkiCollection(db *sql.DB) (time.Time, map[int64]bool, error) {
+	var crt int64
+	var models string
+	if err := db.QueryRow(`SELECT crt, models FROM col LIMIT 1`).Scan(&crt, &models); err != nil {
+		return time.Time{}, nil, fmt.Errorf("read anki collection: %w", err)
+	}
+	var parsed map[string]struct {
+		ID   int64 `json:"id"`
+		Type int   `json:"type"` // 1 for cloze
+	}
+	if err := json.Unmarshal([]byte(models), &parsed); err != nil {
+		return time.Time{}, nil, fmt.Errorf("read anki note types: %w", err)
+	}
+	cloze := map[int64]bool{}
+	for _, m := range parsed {
+		if m.Type == 1 {
+			cloze[m.ID] = true
+		}
+	}
+	return time.Unix(crt, 0), cloze, nil
+}
+
+func readAnkiCards(db *sql.DB, clozeModels map[int64]bool) ([]*ankiCard, error) {
+	rows, err := db.Query(`
+		SELECT c.id, c.type, c.due, c.ivl, c.factor, n.mid, n.flds, n.tags
+		FROM cards c JOIN notes n ON n.id = c.nid ORDER BY c.id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("read anki cards: %w", err)
+	}
+	defer rows.Close()
+
+	var cards []*ankiCard
+	for rows.Next() {
+		var c ankiCard
+		var mid int64
+		var flds string
+		if err := rows.Scan(&c.id, &c.typ, &c.due, &c.ivl, &c.factor, &mid, &flds, &c.tags); err != nil {
+			return nil, fmt.Errorf("read anki cards: %w", err)
+		}
+		c.fields = strings.Split(flds, "\x1f")
+		c.cloze = clozeModels[mid]
+		cards = append(cards, &c)
+	}
+	return cards, rows.Err()
+}
+
+// readAnkiRevlog returns each card's reviews, oldest first.
+func readAnkiRevlog(db *sql.DB) (map[int64][]ankiReview, error) {
+	rows, err := db.Query(`SELECT id, cid, ease, lastIvl, factor, type FROM revlog ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("read anki revlog: %w", err)
+	}
+	defer rows.Close()
+
+	revlog := map[int64][]ankiReview{}
+	for rows.Next() {
+		var r ankiReview
+		if err := rows.Scan(&r.id, &r.cardID, &r.ease, &r.lastIvl, &r.factor, &r.typ); err != nil {
+			return nil, fmt.Errorf("read anki revlog: %w", err)
+		}
+		revlog[r.cardID] = append(revlog[r.cardID], r)
+	}
+	return revlog, rows.Err()
+}
+
+var (
+	ankiBreakPattern = regexp.MustCompile(`(?i)<br\s*/?>|</div>|</p>`)
+	ankiTagPattern   = regexp.MustCompile(`<[^>]*>`)
+)
+
+// ankiFieldText turns the HTML of an Anki field into plain text.
+func ankiFieldText(field string) string {
+	text := ankiBreakPattern.ReplaceAllString(field, "\n")
+	text = html.UnescapeString(ankiTagPattern.ReplaceAllString(text, ""))
+	return strings.TrimSpace(strings.ReplaceAll(text, "\u00a0", " "))
+}
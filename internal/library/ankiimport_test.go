@@ -0,0 +1,155 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"archive/zip"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+func TestAnkiQuality(t *testing.T) {
+	for ease, want := range map[int]int{0: -1, 1: 1, 2: 3, 3: 4, 4: 5, 5: -1} {
+		if got := AnkiQuality(ease); got != want {
+			t.Errorf("AnkiQuality(%d) = %d, want %d", ease, got, want)
+		}
+	}
+}
+
+// writeAnkiPackage builds a package with the tables ImportAnki reads, in
+// the layout of Anki's schema: a basic note type and a cloze one, a review
+// card with a history, a new card, and a cloze card.
+func writeAnkiPackage(t *testing.T, crt time.Time) string {
+	t.Helper()
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "collection.anki21")
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := 24 * time.Hour
+	stmts := []struct {
+		query string
+		args  []any
+	}{
+		{`CREATE TABLE col (id INTEGER PRIMARY KEY, crt INTEGER NOT NULL, models TEXT NOT NULL)`, nil},
+		{`CREATE TABLE notes (id INTEGER PRIMARY KEY, mid INTEGER NOT NULL, tags TEXT NOT NULL, flds TEXT NOT NULL)`, nil},
+		{`CREATE TABLE cards (id INTEGER PRIMARY KEY, nid INTEGER NOT NULL, type INTEGER NOT NULL, due INTEGER NOT NULL,
+			ivl INTEGER NOT NULL, factor INTEGER NOT NULL)`, nil},
+		{`CREATE TABLE revlog (id INTEGER PRIMARY KEY, cid INTEGER NOT NULL, ease INTEGER NOT NULL, ivl INTEGER NOT NULL,
+			lastIvl INTEGER NOT NULL, factor INTEGER NOT NULL, type INTEGER NOT NULL)`, nil},
+		{`INSERT INTO col VALUES (1, ?, '{"10": {"id": 10, "type": 0}, "20": {"id": 20, "type": 1}}')`, []any{crt.Unix()}},
+		{`INSERT INTO notes VALUES (100, 10, ' geo ', '<b>Capital</b> of France?' || char(31) || 'Paris&nbsp;<br>(city)')`, nil},
+		{`INSERT INTO notes VALUES (200, 10, '', 'Largest planet?' || char(31) || 'Jupiter')`, nil},
+		{`INSERT INTO notes VALUES (300, 20, '', 'The capital of Italy is {{c1::Rome}}' || char(31) || '')`, nil},
+		{`INSERT INTO cards VALUES (1000, 100, 2, 30, 10, 2360)`, nil},
+		{`INSERT INTO cards VALUES (2000, 200, 0, 1, 0, 0)`, nil},
+		{`INSERT INTO cards VALUES (3000, 300, 0, 2, 0, 0)`, nil},
+		{`INSERT INTO revlog VALUES (?, 1000, 3, 1, 0, 2500, 0)`, []any{crt.Add(day).UnixMilli()}},
+		{`INSERT INTO revlog VALUES (?, 1000, 1, 1, 1, 2300, 1)`, []any{crt.Add(5 * day).UnixMilli()}},
+		{`INSERT INTO revlog VALUES (?, 1000, 0, 10, 1, 2360, 4)`, []any{crt.Add(6 * day).UnixMilli()}},
+	}
+	for _, st := range stmts {
+		if _, err := db.Exec(st.query, st.args...); err != nil {
+			t.Fatalf("%s: %v", st.query, err)
+		}
+	}
+	db.Close()
+
+	pkg := filepath.Join(dir, "deck.apkg")
+	f, err := os.Create(pkg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	if err := NewAnkiExporter("Test").addFileToZip(zw, dbPath, "collection.anki21"); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	return pkg
+}
+
+func TestImportAnki(t *testing.T) {
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	crt := time.Date(2026, 1, 1, 4, 0, 0, 0, time.UTC)
+	pkg := writeAnkiPackage(t, crt)
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	res, err := ImportAnki(s, pkg, AnkiImportOptions{Tags: []string{"anki"}, Now: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Cards != 3 || res.Reviews != 2 || res.Skipped != 0 {
+		t.Fatalf("result = %+v, want 3 cards, 2 reviews", res)
+	}
+
+	cards, err := s.ListFlashcards(&FlashcardListOptions{Tag: "anki"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reviewed, fresh, cloze *Flashcard
+	for _, c := range cards {
+		switch c.Front {
+		case "Capital of France?":
+			reviewed = c
+		case "Largest planet?":
+			fresh = c
+		default:
+			cloze = c
+		}
+	}
+	if reviewed == nil || fresh == nil || cloze == nil {
+		t.Fatalf("cards = %+v", cards)
+	}
+	if len(reviewed.Tags) != 2 || reviewed.Tags[0] != "geo" || reviewed.Tags[1] != "anki" {
+		t.Errorf("tags = %q", reviewed.Tags)
+	}
+	if cloze.Type != "cloze" || cloze.Cloze != "The capital of Italy is {{c1::Rome}}" {
+		t.Errorf("cloze card = %+v", cloze)
+	}
+	if reviewed.Back != "Paris \n(city)" {
+		t.Errorf("back = %q", reviewed.Back)
+	}
+	if reviewed.Interval != 10 || reviewed.Ease != 2.36 || !reviewed.DueAt.Equal(crt.AddDate(0, 0, 30)) {
+		t.Errorf("schedule = %d days, ease %g, due %v", reviewed.Interval, reviewed.Ease, reviewed.DueAt)
+	}
+	if !reviewed.LastReview.Equal(crt.Add(5 * 24 * time.Hour)) {
+		t.Errorf("last review = %v", reviewed.LastReview)
+	}
+	if !fresh.DueAt.Equal(now) || !fresh.LastReview.IsZero() {
+		t.Errorf("new card due %v, last review %v", fresh.DueAt, fresh.LastReview)
+	}
+
+	reviews, err := s.ListFlashcardReviews(reviewed.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Newest first; the manual reschedule is not a review
+	if len(reviews) != 2 || reviews[0].Quality != 1 || reviews[1].Quality != 4 {
+		t.Fatalf("reviews = %+v", reviews)
+	}
+	if reviews[0].PrevEase != 2.5 || reviews[0].PrevInterval != 1 || reviews[1].PrevEase != 2.5 {
+		t.Errorf("reviews = %+v %+v", reviews[0], reviews[1])
+	}
+
+	// Importing again skips the cards already there
+	res, err = ImportAnki(s, pkg, AnkiImportOptions{Now: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Cards != 0 || res.Skipped != 3 {
+		t.Errorf("reimport = %+v", res)
+	}
+}
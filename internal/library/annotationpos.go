@@ -0,0 +1,231 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// AnnotationPosition is the structure of an annotation's Position: where
+// on a PDF page it sits, what text it quotes, and where that text is in
+// the full text. It follows the W3C Web Annotation model's selectors, so
+// an annotation can be found again when one of them no longer matches:
+// Rects for the FragmentSelector of a PDF viewer, Quote for the
+// TextQuoteSelector, and Offset and Length for the TextPositionSelector
+// (the TextSpan "read" records). Every field is optional.
+type AnnotationPosition struct {
+	Page   int        `json:"page,omitempty"`  // 1-based
+	Rects  []PDFRect  `json:"rects,omitempty"` // in PDF user space
+	Quote  *TextQuote `json:"quote,omitempty"`
+	Offset int        `json:"offset,omitempty"` // into the full text
+	Length int        `json:"length,omitempty"`
+}
+
+// PDFRect is a rectangle on a PDF page in PDF user space: points from
+// the bottom-left corner of the unrotated page, X and Y being the
+// rectangle's bottom-left corner.
+type PDFRect struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// TextQuote is the text an annotation covers, with a little of the text
+// before and after it to tell repeated passages apart.
+type TextQuote struct {
+	Exact  string `json:"exact"`
+	Prefix string `json:"prefix,omitempty"`
+	Suffix string `json:"suffix,omitempty"`
+}
+
+// ParseAnnotationPosition reads an annotation's Position. An empty
+// position is the zero value. Fields other than AnnotationPosition's are
+// ignored, so positions written by older versions and other tools still
+// parse.
+func ParseAnnotationPosition(position string) (AnnotationPosition, error) {
+	var pos AnnotationPosition
+	trimmed := strings.TrimSpace(position)
+	if trimmed == "" {
+		return pos, nil
+	}
+	if !strings.HasPrefix(trimmed, "{") {
+		return pos, errors.New("position must be a JSON object")
+	}
+	if err := json.Unmarshal([]byte(trimmed), &pos); err != nil {
+		return pos, fmt.Errorf("invalid position: %w", err)
+	}
+	return pos, pos.Validate()
+}
+
+// Validate reports what is wrong with the position, if anything.
+func (p AnnotationPosition) Validate() error {
+	if p.Page < 0 {
+		return fmt.Errorf("position page %d is not positive", p.Page)
+	}
+	for i, r := range p.Rects {
+		for _, v := range []float64{r.X, r.Y, r.Width, r.Height} {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				return fmt.Errorf("position rect %d is not finite", i+1)
+			}
+		}
+		if r.Width < 0 || r.Height < 0 {
+			return fmt.Errorf("position rect %d has a negative size", i+1)
+		}
+	}
+	if p.Quote != nil && p.Quote.Exact == "" {
+		return errors.New("position quote has no exact text")
+	}
+	if p.Offset < 0 || p.Length < 0 {
+		return errors.New("position offset and length must not be negative")
+	}
+	return nil
+}
+
+// String returns the position as stored in an Annotation, or "" for the
+// zero value.
+func (p AnnotationPosition) String() string {
+	if p.Page == 0 && len(p.Rects) == 0 && p.Quote == nil && p.Offset == 0 && p.Length == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(p)
+	return string(data)
+}
+
+// validateAnnotation checks an annotation's position before it is stored,
+// and takes its page from the position when it has none.
+func validateAnnotation(ann *Annotation) error {
+	pos, err := ParseAnnotationPosition(ann.Position)
+	if err != nil {
+		return err
+	}
+	if ann.Page == 0 {
+		ann.Page = pos.Page
+	} else if pos.Page != 0 && pos.Page != ann.Page {
+		return fmt.Errorf("position is on page %d but the annotation on page %d", pos.Page, ann.Page)
+	}
+	return nil
+}
+
+// PDFJSSelection is a text selection in the pdf.js viewer, as the web
+// viewer posts it: the page's viewport when the selection was made and
+// the selection's client rects relative to the page element, in CSS
+// pixels.
+type PDFJSSelection struct {
+	Page       int            `json:"page"`       // pdf.js pageNumber, 1-based
+	Scale      float64        `json:"scale"`      // viewport.scale
+	Rotation   int            `json:"rotation"`   // viewport.rotation: 0, 90, 180, or 270
+	PageWidth  float64        `json:"page_width"` // unrotated page size in points, from viewBox
+	PageHeight float64        `json:"page_height"`
+	Rects      []ViewportRect `json:"rects"`
+	Text       string         `json:"text"`
+	Prefix     string         `json:"prefix,omitempty"`
+	Suffix     string         `json:"suffix,omitempty"`
+}
+
+// ViewportRect is a rectangle in a pdf.js viewport: CSS pixels from the
+// top-left corner of the rendered page, as getClientRects returns them
+// less the page element's offset.
+type ViewportRect struct {
+	Left   float64 `json:"left"`
+	Top    float64 `json:"top"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// PositionFromPDFJS converts a pdf.js selection to a position, undoing
+// the viewport's scale and rotation the way viewport.convertToPdfPoint
+// does, so the rectangles stay put when the page is shown at another
+// zoom or rotation.
+func PositionFromPDFJS(sel PDFJSSelection) (AnnotationPosition, error) {
+	if sel.Page < 1 {
+		return AnnotationPosition{}, errors.New("selection has no page")
+	}
+	if sel.Scale <= 0 {
+		return AnnotationPosition{}, errors.New("selection scale must be positive")
+	}
+	if sel.PageWidth <= 0 || sel.PageHeight <= 0 {
+		return AnnotationPosition{}, errors.New("selection has no page size")
+	}
+	rotation := ((sel.Rotation % 360) + 360) % 360
+	if rotation%90 != 0 {
+		return AnnotationPosition{}, fmt.Errorf("unsupported rotation %d", sel.Rotation)
+	}
+
+	pos := AnnotationPosition{Page: sel.Page}
+	for _, r := range sel.Rects {
+		if r.Width <= 0 || r.Height <= 0 {
+			continue // pdf.js reports empty rects at line ends
+		}
+		x1, y1 := pdfPoint(r.Left, r.Top, sel.Scale, rotation, sel.PageWidth, sel.PageHeight)
+		x2, y2 := pdfPoint(r.Left+r.Width, r.Top+r.Height, sel.Scale, rotation, sel.PageWidth, sel.PageHeight)
+		pos.Rects = append(pos.Rects, PDFRect{
+			X:      roundPoint(math.Min(x1, x2)),
+			Y:      roundPoint(math.Min(y1, y2)),
+			Width:  roundPoint(math.Abs(x2 - x1)),
+			Height: roundPoint(math.Abs(y2 - y1)),
+		})
+	}
+	if text := strings.TrimSpace(sel.Text); text != "" {
+		pos.Quote = &TextQuote{Exact: text, Prefix: sel.Prefix, Suffix: sel.Suffix}
+	}
+	return pos, pos.Validate()
+}
+
+// ViewportRects converts the position's rectangles to a pdf.js viewport
+// of the given scale and rotation for a page of the given unrotated size
+// in points, for the web viewer to draw the annotation over the page.
+func (p AnnotationPosition) ViewportRects(scale float64, rotation int, pageWidth, pageHeight float64) []ViewportRect {
+	rotation = ((rotation % 360) + 360) % 360
+	rects := make([]ViewportRect, 0, len(p.Rects))
+	for _, r := range p.Rects {
+		x1, y1 := viewportPoint(r.X, r.Y, scale, rotation, pageWidth, pageHeight)
+		x2, y2 := viewportPoint(r.X+r.Width, r.Y+r.Height, scale, rotation, pageWidth, pageHeight)
+		rects = append(rects, ViewportRect{
+			Left:   math.Min(x1, x2),
+			Top:    math.Min(y1, y2),
+			Width:  math.Abs(x2 - x1),
+			Height: math.Abs(y2 - y1),
+		})
+	}
+	return rects
+}
+
+// viewportPoint maps a point in PDF user space to a pdf.js viewport, as
+// the viewport's transform does for a viewBox at the origin.
+func viewportPoint(x, y, scale float64, rotation int, w, h float64) (float64, float64) {
+	switch rotation {
+	case 90:
+		return y * scale, x * scale
+	case 180:
+		return (w - x) * scale, y * scale
+	case 270:
+		return (h - y) * scale, (w - x) * scale
+	}
+	return x * scale, (h - y) * scale
+}
+
+// pdfPoint is the inverse of viewportPoint.
+func pdfPoint(vx, vy, scale float64, rotation int, w, h float64) (float64, float64) {
+	vx, vy = vx/scale, vy/scale
+	switch rotation {
+	case 90:
+		return vy, vx
+	case 180:
+		return w - vx, vy
+	case 270:
+		return w - vy, h - vx
+	}
+	return vx, h - vy
+}
+
+// roundPoint rounds to a hundredth of a point, well below what a viewer
+// can show, so stored positions don't carry float noise.
+func roundPoint(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"math"
+	"testing"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+func TestParseAnnotationPosition(t *testing.T) {
+	tests := []struct {
+		name     string
+		position string
+		wantErr  bool
+	}{
+		{"empty", "", false},
+		{"legacy coordinates", `{"x": 10, "y": 20}`, false},
+		{"text span", `{"offset": 120, "length": 40}`, false},
+		{"full", `{"page": 3, "rects": [{"x": 72, "y": 600, "width": 200, "height": 12}], "quote": {"exact": "attention", "prefix": "self-", "suffix": " is"}}`, false},
+		{"not an object", `[1, 2]`, true},
+		{"not json", `page 3`, true},
+		{"page as string", `{"page": "3"}`, true},
+		{"negative page", `{"page": -1}`, true},
+		{"negative width", `{"rects": [{"x": 1, "y": 1, "width": -5, "height": 1}]}`, true},
+		{"quote without exact", `{"quote": {"prefix": "a"}}`, true},
+		{"negative offset", `{"offset": -4}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseAnnotationPosition(tt.position)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseAnnotationPosition(%s) error = %v, want error %v", tt.position, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPositionFromPDFJSRoundTrip(t *testing.T) {
+	// A US Letter page with one highlighted line 1 inch from the left
+	// and 2 inches from the top, 3 inches wide and 14 points tall
+	const w, h = 612.0, 792.0
+	want := PDFRect{X: 72, Y: h - 144 - 14, Width: 216, Height: 14}
+
+	for _, rotation := range []int{0, 90, 180, 270} {
+		for _, scale := range []float64{1, 1.5} {
+			pos := AnnotationPosition{Page: 2, Rects: []PDFRect{want}}
+			view := pos.ViewportRects(scale, rotation, w, h)
+			got, err := PositionFromPDFJS(PDFJSSelection{
+				Page:       2,
+				Scale:      scale,
+				Rotation:   rotation,
+				PageWidth:  w,
+				PageHeight: h,
+				Rects:      append(view, ViewportRect{Left: 5, Top: 5}),
+				Text:       " multi-head attention ",
+				Prefix:     "uses ",
+			})
+			if err != nil {
+				t.Fatalf("rotation %d scale %v: %v", rotation, scale, err)
+			}
+			if len(got.Rects) != 1 {
+				t.Fatalf("rotation %d scale %v: got %d rects, want 1 (empty rects dropped)", rotation, scale, len(got.Rects))
+			}
+			r := got.Rects[0]
+			if math.Abs(r.X-want.X) > 0.01 || math.Abs(r.Y-want.Y) > 0.01 || math.Abs(r.Width-want.Width) > 0.01 || math.Abs(r.Height-want.Height) > 0.01 {
+				t.Errorf("rotation %d scale %v: got %+v, want %+v", rotation, scale, r, want)
+			}
+			if got.Quote == nil || got.Quote.Exact != "multi-head attention" || got.Quote.Prefix != "uses " {
+				t.Errorf("rotation %d scale %v: quote = %+v", rotation, scale, got.Quote)
+			}
+		}
+	}
+
+	// Unrotated, the viewport's top-left is the page's top-left
+	view := AnnotationPosition{Rects: []PDFRect{want}}.ViewportRects(1, 0, w, h)
+	if view[0].Left != 72 || view[0].Top != 144 {
+		t.Errorf("viewport rect = %+v, want left 72 top 144", view[0])
+	}
+
+	if _, err := PositionFromPDFJS(PDFJSSelection{Page: 1, Scale: 1, PageWidth: w, PageHeight: h, Rotation: 45}); err == nil {
+		t.Error("expected an error for a 45 degree rotation")
+	}
+	if _, err := PositionFromPDFJS(PDFJSSelection{Page: 1, Scale: 0, PageWidth: w, PageHeight: h}); err == nil {
+		t.Error("expected an error for a zero scale")
+	}
+}
+
+func TestAddAnnotationValidatesPosition(t *testing.T) {
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := &Document{Type: DocTypePaper, Title: "Paper"}
+	if err := s.AddDocument(doc); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.AddAnnotation(&Annotation{DocumentID: doc.ID, Type: "highlight", Position: `{"page": 0.5}`}); err == nil {
+		t.Error("expected an error for an invalid position")
+	}
+	if err := s.AddAnnotation(&Annotation{DocumentID: doc.ID, Type: "highlight", Page: 2, Position: `{"page": 3}`}); err == nil {
+		t.Error("expected an error for a position on another page")
+	}
+
+	pos := AnnotationPosition{Page: 4, Quote: &TextQuote{Exact: "scaled dot-product"}}
+	ann := &Annotation{DocumentID: doc.ID, Type: "highlight", Position: pos.String()}
+	if err := s.AddAnnotation(ann); err != nil {
+		t.Fatal(err)
+	}
+	if ann.Page != 4 {
+		t.Errorf("page = %d, want 4 from the position", ann.Page)
+	}
+	anns, err := s.GetAnnotations(doc.ID)
+	if err != nil || len(anns) != 1 {
+		t.Fatalf("GetAnnotations = %v, %v", anns, err)
+	}
+	got, err := ParseAnnotationPosition(anns[0].Position)
+	if err != nil || got.Quote == nil || got.Quote.Exact != "scaled dot-product" {
+		t.Errorf("stored position = %+v, %v", got, err)
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import "fmt"
+
+// AnnotationNode is an annotation with its replies, as "annotate list"
+// and the web document page show them.
+type AnnotationNode struct {
+	*Annotation
+	Replies []*AnnotationNode `json:"replies"`
+}
+
+// LinkReply checks that an annotation's ParentID names an annotation on
+// the same document, and gives a reply without a page its parent's page
+// so the thread stays together when annotations are sorted by page. An
+// annotation without a parent is left alone.
+func LinkReply(s LibraryStore, ann *Annotation) error {
+	if ann.ParentID == "" {
+		return nil
+	}
+	anns, err := s.GetAnnotations(ann.DocumentID)
+	if err != nil {
+		return err
+	}
+	for _, a := range anns {
+		if a.ID == ann.ParentID {
+			if ann.Page == 0 {
+				ann.Page = a.Page
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("annotation %s is not on document %s", ann.ParentID, ann.DocumentID)
+}
+
+// BuildAnnotationThreads arranges annotations into threads, keeping their
+// input order. Replies whose parent is not in the list, because it was
+// filtered out or deleted, start threads of their own.
+func BuildAnnotationThreads(anns []*Annotation) []*AnnotationNode {
+	nodes := make(map[string]*AnnotationNode, len(anns))
+	for _, a := range anns {
+		nodes[a.ID] = &AnnotationNode{Annotation: a, Replies: []*AnnotationNode{}}
+	}
+
+	roots := []*AnnotationNode{}
+	for _, a := range anns {
+		n := nodes[a.ID]
+		if parent, ok := nodes[a.ParentID]; ok && !inThread(n, parent) {
+			parent.Replies = append(parent.Replies, n)
+			continue
+		}
+		roots = append(roots, n)
+	}
+	return roots
+}
+
+// inThread reports whether target is n or one of its replies, which
+// guards against parent cycles in imported data.
+func inThread(n, target *AnnotationNode) bool {
+	if n == target {
+		return true
+	}
+	for _, r := range n.Replies {
+		if inThread(r, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// FlattenAnnotationThreads returns the threads' annotations depth first,
+// each with its depth in the thread: 0 for the annotation that starts it.
+func FlattenAnnotationThreads(threads []*AnnotationNode) ([]*Annotation, []int) {
+	var anns []*Annotation
+	var depths []int
+	var walk func(nodes []*AnnotationNode, depth int)
+	walk = func(nodes []*AnnotationNode, depth int) {
+		for _, n := range nodes {
+			anns = append(anns, n.Annotation)
+			depths = append(depths, depth)
+			walk(n.Replies, depth+1)
+		}
+	}
+	walk(threads, 0)
+	return anns, depths
+}
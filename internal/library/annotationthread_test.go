@@ -0,0 +1,78 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+func TestBuildAnnotationThreads(t *testing.T) {
+	anns := []*Annotation{
+		{ID: "a", Content: "Key claim"},
+		{ID: "b", Content: "Unclear", ParentID: "a"},
+		{ID: "c", Content: "Another highlight"},
+		{ID: "d", Content: "Resolved in 3.2", ParentID: "b"},
+		{ID: "e", Content: "Orphan", ParentID: "gone"},
+		{ID: "f", Content: "Also on the claim", ParentID: "a"},
+		// A cycle in imported data must not lose the annotations
+		{ID: "x", ParentID: "y"},
+		{ID: "y", ParentID: "x"},
+	}
+	got, depths := FlattenAnnotationThreads(BuildAnnotationThreads(anns))
+	var parts []string
+	for i, a := range got {
+		parts = append(parts, fmt.Sprintf("%s%d", a.ID, depths[i]))
+	}
+	if want := "a0 b1 d2 f1 c0 e0 y0 x1"; strings.Join(parts, " ") != want {
+		t.Errorf("threads = %s, want %s", strings.Join(parts, " "), want)
+	}
+}
+
+func TestLinkReply(t *testing.T) {
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := &Document{Type: DocTypePaper, Title: "Paper"}
+	other := &Document{Type: DocTypePaper, Title: "Other"}
+	for _, d := range []*Document{doc, other} {
+		if err := s.AddDocument(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	parent := &Annotation{DocumentID: doc.ID, Type: "highlight", Content: "Claim", Page: 7}
+	if err := s.AddAnnotation(parent); err != nil {
+		t.Fatal(err)
+	}
+
+	reply := &Annotation{DocumentID: doc.ID, Type: "note", Content: "Follow-up", ParentID: parent.ID}
+	if err := LinkReply(s, reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply.Page != 7 {
+		t.Errorf("reply page = %d, want the parent's 7", reply.Page)
+	}
+	if err := s.AddAnnotation(reply); err != nil {
+		t.Fatal(err)
+	}
+	anns, err := s.GetAnnotations(doc.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	threads := BuildAnnotationThreads(anns)
+	if len(threads) != 1 || len(threads[0].Replies) != 1 || threads[0].Replies[0].ID != reply.ID {
+		t.Errorf("stored reply not threaded under its parent: %+v", threads)
+	}
+
+	if err := LinkReply(s, &Annotation{DocumentID: other.ID, ParentID: parent.ID}); err == nil {
+		t.Error("expected an error for a parent on another document")
+	}
+	if err := LinkReply(s, &Annotation{DocumentID: doc.ID, ParentID: "missing"}); err == nil {
+		t.Error("expected an error for a missing parent")
+	}
+}
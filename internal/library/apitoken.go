@@ -0,0 +1,120 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TokenScope is what an API token may do on the web server. Each scope
+// includes the ones before it.
+type TokenScope string
+
+const (
+	ScopeRead     TokenScope = "read"     // browse documents, search, stats
+	ScopeAnnotate TokenScope = "annotate" // also add annotations
+	ScopeAdmin    TokenScope = "admin"    // also add documents through /api/clip
+)
+
+// TokenScopes lists the scopes from least to most access.
+var TokenScopes = []TokenScope{ScopeRead, ScopeAnnotate, ScopeAdmin}
+
+// apiTokenPrefix marks API token secrets, so they are recognizable in
+// configuration files and secret scanners.
+const apiTokenPrefix = "arcl_"
+
+// APIToken gives access to the web server's API at a scope. Only a hash
+// of the secret is stored; the secret itself is shown once, on creation.
+type APIToken struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Scope     TokenScope `json:"scope"`
+	Hash      string     `json:"-"` // hex SHA-256 of the secret
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// ParseTokenScope validates a scope name.
+func ParseTokenScope(s string) (TokenScope, error) {
+	for _, scope := range TokenScopes {
+		if string(scope) == strings.ToLower(strings.TrimSpace(s)) {
+			return scope, nil
+		}
+	}
+	return "", fmt.Errorf("invalid scope %q (use read, annotate, or admin)", s)
+}
+
+// Allows reports whether a token of this scope may do what required
+// needs.
+func (s TokenScope) Allows(required TokenScope) bool {
+	rank := func(t TokenScope) int {
+		for i, scope := range TokenScopes {
+			if scope == t {
+				return i
+			}
+		}
+		return -1
+	}
+	return rank(s) >= 0 && rank(s) >= rank(required)
+}
+
+// HashAPIToken is the stored form of a token secret.
+func HashAPIToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewAPIToken returns a token and its secret.
+func NewAPIToken(name string, scope TokenScope) (*APIToken, string, error) {
+	// The ID is listed and logged, so it shares no bytes with the secret
+	b := make([]byte, 4+24)
+	if _, err := rand.Read(b); err != nil {
+		return nil, "", fmt.Errorf("generate token: %w", err)
+	}
+	secret := apiTokenPrefix + hex.EncodeToString(b[4:])
+	return &APIToken{
+		ID:        hex.EncodeToString(b[:4]),
+		Name:      name,
+		Scope:     scope,
+		Hash:      HashAPIToken(secret),
+		CreatedAt: time.Now(),
+	}, secret, nil
+}
+
+// FindAPIToken returns the token with idOrName, or nil.
+func FindAPIToken(s LibraryStore, idOrName string) (*APIToken, error) {
+	tokens, err := s.ListAPITokens()
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tokens {
+		if t.ID == idOrName || t.Name == idOrName {
+			return t, nil
+		}
+	}
+	return nil, nil
+}
+
+// AuthenticateAPIToken returns the token whose secret is secret, or nil.
+func AuthenticateAPIToken(s LibraryStore, secret string) (*APIToken, error) {
+	if !strings.HasPrefix(secret, apiTokenPrefix) {
+		return nil, nil
+	}
+	tokens, err := s.ListAPITokens()
+	if err != nil {
+		return nil, err
+	}
+	hash := []byte(HashAPIToken(secret))
+	for _, t := range tokens {
+		if subtle.ConstantTimeCompare(hash, []byte(t.Hash)) == 1 {
+			return t, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+func TestTokenScopeAllows(t *testing.T) {
+	cases := []struct {
+		have, need TokenScope
+		want       bool
+	}{
+		{ScopeRead, ScopeRead, true},
+		{ScopeRead, ScopeAnnotate, false},
+		{ScopeAnnotate, ScopeRead, true},
+		{ScopeAnnotate, ScopeAdmin, false},
+		{ScopeAdmin, ScopeAnnotate, true},
+		{"", ScopeRead, false},
+	}
+	for _, c := range cases {
+		if got := c.have.Allows(c.need); got != c.want {
+			t.Errorf("%q.Allows(%q) = %v", c.have, c.need, got)
+		}
+	}
+	if _, err := ParseTokenScope("write"); err == nil {
+		t.Error("expected an error for an unknown scope")
+	}
+}
+
+func TestAPITokens(t *testing.T) {
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice, secret, err := NewAPIToken("alice", ScopeAnnotate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(secret, apiTokenPrefix) || alice.Hash == "" || strings.Contains(alice.Hash, secret) {
+		t.Fatalf("token %+v, secret %q", alice, secret)
+	}
+	if err := s.CreateAPIToken(alice); err != nil {
+		t.Fatal(err)
+	}
+	dup, _, _ := NewAPIToken("alice", ScopeRead)
+	if err := s.CreateAPIToken(dup); err == nil {
+		t.Error("expected an error for a duplicate name")
+	}
+
+	got, err := AuthenticateAPIToken(s, secret)
+	if err != nil || got == nil || got.Name != "alice" || got.Scope != ScopeAnnotate {
+		t.Fatalf("authenticate = %+v, %v", got, err)
+	}
+	for _, bad := range []string{"", secret + "x", strings.TrimPrefix(secret, apiTokenPrefix), alice.Hash} {
+		if got, _ := AuthenticateAPIToken(s, bad); got != nil {
+			t.Errorf("authenticated %q", bad)
+		}
+	}
+
+	if found, _ := FindAPIToken(s, "alice"); found == nil || found.ID != alice.ID {
+		t.Errorf("find by name = %+v", found)
+	}
+	if err := s.DeleteAPIToken(alice.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := AuthenticateAPIToken(s, secret); got != nil {
+		t.Error("a revoked token still authenticates")
+	}
+}
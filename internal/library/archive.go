@@ -0,0 +1,127 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ArchivePolicy archives completed documents nobody has come back to:
+// once a document is completed and has had no activity for Months
+// months, its status becomes archived. Archived documents drop out of
+// default listings but are still found by search.
+type ArchivePolicy struct {
+	Months int // 0 disables the policy
+}
+
+// ArchivePolicySetting reads the policy from $ARC_LIBRARY_ARCHIVE_AFTER, a
+// number of months such as 6 or 6mo. Unset or 0 leaves documents alone.
+func ArchivePolicySetting() (ArchivePolicy, error) {
+	s := strings.TrimSpace(os.Getenv("ARC_LIBRARY_ARCHIVE_AFTER"))
+	if s == "" {
+		return ArchivePolicy{}, nil
+	}
+	months := strings.TrimSuffix(strings.TrimSuffix(strings.TrimSuffix(s, "s"), "month"), "mo")
+	n, err := strconv.Atoi(strings.TrimSpace(months))
+	if err != nil || n < 0 {
+		return ArchivePolicy{}, fmt.Errorf("invalid ARC_LIBRARY_ARCHIVE_AFTER %q (expected months, like 6)", s)
+	}
+	return ArchivePolicy{Months: n}, nil
+}
+
+// LastActivity returns when doc was last read or worked with: the latest
+// of when it was added and read, its reading sessions, annotations, and
+// the reviews of its flashcards. Edits to its metadata, which imports and
+// refreshes make too, do not count.
+func LastActivity(s LibraryStore, doc *Document) (time.Time, error) {
+	last := doc.CreatedAt
+	later := func(t time.Time) {
+		if t.After(last) {
+			last = t
+		}
+	}
+	later(doc.ReadAt)
+
+	sessions, err := s.ListSessions(doc.ID)
+	if err != nil {
+		return last, err
+	}
+	for _, sess := range sessions {
+		later(sess.StartAt)
+		later(sess.EndAt)
+	}
+	anns, err := s.GetAnnotations(doc.ID)
+	if err != nil {
+		return last, err
+	}
+	for _, a := range anns {
+		later(a.CreatedAt)
+	}
+	// Not every backend has flashcards
+	if cards, err := s.ListFlashcards(&FlashcardListOptions{DocumentID: doc.ID}); err == nil {
+		for _, c := range cards {
+			later(c.LastReview)
+		}
+	}
+	return last, nil
+}
+
+// Stale returns the completed documents the policy archives at now:
+// those with no activity since Months months before it. Documents
+// scheduled for re-reading are still in use and are kept.
+func (p ArchivePolicy) Stale(s LibraryStore, now time.Time) ([]*Document, error) {
+	if p.Months <= 0 {
+		return nil, nil
+	}
+	cutoff := now.AddDate(0, -p.Months, 0)
+	docs, err := s.ListDocuments(&ListOptions{Status: string(StatusCompleted)})
+	if err != nil {
+		return nil, err
+	}
+	var stale []*Document
+	for _, doc := range docs {
+		last, err := LastActivity(s, doc)
+		if err != nil {
+			return nil, fmt.Errorf("activity of %s: %w", doc.ID, err)
+		}
+		if !last.Before(cutoff) {
+			continue
+		}
+		if r, err := s.GetDocumentReview(doc.ID); err == nil && r != nil {
+			continue
+		}
+		stale = append(stale, doc)
+	}
+	return stale, nil
+}
+
+// Apply archives the documents Stale returns, and returns them.
+func (p ArchivePolicy) Apply(s LibraryStore, now time.Time) ([]*Document, error) {
+	stale, err := p.Stale(s, now)
+	if err != nil {
+		return nil, err
+	}
+	for i, doc := range stale {
+		doc.Status = StatusArchived
+		if err := s.UpdateDocument(doc); err != nil {
+			return stale[:i], fmt.Errorf("archive %s: %w", doc.ID, err)
+		}
+	}
+	return stale, nil
+}
+
+// ArchiveCheckPath is the file whose modification time records when the
+// policy last ran, so that it runs at most once a day.
+func ArchiveCheckPath() (string, error) {
+	dir, err := CacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "archive-checked"), nil
+}
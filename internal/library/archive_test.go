@@ -0,0 +1,87 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+func TestArchivePolicy(t *testing.T) {
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	longAgo := now.AddDate(-2, 0, 0)
+	add := func(title string, status ReadingStatus, readAt time.Time) *Document {
+		doc := &Document{Title: title, Type: "paper", Status: status, CreatedAt: longAgo, ReadAt: readAt}
+		if err := s.AddDocument(doc); err != nil {
+			t.Fatal(err)
+		}
+		return doc
+	}
+	stale := add("Finished long ago", StatusCompleted, now.AddDate(-1, 0, 0))
+	recent := add("Finished last month", StatusCompleted, now.AddDate(0, -1, 0))
+	annotated := add("Annotated this week", StatusCompleted, now.AddDate(-1, 0, 0))
+	if err := s.AddAnnotation(&Annotation{DocumentID: annotated.ID, Type: "note", Content: "still useful"}); err != nil {
+		t.Fatal(err)
+	}
+	rereading := add("Scheduled for re-reading", StatusCompleted, now.AddDate(-1, 0, 0))
+	if err := s.SaveDocumentReview(&DocumentReview{DocumentID: rereading.ID, DueAt: now.AddDate(1, 0, 0)}); err != nil {
+		t.Fatal(err)
+	}
+	add("Never finished", StatusReading, time.Time{})
+
+	if docs, err := (ArchivePolicy{}).Apply(s, now); err != nil || len(docs) != 0 {
+		t.Errorf("disabled policy archived %d, %v", len(docs), err)
+	}
+	archived, err := ArchivePolicy{Months: 6}.Apply(s, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(archived) != 1 || archived[0].ID != stale.ID {
+		t.Fatalf("archived %+v, want only %q", archived, stale.Title)
+	}
+	if doc, _ := s.GetDocument(recent.ID); doc.Status != StatusCompleted {
+		t.Errorf("recently finished document is %s", doc.Status)
+	}
+
+	// Hidden from default listings, but listed on request and searchable
+	docs, err := s.ListDocuments(&ListOptions{HideArchived: true})
+	if err != nil || len(docs) != 4 {
+		t.Errorf("default listing: %d documents, %v", len(docs), err)
+	}
+	for _, d := range docs {
+		if d.ID == stale.ID {
+			t.Error("archived document in the default listing")
+		}
+	}
+	if docs, err := s.ListDocuments(&ListOptions{Status: string(StatusArchived), HideArchived: true}); err != nil || len(docs) != 1 {
+		t.Errorf("--status archived: %d documents, %v", len(docs), err)
+	}
+	if docs, err := s.ListDocuments(nil); err != nil || len(docs) != 5 {
+		t.Errorf("--archived: %d documents, %v", len(docs), err)
+	}
+	if docs, err := s.ListDocuments(&ListOptions{Search: "long ago"}); err != nil || len(docs) != 1 {
+		t.Errorf("search: %d documents, %v", len(docs), err)
+	}
+}
+
+func TestArchivePolicySetting(t *testing.T) {
+	for in, want := range map[string]int{"": 0, "0": 0, "6": 6, "12mo": 12, "3 months": 3} {
+		t.Setenv("ARC_LIBRARY_ARCHIVE_AFTER", in)
+		if p, err := ArchivePolicySetting(); err != nil || p.Months != want {
+			t.Errorf("%q = %d, %v; want %d", in, p.Months, err, want)
+		}
+	}
+	for _, in := range []string{"six", "-1", "1y"} {
+		t.Setenv("ARC_LIBRARY_ARCHIVE_AFTER", in)
+		if _, err := ArchivePolicySetting(); err == nil {
+			t.Errorf("%q: no error", in)
+		}
+	}
+}
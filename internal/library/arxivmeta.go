@@ -0,0 +1,24 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+// arxivMetaMapping imports a directory created by arc-arxiv, described by
+// its meta.yaml, as one document.
+var arxivMetaMapping = &MetaMapping{
+	Name:        "arxiv-meta",
+	File:        "meta.yaml",
+	Description: "Paper directories with a meta.yaml, as created by arc-arxiv",
+	Title:       "{{.title}}",
+	Authors:     "{{range .authors}}{{.name}}\n{{end}}",
+	Abstract:    "{{.abstract}}",
+	Source:      "{{.source_type}}",
+	SourceID:    "{{.arxiv_id}}",
+}
+
+func init() {
+	if err := arxivMetaMapping.compile(); err != nil {
+		panic(err)
+	}
+	RegisterImporter(metaMappingImporter{arxivMetaMapping})
+}
@@ -0,0 +1,398 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"fmt"
+	"time"
+)
+
+// AuditAction is what a change did to its entity.
+type AuditAction string
+
+const (
+	AuditCreate AuditAction = "create"
+	AuditUpdate AuditAction = "update"
+	AuditDelete AuditAction = "delete"
+)
+
+// AuditEntry records one change to the library: who made it, when, and
+// to what. Entries are only ever appended.
+type AuditEntry struct {
+	ID         string      `json:"id" yaml:"id"`
+	Entity     string      `json:"entity" yaml:"entity"` // document, annotation, collection, ...
+	EntityID   string      `json:"entity_id" yaml:"entity_id"`
+	DocumentID string      `json:"document_id,omitempty" yaml:"document_id,omitempty"` // the document the entity belongs to, if any
+	Action     AuditAction `json:"action" yaml:"action"`
+	Actor      string      `json:"actor" yaml:"actor"`
+	Summary    string      `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Fields     []string    `json:"fields,omitempty" yaml:"fields,omitempty"` // changed document fields, for updates
+	CreatedAt  time.Time   `json:"created_at" yaml:"created_at"`
+}
+
+// AuditListOptions filters the audit log. Zero values match everything.
+type AuditListOptions struct {
+	Since      time.Time
+	Entity     string
+	DocumentID string // entries for the document or anything belonging to it
+	Actor      string
+	Limit      int
+}
+
+// Matches reports whether e passes the filters other than Limit.
+func (o *AuditListOptions) Matches(e *AuditEntry) bool {
+	if o == nil {
+		return true
+	}
+	if !o.Since.IsZero() && e.CreatedAt.Before(o.Since) {
+		return false
+	}
+	if o.Entity != "" && e.Entity != o.Entity {
+		return false
+	}
+	if o.DocumentID != "" && e.DocumentID != o.DocumentID && !(e.Entity == "document" && e.EntityID == o.DocumentID) {
+		return false
+	}
+	return o.Actor == "" || e.Actor == o.Actor
+}
+
+// AuditedStore wraps a store, appending an audit entry for each change
+// made through it. Study activity (reading sessions, flashcard reviews)
+// and derived caches are not audited.
+type AuditedStore struct {
+	LibraryStore
+	Actor string
+}
+
+// NewAuditedStore returns s with changes recorded as made by actor.
+func NewAuditedStore(s LibraryStore, actor string) *AuditedStore {
+	return &AuditedStore{LibraryStore: s, Actor: actor}
+}
+
+// WithActor returns s recording changes as made by actor, when s is
+// audited; otherwise s itself. A DryRunStore keeps holding changes back,
+// sharing its DryRun with s, and a ReadOnlyStore refusing them.
+func WithActor(s LibraryStore, actor string) LibraryStore {
+	switch a := s.(type) {
+	case *AuditedStore:
+		return &AuditedStore{LibraryStore: a.LibraryStore, Actor: actor}
+	case *DryRunStore:
+		return &DryRunStore{LibraryStore: WithActor(a.LibraryStore, actor), DryRun: a.DryRun}
+	case *ReadOnlyStore:
+		return &ReadOnlyStore{LibraryStore: WithActor(a.LibraryStore, actor), ReadOnly: a.ReadOnly}
+	}
+	return s
+}
+
+// record appends an entry for a change that has been made. The change
+// stands either way, so a failure is reported as the log's.
+func (s *AuditedStore) record(e AuditEntry) error {
+	e.Actor = s.Actor
+	if err := s.LibraryStore.AppendAuditEntry(&e); err != nil {
+		return fmt.Errorf("record audit entry: %w", err)
+	}
+	return nil
+}
+
+func (s *AuditedStore) AddDocument(doc *Document) error {
+	if err := s.LibraryStore.AddDocument(doc); err != nil {
+		return err
+	}
+	return s.record(AuditEntry{Entity: "document", EntityID: doc.ID, Action: AuditCreate, Summary: doc.Title})
+}
+
+func (s *AuditedStore) AddDocuments(docs []*Document) error {
+	if err := s.LibraryStore.AddDocuments(docs); err != nil {
+		return err
+	}
+	for _, doc := range docs {
+		if err := s.record(AuditEntry{Entity: "document", EntityID: doc.ID, Action: AuditCreate, Summary: doc.Title}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *AuditedStore) UpdateDocument(doc *Document) error {
+	old, err := s.LibraryStore.GetDocument(doc.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.LibraryStore.UpdateDocument(doc); err != nil {
+		return err
+	}
+	var fields []string
+	if old != nil {
+		changes, err := DiffDocuments(old, doc)
+		if err != nil {
+			return err
+		}
+		if len(changes) == 0 {
+			return nil
+		}
+		for _, c := range changes {
+			fields = append(fields, c.Field)
+		}
+	}
+	return s.record(AuditEntry{Entity: "document", EntityID: doc.ID, Action: AuditUpdate, Summary: doc.Title, Fields: fields})
+}
+
+func (s *AuditedStore) DeleteDocument(id string) error {
+	doc, err := s.LibraryStore.GetDocument(id)
+	if err != nil {
+		return err
+	}
+	if err := s.LibraryStore.DeleteDocument(id); err != nil {
+		return err
+	}
+	e := AuditEntry{Entity: "document", EntityID: id, Action: AuditDelete}
+	if doc != nil {
+		e.Summary = doc.Title
+	}
+	return s.record(e)
+}
+
+func (s *AuditedStore) AddTag(documentID, tag string) error {
+	if err := s.LibraryStore.AddTag(documentID, tag); err != nil {
+		return err
+	}
+	return s.record(AuditEntry{Entity: "document", EntityID: documentID, Action: AuditUpdate, Summary: "tag +" + tag, Fields: []string{"tags"}})
+}
+
+func (s *AuditedStore) RemoveTag(documentID, tag string) error {
+	if err := s.LibraryStore.RemoveTag(documentID, tag); err != nil {
+		return err
+	}
+	return s.record(AuditEntry{Entity: "document", EntityID: documentID, Action: AuditUpdate, Summary: "tag -" + tag, Fields: []string{"tags"}})
+}
+
+func (s *AuditedStore) CreateCollection(name, description string) (*Collection, error) {
+	c, err := s.LibraryStore.CreateCollection(name, description)
+	if err != nil {
+		return nil, err
+	}
+	return c, s.record(AuditEntry{Entity: "collection", EntityID: c.ID, Action: AuditCreate, Summary: c.Name})
+}
+
+func (s *AuditedStore) AddToCollection(collectionID, documentID string) error {
+	if err := s.LibraryStore.AddToCollection(collectionID, documentID); err != nil {
+		return err
+	}
+	return s.record(AuditEntry{Entity: "collection", EntityID: collectionID, DocumentID: documentID, Action: AuditUpdate, Summary: "added " + documentID})
+}
+
+func (s *AuditedStore) RemoveFromCollection(collectionID, documentID string) error {
+	if err := s.LibraryStore.RemoveFromCollection(collectionID, documentID); err != nil {
+		return err
+	}
+	return s.record(AuditEntry{Entity: "collection", EntityID: collectionID, DocumentID: documentID, Action: AuditUpdate, Summary: "removed " + documentID})
+}
+
+func (s *AuditedStore) DeleteCollection(id string) error {
+	c, err := s.LibraryStore.GetCollection(id)
+	if err != nil {
+		return err
+	}
+	if err := s.LibraryStore.DeleteCollection(id); err != nil {
+		return err
+	}
+	e := AuditEntry{Entity: "collection", EntityID: id, Action: AuditDelete}
+	if c != nil {
+		e.Summary = c.Name
+	}
+	return s.record(e)
+}
+
+func (s *AuditedStore) SetCollectionPublic(id string, public bool) error {
+	if err := s.LibraryStore.SetCollectionPublic(id, public); err != nil {
+		return err
+	}
+	summary := "unpublished"
+	if public {
+		summary = "published"
+	}
+	return s.record(AuditEntry{Entity: "collection", EntityID: id, Action: AuditUpdate, Summary: summary, Fields: []string{"public"}})
+}
+
+func (s *AuditedStore) AddAnnotation(ann *Annotation) error {
+	if err := s.LibraryStore.AddAnnotation(ann); err != nil {
+		return err
+	}
+	return s.record(AuditEntry{Entity: "annotation", EntityID: ann.ID, DocumentID: ann.DocumentID, Action: AuditCreate, Summary: ann.Type + ": " + truncateRunes(ann.Content, 60)})
+}
+
+func (s *AuditedStore) AddAnnotations(anns []*Annotation) error {
+	if err := s.LibraryStore.AddAnnotations(anns); err != nil {
+		return err
+	}
+	for _, ann := range anns {
+		if err := s.record(AuditEntry{Entity: "annotation", EntityID: ann.ID, DocumentID: ann.DocumentID, Action: AuditCreate, Summary: ann.Type + ": " + truncateRunes(ann.Content, 60)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *AuditedStore) DeleteAnnotation(id string) error {
+	if err := s.LibraryStore.DeleteAnnotation(id); err != nil {
+		return err
+	}
+	return s.record(AuditEntry{Entity: "annotation", EntityID: id, Action: AuditDelete})
+}
+
+func (s *AuditedStore) AddDocumentLink(l *DocumentLink) error {
+	if err := s.LibraryStore.AddDocumentLink(l); err != nil {
+		return err
+	}
+	return s.record(AuditEntry{Entity: "link", EntityID: l.ID, DocumentID: l.FromID, Action: AuditCreate, Summary: fmt.Sprintf("%s %s %s", l.FromID, l.Relation, l.ToID)})
+}
+
+func (s *AuditedStore) DeleteDocumentLink(id string) error {
+	if err := s.LibraryStore.DeleteDocumentLink(id); err != nil {
+		return err
+	}
+	return s.record(AuditEntry{Entity: "link", EntityID: id, Action: AuditDelete})
+}
+
+func (s *AuditedStore) AddFlashcard(card *Flashcard) error {
+	if err := s.LibraryStore.AddFlashcard(card); err != nil {
+		return err
+	}
+	return s.record(AuditEntry{Entity: "flashcard", EntityID: card.ID, DocumentID: card.DocumentID, Action: AuditCreate, Summary: truncateRunes(card.Front, 60)})
+}
+
+func (s *AuditedStore) AddFlashcards(cards []*Flashcard) error {
+	if err := s.LibraryStore.AddFlashcards(cards); err != nil {
+		return err
+	}
+	for _, card := range cards {
+		if err := s.record(AuditEntry{Entity: "flashcard", EntityID: card.ID, DocumentID: card.DocumentID, Action: AuditCreate, Summary: truncateRunes(card.Front, 60)}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *AuditedStore) UpdateFlashcard(card *Flashcard) error {
+	if err := s.LibraryStore.UpdateFlashcard(card); err != nil {
+		return err
+	}
+	return s.record(AuditEntry{Entity: "flashcard", EntityID: card.ID, DocumentID: card.DocumentID, Action: AuditUpdate, Summary: truncateRunes(card.Front, 60)})
+}
+
+func (s *AuditedStore) DeleteFlashcard(id string) error {
+	card, err := s.LibraryStore.GetFlashcard(id)
+	if err != nil {
+		return err
+	}
+	if err := s.LibraryStore.DeleteFlashcard(id); err != nil {
+		return err
+	}
+	e := AuditEntry{Entity: "flashcard", EntityID: id, Action: AuditDelete}
+	if card != nil {
+		e.DocumentID, e.Summary = card.DocumentID, truncateRunes(card.Front, 60)
+	}
+	return s.record(e)
+}
+
+func (s *AuditedStore) AddTask(t *Task) error {
+	if err := s.LibraryStore.AddTask(t); err != nil {
+		return err
+	}
+	return s.record(AuditEntry{Entity: "task", EntityID: t.ID, DocumentID: t.DocumentID, Action: AuditCreate, Summary: truncateRunes(t.Description, 60)})
+}
+
+func (s *AuditedStore) UpdateTask(t *Task) error {
+	if err := s.LibraryStore.UpdateTask(t); err != nil {
+		return err
+	}
+	return s.record(AuditEntry{Entity: "task", EntityID: t.ID, DocumentID: t.DocumentID, Action: AuditUpdate, Summary: t.Status + ": " + truncateRunes(t.Description, 60)})
+}
+
+func (s *AuditedStore) DeleteTask(id string) error {
+	if err := s.LibraryStore.DeleteTask(id); err != nil {
+		return err
+	}
+	return s.record(AuditEntry{Entity: "task", EntityID: id, Action: AuditDelete})
+}
+
+func (s *AuditedStore) SetReadingQueue(documentIDs []string) error {
+	if err := s.LibraryStore.SetReadingQueue(documentIDs); err != nil {
+		return err
+	}
+	return s.record(AuditEntry{Entity: "queue", EntityID: "queue", Action: AuditUpdate, Summary: fmt.Sprintf("%d document(s)", len(documentIDs))})
+}
+
+func (s *AuditedStore) DefineField(def *FieldDef) error {
+	if err := s.LibraryStore.DefineField(def); err != nil {
+		return err
+	}
+	return s.record(AuditEntry{Entity: "field", EntityID: def.Name, Action: AuditCreate, Summary: string(def.Type)})
+}
+
+func (s *AuditedStore) DeleteField(name string) error {
+	if err := s.LibraryStore.DeleteField(name); err != nil {
+		return err
+	}
+	return s.record(AuditEntry{Entity: "field", EntityID: name, Action: AuditDelete})
+}
+
+func (s *AuditedStore) CreateShare(sh *Share) error {
+	if err := s.LibraryStore.CreateShare(sh); err != nil {
+		return err
+	}
+	// The token grants access, so only its start is logged
+	return s.record(AuditEntry{Entity: "share", EntityID: truncateRunes(sh.Token, 8), DocumentID: sh.DocumentID, Action: AuditCreate, Summary: "expires " + sh.ExpiresAt.Format("2006-01-02 15:04")})
+}
+
+func (s *AuditedStore) DeleteShare(token string) error {
+	sh, err := s.LibraryStore.GetShare(token)
+	if err != nil {
+		return err
+	}
+	if err := s.LibraryStore.DeleteShare(token); err != nil {
+		return err
+	}
+	e := AuditEntry{Entity: "share", EntityID: truncateRunes(token, 8), Action: AuditDelete}
+	if sh != nil {
+		e.DocumentID = sh.DocumentID
+	}
+	return s.record(e)
+}
+
+func (s *AuditedStore) CreateAPIToken(t *APIToken) error {
+	if err := s.LibraryStore.CreateAPIToken(t); err != nil {
+		return err
+	}
+	return s.record(AuditEntry{Entity: "token", EntityID: t.ID, Action: AuditCreate, Summary: fmt.Sprintf("%s (%s)", t.Name, t.Scope)})
+}
+
+func (s *AuditedStore) DeleteAPIToken(id string) error {
+	if err := s.LibraryStore.DeleteAPIToken(id); err != nil {
+		return err
+	}
+	return s.record(AuditEntry{Entity: "token", EntityID: id, Action: AuditDelete})
+}
+
+func (s *AuditedStore) SaveSearch(ss *SavedSearch) error {
+	if err := s.LibraryStore.SaveSearch(ss); err != nil {
+		return err
+	}
+	return s.record(AuditEntry{Entity: "search", EntityID: ss.ID, Action: AuditUpdate, Summary: ss.Name})
+}
+
+func (s *AuditedStore) DeleteSavedSearch(id string) error {
+	if err := s.LibraryStore.DeleteSavedSearch(id); err != nil {
+		return err
+	}
+	return s.record(AuditEntry{Entity: "search", EntityID: id, Action: AuditDelete})
+}
+
+// truncateRunes shortens s to at most n runes, for summaries.
+func truncateRunes(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
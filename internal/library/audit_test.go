@@ -0,0 +1,105 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+func TestAuditedStore(t *testing.T) {
+	kv, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewAuditedStore(kv, "alice")
+
+	doc := &Document{Title: "Audited", Rating: 2}
+	if err := s.AddDocument(doc); err != nil {
+		t.Fatal(err)
+	}
+	doc.Rating, doc.Abstract = 4, "Now with an abstract."
+	if err := s.UpdateDocument(doc); err != nil {
+		t.Fatal(err)
+	}
+	// An update that changes nothing is not logged
+	if err := s.UpdateDocument(doc); err != nil {
+		t.Fatal(err)
+	}
+	ann := &Annotation{DocumentID: doc.ID, Type: "note", Content: "Check the proof"}
+	if err := WithActor(s, "token:bob").AddAnnotation(ann); err != nil {
+		t.Fatal(err)
+	}
+	other := &Document{Title: "Other"}
+	if err := s.AddDocument(other); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteDocument(other.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := s.ListAuditEntries(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range all {
+		got = append(got, e.Actor+" "+string(e.Action)+" "+e.Entity)
+	}
+	want := []string{
+		"alice delete document",
+		"alice create document",
+		"token:bob create annotation",
+		"alice update document",
+		"alice create document",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("log = %q, want %q", got, want)
+	}
+	if update := all[3]; !reflect.DeepEqual(update.Fields, []string{"abstract", "rating"}) || update.EntityID != doc.ID {
+		t.Errorf("update entry = %+v", update)
+	}
+	if all[0].Summary != "Other" {
+		t.Errorf("delete summary = %q", all[0].Summary)
+	}
+
+	byDoc, _ := s.ListAuditEntries(&AuditListOptions{DocumentID: doc.ID})
+	if len(byDoc) != 3 {
+		t.Errorf("entries for the document = %d, want 3", len(byDoc))
+	}
+	byActor, _ := s.ListAuditEntries(&AuditListOptions{Actor: "token:bob"})
+	if len(byActor) != 1 || byActor[0].EntityID != ann.ID {
+		t.Errorf("entries by bob = %+v", byActor)
+	}
+	if recent, _ := s.ListAuditEntries(&AuditListOptions{Since: time.Now().Add(time.Hour)}); len(recent) != 0 {
+		t.Errorf("entries since the future = %d", len(recent))
+	}
+	if limited, _ := s.ListAuditEntries(&AuditListOptions{Limit: 2}); len(limited) != 2 || limited[0].Action != AuditDelete {
+		t.Errorf("limited = %+v", limited)
+	}
+}
+
+func TestKVAuditLogAcrossDays(t *testing.T) {
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for i, at := range []time.Time{now.AddDate(0, 0, -10), now.AddDate(0, 0, -3), now} {
+		e := &AuditEntry{Entity: "document", EntityID: string(rune('a' + i)), Action: AuditUpdate, Actor: "alice", CreatedAt: at}
+		if err := s.AppendAuditEntry(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := s.ListAuditEntries(&AuditListOptions{Since: now.AddDate(0, 0, -7)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].EntityID != "c" || entries[1].EntityID != "b" {
+		t.Errorf("entries in the last week = %+v", entries)
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"time"
+)
+
+func init() { RegisterExporter(backupExporter{}) }
+
+// BackupVersion is the version of the backup format this build writes.
+// Backups of a later version are refused rather than read in part.
+const BackupVersion = 1
+
+// LibraryBackup is a snapshot of documents and everything belonging to
+// them, as "export --format backup" writes it. Backups are JSON, or JSON
+// compressed with gzip.
+type LibraryBackup struct {
+	Version     int             `json:"version"`
+	CreatedAt   time.Time       `json:"created_at"`
+	Documents   []*Document     `json:"documents"`
+	Annotations []*Annotation   `json:"annotations"`
+	Flashcards  []*Flashcard    `json:"flashcards"`
+	Collections []*Collection   `json:"collections"` // every collection, listing only the backed up documents
+	Links       []*DocumentLink `json:"links"`       // links between backed up documents
+}
+
+// NewLibraryBackup takes a snapshot of docs from s.
+func NewLibraryBackup(s LibraryStore, docs []*Document) (*LibraryBackup, error) {
+	return newLibraryBackup(s, docs, nil)
+}
+
+// newLibraryBackup is NewLibraryBackup calling onProgress, if set, after
+// each document's annotations and links are read.
+func newLibraryBackup(s LibraryStore, docs []*Document, onProgress func(done, total int)) (*LibraryBackup, error) {
+	b := &LibraryBackup{
+		Version:     BackupVersion,
+		CreatedAt:   time.Now().UTC(),
+		Documents:   docs,
+		Annotations: []*Annotation{},
+		Flashcards:  []*Flashcard{},
+		Collections: []*Collection{},
+		Links:       []*DocumentLink{},
+	}
+	if b.Documents == nil {
+		b.Documents = []*Document{}
+	}
+	included := make(map[string]bool, len(docs))
+	for _, d := range docs {
+		included[d.ID] = true
+	}
+
+	seenLinks := map[string]bool{}
+	for i, d := range docs {
+		anns, err := s.GetAnnotations(d.ID)
+		if err != nil {
+			return nil, fmt.Errorf("annotations of %s: %w", d.ID, err)
+		}
+		b.Annotations = append(b.Annotations, anns...)
+		links, err := s.ListDocumentLinks(d.ID)
+		if err != nil {
+			return nil, fmt.Errorf("links of %s: %w", d.ID, err)
+		}
+		for _, l := range links {
+			if included[l.FromID] && included[l.ToID] && !seenLinks[l.ID] {
+				seenLinks[l.ID] = true
+				b.Links = append(b.Links, l)
+			}
+		}
+		if onProgress != nil {
+			onProgress(i+1, len(docs))
+		}
+	}
+
+	cards, err := s.ListFlashcards(nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range cards {
+		if included[c.DocumentID] {
+			b.Flashcards = append(b.Flashcards, c)
+		}
+	}
+
+	colls, err := s.ListCollections()
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range colls {
+		kept := *c
+		kept.DocumentIDs = []string{}
+		kept.AddedAt = nil
+		for _, id := range c.DocumentIDs {
+			if included[id] {
+				kept.DocumentIDs = append(kept.DocumentIDs, id)
+				if t, ok := c.AddedAt[id]; ok {
+					if kept.AddedAt == nil {
+						kept.AddedAt = map[string]time.Time{}
+					}
+					kept.AddedAt[id] = t
+				}
+			}
+		}
+		b.Collections = append(b.Collections, &kept)
+	}
+	return b, nil
+}
+
+// ReadLibraryBackup reads a backup file, compressed or not.
+func ReadLibraryBackup(path string) (*LibraryBackup, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var r io.Reader = bufio.NewReader(f)
+	if magic, _ := r.(*bufio.Reader).Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+	var b LibraryBackup
+	if err := json.NewDecoder(r).Decode(&b); err != nil {
+		return nil, fmt.Errorf("%s: not a library backup: %w", path, err)
+	}
+	if b.Version == 0 || b.Version > BackupVersion {
+		return nil, fmt.Errorf("%s: backup version %d is not supported (this build reads up to %d)", path, b.Version, BackupVersion)
+	}
+	return &b, nil
+}
+
+// backupExporter writes a LibraryBackup of the documents.
+type backupExporter struct{}
+
+func (backupExporter) Format() Format {
+	return Format{
+		Name:         "backup",
+		Description:  "A snapshot of the documents with their annotations, flashcards, collections, and links, for diff and import",
+		Extensions:   []string{".json"},
+		Capabilities: []string{FormatTags, FormatFiles, FormatAnnotations, FormatAllFields, FormatFlashcards},
+	}
+}
+
+func (backupExporter) Export(w io.Writer, s LibraryStore, docs []*Document, opts ExportOptions) error {
+	b, err := newLibraryBackup(s, slices.Clone(docs), opts.OnProgress)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
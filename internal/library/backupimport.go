@@ -0,0 +1,361 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// BackupImportOptions selects what ImportBackup takes from a backup.
+// With both set, a document must be in one of the collections and have
+// one of the tags; with neither, every document is taken.
+type BackupImportOptions struct {
+	Collections []string // backup collections, by name or ID
+	Tags        []string
+
+	// OnProgress, if set, is called after each selected document is added
+	// or matched.
+	OnProgress func(done, total int)
+}
+
+// BackupDuplicate is a backup document that is already in the library.
+type BackupDuplicate struct {
+	BackupID   string `json:"backup_id"`
+	DocumentID string `json:"document_id"` // the library's copy
+	Title      string `json:"title"`
+	Reason     string `json:"reason"` // what matched: source ID, DOI, or full text
+}
+
+// BackupImportResult is what ImportBackup added to the library.
+type BackupImportResult struct {
+	Documents   []*Document       `json:"documents"`  // added, with their new IDs
+	Duplicates  []BackupDuplicate `json:"duplicates"` // matched rather than added; their annotations and flashcards are still merged
+	Annotations int               `json:"annotations"`
+	Flashcards  int               `json:"flashcards"`
+	Collections int               `json:"collections"` // created; documents join existing collections of the same name
+	Links       int               `json:"links"`
+}
+
+// SelectBackupDocuments returns the documents of b that opts selects, in
+// backup order.
+func SelectBackupDocuments(b *LibraryBackup, opts BackupImportOptions) ([]*Document, error) {
+	var inCollection map[string]bool
+	if len(opts.Collections) > 0 {
+		inCollection = map[string]bool{}
+		for _, name := range opts.Collections {
+			found := false
+			for _, c := range b.Collections {
+				if c.ID == name || strings.EqualFold(c.Name, name) {
+					found = true
+					for _, id := range c.DocumentIDs {
+						inCollection[id] = true
+					}
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("no collection %q in the backup", name)
+			}
+		}
+	}
+
+	var docs []*Document
+	for _, d := range b.Documents {
+		if inCollection != nil && !inCollection[d.ID] {
+			continue
+		}
+		if len(opts.Tags) > 0 && !slices.ContainsFunc(opts.Tags, func(t string) bool { return slices.Contains(d.Tags, t) }) {
+			continue
+		}
+		docs = append(docs, d)
+	}
+	return docs, nil
+}
+
+// backupImportBatch is how many documents ImportBackup adds at once.
+const backupImportBatch = 500
+
+// ImportBackup merges the documents opts selects from another library's
+// backup into s, with their annotations, flashcards, collection
+// memberships, and the links between them. Documents get new IDs, and
+// everything referring to them is mapped to the new ones. A document
+// already in the library, by source ID, DOI, or identical full text, is
+// not added again: its annotations and flashcards go to the library's
+// copy, skipping those it already has. Reading sessions are not in
+// backups, so annotations lose theirs.
+func ImportBackup(s LibraryStore, b *LibraryBackup, opts BackupImportOptions) (*BackupImportResult, error) {
+	selected, err := SelectBackupDocuments(b, opts)
+	if err != nil {
+		return nil, err
+	}
+	res := &BackupImportResult{Documents: []*Document{}, Duplicates: []BackupDuplicate{}}
+
+	existing, err := s.ListDocuments(&ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	index := newDuplicateIndex(existing)
+
+	docIDs := map[string]string{} // backup document ID -> library document ID
+	var (
+		batch   []*Document // to add, copies of the backup's
+		from    []string    // their backup IDs
+		repeats []BackupDuplicate
+		done    int
+	)
+	pending := newDuplicateIndex(nil) // the batch, by backup ID
+	flush := func(upTo int) error {
+		if err := s.AddDocuments(batch); err != nil {
+			return fmt.Errorf("add documents: %w", err)
+		}
+		for i, doc := range batch {
+			docIDs[from[i]] = doc.ID
+			index.add(doc)
+			res.Documents = append(res.Documents, doc)
+		}
+		// Documents in the backup twice go with the copy just added
+		for _, r := range repeats {
+			r.DocumentID = docIDs[r.DocumentID]
+			docIDs[r.BackupID] = r.DocumentID
+			res.Duplicates = append(res.Duplicates, r)
+		}
+		batch, from, repeats = nil, nil, nil
+		pending = newDuplicateIndex(nil)
+		if opts.OnProgress != nil {
+			for ; done < upTo; done++ {
+				opts.OnProgress(done+1, len(selected))
+			}
+		}
+		return nil
+	}
+	for i, d := range selected {
+		if id, reason := index.find(d); id != "" {
+			docIDs[d.ID] = id
+			res.Duplicates = append(res.Duplicates, BackupDuplicate{BackupID: d.ID, DocumentID: id, Title: d.Title, Reason: reason})
+		} else if id, reason := pending.find(d); id != "" {
+			repeats = append(repeats, BackupDuplicate{BackupID: d.ID, DocumentID: id, Title: d.Title, Reason: reason})
+		} else {
+			doc := *d
+			doc.ID = ""
+			doc.Tags = slices.Clone(d.Tags)
+			doc.Authors = slices.Clone(d.Authors)
+			batch = append(batch, &doc)
+			from = append(from, d.ID)
+			pending.add(d)
+		}
+		if len(batch) >= backupImportBatch || i == len(selected)-1 {
+			if err := flush(i + 1); err != nil {
+				return res, err
+			}
+		}
+	}
+
+	if err := importBackupAnnotations(s, b, docIDs, res); err != nil {
+		return res, err
+	}
+	if err := importBackupFlashcards(s, b, docIDs, res); err != nil {
+		return res, err
+	}
+
+	for _, c := range b.Collections {
+		var members []string
+		for _, id := range c.DocumentIDs {
+			if to, ok := docIDs[id]; ok {
+				members = append(members, to)
+			}
+		}
+		if len(members) == 0 {
+			continue
+		}
+		target, err := s.GetCollection(c.Name)
+		if err != nil {
+			return res, err
+		}
+		if target == nil {
+			if target, err = s.CreateCollection(c.Name, c.Description); err != nil {
+				return res, fmt.Errorf("create collection %s: %w", c.Name, err)
+			}
+			res.Collections++
+		}
+		for _, id := range members {
+			if err := s.AddToCollection(target.ID, id); err != nil {
+				return res, err
+			}
+		}
+	}
+
+	for _, l := range b.Links {
+		from, ok1 := docIDs[l.FromID]
+		to, ok2 := docIDs[l.ToID]
+		if !ok1 || !ok2 {
+			continue
+		}
+		links, err := s.ListDocumentLinks(from)
+		if err != nil {
+			return res, err
+		}
+		if slices.ContainsFunc(links, func(e *DocumentLink) bool {
+			return e.FromID == from && e.ToID == to && e.Relation == l.Relation
+		}) {
+			continue
+		}
+		if err := s.AddDocumentLink(&DocumentLink{FromID: from, ToID: to, Relation: l.Relation, CreatedAt: l.CreatedAt}); err != nil {
+			return res, err
+		}
+		res.Links++
+	}
+	return res, nil
+}
+
+// importBackupAnnotations adds the annotations of the imported documents,
+// parents before replies so replies can point at their parents' new IDs.
+func importBackupAnnotations(s LibraryStore, b *LibraryBackup, docIDs map[string]string, res *BackupImportResult) error {
+	byDoc := map[string][]*Annotation{}
+	for _, a := range b.Annotations {
+		if _, ok := docIDs[a.DocumentID]; ok {
+			byDoc[a.DocumentID] = append(byDoc[a.DocumentID], a)
+		}
+	}
+	annIDs := map[string]string{}
+	// Annotations are added in batches, a batch ending before a reply to
+	// one of its own, whose ID isn't known yet
+	var (
+		batch []*Annotation
+		from  []string // their backup IDs
+	)
+	flush := func() error {
+		if err := s.AddAnnotations(batch); err != nil {
+			return fmt.Errorf("add annotations: %w", err)
+		}
+		for i, ann := range batch {
+			annIDs[from[i]] = ann.ID
+		}
+		res.Annotations += len(batch)
+		batch, from = nil, nil
+		return nil
+	}
+	for _, d := range b.Documents {
+		anns := byDoc[d.ID]
+		if len(anns) == 0 {
+			continue
+		}
+		docID := docIDs[d.ID]
+		have, err := s.GetAnnotations(docID)
+		if err != nil {
+			return err
+		}
+		ordered, _ := FlattenAnnotationThreads(BuildAnnotationThreads(anns))
+		for _, a := range ordered {
+			if i := slices.IndexFunc(have, func(h *Annotation) bool {
+				return h.Type == a.Type && h.Content == a.Content && h.Page == a.Page && h.Position == a.Position
+			}); i >= 0 {
+				if have[i].ID == "" {
+					// In the backup twice; the first is in the batch
+					if err := flush(); err != nil {
+						return err
+					}
+				}
+				annIDs[a.ID] = have[i].ID
+				continue
+			}
+			if a.ParentID != "" && slices.Contains(from, a.ParentID) {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+			ann := *a
+			ann.ID, ann.DocumentID, ann.SessionID = "", docID, ""
+			ann.ParentID = annIDs[a.ParentID]
+			batch = append(batch, &ann)
+			from = append(from, a.ID)
+			have = append(have, &ann)
+		}
+	}
+	return flush()
+}
+
+// importBackupFlashcards adds the flashcards of the imported documents
+// with their schedules. Their review logs are not in backups.
+func importBackupFlashcards(s LibraryStore, b *LibraryBackup, docIDs map[string]string, res *BackupImportResult) error {
+	have := map[string][]*Flashcard{}
+	var cards []*Flashcard
+	for _, c := range b.Flashcards {
+		docID, ok := docIDs[c.DocumentID]
+		if !ok {
+			continue
+		}
+		if _, ok := have[docID]; !ok {
+			cards, err := s.ListFlashcards(&FlashcardListOptions{DocumentID: docID})
+			if err != nil {
+				return err
+			}
+			have[docID] = cards
+		}
+		if slices.ContainsFunc(have[docID], func(h *Flashcard) bool {
+			return h.Front == c.Front && h.Back == c.Back && h.Cloze == c.Cloze
+		}) {
+			continue
+		}
+		card := *c
+		card.ID, card.DocumentID = "", docID
+		card.Tags = slices.Clone(c.Tags)
+		cards = append(cards, &card)
+		have[docID] = append(have[docID], &card)
+	}
+	if err := s.AddFlashcards(cards); err != nil {
+		return fmt.Errorf("add flashcards: %w", err)
+	}
+	res.Flashcards += len(cards)
+	return nil
+}
+
+// duplicateIndex finds documents already in a library by the identifiers
+// that survive a move between libraries.
+type duplicateIndex struct {
+	bySource map[string]string
+	byDOI    map[string]string
+	byText   map[uint64]string
+}
+
+func newDuplicateIndex(docs []*Document) *duplicateIndex {
+	x := &duplicateIndex{bySource: map[string]string{}, byDOI: map[string]string{}, byText: map[uint64]string{}}
+	for _, d := range docs {
+		x.add(d)
+	}
+	return x
+}
+
+func (x *duplicateIndex) add(d *Document) {
+	if d.SourceID != "" {
+		x.bySource[d.Source+":"+d.SourceID] = d.ID
+	}
+	if doi := strings.ToLower(documentDOI(d)); doi != "" {
+		x.byDOI[doi] = d.ID
+	}
+	if strings.TrimSpace(d.FullText) != "" {
+		x.byText[HashText(d.FullText)] = d.ID
+	}
+}
+
+// find returns the ID of the library's copy of d and what matched, or ""
+// when there is none.
+func (x *duplicateIndex) find(d *Document) (id, reason string) {
+	if d.SourceID != "" {
+		if id := x.bySource[d.Source+":"+d.SourceID]; id != "" {
+			return id, d.Source + " ID"
+		}
+	}
+	if doi := strings.ToLower(documentDOI(d)); doi != "" {
+		if id := x.byDOI[doi]; id != "" {
+			return id, "DOI"
+		}
+	}
+	if strings.TrimSpace(d.FullText) != "" {
+		if id := x.byText[HashText(d.FullText)]; id != "" {
+			return id, "full text"
+		}
+	}
+	return "", ""
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"testing"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+func TestImportBackup(t *testing.T) {
+	// The other library, backed up
+	src, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	paper := &Document{Type: DocTypePaper, Title: "Attention", Source: IDSourceArxiv, SourceID: "1706.03762", Tags: []string{"ml"}}
+	bert := &Document{Type: DocTypePaper, Title: "BERT", Tags: []string{"ml"}, FullText: "We introduce BERT."}
+	other := &Document{Type: DocTypeBook, Title: "Cooking", Tags: []string{"food"}}
+	for _, d := range []*Document{paper, bert, other} {
+		if err := src.AddDocument(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	parent := &Annotation{DocumentID: paper.ID, Type: "highlight", Content: "Scaled dot-product"}
+	if err := src.AddAnnotation(parent); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.AddAnnotation(&Annotation{DocumentID: paper.ID, Type: "note", Content: "Why scale?", ParentID: parent.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.AddFlashcard(&Flashcard{DocumentID: bert.ID, Type: "basic", Front: "What is BERT?", Back: "A transformer encoder", Interval: 6}); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.AddDocumentLink(&DocumentLink{FromID: bert.ID, ToID: paper.ID, Relation: "cites"}); err != nil {
+		t.Fatal(err)
+	}
+	thesis, err := src.CreateCollection("thesis", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range []*Document{paper, bert, other} {
+		if err := src.AddToCollection(thesis.ID, d.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	docs, err := src.ListDocuments(&ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	backup, err := NewLibraryBackup(src, docs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// This library already has the arXiv paper and one of its highlights
+	dst, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	mine := &Document{Type: DocTypePaper, Title: "Attention Is All You Need", Source: IDSourceArxiv, SourceID: "1706.03762"}
+	if err := dst.AddDocument(mine); err != nil {
+		t.Fatal(err)
+	}
+	if err := dst.AddAnnotation(&Annotation{DocumentID: mine.ID, Type: "highlight", Content: "Scaled dot-product"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ImportBackup(dst, backup, BackupImportOptions{Collections: []string{"missing"}}); err == nil {
+		t.Error("expected an error for a collection not in the backup")
+	}
+
+	res, err := ImportBackup(dst, backup, BackupImportOptions{Collections: []string{"thesis"}, Tags: []string{"ml"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Documents) != 1 || res.Documents[0].Title != "BERT" || res.Documents[0].ID == bert.ID {
+		t.Fatalf("documents = %+v", res.Documents)
+	}
+	if len(res.Duplicates) != 1 || res.Duplicates[0].DocumentID != mine.ID || res.Duplicates[0].Reason != "arxiv ID" {
+		t.Errorf("duplicates = %+v", res.Duplicates)
+	}
+	if res.Annotations != 1 || res.Flashcards != 1 || res.Collections != 1 || res.Links != 1 {
+		t.Errorf("result = %+v, want 1 annotation, flashcard, collection, and link", res)
+	}
+	newBert := res.Documents[0].ID
+
+	// The reply joined the existing highlight on the library's copy
+	anns, err := dst.GetAnnotations(mine.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	threads := BuildAnnotationThreads(anns)
+	if len(threads) != 1 || len(threads[0].Replies) != 1 || threads[0].Replies[0].Content != "Why scale?" {
+		t.Errorf("annotations not threaded onto the existing highlight: %+v", anns)
+	}
+	cards, err := dst.ListFlashcards(&FlashcardListOptions{DocumentID: newBert})
+	if err != nil || len(cards) != 1 || cards[0].Interval != 6 {
+		t.Errorf("flashcards = %+v, %v", cards, err)
+	}
+	links, err := dst.ListDocumentLinks(newBert)
+	if err != nil || len(links) != 1 || links[0].ToID != mine.ID {
+		t.Errorf("links = %+v, %v", links, err)
+	}
+	c, err := dst.GetCollection("thesis")
+	if err != nil || c == nil || len(c.DocumentIDs) != 2 {
+		t.Errorf("collection = %+v, %v", c, err)
+	}
+
+	// Importing again finds everything, BERT by its full text
+	again, err := ImportBackup(dst, backup, BackupImportOptions{Tags: []string{"ml"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again.Documents) != 0 || len(again.Duplicates) != 2 || again.Annotations+again.Flashcards+again.Collections+again.Links != 0 {
+		t.Errorf("second import = %+v", again)
+	}
+}
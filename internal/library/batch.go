@@ -0,0 +1,241 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Batch operation kinds.
+const (
+	BatchAddTags              = "add_tags"
+	BatchRemoveTags           = "remove_tags"
+	BatchAddToCollection      = "add_to_collection"
+	BatchRemoveFromCollection = "remove_from_collection"
+	BatchSetStatus            = "set_status"
+)
+
+// BatchOperation is one change applied to several documents. Moving
+// documents between collections is a remove_from_collection and an
+// add_to_collection in the same batch.
+type BatchOperation struct {
+	Op         string   `json:"op"`
+	IDs        []string `json:"ids"`
+	Tags       []string `json:"tags,omitempty"`       // add_tags, remove_tags
+	Collection string   `json:"collection,omitempty"` // ID or name, for the collection operations
+	Status     string   `json:"status,omitempty"`     // set_status
+}
+
+// BatchResult is what a batch changed. Documents and collections it left
+// as they were, say by adding a tag a document already has, are not
+// listed.
+type BatchResult struct {
+	Operations  int      `json:"operations"`  // how many were applied
+	Documents   []string `json:"documents"`   // IDs of the documents updated
+	Collections []string `json:"collections"` // IDs of the collections whose documents changed
+}
+
+// BatchError is an operation ApplyBatch refused before writing anything.
+type BatchError struct {
+	Index int // 1-based
+	Op    string
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("operation %d (%s): %v", e.Index, e.Op, e.Err)
+}
+
+func (e *BatchError) Unwrap() error { return e.Err }
+
+// batchMembership is one collection membership a batch changes.
+type batchMembership struct {
+	collectionID, documentID string
+	add                      bool
+}
+
+// ApplyBatch applies ops in order, as near all or nothing as the stores
+// allow. Every operation is checked, and the resulting documents worked
+// out, before anything is written, so a bad operation is a *BatchError and
+// changes nothing. The writes themselves are not one transaction: if one
+// fails, those already made are undone by writing the documents and
+// memberships back as they were, and the error returned. So other readers
+// may see part of a batch while it is applied, and a crash, or a failure
+// while undoing, can leave part of it in place.
+func ApplyBatch(s LibraryStore, ops []BatchOperation) (*BatchResult, error) {
+	originals := map[string]*Document{}
+	docs := map[string]*Document{}
+	var order []string
+	load := func(id string) (*Document, error) {
+		if doc := docs[id]; doc != nil {
+			return doc, nil
+		}
+		doc, err := s.GetDocument(id)
+		if err != nil {
+			return nil, err
+		}
+		if doc == nil {
+			return nil, fmt.Errorf("%w: %s", ErrDocumentNotFound, id)
+		}
+		// A second copy keeps the stored state for rolling back
+		original, err := s.GetDocument(id)
+		if err != nil {
+			return nil, err
+		}
+		docs[id], originals[id] = doc, original
+		order = append(order, id)
+		return doc, nil
+	}
+
+	members := map[string][]string{} // collection ID -> document IDs, as the batch leaves them
+	var memberships []batchMembership
+	var collectionOrder []string
+
+	for i, op := range ops {
+		fail := func(format string, args ...any) error {
+			return &BatchError{Index: i + 1, Op: op.Op, Err: fmt.Errorf(format, args...)}
+		}
+		if len(op.IDs) == 0 {
+			return nil, fail("ids is required")
+		}
+
+		switch op.Op {
+		case BatchAddTags, BatchRemoveTags:
+			var tags []string
+			for _, t := range op.Tags {
+				if t = strings.TrimSpace(t); t != "" {
+					tags = append(tags, t)
+				}
+			}
+			if len(tags) == 0 {
+				return nil, fail("tags is required")
+			}
+			for _, id := range op.IDs {
+				doc, err := load(id)
+				if err != nil {
+					return nil, fail("%v", err)
+				}
+				for _, tag := range tags {
+					has := slices.IndexFunc(doc.Tags, func(t string) bool { return strings.EqualFold(t, tag) })
+					if op.Op == BatchAddTags && has < 0 {
+						doc.Tags = append(doc.Tags, tag)
+					} else if op.Op == BatchRemoveTags && has >= 0 {
+						doc.Tags = slices.DeleteFunc(doc.Tags, func(t string) bool { return strings.EqualFold(t, tag) })
+					}
+				}
+			}
+
+		case BatchSetStatus:
+			status := ReadingStatus(op.Status)
+			switch status {
+			case StatusUnread, StatusReading, StatusCompleted, StatusArchived:
+			default:
+				return nil, fail("status must be unread, reading, completed, or archived")
+			}
+			for _, id := range op.IDs {
+				doc, err := load(id)
+				if err != nil {
+					return nil, fail("%v", err)
+				}
+				if status == StatusCompleted && doc.Status != StatusCompleted {
+					// Completion date drives "recent --read", as in the TUI
+					doc.ReadAt = Now()
+				}
+				doc.Status = status
+			}
+
+		case BatchAddToCollection, BatchRemoveFromCollection:
+			if op.Collection == "" {
+				return nil, fail("collection is required")
+			}
+			c, err := s.GetCollection(op.Collection)
+			if err != nil {
+				return nil, fail("%v", err)
+			}
+			if c == nil {
+				return nil, fail("collection not found: %s", op.Collection)
+			}
+			if _, ok := members[c.ID]; !ok {
+				members[c.ID] = slices.Clone(c.DocumentIDs)
+				collectionOrder = append(collectionOrder, c.ID)
+			}
+			for _, id := range op.IDs {
+				if _, err := load(id); err != nil {
+					return nil, fail("%v", err)
+				}
+				in := slices.Contains(members[c.ID], id)
+				add := op.Op == BatchAddToCollection
+				if in == add {
+					continue
+				}
+				if add {
+					members[c.ID] = append(members[c.ID], id)
+				} else {
+					members[c.ID] = slices.DeleteFunc(members[c.ID], func(d string) bool { return d == id })
+				}
+				memberships = append(memberships, batchMembership{c.ID, id, add})
+			}
+
+		default:
+			return nil, fail("unknown operation (use %s, %s, %s, %s, or %s)",
+				BatchAddTags, BatchRemoveTags, BatchAddToCollection, BatchRemoveFromCollection, BatchSetStatus)
+		}
+	}
+
+	result := &BatchResult{Operations: len(ops), Documents: []string{}, Collections: []string{}}
+	var written []string
+	var applied []batchMembership
+	rollback := func(err error) (*BatchResult, error) {
+		for _, id := range written {
+			if rerr := s.UpdateDocument(originals[id]); rerr != nil {
+				return nil, fmt.Errorf("%w; rolling back %s also failed: %v", err, id, rerr)
+			}
+		}
+		for i := len(applied) - 1; i >= 0; i-- {
+			m := applied[i]
+			var rerr error
+			if m.add {
+				rerr = s.RemoveFromCollection(m.collectionID, m.documentID)
+			} else {
+				rerr = s.AddToCollection(m.collectionID, m.documentID)
+			}
+			if rerr != nil {
+				return nil, fmt.Errorf("%w; rolling back collection %s also failed: %v", err, m.collectionID, rerr)
+			}
+		}
+		return nil, fmt.Errorf("%w (batch rolled back)", err)
+	}
+
+	for _, id := range order {
+		doc, original := docs[id], originals[id]
+		if doc.Status == original.Status && doc.ReadAt.Equal(original.ReadAt) && slices.Equal(doc.Tags, original.Tags) {
+			continue
+		}
+		if err := s.UpdateDocument(doc); err != nil {
+			return rollback(fmt.Errorf("update %s: %w", id, err))
+		}
+		written = append(written, id)
+		result.Documents = append(result.Documents, id)
+	}
+	for _, m := range memberships {
+		var err error
+		if m.add {
+			err = s.AddToCollection(m.collectionID, m.documentID)
+		} else {
+			err = s.RemoveFromCollection(m.collectionID, m.documentID)
+		}
+		if err != nil {
+			return rollback(fmt.Errorf("collection %s: %w", m.collectionID, err))
+		}
+		applied = append(applied, m)
+	}
+	for _, id := range collectionOrder {
+		if slices.ContainsFunc(applied, func(m batchMembership) bool { return m.collectionID == id }) {
+			result.Collections = append(result.Collections, id)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+// failingStore fails UpdateDocument for one document.
+type failingStore struct {
+	LibraryStore
+	failID string
+}
+
+func (s *failingStore) UpdateDocument(doc *Document) error {
+	if doc.ID == s.failID {
+		return errors.New("disk full")
+	}
+	return s.LibraryStore.UpdateDocument(doc)
+}
+
+func TestApplyBatch(t *testing.T) {
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &Document{Title: "A", Tags: []string{"ml"}}
+	b := &Document{Title: "B"}
+	for _, d := range []*Document{a, b} {
+		if err := s.AddDocument(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	from, _ := s.CreateCollection("inbox", "")
+	to, _ := s.CreateCollection("thesis", "")
+	s.AddToCollection(from.ID, a.ID)
+
+	result, err := ApplyBatch(s, []BatchOperation{
+		{Op: BatchAddTags, IDs: []string{a.ID, b.ID}, Tags: []string{"ML", "nlp"}},
+		{Op: BatchRemoveTags, IDs: []string{b.ID}, Tags: []string{"nlp"}},
+		{Op: BatchSetStatus, IDs: []string{a.ID}, Status: "reading"},
+		{Op: BatchRemoveFromCollection, IDs: []string{a.ID}, Collection: "inbox"},
+		{Op: BatchAddToCollection, IDs: []string{a.ID}, Collection: "thesis"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Operations != 5 || !slices.Equal(result.Documents, []string{a.ID, b.ID}) || !slices.Equal(result.Collections, []string{from.ID, to.ID}) {
+		t.Errorf("result = %+v", result)
+	}
+	gotA, _ := s.GetDocument(a.ID)
+	gotB, _ := s.GetDocument(b.ID)
+	if !slices.Equal(gotA.Tags, []string{"ml", "nlp"}) || gotA.Status != StatusReading || !slices.Equal(gotB.Tags, []string{"ML"}) {
+		t.Errorf("a = %q %s, b = %q", gotA.Tags, gotA.Status, gotB.Tags)
+	}
+	if !gotA.ReadAt.IsZero() {
+		t.Errorf("a read at %v while reading", gotA.ReadAt)
+	}
+	from, _ = s.GetCollection("inbox")
+	to, _ = s.GetCollection("thesis")
+	if len(from.DocumentIDs) != 0 || !slices.Equal(to.DocumentIDs, []string{a.ID}) {
+		t.Errorf("inbox %v, thesis %v", from.DocumentIDs, to.DocumentIDs)
+	}
+
+	// A bad operation anywhere changes nothing
+	for _, ops := range [][]BatchOperation{
+		{{Op: BatchAddTags, IDs: []string{b.ID}, Tags: []string{"x"}}, {Op: BatchSetStatus, IDs: []string{b.ID}, Status: "skimmed"}},
+		{{Op: BatchAddTags, IDs: []string{b.ID}, Tags: []string{"x"}}, {Op: BatchAddTags, IDs: []string{"missing"}, Tags: []string{"x"}}},
+		{{Op: BatchAddTags, IDs: []string{b.ID}, Tags: []string{"x"}}, {Op: BatchAddToCollection, IDs: []string{b.ID}, Collection: "nope"}},
+		{{Op: BatchAddTags, IDs: []string{b.ID}, Tags: []string{" "}}},
+		{{Op: "delete", IDs: []string{b.ID}}},
+		{{Op: BatchSetStatus, Status: "reading"}},
+	} {
+		var batchErr *BatchError
+		if _, err := ApplyBatch(s, ops); !errors.As(err, &batchErr) {
+			t.Errorf("%+v: error %v, want a BatchError", ops, err)
+		}
+	}
+	if got, _ := s.GetDocument(b.ID); !slices.Equal(got.Tags, []string{"ML"}) || got.Status != "" {
+		t.Errorf("b changed by a refused batch: %q %s", got.Tags, got.Status)
+	}
+
+	// A failed write puts back what was written before it
+	_, err = ApplyBatch(&failingStore{LibraryStore: s, failID: b.ID}, []BatchOperation{
+		{Op: BatchAddTags, IDs: []string{a.ID, b.ID}, Tags: []string{"draft"}},
+		{Op: BatchAddToCollection, IDs: []string{b.ID}, Collection: "thesis"},
+	})
+	var batchErr *BatchError
+	if err == nil || errors.As(err, &batchErr) {
+		t.Fatalf("failed write: error %v", err)
+	}
+	if got, _ := s.GetDocument(a.ID); slices.Contains(got.Tags, "draft") {
+		t.Errorf("a kept tags %q after rollback", got.Tags)
+	}
+	if to, _ = s.GetCollection("thesis"); slices.Contains(to.DocumentIDs, b.ID) {
+		t.Errorf("thesis kept b after rollback: %v", to.DocumentIDs)
+	}
+
+	// Completing a document dates it
+	if _, err := ApplyBatch(s, []BatchOperation{{Op: BatchSetStatus, IDs: []string{b.ID}, Status: "completed"}}); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.GetDocument(b.ID); got.Status != StatusCompleted || got.ReadAt.IsZero() {
+		t.Errorf("b completed: %s, read at %v", got.Status, got.ReadAt)
+	}
+}
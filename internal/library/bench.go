@@ -0,0 +1,207 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+	"time"
+)
+
+// benchWords are the words fixture text is drawn from, most frequent
+// first: they are picked with a Zipf distribution, as words in real text
+// are, so searches for early words match many documents and searches for
+// late ones few.
+var benchWords = strings.Fields(`
+	learning model data network neural training results method analysis
+	system performance approach deep algorithm structure function theory
+	graph attention language representation optimization inference
+	distribution probability gradient sampling estimation regression
+	classification clustering embedding transformer convolution recurrent
+	reinforcement policy reward agent environment simulation protein
+	molecule genome sequence expression cell tissue brain cortex neuron
+	signal spectrum frequency quantum entanglement photon lattice spin
+	magnetic thermal fluid turbulence climate ocean carbon emission energy
+	battery catalyst polymer crystal semiconductor transistor circuit
+	compiler database query index transaction consensus protocol latency
+	bandwidth cache memory storage encryption privacy adversarial robust
+	causal counterfactual bayesian variational stochastic convex sparse
+	kernel manifold topology geometry algebra category proof theorem
+	lemma conjecture prime polynomial matrix tensor eigenvalue spectral
+	wavelet fourier differential equation boundary stability bifurcation
+	chaos entropy information channel coding compression retrieval ranking
+	recommendation dialogue translation summarization parsing grammar
+	syntax semantics pragmatics phonology morphology corpus annotation
+	benchmark evaluation ablation baseline scaling pretraining finetuning
+	distillation pruning quantization hardware accelerator robotics
+	navigation perception vision segmentation detection tracking depth
+	pose rendering illumination texture shape mesh point cloud lidar radar
+	satellite telescope galaxy supernova exoplanet cosmology inflation
+	epidemiology vaccine trial cohort survival biomarker diagnosis imaging
+	economics market auction equilibrium incentive mechanism voting policy
+`)
+
+var (
+	benchAuthors = []string{"Ada Lovelace", "Alan Turing", "Grace Hopper", "Claude Shannon", "John von Neumann",
+		"Emmy Noether", "Kurt Gödel", "Barbara Liskov", "Donald Knuth", "Edsger Dijkstra", "Leslie Lamport",
+		"Frances Allen", "Judea Pearl", "Geoffrey Hinton", "Yoshua Bengio", "Fei-Fei Li", "Daphne Koller",
+		"Richard Feynman", "Marie Curie", "Rosalind Franklin", "Lise Meitner", "Srinivasa Ramanujan"}
+	benchTags     = strings.Fields("ml nlp vision theory systems physics biology chemistry math economics survey to-read important reviewed thesis reproduce teaching datasets")
+	benchTypes    = []DocumentType{DocTypePaper, DocTypePaper, DocTypePaper, DocTypeBook, DocTypeArticle, DocTypeNote}
+	benchStatuses = []ReadingStatus{"", "", StatusUnread, StatusReading, StatusCompleted}
+)
+
+// FixtureDocuments returns n made-up documents, the same ones for the same
+// seed, for filling a library to measure it with: titles, authors,
+// abstracts, about 250 words of full text, tags, statuses, and dates
+// spread over five years.
+func FixtureDocuments(n int, seed int64) []*Document {
+	rng := rand.New(rand.NewSource(seed))
+	zipf := rand.NewZipf(rng, 1.1, 4, uint64(len(benchWords)-1))
+	words := func(n int) string {
+		w := make([]string, n)
+		for i := range w {
+			w[i] = benchWords[zipf.Uint64()]
+		}
+		return strings.Join(w, " ")
+	}
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	docs := make([]*Document, n)
+	for i := range docs {
+		title := words(4 + rng.Intn(5))
+		doc := &Document{
+			Type:      benchTypes[rng.Intn(len(benchTypes))],
+			Path:      fmt.Sprintf("/bench/%07d.pdf", i),
+			Source:    "bench",
+			SourceID:  fmt.Sprintf("bench-%07d", i),
+			Title:     strings.ToUpper(title[:1]) + title[1:],
+			Abstract:  words(60),
+			FullText:  words(250),
+			Status:    benchStatuses[rng.Intn(len(benchStatuses))],
+			Rating:    rng.Intn(6),
+			CreatedAt: start.Add(time.Duration(rng.Int63n(int64(5 * 365 * 24 * time.Hour)))),
+			Meta:      JSONMap{"year": 1990 + rng.Intn(36), "venue": words(2)},
+		}
+		for range 1 + rng.Intn(4) {
+			doc.Authors = append(doc.Authors, benchAuthors[rng.Intn(len(benchAuthors))])
+		}
+		for range rng.Intn(4) {
+			if tag := benchTags[rng.Intn(len(benchTags))]; !slices.Contains(doc.Tags, tag) {
+				doc.Tags = append(doc.Tags, tag)
+			}
+		}
+		if doc.Status == StatusCompleted {
+			doc.ReadAt = doc.CreatedAt.Add(time.Duration(rng.Int63n(int64(90 * 24 * time.Hour))))
+		}
+		docs[i] = doc
+	}
+	return docs
+}
+
+// BenchQueries returns searches over fixture text, from words nearly every
+// document has to words few have.
+func BenchQueries() []string {
+	return []string{benchWords[0], benchWords[9], benchWords[40], benchWords[150], benchWords[len(benchWords)-1]}
+}
+
+// BenchOptions says what RunBench measures.
+type BenchOptions struct {
+	Queries    int                   // times each read is repeated
+	Bulk       bool                  // add documents with AddDocuments, benchBulkBatch at a time
+	OnProgress func(done, total int) // called as documents are added; may be nil
+}
+
+// benchBulkBatch is how many documents a bulk import adds at once.
+const benchBulkBatch = 1000
+
+// BenchResult is how long one operation took, repeated Count times.
+type BenchResult struct {
+	Operation string        `json:"operation"` // import, get, list, list-tag, search
+	Count     int           `json:"count"`
+	Elapsed   time.Duration `json:"elapsed_ns"`
+}
+
+// PerSecond returns how many operations were done a second.
+func (r BenchResult) PerSecond() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.Count) / r.Elapsed.Seconds()
+}
+
+// RunBench adds docs to s, which should hold nothing else, one by one or
+// with opts.Bulk in batches, then times reading them back: documents by ID, listings of 50 with and
+// without a tag filter, and full-text searches (see BenchQueries).
+func RunBench(s LibraryStore, docs []*Document, opts BenchOptions) ([]BenchResult, error) {
+	if opts.Queries <= 0 {
+		opts.Queries = 20
+	}
+	var results []BenchResult
+	timed := func(op string, count int, fn func(i int) error) error {
+		start := time.Now()
+		for i := range count {
+			if err := fn(i); err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+		}
+		results = append(results, BenchResult{Operation: op, Count: count, Elapsed: time.Since(start)})
+		return nil
+	}
+
+	err := timed("import", len(docs), func(i int) error {
+		if opts.OnProgress != nil {
+			opts.OnProgress(i, len(docs))
+		}
+		if !opts.Bulk {
+			return s.AddDocument(docs[i])
+		}
+		if i%benchBulkBatch != 0 {
+			return nil
+		}
+		return s.AddDocuments(docs[i:min(i+benchBulkBatch, len(docs))])
+	})
+	if err != nil {
+		return nil, err
+	}
+	if opts.OnProgress != nil {
+		opts.OnProgress(len(docs), len(docs))
+	}
+	if len(docs) == 0 {
+		return results, nil
+	}
+
+	// Reads are spread over the library the same way each run
+	rng := rand.New(rand.NewSource(1))
+	queries := BenchQueries()
+	steps := []struct {
+		op string
+		fn func(i int) error
+	}{
+		{"get", func(i int) error {
+			_, err := s.GetDocument(docs[rng.Intn(len(docs))].ID)
+			return err
+		}},
+		{"list", func(i int) error {
+			_, err := s.ListDocuments(&ListOptions{Limit: 50})
+			return err
+		}},
+		{"list-tag", func(i int) error {
+			_, err := s.ListDocuments(&ListOptions{Tag: benchTags[i%len(benchTags)], Limit: 50})
+			return err
+		}},
+		{"search", func(i int) error {
+			_, err := s.ListDocuments(&ListOptions{Search: queries[i%len(queries)], Limit: 50})
+			return err
+		}},
+	}
+	for _, step := range steps {
+		if err := timed(step.op, opts.Queries, step.fn); err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
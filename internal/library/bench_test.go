@@ -0,0 +1,158 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+// benchLibrarySize is how many fixture documents the read benchmarks
+// search through. "arc-library bench" measures larger libraries.
+const benchLibrarySize = 2000
+
+// benchBackends opens an empty store of each kind.
+var benchBackends = []struct {
+	name string
+	open func(tb testing.TB) LibraryStore
+}{
+	{"sql", func(tb testing.TB) LibraryStore {
+		db, err := sql.Open("sqlite", filepath.Join(tb.TempDir(), "library.db"))
+		if err != nil {
+			tb.Fatal(err)
+		}
+		tb.Cleanup(func() { db.Close() })
+		s, err := NewStore(db)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		return s
+	}},
+	{"kv", func(tb testing.TB) LibraryStore {
+		s, err := NewKVStore(store.NewMemoryStore())
+		if err != nil {
+			tb.Fatal(err)
+		}
+		return s
+	}},
+}
+
+// benchLibrary opens a store holding n fixture documents.
+func benchLibrary(tb testing.TB, open func(testing.TB) LibraryStore, n int) (LibraryStore, []*Document) {
+	s := open(tb)
+	docs := FixtureDocuments(n, 1)
+	for _, doc := range docs {
+		if err := s.AddDocument(doc); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	return s, docs
+}
+
+func TestFixtureDocuments(t *testing.T) {
+	a, b := FixtureDocuments(50, 7), FixtureDocuments(50, 7)
+	if !reflect.DeepEqual(a, b) {
+		t.Error("fixtures differ for the same seed")
+	}
+	if reflect.DeepEqual(a, FixtureDocuments(50, 8)) {
+		t.Error("fixtures the same for another seed")
+	}
+	for _, doc := range a {
+		if doc.Title == "" || len(doc.Authors) == 0 || doc.FullText == "" || doc.CreatedAt.IsZero() {
+			t.Fatalf("incomplete fixture: %+v", doc)
+		}
+	}
+}
+
+func TestRunBench(t *testing.T) {
+	for _, backend := range benchBackends {
+		t.Run(backend.name, func(t *testing.T) {
+			s := backend.open(t)
+			docs := FixtureDocuments(100, 1)
+			progress := 0
+			results, err := RunBench(s, docs, BenchOptions{Queries: 5, OnProgress: func(done, total int) { progress = done }})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ops []string
+			for _, r := range results {
+				ops = append(ops, r.Operation)
+				if r.Elapsed <= 0 || r.PerSecond() <= 0 {
+					t.Errorf("%s: %+v", r.Operation, r)
+				}
+			}
+			if want := []string{"import", "get", "list", "list-tag", "search"}; !reflect.DeepEqual(ops, want) {
+				t.Errorf("operations = %v, want %v", ops, want)
+			}
+			if results[0].Count != 100 || progress != 100 {
+				t.Errorf("imported %d, progress %d", results[0].Count, progress)
+			}
+			if found, err := s.ListDocuments(&ListOptions{Search: BenchQueries()[0]}); err != nil || len(found) == 0 {
+				t.Errorf("search for the most common word found %d, %v", len(found), err)
+			}
+		})
+	}
+}
+
+func BenchmarkAddDocument(b *testing.B) {
+	for _, backend := range benchBackends {
+		b.Run(backend.name, func(b *testing.B) {
+			s := backend.open(b)
+			docs := FixtureDocuments(b.N, 1)
+			b.ResetTimer()
+			for i := range b.N {
+				if err := s.AddDocument(docs[i]); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkGetDocument(b *testing.B) {
+	for _, backend := range benchBackends {
+		b.Run(backend.name, func(b *testing.B) {
+			s, docs := benchLibrary(b, backend.open, benchLibrarySize)
+			b.ResetTimer()
+			for i := range b.N {
+				if _, err := s.GetDocument(docs[i%len(docs)].ID); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkListDocuments(b *testing.B) {
+	for _, backend := range benchBackends {
+		b.Run(backend.name, func(b *testing.B) {
+			s, _ := benchLibrary(b, backend.open, benchLibrarySize)
+			b.ResetTimer()
+			for i := range b.N {
+				if _, err := s.ListDocuments(&ListOptions{Tag: benchTags[i%len(benchTags)], Limit: 50}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkSearch(b *testing.B) {
+	queries := BenchQueries()
+	for _, backend := range benchBackends {
+		b.Run(backend.name, func(b *testing.B) {
+			s, _ := benchLibrary(b, backend.open, benchLibrarySize)
+			b.ResetTimer()
+			for i := range b.N {
+				if _, err := s.ListDocuments(&ListOptions{Search: queries[i%len(queries)], Limit: 50}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,376 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func init() { RegisterExporter(bibtexExporter{}) }
+
+// bibtexExporter writes BibTeX entries for LaTeX and BibLaTeX.
+type bibtexExporter struct{}
+
+func (bibtexExporter) Format() Format {
+	return Format{
+		Name:         "bibtex",
+		Description:  "BibTeX for LaTeX and BibLaTeX",
+		Extensions:   []string{".bib"},
+		Capabilities: []string{FormatTags, FormatFiles},
+	}
+}
+
+// bibtexEntryTypes maps Meta["venue_type"] to the BibTeX entry type. A
+// document without one is an article if it is a paper, a book if it is a
+// book, and misc otherwise.
+var bibtexEntryTypes = map[string]string{
+	"article":       "article",
+	"journal":       "article",
+	"conference":    "inproceedings",
+	"proceedings":   "inproceedings",
+	"inproceedings": "inproceedings",
+	"workshop":      "inproceedings",
+	"techreport":    "techreport",
+	"report":        "techreport",
+	"book":          "book",
+	"chapter":       "incollection",
+	"incollection":  "incollection",
+	"thesis":        "phdthesis",
+	"phdthesis":     "phdthesis",
+	"dissertation":  "phdthesis",
+	"mastersthesis": "mastersthesis",
+	"preprint":      "misc",
+	"misc":          "misc",
+	"web":           "misc",
+}
+
+// bibtexField is a field of a BibTeX entry: where its value comes from,
+// for which entry types (all when nil), and whether it is written as is
+// rather than escaped for LaTeX, as for URLs and paths.
+type bibtexField struct {
+	name     string
+	types    []string
+	verbatim bool
+	value    func(*Document) string
+}
+
+// bibtexFields are the fields BibTeX export writes, in order. Fields
+// taking their value from Meta fall back through the keys that importers
+// and "field set" use for the same thing.
+var bibtexFields = []bibtexField{
+	{name: "title", value: func(d *Document) string { return d.Title }},
+	{name: "author", value: func(d *Document) string { return strings.Join(d.Authors, " and ") }},
+	{name: "journal", types: []string{"article"}, value: metaValue("journal", "venue")},
+	{name: "booktitle", types: []string{"inproceedings", "incollection"}, value: metaValue("booktitle", "venue", "journal")},
+	{name: "institution", types: []string{"techreport"}, value: metaValue("institution", "publisher")},
+	{name: "school", types: []string{"phdthesis", "mastersthesis"}, value: metaValue("school", "institution")},
+	{name: "publisher", types: []string{"book", "incollection", "inproceedings"}, value: metaValue("publisher")},
+	{name: "year", value: func(d *Document) string {
+		if y := DocumentYear(d); y > 0 {
+			return strconv.Itoa(y)
+		}
+		return ""
+	}},
+	{name: "volume", value: metaValue("volume")},
+	{name: "number", value: metaValue("issue", "number", "report_number")},
+	// A book's "pages" from Open Library is its page count, not a range
+	{name: "pages", types: []string{"article", "inproceedings", "incollection", "techreport", "misc"}, value: func(d *Document) string {
+		return pageRange(metaValue("pages", "page")(d))
+	}},
+	{name: "doi", verbatim: true, value: documentDOI},
+	{name: "eprint", verbatim: true, value: documentArxivID},
+	{name: "archivePrefix", value: func(d *Document) string {
+		if documentArxivID(d) != "" {
+			return "arXiv"
+		}
+		return ""
+	}},
+	{name: "isbn", value: metaValue("isbn")},
+	{name: "issn", value: metaValue("issn")},
+	{name: "url", verbatim: true, value: metaValue("url")},
+	{name: "abstract", value: func(d *Document) string { return d.Abstract }},
+	{name: "keywords", value: func(d *Document) string { return strings.Join(d.Tags, ", ") }},
+	{name: "file", verbatim: true, value: func(d *Document) string {
+		if d.Path == "" {
+			return ""
+		}
+		return DocumentPath(d)
+	}},
+}
+
+func (bibtexExporter) Export(w io.Writer, _ LibraryStore, docs []*Document, _ ExportOptions) error {
+	var buf bytes.Buffer
+	keys := CiteKeys(docs)
+	for i, doc := range docs {
+		writeBibTeXEntry(&buf, doc, keys[i])
+		buf.WriteString("\n")
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// writeBibTeXEntry writes doc as an entry with the given key.
+func writeBibTeXEntry(buf *bytes.Buffer, doc *Document, key string) {
+	entryType := BibTeXEntryType(doc)
+	buf.WriteString(fmt.Sprintf("@%s{%s", entryType, key))
+	for _, f := range bibtexFields {
+		if f.types != nil && !slices.Contains(f.types, entryType) {
+			continue
+		}
+		v := strings.TrimSpace(f.value(doc))
+		if v == "" {
+			continue
+		}
+		if !f.verbatim {
+			v = escapeBibTeX(v)
+		}
+		buf.WriteString(fmt.Sprintf(",\n  %s = {%s}", f.name, v))
+	}
+	buf.WriteString("\n}\n")
+}
+
+// BibTeXEntryType returns the entry type a document is exported as, from
+// its Meta["venue_type"] or else its document type. Documents without a
+// type date from when the library held only papers.
+func BibTeXEntryType(doc *Document) string {
+	if vt := strings.ToLower(strings.TrimSpace(metaValue("venue_type")(doc))); vt != "" {
+		if t, ok := bibtexEntryTypes[vt]; ok {
+			return t
+		}
+	}
+	switch doc.Type {
+	case DocTypePaper, "":
+		return "article"
+	case DocTypeBook:
+		return "book"
+	}
+	return "misc"
+}
+
+// metaValue returns a function reading the first of keys set in a
+// document's Meta as text. Numbers are written without a fraction, as
+// JSON decoding turns a stored year into a float64.
+func metaValue(keys ...string) func(*Document) string {
+	return func(d *Document) string {
+		for _, k := range keys {
+			switch v := d.Meta[k].(type) {
+			case string:
+				if v != "" {
+					return v
+				}
+			case int:
+				return strconv.Itoa(v)
+			case int64:
+				return strconv.FormatInt(v, 10)
+			case float64:
+				return strconv.FormatFloat(v, 'f', -1, 64)
+			}
+		}
+		return ""
+	}
+}
+
+// DocumentYear returns the year a document was published, from its
+// Meta["year"], or 0 when it is unknown. The year the document was added
+// to the library is not a publication year, so it is never used instead.
+func DocumentYear(doc *Document) int {
+	switch v := doc.Meta["year"].(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	case string:
+		y, _ := strconv.Atoi(strings.TrimSpace(v))
+		return y
+	}
+	return 0
+}
+
+// documentArxivID returns a document's arXiv ID, from its source or its
+// Meta["arxiv"].
+func documentArxivID(doc *Document) string {
+	if doc.Source == IDSourceArxiv && doc.SourceID != "" {
+		return doc.SourceID
+	}
+	id, _ := doc.Meta["arxiv"].(string)
+	return id
+}
+
+var pageRangePattern = regexp.MustCompile(`^\s*(\w+)\s*[-\x{2013}\x{2014}]+\s*(\w+)\s*$`)
+
+// pageRange writes a page range "12-20" as BibTeX's "12--20".
+func pageRange(pages string) string {
+	if m := pageRangePattern.FindStringSubmatch(pages); m != nil {
+		return m[1] + "--" + m[2]
+	}
+	return strings.TrimSpace(pages)
+}
+
+// bibtexEscaper escapes the characters LaTeX treats specially.
+var bibtexEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`&`, `\&`,
+	`%`, `\%`,
+	`$`, `\$`,
+	`#`, `\#`,
+	`_`, `\_`,
+	`~`, `\textasciitilde{}`,
+	`^`, `\textasciicircum{}`,
+)
+
+// escapeBibTeX escapes special characters for BibTeX.
+func escapeBibTeX(s string) string {
+	return bibtexEscaper.Replace(s)
+}
+
+// CiteKey is the key a document is cited by, as in BibTeX export: its
+// meta "citekey" when set, its arXiv ID or DOI when it came from arXiv
+// or a DOI, and otherwise the first author's family name, the year, and
+// the first word of the title that is not a stop word, as in
+// vaswani2017attention. Without an author the title word comes first.
+// Keys are lower case ASCII, so the same document always gets the same
+// key; CiteKeys tells apart documents that share one.
+func CiteKey(doc *Document) string {
+	if key, ok := doc.Meta["citekey"].(string); ok && key != "" {
+		return key
+	}
+	if doc.Source == IDSourceArxiv && doc.SourceID != "" {
+		return doc.SourceID
+	}
+	if doc.Source == IDSourceDOI && doc.SourceID != "" {
+		return strings.ReplaceAll(doc.SourceID, "/", "_")
+	}
+
+	var author string
+	if len(doc.Authors) > 0 {
+		author = citeKeyWord(familyName(doc.Authors[0]))
+	}
+	var word string
+	for _, w := range strings.Fields(doc.Title) {
+		w = citeKeyWord(w)
+		if w != "" && !citeKeyStopWords[w] {
+			word = w
+			break
+		}
+	}
+	year := ""
+	if y := DocumentYear(doc); y > 0 {
+		year = strconv.Itoa(y)
+	}
+
+	key := author + year + word
+	if author == "" {
+		key = word + year
+	}
+	if key == "" {
+		return "doc" + citeKeyWord(doc.ID)
+	}
+	return key
+}
+
+// legacyCiteKey is the key CiteKey gave before it used family names and
+// title words: the first word of the first author's name and the year.
+// Notes written then still link by it.
+func legacyCiteKey(doc *Document) string {
+	key := "unknown"
+	if len(doc.Authors) > 0 {
+		if parts := strings.Fields(doc.Authors[0]); len(parts) > 0 {
+			key = strings.ToLower(parts[0])
+		}
+	}
+	if y := DocumentYear(doc); y > 0 {
+		key += strconv.Itoa(y)
+	}
+	return key
+}
+
+// CiteKeys returns the cite key of each document in docs, with a letter
+// after keys that several of them share (vaswani2017attentiona,
+// vaswani2017attentionb), given in the order of the documents' IDs so the
+// same library always exports the same keys. Keys set in meta "citekey"
+// are kept as they are.
+func CiteKeys(docs []*Document) []string {
+	keys := make([]string, len(docs))
+	byKey := map[string][]int{}
+	for i, d := range docs {
+		keys[i] = CiteKey(d)
+		if set, _ := d.Meta["citekey"].(string); set == "" {
+			byKey[keys[i]] = append(byKey[keys[i]], i)
+		}
+	}
+	for key, idx := range byKey {
+		if len(idx) < 2 {
+			continue
+		}
+		sort.Slice(idx, func(a, b int) bool { return docs[idx[a]].ID < docs[idx[b]].ID })
+		for n, i := range idx {
+			keys[i] = key + citeKeySuffix(n)
+		}
+	}
+	return keys
+}
+
+// citeKeySuffix is a, b, ..., z, aa, ab, ...
+func citeKeySuffix(n int) string {
+	s := ""
+	for n++; n > 0; n = (n - 1) / 26 {
+		s = string(rune('a'+(n-1)%26)) + s
+	}
+	return s
+}
+
+// familyName returns the family name in "Family, Given" or "Given Family".
+func familyName(name string) string {
+	if family, _, ok := strings.Cut(name, ","); ok {
+		return strings.TrimSpace(family)
+	}
+	parts := strings.Fields(name)
+	if len(parts) == 0 {
+		return ""
+	}
+	return parts[len(parts)-1]
+}
+
+var citeKeyStopWords = map[string]bool{
+	"a": true, "an": true, "the": true, "on": true, "of": true, "in": true,
+	"for": true, "and": true, "to": true, "with": true, "from": true, "towards": true,
+}
+
+// citeKeyFold spells common accented Latin letters without the accent.
+var citeKeyFold = strings.NewReplacer(
+	"à", "a", "á", "a", "â", "a", "ã", "a", "ä", "a", "å", "a", "ā", "a", "ą", "a",
+	"ç", "c", "ć", "c", "č", "c",
+	"è", "e", "é", "e", "ê", "e", "ë", "e", "ē", "e", "ę", "e", "ě", "e",
+	"ì", "i", "í", "i", "î", "i", "ï", "i", "ī", "i",
+	"ñ", "n", "ń", "n", "ň", "n",
+	"ò", "o", "ó", "o", "ô", "o", "õ", "o", "ö", "o", "ø", "o", "ō", "o", "ő", "o",
+	"ù", "u", "ú", "u", "û", "u", "ü", "u", "ū", "u", "ů", "u", "ű", "u",
+	"ý", "y", "ÿ", "y",
+	"ł", "l", "ś", "s", "š", "s", "ß", "ss", "ř", "r", "ž", "z", "ź", "z", "ż", "z",
+	"æ", "ae", "œ", "oe",
+)
+
+// citeKeyWord lower-cases a word and keeps only its ASCII letters and
+// digits, after dropping accents.
+func citeKeyWord(w string) string {
+	w = citeKeyFold.Replace(strings.ToLower(w))
+	var b strings.Builder
+	for _, r := range w {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
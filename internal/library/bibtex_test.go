@@ -0,0 +1,106 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite golden files in testdata")
+
+// checkGolden compares got with testdata/name, or rewrites the file when
+// the tests run with -update.
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s differs from the golden file (run with -update to accept):\n%s", name, got)
+	}
+}
+
+func TestBibTeXExportGolden(t *testing.T) {
+	docs := []*Document{
+		{
+			ID: "d1", Type: DocTypePaper, Title: "Attention Is All You Need",
+			Authors: []string{"Ashish Vaswani", "Noam Shazeer"},
+			Source:  IDSourceArxiv, SourceID: "1706.03762",
+			Tags: []string{"ml", "nlp"},
+			// Stored years come back from JSON as float64
+			Meta: JSONMap{"year": float64(2017), "venue_type": "conference", "venue": "NeurIPS", "pages": "5998-6008"},
+		},
+		{
+			ID: "d2", Type: DocTypePaper, Title: "Deep Residual Learning",
+			Authors: []string{"He, Kaiming"},
+			Source:  IDSourceDOI, SourceID: "10.1109/CVPR.2016.90",
+			Meta: JSONMap{"year": 2016, "journal": "CVPR", "volume": "1", "issue": "2"},
+		},
+		{
+			ID: "d3", Type: DocTypePaper, Title: "The State of Sparsity & Pruning: 50% Less",
+			Authors: []string{"Trevor Gale"},
+			Meta:    JSONMap{"year": "2019", "venue_type": "techreport", "institution": "Google Research", "report_number": "TR-19_02"},
+		},
+		{
+			ID: "d4", Type: DocTypePaper, Title: "Über Lernverfahren",
+			Authors: []string{"Jürgen Schmidhuber"},
+			Meta:    JSONMap{"year": 1990, "venue_type": "thesis", "school": "TU München"},
+		},
+		{
+			ID: "d5", Type: DocTypeBook, Title: "Deep Learning",
+			Authors: []string{"Ian Goodfellow", "Yoshua Bengio", "Aaron Courville"},
+			Meta:    JSONMap{"year": 2016, "publisher": "MIT Press", "isbn": "9780262035613", "pages": 800},
+		},
+		{
+			ID: "d6", Type: DocTypeArticle, Title: "Notes on the Transformer",
+			Meta: JSONMap{"url": "https://example.com/notes_on_transformers?x=1&y=2"},
+		},
+		// Two documents with the same natural key, in reverse ID order
+		{ID: "d8", Type: DocTypePaper, Title: "Scaling Laws", Authors: []string{"Jared Kaplan"}, Meta: JSONMap{"year": 2020}},
+		{ID: "d7", Type: DocTypePaper, Title: "Scaling Laws", Authors: []string{"Jared Kaplan"}, Meta: JSONMap{"year": 2020}},
+		{ID: "d9", Type: DocTypePaper, Title: "Kept", Meta: JSONMap{"citekey": "mykey"}},
+	}
+
+	var buf bytes.Buffer
+	if err := (bibtexExporter{}).Export(&buf, nil, docs, ExportOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "export.bib", buf.Bytes())
+}
+
+func TestCiteKey(t *testing.T) {
+	tests := []struct {
+		doc  *Document
+		want string
+	}{
+		{&Document{Authors: []string{"Ashish Vaswani"}, Title: "Attention Is All You Need", Meta: JSONMap{"year": float64(2017)}}, "vaswani2017attention"},
+		{&Document{Authors: []string{"Vaswani, Ashish"}, Title: "Attention", Meta: JSONMap{"year": 2017}}, "vaswani2017attention"},
+		{&Document{Authors: []string{"Jürgen Schmidhuber"}, Title: "On the Über-Net"}, "schmidhuberubernet"},
+		{&Document{Title: "A Survey of Surveys", Meta: JSONMap{"year": "2021"}}, "survey2021"},
+		{&Document{Source: IDSourceArxiv, SourceID: "1706.03762", Meta: JSONMap{"year": 2017}}, "1706.03762"},
+		{&Document{Source: IDSourceDOI, SourceID: "10.1000/xyz"}, "10.1000_xyz"},
+		{&Document{ID: "ab-12", Meta: JSONMap{"citekey": "Custom:Key"}}, "Custom:Key"},
+		{&Document{ID: "ab-12"}, "docab12"},
+	}
+	for _, tt := range tests {
+		if got := CiteKey(tt.doc); got != tt.want {
+			t.Errorf("CiteKey(%+v) = %q, want %q", tt.doc, got, tt.want)
+		}
+	}
+	if got := citeKeySuffix(26); got != "aa" {
+		t.Errorf("citeKeySuffix(26) = %q, want aa", got)
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// BibTeXMergeResult counts what MergeBibTeX did to a file's entries.
+type BibTeXMergeResult struct {
+	Updated int `json:"updated"` // entries rewritten from the library
+	Added   int `json:"added"`   // documents appended as new entries
+	Kept    int `json:"kept"`    // entries with keys the export does not have, left as written
+}
+
+// bibtexSpan is an entry of a BibTeX file: its key and where it starts
+// and ends.
+type bibtexSpan struct {
+	key        string
+	start, end int
+}
+
+// MergeBibTeX merges docs into the content of an existing BibTeX file, as
+// "export --merge" does to keep a manuscript's bibliography current. An
+// entry whose cite key, ignoring case, is that of one of docs is replaced
+// by the document's entry in place; documents without one are appended.
+// Everything else in the file, from entries typed in by hand to comments
+// and @string definitions, is kept byte for byte. A file that does not
+// parse is an error rather than something to overwrite.
+func MergeBibTeX(existing []byte, docs []*Document) ([]byte, BibTeXMergeResult, error) {
+	var res BibTeXMergeResult
+	spans, err := scanBibTeXEntries(existing)
+	if err != nil {
+		return nil, res, err
+	}
+
+	keys := CiteKeys(docs)
+	entries := make(map[string]string, len(docs))
+	for i, doc := range docs {
+		var buf bytes.Buffer
+		writeBibTeXEntry(&buf, doc, keys[i])
+		entries[strings.ToLower(keys[i])] = strings.TrimSuffix(buf.String(), "\n")
+	}
+
+	var out bytes.Buffer
+	merged := map[string]bool{}
+	last := 0
+	for _, sp := range spans {
+		entry, ok := entries[strings.ToLower(sp.key)]
+		if !ok {
+			res.Kept++
+			continue
+		}
+		out.Write(existing[last:sp.start])
+		out.WriteString(entry)
+		last = sp.end
+		if !merged[strings.ToLower(sp.key)] {
+			merged[strings.ToLower(sp.key)] = true
+			res.Updated++
+		}
+	}
+	out.Write(existing[last:])
+
+	for _, key := range keys {
+		if merged[strings.ToLower(key)] {
+			continue
+		}
+		if out.Len() > 0 && !bytes.HasSuffix(out.Bytes(), []byte("\n\n")) {
+			if !bytes.HasSuffix(out.Bytes(), []byte("\n")) {
+				out.WriteString("\n")
+			}
+			out.WriteString("\n")
+		}
+		out.WriteString(entries[strings.ToLower(key)])
+		out.WriteString("\n")
+		res.Added++
+	}
+	return out.Bytes(), res, nil
+}
+
+// scanBibTeXEntries finds the entries of a BibTeX file, skipping @string,
+// @preamble, and @comment and the text between entries, which BibTeX
+// ignores. Braces inside an entry must balance, as BibTeX requires.
+func scanBibTeXEntries(data []byte) ([]bibtexSpan, error) {
+	var spans []bibtexSpan
+	for i := 0; i < len(data); i++ {
+		if data[i] != '@' {
+			continue
+		}
+		start := i
+		j := i + 1
+		for j < len(data) && isBibTeXNameByte(data[j]) {
+			j++
+		}
+		entryType := strings.ToLower(string(data[i+1 : j]))
+		for j < len(data) && isBibTeXSpace(data[j]) {
+			j++
+		}
+		if entryType == "" || j == len(data) || (data[j] != '{' && data[j] != '(') {
+			// An @ in free text, such as an email address in a comment
+			continue
+		}
+		open := data[j]
+		end, err := bibtexEntryEnd(data, j)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: @%s: %w", bytes.Count(data[:start], []byte("\n"))+1, entryType, err)
+		}
+		i = end - 1
+		switch entryType {
+		case "string", "preamble", "comment":
+			continue
+		}
+
+		body := data[j+1 : end-1]
+		key, _, ok := bytes.Cut(body, []byte(","))
+		if !ok && open == '{' {
+			key = body
+		}
+		spans = append(spans, bibtexSpan{key: strings.TrimSpace(string(key)), start: start, end: end})
+	}
+	return spans, nil
+}
+
+// bibtexEntryEnd returns the offset just past the delimiter closing the
+// one at data[open].
+func bibtexEntryEnd(data []byte, open int) (int, error) {
+	closer := byte('}')
+	if data[open] == '(' {
+		closer = ')'
+	}
+	depth := 0
+	for k := open + 1; k < len(data); k++ {
+		switch c := data[k]; {
+		case c == '{':
+			depth++
+		case c == '}' && depth > 0:
+			depth--
+		case c == closer && depth == 0:
+			return k + 1, nil
+		case c == '}':
+			return 0, fmt.Errorf("unbalanced braces")
+		}
+	}
+	return 0, fmt.Errorf("entry is not closed")
+}
+
+func isBibTeXNameByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+func isBibTeXSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMergeBibTeX(t *testing.T) {
+	const existing = `% Bibliography for the thesis, contact me@example.com
+@string{nips = {Advances in Neural Information Processing Systems}}
+
+@article{Vaswani2017attention,
+  title = {Old Title},
+  note = {{Nested} braces}
+}
+
+@misc{manual2020,
+  title = {Typed in by hand},
+  howpublished = nips
+}
+`
+	docs := []*Document{
+		{ID: "d1", Type: DocTypePaper, Title: "Attention Is All You Need", Authors: []string{"Ashish Vaswani"}, Meta: JSONMap{"year": 2017}},
+		{ID: "d2", Type: DocTypeBook, Title: "Deep Learning", Authors: []string{"Ian Goodfellow"}, Meta: JSONMap{"year": 2016}},
+	}
+	out, res, err := MergeBibTeX([]byte(existing), docs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res != (BibTeXMergeResult{Updated: 1, Added: 1, Kept: 1}) {
+		t.Errorf("result = %+v, want 1 updated, 1 added, 1 kept", res)
+	}
+	got := string(out)
+	for _, want := range []string{
+		"% Bibliography for the thesis, contact me@example.com\n@string{nips",
+		"@article{vaswani2017attention,\n  title = {Attention Is All You Need}",
+		"@misc{manual2020,\n  title = {Typed in by hand},\n  howpublished = nips\n}\n\n@book{goodfellow2016deep,",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("merged file lacks %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "Old Title") {
+		t.Errorf("matching entry not replaced:\n%s", got)
+	}
+
+	// Merging again changes nothing
+	again, res, err := MergeBibTeX(out, docs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != got || res.Added != 0 || res.Updated != 2 {
+		t.Errorf("second merge = %+v:\n%s", res, again)
+	}
+
+	if empty, res, _ := MergeBibTeX(nil, docs); res.Added != 2 || !strings.HasPrefix(string(empty), "@article{") {
+		t.Errorf("merge into an empty file = %+v:\n%s", res, empty)
+	}
+	if _, _, err := MergeBibTeX([]byte("@article{broken,\n  title = {Unclosed\n"), docs); err == nil {
+		t.Error("expected an error for an unclosed entry")
+	}
+}
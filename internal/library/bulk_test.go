@@ -0,0 +1,122 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"testing"
+)
+
+func TestAddDocuments(t *testing.T) {
+	for _, backend := range benchBackends {
+		t.Run(backend.name, func(t *testing.T) {
+			s := backend.open(t)
+			// More than one INSERT's worth
+			docs := FixtureDocuments(150, 1)
+			if err := s.AddDocuments(docs); err != nil {
+				t.Fatal(err)
+			}
+			ids := map[string]bool{}
+			for _, doc := range docs {
+				if doc.ID == "" || ids[doc.ID] || doc.CreatedAt.IsZero() {
+					t.Fatalf("added as %q, created %v", doc.ID, doc.CreatedAt)
+				}
+				ids[doc.ID] = true
+			}
+			all, err := s.ListDocuments(nil)
+			if err != nil || len(all) != len(docs) {
+				t.Fatalf("listed %d of %d, %v", len(all), len(docs), err)
+			}
+			got, err := s.GetDocument(docs[149].ID)
+			if err != nil || got == nil || got.Title != docs[149].Title || got.FullText != docs[149].FullText {
+				t.Errorf("read back %+v, %v", got, err)
+			}
+			counts, err := s.CountDocumentsByType()
+			if err != nil {
+				t.Fatal(err)
+			}
+			total := 0
+			for _, n := range counts {
+				total += n
+			}
+			if total != len(docs) {
+				t.Errorf("counted %d documents", total)
+			}
+			if err := s.AddDocuments(nil); err != nil {
+				t.Errorf("adding none: %v", err)
+			}
+
+			parent := &Annotation{DocumentID: docs[0].ID, Type: "highlight", Content: "First"}
+			if err := s.AddAnnotations([]*Annotation{parent, {DocumentID: docs[1].ID, Type: "note", Content: "Second"}}); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.AddAnnotations([]*Annotation{{DocumentID: docs[0].ID, Type: "note", Content: "Reply", ParentID: parent.ID}}); err != nil {
+				t.Fatal(err)
+			}
+			if anns, err := s.GetAnnotations(docs[0].ID); err != nil || len(anns) != 2 {
+				t.Errorf("annotations of the first = %d, %v", len(anns), err)
+			}
+			if n, err := s.CountAnnotations(); err != nil || n != 3 {
+				t.Errorf("counted %d annotations, %v", n, err)
+			}
+			if err := s.AddAnnotations([]*Annotation{{DocumentID: docs[2].ID, Type: "note", Content: "Fine"}, {DocumentID: docs[2].ID, Type: "highlight", Position: "not json"}}); err == nil {
+				t.Error("added an annotation with an invalid position")
+			}
+			if anns, _ := s.GetAnnotations(docs[2].ID); len(anns) != 0 {
+				t.Errorf("added %d annotations of an invalid batch", len(anns))
+			}
+
+			cards := []*Flashcard{
+				{DocumentID: docs[0].ID, Type: "basic", Front: "Q1", Back: "A1"},
+				{DocumentID: docs[0].ID, Type: "basic", Front: "Q2", Back: "A2"},
+			}
+			if err := s.AddFlashcards(cards); err != nil {
+				t.Fatal(err)
+			}
+			if cards[0].ID == cards[1].ID {
+				t.Errorf("flashcards share ID %s", cards[0].ID)
+			}
+			if listed, err := s.ListFlashcards(&FlashcardListOptions{DocumentID: docs[0].ID}); err != nil || len(listed) != 2 {
+				t.Errorf("listed %d flashcards, %v", len(listed), err)
+			}
+		})
+	}
+}
+
+func TestAddDocumentsAllOrNothing(t *testing.T) {
+	s := benchBackends[0].open(t)
+	docs := FixtureDocuments(100, 1)
+	docs[80].ID = "taken"
+	if err := s.AddDocument(&Document{ID: "taken", Title: "Already here"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddDocuments(docs); err == nil {
+		t.Fatal("added a document with a taken ID")
+	}
+	if all, _ := s.ListDocuments(nil); len(all) != 1 {
+		t.Errorf("%d documents after a failed batch", len(all))
+	}
+}
+
+func TestAuditedAddDocuments(t *testing.T) {
+	s := NewAuditedStore(benchBackends[1].open(t), "test")
+	if err := s.AddDocuments(FixtureDocuments(3, 1)); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := s.ListAuditEntries(&AuditListOptions{})
+	if err != nil || len(entries) != 3 {
+		t.Errorf("audited %d additions, %v", len(entries), err)
+	}
+
+	d := NewDryRunStore(s)
+	d.Enabled = true
+	if err := d.AddDocuments(FixtureDocuments(2, 2)); err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Changes) != 2 {
+		t.Errorf("held back %d changes", len(d.Changes))
+	}
+	if all, _ := s.ListDocuments(nil); len(all) != 3 {
+		t.Errorf("%d documents after a dry run", len(all))
+	}
+}
@@ -0,0 +1,238 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"container/list"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultDocumentCacheSize is how many documents a CachedStore keeps
+// unless ARC_LIBRARY_CACHE_SIZE says otherwise.
+const DefaultDocumentCacheSize = 1000
+
+// DocumentCacheMaxAge is how long a CachedStore serves a document before
+// reading it again. Writes through the store invalidate it at once; this
+// bounds how long changes made by other processes (a CLI command while
+// the web server runs) go unseen.
+const DocumentCacheMaxAge = 30 * time.Second
+
+// DocumentCacheSize reads how many documents to cache from
+// $ARC_LIBRARY_CACHE_SIZE; 0 turns the cache off.
+func DocumentCacheSize() (int, error) {
+	s := strings.TrimSpace(os.Getenv("ARC_LIBRARY_CACHE_SIZE"))
+	if s == "" {
+		return DefaultDocumentCacheSize, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return DefaultDocumentCacheSize, fmt.Errorf("invalid ARC_LIBRARY_CACHE_SIZE %q (expected a number of documents, 0 for none)", s)
+	}
+	return n, nil
+}
+
+// CachedStore wraps a store, keeping the documents most recently read by
+// ID in memory so that commands and handlers looking the same documents
+// up again (stats, session lists, the web UI) don't go back to storage.
+// The least recently used document is dropped when the cache is full.
+// Changes to a document through the store drop it from the cache. It is
+// safe for concurrent use when the wrapped store is.
+type CachedStore struct {
+	LibraryStore
+	Size   int           // documents kept
+	MaxAge time.Duration // how long one is served before it is read again; 0 for no limit
+
+	mu      sync.Mutex
+	order   *list.List               // of *cachedDocument, most recently used first
+	entries map[string]*list.Element // by document ID
+	gen     int                      // counts invalidations, so reads racing a write aren't cached
+	now     func() time.Time
+}
+
+type cachedDocument struct {
+	doc    *Document
+	loaded time.Time
+}
+
+// NewCachedStore returns s with up to size documents cached, each for at
+// most DocumentCacheMaxAge.
+func NewCachedStore(s LibraryStore, size int) *CachedStore {
+	return &CachedStore{
+		LibraryStore: s,
+		Size:         size,
+		MaxAge:       DocumentCacheMaxAge,
+		order:        list.New(),
+		entries:      make(map[string]*list.Element),
+		now:          time.Now,
+	}
+}
+
+// GetDocument returns a copy of the cached document, reading it and
+// caching it first if needed. Documents that don't exist aren't cached.
+func (s *CachedStore) GetDocument(id string) (*Document, error) {
+	doc, gen := s.lookup(id)
+	if doc != nil {
+		return doc, nil
+	}
+	doc, err := s.LibraryStore.GetDocument(id)
+	if err != nil || doc == nil {
+		return doc, err
+	}
+	s.store(doc, gen)
+	return doc, nil
+}
+
+// lookup returns a copy of the cached document, or nil and the
+// generation to cache it under once it is read.
+func (s *CachedStore) lookup(id string) (*Document, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[id]
+	if !ok {
+		return nil, s.gen
+	}
+	c := e.Value.(*cachedDocument)
+	if s.MaxAge > 0 && s.now().Sub(c.loaded) > s.MaxAge {
+		s.order.Remove(e)
+		delete(s.entries, id)
+		return nil, s.gen
+	}
+	s.order.MoveToFront(e)
+	return cloneDocument(c.doc), s.gen
+}
+
+// store caches doc, read in generation gen, unless something has been
+// invalidated since: the read may have been of what a write replaced.
+func (s *CachedStore) store(doc *Document, gen int) {
+	if s.Size <= 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if gen != s.gen {
+		return
+	}
+	c := &cachedDocument{doc: cloneDocument(doc), loaded: s.now()}
+	if e, ok := s.entries[doc.ID]; ok {
+		e.Value = c
+		s.order.MoveToFront(e)
+		return
+	}
+	s.entries[doc.ID] = s.order.PushFront(c)
+	for s.order.Len() > s.Size {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*cachedDocument).doc.ID)
+	}
+}
+
+// Forget drops a document from the cache.
+func (s *CachedStore) Forget(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gen++
+	if e, ok := s.entries[id]; ok {
+		s.order.Remove(e)
+		delete(s.entries, id)
+	}
+}
+
+// Purge empties the cache.
+func (s *CachedStore) Purge() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.gen++
+	s.order.Init()
+	s.entries = make(map[string]*list.Element)
+}
+
+// Len returns how many documents are cached.
+func (s *CachedStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+// The writes below drop the document whether or not they succeed, since a
+// failed write may still have changed it. Adds drop it too: one given the
+// ID of an existing document may replace it.
+
+func (s *CachedStore) AddDocument(doc *Document) error {
+	defer func() { s.Forget(doc.ID) }()
+	return s.LibraryStore.AddDocument(doc)
+}
+
+func (s *CachedStore) AddDocuments(docs []*Document) error {
+	defer func() {
+		for _, doc := range docs {
+			s.Forget(doc.ID)
+		}
+	}()
+	return s.LibraryStore.AddDocuments(docs)
+}
+
+func (s *CachedStore) UpdateDocument(doc *Document) error {
+	defer s.Forget(doc.ID)
+	return s.LibraryStore.UpdateDocument(doc)
+}
+
+func (s *CachedStore) DeleteDocument(id string) error {
+	defer s.Forget(id)
+	return s.LibraryStore.DeleteDocument(id)
+}
+
+func (s *CachedStore) AddTag(documentID, tag string) error {
+	defer s.Forget(documentID)
+	return s.LibraryStore.AddTag(documentID, tag)
+}
+
+func (s *CachedStore) RemoveTag(documentID, tag string) error {
+	defer s.Forget(documentID)
+	return s.LibraryStore.RemoveTag(documentID, tag)
+}
+
+func (s *CachedStore) Compact() (*CompactStats, error) {
+	defer s.Purge()
+	return s.LibraryStore.Compact()
+}
+
+// cloneDocument returns a copy of doc sharing nothing with it, so callers
+// can change what they are given without changing the cache.
+func cloneDocument(doc *Document) *Document {
+	c := *doc
+	c.Authors = append([]string(nil), doc.Authors...)
+	c.Tags = append([]string(nil), doc.Tags...)
+	if doc.Meta != nil {
+		c.Meta = cloneValue(map[string]any(doc.Meta)).(map[string]any)
+	}
+	return &c
+}
+
+// cloneValue deep-copies the maps and slices of a decoded JSON value.
+func cloneValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, x := range v {
+			m[k] = cloneValue(x)
+		}
+		return m
+	case JSONMap:
+		return JSONMap(cloneValue(map[string]any(v)).(map[string]any))
+	case []any:
+		s := make([]any, len(v))
+		for i, x := range v {
+			s[i] = cloneValue(x)
+		}
+		return s
+	case []string:
+		return append([]string(nil), v...)
+	}
+	return v
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+// countingStore counts the documents read from the store it wraps.
+type countingStore struct {
+	LibraryStore
+	reads int
+}
+
+func (s *countingStore) GetDocument(id string) (*Document, error) {
+	s.reads++
+	return s.LibraryStore.GetDocument(id)
+}
+
+func TestCachedStore(t *testing.T) {
+	kv, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	counting := &countingStore{LibraryStore: kv}
+	s := NewCachedStore(counting, 2)
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	var ids []string
+	for _, title := range []string{"One", "Two", "Three"} {
+		doc := &Document{Title: title, Type: "paper", Tags: []string{"ml"}, Meta: JSONMap{"venue": map[string]any{"name": "NeurIPS"}}}
+		if err := s.AddDocument(doc); err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, doc.ID)
+	}
+
+	get := func(id string) *Document {
+		t.Helper()
+		doc, err := s.GetDocument(id)
+		if err != nil || doc == nil {
+			t.Fatalf("get %s: %v, %v", id, doc, err)
+		}
+		return doc
+	}
+
+	// Repeated reads are served from the cache, and changing what they
+	// return leaves the cache alone
+	doc := get(ids[0])
+	doc.Title = "Changed"
+	doc.Tags[0] = "changed"
+	doc.Meta["venue"].(map[string]any)["name"] = "changed"
+	doc = get(ids[0])
+	if counting.reads != 1 {
+		t.Errorf("reads = %d, want 1", counting.reads)
+	}
+	if doc.Title != "One" || doc.Tags[0] != "ml" || doc.Meta["venue"].(map[string]any)["name"] != "NeurIPS" {
+		t.Errorf("cached document changed by a caller: %+v", doc)
+	}
+
+	// Writes drop the document
+	doc.Title = "Uno"
+	if err := s.UpdateDocument(doc); err != nil {
+		t.Fatal(err)
+	}
+	if got := get(ids[0]); got.Title != "Uno" || counting.reads != 2 {
+		t.Errorf("after update: %q, reads = %d", got.Title, counting.reads)
+	}
+	if err := s.AddTag(ids[0], "to-read"); err != nil {
+		t.Fatal(err)
+	}
+	if got := get(ids[0]); len(got.Tags) != 2 || counting.reads != 3 {
+		t.Errorf("after tagging: %v, reads = %d", got.Tags, counting.reads)
+	}
+	// The KV store replaces a document added again with its ID
+	if err := s.AddDocument(&Document{ID: ids[0], Title: "Eins", Type: "paper"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := get(ids[0]); got.Title != "Eins" || counting.reads != 4 {
+		t.Errorf("after replacing: %q, reads = %d", got.Title, counting.reads)
+	}
+	if err := s.AddDocuments([]*Document{{ID: ids[0], Title: "Un", Type: "paper", Tags: []string{"ml"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := get(ids[0]); got.Title != "Un" || counting.reads != 5 {
+		t.Errorf("after replacing in bulk: %q, reads = %d", got.Title, counting.reads)
+	}
+
+	// The least recently used document goes when the cache is full
+	get(ids[1])
+	get(ids[0])
+	get(ids[2])
+	if s.Len() != 2 {
+		t.Errorf("len = %d, want 2", s.Len())
+	}
+	counting.reads = 0
+	get(ids[0])
+	get(ids[2])
+	if counting.reads != 0 {
+		t.Errorf("recently used documents read again %d time(s)", counting.reads)
+	}
+	get(ids[1])
+	if counting.reads != 1 {
+		t.Errorf("evicted document not read again")
+	}
+
+	// Documents are read again once they are too old
+	counting.reads = 0
+	now = now.Add(DocumentCacheMaxAge + time.Second)
+	get(ids[1])
+	if counting.reads != 1 {
+		t.Errorf("stale document not read again")
+	}
+
+	if err := s.DeleteDocument(ids[1]); err != nil {
+		t.Fatal(err)
+	}
+	if doc, err := s.GetDocument(ids[1]); err != nil || doc != nil {
+		t.Errorf("deleted document: %v, %v", doc, err)
+	}
+}
+
+func TestDocumentCacheSize(t *testing.T) {
+	t.Setenv("ARC_LIBRARY_CACHE_SIZE", "")
+	if n, err := DocumentCacheSize(); err != nil || n != DefaultDocumentCacheSize {
+		t.Errorf("default = %d, %v", n, err)
+	}
+	t.Setenv("ARC_LIBRARY_CACHE_SIZE", "0")
+	if n, err := DocumentCacheSize(); err != nil || n != 0 {
+		t.Errorf("0 = %d, %v", n, err)
+	}
+	t.Setenv("ARC_LIBRARY_CACHE_SIZE", "lots")
+	if _, err := DocumentCacheSize(); err == nil {
+		t.Error("invalid size: no error")
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// flashcardColumns are the columns of ExportFlashcardsCSV, question and
+// answer first so the file imports into Anki and Quizlet as is.
+var flashcardColumns = []string{"front", "back", "type", "tags", "document_id", "document", "due", "interval", "ease", "id"}
+
+// ExportFlashcardsCSV writes cards as a table with a header row, one card
+// per row, separated by comma (',' for CSV, '\t' for TSV). Cloze cards
+// have their cloze text as the front. Tags are separated by spaces, as
+// Anki has them. Document titles are looked up in s.
+func ExportFlashcardsCSV(w io.Writer, s LibraryStore, cards []*Flashcard, comma rune) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	if err := cw.Write(flashcardColumns); err != nil {
+		return err
+	}
+	titles := documentTitles(s)
+	for _, c := range cards {
+		due := ""
+		if !c.DueAt.IsZero() {
+			due = HomeDate(c.DueAt)
+		}
+		row := []string{
+			cardQuestion(c), c.Back, c.Type, strings.Join(c.Tags, " "),
+			c.DocumentID, titles(c.DocumentID), due,
+			fmt.Sprintf("%d", c.Interval), fmt.Sprintf("%.2f", c.Ease), c.ID,
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ExportFlashcardsMarkdown writes cards as a Markdown list of questions
+// and answers under a heading per document, in the order the documents
+// first appear in cards. Cards without a document come last.
+func ExportFlashcardsMarkdown(w io.Writer, s LibraryStore, cards []*Flashcard) error {
+	var order []string
+	groups := map[string][]*Flashcard{}
+	for _, c := range cards {
+		if _, ok := groups[c.DocumentID]; !ok && c.DocumentID != "" {
+			order = append(order, c.DocumentID)
+		}
+		groups[c.DocumentID] = append(groups[c.DocumentID], c)
+	}
+	if len(groups[""]) > 0 {
+		order = append(order, "")
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# Flashcards\n\n%d card(s)\n", len(cards))
+	titles := documentTitles(s)
+	for _, id := range order {
+		title := titles(id)
+		if id == "" {
+			title = "Other cards"
+		} else if title == "" {
+			title = id
+		}
+		fmt.Fprintf(bw, "\n## %s\n\n", title)
+		for _, c := range groups[id] {
+			fmt.Fprintf(bw, "- **Q:** %s\n", markdownListText(cardQuestion(c)))
+			if c.Back != "" {
+				fmt.Fprintf(bw, "  **A:** %s\n", markdownListText(c.Back))
+			}
+			if len(c.Tags) > 0 {
+				fmt.Fprintf(bw, "  *Tags:* %s\n", strings.Join(c.Tags, ", "))
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// cardQuestion is what a card asks: its cloze text for cloze cards.
+func cardQuestion(c *Flashcard) string {
+	if c.Type == "cloze" && c.Cloze != "" {
+		return c.Cloze
+	}
+	return c.Front
+}
+
+// markdownListText indents the lines after the first so multi-line text
+// stays inside its list item.
+func markdownListText(s string) string {
+	return strings.ReplaceAll(strings.TrimSpace(s), "\n", "\n  ")
+}
+
+// documentTitles returns a lookup of document titles by ID, remembering
+// each; an unknown document has no title.
+func documentTitles(s LibraryStore) func(id string) string {
+	cache := map[string]string{}
+	return func(id string) string {
+		if id == "" {
+			return ""
+		}
+		if t, ok := cache[id]; ok {
+			return t
+		}
+		title := ""
+		if doc, err := s.GetDocument(id); err == nil && doc != nil {
+			title = doc.Title
+		}
+		cache[id] = title
+		return title
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+func exportFixture(t *testing.T) (LibraryStore, []*Flashcard) {
+	t.Helper()
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := &Document{Type: DocTypePaper, Title: "Attention Is All You Need"}
+	if err := s.AddDocument(doc); err != nil {
+		t.Fatal(err)
+	}
+	due := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	return s, []*Flashcard{
+		{ID: "c1", DocumentID: doc.ID, Type: "basic", Front: "What replaces recurrence?", Back: "Self-attention,\nwith \"heads\"", Tags: []string{"ml", "exam"}, DueAt: due, Interval: 6, Ease: 2.5},
+		{ID: "c2", Type: "cloze", Front: "ignored", Cloze: "The capital of France is {{c1::Paris}}"},
+		{ID: "c3", DocumentID: doc.ID, Type: "basic", Front: "Layers?", Back: "6"},
+	}
+}
+
+func TestExportFlashcardsCSV(t *testing.T) {
+	s, cards := exportFixture(t)
+	for _, comma := range []rune{',', '\t'} {
+		var b strings.Builder
+		if err := ExportFlashcardsCSV(&b, s, cards, comma); err != nil {
+			t.Fatal(err)
+		}
+		r := csv.NewReader(strings.NewReader(b.String()))
+		r.Comma = comma
+		rows, err := r.ReadAll()
+		if err != nil {
+			t.Fatalf("%q: %v\n%s", comma, err, b.String())
+		}
+		if len(rows) != 4 || rows[0][0] != "front" {
+			t.Fatalf("%q: rows = %q", comma, rows)
+		}
+		want := []string{"What replaces recurrence?", "Self-attention,\nwith \"heads\"", "basic", "ml exam", cards[0].DocumentID,
+			"Attention Is All You Need", "2026-05-01", "6", "2.50", "c1"}
+		if strings.Join(rows[1], "|") != strings.Join(want, "|") {
+			t.Errorf("%q: row = %q, want %q", comma, rows[1], want)
+		}
+		if rows[2][0] != "The capital of France is {{c1::Paris}}" || rows[2][6] != "" {
+			t.Errorf("%q: cloze row = %q", comma, rows[2])
+		}
+	}
+}
+
+func TestExportFlashcardsMarkdown(t *testing.T) {
+	s, cards := exportFixture(t)
+	var b strings.Builder
+	if err := ExportFlashcardsMarkdown(&b, s, cards); err != nil {
+		t.Fatal(err)
+	}
+	want := `# Flashcards
+
+3 card(s)
+
+## Attention Is All You Need
+
+- **Q:** What replaces recurrence?
+  **A:** Self-attention,
+  with "heads"
+  *Tags:* ml, exam
+- **Q:** Layers?
+  **A:** 6
+
+## Other cards
+
+- **Q:** The capital of France is {{c1::Paris}}
+`
+	if b.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
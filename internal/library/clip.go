@@ -0,0 +1,102 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Clip is a web page pushed from a browser bookmarklet or extension, with
+// the text highlighted on it.
+type Clip struct {
+	URL       string   `json:"url"`
+	Title     string   `json:"title"`
+	Selection string   `json:"selection"`
+	Tags      []string `json:"tags"`
+}
+
+// ClipResult is what saving a clip did.
+type ClipResult struct {
+	Document   *Document   `json:"document"`
+	Annotation *Annotation `json:"annotation"` // nil without a selection
+	Created    bool        `json:"created"`    // false when the page was already in the library
+}
+
+// FindDocumentByURL returns the document whose Meta["url"] is rawURL, or
+// nil.
+func FindDocumentByURL(s LibraryStore, rawURL string) (*Document, error) {
+	docs, err := s.ListDocuments(nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range docs {
+		if u, _ := d.Meta["url"].(string); u == rawURL {
+			return d, nil
+		}
+	}
+	return nil, nil
+}
+
+// SaveClip adds the clipped page as an article, or adds the clip's tags to
+// the document already saved for its URL, and stores the selection as a
+// highlight.
+func SaveClip(s LibraryStore, c *Clip) (*ClipResult, error) {
+	u, err := url.Parse(c.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid URL: %q", c.URL)
+	}
+
+	doc, err := FindDocumentByURL(s, c.URL)
+	if err != nil {
+		return nil, err
+	}
+	result := &ClipResult{Document: doc}
+	if doc == nil {
+		title := strings.TrimSpace(c.Title)
+		if title == "" {
+			title = u.Host + u.Path
+		}
+		doc = &Document{
+			Type:   DocTypeArticle,
+			Source: "url",
+			Title:  title,
+			Tags:   slices.Clone(c.Tags),
+			Status: StatusUnread,
+			Meta:   JSONMap{"url": c.URL},
+		}
+		DetectDocumentLanguage(doc, false)
+		if err := s.AddDocument(doc); err != nil {
+			return nil, fmt.Errorf("add document: %w", err)
+		}
+		result.Document = doc
+		result.Created = true
+	} else {
+		changed := false
+		for _, tag := range c.Tags {
+			if !slices.Contains(doc.Tags, tag) {
+				doc.Tags = append(doc.Tags, tag)
+				changed = true
+			}
+		}
+		if changed {
+			doc.UpdatedAt = time.Now()
+			if err := s.UpdateDocument(doc); err != nil {
+				return nil, fmt.Errorf("update document: %w", err)
+			}
+		}
+	}
+
+	if selection := strings.TrimSpace(c.Selection); selection != "" {
+		ann := &Annotation{DocumentID: doc.ID, Type: "highlight", Content: selection}
+		if err := s.AddAnnotation(ann); err != nil {
+			return nil, fmt.Errorf("add annotation: %w", err)
+		}
+		result.Annotation = ann
+	}
+	return result, nil
+}
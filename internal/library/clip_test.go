@@ -0,0 +1,56 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+func TestSaveClip(t *testing.T) {
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := SaveClip(s, &Clip{URL: "https://example.com/post", Title: " A Post ", Tags: []string{"web"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !first.Created || first.Annotation != nil {
+		t.Fatalf("first clip: created %v, annotation %v", first.Created, first.Annotation)
+	}
+	doc := first.Document
+	if doc.Title != "A Post" || doc.Type != DocTypeArticle || doc.Meta["url"] != "https://example.com/post" {
+		t.Errorf("saved document = %+v", doc)
+	}
+
+	// Clipping the same page again adds tags and the highlight to it
+	second, err := SaveClip(s, &Clip{URL: "https://example.com/post", Selection: "  quoted text ", Tags: []string{"web", "later"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Created || second.Document.ID != doc.ID {
+		t.Fatalf("second clip created a new document %s", second.Document.ID)
+	}
+	got, _ := s.GetDocument(doc.ID)
+	if !slices.Equal(got.Tags, []string{"web", "later"}) {
+		t.Errorf("tags = %v, want [web later]", got.Tags)
+	}
+	anns, _ := s.GetAnnotations(doc.ID)
+	if len(anns) != 1 || anns[0].Content != "quoted text" || anns[0].Type != "highlight" {
+		t.Errorf("annotations = %+v", anns)
+	}
+
+	docs, _ := s.ListDocuments(nil)
+	if len(docs) != 1 {
+		t.Errorf("library has %d documents, want 1", len(docs))
+	}
+
+	if _, err := SaveClip(s, &Clip{URL: "javascript:alert(1)"}); err == nil {
+		t.Error("non-http URL should be rejected")
+	}
+}
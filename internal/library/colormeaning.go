@@ -0,0 +1,196 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ColorMeanings maps annotation colors to what they stand for, such as
+// yellow to definition and red to disagreement, so highlights can be
+// listed and exported by meaning. Keys are normalized colors.
+type ColorMeanings map[string]string
+
+// namedColors are the highlight colors readers and PDF viewers use most,
+// so "yellow" and "#ffff00" are the same color.
+var namedColors = map[string]string{
+	"yellow": "#ffff00",
+	"red":    "#ff0000",
+	"green":  "#00ff00",
+	"blue":   "#0000ff",
+	"orange": "#ffa500",
+	"purple": "#800080",
+	"pink":   "#ffc0cb",
+	"gray":   "#808080",
+	"grey":   "#808080",
+}
+
+// NormalizeColor returns a color in the form ColorMeanings keys it by:
+// lower case, a known name as its hex code, and #rgb spelled out as
+// #rrggbb.
+func NormalizeColor(color string) string {
+	c := strings.ToLower(strings.TrimSpace(color))
+	if hex, ok := namedColors[c]; ok {
+		return hex
+	}
+	if len(c) == 4 && c[0] == '#' {
+		return string([]byte{'#', c[1], c[1], c[2], c[2], c[3], c[3]})
+	}
+	return c
+}
+
+// ColorName returns the name of a normalized color, or the color itself
+// when it has none.
+func ColorName(color string) string {
+	names := make([]string, 0, len(namedColors))
+	for name, hex := range namedColors {
+		if hex == color {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return color
+	}
+	sort.Strings(names) // gray before grey
+	return names[0]
+}
+
+// ColorMeaningsFile returns the file color meanings are kept in:
+// $ARC_LIBRARY_COLOR_MEANINGS, or arc-library/color-meanings.yaml under
+// the user config directory.
+func ColorMeaningsFile() (string, error) {
+	return configFile("ARC_LIBRARY_COLOR_MEANINGS", "color-meanings.yaml")
+}
+
+// LoadColorMeanings reads a YAML map of colors to meanings. A missing
+// file means none.
+func LoadColorMeanings(path string) (ColorMeanings, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ColorMeanings{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]string
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	meanings := ColorMeanings{}
+	for color, meaning := range raw {
+		meanings.Set(color, meaning)
+	}
+	return meanings, nil
+}
+
+// SaveColorMeanings writes the meanings to path, creating its directory.
+func SaveColorMeanings(path string, meanings ColorMeanings) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(map[string]string(meanings))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// Set gives a color a meaning, or removes the color's meaning when
+// meaning is empty.
+func (m ColorMeanings) Set(color, meaning string) {
+	color, meaning = NormalizeColor(color), strings.TrimSpace(meaning)
+	if meaning == "" {
+		delete(m, color)
+		return
+	}
+	m[color] = meaning
+}
+
+// Meaning returns what a color stands for, or "" when it has no meaning.
+func (m ColorMeanings) Meaning(color string) string {
+	if color == "" {
+		return ""
+	}
+	return m[NormalizeColor(color)]
+}
+
+// Colors returns the colors with the meaning, matched ignoring case.
+func (m ColorMeanings) Colors(meaning string) []string {
+	var colors []string
+	for color, mm := range m {
+		if strings.EqualFold(mm, meaning) {
+			colors = append(colors, color)
+		}
+	}
+	sort.Strings(colors)
+	return colors
+}
+
+// AnnotationFilter selects annotations by type, color, or the meaning of
+// their color. Empty fields match everything.
+type AnnotationFilter struct {
+	Type    string
+	Color   string
+	Meaning string
+}
+
+// FilterAnnotations returns the annotations matching the filter, reading
+// colors' meanings from meanings.
+func FilterAnnotations(anns []*Annotation, f AnnotationFilter, meanings ColorMeanings) []*Annotation {
+	color := NormalizeColor(f.Color)
+	var out []*Annotation
+	for _, a := range anns {
+		if f.Type != "" && a.Type != f.Type {
+			continue
+		}
+		if color != "" && NormalizeColor(a.Color) != color {
+			continue
+		}
+		if f.Meaning != "" && !strings.EqualFold(meanings.Meaning(a.Color), f.Meaning) {
+			continue
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
+// AnnotationGroup is annotations sharing a meaning.
+type AnnotationGroup struct {
+	Meaning     string // "" for annotations whose color has none
+	Annotations []*Annotation
+}
+
+// GroupAnnotationsByMeaning groups annotations by their color's meaning,
+// in the order the meanings first appear, with those without a meaning
+// last.
+func GroupAnnotationsByMeaning(anns []*Annotation, meanings ColorMeanings) []AnnotationGroup {
+	var groups []AnnotationGroup
+	index := map[string]int{}
+	var rest []*Annotation
+	for _, a := range anns {
+		meaning := meanings.Meaning(a.Color)
+		if meaning == "" {
+			rest = append(rest, a)
+			continue
+		}
+		i, ok := index[meaning]
+		if !ok {
+			i = len(groups)
+			index[meaning] = i
+			groups = append(groups, AnnotationGroup{Meaning: meaning})
+		}
+		groups[i].Annotations = append(groups[i].Annotations, a)
+	}
+	if len(rest) > 0 {
+		groups = append(groups, AnnotationGroup{Annotations: rest})
+	}
+	return groups
+}
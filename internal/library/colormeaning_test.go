@@ -0,0 +1,153 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+func TestNormalizeColor(t *testing.T) {
+	tests := map[string]string{
+		"Yellow":  "#ffff00",
+		" red ":   "#ff0000",
+		"#FF0000": "#ff0000",
+		"#f00":    "#ff0000",
+		"teal":    "teal",
+		"":        "",
+		"#abcdef": "#abcdef",
+	}
+	for in, want := range tests {
+		if got := NormalizeColor(in); got != want {
+			t.Errorf("NormalizeColor(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if got := ColorName("#808080"); got != "gray" {
+		t.Errorf("ColorName(#808080) = %q, want gray", got)
+	}
+}
+
+func TestColorMeaningsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "colors", "color-meanings.yaml")
+	meanings, err := LoadColorMeanings(path)
+	if err != nil || len(meanings) != 0 {
+		t.Fatalf("missing file: %v, %v", meanings, err)
+	}
+	meanings.Set("yellow", "definition")
+	meanings.Set("#F00", "disagreement")
+	meanings.Set("blue", "question")
+	meanings.Set("blue", "")
+	if err := SaveColorMeanings(path, meanings); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadColorMeanings(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != 2 {
+		t.Errorf("loaded %v, want two meanings", loaded)
+	}
+	if got := loaded.Meaning("red"); got != "disagreement" {
+		t.Errorf("red means %q, want disagreement", got)
+	}
+	if got := loaded.Meaning("#ffff00"); got != "definition" {
+		t.Errorf("#ffff00 means %q, want definition", got)
+	}
+	if got := loaded.Colors("Definition"); len(got) != 1 || got[0] != "#ffff00" {
+		t.Errorf("Colors(Definition) = %v", got)
+	}
+}
+
+func TestFilterAndGroupAnnotations(t *testing.T) {
+	meanings := ColorMeanings{}
+	meanings.Set("yellow", "definition")
+	meanings.Set("red", "disagreement")
+	anns := []*Annotation{
+		{ID: "1", Type: "highlight", Color: "red", Content: "Overclaims"},
+		{ID: "2", Type: "highlight", Color: "#FFFF00", Content: "Attention"},
+		{ID: "3", Type: "note", Color: "red", Content: "Check this"},
+		{ID: "4", Type: "highlight", Content: "Plain"},
+		{ID: "5", Type: "highlight", Color: "yellow", Content: "Transformer"},
+	}
+
+	ids := func(anns []*Annotation) string {
+		var s []string
+		for _, a := range anns {
+			s = append(s, a.ID)
+		}
+		return strings.Join(s, ",")
+	}
+	tests := []struct {
+		filter AnnotationFilter
+		want   string
+	}{
+		{AnnotationFilter{}, "1,2,3,4,5"},
+		{AnnotationFilter{Type: "highlight", Color: "red"}, "1"},
+		{AnnotationFilter{Color: "#ff0000"}, "1,3"},
+		{AnnotationFilter{Meaning: "Definition"}, "2,5"},
+		{AnnotationFilter{Type: "note", Meaning: "definition"}, ""},
+	}
+	for _, tt := range tests {
+		if got := ids(FilterAnnotations(anns, tt.filter, meanings)); got != tt.want {
+			t.Errorf("FilterAnnotations(%+v) = %s, want %s", tt.filter, got, tt.want)
+		}
+	}
+
+	groups := GroupAnnotationsByMeaning(anns, meanings)
+	var got []string
+	for _, g := range groups {
+		got = append(got, g.Meaning+":"+ids(g.Annotations))
+	}
+	if want := "disagreement:1,3 definition:2,5 :4"; strings.Join(got, " ") != want {
+		t.Errorf("groups = %s, want %s", strings.Join(got, " "), want)
+	}
+}
+
+func TestMarkdownExportGroupByMeaning(t *testing.T) {
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := &Document{Type: DocTypePaper, Title: "Attention Is All You Need"}
+	if err := s.AddDocument(doc); err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range []*Annotation{
+		{Type: "highlight", Color: "yellow", Content: "Self-attention relates positions"},
+		{Type: "highlight", Color: "red", Content: "No recurrence needed"},
+		{Type: "note", Content: "Compare with RNNs"},
+	} {
+		a.DocumentID = doc.ID
+		if err := s.AddAnnotation(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	meanings := ColorMeanings{}
+	meanings.Set("yellow", "Definition")
+	meanings.Set("red", "Disagreement")
+
+	var buf bytes.Buffer
+	if err := (markdownExporter{}).Export(&buf, s, []*Document{doc}, ExportOptions{GroupBy: "meaning", ColorMeanings: meanings}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	def := strings.Index(out, "#### Definition\n\n- [highlight] Self-attention relates positions")
+	dis := strings.Index(out, "#### Disagreement\n\n- [highlight] No recurrence needed")
+	other := strings.Index(out, "#### Other\n\n- [note] Compare with RNNs")
+	if def < 0 || dis < 0 || other < 0 {
+		t.Fatalf("export missing meaning groups:\n%s", out)
+	}
+	if !(def < dis && dis < other) {
+		t.Errorf("groups out of order:\n%s", out)
+	}
+
+	if err := (markdownExporter{}).Export(&buf, s, []*Document{doc}, ExportOptions{GroupBy: "color"}); err == nil {
+		t.Error("expected an error for an unknown grouping")
+	}
+}
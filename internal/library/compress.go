@@ -0,0 +1,108 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/klauspost/compress/zstd"
+	"modernc.org/sqlite"
+)
+
+// Full text is stored zstd-compressed, which shrinks extracted PDF text
+// several times over, or in files (see fulltextfiles.go). Text stored
+// before compression, and text too short to gain from it, stays as it
+// was: reads tell them apart by the zstd frame's magic number and the
+// file reference's prefix, neither of which can start valid UTF-8 text.
+
+var zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedBetterCompression))
+	zstdDecoder, _ = zstd.NewReader(nil)
+)
+
+func init() {
+	// The SQL store's full-text search triggers index the stored text
+	// through this function
+	sqlite.MustRegisterDeterministicScalarFunction("library_full_text", 1, func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		switch v := args[0].(type) {
+		case []byte:
+			return expandFullText(string(v))
+		case string:
+			return v, nil
+		}
+		return args[0], nil
+	})
+}
+
+// encodeFullText returns text as stored: a reference to its file when
+// full text is external (see ExternalFullText), otherwise compressed,
+// unless that does not make it smaller. encoded reports that what is
+// stored is not the text itself.
+func encodeFullText(text string) (stored []byte, encoded bool, err error) {
+	if text == "" {
+		return nil, false, nil
+	}
+	external, err := ExternalFullText()
+	if err != nil {
+		return nil, false, err
+	}
+	if external {
+		ref, err := writeFullTextFile(text)
+		return ref, err == nil, err
+	}
+	compressed := zstdEncoder.EncodeAll([]byte(text), nil)
+	if len(compressed) >= len(text) {
+		return []byte(text), false, nil
+	}
+	return compressed, true, nil
+}
+
+// storedFullText returns text as the SQL store binds it: a blob when
+// encoded, so SQLite does not take it for text.
+func storedFullText(text string) (any, error) {
+	stored, encoded, err := encodeFullText(text)
+	if err != nil || !encoded {
+		return text, err
+	}
+	return stored, nil
+}
+
+// expandFullText returns the text stored as stored, reading it from its
+// file or decompressing it as needed.
+func expandFullText(stored string) (string, error) {
+	switch b := []byte(stored); {
+	case isFullTextRef(b):
+		return readFullTextFile(b)
+	case isCompressed(b):
+		text, err := zstdDecoder.DecodeAll(b, nil)
+		if err != nil {
+			return "", fmt.Errorf("decompress full text: %w", err)
+		}
+		return string(text), nil
+	}
+	return stored, nil
+}
+
+func isCompressed(stored []byte) bool {
+	return bytes.HasPrefix(stored, zstdMagic)
+}
+
+// CompactStats reports what compacting the library's storage did.
+type CompactStats struct {
+	Documents    int   `json:"documents"`     // documents whose full text was compressed or moved
+	FullText     int64 `json:"full_text"`     // bytes of full text, uncompressed
+	StoredText   int64 `json:"stored_text"`   // bytes of full text in storage, file references for external text
+	SizeBefore   int64 `json:"size_before"`   // bytes of storage before compacting
+	SizeAfter    int64 `json:"size_after"`    // and after
+	FilesRemoved int   `json:"files_removed"` // unreferenced full text files removed (see pruneFullTextFiles)
+}
+
+// Saved returns how many bytes compacting freed.
+func (c *CompactStats) Saved() int64 {
+	return c.SizeBefore - c.SizeAfter
+}
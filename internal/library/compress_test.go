@@ -0,0 +1,138 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+var longText = strings.Repeat("Attention is all you need. The transformer relies entirely on attention. ", 200)
+
+func TestKVFullTextCompression(t *testing.T) {
+	kv := store.NewMemoryStore()
+	s, err := NewKVStore(kv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := &Document{Title: "Transformers", Type: "paper", FullText: longText}
+	if err := s.AddDocument(doc); err != nil {
+		t.Fatal(err)
+	}
+	data, err := kv.Get(context.Background(), s.generateKey("doc", doc.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) > len(longText)/4 || strings.Contains(string(data), "attention") {
+		t.Errorf("full text stored uncompressed (%d bytes)", len(data))
+	}
+	got, err := s.GetDocument(doc.ID)
+	if err != nil || got.FullText != longText {
+		t.Fatalf("full text did not round-trip: %v", err)
+	}
+	if docs, err := s.ListDocuments(&ListOptions{Search: "relies entirely"}); err != nil || len(docs) != 1 {
+		t.Errorf("search found %d, %v", len(docs), err)
+	}
+
+	// A document written before compression is read as is, and compacted
+	legacy, err := json.Marshal(&Document{ID: "doc:legacy", Title: "Legacy", Type: "paper", FullText: longText})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := kv.Set(context.Background(), s.generateKey("doc", "doc:legacy"), legacy); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.addToDocumentIndex("doc:legacy"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.GetDocument("doc:legacy"); err != nil || got.FullText != longText {
+		t.Fatalf("legacy full text: %v", err)
+	}
+	stats, err := s.Compact()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Documents != 1 || stats.FullText != 2*int64(len(longText)) || stats.StoredText >= stats.FullText/4 || stats.Saved() <= 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if got, err := s.GetDocument("doc:legacy"); err != nil || got.FullText != longText {
+		t.Errorf("compacted full text: %v", err)
+	}
+	if stats, err := s.Compact(); err != nil || stats.Documents != 0 || stats.Saved() != 0 {
+		t.Errorf("second compact = %+v, %v", stats, err)
+	}
+}
+
+func TestSQLFullTextCompression(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "library.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	s, err := NewStore(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc := &Document{Title: "Transformers", Type: "paper", Path: "/papers/transformers.pdf", Source: "local", FullText: longText}
+	if err := s.AddDocument(doc); err != nil {
+		t.Fatal(err)
+	}
+	short := &Document{Title: "Short", Type: "note", Path: "/notes/short.md", Source: "local", FullText: "A short note."}
+	if err := s.AddDocument(short); err != nil {
+		t.Fatal(err)
+	}
+	var kind string
+	var size int
+	if err := db.QueryRow(`SELECT typeof(full_text), length(full_text) FROM documents WHERE id = ?`, doc.ID).Scan(&kind, &size); err != nil {
+		t.Fatal(err)
+	}
+	if kind != "blob" || size > len(longText)/4 {
+		t.Errorf("full text stored as %s of %d bytes", kind, size)
+	}
+	for _, d := range []*Document{doc, short} {
+		if got, err := s.GetDocument(d.ID); err != nil || got.FullText != d.FullText {
+			t.Errorf("%s: full text did not round-trip: %v", d.Title, err)
+		}
+	}
+
+	// The search index holds the text, not the compressed bytes
+	if docs, err := s.ListDocuments(&ListOptions{Search: "relies entirely"}); err != nil || len(docs) != 1 || docs[0].FullText != longText {
+		t.Errorf("search found %d, %v", len(docs), err)
+	}
+	doc.Title = "Attention"
+	if err := s.UpdateDocument(doc); err != nil {
+		t.Fatal(err)
+	}
+	if docs, err := s.ListDocuments(&ListOptions{Search: "transformer"}); err != nil || len(docs) != 1 {
+		t.Errorf("search after update found %d, %v", len(docs), err)
+	}
+
+	// Text stored before compression is read as is, and compacted
+	if _, err := db.Exec(`UPDATE documents SET full_text = ? WHERE id = ?`, longText, short.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.GetDocument(short.ID); err != nil || got.FullText != longText {
+		t.Fatalf("legacy full text: %v", err)
+	}
+	stats, err := s.Compact()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Documents != 1 || stats.FullText != 2*int64(len(longText)) || stats.StoredText >= stats.FullText/4 || stats.SizeAfter <= 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if got, err := s.GetDocument(short.ID); err != nil || got.FullText != longText {
+		t.Errorf("compacted full text: %v", err)
+	}
+	if docs, err := s.ListDocuments(&ListOptions{Search: "relies entirely"}); err != nil || len(docs) != 2 {
+		t.Errorf("search after compact found %d, %v", len(docs), err)
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"cmp"
+	"slices"
+)
+
+// DefaultCoverageMinAnnotations is the number of annotations from which a
+// document without flashcards is flagged by FlashcardCoverage.
+const DefaultCoverageMinAnnotations = 5
+
+// DocumentCoverage is how far a document's flashcards keep up with its
+// annotations.
+type DocumentCoverage struct {
+	DocumentID  string `json:"document_id"`
+	Title       string `json:"title"`
+	Annotations int    `json:"annotations"`
+	Flashcards  int    `json:"flashcards"`
+	Lagging     bool   `json:"lagging"` // heavily annotated, but no flashcards
+}
+
+// FlashcardCoverage counts the annotations and flashcards of each document
+// that has either. Documents with at least minAnnotations annotations (the
+// default when 0) and no flashcards are lagging; they come first, most
+// annotated first, followed by the rest by flashcards and annotations.
+func FlashcardCoverage(s LibraryStore, minAnnotations int) ([]DocumentCoverage, error) {
+	if minAnnotations <= 0 {
+		minAnnotations = DefaultCoverageMinAnnotations
+	}
+	cards, err := s.ListFlashcards(&FlashcardListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	perDoc := map[string]int{}
+	for _, c := range cards {
+		if c.DocumentID != "" {
+			perDoc[c.DocumentID]++
+		}
+	}
+	docs, err := s.ListDocuments(&ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	var out []DocumentCoverage
+	for _, d := range docs {
+		anns, err := s.GetAnnotations(d.ID)
+		if err != nil {
+			return nil, err
+		}
+		c := DocumentCoverage{DocumentID: d.ID, Title: d.Title, Annotations: len(anns), Flashcards: perDoc[d.ID]}
+		if c.Annotations == 0 && c.Flashcards == 0 {
+			continue
+		}
+		c.Lagging = c.Flashcards == 0 && c.Annotations >= minAnnotations
+		out = append(out, c)
+	}
+	slices.SortStableFunc(out, func(a, b DocumentCoverage) int {
+		if a.Lagging != b.Lagging {
+			if a.Lagging {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Or(cmp.Compare(b.Flashcards, a.Flashcards), cmp.Compare(b.Annotations, a.Annotations))
+	})
+	return out, nil
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"testing"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+func TestFlashcardCoverage(t *testing.T) {
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := func(title string, annotations, cards int) {
+		t.Helper()
+		d := &Document{Type: DocTypePaper, Title: title}
+		if err := s.AddDocument(d); err != nil {
+			t.Fatal(err)
+		}
+		for range annotations {
+			if err := s.AddAnnotation(&Annotation{DocumentID: d.ID, Type: "highlight", Content: "x"}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for range cards {
+			if err := s.AddFlashcard(&Flashcard{DocumentID: d.ID, Type: "basic", Front: "Q", Back: "A"}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	doc("Unread", 0, 0)
+	doc("Skimmed", 2, 0)
+	doc("Studied", 3, 4)
+	doc("Lagging", 6, 0)
+	doc("Cards only", 0, 1)
+
+	got, err := FlashcardCoverage(s, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var titles []string
+	for _, c := range got {
+		titles = append(titles, c.Title)
+	}
+	want := []string{"Lagging", "Studied", "Cards only", "Skimmed"}
+	if len(titles) != len(want) {
+		t.Fatalf("documents = %v, want %v", titles, want)
+	}
+	for i := range want {
+		if titles[i] != want[i] {
+			t.Fatalf("documents = %v, want %v", titles, want)
+		}
+	}
+	if !got[0].Lagging || got[0].Annotations != 6 || got[1].Lagging || got[1].Flashcards != 4 {
+		t.Errorf("coverage = %+v", got)
+	}
+
+	// A lower threshold flags lightly annotated documents too
+	got, err = FlashcardCoverage(s, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got[1].Lagging || got[1].Title != "Skimmed" {
+		t.Errorf("min 2: %+v", got)
+	}
+}
@@ -0,0 +1,279 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultDailyNoteName is the file name layout of daily notes, as a Go
+// time layout. Obsidian names them 2006-01-02.md; Logseq 2006_01_02.md.
+const DefaultDailyNoteName = "2006-01-02.md"
+
+// Markers around the section arc-library writes into a daily note, so
+// writing it again replaces it instead of adding a second copy.
+const (
+	dailyNoteStart = "<!-- arc-library:start -->"
+	dailyNoteEnd   = "<!-- arc-library:end -->"
+)
+
+// DayActivity is what happened in the library on one day.
+type DayActivity struct {
+	Date           string            `json:"date"` // YYYY-MM-DD
+	Added          []*Document       `json:"added"`
+	Finished       []*Document       `json:"finished"` // completed and read that day
+	Sessions       []SessionActivity `json:"sessions"`
+	Annotations    []*Annotation     `json:"annotations"`
+	TasksCompleted []*Task           `json:"tasks_completed"`
+	CardsReviewed  int               `json:"cards_reviewed"`
+	Reviews        int               `json:"reviews"` // a card may be reviewed more than once
+
+	titles map[string]string // document ID -> title, for rendering
+}
+
+// SessionActivity is a reading session with its document's title.
+type SessionActivity struct {
+	*ReadingSession
+	Title   string `json:"title"`
+	Minutes int    `json:"minutes"` // 0 for a session still open
+}
+
+// Empty reports whether nothing happened that day.
+func (a *DayActivity) Empty() bool {
+	return len(a.Added) == 0 && len(a.Finished) == 0 && len(a.Sessions) == 0 &&
+		len(a.Annotations) == 0 && len(a.TasksCompleted) == 0 && a.Reviews == 0
+}
+
+// ComputeDayActivity gathers the activity of the local day containing day.
+// Per-document and task lookups that fail are skipped, as in ComputeStats.
+func ComputeDayActivity(s LibraryStore, day time.Time) (*DayActivity, error) {
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	end := start.AddDate(0, 0, 1)
+	within := func(t time.Time) bool { return !t.Before(start) && t.Before(end) }
+
+	a := &DayActivity{
+		Date:           start.Format("2006-01-02"),
+		Added:          []*Document{},
+		Finished:       []*Document{},
+		Sessions:       []SessionActivity{},
+		Annotations:    []*Annotation{},
+		TasksCompleted: []*Task{},
+		titles:         map[string]string{},
+	}
+
+	docs, err := s.ListDocuments(nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range docs {
+		a.titles[d.ID] = d.Title
+		if within(d.CreatedAt) {
+			a.Added = append(a.Added, d)
+		}
+		if d.Status == StatusCompleted && within(d.ReadAt) {
+			a.Finished = append(a.Finished, d)
+		}
+		if sessions, err := s.ListSessions(d.ID); err == nil {
+			for _, rs := range sessions {
+				if !within(rs.StartAt) {
+					continue
+				}
+				sa := SessionActivity{ReadingSession: rs, Title: d.Title}
+				if !rs.EndAt.IsZero() {
+					sa.Minutes = int(rs.EndAt.Sub(rs.StartAt).Round(time.Minute) / time.Minute)
+				}
+				a.Sessions = append(a.Sessions, sa)
+			}
+		}
+		if anns, err := s.GetAnnotations(d.ID); err == nil {
+			for _, ann := range anns {
+				if within(ann.CreatedAt) {
+					a.Annotations = append(a.Annotations, ann)
+				}
+			}
+		}
+	}
+	sort.SliceStable(a.Added, func(i, j int) bool { return a.Added[i].CreatedAt.Before(a.Added[j].CreatedAt) })
+	sort.SliceStable(a.Sessions, func(i, j int) bool { return a.Sessions[i].StartAt.Before(a.Sessions[j].StartAt) })
+	sort.SliceStable(a.Annotations, func(i, j int) bool { return a.Annotations[i].CreatedAt.Before(a.Annotations[j].CreatedAt) })
+
+	// Not every backend has tasks
+	if tasks, err := s.ListTasks(&TaskListOptions{}); err == nil {
+		for _, t := range tasks {
+			if t.CompletedAt != nil && within(*t.CompletedAt) {
+				a.TasksCompleted = append(a.TasksCompleted, t)
+			}
+		}
+	}
+
+	cards, err := s.ListFlashcards(&FlashcardListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range cards {
+		// A card not reviewed since the day began has no reviews that day
+		if c.LastReview.Before(start) {
+			continue
+		}
+		reviews, err := s.ListFlashcardReviews(c.ID)
+		if err != nil {
+			continue
+		}
+		n := 0
+		for _, r := range reviews {
+			if within(r.ReviewedAt) {
+				n++
+			}
+		}
+		if n > 0 {
+			a.CardsReviewed++
+			a.Reviews += n
+		}
+	}
+	return a, nil
+}
+
+// Markdown renders the activity as a Markdown section for a daily note.
+// With wikiLinks, document titles are written as [[Title]] links, which
+// Obsidian and Logseq resolve to notes of that name.
+func (a *DayActivity) Markdown(wikiLinks bool) string {
+	title := func(id, t string) string {
+		if t == "" {
+			t = id
+		}
+		if wikiLinks {
+			return "[[" + strings.NewReplacer("[", "(", "]", ")", "|", "-").Replace(t) + "]]"
+		}
+		return t
+	}
+
+	var b strings.Builder
+	b.WriteString("## Library\n")
+	if a.Empty() {
+		b.WriteString("\nNo library activity.\n")
+		return b.String()
+	}
+	if len(a.Added) > 0 {
+		b.WriteString("\n### Added\n\n")
+		for _, d := range a.Added {
+			fmt.Fprintf(&b, "- %s", title(d.ID, d.Title))
+			var authors []string
+			for _, au := range d.Authors {
+				if au = strings.TrimSpace(au); au != "" {
+					authors = append(authors, au)
+				}
+			}
+			if len(authors) == 1 {
+				fmt.Fprintf(&b, " (%s)", authors[0])
+			} else if len(authors) > 1 {
+				fmt.Fprintf(&b, " (%s et al.)", authors[0])
+			}
+			b.WriteString("\n")
+		}
+	}
+	if len(a.Finished) > 0 {
+		b.WriteString("\n### Finished\n\n")
+		for _, d := range a.Finished {
+			fmt.Fprintf(&b, "- %s\n", title(d.ID, d.Title))
+		}
+	}
+	if len(a.Sessions) > 0 {
+		b.WriteString("\n### Reading\n\n")
+		for _, rs := range a.Sessions {
+			fmt.Fprintf(&b, "- %s %s", rs.StartAt.Format("15:04"), title(rs.DocumentID, rs.Title))
+			var details []string
+			if rs.Minutes > 0 {
+				details = append(details, fmt.Sprintf("%d min", rs.Minutes))
+			}
+			if rs.PagesRead > 0 {
+				details = append(details, fmt.Sprintf("%d pages", rs.PagesRead))
+			}
+			if len(details) > 0 {
+				fmt.Fprintf(&b, " (%s)", strings.Join(details, ", "))
+			}
+			if rs.Notes != "" {
+				fmt.Fprintf(&b, ": %s", oneLine(rs.Notes))
+			}
+			b.WriteString("\n")
+		}
+	}
+	if len(a.Annotations) > 0 {
+		b.WriteString("\n### Annotations\n\n")
+		for _, ann := range a.Annotations {
+			fmt.Fprintf(&b, "- %s", title(ann.DocumentID, a.titles[ann.DocumentID]))
+			if ann.Page > 0 {
+				fmt.Fprintf(&b, ", p. %d", ann.Page)
+			}
+			if ann.Content != "" {
+				fmt.Fprintf(&b, ": %s", oneLine(ann.Content))
+			} else {
+				fmt.Fprintf(&b, ": %s", ann.Type)
+			}
+			b.WriteString("\n")
+		}
+	}
+	if len(a.TasksCompleted) > 0 {
+		b.WriteString("\n### Tasks done\n\n")
+		for _, t := range a.TasksCompleted {
+			fmt.Fprintf(&b, "- [x] %s\n", oneLine(t.Description))
+		}
+	}
+	if a.Reviews > 0 {
+		fmt.Fprintf(&b, "\n### Flashcards\n\n- Reviewed %d card(s)", a.CardsReviewed)
+		if a.Reviews > a.CardsReviewed {
+			fmt.Fprintf(&b, " (%d reviews)", a.Reviews)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// DailyNotePath is the path of the daily note for day in dir, named by
+// the Go time layout nameLayout (DefaultDailyNoteName if empty).
+func DailyNotePath(dir, nameLayout string, day time.Time) string {
+	if nameLayout == "" {
+		nameLayout = DefaultDailyNoteName
+	}
+	return filepath.Join(dir, day.Format(nameLayout))
+}
+
+// WriteDailyNote adds section to the note at path, creating the note and
+// its directory if needed. A section written before is replaced in place;
+// the rest of the note is left as it is.
+func WriteDailyNote(path, section string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	block := dailyNoteStart + "\n" + strings.TrimRight(section, "\n") + "\n" + dailyNoteEnd + "\n"
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	note := string(data)
+	start := strings.Index(note, dailyNoteStart)
+	end := strings.Index(note, dailyNoteEnd)
+	switch {
+	case start >= 0 && end > start:
+		rest := strings.TrimPrefix(note[end+len(dailyNoteEnd):], "\n")
+		note = note[:start] + block + rest
+	case note == "":
+		note = block
+	default:
+		if !strings.HasSuffix(note, "\n") {
+			note += "\n"
+		}
+		note += "\n" + block
+	}
+	return os.WriteFile(path, []byte(note), 0o644)
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+func TestComputeDayActivity(t *testing.T) {
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	doc := &Document{Title: "Attention Is All You Need", Authors: []string{"Ashish Vaswani", "Noam Shazeer"}}
+	done := &Document{Title: "Layer Normalization", Status: StatusCompleted, ReadAt: now}
+	for _, d := range []*Document{doc, done} {
+		if err := s.AddDocument(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	session, err := s.StartSession(doc.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.EndSession(session.ID, 12, "Section 3\nneeds a reread"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddAnnotation(&Annotation{DocumentID: doc.ID, Type: "highlight", Content: "Attention is all you need", Page: 2, CreatedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+	card := &Flashcard{DocumentID: doc.ID, Type: "basic", Front: "Q", Back: "A"}
+	if err := s.AddFlashcard(card); err != nil {
+		t.Fatal(err)
+	}
+	for _, q := range []int{2, 4} {
+		if _, err := s.ReviewFlashcard(card.ID, q); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a, err := ComputeDayActivity(s, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Date != now.Format("2006-01-02") || len(a.Added) != 2 || len(a.Finished) != 1 || len(a.Sessions) != 1 ||
+		len(a.Annotations) != 1 || a.CardsReviewed != 1 || a.Reviews != 2 {
+		t.Fatalf("activity = %+v", a)
+	}
+
+	// The KV store has no tasks, so add a completed one by hand
+	a.TasksCompleted = append(a.TasksCompleted, &Task{Description: "Summarize the paper", Status: "done"})
+
+	md := a.Markdown(true)
+	for _, want := range []string{
+		"## Library\n",
+		"- [[Attention Is All You Need]] (Ashish Vaswani et al.)\n",
+		"### Finished\n\n- [[Layer Normalization]]\n",
+		"(12 pages): Section 3 needs a reread\n",
+		"- [[Attention Is All You Need]], p. 2: Attention is all you need\n",
+		"- [x] Summarize the paper\n",
+		"- Reviewed 1 card(s) (2 reviews)\n",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown lacks %q:\n%s", want, md)
+		}
+	}
+
+	yesterday, err := ComputeDayActivity(s, now.AddDate(0, 0, -1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !yesterday.Empty() || !strings.Contains(yesterday.Markdown(false), "No library activity.") {
+		t.Errorf("yesterday = %+v", yesterday)
+	}
+}
+
+func TestWriteDailyNote(t *testing.T) {
+	dir := t.TempDir()
+	day := time.Date(2025, 3, 14, 9, 0, 0, 0, time.Local)
+	if got := DailyNotePath(dir, "", day); got != filepath.Join(dir, "2025-03-14.md") {
+		t.Errorf("path = %s", got)
+	}
+	path := DailyNotePath(dir, "journals/2006_01_02.md", day)
+
+	if err := WriteDailyNote(path, "## Library\n\n- first\n"); err != nil {
+		t.Fatal(err)
+	}
+	// The user's own writing around the section survives an update
+	data, _ := os.ReadFile(path)
+	note := "# Friday\n\nMy notes.\n" + string(data) + "\nEvening thoughts.\n"
+	if err := os.WriteFile(path, []byte(note), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteDailyNote(path, "## Library\n\n- second\n"); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(path)
+	want := "# Friday\n\nMy notes.\n" + dailyNoteStart + "\n## Library\n\n- second\n" + dailyNoteEnd + "\n\nEvening thoughts.\n"
+	if string(data) != want {
+		t.Errorf("note =\n%s\nwant\n%s", data, want)
+	}
+
+	// An existing note without the section gets it appended
+	other := filepath.Join(dir, "other.md")
+	os.WriteFile(other, []byte("# Today"), 0o644)
+	if err := WriteDailyNote(other, "## Library\n"); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(other)
+	if string(data) != "# Today\n\n"+dailyNoteStart+"\n## Library\n"+dailyNoteEnd+"\n" {
+		t.Errorf("appended note = %q", data)
+	}
+}
@@ -0,0 +1,291 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseDate reads a date the way people write one, relative to now:
+//
+//   - ISO dates and times: 2025-01-31, 2025-01-31 14:30, RFC 3339
+//   - numeric dates in the locale's order (LC_ALL, LC_TIME, or LANG):
+//     01/31/2025 in the US, 31/01/2025 elsewhere; 31.01.2025 is always
+//     day first, and the year may be left out
+//   - month names: jan 31, January 31, 2025, 31 jan 2025
+//   - today, tomorrow, yesterday, now
+//   - weekdays: friday is the coming one, today if it is Friday; next
+//     friday is the first after today; last friday the last before it
+//   - next week, next month, next year, and last ...
+//   - in 2 weeks, in 3d, 2 days ago, in an hour
+//
+// Any of them may be followed by a time: 17:00, 5pm, 5:30 pm, noon, or
+// midnight, with or without "at"; a time alone is today. A date without a
+// time is midnight in now's location.
+func ParseDate(s string, now time.Time) (time.Time, error) {
+	orig := strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339, orig); err == nil {
+		return t, nil
+	}
+	fail := func() (time.Time, error) {
+		return time.Time{}, fmt.Errorf("unrecognized date %q (use e.g. 2025-01-31, tomorrow, next friday, in 2 weeks, or friday 5pm)", orig)
+	}
+
+	s = strings.Join(strings.Fields(strings.ToLower(strings.ReplaceAll(orig, ",", " "))), " ")
+	if s == "" {
+		return fail()
+	}
+
+	// Split off the time of day
+	datePart, clock, hasClock := s, time.Duration(0), false
+	if i := strings.LastIndex(" "+s, " at "); i >= 0 {
+		c, ok := parseClock(s[i+3:])
+		if !ok {
+			return fail()
+		}
+		datePart, clock, hasClock = strings.TrimSpace(s[:max(i-1, 0)]), c, true
+	} else if c, ok := parseClock(s); ok {
+		datePart, clock, hasClock = "", c, true
+	} else if fields := strings.Fields(s); len(fields) > 1 {
+		// 5pm, or 5 pm, at the end
+		for n := 2; n >= 1; n-- {
+			if len(fields) <= n {
+				continue
+			}
+			if c, ok := parseClock(strings.Join(fields[len(fields)-n:], " ")); ok {
+				datePart, clock, hasClock = strings.Join(fields[:len(fields)-n], " "), c, true
+				break
+			}
+		}
+	}
+	// ISO dates with a time joined by T
+	if d, c, ok := strings.Cut(datePart, "t"); ok && isoDate.MatchString(d) {
+		if c, ok := parseClock(c); ok && !hasClock {
+			datePart, clock, hasClock = d, c, true
+		}
+	}
+
+	day, exact, ok := parseDay(datePart, now)
+	if !ok {
+		return fail()
+	}
+	if exact && !hasClock {
+		return day, nil
+	}
+	y, m, d := day.Date()
+	// Set the clock rather than add to midnight, which a DST change moves
+	return time.Date(y, m, d, int(clock.Hours()), int(clock.Minutes())%60, int(clock.Seconds())%60, 0, now.Location()), nil
+}
+
+var (
+	isoDate      = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	numericDate  = regexp.MustCompile(`^(\d{1,2})([/.-])(\d{1,2})(?:[/.-](\d{2}|\d{4}))?$`)
+	clockPattern = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(?::(\d{2}))?\s*(am|pm|a\.m\.|p\.m\.)?$`)
+	relative     = regexp.MustCompile(`^(?:in (\d+|an?) ?([a-z]+)|(\d+|an?) ?([a-z]+) ago)$`)
+)
+
+// parseDay reads the date part of ParseDate's input. exact reports that
+// the result is a moment, as for "now" or "in 2 hours", rather than a day.
+func parseDay(s string, now time.Time) (t time.Time, exact, ok bool) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch s {
+	case "", "today":
+		return today, false, true
+	case "now":
+		return now, true, true
+	case "tomorrow":
+		return today.AddDate(0, 0, 1), false, true
+	case "yesterday":
+		return today.AddDate(0, 0, -1), false, true
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, now.Location()); err == nil {
+		return t, false, true
+	}
+
+	if m := relative.FindStringSubmatch(s); m != nil {
+		count, unit, sign := m[1], m[2], 1
+		if count == "" {
+			count, unit, sign = m[3], m[4], -1
+		}
+		n := 1
+		if count != "a" && count != "an" {
+			n, _ = strconv.Atoi(count)
+		}
+		return addUnits(now, today, unit, sign*n)
+	}
+
+	fields := strings.Fields(s)
+	if len(fields) == 2 && (fields[0] == "next" || fields[0] == "last" || fields[0] == "this") {
+		if wd, ok := parseWeekday(fields[1]); ok {
+			diff := (int(wd) - int(today.Weekday()) + 7) % 7
+			switch fields[0] {
+			case "next":
+				if diff == 0 {
+					diff = 7
+				}
+			case "last":
+				diff -= 7
+			}
+			return today.AddDate(0, 0, diff), false, true
+		}
+		if fields[0] == "this" {
+			return time.Time{}, false, false
+		}
+		sign := 1
+		if fields[0] == "last" {
+			sign = -1
+		}
+		return addUnits(now, today, fields[1], sign)
+	}
+	if wd, ok := parseWeekday(s); ok {
+		return today.AddDate(0, 0, (int(wd)-int(today.Weekday())+7)%7), false, true
+	}
+
+	if m := numericDate.FindStringSubmatch(s); m != nil {
+		a, _ := strconv.Atoi(m[1])
+		b, _ := strconv.Atoi(m[3])
+		day, month := a, b
+		if m[2] != "." && MonthFirstLocale() {
+			day, month = b, a
+		}
+		return makeDate(m[4], month, day, now)
+	}
+
+	// Month names, either side of the day, with an optional year
+	if len(fields) == 2 || len(fields) == 3 {
+		year := ""
+		if len(fields) == 3 {
+			year = fields[2]
+		}
+		for _, order := range [][2]string{{fields[0], fields[1]}, {fields[1], fields[0]}} {
+			month, mok := parseMonth(order[0])
+			day, err := strconv.Atoi(strings.TrimRight(order[1], "stndrh."))
+			if mok && err == nil {
+				return makeDate(year, int(month), day, now)
+			}
+		}
+	}
+	return time.Time{}, false, false
+}
+
+// addUnits moves n of unit from now, for minutes and hours, or from today.
+func addUnits(now, today time.Time, unit string, n int) (time.Time, bool, bool) {
+	switch strings.TrimSuffix(unit, "s") {
+	case "m", "min", "minute":
+		return now.Add(time.Duration(n) * time.Minute), true, true
+	case "h", "hr", "hour":
+		return now.Add(time.Duration(n) * time.Hour), true, true
+	case "d", "day":
+		return today.AddDate(0, 0, n), false, true
+	case "w", "wk", "week":
+		return today.AddDate(0, 0, 7*n), false, true
+	case "mo", "month":
+		return today.AddDate(0, n, 0), false, true
+	case "y", "yr", "year":
+		return today.AddDate(n, 0, 0), false, true
+	}
+	return time.Time{}, false, false
+}
+
+// makeDate builds a date from its parts, rejecting ones like February 30.
+// A missing year is the current one; two digits are in this century.
+func makeDate(year string, month, day int, now time.Time) (time.Time, bool, bool) {
+	y := now.Year()
+	if year != "" {
+		var err error
+		if y, err = strconv.Atoi(year); err != nil {
+			return time.Time{}, false, false
+		}
+		if len(year) == 2 {
+			y += 2000
+		}
+	}
+	t := time.Date(y, time.Month(month), day, 0, 0, 0, 0, now.Location())
+	if t.Month() != time.Month(month) || t.Day() != day {
+		return time.Time{}, false, false
+	}
+	return t, false, true
+}
+
+// parseClock reads a time of day as an offset from midnight.
+func parseClock(s string) (time.Duration, bool) {
+	switch s {
+	case "noon", "midday":
+		return 12 * time.Hour, true
+	case "midnight":
+		return 0, true
+	}
+	m := clockPattern.FindStringSubmatch(s)
+	if m == nil || (m[2] == "" && m[4] == "") {
+		// A bare number is a day or a count, not a time
+		return 0, false
+	}
+	h, _ := strconv.Atoi(m[1])
+	mins, _ := strconv.Atoi(m[2])
+	secs, _ := strconv.Atoi(m[3])
+	if m[4] != "" {
+		if h < 1 || h > 12 {
+			return 0, false
+		}
+		h %= 12
+		if strings.HasPrefix(m[4], "p") {
+			h += 12
+		}
+	}
+	if h > 23 || mins > 59 || secs > 59 {
+		return 0, false
+	}
+	return time.Duration(h)*time.Hour + time.Duration(mins)*time.Minute + time.Duration(secs)*time.Second, true
+}
+
+// parseWeekday reads a weekday name or its abbreviation.
+func parseWeekday(s string) (time.Weekday, bool) {
+	for wd := time.Sunday; wd <= time.Saturday; wd++ {
+		name := strings.ToLower(wd.String())
+		if s == name || (len(s) >= 3 && strings.HasPrefix(name, s)) {
+			return wd, true
+		}
+	}
+	return 0, false
+}
+
+// parseMonth reads a month name or its abbreviation, such as jan or sept.
+func parseMonth(s string) (time.Month, bool) {
+	s = strings.TrimSuffix(s, ".")
+	for m := time.January; m <= time.December; m++ {
+		name := strings.ToLower(m.String())
+		if s == name || (len(s) >= 3 && strings.HasPrefix(name, s)) {
+			return m, true
+		}
+	}
+	return 0, false
+}
+
+// MonthFirstLocale reports whether numeric dates are written month first,
+// as in the US, going by the locale in LC_ALL, LC_TIME, or LANG. Without
+// a locale, or with C or POSIX, dates are month first too.
+func MonthFirstLocale() bool {
+	locale := ""
+	for _, env := range []string{"LC_ALL", "LC_TIME", "LANG"} {
+		if locale = os.Getenv(env); locale != "" {
+			break
+		}
+	}
+	locale, _, _ = strings.Cut(locale, ".")
+	switch locale {
+	case "", "C", "POSIX":
+		return true
+	}
+	_, region, _ := strings.Cut(locale, "_")
+	switch region {
+	case "US", "PH", "FM", "MH", "PW", "BZ":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDate(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_TIME", "")
+	t.Setenv("LANG", "en_US.UTF-8")
+	// A Wednesday afternoon
+	now := time.Date(2025, 3, 12, 15, 4, 5, 0, time.Local)
+	day := func(m time.Month, d, h, min int) time.Time { return time.Date(2025, m, d, h, min, 0, 0, time.Local) }
+
+	for in, want := range map[string]time.Time{
+		"2025-04-01":           day(4, 1, 0, 0),
+		"2025-04-01 14:30":     day(4, 1, 14, 30),
+		"2025-04-01T14:30":     day(4, 1, 14, 30),
+		"today":                day(3, 12, 0, 0),
+		"Tomorrow":             day(3, 13, 0, 0),
+		"yesterday":            day(3, 11, 0, 0),
+		"now":                  now,
+		"friday":               day(3, 14, 0, 0),
+		"wednesday":            day(3, 12, 0, 0),
+		"next wednesday":       day(3, 19, 0, 0),
+		"next fri":             day(3, 14, 0, 0),
+		"last monday":          day(3, 10, 0, 0),
+		"next week":            day(3, 19, 0, 0),
+		"next month":           day(4, 12, 0, 0),
+		"in 2 weeks":           day(3, 26, 0, 0),
+		"in 3d":                day(3, 15, 0, 0),
+		"2 days ago":           day(3, 10, 0, 0),
+		"in an hour":           now.Add(time.Hour),
+		"tomorrow 9am":         day(3, 13, 9, 0),
+		"friday at 5:30 pm":    day(3, 14, 17, 30),
+		"next friday at noon":  day(3, 14, 12, 0),
+		"in 2 days at 17:00":   day(3, 14, 17, 0),
+		"5pm":                  day(3, 12, 17, 0),
+		"at 08:15":             day(3, 12, 8, 15),
+		"04/01/2025":           day(4, 1, 0, 0),
+		"4/1":                  day(4, 1, 0, 0),
+		"01.04.2025":           day(4, 1, 0, 0),
+		"Apr 1":                day(4, 1, 0, 0),
+		"April 1st, 2025 10am": day(4, 1, 10, 0),
+		"1 april 25":           day(4, 1, 0, 0),
+	} {
+		got, err := ParseDate(in, now)
+		if err != nil {
+			t.Errorf("ParseDate(%q): %v", in, err)
+			continue
+		}
+		if !got.Equal(want) {
+			t.Errorf("ParseDate(%q) = %s, want %s", in, got, want)
+		}
+	}
+
+	if got, err := ParseDate("2025-04-01T14:30:00Z", now); err != nil || !got.Equal(time.Date(2025, 4, 1, 14, 30, 0, 0, time.UTC)) {
+		t.Errorf("RFC 3339 = %s, %v", got, err)
+	}
+
+	// Outside the US, numeric dates are day first
+	t.Setenv("LANG", "de_DE.UTF-8")
+	if got, err := ParseDate("04/01/2025", now); err != nil || !got.Equal(day(1, 4, 0, 0)) {
+		t.Errorf("04/01/2025 in de_DE = %s, %v", got, err)
+	}
+
+	for _, in := range []string{"", "someday", "feb 30", "13/13/2025", "friday 5", "25pm", "this month", "at lunch"} {
+		if got, err := ParseDate(in, now); err == nil {
+			t.Errorf("ParseDate(%q) = %s, want an error", in, got)
+		}
+	}
+}
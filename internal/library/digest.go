@@ -0,0 +1,342 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"html/template"
+	"net"
+	"net/smtp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/emersion/go-message/mail"
+)
+
+// digestCardSample is how many due flashcards a digest lists by name; the
+// rest are only counted.
+const digestCardSample = 5
+
+// Digest summarizes the library's recent activity and what is coming up,
+// for sending by email.
+type Digest struct {
+	Since         time.Time          `json:"since"`
+	Until         time.Time          `json:"until"`
+	Added         []*Document        `json:"added"`
+	Annotations   []DigestAnnotation `json:"annotations"`
+	DueCards      int                `json:"due_cards"`
+	DueSample     []*Flashcard       `json:"due_sample"` // the first few due cards
+	UpcomingTasks []*Task            `json:"upcoming_tasks"`
+}
+
+// DigestAnnotation is an annotation with its document's title.
+type DigestAnnotation struct {
+	*Annotation
+	Title string `json:"title"`
+}
+
+// Empty reports whether the digest has nothing to report.
+func (d *Digest) Empty() bool {
+	return len(d.Added) == 0 && len(d.Annotations) == 0 && d.DueCards == 0 && len(d.UpcomingTasks) == 0
+}
+
+// ComputeDigest gathers the documents added and annotations made since
+// since, the flashcards due at now, and the open tasks due before now plus
+// ahead, overdue ones included. Per-document lookups that fail are
+// skipped, as in ComputeDayActivity.
+func ComputeDigest(s LibraryStore, since, now time.Time, ahead time.Duration) (*Digest, error) {
+	d := &Digest{
+		Since:         since,
+		Until:         now,
+		Added:         []*Document{},
+		Annotations:   []DigestAnnotation{},
+		DueSample:     []*Flashcard{},
+		UpcomingTasks: []*Task{},
+	}
+
+	docs, err := s.ListDocuments(nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, doc := range docs {
+		if !doc.CreatedAt.Before(since) && !doc.CreatedAt.After(now) {
+			d.Added = append(d.Added, doc)
+		}
+		if anns, err := s.GetAnnotations(doc.ID); err == nil {
+			for _, ann := range anns {
+				if !ann.CreatedAt.Before(since) && !ann.CreatedAt.After(now) {
+					d.Annotations = append(d.Annotations, DigestAnnotation{Annotation: ann, Title: doc.Title})
+				}
+			}
+		}
+	}
+	sort.SliceStable(d.Added, func(i, j int) bool { return d.Added[i].CreatedAt.Before(d.Added[j].CreatedAt) })
+	sort.SliceStable(d.Annotations, func(i, j int) bool { return d.Annotations[i].CreatedAt.Before(d.Annotations[j].CreatedAt) })
+
+	cards, err := s.GetDueFlashcards(now)
+	if err != nil {
+		return nil, err
+	}
+	d.DueCards = len(cards)
+	d.DueSample = append(d.DueSample, cards[:min(len(cards), digestCardSample)]...)
+
+	// Not every backend has tasks
+	if tasks, err := s.ListTasks(&TaskListOptions{Open: true, DueBefore: now.Add(ahead)}); err == nil {
+		d.UpcomingTasks = tasks
+		sort.SliceStable(d.UpcomingTasks, func(i, j int) bool { return d.UpcomingTasks[i].DueAt.Before(*d.UpcomingTasks[j].DueAt) })
+	}
+	return d, nil
+}
+
+// Subject is the digest email's subject line.
+func (d *Digest) Subject() string {
+	var parts []string
+	if n := len(d.Added); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d new", n))
+	}
+	if d.DueCards > 0 {
+		parts = append(parts, fmt.Sprintf("%d cards due", d.DueCards))
+	}
+	if n := len(d.UpcomingTasks); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d tasks due", n))
+	}
+	subject := "Library digest " + d.Until.Format("2006-01-02")
+	if len(parts) > 0 {
+		subject += ": " + strings.Join(parts, ", ")
+	}
+	return subject
+}
+
+// Text renders the digest as plain text.
+func (d *Digest) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Library digest, %s to %s\n", d.Since.Format("2006-01-02"), d.Until.Format("2006-01-02"))
+	if d.Empty() {
+		b.WriteString("\nNothing new, and nothing due.\n")
+		return b.String()
+	}
+	if len(d.Added) > 0 {
+		fmt.Fprintf(&b, "\nNew documents (%d)\n\n", len(d.Added))
+		for _, doc := range d.Added {
+			fmt.Fprintf(&b, "- %s", oneLine(doc.Title))
+			if a := firstAuthor(doc); a != "" {
+				fmt.Fprintf(&b, " (%s)", a)
+			}
+			b.WriteString("\n")
+		}
+	}
+	if len(d.Annotations) > 0 {
+		fmt.Fprintf(&b, "\nAnnotations (%d)\n\n", len(d.Annotations))
+		for _, ann := range d.Annotations {
+			fmt.Fprintf(&b, "- %s: %s\n", oneLine(ann.Title), annotationLine(ann.Annotation))
+		}
+	}
+	if d.DueCards > 0 {
+		fmt.Fprintf(&b, "\nFlashcards due (%d)\n\n", d.DueCards)
+		for _, c := range d.DueSample {
+			fmt.Fprintf(&b, "- %s\n", oneLine(c.Front))
+		}
+		if more := d.MoreCards(); more > 0 {
+			fmt.Fprintf(&b, "- and %d more\n", more)
+		}
+	}
+	if len(d.UpcomingTasks) > 0 {
+		fmt.Fprintf(&b, "\nTasks due (%d)\n\n", len(d.UpcomingTasks))
+		for _, t := range d.UpcomingTasks {
+			fmt.Fprintf(&b, "- %s %s", HomeDate(*t.DueAt), oneLine(t.Description))
+			if d.Overdue(t) {
+				b.WriteString(" (overdue)")
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+func firstAuthor(doc *Document) string {
+	var authors []string
+	for _, a := range doc.Authors {
+		if a = strings.TrimSpace(a); a != "" {
+			authors = append(authors, a)
+		}
+	}
+	switch len(authors) {
+	case 0:
+		return ""
+	case 1:
+		return authors[0]
+	}
+	return authors[0] + " et al."
+}
+
+func annotationLine(ann *Annotation) string {
+	s := ann.Type
+	if ann.Content != "" {
+		s = oneLine(ann.Content)
+	}
+	if ann.Page > 0 {
+		s = fmt.Sprintf("p. %d, %s", ann.Page, s)
+	}
+	return s
+}
+
+var digestHTMLTemplate = template.Must(template.New("digest").Funcs(template.FuncMap{
+	"author":     firstAuthor,
+	"annotation": annotationLine,
+	"date":       func(t *time.Time) string { return HomeDate(*t) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif; line-height: 1.5; color: #333; max-width: 700px;">
+<h2 style="color: #2c3e50;">Library digest</h2>
+<p style="color: #666;">{{.Since.Format "2006-01-02"}} to {{.Until.Format "2006-01-02"}}</p>
+{{if .Empty}}<p>Nothing new, and nothing due.</p>{{end}}
+{{if .Added}}
+<h3>New documents ({{len .Added}})</h3>
+<ul>{{range .Added}}<li>{{.Title}}{{with author .}} <span style="color: #666;">({{.}})</span>{{end}}</li>{{end}}</ul>
+{{end}}
+{{if .Annotations}}
+<h3>Annotations ({{len .Annotations}})</h3>
+<ul>{{range .Annotations}}<li><b>{{.Title}}</b>: {{annotation .Annotation}}</li>{{end}}</ul>
+{{end}}
+{{if .DueCards}}
+<h3>Flashcards due ({{.DueCards}})</h3>
+<ul>{{range .DueSample}}<li>{{.Front}}</li>{{end}}{{if .MoreCards}}<li>and {{.MoreCards}} more</li>{{end}}</ul>
+{{end}}
+{{if .UpcomingTasks}}
+<h3>Tasks due ({{len .UpcomingTasks}})</h3>
+<ul>{{range .UpcomingTasks}}<li>{{date .DueAt}} {{.Description}}{{if $.Overdue .}} <span style="color: #c0392b;">(overdue)</span>{{end}}</li>{{end}}</ul>
+{{end}}
+</body>
+</html>
+`))
+
+// MoreCards is how many due cards the digest doesn't list.
+func (d *Digest) MoreCards() int {
+	return d.DueCards - len(d.DueSample)
+}
+
+// Overdue reports whether a task was due before the day the digest was
+// made.
+func (d *Digest) Overdue(t *Task) bool {
+	return t.DueAt != nil && Overdue(*t.DueAt, d.Until)
+}
+
+// HTML renders the digest as an HTML email body.
+func (d *Digest) HTML() (string, error) {
+	var b strings.Builder
+	if err := digestHTMLTemplate.Execute(&b, d); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// Message builds the digest email from from to the recipients, with plain
+// text and HTML alternatives.
+func (d *Digest) Message(from string, to []string) ([]byte, error) {
+	html, err := d.HTML()
+	if err != nil {
+		return nil, err
+	}
+
+	var h mail.Header
+	h.SetDate(d.Until)
+	h.SetSubject(d.Subject())
+	h.SetAddressList("From", []*mail.Address{{Name: "Arc Library", Address: from}})
+	var rcpt []*mail.Address
+	for _, addr := range to {
+		rcpt = append(rcpt, &mail.Address{Address: addr})
+	}
+	h.SetAddressList("To", rcpt)
+	if err := h.GenerateMessageID(); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	w, err := mail.CreateInlineWriter(&buf, h)
+	if err != nil {
+		return nil, err
+	}
+	for _, part := range []struct{ typ, body string }{{"text/plain", d.Text()}, {"text/html", html}} {
+		var ph mail.InlineHeader
+		ph.SetContentType(part.typ, map[string]string{"charset": "utf-8"})
+		pw, err := w.CreatePart(ph)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := pw.Write([]byte(part.body)); err != nil {
+			return nil, err
+		}
+		if err := pw.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SMTPConfig is the mail server digests are sent through.
+type SMTPConfig struct {
+	Addr     string // host:port; port 465 uses TLS from the start, others STARTTLS when offered
+	User     string // "" to send without authenticating
+	Password string
+	From     string
+}
+
+// Send delivers msg to the recipients.
+func (c *SMTPConfig) Send(to []string, msg []byte) error {
+	host, port, err := net.SplitHostPort(c.Addr)
+	if err != nil {
+		return fmt.Errorf("invalid SMTP server %q: %w", c.Addr, err)
+	}
+	var auth smtp.Auth
+	if c.User != "" {
+		auth = smtp.PlainAuth("", c.User, c.Password, host)
+	}
+	if port != "465" {
+		if err := smtp.SendMail(c.Addr, auth, c.From, to, msg); err != nil {
+			return fmt.Errorf("send mail via %s: %w", c.Addr, err)
+		}
+		return nil
+	}
+
+	conn, err := tls.Dial("tcp", c.Addr, &tls.Config{ServerName: host})
+	if err != nil {
+		return fmt.Errorf("connect to %s: %w", c.Addr, err)
+	}
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("connect to %s: %w", c.Addr, err)
+	}
+	defer client.Close()
+	if auth != nil {
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("authenticate with %s: %w", c.Addr, err)
+		}
+	}
+	if err := client.Mail(c.From); err != nil {
+		return fmt.Errorf("send mail via %s: %w", c.Addr, err)
+	}
+	for _, addr := range to {
+		if err := client.Rcpt(addr); err != nil {
+			return fmt.Errorf("send mail to %s: %w", addr, err)
+		}
+	}
+	wc, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("send mail via %s: %w", c.Addr, err)
+	}
+	if _, err := wc.Write(msg); err != nil {
+		return fmt.Errorf("send mail via %s: %w", c.Addr, err)
+	}
+	if err := wc.Close(); err != nil {
+		return fmt.Errorf("send mail via %s: %w", c.Addr, err)
+	}
+	return client.Quit()
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/emersion/go-message/mail"
+	"github.com/yourorg/arc-sdk/store"
+)
+
+func TestComputeDigest(t *testing.T) {
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for _, d := range []*Document{
+		{ID: "old", Title: "Old paper", CreatedAt: now.AddDate(0, 0, -30)},
+		{ID: "new", Title: "Fresh <paper>", Authors: []string{"Ada Lovelace", "Charles Babbage"}, CreatedAt: now.Add(-time.Hour)},
+	} {
+		if err := s.AddDocument(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.AddAnnotation(&Annotation{DocumentID: "old", Type: "note", Content: "Still relevant", Page: 4, CreatedAt: now.Add(-time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	for i, front := range []string{"What is attention?", "Define entropy"} {
+		if err := s.AddFlashcard(&Flashcard{ID: string(rune('a' + i)), DocumentID: "new", Front: front, DueAt: now.Add(-time.Minute)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d, err := ComputeDigest(s, now.AddDate(0, 0, -7), now, 7*24*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Added) != 1 || d.Added[0].ID != "new" || len(d.Annotations) != 1 || d.Annotations[0].Title != "Old paper" {
+		t.Fatalf("digest = %+v", d)
+	}
+	if d.DueCards != 2 || len(d.UpcomingTasks) != 0 {
+		t.Fatalf("due = %d cards, tasks %+v", d.DueCards, d.UpcomingTasks)
+	}
+
+	// The KV store has no tasks
+	overdue, soon := now.AddDate(0, 0, -1), now.AddDate(0, 0, 2)
+	d.UpcomingTasks = []*Task{
+		{ID: "t2", Description: "Email reviewers", DueAt: &overdue},
+		{ID: "t1", Description: "Write related work", DueAt: &soon},
+	}
+
+	text := d.Text()
+	for _, want := range []string{"Fresh <paper> (Ada Lovelace et al.)", "Old paper: p. 4, Still relevant", "Email reviewers (overdue)", "What is attention?"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text lacks %q:\n%s", want, text)
+		}
+	}
+	html, err := d.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(html, "Fresh &lt;paper&gt;") {
+		t.Errorf("HTML does not escape titles:\n%s", html)
+	}
+	if got := d.Subject(); !strings.Contains(got, "1 new, 2 cards due, 2 tasks due") {
+		t.Errorf("subject = %q", got)
+	}
+
+	msg, err := d.Message("library@example.org", []string{"me@example.org"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mr, err := mail.CreateReader(bytes.NewReader(msg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var types []string
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if h, ok := p.Header.(*mail.InlineHeader); ok {
+			typ, _, _ := h.ContentType()
+			types = append(types, typ)
+		}
+	}
+	if len(types) != 2 || types[0] != "text/plain" || types[1] != "text/html" {
+		t.Errorf("parts = %q", types)
+	}
+}
+
+func TestSMTPSend(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	// A minimal SMTP server that accepts one message
+	received := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		tp := textproto.NewConn(conn)
+		tp.PrintfLine("220 localhost ready")
+		var data string
+		for {
+			line, err := tp.ReadLine()
+			if err != nil {
+				return
+			}
+			switch cmd := strings.ToUpper(strings.Fields(line + " x")[0]); cmd {
+			case "EHLO", "HELO":
+				tp.PrintfLine("250 localhost")
+			case "DATA":
+				tp.PrintfLine("354 go ahead")
+				b, _ := io.ReadAll(bufio.NewReader(tp.DotReader()))
+				data = string(b)
+				tp.PrintfLine("250 queued")
+			case "QUIT":
+				tp.PrintfLine("221 bye")
+				received <- data
+				return
+			default:
+				tp.PrintfLine("250 ok")
+			}
+		}
+	}()
+
+	c := &SMTPConfig{Addr: ln.Addr().String(), From: "library@example.org"}
+	if err := c.Send([]string{"me@example.org"}, []byte("Subject: hi\r\n\r\nbody\r\n")); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case data := <-received:
+		if !strings.Contains(data, "Subject: hi") {
+			t.Errorf("server got %q", data)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message received")
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mtreilly/arc-library/internal/scheduler"
+)
+
+// DocumentReview is a document's re-reading schedule, for incremental
+// reading: each review is graded like a flashcard's and SM-2 (see package
+// scheduler) picks when the document comes due again.
+type DocumentReview struct {
+	DocumentID  string    `json:"document_id" yaml:"document_id"`
+	DueAt       time.Time `json:"due_at" yaml:"due_at"`
+	Interval    int       `json:"interval" yaml:"interval"` // days until the next review
+	Ease        float64   `json:"ease" yaml:"ease"`         // SM-2 ease factor
+	Reviews     int       `json:"reviews" yaml:"reviews"`
+	LastQuality int       `json:"last_quality" yaml:"last_quality"`
+	LastReview  time.Time `json:"last_review" yaml:"last_review"`
+}
+
+// ReviewDocument records a re-reading of a document at the given quality
+// (0-5) and schedules the next, starting a schedule for a document never
+// reviewed. The next review falls at the start of a day in now's location.
+func ReviewDocument(s LibraryStore, documentID string, quality int, now time.Time) (*DocumentReview, error) {
+	if quality < 0 || quality > 5 {
+		return nil, fmt.Errorf("quality must be 0-5, got %d", quality)
+	}
+	doc, err := s.GetDocument(documentID)
+	if err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		return nil, fmt.Errorf("%w: %s", ErrDocumentNotFound, documentID)
+	}
+	review, err := s.GetDocumentReview(documentID)
+	if err != nil {
+		return nil, err
+	}
+	if review == nil {
+		review = &DocumentReview{DocumentID: documentID}
+	}
+
+	review.Interval, review.Ease = scheduler.Current().Next(review.Interval, review.Ease, quality)
+	review.DueAt = DueDate(now, review.Interval)
+	review.Reviews++
+	review.LastQuality = quality
+	review.LastReview = now.UTC()
+	if err := s.SaveDocumentReview(review); err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// DueDocument is a document due for re-reading, with its schedule.
+type DueDocument struct {
+	Document *Document       `json:"document"`
+	Review   *DocumentReview `json:"review"`
+}
+
+// DueDocuments returns the documents due for re-reading on now's day, the
+// longest overdue first. Schedules for documents no longer in the library
+// are skipped.
+func DueDocuments(s LibraryStore, now time.Time) ([]DueDocument, error) {
+	reviews, err := s.ListDocumentReviews()
+	if err != nil {
+		return nil, err
+	}
+	var due []DueDocument
+	for _, r := range reviews {
+		if !IsDue(r.DueAt, now) {
+			continue
+		}
+		doc, err := s.GetDocument(r.DocumentID)
+		if err != nil {
+			return nil, err
+		}
+		if doc != nil {
+			due = append(due, DueDocument{Document: doc, Review: r})
+		}
+	}
+	return due, nil
+}
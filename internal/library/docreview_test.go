@@ -0,0 +1,77 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+func TestReviewDocument(t *testing.T) {
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	paper := &Document{Title: "Attention"}
+	book := &Document{Title: "Deep Learning"}
+	for _, d := range []*Document{paper, book} {
+		if err := s.AddDocument(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	// Intervals grow 1, 6, then by the ease; a poor review starts them over.
+	// Each falls due at the start of a day.
+	now := start
+	for i, want := range []int{1, 6, 15, 1} {
+		quality := 4
+		if i == 3 {
+			quality = 1
+		}
+		r, err := ReviewDocument(s, paper.ID, quality, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if r.Interval != want || r.Reviews != i+1 || !r.DueAt.Equal(DueDate(now, want)) {
+			t.Errorf("review %d: %+v, want interval %d", i+1, r, want)
+		}
+		now = r.DueAt
+	}
+	if r, _ := s.GetDocumentReview(paper.ID); r == nil || r.LastQuality != 1 || r.Ease >= 2.5 {
+		t.Errorf("stored review = %+v", r)
+	}
+
+	if _, err := ReviewDocument(s, book.ID, 6, start); err == nil {
+		t.Error("quality 6: no error")
+	}
+	if _, err := ReviewDocument(s, "missing", 4, start); err == nil {
+		t.Error("missing document: no error")
+	}
+
+	// The book comes due first; only what is due by then is listed
+	if _, err := ReviewDocument(s, book.ID, 5, start); err != nil {
+		t.Fatal(err)
+	}
+	due, err := DueDocuments(s, start.AddDate(0, 0, 1))
+	if err != nil || len(due) != 1 || due[0].Document.ID != book.ID {
+		t.Fatalf("due = %+v, %v", due, err)
+	}
+	if due, _ = DueDocuments(s, now); len(due) != 2 || due[0].Document.ID != book.ID {
+		t.Errorf("due later = %+v", due)
+	}
+
+	// Stopping or deleting the document drops the schedule
+	if err := s.DeleteDocumentReview(book.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteDocument(paper.ID); err != nil {
+		t.Fatal(err)
+	}
+	if reviews, _ := s.ListDocumentReviews(); len(reviews) != 0 {
+		t.Errorf("reviews left: %+v", reviews)
+	}
+}
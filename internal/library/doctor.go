@@ -0,0 +1,186 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// DoctorStatus is the outcome of a DoctorCheck.
+type DoctorStatus string
+
+const (
+	DoctorOK   DoctorStatus = "ok"
+	DoctorWarn DoctorStatus = "warn" // a feature will not work
+	DoctorFail DoctorStatus = "fail" // the library will not work
+	DoctorSkip DoctorStatus = "skip" // not checked, such as the network with --offline
+)
+
+// DoctorCheck is one check of the environment arc-library runs in: a
+// program it calls, an API it queries, where it keeps data, or a setting.
+type DoctorCheck struct {
+	Group  string       `json:"group"` // programs, network, storage, or config
+	Name   string       `json:"name"`
+	Status DoctorStatus `json:"status"`
+	Detail string       `json:"detail,omitempty"` // what was found
+	Fix    string       `json:"fix,omitempty"`    // what to do about it, when not ok
+}
+
+// DoctorTool is an external program some features run.
+type DoctorTool struct {
+	Name    string
+	Used    string // what needs it
+	Install string // how to install it
+}
+
+// DoctorTools are the external programs arc-library runs.
+var DoctorTools = []DoctorTool{
+	{"pdftotext", "--extract-text, and titles and DOIs read from PDFs", "install poppler: brew install poppler (macOS), apt install poppler-utils (Debian/Ubuntu)"},
+	{"pdftoppm", "PDF thumbnails in the web dashboard", "install poppler: brew install poppler (macOS), apt install poppler-utils (Debian/Ubuntu)"},
+	{"tesseract", `"ocr" of scanned PDFs`, "brew install tesseract (macOS), apt install tesseract-ocr (Debian/Ubuntu); add language packs such as tesseract-ocr-deu for --lang"},
+	{"ocrmypdf", `"ocr --engine ocrmypdf"`, "pipx install ocrmypdf, or brew install ocrmypdf; tesseract alone is enough for \"ocr\""},
+	{"arc-ai", `the "ai" commands and "export --key-points"`, "install arc-ai and make sure the directory it is in is on your PATH"},
+}
+
+// CheckTool reports whether t is on the PATH, and where.
+func CheckTool(t DoctorTool) DoctorCheck {
+	c := DoctorCheck{Name: t.Name}
+	path, err := exec.LookPath(t.Name)
+	if err != nil {
+		c.Status, c.Detail, c.Fix = DoctorWarn, "not found on PATH; needed for "+t.Used, t.Install
+		return c
+	}
+	c.Status, c.Detail = DoctorOK, path
+	return c
+}
+
+// DoctorEndpoint is an API arc-library resolves metadata from.
+type DoctorEndpoint struct {
+	Name string
+	URL  string // a cheap request that any working API answers
+}
+
+// DoctorEndpoints are the APIs checked for reachability, the ones most
+// imports go through.
+func DoctorEndpoints() []DoctorEndpoint {
+	return []DoctorEndpoint{
+		{"Crossref", crossrefAPI + "?rows=0"},
+		{"arXiv", arxivAPI + "?max_results=0"},
+	}
+}
+
+// CheckEndpoint requests e.URL once, without retries. Any answer but a
+// server error or rate limit means the API is reachable.
+func CheckEndpoint(e DoctorEndpoint, timeout time.Duration) DoctorCheck {
+	c := DoctorCheck{Name: e.Name}
+	req, err := http.NewRequest(http.MethodGet, e.URL, nil)
+	if err != nil {
+		c.Status, c.Detail = DoctorFail, err.Error()
+		return c
+	}
+	req.Header.Set("User-Agent", "arc-library/1.0")
+	start := time.Now()
+	resp, err := (&http.Client{Timeout: timeout}).Do(req)
+	if err != nil {
+		c.Status, c.Detail = DoctorWarn, err.Error()
+		c.Fix = "check your connection, firewall, or proxy (HTTPS_PROXY); --offline resolves from the cache meanwhile"
+		return c
+	}
+	resp.Body.Close()
+	elapsed := time.Since(start).Round(time.Millisecond)
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		c.Status, c.Detail = DoctorWarn, fmt.Sprintf("rate limited (%s)", resp.Status)
+		c.Fix = "lower ARC_LIBRARY_API_RATE, and set ARC_LIBRARY_CONTACT_EMAIL for Crossref's polite pool"
+	case resp.StatusCode >= 500:
+		c.Status, c.Detail = DoctorWarn, fmt.Sprintf("%s from %s", resp.Status, req.URL.Host)
+		c.Fix = "the service is having trouble; try again later"
+	default:
+		c.Status, c.Detail = DoctorOK, fmt.Sprintf("%s in %s", req.URL.Host, elapsed)
+	}
+	return c
+}
+
+// CheckDatabaseFile reports whether the database at path can be opened
+// for writing, or, before it exists, created.
+func CheckDatabaseFile(path string) DoctorCheck {
+	c := DoctorCheck{Name: "database"}
+	abs, err := filepath.Abs(path)
+	if err == nil {
+		path = abs
+	}
+	info, err := os.Stat(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		dc := CheckWritableDir("database", filepath.Dir(path))
+		if dc.Status == DoctorOK {
+			dc.Detail = path + " will be created"
+		} else {
+			dc.Status = DoctorFail
+		}
+		return dc
+	case err != nil:
+		c.Status, c.Detail = DoctorFail, err.Error()
+		return c
+	case info.IsDir():
+		c.Status, c.Detail, c.Fix = DoctorFail, path+" is a directory", "move it aside or point the database elsewhere"
+		return c
+	}
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		c.Status, c.Detail = DoctorFail, err.Error()
+		c.Fix = fmt.Sprintf("chmod u+rw %s, or chown it to you if another user created it", path)
+		return c
+	}
+	f.Close()
+	// SQLite writes its journal next to the database
+	if dc := CheckWritableDir("database", filepath.Dir(path)); dc.Status != DoctorOK {
+		dc.Status = DoctorFail
+		return dc
+	}
+	c.Status, c.Detail = DoctorOK, fmt.Sprintf("%s (%d KB)", path, (info.Size()+1023)/1024)
+	return c
+}
+
+// CheckWritableDir reports whether files can be created in dir, which may
+// not exist yet if it can be created.
+func CheckWritableDir(name, dir string) DoctorCheck {
+	c := DoctorCheck{Name: name}
+	existing := dir
+	for {
+		info, err := os.Stat(existing)
+		if err == nil {
+			if !info.IsDir() {
+				c.Status, c.Detail, c.Fix = DoctorWarn, existing+" is not a directory", "move it aside or choose another directory"
+				return c
+			}
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		existing = parent
+	}
+	f, err := os.CreateTemp(existing, ".arc-library-doctor-*")
+	if err != nil {
+		c.Status, c.Detail = DoctorWarn, fmt.Sprintf("cannot write to %s: %v", existing, err)
+		c.Fix = fmt.Sprintf("chmod u+w %s, or choose another directory", existing)
+		return c
+	}
+	f.Close()
+	os.Remove(f.Name())
+	c.Status, c.Detail = DoctorOK, dir
+	if existing != dir {
+		c.Detail += " (will be created)"
+	}
+	return c
+}
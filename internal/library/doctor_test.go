@@ -0,0 +1,75 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCheckEndpoint(t *testing.T) {
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		status int
+		want   DoctorStatus
+	}{
+		{http.StatusOK, DoctorOK},
+		{http.StatusBadRequest, DoctorOK}, // answered, so reachable
+		{http.StatusTooManyRequests, DoctorWarn},
+		{http.StatusBadGateway, DoctorWarn},
+	} {
+		status = tc.status
+		if c := CheckEndpoint(DoctorEndpoint{Name: "test", URL: srv.URL}, time.Second); c.Status != tc.want {
+			t.Errorf("%d: %+v, want %s", tc.status, c, tc.want)
+		}
+	}
+
+	srv.Close()
+	if c := CheckEndpoint(DoctorEndpoint{Name: "test", URL: srv.URL}, time.Second); c.Status != DoctorWarn || c.Fix == "" {
+		t.Errorf("unreachable: %+v", c)
+	}
+}
+
+func TestCheckDatabaseFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "arc.db")
+	if c := CheckDatabaseFile(path); c.Status != DoctorOK {
+		t.Errorf("before creation: %+v", c)
+	}
+	os.WriteFile(path, []byte("x"), 0o644)
+	if c := CheckDatabaseFile(path); c.Status != DoctorOK {
+		t.Errorf("writable: %+v", c)
+	}
+	if c := CheckDatabaseFile(dir); c.Status != DoctorFail {
+		t.Errorf("directory: %+v", c)
+	}
+	if os.Getuid() != 0 { // root can write anything
+		os.Chmod(path, 0o444)
+		if c := CheckDatabaseFile(path); c.Status != DoctorFail || c.Fix == "" {
+			t.Errorf("read-only: %+v", c)
+		}
+	}
+
+	if c := CheckWritableDir("files", filepath.Join(dir, "a", "b")); c.Status != DoctorOK {
+		t.Errorf("missing directory under a writable one: %+v", c)
+	}
+	if c := CheckWritableDir("files", path); c.Status != DoctorWarn {
+		t.Errorf("a file: %+v", c)
+	}
+}
+
+func TestCheckTool(t *testing.T) {
+	if c := CheckTool(DoctorTool{Name: "arc-library-no-such-tool", Install: "install it"}); c.Status != DoctorWarn || c.Fix != "install it" {
+		t.Errorf("missing tool: %+v", c)
+	}
+}
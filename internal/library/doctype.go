@@ -0,0 +1,131 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// DocumentTypes are the known document types.
+var DocumentTypes = []DocumentType{
+	DocTypePaper, DocTypeBook, DocTypeArticle, DocTypeVideo, DocTypeNote, DocTypeRepo, DocTypeOther,
+}
+
+// TypeRule gives documents matching a condition a type. Conditions are:
+//
+//	isbn           an ISBN, from Open Library or in meta
+//	journal        a DOI and a journal name
+//	doi            a DOI
+//	arxiv          an arXiv ID
+//	html           a web page saved as a bookmark, without a file
+//	host:<domain>  a URL on the domain or a subdomain of it
+//	ext:<.ext>     a file with the extension
+type TypeRule struct {
+	Match string       `json:"match"`
+	Type  DocumentType `json:"type"`
+}
+
+// DefaultTypeRules are the built-in type detection rules, in order.
+var DefaultTypeRules = []TypeRule{
+	{"isbn", DocTypeBook},
+	{"host:youtube.com", DocTypeVideo},
+	{"host:youtu.be", DocTypeVideo},
+	{"host:vimeo.com", DocTypeVideo},
+	{"host:github.com", DocTypeRepo},
+	{"host:gitlab.com", DocTypeRepo},
+	{"journal", DocTypePaper},
+	{"ext:.epub", DocTypeBook},
+	{"html", DocTypeArticle},
+}
+
+// ParseTypeRules parses comma-separated rules such as
+// "host:nature.com=paper,ext:.djvu=book". They are tried before
+// DefaultTypeRules, so they can override them; an empty string yields
+// DefaultTypeRules.
+func ParseTypeRules(s string) ([]TypeRule, error) {
+	var rules []TypeRule
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		match, typ, ok := strings.Cut(field, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid type rule %q (want condition=type)", field)
+		}
+		rule := TypeRule{Match: strings.ToLower(strings.TrimSpace(match)), Type: DocumentType(strings.ToLower(strings.TrimSpace(typ)))}
+		if !slices.Contains(DocumentTypes, rule.Type) {
+			return nil, fmt.Errorf("invalid type rule %q: unknown type %q", field, rule.Type)
+		}
+		if err := checkTypeCondition(rule.Match); err != nil {
+			return nil, fmt.Errorf("invalid type rule %q: %w", field, err)
+		}
+		rules = append(rules, rule)
+	}
+	return append(rules, DefaultTypeRules...), nil
+}
+
+func checkTypeCondition(match string) error {
+	switch match {
+	case "isbn", "journal", "doi", "arxiv", "html":
+		return nil
+	}
+	kind, arg, _ := strings.Cut(match, ":")
+	switch {
+	case kind == "host" && arg != "":
+		return nil
+	case kind == "ext" && strings.HasPrefix(arg, ".") && len(arg) > 1:
+		return nil
+	}
+	return fmt.Errorf("unknown condition %q (use isbn, journal, doi, arxiv, html, host:<domain>, or ext:<.ext>)", match)
+}
+
+// DetectDocumentType returns the type of the first rule doc matches, and
+// false when none does.
+func DetectDocumentType(doc *Document, rules []TypeRule) (DocumentType, bool) {
+	for _, r := range rules {
+		if matchesTypeRule(doc, r.Match) {
+			return r.Type, true
+		}
+	}
+	return "", false
+}
+
+func matchesTypeRule(doc *Document, match string) bool {
+	switch match {
+	case "isbn":
+		isbn, _ := doc.Meta["isbn"].(string)
+		return doc.Source == IDSourceISBN || isbn != ""
+	case "journal":
+		journal, _ := doc.Meta["journal"].(string)
+		return documentDOI(doc) != "" && journal != ""
+	case "doi":
+		return documentDOI(doc) != ""
+	case "arxiv":
+		arxiv, _ := doc.Meta["arxiv"].(string)
+		return doc.Source == IDSourceArxiv || arxiv != ""
+	case "html":
+		return doc.Source == "url" && doc.Path == ""
+	}
+
+	kind, arg, _ := strings.Cut(match, ":")
+	switch kind {
+	case "host":
+		raw, _ := doc.Meta["url"].(string)
+		u, err := url.Parse(raw)
+		if err != nil || u.Host == "" {
+			return false
+		}
+		host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+		arg = strings.TrimPrefix(arg, "www.")
+		return host == arg || strings.HasSuffix(host, "."+arg)
+	case "ext":
+		return doc.Path != "" && strings.EqualFold(filepath.Ext(doc.Path), arg)
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import "testing"
+
+func TestDetectDocumentType(t *testing.T) {
+	rules, err := ParseTypeRules("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name string
+		doc  *Document
+		want DocumentType
+	}{
+		{"isbn", &Document{Source: IDSourceISBN, SourceID: "9780262033848"}, DocTypeBook},
+		{"journal doi", &Document{Source: IDSourceDOI, SourceID: "10.1038/nature12373", Meta: JSONMap{"journal": "Nature"}}, DocTypePaper},
+		{"youtube", &Document{Source: "url", Meta: JSONMap{"url": "https://www.youtube.com/watch?v=kCc8FmEb1nY"}}, DocTypeVideo},
+		{"short youtube", &Document{Source: "url", Meta: JSONMap{"url": "https://youtu.be/kCc8FmEb1nY"}}, DocTypeVideo},
+		{"github", &Document{Source: "url", Meta: JSONMap{"url": "https://github.com/karpathy/nanoGPT"}}, DocTypeRepo},
+		{"web page", &Document{Source: "url", Meta: JSONMap{"url": "https://example.org/post"}}, DocTypeArticle},
+		{"epub", &Document{Path: "books/dl.EPUB"}, DocTypeBook},
+	}
+	for _, c := range cases {
+		if got, ok := DetectDocumentType(c.doc, rules); !ok || got != c.want {
+			t.Errorf("%s: got %q, %v; want %q", c.name, got, ok, c.want)
+		}
+	}
+
+	// A downloaded PDF and a DOI without a journal match no rule
+	for _, doc := range []*Document{
+		{Source: "url", Path: "files/paper.pdf", Meta: JSONMap{"url": "https://example.org/paper.pdf"}},
+		{Source: IDSourceDOI, SourceID: "10.1007/978-3-030-01234-2_1"},
+		{Source: "local", Path: "notyoutube.com.pdf", Meta: JSONMap{"url": "https://notyoutube.com/x"}},
+	} {
+		if got, ok := DetectDocumentType(doc, rules); ok {
+			t.Errorf("%+v detected as %q", doc, got)
+		}
+	}
+}
+
+func TestParseTypeRules(t *testing.T) {
+	rules, err := ParseTypeRules(" host:www.Nature.com=paper, doi=paper,html=note ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rules) != 3+len(DefaultTypeRules) || rules[0].Match != "host:www.nature.com" {
+		t.Fatalf("rules = %+v", rules)
+	}
+	// Custom rules win over the defaults
+	page := &Document{Source: "url", Meta: JSONMap{"url": "https://blogs.nature.com/x"}}
+	if got, _ := DetectDocumentType(page, rules); got != DocTypePaper {
+		t.Errorf("nature.com page = %q", got)
+	}
+	if got, _ := DetectDocumentType(&Document{Source: "url"}, rules); got != DocTypeNote {
+		t.Errorf("web page = %q", got)
+	}
+
+	for _, bad := range []string{"isbn", "isbn=pamphlet", "title:foo=book", "ext:pdf=paper", "host:=repo"} {
+		if _, err := ParseTypeRules(bad); err == nil {
+			t.Errorf("ParseTypeRules(%q) succeeded", bad)
+		}
+	}
+}
@@ -0,0 +1,628 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DryRunChange is a change a DryRunStore was asked to make and did not.
+type DryRunChange struct {
+	Entity     string      `json:"entity"` // document, annotation, collection, ...
+	ID         string      `json:"id,omitempty"`
+	DocumentID string      `json:"document_id,omitempty"` // the document the entity belongs to, if any
+	Action     AuditAction `json:"action"`
+	Summary    string      `json:"summary,omitempty"`
+}
+
+// DryRun is the state a DryRunStore and the stores WithActor derives from
+// it share: whether writes are held back, and the changes held back so far.
+// The stores are safe for concurrent use, as the web server makes them;
+// read Changes once the writes are done, or through HeldChanges.
+type DryRun struct {
+	Enabled bool
+	Report  func(DryRunChange) // called for each change as it is held back, in order; may be nil, and must not use the store
+	Changes []DryRunChange
+
+	mu          sync.Mutex // guards Changes and the fields below
+	next        int
+	documents   map[string]*Document   // added during the run, by ID
+	collections map[string]*Collection // created during the run, by ID
+}
+
+// DryRunStore wraps a store so that, while its DryRun is enabled, reads
+// go through and writes are recorded instead of made. Documents,
+// collections, and other records it is asked to create get placeholder
+// IDs, and documents and collections can be read back, so a command
+// importing many files into a new collection creates it once and reports
+// every file. Derived caches and the audit log and journal are not
+// written either, and not reported.
+type DryRunStore struct {
+	LibraryStore
+	*DryRun
+}
+
+// NewDryRunStore returns s with writes held back while the returned
+// store's DryRun is enabled; it starts disabled.
+func NewDryRunStore(s LibraryStore) *DryRunStore {
+	return &DryRunStore{LibraryStore: s, DryRun: &DryRun{}}
+}
+
+// HeldChanges returns a copy of the changes held back so far.
+func (d *DryRun) HeldChanges() []DryRunChange {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]DryRunChange(nil), d.Changes...)
+}
+
+// hold records a change instead of making it.
+func (s *DryRunStore) hold(c DryRunChange) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Changes = append(s.Changes, c)
+	if s.Report != nil {
+		s.Report(c)
+	}
+}
+
+// newID returns a placeholder ID for a record the run did not create.
+func (s *DryRunStore) newID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.next++
+	return fmt.Sprintf("dry-run-%d", s.next)
+}
+
+// documentName names a document for a change summary.
+func (s *DryRunStore) documentName(id string) string {
+	if d, _ := s.GetDocument(id); d != nil && d.Title != "" {
+		return fmt.Sprintf("%q", truncateRunes(d.Title, 60))
+	}
+	return id
+}
+
+// collectionName names a collection for a change summary.
+func (s *DryRunStore) collectionName(id string) string {
+	if c, _ := s.GetCollection(id); c != nil {
+		return c.Name
+	}
+	return id
+}
+
+// heldDocument returns a document added during the run that match
+// accepts, or nil.
+func (s *DryRunStore) heldDocument(match func(*Document) bool) *Document {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, d := range s.documents {
+		if match(d) {
+			return d
+		}
+	}
+	return nil
+}
+
+// heldCollection returns the collection created during the run with the
+// ID or name, or nil.
+func (s *DryRunStore) heldCollection(idOrName string) *Collection {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.collections {
+		if c.ID == idOrName || strings.EqualFold(c.Name, idOrName) {
+			return c
+		}
+	}
+	return nil
+}
+
+func (s *DryRunStore) GetDocument(id string) (*Document, error) {
+	if d := s.heldDocument(func(d *Document) bool { return d.ID == id }); d != nil {
+		return d, nil
+	}
+	return s.LibraryStore.GetDocument(id)
+}
+
+func (s *DryRunStore) GetDocumentByPath(path string) (*Document, error) {
+	if d := s.heldDocument(func(d *Document) bool { return path != "" && d.Path == path }); d != nil {
+		return d, nil
+	}
+	return s.LibraryStore.GetDocumentByPath(path)
+}
+
+func (s *DryRunStore) GetDocumentBySourceID(source, sourceID string) (*Document, error) {
+	if d := s.heldDocument(func(d *Document) bool { return sourceID != "" && d.Source == source && d.SourceID == sourceID }); d != nil {
+		return d, nil
+	}
+	return s.LibraryStore.GetDocumentBySourceID(source, sourceID)
+}
+
+func (s *DryRunStore) GetCollection(idOrName string) (*Collection, error) {
+	if c := s.heldCollection(idOrName); c != nil {
+		return c, nil
+	}
+	return s.LibraryStore.GetCollection(idOrName)
+}
+
+func (s *DryRunStore) AddDocument(doc *Document) error {
+	if !s.Enabled {
+		return s.LibraryStore.AddDocument(doc)
+	}
+	if doc.ID == "" {
+		doc.ID = s.newID()
+	}
+	now := time.Now()
+	if doc.CreatedAt.IsZero() {
+		doc.CreatedAt = now
+	}
+	doc.UpdatedAt = now
+	s.mu.Lock()
+	if s.documents == nil {
+		s.documents = map[string]*Document{}
+	}
+	s.documents[doc.ID] = doc
+	s.mu.Unlock()
+	// The metadata an import resolved, so it can be checked before it is real
+	summary := fmt.Sprintf("%s %q", doc.Type, truncateRunes(doc.Title, 60))
+	if len(doc.Authors) > 3 {
+		summary += " by " + strings.Join(doc.Authors[:3], ", ") + " et al."
+	} else if len(doc.Authors) > 0 {
+		summary += " by " + strings.Join(doc.Authors, ", ")
+	}
+	if doc.SourceID != "" {
+		summary += " " + doc.Source + ":" + doc.SourceID
+	}
+	if doc.Path != "" {
+		summary += " (" + doc.Path + ")"
+	}
+	if len(doc.Tags) > 0 {
+		summary += " [" + strings.Join(doc.Tags, ", ") + "]"
+	}
+	s.hold(DryRunChange{Entity: "document", ID: doc.ID, Action: AuditCreate, Summary: summary})
+	return nil
+}
+
+func (s *DryRunStore) AddDocuments(docs []*Document) error {
+	if !s.Enabled {
+		return s.LibraryStore.AddDocuments(docs)
+	}
+	for _, doc := range docs {
+		if err := s.AddDocument(doc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *DryRunStore) UpdateDocument(doc *Document) error {
+	if !s.Enabled {
+		return s.LibraryStore.UpdateDocument(doc)
+	}
+	summary := doc.Title
+	if old, err := s.GetDocument(doc.ID); err == nil && old != nil {
+		changes, err := DiffDocuments(old, doc)
+		if err != nil {
+			return err
+		}
+		if len(changes) == 0 {
+			return nil
+		}
+		var fields []string
+		for _, c := range changes {
+			fields = append(fields, c.Field)
+		}
+		summary += " [" + strings.Join(fields, ", ") + "]"
+	}
+	s.hold(DryRunChange{Entity: "document", ID: doc.ID, Action: AuditUpdate, Summary: summary})
+	return nil
+}
+
+func (s *DryRunStore) DeleteDocument(id string) error {
+	if !s.Enabled {
+		return s.LibraryStore.DeleteDocument(id)
+	}
+	s.hold(DryRunChange{Entity: "document", ID: id, Action: AuditDelete, Summary: s.documentName(id)})
+	return nil
+}
+
+func (s *DryRunStore) Compact() (*CompactStats, error) {
+	if !s.Enabled {
+		return s.LibraryStore.Compact()
+	}
+	s.hold(DryRunChange{Entity: "database", Action: AuditUpdate, Summary: "compact"})
+	return &CompactStats{}, nil
+}
+
+func (s *DryRunStore) RebuildIndexes() (*IndexStats, error) {
+	if !s.Enabled {
+		return s.LibraryStore.RebuildIndexes()
+	}
+	s.hold(DryRunChange{Entity: "database", Action: AuditUpdate, Summary: "rebuild indexes"})
+	return &IndexStats{}, nil
+}
+
+func (s *DryRunStore) AddTag(documentID, tag string) error {
+	if !s.Enabled {
+		return s.LibraryStore.AddTag(documentID, tag)
+	}
+	s.hold(DryRunChange{Entity: "document", ID: documentID, Action: AuditUpdate, Summary: fmt.Sprintf("tag +%s on %s", tag, s.documentName(documentID))})
+	return nil
+}
+
+func (s *DryRunStore) RemoveTag(documentID, tag string) error {
+	if !s.Enabled {
+		return s.LibraryStore.RemoveTag(documentID, tag)
+	}
+	s.hold(DryRunChange{Entity: "document", ID: documentID, Action: AuditUpdate, Summary: fmt.Sprintf("tag -%s on %s", tag, s.documentName(documentID))})
+	return nil
+}
+
+func (s *DryRunStore) CreateCollection(name, description string) (*Collection, error) {
+	if !s.Enabled {
+		return s.LibraryStore.CreateCollection(name, description)
+	}
+	if existing, err := s.GetCollection(name); err != nil {
+		return nil, err
+	} else if existing != nil && existing.Name == name {
+		return nil, fmt.Errorf("%w: %s", ErrCollectionExists, name)
+	}
+	now := time.Now()
+	c := &Collection{ID: s.newID(), Name: name, Description: description, DocumentIDs: []string{}, CreatedAt: now, UpdatedAt: now}
+	s.mu.Lock()
+	if s.collections == nil {
+		s.collections = map[string]*Collection{}
+	}
+	s.collections[c.ID] = c
+	s.mu.Unlock()
+	s.hold(DryRunChange{Entity: "collection", ID: c.ID, Action: AuditCreate, Summary: name})
+	return c, nil
+}
+
+func (s *DryRunStore) AddToCollection(collectionID, documentID string) error {
+	if !s.Enabled {
+		return s.LibraryStore.AddToCollection(collectionID, documentID)
+	}
+	s.hold(DryRunChange{Entity: "collection", ID: collectionID, DocumentID: documentID, Action: AuditUpdate,
+		Summary: fmt.Sprintf("add %s to %s", s.documentName(documentID), s.collectionName(collectionID))})
+	return nil
+}
+
+func (s *DryRunStore) RemoveFromCollection(collectionID, documentID string) error {
+	if !s.Enabled {
+		return s.LibraryStore.RemoveFromCollection(collectionID, documentID)
+	}
+	s.hold(DryRunChange{Entity: "collection", ID: collectionID, DocumentID: documentID, Action: AuditUpdate,
+		Summary: fmt.Sprintf("remove %s from %s", s.documentName(documentID), s.collectionName(collectionID))})
+	return nil
+}
+
+func (s *DryRunStore) DeleteCollection(id string) error {
+	if !s.Enabled {
+		return s.LibraryStore.DeleteCollection(id)
+	}
+	s.hold(DryRunChange{Entity: "collection", ID: id, Action: AuditDelete, Summary: s.collectionName(id)})
+	return nil
+}
+
+func (s *DryRunStore) SetCollectionPublic(id string, public bool) error {
+	if !s.Enabled {
+		return s.LibraryStore.SetCollectionPublic(id, public)
+	}
+	summary := "unpublish " + s.collectionName(id)
+	if public {
+		summary = "publish " + s.collectionName(id)
+	}
+	s.hold(DryRunChange{Entity: "collection", ID: id, Action: AuditUpdate, Summary: summary})
+	return nil
+}
+
+func (s *DryRunStore) AddAnnotation(ann *Annotation) error {
+	if !s.Enabled {
+		return s.LibraryStore.AddAnnotation(ann)
+	}
+	if ann.ID == "" {
+		ann.ID = s.newID()
+	}
+	s.hold(DryRunChange{Entity: "annotation", ID: ann.ID, DocumentID: ann.DocumentID, Action: AuditCreate, Summary: ann.Type + ": " + truncateRunes(ann.Content, 60)})
+	return nil
+}
+
+func (s *DryRunStore) AddAnnotations(anns []*Annotation) error {
+	if !s.Enabled {
+		return s.LibraryStore.AddAnnotations(anns)
+	}
+	for _, ann := range anns {
+		if err := s.AddAnnotation(ann); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *DryRunStore) DeleteAnnotation(id string) error {
+	if !s.Enabled {
+		return s.LibraryStore.DeleteAnnotation(id)
+	}
+	s.hold(DryRunChange{Entity: "annotation", ID: id, Action: AuditDelete})
+	return nil
+}
+
+func (s *DryRunStore) StartSession(documentID string) (*ReadingSession, error) {
+	if !s.Enabled {
+		return s.LibraryStore.StartSession(documentID)
+	}
+	sess := &ReadingSession{ID: s.newID(), DocumentID: documentID, StartAt: time.Now()}
+	s.hold(DryRunChange{Entity: "session", ID: sess.ID, DocumentID: documentID, Action: AuditCreate, Summary: "start reading " + s.documentName(documentID)})
+	return sess, nil
+}
+
+func (s *DryRunStore) EndSession(sessionID string, pagesRead int, notes string) error {
+	if !s.Enabled {
+		return s.LibraryStore.EndSession(sessionID, pagesRead, notes)
+	}
+	s.hold(DryRunChange{Entity: "session", ID: sessionID, Action: AuditUpdate, Summary: fmt.Sprintf("end, %d page(s) read", pagesRead)})
+	return nil
+}
+
+func (s *DryRunStore) AddDocumentLink(l *DocumentLink) error {
+	if !s.Enabled {
+		return s.LibraryStore.AddDocumentLink(l)
+	}
+	if l.ID == "" {
+		l.ID = s.newID()
+	}
+	s.hold(DryRunChange{Entity: "link", ID: l.ID, DocumentID: l.FromID, Action: AuditCreate, Summary: fmt.Sprintf("%s %s %s", s.documentName(l.FromID), l.Relation, s.documentName(l.ToID))})
+	return nil
+}
+
+func (s *DryRunStore) DeleteDocumentLink(id string) error {
+	if !s.Enabled {
+		return s.LibraryStore.DeleteDocumentLink(id)
+	}
+	s.hold(DryRunChange{Entity: "link", ID: id, Action: AuditDelete})
+	return nil
+}
+
+func (s *DryRunStore) AddFlashcard(card *Flashcard) error {
+	if !s.Enabled {
+		return s.LibraryStore.AddFlashcard(card)
+	}
+	if card.ID == "" {
+		card.ID = s.newID()
+	}
+	s.hold(DryRunChange{Entity: "flashcard", ID: card.ID, DocumentID: card.DocumentID, Action: AuditCreate, Summary: truncateRunes(card.Front, 60)})
+	return nil
+}
+
+func (s *DryRunStore) AddFlashcards(cards []*Flashcard) error {
+	if !s.Enabled {
+		return s.LibraryStore.AddFlashcards(cards)
+	}
+	for _, card := range cards {
+		if err := s.AddFlashcard(card); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *DryRunStore) UpdateFlashcard(card *Flashcard) error {
+	if !s.Enabled {
+		return s.LibraryStore.UpdateFlashcard(card)
+	}
+	s.hold(DryRunChange{Entity: "flashcard", ID: card.ID, DocumentID: card.DocumentID, Action: AuditUpdate, Summary: truncateRunes(card.Front, 60)})
+	return nil
+}
+
+func (s *DryRunStore) DeleteFlashcard(id string) error {
+	if !s.Enabled {
+		return s.LibraryStore.DeleteFlashcard(id)
+	}
+	c := DryRunChange{Entity: "flashcard", ID: id, Action: AuditDelete}
+	if card, _ := s.GetFlashcard(id); card != nil {
+		c.DocumentID, c.Summary = card.DocumentID, truncateRunes(card.Front, 60)
+	}
+	s.hold(c)
+	return nil
+}
+
+// ReviewFlashcard returns the card as it is, unscheduled.
+func (s *DryRunStore) ReviewFlashcard(id string, quality int) (*Flashcard, error) {
+	if !s.Enabled {
+		return s.LibraryStore.ReviewFlashcard(id, quality)
+	}
+	card, err := s.GetFlashcard(id)
+	if err != nil {
+		return nil, err
+	}
+	if card == nil {
+		return nil, fmt.Errorf("%w: %s", ErrFlashcardNotFound, id)
+	}
+	s.hold(DryRunChange{Entity: "flashcard", ID: id, DocumentID: card.DocumentID, Action: AuditUpdate, Summary: fmt.Sprintf("review, quality %d", quality)})
+	return card, nil
+}
+
+func (s *DryRunStore) AddFlashcardReview(r *FlashcardReview) error {
+	if !s.Enabled {
+		return s.LibraryStore.AddFlashcardReview(r)
+	}
+	s.hold(DryRunChange{Entity: "flashcard", ID: r.FlashcardID, Action: AuditUpdate, Summary: fmt.Sprintf("record review, quality %d", r.Quality)})
+	return nil
+}
+
+func (s *DryRunStore) SaveDocumentReview(r *DocumentReview) error {
+	if !s.Enabled {
+		return s.LibraryStore.SaveDocumentReview(r)
+	}
+	s.hold(DryRunChange{Entity: "review", ID: r.DocumentID, DocumentID: r.DocumentID, Action: AuditUpdate, Summary: "due " + HomeDate(r.DueAt)})
+	return nil
+}
+
+func (s *DryRunStore) DeleteDocumentReview(documentID string) error {
+	if !s.Enabled {
+		return s.LibraryStore.DeleteDocumentReview(documentID)
+	}
+	s.hold(DryRunChange{Entity: "review", ID: documentID, DocumentID: documentID, Action: AuditDelete})
+	return nil
+}
+
+func (s *DryRunStore) AddTask(t *Task) error {
+	if !s.Enabled {
+		return s.LibraryStore.AddTask(t)
+	}
+	if t.ID == "" {
+		t.ID = s.newID()
+	}
+	s.hold(DryRunChange{Entity: "task", ID: t.ID, DocumentID: t.DocumentID, Action: AuditCreate, Summary: truncateRunes(t.Description, 60)})
+	return nil
+}
+
+func (s *DryRunStore) UpdateTask(t *Task) error {
+	if !s.Enabled {
+		return s.LibraryStore.UpdateTask(t)
+	}
+	s.hold(DryRunChange{Entity: "task", ID: t.ID, DocumentID: t.DocumentID, Action: AuditUpdate, Summary: t.Status + ": " + truncateRunes(t.Description, 60)})
+	return nil
+}
+
+func (s *DryRunStore) DeleteTask(id string) error {
+	if !s.Enabled {
+		return s.LibraryStore.DeleteTask(id)
+	}
+	s.hold(DryRunChange{Entity: "task", ID: id, Action: AuditDelete})
+	return nil
+}
+
+func (s *DryRunStore) SetReadingQueue(documentIDs []string) error {
+	if !s.Enabled {
+		return s.LibraryStore.SetReadingQueue(documentIDs)
+	}
+	s.hold(DryRunChange{Entity: "queue", ID: "queue", Action: AuditUpdate, Summary: fmt.Sprintf("%d document(s)", len(documentIDs))})
+	return nil
+}
+
+func (s *DryRunStore) DefineField(def *FieldDef) error {
+	if !s.Enabled {
+		return s.LibraryStore.DefineField(def)
+	}
+	s.hold(DryRunChange{Entity: "field", ID: def.Name, Action: AuditCreate, Summary: string(def.Type)})
+	return nil
+}
+
+func (s *DryRunStore) DeleteField(name string) error {
+	if !s.Enabled {
+		return s.LibraryStore.DeleteField(name)
+	}
+	s.hold(DryRunChange{Entity: "field", ID: name, Action: AuditDelete})
+	return nil
+}
+
+func (s *DryRunStore) CreateShare(sh *Share) error {
+	if !s.Enabled {
+		return s.LibraryStore.CreateShare(sh)
+	}
+	s.hold(DryRunChange{Entity: "share", DocumentID: sh.DocumentID, Action: AuditCreate, Summary: s.documentName(sh.DocumentID) + ", expires " + sh.ExpiresAt.Format("2006-01-02 15:04")})
+	return nil
+}
+
+func (s *DryRunStore) DeleteShare(token string) error {
+	if !s.Enabled {
+		return s.LibraryStore.DeleteShare(token)
+	}
+	s.hold(DryRunChange{Entity: "share", ID: truncateRunes(token, 8), Action: AuditDelete})
+	return nil
+}
+
+func (s *DryRunStore) CreateAPIToken(t *APIToken) error {
+	if !s.Enabled {
+		return s.LibraryStore.CreateAPIToken(t)
+	}
+	if t.ID == "" {
+		t.ID = s.newID()
+	}
+	s.hold(DryRunChange{Entity: "token", ID: t.ID, Action: AuditCreate, Summary: fmt.Sprintf("%s (%s)", t.Name, t.Scope)})
+	return nil
+}
+
+func (s *DryRunStore) DeleteAPIToken(id string) error {
+	if !s.Enabled {
+		return s.LibraryStore.DeleteAPIToken(id)
+	}
+	s.hold(DryRunChange{Entity: "token", ID: id, Action: AuditDelete})
+	return nil
+}
+
+func (s *DryRunStore) SaveSearch(ss *SavedSearch) error {
+	if !s.Enabled {
+		return s.LibraryStore.SaveSearch(ss)
+	}
+	if ss.ID == "" {
+		ss.ID = s.newID()
+	}
+	s.hold(DryRunChange{Entity: "search", ID: ss.ID, Action: AuditUpdate, Summary: ss.Name})
+	return nil
+}
+
+func (s *DryRunStore) DeleteSavedSearch(id string) error {
+	if !s.Enabled {
+		return s.LibraryStore.DeleteSavedSearch(id)
+	}
+	s.hold(DryRunChange{Entity: "search", ID: id, Action: AuditDelete})
+	return nil
+}
+
+func (s *DryRunStore) SaveZoteroSyncState(st *ZoteroSyncState) error {
+	if !s.Enabled {
+		return s.LibraryStore.SaveZoteroSyncState(st)
+	}
+	s.hold(DryRunChange{Entity: "zotero", Action: AuditUpdate, Summary: "sync state"})
+	return nil
+}
+
+// Caches, the journal, and the audit log follow the changes above, which
+// were not made.
+
+func (s *DryRunStore) SaveTextSignature(sig *TextSignature) error {
+	if s.Enabled {
+		return nil
+	}
+	return s.LibraryStore.SaveTextSignature(sig)
+}
+
+func (s *DryRunStore) SaveDocumentSections(ds *DocumentSections) error {
+	if s.Enabled {
+		return nil
+	}
+	return s.LibraryStore.SaveDocumentSections(ds)
+}
+
+func (s *DryRunStore) SaveDocumentReferences(r *DocumentReferences) error {
+	if s.Enabled {
+		return nil
+	}
+	return s.LibraryStore.SaveDocumentReferences(r)
+}
+
+func (s *DryRunStore) RecordOperation(op *Operation) error {
+	if s.Enabled {
+		return nil
+	}
+	return s.LibraryStore.RecordOperation(op)
+}
+
+func (s *DryRunStore) DeleteOperation(id string) error {
+	if s.Enabled {
+		return nil
+	}
+	return s.LibraryStore.DeleteOperation(id)
+}
+
+func (s *DryRunStore) AppendAuditEntry(e *AuditEntry) error {
+	if s.Enabled {
+		return nil
+	}
+	return s.LibraryStore.AppendAuditEntry(e)
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+func TestDryRunStore(t *testing.T) {
+	kv, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	existing := &Document{Type: DocTypePaper, Title: "Already here", Path: "papers/old.pdf"}
+	if err := kv.AddDocument(existing); err != nil {
+		t.Fatal(err)
+	}
+	s := NewDryRunStore(NewAuditedStore(kv, "test"))
+	s.Enabled = true
+	var reported []string
+	s.Report = func(c DryRunChange) {
+		reported = append(reported, fmt.Sprintf("%s %s: %s", c.Action, c.Entity, c.Summary))
+	}
+
+	// An import into a new collection, as watch --one-shot runs it, through
+	// a store WithActor derived
+	w := WithActor(s, "watch")
+	for _, name := range []string{"a.pdf", "b.pdf"} {
+		doc := &Document{Type: DocTypePaper, Title: strings.TrimSuffix(name, ".pdf"), Path: "papers/" + name, Tags: []string{"inbox"}}
+		if err := w.AddDocument(doc); err != nil {
+			t.Fatal(err)
+		}
+		if doc.ID == "" {
+			t.Fatal("no placeholder ID")
+		}
+		if got, _ := w.GetDocumentByPath(doc.Path); got != doc {
+			t.Errorf("added document not readable by path: %v", got)
+		}
+		c, err := w.GetCollection("To Read")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c == nil {
+			if c, err = w.CreateCollection("To Read", ""); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.AddToCollection(c.ID, doc.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.AddTag(existing.ID, "ml"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		`create document: paper "a" (papers/a.pdf) [inbox]`,
+		`create collection: To Read`,
+		`update collection: add "a" to To Read`,
+		`create document: paper "b" (papers/b.pdf) [inbox]`,
+		`update collection: add "b" to To Read`,
+		`update document: tag +ml on "Already here"`,
+	}
+	if strings.Join(reported, "\n") != strings.Join(want, "\n") {
+		t.Errorf("reported:\n%s\nwant:\n%s", strings.Join(reported, "\n"), strings.Join(want, "\n"))
+	}
+	if len(s.Changes) != len(want) {
+		t.Errorf("%d changes held, want %d", len(s.Changes), len(want))
+	}
+
+	// Nothing reached the store or its audit log
+	docs, _ := kv.ListDocuments(&ListOptions{})
+	colls, _ := kv.ListCollections()
+	entries, _ := kv.ListAuditEntries(nil)
+	if len(docs) != 1 || len(docs[0].Tags) != 0 || len(colls) != 0 || len(entries) != 0 {
+		t.Errorf("store written: %d documents %v, %d collections, %d audit entries", len(docs), docs[0].Tags, len(colls), len(entries))
+	}
+
+	// Disabled, writes go through
+	s.Enabled = false
+	if err := s.AddTag(existing.ID, "ml"); err != nil {
+		t.Fatal(err)
+	}
+	if doc, _ := kv.GetDocument(existing.ID); doc == nil || len(doc.Tags) != 1 {
+		t.Errorf("tag not written: %+v", doc)
+	}
+}
+
+func TestDryRunStoreConcurrent(t *testing.T) {
+	kv, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewDryRunStore(kv)
+	s.Enabled = true
+
+	// As the web server's handlers would, under -race
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			doc := &Document{Title: fmt.Sprint(i), Path: fmt.Sprintf("%d.pdf", i)}
+			if err := s.AddDocument(doc); err != nil {
+				t.Error(err)
+			}
+			if got, _ := s.GetDocumentByPath(doc.Path); got != doc {
+				t.Errorf("document %d not read back", i)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := len(s.HeldChanges()); n != 8 {
+		t.Errorf("%d changes held, want 8", n)
+	}
+}
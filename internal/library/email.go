@@ -0,0 +1,119 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	_ "github.com/emersion/go-message/charset" // decode non-UTF-8 mail
+	"github.com/emersion/go-message/mail"
+)
+
+// maxEmailLinks caps how many links one email can import, so a forwarded
+// newsletter doesn't flood the library.
+const maxEmailLinks = 10
+
+// InboxEmail is what an email sent to the library contains: PDF
+// attachments, or links when it has none.
+type InboxEmail struct {
+	Subject     string
+	From        string
+	Attachments []EmailAttachment
+	Links       []string
+}
+
+// EmailAttachment is a PDF attached to an email.
+type EmailAttachment struct {
+	Filename string
+	Data     []byte
+}
+
+var (
+	emailLinkPattern = regexp.MustCompile(`https?://[^\s<>"'()\[\]]+`)
+	emailHrefPattern = regexp.MustCompile(`(?i)href\s*=\s*["'](https?://[^"']+)["']`)
+)
+
+// ParseInboxEmail reads a raw RFC 5322 message. Links are taken from the
+// plain-text body, or from the HTML body's hrefs when there is no plain
+// text, and only when the email has no PDF attachments.
+func ParseInboxEmail(r io.Reader) (*InboxEmail, error) {
+	mr, err := mail.CreateReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("parse email: %w", err)
+	}
+	defer mr.Close()
+
+	email := &InboxEmail{}
+	email.Subject, _ = mr.Header.Subject()
+	if from, err := mr.Header.AddressList("From"); err == nil && len(from) > 0 {
+		email.From = from[0].Address
+	}
+
+	var plain, htmlBody string
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse email: %w", err)
+		}
+
+		var contentType, filename string
+		switch h := part.Header.(type) {
+		case *mail.InlineHeader:
+			contentType, _, _ = h.ContentType()
+		case *mail.AttachmentHeader:
+			contentType, _, _ = h.ContentType()
+			filename, _ = h.Filename()
+		}
+
+		switch {
+		case contentType == "application/pdf" || strings.EqualFold(filepath.Ext(filename), ".pdf"):
+			data, err := io.ReadAll(part.Body)
+			if err != nil {
+				return nil, fmt.Errorf("read attachment %s: %w", filename, err)
+			}
+			if filename == "" {
+				filename = "attachment.pdf"
+			}
+			email.Attachments = append(email.Attachments, EmailAttachment{Filename: filename, Data: data})
+		case filename != "":
+			// Other attachments (signatures, images) are ignored
+		case contentType == "text/plain" && plain == "":
+			b, _ := io.ReadAll(part.Body)
+			plain = string(b)
+		case contentType == "text/html" && htmlBody == "":
+			b, _ := io.ReadAll(part.Body)
+			htmlBody = string(b)
+		}
+	}
+
+	if len(email.Attachments) > 0 {
+		return email, nil
+	}
+	var links []string
+	if plain != "" {
+		links = emailLinkPattern.FindAllString(plain, -1)
+	} else {
+		for _, m := range emailHrefPattern.FindAllStringSubmatch(htmlBody, -1) {
+			links = append(links, m[1])
+		}
+	}
+	seen := make(map[string]bool)
+	for _, link := range links {
+		link = strings.TrimRight(link, ".,;:!?")
+		if seen[link] || len(email.Links) == maxEmailLinks {
+			continue
+		}
+		seen[link] = true
+		email.Links = append(email.Links, link)
+	}
+	return email, nil
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseInboxEmail(t *testing.T) {
+	withPDF := strings.ReplaceAll(`From: Ada <ada@example.com>
+Subject: Paper for later
+MIME-Version: 1.0
+Content-Type: multipart/mixed; boundary="b"
+
+--b
+Content-Type: text/plain
+
+See https://example.com/ignored when there is a PDF.
+--b
+Content-Type: application/pdf
+Content-Disposition: attachment; filename="attention.pdf"
+Content-Transfer-Encoding: base64
+
+JVBERi0xLjQKJSVFT0YK
+--b
+Content-Type: image/png
+Content-Disposition: attachment; filename="logo.png"
+
+png
+--b--
+`, "\n", "\r\n")
+
+	email, err := ParseInboxEmail(strings.NewReader(withPDF))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if email.Subject != "Paper for later" || email.From != "ada@example.com" {
+		t.Errorf("subject %q, from %q", email.Subject, email.From)
+	}
+	if len(email.Attachments) != 1 || email.Attachments[0].Filename != "attention.pdf" || string(email.Attachments[0].Data) != "%PDF-1.4\n%%EOF\n" {
+		t.Errorf("attachments = %+v", email.Attachments)
+	}
+	if len(email.Links) != 0 {
+		t.Errorf("links = %v, want none alongside a PDF", email.Links)
+	}
+
+	linkOnly := "From: ada@example.com\r\nSubject: Read this\r\nContent-Type: text/plain\r\n\r\n" +
+		"Worth a look: https://example.com/post-1. Also (https://arxiv.org/abs/2304.00067)\r\nhttps://example.com/post-1\r\n"
+	email, err = ParseInboxEmail(strings.NewReader(linkOnly))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"https://example.com/post-1", "https://arxiv.org/abs/2304.00067"}
+	if !slices.Equal(email.Links, want) {
+		t.Errorf("links = %v, want %v", email.Links, want)
+	}
+
+	htmlOnly := "From: ada@example.com\r\nContent-Type: text/html\r\n\r\n<p><a href=\"https://example.com/a\">A</a></p>\r\n"
+	email, err = ParseInboxEmail(strings.NewReader(htmlOnly))
+	if err != nil || !slices.Equal(email.Links, []string{"https://example.com/a"}) {
+		t.Errorf("html links = %v, %v", email.Links, err)
+	}
+}
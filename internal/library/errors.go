@@ -0,0 +1,22 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import "errors"
+
+// Errors the stores and the commands over them wrap, with the ID or name
+// concerned, so callers can tell failures apart with errors.Is.
+var (
+	ErrDocumentNotFound   = errors.New("document not found")
+	ErrCollectionNotFound = errors.New("collection not found")
+	ErrCollectionExists   = errors.New("collection already exists")
+	ErrFlashcardNotFound  = errors.New("flashcard not found")
+
+	// ErrReadOnly is returned for writes to a ReadOnlyStore.
+	ErrReadOnly = errors.New("library is read-only")
+
+	// ErrBackendUnsupported is returned for features the storage backend
+	// in use lacks, such as tasks in the KV store.
+	ErrBackendUnsupported = errors.New("not supported by this storage backend")
+)
@@ -0,0 +1,40 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSentinelErrors(t *testing.T) {
+	for _, backend := range benchBackends {
+		t.Run(backend.name, func(t *testing.T) {
+			s := backend.open(t)
+			if err := s.AddTag("missing", "ml"); !errors.Is(err, ErrDocumentNotFound) {
+				t.Errorf("tagging a missing document: %v", err)
+			}
+			if err := s.SetCollectionPublic("missing", true); !errors.Is(err, ErrCollectionNotFound) {
+				t.Errorf("publishing a missing collection: %v", err)
+			}
+			if _, err := s.CreateCollection("Reading", ""); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := s.CreateCollection("Reading", ""); !errors.Is(err, ErrCollectionExists) {
+				t.Errorf("creating a collection twice: %v", err)
+			}
+
+			d := NewDryRunStore(s)
+			d.Enabled = true
+			if _, err := d.CreateCollection("Reading", ""); !errors.Is(err, ErrCollectionExists) {
+				t.Errorf("dry run of creating a collection twice: %v", err)
+			}
+		})
+	}
+
+	kv := benchBackends[1].open(t)
+	if _, err := kv.ListTasks(nil); !errors.Is(err, ErrBackendUnsupported) {
+		t.Errorf("listing tasks in the KV store: %v", err)
+	}
+}
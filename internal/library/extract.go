@@ -0,0 +1,250 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultExtractTimeout is how long pdftotext may take on one file before
+// it is killed. Text extraction takes seconds even for long books; a
+// damaged PDF can keep it busy forever.
+const DefaultExtractTimeout = 2 * time.Minute
+
+// pdftotextCommand is the program extracting text; tests replace it.
+var pdftotextCommand = "pdftotext"
+
+// ErrExtractTimeout is returned for extractions that ran out of time.
+var ErrExtractTimeout = errors.New("text extraction timed out")
+
+// Extraction outcomes, as recorded in Meta["text_extraction"].
+const (
+	ExtractOK      = "ok"
+	ExtractFailed  = "failed"
+	ExtractTimeout = "timeout"
+)
+
+// TextExtraction is the outcome of extracting one file's text.
+type TextExtraction struct {
+	Path     string
+	Text     string
+	Duration time.Duration
+	Err      error
+}
+
+// Status returns ExtractOK, ExtractFailed, or ExtractTimeout.
+func (e *TextExtraction) Status() string {
+	switch {
+	case e.Err == nil:
+		return ExtractOK
+	case errors.Is(e.Err, ErrExtractTimeout):
+		return ExtractTimeout
+	}
+	return ExtractFailed
+}
+
+// Record notes the outcome in doc.Meta, so documents whose text could not
+// be extracted can be found and tried again (see ExtractionFailed):
+// text_extraction is its status, text_extraction_ms how long it took, and
+// text_extraction_error why it failed. The text itself is left to the
+// caller.
+func (e *TextExtraction) Record(doc *Document) {
+	if doc.Meta == nil {
+		doc.Meta = make(JSONMap)
+	}
+	doc.Meta["text_extraction"] = e.Status()
+	doc.Meta["text_extraction_ms"] = e.Duration.Milliseconds()
+	if e.Err != nil {
+		doc.Meta["text_extraction_error"] = e.Err.Error()
+	} else {
+		delete(doc.Meta, "text_extraction_error")
+	}
+}
+
+// ExtractionFailed reports whether doc's last text extraction failed or
+// timed out.
+func ExtractionFailed(doc *Document) bool {
+	s, _ := doc.Meta["text_extraction"].(string)
+	return s == ExtractFailed || s == ExtractTimeout
+}
+
+// TextExtractor runs pdftotext on at most Workers files at once, however
+// many goroutines ask it to, and kills it after Timeout.
+type TextExtractor struct {
+	Workers int
+	Timeout time.Duration // 0 for no limit
+
+	slots chan struct{}
+}
+
+// NewTextExtractor returns an extractor running up to workers extractions
+// at once, each for at most timeout.
+func NewTextExtractor(workers int, timeout time.Duration) *TextExtractor {
+	workers = max(workers, 1)
+	return &TextExtractor{Workers: workers, Timeout: timeout, slots: make(chan struct{}, workers)}
+}
+
+// textExtractor is used by PDFTextExtractor and the commands that extract
+// text.
+var textExtractor = NewTextExtractor(max(1, runtime.NumCPU()/2), DefaultExtractTimeout)
+
+// SetTextExtraction sets how many extractions run at once and how long
+// each may take.
+func SetTextExtraction(workers int, timeout time.Duration) {
+	textExtractor = NewTextExtractor(workers, timeout)
+}
+
+// DefaultTextExtractor returns the extractor SetTextExtraction configured.
+func DefaultTextExtractor() *TextExtractor {
+	return textExtractor
+}
+
+// Extract extracts the text of the PDF at path, waiting for a free worker
+// first.
+func (x *TextExtractor) Extract(path string) *TextExtraction {
+	x.slots <- struct{}{}
+	defer func() { <-x.slots }()
+
+	start := time.Now()
+	out, err := runPDFToText(x.Timeout, path, "-")
+	// Clean up excessive whitespace
+	text := strings.TrimSpace(string(out))
+	return &TextExtraction{Path: path, Text: text, Duration: time.Since(start), Err: err}
+}
+
+// ExtractAll starts extracting the text of paths in the background, in
+// order, and returns a function that waits for the extraction of one of
+// them. Callers are expected to ask in order, skipping any they don't
+// need: asking for a path lets go of the results of those before it.
+// The workers run at most twice Workers paths ahead of the last asked
+// for, so results don't pile up when the caller is slow or stops early,
+// and stop once ctx is done. Paths it wasn't given, or no longer holds,
+// are extracted when asked for.
+func (x *TextExtractor) ExtractAll(ctx context.Context, paths []string) func(path string) *TextExtraction {
+	type pending struct {
+		path string
+		fed  bool // given to a worker
+		done chan struct{}
+		res  *TextExtraction
+	}
+	index := make(map[string]int, len(paths))
+	var queue []*pending
+	for _, p := range paths {
+		if _, ok := index[p]; !ok {
+			index[p] = len(queue)
+			queue = append(queue, &pending{path: p, done: make(chan struct{})})
+		}
+	}
+	ahead := 2 * max(x.Workers, 1)
+
+	var mu sync.Mutex
+	next := 0                       // the first path in queue not let go of
+	moved := make(chan struct{}, 1) // next has moved on
+	jobs := make(chan *pending)
+	go func() {
+		defer close(jobs)
+		for i := 0; ; i++ {
+			mu.Lock()
+			i = max(i, next)
+			for i < len(queue) && i >= next+ahead {
+				mu.Unlock()
+				select {
+				case <-moved:
+				case <-ctx.Done():
+					return
+				}
+				mu.Lock()
+				i = max(i, next)
+			}
+			if i >= len(queue) || ctx.Err() != nil {
+				mu.Unlock()
+				return
+			}
+			r := queue[i]
+			r.fed = true
+			mu.Unlock()
+			select {
+			case jobs <- r:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	for range min(x.Workers, len(queue)) {
+		go func() {
+			for r := range jobs {
+				if err := ctx.Err(); err != nil {
+					r.res = &TextExtraction{Path: r.path, Err: err}
+				} else {
+					r.res = x.Extract(r.path)
+				}
+				close(r.done)
+			}
+		}()
+	}
+
+	return func(path string) *TextExtraction {
+		if err := ctx.Err(); err != nil {
+			return &TextExtraction{Path: path, Err: err}
+		}
+		mu.Lock()
+		i, ok := index[path]
+		var r *pending
+		fed := false
+		if ok && i >= next {
+			r, fed = queue[i], queue[i].fed
+			for j := next; j <= i; j++ {
+				queue[j] = nil
+			}
+			next = i + 1
+			select {
+			case moved <- struct{}{}:
+			default:
+			}
+		}
+		mu.Unlock()
+		if !fed {
+			return x.Extract(path)
+		}
+		select {
+		case <-r.done:
+			return r.res
+		case <-ctx.Done():
+			return &TextExtraction{Path: path, Err: ctx.Err()}
+		}
+	}
+}
+
+// runPDFToText runs pdftotext with args and returns its output, killing
+// it after timeout.
+func runPDFToText(timeout time.Duration, args ...string) ([]byte, error) {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	cmd := exec.CommandContext(ctx, pdftotextCommand, args...)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = bytes.NewBuffer(nil)
+	// Once killed, stop waiting for its output after a moment, in case
+	// something it started holds on to it
+	cmd.WaitDelay = 5 * time.Second
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("%w after %s", ErrExtractTimeout, timeout)
+		}
+		return nil, fmt.Errorf("pdftotext failed: %w (is poppler installed?)", err)
+	}
+	return out.Bytes(), nil
+}
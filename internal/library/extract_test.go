@@ -0,0 +1,161 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// fakePDFToText stands in for pdftotext: it prints the file's contents,
+// fails on files named bad.pdf, and hangs on files named hang.pdf.
+func fakePDFToText(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a shell script")
+	}
+	script := filepath.Join(t.TempDir(), "pdftotext")
+	err := os.WriteFile(script, []byte(`#!/bin/sh
+case "$1" in
+*bad.pdf) echo "Syntax Error: Couldn't find trailer dictionary" >&2; exit 1 ;;
+*hang.pdf) exec sleep 30 ;;
+esac
+cat "$1"
+`), 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := pdftotextCommand
+	pdftotextCommand = script
+	t.Cleanup(func() { pdftotextCommand = old })
+}
+
+func TestTextExtractor(t *testing.T) {
+	fakePDFToText(t)
+	dir := t.TempDir()
+	for _, name := range []string{"good.pdf", "bad.pdf", "hang.pdf", "hang2/hang.pdf"} {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0o755)
+		if err := os.WriteFile(path, []byte("  Text of "+name+"\n\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := func(name string) string { return filepath.Join(dir, name) }
+
+	x := NewTextExtractor(4, 300*time.Millisecond)
+	good := x.Extract(path("good.pdf"))
+	if good.Err != nil || good.Text != "Text of good.pdf" || good.Status() != ExtractOK {
+		t.Errorf("good = %+v", good)
+	}
+	bad := x.Extract(path("bad.pdf"))
+	if bad.Err == nil || bad.Status() != ExtractFailed {
+		t.Errorf("bad = %+v", bad)
+	}
+
+	// Files that hang are killed after the timeout, side by side
+	start := time.Now()
+	extracted := x.ExtractAll(context.Background(), []string{path("hang.pdf"), path("hang2/hang.pdf"), path("good.pdf")})
+	for _, name := range []string{"hang.pdf", "hang2/hang.pdf"} {
+		res := extracted(path(name))
+		if !errors.Is(res.Err, ErrExtractTimeout) || res.Status() != ExtractTimeout {
+			t.Errorf("%s = %+v", name, res)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("hung extractions took %s", elapsed)
+	}
+	if res := extracted(path("good.pdf")); res.Text != "Text of good.pdf" {
+		t.Errorf("good in batch = %+v", res)
+	}
+	if res := extracted(path("bad.pdf")); res.Status() != ExtractFailed {
+		t.Errorf("file not in batch = %+v", res)
+	}
+
+	// Outcomes are recorded for retrying failures
+	doc := &Document{Title: "Broken"}
+	bad.Record(doc)
+	if !ExtractionFailed(doc) || doc.Meta["text_extraction_error"] == nil {
+		t.Errorf("failure recorded as %v", doc.Meta)
+	}
+	good.Record(doc)
+	if ExtractionFailed(doc) || doc.Meta["text_extraction"] != ExtractOK || doc.Meta["text_extraction_error"] != nil {
+		t.Errorf("success recorded as %v", doc.Meta)
+	}
+	if _, ok := doc.Meta["text_extraction_ms"].(int64); !ok {
+		t.Errorf("duration recorded as %v", doc.Meta["text_extraction_ms"])
+	}
+}
+
+func TestTextExtractorWorkers(t *testing.T) {
+	fakePDFToText(t)
+	dir := t.TempDir()
+	var paths []string
+	for i := range 3 {
+		p := filepath.Join(dir, string(rune('a'+i)), "hang.pdf")
+		os.MkdirAll(filepath.Dir(p), 0o755)
+		os.WriteFile(p, nil, 0o644)
+		paths = append(paths, p)
+	}
+
+	// One worker runs the hanging files one after another
+	x := NewTextExtractor(1, 200*time.Millisecond)
+	start := time.Now()
+	extracted := x.ExtractAll(context.Background(), paths)
+	for _, p := range paths {
+		extracted(p)
+	}
+	if elapsed := time.Since(start); elapsed < 600*time.Millisecond {
+		t.Errorf("3 extractions with 1 worker took %s", elapsed)
+	}
+}
+
+func TestExtractAllBounded(t *testing.T) {
+	fakePDFToText(t)
+	dir := t.TempDir()
+	var paths []string
+	for i := range 10 {
+		p := filepath.Join(dir, fmt.Sprintf("%d.pdf", i))
+		if err := os.WriteFile(p, []byte("Early"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, p)
+	}
+
+	// Only the first few run ahead of the caller
+	x := NewTextExtractor(1, time.Minute)
+	extracted := x.ExtractAll(context.Background(), paths)
+	time.Sleep(300 * time.Millisecond)
+	for _, p := range paths {
+		os.WriteFile(p, []byte("Late"), 0o644)
+	}
+	if res := extracted(paths[0]); res.Text != "Early" {
+		t.Errorf("first file = %q, want it extracted ahead", res.Text)
+	}
+	if res := extracted(paths[5]); res.Text != "Late" {
+		t.Errorf("sixth file = %q, want it extracted when asked for", res.Text)
+	}
+	for _, p := range paths[6:] {
+		extracted(p)
+	}
+
+	// Once cancelled, nothing more is extracted
+	for i, p := range paths {
+		paths[i] = filepath.Join(filepath.Dir(p), fmt.Sprint(i), "hang.pdf")
+		os.MkdirAll(filepath.Dir(paths[i]), 0o755)
+		os.WriteFile(paths[i], nil, 0o644)
+	}
+	x = NewTextExtractor(1, 200*time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	extracted = x.ExtractAll(ctx, paths)
+	if res := extracted(paths[1]); res.Err == nil {
+		t.Errorf("after cancelling = %+v", res)
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"encoding/xml"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FeedLimit is how many of a collection's latest documents its feed lists.
+const FeedLimit = 50
+
+// ReadingListItem is a document on a collection's reading list.
+type ReadingListItem struct {
+	Document *Document `json:"document"`
+	AddedAt  time.Time `json:"added_at"`
+	URL      string    `json:"url,omitempty"` // where others can read it
+}
+
+// CollectionReadingList returns a collection's documents, most recently
+// added first, up to limit (0 for all). Documents added before the time
+// was recorded count as added when they were created.
+func CollectionReadingList(s LibraryStore, c *Collection, limit int) ([]ReadingListItem, error) {
+	items := []ReadingListItem{}
+	for _, id := range c.DocumentIDs {
+		doc, err := s.GetDocument(id)
+		if err != nil {
+			return nil, err
+		}
+		if doc == nil {
+			continue
+		}
+		added, ok := c.AddedAt[id]
+		if !ok || added.IsZero() {
+			added = doc.CreatedAt
+		}
+		items = append(items, ReadingListItem{Document: doc, AddedAt: added, URL: PublicDocumentURL(doc)})
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].AddedAt.After(items[j].AddedAt) })
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	return items, nil
+}
+
+// PublicDocumentURL returns an address anyone can read a document at: its
+// web page, its DOI, or its arXiv abstract, or "" when it has none. Local
+// files are never exposed.
+func PublicDocumentURL(doc *Document) string {
+	if u, _ := doc.Meta["url"].(string); strings.HasPrefix(u, "https://") || strings.HasPrefix(u, "http://") {
+		return u
+	}
+	if doi := documentDOI(doc); doi != "" {
+		return "https://doi.org/" + doi
+	}
+	if doc.Source == IDSourceArxiv && doc.SourceID != "" {
+		return "https://arxiv.org/abs/" + doc.SourceID
+	}
+	return ""
+}
+
+// WriteCollectionAtom writes a collection's reading list as an Atom feed,
+// newest first. selfURL is the feed's own address and pageURL the reading
+// list page; entries without a public URL link to their place on the page.
+func WriteCollectionAtom(w io.Writer, c *Collection, items []ReadingListItem, selfURL, pageURL string) error {
+	type link struct {
+		Rel  string `xml:"rel,attr,omitempty"`
+		Type string `xml:"type,attr,omitempty"`
+		Href string `xml:"href,attr"`
+	}
+	type person struct {
+		Name string `xml:"name"`
+	}
+	type entry struct {
+		ID        string   `xml:"id"`
+		Title     string   `xml:"title"`
+		Link      link     `xml:"link"`
+		Updated   string   `xml:"updated"`
+		Published string   `xml:"published"`
+		Authors   []person `xml:"author"`
+		Summary   string   `xml:"summary,omitempty"`
+	}
+	type feed struct {
+		XMLName  xml.Name `xml:"feed"`
+		XMLNS    string   `xml:"xmlns,attr"`
+		ID       string   `xml:"id"`
+		Title    string   `xml:"title"`
+		Subtitle string   `xml:"subtitle,omitempty"`
+		Links    []link   `xml:"link"`
+		Updated  string   `xml:"updated"`
+		Author   person   `xml:"author"`
+		Entries  []entry  `xml:"entry"`
+	}
+
+	updated := c.UpdatedAt
+	if len(items) > 0 && items[0].AddedAt.After(updated) {
+		updated = items[0].AddedAt
+	}
+	doc := feed{
+		XMLNS:    "http://www.w3.org/2005/Atom",
+		ID:       selfURL,
+		Title:    c.Name,
+		Subtitle: c.Description,
+		Links: []link{
+			{Rel: "self", Type: "application/atom+xml", Href: selfURL},
+			{Rel: "alternate", Type: "text/html", Href: pageURL},
+		},
+		Updated: updated.UTC().Format(time.RFC3339),
+		Author:  person{Name: "Arc Library"},
+	}
+	for _, it := range items {
+		d := it.Document
+		anchor := pageURL + "#" + d.ID
+		e := entry{
+			ID:        anchor,
+			Title:     d.Title,
+			Link:      link{Rel: "alternate", Href: anchor},
+			Updated:   it.AddedAt.UTC().Format(time.RFC3339),
+			Published: it.AddedAt.UTC().Format(time.RFC3339),
+			Summary:   d.Abstract,
+		}
+		if it.URL != "" {
+			e.Link.Href = it.URL
+		}
+		for _, a := range d.Authors {
+			if a = strings.TrimSpace(a); a != "" {
+				e.Authors = append(e.Authors, person{Name: a})
+			}
+		}
+		doc.Entries = append(doc.Entries, e)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+func TestCollectionFeed(t *testing.T) {
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := s.CreateCollection("reading group", "Papers for Thursdays")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range []*Document{
+		{ID: "a", Title: "First", Source: IDSourceArxiv, SourceID: "1706.03762", Authors: []string{"A. Vaswani"}},
+		{ID: "b", Title: "Second & <newer>", Source: "local", Path: "/home/me/secret.pdf", Abstract: "About things."},
+	} {
+		if err := s.AddDocument(d); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.AddToCollection(c.ID, d.ID); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+	if err := s.SetCollectionPublic(c.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	if c, _ = s.GetCollection("reading group"); !c.Public || len(c.AddedAt) != 2 {
+		t.Fatalf("collection = %+v", c)
+	}
+
+	items, err := CollectionReadingList(s, c, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 || items[0].Document.ID != "b" || items[0].URL != "" || items[1].URL != "https://arxiv.org/abs/1706.03762" {
+		t.Fatalf("items = %+v", items)
+	}
+	if limited, _ := CollectionReadingList(s, c, 1); len(limited) != 1 {
+		t.Errorf("limit 1 gave %d items", len(limited))
+	}
+
+	var buf bytes.Buffer
+	page := "https://papers.example.org/list/reading%20group"
+	if err := WriteCollectionAtom(&buf, c, items, "https://papers.example.org/feed/collection/reading%20group.xml", page); err != nil {
+		t.Fatal(err)
+	}
+	var feed struct {
+		Title   string `xml:"title"`
+		Entries []struct {
+			Title string `xml:"title"`
+			Link  struct {
+				Href string `xml:"href,attr"`
+			} `xml:"link"`
+			Authors []string `xml:"author>name"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &feed); err != nil {
+		t.Fatalf("feed does not parse: %v\n%s", err, buf.String())
+	}
+	if feed.Title != "reading group" || len(feed.Entries) != 2 {
+		t.Fatalf("feed = %+v", feed)
+	}
+	if e := feed.Entries[0]; e.Title != "Second & <newer>" || e.Link.Href != page+"#b" {
+		t.Errorf("local document entry = %+v", e)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("secret.pdf")) {
+		t.Error("feed exposes a local file path")
+	}
+	if e := feed.Entries[1]; len(e.Authors) != 1 || e.Authors[0] != "A. Vaswani" {
+		t.Errorf("arXiv entry = %+v", e)
+	}
+}
@@ -0,0 +1,297 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldType is the value type of a custom document field.
+type FieldType string
+
+const (
+	FieldString FieldType = "string"
+	FieldInt    FieldType = "int"
+	FieldFloat  FieldType = "float"
+	FieldBool   FieldType = "bool"
+	FieldDate   FieldType = "date" // stored as YYYY-MM-DD
+	FieldEnum   FieldType = "enum" // one of FieldDef.Values
+)
+
+// FieldTypes lists the supported field types.
+var FieldTypes = []FieldType{FieldString, FieldInt, FieldFloat, FieldBool, FieldDate, FieldEnum}
+
+// FieldDef declares a custom field stored in Document.Meta under Name.
+type FieldDef struct {
+	Name      string    `json:"name" yaml:"name"`
+	Type      FieldType `json:"type" yaml:"type"`
+	Values    []string  `json:"values,omitempty" yaml:"values,omitempty"` // allowed enum values
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
+}
+
+var fieldNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// ParseFieldDef parses a "name:type" declaration such as "year:int" or
+// "stage:enum(draft,review,final)".
+func ParseFieldDef(s string) (*FieldDef, error) {
+	name, typ, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid field %q (use name:type)", s)
+	}
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !fieldNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid field name %q (use lowercase letters, digits and _)", name)
+	}
+
+	def := &FieldDef{Name: name}
+	typ = strings.ToLower(strings.TrimSpace(typ))
+	if rest, ok := strings.CutPrefix(typ, "enum("); ok {
+		list, ok := strings.CutSuffix(rest, ")")
+		if !ok {
+			return nil, fmt.Errorf("invalid field %q: missing ) after enum values", s)
+		}
+		for _, v := range strings.Split(list, ",") {
+			v = strings.TrimSpace(v)
+			if v != "" && !slices.Contains(def.Values, v) {
+				def.Values = append(def.Values, v)
+			}
+		}
+		if len(def.Values) == 0 {
+			return nil, fmt.Errorf("invalid field %q: enum needs at least one value", s)
+		}
+		def.Type = FieldEnum
+		return def, nil
+	}
+
+	def.Type = FieldType(typ)
+	if def.Type == FieldEnum || !slices.Contains(FieldTypes, def.Type) {
+		return nil, fmt.Errorf("invalid field type %q (use string, int, float, bool, date, enum(a,b,...))", typ)
+	}
+	return def, nil
+}
+
+// String returns the declaration ParseFieldDef accepts.
+func (d *FieldDef) String() string {
+	if d.Type == FieldEnum {
+		return fmt.Sprintf("%s:enum(%s)", d.Name, strings.Join(d.Values, ","))
+	}
+	return d.Name + ":" + string(d.Type)
+}
+
+// Parse converts a command-line value to the typed value stored in Meta.
+func (d *FieldDef) Parse(value string) (any, error) {
+	value = strings.TrimSpace(value)
+	switch d.Type {
+	case FieldInt:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %q is not an integer", d.Name, value)
+		}
+		return n, nil
+	case FieldFloat:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %q is not a number", d.Name, value)
+		}
+		return f, nil
+	case FieldBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %q is not true or false", d.Name, value)
+		}
+		return b, nil
+	case FieldDate:
+		t, err := ParseDate(value, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("%s: %q is not a date (use e.g. 2025-01-31 or next friday)", d.Name, value)
+		}
+		return t.Format("2006-01-02"), nil
+	case FieldEnum:
+		for _, v := range d.Values {
+			if strings.EqualFold(v, value) {
+				return v, nil
+			}
+		}
+		return nil, fmt.Errorf("%s: %q is not one of %s", d.Name, value, strings.Join(d.Values, ", "))
+	default:
+		return value, nil
+	}
+}
+
+// Validate reports whether a value already in Meta, as decoded from JSON,
+// fits the field.
+func (d *FieldDef) Validate(v any) error {
+	switch d.Type {
+	case FieldInt:
+		if f, ok := toFloat(v); !ok || f != float64(int64(f)) {
+			return fmt.Errorf("%s: %v is not an integer", d.Name, v)
+		}
+		return nil
+	case FieldFloat:
+		if _, ok := toFloat(v); !ok {
+			return fmt.Errorf("%s: %v is not a number", d.Name, v)
+		}
+		return nil
+	case FieldBool:
+		if _, ok := v.(bool); !ok {
+			return fmt.Errorf("%s: %v is not true or false", d.Name, v)
+		}
+		return nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("%s: %v is not text", d.Name, v)
+	}
+	if d.Type == FieldString {
+		return nil
+	}
+	_, err := d.Parse(s)
+	return err
+}
+
+// FieldFilter is a condition on a custom field, such as year >= 2020.
+// Documents without the field never match.
+type FieldFilter struct {
+	Field *FieldDef
+	Op    string // =, !=, <, <=, >, >=
+	Value any    // typed as FieldDef.Parse returns
+}
+
+// fieldOps are tried longest first so ">=" is not read as ">".
+var fieldOps = []string{"!=", ">=", "<=", "=", ":", "<", ">"}
+
+// ParseFieldFilter parses "name<op>value" against the defined fields, where
+// op is one of = : != < <= > >= and ":" means "=". Ordering operators only
+// apply to numbers, dates and strings.
+func ParseFieldFilter(expr string, defs []*FieldDef) (*FieldFilter, error) {
+	i, op := fieldOpIndex(expr)
+	if i < 0 {
+		return nil, fmt.Errorf("invalid field filter %q (use name=value, name>=value, ...)", expr)
+	}
+	name := strings.ToLower(strings.TrimSpace(expr[:i]))
+	def := findField(defs, name)
+	if def == nil {
+		return nil, fmt.Errorf("field %q is not defined", name)
+	}
+	if op == ":" {
+		op = "="
+	}
+	if op != "=" && op != "!=" && (def.Type == FieldBool || def.Type == FieldEnum) {
+		return nil, fmt.Errorf("%s: %s fields only support = and !=", name, def.Type)
+	}
+	value, err := def.Parse(expr[i+len(op):])
+	if err != nil {
+		return nil, err
+	}
+	return &FieldFilter{Field: def, Op: op, Value: value}, nil
+}
+
+// fieldOpIndex returns the position and text of the first operator in expr.
+func fieldOpIndex(expr string) (int, string) {
+	for i := range expr {
+		for _, op := range fieldOps {
+			if strings.HasPrefix(expr[i:], op) {
+				return i, op
+			}
+		}
+	}
+	return -1, ""
+}
+
+// SplitFieldQuery separates a search query into free text and conditions
+// on defined fields, so "attention year>=2020 venue:NeurIPS" searches for
+// "attention" among matching documents. Words naming no defined field are
+// left in the text.
+func SplitFieldQuery(query string, defs []*FieldDef) (string, []FieldFilter, error) {
+	var words []string
+	var filters []FieldFilter
+	for _, w := range strings.Fields(query) {
+		if i, _ := fieldOpIndex(w); i > 0 && findField(defs, strings.ToLower(w[:i])) != nil {
+			f, err := ParseFieldFilter(w, defs)
+			if err != nil {
+				return "", nil, err
+			}
+			filters = append(filters, *f)
+			continue
+		}
+		words = append(words, w)
+	}
+	return strings.Join(words, " "), filters, nil
+}
+
+// Match reports whether doc satisfies the filter.
+func (f FieldFilter) Match(doc *Document) bool {
+	v, ok := doc.Meta[f.Field.Name]
+	if !ok || v == nil || f.Field.Validate(v) != nil {
+		return false
+	}
+
+	var c int
+	switch want := f.Value.(type) {
+	case int64, float64:
+		got, _ := toFloat(v)
+		w, _ := toFloat(want)
+		c = cmpFloat(got, w)
+	case bool:
+		if v.(bool) == want {
+			c = 0
+		} else {
+			c = 1
+		}
+	case string:
+		c = strings.Compare(v.(string), want)
+	}
+
+	switch f.Op {
+	case "=":
+		return c == 0
+	case "!=":
+		return c != 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	}
+	return false
+}
+
+func findField(defs []*FieldDef, name string) *FieldDef {
+	for _, d := range defs {
+		if d.Name == name {
+			return d
+		}
+	}
+	return nil
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int64:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func cmpFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
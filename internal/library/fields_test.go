@@ -0,0 +1,125 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+func TestParseFieldDef(t *testing.T) {
+	def, err := ParseFieldDef("Stage:enum(draft, review,final,draft)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def.Name != "stage" || def.Type != FieldEnum || !reflect.DeepEqual(def.Values, []string{"draft", "review", "final"}) {
+		t.Errorf("def = %+v", def)
+	}
+	if def.String() != "stage:enum(draft,review,final)" {
+		t.Errorf("String() = %q", def.String())
+	}
+
+	for _, bad := range []string{"year", "year:integer", "2x:int", "stage:enum()", "stage:enum(a", "s:enum"} {
+		if _, err := ParseFieldDef(bad); err == nil {
+			t.Errorf("ParseFieldDef(%q) should fail", bad)
+		}
+	}
+}
+
+func TestFieldFilters(t *testing.T) {
+	defs := []*FieldDef{
+		{Name: "year", Type: FieldInt},
+		{Name: "venue", Type: FieldString},
+		{Name: "stage", Type: FieldEnum, Values: []string{"draft", "final"}},
+		{Name: "peer", Type: FieldBool},
+	}
+
+	text, filters, err := SplitFieldQuery("attention year>=2020 stage:Final note:x", defs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "attention note:x" || len(filters) != 2 {
+		t.Fatalf("split = %q, %+v", text, filters)
+	}
+	if filters[1].Op != "=" || filters[1].Value != "final" {
+		t.Errorf("stage filter = %+v, want = final", filters[1])
+	}
+
+	if _, _, err := SplitFieldQuery("year>=soon", defs); err == nil {
+		t.Error("invalid value for a defined field should fail")
+	}
+	if _, err := ParseFieldFilter("stage>draft", defs); err == nil {
+		t.Error("ordering an enum should fail")
+	}
+
+	// Meta as it comes back from JSON: numbers are float64
+	var meta JSONMap
+	if err := json.Unmarshal([]byte(`{"year": 2021, "stage": "final", "peer": true, "venue": 7}`), &meta); err != nil {
+		t.Fatal(err)
+	}
+	doc := &Document{Meta: meta}
+	cases := map[string]bool{
+		"year>=2020":   true,
+		"year<2021":    false,
+		"year!=2020":   true,
+		"stage=final":  true,
+		"stage!=final": false,
+		"peer=true":    true,
+		"venue!=x":     false, // wrong stored type never matches
+	}
+	for expr, want := range cases {
+		f, err := ParseFieldFilter(expr, defs)
+		if err != nil {
+			t.Fatalf("ParseFieldFilter(%q): %v", expr, err)
+		}
+		if got := f.Match(doc); got != want {
+			t.Errorf("%s matched = %v, want %v", expr, got, want)
+		}
+	}
+	if f, _ := ParseFieldFilter("year>0", defs); f.Match(&Document{}) {
+		t.Error("a document without the field should not match")
+	}
+}
+
+func TestKVStoreFields(t *testing.T) {
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, decl := range []string{"year:int", "venue:string", "year:float"} {
+		def, _ := ParseFieldDef(decl)
+		if err := s.DefineField(def); err != nil {
+			t.Fatal(err)
+		}
+	}
+	defs, err := s.ListFields()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(defs) != 2 || defs[0].Name != "venue" || defs[1].Type != FieldFloat {
+		t.Errorf("fields = %+v, want venue and year:float", defs)
+	}
+
+	s.AddDocument(&Document{ID: "old", Title: "Old", Meta: JSONMap{"year": 2015}})
+	s.AddDocument(&Document{ID: "new", Title: "New", Meta: JSONMap{"year": 2023}})
+	f, _ := ParseFieldFilter("year>=2020", defs)
+	docs, err := s.ListDocuments(&ListOptions{Fields: []FieldFilter{*f}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 1 || docs[0].ID != "new" {
+		t.Errorf("filtered documents = %d, want only new", len(docs))
+	}
+
+	if err := s.DeleteField("venue"); err != nil {
+		t.Fatal(err)
+	}
+	if defs, _ := s.ListFields(); len(defs) != 1 {
+		t.Errorf("got %d fields after delete, want 1", len(defs))
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrFileNotReady is returned by CheckFileReady for files that are still
+// being written.
+var ErrFileNotReady = errors.New("file not ready")
+
+// pdfTrailerWindow is how far from the end of a PDF its %%EOF marker is
+// looked for; writers may append a little whitespace or garbage after it.
+const pdfTrailerWindow = 1024
+
+// CheckFileReady reports whether a file has finished being written: it is
+// non-empty, its size and modification time stay the same across checks
+// samples taken interval apart, it can be opened for writing (which fails
+// on Windows while another process holds it open), and a PDF ends with its
+// %%EOF trailer. Pass checks 0 to skip the sampling. Errors for files that
+// are not ready wrap ErrFileNotReady.
+func CheckFileReady(path string, interval time.Duration, checks int) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.Size() == 0 {
+		return fmt.Errorf("%w: empty", ErrFileNotReady)
+	}
+
+	for i := 0; i < checks; i++ {
+		time.Sleep(interval)
+		next, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if next.Size() != info.Size() || !next.ModTime().Equal(info.ModTime()) {
+			return fmt.Errorf("%w: still growing", ErrFileNotReady)
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		// A read-only file can't be opened for writing either, but isn't
+		// being written
+		if !errors.Is(err, fs.ErrPermission) {
+			return fmt.Errorf("%w: in use: %v", ErrFileNotReady, err)
+		}
+	} else {
+		f.Close()
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".pdf") {
+		return checkPDFComplete(path, info.Size())
+	}
+	return nil
+}
+
+// checkPDFComplete checks for the %PDF header and the %%EOF trailer that
+// a half-downloaded file lacks.
+func checkPDFComplete(path string, size int64) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	head := make([]byte, 5)
+	if _, err := io.ReadFull(f, head); err != nil || !bytes.Equal(head, []byte("%PDF-")) {
+		return fmt.Errorf("%w: no PDF header", ErrFileNotReady)
+	}
+
+	offset := max(size-pdfTrailerWindow, 0)
+	tail := make([]byte, size-offset)
+	if _, err := f.ReadAt(tail, offset); err != nil && err != io.EOF {
+		return err
+	}
+	if !bytes.Contains(tail, []byte("%%EOF")) {
+		return fmt.Errorf("%w: PDF has no end-of-file marker", ErrFileNotReady)
+	}
+	return nil
+}
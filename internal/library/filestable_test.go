@@ -0,0 +1,44 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckFileReady(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	complete := write("complete.pdf", "%PDF-1.7\n"+strings.Repeat("x", 4096)+"\n%%EOF\n")
+	if err := CheckFileReady(complete, time.Millisecond, 2); err != nil {
+		t.Errorf("complete PDF: %v", err)
+	}
+
+	for name, content := range map[string]string{
+		"empty.pdf":     "",
+		"truncated.pdf": "%PDF-1.7\n" + strings.Repeat("x", 4096),
+		"fake.pdf":      "<html>error page</html>\n%%EOF",
+	} {
+		if err := CheckFileReady(write(name, content), 0, 0); !errors.Is(err, ErrFileNotReady) {
+			t.Errorf("%s: err = %v, want ErrFileNotReady", name, err)
+		}
+	}
+
+	// Only PDFs are checked for a trailer
+	if err := CheckFileReady(write("notes.txt", "partial"), 0, 0); err != nil {
+		t.Errorf("text file: %v", err)
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// findDocumentCandidates caps the search matches FindDocument looks
+// through, and lists when none is exact.
+const findDocumentCandidates = 20
+
+// FindDocument returns the document a command argument names: its ID,
+// or else its source ID (as "2301.00001" or "arxiv:2301.00001") or whole
+// title, ignoring case. A search that matches other documents, or names
+// more than one, is an error listing them rather than a guess, so a
+// command never acts on the wrong document.
+func FindDocument(s LibraryStore, idOrQuery string) (*Document, error) {
+	doc, err := s.GetDocument(idOrQuery)
+	if err != nil || doc != nil {
+		return doc, err
+	}
+	q := strings.TrimSpace(idOrQuery)
+	if q == "" {
+		return nil, fmt.Errorf("%w: %q", ErrDocumentNotFound, idOrQuery)
+	}
+	var exact []*Document
+	add := func(d *Document) {
+		if !slices.ContainsFunc(exact, func(e *Document) bool { return e.ID == d.ID }) {
+			exact = append(exact, d)
+		}
+	}
+	// Source IDs aren't searched; look them up as given, or as an arXiv
+	// ID or DOI
+	lookups := [][2]string{{"arxiv", q}, {"doi", q}}
+	if source, id, ok := strings.Cut(q, ":"); ok {
+		lookups = append(lookups, [2]string{source, id})
+	}
+	for _, l := range lookups {
+		d, err := s.GetDocumentBySourceID(l[0], l[1])
+		if err != nil {
+			return nil, err
+		}
+		if d != nil {
+			add(d)
+		}
+	}
+	docs, err := s.ListDocuments(&ListOptions{Search: q, Limit: findDocumentCandidates})
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range docs {
+		if documentNamed(d, q) {
+			add(d)
+		}
+	}
+	switch {
+	case len(exact) == 1:
+		return exact[0], nil
+	case len(exact) > 1:
+		return nil, fmt.Errorf("%q names %d documents; use an ID:%s", idOrQuery, len(exact), candidateList(exact))
+	case len(docs) > 0:
+		return nil, fmt.Errorf("%w: no ID, source ID, or title is %q; matching documents:%s", ErrDocumentNotFound, idOrQuery, candidateList(docs))
+	}
+	return nil, fmt.Errorf("%w: %s", ErrDocumentNotFound, idOrQuery)
+}
+
+// documentNamed reports whether q is d's source ID or title.
+func documentNamed(d *Document, q string) bool {
+	return d.SourceID != "" && (strings.EqualFold(d.SourceID, q) || strings.EqualFold(d.Source+":"+d.SourceID, q)) ||
+		strings.EqualFold(strings.TrimSpace(d.Title), q)
+}
+
+// candidateList formats documents for an error, one per line.
+func candidateList(docs []*Document) string {
+	var b strings.Builder
+	for _, d := range docs {
+		fmt.Fprintf(&b, "\n  %s  %s", d.ID, d.Title)
+	}
+	return b.String()
+}
@@ -0,0 +1,40 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFindDocument(t *testing.T) {
+	for _, backend := range benchBackends {
+		t.Run(backend.name, func(t *testing.T) {
+			s := backend.open(t)
+			attention := &Document{Title: "Attention Is All You Need", Type: DocTypePaper, Source: "arxiv", SourceID: "1706.03762"}
+			survey := &Document{Title: "Attention Mechanisms: A Survey", Type: DocTypePaper}
+			twin := &Document{Title: "Notes", Type: DocTypeNote}
+			twin2 := &Document{Title: "notes", Type: DocTypeNote}
+			if err := s.AddDocuments([]*Document{attention, survey, twin, twin2}); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, arg := range []string{attention.ID, "1706.03762", "arxiv:1706.03762", "attention is all you need"} {
+				if doc, err := FindDocument(s, arg); err != nil || doc.ID != attention.ID {
+					t.Errorf("%q: %v, %v", arg, doc, err)
+				}
+			}
+			// Matches that the argument doesn't name are listed, not guessed at
+			if _, err := FindDocument(s, "attention"); !errors.Is(err, ErrDocumentNotFound) {
+				t.Errorf("partial title: %v", err)
+			}
+			if _, err := FindDocument(s, "notes"); err == nil || errors.Is(err, ErrDocumentNotFound) {
+				t.Errorf("title of two documents: %v", err)
+			}
+			if _, err := FindDocument(s, "missing"); !errors.Is(err, ErrDocumentNotFound) {
+				t.Errorf("no match: %v", err)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/mtreilly/arc-library/internal/scheduler"
 	"github.com/yourorg/arc-sdk/store"
 )
 
@@ -153,4 +154,18 @@ func TestFlashcardSM2(t *testing.T) {
 	if !due2.After(due1) {
 		t.Error("Second due date should be after first")
 	}
+
+	// Each review records the scheduler settings it used
+	reviews, err := s.ListFlashcardReviews(card.ID)
+	if err != nil {
+		t.Fatalf("ListFlashcardReviews: %v", err)
+	}
+	if len(reviews) != 2 {
+		t.Fatalf("%d reviews, want 2", len(reviews))
+	}
+	for _, r := range reviews {
+		if r.Params != scheduler.Current().String() {
+			t.Errorf("review params = %q, want %q", r.Params, scheduler.Current().String())
+		}
+	}
 }
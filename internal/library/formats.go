@@ -0,0 +1,160 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"slices"
+	"sort"
+)
+
+// Format capabilities, listed by "formats list".
+const (
+	FormatTags        = "tags"        // carries tags
+	FormatFiles       = "files"       // refers to the documents' local files
+	FormatAnnotations = "annotations" // includes annotations and notes
+	FormatAllFields   = "all-fields"  // every document field, lossless
+	FormatGraph       = "graph"       // documents and their connections; takes edge kinds
+	FormatDirectory   = "directory"   // imports a directory as one document
+	FormatFlashcards  = "flashcards"  // made from the documents' flashcards
+	FormatMetadata    = "metadata"    // reference records whose metadata import keeps as is
+)
+
+// Format describes an importer or exporter.
+type Format struct {
+	Name         string   `json:"name"`
+	Description  string   `json:"description"`
+	Extensions   []string `json:"extensions"`
+	Capabilities []string `json:"capabilities"`
+}
+
+// Has reports whether the format has a capability.
+func (f Format) Has(capability string) bool {
+	return slices.Contains(f.Capabilities, capability)
+}
+
+// ExportOptions are the settings an exporter may use.
+type ExportOptions struct {
+	Edges map[string]bool // graph edge kinds from ParseGraphEdgeKinds; nil for all
+
+	// Quiz options: group questions by "document" (the default) or
+	// "collection", and optionally add questions beyond the flashcards,
+	// such as AI-selected key points, for each document.
+	GroupBy   string
+	KeyPoints func(*Document) ([]GeneratedCard, error)
+
+	// Markdown with GroupBy "meaning" lists each document's annotations
+	// under the meanings of their colors.
+	ColorMeanings ColorMeanings
+
+	// OnProgress, if set, is called after each document by the exporters
+	// that read more than the documents themselves: backup, quiz, and
+	// markdown to a single file.
+	OnProgress func(done, total int)
+}
+
+// Exporter writes documents in a format. Exporters register themselves
+// with RegisterExporter from an init function in their own file.
+type Exporter interface {
+	Format() Format
+	Export(w io.Writer, s LibraryStore, docs []*Document, opts ExportOptions) error
+}
+
+// Importer reads documents from a file or directory. The documents it
+// returns are not yet in the library: the caller stores them, applying
+// tags, type detection, and the like. Importers register themselves with
+// RegisterImporter from an init function in their own file.
+type Importer interface {
+	Format() Format
+	// Detect reports whether the importer reads path.
+	Detect(path string, info fs.FileInfo) bool
+	Import(path string) ([]*Document, error)
+}
+
+var (
+	exporters = map[string]Exporter{}
+	importers = map[string]Importer{}
+)
+
+// RegisterExporter makes an exporter available by its format name. It
+// panics if the name is taken.
+func RegisterExporter(e Exporter) {
+	name := e.Format().Name
+	if _, ok := exporters[name]; ok {
+		panic(fmt.Sprintf("library: exporter %q registered twice", name))
+	}
+	exporters[name] = e
+}
+
+// RegisterImporter makes an importer available by its format name. It
+// panics if the name is taken.
+func RegisterImporter(i Importer) {
+	name := i.Format().Name
+	if _, ok := importers[name]; ok {
+		panic(fmt.Sprintf("library: importer %q registered twice", name))
+	}
+	importers[name] = i
+}
+
+// LookupExporter returns the exporter for a format name, or nil.
+func LookupExporter(name string) Exporter {
+	return exporters[name]
+}
+
+// LookupImporter returns the importer for a format name, or nil.
+func LookupImporter(name string) Importer {
+	return importers[name]
+}
+
+// Exporters returns the registered exporters by name.
+func Exporters() []Exporter {
+	list := make([]Exporter, 0, len(exporters))
+	for _, e := range exporters {
+		list = append(list, e)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Format().Name < list[j].Format().Name })
+	return list
+}
+
+// Importers returns the registered importers by name.
+func Importers() []Importer {
+	list := make([]Importer, 0, len(importers))
+	for _, i := range importers {
+		list = append(list, i)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Format().Name < list[j].Format().Name })
+	return list
+}
+
+// ExporterNames returns the registered export format names, sorted.
+func ExporterNames() []string {
+	var names []string
+	for _, e := range Exporters() {
+		names = append(names, e.Format().Name)
+	}
+	return names
+}
+
+// ImportExtensions returns the file extensions the importers read, in
+// importer order.
+func ImportExtensions() []string {
+	var exts []string
+	for _, i := range Importers() {
+		exts = append(exts, i.Format().Extensions...)
+	}
+	return exts
+}
+
+// DetectImporter returns the first importer, by name, that reads path,
+// or nil when none does.
+func DetectImporter(path string, info fs.FileInfo) Importer {
+	for _, i := range Importers() {
+		if i.Detect(path, info) {
+			return i
+		}
+	}
+	return nil
+}
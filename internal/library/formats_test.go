@@ -0,0 +1,120 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+func TestFormatRegistry(t *testing.T) {
+	names := ExporterNames()
+	for _, want := range []string{"bibtex", "dot", "graphml", "json", "json-graph", "markdown", "ris"} {
+		if !slices.Contains(names, want) {
+			t.Errorf("exporter %q not registered: %v", want, names)
+		}
+	}
+	if !slices.IsSorted(names) {
+		t.Errorf("exporter names not sorted: %v", names)
+	}
+	if LookupExporter("nope") != nil || LookupImporter("pdf") == nil {
+		t.Error("lookup by name")
+	}
+	if !LookupExporter("graphml").Format().Has(FormatGraph) || LookupExporter("bibtex").Format().Has(FormatGraph) {
+		t.Error("graph capability")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a taken name did not panic")
+		}
+	}()
+	RegisterExporter(bibtexExporter{})
+}
+
+func TestImporters(t *testing.T) {
+	dir := t.TempDir()
+	paper := filepath.Join(dir, "2301.00001")
+	pdf := filepath.Join(dir, "Some Paper.PDF")
+	if err := os.Mkdir(paper, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	meta := "arxiv_id: \"2301.00001\"\ntitle: Meta Paper\nsource_type: arxiv\nauthors:\n  - name: Ada Lovelace\n  - name: Charles Babbage\n"
+	if err := os.WriteFile(filepath.Join(paper, "meta.yaml"), []byte(meta), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(pdf, []byte("%PDF-1.4\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	detect := func(path string) string {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if imp := DetectImporter(path, info); imp != nil {
+			return imp.Format().Name
+		}
+		return ""
+	}
+	if got := detect(paper); got != "arxiv-meta" {
+		t.Errorf("meta directory detected as %q", got)
+	}
+	if got := detect(pdf); got != "pdf" {
+		t.Errorf("PDF detected as %q", got)
+	}
+	if got := detect(dir); got != "" {
+		t.Errorf("plain directory detected as %q", got)
+	}
+
+	docs, err := LookupImporter("arxiv-meta").Import(paper)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 1 || docs[0].Title != "Meta Paper" || docs[0].SourceID != "2301.00001" || docs[0].Source != "arxiv" ||
+		!slices.Equal(docs[0].Authors, []string{"Ada Lovelace", "Charles Babbage"}) || docs[0].Path != paper {
+		t.Errorf("meta import = %+v", docs)
+	}
+	if docs, _ = LookupImporter("pdf").Import(pdf); len(docs) != 1 || docs[0].Title != "Some Paper" || docs[0].Type != DocTypePaper {
+		t.Errorf("PDF import = %+v", docs)
+	}
+}
+
+func TestExporters(t *testing.T) {
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := &Document{ID: "d1", Title: "Attention {Is} All", Authors: []string{"Ashish Vaswani"}, Source: IDSourceArxiv, SourceID: "1706.03762", Tags: []string{"ml"}}
+	if err := s.AddDocument(doc); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddAnnotation(&Annotation{DocumentID: "d1", Type: "note", Content: "Key idea"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[string][]string{
+		"bibtex":     {"@article{1706.03762", `title = {Attention \{Is\} All}`, "eprint = {1706.03762}", "keywords = {ml}"},
+		"ris":        {"TY  - JOUR", "AU  - Ashish Vaswani", "UR  - https://arxiv.org/abs/1706.03762", "ER  - "},
+		"markdown":   {"## Attention {Is} All", "- [note] Key idea"},
+		"json":       {`"id": "d1"`},
+		"json-graph": {`"author:ashish vaswani"`, `"tag:ml"`},
+	} {
+		var buf bytes.Buffer
+		if err := LookupExporter(name).Export(&buf, s, []*Document{doc}, ExportOptions{}); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		for _, w := range want {
+			if !strings.Contains(buf.String(), w) {
+				t.Errorf("%s export lacks %q:\n%s", name, w, buf.String())
+			}
+		}
+	}
+}
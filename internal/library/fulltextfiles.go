@@ -0,0 +1,230 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// With ARC_LIBRARY_FULLTEXT=external, full text is kept out of the
+// database, in plain text files under FullTextDir named by the SHA-256 of
+// the text, and the database holds only that reference. The database
+// stays small and the text can be grepped on disk. Documents with the
+// same text share a file. References are read whatever the setting, so
+// switching back to inline storage leaves the library readable, and
+// "db compact" moves existing text to match the setting and removes the
+// files nothing refers to.
+
+// fullTextRef starts a stored reference to a full text file. 0xff never
+// occurs in UTF-8, so it cannot be mistaken for text.
+var fullTextRef = []byte("\xffarc-fulltext:")
+
+// ExternalFullText reports whether full text is stored in files, going by
+// $ARC_LIBRARY_FULLTEXT: inline (the default) or external.
+func ExternalFullText() (bool, error) {
+	switch s := strings.ToLower(strings.TrimSpace(os.Getenv("ARC_LIBRARY_FULLTEXT"))); s {
+	case "", "inline":
+		return false, nil
+	case "external":
+		return true, nil
+	default:
+		return false, fmt.Errorf("invalid ARC_LIBRARY_FULLTEXT %q (use inline or external)", s)
+	}
+}
+
+// FullTextDir returns where external full text is kept:
+// $ARC_LIBRARY_FULLTEXT_DIR, or a directory beside the database named
+// after it and the storage backend, such as arc.db.fulltext, so that no
+// two libraries share one by default.
+func FullTextDir() (string, error) {
+	if dir := os.Getenv("ARC_LIBRARY_FULLTEXT_DIR"); dir != "" {
+		return ExpandPath(dir), nil
+	}
+	if storageBackend() == "sql" {
+		return DatabasePath() + ".fulltext", nil
+	}
+	return DatabasePath() + ".kv-fulltext", nil
+}
+
+// legacyFullTextDir is where every library kept external full text before
+// each had its own directory. Files there are still read, and never
+// removed, as other libraries may refer to them.
+func legacyFullTextDir() (string, error) {
+	if os.Getenv("ARC_LIBRARY_FULLTEXT_DIR") != "" {
+		return "", nil
+	}
+	base, err := dataHome()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "arc", "fulltext"), nil
+}
+
+// storageBackend returns $ARC_LIBRARY_STORAGE, or sql by default.
+func storageBackend() string {
+	if s := os.Getenv("ARC_LIBRARY_STORAGE"); s != "" {
+		return s
+	}
+	return "sql"
+}
+
+// fullTextOwnerFile, in a full text directory, names the library that
+// keeps its text there, so that no other library prunes it.
+const fullTextOwnerFile = ".library"
+
+// fullTextOwner names this process's library for fullTextOwnerFile.
+func fullTextOwner() string {
+	return storageBackend() + " " + DatabasePath()
+}
+
+// claimFullTextDir marks dir as this library's, if no library has claimed
+// it yet, and fails if another has.
+func claimFullTextDir(dir string) error {
+	owner := filepath.Join(dir, fullTextOwnerFile)
+	data, err := os.ReadFile(owner)
+	if errors.Is(err, os.ErrNotExist) {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("create full text directory: %w", err)
+		}
+		return os.WriteFile(owner, []byte(fullTextOwner()+"\n"), 0o644)
+	}
+	if err != nil {
+		return err
+	}
+	if got := strings.TrimSpace(string(data)); got != fullTextOwner() {
+		return fmt.Errorf("full text directory %s belongs to another library (%s); set ARC_LIBRARY_FULLTEXT_DIR to a directory of this library's own", dir, got)
+	}
+	return nil
+}
+
+// fullTextHash names the file holding text.
+func fullTextHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// FullTextFile returns the file that holds the text with the given hash,
+// in a subdirectory named by its first two digits.
+func FullTextFile(hash string) (string, error) {
+	dir, err := FullTextDir()
+	if err != nil {
+		return "", err
+	}
+	if len(hash) < 3 {
+		return "", fmt.Errorf("invalid full text reference %q", hash)
+	}
+	return filepath.Join(dir, hash[:2], hash+".txt"), nil
+}
+
+// writeFullTextFile stores text in its file, unless it is there already,
+// and returns the reference to it.
+func writeFullTextFile(text string) ([]byte, error) {
+	hash := fullTextHash(text)
+	path, err := FullTextFile(hash)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); err != nil {
+		if err := claimFullTextDir(filepath.Dir(filepath.Dir(path))); err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil, fmt.Errorf("create full text directory: %w", err)
+		}
+		// Write under another name first, so a file with the text's
+		// name always holds all of it
+		tmp, err := os.CreateTemp(filepath.Dir(path), hash+".*.tmp")
+		if err != nil {
+			return nil, fmt.Errorf("write full text: %w", err)
+		}
+		_, werr := tmp.WriteString(text)
+		if err := tmp.Close(); werr == nil {
+			werr = err
+		}
+		if werr == nil {
+			werr = os.Rename(tmp.Name(), path)
+		}
+		if werr != nil {
+			os.Remove(tmp.Name())
+			return nil, fmt.Errorf("write full text: %w", werr)
+		}
+	}
+	return append(append([]byte{}, fullTextRef...), hash...), nil
+}
+
+// readFullTextFile returns the text a reference points to. A missing file
+// reads as no text, so that one lost file does not stop listings.
+func readFullTextFile(ref []byte) (string, error) {
+	hash, _ := fullTextRefHash(ref)
+	path, err := FullTextFile(hash)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		// Written before the library had a directory of its own
+		if legacy, _ := legacyFullTextDir(); legacy != "" && len(hash) > 2 {
+			data, err = os.ReadFile(filepath.Join(legacy, hash[:2], hash+".txt"))
+		}
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("read full text: %w", err)
+	}
+	return string(data), nil
+}
+
+func isFullTextRef(stored []byte) bool {
+	return bytes.HasPrefix(stored, fullTextRef)
+}
+
+// pruneFullTextFiles removes the full text files not in used, by hash:
+// those left behind when documents are deleted, their text changes, or
+// it moves back into the database. It returns how many it removed. It
+// refuses to prune a directory another library has claimed, and leaves
+// the legacy shared directory alone.
+func pruneFullTextFiles(used map[string]bool) (int, error) {
+	dir, err := FullTextDir()
+	if err != nil {
+		return 0, err
+	}
+	if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err := claimFullTextDir(dir); err != nil {
+		return 0, err
+	}
+	removed := 0
+	err = filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if hash, ok := strings.CutSuffix(d.Name(), ".txt"); ok && !used[hash] {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+			removed++
+		}
+		return nil
+	})
+	return removed, err
+}
+
+// fullTextRefHash returns the hash a stored reference names, if stored is
+// one.
+func fullTextRefHash(stored []byte) (string, bool) {
+	if !isFullTextRef(stored) {
+		return "", false
+	}
+	return string(stored[len(fullTextRef):]), true
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+func TestExternalFullText(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("ARC_LIBRARY_FULLTEXT_DIR", dir)
+	t.Setenv("ARC_LIBRARY_FULLTEXT", "external")
+
+	kv, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "library.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	sqlStore, err := NewStore(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, s := range map[string]LibraryStore{"kv": kv, "sql": sqlStore} {
+		doc := &Document{Title: "Transformers", Type: "paper", Path: "/papers/" + name + ".pdf", Source: "local", FullText: longText}
+		if err := s.AddDocument(doc); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		path, err := FullTextFile(fullTextHash(longText))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if data, err := os.ReadFile(path); err != nil || string(data) != longText {
+			t.Fatalf("%s: text file %s: %v", name, path, err)
+		}
+		if got, err := s.GetDocument(doc.ID); err != nil || got.FullText != longText {
+			t.Errorf("%s: full text did not round-trip: %v", name, err)
+		}
+		if docs, err := s.ListDocuments(&ListOptions{Search: "relies entirely"}); err != nil || len(docs) != 1 {
+			t.Errorf("%s: search found %d, %v", name, len(docs), err)
+		}
+
+		// Back to inline storage: existing text is still read, and compacting
+		// moves it back into storage
+		t.Setenv("ARC_LIBRARY_FULLTEXT", "inline")
+		if got, err := s.GetDocument(doc.ID); err != nil || got.FullText != longText {
+			t.Errorf("%s: external text after switching to inline: %v", name, err)
+		}
+		if stats, err := s.Compact(); err != nil || stats.Documents != 1 || stats.FilesRemoved != 1 {
+			t.Errorf("%s: compact to inline = %+v, %v", name, stats, err)
+		}
+		if got, err := s.GetDocument(doc.ID); err != nil || got.FullText != longText {
+			t.Errorf("%s: full text after moving inline: %v", name, err)
+		}
+
+		// And out again
+		t.Setenv("ARC_LIBRARY_FULLTEXT", "external")
+		if stats, err := s.Compact(); err != nil || stats.Documents != 1 || stats.StoredText > 100 || stats.FilesRemoved != 0 {
+			t.Errorf("%s: compact to external = %+v, %v", name, stats, err)
+		}
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("%s: text not moved to its file: %v", name, err)
+		}
+		if err := s.DeleteDocument(doc.ID); err != nil {
+			t.Fatal(err)
+		}
+		if stats, err := s.Compact(); err != nil || stats.FilesRemoved != 1 {
+			t.Errorf("%s: compact after delete = %+v, %v", name, stats, err)
+		}
+	}
+
+	t.Setenv("ARC_LIBRARY_FULLTEXT", "somewhere")
+	if _, err := ExternalFullText(); err == nil {
+		t.Error("invalid setting: no error")
+	}
+}
+
+func TestFullTextDirPerLibrary(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("ARC_LIBRARY_FULLTEXT_DIR", "")
+	t.Setenv("ARC_LIBRARY_STORAGE", "")
+	t.Setenv("ARC_LIBRARY_DB", filepath.Join(dir, "a.db"))
+	a, err := FullTextDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ARC_LIBRARY_DB", filepath.Join(dir, "b.db"))
+	if b, _ := FullTextDir(); a == b {
+		t.Errorf("two libraries share %s", a)
+	}
+
+	// A directory claimed by library a is not pruned by library b
+	t.Setenv("ARC_LIBRARY_FULLTEXT_DIR", filepath.Join(dir, "shared"))
+	t.Setenv("ARC_LIBRARY_DB", filepath.Join(dir, "a.db"))
+	ref, err := writeFullTextFile("Text of a document in library a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ARC_LIBRARY_DB", filepath.Join(dir, "b.db"))
+	if n, err := pruneFullTextFiles(nil); err == nil || n != 0 {
+		t.Errorf("pruned %d files of another library, %v", n, err)
+	}
+	t.Setenv("ARC_LIBRARY_DB", filepath.Join(dir, "a.db"))
+	if text, err := readFullTextFile(ref); err != nil || text == "" {
+		t.Errorf("text after another library's compact: %q, %v", text, err)
+	}
+}
@@ -0,0 +1,314 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// Node kinds of a library graph.
+const (
+	GraphNodeDocument   = "document"
+	GraphNodeAuthor     = "author"
+	GraphNodeTag        = "tag"
+	GraphNodeCollection = "collection"
+)
+
+// Edge kinds of a library graph besides the link relations, which are
+// used as they are ("cites", "supersedes", ...).
+const (
+	GraphEdgeAuthor     = "author"     // document -> author
+	GraphEdgeTag        = "tag"        // document -> tag
+	GraphEdgeCollection = "collection" // collection -> document
+)
+
+// GraphEdgeKinds lists the edge kinds BuildGraph accepts, in order.
+func GraphEdgeKinds() []string {
+	kinds := []string{GraphEdgeAuthor, GraphEdgeTag, GraphEdgeCollection}
+	for _, r := range LinkRelations {
+		kinds = append(kinds, string(r))
+	}
+	return kinds
+}
+
+// GraphNode is a document, author, tag, or collection. IDs are prefixed
+// with the kind, e.g. "doc:<id>" or "tag:ml".
+type GraphNode struct {
+	ID    string `json:"id"`
+	Kind  string `json:"kind"`
+	Label string `json:"label"`
+	Type  string `json:"type,omitempty"` // document type
+	Year  int    `json:"year,omitempty"`
+}
+
+// GraphEdge is a directed edge between two nodes.
+type GraphEdge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Kind   string `json:"kind"`
+}
+
+// Graph is a library as a graph of documents and what connects them.
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// ParseGraphEdgeKinds validates a list of edge kinds. "links" stands for
+// all link relations; an empty list selects every kind.
+func ParseGraphEdgeKinds(kinds []string) (map[string]bool, error) {
+	all := GraphEdgeKinds()
+	selected := map[string]bool{}
+	if len(kinds) == 0 {
+		kinds = all
+	}
+	for _, k := range kinds {
+		k = strings.ToLower(strings.TrimSpace(k))
+		switch {
+		case k == "links":
+			for _, r := range LinkRelations {
+				selected[string(r)] = true
+			}
+		case slices.Contains(all, k):
+			selected[k] = true
+		default:
+			return nil, fmt.Errorf("unknown edge kind %q (use %s, or links)", k, strings.Join(all, ", "))
+		}
+	}
+	return selected, nil
+}
+
+// BuildGraph builds the graph of docs with the selected edge kinds.
+// Author, tag, and collection nodes are added only for the edges that
+// reach them, and links only between documents in docs.
+func BuildGraph(s LibraryStore, docs []*Document, edges map[string]bool) (*Graph, error) {
+	g := &Graph{Nodes: []GraphNode{}, Edges: []GraphEdge{}}
+	seen := map[string]bool{}
+	addNode := func(n GraphNode) {
+		if !seen[n.ID] {
+			seen[n.ID] = true
+			g.Nodes = append(g.Nodes, n)
+		}
+	}
+
+	inGraph := map[string]bool{}
+	for _, d := range docs {
+		inGraph[d.ID] = true
+		n := GraphNode{ID: "doc:" + d.ID, Kind: GraphNodeDocument, Label: d.Title, Type: string(d.Type)}
+		if n.Label == "" {
+			n.Label = d.Path
+		}
+		if y, ok := d.Meta["year"].(float64); ok {
+			n.Year = int(y)
+		} else if y, ok := d.Meta["year"].(int); ok {
+			n.Year = y
+		}
+		addNode(n)
+	}
+
+	for _, d := range docs {
+		if edges[GraphEdgeAuthor] {
+			for _, a := range d.Authors {
+				if a = strings.TrimSpace(a); a == "" {
+					continue
+				}
+				id := "author:" + strings.ToLower(a)
+				addNode(GraphNode{ID: id, Kind: GraphNodeAuthor, Label: a})
+				g.Edges = append(g.Edges, GraphEdge{Source: "doc:" + d.ID, Target: id, Kind: GraphEdgeAuthor})
+			}
+		}
+		if edges[GraphEdgeTag] {
+			for _, t := range d.Tags {
+				id := "tag:" + t
+				addNode(GraphNode{ID: id, Kind: GraphNodeTag, Label: t})
+				g.Edges = append(g.Edges, GraphEdge{Source: "doc:" + d.ID, Target: id, Kind: GraphEdgeTag})
+			}
+		}
+	}
+
+	if edges[GraphEdgeCollection] {
+		collections, err := s.ListCollections()
+		if err != nil {
+			return nil, fmt.Errorf("list collections: %w", err)
+		}
+		for _, c := range collections {
+			id := "collection:" + c.ID
+			for _, docID := range c.DocumentIDs {
+				if !inGraph[docID] {
+					continue
+				}
+				addNode(GraphNode{ID: id, Kind: GraphNodeCollection, Label: c.Name})
+				g.Edges = append(g.Edges, GraphEdge{Source: id, Target: "doc:" + docID, Kind: GraphEdgeCollection})
+			}
+		}
+	}
+
+	linked := map[string]bool{}
+	for _, d := range docs {
+		links, err := s.ListDocumentLinks(d.ID)
+		if err != nil {
+			return nil, fmt.Errorf("list links: %w", err)
+		}
+		for _, l := range links {
+			if linked[l.ID] || !edges[string(l.Relation)] || !inGraph[l.FromID] || !inGraph[l.ToID] {
+				continue
+			}
+			linked[l.ID] = true
+			g.Edges = append(g.Edges, GraphEdge{Source: "doc:" + l.FromID, Target: "doc:" + l.ToID, Kind: string(l.Relation)})
+		}
+	}
+	return g, nil
+}
+
+// WriteJSON writes the graph as {"nodes": [...], "edges": [...]}.
+func (g *Graph) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(g)
+}
+
+// WriteGraphML writes the graph as GraphML, which Gephi, yEd, and
+// Cytoscape read. Nodes carry label, kind, type, and year attributes and
+// edges their kind.
+func (g *Graph) WriteGraphML(w io.Writer) error {
+	type data struct {
+		Key   string `xml:"key,attr"`
+		Value string `xml:",chardata"`
+	}
+	type node struct {
+		ID   string `xml:"id,attr"`
+		Data []data `xml:"data"`
+	}
+	type edge struct {
+		Source string `xml:"source,attr"`
+		Target string `xml:"target,attr"`
+		Data   []data `xml:"data"`
+	}
+	type key struct {
+		ID       string `xml:"id,attr"`
+		For      string `xml:"for,attr"`
+		AttrName string `xml:"attr.name,attr"`
+		AttrType string `xml:"attr.type,attr"`
+	}
+	type graph struct {
+		EdgeDefault string `xml:"edgedefault,attr"`
+		Nodes       []node `xml:"node"`
+		Edges       []edge `xml:"edge"`
+	}
+	type graphml struct {
+		XMLName xml.Name `xml:"graphml"`
+		XMLNS   string   `xml:"xmlns,attr"`
+		Keys    []key    `xml:"key"`
+		Graph   graph    `xml:"graph"`
+	}
+
+	doc := graphml{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []key{
+			{ID: "label", For: "node", AttrName: "label", AttrType: "string"},
+			{ID: "kind", For: "node", AttrName: "kind", AttrType: "string"},
+			{ID: "type", For: "node", AttrName: "type", AttrType: "string"},
+			{ID: "year", For: "node", AttrName: "year", AttrType: "int"},
+			{ID: "edgekind", For: "edge", AttrName: "kind", AttrType: "string"},
+		},
+		Graph: graph{EdgeDefault: "directed"},
+	}
+	for _, n := range g.Nodes {
+		gn := node{ID: n.ID, Data: []data{{"label", n.Label}, {"kind", n.Kind}}}
+		if n.Type != "" {
+			gn.Data = append(gn.Data, data{"type", n.Type})
+		}
+		if n.Year > 0 {
+			gn.Data = append(gn.Data, data{"year", fmt.Sprint(n.Year)})
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, gn)
+	}
+	for _, e := range g.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, edge{Source: e.Source, Target: e.Target, Data: []data{{"edgekind", e.Kind}}})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// graphDOTShapes draws each node kind in its own shape.
+var graphDOTShapes = map[string]string{
+	GraphNodeDocument:   "box",
+	GraphNodeAuthor:     "ellipse",
+	GraphNodeTag:        "note",
+	GraphNodeCollection: "folder",
+}
+
+// WriteDOT writes the graph in Graphviz DOT.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph library {\n")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(&b, "  %s [label=%s, shape=%s];\n", dotQuote(n.ID), dotQuote(n.Label), graphDOTShapes[n.Kind])
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "  %s -> %s [label=%s];\n", dotQuote(e.Source), dotQuote(e.Target), dotQuote(e.Kind))
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// dotQuote quotes s as a DOT string.
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+func init() {
+	RegisterExporter(graphExporter{"graphml", "GraphML for Gephi, yEd, and Cytoscape", ".graphml", (*Graph).WriteGraphML})
+	RegisterExporter(graphExporter{"dot", "Graphviz DOT", ".dot", (*Graph).WriteDOT})
+	RegisterExporter(graphExporter{"json-graph", `{"nodes", "edges"} JSON for custom graph tools`, ".json", (*Graph).WriteJSON})
+}
+
+// graphExporter writes the documents with their authors, tags,
+// collections, and links as a graph.
+type graphExporter struct {
+	name, description, ext string
+	write                  func(*Graph, io.Writer) error
+}
+
+func (e graphExporter) Format() Format {
+	return Format{
+		Name:         e.name,
+		Description:  e.description,
+		Extensions:   []string{e.ext},
+		Capabilities: []string{FormatTags, FormatGraph},
+	}
+}
+
+func (e graphExporter) Export(w io.Writer, s LibraryStore, docs []*Document, opts ExportOptions) error {
+	edges := opts.Edges
+	if edges == nil {
+		var err error
+		if edges, err = ParseGraphEdgeKinds(nil); err != nil {
+			return err
+		}
+	}
+	g, err := BuildGraph(s, docs, edges)
+	if err != nil {
+		return err
+	}
+	return e.write(g, w)
+}
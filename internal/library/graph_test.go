@@ -0,0 +1,113 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+func TestBuildGraph(t *testing.T) {
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &Document{Title: "Attention Is All You Need", Type: DocTypePaper, Authors: []string{"Ashish Vaswani", "Noam Shazeer"}, Tags: []string{"ml"}, Meta: JSONMap{"year": 2017}}
+	b := &Document{Title: "Layer \"Norm\"", Type: DocTypePaper, Authors: []string{"ashish vaswani"}, Tags: []string{"ml"}}
+	c := &Document{Title: "Unrelated", Type: DocTypeBook}
+	for _, d := range []*Document{a, b, c} {
+		if err := s.AddDocument(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	coll, err := s.CreateCollection("reading", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.AddToCollection(coll.ID, a.ID)
+	s.AddToCollection(coll.ID, c.ID)
+	for _, l := range []*DocumentLink{
+		{FromID: a.ID, ToID: b.ID, Relation: LinkCites},
+		{FromID: a.ID, ToID: c.ID, Relation: LinkSupersedes},
+	} {
+		if err := s.AddDocumentLink(l); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	edges, err := ParseGraphEdgeKinds(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := BuildGraph(s, []*Document{a, b}, edges)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kinds := map[string]int{}
+	for _, n := range g.Nodes {
+		kinds[n.Kind]++
+	}
+	// Authors are merged case-insensitively; c is outside the graph
+	if kinds[GraphNodeDocument] != 2 || kinds[GraphNodeAuthor] != 2 || kinds[GraphNodeTag] != 1 || kinds[GraphNodeCollection] != 1 {
+		t.Errorf("node kinds = %v", kinds)
+	}
+	var got []string
+	for _, e := range g.Edges {
+		got = append(got, e.Kind)
+	}
+	if strings.Join(got, ",") != "author,author,tag,author,tag,collection,cites" {
+		t.Errorf("edge kinds = %v", got)
+	}
+	if g.Nodes[0].Year != 2017 {
+		t.Errorf("year = %d", g.Nodes[0].Year)
+	}
+
+	edges, err = ParseGraphEdgeKinds([]string{"links"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err = BuildGraph(s, []*Document{a, b, c}, edges)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Nodes) != 3 || len(g.Edges) != 2 {
+		t.Errorf("links only: %d nodes, %d edges", len(g.Nodes), len(g.Edges))
+	}
+	if _, err := ParseGraphEdgeKinds([]string{"friends"}); err == nil {
+		t.Error("expected an error for an unknown edge kind")
+	}
+
+	var buf bytes.Buffer
+	if err := g.WriteGraphML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var parsed struct {
+		Nodes []struct {
+			ID string `xml:"id,attr"`
+		} `xml:"graph>node"`
+		Edges []struct {
+			Source string `xml:"source,attr"`
+		} `xml:"graph>edge"`
+	}
+	if err := xml.Unmarshal(buf.Bytes(), &parsed); err != nil {
+		t.Fatalf("GraphML does not parse: %v\n%s", err, buf.String())
+	}
+	if len(parsed.Nodes) != 3 || len(parsed.Edges) != 2 {
+		t.Errorf("GraphML has %d nodes, %d edges", len(parsed.Nodes), len(parsed.Edges))
+	}
+
+	buf.Reset()
+	if err := g.WriteDOT(&buf); err != nil {
+		t.Fatal(err)
+	}
+	dot := buf.String()
+	if !strings.HasPrefix(dot, "digraph library {") || !strings.Contains(dot, `label="Layer \"Norm\""`) ||
+		!strings.Contains(dot, `"doc:`+a.ID+`" -> "doc:`+b.ID+`" [label="cites"]`) {
+		t.Errorf("DOT =\n%s", dot)
+	}
+}
@@ -0,0 +1,1299 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GraphQL serves read-only queries over the library: documents with their
+// annotations, collections, flashcards, and reading sessions, and the
+// collections, flashcards, and tags themselves. It implements the query
+// language (fields, aliases, arguments, variables, fragments, and the
+// @include and @skip directives) but not mutations, subscriptions, or
+// introspection beyond __typename; GraphQLSchema describes the types.
+
+// GraphQLRequest is a GraphQL request as POSTed by clients.
+type GraphQLRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// GraphQLResponse is a GraphQL result: the data, and the errors met
+// producing it. Fields that failed are null in the data.
+type GraphQLResponse struct {
+	Data   any            `json:"data"`
+	Errors []GraphQLError `json:"errors,omitempty"`
+}
+
+// GraphQLError is an error in a GraphQL response; Path locates the field
+// it nulled.
+type GraphQLError struct {
+	Message string `json:"message"`
+	Path    []any  `json:"path,omitempty"`
+}
+
+// Limits on queries, checked when they are parsed so that no query costs
+// much to reject. gqlMaxDepth bounds how deeply fields with selections
+// nest, so a query cannot walk document -> collections -> documents -> ...
+// indefinitely; gqlMaxFields bounds the fields a query selects once its
+// fragments are expanded, so fragments spread side by side cannot
+// multiply them. gqlMaxNesting bounds how deeply braces and brackets nest
+// while parsing, and gqlMaxQuerySize the query's length in bytes.
+const (
+	gqlMaxDepth     = 12
+	gqlMaxFields    = 1000
+	gqlMaxNesting   = 32
+	gqlMaxQuerySize = 64 << 10
+)
+
+// ExecuteGraphQL runs a query against s. A request that cannot be parsed
+// or names no runnable operation gets only errors and null data.
+func ExecuteGraphQL(s LibraryStore, req *GraphQLRequest) *GraphQLResponse {
+	doc, err := parseGraphQL(req.Query)
+	if err != nil {
+		return &GraphQLResponse{Errors: []GraphQLError{{Message: err.Error()}}}
+	}
+	op, err := doc.operation(req.OperationName)
+	if err != nil {
+		return &GraphQLResponse{Errors: []GraphQLError{{Message: err.Error()}}}
+	}
+	vars, err := op.variables(req.Variables)
+	if err != nil {
+		return &GraphQLResponse{Errors: []GraphQLError{{Message: err.Error()}}}
+	}
+
+	e := &gqlExec{s: s, doc: doc, vars: vars}
+	data := e.selectObject(gqlQuery, nil, op.selections, nil, 0)
+	return &GraphQLResponse{Data: data, Errors: e.errors}
+}
+
+// Schema
+
+// gqlField is a field of an object type.
+type gqlField struct {
+	typ     string   // its type, in schema notation
+	args    []string // "name: Type"
+	desc    string
+	resolve func(e *gqlExec, parent any, args map[string]any) (any, error)
+}
+
+// gqlType is an object type. Fields are the exported, JSON-tagged fields
+// of its Go struct, camelCased (source_id is sourceId), plus relations.
+type gqlType struct {
+	name   string
+	desc   string
+	fields map[string]*gqlField
+}
+
+// graphQLTag is a tag and how many documents carry it.
+type graphQLTag struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+var (
+	gqlQuery = &gqlType{name: "Query", desc: "The entry points of every query.", fields: map[string]*gqlField{}}
+	gqlTypes = map[reflect.Type]*gqlType{} // struct type -> object type
+)
+
+// gqlObject registers the object type for a struct, with its JSON fields
+// and the given relations.
+func gqlObject(name, desc string, sample any, relations map[string]*gqlField) *gqlType {
+	t := &gqlType{name: name, desc: desc, fields: map[string]*gqlField{}}
+	rt := reflect.TypeOf(sample)
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		tag, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if !sf.IsExported() || tag == "" || tag == "-" {
+			continue
+		}
+		index := sf.Index
+		t.fields[gqlName(tag)] = &gqlField{
+			typ: gqlScalarType(tag, sf.Type),
+			resolve: func(e *gqlExec, parent any, args map[string]any) (any, error) {
+				return reflect.ValueOf(parent).Elem().FieldByIndex(index).Interface(), nil
+			},
+		}
+	}
+	for n, f := range relations {
+		t.fields[n] = f
+	}
+	gqlTypes[reflect.PointerTo(rt)] = t
+	return t
+}
+
+// gqlName camelCases a JSON field name.
+func gqlName(jsonName string) string {
+	parts := strings.Split(jsonName, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// gqlScalarType names the schema type of a struct field.
+func gqlScalarType(name string, t reflect.Type) string {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case name == "id":
+		return "ID!"
+	case t == reflect.TypeOf(time.Time{}):
+		return "Time"
+	case t.Kind() == reflect.String:
+		return "String"
+	case t.Kind() == reflect.Int:
+		return "Int"
+	case t.Kind() == reflect.Float64:
+		return "Float"
+	case t.Kind() == reflect.Bool:
+		return "Boolean"
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.String:
+		return "[String!]"
+	}
+	return "JSON"
+}
+
+func init() {
+	doc := func(f func(e *gqlExec, id string) (any, error)) func(e *gqlExec, parent any, args map[string]any) (any, error) {
+		return func(e *gqlExec, parent any, args map[string]any) (any, error) {
+			return f(e, reflect.ValueOf(parent).Elem().FieldByName("DocumentID").String())
+		}
+	}
+	document := &gqlField{typ: "Document", desc: "The document it belongs to.", resolve: doc(func(e *gqlExec, id string) (any, error) {
+		return e.s.GetDocument(id)
+	})}
+
+	gqlObject("Annotation", "A highlight, note, or bookmark.", Annotation{}, map[string]*gqlField{"document": document})
+	gqlObject("ReadingSession", "Time spent reading a document.", ReadingSession{}, map[string]*gqlField{"document": document})
+	gqlObject("FlashcardReview", "One review of a flashcard.", FlashcardReview{}, nil)
+	gqlObject("Flashcard", "A spaced repetition card.", Flashcard{}, map[string]*gqlField{
+		"document": document,
+		"reviews": {typ: "[FlashcardReview!]!", desc: "Its reviews, oldest first.", resolve: func(e *gqlExec, parent any, args map[string]any) (any, error) {
+			return e.s.ListFlashcardReviews(parent.(*Flashcard).ID)
+		}},
+	})
+	gqlObject("Tag", "A tag and how many documents carry it.", graphQLTag{}, nil)
+	gqlObject("Collection", "A named group of documents.", Collection{}, map[string]*gqlField{
+		"documents": {typ: "[Document!]!", args: []string{"limit: Int"}, desc: "Its documents, in collection order.", resolve: func(e *gqlExec, parent any, args map[string]any) (any, error) {
+			limit, err := gqlInt(args, "limit")
+			if err != nil {
+				return nil, err
+			}
+			return e.documents(parent.(*Collection).DocumentIDs, limit)
+		}},
+	})
+	gqlObject("Document", "A paper, book, article, or other document.", Document{}, map[string]*gqlField{
+		"annotations": {typ: "[Annotation!]!", resolve: func(e *gqlExec, parent any, args map[string]any) (any, error) {
+			return e.s.GetAnnotations(parent.(*Document).ID)
+		}},
+		"collections": {typ: "[Collection!]!", desc: "The collections it is in.", resolve: func(e *gqlExec, parent any, args map[string]any) (any, error) {
+			cols, err := e.allCollections()
+			var in []*Collection
+			for _, c := range cols {
+				if slices.Contains(c.DocumentIDs, parent.(*Document).ID) {
+					in = append(in, c)
+				}
+			}
+			return in, err
+		}},
+		"flashcards": {typ: "[Flashcard!]!", args: []string{"due: Boolean"}, resolve: func(e *gqlExec, parent any, args map[string]any) (any, error) {
+			due, err := gqlBool(args, "due")
+			if err != nil {
+				return nil, err
+			}
+			return e.s.ListFlashcards(&FlashcardListOptions{DocumentID: parent.(*Document).ID, Due: due})
+		}},
+		"sessions": {typ: "[ReadingSession!]!", desc: "Its reading sessions.", resolve: func(e *gqlExec, parent any, args map[string]any) (any, error) {
+			return e.s.ListSessions(parent.(*Document).ID)
+		}},
+	})
+
+	gqlQuery.fields = map[string]*gqlField{
+		"documents": {
+			typ:  "[Document!]!",
+			args: []string{"tag: String", "source: String", "type: String", "status: String", "author: String", "search: String", "language: String", "minRating: Int", "collection: String", "limit: Int"},
+			desc: "Documents matching every filter given, as \"list\" filters them; collection is an ID or name.",
+			resolve: func(e *gqlExec, parent any, args map[string]any) (any, error) {
+				opts := &ListOptions{}
+				for name, dst := range map[string]*string{
+					"tag": &opts.Tag, "source": &opts.Source, "type": &opts.Type, "status": &opts.Status,
+					"author": &opts.Author, "search": &opts.Search, "language": &opts.Language,
+				} {
+					v, err := gqlString(args, name)
+					if err != nil {
+						return nil, err
+					}
+					*dst = v
+				}
+				var err error
+				if opts.MinRating, err = gqlInt(args, "minRating"); err != nil {
+					return nil, err
+				}
+				limit, err := gqlInt(args, "limit")
+				if err != nil {
+					return nil, err
+				}
+				collection, err := gqlString(args, "collection")
+				if err != nil {
+					return nil, err
+				}
+				if collection == "" {
+					opts.Limit = limit
+					return e.s.ListDocuments(opts)
+				}
+				c, err := e.s.GetCollection(collection)
+				if err != nil {
+					return nil, err
+				}
+				if c == nil {
+					return nil, fmt.Errorf("%w: %s", ErrCollectionNotFound, collection)
+				}
+				docs, err := e.s.ListDocuments(opts)
+				if err != nil {
+					return nil, err
+				}
+				docs = slices.DeleteFunc(docs, func(d *Document) bool { return !slices.Contains(c.DocumentIDs, d.ID) })
+				if limit > 0 && len(docs) > limit {
+					docs = docs[:limit]
+				}
+				return docs, nil
+			},
+		},
+		"document": {typ: "Document", args: []string{"id: ID!"}, resolve: func(e *gqlExec, parent any, args map[string]any) (any, error) {
+			id, err := gqlString(args, "id")
+			if err != nil || id == "" {
+				return nil, fmt.Errorf("id is required")
+			}
+			return e.s.GetDocument(id)
+		}},
+		"collections": {typ: "[Collection!]!", resolve: func(e *gqlExec, parent any, args map[string]any) (any, error) {
+			return e.allCollections()
+		}},
+		"collection": {typ: "Collection", args: []string{"id: ID!"}, desc: "A collection by ID or name.", resolve: func(e *gqlExec, parent any, args map[string]any) (any, error) {
+			id, err := gqlString(args, "id")
+			if err != nil || id == "" {
+				return nil, fmt.Errorf("id is required")
+			}
+			return e.s.GetCollection(id)
+		}},
+		"flashcards": {typ: "[Flashcard!]!", args: []string{"tag: String", "due: Boolean", "limit: Int"}, resolve: func(e *gqlExec, parent any, args map[string]any) (any, error) {
+			opts := &FlashcardListOptions{}
+			var err error
+			if opts.Tag, err = gqlString(args, "tag"); err != nil {
+				return nil, err
+			}
+			if opts.Due, err = gqlBool(args, "due"); err != nil {
+				return nil, err
+			}
+			if opts.Limit, err = gqlInt(args, "limit"); err != nil {
+				return nil, err
+			}
+			return e.s.ListFlashcards(opts)
+		}},
+		"tags": {typ: "[Tag!]!", desc: "Every tag, most used first.", resolve: func(e *gqlExec, parent any, args map[string]any) (any, error) {
+			counts, err := e.s.ListTags()
+			if err != nil {
+				return nil, err
+			}
+			tags := make([]*graphQLTag, 0, len(counts))
+			for name, n := range counts {
+				tags = append(tags, &graphQLTag{Name: name, Count: n})
+			}
+			sort.Slice(tags, func(i, j int) bool {
+				if tags[i].Count != tags[j].Count {
+					return tags[i].Count > tags[j].Count
+				}
+				return tags[i].Name < tags[j].Name
+			})
+			return tags, nil
+		}},
+	}
+}
+
+// GraphQLSchema returns the schema in the GraphQL schema language.
+func GraphQLSchema() string {
+	var b strings.Builder
+	b.WriteString("# Time is an RFC 3339 string; JSON is any JSON value.\nscalar Time\nscalar JSON\n")
+	types := []*gqlType{gqlQuery}
+	for _, t := range gqlTypes {
+		types = append(types, t)
+	}
+	sort.SliceStable(types[1:], func(i, j int) bool { return types[i+1].name < types[j+1].name })
+	for _, t := range types {
+		fmt.Fprintf(&b, "\n# %s\ntype %s {\n", t.desc, t.name)
+		names := make([]string, 0, len(t.fields))
+		for n := range t.fields {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		for _, n := range names {
+			f := t.fields[n]
+			if f.desc != "" {
+				fmt.Fprintf(&b, "  # %s\n", f.desc)
+			}
+			args := ""
+			if len(f.args) > 0 {
+				args = "(" + strings.Join(f.args, ", ") + ")"
+			}
+			fmt.Fprintf(&b, "  %s%s: %s\n", n, args, f.typ)
+		}
+		b.WriteString("}\n")
+	}
+	return b.String()
+}
+
+// Arguments
+
+func gqlString(args map[string]any, name string) (string, error) {
+	switch v := args[name].(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	default:
+		return "", fmt.Errorf("argument %s: expected a string", name)
+	}
+}
+
+func gqlInt(args map[string]any, name string) (int, error) {
+	switch v := args[name].(type) {
+	case nil:
+		return 0, nil
+	case int:
+		return v, nil
+	case float64: // from JSON variables
+		if v == float64(int(v)) {
+			return int(v), nil
+		}
+	}
+	return 0, fmt.Errorf("argument %s: expected an integer", name)
+}
+
+func gqlBool(args map[string]any, name string) (bool, error) {
+	switch v := args[name].(type) {
+	case nil:
+		return false, nil
+	case bool:
+		return v, nil
+	default:
+		return false, fmt.Errorf("argument %s: expected a boolean", name)
+	}
+}
+
+// Execution
+
+type gqlExec struct {
+	s           LibraryStore
+	doc         *gqlDocument
+	vars        map[string]any
+	errors      []GraphQLError
+	collections []*Collection // loaded once per request
+	loaded      bool
+}
+
+func (e *gqlExec) allCollections() ([]*Collection, error) {
+	if !e.loaded {
+		cols, err := e.s.ListCollections()
+		if err != nil {
+			return nil, err
+		}
+		e.collections, e.loaded = cols, true
+	}
+	return e.collections, nil
+}
+
+// documents loads documents by ID, skipping any since deleted.
+func (e *gqlExec) documents(ids []string, limit int) ([]*Document, error) {
+	var docs []*Document
+	for _, id := range ids {
+		if limit > 0 && len(docs) == limit {
+			break
+		}
+		d, err := e.s.GetDocument(id)
+		if err != nil {
+			return nil, err
+		}
+		if d != nil {
+			docs = append(docs, d)
+		}
+	}
+	return docs, nil
+}
+
+func (e *gqlExec) fail(path []any, format string, args ...any) {
+	e.errors = append(e.errors, GraphQLError{Message: fmt.Sprintf(format, args...), Path: slices.Clone(path)})
+}
+
+// gqlResult is a JSON object that keeps its fields in query order.
+type gqlResult struct {
+	keys   []string
+	values map[string]any
+}
+
+func (r *gqlResult) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, k := range r.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(k)
+		val, err := json.Marshal(r.values[k])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(val)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// selectObject resolves the selections on v, an object of type t.
+func (e *gqlExec) selectObject(t *gqlType, v any, sels []*gqlSelection, path []any, depth int) *gqlResult {
+	res := &gqlResult{values: map[string]any{}}
+	fields := map[string][]*gqlSelection{}
+	e.collectFields(t, sels, res, fields, map[string]bool{}, path)
+	for _, key := range res.keys {
+		group := fields[key]
+		sel := group[0]
+		fieldPath := append(slices.Clone(path), key)
+		if sel.name == "__typename" {
+			res.values[key] = t.name
+			continue
+		}
+		f := t.fields[sel.name]
+		if f == nil {
+			e.fail(fieldPath, "cannot query field %q on type %s", sel.name, t.name)
+			continue
+		}
+		args, err := e.arguments(f, sel)
+		if err != nil {
+			e.fail(fieldPath, "%v", err)
+			continue
+		}
+		value, err := f.resolve(e, v, args)
+		if err != nil {
+			e.fail(fieldPath, "%v", err)
+			continue
+		}
+		var sub []*gqlSelection
+		for _, s := range group {
+			sub = append(sub, s.selections...)
+		}
+		res.values[key] = e.complete(value, sel.name, sub, fieldPath, depth+1)
+	}
+	return res
+}
+
+// collectFields gathers the fields selected on t, expanding fragments and
+// applying directives, grouped by response key in order.
+func (e *gqlExec) collectFields(t *gqlType, sels []*gqlSelection, res *gqlResult, fields map[string][]*gqlSelection, visited map[string]bool, path []any) {
+	for _, sel := range sels {
+		if !e.included(sel, path) {
+			continue
+		}
+		switch {
+		case sel.spread != "":
+			frag := e.doc.fragments[sel.spread]
+			if frag == nil {
+				e.fail(path, "unknown fragment %q", sel.spread)
+				continue
+			}
+			if visited[sel.spread] || (frag.typeCond != "" && frag.typeCond != t.name) {
+				continue
+			}
+			visited[sel.spread] = true
+			e.collectFields(t, frag.selections, res, fields, visited, path)
+		case sel.inline:
+			if sel.typeCond == "" || sel.typeCond == t.name {
+				e.collectFields(t, sel.selections, res, fields, visited, path)
+			}
+		default:
+			key := sel.alias
+			if key == "" {
+				key = sel.name
+			}
+			if _, ok := fields[key]; !ok {
+				res.keys = append(res.keys, key)
+			}
+			fields[key] = append(fields[key], sel)
+		}
+	}
+}
+
+// included applies @include(if:) and @skip(if:).
+func (e *gqlExec) included(sel *gqlSelection, path []any) bool {
+	for _, d := range sel.directives {
+		if d.name != "include" && d.name != "skip" {
+			e.fail(path, "unknown directive @%s", d.name)
+			return false
+		}
+		cond, ok := e.resolveValue(d.args["if"]).(bool)
+		if !ok {
+			e.fail(path, "@%s needs a boolean if argument", d.name)
+			return false
+		}
+		if cond == (d.name == "skip") {
+			return false
+		}
+	}
+	return true
+}
+
+// arguments resolves a field's arguments, rejecting unknown ones.
+func (e *gqlExec) arguments(f *gqlField, sel *gqlSelection) (map[string]any, error) {
+	args := map[string]any{}
+	for name, v := range sel.args {
+		known := slices.ContainsFunc(f.args, func(a string) bool { return strings.HasPrefix(a, name+":") })
+		if !known {
+			return nil, fmt.Errorf("unknown argument %q on field %q", name, sel.name)
+		}
+		args[name] = e.resolveValue(v)
+	}
+	return args, nil
+}
+
+// resolveValue replaces variables in an argument value.
+func (e *gqlExec) resolveValue(v any) any {
+	switch v := v.(type) {
+	case gqlVariable:
+		return e.vars[string(v)]
+	case gqlEnum:
+		return string(v)
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = e.resolveValue(item)
+		}
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, item := range v {
+			out[k] = e.resolveValue(item)
+		}
+		return out
+	}
+	return v
+}
+
+// complete turns a resolved value into its response: objects and lists of
+// them by their selections, and scalars as JSON.
+func (e *gqlExec) complete(v any, name string, sels []*gqlSelection, path []any, depth int) any {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || ((rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Map) && rv.IsNil()) {
+		return nil
+	}
+	if t := gqlTypes[rv.Type()]; t != nil {
+		if len(sels) == 0 {
+			e.fail(path, "field %q of type %s needs a selection of subfields", name, t.name)
+			return nil
+		}
+		if depth > gqlMaxDepth {
+			e.fail(path, "query nests deeper than %d levels", gqlMaxDepth)
+			return nil
+		}
+		return e.selectObject(t, v, sels, path, depth)
+	}
+	if rv.Kind() == reflect.Slice && gqlTypes[rv.Type().Elem()] != nil {
+		items := make([]any, rv.Len())
+		for i := range items {
+			items[i] = e.complete(rv.Index(i).Interface(), name, sels, append(slices.Clone(path), i), depth)
+		}
+		return items
+	}
+	if len(sels) > 0 {
+		e.fail(path, "field %q is a scalar and has no subfields", name)
+		return nil
+	}
+	switch v := v.(type) {
+	case time.Time:
+		if v.IsZero() {
+			return nil
+		}
+		return v.Format(time.RFC3339)
+	case *time.Time:
+		return e.complete(*v, name, nil, path, depth)
+	}
+	return v
+}
+
+// Parsing
+
+type gqlDocument struct {
+	operations []*gqlOperation
+	fragments  map[string]*gqlFragment
+}
+
+type gqlOperation struct {
+	kind, name string
+	vars       []gqlVarDef
+	selections []*gqlSelection
+}
+
+type gqlVarDef struct {
+	name     string
+	required bool
+	def      any
+}
+
+type gqlFragment struct {
+	typeCond   string
+	selections []*gqlSelection
+}
+
+type gqlSelection struct {
+	alias, name string
+	args        map[string]any
+	directives  []gqlDirective
+	selections  []*gqlSelection
+	spread      string // a named fragment spread
+	inline      bool   // an inline fragment
+	typeCond    string
+}
+
+type gqlDirective struct {
+	name string
+	args map[string]any
+}
+
+// gqlVariable is a $variable in an argument; gqlEnum an enum value.
+type (
+	gqlVariable string
+	gqlEnum     string
+)
+
+// operation picks the operation to run.
+func (d *gqlDocument) operation(name string) (*gqlOperation, error) {
+	var op *gqlOperation
+	switch {
+	case name != "":
+		for _, o := range d.operations {
+			if o.name == name {
+				op = o
+			}
+		}
+		if op == nil {
+			return nil, fmt.Errorf("unknown operation %q", name)
+		}
+	case len(d.operations) == 1:
+		op = d.operations[0]
+	case len(d.operations) == 0:
+		return nil, fmt.Errorf("no operation in the query")
+	default:
+		return nil, fmt.Errorf("the query has several operations; set operationName")
+	}
+	if op.kind != "query" {
+		return nil, fmt.Errorf("only queries are supported, not %ss", op.kind)
+	}
+	return op, nil
+}
+
+// variables checks the request's variables against the operation's
+// definitions, filling in defaults.
+func (o *gqlOperation) variables(given map[string]any) (map[string]any, error) {
+	vars := map[string]any{}
+	for _, def := range o.vars {
+		v, ok := given[def.name]
+		if !ok {
+			v = def.def
+		}
+		if v == nil && def.required {
+			return nil, fmt.Errorf("variable $%s is required", def.name)
+		}
+		vars[def.name] = v
+	}
+	return vars, nil
+}
+
+type gqlToken struct {
+	kind  byte // 'n' name, 'i' int, 'f' float, 's' string, 'p' punctuator, 0 end
+	value string
+	pos   int
+}
+
+type gqlParser struct {
+	src     string
+	pos     int
+	tok     gqlToken
+	vars    map[string]bool // variables the current operation defines
+	nesting int             // selection sets, lists, and objects open
+}
+
+// parseGraphQL parses a query document, rejecting one beyond the limits
+// on queries.
+func parseGraphQL(src string) (*gqlDocument, error) {
+	if len(src) > gqlMaxQuerySize {
+		return nil, fmt.Errorf("query is longer than %d bytes", gqlMaxQuerySize)
+	}
+	p := &gqlParser{src: src}
+	doc := &gqlDocument{fragments: map[string]*gqlFragment{}}
+	err := p.next()
+	for err == nil && p.tok.kind != 0 {
+		switch {
+		case p.tok.kind == 'p' && p.tok.value == "{":
+			var sels []*gqlSelection
+			if sels, err = p.selectionSet(); err == nil {
+				doc.operations = append(doc.operations, &gqlOperation{kind: "query", selections: sels})
+			}
+		case p.tok.kind == 'n' && p.tok.value == "fragment":
+			err = p.fragment(doc)
+		case p.tok.kind == 'n' && (p.tok.value == "query" || p.tok.value == "mutation" || p.tok.value == "subscription"):
+			var op *gqlOperation
+			if op, err = p.operation(); err == nil {
+				doc.operations = append(doc.operations, op)
+			}
+		default:
+			err = p.unexpected()
+		}
+	}
+	if err == nil {
+		err = doc.checkSize()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// gqlSize is how deeply a selection set's fields nest and how many there
+// are, with fragments expanded.
+type gqlSize struct{ depth, fields int }
+
+// checkSize checks every operation and fragment against gqlMaxDepth and
+// gqlMaxFields, and that no fragment spreads itself. Fragments are
+// measured once, so this takes time in the length of the query rather
+// than of its expansion.
+func (d *gqlDocument) checkSize() error {
+	sizes := map[string]gqlSize{}
+	measuring := map[string]bool{}
+	var measure func(sels []*gqlSelection) (gqlSize, error)
+	measure = func(sels []*gqlSelection) (gqlSize, error) {
+		var size gqlSize
+		for _, sel := range sels {
+			var sub gqlSize
+			var err error
+			switch {
+			case sel.spread != "":
+				frag := d.fragments[sel.spread]
+				if frag == nil {
+					// Reported when the query runs
+					continue
+				}
+				if measuring[sel.spread] {
+					return size, fmt.Errorf("fragment %q spreads itself", sel.spread)
+				}
+				var ok bool
+				if sub, ok = sizes[sel.spread]; !ok {
+					measuring[sel.spread] = true
+					sub, err = measure(frag.selections)
+					delete(measuring, sel.spread)
+					sizes[sel.spread] = sub
+				}
+			case sel.inline:
+				sub, err = measure(sel.selections)
+			default:
+				sub, err = measure(sel.selections)
+				sub.fields++
+				if sel.selections != nil {
+					sub.depth++
+				}
+			}
+			if err != nil {
+				return size, err
+			}
+			size.depth = max(size.depth, sub.depth)
+			size.fields += sub.fields
+			if size.depth > gqlMaxDepth {
+				return size, fmt.Errorf("query nests deeper than %d levels", gqlMaxDepth)
+			}
+			if size.fields > gqlMaxFields {
+				return size, fmt.Errorf("query selects more than %d fields", gqlMaxFields)
+			}
+		}
+		return size, nil
+	}
+	for _, op := range d.operations {
+		if _, err := measure(op.selections); err != nil {
+			return err
+		}
+	}
+	for _, frag := range d.fragments {
+		if _, err := measure(frag.selections); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nest opens a selection set, list, or object; the returned func closes
+// it.
+func (p *gqlParser) nest() (func(), error) {
+	if p.nesting >= gqlMaxNesting {
+		return nil, fmt.Errorf("query nests deeper than %d levels at offset %d", gqlMaxNesting, p.tok.pos)
+	}
+	p.nesting++
+	return func() { p.nesting-- }, nil
+}
+
+func (p *gqlParser) operation() (*gqlOperation, error) {
+	op := &gqlOperation{kind: p.tok.value}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind == 'n' {
+		op.name = p.tok.value
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+	}
+	p.vars = map[string]bool{}
+	if p.is("(") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		for !p.is(")") {
+			if err := p.expect("$"); err != nil {
+				return nil, err
+			}
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			typ, err := p.typeRef()
+			if err != nil {
+				return nil, err
+			}
+			def := gqlVarDef{name: name, required: strings.HasSuffix(typ, "!")}
+			if p.is("=") {
+				if err := p.next(); err != nil {
+					return nil, err
+				}
+				if def.def, err = p.value(true); err != nil {
+					return nil, err
+				}
+			}
+			op.vars = append(op.vars, def)
+			p.vars[name] = true
+		}
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := p.directives(); err != nil {
+		return nil, err
+	}
+	sels, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	op.selections = sels
+	p.vars = nil
+	return op, nil
+}
+
+func (p *gqlParser) fragment(doc *gqlDocument) error {
+	if err := p.next(); err != nil {
+		return err
+	}
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+	if p.tok.kind != 'n' || p.tok.value != "on" {
+		return p.unexpected()
+	}
+	if err := p.next(); err != nil {
+		return err
+	}
+	typeCond, err := p.name()
+	if err != nil {
+		return err
+	}
+	if _, err := p.directives(); err != nil {
+		return err
+	}
+	sels, err := p.selectionSet()
+	if err != nil {
+		return err
+	}
+	if _, dup := doc.fragments[name]; dup {
+		return fmt.Errorf("fragment %q defined twice", name)
+	}
+	doc.fragments[name] = &gqlFragment{typeCond: typeCond, selections: sels}
+	return nil
+}
+
+// typeRef reads a variable's type, returned as written.
+func (p *gqlParser) typeRef() (string, error) {
+	var typ string
+	if p.is("[") {
+		if err := p.next(); err != nil {
+			return "", err
+		}
+		inner, err := p.typeRef()
+		if err != nil {
+			return "", err
+		}
+		if err := p.expect("]"); err != nil {
+			return "", err
+		}
+		typ = "[" + inner + "]"
+	} else {
+		name, err := p.name()
+		if err != nil {
+			return "", err
+		}
+		typ = name
+	}
+	if p.is("!") {
+		typ += "!"
+		if err := p.next(); err != nil {
+			return "", err
+		}
+	}
+	return typ, nil
+}
+
+func (p *gqlParser) selectionSet() ([]*gqlSelection, error) {
+	done, err := p.nest()
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var sels []*gqlSelection
+	for !p.is("}") {
+		sel, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		sels = append(sels, sel)
+	}
+	if len(sels) == 0 {
+		return nil, fmt.Errorf("empty selection at offset %d", p.tok.pos)
+	}
+	return sels, p.next()
+}
+
+func (p *gqlParser) selection() (*gqlSelection, error) {
+	sel := &gqlSelection{}
+	var err error
+	if p.is("...") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		if p.tok.kind == 'n' && p.tok.value != "on" {
+			sel.spread = p.tok.value
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			sel.directives, err = p.directives()
+			return sel, err
+		}
+		sel.inline = true
+		if p.tok.kind == 'n' {
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			if sel.typeCond, err = p.name(); err != nil {
+				return nil, err
+			}
+		}
+		if sel.directives, err = p.directives(); err != nil {
+			return nil, err
+		}
+		sel.selections, err = p.selectionSet()
+		return sel, err
+	}
+
+	if sel.name, err = p.name(); err != nil {
+		return nil, err
+	}
+	if p.is(":") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		sel.alias = sel.name
+		if sel.name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	if sel.args, err = p.arguments(); err != nil {
+		return nil, err
+	}
+	if sel.directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if p.is("{") {
+		if sel.selections, err = p.selectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return sel, nil
+}
+
+func (p *gqlParser) arguments() (map[string]any, error) {
+	if !p.is("(") {
+		return nil, nil
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	args := map[string]any{}
+	for !p.is(")") {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		if args[name], err = p.value(false); err != nil {
+			return nil, err
+		}
+	}
+	return args, p.next()
+}
+
+func (p *gqlParser) directives() ([]gqlDirective, error) {
+	var ds []gqlDirective
+	for p.is("@") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		args, err := p.arguments()
+		if err != nil {
+			return nil, err
+		}
+		ds = append(ds, gqlDirective{name: name, args: args})
+	}
+	return ds, nil
+}
+
+// value reads an argument value; constant ones may not use variables.
+func (p *gqlParser) value(constant bool) (any, error) {
+	tok := p.tok
+	switch {
+	case tok.kind == 'p' && tok.value == "$" && !constant:
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if p.vars != nil && !p.vars[name] {
+			return nil, fmt.Errorf("variable $%s is not defined", name)
+		}
+		if p.vars == nil {
+			return nil, fmt.Errorf("variable $%s used outside an operation that defines it", name)
+		}
+		return gqlVariable(name), nil
+	case tok.kind == 'i':
+		n, err := strconv.Atoi(tok.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %s", tok.value)
+		}
+		return n, p.next()
+	case tok.kind == 'f':
+		f, err := strconv.ParseFloat(tok.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s", tok.value)
+		}
+		return f, p.next()
+	case tok.kind == 's':
+		return tok.value, p.next()
+	case tok.kind == 'n':
+		var v any
+		switch tok.value {
+		case "true":
+			v = true
+		case "false":
+			v = false
+		case "null":
+			v = nil
+		default:
+			v = gqlEnum(tok.value)
+		}
+		return v, p.next()
+	case tok.kind == 'p' && tok.value == "[":
+		done, err := p.nest()
+		if err != nil {
+			return nil, err
+		}
+		defer done()
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		list := []any{}
+		for !p.is("]") {
+			item, err := p.value(constant)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+		}
+		return list, p.next()
+	case tok.kind == 'p' && tok.value == "{":
+		done, err := p.nest()
+		if err != nil {
+			return nil, err
+		}
+		defer done()
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		obj := map[string]any{}
+		for !p.is("}") {
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			if obj[name], err = p.value(constant); err != nil {
+				return nil, err
+			}
+		}
+		return obj, p.next()
+	}
+	return nil, p.unexpected()
+}
+
+func (p *gqlParser) is(punct string) bool {
+	return p.tok.kind == 'p' && p.tok.value == punct
+}
+
+func (p *gqlParser) expect(punct string) error {
+	if !p.is(punct) {
+		return p.unexpected()
+	}
+	return p.next()
+}
+
+func (p *gqlParser) name() (string, error) {
+	if p.tok.kind != 'n' {
+		return "", p.unexpected()
+	}
+	name := p.tok.value
+	return name, p.next()
+}
+
+func (p *gqlParser) unexpected() error {
+	if p.tok.kind == 0 {
+		return fmt.Errorf("syntax error: unexpected end of query")
+	}
+	return fmt.Errorf("syntax error at offset %d: unexpected %q", p.tok.pos, p.tok.value)
+}
+
+// next reads the next token, skipping whitespace, commas, and comments.
+func (p *gqlParser) next() error {
+	src := p.src
+	for p.pos < len(src) {
+		c := src[p.pos]
+		if c == '#' {
+			for p.pos < len(src) && src[p.pos] != '\n' {
+				p.pos++
+			}
+		} else if c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',' {
+			p.pos++
+		} else {
+			break
+		}
+	}
+	start := p.pos
+	if p.pos >= len(src) {
+		p.tok = gqlToken{pos: start}
+		return nil
+	}
+
+	c := src[p.pos]
+	switch {
+	case strings.HasPrefix(src[p.pos:], "..."):
+		p.pos += 3
+		p.tok = gqlToken{'p', "...", start}
+	case strings.ContainsRune("!$()[]{}:=@|", rune(c)):
+		p.pos++
+		p.tok = gqlToken{'p', string(c), start}
+	case gqlNameByte(c, false):
+		for p.pos < len(src) && gqlNameByte(src[p.pos], true) {
+			p.pos++
+		}
+		p.tok = gqlToken{'n', src[start:p.pos], start}
+	case c == '-' || (c >= '0' && c <= '9'):
+		p.pos++
+		kind := byte('i')
+		for p.pos < len(src) {
+			d := src[p.pos]
+			if d >= '0' && d <= '9' {
+				p.pos++
+			} else if d == '.' || d == 'e' || d == 'E' || ((d == '+' || d == '-') && (src[p.pos-1] == 'e' || src[p.pos-1] == 'E')) {
+				kind = 'f'
+				p.pos++
+			} else {
+				break
+			}
+		}
+		p.tok = gqlToken{kind, src[start:p.pos], start}
+	case strings.HasPrefix(src[p.pos:], `"""`):
+		end := strings.Index(src[p.pos+3:], `"""`)
+		if end < 0 {
+			return fmt.Errorf("syntax error at offset %d: unterminated string", start)
+		}
+		p.tok = gqlToken{'s', strings.TrimSpace(src[p.pos+3 : p.pos+3+end]), start}
+		p.pos += end + 6
+	case c == '"':
+		p.pos++
+		for p.pos < len(src) && src[p.pos] != '"' && src[p.pos] != '\n' {
+			if src[p.pos] == '\\' {
+				p.pos++
+			}
+			p.pos++
+		}
+		if p.pos >= len(src) || src[p.pos] != '"' {
+			return fmt.Errorf("syntax error at offset %d: unterminated string", start)
+		}
+		p.pos++
+		s, err := strconv.Unquote(src[start:p.pos])
+		if err != nil {
+			return fmt.Errorf("syntax error at offset %d: invalid string", start)
+		}
+		p.tok = gqlToken{'s', s, start}
+	default:
+		return fmt.Errorf("syntax error at offset %d: unexpected character %q", start, c)
+	}
+	return nil
+}
+
+// gqlNameByte reports whether c may appear in a name: letters and _, and
+// digits after the first.
+func gqlNameByte(c byte, digits bool) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (digits && c >= '0' && c <= '9')
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+func TestExecuteGraphQL(t *testing.T) {
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := &Document{Title: "Attention", SourceID: "1706.03762", Authors: []string{"Vaswani"}, Tags: []string{"ml"}}
+	other := &Document{Title: "Other", Tags: []string{"misc"}}
+	for _, d := range []*Document{doc, other} {
+		if err := s.AddDocument(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c, _ := s.CreateCollection("thesis", "")
+	s.AddToCollection(c.ID, doc.ID)
+	s.AddAnnotation(&Annotation{DocumentID: doc.ID, Type: "note", Content: "Key idea", Page: 3})
+	s.AddFlashcard(&Flashcard{DocumentID: doc.ID, Type: "basic", Front: "Q?", Back: "A"})
+
+	run := func(query string, vars map[string]any) (string, []GraphQLError) {
+		t.Helper()
+		resp := ExecuteGraphQL(s, &GraphQLRequest{Query: query, Variables: vars})
+		data, err := json.Marshal(resp.Data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data), resp.Errors
+	}
+
+	got, errs := run(`query Docs($tag: String = "ml") {
+		documents(tag: $tag) {
+			__typename
+			name: title, sourceId
+			annotations { content page }
+			collections { name }
+			flashcards { front document { title } }
+			...Extra
+		}
+	}
+	fragment Extra on Document { authors sessions { id } }`, nil)
+	want := `{"documents":[{"__typename":"Document","name":"Attention","sourceId":"1706.03762",` +
+		`"annotations":[{"content":"Key idea","page":3}],"collections":[{"name":"thesis"}],` +
+		`"flashcards":[{"front":"Q?","document":{"title":"Attention"}}],"authors":["Vaswani"],"sessions":[]}]}`
+	if got != want || errs != nil {
+		t.Errorf("got %s %v\nwant %s", got, errs, want)
+	}
+
+	got, errs = run(`query($id: ID!, $withDocs: Boolean!) {
+		collection(id: $id) { name documents @include(if: $withDocs) { title } }
+		tags { name count }
+	}`, map[string]any{"id": "thesis", "withDocs": false})
+	if want := `{"collection":{"name":"thesis"},"tags":[{"name":"misc","count":1},{"name":"ml","count":1}]}`; got != want || errs != nil {
+		t.Errorf("got %s %v\nwant %s", got, errs, want)
+	}
+
+	// Field errors null the field and leave the rest
+	got, errs = run(`{ document(id: "missing") { title } documents(limit: 1, collection: "nope") { title } collections { nope } }`, nil)
+	if want := `{"document":null,"documents":null,"collections":[{"nope":null}]}`; got != want || len(errs) != 2 {
+		t.Errorf("got %s %v\nwant %s", got, errs, want)
+	}
+	if errs[0].Path[0] != "documents" || !strings.Contains(errs[1].Message, `"nope"`) {
+		t.Errorf("errors = %+v", errs)
+	}
+
+	for query, msg := range map[string]string{
+		`{ documents { title }`:                                   "unexpected end",
+		`mutation { documents { title } }`:                        "only queries",
+		`query Q { a: documents { title } } { tags { name } }`:    "operationName",
+		`query($tag: String!) { documents(tag: $tag) { title } }`: "required",
+		`{ documents(tag: $tag) { title } }`:                      "$tag",
+		`{ documents }`:                                           "selection of subfields",
+		`{ documents { title { x } } }`:                           "no subfields",
+		`{ documents(shelf: "x") { title } }`:                     "unknown argument",
+	} {
+		resp := ExecuteGraphQL(s, &GraphQLRequest{Query: query})
+		if len(resp.Errors) == 0 || !strings.Contains(resp.Errors[0].Message, msg) {
+			t.Errorf("%s: errors %+v, want %q", query, resp.Errors, msg)
+		}
+	}
+}
+
+func TestParseGraphQLLimits(t *testing.T) {
+	nested := func(n int) string {
+		return "{ documents" + strings.Repeat(" { collections { documents", n/2) + " { title }" + strings.Repeat(" } }", n/2) + " }"
+	}
+	if _, err := parseGraphQL(nested(gqlMaxDepth - 2)); err != nil {
+		t.Errorf("query %d deep: %v", gqlMaxDepth-1, err)
+	}
+
+	// Each fragment selects both the next twice, so the last is selected
+	// 2^12 times
+	var spreads strings.Builder
+	spreads.WriteString("{ documents { ...F0 } }")
+	for i := 0; i < 12; i++ {
+		fmt.Fprintf(&spreads, " fragment F%d on Document { a: collections { ...F%d } b: collections { ...F%d } }", i, i+1, i+1)
+	}
+	spreads.WriteString(" fragment F12 on Document { title }")
+
+	for query, msg := range map[string]string{
+		nested(gqlMaxDepth + 2): "deeper than 12",
+		spreads.String():        "more than 1000 fields",
+		`{ documents { ...F } } fragment F on Document { collections { documents { ...F } } }`: `"F" spreads itself`,
+		`{ documents(tag: ` + strings.Repeat("[", 100) + `) { title } }`:                       "deeper than 32",
+		`{ documents { title } }` + strings.Repeat(" ", gqlMaxQuerySize):                       "longer than",
+	} {
+		if _, err := parseGraphQL(query); err == nil || !strings.Contains(err.Error(), msg) {
+			t.Errorf("%.60s: %v, want %q", query, err, msg)
+		}
+	}
+}
+
+func TestGraphQLSchema(t *testing.T) {
+	schema := GraphQLSchema()
+	for _, want := range []string{
+		"type Query {",
+		"  documents(tag: String, source: String",
+		"type Document {",
+		"  sourceId: String\n",
+		"  annotations: [Annotation!]!\n",
+		"  createdAt: Time\n",
+		"  meta: JSON\n",
+	} {
+		if !strings.Contains(schema, want) {
+			t.Errorf("schema lacks %q:\n%s", want, schema)
+		}
+	}
+}
@@ -0,0 +1,390 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ReferencesSourceGrobid marks references parsed by GROBID from the PDF
+// rather than from the extracted text.
+const ReferencesSourceGrobid = "grobid"
+
+// GrobidClient sends PDFs to a GROBID server
+// (https://github.com/kermitt2/grobid) to extract their metadata and
+// references.
+type GrobidClient struct {
+	BaseURL string // e.g. http://localhost:8070
+	HTTP    *http.Client
+}
+
+// NewGrobidClient returns a client for the GROBID server at baseURL.
+// Parsing a long paper can take GROBID a while, hence the long timeout.
+func NewGrobidClient(baseURL string) *GrobidClient {
+	return &GrobidClient{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		HTTP:    &http.Client{Timeout: 3 * time.Minute},
+	}
+}
+
+// GrobidAuthor is an author with the affiliations GROBID found for them.
+type GrobidAuthor struct {
+	Name         string   `json:"name"`
+	Email        string   `json:"email,omitempty"`
+	Affiliations []string `json:"affiliations,omitempty"`
+}
+
+// GrobidResult is the header metadata and bibliography of a PDF.
+type GrobidResult struct {
+	Title      string
+	Authors    []GrobidAuthor
+	Abstract   string
+	DOI        string
+	ArxivID    string
+	Year       int
+	Journal    string
+	Keywords   []string
+	References []Reference
+}
+
+// ProcessPDF runs GROBID's full-text service on the PDF at path.
+func (c *GrobidClient) ProcessPDF(path string) (*GrobidResult, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("input", filepath.Base(path))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(part, f); err != nil {
+		return nil, err
+	}
+	w.WriteField("includeRawCitations", "1")
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", c.BaseURL+"/api/processFulltextDocument", &body)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	req.Header.Set("Accept", "application/xml")
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("GROBID request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 32<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		// GROBID answers 204 when it could not extract anything
+		return nil, fmt.Errorf("GROBID: %s", resp.Status)
+	}
+	return ParseGrobidTEI(data)
+}
+
+// TEI elements GROBID produces, reduced to what is used here. Element
+// names match in any namespace.
+type teiDocument struct {
+	Title     teiText         `xml:"teiHeader>fileDesc>titleStmt>title"`
+	Source    teiBiblStruct   `xml:"teiHeader>fileDesc>sourceDesc>biblStruct"`
+	Keywords  []teiText       `xml:"teiHeader>profileDesc>textClass>keywords>term"`
+	Abstract  []teiText       `xml:"teiHeader>profileDesc>abstract>div>p"`
+	Abstract2 []teiText       `xml:"teiHeader>profileDesc>abstract>p"`
+	Bibl      []teiBiblStruct `xml:"text>back>div>listBibl>biblStruct"`
+}
+
+type teiBiblStruct struct {
+	Analytic teiBiblLevel `xml:"analytic"`
+	Monogr   teiBiblLevel `xml:"monogr"`
+	Idnos    []teiIdno    `xml:"idno"`
+	Notes    []teiNote    `xml:"note"`
+}
+
+type teiBiblLevel struct {
+	Titles  []teiTitle  `xml:"title"`
+	Authors []teiAuthor `xml:"author"`
+	Idnos   []teiIdno   `xml:"idno"`
+	Dates   []teiDate   `xml:"imprint>date"`
+}
+
+type teiTitle struct {
+	Level string `xml:"level,attr"`
+	teiText
+}
+
+type teiAuthor struct {
+	Forenames    []teiText        `xml:"persName>forename"`
+	Surname      teiText          `xml:"persName>surname"`
+	Email        teiText          `xml:"email"`
+	Affiliations []teiAffiliation `xml:"affiliation"`
+}
+
+type teiAffiliation struct {
+	OrgNames []teiText `xml:"orgName"`
+	Country  teiText   `xml:"address>country"`
+}
+
+type teiIdno struct {
+	Type string `xml:"type,attr"`
+	teiText
+}
+
+type teiNote struct {
+	Type string `xml:"type,attr"`
+	teiText
+}
+
+type teiDate struct {
+	When string `xml:"when,attr"`
+	teiText
+}
+
+// teiText is an element's text with any markup inside it removed.
+type teiText struct {
+	Inner string `xml:",innerxml"`
+}
+
+var teiTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+func (t teiText) String() string {
+	return strings.Join(strings.Fields(html.UnescapeString(teiTagPattern.ReplaceAllString(t.Inner, " "))), " ")
+}
+
+// ParseGrobidTEI reads GROBID's TEI output.
+func ParseGrobidTEI(data []byte) (*GrobidResult, error) {
+	var tei teiDocument
+	if err := xml.Unmarshal(data, &tei); err != nil {
+		return nil, fmt.Errorf("parse TEI: %w", err)
+	}
+
+	r := &GrobidResult{Title: tei.Title.String()}
+	if r.Title == "" {
+		r.Title = tei.Source.title()
+	}
+	for _, a := range append(tei.Source.Analytic.Authors, tei.Source.Monogr.Authors...) {
+		name := a.name()
+		if name == "" {
+			continue
+		}
+		author := GrobidAuthor{Name: name, Email: a.Email.String()}
+		for _, aff := range a.Affiliations {
+			if s := aff.String(); s != "" {
+				author.Affiliations = append(author.Affiliations, s)
+			}
+		}
+		r.Authors = append(r.Authors, author)
+	}
+	var paragraphs []string
+	for _, p := range append(tei.Abstract, tei.Abstract2...) {
+		if s := p.String(); s != "" {
+			paragraphs = append(paragraphs, s)
+		}
+	}
+	r.Abstract = strings.Join(paragraphs, "\n\n")
+	r.DOI, r.ArxivID = tei.Source.identifiers()
+	r.Year = tei.Source.year()
+	for _, t := range tei.Source.Monogr.Titles {
+		if t.Level == "j" {
+			r.Journal = t.String()
+			break
+		}
+	}
+	for _, k := range tei.Keywords {
+		if s := k.String(); s != "" {
+			r.Keywords = append(r.Keywords, s)
+		}
+	}
+
+	r.References = make([]Reference, 0, len(tei.Bibl))
+	for i, b := range tei.Bibl {
+		ref := Reference{Index: i + 1, Title: b.title(), Year: b.year()}
+		ref.DOI, ref.ArxivID = b.identifiers()
+		for _, a := range append(b.Analytic.Authors, b.Monogr.Authors...) {
+			if name := a.name(); name != "" {
+				ref.Authors = append(ref.Authors, name)
+			}
+		}
+		for _, n := range b.Notes {
+			if n.Type == "raw_reference" {
+				ref.Raw = n.String()
+			}
+		}
+		for _, id := range b.Idnos {
+			if strings.EqualFold(id.Type, "url") {
+				ref.URL = id.String()
+			}
+		}
+		if ref.Raw == "" {
+			ref.Raw = b.summary(ref)
+		}
+		r.References = append(r.References, ref)
+	}
+	return r, nil
+}
+
+func (a teiAuthor) name() string {
+	var parts []string
+	for _, f := range a.Forenames {
+		parts = append(parts, f.String())
+	}
+	parts = append(parts, a.Surname.String())
+	return strings.TrimSpace(strings.Join(parts, " "))
+}
+
+// String joins an affiliation's department, institution, and country.
+func (a teiAffiliation) String() string {
+	var parts []string
+	for _, o := range a.OrgNames {
+		if s := o.String(); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	if c := a.Country.String(); c != "" {
+		parts = append(parts, c)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// title is the article title, or the book or journal title of a
+// monograph.
+func (b teiBiblStruct) title() string {
+	for _, t := range append(b.Analytic.Titles, b.Monogr.Titles...) {
+		if s := t.String(); s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+func (b teiBiblStruct) identifiers() (doi, arxivID string) {
+	for _, id := range append(append(b.Analytic.Idnos, b.Monogr.Idnos...), b.Idnos...) {
+		switch strings.ToLower(id.Type) {
+		case "doi":
+			if doi == "" {
+				doi = strings.ToLower(id.String())
+			}
+		case "arxiv":
+			if arxivID == "" {
+				arxivID = arxivBaseID(id.String())
+			}
+		}
+	}
+	return doi, arxivID
+}
+
+func (b teiBiblStruct) year() int {
+	for _, d := range append(b.Monogr.Dates, b.Analytic.Dates...) {
+		for _, s := range []string{d.When, d.String()} {
+			if m := refYearPattern.FindStringSubmatch(s); m != nil {
+				y, _ := strconv.Atoi(m[1])
+				return y
+			}
+		}
+	}
+	return 0
+}
+
+// summary stands in for the raw citation when GROBID didn't include it.
+func (b teiBiblStruct) summary(ref Reference) string {
+	var parts []string
+	if len(ref.Authors) > 0 {
+		parts = append(parts, strings.Join(ref.Authors, ", "))
+	}
+	if ref.Title != "" {
+		parts = append(parts, ref.Title)
+	}
+	for _, t := range b.Monogr.Titles {
+		if s := t.String(); s != "" && s != ref.Title {
+			parts = append(parts, s)
+			break
+		}
+	}
+	if ref.Year > 0 {
+		parts = append(parts, strconv.Itoa(ref.Year))
+	}
+	return strings.Join(parts, ". ")
+}
+
+// Apply fills in doc from the result. The title, authors, and abstract are
+// replaced only where the set flag says no value was given otherwise;
+// identifiers, year, journal, keywords, and affiliations go into Meta
+// without replacing what is there.
+func (r *GrobidResult) Apply(doc *Document, setTitle, setAuthors, setAbstract bool) {
+	if r.Title != "" && (setTitle || doc.Title == "") {
+		doc.Title = r.Title
+	}
+	if len(r.Authors) > 0 && (setAuthors || len(doc.Authors) == 0) {
+		doc.Authors = make([]string, len(r.Authors))
+		for i, a := range r.Authors {
+			doc.Authors[i] = a.Name
+		}
+	}
+	if r.Abstract != "" && (setAbstract || doc.Abstract == "") {
+		doc.Abstract = r.Abstract
+	}
+
+	if doc.Meta == nil {
+		doc.Meta = JSONMap{}
+	}
+	setMeta := func(key string, value any) {
+		if _, ok := doc.Meta[key]; !ok {
+			doc.Meta[key] = value
+		}
+	}
+	if r.DOI != "" {
+		setMeta("doi", r.DOI)
+	}
+	if r.ArxivID != "" {
+		setMeta("arxiv", r.ArxivID)
+	}
+	if r.Year > 0 {
+		setMeta("year", r.Year)
+	}
+	if r.Journal != "" {
+		setMeta("journal", r.Journal)
+	}
+	if len(r.Keywords) > 0 {
+		setMeta("keywords", r.Keywords)
+	}
+	for _, a := range r.Authors {
+		if len(a.Affiliations) > 0 {
+			setMeta("affiliations", r.Authors)
+			break
+		}
+	}
+}
+
+// DocumentReferences returns the result's references for storing with
+// doc. They are kept when the document's text changes, since they were
+// parsed from the PDF.
+func (r *GrobidResult) DocumentReferences(doc *Document) *DocumentReferences {
+	return &DocumentReferences{
+		DocumentID: doc.ID,
+		TextHash:   HashText(doc.FullText),
+		Source:     ReferencesSourceGrobid,
+		References: r.References,
+		UpdatedAt:  time.Now(),
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+const sampleTEI = `<?xml version="1.0" encoding="UTF-8"?>
+<TEI xmlns="http://www.tei-c.org/ns/1.0">
+	<teiHeader>
+		<fileDesc>
+			<titleStmt><title level="a" type="main">Attention Is All You Need</title></titleStmt>
+			<sourceDesc>
+				<biblStruct>
+					<analytic>
+						<author>
+							<persName><forename type="first">Ashish</forename><surname>Vaswani</surname></persName>
+							<email>avaswani@google.com</email>
+							<affiliation key="aff0">
+								<orgName type="department">Google Brain</orgName>
+								<orgName type="institution">Google</orgName>
+								<address><country key="US">USA</country></address>
+							</affiliation>
+						</author>
+						<author>
+							<persName><forename type="first">Noam</forename><forename type="middle">M</forename><surname>Shazeer</surname></persName>
+						</author>
+						<title level="a" type="main">Attention Is All You Need</title>
+					</analytic>
+					<monogr>
+						<title level="j" type="main">Advances in Neural Information Processing Systems</title>
+						<imprint><date type="published" when="2017-12-04">2017</date></imprint>
+					</monogr>
+					<idno type="DOI">10.48550/ARXIV.1706.03762</idno>
+					<idno type="arXiv">arXiv:1706.03762v5</idno>
+				</biblStruct>
+			</sourceDesc>
+		</fileDesc>
+		<profileDesc>
+			<textClass><keywords><term>transformers</term><term>attention</term></keywords></textClass>
+			<abstract><div><p>The dominant sequence transduction models are based on <ref>recurrent</ref> networks.</p><p>We propose the Transformer &amp; more.</p></div></abstract>
+		</profileDesc>
+	</teiHeader>
+	<text>
+		<body><div><head>Introduction</head><p>Body text.</p></div></body>
+		<back>
+			<div type="references">
+				<listBibl>
+					<biblStruct xml:id="b0">
+						<analytic>
+							<title level="a" type="main">Layer normalization</title>
+							<author><persName><forename type="first">Jimmy</forename><forename type="middle">Lei</forename><surname>Ba</surname></persName></author>
+						</analytic>
+						<monogr>
+							<title level="m">arXiv preprint</title>
+							<imprint><date type="published" when="2016" /></imprint>
+						</monogr>
+						<idno type="arXiv">arXiv:1607.06450</idno>
+						<note type="raw_reference">Jimmy Lei Ba. Layer normalization. arXiv:1607.06450, 2016.</note>
+					</biblStruct>
+					<biblStruct xml:id="b1">
+						<analytic>
+							<title level="a" type="main">Long short-term memory</title>
+							<author><persName><forename type="first">Sepp</forename><surname>Hochreiter</surname></persName></author>
+							<idno type="DOI">10.1162/NECO.1997.9.8.1735</idno>
+						</analytic>
+						<monogr>
+							<title level="j">Neural Computation</title>
+							<imprint><date type="published" when="1997" /></imprint>
+						</monogr>
+					</biblStruct>
+				</listBibl>
+			</div>
+		</back>
+	</text>
+</TEI>`
+
+func TestParseGrobidTEI(t *testing.T) {
+	r, err := ParseGrobidTEI([]byte(sampleTEI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Title != "Attention Is All You Need" || r.Year != 2017 || r.DOI != "10.48550/arxiv.1706.03762" || r.ArxivID != "1706.03762" {
+		t.Errorf("header = %+v", r)
+	}
+	if r.Journal != "Advances in Neural Information Processing Systems" {
+		t.Errorf("journal = %q", r.Journal)
+	}
+	if r.Abstract != "The dominant sequence transduction models are based on recurrent networks.\n\nWe propose the Transformer & more." {
+		t.Errorf("abstract = %q", r.Abstract)
+	}
+	if strings.Join(r.Keywords, ",") != "transformers,attention" {
+		t.Errorf("keywords = %q", r.Keywords)
+	}
+	if len(r.Authors) != 2 || r.Authors[0].Name != "Ashish Vaswani" || r.Authors[1].Name != "Noam M Shazeer" {
+		t.Fatalf("authors = %+v", r.Authors)
+	}
+	if a := r.Authors[0]; a.Email != "avaswani@google.com" || len(a.Affiliations) != 1 || a.Affiliations[0] != "Google Brain, Google, USA" {
+		t.Errorf("author 1 = %+v", a)
+	}
+
+	if len(r.References) != 2 {
+		t.Fatalf("references = %+v", r.References)
+	}
+	if ref := r.References[0]; ref.Index != 1 || ref.Title != "Layer normalization" || ref.ArxivID != "1607.06450" || ref.Year != 2016 ||
+		ref.Raw != "Jimmy Lei Ba. Layer normalization. arXiv:1607.06450, 2016." {
+		t.Errorf("reference 1 = %+v", ref)
+	}
+	if ref := r.References[1]; ref.DOI != "10.1162/neco.1997.9.8.1735" || ref.Year != 1997 ||
+		ref.Raw != "Sepp Hochreiter. Long short-term memory. Neural Computation. 1997" {
+		t.Errorf("reference 2 = %+v", ref)
+	}
+}
+
+func TestGrobidProcessPDF(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/api/processFulltextDocument" {
+			http.NotFound(w, r)
+			return
+		}
+		f, _, err := r.FormFile("input")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		data, _ := io.ReadAll(f)
+		if !strings.HasPrefix(string(data), "%PDF-") {
+			http.Error(w, "not a PDF", http.StatusBadRequest)
+			return
+		}
+		io.WriteString(w, sampleTEI)
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "1706.03762.pdf")
+	if err := os.WriteFile(path, []byte("%PDF-1.4\n%%EOF\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewGrobidClient(srv.URL + "/").ProcessPDF(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The file name title is replaced; given authors are kept
+	doc := &Document{Title: "1706.03762", Authors: []string{"A. Vaswani"}, Meta: JSONMap{"year": 2018}}
+	r.Apply(doc, true, false, true)
+	if doc.Title != "Attention Is All You Need" || strings.Join(doc.Authors, ",") != "A. Vaswani" || !strings.HasPrefix(doc.Abstract, "The dominant") {
+		t.Errorf("doc = %+v", doc)
+	}
+	if doc.Meta["year"] != 2018 || doc.Meta["doi"] != "10.48550/arxiv.1706.03762" || doc.Meta["journal"] == nil || doc.Meta["affiliations"] == nil {
+		t.Errorf("meta = %+v", doc.Meta)
+	}
+
+	// GROBID's references are kept when the text changes
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddDocument(doc); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveDocumentReferences(r.DocumentReferences(doc)); err != nil {
+		t.Fatal(err)
+	}
+	doc.FullText = samplePaper
+	refs, err := LoadDocumentReferences(s, doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refs.Source != ReferencesSourceGrobid || len(refs.References) != 2 || refs.References[1].Title != "Long short-term memory" {
+		t.Errorf("references = %+v", refs)
+	}
+
+	srv.Close()
+	if _, err := NewGrobidClient(srv.URL).ProcessPDF(path); err == nil {
+		t.Error("expected an error with the server down")
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Names of the checks in a HealthReport, in the order they are reported.
+const (
+	HealthMissingAbstract = "missing_abstract"
+	HealthMissingAuthors  = "missing_authors"
+	HealthMissingFile     = "missing_file"
+	HealthUntagged        = "untagged"
+	HealthStaleUnread     = "stale_unread"
+	HealthLargeFullText   = "large_full_text"
+)
+
+// Default thresholds of CheckHealth.
+const (
+	DefaultStaleUnreadDays  = 180
+	DefaultMaxFullTextBytes = 1 << 20
+)
+
+// HealthOptions are the thresholds of CheckHealth. Zero values use the
+// defaults.
+type HealthOptions struct {
+	StaleDays   int // unread documents added longer ago than this are stale
+	MaxFullText int // bytes of full text above which a document is flagged
+	Now         time.Time
+}
+
+// HealthReport is a library's hygiene at one point in time: each check
+// with the documents failing it. Reports from different days can be
+// compared to see whether the library is getting tidier.
+type HealthReport struct {
+	CheckedAt     time.Time     `json:"checked_at"`
+	Documents     int           `json:"documents"`
+	UntaggedRatio float64       `json:"untagged_ratio"` // untagged / documents, 0-1
+	Checks        []HealthCheck `json:"checks"`
+}
+
+// HealthCheck is one check of a HealthReport.
+type HealthCheck struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Count       int      `json:"count"`
+	DocumentIDs []string `json:"document_ids"`
+}
+
+// Check returns the named check of the report, or nil.
+func (r *HealthReport) Check(name string) *HealthCheck {
+	for i := range r.Checks {
+		if r.Checks[i].Name == name {
+			return &r.Checks[i]
+		}
+	}
+	return nil
+}
+
+// Issues returns the number of failed checks summed over documents; a
+// document failing two checks counts twice.
+func (r *HealthReport) Issues() int {
+	n := 0
+	for _, c := range r.Checks {
+		n += c.Count
+	}
+	return n
+}
+
+// CheckHealth checks every document for missing metadata, missing files,
+// missing tags, unread documents left for too long, and very large full
+// text. Notes are not expected to have an abstract, authors, or a file,
+// and articles without a file keep their text in the library, so those
+// are not flagged.
+func CheckHealth(s LibraryStore, opts HealthOptions) (*HealthReport, error) {
+	if opts.StaleDays <= 0 {
+		opts.StaleDays = DefaultStaleUnreadDays
+	}
+	if opts.MaxFullText <= 0 {
+		opts.MaxFullText = DefaultMaxFullTextBytes
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+
+	docs, err := s.ListDocuments(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &HealthReport{CheckedAt: opts.Now, Documents: len(docs)}
+	for _, c := range []struct{ name, desc string }{
+		{HealthMissingAbstract, "no abstract"},
+		{HealthMissingAuthors, "no authors"},
+		{HealthMissingFile, "file missing from disk, or a paper or book with no file"},
+		{HealthUntagged, "no tags"},
+		{HealthStaleUnread, fmt.Sprintf("unread and added more than %d days ago", opts.StaleDays)},
+		{HealthLargeFullText, fmt.Sprintf("full text over %d KB", opts.MaxFullText/1024)},
+	} {
+		r.Checks = append(r.Checks, HealthCheck{Name: c.name, Description: c.desc, DocumentIDs: []string{}})
+	}
+	flag := func(name string, doc *Document) {
+		c := r.Check(name)
+		c.Count++
+		c.DocumentIDs = append(c.DocumentIDs, doc.ID)
+	}
+
+	stale := opts.Now.AddDate(0, 0, -opts.StaleDays)
+	for _, d := range docs {
+		note := d.Type == DocTypeNote
+		if !note && d.Abstract == "" {
+			flag(HealthMissingAbstract, d)
+		}
+		if !note && len(d.Authors) == 0 {
+			flag(HealthMissingAuthors, d)
+		}
+		if d.Path != "" {
+			if _, err := os.Stat(DocumentPath(d)); err != nil {
+				flag(HealthMissingFile, d)
+			}
+		} else if d.Type == DocTypePaper || d.Type == DocTypeBook {
+			flag(HealthMissingFile, d)
+		}
+		if len(d.Tags) == 0 {
+			flag(HealthUntagged, d)
+		}
+		if (d.Status == "" || d.Status == StatusUnread) && d.CreatedAt.Before(stale) {
+			flag(HealthStaleUnread, d)
+		}
+		if len(d.FullText) > opts.MaxFullText {
+			flag(HealthLargeFullText, d)
+		}
+	}
+	if r.Documents > 0 {
+		r.UntaggedRatio = float64(r.Check(HealthUntagged).Count) / float64(r.Documents)
+	}
+	return r, nil
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+func TestCheckHealth(t *testing.T) {
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	present := filepath.Join(dir, "present.pdf")
+	if err := os.WriteFile(present, []byte("%PDF-1.4"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	tidy := &Document{Type: DocTypePaper, Title: "Tidy", Abstract: "a", Authors: []string{"Ada"}, Tags: []string{"ml"},
+		Path: present, Status: StatusCompleted, CreatedAt: now.AddDate(-2, 0, 0)}
+	gone := &Document{Type: DocTypePaper, Title: "Gone", Abstract: "a", Authors: []string{"Ada"}, Tags: []string{"ml"},
+		Path: filepath.Join(dir, "gone.pdf"), CreatedAt: now.AddDate(0, 0, -10)}
+	stale := &Document{Type: DocTypeArticle, Title: "Stale", CreatedAt: now.AddDate(0, 0, -200),
+		FullText: strings.Repeat("x", 2048)}
+	note := &Document{Type: DocTypeNote, Title: "Idea", CreatedAt: now}
+	for _, d := range []*Document{tidy, gone, stale, note} {
+		if err := s.AddDocument(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r, err := CheckHealth(s, HealthOptions{MaxFullText: 1024, Now: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{
+		HealthMissingAbstract: {stale.ID},
+		HealthMissingAuthors:  {stale.ID},
+		HealthMissingFile:     {gone.ID},
+		HealthUntagged:        {stale.ID, note.ID},
+		HealthStaleUnread:     {stale.ID},
+		HealthLargeFullText:   {stale.ID},
+	}
+	if len(r.Checks) != len(want) {
+		t.Fatalf("%d checks, want %d", len(r.Checks), len(want))
+	}
+	for _, c := range r.Checks {
+		got := slices.Clone(c.DocumentIDs)
+		slices.Sort(got)
+		w := slices.Clone(want[c.Name])
+		slices.Sort(w)
+		if !slices.Equal(got, w) || c.Count != len(w) {
+			t.Errorf("%s = %v (count %d), want %v", c.Name, got, c.Count, w)
+		}
+	}
+	if r.Documents != 4 || r.UntaggedRatio != 0.5 || r.Issues() != 7 {
+		t.Errorf("documents %d, untagged ratio %v, issues %d", r.Documents, r.UntaggedRatio, r.Issues())
+	}
+
+	// A paper with no file at all is flagged; a longer stale window clears the article
+	tidy.Path = ""
+	if err := s.UpdateDocument(tidy); err != nil {
+		t.Fatal(err)
+	}
+	r, err = CheckHealth(s, HealthOptions{StaleDays: 365, MaxFullText: 1024, Now: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := r.Check(HealthMissingFile); c.Count != 2 {
+		t.Errorf("missing_file = %v, want the tidy and gone papers", c.DocumentIDs)
+	}
+	if c := r.Check(HealthStaleUnread); c.Count != 0 {
+		t.Errorf("stale_unread with 365 days = %v", c.DocumentIDs)
+	}
+}
@@ -0,0 +1,241 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Hook events: the points in a document's life that run hooks.
+const (
+	HookPreImport  = "pre_import"  // before an imported document is stored
+	HookPostImport = "post_import" // after it is stored
+	HookPreExport  = "pre_export"  // before an export is written
+)
+
+// HookEvents lists the hook events in order.
+var HookEvents = []string{HookPreImport, HookPostImport, HookPreExport}
+
+// Hooks holds the shell commands to run at each event, in order. Commands
+// are text/template strings over the same values the environment carries
+// (see HookVars), shell-quoted, so "./rename.sh {{.Path}}" is safe with
+// any file name.
+type Hooks struct {
+	commands map[string][]*template.Template
+	sources  map[string][]string
+}
+
+// HooksFile returns the hooks file: $ARC_LIBRARY_HOOKS, or
+// arc-library/hooks.yaml under the user config directory.
+func HooksFile() (string, error) {
+	return configFile("ARC_LIBRARY_HOOKS", "hooks.yaml")
+}
+
+// LoadHooks reads a hooks file, which maps events to a command or a list
+// of commands:
+//
+//	post_import: ./scripts/rename.sh {{.Path}}
+//	pre_export:
+//	  - cp ~/library.db ~/backups/
+//
+// A missing file means no hooks.
+func LoadHooks(path string) (*Hooks, error) {
+	h := &Hooks{commands: map[string][]*template.Template{}, sources: map[string][]string{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]yaml.Node
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for event, node := range raw {
+		if !slices.Contains(HookEvents, event) {
+			return nil, fmt.Errorf("%s: unknown hook %q (use %s)", path, event, strings.Join(HookEvents, ", "))
+		}
+		var cmds []string
+		if node.Kind == yaml.ScalarNode {
+			cmds = []string{node.Value}
+		} else if err := node.Decode(&cmds); err != nil {
+			return nil, fmt.Errorf("%s: %s: expected a command or a list of commands", path, event)
+		}
+		for _, c := range cmds {
+			if strings.TrimSpace(c) == "" {
+				continue
+			}
+			if err := h.Add(event, c); err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+		}
+	}
+	return h, nil
+}
+
+// Add appends a command to an event's hooks.
+func (h *Hooks) Add(event, command string) error {
+	if !slices.Contains(HookEvents, event) {
+		return fmt.Errorf("unknown hook %q (use %s)", event, strings.Join(HookEvents, ", "))
+	}
+	t, err := template.New(event).Option("missingkey=error").Parse(command)
+	if err != nil {
+		return fmt.Errorf("%s: %w", event, err)
+	}
+	if h.commands == nil {
+		h.commands, h.sources = map[string][]*template.Template{}, map[string][]string{}
+	}
+	h.commands[event] = append(h.commands[event], t)
+	h.sources[event] = append(h.sources[event], command)
+	return nil
+}
+
+// HookVars are the values a hook sees. Each key K is {{.K}} in a command
+// and ARC_LIBRARY_HOOK_<K in upper snake case> in its environment, e.g.
+// SourceID and ARC_LIBRARY_HOOK_SOURCE_ID.
+type HookVars map[string]string
+
+// DocumentHookVars returns the values describing doc. Path is the file's
+// full path and Document the whole document as JSON. The ID is empty
+// before the document is stored.
+func DocumentHookVars(doc *Document) HookVars {
+	data, _ := json.Marshal(doc)
+	return HookVars{
+		"ID":       doc.ID,
+		"Title":    doc.Title,
+		"Path":     DocumentPath(doc),
+		"Type":     string(doc.Type),
+		"Source":   doc.Source,
+		"SourceID": doc.SourceID,
+		"Authors":  strings.Join(doc.Authors, "; "),
+		"Tags":     strings.Join(doc.Tags, ","),
+		"Document": string(data),
+	}
+}
+
+// ExportHookVars returns the values describing an export: its format,
+// the output file ("" for stdout), and how many documents it holds.
+func ExportHookVars(format, file string, count int) HookVars {
+	return HookVars{"Format": format, "File": file, "Count": strconv.Itoa(count)}
+}
+
+// hookEnvName turns a HookVars key into its environment variable.
+func hookEnvName(key string) string {
+	var b strings.Builder
+	b.WriteString("ARC_LIBRARY_HOOK_")
+	for i, r := range key {
+		if i > 0 && r >= 'A' && r <= 'Z' && !(key[i-1] >= 'A' && key[i-1] <= 'Z') {
+			b.WriteByte('_')
+		}
+		b.WriteRune(r)
+	}
+	return strings.ToUpper(b.String())
+}
+
+// Run runs an event's commands in order with vars, stopping at the first
+// that fails. Their standard error goes to errOut, as does standard output
+// unless it is a JSON object, which Run returns instead: pre_import hooks
+// print one to change the document.
+func (h *Hooks) Run(event string, vars HookVars, errOut io.Writer) ([][]byte, error) {
+	if h == nil {
+		return nil, nil
+	}
+	quoted := map[string]string{}
+	env := append(os.Environ(), "ARC_LIBRARY_HOOK="+event)
+	for k, v := range vars {
+		quoted[k] = hookWord(k, v)
+		env = append(env, hookEnvName(k)+"="+v)
+	}
+
+	var objects [][]byte
+	for i, t := range h.commands[event] {
+		var line strings.Builder
+		if err := t.Execute(&line, quoted); err != nil {
+			return objects, fmt.Errorf("%s hook %q: %w", event, h.sources[event][i], err)
+		}
+		var c *exec.Cmd
+		if runtime.GOOS == "windows" {
+			// /V:ON for the !VAR! references hookWord inserts
+			c = exec.Command("cmd", "/V:ON", "/C", line.String())
+		} else {
+			c = exec.Command("sh", "-c", line.String())
+		}
+		var stdout bytes.Buffer
+		c.Env, c.Stdout, c.Stderr = env, &stdout, errOut
+		err := c.Run()
+		if out := bytes.TrimSpace(stdout.Bytes()); err == nil && bytes.HasPrefix(out, []byte("{")) {
+			objects = append(objects, out)
+		} else {
+			errOut.Write(stdout.Bytes())
+		}
+		if err != nil {
+			return objects, fmt.Errorf("%s hook %q: %w", event, h.sources[event][i], err)
+		}
+	}
+	return objects, nil
+}
+
+// PreImport runs the pre_import hooks for doc and applies the JSON
+// objects they print to it, so a hook that renames the file can report
+// {"path": "<new path>"}. An error means doc should not be imported.
+func (h *Hooks) PreImport(doc *Document, errOut io.Writer) error {
+	objects, err := h.Run(HookPreImport, DocumentHookVars(doc), errOut)
+	if err != nil {
+		return err
+	}
+	for _, obj := range objects {
+		path := doc.Path
+		if err := json.Unmarshal(obj, doc); err != nil {
+			return fmt.Errorf("%s hook printed invalid JSON: %w", HookPreImport, err)
+		}
+		if doc.Path != path {
+			doc.Path = StoredPath(ResolvePath(doc.Path, LibraryRoot()), LibraryRoot())
+		}
+	}
+	return nil
+}
+
+// PostImport runs the post_import hooks for a stored document.
+func (h *Hooks) PostImport(doc *Document, errOut io.Writer) error {
+	_, err := h.Run(HookPostImport, DocumentHookVars(doc), errOut)
+	return err
+}
+
+// PreExport runs the pre_export hooks. An error means the export should
+// not be written.
+func (h *Hooks) PreExport(format, file string, count int, errOut io.Writer) error {
+	_, err := h.Run(HookPreExport, ExportHookVars(format, file, count), errOut)
+	return err
+}
+
+// hookWord is what {{.K}} becomes in a hook command for the value v of
+// key K: v quoted for sh, or, on Windows, a reference to its environment
+// variable. No quoting keeps cmd from expanding %VAR% in a value, or
+// from acting on & and | in one, but cmd expands a delayed !VAR!
+// reference only after it has parsed the line, and never again.
+func hookWord(key, v string) string {
+	if runtime.GOOS == "windows" {
+		return `"!` + hookEnvName(key) + `!"`
+	}
+	return shellQuote(v)
+}
+
+// shellQuote quotes s as one word for sh.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
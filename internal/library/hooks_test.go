@@ -0,0 +1,113 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestLoadHooks(t *testing.T) {
+	dir := t.TempDir()
+	if h, err := LoadHooks(filepath.Join(dir, "missing.yaml")); err != nil || len(h.commands) != 0 {
+		t.Errorf("missing file = %+v, %v", h, err)
+	}
+
+	for content, wantErr := range map[string]string{
+		"post_import: echo {{.Path}}\npre_export:\n  - echo one\n  - echo two\n": "",
+		"post_imports: echo hi\n":      `unknown hook "post_imports"`,
+		"pre_import: echo {{.Path\n":   "unclosed action",
+		"pre_export:\n  nested: map\n": "expected a command or a list",
+	} {
+		path := filepath.Join(dir, "hooks.yaml")
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		h, err := LoadHooks(path)
+		if wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), wantErr) {
+				t.Errorf("%q: err = %v, want %q", content, err, wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%q: %v", content, err)
+		}
+		if len(h.sources[HookPostImport]) != 1 || len(h.sources[HookPreExport]) != 2 {
+			t.Errorf("hooks = %v", h.sources)
+		}
+	}
+}
+
+func TestRunHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hooks run with sh")
+	}
+	t.Setenv("ARC_LIBRARY_ROOT", "")
+	dir := t.TempDir()
+	file := filepath.Join(dir, "it's $(here).pdf")
+	if err := os.WriteFile(file, []byte("%PDF"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var h Hooks
+	renamed := filepath.Join(dir, "renamed.pdf")
+	// The title is quoted like every value, so the substitution stays text
+	for _, c := range []string{
+		`test -f {{.Path}} && mv {{.Path}} ` + renamed,
+		`echo "source $ARC_LIBRARY_HOOK_SOURCE_ID" >&2; echo {{.Title}}`,
+		`echo '{"path": "` + renamed + `", "tags": ["hooked"]}'`,
+	} {
+		if err := h.Add(HookPreImport, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	doc := &Document{Title: "$(touch pwned)", Path: file, SourceID: "2301.00001"}
+	var errOut bytes.Buffer
+	if err := h.PreImport(doc, &errOut); err != nil {
+		t.Fatalf("%v\n%s", err, errOut.String())
+	}
+	if doc.Path != renamed || len(doc.Tags) != 1 || doc.Tags[0] != "hooked" {
+		t.Errorf("document after hooks = %+v", doc)
+	}
+	if got := errOut.String(); got != "source 2301.00001\n$(touch pwned)\n" {
+		t.Errorf("hook output = %q", got)
+	}
+	if _, err := os.Stat("pwned"); err == nil {
+		os.Remove("pwned")
+		t.Error("a title was run as a command")
+	}
+
+	// A failing hook stops the rest and reports itself
+	var failing Hooks
+	failing.Add(HookPreExport, "exit 3")
+	failing.Add(HookPreExport, "echo never")
+	errOut.Reset()
+	err := failing.PreExport("bibtex", "", 2, &errOut)
+	if err == nil || !strings.Contains(err.Error(), "exit status 3") || errOut.Len() != 0 {
+		t.Errorf("failing hook: err = %v, output %q", err, errOut.String())
+	}
+
+	// No hooks configured is not an error
+	var none *Hooks
+	if err := none.PostImport(doc, &errOut); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHookEnvName(t *testing.T) {
+	for key, want := range map[string]string{
+		"ID":       "ARC_LIBRARY_HOOK_ID",
+		"SourceID": "ARC_LIBRARY_HOOK_SOURCE_ID",
+		"Count":    "ARC_LIBRARY_HOOK_COUNT",
+	} {
+		if got := hookEnvName(key); got != want {
+			t.Errorf("hookEnvName(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
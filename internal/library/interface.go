@@ -8,14 +8,20 @@ import "time"
 // LibraryStore is the interface for persisting and retrieving library data.
 // Implementations may use SQL, KV storage, or in-memory structures.
 type LibraryStore interface {
+	Capabilities() Capabilities // the optional features this backend has
+
 	// Document operations
 	AddDocument(*Document) error
+	AddDocuments([]*Document) error // adds many at once, much faster than one by one; the SQL store adds all or none
 	GetDocument(id string) (*Document, error)
 	GetDocumentByPath(path string) (*Document, error)
 	GetDocumentBySourceID(source, sourceID string) (*Document, error)
-	ListDocuments(opts *ListOptions) ([]*Document, error)
+	ListDocuments(opts *ListOptions) ([]*Document, error) // most recently updated first, so Limit keeps those
 	UpdateDocument(*Document) error
 	DeleteDocument(id string) error
+	ListDocumentRevisions(documentID string) ([]*DocumentRevision, error) // oldest first; UpdateDocument records them
+	Compact() (*CompactStats, error)                                      // compresses full text stored uncompressed, reclaims space
+	RebuildIndexes() (*IndexStats, error)                                 // rebuilds lookup and filter indexes from the documents
 
 	// Tag operations
 	AddTag(documentID, tag string) error
@@ -29,9 +35,11 @@ type LibraryStore interface {
 	AddToCollection(collectionID, documentID string) error
 	RemoveFromCollection(collectionID, documentID string) error
 	DeleteCollection(id string) error
+	SetCollectionPublic(id string, public bool) error
 
 	// Annotation operations
 	AddAnnotation(*Annotation) error
+	AddAnnotations([]*Annotation) error // as AddDocuments; replies need their parents' IDs
 	GetAnnotations(documentID string) ([]*Annotation, error)
 	DeleteAnnotation(id string) error
 
@@ -40,16 +48,34 @@ type LibraryStore interface {
 	EndSession(sessionID string, pagesRead int, notes string) error
 	ListSessions(documentID string) ([]*ReadingSession, error)
 
+	// Document link operations
+	AddDocumentLink(*DocumentLink) error
+	ListDocumentLinks(documentID string) ([]*DocumentLink, error) // links from or to the document
+	DeleteDocumentLink(id string) error
+
+	// Aggregate operations, for stats without loading every record
+	CountDocumentsByType() (map[DocumentType]int, error)
+	CountAnnotations() (int, error)
+	SessionTotals() (sessions, pagesRead int, err error)
+
 	// Flashcard operations (Phase 2)
 	AddFlashcard(*Flashcard) error
+	AddFlashcards([]*Flashcard) error // as AddDocuments
 	GetFlashcard(id string) (*Flashcard, error)
 	ListFlashcards(opts *FlashcardListOptions) ([]*Flashcard, error)
 	UpdateFlashcard(*Flashcard) error
 	DeleteFlashcard(id string) error
 	ReviewFlashcard(id string, quality int) (*Flashcard, error) // quality 0-5, updates interval/ease
 	ListFlashcardReviews(flashcardID string) ([]*FlashcardReview, error)
+	AddFlashcardReview(*FlashcardReview) error // records a past review as is, without rescheduling
 	GetDueFlashcards(now time.Time) ([]*Flashcard, error)
 
+	// Document review schedules, for re-reading whole documents (see ReviewDocument)
+	GetDocumentReview(documentID string) (*DocumentReview, error) // nil if never reviewed
+	SaveDocumentReview(*DocumentReview) error                     // replaces the document's schedule
+	ListDocumentReviews() ([]*DocumentReview, error)              // soonest due first
+	DeleteDocumentReview(documentID string) error
+
 	// Task operations (Phase 3)
 	AddTask(*Task) error
 	GetTask(id string) (*Task, error)
@@ -57,9 +83,63 @@ type LibraryStore interface {
 	UpdateTask(*Task) error
 	DeleteTask(id string) error
 
+	// Reading queue operations: the pinned to-read order, head first
+	GetReadingQueue() ([]string, error)
+	SetReadingQueue(documentIDs []string) error
+
+	// Operation journal, for undo
+	RecordOperation(*Operation) error
+	ListOperations(limit int) ([]*Operation, error) // newest first; limit <= 0 for all
+	DeleteOperation(id string) error
+
+	// Custom field schema, for values kept in Document.Meta
+	DefineField(*FieldDef) error      // creates or replaces the field of that name
+	ListFields() ([]*FieldDef, error) // sorted by name
+	DeleteField(name string) error
+
+	// Full-text MinHash signatures, cached for near-duplicate detection
+	ListTextSignatures() ([]*TextSignature, error)
+	SaveTextSignature(*TextSignature) error // replaces the document's signature
+
+	// Full-text section structure, cached for AI context and section search
+	GetDocumentSections(documentID string) (*DocumentSections, error) // nil before the first computation
+	SaveDocumentSections(*DocumentSections) error                     // replaces the document's sections
+
+	// Bibliography parsed from the full text's references section
+	GetDocumentReferences(documentID string) (*DocumentReferences, error) // nil before the first parse
+	SaveDocumentReferences(*DocumentReferences) error                     // replaces the document's references
+
+	// Zotero sync state, one per synced Zotero library
+	GetZoteroSyncState(library string) (*ZoteroSyncState, error) // nil before the first sync
+	SaveZoteroSyncState(*ZoteroSyncState) error
+
+	// Share links, each giving access to one document
+	CreateShare(*Share) error
+	GetShare(token string) (*Share, error) // nil if there is no such share
+	ListShares() ([]*Share, error)         // newest first, expired ones included
+	DeleteShare(token string) error
+
+	// API tokens for the web server
+	CreateAPIToken(*APIToken) error      // fails if the name is taken
+	ListAPITokens() ([]*APIToken, error) // oldest first
+	DeleteAPIToken(id string) error
+
+	// Audit log of changes, append-only; AuditedStore writes it
+	AppendAuditEntry(*AuditEntry) error
+	ListAuditEntries(opts *AuditListOptions) ([]*AuditEntry, error) // newest first
+
 	// SavedSearch operations
 	SaveSearch(*SavedSearch) error
 	GetSavedSearch(idOrName string) (*SavedSearch, error)
 	ListSavedSearches() ([]*SavedSearch, error)
 	DeleteSavedSearch(id string) error
 }
+
+// Capabilities are the features a LibraryStore backend may lack. Commands
+// check them to skip or refuse what the backend can't do, rather than
+// fail partway through; the operations of a missing feature return
+// ErrBackendUnsupported.
+type Capabilities struct {
+	Tasks         bool `json:"tasks"`          // the task operations
+	SavedSearches bool `json:"saved_searches"` // the saved search operations
+}
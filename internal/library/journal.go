@@ -0,0 +1,332 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// OperationKind identifies an undoable operation in the journal.
+type OperationKind string
+
+const (
+	OpDeleteDocument   OperationKind = "delete-document"
+	OpDeleteCollection OperationKind = "delete-collection"
+	OpDeleteFlashcard  OperationKind = "delete-flashcard"
+	OpTagChange        OperationKind = "tag-change"
+)
+
+// JournalLimit is how many operations the journal keeps; older ones can no
+// longer be undone.
+const JournalLimit = 50
+
+// Operation is one journaled destructive action. Data holds what is needed
+// to reverse it: a snapshot of deleted records, or for tag changes the
+// revision each document was at beforehand.
+type Operation struct {
+	ID        string          `json:"id" yaml:"id"`
+	Kind      OperationKind   `json:"kind" yaml:"kind"`
+	Summary   string          `json:"summary" yaml:"summary"`
+	Data      json.RawMessage `json:"data" yaml:"data"`
+	CreatedAt time.Time       `json:"created_at" yaml:"created_at"`
+}
+
+// DocumentSnapshot is a deleted document with the records that go with it.
+type DocumentSnapshot struct {
+	Document      *Document       `json:"document"`
+	Annotations   []*Annotation   `json:"annotations,omitempty"`
+	Flashcards    []*Flashcard    `json:"flashcards,omitempty"`
+	CollectionIDs []string        `json:"collection_ids,omitempty"`
+	Links         []*DocumentLink `json:"links,omitempty"`
+}
+
+// CollectionSnapshot is a deleted collection and the tasks filed under it.
+type CollectionSnapshot struct {
+	Collection *Collection `json:"collection"`
+	TaskIDs    []string    `json:"task_ids,omitempty"`
+}
+
+// tagChange maps each document touched by a tag change to its latest
+// revision before the change.
+type tagChange struct {
+	Revisions map[string]int `json:"revisions"`
+}
+
+// DeleteDocumentUndoable deletes a document after journaling a snapshot of
+// it, its annotations, flashcards, collection memberships, and links. Its
+// metadata history outlives the delete; reading sessions are not restored.
+func DeleteDocumentUndoable(s LibraryStore, id string) (*Document, error) {
+	doc, err := s.GetDocument(id)
+	if err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		return nil, fmt.Errorf("%w: %s", ErrDocumentNotFound, id)
+	}
+
+	// Related records are best-effort; a backend lacking one has none to lose
+	snap := DocumentSnapshot{Document: doc}
+	snap.Annotations, _ = s.GetAnnotations(id)
+	snap.Flashcards, _ = s.ListFlashcards(&FlashcardListOptions{DocumentID: id})
+	snap.Links, _ = s.ListDocumentLinks(id)
+	if colls, err := s.ListCollections(); err == nil {
+		for _, c := range colls {
+			for _, docID := range c.DocumentIDs {
+				if docID == id {
+					snap.CollectionIDs = append(snap.CollectionIDs, c.ID)
+					break
+				}
+			}
+		}
+	}
+
+	if err := journal(s, OpDeleteDocument, "delete document "+doc.Title, snap); err != nil {
+		return nil, err
+	}
+	return doc, s.DeleteDocument(id)
+}
+
+// DeleteCollectionUndoable deletes a collection after journaling it. Undo
+// recreates it under a new ID with the same name, documents, and tasks.
+func DeleteCollectionUndoable(s LibraryStore, idOrName string) (*Collection, error) {
+	c, err := s.GetCollection(idOrName)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return nil, fmt.Errorf("%w: %s", ErrCollectionNotFound, idOrName)
+	}
+
+	snap := CollectionSnapshot{Collection: c}
+	if tasks, err := s.ListTasks(&TaskListOptions{CollectionID: c.ID}); err == nil {
+		for _, t := range tasks {
+			snap.TaskIDs = append(snap.TaskIDs, t.ID)
+		}
+	}
+
+	if err := journal(s, OpDeleteCollection, "delete collection "+c.Name, snap); err != nil {
+		return nil, err
+	}
+	return c, s.DeleteCollection(c.ID)
+}
+
+// DeleteFlashcardUndoable deletes a flashcard after journaling it. Undo
+// restores the card with its schedule but not its review log.
+func DeleteFlashcardUndoable(s LibraryStore, id string) (*Flashcard, error) {
+	card, err := s.GetFlashcard(id)
+	if err != nil {
+		return nil, err
+	}
+	if card == nil {
+		return nil, fmt.Errorf("%w: %s", ErrFlashcardNotFound, id)
+	}
+
+	if err := journal(s, OpDeleteFlashcard, "delete flashcard "+card.Front, card); err != nil {
+		return nil, err
+	}
+	return card, s.DeleteFlashcard(id)
+}
+
+// ChangeTagsUndoable runs change, which adds or removes tags on the given
+// documents, and journals it so undo can restore their previous tags. It
+// relies on the revisions UpdateDocument records; other fields changed
+// since are left alone.
+func ChangeTagsUndoable(s LibraryStore, summary string, documentIDs []string, change func() error) error {
+	tc := tagChange{Revisions: make(map[string]int, len(documentIDs))}
+	for _, id := range documentIDs {
+		revs, err := s.ListDocumentRevisions(id)
+		if err != nil {
+			return err
+		}
+		tc.Revisions[id] = latestRevision(revs)
+	}
+
+	if err := change(); err != nil {
+		return err
+	}
+	return journal(s, OpTagChange, summary, tc)
+}
+
+// Undo reverses the most recent journaled operation and removes it from
+// the journal. It returns nil when there is nothing to undo.
+func Undo(s LibraryStore) (*Operation, error) {
+	ops, err := s.ListOperations(1)
+	if err != nil {
+		return nil, err
+	}
+	if len(ops) == 0 {
+		return nil, nil
+	}
+	op := ops[0]
+
+	switch op.Kind {
+	case OpDeleteDocument:
+		var snap DocumentSnapshot
+		if err := json.Unmarshal(op.Data, &snap); err != nil {
+			return nil, fmt.Errorf("read journal entry: %w", err)
+		}
+		err = restoreDocument(s, &snap)
+	case OpDeleteCollection:
+		var snap CollectionSnapshot
+		if err := json.Unmarshal(op.Data, &snap); err != nil {
+			return nil, fmt.Errorf("read journal entry: %w", err)
+		}
+		err = restoreCollection(s, &snap)
+	case OpDeleteFlashcard:
+		var card Flashcard
+		if err := json.Unmarshal(op.Data, &card); err != nil {
+			return nil, fmt.Errorf("read journal entry: %w", err)
+		}
+		if existing, _ := s.GetFlashcard(card.ID); existing == nil {
+			err = s.AddFlashcard(&card)
+		}
+	case OpTagChange:
+		var tc tagChange
+		if err := json.Unmarshal(op.Data, &tc); err != nil {
+			return nil, fmt.Errorf("read journal entry: %w", err)
+		}
+		err = restoreTags(s, tc)
+	default:
+		err = fmt.Errorf("cannot undo %q operations", op.Kind)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("undo %s: %w", op.Summary, err)
+	}
+
+	return op, s.DeleteOperation(op.ID)
+}
+
+// restoreDocument re-adds a deleted document and its related records,
+// skipping any that survived the delete.
+func restoreDocument(s LibraryStore, snap *DocumentSnapshot) error {
+	doc := snap.Document
+	if existing, err := s.GetDocument(doc.ID); err != nil {
+		return err
+	} else if existing != nil {
+		return fmt.Errorf("document %s already exists", doc.ID)
+	}
+	if err := s.AddDocument(doc); err != nil {
+		return err
+	}
+
+	kept := make(map[string]bool)
+	if anns, err := s.GetAnnotations(doc.ID); err == nil {
+		for _, a := range anns {
+			kept[a.ID] = true
+		}
+	}
+	for _, a := range snap.Annotations {
+		if !kept[a.ID] {
+			if err := s.AddAnnotation(a); err != nil {
+				return fmt.Errorf("restore annotation: %w", err)
+			}
+		}
+	}
+	for _, card := range snap.Flashcards {
+		if existing, _ := s.GetFlashcard(card.ID); existing == nil {
+			if err := s.AddFlashcard(card); err != nil {
+				return fmt.Errorf("restore flashcard: %w", err)
+			}
+		}
+	}
+	for _, collID := range snap.CollectionIDs {
+		// The collection may have been deleted since; membership is best-effort
+		_ = s.AddToCollection(collID, doc.ID)
+	}
+	for _, l := range snap.Links {
+		other := l.ToID
+		if other == doc.ID {
+			other = l.FromID
+		}
+		if d, _ := s.GetDocument(other); d != nil {
+			_ = s.AddDocumentLink(l)
+		}
+	}
+	return nil
+}
+
+func restoreCollection(s LibraryStore, snap *CollectionSnapshot) error {
+	old := snap.Collection
+	c, err := s.CreateCollection(old.Name, old.Description)
+	if err != nil {
+		return err
+	}
+	if old.Public {
+		if err := s.SetCollectionPublic(c.ID, true); err != nil {
+			return err
+		}
+	}
+	for _, docID := range old.DocumentIDs {
+		if d, _ := s.GetDocument(docID); d != nil {
+			if err := s.AddToCollection(c.ID, docID); err != nil {
+				return err
+			}
+		}
+	}
+	for _, taskID := range snap.TaskIDs {
+		t, err := s.GetTask(taskID)
+		if err != nil || t == nil || t.CollectionID != old.ID {
+			continue
+		}
+		t.CollectionID = c.ID
+		t.UpdatedAt = time.Now()
+		if err := s.UpdateTask(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreTags sets each document's tags back to those at its recorded
+// revision.
+func restoreTags(s LibraryStore, tc tagChange) error {
+	for id, rev := range tc.Revisions {
+		doc, err := s.GetDocument(id)
+		if err != nil {
+			return err
+		}
+		if doc == nil {
+			continue // deleted since; nothing to restore
+		}
+		revs, err := s.ListDocumentRevisions(id)
+		if err != nil {
+			return err
+		}
+		if rev > latestRevision(revs) {
+			continue // history lost, e.g. an older backend; leave the tags as they are
+		}
+		before, err := RevertDocument(doc, revs, rev)
+		if err != nil {
+			return err
+		}
+		doc.Tags = before.Tags
+		if err := s.UpdateDocument(doc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// journal records an operation and trims the journal to JournalLimit.
+func journal(s LibraryStore, kind OperationKind, summary string, data any) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("marshal journal entry: %w", err)
+	}
+	op := &Operation{Kind: kind, Summary: summary, Data: raw}
+	if err := s.RecordOperation(op); err != nil {
+		return fmt.Errorf("record operation: %w", err)
+	}
+
+	ops, err := s.ListOperations(0)
+	if err != nil {
+		return nil // trimming is housekeeping; the operation is recorded
+	}
+	for _, old := range ops[min(len(ops), JournalLimit):] {
+		_ = s.DeleteOperation(old.ID)
+	}
+	return nil
+}
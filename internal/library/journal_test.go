@@ -0,0 +1,86 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+func TestUndo(t *testing.T) {
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if op, err := Undo(s); err != nil || op != nil {
+		t.Fatalf("Undo on empty journal = %v, %v; want nil, nil", op, err)
+	}
+
+	for _, id := range []string{"d1", "d2"} {
+		if err := s.AddDocument(&Document{ID: id, Title: id, Path: "/" + id + ".pdf", Tags: []string{"ml"}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.AddAnnotation(&Annotation{ID: "a1", DocumentID: "d1", Content: "note"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddDocumentLink(&DocumentLink{FromID: "d1", ToID: "d2", Relation: LinkSupersedes}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddFlashcard(&Flashcard{ID: "f1", DocumentID: "d2", Front: "Q", Back: "A"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Tag change, then a delete on top: undo runs newest first
+	err = ChangeTagsUndoable(s, "tag d1 +nlp", []string{"d1"}, func() error {
+		doc, _ := s.GetDocument("d1")
+		doc.Tags = append(doc.Tags, "nlp")
+		return s.UpdateDocument(doc)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DeleteDocumentUndoable(s, "d1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DeleteFlashcardUndoable(s, "f1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if op, err := Undo(s); err != nil || op.Kind != OpDeleteFlashcard {
+		t.Fatalf("first undo = %+v, %v; want flashcard delete", op, err)
+	}
+	if card, _ := s.GetFlashcard("f1"); card == nil || card.Front != "Q" {
+		t.Errorf("flashcard not restored: %+v", card)
+	}
+
+	if op, err := Undo(s); err != nil || op.Kind != OpDeleteDocument {
+		t.Fatalf("second undo = %+v, %v; want document delete", op, err)
+	}
+	doc, _ := s.GetDocument("d1")
+	if doc == nil || !reflect.DeepEqual(doc.Tags, []string{"ml", "nlp"}) {
+		t.Fatalf("restored document = %+v", doc)
+	}
+	if anns, _ := s.GetAnnotations("d1"); len(anns) != 1 {
+		t.Errorf("got %d annotations after restore, want 1", len(anns))
+	}
+	if links, _ := s.ListDocumentLinks("d1"); len(links) != 1 {
+		t.Errorf("got %d links after restore, want 1", len(links))
+	}
+
+	// History survives the delete, so the older tag change still undoes
+	if op, err := Undo(s); err != nil || op.Kind != OpTagChange {
+		t.Fatalf("third undo = %+v, %v; want tag change", op, err)
+	}
+	if doc, _ := s.GetDocument("d1"); !reflect.DeepEqual(doc.Tags, []string{"ml"}) {
+		t.Errorf("tags after undo = %v, want [ml]", doc.Tags)
+	}
+
+	if ops, _ := s.ListOperations(0); len(ops) != 0 {
+		t.Errorf("journal has %d operations left, want 0", len(ops))
+	}
+}
@@ -0,0 +1,32 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"encoding/json"
+	"io"
+)
+
+func init() { RegisterExporter(jsonExporter{}) }
+
+// jsonExporter writes the documents as they are stored.
+type jsonExporter struct{}
+
+func (jsonExporter) Format() Format {
+	return Format{
+		Name:         "json",
+		Description:  "The documents as JSON, for custom processing",
+		Extensions:   []string{".json"},
+		Capabilities: []string{FormatTags, FormatFiles, FormatAllFields},
+	}
+}
+
+func (jsonExporter) Export(w io.Writer, _ LibraryStore, docs []*Document, _ ExportOptions) error {
+	data, err := json.MarshalIndent(docs, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
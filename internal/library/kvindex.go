@@ -0,0 +1,317 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+// IndexStats reports what rebuilding the library's indexes did.
+type IndexStats struct {
+	Documents int `json:"documents"` // documents indexed
+	Indexes   int `json:"indexes"`   // SQL indexes, or the KV store's filter indexes, rebuilt
+}
+
+// kvFilterIndexVersion is the version of the filter indexes' layout;
+// libraries with another are read as if they had none.
+const kvFilterIndexVersion = 1
+
+// kvFilterIndexes is the registry of the KV store's filter indexes: for
+// each tag (lower-cased), status, type, and source, the IDs of the
+// documents with it, so filtered listings read only the documents that
+// match. The KV store cannot enumerate keys, so the registry lists them
+// for RebuildIndexes to clear. Libraries from before these indexes have no
+// registry, and ListDocuments reads every document until they are built.
+type kvFilterIndexes struct {
+	Version int      `json:"version"`
+	Keys    []string `json:"keys"` // as filterKey returns them, e.g. "tag:ml"
+}
+
+// filterKey names the filter index of documents whose field is value.
+func filterKey(field, value string) string {
+	return field + ":" + value
+}
+
+// documentFilterKeys returns the filter indexes doc belongs in.
+func documentFilterKeys(doc *Document) []string {
+	status := doc.Status
+	if status == "" {
+		status = StatusUnread
+	}
+	keys := []string{filterKey("status", string(status)), filterKey("type", string(doc.Type))}
+	if doc.Source != "" {
+		keys = append(keys, filterKey("source", doc.Source))
+	}
+	for _, t := range doc.Tags {
+		if k := filterKey("tag", strings.ToLower(t)); !slices.Contains(keys, k) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// optionFilterKeys returns the filter indexes holding every document opts
+// can match, of which the documents must be in all.
+func optionFilterKeys(opts *ListOptions) []string {
+	var keys []string
+	if opts.Tag != "" {
+		keys = append(keys, filterKey("tag", strings.ToLower(opts.Tag)))
+	}
+	if opts.Status != "" {
+		keys = append(keys, filterKey("status", opts.Status))
+	}
+	if opts.Type != "" {
+		keys = append(keys, filterKey("type", opts.Type))
+	}
+	if opts.Source != "" {
+		keys = append(keys, filterKey("source", opts.Source))
+	}
+	return keys
+}
+
+func (s *KVStore) filterIndexKey(key string) string {
+	return s.generateKey("index", "doc:"+key)
+}
+
+func (s *KVStore) loadFilterIndexes() (*kvFilterIndexes, error) {
+	data, err := s.kv.Get(context.Background(), s.generateKey("index", "filters"))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var reg kvFilterIndexes
+	if err := json.Unmarshal(data, &reg); err != nil || reg.Version != kvFilterIndexVersion {
+		return nil, nil
+	}
+	return &reg, nil
+}
+
+func (s *KVStore) saveFilterIndexes(reg *kvFilterIndexes) error {
+	data, err := json.Marshal(reg)
+	if err != nil {
+		return err
+	}
+	return s.kv.Set(context.Background(), s.generateKey("index", "filters"), data)
+}
+
+func (s *KVStore) getFilterIndex(key string) ([]string, error) {
+	data, err := s.kv.Get(context.Background(), s.filterIndexKey(key))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var ids []string
+	if err := json.Unmarshal(data, &ids); err != nil {
+		return nil, fmt.Errorf("unmarshal %s index: %w", key, err)
+	}
+	return ids, nil
+}
+
+// filterCandidates returns the IDs of the documents that can match opts,
+// by its filter indexes, or false when opts filters by none of them or
+// the library's filter indexes aren't built.
+func (s *KVStore) filterCandidates(opts *ListOptions) (map[string]bool, bool, error) {
+	keys := optionFilterKeys(opts)
+	if len(keys) == 0 {
+		return nil, false, nil
+	}
+	reg, err := s.loadFilterIndexes()
+	if err != nil || reg == nil {
+		return nil, false, err
+	}
+	var candidates map[string]bool
+	for _, key := range keys {
+		ids, err := s.getFilterIndex(key)
+		if err != nil {
+			return nil, false, err
+		}
+		in := make(map[string]bool, len(ids))
+		for _, id := range ids {
+			if candidates == nil || candidates[id] {
+				in[id] = true
+			}
+		}
+		candidates = in
+	}
+	return candidates, true, nil
+}
+
+// filterIndexEdits are the changes to make to filter indexes, by key.
+type filterIndexEdits map[string]*struct{ add, remove []string }
+
+// document notes moving a document from the indexes of old to those of
+// doc; either may be nil, for a document added or deleted.
+func (e filterIndexEdits) document(old, doc *Document) {
+	var before, after []string
+	id := ""
+	if old != nil {
+		before, id = documentFilterKeys(old), old.ID
+	}
+	if doc != nil {
+		after, id = documentFilterKeys(doc), doc.ID
+	}
+	edit := func(key string) *struct{ add, remove []string } {
+		if e[key] == nil {
+			e[key] = &struct{ add, remove []string }{}
+		}
+		return e[key]
+	}
+	for _, k := range before {
+		if !slices.Contains(after, k) {
+			edit(k).remove = append(edit(k).remove, id)
+		}
+	}
+	for _, k := range after {
+		if !slices.Contains(before, k) {
+			edit(k).add = append(edit(k).add, id)
+		}
+	}
+}
+
+// updateFilterIndexes applies edits, registering indexes made for the
+// first time. Libraries whose filter indexes aren't built are left alone
+// until RebuildIndexes builds them all.
+func (s *KVStore) updateFilterIndexes(edits filterIndexEdits) error {
+	if len(edits) == 0 {
+		return nil
+	}
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+	reg, err := s.loadFilterIndexes()
+	if err != nil || reg == nil {
+		return err
+	}
+	ctx := context.Background()
+	keys := make([]string, 0, len(edits))
+	for k := range edits {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var created []string
+	for _, key := range keys {
+		edit := edits[key]
+		ids, err := s.getFilterIndex(key)
+		if err != nil {
+			return err
+		}
+		if ids == nil && len(edit.add) > 0 {
+			created = append(created, key)
+		}
+		ids = slices.DeleteFunc(ids, func(id string) bool { return slices.Contains(edit.remove, id) })
+		ids, _ = appendMissing(ids, edit.add)
+		if len(ids) == 0 {
+			if err := s.kv.Delete(ctx, s.filterIndexKey(key)); err != nil && !errors.Is(err, store.ErrNotFound) {
+				return err
+			}
+			continue
+		}
+		data, _ := json.Marshal(ids)
+		if err := s.kv.Set(ctx, s.filterIndexKey(key), data); err != nil {
+			return err
+		}
+	}
+
+	var added bool
+	if reg.Keys, added = appendMissing(reg.Keys, created); !added {
+		return nil
+	}
+	return s.saveFilterIndexes(reg)
+}
+
+// RebuildIndexes rebuilds every index from the documents: the filter
+// indexes ListDocuments uses (building them for libraries from before
+// them), the path and source lookups, and the counters. The new filter
+// indexes are built before they replace the old, and the registry is
+// written last, so one that fails part-way leaves the old ones usable.
+func (s *KVStore) RebuildIndexes() (*IndexStats, error) {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+	ctx := context.Background()
+	ids, err := s.getDocumentIndex()
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return nil, err
+	}
+	old, err := s.loadFilterIndexes()
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &IndexStats{}
+	filters := map[string][]string{}
+	var present []string
+	for _, id := range ids {
+		doc, err := s.GetDocument(id)
+		if err != nil {
+			return stats, err
+		}
+		if doc == nil {
+			continue // in the index but gone; dropped from it below
+		}
+		present = append(present, id)
+		for _, key := range documentFilterKeys(doc) {
+			filters[key] = append(filters[key], id)
+		}
+		if doc.Path != "" {
+			if err := s.kv.Set(ctx, s.generateKey("doc:path", doc.Path), []byte(id)); err != nil {
+				return stats, err
+			}
+		}
+		if doc.Source != "" && doc.SourceID != "" {
+			if err := s.kv.Set(ctx, s.generateKey("doc:source", doc.Source+":"+doc.SourceID), []byte(id)); err != nil {
+				return stats, err
+			}
+		}
+		stats.Documents++
+	}
+	if len(present) < len(ids) {
+		data, _ := json.Marshal(present)
+		if err := s.kv.Set(ctx, s.generateKey("index", "documents"), data); err != nil {
+			return stats, err
+		}
+	}
+
+	// Write the new indexes over the old, then drop the old ones no
+	// document is in any more; until the registry is saved it still lists
+	// those, for a rebuild after a failure to clear
+	reg := &kvFilterIndexes{Version: kvFilterIndexVersion, Keys: make([]string, 0, len(filters))}
+	for key, ids := range filters {
+		data, _ := json.Marshal(ids)
+		if err := s.kv.Set(ctx, s.filterIndexKey(key), data); err != nil {
+			return stats, err
+		}
+		reg.Keys = append(reg.Keys, key)
+	}
+	sort.Strings(reg.Keys)
+	if old != nil {
+		for _, key := range old.Keys {
+			if _, ok := filters[key]; ok {
+				continue
+			}
+			if err := s.kv.Delete(ctx, s.filterIndexKey(key)); err != nil && !errors.Is(err, store.ErrNotFound) {
+				return stats, err
+			}
+		}
+	}
+	if err := s.saveFilterIndexes(reg); err != nil {
+		return stats, err
+	}
+	if _, err := s.rebuildCounters(); err != nil {
+		return stats, err
+	}
+	stats.Indexes = len(reg.Keys)
+	return stats, nil
+}
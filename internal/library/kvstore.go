@@ -4,73 +4,153 @@
 package library
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/mtreilly/arc-library/internal/scheduler"
 	"github.com/yourorg/arc-sdk/store"
 )
 
 // KVStore implements the LibraryStore interface using arc-sdk/store.KVStore.
 type KVStore struct {
 	kv store.KVStore
+
+	// countersMu serialises updates of the stored kvCounters, and indexMu
+	// those of the document and filter indexes; each is read, changed
+	// and written back
+	countersMu sync.Mutex
+	indexMu    sync.Mutex
 }
 
 // NewKVStore creates a new library store backed by the given KVStore.
 func NewKVStore(kv store.KVStore) (*KVStore, error) {
 	s := &KVStore{kv: kv}
+	// A new library has its filter indexes from the start; older ones get
+	// them from RebuildIndexes
+	if _, err := s.getDocumentIndex(); errors.Is(err, store.ErrNotFound) {
+		if reg, err := s.loadFilterIndexes(); err != nil {
+			return nil, err
+		} else if reg == nil {
+			if err := s.saveFilterIndexes(&kvFilterIndexes{Version: kvFilterIndexVersion, Keys: []string{}}); err != nil {
+				return nil, err
+			}
+		}
+	}
 	return s, nil
 }
 
+// Capabilities reports the KV store's features; it has no tasks yet.
+func (s *KVStore) Capabilities() Capabilities {
+	return Capabilities{SavedSearches: true}
+}
+
 // generateKey creates namespaced keys for different entity types.
 func (s *KVStore) generateKey(prefix, id string) string {
 	return fmt.Sprintf("arc-library:%s:%s", prefix, id)
 }
 
+// lastKVID is the last time kvID used, as nanosecond clocks can repeat
+// when many records are added at once.
+var lastKVID atomic.Int64
+
+// kvID returns a new ID of the form prefix:<unix nanoseconds>, distinct
+// from every other it returned.
+func kvID(prefix string) string {
+	for {
+		last, now := lastKVID.Load(), time.Now().UnixNano()
+		if now <= last {
+			now = last + 1
+		}
+		if lastKVID.CompareAndSwap(last, now) {
+			return fmt.Sprintf("%s:%d", prefix, now)
+		}
+	}
+}
+
 // Document operations
 
 func (s *KVStore) AddDocument(doc *Document) error {
-	if doc.ID == "" {
-		doc.ID = fmt.Sprintf("doc:%d", time.Now().UnixNano())
-	}
-	now := time.Now()
-	doc.CreatedAt = now
-	doc.UpdatedAt = now
+	return s.AddDocuments([]*Document{doc})
+}
 
-	data, err := json.Marshal(doc)
-	if err != nil {
-		return fmt.Errorf("marshal document: %w", err)
+// AddDocuments adds documents, updating the document index and counters
+// once for all of them rather than once each.
+func (s *KVStore) AddDocuments(docs []*Document) error {
+	if len(docs) == 0 {
+		return nil
 	}
-
 	ctx := context.Background()
-	key := s.generateKey("doc", doc.ID)
-	if err := s.kv.Set(ctx, key, data); err != nil {
-		return fmt.Errorf("set document: %w", err)
-	}
+	now := time.Now()
+	var replaced []*Document
+	ids := make([]string, 0, len(docs))
+	filters := filterIndexEdits{}
+	for _, doc := range docs {
+		var prev *Document
+		if doc.ID == "" {
+			doc.ID = kvID("doc")
+		} else if prev, _ = s.GetDocument(doc.ID); prev != nil {
+			// Re-adding an existing ID replaces it; counters must not count it twice
+			replaced = append(replaced, prev)
+		}
+		if doc.CreatedAt.IsZero() {
+			// Restored documents keep their original creation time
+			doc.CreatedAt = now
+		}
+		doc.UpdatedAt = now
 
-	// Index by path for deduplication
-	if doc.Path != "" {
-		if err := s.kv.Set(ctx, s.generateKey("doc:path", doc.Path), []byte(doc.ID)); err != nil {
-			// Log but don't fail - indices can be rebuilt
+		data, err := marshalDocument(doc)
+		if err != nil {
+			return fmt.Errorf("marshal document: %w", err)
 		}
-	}
+		key := s.generateKey("doc", doc.ID)
+		if err := s.kv.Set(ctx, key, data); err != nil {
+			return fmt.Errorf("set document: %w", err)
+		}
+		ids = append(ids, doc.ID)
+		filters.document(prev, doc)
 
-	// Index by source+source_id if present
-	if doc.Source != "" && doc.SourceID != "" {
-		sourceKey := fmt.Sprintf("%s:%s", doc.Source, doc.SourceID)
-		if err := s.kv.Set(ctx, s.generateKey("doc:source", sourceKey), []byte(doc.ID)); err != nil {
-			// Log but don't fail
+		// Index by path for deduplication
+		if doc.Path != "" {
+			if err := s.kv.Set(ctx, s.generateKey("doc:path", doc.Path), []byte(doc.ID)); err != nil {
+				// Log but don't fail - indices can be rebuilt
+			}
+		}
+
+		// Index by source+source_id if present
+		if doc.Source != "" && doc.SourceID != "" {
+			sourceKey := fmt.Sprintf("%s:%s", doc.Source, doc.SourceID)
+			if err := s.kv.Set(ctx, s.generateKey("doc:source", sourceKey), []byte(doc.ID)); err != nil {
+				// Log but don't fail
+			}
 		}
 	}
 
 	// Add to main document index
-	if err := s.addToDocumentIndex(doc.ID); err != nil {
-		// Log but don't fail
+	if err := s.addToDocumentIndex(ids...); err != nil {
+		return fmt.Errorf("update document index: %w", err)
+	}
+	if err := s.updateFilterIndexes(filters); err != nil {
+		return fmt.Errorf("update filter indexes (run \"index rebuild\"): %w", err)
 	}
 
+	s.adjustCounters(func(c *kvCounters) {
+		for _, prev := range replaced {
+			c.ByType[prev.Type]--
+		}
+		for _, doc := range docs {
+			c.ByType[doc.Type]++
+		}
+	})
+
 	return nil
 }
 
@@ -84,11 +164,47 @@ func (s *KVStore) GetDocument(id string) (*Document, error) {
 		}
 		return nil, err
 	}
-	var d Document
-	if err := json.Unmarshal(data, &d); err != nil {
+	return unmarshalDocument(data)
+}
+
+// kvDocument is a document as the KV store keeps it, with its full text
+// compressed or referring to its file. Documents written before
+// compression, and short texts, have it in FullText.
+type kvDocument struct {
+	*Document
+	FullText       string `json:"full_text,omitempty"`
+	FullTextStored []byte `json:"full_text_zstd,omitempty"`
+}
+
+func marshalDocument(doc *Document) ([]byte, error) {
+	stored := kvDocument{Document: doc}
+	text, encoded, err := encodeFullText(doc.FullText)
+	if err != nil {
+		return nil, err
+	}
+	if encoded {
+		stored.FullTextStored = text
+	} else {
+		stored.FullText = doc.FullText
+	}
+	return json.Marshal(stored)
+}
+
+func unmarshalDocument(data []byte) (*Document, error) {
+	stored := kvDocument{Document: &Document{}}
+	if err := json.Unmarshal(data, &stored); err != nil {
 		return nil, fmt.Errorf("unmarshal document: %w", err)
 	}
-	return &d, nil
+	d := stored.Document
+	d.FullText = stored.FullText
+	if len(stored.FullTextStored) > 0 {
+		text, err := expandFullText(string(stored.FullTextStored))
+		if err != nil {
+			return nil, err
+		}
+		d.FullText = text
+	}
+	return d, nil
 }
 
 func (s *KVStore) GetDocumentByPath(path string) (*Document, error) {
@@ -131,7 +247,21 @@ func (s *KVStore) ListDocuments(opts *ListOptions) ([]*Document, error) {
 	var ids []string
 	if err == nil {
 		if err := json.Unmarshal(idsData, &ids); err != nil {
-			ids = []string{}
+			// An index that can't be read hides everything in it
+			if err := unreadable("index", "documents", err); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Read only the documents the filter indexes say can match
+	if opts != nil {
+		candidates, ok, err := s.filterCandidates(opts)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			ids = slices.DeleteFunc(ids, func(id string) bool { return !candidates[id] })
 		}
 	}
 
@@ -139,6 +269,9 @@ func (s *KVStore) ListDocuments(opts *ListOptions) ([]*Document, error) {
 	for _, id := range ids {
 		doc, err := s.GetDocument(id)
 		if err != nil {
+			if err := unreadable("document", id, err); err != nil {
+				return nil, err
+			}
 			continue
 		}
 		if doc == nil {
@@ -176,31 +309,93 @@ func (s *KVStore) ListDocuments(opts *ListOptions) ([]*Document, error) {
 			if opts.Type != "" && doc.Type != DocumentType(opts.Type) {
 				continue
 			}
+			if !matchesMetadataFilters(doc, opts) {
+				continue
+			}
 		}
 
 		docs = append(docs, doc)
-
-		if opts != nil && opts.Limit > 0 && len(docs) >= opts.Limit {
-			break
-		}
 	}
 
+	// The index is in the order documents were added; list, and limit,
+	// most recently updated first as the SQL store does
+	slices.SortStableFunc(docs, func(a, b *Document) int { return b.UpdatedAt.Compare(a.UpdatedAt) })
+	if opts != nil && opts.Limit > 0 && len(docs) > opts.Limit {
+		docs = docs[:opts.Limit]
+	}
 	return docs, nil
 }
 
+// matchesMetadataFilters applies the status, rating, author and date-range
+// filters of opts to doc, mirroring the SQL store's WHERE clauses.
+func matchesMetadataFilters(doc *Document, opts *ListOptions) bool {
+	if opts.Status != "" {
+		status := doc.Status
+		if status == "" {
+			status = StatusUnread
+		}
+		if status != ReadingStatus(opts.Status) {
+			return false
+		}
+	} else if opts.HideArchived && doc.Status == StatusArchived {
+		return false
+	}
+	if opts.MinRating > 0 && doc.Rating < opts.MinRating {
+		return false
+	}
+	if opts.Author != "" {
+		found := false
+		for _, a := range doc.Authors {
+			if strings.Contains(strings.ToLower(a), strings.ToLower(opts.Author)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if opts.Language != "" && !strings.EqualFold(DocumentLanguage(doc), opts.Language) {
+		return false
+	}
+	for _, f := range opts.Fields {
+		if !f.Match(doc) {
+			return false
+		}
+	}
+	if !opts.CreatedAfter.IsZero() && doc.CreatedAt.Before(opts.CreatedAfter) {
+		return false
+	}
+	if !opts.CreatedBefore.IsZero() && !doc.CreatedAt.Before(opts.CreatedBefore) {
+		return false
+	}
+	if !opts.ReadAfter.IsZero() || !opts.ReadBefore.IsZero() {
+		if doc.ReadAt.IsZero() {
+			return false
+		}
+		if !opts.ReadAfter.IsZero() && doc.ReadAt.Before(opts.ReadAfter) {
+			return false
+		}
+		if !opts.ReadBefore.IsZero() && !doc.ReadAt.Before(opts.ReadBefore) {
+			return false
+		}
+	}
+	return true
+}
+
 func (s *KVStore) UpdateDocument(doc *Document) error {
 	existing, err := s.GetDocument(doc.ID)
 	if err != nil {
 		return err
 	}
 	if existing == nil {
-		return fmt.Errorf("document not found: %s", doc.ID)
+		return fmt.Errorf("%w: %s", ErrDocumentNotFound, doc.ID)
 	}
 
 	doc.CreatedAt = existing.CreatedAt
 	doc.UpdatedAt = time.Now()
 
-	data, err := json.Marshal(doc)
+	data, err := marshalDocument(doc)
 	if err != nil {
 		return fmt.Errorf("marshal document: %w", err)
 	}
@@ -231,7 +426,126 @@ func (s *KVStore) UpdateDocument(doc *Document) error {
 		}
 	}
 
-	return nil
+	filters := filterIndexEdits{}
+	filters.document(existing, doc)
+	if err := s.updateFilterIndexes(filters); err != nil {
+		return fmt.Errorf("update filter indexes (run \"index rebuild\"): %w", err)
+	}
+
+	if existing.Type != doc.Type {
+		s.adjustCounters(func(c *kvCounters) {
+			c.ByType[existing.Type]--
+			c.ByType[doc.Type]++
+		})
+	}
+
+	return s.recordRevision(existing, doc)
+}
+
+// recordRevision appends the fields changed between old and updated to the
+// document's revision list; nothing is stored when nothing changed.
+func (s *KVStore) recordRevision(old, updated *Document) error {
+	changes, err := DiffDocuments(old, updated)
+	if err != nil || len(changes) == 0 {
+		return err
+	}
+	revs, err := s.ListDocumentRevisions(updated.ID)
+	if err != nil {
+		return err
+	}
+	revs = append(revs, &DocumentRevision{
+		ID:         fmt.Sprintf("revision:%d", time.Now().UnixNano()),
+		DocumentID: updated.ID,
+		Rev:        len(revs) + 1,
+		Changes:    changes,
+		CreatedAt:  updated.UpdatedAt,
+	})
+	data, err := json.Marshal(revs)
+	if err != nil {
+		return fmt.Errorf("marshal revisions: %w", err)
+	}
+	return s.kv.Set(context.Background(), s.generateKey("revisions", updated.ID), data)
+}
+
+func (s *KVStore) ListDocumentRevisions(documentID string) ([]*DocumentRevision, error) {
+	data, err := s.kv.Get(context.Background(), s.generateKey("revisions", documentID))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var revs []*DocumentRevision
+	if err := json.Unmarshal(data, &revs); err != nil {
+		return nil, fmt.Errorf("unmarshal revisions: %w", err)
+	}
+	return revs, nil
+}
+
+// Compact stores the full text of every document as it is stored now:
+// compressed, or in files with ARC_LIBRARY_FULLTEXT=external, for text
+// stored before compression or under the other setting, and removes the
+// full text files no document refers to. The sizes it reports are of the
+// stored documents, since the KV store leaves reclaiming space to its
+// backend.
+func (s *KVStore) Compact() (*CompactStats, error) {
+	ids, err := s.getDocumentIndex()
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	stats := &CompactStats{}
+	used := make(map[string]bool)
+	for _, id := range ids {
+		key := s.generateKey("doc", id)
+		data, err := s.kv.Get(ctx, key)
+		if errors.Is(err, store.ErrNotFound) {
+			continue
+		} else if err != nil {
+			return stats, err
+		}
+		stats.SizeBefore += int64(len(data))
+
+		var stored kvDocument
+		if err := json.Unmarshal(data, &stored); err != nil {
+			return stats, fmt.Errorf("unmarshal document %s: %w", id, err)
+		}
+		text := stored.FullText
+		if len(stored.FullTextStored) > 0 {
+			if text, err = expandFullText(string(stored.FullTextStored)); err != nil {
+				return stats, err
+			}
+		}
+		want, encoded, err := encodeFullText(text)
+		if err != nil {
+			return stats, err
+		}
+		// Text whose file is missing reads as empty; its reference is kept
+		moved := encoded && !bytes.Equal(want, stored.FullTextStored) || !encoded && text != "" && len(stored.FullTextStored) > 0
+		if moved {
+			stored.FullText, stored.FullTextStored = "", nil
+			if encoded {
+				stored.FullTextStored = want
+			} else {
+				stored.FullText = text
+			}
+			if data, err = json.Marshal(stored); err != nil {
+				return stats, fmt.Errorf("marshal document: %w", err)
+			}
+			if err := s.kv.Set(ctx, key, data); err != nil {
+				return stats, fmt.Errorf("set document: %w", err)
+			}
+			stats.Documents++
+		}
+		stats.SizeAfter += int64(len(data))
+		stats.FullText += int64(len(text))
+		stats.StoredText += int64(len(stored.FullText) + len(stored.FullTextStored))
+		if hash, ok := fullTextRefHash(stored.FullTextStored); ok {
+			used[hash] = true
+		}
+	}
+	stats.FilesRemoved, err = pruneFullTextFiles(used)
+	return stats, err
 }
 
 func (s *KVStore) DeleteDocument(id string) error {
@@ -260,6 +574,40 @@ func (s *KVStore) DeleteDocument(id string) error {
 		s.DeleteAnnotation(a.ID)
 	}
 
+	// Delete links from or to this document
+	links, _ := s.ListDocumentLinks(id)
+	for _, l := range links {
+		s.DeleteDocumentLink(l.ID)
+	}
+	_ = s.kv.Delete(ctx, s.generateKey("index", "doc:links:"+id))
+	// Revisions are kept so a document restored by undo keeps its history
+
+	// Drop the cached sections, references, and text signature
+	_ = s.kv.Delete(ctx, s.generateKey("sections", id))
+	_ = s.kv.Delete(ctx, s.generateKey("references", id))
+	_ = s.DeleteDocumentReview(id)
+	if shares, err := s.ListShares(); err == nil {
+		if kept := slices.DeleteFunc(shares, func(sh *Share) bool { return sh.DocumentID == id }); len(kept) < len(shares) {
+			_ = s.saveShares(kept)
+		}
+	}
+	if sigs, err := s.textSignatures(); err == nil {
+		if _, ok := sigs[id]; ok {
+			delete(sigs, id)
+			_ = s.saveTextSignatures(sigs)
+		}
+	}
+
+	// Sessions stay stored but are no longer reachable, so drop them from the totals
+	sessions, _ := s.ListSessions(id)
+	s.adjustCounters(func(c *kvCounters) {
+		c.ByType[doc.Type]--
+		c.Sessions -= len(sessions)
+		for _, sess := range sessions {
+			c.PagesRead -= sess.PagesRead
+		}
+	})
+
 	// Delete indices
 	_ = s.kv.Delete(ctx, s.generateKey("doc:path", doc.Path))
 	if doc.Source != "" && doc.SourceID != "" {
@@ -271,6 +619,11 @@ func (s *KVStore) DeleteDocument(id string) error {
 	if err := s.removeFromDocumentIndex(id); err != nil {
 		// Log but continue
 	}
+	filters := filterIndexEdits{}
+	filters.document(doc, nil)
+	if err := s.updateFilterIndexes(filters); err != nil {
+		// Log but continue
+	}
 
 	// Delete document data
 	key := s.generateKey("doc", id)
@@ -279,7 +632,9 @@ func (s *KVStore) DeleteDocument(id string) error {
 
 // Document index maintenance
 
-func (s *KVStore) addToDocumentIndex(docID string) error {
+func (s *KVStore) addToDocumentIndex(docIDs ...string) error {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
 	ctx := context.Background()
 	indexKey := s.generateKey("index", "documents")
 
@@ -288,19 +643,34 @@ func (s *KVStore) addToDocumentIndex(docID string) error {
 		return err
 	}
 
-	// Avoid duplicates
-	for _, id := range ids {
-		if id == docID {
-			return nil
-		}
+	ids, added := appendMissing(ids, docIDs)
+	if !added {
+		return nil
 	}
-
-	ids = append(ids, docID)
 	data, _ := json.Marshal(ids)
 	return s.kv.Set(ctx, indexKey, data)
 }
 
+// appendMissing appends the IDs of add that ids lacks, each once, and
+// reports whether there were any.
+func appendMissing(ids, add []string) ([]string, bool) {
+	have := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		have[id] = true
+	}
+	n := len(ids)
+	for _, id := range add {
+		if !have[id] {
+			have[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids, len(ids) > n
+}
+
 func (s *KVStore) removeFromDocumentIndex(docID string) error {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
 	ctx := context.Background()
 	indexKey := s.generateKey("index", "documents")
 
@@ -345,7 +715,7 @@ func (s *KVStore) AddTag(documentID, tag string) error {
 		return err
 	}
 	if doc == nil {
-		return fmt.Errorf("document not found: %s", documentID)
+		return fmt.Errorf("%w: %s", ErrDocumentNotFound, documentID)
 	}
 
 	// Check if already tagged
@@ -365,7 +735,7 @@ func (s *KVStore) RemoveTag(documentID, tag string) error {
 		return err
 	}
 	if doc == nil {
-		return fmt.Errorf("document not found: %s", documentID)
+		return fmt.Errorf("%w: %s", ErrDocumentNotFound, documentID)
 	}
 
 	newTags := make([]string, 0, len(doc.Tags))
@@ -397,6 +767,11 @@ func (s *KVStore) ListTags() (map[string]int, error) {
 // Collection operations
 
 func (s *KVStore) CreateCollection(name, description string) (*Collection, error) {
+	if existing, err := s.getCollectionByName(name); err != nil {
+		return nil, err
+	} else if existing != nil {
+		return nil, fmt.Errorf("%w: %s", ErrCollectionExists, name)
+	}
 	c := &Collection{
 		ID:          fmt.Sprintf("collection:%d", time.Now().UnixNano()),
 		Name:        name,
@@ -478,7 +853,10 @@ func (s *KVStore) ListCollections() ([]*Collection, error) {
 	var ids []string
 	if err == nil {
 		if err := json.Unmarshal(idsData, &ids); err != nil {
-			ids = []string{}
+			// An index that can't be read hides everything in it
+			if err := unreadable("index", "collections", err); err != nil {
+				return nil, err
+			}
 		}
 	}
 
@@ -486,6 +864,9 @@ func (s *KVStore) ListCollections() ([]*Collection, error) {
 	for _, id := range ids {
 		c, err := s.getCollectionByID(id)
 		if err != nil {
+			if err := unreadable("collection", id, err); err != nil {
+				return nil, err
+			}
 			continue
 		}
 		if c == nil {
@@ -512,7 +893,7 @@ func (s *KVStore) AddToCollection(collectionID, documentID string) error {
 		return err
 	}
 	if c == nil {
-		return fmt.Errorf("collection not found: %s", collectionID)
+		return fmt.Errorf("%w: %s", ErrCollectionNotFound, collectionID)
 	}
 
 	// Check if already in collection
@@ -524,6 +905,10 @@ func (s *KVStore) AddToCollection(collectionID, documentID string) error {
 
 	c.DocumentIDs = append(c.DocumentIDs, documentID)
 	c.UpdatedAt = time.Now()
+	if c.AddedAt == nil {
+		c.AddedAt = make(map[string]time.Time)
+	}
+	c.AddedAt[documentID] = c.UpdatedAt
 
 	ctx := context.Background()
 	key := s.generateKey("collection", c.ID)
@@ -540,7 +925,7 @@ func (s *KVStore) RemoveFromCollection(collectionID, documentID string) error {
 		return err
 	}
 	if c == nil {
-		return fmt.Errorf("collection not found: %s", collectionID)
+		return fmt.Errorf("%w: %s", ErrCollectionNotFound, collectionID)
 	}
 
 	newIDs := make([]string, 0, len(c.DocumentIDs))
@@ -551,6 +936,7 @@ func (s *KVStore) RemoveFromCollection(collectionID, documentID string) error {
 	}
 
 	c.DocumentIDs = newIDs
+	delete(c.AddedAt, documentID)
 	c.UpdatedAt = time.Now()
 
 	ctx := context.Background()
@@ -587,6 +973,26 @@ func (s *KVStore) DeleteCollection(id string) error {
 	return nil
 }
 
+func (s *KVStore) SetCollectionPublic(id string, public bool) error {
+	c, err := s.getCollectionByID(id)
+	if err != nil {
+		return err
+	}
+	if c == nil {
+		return fmt.Errorf("%w: %s", ErrCollectionNotFound, id)
+	}
+	c.Public = public
+	c.UpdatedAt = time.Now()
+
+	ctx := context.Background()
+	key := s.generateKey("collection", c.ID)
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("marshal collection: %w", err)
+	}
+	return s.kv.Set(ctx, key, data)
+}
+
 func (s *KVStore) addToCollectionIndex(collectionID string) error {
 	ctx := context.Background()
 	indexKey := s.generateKey("index", "collections")
@@ -641,25 +1047,54 @@ func (s *KVStore) getCollectionIndex() ([]string, error) {
 // Annotation operations (use DocumentID)
 
 func (s *KVStore) AddAnnotation(ann *Annotation) error {
-	if ann.ID == "" {
-		ann.ID = fmt.Sprintf("annotation:%d", time.Now().UnixNano())
+	return s.AddAnnotations([]*Annotation{ann})
+}
+
+// AddAnnotations adds annotations, updating each document's annotation
+// index once.
+func (s *KVStore) AddAnnotations(anns []*Annotation) error {
+	if len(anns) == 0 {
+		return nil
+	}
+	for _, ann := range anns {
+		if err := validateAnnotation(ann); err != nil {
+			return err
+		}
 	}
-	ann.CreatedAt = time.Now()
 
 	ctx := context.Background()
-	key := s.generateKey("annotation", ann.ID)
-	data, err := json.Marshal(ann)
-	if err != nil {
-		return fmt.Errorf("marshal annotation: %w", err)
-	}
-	if err := s.kv.Set(ctx, key, data); err != nil {
-		return err
+	var docIDs []string
+	byDoc := map[string][]string{}
+	for _, ann := range anns {
+		if ann.ID == "" {
+			ann.ID = kvID("annotation")
+		}
+		if ann.CreatedAt.IsZero() {
+			// Imported and restored annotations keep their original time
+			ann.CreatedAt = time.Now()
+		}
+
+		key := s.generateKey("annotation", ann.ID)
+		data, err := json.Marshal(ann)
+		if err != nil {
+			return fmt.Errorf("marshal annotation: %w", err)
+		}
+		if err := s.kv.Set(ctx, key, data); err != nil {
+			return err
+		}
+		if _, ok := byDoc[ann.DocumentID]; !ok {
+			docIDs = append(docIDs, ann.DocumentID)
+		}
+		byDoc[ann.DocumentID] = append(byDoc[ann.DocumentID], ann.ID)
 	}
 
-	// Add to document's annotation index
-	if err := s.addToDocumentAnnotationsIndex(ann.DocumentID, ann.ID); err != nil {
-		// Log but don't fail
+	// Add to documents' annotation indexes
+	for _, docID := range docIDs {
+		if err := s.addToDocumentAnnotationsIndex(docID, byDoc[docID]...); err != nil {
+			// Log but don't fail
+		}
 	}
+	s.adjustCounters(func(c *kvCounters) { c.Annotations += len(anns) })
 
 	return nil
 }
@@ -677,7 +1112,10 @@ func (s *KVStore) GetAnnotations(documentID string) ([]*Annotation, error) {
 	var ids []string
 	if err == nil {
 		if err := json.Unmarshal(idsData, &ids); err != nil {
-			ids = []string{}
+			// An index that can't be read hides everything in it
+			if err := unreadable("index", "doc:annotations:"+documentID, err); err != nil {
+				return nil, err
+			}
 		}
 	}
 
@@ -693,6 +1131,9 @@ func (s *KVStore) GetAnnotations(documentID string) ([]*Annotation, error) {
 		}
 		var a Annotation
 		if err := json.Unmarshal(data, &a); err != nil {
+			if err := unreadable("annotation", id, err); err != nil {
+				return nil, err
+			}
 			continue
 		}
 		anns = append(anns, &a)
@@ -720,25 +1161,23 @@ func (s *KVStore) DeleteAnnotation(id string) error {
 
 	// Remove from document's annotation index
 	_ = s.removeFromDocumentAnnotationsIndex(a.DocumentID, id)
+	s.adjustCounters(func(c *kvCounters) { c.Annotations-- })
 
 	// Delete annotation
 	return s.kv.Delete(ctx, key)
 }
 
-func (s *KVStore) addToDocumentAnnotationsIndex(documentID, annotationID string) error {
+func (s *KVStore) addToDocumentAnnotationsIndex(documentID string, annotationIDs ...string) error {
 	ctx := context.Background()
 	indexKey := s.generateKey("index", "doc:annotations:"+documentID)
 	ids, err := s.getDocumentAnnotationsIndex(documentID)
 	if err != nil && !errors.Is(err, store.ErrNotFound) {
 		return err
 	}
-	// Avoid duplicates
-	for _, id := range ids {
-		if id == annotationID {
-			return nil
-		}
+	ids, added := appendMissing(ids, annotationIDs)
+	if !added {
+		return nil
 	}
-	ids = append(ids, annotationID)
 	data, _ := json.Marshal(ids)
 	return s.kv.Set(ctx, indexKey, data)
 }
@@ -777,35 +1216,72 @@ func (s *KVStore) getDocumentAnnotationsIndex(documentID string) ([]string, erro
 	return ids, nil
 }
 
-// Reading session operations (Phase 1)
+// Document link operations
 
-func (s *KVStore) StartSession(documentID string) (*ReadingSession, error) {
-	session := &ReadingSession{
-		ID:          fmt.Sprintf("session:%d", time.Now().UnixNano()),
-		DocumentID:  documentID,
-		StartAt:     time.Now(),
+func (s *KVStore) AddDocumentLink(l *DocumentLink) error {
+	existing, err := s.ListDocumentLinks(l.FromID)
+	if err != nil {
+		return err
 	}
-	ctx := context.Background()
-	key := s.generateKey("session", session.ID)
-	data, err := json.Marshal(session)
+	for _, e := range existing {
+		if e.FromID == l.FromID && e.ToID == l.ToID && e.Relation == l.Relation {
+			return fmt.Errorf("link already exists: %s %s %s", l.FromID, l.Relation, l.ToID)
+		}
+	}
+
+	if l.ID == "" {
+		l.ID = fmt.Sprintf("link:%d", time.Now().UnixNano())
+	}
+	l.CreatedAt = time.Now()
+
+	data, err := json.Marshal(l)
 	if err != nil {
-		return nil, fmt.Errorf("marshal session: %w", err)
+		return fmt.Errorf("marshal link: %w", err)
 	}
-	if err := s.kv.Set(ctx, key, data); err != nil {
-		return nil, err
+	if err := s.kv.Set(context.Background(), s.generateKey("link", l.ID), data); err != nil {
+		return err
 	}
-	// Add to document's sessions index
-	if err := s.addToDocumentSessionsIndex(documentID, session.ID); err != nil {
-		// Log but don't fail
+
+	// Index under both ends so either document lists the link
+	for _, docID := range []string{l.FromID, l.ToID} {
+		if err := s.updateDocumentLinksIndex(docID, func(ids []string) []string { return append(ids, l.ID) }); err != nil {
+			// Log but don't fail
+		}
 	}
-	return session, nil
+	return nil
 }
 
-func (s *KVStore) EndSession(sessionID string, pagesRead int, notes string) error {
+func (s *KVStore) ListDocumentLinks(documentID string) ([]*DocumentLink, error) {
 	ctx := context.Background()
+	ids, err := s.getDocumentLinksIndex(documentID)
+	if err != nil {
+		return nil, err
+	}
 
-	// Get session first
-	key := s.generateKey("session", sessionID)
+	var links []*DocumentLink
+	for _, id := range ids {
+		data, err := s.kv.Get(ctx, s.generateKey("link", id))
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				continue // Orphaned index entry - skip
+			}
+			return nil, err
+		}
+		var l DocumentLink
+		if err := json.Unmarshal(data, &l); err != nil {
+			if err := unreadable("link", id, err); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		links = append(links, &l)
+	}
+	return links, nil
+}
+
+func (s *KVStore) DeleteDocumentLink(id string) error {
+	ctx := context.Background()
+	key := s.generateKey("link", id)
 	data, err := s.kv.Get(ctx, key)
 	if err != nil {
 		if errors.Is(err, store.ErrNotFound) {
@@ -813,12 +1289,95 @@ func (s *KVStore) EndSession(sessionID string, pagesRead int, notes string) erro
 		}
 		return err
 	}
-	var session ReadingSession
-	if err := json.Unmarshal(data, &session); err != nil {
-		return fmt.Errorf("unmarshal session: %w", err)
+	var l DocumentLink
+	if err := json.Unmarshal(data, &l); err != nil {
+		return fmt.Errorf("unmarshal link: %w", err)
 	}
 
-	session.EndAt = time.Now()
+	for _, docID := range []string{l.FromID, l.ToID} {
+		_ = s.updateDocumentLinksIndex(docID, func(ids []string) []string {
+			kept := ids[:0]
+			for _, x := range ids {
+				if x != id {
+					kept = append(kept, x)
+				}
+			}
+			return kept
+		})
+	}
+	return s.kv.Delete(ctx, key)
+}
+
+func (s *KVStore) updateDocumentLinksIndex(documentID string, fn func([]string) []string) error {
+	ids, err := s.getDocumentLinksIndex(documentID)
+	if err != nil {
+		return err
+	}
+	data, _ := json.Marshal(fn(ids))
+	return s.kv.Set(context.Background(), s.generateKey("index", "doc:links:"+documentID), data)
+}
+
+// getDocumentLinksIndex returns the IDs of links touching a document; a
+// missing index means no links.
+func (s *KVStore) getDocumentLinksIndex(documentID string) ([]string, error) {
+	data, err := s.kv.Get(context.Background(), s.generateKey("index", "doc:links:"+documentID))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var ids []string
+	if err := json.Unmarshal(data, &ids); err != nil {
+		return nil, fmt.Errorf("unmarshal links index: %w", err)
+	}
+	return ids, nil
+}
+
+// Reading session operations (Phase 1)
+
+func (s *KVStore) StartSession(documentID string) (*ReadingSession, error) {
+	session := &ReadingSession{
+		ID:          fmt.Sprintf("session:%d", time.Now().UnixNano()),
+		DocumentID:  documentID,
+		StartAt:     time.Now(),
+	}
+	ctx := context.Background()
+	key := s.generateKey("session", session.ID)
+	data, err := json.Marshal(session)
+	if err != nil {
+		return nil, fmt.Errorf("marshal session: %w", err)
+	}
+	if err := s.kv.Set(ctx, key, data); err != nil {
+		return nil, err
+	}
+	// Add to document's sessions index
+	if err := s.addToDocumentSessionsIndex(documentID, session.ID); err != nil {
+		// Log but don't fail
+	}
+	s.adjustCounters(func(c *kvCounters) { c.Sessions++ })
+	return session, nil
+}
+
+func (s *KVStore) EndSession(sessionID string, pagesRead int, notes string) error {
+	ctx := context.Background()
+
+	// Get session first
+	key := s.generateKey("session", sessionID)
+	data, err := s.kv.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	var session ReadingSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return fmt.Errorf("unmarshal session: %w", err)
+	}
+
+	prevPages := session.PagesRead
+	session.EndAt = time.Now()
 	session.PagesRead = pagesRead
 	session.Notes = notes
 
@@ -826,7 +1385,11 @@ func (s *KVStore) EndSession(sessionID string, pagesRead int, notes string) erro
 	if err != nil {
 		return fmt.Errorf("marshal session: %w", err)
 	}
-	return s.kv.Set(ctx, key, updatedData)
+	if err := s.kv.Set(ctx, key, updatedData); err != nil {
+		return err
+	}
+	s.adjustCounters(func(c *kvCounters) { c.PagesRead += pagesRead - prevPages })
+	return nil
 }
 
 func (s *KVStore) ListSessions(documentID string) ([]*ReadingSession, error) {
@@ -841,7 +1404,10 @@ func (s *KVStore) ListSessions(documentID string) ([]*ReadingSession, error) {
 	var ids []string
 	if err == nil {
 		if err := json.Unmarshal(idsData, &ids); err != nil {
-			ids = []string{}
+			// An index that can't be read hides everything in it
+			if err := unreadable("index", "doc:sessions:"+documentID, err); err != nil {
+				return nil, err
+			}
 		}
 	}
 
@@ -857,6 +1423,9 @@ func (s *KVStore) ListSessions(documentID string) ([]*ReadingSession, error) {
 		}
 		var sess ReadingSession
 		if err := json.Unmarshal(data, &sess); err != nil {
+			if err := unreadable("session", id, err); err != nil {
+				return nil, err
+			}
 			continue
 		}
 		sessions = append(sessions, &sess)
@@ -905,28 +1474,155 @@ func (s *KVStore) getDocumentSessionsIndex(documentID string) ([]string, error)
 	return ids, nil
 }
 
-// Flashcard operations (Phase 2)
+// Aggregate operations
+
+// kvCounters are running totals kept under a single key so aggregate
+// queries do not have to load every document, annotation and session.
+// They count only records reachable from the document index, and are
+// rebuilt from a full scan whenever the key is missing.
+type kvCounters struct {
+	ByType      map[DocumentType]int `json:"by_type"`
+	Annotations int                  `json:"annotations"`
+	Sessions    int                  `json:"sessions"`
+	PagesRead   int                  `json:"pages_read"`
+}
 
-func (s *KVStore) AddFlashcard(card *Flashcard) error {
-	if card.ID == "" {
-		card.ID = fmt.Sprintf("flashcard:%d", time.Now().UnixNano())
+func (s *KVStore) CountDocumentsByType() (map[DocumentType]int, error) {
+	c, err := s.loadCounters()
+	if err != nil {
+		return nil, err
 	}
-	now := time.Now()
-	card.CreatedAt = now
-	card.UpdatedAt = now
+	counts := make(map[DocumentType]int, len(c.ByType))
+	for t, n := range c.ByType {
+		if n > 0 {
+			counts[t] = n
+		}
+	}
+	return counts, nil
+}
 
+func (s *KVStore) CountAnnotations() (int, error) {
+	c, err := s.loadCounters()
+	if err != nil {
+		return 0, err
+	}
+	return c.Annotations, nil
+}
+
+func (s *KVStore) SessionTotals() (sessions, pagesRead int, err error) {
+	c, err := s.loadCounters()
+	if err != nil {
+		return 0, 0, err
+	}
+	return c.Sessions, c.PagesRead, nil
+}
+
+func (s *KVStore) loadCounters() (*kvCounters, error) {
 	ctx := context.Background()
-	key := s.generateKey("flashcard", card.ID)
-	data, err := json.Marshal(card)
+	data, err := s.kv.Get(ctx, s.generateKey("counters", "library"))
 	if err != nil {
-		return fmt.Errorf("marshal flashcard: %w", err)
+		if errors.Is(err, store.ErrNotFound) {
+			return s.rebuildCounters()
+		}
+		return nil, err
 	}
-	if err := s.kv.Set(ctx, key, data); err != nil {
+	var c kvCounters
+	if err := json.Unmarshal(data, &c); err != nil {
+		return s.rebuildCounters()
+	}
+	return &c, nil
+}
+
+// rebuildCounters recomputes the totals with a full scan and persists them.
+func (s *KVStore) rebuildCounters() (*kvCounters, error) {
+	s.countersMu.Lock()
+	defer s.countersMu.Unlock()
+	docs, err := s.ListDocuments(nil)
+	if err != nil {
+		return nil, err
+	}
+	c := &kvCounters{ByType: make(map[DocumentType]int)}
+	for _, d := range docs {
+		c.ByType[d.Type]++
+		if ids, err := s.getDocumentAnnotationsIndex(d.ID); err == nil {
+			c.Annotations += len(ids)
+		}
+		sessions, _ := s.ListSessions(d.ID)
+		c.Sessions += len(sessions)
+		for _, sess := range sessions {
+			c.PagesRead += sess.PagesRead
+		}
+	}
+	if err := s.saveCounters(c); err != nil {
+		// Log but don't fail - the next read rebuilds again
+	}
+	return c, nil
+}
+
+func (s *KVStore) saveCounters(c *kvCounters) error {
+	data, err := json.Marshal(c)
+	if err != nil {
 		return err
 	}
+	return s.kv.Set(context.Background(), s.generateKey("counters", "library"), data)
+}
+
+// adjustCounters applies fn to the stored totals. When no totals are stored
+// yet nothing is done: the next read rebuilds them, change included.
+func (s *KVStore) adjustCounters(fn func(*kvCounters)) {
+	s.countersMu.Lock()
+	defer s.countersMu.Unlock()
+	data, err := s.kv.Get(context.Background(), s.generateKey("counters", "library"))
+	if err != nil {
+		return
+	}
+	var c kvCounters
+	if err := json.Unmarshal(data, &c); err != nil {
+		return
+	}
+	if c.ByType == nil {
+		c.ByType = make(map[DocumentType]int)
+	}
+	fn(&c)
+	if err := s.saveCounters(&c); err != nil {
+		// Log but don't fail - counters can be rebuilt
+	}
+}
+
+// Flashcard operations (Phase 2)
+
+func (s *KVStore) AddFlashcard(card *Flashcard) error {
+	return s.AddFlashcards([]*Flashcard{card})
+}
+
+// AddFlashcards adds flashcards, updating the flashcard index once.
+func (s *KVStore) AddFlashcards(cards []*Flashcard) error {
+	if len(cards) == 0 {
+		return nil
+	}
+	ctx := context.Background()
+	now := time.Now()
+	ids := make([]string, 0, len(cards))
+	for _, card := range cards {
+		if card.ID == "" {
+			card.ID = kvID("flashcard")
+		}
+		card.CreatedAt = now
+		card.UpdatedAt = now
+
+		key := s.generateKey("flashcard", card.ID)
+		data, err := json.Marshal(card)
+		if err != nil {
+			return fmt.Errorf("marshal flashcard: %w", err)
+		}
+		if err := s.kv.Set(ctx, key, data); err != nil {
+			return err
+		}
+		ids = append(ids, card.ID)
+	}
 
 	// Add to flashcard index
-	if err := s.addToFlashcardIndex(card.ID); err != nil {
+	if err := s.addToFlashcardIndex(ids...); err != nil {
 		// Log but don't fail
 	}
 
@@ -962,7 +1658,10 @@ func (s *KVStore) ListFlashcards(opts *FlashcardListOptions) ([]*Flashcard, erro
 	var ids []string
 	if err == nil {
 		if err := json.Unmarshal(idsData, &ids); err != nil {
-			ids = []string{}
+			// An index that can't be read hides everything in it
+			if err := unreadable("index", "flashcards", err); err != nil {
+				return nil, err
+			}
 		}
 	}
 
@@ -970,6 +1669,9 @@ func (s *KVStore) ListFlashcards(opts *FlashcardListOptions) ([]*Flashcard, erro
 	for _, id := range ids {
 		card, err := s.GetFlashcard(id)
 		if err != nil {
+			if err := unreadable("flashcard", id, err); err != nil {
+				return nil, err
+			}
 			continue
 		}
 		if card == nil {
@@ -993,7 +1695,7 @@ func (s *KVStore) ListFlashcards(opts *FlashcardListOptions) ([]*Flashcard, erro
 					continue
 				}
 			}
-			if opts.Due && card.DueAt.After(time.Now()) {
+			if opts.Due && !IsDue(card.DueAt, Now()) {
 				continue
 			}
 		}
@@ -1023,7 +1725,7 @@ func (s *KVStore) UpdateFlashcard(card *Flashcard) error {
 		return err
 	}
 	if existing == nil {
-		return fmt.Errorf("flashcard not found: %s", card.ID)
+		return fmt.Errorf("%w: %s", ErrFlashcardNotFound, card.ID)
 	}
 
 	card.CreatedAt = existing.CreatedAt
@@ -1056,44 +1758,24 @@ func (s *KVStore) ReviewFlashcard(id string, quality int) (*Flashcard, error) {
 		return nil, err
 	}
 	if card == nil {
-		return nil, fmt.Errorf("flashcard not found: %s", id)
+		return nil, fmt.Errorf("%w: %s", ErrFlashcardNotFound, id)
 	}
 
-	now := time.Now()
+	now := time.Now().UTC()
 
 	// Capture previous values
 	prevInterval := card.Interval
+	sched := scheduler.Current()
 	prevEase := card.Ease
 	if prevEase == 0 {
-		prevEase = 2.5
+		prevEase = sched.InitialEase
 	}
 
-	// SM-2 algorithm
-	ease := prevEase
-	ease = ease + (0.1 - (float64(5-quality)*(0.08+float64(5-quality)*0.02)))
-	if ease < 1.3 {
-		ease = 1.3
-	}
-	if ease > 2.5 {
-		ease = 2.5
-	}
-
-	var interval int
-	if quality < 3 {
-		interval = 1
-	} else {
-		if prevInterval == 0 {
-			interval = 1
-		} else if prevInterval == 1 {
-			interval = 6
-		} else {
-			interval = int(float64(prevInterval) * ease)
-		}
-	}
+	interval, ease := sched.Next(prevInterval, prevEase, quality)
 
 	card.Interval = interval
 	card.Ease = ease
-	card.DueAt = now.AddDate(0, 0, interval)
+	card.DueAt = DueDate(now.In(HomeLocation()), interval)
 	card.LastReview = now
 	card.UpdatedAt = now
 
@@ -1110,6 +1792,7 @@ func (s *KVStore) ReviewFlashcard(id string, quality int) (*Flashcard, error) {
 		ReviewedAt:   now,
 		PrevInterval: prevInterval,
 		PrevEase:     prevEase,
+		Params:       sched.String(),
 	}
 	// Store review (we need a review index)
 	if err := s.addReview(review); err != nil {
@@ -1119,6 +1802,13 @@ func (s *KVStore) ReviewFlashcard(id string, quality int) (*Flashcard, error) {
 	return card, nil
 }
 
+func (s *KVStore) AddFlashcardReview(review *FlashcardReview) error {
+	if review.ID == "" {
+		review.ID = fmt.Sprintf("review:%d", time.Now().UnixNano())
+	}
+	return s.addReview(review)
+}
+
 func (s *KVStore) addReview(review *FlashcardReview) error {
 	ctx := context.Background()
 	key := s.generateKey("review", review.ID)
@@ -1167,7 +1857,10 @@ func (s *KVStore) ListFlashcardReviews(flashcardID string) ([]*FlashcardReview,
 	var ids []string
 	if err == nil {
 		if err := json.Unmarshal(idsData, &ids); err != nil {
-			ids = []string{}
+			// An index that can't be read hides everything in it
+			if err := unreadable("index", "flashcard:reviews:"+flashcardID, err); err != nil {
+				return nil, err
+			}
 		}
 	}
 
@@ -1183,6 +1876,9 @@ func (s *KVStore) ListFlashcardReviews(flashcardID string) ([]*FlashcardReview,
 		}
 		var r FlashcardReview
 		if err := json.Unmarshal(data, &r); err != nil {
+			if err := unreadable("flashcard review", id, err); err != nil {
+				return nil, err
+			}
 			continue
 		}
 		reviews = append(reviews, &r)
@@ -1201,25 +1897,32 @@ func (s *KVStore) ListFlashcardReviews(flashcardID string) ([]*FlashcardReview,
 }
 
 func (s *KVStore) GetDueFlashcards(now time.Time) ([]*Flashcard, error) {
-	// Reuse ListFlashcards with due filter
-	return s.ListFlashcards(&FlashcardListOptions{Due: true})
+	cards, err := s.ListFlashcards(nil)
+	if err != nil {
+		return nil, err
+	}
+	var due []*Flashcard
+	for _, c := range cards {
+		if IsDue(c.DueAt, now) {
+			due = append(due, c)
+		}
+	}
+	return due, nil
 }
 
 // Flashcard index maintenance
 
-func (s *KVStore) addToFlashcardIndex(cardID string) error {
+func (s *KVStore) addToFlashcardIndex(cardIDs ...string) error {
 	ctx := context.Background()
 	indexKey := s.generateKey("index", "flashcards")
 	ids, err := s.getFlashcardIndex()
 	if err != nil && !errors.Is(err, store.ErrNotFound) {
 		return err
 	}
-	for _, id := range ids {
-		if id == cardID {
-			return nil
-		}
+	ids, added := appendMissing(ids, cardIDs)
+	if !added {
+		return nil
 	}
-	ids = append(ids, cardID)
 	data, _ := json.Marshal(ids)
 	return s.kv.Set(ctx, indexKey, data)
 }
@@ -1258,43 +1961,628 @@ func (s *KVStore) getFlashcardIndex() ([]string, error) {
 	return ids, nil
 }
 
-// Task operations (Phase 3) - Stubs for KVStore
+// Task operations (Phase 3) - Stubs for KVStore, which reports no Tasks
+// capability
 // TODO: Implement proper task support for KV backend
 
 func (s *KVStore) AddTask(t *Task) error {
-	return fmt.Errorf("tasks not yet implemented for KV store: use SQL backend")
+	return fmt.Errorf("tasks are %w yet: use the SQL backend", ErrBackendUnsupported)
 }
 
 func (s *KVStore) GetTask(id string) (*Task, error) {
-	return nil, fmt.Errorf("tasks not yet implemented for KV store: use SQL backend")
+	return nil, fmt.Errorf("tasks are %w yet: use the SQL backend", ErrBackendUnsupported)
 }
 
 func (s *KVStore) ListTasks(opts *TaskListOptions) ([]*Task, error) {
-	return nil, fmt.Errorf("tasks not yet implemented for KV store: use SQL backend")
+	return nil, fmt.Errorf("tasks are %w yet: use the SQL backend", ErrBackendUnsupported)
 }
 
 func (s *KVStore) UpdateTask(t *Task) error {
-	return fmt.Errorf("tasks not yet implemented for KV store: use SQL backend")
+	return fmt.Errorf("tasks are %w yet: use the SQL backend", ErrBackendUnsupported)
 }
 
 func (s *KVStore) DeleteTask(id string) error {
-	return fmt.Errorf("tasks not yet implemented for KV store: use SQL backend")
+	return fmt.Errorf("tasks are %w yet: use the SQL backend", ErrBackendUnsupported)
+}
+
+// Reading queue operations
+
+func (s *KVStore) GetReadingQueue() ([]string, error) {
+	data, err := s.kv.Get(context.Background(), s.generateKey("queue", "reading"))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var ids []string
+	if err := json.Unmarshal(data, &ids); err != nil {
+		return nil, err
+	}
+	return ids, nil
 }
 
-// SavedSearch operations - Stubs for KVStore
+func (s *KVStore) SetReadingQueue(documentIDs []string) error {
+	data, err := json.Marshal(documentIDs)
+	if err != nil {
+		return err
+	}
+	return s.kv.Set(context.Background(), s.generateKey("queue", "reading"), data)
+}
+
+// Operation journal, stored newest first under a single key
+
+func (s *KVStore) RecordOperation(op *Operation) error {
+	if op.ID == "" {
+		op.ID = fmt.Sprintf("operation:%d", time.Now().UnixNano())
+	}
+	op.CreatedAt = time.Now()
+
+	ops, err := s.ListOperations(0)
+	if err != nil {
+		return err
+	}
+	return s.saveOperations(append([]*Operation{op}, ops...))
+}
+
+func (s *KVStore) ListOperations(limit int) ([]*Operation, error) {
+	data, err := s.kv.Get(context.Background(), s.generateKey("journal", "operations"))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var ops []*Operation
+	if err := json.Unmarshal(data, &ops); err != nil {
+		return nil, fmt.Errorf("unmarshal journal: %w", err)
+	}
+	if limit > 0 && len(ops) > limit {
+		ops = ops[:limit]
+	}
+	return ops, nil
+}
+
+func (s *KVStore) DeleteOperation(id string) error {
+	ops, err := s.ListOperations(0)
+	if err != nil {
+		return err
+	}
+	kept := ops[:0]
+	for _, op := range ops {
+		if op.ID != id {
+			kept = append(kept, op)
+		}
+	}
+	return s.saveOperations(kept)
+}
+
+func (s *KVStore) saveOperations(ops []*Operation) error {
+	data, err := json.Marshal(ops)
+	if err != nil {
+		return fmt.Errorf("marshal journal: %w", err)
+	}
+	return s.kv.Set(context.Background(), s.generateKey("journal", "operations"), data)
+}
+
+// Text signatures, stored as one map keyed by document ID
+
+func (s *KVStore) ListTextSignatures() ([]*TextSignature, error) {
+	sigs, err := s.textSignatures()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*TextSignature, 0, len(sigs))
+	for _, sig := range sigs {
+		list = append(list, sig)
+	}
+	return list, nil
+}
+
+func (s *KVStore) SaveTextSignature(sig *TextSignature) error {
+	sigs, err := s.textSignatures()
+	if err != nil {
+		return err
+	}
+	sigs[sig.DocumentID] = sig
+	return s.saveTextSignatures(sigs)
+}
+
+func (s *KVStore) textSignatures() (map[string]*TextSignature, error) {
+	sigs := make(map[string]*TextSignature)
+	data, err := s.kv.Get(context.Background(), s.generateKey("signatures", "text"))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return sigs, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &sigs); err != nil {
+		return nil, fmt.Errorf("unmarshal signatures: %w", err)
+	}
+	return sigs, nil
+}
+
+func (s *KVStore) saveTextSignatures(sigs map[string]*TextSignature) error {
+	data, err := json.Marshal(sigs)
+	if err != nil {
+		return fmt.Errorf("marshal signatures: %w", err)
+	}
+	return s.kv.Set(context.Background(), s.generateKey("signatures", "text"), data)
+}
+
+// Document review schedules, all under one key
+
+func (s *KVStore) GetDocumentReview(documentID string) (*DocumentReview, error) {
+	reviews, err := s.ListDocumentReviews()
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range reviews {
+		if r.DocumentID == documentID {
+			return r, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *KVStore) SaveDocumentReview(r *DocumentReview) error {
+	reviews, err := s.ListDocumentReviews()
+	if err != nil {
+		return err
+	}
+	reviews = slices.DeleteFunc(reviews, func(e *DocumentReview) bool { return e.DocumentID == r.DocumentID })
+	return s.saveDocumentReviews(append(reviews, r))
+}
+
+func (s *KVStore) ListDocumentReviews() ([]*DocumentReview, error) {
+	data, err := s.kv.Get(context.Background(), s.generateKey("document-reviews", "all"))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var reviews []*DocumentReview
+	if err := json.Unmarshal(data, &reviews); err != nil {
+		return nil, fmt.Errorf("unmarshal document reviews: %w", err)
+	}
+	return reviews, nil
+}
+
+func (s *KVStore) DeleteDocumentReview(documentID string) error {
+	reviews, err := s.ListDocumentReviews()
+	if err != nil {
+		return err
+	}
+	kept := slices.DeleteFunc(reviews, func(r *DocumentReview) bool { return r.DocumentID == documentID })
+	if len(kept) == len(reviews) {
+		return nil
+	}
+	return s.saveDocumentReviews(kept)
+}
+
+// saveDocumentReviews stores the schedules soonest due first, the order
+// ListDocumentReviews returns.
+func (s *KVStore) saveDocumentReviews(reviews []*DocumentReview) error {
+	slices.SortStableFunc(reviews, func(a, b *DocumentReview) int { return a.DueAt.Compare(b.DueAt) })
+	data, err := json.Marshal(reviews)
+	if err != nil {
+		return fmt.Errorf("marshal document reviews: %w", err)
+	}
+	return s.kv.Set(context.Background(), s.generateKey("document-reviews", "all"), data)
+}
+
+// Document sections, one key per document
+
+func (s *KVStore) GetDocumentSections(documentID string) (*DocumentSections, error) {
+	data, err := s.kv.Get(context.Background(), s.generateKey("sections", documentID))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var secs DocumentSections
+	if err := json.Unmarshal(data, &secs); err != nil {
+		return nil, fmt.Errorf("unmarshal sections: %w", err)
+	}
+	return &secs, nil
+}
+
+func (s *KVStore) SaveDocumentSections(secs *DocumentSections) error {
+	data, err := json.Marshal(secs)
+	if err != nil {
+		return fmt.Errorf("marshal sections: %w", err)
+	}
+	return s.kv.Set(context.Background(), s.generateKey("sections", secs.DocumentID), data)
+}
+
+// Document references, one key per document
+
+func (s *KVStore) GetDocumentReferences(documentID string) (*DocumentReferences, error) {
+	data, err := s.kv.Get(context.Background(), s.generateKey("references", documentID))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var refs DocumentReferences
+	if err := json.Unmarshal(data, &refs); err != nil {
+		return nil, fmt.Errorf("unmarshal references: %w", err)
+	}
+	return &refs, nil
+}
+
+func (s *KVStore) SaveDocumentReferences(refs *DocumentReferences) error {
+	data, err := json.Marshal(refs)
+	if err != nil {
+		return fmt.Errorf("marshal references: %w", err)
+	}
+	return s.kv.Set(context.Background(), s.generateKey("references", refs.DocumentID), data)
+}
+
+// Zotero sync state, one key per Zotero library
+
+func (s *KVStore) GetZoteroSyncState(library string) (*ZoteroSyncState, error) {
+	data, err := s.kv.Get(context.Background(), s.generateKey("zotero", library))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var state ZoteroSyncState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("unmarshal zotero sync state: %w", err)
+	}
+	return &state, nil
+}
+
+func (s *KVStore) SaveZoteroSyncState(state *ZoteroSyncState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("marshal zotero sync state: %w", err)
+	}
+	return s.kv.Set(context.Background(), s.generateKey("zotero", state.Library), data)
+}
+
+// Custom field schema, stored sorted by name under a single key
+
+func (s *KVStore) DefineField(def *FieldDef) error {
+	defs, err := s.ListFields()
+	if err != nil {
+		return err
+	}
+	if def.CreatedAt.IsZero() {
+		def.CreatedAt = time.Now()
+	}
+	defs = slices.DeleteFunc(defs, func(d *FieldDef) bool { return d.Name == def.Name })
+	defs = append(defs, def)
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Name < defs[j].Name })
+	return s.saveFields(defs)
+}
+
+func (s *KVStore) ListFields() ([]*FieldDef, error) {
+	data, err := s.kv.Get(context.Background(), s.generateKey("fields", "schema"))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var defs []*FieldDef
+	if err := json.Unmarshal(data, &defs); err != nil {
+		return nil, fmt.Errorf("unmarshal fields: %w", err)
+	}
+	return defs, nil
+}
+
+func (s *KVStore) DeleteField(name string) error {
+	defs, err := s.ListFields()
+	if err != nil {
+		return err
+	}
+	return s.saveFields(slices.DeleteFunc(defs, func(d *FieldDef) bool { return d.Name == name }))
+}
+
+func (s *KVStore) saveFields(defs []*FieldDef) error {
+	data, err := json.Marshal(defs)
+	if err != nil {
+		return fmt.Errorf("marshal fields: %w", err)
+	}
+	return s.kv.Set(context.Background(), s.generateKey("fields", "schema"), data)
+}
+
+// Share links, stored newest first under a single key
+
+func (s *KVStore) CreateShare(sh *Share) error {
+	shares, err := s.ListShares()
+	if err != nil {
+		return err
+	}
+	if sh.CreatedAt.IsZero() {
+		sh.CreatedAt = time.Now()
+	}
+	return s.saveShares(append([]*Share{sh}, shares...))
+}
+
+func (s *KVStore) GetShare(token string) (*Share, error) {
+	shares, err := s.ListShares()
+	if err != nil {
+		return nil, err
+	}
+	for _, sh := range shares {
+		if sh.Token == token {
+			return sh, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *KVStore) ListShares() ([]*Share, error) {
+	data, err := s.kv.Get(context.Background(), s.generateKey("shares", "all"))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var shares []*Share
+	if err := json.Unmarshal(data, &shares); err != nil {
+		return nil, fmt.Errorf("unmarshal shares: %w", err)
+	}
+	return shares, nil
+}
+
+func (s *KVStore) DeleteShare(token string) error {
+	shares, err := s.ListShares()
+	if err != nil {
+		return err
+	}
+	return s.saveShares(slices.DeleteFunc(shares, func(sh *Share) bool { return sh.Token == token }))
+}
+
+func (s *KVStore) saveShares(shares []*Share) error {
+	data, err := json.Marshal(shares)
+	if err != nil {
+		return fmt.Errorf("marshal shares: %w", err)
+	}
+	return s.kv.Set(context.Background(), s.generateKey("shares", "all"), data)
+}
+
+// API tokens, stored oldest first under a single key
+
+func (s *KVStore) CreateAPIToken(t *APIToken) error {
+	tokens, err := s.ListAPITokens()
+	if err != nil {
+		return err
+	}
+	for _, other := range tokens {
+		if other.Name == t.Name {
+			return fmt.Errorf("a token named %q already exists", t.Name)
+		}
+	}
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now()
+	}
+	return s.saveAPITokens(append(tokens, t))
+}
+
+func (s *KVStore) ListAPITokens() ([]*APIToken, error) {
+	data, err := s.kv.Get(context.Background(), s.generateKey("tokens", "all"))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	// Hash is left out of the token's JSON, so it is stored alongside
+	var stored []struct {
+		*APIToken
+		Hash string `json:"hash"`
+	}
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("unmarshal tokens: %w", err)
+	}
+	tokens := make([]*APIToken, len(stored))
+	for i, st := range stored {
+		st.APIToken.Hash = st.Hash
+		tokens[i] = st.APIToken
+	}
+	return tokens, nil
+}
+
+func (s *KVStore) DeleteAPIToken(id string) error {
+	tokens, err := s.ListAPITokens()
+	if err != nil {
+		return err
+	}
+	return s.saveAPITokens(slices.DeleteFunc(tokens, func(t *APIToken) bool { return t.ID == id }))
+}
+
+func (s *KVStore) saveAPITokens(tokens []*APIToken) error {
+	type storedToken struct {
+		*APIToken
+		Hash string `json:"hash"`
+	}
+	stored := make([]storedToken, len(tokens))
+	for i, t := range tokens {
+		stored[i] = storedToken{t, t.Hash}
+	}
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return fmt.Errorf("marshal tokens: %w", err)
+	}
+	return s.kv.Set(context.Background(), s.generateKey("tokens", "all"), data)
+}
+
+// Audit log, stored oldest first under one key per day, so appending
+// never rewrites more than a day's entries. The "days" key lists the days.
+
+func (s *KVStore) AppendAuditEntry(e *AuditEntry) error {
+	if e.ID == "" {
+		e.ID = fmt.Sprintf("audit:%d", time.Now().UnixNano())
+	}
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+	day := e.CreatedAt.UTC().Format("2006-01-02")
+
+	entries, err := s.auditDay(day)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		days, err := s.auditDays()
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(days, day) {
+			days = append(days, day)
+			slices.Sort(days)
+			data, err := json.Marshal(days)
+			if err != nil {
+				return fmt.Errorf("marshal audit days: %w", err)
+			}
+			if err := s.kv.Set(context.Background(), s.generateKey("audit", "days"), data); err != nil {
+				return err
+			}
+		}
+	}
+
+	data, err := json.Marshal(append(entries, e))
+	if err != nil {
+		return fmt.Errorf("marshal audit log: %w", err)
+	}
+	return s.kv.Set(context.Background(), s.generateKey("audit", day), data)
+}
+
+func (s *KVStore) ListAuditEntries(opts *AuditListOptions) ([]*AuditEntry, error) {
+	days, err := s.auditDays()
+	if err != nil {
+		return nil, err
+	}
+	var entries []*AuditEntry
+	for i := len(days) - 1; i >= 0; i-- {
+		if opts != nil && !opts.Since.IsZero() && days[i] < opts.Since.UTC().Format("2006-01-02") {
+			break
+		}
+		day, err := s.auditDay(days[i])
+		if err != nil {
+			return nil, err
+		}
+		for j := len(day) - 1; j >= 0; j-- {
+			if !opts.Matches(day[j]) {
+				continue
+			}
+			entries = append(entries, day[j])
+			if opts != nil && opts.Limit > 0 && len(entries) == opts.Limit {
+				return entries, nil
+			}
+		}
+	}
+	return entries, nil
+}
+
+func (s *KVStore) auditDays() ([]string, error) {
+	data, err := s.kv.Get(context.Background(), s.generateKey("audit", "days"))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var days []string
+	if err := json.Unmarshal(data, &days); err != nil {
+		return nil, fmt.Errorf("unmarshal audit days: %w", err)
+	}
+	return days, nil
+}
+
+func (s *KVStore) auditDay(day string) ([]*AuditEntry, error) {
+	data, err := s.kv.Get(context.Background(), s.generateKey("audit", day))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var entries []*AuditEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("unmarshal audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// Saved searches, stored under a single key. As in the SQL store, saving
+// under a name that's taken replaces that search, keeping its ID.
 
 func (s *KVStore) SaveSearch(ss *SavedSearch) error {
-	return fmt.Errorf("saved searches not yet implemented for KV store: use SQL backend")
+	searches, err := s.ListSavedSearches()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	ss.CreatedAt, ss.UpdatedAt = now, now
+	i := slices.IndexFunc(searches, func(other *SavedSearch) bool { return other.Name == ss.Name })
+	if i < 0 {
+		if ss.ID == "" {
+			ss.ID = kvID("search")
+		}
+		return s.saveSavedSearches(append(searches, ss))
+	}
+	ss.ID, ss.CreatedAt = searches[i].ID, searches[i].CreatedAt
+	searches[i] = ss
+	return s.saveSavedSearches(searches)
 }
 
 func (s *KVStore) GetSavedSearch(idOrName string) (*SavedSearch, error) {
-	return nil, fmt.Errorf("saved searches not yet implemented for KV store: use SQL backend")
+	searches, err := s.ListSavedSearches()
+	if err != nil {
+		return nil, err
+	}
+	// By ID first, then by name
+	if i := slices.IndexFunc(searches, func(ss *SavedSearch) bool { return ss.ID == idOrName }); i >= 0 {
+		return searches[i], nil
+	}
+	if i := slices.IndexFunc(searches, func(ss *SavedSearch) bool { return ss.Name == idOrName }); i >= 0 {
+		return searches[i], nil
+	}
+	return nil, nil
 }
 
+// ListSavedSearches returns the saved searches, most recently updated
+// first.
 func (s *KVStore) ListSavedSearches() ([]*SavedSearch, error) {
-	return nil, fmt.Errorf("saved searches not yet implemented for KV store: use SQL backend")
+	data, err := s.kv.Get(context.Background(), s.generateKey("searches", "all"))
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var searches []*SavedSearch
+	if err := json.Unmarshal(data, &searches); err != nil {
+		return nil, fmt.Errorf("unmarshal saved searches: %w", err)
+	}
+	sort.SliceStable(searches, func(i, j int) bool { return searches[i].UpdatedAt.After(searches[j].UpdatedAt) })
+	return searches, nil
 }
 
 func (s *KVStore) DeleteSavedSearch(id string) error {
-	return fmt.Errorf("saved searches not yet implemented for KV store: use SQL backend")
+	searches, err := s.ListSavedSearches()
+	if err != nil {
+		return err
+	}
+	return s.saveSavedSearches(slices.DeleteFunc(searches, func(ss *SavedSearch) bool { return ss.ID == id }))
+}
+
+func (s *KVStore) saveSavedSearches(searches []*SavedSearch) error {
+	data, err := json.Marshal(searches)
+	if err != nil {
+		return fmt.Errorf("marshal saved searches: %w", err)
+	}
+	return s.kv.Set(context.Background(), s.generateKey("searches", "all"), data)
 }
@@ -3,6 +3,10 @@ package library
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/yourorg/arc-sdk/store"
@@ -40,3 +44,180 @@ func TestKVStoreDocumentIndex(t *testing.T) {
 		t.Fatalf("Index contains wrong ID: %s", ids[0])
 	}
 }
+
+// readCountingKV counts the documents read from it.
+type readCountingKV struct {
+	store.KVStore
+	docReads int
+}
+
+func (kv *readCountingKV) Get(ctx context.Context, key string) ([]byte, error) {
+	if strings.HasPrefix(key, "arc-library:doc:") && !strings.HasPrefix(key, "arc-library:doc:path:") && !strings.HasPrefix(key, "arc-library:doc:source:") {
+		kv.docReads++
+	}
+	return kv.KVStore.Get(ctx, key)
+}
+
+func TestKVStoreFilterIndexes(t *testing.T) {
+	kv := &readCountingKV{KVStore: store.NewMemoryStore()}
+	s, _ := NewKVStore(kv)
+
+	docs := FixtureDocuments(40, 1)
+	docs[3].Tags = append(docs[3].Tags, "Rare")
+	docs[3].Status = StatusReading
+	docs[3].Type = DocTypeBook
+	if err := s.AddDocuments(docs); err != nil {
+		t.Fatal(err)
+	}
+	ids := func(docs []*Document) []string {
+		var ids []string
+		for _, d := range docs {
+			ids = append(ids, d.ID)
+		}
+		return ids
+	}
+
+	kv.docReads = 0
+	got, err := s.ListDocuments(&ListOptions{Tag: "rare"})
+	if err != nil || len(got) != 1 || got[0].ID != docs[3].ID {
+		t.Fatalf("tag rare = %v, %v", ids(got), err)
+	}
+	if kv.docReads != 1 {
+		t.Errorf("read %d documents to list one", kv.docReads)
+	}
+	if got, _ := s.ListDocuments(&ListOptions{Tag: "rare", Status: "reading", Type: "book"}); len(got) != 1 {
+		t.Errorf("tag, status, and type = %v", ids(got))
+	}
+	if got, _ := s.ListDocuments(&ListOptions{Tag: "rare", Status: "unread"}); len(got) != 0 {
+		t.Errorf("tag rare, unread = %v", ids(got))
+	}
+
+	// Documents move between indexes as they change, and leave when deleted
+	if err := s.RemoveTag(docs[3].ID, "RARE"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddTag(docs[5].ID, "rare"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.ListDocuments(&ListOptions{Tag: "rare"}); len(got) != 1 || got[0].ID != docs[5].ID {
+		t.Errorf("after retagging, tag rare = %v", ids(got))
+	}
+	unread, _ := s.ListDocuments(&ListOptions{Status: "unread"})
+	if len(unread) < 2 {
+		t.Fatalf("%d unread fixtures", len(unread))
+	}
+	finished, _ := s.GetDocument(unread[0].ID)
+	finished.Status = StatusCompleted
+	finished.Tags = append(finished.Tags, "rare")
+	if err := s.UpdateDocument(finished); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteDocument(unread[1].ID); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.ListDocuments(&ListOptions{Status: "unread"}); len(got) != len(unread)-2 {
+		t.Errorf("%d unread after finishing and deleting one of %d", len(got), len(unread))
+	}
+	got, _ = s.ListDocuments(&ListOptions{Tag: "rare", Status: "completed"})
+	if !slices.Contains(ids(got), finished.ID) {
+		t.Errorf("tag rare, completed = %v, want %s among them", ids(got), finished.ID)
+	}
+	for _, d := range got {
+		if d.Status != StatusCompleted {
+			t.Errorf("listed %s, %s", d.ID, d.Status)
+		}
+	}
+}
+
+func TestKVStoreRebuildIndexes(t *testing.T) {
+	kv := &readCountingKV{KVStore: store.NewMemoryStore()}
+	s, _ := NewKVStore(kv)
+	docs := FixtureDocuments(20, 1)
+	if err := s.AddDocuments(docs); err != nil {
+		t.Fatal(err)
+	}
+	want, _ := s.ListDocuments(&ListOptions{Tag: docs[0].Tags[0]})
+
+	// A library from before the filter indexes lists by reading everything
+	if err := kv.Delete(context.Background(), s.generateKey("index", "filters")); err != nil {
+		t.Fatal(err)
+	}
+	s, _ = NewKVStore(kv)
+	kv.docReads = 0
+	if got, _ := s.ListDocuments(&ListOptions{Tag: docs[0].Tags[0]}); len(got) != len(want) {
+		t.Errorf("without indexes, listed %d, want %d", len(got), len(want))
+	}
+	if kv.docReads != len(docs) {
+		t.Errorf("without indexes, read %d documents", kv.docReads)
+	}
+
+	stats, err := s.RebuildIndexes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Documents != len(docs) || stats.Indexes == 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+	kv.docReads = 0
+	if got, _ := s.ListDocuments(&ListOptions{Tag: docs[0].Tags[0]}); len(got) != len(want) {
+		t.Errorf("after rebuilding, listed %d, want %d", len(got), len(want))
+	}
+	if kv.docReads != len(want) {
+		t.Errorf("after rebuilding, read %d documents for %d", kv.docReads, len(want))
+	}
+}
+
+func TestKVStoreFilterIndexesConcurrent(t *testing.T) {
+	s, _ := NewKVStore(store.NewMemoryStore())
+	const n = 20
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.AddDocument(&Document{Title: "Shared", Tags: []string{"shared"}}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if got, _ := s.ListDocuments(nil); len(got) != n {
+		t.Errorf("listed %d of %d documents", len(got), n)
+	}
+	if got, _ := s.ListDocuments(&ListOptions{Tag: "shared"}); len(got) != n {
+		t.Errorf("listed %d of %d documents by tag", len(got), n)
+	}
+}
+
+// failingIndexKV fails writes to filter indexes once fail is set.
+type failingIndexKV struct {
+	store.KVStore
+	fail bool
+}
+
+func (kv *failingIndexKV) Set(ctx context.Context, key string, value []byte) error {
+	if kv.fail && strings.HasPrefix(key, "arc-library:index:doc:") {
+		return errors.New("disk full")
+	}
+	return kv.KVStore.Set(ctx, key, value)
+}
+
+func TestKVStoreRebuildIndexesFailure(t *testing.T) {
+	kv := &failingIndexKV{KVStore: store.NewMemoryStore()}
+	s, _ := NewKVStore(kv)
+	docs := FixtureDocuments(20, 1)
+	if err := s.AddDocuments(docs); err != nil {
+		t.Fatal(err)
+	}
+	want, _ := s.ListDocuments(&ListOptions{Tag: docs[0].Tags[0]})
+
+	// A rebuild that fails part-way leaves the indexes there were
+	kv.fail = true
+	if _, err := s.RebuildIndexes(); err == nil {
+		t.Fatal("rebuild succeeded")
+	}
+	kv.fail = false
+	if got, _ := s.ListDocuments(&ListOptions{Tag: docs[0].Tags[0]}); len(got) != len(want) {
+		t.Errorf("after a failed rebuild, listed %d, want %d", len(got), len(want))
+	}
+}
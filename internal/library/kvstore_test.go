@@ -6,7 +6,10 @@ package library
 import (
 	"context"
 	"encoding/json"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/yourorg/arc-sdk/store"
 )
@@ -434,3 +437,266 @@ func TestKVStoreReadingSessions(t *testing.T) {
 		t.Fatalf("Session EndAt is zero, want non-zero. Full session: %+v", sessions[0])
 	}
 }
+
+func TestKVStoreListMetadataFilters(t *testing.T) {
+	kv := store.NewMemoryStore()
+	s, _ := NewKVStore(kv)
+
+	old := &Document{Path: "/tmp/old.pdf", Type: DocTypePaper, Title: "Old", Authors: []string{"Geoffrey Hinton"}, Rating: 5, Status: StatusCompleted}
+	if err := s.AddDocument(old); err != nil {
+		t.Fatalf("AddDocument: %v", err)
+	}
+	cutoff := time.Now()
+	time.Sleep(time.Millisecond)
+	fresh := &Document{Path: "/tmp/new.pdf", Type: DocTypePaper, Title: "New", Authors: []string{"Yann LeCun"}, Rating: 2}
+	if err := s.AddDocument(fresh); err != nil {
+		t.Fatalf("AddDocument: %v", err)
+	}
+	old.ReadAt = cutoff.Add(-time.Hour)
+	if err := s.UpdateDocument(old); err != nil {
+		t.Fatalf("UpdateDocument: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		opts ListOptions
+		want []string
+	}{
+		{"status", ListOptions{Status: "completed"}, []string{"Old"}},
+		{"unset status is unread", ListOptions{Status: "unread"}, []string{"New"}},
+		{"min rating", ListOptions{MinRating: 3}, []string{"Old"}},
+		{"author", ListOptions{Author: "lecun"}, []string{"New"}},
+		{"created after", ListOptions{CreatedAfter: cutoff}, []string{"New"}},
+		{"created before", ListOptions{CreatedBefore: cutoff}, []string{"Old"}},
+		{"read before", ListOptions{ReadBefore: cutoff}, []string{"Old"}},
+		{"read after", ListOptions{ReadAfter: cutoff}, nil},
+	}
+	for _, tt := range tests {
+		opts := tt.opts
+		docs, err := s.ListDocuments(&opts)
+		if err != nil {
+			t.Fatalf("%s: ListDocuments: %v", tt.name, err)
+		}
+		var got []string
+		for _, d := range docs {
+			got = append(got, d.Title)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestKVStoreAggregateCounters(t *testing.T) {
+	kv := store.NewMemoryStore()
+	s, _ := NewKVStore(kv)
+
+	paper := &Document{Path: "/tmp/p.pdf", Type: DocTypePaper, Title: "Paper"}
+	if err := s.AddDocument(paper); err != nil {
+		t.Fatalf("AddDocument: %v", err)
+	}
+
+	// First read builds the counters from a scan
+	counts, err := s.CountDocumentsByType()
+	if err != nil {
+		t.Fatalf("CountDocumentsByType: %v", err)
+	}
+	if counts[DocTypePaper] != 1 {
+		t.Fatalf("papers: got %d, want 1", counts[DocTypePaper])
+	}
+
+	// Later writes adjust them incrementally
+	book := &Document{Path: "/tmp/b.pdf", Type: DocTypeBook, Title: "Book"}
+	if err := s.AddDocument(book); err != nil {
+		t.Fatalf("AddDocument: %v", err)
+	}
+	if err := s.AddAnnotation(&Annotation{DocumentID: book.ID, Type: "note", Content: "x"}); err != nil {
+		t.Fatalf("AddAnnotation: %v", err)
+	}
+	sess, err := s.StartSession(book.ID)
+	if err != nil {
+		t.Fatalf("StartSession: %v", err)
+	}
+	if err := s.EndSession(sess.ID, 12, ""); err != nil {
+		t.Fatalf("EndSession: %v", err)
+	}
+
+	counts, _ = s.CountDocumentsByType()
+	if counts[DocTypeBook] != 1 || counts[DocTypePaper] != 1 {
+		t.Fatalf("by type: got %v", counts)
+	}
+	if n, _ := s.CountAnnotations(); n != 1 {
+		t.Fatalf("annotations: got %d, want 1", n)
+	}
+	if n, pages, _ := s.SessionTotals(); n != 1 || pages != 12 {
+		t.Fatalf("sessions: got %d/%d pages, want 1/12", n, pages)
+	}
+
+	if err := s.DeleteDocument(book.ID); err != nil {
+		t.Fatalf("DeleteDocument: %v", err)
+	}
+	counts, _ = s.CountDocumentsByType()
+	if _, ok := counts[DocTypeBook]; ok {
+		t.Fatalf("book still counted after delete: %v", counts)
+	}
+	if n, _ := s.CountAnnotations(); n != 0 {
+		t.Fatalf("annotations after delete: got %d, want 0", n)
+	}
+	if n, pages, _ := s.SessionTotals(); n != 0 || pages != 0 {
+		t.Fatalf("sessions after delete: got %d/%d, want 0/0", n, pages)
+	}
+}
+
+func TestKVStoreListDocumentsLimit(t *testing.T) {
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var docs []*Document
+	for _, title := range []string{"first", "second", "third"} {
+		d := &Document{Title: title, Path: "/tmp/" + title + ".pdf"}
+		if err := s.AddDocument(d); err != nil {
+			t.Fatal(err)
+		}
+		docs = append(docs, d)
+	}
+	docs[0].Notes = "touched"
+	if err := s.UpdateDocument(docs[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	// The most recently updated, not the first added
+	got, err := s.ListDocuments(&ListOptions{Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Title != "first" || got[1].Title != "third" {
+		var titles []string
+		for _, d := range got {
+			titles = append(titles, d.Title)
+		}
+		t.Errorf("got %v, want [first third]", titles)
+	}
+}
+
+func TestKVStoreCountersConcurrent(t *testing.T) {
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CountAnnotations(); err != nil {
+		t.Fatal(err)
+	}
+
+	const n = 50
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.adjustCounters(func(c *kvCounters) { c.Annotations++ })
+		}()
+	}
+	wg.Wait()
+	if got, _ := s.CountAnnotations(); got != n {
+		t.Errorf("annotations: got %d, want %d", got, n)
+	}
+}
+
+func TestKVStoreDocumentLinks(t *testing.T) {
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"preprint", "published", "other"} {
+		if err := s.AddDocument(&Document{ID: id, Title: id, Path: "/" + id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	link := &DocumentLink{FromID: "published", ToID: "preprint", Relation: LinkSupersedes}
+	if err := s.AddDocumentLink(link); err != nil {
+		t.Fatalf("AddDocumentLink: %v", err)
+	}
+	if err := s.AddDocumentLink(&DocumentLink{FromID: "published", ToID: "preprint", Relation: LinkSupersedes}); err == nil {
+		t.Error("duplicate link should fail")
+	}
+	if err := s.AddDocumentLink(&DocumentLink{FromID: "other", ToID: "preprint", Relation: LinkRespondsTo}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Both ends see the link
+	for _, id := range []string{"published", "preprint"} {
+		links, err := s.ListDocumentLinks(id)
+		if err != nil || len(links) == 0 || links[0].ID != link.ID {
+			t.Errorf("ListDocumentLinks(%s) = %v, %v", id, links, err)
+		}
+	}
+
+	if err := s.DeleteDocumentLink(link.ID); err != nil {
+		t.Fatal(err)
+	}
+	if links, _ := s.ListDocumentLinks("published"); len(links) != 0 {
+		t.Errorf("published still has %d link(s)", len(links))
+	}
+
+	// Deleting a document drops its links from the other end too
+	if err := s.DeleteDocument("other"); err != nil {
+		t.Fatal(err)
+	}
+	if links, _ := s.ListDocumentLinks("preprint"); len(links) != 0 {
+		t.Errorf("preprint still has %d link(s) after delete", len(links))
+	}
+}
+
+func TestKVStoreSavedSearches(t *testing.T) {
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if caps := s.Capabilities(); !caps.SavedSearches || caps.Tasks {
+		t.Errorf("capabilities %+v", caps)
+	}
+
+	ml := &SavedSearch{Name: "ml", Query: "neural", Tag: "ml"}
+	if err := s.SaveSearch(ml); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SaveSearch(&SavedSearch{Name: "rl", Query: "reward"}); err != nil {
+		t.Fatal(err)
+	}
+	if ml.ID == "" {
+		t.Error("saved search ID should be generated")
+	}
+
+	// Saving under a taken name replaces the search, keeping its ID
+	time.Sleep(time.Millisecond)
+	again := &SavedSearch{Name: "ml", Query: "transformer"}
+	if err := s.SaveSearch(again); err != nil {
+		t.Fatal(err)
+	}
+	if again.ID != ml.ID {
+		t.Errorf("resaved as %s, was %s", again.ID, ml.ID)
+	}
+	got, err := s.GetSavedSearch("ml")
+	if err != nil || got == nil || got.Query != "transformer" || got.Tag != "" {
+		t.Fatalf("GetSavedSearch by name = %+v, %v", got, err)
+	}
+	if got, _ := s.GetSavedSearch(ml.ID); got == nil || got.Name != "ml" {
+		t.Errorf("GetSavedSearch by ID = %+v", got)
+	}
+	if got, err := s.GetSavedSearch("missing"); got != nil || err != nil {
+		t.Errorf("GetSavedSearch of a missing search = %+v, %v", got, err)
+	}
+
+	searches, err := s.ListSavedSearches()
+	if err != nil || len(searches) != 2 || searches[0].Name != "ml" {
+		t.Fatalf("ListSavedSearches = %d, %v", len(searches), err)
+	}
+	if err := s.DeleteSavedSearch(ml.ID); err != nil {
+		t.Fatal(err)
+	}
+	if searches, _ := s.ListSavedSearches(); len(searches) != 1 || searches[0].Name != "rl" {
+		t.Errorf("after delete: %d searches", len(searches))
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"strings"
+	"unicode"
+)
+
+// languageSample caps how much text language detection reads.
+const languageSample = 20000
+
+// stopwords are frequent function words that tell Latin-script languages
+// apart. Words shared by several languages still count for each; the
+// distinctive ones decide.
+var stopwords = map[string][]string{
+	"en": {"the", "and", "of", "to", "in", "is", "that", "for", "with", "this", "are", "on", "we", "by", "as", "be", "which"},
+	"de": {"der", "die", "und", "das", "ist", "nicht", "mit", "den", "ein", "eine", "zu", "von", "sich", "auf", "für", "wir", "dem", "auch"},
+	"fr": {"le", "la", "les", "et", "des", "est", "une", "dans", "du", "pour", "que", "qui", "nous", "sur", "pas", "au", "avec", "ce"},
+	"es": {"el", "la", "los", "las", "y", "de", "que", "en", "es", "por", "una", "con", "para", "del", "se", "como", "más"},
+	"it": {"il", "di", "che", "e", "la", "per", "un", "una", "non", "sono", "della", "con", "gli", "nel", "del", "questo"},
+	"pt": {"o", "os", "de", "que", "e", "do", "da", "em", "um", "uma", "para", "com", "não", "são", "dos", "das", "pelo"},
+	"nl": {"de", "het", "een", "en", "van", "is", "dat", "niet", "op", "te", "voor", "met", "zijn", "ook", "wordt", "deze"},
+	"sv": {"och", "att", "det", "som", "en", "är", "på", "för", "med", "av", "inte", "den", "till", "har", "vi", "kan"},
+}
+
+// stopwordLanguages inverts stopwords.
+var stopwordLanguages = func() map[string][]string {
+	m := make(map[string][]string)
+	for lang, words := range stopwords {
+		for _, w := range words {
+			m[w] = append(m[w], lang)
+		}
+	}
+	return m
+}()
+
+// CJKLanguages are written without spaces between words, so search
+// indexes them by character trigrams instead of words.
+var CJKLanguages = []string{"zh", "ja", "ko", "th"}
+
+// DetectLanguage guesses the ISO 639-1 code of text: by script for
+// non-Latin writing systems, otherwise by counting stopwords. It returns ""
+// when the text gives too little evidence.
+func DetectLanguage(text string) string {
+	if len(text) > languageSample {
+		text = text[:languageSample]
+	}
+
+	scripts := map[string]int{}
+	latin, letters := 0, 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		switch {
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			scripts["ja"]++
+		case unicode.Is(unicode.Hangul, r):
+			scripts["ko"]++
+		case unicode.Is(unicode.Han, r):
+			scripts["zh"]++
+		case unicode.Is(unicode.Cyrillic, r):
+			scripts["ru"]++
+			if strings.ContainsRune("іїєґІЇЄҐ", r) {
+				scripts["uk"]++
+			}
+		case unicode.Is(unicode.Greek, r):
+			scripts["el"]++
+		case unicode.Is(unicode.Arabic, r):
+			scripts["ar"]++
+		case unicode.Is(unicode.Hebrew, r):
+			scripts["he"]++
+		case unicode.Is(unicode.Thai, r):
+			scripts["th"]++
+		case unicode.Is(unicode.Devanagari, r):
+			scripts["hi"]++
+		}
+	}
+	if letters == 0 {
+		return ""
+	}
+
+	if latin*2 < letters {
+		switch {
+		case scripts["ja"] > 0:
+			// Japanese mixes kana with Han characters
+			return "ja"
+		case scripts["uk"] > 0:
+			return "uk"
+		}
+		best, bestCount := "", 0
+		for lang, n := range scripts {
+			if lang != "uk" && n > bestCount {
+				best, bestCount = lang, n
+			}
+		}
+		return best
+	}
+
+	scores := map[string]int{}
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) }) {
+		for _, lang := range stopwordLanguages[w] {
+			scores[lang]++
+		}
+	}
+	best, bestScore, second := "", 0, 0
+	for lang, n := range scores {
+		switch {
+		case n > bestScore:
+			best, bestScore, second = lang, n, bestScore
+		case n > second:
+			second = n
+		}
+	}
+	if bestScore < 3 || bestScore == second {
+		return ""
+	}
+	return best
+}
+
+// DocumentLanguage returns the language recorded in doc.Meta, or "".
+func DocumentLanguage(doc *Document) string {
+	lang, _ := doc.Meta["language"].(string)
+	return lang
+}
+
+// DetectDocumentLanguage detects the language of doc from its title,
+// abstract and full text and records it in Meta["language"]. A language
+// already recorded is kept unless force is set. It returns the language.
+func DetectDocumentLanguage(doc *Document, force bool) string {
+	if lang := DocumentLanguage(doc); lang != "" && !force {
+		return lang
+	}
+	lang := DetectLanguage(doc.Title + "\n" + doc.Abstract + "\n" + doc.FullText)
+	if lang != "" {
+		SetDocumentLanguage(doc, lang)
+	}
+	return lang
+}
+
+// SetDocumentLanguage records lang in doc.Meta, or clears it when empty.
+func SetDocumentLanguage(doc *Document, lang string) {
+	if lang == "" {
+		delete(doc.Meta, "language")
+		return
+	}
+	if doc.Meta == nil {
+		doc.Meta = make(JSONMap)
+	}
+	doc.Meta["language"] = strings.ToLower(lang)
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"testing"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	cases := map[string]string{
+		"We show that the model is robust to noise and that it scales with the data.":            "en",
+		"Wir zeigen, dass das Modell nicht nur robust ist, sondern auch mit den Daten skaliert.": "de",
+		"Nous montrons que le modèle est robuste et qu'il passe à l'échelle avec les données.":   "fr",
+		"Mostramos que el modelo es robusto y que escala con los datos de la tarea.":             "es",
+		"深層学習による画像認識の研究":                                                                         "ja",
+		"基于深度学习的图像识别研究":                                                                          "zh",
+		"딥러닝을 이용한 이미지 인식 연구":                                                                     "ko",
+		"Мы показываем, что модель устойчива к шуму":                                             "ru",
+		"Attention":  "",
+		"2304.00067": "",
+	}
+	for text, want := range cases {
+		if got := DetectLanguage(text); got != want {
+			t.Errorf("DetectLanguage(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestDetectDocumentLanguage(t *testing.T) {
+	doc := &Document{Title: "Über die Theorie", Abstract: "Wir zeigen, dass die Methode mit den Daten nicht skaliert."}
+	if lang := DetectDocumentLanguage(doc, false); lang != "de" || doc.Meta["language"] != "de" {
+		t.Fatalf("detected %q, meta %v", lang, doc.Meta)
+	}
+
+	// A recorded language wins unless forced
+	SetDocumentLanguage(doc, "EN")
+	if lang := DetectDocumentLanguage(doc, false); lang != "en" {
+		t.Errorf("kept language = %q, want en", lang)
+	}
+	if lang := DetectDocumentLanguage(doc, true); lang != "de" {
+		t.Errorf("forced detection = %q, want de", lang)
+	}
+
+	SetDocumentLanguage(doc, "")
+	if DocumentLanguage(doc) != "" {
+		t.Error("empty language should clear it")
+	}
+}
+
+func TestKVStoreLanguageFilter(t *testing.T) {
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.AddDocument(&Document{ID: "en", Title: "English", Meta: JSONMap{"language": "en"}})
+	s.AddDocument(&Document{ID: "de", Title: "Deutsch", Meta: JSONMap{"language": "de"}})
+	s.AddDocument(&Document{ID: "none", Title: "Unknown"})
+
+	docs, err := s.ListDocuments(&ListOptions{Language: "DE"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 1 || docs[0].ID != "de" {
+		t.Errorf("got %d documents for de, want only de", len(docs))
+	}
+}
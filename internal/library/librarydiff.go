@@ -0,0 +1,251 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// DiffEntities are the kinds of record a LibraryDiff compares, in the
+// order it lists them.
+var DiffEntities = []string{"document", "annotation", "flashcard", "collection", "link"}
+
+// EntityChange is a record added, removed, or modified between two
+// snapshots of a library.
+type EntityChange struct {
+	Entity     string      `json:"entity"`
+	ID         string      `json:"id"`
+	DocumentID string      `json:"document_id,omitempty"` // the document the record belongs to, if any
+	Action     AuditAction `json:"action"`                // create for added, delete for removed, update for modified
+	Summary    string      `json:"summary,omitempty"`
+	Fields     []string    `json:"fields,omitempty"` // the fields a modification changed, when known
+}
+
+// DiffCount is how many records of a kind a LibraryDiff has added,
+// removed, and modified.
+type DiffCount struct {
+	Entity   string `json:"entity"`
+	Added    int    `json:"added"`
+	Removed  int    `json:"removed"`
+	Modified int    `json:"modified"`
+}
+
+// LibraryDiff is what changed between two snapshots of a library.
+type LibraryDiff struct {
+	Changes []EntityChange `json:"changes"`
+	Counts  []DiffCount    `json:"counts"`
+}
+
+// diffRecord is a record of a snapshot, reduced to what a diff compares.
+type diffRecord struct {
+	documentID string
+	summary    string
+	fields     map[string]json.RawMessage
+}
+
+// DiffBackups compares two backups, before then after. Records are
+// matched by ID; a record in both is modified when a field other than its
+// update time differs. Document full text counts, as it does not for
+// revisions, since a migration may lose it.
+func DiffBackups(before, after *LibraryBackup) (*LibraryDiff, error) {
+	from, err := backupRecords(before)
+	if err != nil {
+		return nil, err
+	}
+	to, err := backupRecords(after)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []EntityChange
+	for _, entity := range DiffEntities {
+		old, cur := from[entity], to[entity]
+		for id, r := range old {
+			if _, ok := cur[id]; !ok {
+				changes = append(changes, EntityChange{Entity: entity, ID: id, DocumentID: r.documentID, Action: AuditDelete, Summary: r.summary})
+			}
+		}
+		for id, r := range cur {
+			o, ok := old[id]
+			if !ok {
+				changes = append(changes, EntityChange{Entity: entity, ID: id, DocumentID: r.documentID, Action: AuditCreate, Summary: r.summary})
+				continue
+			}
+			if fields := changedFields(o.fields, r.fields); len(fields) > 0 {
+				changes = append(changes, EntityChange{Entity: entity, ID: id, DocumentID: r.documentID, Action: AuditUpdate, Summary: r.summary, Fields: fields})
+			}
+		}
+	}
+	return newLibraryDiff(changes), nil
+}
+
+// DiffAuditLog summarises audit entries, newest first as the log lists
+// them, as the changes between the library before the oldest and after
+// the newest: a record created and then deleted is no change, and the
+// fields of repeated updates are merged. Entities other than the
+// DiffEntities, such as tasks and shares, are left out.
+func DiffAuditLog(entries []*AuditEntry) *LibraryDiff {
+	byKey := map[string]*EntityChange{}
+	var order []string
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		if !slices.Contains(DiffEntities, e.Entity) {
+			continue
+		}
+		key := e.Entity + "\x00" + e.EntityID
+		c, ok := byKey[key]
+		if !ok {
+			c = &EntityChange{Entity: e.Entity, ID: e.EntityID, DocumentID: e.DocumentID, Action: e.Action, Summary: e.Summary, Fields: slices.Clone(e.Fields)}
+			byKey[key] = c
+			order = append(order, key)
+			continue
+		}
+		switch {
+		case e.Action == AuditDelete && c.Action == AuditCreate:
+			delete(byKey, key)
+		case e.Action == AuditDelete:
+			c.Action, c.Fields = AuditDelete, nil
+		case e.Action == AuditCreate && c.Action == AuditDelete:
+			// Deleted and created again, as undo does: changed at most
+			c.Action = AuditUpdate
+		case c.Action == AuditUpdate:
+			for _, f := range e.Fields {
+				if !slices.Contains(c.Fields, f) {
+					c.Fields = append(c.Fields, f)
+				}
+			}
+		}
+		// Collection summaries name the change rather than the collection
+		if e.Summary != "" && e.Entity != "collection" {
+			c.Summary = e.Summary
+		}
+		if c.DocumentID == "" {
+			c.DocumentID = e.DocumentID
+		}
+	}
+
+	var changes []EntityChange
+	for _, key := range order {
+		if c, ok := byKey[key]; ok {
+			sort.Strings(c.Fields)
+			changes = append(changes, *c)
+		}
+	}
+	return newLibraryDiff(changes)
+}
+
+// newLibraryDiff sorts changes by entity, then removed, added, and
+// modified, then summary, and counts them.
+func newLibraryDiff(changes []EntityChange) *LibraryDiff {
+	actionOrder := map[AuditAction]int{AuditDelete: 0, AuditCreate: 1, AuditUpdate: 2}
+	sort.SliceStable(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if a.Entity != b.Entity {
+			return slices.Index(DiffEntities, a.Entity) < slices.Index(DiffEntities, b.Entity)
+		}
+		if a.Action != b.Action {
+			return actionOrder[a.Action] < actionOrder[b.Action]
+		}
+		if a.Summary != b.Summary {
+			return a.Summary < b.Summary
+		}
+		return a.ID < b.ID
+	})
+
+	d := &LibraryDiff{Changes: changes, Counts: []DiffCount{}}
+	if d.Changes == nil {
+		d.Changes = []EntityChange{}
+	}
+	for _, entity := range DiffEntities {
+		n := DiffCount{Entity: entity}
+		for _, c := range changes {
+			if c.Entity != entity {
+				continue
+			}
+			switch c.Action {
+			case AuditCreate:
+				n.Added++
+			case AuditDelete:
+				n.Removed++
+			default:
+				n.Modified++
+			}
+		}
+		d.Counts = append(d.Counts, n)
+	}
+	return d
+}
+
+// backupRecords indexes a backup's records by entity and ID.
+func backupRecords(b *LibraryBackup) (map[string]map[string]diffRecord, error) {
+	records := map[string]map[string]diffRecord{}
+	for _, e := range DiffEntities {
+		records[e] = map[string]diffRecord{}
+	}
+	add := func(entity, id, documentID, summary string, v any) error {
+		fields, err := jsonFields(v)
+		if err != nil {
+			return fmt.Errorf("%s %s: %w", entity, id, err)
+		}
+		records[entity][id] = diffRecord{documentID: documentID, summary: summary, fields: fields}
+		return nil
+	}
+
+	for _, d := range b.Documents {
+		if err := add("document", d.ID, "", d.Title, d); err != nil {
+			return nil, err
+		}
+	}
+	for _, a := range b.Annotations {
+		if err := add("annotation", a.ID, a.DocumentID, a.Type+": "+truncateRunes(a.Content, 60), a); err != nil {
+			return nil, err
+		}
+	}
+	for _, c := range b.Flashcards {
+		if err := add("flashcard", c.ID, c.DocumentID, truncateRunes(c.Front, 60), c); err != nil {
+			return nil, err
+		}
+	}
+	for _, c := range b.Collections {
+		// Membership is a set; its order is the store's
+		sorted := *c
+		sorted.DocumentIDs = slices.Sorted(slices.Values(c.DocumentIDs))
+		if err := add("collection", c.ID, "", c.Name, &sorted); err != nil {
+			return nil, err
+		}
+	}
+	for _, l := range b.Links {
+		if err := add("link", l.ID, l.FromID, fmt.Sprintf("%s %s %s", l.FromID, l.Relation, l.ToID), l); err != nil {
+			return nil, err
+		}
+	}
+	return records, nil
+}
+
+// changedFields returns the fields, by JSON name, that differ between two
+// versions of a record, sorted, leaving out the update time.
+func changedFields(before, after map[string]json.RawMessage) []string {
+	var fields []string
+	for f := range mergeKeys(before, after) {
+		if f == "updated_at" {
+			continue
+		}
+		o, n := before[f], after[f]
+		if o == nil {
+			o = jsonNull
+		}
+		if n == nil {
+			n = jsonNull
+		}
+		if !bytes.Equal(o, n) {
+			fields = append(fields, f)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
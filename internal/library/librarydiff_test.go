@@ -0,0 +1,155 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+// writeBackup exports s as a backup file, compressed when gz is set.
+func writeBackup(t *testing.T, s LibraryStore, gz bool) string {
+	t.Helper()
+	docs, err := s.ListDocuments(&ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := LookupExporter("backup").Export(&buf, s, docs, ExportOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "backup.json")
+	data := buf.Bytes()
+	if gz {
+		var z bytes.Buffer
+		w := gzip.NewWriter(&z)
+		w.Write(data)
+		w.Close()
+		path, data = path+".gz", z.Bytes()
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDiffBackups(t *testing.T) {
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	keep := &Document{Type: DocTypePaper, Title: "Kept", Meta: JSONMap{"year": 2017}}
+	gone := &Document{Type: DocTypePaper, Title: "Removed"}
+	for _, d := range []*Document{keep, gone} {
+		if err := s.AddDocument(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ann := &Annotation{DocumentID: keep.ID, Type: "highlight", Content: "Claim"}
+	if err := s.AddAnnotation(ann); err != nil {
+		t.Fatal(err)
+	}
+	c, err := s.CreateCollection("thesis", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddToCollection(c.ID, gone.ID); err != nil {
+		t.Fatal(err)
+	}
+	before, err := ReadLibraryBackup(writeBackup(t, s, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(before.Documents) != 2 || len(before.Annotations) != 1 || len(before.Collections) != 1 {
+		t.Fatalf("backup = %+v", before)
+	}
+
+	keep.Tags = []string{"ml"}
+	if err := s.UpdateDocument(keep); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteDocument(gone.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RemoveFromCollection(c.ID, gone.ID); err != nil {
+		t.Fatal(err)
+	}
+	card := &Flashcard{DocumentID: keep.ID, Type: "basic", Front: "What is attention?"}
+	if err := s.AddFlashcard(card); err != nil {
+		t.Fatal(err)
+	}
+	after, err := ReadLibraryBackup(writeBackup(t, s, true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diff, err := DiffBackups(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, ch := range diff.Changes {
+		got = append(got, fmt.Sprintf("%s %s %s %v", ch.Action, ch.Entity, ch.Summary, ch.Fields))
+	}
+	want := []string{
+		"delete document Removed []",
+		"update document Kept [tags]",
+		"create flashcard What is attention? []",
+		"update collection thesis [added_at document_ids]",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("changes:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if n := diff.Counts[0]; n != (DiffCount{Entity: "document", Removed: 1, Modified: 1}) {
+		t.Errorf("document counts = %+v", n)
+	}
+
+	if same, _ := DiffBackups(after, after); len(same.Changes) != 0 {
+		t.Errorf("a backup differs from itself: %+v", same.Changes)
+	}
+}
+
+func TestReadLibraryBackup(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"newer.json":  `{"version": 99, "documents": []}`,
+		"other.json":  `[{"id": "d1"}]`,
+		"nothing.txt": `not json`,
+	} {
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, []byte(content), 0o644)
+		if _, err := ReadLibraryBackup(path); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestDiffAuditLog(t *testing.T) {
+	at := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	// Newest first, as ListAuditEntries returns them
+	entries := []*AuditEntry{
+		{Entity: "annotation", EntityID: "a2", Action: AuditDelete, CreatedAt: at.Add(5 * time.Minute)},
+		{Entity: "document", EntityID: "d1", Action: AuditUpdate, Summary: "Paper", Fields: []string{"title"}, CreatedAt: at.Add(4 * time.Minute)},
+		{Entity: "annotation", EntityID: "a2", Action: AuditCreate, Summary: "note: gone again", CreatedAt: at.Add(3 * time.Minute)},
+		{Entity: "task", EntityID: "t1", Action: AuditCreate, CreatedAt: at.Add(2 * time.Minute)},
+		{Entity: "document", EntityID: "d1", Action: AuditUpdate, Summary: "tag +ml", Fields: []string{"tags"}, CreatedAt: at.Add(time.Minute)},
+		{Entity: "document", EntityID: "d2", Action: AuditDelete, Summary: "Old", CreatedAt: at},
+	}
+	diff := DiffAuditLog(entries)
+	var got []string
+	for _, ch := range diff.Changes {
+		got = append(got, fmt.Sprintf("%s %s %s %v", ch.Action, ch.ID, ch.Summary, ch.Fields))
+	}
+	if want := "delete d2 Old []\nupdate d1 Paper [tags title]"; strings.Join(got, "\n") != want {
+		t.Errorf("changes:\n%s\nwant:\n%s", strings.Join(got, "\n"), want)
+	}
+}
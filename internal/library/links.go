@@ -0,0 +1,44 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LinkRelations lists the supported document relations.
+var LinkRelations = []LinkRelation{LinkSupersedes, LinkDuplicateOf, LinkPartOf, LinkRespondsTo, LinkTranslationOf, LinkCites, LinkReferences}
+
+var linkInverses = map[LinkRelation]string{
+	LinkSupersedes:    "superseded-by",
+	LinkDuplicateOf:   "duplicated-by",
+	LinkPartOf:        "has-part",
+	LinkRespondsTo:    "responded-to-by",
+	LinkTranslationOf: "translated-as",
+	LinkCites:         "cited-by",
+	LinkReferences:    "referenced-by",
+}
+
+// ParseLinkRelation validates a relation name, case-insensitively.
+func ParseLinkRelation(s string) (LinkRelation, error) {
+	r := LinkRelation(strings.ToLower(strings.TrimSpace(s)))
+	if _, ok := linkInverses[r]; ok {
+		return r, nil
+	}
+	names := make([]string, len(LinkRelations))
+	for i, r := range LinkRelations {
+		names[i] = string(r)
+	}
+	return "", fmt.Errorf("unknown relation %q (use %s)", s, strings.Join(names, ", "))
+}
+
+// Inverse names the relation as seen from the target document, e.g.
+// "superseded-by" for supersedes.
+func (r LinkRelation) Inverse() string {
+	if inv, ok := linkInverses[r]; ok {
+		return inv
+	}
+	return string(r) + " (inverse)"
+}
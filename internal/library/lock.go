@@ -0,0 +1,72 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrLocked is returned by LockLibrary while another process holds the
+// lock.
+var ErrLocked = errors.New("locked by another process")
+
+// errLockHeld is what lockFile returns when another process holds the
+// lock.
+var errLockHeld = errors.New("lock held")
+
+// LibraryLock is a process's exclusive hold on a library, so that only one
+// process writes to it at a time. It lasts until Unlock or the process
+// exits.
+type LibraryLock struct {
+	f *os.File
+}
+
+// LockPath returns the lock file of the library in DatabasePath.
+func LockPath() string {
+	return DatabasePath() + ".lock"
+}
+
+// LockLibrary takes the lock at path without waiting, writing who holds it
+// into the file. It fails with ErrLocked, naming the holder, when another
+// process has it. Where file locks aren't supported, it always succeeds.
+func LockLibrary(path string) (*LibraryLock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f); err != nil {
+		defer f.Close()
+		if errors.Is(err, errLockHeld) {
+			holder, _ := io.ReadAll(io.LimitReader(f, 512))
+			if h := strings.TrimSpace(string(holder)); h != "" {
+				return nil, fmt.Errorf("%w (%s)", ErrLocked, h)
+			}
+			return nil, ErrLocked
+		}
+		return nil, fmt.Errorf("lock %s: %w", path, err)
+	}
+	// Best-effort: the holder is only named in others' errors
+	if err := f.Truncate(0); err == nil {
+		fmt.Fprintf(f, "pid %d: %s\n", os.Getpid(), truncateRunes(strings.Join(append([]string{filepath.Base(os.Args[0])}, os.Args[1:]...), " "), 120))
+	}
+	return &LibraryLock{f: f}, nil
+}
+
+// Unlock releases the lock.
+func (l *LibraryLock) Unlock() error {
+	_ = l.f.Truncate(0)
+	if err := unlockFile(l.f); err != nil {
+		l.f.Close()
+		return err
+	}
+	return l.f.Close()
+}
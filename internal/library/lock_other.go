@@ -0,0 +1,14 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+//go:build !unix
+
+package library
+
+import "os"
+
+// Without flock, libraries aren't locked; --read-only still works.
+
+func lockFile(f *os.File) error { return nil }
+
+func unlockFile(f *os.File) error { return nil }
@@ -0,0 +1,110 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+func init() { RegisterExporter(markdownExporter{}) }
+
+// markdownExporter writes a Markdown notes collection with each
+// document's notes and annotations.
+type markdownExporter struct{}
+
+func (markdownExporter) Format() Format {
+	return Format{
+		Name:         "markdown",
+		Description:  "Markdown notes for Obsidian and other note apps",
+		Extensions:   []string{".md"},
+		Capabilities: []string{FormatTags, FormatAnnotations},
+	}
+}
+
+func (markdownExporter) Export(w io.Writer, store LibraryStore, docs []*Document, opts ExportOptions) error {
+	if opts.GroupBy != "" && opts.GroupBy != "meaning" {
+		return fmt.Errorf("unknown markdown grouping %q (use meaning)", opts.GroupBy)
+	}
+	var buf bytes.Buffer
+
+	buf.WriteString("# Library Export\n\n")
+	buf.WriteString(fmt.Sprintf("Generated: %s\n\n", time.Now().Format(time.RFC3339)))
+	buf.WriteString(fmt.Sprintf("Total documents: %d\n\n---\n\n", len(docs)))
+
+	for i, doc := range docs {
+		writeMarkdownDocument(&buf, store, doc, opts, "##")
+		buf.WriteString("---\n\n")
+		if opts.OnProgress != nil {
+			opts.OnProgress(i+1, len(docs))
+		}
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// writeMarkdownDocument writes a document's metadata, notes, and
+// annotations under a heading of the given level, such as "##".
+func writeMarkdownDocument(buf *bytes.Buffer, store LibraryStore, doc *Document, opts ExportOptions, heading string) {
+	buf.WriteString(fmt.Sprintf("%s %s\n\n", heading, doc.Title))
+
+	// Metadata
+	buf.WriteString("**Type:** " + string(doc.Type) + "\n\n")
+	if len(doc.Authors) > 0 {
+		buf.WriteString("**Authors:** " + strings.Join(doc.Authors, ", ") + "\n\n")
+	}
+	if doc.Source != "" {
+		buf.WriteString(fmt.Sprintf("**Source:** %s %s\n\n", doc.Source, doc.SourceID))
+	}
+	if doc.Abstract != "" {
+		buf.WriteString("**Abstract**\n\n")
+		buf.WriteString(doc.Abstract + "\n\n")
+	}
+	if doc.FullText != "" {
+		buf.WriteString("**Full Text**\n\n")
+		buf.WriteString(doc.FullText[:min(2000, len(doc.FullText))] + "...\n\n")
+	}
+	if len(doc.Tags) > 0 {
+		buf.WriteString("**Tags:** " + strings.Join(doc.Tags, ", ") + "\n\n")
+	}
+	if doc.Notes != "" {
+		buf.WriteString("**Notes**\n\n")
+		buf.WriteString(doc.Notes + "\n\n")
+	}
+	if doc.Rating > 0 {
+		buf.WriteString(fmt.Sprintf("**Rating:** %d/5\n\n", doc.Rating))
+	}
+
+	// Annotations for this document
+	anns, _ := store.GetAnnotations(doc.ID)
+	if len(anns) > 0 {
+		buf.WriteString(heading + "# Annotations\n\n")
+		if opts.GroupBy == "meaning" {
+			for _, g := range GroupAnnotationsByMeaning(anns, opts.ColorMeanings) {
+				meaning := g.Meaning
+				if meaning == "" {
+					meaning = "Other"
+				}
+				buf.WriteString(heading + "## " + meaning + "\n\n")
+				writeMarkdownAnnotations(buf, g.Annotations)
+			}
+		} else {
+			writeMarkdownAnnotations(buf, anns)
+		}
+	}
+}
+
+func writeMarkdownAnnotations(buf *bytes.Buffer, anns []*Annotation) {
+	for _, a := range anns {
+		buf.WriteString(fmt.Sprintf("- [%s] %s\n", a.Type, a.Content))
+		if a.Page > 0 {
+			buf.WriteString(fmt.Sprintf("  (page %d)\n", a.Page))
+		}
+		buf.WriteString("\n")
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MarkdownSplits are what ExportMarkdownTree can split a library by.
+var MarkdownSplits = []string{"collection", "tag", "type"}
+
+// MarkdownTreeResult is what ExportMarkdownTree wrote.
+type MarkdownTreeResult struct {
+	Dir       string `json:"dir"`
+	Groups    int    `json:"groups"`
+	Documents int    `json:"documents"`
+	Files     int    `json:"files"` // document files, a document in several groups counting once per group
+}
+
+// markdownGroup is a directory of the tree and the documents in it.
+type markdownGroup struct {
+	name string
+	dir  string
+	docs []*Document
+}
+
+// ExportMarkdownTree writes docs as a directory of Markdown files under
+// dir, one subdirectory per collection, tag, or document type as splitBy
+// says, for publishing or importing into a notes vault. Each document is
+// a file of its own, written as the markdown exporter writes it, in every
+// group it belongs to; documents in no collection, without tags, or
+// without a type go in "Unsorted", "Untagged", or "Other". Each directory has an index.md linking its
+// files, and dir/index.md links every group. Files already in dir are
+// overwritten but not removed.
+func ExportMarkdownTree(s LibraryStore, docs []*Document, dir, splitBy string, opts ExportOptions) (*MarkdownTreeResult, error) {
+	if opts.GroupBy != "" && opts.GroupBy != "meaning" {
+		return nil, fmt.Errorf("unknown markdown grouping %q (use meaning)", opts.GroupBy)
+	}
+	groups, err := splitMarkdownDocuments(s, docs, splitBy)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &MarkdownTreeResult{Dir: dir, Groups: len(groups), Documents: len(docs)}
+	var index bytes.Buffer
+	index.WriteString("# Library Export\n\n")
+	index.WriteString(fmt.Sprintf("Generated: %s\n\n", time.Now().Format(time.RFC3339)))
+	index.WriteString(fmt.Sprintf("Total documents: %d, by %s\n\n", len(docs), splitBy))
+
+	for _, g := range groups {
+		groupDir := filepath.Join(dir, g.dir)
+		if err := os.MkdirAll(groupDir, 0o755); err != nil {
+			return res, err
+		}
+		var groupIndex bytes.Buffer
+		groupIndex.WriteString(fmt.Sprintf("# %s\n\n", g.name))
+		index.WriteString(fmt.Sprintf("## [%s](<%s/index.md>)\n\n", g.name, g.dir))
+
+		used := map[string]bool{"index.md": true}
+		for _, doc := range g.docs {
+			name := markdownFileName(doc, used)
+			var buf bytes.Buffer
+			writeMarkdownDocument(&buf, s, doc, opts, "#")
+			if err := os.WriteFile(filepath.Join(groupDir, name), buf.Bytes(), 0o644); err != nil {
+				return res, err
+			}
+			res.Files++
+			groupIndex.WriteString(fmt.Sprintf("- [%s](<%s>)\n", doc.Title, name))
+			index.WriteString(fmt.Sprintf("- [%s](<%s/%s>)\n", doc.Title, g.dir, name))
+		}
+		index.WriteString("\n")
+		if err := os.WriteFile(filepath.Join(groupDir, "index.md"), groupIndex.Bytes(), 0o644); err != nil {
+			return res, err
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return res, err
+	}
+	return res, os.WriteFile(filepath.Join(dir, "index.md"), index.Bytes(), 0o644)
+}
+
+// splitMarkdownDocuments groups docs by splitBy, groups sorted by name
+// with the documents in none last.
+func splitMarkdownDocuments(s LibraryStore, docs []*Document, splitBy string) ([]*markdownGroup, error) {
+	var keys func(*Document) []string
+	var rest string
+	switch splitBy {
+	case "collection":
+		colls, err := s.ListCollections()
+		if err != nil {
+			return nil, err
+		}
+		byDoc := map[string][]string{}
+		for _, c := range colls {
+			for _, id := range c.DocumentIDs {
+				byDoc[id] = append(byDoc[id], c.Name)
+			}
+		}
+		keys = func(d *Document) []string { return byDoc[d.ID] }
+		rest = "Unsorted"
+	case "tag":
+		keys = func(d *Document) []string { return d.Tags }
+		rest = "Untagged"
+	case "type":
+		keys = func(d *Document) []string { return []string{string(d.Type)} }
+		rest = "Other"
+	default:
+		return nil, fmt.Errorf("unknown split %q (use %s)", splitBy, strings.Join(MarkdownSplits, ", "))
+	}
+
+	byName := map[string]*markdownGroup{}
+	var names []string
+	var ungrouped []*Document
+	for _, doc := range docs {
+		seen := map[string]bool{}
+		for _, k := range keys(doc) {
+			if k == "" || seen[k] {
+				continue
+			}
+			seen[k] = true
+			g := byName[k]
+			if g == nil {
+				g = &markdownGroup{name: k}
+				byName[k] = g
+				names = append(names, k)
+			}
+			g.docs = append(g.docs, doc)
+		}
+		if len(seen) == 0 {
+			ungrouped = append(ungrouped, doc)
+		}
+	}
+	sort.Strings(names)
+
+	groups := make([]*markdownGroup, 0, len(names)+1)
+	for _, n := range names {
+		groups = append(groups, byName[n])
+	}
+	if len(ungrouped) > 0 {
+		groups = append(groups, &markdownGroup{name: rest, docs: ungrouped})
+	}
+
+	// Names differing only in characters unsafe in paths get their own
+	// directories too
+	usedDirs := map[string]bool{}
+	for _, g := range groups {
+		base := markdownPathName(g.name)
+		g.dir = base
+		for i := 2; usedDirs[strings.ToLower(g.dir)]; i++ {
+			g.dir = fmt.Sprintf("%s (%d)", base, i)
+		}
+		usedDirs[strings.ToLower(g.dir)] = true
+	}
+	return groups, nil
+}
+
+// markdownFileName returns a file name for doc after its title that is
+// not in used, ignoring case for case-insensitive file systems, and adds
+// it to used.
+func markdownFileName(doc *Document, used map[string]bool) string {
+	title := doc.Title
+	if title == "" {
+		title = doc.ID
+	}
+	base := markdownPathName(title)
+	name := base + ".md"
+	for i := 2; used[strings.ToLower(name)]; i++ {
+		name = fmt.Sprintf("%s (%d).md", base, i)
+	}
+	used[strings.ToLower(name)] = true
+	return name
+}
+
+// markdownPathName makes a collection, tag, or title usable as a file or
+// directory name, keeping a slash in it from nesting directories.
+func markdownPathName(name string) string {
+	name = strings.Trim(strings.TrimSpace(unsafeFileChars.ReplaceAllString(name, "_")), ".")
+	if name == "" {
+		return "_"
+	}
+	return name
+}
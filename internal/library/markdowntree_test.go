@@ -0,0 +1,126 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+func TestExportMarkdownTree(t *testing.T) {
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	add := func(title string, typ DocumentType, tags ...string) *Document {
+		t.Helper()
+		d := &Document{Type: typ, Title: title, Tags: tags}
+		if err := s.AddDocument(d); err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	attention := add("Attention Is All You Need", DocTypePaper, "ml", "nlp")
+	bert := add("BERT: Pre-training", DocTypePaper, "nlp")
+	dup := add("Attention Is All You Need", DocTypeArticle, "ml")
+	add("Loose notes", DocTypeArticle)
+	if err := s.AddAnnotation(&Annotation{DocumentID: attention.ID, Type: "highlight", Content: "Scaled dot-product"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, ids := range map[string][]string{
+		"Thesis/Chapter 2": {attention.ID, bert.ID},
+		"Reading":          {dup.ID},
+	} {
+		c, err := s.CreateCollection(name, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, id := range ids {
+			if err := s.AddToCollection(c.ID, id); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	docs, err := s.ListDocuments(&ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	read := func(path string) string {
+		t.Helper()
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	t.Run("tag", func(t *testing.T) {
+		dir := t.TempDir()
+		res, err := ExportMarkdownTree(s, docs, dir, "tag", ExportOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// ml, nlp, and Untagged; the two papers with the same title share ml
+		if res.Groups != 3 || res.Files != 5 || res.Documents != 4 {
+			t.Errorf("result = %+v, want 3 groups, 5 files, 4 documents", res)
+		}
+		ml := read(filepath.Join(dir, "ml", "index.md"))
+		if !strings.Contains(ml, "(<Attention Is All You Need.md>)") || !strings.Contains(ml, "(<Attention Is All You Need (2).md>)") {
+			t.Errorf("ml index does not link both same-titled documents:\n%s", ml)
+		}
+		doc := read(filepath.Join(dir, "nlp", "Attention Is All You Need.md"))
+		if !strings.HasPrefix(doc, "# Attention Is All You Need\n") || !strings.Contains(doc, "## Annotations\n\n- [highlight] Scaled dot-product") {
+			t.Errorf("document file:\n%s", doc)
+		}
+		index := read(filepath.Join(dir, "index.md"))
+		for _, want := range []string{"## [ml](<ml/index.md>)", "- [BERT: Pre-training](<nlp/BERT_ Pre-training.md>)", "## [Untagged](<Untagged/index.md>)"} {
+			if !strings.Contains(index, want) {
+				t.Errorf("index missing %q:\n%s", want, index)
+			}
+		}
+		if strings.Index(index, "[nlp]") > strings.Index(index, "[Untagged]") {
+			t.Errorf("Untagged should come last:\n%s", index)
+		}
+	})
+
+	t.Run("collection", func(t *testing.T) {
+		dir := t.TempDir()
+		res, err := ExportMarkdownTree(s, docs, dir, "collection", ExportOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Groups != 3 {
+			t.Errorf("groups = %d, want Reading, Thesis/Chapter 2, Unsorted", res.Groups)
+		}
+		// The slash in the collection name must not nest directories
+		if _, err := os.Stat(filepath.Join(dir, "Thesis_Chapter 2", "BERT_ Pre-training.md")); err != nil {
+			t.Error(err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "Unsorted", "Loose notes.md")); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("type", func(t *testing.T) {
+		dir := t.TempDir()
+		res, err := ExportMarkdownTree(s, docs, dir, "type", ExportOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Groups != 2 || res.Files != 4 {
+			t.Errorf("result = %+v, want 2 groups and 4 files", res)
+		}
+	})
+
+	if _, err := ExportMarkdownTree(s, docs, t.TempDir(), "author", ExportOptions{}); err == nil {
+		t.Error("expected an error for an unknown split")
+	}
+}
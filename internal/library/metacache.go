@@ -0,0 +1,113 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultMetadataTTL is how long cached resolver responses are used before
+// they are fetched again.
+const DefaultMetadataTTL = 30 * 24 * time.Hour
+
+// ErrOffline is returned for network fetches in offline mode.
+var ErrOffline = errors.New("offline")
+
+// MetadataCache keeps the responses of the metadata resolvers (Crossref,
+// PubMed, arXiv, ...) on disk, one file per request URL, so repeated
+// lookups of the same identifier don't hit the network. Only successful
+// responses are cached.
+type MetadataCache struct {
+	Dir string
+	// TTL is how long a response stays fresh; 0 always refetches, but the
+	// cache is still filled for offline use.
+	TTL time.Duration
+	// Offline answers lookups from the cache only, however old the entry,
+	// and fails the rest with ErrOffline. Downloads fail too.
+	Offline bool
+}
+
+// metadataCache is used by the resolvers; nil fetches every time.
+var metadataCache *MetadataCache
+
+// SetMetadataCache makes the resolvers use c, or no cache when c is nil.
+func SetMetadataCache(c *MetadataCache) {
+	metadataCache = c
+}
+
+// LimitMetadataAge treats cached responses older than maxAge as stale, for
+// commands that want fresher metadata than the cache's TTL gives.
+func LimitMetadataAge(maxAge time.Duration) {
+	if metadataCache != nil && maxAge < metadataCache.TTL {
+		c := *metadataCache
+		c.TTL = maxAge
+		metadataCache = &c
+	}
+}
+
+// MetadataCacheDir returns the managed cache directory for resolver
+// responses: $ARC_LIBRARY_CACHE_DIR/metadata, or arc-library/metadata under
+// the user cache directory.
+func MetadataCacheDir() (string, error) {
+	base, err := CacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "metadata"), nil
+}
+
+func (c *MetadataCache) path(rawURL string) string {
+	sum := sha256.Sum256([]byte(rawURL))
+	return filepath.Join(c.Dir, hex.EncodeToString(sum[:]))
+}
+
+// Get returns the cached response for rawURL and its age.
+func (c *MetadataCache) Get(rawURL string) ([]byte, time.Duration, bool) {
+	p := c.path(rawURL)
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, 0, false
+	}
+	body, err := os.ReadFile(p)
+	if err != nil {
+		return nil, 0, false
+	}
+	return body, time.Since(info.ModTime()), true
+}
+
+// Put stores the response for rawURL, replacing any earlier one.
+func (c *MetadataCache) Put(rawURL string, body []byte) error {
+	if err := os.MkdirAll(c.Dir, 0o755); err != nil {
+		return fmt.Errorf("create cache directory: %w", err)
+	}
+	// Write then rename so concurrent readers never see a partial entry
+	tmp, err := os.CreateTemp(c.Dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(body); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path(rawURL))
+}
+
+// checkOnline fails with ErrOffline when the resolvers are in offline mode.
+func checkOnline(rawURL string) error {
+	if metadataCache != nil && metadataCache.Offline {
+		return fmt.Errorf("%w: not fetching %s", ErrOffline, rawURL)
+	}
+	return nil
+}
@@ -4,14 +4,14 @@
 package library
 
 import (
-	"bytes"
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
-	"io"
-	"net/http"
-	"os/exec"
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
-	"time"
 )
 
 // DOIResolver resolves a DOI to document metadata using Crossref API.
@@ -23,24 +23,9 @@ func DOIResolver(doi string) (JSONMap, error) {
 	}
 
 	// Use Crossref API: https://api.crossref.org/works/<doi>
-	url := "https://api.crossref.org/works/" + strings.TrimPrefix(doi, "https://doi.org/")
-	// It's okay to not set User-Agent; but Crossref requires a User-Agent. Let's set a generic one.
-	req, err := http.NewRequest("GET", url, nil)
+	body, err := fetchMetadata(crossrefAPI + "/" + strings.TrimPrefix(doi, "https://doi.org/"))
 	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
-	}
-	req.Header.Set("User-Agent", "arc-library/1.0 (mailto:you@example.com)")
-
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("query doi: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("DOI lookup failed: %s: %s", resp.Status, string(body))
+		return nil, fmt.Errorf("DOI lookup failed: %w", err)
 	}
 
 	var envelope struct {
@@ -62,7 +47,7 @@ func DOIResolver(doi string) (JSONMap, error) {
 			JournalTitle string `json:"container-title"`
 		} `json:"message"`
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+	if err := json.Unmarshal(body, &envelope); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
 
@@ -109,22 +94,525 @@ func DOIResolver(doi string) (JSONMap, error) {
 	return meta, nil
 }
 
+// Identifier sources recognized by ParseIdentifier.
+const (
+	IDSourceDOI     = "doi"
+	IDSourcePubMed  = "pubmed"
+	IDSourcePMC     = "pmc"
+	IDSourceBioRxiv = "biorxiv" // also medRxiv, which shares the 10.1101 prefix
+	IDSourceArxiv   = "arxiv"
+	IDSourceISBN    = "isbn"
+)
+
+// Endpoints the resolvers query; variables so tests can point them at a
+// local server.
+var (
+	crossrefAPI    = "https://api.crossref.org/works"
+	pubMedAPI      = "https://eutils.ncbi.nlm.nih.gov/entrez/eutils/efetch.fcgi"
+	pmcIDConvAPI   = "https://www.ncbi.nlm.nih.gov/pmc/utils/idconv/v1.0/"
+	bioRxivAPI     = "https://api.biorxiv.org/details"
+	arxivAPI       = "https://export.arxiv.org/api/query"
+	openLibraryAPI = "https://openlibrary.org/api/books"
+	unpaywallAPI   = "https://api.unpaywall.org/v2"
+)
+
+var (
+	pmidPattern       = regexp.MustCompile(`(?i)^(?:pmid:?\s*)?(\d{1,9})$`)
+	pmcidPattern      = regexp.MustCompile(`(?i)^(?:pmcid:?\s*)?(PMC\d+)$`)
+	doiPattern        = regexp.MustCompile(`^10\.\d{4,9}/\S+$`)
+	bioRxivURLPattern = regexp.MustCompile(`^https?://(?:www\.)?(?:bio|med)rxiv\.org/content/(10\.1101/[^?#]+?)(?:v\d+)?(?:\.full(?:\.pdf)?)?/?$`)
+	arxivIDPattern    = regexp.MustCompile(`(?i)^(?:arxiv:\s*|https?://(?:www\.)?arxiv\.org/(?:abs|pdf)/)?(\d{4}\.\d{4,5}|[a-z-]+(?:\.[a-z]{2})?/\d{7})(?:v\d+)?(?:\.pdf)?/?$`)
+	isbnPattern       = regexp.MustCompile(`^(?:\d{9}[\dX]|97[89]\d{10})$`)
+)
+
+// ParseIdentifier recognizes a PMID ("12345678", "PMID:12345678"), PMC ID
+// ("PMC1234567"), DOI ("10.1234/x", "doi:10.1234/x", a doi.org URL),
+// bioRxiv and medRxiv DOIs and article URLs, arXiv IDs and URLs
+// ("2304.00067", "arXiv:hep-th/9901001"), and ISBN-10s and ISBN-13s. It
+// returns the identifier's source and its normalized form; arXiv IDs lose
+// their version.
+func ParseIdentifier(s string) (source, id string, err error) {
+	s = strings.TrimSpace(s)
+	if m := bioRxivURLPattern.FindStringSubmatch(s); m != nil {
+		return IDSourceBioRxiv, m[1], nil
+	}
+	if m := arxivIDPattern.FindStringSubmatch(s); m != nil {
+		return IDSourceArxiv, m[1], nil
+	}
+	isbn := strings.NewReplacer("-", "", " ", "").Replace(strings.ToUpper(s))
+	isbn = strings.TrimPrefix(isbn, "ISBN:")
+	isbn = strings.TrimPrefix(isbn, "ISBN")
+	if isbnPattern.MatchString(isbn) && validISBN(isbn) {
+		return IDSourceISBN, isbn, nil
+	}
+	if m := pmidPattern.FindStringSubmatch(s); m != nil {
+		return IDSourcePubMed, m[1], nil
+	}
+	if m := pmcidPattern.FindStringSubmatch(s); m != nil {
+		return IDSourcePMC, strings.ToUpper(m[1]), nil
+	}
+	doi := s
+	for _, prefix := range []string{"https://doi.org/", "http://doi.org/", "https://dx.doi.org/", "doi:"} {
+		if len(doi) >= len(prefix) && strings.EqualFold(doi[:len(prefix)], prefix) {
+			doi = doi[len(prefix):]
+		}
+	}
+	if doiPattern.MatchString(doi) {
+		if strings.HasPrefix(doi, "10.1101/") {
+			return IDSourceBioRxiv, doi, nil
+		}
+		return IDSourceDOI, doi, nil
+	}
+	return "", "", fmt.Errorf("unrecognized identifier %q (expected a DOI, PMID, PMC ID, bioRxiv DOI, arXiv ID, or ISBN)", s)
+}
+
+// validISBN checks the check digit of an ISBN-10 or ISBN-13 without
+// hyphens.
+func validISBN(isbn string) bool {
+	sum := 0
+	if len(isbn) == 10 {
+		for i, c := range isbn {
+			v := int(c - '0')
+			if c == 'X' {
+				v = 10
+			}
+			sum += (10 - i) * v
+		}
+		return sum%11 == 0
+	}
+	for i, c := range isbn {
+		v := int(c - '0')
+		if i%2 == 1 {
+			v *= 3
+		}
+		sum += v
+	}
+	return sum%10 == 0
+}
+
+// ResolveIdentifier fetches metadata for an identifier from ParseIdentifier,
+// in the form DOIResolver returns. Other identifiers found along the way are
+// added as "doi", "pmid", and "pmcid".
+func ResolveIdentifier(source, id string) (JSONMap, error) {
+	switch source {
+	case IDSourceDOI:
+		meta, err := DOIResolver(id)
+		if err != nil {
+			return nil, err
+		}
+		meta["doi"] = id
+		return meta, nil
+	case IDSourcePubMed:
+		return PubMedResolver(id)
+	case IDSourcePMC:
+		return PMCResolver(id)
+	case IDSourceBioRxiv:
+		return BioRxivResolver(id)
+	case IDSourceArxiv:
+		return ArxivResolver(id)
+	case IDSourceISBN:
+		return ISBNResolver(id)
+	}
+	return nil, fmt.Errorf("no resolver for %s identifiers", source)
+}
+
+// pubMedArticle is the part of a PubMed efetch record that is imported.
+type pubMedArticle struct {
+	Article struct {
+		Title    innerXML `xml:"ArticleTitle"`
+		Abstract []struct {
+			Label string `xml:"Label,attr"`
+			XML   string `xml:",innerxml"`
+		} `xml:"Abstract>AbstractText"`
+		Authors []struct {
+			LastName       string `xml:"LastName"`
+			ForeName       string `xml:"ForeName"`
+			CollectiveName string `xml:"CollectiveName"`
+		} `xml:"AuthorList>Author"`
+		Journal struct {
+			Title       string `xml:"Title"`
+			Year        string `xml:"JournalIssue>PubDate>Year"`
+			MedlineDate string `xml:"JournalIssue>PubDate>MedlineDate"`
+		} `xml:"Journal"`
+	} `xml:"MedlineCitation>Article"`
+	PMID       string `xml:"MedlineCitation>PMID"`
+	ArticleIDs []struct {
+		Type  string `xml:"IdType,attr"`
+		Value string `xml:",chardata"`
+	} `xml:"PubmedData>ArticleIdList>ArticleId"`
+}
+
+// innerXML is element content that may hold inline markup such as <i>.
+type innerXML struct {
+	XML string `xml:",innerxml"`
+}
+
+var xmlTagPattern = regexp.MustCompile(`<[^>]+>`)
+
+// Text strips the markup and collapses whitespace.
+func (x innerXML) Text() string {
+	return strings.Join(strings.Fields(html.UnescapeString(xmlTagPattern.ReplaceAllString(x.XML, ""))), " ")
+}
+
+// PubMedResolver resolves a PMID to document metadata using NCBI
+// E-utilities, including the abstract.
+func PubMedResolver(pmid string) (JSONMap, error) {
+	q := url.Values{"db": {"pubmed"}, "id": {pmid}, "retmode": {"xml"}}
+	body, err := fetchMetadata(pubMedAPI + "?" + q.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("PubMed lookup failed: %w", err)
+	}
+	var set struct {
+		Articles []pubMedArticle `xml:"PubmedArticle"`
+	}
+	if err := xml.Unmarshal(body, &set); err != nil {
+		return nil, fmt.Errorf("decode PubMed record: %w", err)
+	}
+	if len(set.Articles) == 0 {
+		return nil, fmt.Errorf("PMID %s not found", pmid)
+	}
+	rec := set.Articles[0]
+	a := rec.Article
+
+	meta := JSONMap{"pmid": rec.PMID, "url": "https://pubmed.ncbi.nlm.nih.gov/" + rec.PMID + "/"}
+	if title := strings.TrimSuffix(a.Title.Text(), "."); title != "" {
+		meta["title"] = title
+	}
+	authors := make([]string, 0, len(a.Authors))
+	for _, au := range a.Authors {
+		switch {
+		case au.CollectiveName != "":
+			authors = append(authors, au.CollectiveName)
+		case au.ForeName != "":
+			authors = append(authors, au.ForeName+" "+au.LastName)
+		case au.LastName != "":
+			authors = append(authors, au.LastName)
+		}
+	}
+	meta["authors"] = authors
+
+	var parts []string
+	for _, p := range a.Abstract {
+		text := innerXML{p.XML}.Text()
+		if p.Label != "" {
+			text = p.Label + ": " + text
+		}
+		parts = append(parts, text)
+	}
+	if len(parts) > 0 {
+		meta["abstract"] = strings.Join(parts, "\n\n")
+	}
+
+	if a.Journal.Title != "" {
+		meta["journal"] = a.Journal.Title
+	}
+	year := a.Journal.Year
+	if year == "" && len(a.Journal.MedlineDate) >= 4 {
+		year = a.Journal.MedlineDate[:4] // e.g. "1998 Dec-1999 Jan"
+	}
+	if y, err := strconv.Atoi(year); err == nil {
+		meta["year"] = y
+	}
+	for _, id := range rec.ArticleIDs {
+		switch id.Type {
+		case "doi":
+			meta["doi"] = id.Value
+		case "pmc":
+			meta["pmcid"] = id.Value
+		}
+	}
+	return meta, nil
+}
+
+// PMCResolver resolves a PMC ID to document metadata through the PubMed
+// record it maps to, or Crossref when it has only a DOI.
+func PMCResolver(pmcid string) (JSONMap, error) {
+	q := url.Values{"ids": {pmcid}, "format": {"json"}, "tool": {"arc-library"}}
+	body, err := fetchMetadata(pmcIDConvAPI + "?" + q.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("PMC ID lookup failed: %w", err)
+	}
+	var conv struct {
+		Records []struct {
+			PMID   string `json:"pmid"`
+			DOI    string `json:"doi"`
+			Status string `json:"status"`
+		} `json:"records"`
+	}
+	if err := json.Unmarshal(body, &conv); err != nil {
+		return nil, fmt.Errorf("decode PMC ID lookup: %w", err)
+	}
+	if len(conv.Records) == 0 || conv.Records[0].Status == "error" {
+		return nil, fmt.Errorf("%s not found", pmcid)
+	}
+
+	rec := conv.Records[0]
+	var meta JSONMap
+	switch {
+	case rec.PMID != "":
+		meta, err = PubMedResolver(rec.PMID)
+	case rec.DOI != "":
+		meta, err = ResolveIdentifier(IDSourceDOI, rec.DOI)
+	default:
+		return nil, fmt.Errorf("%s has no PMID or DOI to resolve", pmcid)
+	}
+	if err != nil {
+		return nil, err
+	}
+	meta["pmcid"] = pmcid
+	meta["url"] = "https://www.ncbi.nlm.nih.gov/pmc/articles/" + pmcid + "/"
+	return meta, nil
+}
+
+// BioRxivResolver resolves a bioRxiv or medRxiv DOI to the metadata of the
+// preprint's latest version, including the abstract.
+func BioRxivResolver(doi string) (JSONMap, error) {
+	type preprint struct {
+		DOI       string `json:"doi"`
+		Title     string `json:"title"`
+		Authors   string `json:"authors"` // "Last, F.; Last, F."
+		Date      string `json:"date"`
+		Version   string `json:"version"`
+		Abstract  string `json:"abstract"`
+		Published string `json:"published"` // journal DOI, or "NA"
+		Server    string `json:"server"`
+	}
+	var latest *preprint
+	for _, server := range []string{"biorxiv", "medrxiv"} {
+		body, err := fetchMetadata(bioRxivAPI + "/" + server + "/" + doi)
+		if err != nil {
+			return nil, fmt.Errorf("bioRxiv lookup failed: %w", err)
+		}
+		var resp struct {
+			Collection []preprint `json:"collection"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil, fmt.Errorf("decode bioRxiv record: %w", err)
+		}
+		if n := len(resp.Collection); n > 0 {
+			// Versions are listed oldest first
+			latest = &resp.Collection[n-1]
+			if latest.Server == "" {
+				latest.Server = server
+			}
+			break
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("DOI %s not found on bioRxiv or medRxiv", doi)
+	}
+
+	journal := "bioRxiv"
+	if strings.EqualFold(latest.Server, "medrxiv") {
+		journal = "medRxiv"
+	}
+	meta := JSONMap{
+		"title":   strings.TrimSpace(latest.Title),
+		"doi":     doi,
+		"journal": journal,
+		"url":     fmt.Sprintf("https://www.%s.org/content/%sv%s", strings.ToLower(journal), doi, latest.Version),
+	}
+	authors := []string{}
+	for _, a := range strings.Split(latest.Authors, ";") {
+		last, first, _ := strings.Cut(strings.TrimSpace(a), ",")
+		if name := strings.TrimSpace(strings.TrimSpace(first) + " " + strings.TrimSpace(last)); name != "" {
+			authors = append(authors, name)
+		}
+	}
+	meta["authors"] = authors
+	if abstract := strings.TrimSpace(latest.Abstract); abstract != "" {
+		meta["abstract"] = abstract
+	}
+	if len(latest.Date) >= 4 {
+		if y, err := strconv.Atoi(latest.Date[:4]); err == nil {
+			meta["year"] = y
+		}
+	}
+	if latest.Published != "" && latest.Published != "NA" {
+		meta["published_doi"] = latest.Published
+	}
+	return meta, nil
+}
+
+// ArxivResolver resolves an arXiv ID to document metadata using the arXiv
+// API, including the abstract and, as "pdf_url", the paper's PDF.
+func ArxivResolver(id string) (JSONMap, error) {
+	q := url.Values{"id_list": {id}}
+	body, err := fetchMetadata(arxivAPI + "?" + q.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("arXiv lookup failed: %w", err)
+	}
+	var feed struct {
+		Entries []struct {
+			ID        string   `xml:"id"`
+			Title     string   `xml:"title"`
+			Summary   string   `xml:"summary"`
+			Published string   `xml:"published"`
+			Authors   []string `xml:"author>name"`
+			DOI       string   `xml:"http://arxiv.org/schemas/atom doi"`
+			Journal   string   `xml:"http://arxiv.org/schemas/atom journal_ref"`
+			Links     []struct {
+				Href  string `xml:"href,attr"`
+				Title string `xml:"title,attr"`
+			} `xml:"link"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(body, &feed); err != nil {
+		return nil, fmt.Errorf("decode arXiv response: %w", err)
+	}
+	// Unknown IDs come back as an entry describing the error
+	if len(feed.Entries) == 0 || strings.Contains(feed.Entries[0].ID, "api/errors") || feed.Entries[0].Title == "" {
+		return nil, fmt.Errorf("arXiv ID %s not found", id)
+	}
+	e := feed.Entries[0]
+
+	meta := JSONMap{
+		"title":   strings.Join(strings.Fields(e.Title), " "),
+		"authors": e.Authors,
+		"url":     "https://arxiv.org/abs/" + id,
+		"pdf_url": "https://arxiv.org/pdf/" + id,
+	}
+	if abstract := strings.Join(strings.Fields(e.Summary), " "); abstract != "" {
+		meta["abstract"] = abstract
+	}
+	if len(e.Published) >= 4 {
+		if y, err := strconv.Atoi(e.Published[:4]); err == nil {
+			meta["year"] = y
+		}
+	}
+	for _, l := range e.Links {
+		if l.Title == "pdf" && l.Href != "" {
+			meta["pdf_url"] = l.Href
+		}
+	}
+	if e.DOI != "" {
+		meta["doi"] = e.DOI
+	}
+	if e.Journal != "" {
+		meta["journal"] = strings.TrimSpace(e.Journal)
+	}
+	return meta, nil
+}
+
+// ISBNResolver resolves an ISBN to book metadata using Open Library.
+func ISBNResolver(isbn string) (JSONMap, error) {
+	key := "ISBN:" + isbn
+	q := url.Values{"bibkeys": {key}, "format": {"json"}, "jscmd": {"data"}}
+	body, err := fetchMetadata(openLibraryAPI + "?" + q.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("Open Library lookup failed: %w", err)
+	}
+	var books map[string]struct {
+		Title    string `json:"title"`
+		Subtitle string `json:"subtitle"`
+		Authors  []struct {
+			Name string `json:"name"`
+		} `json:"authors"`
+		PublishDate string `json:"publish_date"`
+		Publishers  []struct {
+			Name string `json:"name"`
+		} `json:"publishers"`
+		Pages int    `json:"number_of_pages"`
+		URL   string `json:"url"`
+	}
+	if err := json.Unmarshal(body, &books); err != nil {
+		return nil, fmt.Errorf("decode Open Library response: %w", err)
+	}
+	book, ok := books[key]
+	if !ok {
+		return nil, fmt.Errorf("ISBN %s not found on Open Library", isbn)
+	}
+
+	meta := JSONMap{"title": book.Title, "isbn": isbn}
+	if book.Subtitle != "" {
+		meta["title"] = book.Title + ": " + book.Subtitle
+	}
+	authors := make([]string, 0, len(book.Authors))
+	for _, a := range book.Authors {
+		authors = append(authors, a.Name)
+	}
+	meta["authors"] = authors
+	if y := yearPattern.FindString(book.PublishDate); y != "" {
+		meta["year"], _ = strconv.Atoi(y)
+	}
+	if len(book.Publishers) > 0 {
+		meta["publisher"] = book.Publishers[0].Name
+	}
+	if book.Pages > 0 {
+		meta["pages"] = book.Pages
+	}
+	if book.URL != "" {
+		meta["url"] = book.URL
+	}
+	return meta, nil
+}
+
+// UnpaywallPDF returns the URL of the best open-access PDF of a DOI
+// according to Unpaywall, or "" when there is none. Unpaywall requires a
+// contact email with each request.
+func UnpaywallPDF(doi, email string) (string, error) {
+	if email == "" {
+		return "", fmt.Errorf("Unpaywall requires an email address")
+	}
+	q := url.Values{"email": {email}}
+	body, err := fetchMetadata(unpaywallAPI + "/" + doi + "?" + q.Encode())
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "404") {
+			return "", nil
+		}
+		return "", fmt.Errorf("Unpaywall lookup failed: %w", err)
+	}
+	var record struct {
+		Best *struct {
+			PDF string `json:"url_for_pdf"`
+		} `json:"best_oa_location"`
+		Locations []struct {
+			PDF string `json:"url_for_pdf"`
+		} `json:"oa_locations"`
+	}
+	if err := json.Unmarshal(body, &record); err != nil {
+		return "", fmt.Errorf("decode Unpaywall response: %w", err)
+	}
+	if record.Best != nil && record.Best.PDF != "" {
+		return record.Best.PDF, nil
+	}
+	for _, l := range record.Locations {
+		if l.PDF != "" {
+			return l.PDF, nil
+		}
+	}
+	return "", nil
+}
+
+// fetchMetadata GETs a metadata API URL and returns the body, going
+// through the metadata cache when one is set.
+func fetchMetadata(rawURL string) ([]byte, error) {
+	c := metadataCache
+	if c == nil {
+		return fetchMetadataLive(rawURL)
+	}
+	if body, age, ok := c.Get(rawURL); ok && (c.Offline || age < c.TTL) {
+		return body, nil
+	}
+	if c.Offline {
+		return nil, fmt.Errorf("%w: %s is not cached", ErrOffline, rawURL)
+	}
+	body, err := fetchMetadataLive(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	// A cache that can't be written only costs a refetch next time
+	_ = c.Put(rawURL, body)
+	return body, nil
+}
+
 // PDFTextExtractor extracts text from a PDF file using external tool (pdftotext).
 // It returns the full text content.
-// If pdftotext is not available, it returns an error.
+// If pdftotext is not available, it returns an error. Extractions share
+// the workers and timeout SetTextExtraction configures.
 func PDFTextExtractor(pdfPath string) (string, error) {
-	// Try pdftotext (from poppler)
-	cmd := exec.Command("pdftotext", pdfPath, "-")
-	var out bytes.Buffer
-	cmd.Stdout = &out
-	cmd.Stderr = bytes.NewBuffer(nil)
-	if err := cmd.Run(); err != nil {
-		return "", fmt.Errorf("pdftotext failed: %w (is poppler installed?)", err)
-	}
-	text := out.String()
-	// Clean up excessive whitespace
-	text = strings.TrimSpace(text)
-	return text, nil
+	res := textExtractor.Extract(pdfPath)
+	return res.Text, res.Err
 }
 
 // Suggest: In the future, you could also use a pure Go PDF parser like "unidoc/unipdf"
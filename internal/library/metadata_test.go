@@ -0,0 +1,367 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseIdentifier(t *testing.T) {
+	cases := []struct{ in, source, id string }{
+		{"23193287", IDSourcePubMed, "23193287"},
+		{"PMID: 23193287", IDSourcePubMed, "23193287"},
+		{"pmc3531190", IDSourcePMC, "PMC3531190"},
+		{"10.1038/nature12373", IDSourceDOI, "10.1038/nature12373"},
+		{"https://doi.org/10.1038/nature12373", IDSourceDOI, "10.1038/nature12373"},
+		{"doi:10.1101/2020.03.01.972935", IDSourceBioRxiv, "10.1101/2020.03.01.972935"},
+		{"https://www.biorxiv.org/content/10.1101/2020.03.01.972935v2.full", IDSourceBioRxiv, "10.1101/2020.03.01.972935"},
+		{"2304.00067v2", IDSourceArxiv, "2304.00067"},
+		{"arXiv:hep-th/9901001", IDSourceArxiv, "hep-th/9901001"},
+		{"https://arxiv.org/pdf/1706.03762v7.pdf", IDSourceArxiv, "1706.03762"},
+		{"978-0-262-03384-8", IDSourceISBN, "9780262033848"},
+		{"ISBN 0-306-40615-2", IDSourceISBN, "0306406152"},
+	}
+	for _, c := range cases {
+		source, id, err := ParseIdentifier(c.in)
+		if err != nil || source != c.source || id != c.id {
+			t.Errorf("ParseIdentifier(%q) = %q, %q, %v; want %q, %q", c.in, source, id, err, c.source, c.id)
+		}
+	}
+	for _, in := range []string{"not an id", "978-0-262-03384-9"} {
+		if _, _, err := ParseIdentifier(in); err == nil {
+			t.Errorf("ParseIdentifier(%q) should fail", in)
+		}
+	}
+}
+
+// withMetadataServer points the resolvers at a test server for the test,
+// without rate limiting or retries.
+func withMetadataServer(t *testing.T, handler http.HandlerFunc) {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	SetResolverOptions(ResolverOptions{})
+	t.Cleanup(func() {
+		SetResolverOptions(ResolverOptions{Rate: DefaultResolverRate, Retries: DefaultResolverRetries})
+	})
+	apis := []*string{&crossrefAPI, &pubMedAPI, &pmcIDConvAPI, &bioRxivAPI, &arxivAPI, &openLibraryAPI, &unpaywallAPI}
+	paths := []string{"/works", "/efetch", "/idconv/", "/details", "/arxiv", "/books", "/unpaywall"}
+	for i, api := range apis {
+		old := *api
+		*api = srv.URL + paths[i]
+		t.Cleanup(func() { *api = old })
+	}
+}
+
+const pubMedRecord = `<?xml version="1.0"?>
+<PubmedArticleSet><PubmedArticle>
+<MedlineCitation><PMID>23193287</PMID><Article>
+	<Journal><Title>Nucleic acids research</Title><JournalIssue><PubDate><Year>2013</Year></PubDate></JournalIssue></Journal>
+	<ArticleTitle>The <i>NCBI</i> Taxonomy database.</ArticleTitle>
+	<Abstract>
+		<AbstractText Label="BACKGROUND">Taxonomy &amp; names.</AbstractText>
+		<AbstractText Label="RESULTS">It works.</AbstractText>
+	</Abstract>
+	<AuthorList>
+		<Author><LastName>Federhen</LastName><ForeName>Scott</ForeName></Author>
+		<Author><CollectiveName>NCBI Staff</CollectiveName></Author>
+	</AuthorList>
+</Article></MedlineCitation>
+<PubmedData><ArticleIdList>
+	<ArticleId IdType="pubmed">23193287</ArticleId>
+	<ArticleId IdType="doi">10.1093/nar/gkr1178</ArticleId>
+	<ArticleId IdType="pmc">PMC3245000</ArticleId>
+</ArticleIdList></PubmedData>
+</PubmedArticle></PubmedArticleSet>`
+
+func TestPubMedAndPMCResolvers(t *testing.T) {
+	withMetadataServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/efetch":
+			if r.URL.Query().Get("id") != "23193287" {
+				w.Write([]byte(`<PubmedArticleSet></PubmedArticleSet>`))
+				return
+			}
+			w.Write([]byte(pubMedRecord))
+		case "/idconv/":
+			w.Write([]byte(`{"records": [{"pmcid": "PMC3245000", "pmid": "23193287", "doi": "10.1093/nar/gkr1178"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	meta, err := ResolveIdentifier(IDSourcePubMed, "23193287")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta["title"] != "The NCBI Taxonomy database" || meta["journal"] != "Nucleic acids research" || meta["year"] != 2013 {
+		t.Errorf("meta = %v", meta)
+	}
+	if meta["abstract"] != "BACKGROUND: Taxonomy & names.\n\nRESULTS: It works." {
+		t.Errorf("abstract = %q", meta["abstract"])
+	}
+	if !slices.Equal(meta["authors"].([]string), []string{"Scott Federhen", "NCBI Staff"}) {
+		t.Errorf("authors = %v", meta["authors"])
+	}
+	if meta["doi"] != "10.1093/nar/gkr1178" || meta["pmcid"] != "PMC3245000" {
+		t.Errorf("ids = %v, %v", meta["doi"], meta["pmcid"])
+	}
+
+	if _, err := PubMedResolver("1"); err == nil {
+		t.Error("expected an error for an unknown PMID")
+	}
+
+	meta, err = ResolveIdentifier(IDSourcePMC, "PMC3245000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta["pmid"] != "23193287" || meta["url"] != "https://www.ncbi.nlm.nih.gov/pmc/articles/PMC3245000/" {
+		t.Errorf("PMC meta = %v", meta)
+	}
+}
+
+func TestBioRxivResolver(t *testing.T) {
+	withMetadataServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/details/biorxiv/10.1101/2020.03.01.972935":
+			w.Write([]byte(`{"collection": []}`))
+		case "/details/medrxiv/10.1101/2020.03.01.972935":
+			w.Write([]byte(`{"collection": [
+				{"title": "Draft", "authors": "Doe, J.", "date": "2020-03-02", "version": "1", "abstract": "Old.", "published": "NA"},
+				{"title": "Final Title", "authors": "Doe, J.; Roe, R. A.", "date": "2020-04-10", "version": "2",
+				 "abstract": "New abstract.", "published": "10.1000/journal.1", "server": "medRxiv"}
+			]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	meta, err := BioRxivResolver("10.1101/2020.03.01.972935")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta["title"] != "Final Title" || meta["abstract"] != "New abstract." || meta["year"] != 2020 {
+		t.Errorf("meta = %v, want the latest version", meta)
+	}
+	if meta["journal"] != "medRxiv" || meta["url"] != "https://www.medrxiv.org/content/10.1101/2020.03.01.972935v2" {
+		t.Errorf("journal %v, url %v", meta["journal"], meta["url"])
+	}
+	if !slices.Equal(meta["authors"].([]string), []string{"J. Doe", "R. A. Roe"}) {
+		t.Errorf("authors = %v", meta["authors"])
+	}
+	if meta["published_doi"] != "10.1000/journal.1" {
+		t.Errorf("published_doi = %v", meta["published_doi"])
+	}
+}
+
+const arxivFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xmlns:arxiv="http://arxiv.org/schemas/atom">
+<entry>
+	<id>http://arxiv.org/abs/1706.03762v7</id>
+	<published>2017-06-12T17:57:34Z</published>
+	<title>Attention Is All
+	  You Need</title>
+	<summary>  The dominant sequence transduction models.
+	</summary>
+	<author><name>Ashish Vaswani</name></author>
+	<author><name>Noam Shazeer</name></author>
+	<arxiv:doi>10.48550/arXiv.1706.03762</arxiv:doi>
+	<link title="pdf" href="http://arxiv.org/pdf/1706.03762v7" rel="related" type="application/pdf"/>
+</entry>
+</feed>`
+
+func TestArxivAndISBNResolvers(t *testing.T) {
+	withMetadataServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/arxiv":
+			if r.URL.Query().Get("id_list") != "1706.03762" {
+				w.Write([]byte(`<feed xmlns="http://www.w3.org/2005/Atom"></feed>`))
+				return
+			}
+			w.Write([]byte(arxivFeed))
+		case "/books":
+			if r.URL.Query().Get("bibkeys") != "ISBN:9780262033848" {
+				w.Write([]byte(`{}`))
+				return
+			}
+			w.Write([]byte(`{"ISBN:9780262033848": {"title": "Introduction to Algorithms", "subtitle": "Third Edition",
+				"authors": [{"name": "Thomas H. Cormen"}, {"name": "Charles E. Leiserson"}],
+				"publish_date": "July 31, 2009", "publishers": [{"name": "MIT Press"}], "number_of_pages": 1292}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	meta, err := ResolveIdentifier(IDSourceArxiv, "1706.03762")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta["title"] != "Attention Is All You Need" || meta["abstract"] != "The dominant sequence transduction models." || meta["year"] != 2017 {
+		t.Errorf("meta = %v", meta)
+	}
+	if meta["pdf_url"] != "http://arxiv.org/pdf/1706.03762v7" || meta["doi"] != "10.48550/arXiv.1706.03762" {
+		t.Errorf("pdf_url %v, doi %v", meta["pdf_url"], meta["doi"])
+	}
+	if !slices.Equal(meta["authors"].([]string), []string{"Ashish Vaswani", "Noam Shazeer"}) {
+		t.Errorf("authors = %v", meta["authors"])
+	}
+	if _, err := ArxivResolver("0000.00000"); err == nil {
+		t.Error("expected an error for an unknown arXiv ID")
+	}
+
+	meta, err = ResolveIdentifier(IDSourceISBN, "9780262033848")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta["title"] != "Introduction to Algorithms: Third Edition" || meta["year"] != 2009 || meta["publisher"] != "MIT Press" || meta["pages"] != 1292 {
+		t.Errorf("meta = %v", meta)
+	}
+	if _, err := ISBNResolver("0306406152"); err == nil {
+		t.Error("expected an error for an unknown ISBN")
+	}
+}
+
+func TestUnpaywallPDF(t *testing.T) {
+	withMetadataServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("email") == "" {
+			http.Error(w, "email required", http.StatusUnprocessableEntity)
+			return
+		}
+		switch r.URL.Path {
+		case "/unpaywall/10.1038/nature12373":
+			w.Write([]byte(`{"best_oa_location": {"url_for_pdf": null},
+				"oa_locations": [{"url_for_pdf": null}, {"url_for_pdf": "https://example.org/paper.pdf"}]}`))
+		case "/unpaywall/10.1000/closed":
+			w.Write([]byte(`{"best_oa_location": null, "oa_locations": []}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	if pdf, err := UnpaywallPDF("10.1038/nature12373", "me@example.org"); err != nil || pdf != "https://example.org/paper.pdf" {
+		t.Errorf("open access: %q, %v", pdf, err)
+	}
+	for _, doi := range []string{"10.1000/closed", "10.1000/unknown"} {
+		if pdf, err := UnpaywallPDF(doi, "me@example.org"); err != nil || pdf != "" {
+			t.Errorf("%s: %q, %v; want no PDF", doi, pdf, err)
+		}
+	}
+	if _, err := UnpaywallPDF("10.1038/nature12373", ""); err == nil {
+		t.Error("expected an error without an email")
+	}
+}
+
+func TestMetadataCache(t *testing.T) {
+	requests := 0
+	withMetadataServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/works/10.1038/nature12373" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"message": {"title": ["Nanometre-scale thermometry"], "published": {"date-parts": [[2013]]}}}`))
+	})
+	cache := &MetadataCache{Dir: t.TempDir(), TTL: time.Hour}
+	SetMetadataCache(cache)
+	t.Cleanup(func() { SetMetadataCache(nil) })
+
+	for range 2 {
+		meta, err := DOIResolver("10.1038/nature12373")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if meta["title"] != "Nanometre-scale thermometry" || meta["year"] != 2013 {
+			t.Errorf("meta = %v", meta)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("%d requests, want the second lookup served from the cache", requests)
+	}
+	if _, err := DOIResolver("10.1000/missing"); err == nil {
+		t.Error("expected an error for an unknown DOI")
+	}
+
+	// Misses aren't cached, and expired entries are refetched
+	cache.TTL = 0
+	DOIResolver("10.1000/missing")
+	DOIResolver("10.1038/nature12373")
+	if requests != 4 {
+		t.Errorf("%d requests, want 4", requests)
+	}
+
+	// Offline, any cached entry is used and nothing is fetched
+	cache.Offline = true
+	if _, err := DOIResolver("10.1038/nature12373"); err != nil {
+		t.Errorf("offline cached lookup: %v", err)
+	}
+	if _, err := DOIResolver("10.1000/missing"); !errors.Is(err, ErrOffline) {
+		t.Errorf("offline uncached lookup: %v, want ErrOffline", err)
+	}
+	if _, err := DownloadPDF("http://127.0.0.1:1/x.pdf", t.TempDir(), ""); !errors.Is(err, ErrOffline) {
+		t.Errorf("offline download: %v, want ErrOffline", err)
+	}
+	if requests != 4 {
+		t.Errorf("%d requests in offline mode", requests-4)
+	}
+}
+
+func TestFetchMetadataRetries(t *testing.T) {
+	var requests, agent = 0, ""
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		agent = r.UserAgent()
+		switch {
+		case r.URL.Path == "/limited":
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case r.URL.Path == "/flaky" && requests < 3:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.Header().Set("X-Rate-Limit-Limit", "50")
+			w.Header().Set("X-Rate-Limit-Interval", "1s")
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer srv.Close()
+
+	var retries []time.Duration
+	SetResolverOptions(ResolverOptions{
+		Email:   "me@example.org",
+		Retries: 2,
+		OnRetry: func(host, status string, wait time.Duration) { retries = append(retries, wait) },
+	})
+	oldDelay := retryBaseDelay
+	retryBaseDelay = time.Millisecond
+	t.Cleanup(func() {
+		retryBaseDelay = oldDelay
+		SetResolverOptions(ResolverOptions{Rate: DefaultResolverRate, Retries: DefaultResolverRetries})
+	})
+
+	body, err := fetchMetadataLive(srv.URL + "/flaky")
+	if err != nil || string(body) != "ok" {
+		t.Fatalf("flaky: %q, %v", body, err)
+	}
+	if requests != 3 || !slices.Equal(retries, []time.Duration{time.Millisecond, 2 * time.Millisecond}) {
+		t.Errorf("%d requests, retry waits %v; want 3 with exponential backoff", requests, retries)
+	}
+	if agent != "arc-library/1.0 (mailto:me@example.org)" {
+		t.Errorf("User-Agent = %q", agent)
+	}
+	if l := limiterFor(strings.TrimPrefix(srv.URL, "http://")); l.interval != 20*time.Millisecond {
+		t.Errorf("limiter interval %v, want the announced 50 per second", l.interval)
+	}
+
+	// A Retry-After beyond maxRetryWait is reported rather than waited out
+	requests = 0
+	_, err = fetchMetadataLive(srv.URL + "/limited")
+	var limited *RateLimitError
+	if !errors.As(err, &limited) || limited.RetryAfter != time.Hour || requests != 1 {
+		t.Errorf("limited: %v after %d requests", err, requests)
+	}
+}
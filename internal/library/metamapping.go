@@ -0,0 +1,312 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MetaMapping imports directories described by a metadata file, such as
+// the meta.yaml arc-arxiv writes, by mapping the file's fields onto a
+// document. Each field is a text/template over the parsed file, so
+// "{{.info.title}}" reads a nested key; list fields (authors and tags)
+// take one item per line. Scalars in the file are kept as written, so an
+// ID like 2301.10000 is not read as a number.
+type MetaMapping struct {
+	Name        string            `yaml:"name"`
+	File        string            `yaml:"file"` // metadata file in the directory: .yaml, .yml, or .json
+	Description string            `yaml:"description,omitempty"`
+	Title       string            `yaml:"title"`
+	Authors     string            `yaml:"authors,omitempty"`
+	Abstract    string            `yaml:"abstract,omitempty"`
+	Source      string            `yaml:"source,omitempty"`
+	SourceID    string            `yaml:"source_id,omitempty"`
+	Type        string            `yaml:"type,omitempty"`
+	Tags        string            `yaml:"tags,omitempty"`
+	Path        string            `yaml:"path,omitempty"` // the document's file, relative to the directory; may be a glob; default the directory
+	Meta        map[string]string `yaml:"meta,omitempty"` // extra metadata, e.g. doi, year, journal
+
+	templates map[string]*template.Template
+}
+
+// ImportMappingsFile returns the file user mappings are read from:
+// $ARC_LIBRARY_IMPORT_MAPPINGS, or arc-library/import-mappings.yaml under
+// the user config directory.
+func ImportMappingsFile() (string, error) {
+	return configFile("ARC_LIBRARY_IMPORT_MAPPINGS", "import-mappings.yaml")
+}
+
+// LoadMetaMappings reads a YAML list of mappings. A missing file means
+// none.
+func LoadMetaMappings(path string) ([]*MetaMapping, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var mappings []*MetaMapping
+	if err := yaml.Unmarshal(data, &mappings); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for i, m := range mappings {
+		if err := m.compile(); err != nil {
+			return nil, fmt.Errorf("%s: mapping %d: %w", path, i+1, err)
+		}
+	}
+	return mappings, nil
+}
+
+// RegisterMetaMappings makes mappings available as importers under their
+// names.
+func RegisterMetaMappings(mappings []*MetaMapping) error {
+	for _, m := range mappings {
+		if LookupImporter(m.Name) != nil {
+			return fmt.Errorf("import mapping %q: an importer has that name", m.Name)
+		}
+		RegisterImporter(metaMappingImporter{m})
+	}
+	return nil
+}
+
+// compile validates the mapping and parses its templates.
+func (m *MetaMapping) compile() error {
+	switch {
+	case m.Name == "":
+		return fmt.Errorf("name is required")
+	case m.File == "" || m.File != filepath.Base(m.File):
+		return fmt.Errorf("%s: file must name the metadata file in the directory", m.Name)
+	case m.Title == "":
+		return fmt.Errorf("%s: title is required", m.Name)
+	}
+	switch strings.ToLower(filepath.Ext(m.File)) {
+	case ".yaml", ".yml", ".json":
+	default:
+		return fmt.Errorf("%s: file must be .yaml, .yml, or .json", m.Name)
+	}
+	if m.Type != "" && !strings.Contains(m.Type, "{{") && !slices.Contains(DocumentTypes, DocumentType(m.Type)) {
+		return fmt.Errorf("%s: unknown type %q", m.Name, m.Type)
+	}
+
+	fields := map[string]string{
+		"title": m.Title, "authors": m.Authors, "abstract": m.Abstract, "source": m.Source,
+		"source_id": m.SourceID, "type": m.Type, "tags": m.Tags, "path": m.Path,
+	}
+	for k, v := range m.Meta {
+		fields["meta."+k] = v
+	}
+	m.templates = map[string]*template.Template{}
+	for field, text := range fields {
+		if text == "" {
+			continue
+		}
+		t, err := template.New(field).Parse(text)
+		if err != nil {
+			return fmt.Errorf("%s: %w", m.Name, err)
+		}
+		m.templates[field] = t
+	}
+	return nil
+}
+
+// field expands a field's template over data; "" when it is unset.
+func (m *MetaMapping) field(name string, data any) (string, error) {
+	t := m.templates[name]
+	if t == nil {
+		return "", nil
+	}
+	var b bytes.Buffer
+	if err := t.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	// Missing keys of a map print as "<no value>"
+	return strings.TrimSpace(strings.ReplaceAll(b.String(), "<no value>", "")), nil
+}
+
+// list expands a list field, one item per line.
+func (m *MetaMapping) list(name string, data any) ([]string, error) {
+	s, err := m.field(name, data)
+	if err != nil || s == "" {
+		return nil, err
+	}
+	var items []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			items = append(items, line)
+		}
+	}
+	return items, nil
+}
+
+// Import reads the metadata file in dir and maps it onto a document.
+func (m *MetaMapping) Import(dir string) (*Document, error) {
+	file := filepath.Join(dir, m.File)
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	data, err := parseMetadataFile(raw, filepath.Ext(m.File))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+
+	doc := &Document{Path: dir}
+	for name, dst := range map[string]*string{
+		"title": &doc.Title, "abstract": &doc.Abstract, "source": &doc.Source, "source_id": &doc.SourceID,
+	} {
+		if *dst, err = m.field(name, data); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+	}
+	if doc.Authors, err = m.list("authors", data); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	if doc.Tags, err = m.list("tags", data); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	typ, err := m.field("type", data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	if typ != "" {
+		if !slices.Contains(DocumentTypes, DocumentType(typ)) {
+			return nil, fmt.Errorf("%s: unknown type %q", file, typ)
+		}
+		doc.Type = DocumentType(typ)
+	}
+	for k := range m.Meta {
+		v, err := m.field("meta."+k, data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		if v == "" {
+			continue
+		}
+		if doc.Meta == nil {
+			doc.Meta = JSONMap{}
+		}
+		if n, err := strconv.Atoi(v); err == nil {
+			doc.Meta[k] = n
+		} else {
+			doc.Meta[k] = v
+		}
+	}
+
+	p, err := m.field("path", data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	if p != "" {
+		if filepath.IsAbs(p) {
+			return nil, fmt.Errorf("%s: path %q must be inside the directory", file, p)
+		}
+		p = filepath.Join(dir, p)
+		if strings.ContainsAny(p, "*?[") {
+			matches, _ := filepath.Glob(p)
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("%s: no file matches %s", file, p)
+			}
+			p = matches[0]
+		}
+		doc.Path = p
+	}
+	if doc.Title == "" {
+		doc.Title = filepath.Base(dir)
+	}
+	return doc, nil
+}
+
+// parseMetadataFile parses YAML or JSON with every scalar kept as the
+// text it was written as.
+func parseMetadataFile(raw []byte, ext string) (any, error) {
+	if strings.EqualFold(ext, ".json") {
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		var v any
+		if err := dec.Decode(&v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(raw, &node); err != nil {
+		return nil, err
+	}
+	return yamlValue(&node), nil
+}
+
+// yamlValue converts a YAML node to maps, slices, and strings.
+func yamlValue(n *yaml.Node) any {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			return nil
+		}
+		return yamlValue(n.Content[0])
+	case yaml.MappingNode:
+		m := make(map[string]any, len(n.Content)/2)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			m[n.Content[i].Value] = yamlValue(n.Content[i+1])
+		}
+		return m
+	case yaml.SequenceNode:
+		items := make([]any, len(n.Content))
+		for i, c := range n.Content {
+			items[i] = yamlValue(c)
+		}
+		return items
+	case yaml.AliasNode:
+		return yamlValue(n.Alias)
+	default:
+		if n.Tag == "!!null" {
+			return nil
+		}
+		return n.Value
+	}
+}
+
+// metaMappingImporter is the importer for a MetaMapping.
+type metaMappingImporter struct{ m *MetaMapping }
+
+func (i metaMappingImporter) Format() Format {
+	desc := i.m.Description
+	if desc == "" {
+		desc = "Directories with a " + i.m.File
+	}
+	return Format{
+		Name:         i.m.Name,
+		Description:  desc,
+		Extensions:   []string{},
+		Capabilities: []string{FormatFiles, FormatDirectory},
+	}
+}
+
+func (i metaMappingImporter) Detect(path string, info fs.FileInfo) bool {
+	if !info.IsDir() {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(path, i.m.File))
+	return err == nil
+}
+
+func (i metaMappingImporter) Import(path string) ([]*Document, error) {
+	doc, err := i.m.Import(path)
+	if err != nil {
+		return nil, err
+	}
+	return []*Document{doc}, nil
+}
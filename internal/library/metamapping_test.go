@@ -0,0 +1,116 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLoadMetaMappings(t *testing.T) {
+	dir := t.TempDir()
+	if m, err := LoadMetaMappings(filepath.Join(dir, "missing.yaml")); err != nil || m != nil {
+		t.Errorf("missing file = %v, %v", m, err)
+	}
+
+	for name, content := range map[string]string{
+		"no name":      "- file: info.json\n  title: '{{.title}}'\n",
+		"no title":     "- name: x\n  file: info.json\n",
+		"nested file":  "- name: x\n  file: sub/info.json\n  title: '{{.title}}'\n",
+		"bad ext":      "- name: x\n  file: info.toml\n  title: '{{.title}}'\n",
+		"bad type":     "- name: x\n  file: info.json\n  title: '{{.title}}'\n  type: pamphlet\n",
+		"bad template": "- name: x\n  file: info.json\n  title: '{{.title'\n",
+	} {
+		path := filepath.Join(dir, "bad.yaml")
+		os.WriteFile(path, []byte(content), 0o644)
+		if _, err := LoadMetaMappings(path); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+	}
+}
+
+func TestMetaMappingImport(t *testing.T) {
+	dir := t.TempDir()
+	mappings := filepath.Join(dir, "mappings.yaml")
+	os.WriteFile(mappings, []byte(`
+- name: test-json
+  file: info.json
+  title: "{{.bib.title}}"
+  authors: "{{range .creators}}{{.given}} {{.family}}\n{{end}}"
+  abstract: "{{.summary}}"
+  source: doi
+  source_id: "{{.bib.doi}}"
+  type: book
+  tags: "{{range .keywords}}{{.}}\n{{end}}"
+  path: "*.pdf"
+  meta:
+    year: "{{.bib.year}}"
+    publisher: "{{.bib.publisher}}"
+- name: test-yaml
+  file: record.yml
+  title: "{{.name}}"
+  source: arxiv
+  source_id: "{{.id}}"
+`), 0o644)
+	ms, err := LoadMetaMappings(mappings)
+	if err != nil || len(ms) != 2 {
+		t.Fatalf("LoadMetaMappings = %v, %v", ms, err)
+	}
+
+	book := filepath.Join(dir, "book")
+	os.Mkdir(book, 0o755)
+	os.WriteFile(filepath.Join(book, "info.json"), []byte(`{
+  "bib": {"title": "Structure and Interpretation", "doi": "10.1000/sicp", "year": 1985},
+  "creators": [{"given": "Harold", "family": "Abelson"}, {"given": "Gerald", "family": "Sussman"}],
+  "keywords": ["lisp", "cs"]
+}`), 0o644)
+	os.WriteFile(filepath.Join(book, "sicp.pdf"), []byte("%PDF-1.4"), 0o644)
+
+	doc, err := ms[0].Import(book)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Title != "Structure and Interpretation" || doc.Source != "doi" || doc.SourceID != "10.1000/sicp" || doc.Type != DocTypeBook ||
+		doc.Abstract != "" || doc.Path != filepath.Join(book, "sicp.pdf") {
+		t.Errorf("doc = %+v", doc)
+	}
+	if !slices.Equal(doc.Authors, []string{"Harold Abelson", "Gerald Sussman"}) || !slices.Equal(doc.Tags, []string{"lisp", "cs"}) {
+		t.Errorf("authors %q, tags %q", doc.Authors, doc.Tags)
+	}
+	if doc.Meta["year"] != 1985 {
+		t.Errorf("year = %#v", doc.Meta["year"])
+	}
+	if _, ok := doc.Meta["publisher"]; ok {
+		t.Errorf("missing key set publisher = %#v", doc.Meta["publisher"])
+	}
+
+	// YAML scalars keep their text: 2301.10000 is not the number 2301.1
+	paper := filepath.Join(dir, "paper")
+	os.Mkdir(paper, 0o755)
+	os.WriteFile(filepath.Join(paper, "record.yml"), []byte("id: 2301.10000\n"), 0o644)
+	if doc, err = ms[1].Import(paper); err != nil {
+		t.Fatal(err)
+	}
+	if doc.SourceID != "2301.10000" || doc.Title != "paper" || doc.Path != paper {
+		t.Errorf("doc = %+v", doc)
+	}
+
+	if err := RegisterMetaMappings(ms); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		delete(importers, "test-json")
+		delete(importers, "test-yaml")
+	})
+	info, _ := os.Stat(book)
+	if imp := DetectImporter(book, info); imp == nil || imp.Format().Name != "test-json" {
+		t.Errorf("book detected as %v", imp)
+	}
+	if err := RegisterMetaMappings([]*MetaMapping{arxivMetaMapping}); err == nil || !strings.Contains(err.Error(), "arxiv-meta") {
+		t.Errorf("duplicate name: %v", err)
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"hash/fnv"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// ShingleSize is the number of consecutive words per shingle.
+	ShingleSize = 5
+	// MinHashSize is the number of hash functions in a signature.
+	MinHashSize = 128
+	// minHashBands split a signature for locality-sensitive hashing; with 32
+	// bands of 4 rows, pairs above about 0.5 similarity become candidates.
+	minHashBands = 32
+	// minShingles is the least text worth comparing; shorter texts (a stub
+	// abstract, a failed extraction) would match each other too easily.
+	minShingles = 50
+)
+
+// TextSignature is the MinHash signature of a document's full text.
+// TextHash identifies the text it was computed from, so stale signatures can
+// be recomputed after the text changes.
+type TextSignature struct {
+	DocumentID string    `json:"document_id"`
+	TextHash   uint64    `json:"text_hash"`
+	MinHash    []uint64  `json:"minhash"` // empty when the text is too short to compare
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// NearDuplicate is a pair of documents whose full texts are similar.
+type NearDuplicate struct {
+	A, B       string  // document IDs
+	Similarity float64 // estimated Jaccard similarity of their shingles, 0-1
+}
+
+// minHashSeeds derives one seed per hash function from a fixed start, so
+// signatures stay comparable across runs.
+var minHashSeeds = func() [MinHashSize]uint64 {
+	var seeds [MinHashSize]uint64
+	x := uint64(0x9e3779b97f4a7c15)
+	for i := range seeds {
+		x = splitmix64(x)
+		seeds[i] = x
+	}
+	return seeds
+}()
+
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// HashText identifies a full text for TextSignature.TextHash.
+func HashText(text string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(text))
+	return h.Sum64()
+}
+
+// Shingles returns the distinct hashed k-word shingles of text, ignoring
+// case and punctuation so re-extracted or re-typeset copies still match.
+func Shingles(text string, k int) []uint64 {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) < k {
+		return nil
+	}
+
+	seen := make(map[uint64]bool, len(words))
+	var shingles []uint64
+	for i := 0; i+k <= len(words); i++ {
+		h := HashText(strings.Join(words[i:i+k], " "))
+		if !seen[h] {
+			seen[h] = true
+			shingles = append(shingles, h)
+		}
+	}
+	return shingles
+}
+
+// ComputeTextSignature builds the signature of a document's full text.
+func ComputeTextSignature(doc *Document) *TextSignature {
+	sig := &TextSignature{DocumentID: doc.ID, TextHash: HashText(doc.FullText), UpdatedAt: time.Now()}
+	shingles := Shingles(doc.FullText, ShingleSize)
+	if len(shingles) < minShingles {
+		return sig
+	}
+
+	sig.MinHash = make([]uint64, MinHashSize)
+	for i := range sig.MinHash {
+		sig.MinHash[i] = ^uint64(0)
+	}
+	for _, s := range shingles {
+		for i, seed := range minHashSeeds {
+			if h := splitmix64(s ^ seed); h < sig.MinHash[i] {
+				sig.MinHash[i] = h
+			}
+		}
+	}
+	return sig
+}
+
+// Similarity estimates the Jaccard similarity of the texts behind two
+// signatures: the fraction of hash functions with the same minimum.
+func (s *TextSignature) Similarity(other *TextSignature) float64 {
+	if len(s.MinHash) != MinHashSize || len(other.MinHash) != MinHashSize {
+		return 0
+	}
+	same := 0
+	for i := range s.MinHash {
+		if s.MinHash[i] == other.MinHash[i] {
+			same++
+		}
+	}
+	return float64(same) / MinHashSize
+}
+
+// FindNearDuplicates returns the pairs of signatures at or above threshold,
+// most similar first. Banding keeps it from comparing every pair: only
+// documents sharing an identical band are scored.
+func FindNearDuplicates(sigs []*TextSignature, threshold float64) []NearDuplicate {
+	rows := MinHashSize / minHashBands
+	buckets := make(map[[2]uint64][]int)
+	for i, sig := range sigs {
+		if len(sig.MinHash) != MinHashSize {
+			continue
+		}
+		for b := 0; b < minHashBands; b++ {
+			h := fnv.New64a()
+			for _, v := range sig.MinHash[b*rows : (b+1)*rows] {
+				var buf [8]byte
+				for j := range buf {
+					buf[j] = byte(v >> (8 * j))
+				}
+				h.Write(buf[:])
+			}
+			key := [2]uint64{uint64(b), h.Sum64()}
+			buckets[key] = append(buckets[key], i)
+		}
+	}
+
+	seen := make(map[[2]int]bool)
+	var pairs []NearDuplicate
+	for _, members := range buckets {
+		for x := 0; x < len(members); x++ {
+			for y := x + 1; y < len(members); y++ {
+				i, j := members[x], members[y]
+				if seen[[2]int{i, j}] {
+					continue
+				}
+				seen[[2]int{i, j}] = true
+				if sim := sigs[i].Similarity(sigs[j]); sim >= threshold {
+					pairs = append(pairs, NearDuplicate{A: sigs[i].DocumentID, B: sigs[j].DocumentID, Similarity: sim})
+				}
+			}
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].Similarity != pairs[j].Similarity {
+			return pairs[i].Similarity > pairs[j].Similarity
+		}
+		return pairs[i].A+pairs[i].B < pairs[j].A+pairs[j].B
+	})
+	return pairs
+}
+
+// TextSignatures returns the signatures of docs, reusing the cached ones
+// whose text is unchanged and computing and saving the rest. It also
+// reports how many were computed.
+func TextSignatures(s LibraryStore, docs []*Document) ([]*TextSignature, int, error) {
+	cached, err := s.ListTextSignatures()
+	if err != nil {
+		return nil, 0, err
+	}
+	byID := make(map[string]*TextSignature, len(cached))
+	for _, sig := range cached {
+		byID[sig.DocumentID] = sig
+	}
+
+	sigs := make([]*TextSignature, 0, len(docs))
+	computed := 0
+	for _, doc := range docs {
+		sig := byID[doc.ID]
+		if sig == nil || sig.TextHash != HashText(doc.FullText) {
+			sig = ComputeTextSignature(doc)
+			if err := s.SaveTextSignature(sig); err != nil {
+				return nil, computed, err
+			}
+			computed++
+		}
+		sigs = append(sigs, sig)
+	}
+	return sigs, computed, nil
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+// sampleText builds n pseudo-random words; different topics share none of
+// their shingles.
+func sampleText(topic string, n int) string {
+	seed := HashText(topic)
+	words := make([]string, n)
+	for i := range words {
+		seed = splitmix64(seed)
+		words[i] = fmt.Sprintf("%s%d", topic[:2], seed%500)
+	}
+	return strings.Join(words, " ")
+}
+
+func TestTextSignatureSimilarity(t *testing.T) {
+	text := sampleText("protein", 300)
+	a := ComputeTextSignature(&Document{ID: "a", FullText: text})
+	// A re-extracted copy: different case, punctuation and a changed tail
+	b := ComputeTextSignature(&Document{ID: "b", FullText: strings.ToUpper(strings.ReplaceAll(text, " ", ", ")) + " " + sampleText("extra", 10)})
+	c := ComputeTextSignature(&Document{ID: "c", FullText: sampleText("galaxy", 300)})
+
+	if sim := a.Similarity(b); sim < 0.8 {
+		t.Errorf("near-identical texts scored %.2f", sim)
+	}
+	if sim := a.Similarity(c); sim > 0.3 {
+		t.Errorf("unrelated texts scored %.2f", sim)
+	}
+
+	short := ComputeTextSignature(&Document{ID: "s", FullText: "too short to compare"})
+	if len(short.MinHash) != 0 || short.Similarity(short) != 0 {
+		t.Errorf("short text got signature %v", short.MinHash)
+	}
+
+	pairs := FindNearDuplicates([]*TextSignature{a, c, b, short}, 0.5)
+	if len(pairs) != 1 || pairs[0].A != "a" || pairs[0].B != "b" {
+		t.Errorf("pairs = %+v, want only a-b", pairs)
+	}
+}
+
+func TestKVStoreTextSignatures(t *testing.T) {
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	docs := []*Document{
+		{ID: "a", Title: "A", FullText: sampleText("protein", 100)},
+		{ID: "b", Title: "B", FullText: sampleText("galaxy", 100)},
+	}
+	for _, d := range docs {
+		s.AddDocument(d)
+	}
+
+	sigs, computed, err := TextSignatures(s, docs)
+	if err != nil || computed != 2 || len(sigs) != 2 {
+		t.Fatalf("first pass: %d signatures, %d computed, %v", len(sigs), computed, err)
+	}
+	if _, computed, _ = TextSignatures(s, docs); computed != 0 {
+		t.Errorf("unchanged texts recomputed %d signatures", computed)
+	}
+
+	// Changed text is recomputed; deleting a document drops its signature
+	docs[0].FullText += " revised"
+	if _, computed, _ = TextSignatures(s, docs); computed != 1 {
+		t.Errorf("changed text recomputed %d signatures, want 1", computed)
+	}
+	s.DeleteDocument("b")
+	cached, err := s.ListTextSignatures()
+	if err != nil || len(cached) != 1 || cached[0].DocumentID != "a" {
+		t.Errorf("after delete: %+v, %v", cached, err)
+	}
+}
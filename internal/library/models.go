@@ -53,8 +53,11 @@ type Collection struct {
 	Name        string    `json:"name" yaml:"name"`
 	Description string    `json:"description,omitempty" yaml:"description,omitempty"`
 	DocumentIDs []string  `json:"document_ids" yaml:"document_ids"` // Renamed from PaperIDs
-	CreatedAt   time.Time `json:"created_at" yaml:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" yaml:"updated_at"`
+	// AddedAt is when each document was added, by document ID
+	AddedAt   map[string]time.Time `json:"added_at,omitempty" yaml:"added_at,omitempty"`
+	Public    bool                 `json:"public,omitempty" yaml:"public,omitempty"` // served as a feed and reading list without a token
+	CreatedAt time.Time            `json:"created_at" yaml:"created_at"`
+	UpdatedAt time.Time            `json:"updated_at" yaml:"updated_at"`
 }
 
 // Annotation represents a highlight or note on a specific part of a document.
@@ -64,8 +67,10 @@ type Annotation struct {
 	Type      string    `json:"type" yaml:"type"`               // highlight, note, bookmark
 	Content   string    `json:"content,omitempty" yaml:"content,omitempty"`
 	Page      int       `json:"page,omitempty" yaml:"page,omitempty"`
-	Position  string    `json:"position,omitempty" yaml:"position,omitempty"` // JSON coordinates
+	Position  string    `json:"position,omitempty" yaml:"position,omitempty"` // an AnnotationPosition as JSON
 	Color     string    `json:"color,omitempty" yaml:"color,omitempty"`
+	SessionID string    `json:"session_id,omitempty" yaml:"session_id,omitempty"` // the reading session it was made in
+	ParentID  string    `json:"parent_id,omitempty" yaml:"parent_id,omitempty"`   // set for replies
 	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
 }
 
@@ -100,7 +105,7 @@ type Flashcard struct {
 	Tags        []string  `json:"tags,omitempty" yaml:"tags,omitempty"`
 	DueAt       time.Time `json:"due_at" yaml:"due_at"`
 	Interval    int       `json:"interval" yaml:"interval"`     // days until next review
-	Ease        float64   `json:"ease" yaml:"ease"`             // SM-2 ease factor (1.3-2.5)
+	Ease        float64   `json:"ease" yaml:"ease"`             // SM-2 ease factor (see package scheduler)
 	LastReview  time.Time `json:"last_review,omitempty" yaml:"last_review,omitempty"`
 	CreatedAt   time.Time `json:"created_at" yaml:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at" yaml:"updated_at"`
@@ -114,15 +119,31 @@ type FlashcardReview struct {
 	ReviewedAt  time.Time `json:"reviewed_at" yaml:"reviewed_at"`
 	PrevInterval int      `json:"prev_interval,omitempty" yaml:"prev_interval,omitempty"`
 	PrevEase    float64  `json:"prev_ease,omitempty" yaml:"prev_ease,omitempty"`
+	Params      string   `json:"params,omitempty" yaml:"params,omitempty"` // the scheduler settings used, as scheduler.Config.String
 }
 
 // ListOptions filters document listing.
 type ListOptions struct {
-	Tag    string
-	Source string
-	Search string
-	Type   string
-	Limit  int
+	Tag       string
+	Source    string
+	Search    string
+	Type      string
+	Status    string        // an unset document status matches "unread"
+	MinRating int           // 1-5; 0 disables
+	Author    string        // case-insensitive substring of any author
+	Language  string        // ISO 639-1 code recorded in Meta["language"]
+	Fields    []FieldFilter // conditions on custom fields; all must hold
+	Limit     int
+
+	// HideArchived skips archived documents unless Status asks for them.
+	HideArchived bool
+
+	// Date ranges: *After is inclusive, *Before exclusive. Zero disables.
+	// Read filters only match documents that have a read date.
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	ReadAfter     time.Time
+	ReadBefore    time.Time
 }
 
 // FlashcardListOptions filters flashcard listing.
@@ -138,9 +159,13 @@ type Task struct {
 	ID           string     `json:"id" yaml:"id"`
 	Description  string     `json:"description" yaml:"description"`
 	CollectionID string     `json:"collection_id,omitempty" yaml:"collection_id,omitempty"`
-	Status       string     `json:"status" yaml:"status"` // todo, done
+	DocumentID   string     `json:"document_id,omitempty" yaml:"document_id,omitempty"`
+	ParentID     string     `json:"parent_id,omitempty" yaml:"parent_id,omitempty"` // set for subtasks
+	Status       string     `json:"status" yaml:"status"` // todo, done, or a configured workflow status; see ParseTaskStatuses
 	Priority     string     `json:"priority,omitempty" yaml:"priority,omitempty"` // low, medium, high
 	Tags         []string   `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Repeat       string     `json:"repeat,omitempty" yaml:"repeat,omitempty"` // e.g. "weekly", "every 2 weeks"; see ParseRecurrence
+	RepeatDay    int        `json:"repeat_day,omitempty" yaml:"repeat_day,omitempty"` // day of the month a repeat falls on, when a shorter month moved DueAt earlier
 	DueAt        *time.Time `json:"due_at,omitempty" yaml:"due_at,omitempty"`
 	CompletedAt  *time.Time `json:"completed_at,omitempty" yaml:"completed_at,omitempty"`
 	CreatedAt    time.Time  `json:"created_at" yaml:"created_at"`
@@ -150,10 +175,37 @@ type Task struct {
 // TaskListOptions filters task listing.
 type TaskListOptions struct {
 	CollectionID string
+	DocumentID   string
+	ParentID     string
 	Status       string
+	Open         bool      // only tasks not yet done, whatever their workflow status
+	DueBefore    time.Time // only tasks with a due date before this; zero disables
 	Limit        int
 }
 
+// LinkRelation is the type of a directed relation between two documents.
+type LinkRelation string
+
+const (
+	LinkSupersedes    LinkRelation = "supersedes"     // e.g. the published version supersedes the preprint
+	LinkDuplicateOf   LinkRelation = "duplicate-of"
+	LinkPartOf        LinkRelation = "part-of"        // chapter or installment of a series
+	LinkRespondsTo    LinkRelation = "responds-to"    // comment, reply, or rebuttal
+	LinkTranslationOf LinkRelation = "translation-of"
+	LinkCites         LinkRelation = "cites"          // the target is in the document's references
+	LinkReferences    LinkRelation = "references"     // a note mentions the target; see SyncNoteLinks
+)
+
+// DocumentLink is a typed relation between documents, read as
+// "FromID <Relation> ToID".
+type DocumentLink struct {
+	ID        string       `json:"id" yaml:"id"`
+	FromID    string       `json:"from_id" yaml:"from_id"`
+	ToID      string       `json:"to_id" yaml:"to_id"`
+	Relation  LinkRelation `json:"relation" yaml:"relation"`
+	CreatedAt time.Time    `json:"created_at" yaml:"created_at"`
+}
+
 // SavedSearch represents a bookmarked search query
 type SavedSearch struct {
 	ID          string    `json:"id" yaml:"id"`
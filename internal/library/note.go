@@ -0,0 +1,48 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+// noteTitleLen is the most of a note's first line its title takes.
+const noteTitleLen = 80
+
+// NewNote makes a note document from captured text. The text is kept as
+// the note's notes, where search finds it, and its first line, less any
+// Markdown heading marks, is the title.
+func NewNote(text string) (*Document, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, errors.New("note is empty")
+	}
+	first, _, _ := strings.Cut(text, "\n")
+	return &Document{
+		Title:  noteTitle(first),
+		Type:   DocTypeNote,
+		Source: "note",
+		Notes:  text,
+	}, nil
+}
+
+// noteTitle shortens a line to a title, cutting long lines at a word
+// boundary.
+func noteTitle(line string) string {
+	title := strings.Join(strings.Fields(strings.TrimLeft(strings.TrimSpace(line), "# ")), " ")
+	if len(title) <= noteTitleLen {
+		return title
+	}
+	cut := title[:noteTitleLen]
+	if i := strings.LastIndexByte(cut, ' '); i > noteTitleLen/2 {
+		cut = cut[:i]
+	}
+	// Without a good word boundary the cut may split a character
+	for !utf8.ValidString(cut) {
+		cut = cut[:len(cut)-1]
+	}
+	return strings.TrimRight(cut, " ,;:.-") + "…"
+}
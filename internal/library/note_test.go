@@ -0,0 +1,49 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+func TestNewNote(t *testing.T) {
+	doc, err := NewNote("\n## Idea:  attention   for parsing\nTry it on the treebank.\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Title != "Idea: attention for parsing" || doc.Type != DocTypeNote || doc.Source != "note" {
+		t.Errorf("note = %q %s %s", doc.Title, doc.Type, doc.Source)
+	}
+	if doc.Notes != "## Idea:  attention   for parsing\nTry it on the treebank." {
+		t.Errorf("notes = %q", doc.Notes)
+	}
+	if _, err := NewNote(" \n "); err == nil {
+		t.Error("empty note: no error")
+	}
+
+	long, _ := NewNote(strings.Repeat("word ", 30))
+	if long.Title != strings.TrimSpace(strings.Repeat("word ", 16))+"…" {
+		t.Errorf("long title = %q", long.Title)
+	}
+	unbroken, _ := NewNote(strings.Repeat("é", 60))
+	if unbroken.Title != strings.Repeat("é", 40)+"…" {
+		t.Errorf("unbroken title = %q", unbroken.Title)
+	}
+
+	// Notes are found by search like other documents
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddDocument(doc); err != nil {
+		t.Fatal(err)
+	}
+	found, err := s.ListDocuments(&ListOptions{Search: "treebank"})
+	if err != nil || len(found) != 1 || found[0].ID != doc.ID {
+		t.Errorf("search found %v, %v", found, err)
+	}
+}
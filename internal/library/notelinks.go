@@ -0,0 +1,154 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	// [[target]] or [[target|label]]
+	noteWikiLinkRe = regexp.MustCompile(`\[\[([^\]|]+)(?:\|[^\]]*)?\]\]`)
+	// @citekey, as Pandoc writes citations; not an email address
+	noteCitationRe = regexp.MustCompile(`(?:^|[^\w@])@(\w(?:[\w:./-]*\w)?)`)
+	// Words that may be a document ID
+	noteWordRe = regexp.MustCompile(`\w(?:[\w:.-]*\w)?`)
+)
+
+// noteIndex finds documents by what a note may call them.
+type noteIndex struct {
+	byID    map[string]*Document
+	byKey   map[string]*Document // lowercased cite key; nil when ambiguous
+	byTitle map[string]*Document // lowercased title; nil when ambiguous
+}
+
+func newNoteIndex(docs []*Document) *noteIndex {
+	idx := &noteIndex{byID: map[string]*Document{}, byKey: map[string]*Document{}, byTitle: map[string]*Document{}}
+	legacyKeys := map[string]*Document{}
+	add := func(m map[string]*Document, key string, doc *Document) {
+		if key == "" {
+			return
+		}
+		if _, seen := m[key]; seen {
+			m[key] = nil
+		} else {
+			m[key] = doc
+		}
+	}
+	for _, d := range docs {
+		idx.byID[d.ID] = d
+		add(idx.byKey, strings.ToLower(CiteKey(d)), d)
+		add(legacyKeys, legacyCiteKey(d), d)
+		add(idx.byTitle, strings.ToLower(strings.TrimSpace(d.Title)), d)
+	}
+	// Keys of the older scheme still link, unless a current key or
+	// another document claims them
+	for key, d := range legacyKeys {
+		if _, taken := idx.byKey[key]; !taken && key != "unknown" {
+			idx.byKey[key] = d
+		}
+	}
+	return idx
+}
+
+// NoteReferences returns the documents among docs that text mentions, in
+// the order first mentioned: by [[ID]], [[citekey]], or [[title]] wiki
+// links, by @citekey citations, and by bare document IDs. Cite keys are
+// CiteKey's, or those it gave before, and, like titles, match
+// case-insensitively; one shared by
+// several documents matches none of them.
+func NoteReferences(text string, docs []*Document) []*Document {
+	idx := newNoteIndex(docs)
+	type mention struct {
+		at  int
+		doc *Document
+	}
+	var mentions []mention
+	for _, m := range noteWikiLinkRe.FindAllStringSubmatchIndex(text, -1) {
+		target := strings.TrimSpace(text[m[2]:m[3]])
+		doc := idx.byID[target]
+		if doc == nil {
+			doc = idx.byKey[strings.ToLower(strings.TrimPrefix(target, "@"))]
+		}
+		if doc == nil {
+			doc = idx.byTitle[strings.ToLower(target)]
+		}
+		if doc != nil {
+			mentions = append(mentions, mention{m[0], doc})
+		}
+	}
+	for _, m := range noteCitationRe.FindAllStringSubmatchIndex(text, -1) {
+		if doc := idx.byKey[strings.ToLower(text[m[2]:m[3]])]; doc != nil {
+			mentions = append(mentions, mention{m[2], doc})
+		}
+	}
+	for _, m := range noteWordRe.FindAllStringIndex(text, -1) {
+		if doc := idx.byID[text[m[0]:m[1]]]; doc != nil {
+			mentions = append(mentions, mention{m[0], doc})
+		}
+	}
+
+	slices.SortStableFunc(mentions, func(a, b mention) int { return a.at - b.at })
+	var refs []*Document
+	for _, m := range mentions {
+		if !slices.Contains(refs, m.doc) {
+			refs = append(refs, m.doc)
+		}
+	}
+	return refs
+}
+
+// SyncNoteLinks makes the references links from note documents match
+// what their notes and full text mention (see NoteReferences): links to
+// newly mentioned documents are added, and references links to documents
+// no longer mentioned removed. Documents other than notes are skipped.
+// It returns the links added and removed.
+func SyncNoteLinks(s LibraryStore, notes []*Document) (added, removed []*DocumentLink, err error) {
+	docs, err := s.ListDocuments(nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list documents: %w", err)
+	}
+	for _, note := range notes {
+		if note.Type != DocTypeNote {
+			continue
+		}
+		refs := NoteReferences(note.Notes+"\n"+note.FullText, docs)
+		want := map[string]bool{}
+		for _, d := range refs {
+			want[d.ID] = d.ID != note.ID
+		}
+
+		links, err := s.ListDocumentLinks(note.ID)
+		if err != nil {
+			return added, removed, fmt.Errorf("list links of %s: %w", note.ID, err)
+		}
+		have := map[string]bool{}
+		for _, l := range links {
+			if l.FromID != note.ID || l.Relation != LinkReferences {
+				continue
+			}
+			have[l.ToID] = true
+			if !want[l.ToID] {
+				if err := s.DeleteDocumentLink(l.ID); err != nil {
+					return added, removed, fmt.Errorf("remove link %s: %w", l.ID, err)
+				}
+				removed = append(removed, l)
+			}
+		}
+		for _, d := range refs {
+			if !want[d.ID] || have[d.ID] {
+				continue
+			}
+			l := &DocumentLink{FromID: note.ID, ToID: d.ID, Relation: LinkReferences}
+			if err := s.AddDocumentLink(l); err != nil {
+				return added, removed, fmt.Errorf("link %s to %s: %w", note.ID, d.ID, err)
+			}
+			added = append(added, l)
+		}
+	}
+	return added, removed, nil
+}
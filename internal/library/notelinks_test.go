@@ -0,0 +1,74 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"testing"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+func TestNoteReferences(t *testing.T) {
+	attention := &Document{ID: "doc:1", Title: "Attention Is All You Need", Source: "arxiv", SourceID: "1706.03762"}
+	bert := &Document{ID: "doc:2", Title: "BERT", Authors: []string{"Devlin Jacob"}, Meta: JSONMap{"year": 2019}}
+	keyed := &Document{ID: "doc:3", Title: "Deep Learning", Meta: JSONMap{"citekey": "goodfellow2016"}}
+	twinA := &Document{ID: "doc:4", Title: "Twin", Authors: []string{"Smith"}}
+	twinB := &Document{ID: "doc:5", Title: "Other twin", Authors: []string{"Smith"}}
+	docs := []*Document{attention, bert, keyed, twinA, twinB}
+
+	text := "Builds on @devlin2019 and [[attention is all you need]]; see also [[Goodfellow2016|the book]].\n" +
+		"Mail me@1706.03762 is no citation, [[nothing]] is no link, and @smith is ambiguous.\n" +
+		"Twin again: doc:5. And @1706.03762, twice."
+	refs := NoteReferences(text, docs)
+	var ids []string
+	for _, d := range refs {
+		ids = append(ids, d.ID)
+	}
+	if want := []string{"doc:2", "doc:1", "doc:3", "doc:5"}; len(ids) != len(want) || ids[0] != want[0] || ids[1] != want[1] || ids[2] != want[2] || ids[3] != want[3] {
+		t.Errorf("references = %v, want %v", ids, want)
+	}
+}
+
+func TestSyncNoteLinks(t *testing.T) {
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	paper := &Document{Title: "Attention Is All You Need", Source: "arxiv", SourceID: "1706.03762"}
+	book := &Document{Title: "Deep Learning"}
+	for _, d := range []*Document{paper, book} {
+		if err := s.AddDocument(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	note, _ := NewNote("Idea\nCompare @1706.03762 with [[Deep Learning]] and [[Idea]].")
+	if err := s.AddDocument(note); err != nil {
+		t.Fatal(err)
+	}
+
+	added, removed, err := SyncNoteLinks(s, []*Document{note, paper})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(added) != 2 || len(removed) != 0 {
+		t.Fatalf("added %d, removed %d", len(added), len(removed))
+	}
+	links, _ := s.ListDocumentLinks(paper.ID)
+	if len(links) != 1 || links[0].FromID != note.ID || links[0].Relation != LinkReferences || links[0].Relation.Inverse() != "referenced-by" {
+		t.Errorf("paper links = %+v", links)
+	}
+
+	// Syncing again changes nothing; dropping a mention drops its link
+	if added, removed, _ := SyncNoteLinks(s, []*Document{note}); len(added)+len(removed) != 0 {
+		t.Errorf("resync added %d, removed %d", len(added), len(removed))
+	}
+	note.Notes = "Idea\nOnly [[Deep Learning]] now."
+	added, removed, err = SyncNoteLinks(s, []*Document{note})
+	if err != nil || len(added) != 0 || len(removed) != 1 || removed[0].ToID != paper.ID {
+		t.Errorf("after edit: added %v, removed %v, %v", added, removed, err)
+	}
+	if links, _ := s.ListDocumentLinks(paper.ID); len(links) != 0 {
+		t.Errorf("paper still linked: %+v", links)
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// OCREngine is an external OCR program.
+type OCREngine string
+
+const (
+	OCRTesseract OCREngine = "tesseract" // pages rendered with pdftoppm; reports word confidence
+	OCRMyPDF     OCREngine = "ocrmypdf"  // reports no confidence
+)
+
+// OCRFlagTag is added to documents whose OCR confidence fell below the
+// threshold, so they can be found and checked by hand.
+const OCRFlagTag = "ocr-low-confidence"
+
+// DefaultOCRMinConfidence is the mean word confidence (0-100) below which
+// OCR output is flagged.
+const DefaultOCRMinConfidence = 60.0
+
+// OCRResult is the text recognized in a PDF.
+type OCRResult struct {
+	Engine     OCREngine
+	Text       string
+	Words      int
+	Confidence float64 // mean word confidence, 0-100; -1 when the engine reports none
+}
+
+// DetectOCREngine returns the first available engine, preferring tesseract
+// because it reports confidence.
+func DetectOCREngine() (OCREngine, error) {
+	if hasCommand("tesseract") && hasCommand("pdftoppm") {
+		return OCRTesseract, nil
+	}
+	if hasCommand("ocrmypdf") {
+		return OCRMyPDF, nil
+	}
+	return "", fmt.Errorf("no OCR engine found: install tesseract and poppler, or ocrmypdf")
+}
+
+// RunOCR recognizes the text of every page of a PDF. lang is a tesseract
+// language code such as "eng" or "eng+deu".
+func RunOCR(engine OCREngine, pdfPath, lang string) (*OCRResult, error) {
+	tmp, err := os.MkdirTemp("", "arc-library-ocr-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	switch engine {
+	case OCRTesseract:
+		return runTesseract(pdfPath, lang, tmp)
+	case OCRMyPDF:
+		return runOCRmyPDF(pdfPath, lang, tmp)
+	}
+	return nil, fmt.Errorf("unknown OCR engine %q (use tesseract or ocrmypdf)", engine)
+}
+
+func runTesseract(pdfPath, lang, tmp string) (*OCRResult, error) {
+	if err := runQuiet("pdftoppm", "-r", "300", "-png", pdfPath, filepath.Join(tmp, "page")); err != nil {
+		return nil, err
+	}
+	pages, err := filepath.Glob(filepath.Join(tmp, "page*.png"))
+	if err != nil {
+		return nil, err
+	}
+	// pdftoppm zero-pads page numbers, so names sort in page order
+	slices.Sort(pages)
+
+	res := &OCRResult{Engine: OCRTesseract, Confidence: -1}
+	var texts []string
+	var confSum float64
+	for _, page := range pages {
+		var out bytes.Buffer
+		cmd := exec.Command("tesseract", page, "stdout", "-l", lang, "tsv")
+		cmd.Stdout = &out
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return nil, fmt.Errorf("tesseract failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		page, err := parseTesseractTSV(&out)
+		if err != nil {
+			return nil, err
+		}
+		texts = append(texts, page.Text)
+		res.Words += page.Words
+		confSum += page.Confidence * float64(page.Words)
+	}
+	res.Text = strings.TrimSpace(strings.Join(texts, "\n\n"))
+	if res.Words > 0 {
+		res.Confidence = confSum / float64(res.Words)
+	}
+	return res, nil
+}
+
+func runOCRmyPDF(pdfPath, lang, tmp string) (*OCRResult, error) {
+	sidecar := filepath.Join(tmp, "text.txt")
+	// --skip-text leaves pages that already have text alone; the output PDF is discarded
+	err := runQuiet("ocrmypdf", "--skip-text", "--sidecar", sidecar, "-l", lang, pdfPath, filepath.Join(tmp, "out.pdf"))
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(sidecar)
+	if err != nil {
+		return nil, fmt.Errorf("read ocrmypdf output: %w", err)
+	}
+	// The sidecar separates pages with form feeds
+	text := strings.TrimSpace(strings.ReplaceAll(string(data), "\f", "\n\n"))
+	return &OCRResult{Engine: OCRMyPDF, Text: text, Words: len(strings.Fields(text)), Confidence: -1}, nil
+}
+
+// parseTesseractTSV rebuilds the text of one page from tesseract's TSV
+// output, keeping line breaks and blank lines between paragraphs, and
+// averages the confidence of recognized words.
+func parseTesseractTSV(r io.Reader) (*OCRResult, error) {
+	res := &OCRResult{Engine: OCRTesseract, Confidence: -1}
+	var b strings.Builder
+	var confSum float64
+	lastPar, lastLine := "", ""
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	header := true
+	for sc.Scan() {
+		if header {
+			header = false
+			continue
+		}
+		// level page block par line word left top width height conf text
+		cols := strings.Split(sc.Text(), "\t")
+		if len(cols) < 12 || cols[0] != "5" {
+			continue
+		}
+		word := strings.TrimSpace(cols[11])
+		conf, err := strconv.ParseFloat(cols[10], 64)
+		if word == "" || err != nil || conf < 0 {
+			continue
+		}
+
+		par := cols[2] + "." + cols[3]
+		line := par + "." + cols[4]
+		switch {
+		case b.Len() == 0:
+		case par != lastPar:
+			b.WriteString("\n\n")
+		case line != lastLine:
+			b.WriteString("\n")
+		default:
+			b.WriteString(" ")
+		}
+		b.WriteString(word)
+		lastPar, lastLine = par, line
+
+		res.Words++
+		confSum += conf
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read tesseract output: %w", err)
+	}
+
+	res.Text = b.String()
+	if res.Words > 0 {
+		res.Confidence = confSum / float64(res.Words)
+	}
+	return res, nil
+}
+
+// ApplyOCR stores recognized text in doc.FullText, records the engine and
+// confidence in Meta (ocr_engine, ocr_confidence), and adds or clears
+// OCRFlagTag. It reports whether the document was flagged: confidence below
+// minConfidence, or no text recognized at all.
+func ApplyOCR(doc *Document, res *OCRResult, minConfidence float64) bool {
+	doc.FullText = res.Text
+	if doc.Meta == nil {
+		doc.Meta = make(JSONMap)
+	}
+	doc.Meta["ocr_engine"] = string(res.Engine)
+	if res.Confidence >= 0 {
+		doc.Meta["ocr_confidence"] = float64(int(res.Confidence*10+0.5)) / 10
+	} else {
+		delete(doc.Meta, "ocr_confidence")
+	}
+
+	flagged := res.Words == 0 || (res.Confidence >= 0 && res.Confidence < minConfidence)
+	doc.Tags = slices.DeleteFunc(doc.Tags, func(t string) bool { return t == OCRFlagTag })
+	if flagged {
+		doc.Tags = append(doc.Tags, OCRFlagTag)
+	}
+	return flagged
+}
+
+func hasCommand(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
+
+func runQuiet(name string, args ...string) error {
+	if !hasCommand(name) {
+		return fmt.Errorf("%s not found", name)
+	}
+	cmd := exec.Command(name, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s failed: %w: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseTesseractTSV(t *testing.T) {
+	tsv := strings.Join([]string{
+		"level\tpage_num\tblock_num\tpar_num\tline_num\tword_num\tleft\ttop\twidth\theight\tconf\ttext",
+		"1\t1\t0\t0\t0\t0\t0\t0\t2480\t3508\t-1\t",
+		"5\t1\t1\t1\t1\t1\t10\t10\t50\t20\t90\tDeep",
+		"5\t1\t1\t1\t1\t2\t70\t10\t50\t20\t80\tlearning",
+		"5\t1\t1\t1\t2\t1\t10\t40\t50\t20\t70\tworks",
+		"5\t1\t1\t1\t2\t2\t70\t40\t50\t20\t-1\t ",
+		"5\t1\t2\t1\t1\t1\t10\t90\t50\t20\t60\tReferences",
+	}, "\n")
+
+	res, err := parseTesseractTSV(strings.NewReader(tsv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Deep learning\nworks\n\nReferences"
+	if res.Text != want {
+		t.Errorf("text = %q, want %q", res.Text, want)
+	}
+	if res.Words != 4 || res.Confidence != 75 {
+		t.Errorf("words = %d, confidence = %v; want 4, 75", res.Words, res.Confidence)
+	}
+
+	empty, err := parseTesseractTSV(strings.NewReader(""))
+	if err != nil || empty.Words != 0 || empty.Confidence != -1 {
+		t.Errorf("empty page = %+v, %v", empty, err)
+	}
+}
+
+func TestApplyOCR(t *testing.T) {
+	doc := &Document{Tags: []string{"scan"}}
+
+	if !ApplyOCR(doc, &OCRResult{Engine: OCRTesseract, Text: "blurry", Words: 1, Confidence: 41.26}, DefaultOCRMinConfidence) {
+		t.Error("low confidence should be flagged")
+	}
+	if doc.FullText != "blurry" || doc.Meta["ocr_confidence"] != 41.3 || !slices.Contains(doc.Tags, OCRFlagTag) {
+		t.Errorf("after low-confidence OCR: %+v", doc)
+	}
+
+	// A better pass clears the flag; an engine without confidence drops the score
+	if ApplyOCR(doc, &OCRResult{Engine: OCRMyPDF, Text: "clear text", Words: 2, Confidence: -1}, DefaultOCRMinConfidence) {
+		t.Error("result without confidence should not be flagged")
+	}
+	if slices.Contains(doc.Tags, OCRFlagTag) || len(doc.Tags) != 1 {
+		t.Errorf("tags = %v, want [scan]", doc.Tags)
+	}
+	if _, ok := doc.Meta["ocr_confidence"]; ok || doc.Meta["ocr_engine"] != "ocrmypdf" {
+		t.Errorf("meta = %v", doc.Meta)
+	}
+
+	if !ApplyOCR(doc, &OCRResult{Engine: OCRMyPDF, Confidence: -1}, DefaultOCRMinConfidence) {
+		t.Error("no recognized text should be flagged")
+	}
+}
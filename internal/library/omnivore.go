@@ -0,0 +1,176 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// omnivoreArticle is an entry of an Omnivore export's metadata_*.json.
+type omnivoreArticle struct {
+	ID              string              `json:"id"`
+	Slug            string              `json:"slug"`
+	Title           string              `json:"title"`
+	Description     string              `json:"description"`
+	Author          string              `json:"author"`
+	URL             string              `json:"url"`
+	State           string              `json:"state"`
+	ReadingProgress float64             `json:"readingProgress"` // percent
+	Labels          []json.RawMessage   `json:"labels"`          // names, or objects with a name
+	SavedAt         time.Time           `json:"savedAt"`
+	Highlights      []omnivoreHighlight `json:"highlights"` // in API responses; exports use highlights/<slug>.md
+}
+
+type omnivoreHighlight struct {
+	Quote      string    `json:"quote"`
+	Annotation string    `json:"annotation"`
+	Color      string    `json:"color"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// omnivoreHighlightLink is the link back to Omnivore after each quote in
+// an exported highlights file.
+var omnivoreHighlightLink = regexp.MustCompile(`\s*\[⤴️?\]\([^)]*\)\s*$`)
+
+// OpenOmnivoreExport reads the articles and highlights of an Omnivore
+// export, given as the zip file Omnivore produced or the directory it was
+// unpacked to.
+func OpenOmnivoreExport(p string) ([]*ReadLaterItem, error) {
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return ParseOmnivoreExport(os.DirFS(p))
+	}
+	zr, err := zip.OpenReader(p)
+	if err != nil {
+		return nil, fmt.Errorf("open Omnivore export: %w", err)
+	}
+	defer zr.Close()
+	return ParseOmnivoreExport(zr)
+}
+
+// ParseOmnivoreExport reads an Omnivore export: metadata_*.json files
+// listing the saved articles, and a highlights/<slug>.md file next to
+// them for each article with highlights.
+func ParseOmnivoreExport(fsys fs.FS) ([]*ReadLaterItem, error) {
+	var metaFiles []string
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := d.Name()
+		if !d.IsDir() && strings.HasPrefix(name, "metadata_") && strings.HasSuffix(name, ".json") {
+			metaFiles = append(metaFiles, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(metaFiles) == 0 {
+		return nil, fmt.Errorf("not an Omnivore export: no metadata_*.json files")
+	}
+	sort.Strings(metaFiles)
+
+	var items []*ReadLaterItem
+	for _, p := range metaFiles {
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return nil, err
+		}
+		var articles []omnivoreArticle
+		if err := json.Unmarshal(data, &articles); err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+		for _, a := range articles {
+			item := &ReadLaterItem{
+				ID:       a.ID,
+				URL:      a.URL,
+				Title:    a.Title,
+				Author:   a.Author,
+				Summary:  a.Description,
+				Tags:     omnivoreLabels(a.Labels),
+				SavedAt:  a.SavedAt,
+				Progress: a.ReadingProgress / 100,
+				Archived: strings.EqualFold(a.State, "archived"),
+			}
+			for _, h := range a.Highlights {
+				item.Highlights = append(item.Highlights, ReadLaterHighlight{Text: h.Quote, Note: h.Annotation, Color: h.Color, CreatedAt: h.CreatedAt})
+			}
+			if len(item.Highlights) == 0 && a.Slug != "" {
+				md, err := fs.ReadFile(fsys, path.Join(path.Dir(p), "highlights", a.Slug+".md"))
+				if err == nil {
+					item.Highlights = ParseOmnivoreHighlights(string(md))
+				}
+			}
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// ParseOmnivoreHighlights reads an exported highlights file, in which each
+// highlight is a "> " quote followed by the note made on it, if any.
+func ParseOmnivoreHighlights(md string) []ReadLaterHighlight {
+	var highlights []ReadLaterHighlight
+	var quote, note []string
+	inQuote := false
+	flush := func() {
+		if len(quote) > 0 {
+			text := omnivoreHighlightLink.ReplaceAllString(strings.Join(quote, "\n"), "")
+			highlights = append(highlights, ReadLaterHighlight{Text: strings.TrimSpace(text), Note: strings.TrimSpace(strings.Join(note, "\n"))})
+		}
+		quote, note = nil, nil
+	}
+	for _, line := range strings.Split(md, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, ">"):
+			if !inQuote {
+				flush()
+			}
+			inQuote = true
+			quote = append(quote, strings.TrimSpace(strings.TrimPrefix(trimmed, ">")))
+		case trimmed == "" || strings.HasPrefix(trimmed, "#"):
+			inQuote = false
+			if len(note) > 0 {
+				note = append(note, "")
+			}
+		case len(quote) > 0:
+			inQuote = false
+			note = append(note, trimmed)
+		}
+	}
+	flush()
+	return highlights
+}
+
+func omnivoreLabels(raw []json.RawMessage) []string {
+	var labels []string
+	for _, r := range raw {
+		var name string
+		if json.Unmarshal(r, &name) != nil {
+			var label struct {
+				Name string `json:"name"`
+			}
+			json.Unmarshal(r, &label)
+			name = label.Name
+		}
+		if name != "" {
+			labels = append(labels, name)
+		}
+	}
+	return labels
+}
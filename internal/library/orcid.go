@@ -0,0 +1,259 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ORCIDAPI is the ORCID public API base URL.
+const ORCIDAPI = "https://pub.orcid.org/v3.0"
+
+// NoFileTag marks documents imported as metadata only, without a file.
+const NoFileTag = "no-file"
+
+// orcidBulkSize is the most works the API returns details for at once.
+const orcidBulkSize = 100
+
+var orcidPattern = regexp.MustCompile(`^(\d{4})-?(\d{4})-?(\d{4})-?(\d{3}[\dX])$`)
+
+// NormalizeORCID validates an ORCID iD, given bare or as an orcid.org URL,
+// and returns it as 0000-0000-0000-0000.
+func NormalizeORCID(s string) (string, error) {
+	id := strings.TrimSpace(s)
+	for _, prefix := range []string{"https://", "http://", "orcid.org/", "www.orcid.org/"} {
+		id = strings.TrimPrefix(id, prefix)
+	}
+	m := orcidPattern.FindStringSubmatch(strings.ToUpper(id))
+	if m == nil {
+		return "", fmt.Errorf("invalid ORCID iD: %q", s)
+	}
+	digits := m[1] + m[2] + m[3] + m[4]
+
+	// ISO 7064 MOD 11-2 check digit
+	total := 0
+	for _, c := range digits[:15] {
+		total = (total + int(c-'0')) * 2
+	}
+	check := (12 - total%11) % 11
+	want := byte('0' + check)
+	if check == 10 {
+		want = 'X'
+	}
+	if digits[15] != want {
+		return "", fmt.Errorf("invalid ORCID iD: %q (checksum mismatch)", s)
+	}
+	return strings.Join(m[1:], "-"), nil
+}
+
+// orcidDocTypes maps ORCID work types to document types; others are
+// DocTypeOther.
+var orcidDocTypes = map[string]DocumentType{
+	"journal-article":     DocTypePaper,
+	"conference-paper":    DocTypePaper,
+	"preprint":            DocTypePaper,
+	"report":              DocTypePaper,
+	"working-paper":       DocTypePaper,
+	"dissertation-thesis": DocTypePaper,
+	"book":                DocTypeBook,
+	"book-chapter":        DocTypeBook,
+	"edited-book":         DocTypeBook,
+	"magazine-article":    DocTypeArticle,
+	"newspaper-article":   DocTypeArticle,
+	"online-resource":     DocTypeArticle,
+	"website":             DocTypeArticle,
+	"lecture-speech":      DocTypeVideo,
+	"software":            DocTypeRepo,
+}
+
+type orcidValue struct {
+	Value string `json:"value"`
+}
+
+type orcidExternalIDs struct {
+	ExternalID []struct {
+		Type         string `json:"external-id-type"`
+		Value        string `json:"external-id-value"`
+		Relationship string `json:"external-id-relationship"`
+	} `json:"external-id"`
+}
+
+// orcidWork is the part of an ORCID work record that is imported, common
+// to work summaries and full works.
+type orcidWork struct {
+	PutCode int    `json:"put-code"`
+	Type    string `json:"type"`
+	Title   struct {
+		Title orcidValue `json:"title"`
+	} `json:"title"`
+	JournalTitle    *orcidValue      `json:"journal-title"`
+	ShortDesc       string           `json:"short-description"`
+	URL             *orcidValue      `json:"url"`
+	ExternalIDs     orcidExternalIDs `json:"external-ids"`
+	PublicationDate *struct {
+		Year *orcidValue `json:"year"`
+	} `json:"publication-date"`
+	Contributors *struct {
+		Contributor []struct {
+			CreditName *orcidValue `json:"credit-name"`
+		} `json:"contributor"`
+	} `json:"contributors"`
+}
+
+// FetchORCIDWorks returns documents for the works on an ORCID record, one
+// per work (ORCID groups duplicate entries from different sources). The
+// documents are unsaved, metadata only, and tagged NoFileTag; works with a
+// DOI get it as their source ID. Authors come from each work's contributor
+// list, or are the record's owner when it has none. baseURL defaults to
+// ORCIDAPI.
+func FetchORCIDWorks(baseURL, id string) ([]*Document, error) {
+	if baseURL == "" {
+		baseURL = ORCIDAPI
+	}
+	base := strings.TrimSuffix(baseURL, "/") + "/" + id
+	client := &http.Client{Timeout: 60 * time.Second}
+
+	var person struct {
+		Name *struct {
+			GivenNames *orcidValue `json:"given-names"`
+			FamilyName *orcidValue `json:"family-name"`
+			CreditName *orcidValue `json:"credit-name"`
+		} `json:"name"`
+	}
+	if err := orcidGet(client, base+"/person", &person); err != nil {
+		return nil, err
+	}
+	owner := ""
+	if n := person.Name; n != nil {
+		switch {
+		case n.CreditName != nil && n.CreditName.Value != "":
+			owner = n.CreditName.Value
+		case n.GivenNames != nil:
+			owner = n.GivenNames.Value
+			if n.FamilyName != nil {
+				owner += " " + n.FamilyName.Value
+			}
+		}
+	}
+
+	var works struct {
+		Group []struct {
+			Summary []orcidWork `json:"work-summary"`
+		} `json:"group"`
+	}
+	if err := orcidGet(client, base+"/works", &works); err != nil {
+		return nil, err
+	}
+	// The first summary of each group is the record owner's preferred one
+	var codes []string
+	for _, g := range works.Group {
+		if len(g.Summary) > 0 {
+			codes = append(codes, strconv.Itoa(g.Summary[0].PutCode))
+		}
+	}
+
+	var docs []*Document
+	for start := 0; start < len(codes); start += orcidBulkSize {
+		batch := codes[start:min(start+orcidBulkSize, len(codes))]
+		var bulk struct {
+			Bulk []struct {
+				Work *orcidWork `json:"work"`
+			} `json:"bulk"`
+		}
+		if err := orcidGet(client, base+"/works/"+strings.Join(batch, ","), &bulk); err != nil {
+			return nil, err
+		}
+		for _, b := range bulk.Bulk {
+			if b.Work != nil {
+				docs = append(docs, orcidDocument(id, owner, b.Work))
+			}
+		}
+	}
+	return docs, nil
+}
+
+// orcidDocument builds the document for a work on the record of id.
+func orcidDocument(id, owner string, w *orcidWork) *Document {
+	docType, ok := orcidDocTypes[w.Type]
+	if !ok {
+		docType = DocTypeOther
+	}
+	doc := &Document{
+		Type:     docType,
+		Source:   "orcid",
+		SourceID: fmt.Sprintf("%s/%d", id, w.PutCode),
+		Title:    strings.TrimSpace(w.Title.Title.Value),
+		Abstract: strings.TrimSpace(w.ShortDesc),
+		Tags:     []string{NoFileTag},
+		Status:   StatusUnread,
+		Meta:     JSONMap{"orcid": id},
+	}
+	for _, ext := range w.ExternalIDs.ExternalID {
+		// Only the work's own identifiers, not those of what it is part of
+		if ext.Relationship != "" && ext.Relationship != "self" {
+			continue
+		}
+		switch strings.ToLower(ext.Type) {
+		case "doi":
+			if doc.Source != "doi" {
+				doc.Source = "doi"
+				doc.SourceID = strings.TrimPrefix(strings.ToLower(ext.Value), "https://doi.org/")
+			}
+		case "arxiv":
+			doc.Meta["arxiv"] = strings.TrimPrefix(ext.Value, "arXiv:")
+		}
+	}
+	if w.Contributors != nil {
+		for _, c := range w.Contributors.Contributor {
+			if c.CreditName != nil && c.CreditName.Value != "" {
+				doc.Authors = append(doc.Authors, c.CreditName.Value)
+			}
+		}
+	}
+	if len(doc.Authors) == 0 && owner != "" {
+		doc.Authors = []string{owner}
+	}
+	if w.PublicationDate != nil && w.PublicationDate.Year != nil {
+		if y, err := strconv.Atoi(w.PublicationDate.Year.Value); err == nil {
+			doc.Meta["year"] = y
+		}
+	}
+	if w.JournalTitle != nil && w.JournalTitle.Value != "" {
+		doc.Meta["journal"] = w.JournalTitle.Value
+	}
+	if w.URL != nil && w.URL.Value != "" {
+		doc.Meta["url"] = w.URL.Value
+	}
+	return doc
+}
+
+func orcidGet(client *http.Client, url string, out any) error {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "arc-library/1.0")
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("orcid: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("orcid: no public record at %s", url)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("orcid: %s: %s", url, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("orcid: decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestNormalizeORCID(t *testing.T) {
+	for _, in := range []string{"0000-0002-1825-0097", "https://orcid.org/0000-0002-1825-0097", "0000000218250097"} {
+		if id, err := NormalizeORCID(in); err != nil || id != "0000-0002-1825-0097" {
+			t.Errorf("NormalizeORCID(%q) = %q, %v", in, id, err)
+		}
+	}
+	if id, err := NormalizeORCID("0000-0002-1694-233x"); err != nil || id != "0000-0002-1694-233X" {
+		t.Errorf("X check digit: %q, %v", id, err)
+	}
+	for _, in := range []string{"0000-0002-1825-0098", "1234", ""} {
+		if _, err := NormalizeORCID(in); err == nil {
+			t.Errorf("NormalizeORCID(%q) should fail", in)
+		}
+	}
+}
+
+func TestFetchORCIDWorks(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/0000-0002-1825-0097/person":
+			w.Write([]byte(`{"name": {"given-names": {"value": "Josiah"}, "family-name": {"value": "Carberry"}}}`))
+		case "/0000-0002-1825-0097/works":
+			w.Write([]byte(`{"group": [
+				{"work-summary": [{"put-code": 11}, {"put-code": 12}]},
+				{"work-summary": [{"put-code": 21}]}
+			]}`))
+		case "/0000-0002-1825-0097/works/11,21":
+			w.Write([]byte(`{"bulk": [
+				{"work": {"put-code": 11, "type": "journal-article",
+					"title": {"title": {"value": "Psychoceramics"}},
+					"journal-title": {"value": "Journal of Pottery"},
+					"publication-date": {"year": {"value": "2008"}},
+					"external-ids": {"external-id": [
+						{"external-id-type": "doi", "external-id-value": "10.5555/12345678", "external-id-relationship": "self"},
+						{"external-id-type": "issn", "external-id-value": "1234-5678", "external-id-relationship": "part-of"}
+					]},
+					"contributors": {"contributor": [{"credit-name": {"value": "Josiah Carberry"}}, {"credit-name": {"value": "Ann Other"}}]}}},
+				{"work": {"put-code": 21, "type": "book", "title": {"title": {"value": "Cracked Pots"}}}}
+			]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	docs, err := FetchORCIDWorks(srv.URL, "0000-0002-1825-0097")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 2 {
+		t.Fatalf("got %d documents, want one per work group", len(docs))
+	}
+
+	paper := docs[0]
+	if paper.Type != DocTypePaper || paper.Source != "doi" || paper.SourceID != "10.5555/12345678" {
+		t.Errorf("paper = %+v", paper)
+	}
+	if paper.Meta["year"] != 2008 || paper.Meta["journal"] != "Journal of Pottery" || paper.Meta["orcid"] != "0000-0002-1825-0097" {
+		t.Errorf("paper meta = %v", paper.Meta)
+	}
+	if !slices.Equal(paper.Authors, []string{"Josiah Carberry", "Ann Other"}) || !slices.Equal(paper.Tags, []string{NoFileTag}) {
+		t.Errorf("paper authors %v, tags %v", paper.Authors, paper.Tags)
+	}
+
+	book := docs[1]
+	if book.Type != DocTypeBook || book.Source != "orcid" || book.SourceID != "0000-0002-1825-0097/21" {
+		t.Errorf("book = %+v", book)
+	}
+	if !slices.Equal(book.Authors, []string{"Josiah Carberry"}) {
+		t.Errorf("book authors = %v, want the record owner", book.Authors)
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/yourorg/arc-sdk/db"
+)
+
+// MissingFileTag marks documents whose file was deleted or moved out of a
+// watched directory.
+const MissingFileTag = "file-missing"
+
+// LibraryRoot returns the library root directory from $ARC_LIBRARY_ROOT,
+// or "" when unset. Document files under the root are stored with paths
+// relative to it, so the library directory can be moved or synced between
+// machines without breaking them.
+func LibraryRoot() string {
+	root := ExpandPath(os.Getenv("ARC_LIBRARY_ROOT"))
+	if root == "" {
+		return ""
+	}
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+	return root
+}
+
+// StoredPath returns the form of path to store in Document.Path: relative
+// to root with forward slashes when the file is inside root, otherwise
+// absolute.
+func StoredPath(path, root string) string {
+	if path == "" {
+		return ""
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if root == "" {
+		return path
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// ResolvePath turns a stored Document.Path back into a filesystem path,
+// joining relative paths onto root. Relative paths stay as they are when
+// no root is configured.
+func ResolvePath(path, root string) string {
+	if path == "" || filepath.IsAbs(path) || root == "" {
+		return path
+	}
+	return filepath.Join(root, filepath.FromSlash(path))
+}
+
+// DocumentPath returns the filesystem path of doc's file under the
+// configured library root, or "" if it has none.
+func DocumentPath(doc *Document) string {
+	return ResolvePath(doc.Path, LibraryRoot())
+}
+
+// envVarPattern matches $VAR, ${VAR}, and Windows' %VAR%.
+var envVarPattern = regexp.MustCompile(`\$\{(\w+)\}|\$(\w+)|%(\w+)%`)
+
+// ExpandPath expands a path from a setting the way a shell would, for
+// paths no shell has seen: environment variables and settings files.
+// Command-line arguments have been through the shell already, and are
+// used as given. A leading ~ becomes the home directory,
+// and $VAR, ${VAR}, and %VAR% become the variable's value; HOME and
+// USERPROFILE are the home directory on every platform, so a setting
+// shared between Windows and Unix machines works on both. A variable that
+// is not set is left as written, so file names with $ or % in them
+// survive. Paths written the Windows way, after ~\ or %VAR%, have their
+// backslashes turned into slashes elsewhere. The result is cleaned, and
+// uses the platform's separator.
+func ExpandPath(p string) string {
+	if p == "" {
+		return ""
+	}
+	windowsStyle := strings.HasPrefix(p, `~\`)
+	if p == "~" || strings.HasPrefix(p, "~/") || windowsStyle {
+		if home, err := os.UserHomeDir(); err == nil {
+			p = home + p[1:]
+		}
+	}
+	p = envVarPattern.ReplaceAllStringFunc(p, func(m string) string {
+		sub := envVarPattern.FindStringSubmatch(m)
+		name := sub[1] + sub[2] + sub[3]
+		if v, ok := os.LookupEnv(name); ok {
+			windowsStyle = windowsStyle || sub[3] != ""
+			return v
+		}
+		if strings.EqualFold(name, "HOME") || strings.EqualFold(name, "USERPROFILE") {
+			if home, err := os.UserHomeDir(); err == nil {
+				windowsStyle = windowsStyle || sub[3] != ""
+				return home
+			}
+		}
+		return m
+	})
+	if windowsStyle && runtime.GOOS != "windows" {
+		p = strings.ReplaceAll(p, `\`, "/")
+	}
+	return filepath.Clean(filepath.FromSlash(p))
+}
+
+// userConfigDir is the platform's config directory, which ConfigDir uses
+// unless $XDG_CONFIG_HOME is set.
+var userConfigDir = os.UserConfigDir
+
+// ConfigDir returns the directory arc-library's settings files are in:
+// arc-library under $XDG_CONFIG_HOME when it is set, on any platform,
+// otherwise under the platform's config directory (~/.config,
+// ~/Library/Application Support, or %AppData%).
+func ConfigDir() (string, error) {
+	base := ExpandPath(os.Getenv("XDG_CONFIG_HOME"))
+	if !filepath.IsAbs(base) {
+		var err error
+		if base, err = userConfigDir(); err != nil {
+			return "", fmt.Errorf("find config directory: %w", err)
+		}
+	}
+	return filepath.Join(base, "arc-library"), nil
+}
+
+// configFile returns the settings file env names, or name under ConfigDir.
+// Where $XDG_CONFIG_HOME moves ConfigDir away from the platform's config
+// directory, as on macOS and Windows, a file kept in the platform's
+// directory is still used while ConfigDir has none.
+func configFile(env, name string) (string, error) {
+	if p := os.Getenv(env); p != "" {
+		return ExpandPath(p), nil
+	}
+	dir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, name)
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		if base, err := userConfigDir(); err == nil {
+			if old := filepath.Join(base, "arc-library", name); old != path {
+				if _, err := os.Stat(old); err == nil {
+					return old, nil
+				}
+			}
+		}
+	}
+	return path, nil
+}
+
+// CacheDir returns the base directory of the managed caches:
+// $ARC_LIBRARY_CACHE_DIR, or arc-library under $XDG_CACHE_HOME when it is
+// set, otherwise under the platform's cache directory (~/.cache,
+// ~/Library/Caches, or %LocalAppData%).
+func CacheDir() (string, error) {
+	if base := os.Getenv("ARC_LIBRARY_CACHE_DIR"); base != "" {
+		return ExpandPath(base), nil
+	}
+	base := ExpandPath(os.Getenv("XDG_CACHE_HOME"))
+	if !filepath.IsAbs(base) {
+		var err error
+		if base, err = os.UserCacheDir(); err != nil {
+			return "", fmt.Errorf("find cache directory: %w", err)
+		}
+	}
+	return filepath.Join(base, "arc-library"), nil
+}
+
+// dataHome returns the base directory for user data: $XDG_DATA_HOME when
+// it is set, %LocalAppData% on Windows, otherwise ~/.local/share.
+func dataHome() (string, error) {
+	if base := ExpandPath(os.Getenv("XDG_DATA_HOME")); filepath.IsAbs(base) {
+		return base, nil
+	}
+	if runtime.GOOS == "windows" {
+		if base := os.Getenv("LOCALAPPDATA"); base != "" {
+			return base, nil
+		}
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot determine home directory: %w", err)
+	}
+	return filepath.Join(home, ".local", "share"), nil
+}
+
+// DatabasePath returns the SQL database file: $ARC_LIBRARY_DB, or the
+// arc-sdk database the other arc tools share.
+func DatabasePath() string {
+	if p := os.Getenv("ARC_LIBRARY_DB"); p != "" {
+		return ExpandPath(p)
+	}
+	return db.DefaultDBPath()
+}
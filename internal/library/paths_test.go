@@ -0,0 +1,138 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestStoredPath(t *testing.T) {
+	root := t.TempDir()
+	inside := filepath.Join(root, "papers", "a.pdf")
+	outside := filepath.Join(filepath.Dir(root), "elsewhere.pdf")
+
+	if got := StoredPath(inside, root); got != "papers/a.pdf" {
+		t.Errorf("inside root = %q, want papers/a.pdf", got)
+	}
+	if got := StoredPath(outside, root); got != outside {
+		t.Errorf("outside root = %q, want %q", got, outside)
+	}
+	if got := StoredPath(inside, ""); got != inside {
+		t.Errorf("without root = %q, want %q", got, inside)
+	}
+
+	// Stored paths resolve back to the files, under whichever root is current
+	moved := t.TempDir()
+	if got := ResolvePath("papers/a.pdf", moved); got != filepath.Join(moved, "papers", "a.pdf") {
+		t.Errorf("resolved = %q", got)
+	}
+	if got := ResolvePath(outside, moved); got != outside {
+		t.Errorf("absolute path resolved to %q", got)
+	}
+}
+
+func TestDocumentPath(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("ARC_LIBRARY_ROOT", root)
+	doc := &Document{Path: "books/b.epub"}
+	if got := DocumentPath(doc); got != filepath.Join(root, "books", "b.epub") {
+		t.Errorf("DocumentPath = %q", got)
+	}
+}
+
+func TestExpandPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", "")
+	os.Unsetenv("USERPROFILE")
+	t.Setenv("PAPERS", "papers")
+	os.Unsetenv("ARC_TEST_UNSET")
+
+	for in, want := range map[string]string{
+		"":                     "",
+		"~":                    home,
+		"~/Dropbox/lib/":       filepath.Join(home, "Dropbox", "lib"),
+		`~\Dropbox\lib`:        filepath.Join(home, "Dropbox", "lib"),
+		"$HOME/$PAPERS":        filepath.Join(home, "papers"),
+		"${HOME}/a//b/../c":    filepath.Join(home, "a", "c"),
+		`%USERPROFILE%\Papers`: filepath.Join(home, "Papers"),
+		"~other/x":             filepath.Join("~other", "x"),
+		"$ARC_TEST_UNSET/x":    filepath.Join("$ARC_TEST_UNSET", "x"),
+		"100%done.pdf":         "100%done.pdf",
+	} {
+		if got := ExpandPath(in); got != want {
+			t.Errorf("ExpandPath(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if runtime.GOOS != "windows" {
+		// Only paths written the Windows way lose their backslashes
+		if got := ExpandPath(`notes\a.md`); got != `notes\a.md` {
+			t.Errorf("backslash in a Unix file name = %q", got)
+		}
+	}
+
+	t.Setenv("ARC_LIBRARY_ROOT", "~/library")
+	if got := LibraryRoot(); got != filepath.Join(home, "library") {
+		t.Errorf("LibraryRoot = %q", got)
+	}
+}
+
+func TestUserDirs(t *testing.T) {
+	base := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(base, "config"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(base, "cache"))
+	t.Setenv("XDG_DATA_HOME", filepath.Join(base, "data"))
+	t.Setenv("ARC_LIBRARY_CACHE_DIR", "")
+	t.Setenv("ARC_LIBRARY_ROOT", "")
+	t.Setenv("ARC_LIBRARY_HOOKS", "")
+
+	if dir, err := ConfigDir(); err != nil || dir != filepath.Join(base, "config", "arc-library") {
+		t.Errorf("ConfigDir = %q, %v", dir, err)
+	}
+	if path, err := HooksFile(); err != nil || path != filepath.Join(base, "config", "arc-library", "hooks.yaml") {
+		t.Errorf("HooksFile = %q, %v", path, err)
+	}
+	if dir, err := CacheDir(); err != nil || dir != filepath.Join(base, "cache", "arc-library") {
+		t.Errorf("CacheDir = %q, %v", dir, err)
+	}
+	if dir, err := FilesDir(); err != nil || dir != filepath.Join(base, "data", "arc", "files") {
+		t.Errorf("FilesDir = %q, %v", dir, err)
+	}
+
+	// Settings left in the platform's directory are read while the XDG
+	// one has none
+	platform := filepath.Join(base, "platform")
+	userConfigDir = func() (string, error) { return platform, nil }
+	t.Cleanup(func() { userConfigDir = os.UserConfigDir })
+	old := filepath.Join(platform, "arc-library", "hooks.yaml")
+	if err := os.MkdirAll(filepath.Dir(old), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(old, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if path, err := HooksFile(); err != nil || path != old {
+		t.Errorf("HooksFile = %q, %v, want the platform's", path, err)
+	}
+	xdg := filepath.Join(base, "config", "arc-library", "hooks.yaml")
+	if err := os.MkdirAll(filepath.Dir(xdg), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(xdg, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if path, err := HooksFile(); err != nil || path != xdg {
+		t.Errorf("HooksFile = %q, %v, want the XDG one", path, err)
+	}
+
+	// A file named by its variable is expanded like any path
+	t.Setenv("HOME", base)
+	t.Setenv("ARC_LIBRARY_HOOKS", "~/hooks.yaml")
+	if path, err := HooksFile(); err != nil || path != filepath.Join(base, "hooks.yaml") {
+		t.Errorf("HooksFile = %q, %v", path, err)
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+func init() { RegisterImporter(pdfImporter{}) }
+
+// pdfImporter imports a PDF as a paper titled by its file name. The
+// caller reads a better title from the page, GROBID, or an identifier.
+type pdfImporter struct{}
+
+func (pdfImporter) Format() Format {
+	return Format{
+		Name:         "pdf",
+		Description:  "PDF files, one document each; a directory imports the PDFs in it",
+		Extensions:   []string{".pdf"},
+		Capabilities: []string{FormatFiles},
+	}
+}
+
+func (pdfImporter) Detect(path string, info fs.FileInfo) bool {
+	return !info.IsDir() && strings.EqualFold(filepath.Ext(path), ".pdf")
+}
+
+func (pdfImporter) Import(path string) ([]*Document, error) {
+	title := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return []*Document{{Path: path, Title: title, Type: DocTypePaper}}, nil
+}
@@ -0,0 +1,318 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// PDFHeading is the title and authors read from the top of a PDF's first
+// page.
+type PDFHeading struct {
+	Title   string   `json:"title"`
+	Authors []string `json:"authors,omitempty"`
+}
+
+// ExtractPDFHeading reads the layout of a PDF's first page with
+// "pdftotext -bbox-layout" and finds its title and authors; see
+// ParsePDFHeading. pdftotext gets as long as for text extraction (see
+// SetTextExtraction).
+func ExtractPDFHeading(pdfPath string) (*PDFHeading, error) {
+	out, err := runPDFToText(textExtractor.Timeout, "-bbox-layout", "-f", "1", "-l", "1", pdfPath, "-")
+	if err != nil {
+		return nil, err
+	}
+	return ParsePDFHeading(out)
+}
+
+// bboxPage is a page of "pdftotext -bbox-layout" output.
+type bboxPage struct {
+	Height float64 `xml:"height,attr"`
+	Lines  []struct {
+		YMin  float64 `xml:"yMin,attr"`
+		YMax  float64 `xml:"yMax,attr"`
+		Words []struct {
+			XMin float64 `xml:"xMin,attr"`
+			XMax float64 `xml:"xMax,attr"`
+			Text string  `xml:",chardata"`
+		} `xml:"word"`
+	} `xml:"flow>block>line"`
+}
+
+// layoutLine is a line of text with its vertical extent in points; its
+// height stands in for the font size.
+type layoutLine struct {
+	Text      string
+	Top, Size float64
+}
+
+// ParsePDFHeading finds the title and authors in "pdftotext -bbox-layout"
+// output. The title is the run of lines set in the largest type in the
+// top part of the page, skipping running headers, arXiv stamps, emails,
+// and URLs; the authors are the names on the lines below it, up to the
+// affiliations or abstract. The title is "" when no line stands out from
+// the body text.
+func ParsePDFHeading(bbox []byte) (*PDFHeading, error) {
+	var doc struct {
+		Pages []bboxPage `xml:"body>doc>page"`
+	}
+	dec := xml.NewDecoder(bytes.NewReader(bbox))
+	dec.Strict = false
+	dec.Entity = xml.HTMLEntity
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("parse pdftotext layout: %w", err)
+	}
+	heading := &PDFHeading{}
+	if len(doc.Pages) == 0 {
+		return heading, nil
+	}
+	page := doc.Pages[0]
+
+	var lines []layoutLine
+	for _, l := range page.Lines {
+		size := l.YMax - l.YMin
+		var text strings.Builder
+		for i, w := range l.Words {
+			if i > 0 {
+				// Names set apart by wide spaces are separate names
+				if w.XMin-l.Words[i-1].XMax > size {
+					text.WriteByte(',')
+				}
+				text.WriteByte(' ')
+			}
+			text.WriteString(strings.TrimSpace(w.Text))
+		}
+		if t := strings.Join(strings.Fields(text.String()), " "); t != "" && size > 0 {
+			lines = append(lines, layoutLine{Text: t, Top: l.YMin, Size: size})
+		}
+	}
+	if len(lines) == 0 {
+		return heading, nil
+	}
+
+	// Most lines are body text, so the median size is its size; a title is
+	// set clearly larger
+	sizes := make([]float64, len(lines))
+	for i, l := range lines {
+		sizes[i] = l.Size
+	}
+	sort.Float64s(sizes)
+	body := sizes[len(sizes)/2]
+
+	limit := page.Height * 0.6
+	if limit == 0 {
+		limit = lines[len(lines)-1].Top + 1
+	}
+	start, largest := -1, 0.0
+	for i, l := range lines {
+		if l.Top > limit || noiseLine(l.Text) {
+			continue
+		}
+		if l.Size > largest*1.05 {
+			start, largest = i, l.Size
+		}
+	}
+	if start < 0 || largest < body*1.15 {
+		return heading, nil
+	}
+
+	// The title continues over lines of the same size just below
+	end := start + 1
+	for end < len(lines) {
+		prev, l := lines[end-1], lines[end]
+		if l.Size < largest*0.9 || l.Size > largest*1.1 || l.Top-prev.Top > 2.5*largest || noiseLine(l.Text) {
+			break
+		}
+		end++
+	}
+	var title strings.Builder
+	for _, l := range lines[start:end] {
+		switch {
+		case title.Len() == 0:
+		case strings.HasSuffix(title.String(), "-"):
+			// A word broken over two lines
+		default:
+			title.WriteByte(' ')
+		}
+		title.WriteString(l.Text)
+	}
+	heading.Title = strings.TrimSpace(title.String())
+	if n := len([]rune(heading.Title)); n < 4 || n > 300 {
+		return &PDFHeading{}, nil
+	}
+
+	for _, l := range lines[end:min(end+4, len(lines))] {
+		if abstractLine.MatchString(l.Text) || affiliationLine.MatchString(l.Text) {
+			break
+		}
+		if noiseLine(l.Text) {
+			continue
+		}
+		heading.Authors = append(heading.Authors, parseHeadingAuthors(l.Text)...)
+	}
+	return heading, nil
+}
+
+var (
+	// noisePattern matches lines that are not a title: running headers,
+	// identifiers, contact details, and publication notes.
+	noisePattern    = regexp.MustCompile(`(?i)@|https?://|www\.|\barxiv:|\bdoi\b|©|\bcopyright\b|\bpreprint\b|\bproceedings\b|\bconference\b|\bworkshop\b|\bvol\.|\bissn\b|\b(?:accepted|submitted|published|received)\b|\bunder review\b|^page \d|^\d+$`)
+	abstractLine    = regexp.MustCompile(`(?i)^\s*abstract\b`)
+	affiliationLine = regexp.MustCompile(`(?i)\b(?:university|universit[àäé]|institute|department|dept\.|school of|laboratory|college|faculty|centre|center for|inc\.|corporation)\b`)
+	// nameParticles are the lowercase words allowed in author names.
+	nameParticles = map[string]bool{"van": true, "von": true, "de": true, "der": true, "del": true, "da": true, "di": true, "le": true, "la": true}
+	// footnoteMarks are the affiliation and note markers after author names.
+	footnoteMarks = regexp.MustCompile(`[\d*†‡§¶#∗⋆]+`)
+)
+
+// noiseLine reports whether a line of the page head is something other
+// than the title.
+func noiseLine(s string) bool {
+	letters := 0
+	for _, r := range s {
+		if unicode.IsLetter(r) {
+			letters++
+		}
+	}
+	return letters < 3 || noisePattern.MatchString(s)
+}
+
+// parseHeadingAuthors splits an author line into names, dropping footnote
+// markers and anything that doesn't look like a name.
+func parseHeadingAuthors(s string) []string {
+	s = footnoteMarks.ReplaceAllString(s, " ")
+	s = strings.NewReplacer(" and ", ",", "&", ",", ";", ",", "·", ",", "•", ",").Replace(s)
+	var names []string
+	for _, part := range strings.Split(s, ",") {
+		words := strings.Fields(part)
+		if len(words) < 2 || len(words) > 4 {
+			continue
+		}
+		ok := true
+		for _, w := range words {
+			r := []rune(w)
+			if !unicode.IsUpper(r[0]) && !nameParticles[w] {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			names = append(names, strings.Join(words, " "))
+		}
+	}
+	return names
+}
+
+// GuessTitleFromText picks a title from plain extracted text: the first of
+// the opening lines that is of title length and not a header, stamp, or
+// contact line. It is the fallback when the layout gives no answer.
+func GuessTitleFromText(text string) string {
+	lines := strings.Split(text, "\n")
+	for _, line := range lines[:min(len(lines), 40)] {
+		line = strings.Join(strings.Fields(line), " ")
+		if len(line) <= 10 || len(line) >= 200 || noiseLine(line) || len(strings.Fields(line)) < 2 {
+			continue
+		}
+		if abstractLine.MatchString(line) || affiliationLine.MatchString(line) {
+			continue
+		}
+		return line
+	}
+	return ""
+}
+
+// MatchTitle looks a title up on Crossref and then arXiv and returns the
+// identifier of a work with the same title, or "" for both when neither
+// has one. Titles match when their words are nearly the same, so case,
+// punctuation, and a stray extraction error don't matter.
+func MatchTitle(title string) (source, id string, err error) {
+	if normalizeReferenceTitle(title) == "" {
+		return "", "", nil
+	}
+
+	q := url.Values{"query.bibliographic": {title}, "rows": {"5"}, "select": {"DOI,title"}}
+	body, err := fetchMetadata(crossrefAPI + "?" + q.Encode())
+	if err != nil {
+		return "", "", fmt.Errorf("Crossref title search failed: %w", err)
+	}
+	var works struct {
+		Message struct {
+			Items []struct {
+				DOI   string   `json:"DOI"`
+				Title []string `json:"title"`
+			} `json:"items"`
+		} `json:"message"`
+	}
+	if err := json.Unmarshal(body, &works); err != nil {
+		return "", "", fmt.Errorf("decode Crossref search: %w", err)
+	}
+	for _, item := range works.Message.Items {
+		if len(item.Title) > 0 && titlesMatch(title, item.Title[0]) && item.DOI != "" {
+			if strings.HasPrefix(item.DOI, "10.1101/") {
+				return IDSourceBioRxiv, item.DOI, nil
+			}
+			return IDSourceDOI, item.DOI, nil
+		}
+	}
+
+	// arXiv phrase search wants the title's words without punctuation
+	q = url.Values{"search_query": {`ti:"` + normalizeReferenceTitle(title) + `"`}, "max_results": {"5"}}
+	body, err = fetchMetadata(arxivAPI + "?" + q.Encode())
+	if err != nil {
+		return "", "", fmt.Errorf("arXiv title search failed: %w", err)
+	}
+	var feed struct {
+		Entries []struct {
+			ID    string `xml:"id"`
+			Title string `xml:"title"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(body, &feed); err != nil {
+		return "", "", fmt.Errorf("decode arXiv search: %w", err)
+	}
+	for _, e := range feed.Entries {
+		if !titlesMatch(title, e.Title) {
+			continue
+		}
+		if m := arxivIDPattern.FindStringSubmatch(e.ID); m != nil {
+			return IDSourceArxiv, m[1], nil
+		}
+	}
+	return "", "", nil
+}
+
+// titlesMatch reports whether two titles have nearly the same words: a
+// Jaccard similarity of at least 0.85 over words of three letters or more.
+func titlesMatch(a, b string) bool {
+	na, nb := normalizeReferenceTitle(a), normalizeReferenceTitle(b)
+	if na == nb {
+		return na != ""
+	}
+	words := func(s string) map[string]bool {
+		set := map[string]bool{}
+		for _, w := range strings.Fields(s) {
+			if len(w) > 2 {
+				set[w] = true
+			}
+		}
+		return set
+	}
+	wa, wb := words(na), words(nb)
+	shared := 0
+	for w := range wa {
+		if wb[w] {
+			shared++
+		}
+	}
+	union := len(wa) + len(wb) - shared
+	return union > 0 && float64(shared)/float64(union) >= 0.85
+}
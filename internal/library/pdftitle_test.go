@@ -0,0 +1,98 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+)
+
+// firstPageLayout is trimmed "pdftotext -bbox-layout" output for a paper
+// with an arXiv stamp and a conference header above the title.
+const firstPageLayout = `<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Transitional//EN" "http://www.w3.org/TR/xhtml1/DTD/xhtml1-transitional.dtd">
+<html xmlns="http://www.w3.org/1999/xhtml">
+<head><title></title></head>
+<body>
+<doc>
+  <page width="612.000000" height="792.000000">
+    <flow><block>
+      <line xMin="10" yMin="200.0" xMax="30" yMax="220.0"><word>arXiv:1706.03762v7</word><word>[cs.CL]</word><word>2</word><word>Aug</word><word>2023</word></line>
+    </block></flow>
+    <flow><block>
+      <line xMin="100" yMin="60.0" xMax="500" yMax="69.0"><word>31st</word><word>Conference</word><word>on</word><word>Neural</word><word>Information</word><word>Processing</word><word>Systems</word></line>
+    </block></flow>
+    <flow><block>
+      <line xMin="150" yMin="100.0" xMax="450" yMax="117.2"><word>Attention</word><word>Is</word><word>All</word><word>You</word></line>
+      <line xMin="150" yMin="120.0" xMax="450" yMax="137.2"><word>Need</word><word>&amp;</word><word>Self-</word></line>
+      <line xMin="150" yMin="140.0" xMax="450" yMax="157.2"><word>Attention</word></line>
+    </block></flow>
+    <flow><block>
+      <line xMin="100" yMin="180.0" xMax="500" yMax="190.0"><word xMin="100" xMax="130">Ashish</word><word xMin="133" xMax="170">Vaswani∗</word><word xMin="220" xMax="245">Noam</word><word xMin="248" xMax="290">Shazeer1,</word><word xMin="293" xMax="310">and</word><word xMin="313" xMax="330">Niki</word><word xMin="333" xMax="360">Parmar†</word></line>
+      <line xMin="100" yMin="192.0" xMax="500" yMax="202.0"><word>Google</word><word>Brain,</word><word>Research</word><word>Institute</word></line>
+      <line xMin="100" yMin="204.0" xMax="500" yMax="214.0"><word>avaswani@google.com</word></line>
+    </block></flow>
+    <flow><block>
+      <line xMin="100" yMin="260.0" xMax="500" yMax="270.0"><word>Abstract</word></line>
+      <line xMin="100" yMin="272.0" xMax="500" yMax="282.0"><word>The</word><word>dominant</word><word>sequence</word><word>models</word></line>
+      <line xMin="100" yMin="284.0" xMax="500" yMax="294.0"><word>are</word><word>based</word><word>on</word><word>recurrent</word></line>
+      <line xMin="100" yMin="296.0" xMax="500" yMax="306.0"><word>neural</word><word>networks</word><word>that</word><word>include</word></line>
+    </block></flow>
+  </page>
+</doc>
+</body>
+</html>`
+
+func TestParsePDFHeading(t *testing.T) {
+	h, err := ParsePDFHeading([]byte(firstPageLayout))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Title != "Attention Is All You Need & Self-Attention" {
+		t.Errorf("title = %q", h.Title)
+	}
+	if !slices.Equal(h.Authors, []string{"Ashish Vaswani", "Noam Shazeer", "Niki Parmar"}) {
+		t.Errorf("authors = %q", h.Authors)
+	}
+
+	// A page set in one size has no title to find
+	flat, err := ParsePDFHeading([]byte(`<html><body><doc><page height="792">
+		<flow><block><line yMin="100" yMax="110"><word>Some</word><word>body</word><word>text</word></line>
+		<line yMin="112" yMax="122"><word>more</word><word>body</word><word>text</word></line></block></flow>
+		</page></doc></body></html>`))
+	if err != nil || flat.Title != "" {
+		t.Errorf("flat page = %+v, %v", flat, err)
+	}
+}
+
+func TestGuessTitleFromText(t *testing.T) {
+	text := "arXiv:2304.00067v1 [cs.LG] 1 Apr 2023\n\nPreprint. Under review.\n12\n" +
+		"Learning to Rank Papers by Relevance\nJane Doe\njane@example.org\n"
+	if got := GuessTitleFromText(text); got != "Learning to Rank Papers by Relevance" {
+		t.Errorf("title = %q", got)
+	}
+}
+
+func TestMatchTitle(t *testing.T) {
+	withMetadataServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/works":
+			w.Write([]byte(`{"message": {"items": [{"DOI": "10.1/other", "title": ["Attention Is Not Explanation"]}]}}`))
+		case "/arxiv":
+			w.Write([]byte(`<feed xmlns="http://www.w3.org/2005/Atom"><entry>
+				<id>http://arxiv.org/abs/1706.03762v7</id><title>Attention Is All
+				You Need</title></entry></feed>`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	source, id, err := MatchTitle("Attention is all you need.")
+	if err != nil || source != IDSourceArxiv || id != "1706.03762" {
+		t.Errorf("MatchTitle = %q, %q, %v", source, id, err)
+	}
+	if source, _, _ := MatchTitle("Completely Unrelated Work on Soil"); source != "" {
+		t.Errorf("matched an unrelated title from %s", source)
+	}
+}
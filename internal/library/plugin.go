@@ -0,0 +1,309 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// PluginPrefix starts the name of every plugin executable: a program
+// arc-library-foo on PATH runs as "arc-library foo".
+const PluginPrefix = "arc-library-"
+
+// Plugin is an external command found on PATH.
+type Plugin struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// DiscoverPlugins finds the plugin executables in the directories of
+// pathList (as in $PATH), sorted by name. When two directories hold the
+// same plugin the earlier one wins, as it would for the shell.
+func DiscoverPlugins(pathList string) []Plugin {
+	seen := map[string]bool{}
+	var plugins []Plugin
+	for _, dir := range filepath.SplitList(pathList) {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			name, ok := strings.CutPrefix(e.Name(), PluginPrefix)
+			if runtime.GOOS == "windows" {
+				name = strings.TrimSuffix(name, ".exe")
+			}
+			if !ok || name == "" || seen[name] || e.IsDir() {
+				continue
+			}
+			info, err := os.Stat(filepath.Join(dir, e.Name()))
+			if err != nil || info.IsDir() || (runtime.GOOS != "windows" && info.Mode().Perm()&0o111 == 0) {
+				continue
+			}
+			seen[name] = true
+			plugins = append(plugins, Plugin{Name: name, Path: filepath.Join(dir, e.Name())})
+		}
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins
+}
+
+// JSON-RPC 2.0 error codes.
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcServerError    = -32000 // the library refused the call
+)
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string { return e.Message }
+
+// pluginMethod handles one RPC method; params is the raw params value.
+type pluginMethod func(s LibraryStore, params json.RawMessage) (any, error)
+
+// pluginMethods are the calls a plugin can make, by name.
+var pluginMethods = map[string]pluginMethod{
+	"documents.list": func(s LibraryStore, params json.RawMessage) (any, error) {
+		var p struct {
+			Tag, Source, Type, Status, Author, Search string
+			Limit                                     int
+		}
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		docs, err := s.ListDocuments(&ListOptions{Tag: p.Tag, Source: p.Source, Type: p.Type, Status: p.Status, Author: p.Author, Search: p.Search, Limit: p.Limit})
+		if docs == nil {
+			docs = []*Document{}
+		}
+		return docs, err
+	},
+	"documents.get": func(s LibraryStore, params json.RawMessage) (any, error) {
+		var p struct{ ID string }
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		return s.GetDocument(p.ID)
+	},
+	"documents.add": func(s LibraryStore, params json.RawMessage) (any, error) {
+		var p struct{ Document *Document }
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		if p.Document == nil || p.Document.Title == "" {
+			return nil, &rpcError{rpcInvalidParams, "document with a title is required"}
+		}
+		// The store picks new documents' IDs; given one, some stores
+		// would quietly replace the document that has it
+		if p.Document.ID != "" {
+			return nil, &rpcError{rpcInvalidParams, "a new document can't have an id; use documents.update to change one"}
+		}
+		return p.Document, s.AddDocument(p.Document)
+	},
+	"documents.update": func(s LibraryStore, params json.RawMessage) (any, error) {
+		var p struct{ Document *Document }
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		if p.Document == nil || p.Document.ID == "" {
+			return nil, &rpcError{rpcInvalidParams, "document with an id is required"}
+		}
+		return p.Document, s.UpdateDocument(p.Document)
+	},
+	"tags.list": func(s LibraryStore, params json.RawMessage) (any, error) {
+		return s.ListTags()
+	},
+	"tags.add": func(s LibraryStore, params json.RawMessage) (any, error) {
+		var p struct{ DocumentID, Tag string }
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		return nil, s.AddTag(p.DocumentID, p.Tag)
+	},
+	"tags.remove": func(s LibraryStore, params json.RawMessage) (any, error) {
+		var p struct{ DocumentID, Tag string }
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		return nil, s.RemoveTag(p.DocumentID, p.Tag)
+	},
+	"collections.list": func(s LibraryStore, params json.RawMessage) (any, error) {
+		cols, err := s.ListCollections()
+		if cols == nil {
+			cols = []*Collection{}
+		}
+		return cols, err
+	},
+	"collections.get": func(s LibraryStore, params json.RawMessage) (any, error) {
+		var p struct{ Collection string }
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		return s.GetCollection(p.Collection)
+	},
+	"collections.add": func(s LibraryStore, params json.RawMessage) (any, error) {
+		var p struct{ Collection, DocumentID string }
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		c, err := s.GetCollection(p.Collection)
+		if err != nil {
+			return nil, err
+		}
+		if c == nil {
+			return nil, fmt.Errorf("%w: %s", ErrCollectionNotFound, p.Collection)
+		}
+		return nil, s.AddToCollection(c.ID, p.DocumentID)
+	},
+	"annotations.list": func(s LibraryStore, params json.RawMessage) (any, error) {
+		var p struct{ DocumentID string }
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		anns, err := s.GetAnnotations(p.DocumentID)
+		if anns == nil {
+			anns = []*Annotation{}
+		}
+		return anns, err
+	},
+	"annotations.add": func(s LibraryStore, params json.RawMessage) (any, error) {
+		var p struct{ Annotation *Annotation }
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		if p.Annotation == nil || p.Annotation.DocumentID == "" {
+			return nil, &rpcError{rpcInvalidParams, "annotation with a document_id is required"}
+		}
+		if p.Annotation.ID != "" {
+			return nil, &rpcError{rpcInvalidParams, "a new annotation can't have an id"}
+		}
+		return p.Annotation, s.AddAnnotation(p.Annotation)
+	},
+}
+
+func init() {
+	pluginMethods["rpc.methods"] = func(s LibraryStore, params json.RawMessage) (any, error) {
+		return PluginMethods(), nil
+	}
+}
+
+// PluginMethods lists the RPC methods plugins can call, sorted.
+func PluginMethods() []string {
+	names := make([]string, 0, len(pluginMethods))
+	for name := range pluginMethods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// decodeParams decodes by-name params. Keys match struct fields without
+// regard to case or underscores, so document_id fills DocumentID.
+func decodeParams(params json.RawMessage, v any) error {
+	if len(params) == 0 || string(params) == "null" {
+		return nil
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(params, &raw); err != nil {
+		return &rpcError{rpcInvalidParams, "params must be an object"}
+	}
+	norm := make(map[string]json.RawMessage, len(raw))
+	for k, val := range raw {
+		norm[strings.ReplaceAll(k, "_", "")] = val
+	}
+	data, _ := json.Marshal(norm)
+	if err := json.Unmarshal(data, v); err != nil {
+		return &rpcError{rpcInvalidParams, "invalid params: " + err.Error()}
+	}
+	return nil
+}
+
+// ServePluginRPC answers a plugin's JSON-RPC 2.0 calls: one request per
+// line read from r, one response per line written to w. It returns when r
+// ends. Requests without an id are notifications and get no response.
+func ServePluginRPC(s LibraryStore, r io.Reader, w io.Writer) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	enc := json.NewEncoder(w)
+	for sc.Scan() {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var req rpcRequest
+		if err := json.Unmarshal(line, &req); err != nil {
+			if err := enc.Encode(rpcResponse{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{rpcParseError, "parse error: " + err.Error()}}); err != nil {
+				return err
+			}
+			continue
+		}
+		resp := callPluginMethod(s, &req)
+		if len(req.ID) == 0 {
+			continue
+		}
+		if err := enc.Encode(resp); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
+
+func callPluginMethod(s LibraryStore, req *rpcRequest) rpcResponse {
+	resp := rpcResponse{JSONRPC: "2.0", ID: req.ID}
+	method, ok := pluginMethods[req.Method]
+	switch {
+	case req.JSONRPC != "2.0" || req.Method == "":
+		resp.Error = &rpcError{rpcInvalidRequest, "invalid request: jsonrpc must be \"2.0\" and method set"}
+	case !ok:
+		resp.Error = &rpcError{rpcMethodNotFound, "method not found: " + req.Method}
+	default:
+		result, err := method(s, req.Params)
+		var rerr *rpcError
+		switch {
+		case errors.As(err, &rerr):
+			resp.Error = rerr
+		case err != nil:
+			resp.Error = &rpcError{rpcServerError, err.Error()}
+		default:
+			if resp.Result, err = json.Marshal(result); err != nil {
+				resp.Result, resp.Error = nil, &rpcError{rpcServerError, err.Error()}
+			}
+		}
+	}
+	if len(resp.ID) == 0 {
+		resp.ID = json.RawMessage("null")
+	}
+	return resp
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+func TestDiscoverPlugins(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	for path, mode := range map[string]os.FileMode{
+		filepath.Join(first, "arc-library-zotero2"):  0o755,
+		filepath.Join(first, "arc-library-notes"):    0o644, // not executable
+		filepath.Join(second, "arc-library-zotero2"): 0o755, // shadowed by first
+		filepath.Join(second, "arc-library-anki"):    0o755,
+		filepath.Join(second, "arc-library-"):        0o755,
+		filepath.Join(second, "other-tool"):          0o755,
+	} {
+		if err := os.WriteFile(path, []byte("#!/bin/sh\n"), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := DiscoverPlugins(first + string(os.PathListSeparator) + string(os.PathListSeparator) + second)
+	want := []Plugin{
+		{Name: "anki", Path: filepath.Join(second, "arc-library-anki")},
+		{Name: "zotero2", Path: filepath.Join(first, "arc-library-zotero2")},
+	}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("plugins = %+v, want %+v", got, want)
+	}
+}
+
+func TestServePluginRPC(t *testing.T) {
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	requests := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"documents.add","params":{"document":{"title":"Other paper","type":"paper"}}}`,
+		`{"jsonrpc":"2.0","method":"documents.add","params":{"document":{"title":"Plugin paper","type":"paper","tags":["from-plugin"]}}}`,
+		`{"jsonrpc":"2.0","id":"two","method":"documents.list","params":{"tag":"from-plugin"}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"documents.get","params":{"id":"missing"}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"collections.add","params":{"collection":"nope","document_id":"d1"}}`,
+		`{"jsonrpc":"2.0","id":5,"method":"documents.add","params":[1]}`,
+		`{"jsonrpc":"2.0","id":6,"method":"library.drop"}`,
+		`{"jsonrpc":"2.0","id":7,"method":"documents.add","params":{"document":{"id":"d1","title":"Replacement"}}}`,
+		`not json`,
+	}, "\n")
+
+	var out bytes.Buffer
+	if err := ServePluginRPC(s, strings.NewReader(requests), &out); err != nil {
+		t.Fatal(err)
+	}
+	type response struct {
+		ID     json.RawMessage
+		Result json.RawMessage
+		Error  *struct{ Code int }
+	}
+	var responses []response
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var r response
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("bad response %q: %v", line, err)
+		}
+		responses = append(responses, r)
+	}
+	// The notification gets no response
+	if len(responses) != 8 {
+		t.Fatalf("got %d responses:\n%s", len(responses), out.String())
+	}
+
+	var docs []*Document
+	if err := json.Unmarshal(responses[1].Result, &docs); err != nil || string(responses[1].ID) != `"two"` {
+		t.Fatalf("documents.list = %s (%v)", responses[1].Result, err)
+	}
+	if len(docs) != 1 || docs[0].Title != "Plugin paper" {
+		t.Errorf("documents.list = %+v", docs)
+	}
+	if r := responses[2]; r.Error != nil || string(r.Result) != "null" {
+		t.Errorf("documents.get of a missing document = %s, %+v", r.Result, r.Error)
+	}
+	for i, code := range map[int]int{3: rpcServerError, 4: rpcInvalidParams, 5: rpcMethodNotFound, 6: rpcInvalidParams, 7: rpcParseError} {
+		if r := responses[i]; r.Error == nil || r.Error.Code != code {
+			t.Errorf("response %d = %+v, want error %d", i, r, code)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// QueueEntry is one document in the reading queue, with the score and
+// reasons behind its position.
+type QueueEntry struct {
+	Document *Document `json:"document"`
+	Score    float64   `json:"score"`
+	Pinned   bool      `json:"pinned"` // pushed explicitly rather than suggested
+	Reasons  []string  `json:"reasons"`
+}
+
+// QueueOptions tunes reading queue prioritization.
+type QueueOptions struct {
+	BoostTags []string // documents with any of these tags rank higher
+}
+
+// Reading queue score weights. A five-star paper (10) ranks level with an
+// overdue task (10); a boosted tag is worth a star and a half.
+const (
+	queueRatingWeight  = 2.0
+	queueOverdueTask   = 10.0
+	queueDueSoonTask   = 6.0
+	queueOpenTask      = 2.0
+	queueTagWeight     = 3.0
+	queueAgePerMonth   = 0.5
+	queueMaxAgeMonths  = 6
+	queueDueSoonWindow = 7 * 24 * time.Hour
+)
+
+// BuildReadingQueue orders documents into a reading queue. Pinned document
+// IDs come first in their given order, skipping any that are missing or
+// already completed or archived. The remaining unread documents follow by
+// descending score, which rewards high ratings, open tasks linked to the
+// document (most for overdue ones), boosted tags, and time spent waiting;
+// ties go to the oldest document.
+func BuildReadingQueue(pinned []string, docs []*Document, openTasks []*Task, opts QueueOptions, now time.Time) []QueueEntry {
+	byID := make(map[string]*Document, len(docs))
+	for _, d := range docs {
+		byID[d.ID] = d
+	}
+	tasksByDoc := make(map[string][]*Task)
+	for _, t := range openTasks {
+		if t.DocumentID != "" && t.Status != TaskDone {
+			tasksByDoc[t.DocumentID] = append(tasksByDoc[t.DocumentID], t)
+		}
+	}
+
+	queue := []QueueEntry{}
+	seen := make(map[string]bool)
+	for _, id := range pinned {
+		d := byID[id]
+		if d == nil || seen[id] || d.Status == StatusCompleted || d.Status == StatusArchived {
+			continue
+		}
+		seen[id] = true
+		e := scoreQueueEntry(d, tasksByDoc[id], opts, now)
+		e.Pinned = true
+		queue = append(queue, e)
+	}
+
+	var suggested []QueueEntry
+	for _, d := range docs {
+		if seen[d.ID] || (d.Status != "" && d.Status != StatusUnread) {
+			continue
+		}
+		suggested = append(suggested, scoreQueueEntry(d, tasksByDoc[d.ID], opts, now))
+	}
+	sort.SliceStable(suggested, func(i, j int) bool {
+		if suggested[i].Score != suggested[j].Score {
+			return suggested[i].Score > suggested[j].Score
+		}
+		return suggested[i].Document.CreatedAt.Before(suggested[j].Document.CreatedAt)
+	})
+
+	return append(queue, suggested...)
+}
+
+func scoreQueueEntry(d *Document, tasks []*Task, opts QueueOptions, now time.Time) QueueEntry {
+	e := QueueEntry{Document: d, Reasons: []string{}}
+
+	if d.Rating > 0 {
+		e.Score += queueRatingWeight * float64(d.Rating)
+		e.Reasons = append(e.Reasons, fmt.Sprintf("rated %d/5", d.Rating))
+	}
+
+	// Only the most pressing linked task counts
+	var taskScore float64
+	var taskReason string
+	for _, t := range tasks {
+		switch {
+		case t.DueAt != nil && Overdue(*t.DueAt, now):
+			if taskScore < queueOverdueTask {
+				taskScore, taskReason = queueOverdueTask, "task overdue: "+t.Description
+			}
+		case t.DueAt != nil && t.DueAt.Sub(now) <= queueDueSoonWindow:
+			if taskScore < queueDueSoonTask {
+				taskScore, taskReason = queueDueSoonTask, fmt.Sprintf("task due %s: %s", HomeDate(*t.DueAt), t.Description)
+			}
+		default:
+			if taskScore < queueOpenTask {
+				taskScore, taskReason = queueOpenTask, "open task: "+t.Description
+			}
+		}
+	}
+	if taskScore > 0 {
+		e.Score += taskScore
+		e.Reasons = append(e.Reasons, taskReason)
+	}
+
+	for _, boost := range opts.BoostTags {
+		for _, tag := range d.Tags {
+			if strings.EqualFold(tag, boost) {
+				e.Score += queueTagWeight
+				e.Reasons = append(e.Reasons, "tagged "+tag)
+				break
+			}
+		}
+	}
+
+	if months := int(now.Sub(d.CreatedAt).Hours() / 24 / 30); months > 0 {
+		e.Score += queueAgePerMonth * float64(min(months, queueMaxAgeMonths))
+		e.Reasons = append(e.Reasons, fmt.Sprintf("waiting %d month(s)", months))
+	}
+
+	return e
+}
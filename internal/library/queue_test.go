@@ -0,0 +1,73 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+func TestBuildReadingQueue(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	week := 7 * 24 * time.Hour
+	docs := []*Document{
+		{ID: "plain", Status: StatusUnread, CreatedAt: now.Add(-week)},
+		{ID: "rated", Status: StatusUnread, Rating: 4, CreatedAt: now.Add(-week)},
+		{ID: "overdue", Status: StatusUnread, CreatedAt: now.Add(-week)},
+		{ID: "tagged", Status: StatusUnread, Tags: []string{"ML"}, CreatedAt: now.Add(-week)},
+		{ID: "old", CreatedAt: now.AddDate(-1, 0, 0)},
+		{ID: "reading", Status: StatusReading, CreatedAt: now.Add(-week)},
+		{ID: "done", Status: StatusCompleted, CreatedAt: now.Add(-week)},
+	}
+	past := now.Add(-24 * time.Hour)
+	tasks := []*Task{
+		{DocumentID: "overdue", Description: "Summarise", Status: TaskTodo, DueAt: &past},
+		{DocumentID: "rated", Description: "Someday", Status: TaskTodo},
+	}
+
+	queue := BuildReadingQueue([]string{"reading", "done", "missing"}, docs, tasks, QueueOptions{BoostTags: []string{"ml"}}, now)
+
+	var ids []string
+	for _, e := range queue {
+		ids = append(ids, e.Document.ID)
+	}
+	// rated: 8 + 2 (open task) = 10 ties overdue (10); ties go to the older, and
+	// both were added together so input order stands
+	want := []string{"reading", "rated", "overdue", "old", "tagged", "plain"}
+	if len(ids) != len(want) {
+		t.Fatalf("queue = %v, want %v", ids, want)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Fatalf("queue = %v, want %v", ids, want)
+		}
+	}
+	if !queue[0].Pinned || queue[1].Pinned {
+		t.Errorf("only the pushed document should be pinned")
+	}
+	if queue[2].Score != queueOverdueTask || len(queue[2].Reasons) != 1 {
+		t.Errorf("overdue entry = %+v", queue[2])
+	}
+}
+
+func TestKVStoreReadingQueue(t *testing.T) {
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatalf("NewKVStore: %v", err)
+	}
+
+	ids, err := s.GetReadingQueue()
+	if err != nil || len(ids) != 0 {
+		t.Fatalf("empty queue = %v, %v", ids, err)
+	}
+	if err := s.SetReadingQueue([]string{"b", "a"}); err != nil {
+		t.Fatalf("SetReadingQueue: %v", err)
+	}
+	ids, err = s.GetReadingQueue()
+	if err != nil || len(ids) != 2 || ids[0] != "b" || ids[1] != "a" {
+		t.Errorf("queue = %v, %v; want [b a]", ids, err)
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+func init() { RegisterExporter(quizExporter{}) }
+
+// quizExporter writes the documents' flashcards as a printable Markdown
+// quiz: numbered questions grouped by document or collection, then an
+// answer key with the same numbers.
+type quizExporter struct{}
+
+func (quizExporter) Format() Format {
+	return Format{
+		Name:         "quiz",
+		Description:  "A printable Markdown quiz with an answer key, from flashcards",
+		Extensions:   []string{".md"},
+		Capabilities: []string{FormatFlashcards},
+	}
+}
+
+// quizQuestion is one numbered question of a quiz.
+type quizQuestion struct {
+	question, answer string
+}
+
+// quizGroup is a heading of a quiz with its questions.
+type quizGroup struct {
+	title     string
+	questions []quizQuestion
+}
+
+func (quizExporter) Export(w io.Writer, s LibraryStore, docs []*Document, opts ExportOptions) error {
+	groups, err := quizGroups(s, docs, opts)
+	if err != nil {
+		return err
+	}
+	total := 0
+	for _, g := range groups {
+		total += len(g.questions)
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# Quiz\n\n%s · %d question(s)\n", time.Now().Format("2006-01-02"), total)
+	n := 0
+	for _, g := range groups {
+		fmt.Fprintf(bw, "\n## %s\n\n", g.title)
+		for _, q := range g.questions {
+			n++
+			fmt.Fprintf(bw, "%d. %s\n", n, markdownListText(q.question))
+		}
+	}
+
+	fmt.Fprint(bw, "\n---\n\n# Answer Key\n")
+	n = 0
+	for _, g := range groups {
+		fmt.Fprintf(bw, "\n## %s\n\n", g.title)
+		for _, q := range g.questions {
+			n++
+			fmt.Fprintf(bw, "%d. %s\n", n, markdownListText(q.answer))
+		}
+	}
+	return bw.Flush()
+}
+
+// quizGroups collects each document's questions, from its flashcards and
+// opts.KeyPoints, and groups them. By collection, a document is asked
+// about under each of its collections among docs' and under "Other
+// documents" when it is in none.
+func quizGroups(s LibraryStore, docs []*Document, opts ExportOptions) ([]quizGroup, error) {
+	perDoc := map[string][]quizQuestion{}
+	for i, doc := range docs {
+		cards, err := s.ListFlashcards(&FlashcardListOptions{DocumentID: doc.ID})
+		if err != nil {
+			return nil, err
+		}
+		slices.SortStableFunc(cards, func(a, b *Flashcard) int { return a.CreatedAt.Compare(b.CreatedAt) })
+		var questions []quizQuestion
+		for _, c := range cards {
+			questions = append(questions, cardQuizQuestion(c.Type, c.Front, c.Back, c.Cloze))
+		}
+		if opts.KeyPoints != nil {
+			points, err := opts.KeyPoints(doc)
+			if err != nil {
+				return nil, fmt.Errorf("key points of %s: %w", doc.ID, err)
+			}
+			for _, p := range points {
+				questions = append(questions, cardQuizQuestion(p.Type, p.Front, p.Back, p.Cloze))
+			}
+		}
+		perDoc[doc.ID] = questions
+		if opts.OnProgress != nil {
+			opts.OnProgress(i+1, len(docs))
+		}
+	}
+
+	var groups []quizGroup
+	switch opts.GroupBy {
+	case "", "document":
+		for _, doc := range docs {
+			if qs := perDoc[doc.ID]; len(qs) > 0 {
+				groups = append(groups, quizGroup{title: doc.Title, questions: qs})
+			}
+		}
+	case "collection":
+		colls, err := s.ListCollections()
+		if err != nil {
+			return nil, err
+		}
+		slices.SortFunc(colls, func(a, b *Collection) int { return strings.Compare(a.Name, b.Name) })
+		grouped := map[string]bool{}
+		for _, c := range colls {
+			g := quizGroup{title: c.Name}
+			for _, doc := range docs {
+				if slices.Contains(c.DocumentIDs, doc.ID) {
+					g.questions = append(g.questions, perDoc[doc.ID]...)
+					grouped[doc.ID] = true
+				}
+			}
+			if len(g.questions) > 0 {
+				groups = append(groups, g)
+			}
+		}
+		other := quizGroup{title: "Other documents"}
+		for _, doc := range docs {
+			if !grouped[doc.ID] {
+				other.questions = append(other.questions, perDoc[doc.ID]...)
+			}
+		}
+		if len(other.questions) > 0 {
+			groups = append(groups, other)
+		}
+	default:
+		return nil, fmt.Errorf("unknown quiz grouping %q (use document or collection)", opts.GroupBy)
+	}
+	return groups, nil
+}
+
+// cardQuizQuestion turns a card into a question: a cloze card's deletions
+// become blanks, answered by the deletions in order.
+func cardQuizQuestion(typ, front, back, cloze string) quizQuestion {
+	if typ != "cloze" || cloze == "" {
+		return quizQuestion{question: front, answer: back}
+	}
+	var answers []string
+	for _, m := range clozeAnswerPattern.FindAllStringSubmatch(cloze, -1) {
+		answers = append(answers, m[1])
+	}
+	answer := strings.Join(answers, "; ")
+	if back != "" {
+		answer += " (" + back + ")"
+	}
+	return quizQuestion{question: clozeAnswerPattern.ReplaceAllString(cloze, "_____"), answer: answer}
+}
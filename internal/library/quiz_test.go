@@ -0,0 +1,85 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+func TestQuizExport(t *testing.T) {
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &Document{ID: "a", Title: "Attention"}
+	b := &Document{ID: "b", Title: "Geography"}
+	for _, d := range []*Document{a, b} {
+		if err := s.AddDocument(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cards := []*Flashcard{
+		{DocumentID: "a", Type: "basic", Front: "What replaces recurrence?", Back: "Self-attention"},
+		{DocumentID: "b", Type: "cloze", Front: "x", Cloze: "{{c1::Paris}} is the capital of {{c2::France}}"},
+	}
+	for _, c := range cards {
+		if err := s.AddFlashcard(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	coll, err := s.CreateCollection("Exam", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddToCollection(coll.ID, "b"); err != nil {
+		t.Fatal(err)
+	}
+
+	export := func(opts ExportOptions) string {
+		t.Helper()
+		var buf bytes.Buffer
+		if err := LookupExporter("quiz").Export(&buf, s, []*Document{a, b}, opts); err != nil {
+			t.Fatal(err)
+		}
+		return buf.String()
+	}
+
+	out := export(ExportOptions{KeyPoints: func(d *Document) ([]GeneratedCard, error) {
+		if d.ID != "a" {
+			return nil, nil
+		}
+		return []GeneratedCard{{Type: "basic", Front: "Key point?", Back: "Yes"}}, nil
+	}})
+	questions, key, ok := strings.Cut(out, "# Answer Key")
+	if !ok {
+		t.Fatalf("no answer key:\n%s", out)
+	}
+	for _, want := range []string{"3 question(s)", "## Attention\n\n1. What replaces recurrence?\n2. Key point?\n", "## Geography\n\n3. _____ is the capital of _____\n"} {
+		if !strings.Contains(questions, want) {
+			t.Errorf("questions lack %q:\n%s", want, questions)
+		}
+	}
+	if strings.Contains(questions, "Self-attention") {
+		t.Errorf("answer in the questions:\n%s", questions)
+	}
+	for _, want := range []string{"1. Self-attention\n2. Yes\n", "3. Paris; France\n"} {
+		if !strings.Contains(key, want) {
+			t.Errorf("answer key lacks %q:\n%s", want, key)
+		}
+	}
+
+	out = export(ExportOptions{GroupBy: "collection"})
+	if !strings.Contains(out, "## Exam\n\n1. _____ is the capital") || !strings.Contains(out, "## Other documents\n\n2. What replaces") {
+		t.Errorf("by collection:\n%s", out)
+	}
+
+	var buf bytes.Buffer
+	if err := LookupExporter("quiz").Export(&buf, s, []*Document{a}, ExportOptions{GroupBy: "tag"}); err == nil {
+		t.Error("unknown grouping: want error")
+	}
+}
@@ -0,0 +1,315 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RaindropAPI is the Raindrop.io REST API base URL.
+const RaindropAPI = "https://api.raindrop.io/rest/v1"
+
+// Raindrop's system collections.
+const (
+	RaindropAll      = 0  // every bookmark outside the trash
+	RaindropUnsorted = -1 // bookmarks in no collection
+)
+
+const (
+	raindropPageSize  = 50  // the most bookmarks listed per request
+	raindropBatchSize = 100 // the most bookmarks created per request
+	raindropRetries   = 3
+)
+
+// RaindropClient talks to a Raindrop.io account through the REST API.
+type RaindropClient struct {
+	BaseURL string // defaults to RaindropAPI
+	Token   string // a test token from https://app.raindrop.io/settings/integrations
+	HTTP    *http.Client
+}
+
+// NewRaindropClient returns a client authenticated with token.
+func NewRaindropClient(token string) *RaindropClient {
+	return &RaindropClient{
+		BaseURL: RaindropAPI,
+		Token:   token,
+		HTTP:    &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// RaindropCollection is a Raindrop collection.
+type RaindropCollection struct {
+	ID    int    `json:"_id"`
+	Title string `json:"title"`
+}
+
+// raindropBookmark is a bookmark ("raindrop") as the API returns it.
+type raindropBookmark struct {
+	ID         int                 `json:"_id"`
+	Link       string              `json:"link"`
+	Title      string              `json:"title"`
+	Excerpt    string              `json:"excerpt"`
+	Note       string              `json:"note"`
+	Type       string              `json:"type"` // link, article, image, video, document, or audio
+	Tags       []string            `json:"tags"`
+	Domain     string              `json:"domain"`
+	Created    time.Time           `json:"created"`
+	Highlights []raindropHighlight `json:"highlights"`
+}
+
+type raindropHighlight struct {
+	Text    string    `json:"text"`
+	Note    string    `json:"note"`
+	Color   string    `json:"color"`
+	Created time.Time `json:"created"`
+}
+
+// raindropNewBookmark is a bookmark to create.
+type raindropNewBookmark struct {
+	Link       string         `json:"link"`
+	Title      string         `json:"title,omitempty"`
+	Excerpt    string         `json:"excerpt,omitempty"`
+	Tags       []string       `json:"tags,omitempty"`
+	Collection map[string]int `json:"collection"`
+}
+
+// do sends a request with a JSON body, if any, and decodes the response
+// into out.
+func (c *RaindropClient) do(method, path string, body, out any) error {
+	base := c.BaseURL
+	if base == "" {
+		base = RaindropAPI
+	}
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	resp, data, err := doWithRetries(c.HTTP, func() (*http.Request, error) {
+		req, err := http.NewRequest(method, strings.TrimRight(base, "/")+path, bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		return req, nil
+	}, raindropRetries)
+	if err != nil {
+		return fmt.Errorf("raindrop: %w", err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		return fmt.Errorf("raindrop: %s (check the access token)", resp.Status)
+	case resp.StatusCode != http.StatusOK:
+		var apiErr struct {
+			ErrorMessage string `json:"errorMessage"`
+		}
+		json.Unmarshal(data, &apiErr)
+		if apiErr.ErrorMessage != "" {
+			return fmt.Errorf("raindrop: %s: %s", resp.Status, apiErr.ErrorMessage)
+		}
+		return fmt.Errorf("raindrop: %s", resp.Status)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("raindrop: decode response: %w", err)
+	}
+	return nil
+}
+
+// Collections lists the account's collections, nested ones included.
+func (c *RaindropClient) Collections() ([]RaindropCollection, error) {
+	var all []RaindropCollection
+	for _, path := range []string{"/collections", "/collections/childrens"} {
+		var resp struct {
+			Items []RaindropCollection `json:"items"`
+		}
+		if err := c.do("GET", path, nil, &resp); err != nil {
+			return nil, err
+		}
+		all = append(all, resp.Items...)
+	}
+	return all, nil
+}
+
+// FindCollection resolves a collection given by name (case-insensitively)
+// or ID. "" and "all" are every bookmark, "unsorted" those in no
+// collection.
+func (c *RaindropClient) FindCollection(name string) (RaindropCollection, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "all":
+		return RaindropCollection{ID: RaindropAll, Title: "All bookmarks"}, nil
+	case "unsorted":
+		return RaindropCollection{ID: RaindropUnsorted, Title: "Unsorted"}, nil
+	}
+	collections, err := c.Collections()
+	if err != nil {
+		return RaindropCollection{}, err
+	}
+	id, idErr := strconv.Atoi(name)
+	var titles []string
+	for _, coll := range collections {
+		if strings.EqualFold(coll.Title, strings.TrimSpace(name)) || (idErr == nil && coll.ID == id) {
+			return coll, nil
+		}
+		titles = append(titles, coll.Title)
+	}
+	return RaindropCollection{}, fmt.Errorf("no Raindrop collection %q (have: %s)", name, strings.Join(titles, ", "))
+}
+
+// Bookmarks lists the bookmarks in a collection, with their highlights.
+func (c *RaindropClient) Bookmarks(collectionID int) ([]*ReadLaterItem, error) {
+	var items []*ReadLaterItem
+	for page := 0; ; page++ {
+		var resp struct {
+			Items []raindropBookmark `json:"items"`
+		}
+		path := fmt.Sprintf("/raindrops/%d?page=%d&perpage=%d", collectionID, page, raindropPageSize)
+		if err := c.do("GET", path, nil, &resp); err != nil {
+			return nil, err
+		}
+		for _, b := range resp.Items {
+			items = append(items, raindropItem(b))
+		}
+		if len(resp.Items) < raindropPageSize {
+			return items, nil
+		}
+	}
+}
+
+func raindropItem(b raindropBookmark) *ReadLaterItem {
+	item := &ReadLaterItem{
+		ID:       strconv.Itoa(b.ID),
+		URL:      b.Link,
+		Title:    b.Title,
+		Summary:  b.Excerpt,
+		Note:     b.Note,
+		SiteName: b.Domain,
+		Tags:     b.Tags,
+		SavedAt:  b.Created,
+	}
+	switch b.Type {
+	case "video":
+		item.Type = DocTypeVideo
+	case "book":
+		item.Type = DocTypeBook
+	}
+	for _, h := range b.Highlights {
+		item.Highlights = append(item.Highlights, ReadLaterHighlight{Text: h.Text, Note: h.Note, Color: h.Color, CreatedAt: h.Created})
+	}
+	return item
+}
+
+// CreateBookmarks saves docs' URLs as bookmarks in a collection, with
+// their titles, abstracts as excerpts, and tags.
+func (c *RaindropClient) CreateBookmarks(collectionID int, docs []*Document) error {
+	for start := 0; start < len(docs); start += raindropBatchSize {
+		batch := docs[start:min(start+raindropBatchSize, len(docs))]
+		var body struct {
+			Items []raindropNewBookmark `json:"items"`
+		}
+		for _, d := range batch {
+			link, _ := d.Meta["url"].(string)
+			body.Items = append(body.Items, raindropNewBookmark{
+				Link:       link,
+				Title:      d.Title,
+				Excerpt:    d.Abstract,
+				Tags:       d.Tags,
+				Collection: map[string]int{"$id": collectionID},
+			})
+		}
+		var resp struct {
+			Result bool `json:"result"`
+		}
+		if err := c.do("POST", "/raindrops", body, &resp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RaindropSync pulls the bookmarks of a Raindrop collection into the
+// library and, with Push, saves the library's articles missing from
+// Raindrop back to it.
+type RaindropSync struct {
+	Store      LibraryStore
+	Client     *RaindropClient
+	Collection string // name or ID; "" is every bookmark
+	Tags       []string
+	Push       bool
+	DryRun     bool
+}
+
+// RaindropSyncResult is what a Raindrop sync did.
+type RaindropSyncResult struct {
+	Collection string           `json:"collection"`
+	Pulled     *ReadLaterImport `json:"pulled"`
+	Pushed     []*Document      `json:"pushed"`
+}
+
+// Run syncs. Bookmarks come in as documents like read-later items, matched
+// to the library by URL. Pushed articles are those with a URL that is not
+// bookmarked anywhere in the account, excluding documents that came from
+// Raindrop, whose bookmarks were deleted there. They go to the collection,
+// or to Unsorted when syncing every bookmark.
+func (rs *RaindropSync) Run() (*RaindropSyncResult, error) {
+	coll, err := rs.Client.FindCollection(rs.Collection)
+	if err != nil {
+		return nil, err
+	}
+	items, err := rs.Client.Bookmarks(coll.ID)
+	if err != nil {
+		return nil, err
+	}
+	result := &RaindropSyncResult{Collection: coll.Title, Pushed: []*Document{}}
+	if result.Pulled, err = ImportReadLater(rs.Store, "raindrop", items, rs.Tags, rs.DryRun); err != nil {
+		return nil, err
+	}
+	if !rs.Push {
+		return result, nil
+	}
+
+	bookmarked := items
+	if coll.ID != RaindropAll {
+		if bookmarked, err = rs.Client.Bookmarks(RaindropAll); err != nil {
+			return nil, err
+		}
+	}
+	known := map[string]bool{}
+	for _, item := range bookmarked {
+		known[readLaterURLKey(item.URL)] = true
+	}
+	docs, err := rs.Store.ListDocuments(&ListOptions{Type: string(DocTypeArticle)})
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range docs {
+		u, _ := d.Meta["url"].(string)
+		if u == "" || d.Source == "raindrop" || known[readLaterURLKey(u)] {
+			continue
+		}
+		known[readLaterURLKey(u)] = true
+		result.Pushed = append(result.Pushed, d)
+	}
+
+	target := coll.ID
+	if target == RaindropAll {
+		target = RaindropUnsorted
+	}
+	if !rs.DryRun && len(result.Pushed) > 0 {
+		if err := rs.Client.CreateBookmarks(target, result.Pushed); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+func TestRaindropSync(t *testing.T) {
+	bookmarks := map[int][]map[string]any{
+		42: {{"_id": 1, "link": "https://example.com/a", "title": "A", "excerpt": "About A", "tags": []string{"ml"},
+			"type": "article", "created": "2025-01-02T10:00:00Z",
+			"highlights": []map[string]any{{"text": "Key point", "note": "!", "created": "2025-01-03T10:00:00Z"}}}},
+		7: {{"_id": 2, "link": "https://example.com/elsewhere", "title": "Elsewhere", "type": "link"}},
+	}
+	var created []raindropNewBookmark
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.URL.Path == "/collections":
+			fmt.Fprint(w, `{"result": true, "items": [{"_id": 42, "title": "Reading"}, {"_id": 7, "title": "Other"}]}`)
+		case r.URL.Path == "/collections/childrens":
+			fmt.Fprint(w, `{"result": true, "items": []}`)
+		case r.Method == "POST" && r.URL.Path == "/raindrops":
+			var body struct {
+				Items []raindropNewBookmark `json:"items"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			created = append(created, body.Items...)
+			fmt.Fprint(w, `{"result": true, "items": []}`)
+		default:
+			var id int
+			fmt.Sscanf(r.URL.Path, "/raindrops/%d", &id)
+			items := bookmarks[id]
+			if id == RaindropAll {
+				items = append(slices.Clone(bookmarks[42]), bookmarks[7]...)
+			}
+			json.NewEncoder(w).Encode(map[string]any{"result": true, "items": items})
+		}
+	}))
+	defer srv.Close()
+
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range []*Document{
+		{Type: DocTypeArticle, Title: "Mine", Tags: []string{"web"}, Meta: JSONMap{"url": "https://example.com/mine"}},
+		{Type: DocTypeArticle, Title: "Bookmarked elsewhere", Meta: JSONMap{"url": "https://example.com/elsewhere/"}},
+		{Type: DocTypePaper, Title: "Paper", Meta: JSONMap{"url": "https://example.com/paper"}},
+	} {
+		if err := s.AddDocument(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	client := NewRaindropClient("secret")
+	client.BaseURL = srv.URL
+	if _, err := client.FindCollection("nope"); err == nil {
+		t.Error("expected an error for an unknown collection")
+	}
+
+	sync := &RaindropSync{Store: s, Client: client, Collection: "reading", Push: true}
+	result, err := sync.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Collection != "Reading" || len(result.Pulled.Imported) != 1 || result.Pulled.Highlights != 2 {
+		t.Fatalf("result = %+v, pulled %+v", result, result.Pulled)
+	}
+	a := result.Pulled.Imported[0]
+	if a.Source != "raindrop" || a.SourceID != "1" || a.Abstract != "About A" || !slices.Equal(a.Tags, []string{"ml"}) {
+		t.Errorf("pulled = %+v", a)
+	}
+	// Only the article not bookmarked anywhere is pushed, to the collection
+	if len(result.Pushed) != 1 || result.Pushed[0].Title != "Mine" ||
+		len(created) != 1 || created[0].Link != "https://example.com/mine" || created[0].Collection["$id"] != 42 {
+		t.Errorf("pushed %v, created %+v", result.Pushed, created)
+	}
+
+	// Pulled documents are not pushed back
+	bookmarks[42] = nil
+	created = nil
+	result, err = sync.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Pushed) != 1 || result.Pushed[0].Title != "Mine" {
+		t.Errorf("second sync pushed %v", result.Pushed)
+	}
+}
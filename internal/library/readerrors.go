@@ -0,0 +1,105 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// ReadError is a stored record that could not be read: a row that did
+// not scan, or JSON that did not unmarshal. Listings leave such records
+// out, or, for a field of one, leave the field empty, and carry on; see
+// SetStrictReads.
+type ReadError struct {
+	Entity string `json:"entity"`       // document, annotation, index, ...
+	ID     string `json:"id,omitempty"` // empty when the record is too damaged to tell
+	Err    error  `json:"-"`
+}
+
+func (e *ReadError) Error() string {
+	if e.ID == "" {
+		return fmt.Sprintf("read %s: %v", e.Entity, e.Err)
+	}
+	return fmt.Sprintf("read %s %s: %v", e.Entity, e.ID, e.Err)
+}
+
+func (e *ReadError) Unwrap() error { return e.Err }
+
+// maxReadErrors caps the records readErrors keeps; past it they are only
+// counted, so a long-running command that is slow to take them doesn't
+// grow without bound.
+const maxReadErrors = 1000
+
+// readErrors collects the records the stores could not read, until
+// TakeReadErrors, each once however often it is read.
+var readErrors struct {
+	sync.Mutex
+	strict bool
+	list   []*ReadError
+	seen   map[string]bool // entity and ID, or error when there is no ID
+	more   int             // not kept, past maxReadErrors
+}
+
+// SetStrictReads makes the stores fail on the first record they cannot
+// read instead of skipping it.
+func SetStrictReads(strict bool) {
+	readErrors.Lock()
+	defer readErrors.Unlock()
+	readErrors.strict = strict
+}
+
+// TakeReadErrors returns the records that could not be read since the
+// last call, and how many more there were past those it kept, and forgets
+// them.
+func TakeReadErrors() (errs []*ReadError, more int) {
+	readErrors.Lock()
+	defer readErrors.Unlock()
+	errs, more = readErrors.list, readErrors.more
+	readErrors.list, readErrors.seen, readErrors.more = nil, nil, 0
+	return errs, more
+}
+
+// unreadable notes that a record could not be read. It returns the error
+// to stop with under SetStrictReads, and otherwise nil, for the caller to
+// skip the record, or the field, and go on.
+func unreadable(entity, id string, err error) error {
+	e := &ReadError{Entity: entity, ID: id, Err: err}
+	readErrors.Lock()
+	defer readErrors.Unlock()
+	if readErrors.strict {
+		return e
+	}
+	key := entity + "\x00" + id
+	if id == "" {
+		key += "\x00" + err.Error()
+	}
+	switch {
+	case readErrors.seen[key]:
+		return nil
+	case len(readErrors.list) == maxReadErrors:
+		// Not remembered either, so past the cap a record read again is
+		// counted again
+		readErrors.more++
+		return nil
+	case readErrors.seen == nil:
+		readErrors.seen = map[string]bool{}
+	}
+	readErrors.seen[key] = true
+	readErrors.list = append(readErrors.list, e)
+	return nil
+}
+
+// unmarshalField unmarshals data, a JSON field of the record id, into v,
+// noting data it cannot unmarshal. Empty data leaves v alone.
+func unmarshalField(entity, id, field string, data []byte, v any) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return unreadable(entity, id, fmt.Errorf("%s: %w", field, err))
+	}
+	return nil
+}
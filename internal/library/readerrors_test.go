@@ -0,0 +1,71 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestReadErrors(t *testing.T) {
+	t.Cleanup(func() { SetStrictReads(false); TakeReadErrors() })
+
+	sqlStore := benchBackends[0].open(t).(*Store)
+	docs := FixtureDocuments(3, 1)
+	if err := sqlStore.AddDocuments(docs); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sqlStore.db.Exec(`UPDATE documents SET tags = 'not json' WHERE id = ?`, docs[1].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	kvStore := benchBackends[1].open(t).(*KVStore)
+	ann := &Annotation{DocumentID: "doc", Type: "note", Content: "Fine"}
+	bad := &Annotation{DocumentID: "doc", Type: "note", Content: "Lost"}
+	if err := kvStore.AddAnnotations([]*Annotation{ann, bad}); err != nil {
+		t.Fatal(err)
+	}
+	if err := kvStore.kv.Set(context.Background(), kvStore.generateKey("annotation", bad.ID), []byte("{")); err != nil {
+		t.Fatal(err)
+	}
+
+	TakeReadErrors()
+	if all, err := sqlStore.ListDocuments(nil); err != nil || len(all) != 3 {
+		t.Errorf("listed %d documents, %v", len(all), err)
+	}
+	if anns, err := kvStore.GetAnnotations("doc"); err != nil || len(anns) != 1 {
+		t.Errorf("listed %d annotations, %v", len(anns), err)
+	}
+	// Reading them again notes them once
+	sqlStore.ListDocuments(nil)
+	errs, more := TakeReadErrors()
+	if len(errs) != 2 || more != 0 || errs[0].Entity != "document" || errs[0].ID != docs[1].ID || errs[1].Entity != "annotation" || errs[1].ID != bad.ID {
+		t.Fatalf("read errors %v", errs)
+	}
+	if errs, _ := TakeReadErrors(); errs != nil {
+		t.Error("read errors kept after being taken")
+	}
+
+	// Past the cap, they are only counted
+	for i := range maxReadErrors + 5 {
+		unreadable("document", fmt.Sprint(i), errors.New("bad"))
+	}
+	if errs, more := TakeReadErrors(); len(errs) != maxReadErrors || more != 5 {
+		t.Errorf("took %d read errors and %d more", len(errs), more)
+	}
+
+	SetStrictReads(true)
+	var re *ReadError
+	if _, err := sqlStore.ListDocuments(nil); !errors.As(err, &re) || re.ID != docs[1].ID {
+		t.Errorf("strict listing of documents: %v", err)
+	}
+	if _, err := kvStore.GetAnnotations("doc"); !errors.As(err, &re) || re.ID != bad.ID {
+		t.Errorf("strict listing of annotations: %v", err)
+	}
+	if errs, _ := TakeReadErrors(); errs != nil {
+		t.Errorf("strict reads noted %v", errs)
+	}
+}
@@ -0,0 +1,217 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ReadLaterItem is an article saved in a read-later service such as
+// Readwise Reader or Omnivore, with the highlights made on it.
+type ReadLaterItem struct {
+	ID         string
+	URL        string
+	Title      string
+	Author     string
+	Summary    string
+	Note       string // the user's note on the whole item
+	SiteName   string
+	Type       DocumentType
+	Tags       []string
+	SavedAt    time.Time
+	Progress   float64 // 0 to 1
+	Archived   bool
+	Highlights []ReadLaterHighlight
+}
+
+// ReadLaterHighlight is a highlighted passage and the note made on it.
+type ReadLaterHighlight struct {
+	Text      string
+	Note      string
+	Color     string
+	CreatedAt time.Time
+}
+
+// ReadLaterImport is what importing read-later items did.
+type ReadLaterImport struct {
+	Imported   []*Document `json:"imported"`
+	Updated    []*Document `json:"updated"`    // already in the library; tags or highlights added
+	Skipped    int         `json:"skipped"`    // already in the library, nothing new
+	Highlights int         `json:"highlights"` // annotations added, notes included
+}
+
+// readLaterURLKey normalizes an article URL for deduplication: the
+// fragment, utm_ tracking parameters, and a trailing slash are dropped.
+func readLaterURLKey(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Host == "" {
+		return strings.TrimSpace(rawURL)
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(strings.TrimPrefix(u.Host, "www."))
+	u.Fragment = ""
+	q := u.Query()
+	for k := range q {
+		if strings.HasPrefix(k, "utm_") {
+			q.Del(k)
+		}
+	}
+	u.RawQuery = q.Encode()
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	return u.String()
+}
+
+// ImportReadLater adds items from source ("readwise", "omnivore", ...) as
+// article documents, with their highlights as annotations. An item whose
+// URL is already in the library is not added again: its tags and any
+// highlights not yet on the document are added to it instead, so an
+// import can be repeated to pick up new highlights. With dryRun nothing
+// is written.
+func ImportReadLater(s LibraryStore, source string, items []*ReadLaterItem, tags []string, dryRun bool) (*ReadLaterImport, error) {
+	docs, err := s.ListDocuments(nil)
+	if err != nil {
+		return nil, err
+	}
+	byURL := make(map[string]*Document, len(docs))
+	for _, d := range docs {
+		if u, _ := d.Meta["url"].(string); u != "" {
+			byURL[readLaterURLKey(u)] = d
+		}
+	}
+
+	result := &ReadLaterImport{Imported: []*Document{}, Updated: []*Document{}}
+	for _, item := range items {
+		if item.URL == "" {
+			continue
+		}
+		key := readLaterURLKey(item.URL)
+		itemTags := slices.Clone(item.Tags)
+		for _, t := range tags {
+			if !slices.Contains(itemTags, t) {
+				itemTags = append(itemTags, t)
+			}
+		}
+
+		doc := byURL[key]
+		if doc == nil {
+			doc = readLaterDocument(source, item, itemTags)
+			DetectDocumentLanguage(doc, false)
+			if !dryRun {
+				if err := s.AddDocument(doc); err != nil {
+					return nil, fmt.Errorf("add %q: %w", doc.Title, err)
+				}
+			}
+			byURL[key] = doc
+			result.Imported = append(result.Imported, doc)
+			n, err := addReadLaterHighlights(s, doc, item.Highlights, nil, dryRun)
+			if err != nil {
+				return nil, err
+			}
+			result.Highlights += n
+			continue
+		}
+
+		changed := false
+		for _, t := range itemTags {
+			if !slices.Contains(doc.Tags, t) {
+				doc.Tags = append(doc.Tags, t)
+				changed = true
+			}
+		}
+		if changed && !dryRun {
+			doc.UpdatedAt = time.Now()
+			if err := s.UpdateDocument(doc); err != nil {
+				return nil, fmt.Errorf("update %q: %w", doc.Title, err)
+			}
+		}
+		var existing []*Annotation
+		if !slices.Contains(result.Imported, doc) {
+			if existing, err = s.GetAnnotations(doc.ID); err != nil {
+				return nil, fmt.Errorf("annotations of %q: %w", doc.Title, err)
+			}
+		}
+		n, err := addReadLaterHighlights(s, doc, item.Highlights, existing, dryRun)
+		if err != nil {
+			return nil, err
+		}
+		result.Highlights += n
+		switch {
+		case slices.Contains(result.Imported, doc) || slices.Contains(result.Updated, doc):
+		case changed || n > 0:
+			result.Updated = append(result.Updated, doc)
+		default:
+			result.Skipped++
+		}
+	}
+	return result, nil
+}
+
+// readLaterDocument is the document for a newly imported item.
+func readLaterDocument(source string, item *ReadLaterItem, tags []string) *Document {
+	doc := &Document{
+		Type:      item.Type,
+		Source:    source,
+		SourceID:  item.ID,
+		Title:     strings.TrimSpace(item.Title),
+		Abstract:  strings.TrimSpace(item.Summary),
+		Notes:     strings.TrimSpace(item.Note),
+		Tags:      tags,
+		Status:    StatusUnread,
+		CreatedAt: item.SavedAt,
+		Meta:      JSONMap{"url": item.URL},
+	}
+	if doc.Type == "" {
+		doc.Type = DocTypeArticle
+	}
+	if doc.Title == "" {
+		doc.Title = item.URL
+	}
+	if a := strings.TrimSpace(item.Author); a != "" {
+		doc.Authors = []string{a}
+	}
+	if item.SiteName != "" {
+		doc.Meta["site_name"] = item.SiteName
+	}
+	switch {
+	case item.Progress >= 0.99 || item.Archived:
+		doc.Status = StatusCompleted
+	case item.Progress > 0:
+		doc.Status = StatusReading
+	}
+	return doc
+}
+
+// addReadLaterHighlights stores highlights as "highlight" annotations and
+// their notes as "note" annotations, skipping those whose text is among
+// existing. It returns how many annotations were added.
+func addReadLaterHighlights(s LibraryStore, doc *Document, highlights []ReadLaterHighlight, existing []*Annotation, dryRun bool) (int, error) {
+	have := map[string]bool{}
+	for _, a := range existing {
+		have[a.Type+"\x00"+strings.TrimSpace(a.Content)] = true
+	}
+	added := 0
+	for _, h := range highlights {
+		for _, ann := range []*Annotation{
+			{DocumentID: doc.ID, Type: "highlight", Content: strings.TrimSpace(h.Text), Color: h.Color, CreatedAt: h.CreatedAt},
+			{DocumentID: doc.ID, Type: "note", Content: strings.TrimSpace(h.Note), CreatedAt: h.CreatedAt},
+		} {
+			key := ann.Type + "\x00" + ann.Content
+			if ann.Content == "" || have[key] {
+				continue
+			}
+			have[key] = true
+			if !dryRun {
+				if err := s.AddAnnotation(ann); err != nil {
+					return added, fmt.Errorf("add annotation to %q: %w", doc.Title, err)
+				}
+			}
+			added++
+		}
+	}
+	return added, nil
+}
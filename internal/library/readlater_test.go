@@ -0,0 +1,150 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+func TestReadwiseFetchDocuments(t *testing.T) {
+	pages := map[string]string{
+		"": `{"count": 4, "nextPageCursor": "p2", "results": [
+			{"id": "d1", "url": "https://read.readwise.io/read/d1", "source_url": "https://example.com/post", "title": "A Post",
+			 "author": "Jane Doe", "category": "article", "location": "archive", "tags": {"ml": {"name": "ml"}},
+			 "site_name": "Example", "reading_progress": 1, "saved_at": "2025-01-02T10:00:00Z"},
+			{"id": "f1", "source_url": "https://example.com/feed-item", "title": "Unsaved", "category": "rss", "location": "feed"}]}`,
+		"p2": `{"count": 4, "nextPageCursor": null, "results": [
+			{"id": "h2", "category": "highlight", "parent_id": "d1", "content": "Second", "created_at": "2025-01-03T10:00:00Z"},
+			{"id": "h1", "category": "highlight", "parent_id": "d1", "content": "First", "notes": "Agree", "created_at": "2025-01-02T11:00:00Z"}]}`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Token secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, pages[r.URL.Query().Get("pageCursor")])
+	}))
+	defer srv.Close()
+
+	c := NewReadwiseClient("secret")
+	c.BaseURL = srv.URL
+	items, err := c.FetchDocuments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 {
+		t.Fatalf("items = %d, want the feed item left out", len(items))
+	}
+	it := items[0]
+	if it.URL != "https://example.com/post" || it.Type != "" || !it.Archived || !slices.Equal(it.Tags, []string{"ml"}) {
+		t.Errorf("item = %+v", it)
+	}
+	if len(it.Highlights) != 2 || it.Highlights[0].Text != "First" || it.Highlights[0].Note != "Agree" {
+		t.Errorf("highlights = %+v", it.Highlights)
+	}
+
+	c.Token = "wrong"
+	if _, err := c.FetchDocuments(); err == nil {
+		t.Error("expected an error for a bad token")
+	}
+}
+
+func TestParseOmnivoreExport(t *testing.T) {
+	fsys := fstest.MapFS{
+		"export/metadata_0_to_1.json": {Data: []byte(`[
+			{"id": "o1", "slug": "a-post", "title": "A Post", "url": "https://example.com/post/", "state": "Archived",
+			 "readingProgress": 40, "labels": ["ml", {"name": "to-cite"}], "savedAt": "2025-01-02T10:00:00Z"},
+			{"id": "o2", "slug": "other", "title": "Other", "url": "https://example.com/other"}]`)},
+		"export/highlights/a-post.md": {Data: []byte("> First line\n> continued [⤴️](https://omnivore.app/me/a-post#h1)\n\nMy note\n\n> Second [⤴️](https://omnivore.app/me/a-post#h2)\n")},
+	}
+	items, err := ParseOmnivoreExport(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("items = %d", len(items))
+	}
+	it := items[0]
+	if !it.Archived || it.Progress != 0.4 || !slices.Equal(it.Tags, []string{"ml", "to-cite"}) {
+		t.Errorf("item = %+v", it)
+	}
+	want := []ReadLaterHighlight{{Text: "First line\ncontinued", Note: "My note"}, {Text: "Second"}}
+	if !slices.Equal(it.Highlights, want) {
+		t.Errorf("highlights = %+v", it.Highlights)
+	}
+	if len(items[1].Highlights) != 0 {
+		t.Errorf("other highlights = %+v", items[1].Highlights)
+	}
+
+	if _, err := ParseOmnivoreExport(fstest.MapFS{"x.json": {Data: []byte("[]")}}); err == nil {
+		t.Error("expected an error without metadata files")
+	}
+}
+
+func TestImportReadLater(t *testing.T) {
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	clipped := &Document{Type: DocTypeArticle, Title: "Clipped", Tags: []string{"web"}, Meta: JSONMap{"url": "https://www.example.com/post"}}
+	if err := s.AddDocument(clipped); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddAnnotation(&Annotation{DocumentID: clipped.ID, Type: "highlight", Content: "First"}); err != nil {
+		t.Fatal(err)
+	}
+
+	items := []*ReadLaterItem{
+		{ID: "o1", URL: "https://example.com/post/?utm_source=rss#top", Title: "A Post", Tags: []string{"ml"},
+			Highlights: []ReadLaterHighlight{{Text: "First"}, {Text: "Second", Note: "Why?"}}},
+		{ID: "o2", URL: "https://example.com/new", Title: "New", Author: "Jane Doe", Progress: 0.5,
+			Highlights: []ReadLaterHighlight{{Text: "Quote"}}},
+		{ID: "o3", Title: "No URL"},
+	}
+
+	dry, err := ImportReadLater(s, "omnivore", items, []string{"omnivore"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if docs, _ := s.ListDocuments(nil); len(docs) != 1 || len(dry.Imported) != 1 {
+		t.Fatalf("dry run imported %d and stored %d documents", len(dry.Imported), len(docs))
+	}
+
+	result, err := ImportReadLater(s, "omnivore", items, []string{"omnivore"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Imported) != 1 || len(result.Updated) != 1 || result.Skipped != 0 || result.Highlights != 3 {
+		t.Fatalf("result = %+v", result)
+	}
+	added := result.Imported[0]
+	if added.Type != DocTypeArticle || added.Source != "omnivore" || added.SourceID != "o2" || added.Status != StatusReading ||
+		!slices.Equal(added.Authors, []string{"Jane Doe"}) || added.Meta["url"] != "https://example.com/new" {
+		t.Errorf("imported = %+v", added)
+	}
+	doc, _ := s.GetDocument(clipped.ID)
+	if !slices.Equal(doc.Tags, []string{"web", "ml", "omnivore"}) {
+		t.Errorf("tags = %v", doc.Tags)
+	}
+	anns, _ := s.GetAnnotations(clipped.ID)
+	if len(anns) != 3 {
+		t.Errorf("annotations = %d, want First, Second, and its note", len(anns))
+	}
+
+	// Importing again adds nothing
+	again, err := ImportReadLater(s, "omnivore", items, []string{"omnivore"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again.Imported) != 0 || len(again.Updated) != 0 || again.Skipped != 2 || again.Highlights != 0 {
+		t.Errorf("second import = %+v", again)
+	}
+}
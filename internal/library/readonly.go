@@ -0,0 +1,523 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ReadOnly is the state a ReadOnlyStore and the stores WithActor derives
+// from it share: whether writes are refused, and why, and the library
+// lock writes take.
+type ReadOnly struct {
+	Enabled bool
+	Reason  string // e.g. "--read-only"; ends the refusal's message
+
+	// LockPath, when set, is the lock (see LockLibrary) the first write
+	// takes, so that only one process writes to the library at a time.
+	// While another process holds it, writes are refused and reads go on.
+	LockPath string
+	// PerWrite releases the lock after each write instead of holding it
+	// until the process exits, for long-running commands such as serve,
+	// which mostly read and must not keep others from writing.
+	PerWrite bool
+
+	mu   sync.Mutex
+	lock *LibraryLock
+}
+
+// ReadOnlyStore wraps a store so that, while its ReadOnly is enabled,
+// reads go through and writes fail with ErrReadOnly, so one process can
+// browse a library another is changing. Derived caches, such as section
+// structure and text signatures, are computed but not saved.
+type ReadOnlyStore struct {
+	LibraryStore
+	*ReadOnly
+}
+
+// NewReadOnlyStore returns s with writes refused while the returned
+// store's ReadOnly is enabled; it starts disabled.
+func NewReadOnlyStore(s LibraryStore) *ReadOnlyStore {
+	return &ReadOnlyStore{LibraryStore: s, ReadOnly: &ReadOnly{}}
+}
+
+// refuse returns the error for a write.
+func (s *ReadOnlyStore) refuse() error {
+	if s.Reason == "" {
+		return ErrReadOnly
+	}
+	return fmt.Errorf("%w: %s", ErrReadOnly, s.Reason)
+}
+
+// begin readies a write: it fails when writes are refused, and otherwise
+// takes the library lock if it isn't held. The write calls release when
+// done, which under PerWrite gives the lock up again.
+func (s *ReadOnlyStore) begin() (release func(), err error) {
+	r := s.ReadOnly
+	r.mu.Lock()
+	if r.Enabled {
+		r.mu.Unlock()
+		return nil, s.refuse()
+	}
+	if r.LockPath == "" || r.lock != nil {
+		r.mu.Unlock()
+		return func() {}, nil
+	}
+	lock, err := LockLibrary(r.LockPath)
+	if err != nil {
+		r.mu.Unlock()
+		if errors.Is(err, ErrLocked) {
+			return nil, fmt.Errorf("%w: %w", ErrReadOnly, err)
+		}
+		return nil, fmt.Errorf("lock library: %w", err)
+	}
+	r.lock = lock
+	if !r.PerWrite {
+		r.mu.Unlock()
+		return func() {}, nil
+	}
+	// Writes in this process wait for each other until the lock is released
+	return func() {
+		r.lock.Unlock()
+		r.lock = nil
+		r.mu.Unlock()
+	}, nil
+}
+
+// Unlock releases the library lock, if a write took it.
+func (r *ReadOnly) Unlock() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.lock == nil {
+		return nil
+	}
+	err := r.lock.Unlock()
+	r.lock = nil
+	return err
+}
+
+func (s *ReadOnlyStore) AddDocument(doc *Document) error {
+	release, err := s.begin()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.LibraryStore.AddDocument(doc)
+}
+
+func (s *ReadOnlyStore) AddDocuments(docs []*Document) error {
+	release, err := s.begin()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.LibraryStore.AddDocuments(docs)
+}
+
+func (s *ReadOnlyStore) UpdateDocument(doc *Document) error {
+	release, err := s.begin()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.LibraryStore.UpdateDocument(doc)
+}
+
+func (s *ReadOnlyStore) DeleteDocument(id string) error {
+	release, err := s.begin()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.LibraryStore.DeleteDocument(id)
+}
+
+func (s *ReadOnlyStore) Compact() (*CompactStats, error) {
+	release, err := s.begin()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return s.LibraryStore.Compact()
+}
+
+func (s *ReadOnlyStore) RebuildIndexes() (*IndexStats, error) {
+	release, err := s.begin()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return s.LibraryStore.RebuildIndexes()
+}
+
+func (s *ReadOnlyStore) AddTag(documentID, tag string) error {
+	release, err := s.begin()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.LibraryStore.AddTag(documentID, tag)
+}
+
+func (s *ReadOnlyStore) RemoveTag(documentID, tag string) error {
+	release, err := s.begin()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.LibraryStore.RemoveTag(documentID, tag)
+}
+
+func (s *ReadOnlyStore) CreateCollection(name, description string) (*Collection, error) {
+	release, err := s.begin()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return s.LibraryStore.CreateCollection(name, description)
+}
+
+func (s *ReadOnlyStore) AddToCollection(collectionID, documentID string) error {
+	release, err := s.begin()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.LibraryStore.AddToCollection(collectionID, documentID)
+}
+
+func (s *ReadOnlyStore) RemoveFromCollection(collectionID, documentID string) error {
+	release, err := s.begin()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.LibraryStore.RemoveFromCollection(collectionID, documentID)
+}
+
+func (s *ReadOnlyStore) DeleteCollection(id string) error {
+	release, err := s.begin()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.LibraryStore.DeleteCollection(id)
+}
+
+func (s *ReadOnlyStore) SetCollectionPublic(id string, public bool) error {
+	release, err := s.begin()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.LibraryStore.SetCollectionPublic(id, public)
+}
+
+func (s *ReadOnlyStore) AddAnnotation(ann *Annotation) error {
+	release, err := s.begin()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.LibraryStore.AddAnnotation(ann)
+}
+
+func (s *ReadOnlyStore) AddAnnotations(anns []*Annotation) error {
+	release, err := s.begin()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.LibraryStore.AddAnnotations(anns)
+}
+
+func (s *ReadOnlyStore) DeleteAnnotation(id string) error {
+	release, err := s.begin()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.LibraryStore.DeleteAnnotation(id)
+}
+
+func (s *ReadOnlyStore) StartSession(documentID string) (*ReadingSession, error) {
+	release, err := s.begin()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return s.LibraryStore.StartSession(documentID)
+}
+
+func (s *ReadOnlyStore) EndSession(sessionID string, pagesRead int, notes string) error {
+	release, err := s.begin()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.LibraryStore.EndSession(sessionID, pagesRead, notes)
+}
+
+func (s *ReadOnlyStore) AddDocumentLink(l *DocumentLink) error {
+	release, err := s.begin()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.LibraryStore.AddDocumentLink(l)
+}
+
+func (s *ReadOnlyStore) DeleteDocumentLink(id string) error {
+	release, err := s.begin()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.LibraryStore.DeleteDocumentLink(id)
+}
+
+func (s *ReadOnlyStore) AddFlashcard(card *Flashcard) error {
+	release, err := s.begin()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.LibraryStore.AddFlashcard(card)
+}
+
+func (s *ReadOnlyStore) AddFlashcards(cards []*Flashcard) error {
+	release, err := s.begin()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.LibraryStore.AddFlashcards(cards)
+}
+
+func (s *ReadOnlyStore) UpdateFlashcard(card *Flashcard) error {
+	release, err := s.begin()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.LibraryStore.UpdateFlashcard(card)
+}
+
+func (s *ReadOnlyStore) DeleteFlashcard(id string) error {
+	release, err := s.begin()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.LibraryStore.DeleteFlashcard(id)
+}
+
+func (s *ReadOnlyStore) ReviewFlashcard(id string, quality int) (*Flashcard, error) {
+	release, err := s.begin()
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return s.LibraryStore.ReviewFlashcard(id, quality)
+}
+
+func (s *ReadOnlyStore) AddFlashcardReview(r *FlashcardReview) error {
+	release, err := s.begin()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.LibraryStore.AddFlashcardReview(r)
+}
+
+func (s *ReadOnlyStore) SaveDocumentReview(r *DocumentReview) error {
+	release, err := s.begin()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.LibraryStore.SaveDocumentReview(r)
+}
+
+func (s *ReadOnlyStore) DeleteDocumentReview(documentID string) error {
+	release, err := s.begin()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.LibraryStore.DeleteDocumentReview(documentID)
+}
+
+func (s *ReadOnlyStore) AddTask(t *Task) error {
+	release, err := s.begin()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.LibraryStore.AddTask(t)
+}
+
+func (s *ReadOnlyStore) UpdateTask(t *Task) error {
+	release, err := s.begin()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.LibraryStore.UpdateTask(t)
+}
+
+func (s *ReadOnlyStore) DeleteTask(id string) error {
+	release, err := s.begin()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.LibraryStore.DeleteTask(id)
+}
+
+func (s *ReadOnlyStore) SetReadingQueue(documentIDs []string) error {
+	release, err := s.begin()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.LibraryStore.SetReadingQueue(documentIDs)
+}
+
+func (s *ReadOnlyStore) DefineField(def *FieldDef) error {
+	release, err := s.begin()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.LibraryStore.DefineField(def)
+}
+
+func (s *ReadOnlyStore) DeleteField(name string) error {
+	release, err := s.begin()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.LibraryStore.DeleteField(name)
+}
+
+func (s *ReadOnlyStore) CreateShare(sh *Share) error {
+	release, err := s.begin()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.LibraryStore.CreateShare(sh)
+}
+
+func (s *ReadOnlyStore) DeleteShare(token string) error {
+	release, err := s.begin()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.LibraryStore.DeleteShare(token)
+}
+
+func (s *ReadOnlyStore) CreateAPIToken(t *APIToken) error {
+	release, err := s.begin()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.LibraryStore.CreateAPIToken(t)
+}
+
+func (s *ReadOnlyStore) DeleteAPIToken(id string) error {
+	release, err := s.begin()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.LibraryStore.DeleteAPIToken(id)
+}
+
+func (s *ReadOnlyStore) SaveSearch(ss *SavedSearch) error {
+	release, err := s.begin()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.LibraryStore.SaveSearch(ss)
+}
+
+func (s *ReadOnlyStore) DeleteSavedSearch(id string) error {
+	release, err := s.begin()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.LibraryStore.DeleteSavedSearch(id)
+}
+
+func (s *ReadOnlyStore) SaveZoteroSyncState(st *ZoteroSyncState) error {
+	release, err := s.begin()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.LibraryStore.SaveZoteroSyncState(st)
+}
+
+func (s *ReadOnlyStore) SaveTextSignature(sig *TextSignature) error {
+	release, err := s.begin()
+	if err != nil {
+		return nil // computed again when next needed
+	}
+	defer release()
+	return s.LibraryStore.SaveTextSignature(sig)
+}
+
+func (s *ReadOnlyStore) SaveDocumentSections(ds *DocumentSections) error {
+	release, err := s.begin()
+	if err != nil {
+		return nil // computed again when next needed
+	}
+	defer release()
+	return s.LibraryStore.SaveDocumentSections(ds)
+}
+
+func (s *ReadOnlyStore) SaveDocumentReferences(r *DocumentReferences) error {
+	release, err := s.begin()
+	if err != nil {
+		return nil // computed again when next needed
+	}
+	defer release()
+	return s.LibraryStore.SaveDocumentReferences(r)
+}
+
+func (s *ReadOnlyStore) RecordOperation(op *Operation) error {
+	release, err := s.begin()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.LibraryStore.RecordOperation(op)
+}
+
+func (s *ReadOnlyStore) DeleteOperation(id string) error {
+	release, err := s.begin()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.LibraryStore.DeleteOperation(id)
+}
+
+func (s *ReadOnlyStore) AppendAuditEntry(e *AuditEntry) error {
+	release, err := s.begin()
+	if err != nil {
+		return err
+	}
+	defer release()
+	return s.LibraryStore.AppendAuditEntry(e)
+}
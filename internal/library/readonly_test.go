@@ -0,0 +1,115 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"errors"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestReadOnlyStore(t *testing.T) {
+	base := benchBackends[0].open(t)
+	doc := &Document{Title: "Kept", Path: "/tmp/kept.pdf", Source: "local"}
+	if err := base.AddDocument(doc); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewReadOnlyStore(NewAuditedStore(base, "test"))
+	s.Enabled, s.Reason = true, "testing"
+	if got, err := s.GetDocument(doc.ID); err != nil || got == nil {
+		t.Fatalf("GetDocument = %v, %v", got, err)
+	}
+	err := s.AddTag(doc.ID, "ml")
+	if !errors.Is(err, ErrReadOnly) || !strings.Contains(err.Error(), "testing") {
+		t.Errorf("AddTag = %v", err)
+	}
+	if _, err := WithActor(s, "web").CreateCollection("Reading", ""); !errors.Is(err, ErrReadOnly) {
+		t.Errorf("CreateCollection with an actor = %v", err)
+	}
+	// Caches are computed on reads, so saving them is skipped, not refused
+	if err := s.SaveTextSignature(&TextSignature{DocumentID: doc.ID}); err != nil {
+		t.Errorf("SaveTextSignature = %v", err)
+	}
+	if entries, _ := base.ListAuditEntries(&AuditListOptions{}); len(entries) != 0 {
+		t.Errorf("audited %d refused changes", len(entries))
+	}
+
+	s.Enabled = false
+	if err := s.AddTag(doc.ID, "ml"); err != nil {
+		t.Errorf("AddTag once writable = %v", err)
+	}
+}
+
+func TestLockLibrary(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("libraries are not locked on Windows")
+	}
+	path := filepath.Join(t.TempDir(), "library.db.lock")
+	lock, err := LockLibrary(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = LockLibrary(path)
+	if !errors.Is(err, ErrLocked) || !strings.Contains(err.Error(), "pid ") {
+		t.Errorf("second lock = %v", err)
+	}
+	if err := lock.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	again, err := LockLibrary(path)
+	if err != nil {
+		t.Fatalf("lock after unlock = %v", err)
+	}
+	again.Unlock()
+}
+
+func TestReadOnlyStoreLocksOnWrite(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("libraries are not locked on Windows")
+	}
+	base := benchBackends[0].open(t)
+	doc := &Document{Title: "Kept", Path: "/tmp/kept.pdf", Source: "local"}
+	if err := base.AddDocument(doc); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "library.db.lock")
+	s := NewReadOnlyStore(base)
+	s.LockPath = path
+
+	// Another process writing: reads go on, writes are refused
+	other, err := LockLibrary(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := s.GetDocument(doc.ID); err != nil || got == nil {
+		t.Fatalf("GetDocument = %v, %v", got, err)
+	}
+	if err := s.AddTag(doc.ID, "ml"); !errors.Is(err, ErrReadOnly) || !errors.Is(err, ErrLocked) {
+		t.Errorf("AddTag while locked = %v", err)
+	}
+	other.Unlock()
+
+	// The first write takes the lock and keeps it
+	if err := s.AddTag(doc.ID, "ml"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LockLibrary(path); !errors.Is(err, ErrLocked) {
+		t.Errorf("lock after a write = %v", err)
+	}
+	s.Unlock()
+
+	// Long-running commands give it up after each write
+	s.PerWrite = true
+	if err := s.AddTag(doc.ID, "rl"); err != nil {
+		t.Fatal(err)
+	}
+	lock, err := LockLibrary(path)
+	if err != nil {
+		t.Fatalf("lock after a write under PerWrite = %v", err)
+	}
+	lock.Unlock()
+}
@@ -0,0 +1,157 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Paragraph is one paragraph of a document's full text, with its lines
+// joined.
+type Paragraph struct {
+	Offset int    // byte offset of its first character in the full text
+	Length int    // bytes it spans in the full text
+	Page   int    // 1-based, counting form feeds; 0 if the text has none
+	Text   string // lines joined with spaces, hyphenated line breaks rejoined
+}
+
+// SplitParagraphs splits full text into paragraphs at blank lines and
+// page breaks (form feeds, as pdftotext writes them).
+func SplitParagraphs(text string) []Paragraph {
+	paged := strings.Contains(text, "\f")
+	page := 1
+	var paras []Paragraph
+	var lines []string
+	start, end := -1, 0
+	flush := func() {
+		if start >= 0 {
+			p := Paragraph{Offset: start, Length: end - start, Text: joinParagraphLines(lines)}
+			if paged {
+				p.Page = page
+			}
+			paras = append(paras, p)
+		}
+		lines, start = nil, -1
+	}
+
+	offset := 0
+	for _, raw := range strings.SplitAfter(text, "\n") {
+		lineStart := offset
+		offset += len(raw)
+		line := strings.TrimRight(raw, "\r\n")
+		// A form feed starts the line after a page
+		for strings.HasPrefix(line, "\f") {
+			flush()
+			page++
+			line = line[1:]
+			lineStart++
+		}
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			flush()
+			continue
+		}
+		lead := strings.Index(line, trimmed)
+		if start < 0 {
+			start = lineStart + lead
+		}
+		end = lineStart + lead + len(trimmed)
+		lines = append(lines, trimmed)
+	}
+	flush()
+	return paras
+}
+
+// joinParagraphLines joins a paragraph's lines with spaces. A line ending
+// in a hyphen before a lowercase word is taken as a word broken across
+// lines and rejoined.
+func joinParagraphLines(lines []string) string {
+	text := lines[0]
+	for _, line := range lines[1:] {
+		if len(text) > 1 && strings.HasSuffix(text, "-") && isLowerStart(line) {
+			text = text[:len(text)-1] + line
+		} else {
+			text += " " + line
+		}
+	}
+	return text
+}
+
+func isLowerStart(s string) bool {
+	return s != "" && s[0] >= 'a' && s[0] <= 'z'
+}
+
+// ParagraphAt returns the index of the paragraph containing offset, or the
+// last one starting before it; 0 for offsets before the first.
+func ParagraphAt(paras []Paragraph, offset int) int {
+	i := sort.Search(len(paras), func(i int) bool { return paras[i].Offset > offset })
+	return max(i-1, 0)
+}
+
+// ReadPosition is where reading a document's full text stopped.
+type ReadPosition struct {
+	Offset    int       `json:"offset"` // byte offset of the paragraph being read
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ReadPositionsFile returns the file "read" keeps positions in:
+// $ARC_LIBRARY_READ_POSITIONS, or arc-library/read-positions.json under
+// the user config directory.
+func ReadPositionsFile() (string, error) {
+	return configFile("ARC_LIBRARY_READ_POSITIONS", "read-positions.json")
+}
+
+// LoadReadPositions reads the positions file, keyed by document ID. A
+// missing file means no positions.
+func LoadReadPositions(path string) (map[string]ReadPosition, error) {
+	positions := map[string]ReadPosition{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return positions, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &positions); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return positions, nil
+}
+
+// SaveReadPosition records the position for a document, keeping the
+// others in the file.
+func SaveReadPosition(path, documentID string, pos ReadPosition) error {
+	positions, err := LoadReadPositions(path)
+	if err != nil {
+		return err
+	}
+	positions[documentID] = pos
+	data, err := json.MarshalIndent(positions, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	// Write then rename so an interrupted save keeps the old file
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// TextSpan is an annotation Position for a span of the full text, as
+// "read" records highlights.
+type TextSpan struct {
+	Offset int `json:"offset"`
+	Length int `json:"length"`
+}
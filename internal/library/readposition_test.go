@@ -0,0 +1,72 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSplitParagraphs(t *testing.T) {
+	text := "  Attention is all\nyou need.\n\n\nWe propose a new archi-\ntecture, the Trans-\nformer.\n\fPage two starts here.\r\n\n\f\fLast page.\n"
+	paras := SplitParagraphs(text)
+	want := []Paragraph{
+		{Page: 1, Text: "Attention is all you need."},
+		{Page: 1, Text: "We propose a new architecture, the Transformer."},
+		{Page: 2, Text: "Page two starts here."},
+		{Page: 4, Text: "Last page."},
+	}
+	if len(paras) != len(want) {
+		t.Fatalf("got %d paragraphs: %+v", len(paras), paras)
+	}
+	for i, p := range paras {
+		if p.Page != want[i].Page || p.Text != want[i].Text {
+			t.Errorf("paragraph %d = %+v, want %+v", i, p, want[i])
+		}
+	}
+	if got := text[paras[0].Offset : paras[0].Offset+paras[0].Length]; got != "Attention is all\nyou need." {
+		t.Errorf("first paragraph spans %q", got)
+	}
+	if got := text[paras[2].Offset : paras[2].Offset+paras[2].Length]; got != "Page two starts here." {
+		t.Errorf("third paragraph spans %q", got)
+	}
+
+	if got := SplitParagraphs("One.\n\nTwo."); len(got) != 2 || got[1].Page != 0 {
+		t.Errorf("unpaged text: %+v", got)
+	}
+	if got := SplitParagraphs(" \n\n"); len(got) != 0 {
+		t.Errorf("blank text: %+v", got)
+	}
+
+	for offset, want := range map[int]int{0: 0, paras[1].Offset: 1, paras[1].Offset + 5: 1, len(text): 3} {
+		if got := ParagraphAt(paras, offset); got != want {
+			t.Errorf("ParagraphAt(%d) = %d, want %d", offset, got, want)
+		}
+	}
+}
+
+func TestReadPositions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "read-positions.json")
+	if positions, err := LoadReadPositions(path); err != nil || len(positions) != 0 {
+		t.Fatalf("missing file: %v %v", positions, err)
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	if err := SaveReadPosition(path, "a", ReadPosition{Offset: 120, UpdatedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveReadPosition(path, "b", ReadPosition{Offset: 7, UpdatedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveReadPosition(path, "a", ReadPosition{Offset: 300, UpdatedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+	positions, err := LoadReadPositions(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(positions) != 2 || positions["a"].Offset != 300 || positions["b"].Offset != 7 || !positions["b"].UpdatedAt.Equal(now) {
+		t.Errorf("positions = %+v", positions)
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Recurrence is a parsed task repeat rule such as "weekly" or "every 2 weeks".
+type Recurrence struct {
+	Every int    // number of units between occurrences, >= 1
+	Unit  string // "day", "week", "month", "year"
+}
+
+var recurrenceAliases = map[string]Recurrence{
+	"daily":       {1, "day"},
+	"weekly":      {1, "week"},
+	"biweekly":    {2, "week"},
+	"fortnightly": {2, "week"},
+	"monthly":     {1, "month"},
+	"quarterly":   {3, "month"},
+	"yearly":      {1, "year"},
+	"annually":    {1, "year"},
+}
+
+// ParseRecurrence parses a repeat rule: one of daily, weekly, biweekly
+// (fortnightly), monthly, quarterly, yearly, or "every N days|weeks|months|years".
+func ParseRecurrence(s string) (Recurrence, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if r, ok := recurrenceAliases[s]; ok {
+		return r, nil
+	}
+
+	fields := strings.Fields(s)
+	if len(fields) < 2 || fields[0] != "every" {
+		return Recurrence{}, fmt.Errorf("invalid repeat %q (use daily, weekly, monthly, yearly, or \"every N weeks\")", s)
+	}
+	r := Recurrence{Every: 1}
+	unit := fields[1]
+	if len(fields) == 3 {
+		n, err := strconv.Atoi(fields[1])
+		if err != nil || n < 1 {
+			return Recurrence{}, fmt.Errorf("invalid repeat interval %q", fields[1])
+		}
+		r.Every = n
+		unit = fields[2]
+	} else if len(fields) > 3 {
+		return Recurrence{}, fmt.Errorf("invalid repeat %q", s)
+	}
+	r.Unit = strings.TrimSuffix(unit, "s")
+	switch r.Unit {
+	case "day", "week", "month", "year":
+		return r, nil
+	}
+	return Recurrence{}, fmt.Errorf("invalid repeat unit %q (use days, weeks, months, years)", unit)
+}
+
+// Next returns the occurrence one interval after t. Monthly and yearly
+// occurrences fall on t's day of the month, or the last day of a month
+// too short for it: Jan 31 is followed by Feb 28, not Mar 3.
+func (r Recurrence) Next(t time.Time) time.Time {
+	return r.nextOn(t, t.Day())
+}
+
+// nextOn is Next for a repeat that falls on day of the month, which t's
+// may be short of after a short month.
+func (r Recurrence) nextOn(t time.Time, day int) time.Time {
+	var months int
+	switch r.Unit {
+	case "day":
+		return t.AddDate(0, 0, r.Every)
+	case "week":
+		return t.AddDate(0, 0, 7*r.Every)
+	case "month":
+		months = r.Every
+	default:
+		months = 12 * r.Every
+	}
+	y, m, _ := t.Date()
+	// Day 0 of the month after is the target month's last day
+	last := time.Date(y, m+time.Month(months)+1, 0, 0, 0, 0, 0, t.Location()).Day()
+	return time.Date(y, m+time.Month(months), min(day, last), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+}
+
+// NextTask returns the follow-up occurrence of a recurring task completed at
+// now, or nil if t does not repeat. The new due date advances from the old
+// one until it lies in the future, so a long-overdue task is not regenerated
+// already overdue; tasks without a due date are scheduled from now.
+func NextTask(t *Task, now time.Time) (*Task, error) {
+	if t.Repeat == "" {
+		return nil, nil
+	}
+	r, err := ParseRecurrence(t.Repeat)
+	if err != nil {
+		return nil, err
+	}
+
+	// Step in now's location, so a due date at midnight stays at midnight
+	// across DST changes
+	due := now
+	if t.DueAt != nil {
+		due = t.DueAt.In(now.Location())
+	}
+	// Keep to the day the repeat started on, through months too short
+	// for it
+	day := t.RepeatDay
+	if day == 0 {
+		day = due.Day()
+	}
+	due = r.nextOn(due, day)
+	for !due.After(now) {
+		due = r.nextOn(due, day)
+	}
+	repeatDay := 0
+	if due.Day() != day && (r.Unit == "month" || r.Unit == "year") {
+		repeatDay = day
+	}
+	due = due.UTC()
+
+	return &Task{
+		Description:  t.Description,
+		CollectionID: t.CollectionID,
+		DocumentID:   t.DocumentID,
+		ParentID:     t.ParentID,
+		Status:       TaskTodo,
+		Priority:     t.Priority,
+		Tags:         t.Tags,
+		Repeat:       t.Repeat,
+		RepeatDay:    repeatDay,
+		DueAt:        &due,
+	}, nil
+}
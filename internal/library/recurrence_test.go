@@ -0,0 +1,130 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Recurrence
+	}{
+		{"weekly", Recurrence{1, "week"}},
+		{"Daily", Recurrence{1, "day"}},
+		{"every 2 weeks", Recurrence{2, "week"}},
+		{"every month", Recurrence{1, "month"}},
+		{"every 3 days", Recurrence{3, "day"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseRecurrence(tt.in)
+		if err != nil {
+			t.Fatalf("ParseRecurrence(%q): %v", tt.in, err)
+		}
+		if got != tt.want {
+			t.Errorf("ParseRecurrence(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "sometimes", "every 0 weeks", "every two weeks", "every 2 fortnights"} {
+		if _, err := ParseRecurrence(bad); err == nil {
+			t.Errorf("ParseRecurrence(%q) should fail", bad)
+		}
+	}
+}
+
+func TestRecurrenceNextMonthEnd(t *testing.T) {
+	monthly, yearly := Recurrence{1, "month"}, Recurrence{1, "year"}
+	for _, tt := range []struct {
+		r    Recurrence
+		from time.Time
+		want time.Time
+	}{
+		{monthly, time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC), time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC)},
+		{monthly, time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{monthly, time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC), time.Date(2025, 4, 30, 0, 0, 0, 0, time.UTC)},
+		{Recurrence{3, "month"}, time.Date(2025, 11, 30, 0, 0, 0, 0, time.UTC), time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC)},
+		{yearly, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC)},
+		{yearly, time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC), time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)},
+	} {
+		if got := tt.r.Next(tt.from); !got.Equal(tt.want) {
+			t.Errorf("%+v after %s = %s, want %s", tt.r, tt.from.Format("2006-01-02"), got.Format("2006-01-02"), tt.want.Format("2006-01-02"))
+		}
+	}
+}
+
+func TestNextTaskKeepsDayOfMonth(t *testing.T) {
+	// Monthly from Jan 31: Feb 28, then back to Mar 31
+	due := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	task := &Task{Repeat: "monthly", DueAt: &due}
+	for _, want := range []time.Time{
+		time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 4, 30, 0, 0, 0, 0, time.UTC),
+	} {
+		next, err := NextTask(task, task.DueAt.Add(12*time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !next.DueAt.Equal(want) {
+			t.Fatalf("after %s: due %s, want %s", task.DueAt.Format("2006-01-02"), next.DueAt.Format("2006-01-02"), want.Format("2006-01-02"))
+		}
+		task = next
+	}
+
+	// Skipping ahead steps from the 31st too, not from each month's last day
+	next, _ := NextTask(&Task{Repeat: "monthly", DueAt: &due}, time.Date(2025, 3, 15, 12, 0, 0, 0, time.UTC))
+	if want := time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC); !next.DueAt.Equal(want) {
+		t.Errorf("overdue next due = %v, want %v", next.DueAt, want)
+	}
+
+	// Yearly from Feb 29: Feb 28 until the next leap year
+	leap := time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)
+	task = &Task{Repeat: "yearly", DueAt: &leap}
+	for _, want := range []time.Time{
+		time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC),
+		time.Date(2027, 2, 28, 0, 0, 0, 0, time.UTC),
+		time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC),
+	} {
+		next, err := NextTask(task, task.DueAt.Add(12*time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !next.DueAt.Equal(want) {
+			t.Fatalf("after %s: due %s, want %s", task.DueAt.Format("2006-01-02"), next.DueAt.Format("2006-01-02"), want.Format("2006-01-02"))
+		}
+		task = next
+	}
+}
+
+func TestNextTask(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	if next, err := NextTask(&Task{Description: "once"}, now); err != nil || next != nil {
+		t.Fatalf("non-repeating task: got %v, %v", next, err)
+	}
+
+	// On time: one interval after the old due date
+	due := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	next, err := NextTask(&Task{Description: "review", Repeat: "weekly", Priority: "high", DueAt: &due}, now)
+	if err != nil {
+		t.Fatalf("NextTask: %v", err)
+	}
+	if want := due.AddDate(0, 0, 7); !next.DueAt.Equal(want) {
+		t.Errorf("next due = %v, want %v", next.DueAt, want)
+	}
+	if next.Status != "todo" || next.Priority != "high" || next.Repeat != "weekly" {
+		t.Errorf("next task fields not carried over: %+v", next)
+	}
+
+	// Long overdue: skips ahead until the due date is in the future
+	old := time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC)
+	next, _ = NextTask(&Task{Repeat: "every 2 weeks", DueAt: &old}, now)
+	if want := time.Date(2025, 3, 17, 0, 0, 0, 0, time.UTC); !next.DueAt.Equal(want) {
+		t.Errorf("overdue next due = %v, want %v", next.DueAt, want)
+	}
+}
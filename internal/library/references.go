@@ -0,0 +1,382 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Reference is one entry of a document's bibliography. Raw is the entry
+// as printed; the other fields are what could be picked out of it.
+type Reference struct {
+	Index   int      `json:"index"` // 1-based position in the list
+	Raw     string   `json:"raw"`
+	Authors []string `json:"authors,omitempty"`
+	Title   string   `json:"title,omitempty"`
+	Year    int      `json:"year,omitempty"`
+	DOI     string   `json:"doi,omitempty"`
+	ArxivID string   `json:"arxiv_id,omitempty"`
+	URL     string   `json:"url,omitempty"`
+}
+
+// DocumentReferences is the parsed bibliography of a document's full text.
+// TextHash is the HashText of the text it was parsed from. Source is
+// ReferencesSourceGrobid for references GROBID parsed from the PDF, and
+// empty for those parsed from the text.
+type DocumentReferences struct {
+	DocumentID string      `json:"document_id"`
+	TextHash   uint64      `json:"text_hash"`
+	Source     string      `json:"source,omitempty"`
+	References []Reference `json:"references"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+}
+
+var (
+	refBracketStart  = regexp.MustCompile(`^\[(\d{1,3})\]\s*`)
+	refNumberStart   = regexp.MustCompile(`^(\d{1,3})\.?\s+\S`)
+	refNumberPrefix  = regexp.MustCompile(`^(\d{1,3})\.?\s+`)
+	refAuthorStart   = regexp.MustCompile(`^\p{Lu}[\p{L}'’-]+,\s+\p{Lu}`)
+	refDOIPattern    = regexp.MustCompile(`10\.\d{4,9}/[^\s"<>]+`)
+	refArxivPattern  = regexp.MustCompile(`(?i)(?:arxiv[:\s]*|arxiv\.org/(?:abs|pdf)/)(\d{4}\.\d{4,5})(?:v\d+)?`)
+	refURLPattern    = regexp.MustCompile(`https?://[^\s"<>]+`)
+	refYearParen     = regexp.MustCompile(`\((1[89]\d\d|20\d\d)[a-z]?\)`)
+	refYearPattern   = regexp.MustCompile(`\b(1[89]\d\d|20\d\d)[a-z]?\b`)
+	refQuotedTitle   = regexp.MustCompile(`["“]([^"”]{8,})["”]`)
+	refInitials      = regexp.MustCompile(`^(?:\p{Lu}\.\s*-?\s*)+$`)
+	refAuthorDivider = regexp.MustCompile(`\s*(?:;|,?\s+and\s+|,?\s+&\s+|,)\s*`)
+)
+
+// ParseReferences splits a bibliography into entries and picks out their
+// authors, title, year, and identifiers. Entries are recognized by
+// "[n]" or "n." numbering, or else by blank lines or lines starting with
+// "Surname, I." as in author-year styles.
+func ParseReferences(text string) []Reference {
+	lines := strings.Split(text, "\n")
+	// The first non-blank line is the heading when it isn't an entry
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if !refBracketStart.MatchString(line) && !refNumberStart.MatchString(line) && !refAuthorStart.MatchString(line) {
+			if _, ok := sectionNames[strings.ToLower(strings.Trim(line, " :."))]; ok || len(line) < 30 {
+				lines = lines[i+1:]
+			}
+		}
+		break
+	}
+
+	var entries []string
+	for _, raw := range splitReferenceEntries(lines) {
+		if raw = strings.Join(strings.Fields(raw), " "); len(raw) >= 10 {
+			entries = append(entries, raw)
+		}
+	}
+
+	refs := make([]Reference, len(entries))
+	for i, raw := range entries {
+		refs[i] = parseReference(raw)
+		refs[i].Index = i + 1
+	}
+	return refs
+}
+
+// splitReferenceEntries groups lines into entries by the numbering style
+// of the first entry.
+func splitReferenceEntries(lines []string) []string {
+	var first string
+	for _, line := range lines {
+		if first = strings.TrimSpace(line); first != "" {
+			break
+		}
+	}
+	var starts func(line string, blankBefore bool) bool
+	switch {
+	case refBracketStart.MatchString(first):
+		starts = func(line string, _ bool) bool { return refBracketStart.MatchString(line) }
+	case refNumberStart.MatchString(first):
+		// Numbers must run in sequence so years or page numbers that
+		// wrap onto the start of a line don't split an entry
+		next := 0
+		starts = func(line string, _ bool) bool {
+			m := refNumberStart.FindStringSubmatch(line)
+			if m == nil {
+				return false
+			}
+			n, _ := strconv.Atoi(m[1])
+			if next != 0 && (n <= next-1 || n > next+20) {
+				return false
+			}
+			next = n + 1
+			return true
+		}
+	default:
+		hasBlank := false
+		for i := 1; i < len(lines)-1; i++ {
+			if strings.TrimSpace(lines[i]) == "" {
+				hasBlank = true
+				break
+			}
+		}
+		starts = func(line string, blankBefore bool) bool {
+			if hasBlank {
+				return blankBefore
+			}
+			return refAuthorStart.MatchString(line)
+		}
+	}
+
+	var entries []string
+	var cur strings.Builder
+	blank := true
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			blank = true
+			continue
+		}
+		if isStart := starts(line, blank); cur.Len() == 0 || isStart {
+			if cur.Len() > 0 {
+				entries = append(entries, cur.String())
+				cur.Reset()
+			}
+		} else if strings.HasSuffix(cur.String(), "-") && unicode.IsLower([]rune(line)[0]) {
+			// Rejoin a word hyphenated across lines
+			s := cur.String()
+			cur.Reset()
+			cur.WriteString(s[:len(s)-1])
+		} else {
+			cur.WriteByte(' ')
+		}
+		cur.WriteString(line)
+		blank = false
+	}
+	if cur.Len() > 0 {
+		entries = append(entries, cur.String())
+	}
+	return entries
+}
+
+// parseReference picks the fields out of one entry.
+func parseReference(raw string) Reference {
+	if m := refBracketStart.FindString(raw); m != "" {
+		raw = raw[len(m):]
+	} else if m := refNumberPrefix.FindString(raw); m != "" {
+		raw = raw[len(m):]
+	}
+	ref := Reference{Raw: raw}
+
+	if m := refDOIPattern.FindString(raw); m != "" {
+		ref.DOI = strings.ToLower(strings.TrimRight(m, ".,;)]"))
+	}
+	if m := refArxivPattern.FindStringSubmatch(raw); m != nil {
+		ref.ArxivID = m[1]
+	}
+	if m := refURLPattern.FindString(raw); m != "" {
+		ref.URL = strings.TrimRight(m, ".,;)]")
+	}
+	if m := refYearParen.FindStringSubmatch(raw); m != nil {
+		ref.Year, _ = strconv.Atoi(m[1])
+	} else {
+		// The last year is the publication year more often than the first,
+		// which may be part of a title or a conference name
+		for _, y := range refYearPattern.FindAllStringSubmatch(raw, -1) {
+			ref.Year, _ = strconv.Atoi(y[1])
+		}
+	}
+
+	// Identifiers are dropped before splitting so their dots don't count
+	text := refURLPattern.ReplaceAllString(raw, "")
+	text = refDOIPattern.ReplaceAllString(text, "")
+	parts := referenceParts(text)
+	if len(parts) > 0 {
+		authors := strings.TrimSpace(refYearParen.ReplaceAllString(parts[0], ""))
+		ref.Authors = splitReferenceAuthors(authors)
+	}
+	switch m := refQuotedTitle.FindStringSubmatch(raw); {
+	case m != nil:
+		ref.Title = strings.TrimRight(strings.TrimSpace(m[1]), ",.")
+	case len(parts) > 1:
+		ref.Title = parts[1]
+	}
+	return ref
+}
+
+// referenceParts splits an entry into its period-separated parts, such as
+// authors, title, and venue. Periods after initials and "et al" don't end
+// a part.
+func referenceParts(s string) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] != '.' && s[i] != '?' && s[i] != '!' {
+			continue
+		}
+		if i+1 < len(s) && s[i+1] != ' ' {
+			continue
+		}
+		if s[i] == '.' {
+			word := s[start:i]
+			if j := strings.LastIndexAny(word, " ,("); j >= 0 {
+				word = word[j+1:]
+			}
+			if r := []rune(word); len(r) == 1 && unicode.IsUpper(r[0]) || word == "al" || word == "eds" || word == "Ed" || word == "Vol" || word == "pp" {
+				continue
+			}
+		}
+		end := i
+		if s[i] != '.' {
+			end = i + 1
+		}
+		if part := strings.TrimSpace(s[start:end]); part != "" {
+			parts = append(parts, part)
+		}
+		start = i + 1
+	}
+	if part := strings.TrimRight(strings.TrimSpace(s[start:]), "."); part != "" {
+		parts = append(parts, part)
+	}
+	return parts
+}
+
+// splitReferenceAuthors splits an author list, keeping "Surname, I."
+// pairs together.
+func splitReferenceAuthors(s string) []string {
+	var authors []string
+	for _, a := range refAuthorDivider.Split(s, -1) {
+		a = strings.TrimSpace(a)
+		switch {
+		case a == "" || strings.EqualFold(a, "et al") || strings.EqualFold(a, "et al."):
+		case refInitials.MatchString(a) && len(authors) > 0:
+			authors[len(authors)-1] += ", " + a
+		default:
+			authors = append(authors, strings.TrimPrefix(a, "and "))
+		}
+	}
+	return authors
+}
+
+// ComputeDocumentReferences parses the references section of doc's full
+// text.
+func ComputeDocumentReferences(doc *Document, secs *DocumentSections) *DocumentReferences {
+	refs := &DocumentReferences{
+		DocumentID: doc.ID,
+		TextHash:   HashText(doc.FullText),
+		References: []Reference{},
+		UpdatedAt:  time.Now(),
+	}
+	if sec := secs.Find(SectionReferences); sec != nil {
+		refs.References = ParseReferences(doc.FullText[sec.Start:sec.End])
+	}
+	return refs
+}
+
+// LoadDocumentReferences returns the references of doc's full text,
+// reusing the stored ones while the text is unchanged and parsing and
+// saving them otherwise. References from GROBID are always reused.
+func LoadDocumentReferences(s LibraryStore, doc *Document) (*DocumentReferences, error) {
+	refs, err := s.GetDocumentReferences(doc.ID)
+	if err != nil {
+		return nil, err
+	}
+	if refs != nil && (refs.Source == ReferencesSourceGrobid || refs.TextHash == HashText(doc.FullText)) {
+		return refs, nil
+	}
+	secs, err := LoadDocumentSections(s, doc)
+	if err != nil {
+		return nil, err
+	}
+	refs = ComputeDocumentReferences(doc, secs)
+	if err := s.SaveDocumentReferences(refs); err != nil {
+		return nil, err
+	}
+	return refs, nil
+}
+
+// ReferenceMatcher finds the library documents references point to.
+type ReferenceMatcher struct {
+	byDOI   map[string]string
+	byArxiv map[string]string
+	byTitle map[string]string
+	titles  []matcherTitle // long titles, for finding them inside raw entries
+}
+
+type matcherTitle struct {
+	title string
+	id    string
+}
+
+// NewReferenceMatcher indexes docs by DOI, arXiv ID, and title.
+func NewReferenceMatcher(docs []*Document) *ReferenceMatcher {
+	m := &ReferenceMatcher{byDOI: map[string]string{}, byArxiv: map[string]string{}, byTitle: map[string]string{}}
+	for _, d := range docs {
+		if d.Source == IDSourceDOI && d.SourceID != "" {
+			m.byDOI[strings.ToLower(d.SourceID)] = d.ID
+		}
+		if doi, ok := d.Meta["doi"].(string); ok && doi != "" {
+			m.byDOI[strings.ToLower(doi)] = d.ID
+		}
+		if d.Source == IDSourceArxiv && d.SourceID != "" {
+			m.byArxiv[arxivBaseID(d.SourceID)] = d.ID
+		}
+		if id, ok := d.Meta["arxiv"].(string); ok && id != "" {
+			m.byArxiv[arxivBaseID(id)] = d.ID
+		}
+		if t := normalizeReferenceTitle(d.Title); t != "" {
+			m.byTitle[t] = d.ID
+			if len(strings.Fields(t)) >= 4 {
+				m.titles = append(m.titles, matcherTitle{title: " " + t + " ", id: d.ID})
+			}
+		}
+	}
+	return m
+}
+
+// Match returns the ID of the document ref points to, and how it was
+// matched ("doi", "arxiv", or "title"), or "" if none does.
+func (m *ReferenceMatcher) Match(ref Reference) (id, by string) {
+	if id := m.byDOI[ref.DOI]; ref.DOI != "" && id != "" {
+		return id, "doi"
+	}
+	if id := m.byArxiv[ref.ArxivID]; ref.ArxivID != "" && id != "" {
+		return id, "arxiv"
+	}
+	if t := normalizeReferenceTitle(ref.Title); t != "" {
+		if id := m.byTitle[t]; id != "" {
+			return id, "title"
+		}
+	}
+	// The title may not have been split out correctly, so look for whole
+	// titles of four or more words in the entry itself
+	raw := " " + normalizeReferenceTitle(ref.Raw) + " "
+	for _, t := range m.titles {
+		if strings.Contains(raw, t.title) {
+			return t.id, "title"
+		}
+	}
+	return "", ""
+}
+
+// arxivBaseID strips the "arXiv:" prefix and version from an arXiv ID.
+func arxivBaseID(id string) string {
+	id = strings.TrimPrefix(strings.ToLower(id), "arxiv:")
+	if i := strings.LastIndex(id, "v"); i > 0 && i > strings.LastIndexAny(id, "./") {
+		if _, err := strconv.Atoi(id[i+1:]); err == nil {
+			id = id[:i]
+		}
+	}
+	return id
+}
+
+// normalizeReferenceTitle lowercases a title and reduces it to words of
+// letters and digits.
+func normalizeReferenceTitle(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
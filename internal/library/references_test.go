@@ -0,0 +1,130 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+func TestParseReferencesNumbered(t *testing.T) {
+	text := `References
+
+[1] A. Vaswani, N. Shazeer, and N. Parmar. Attention is all you
+need. In Advances in Neural Information Processing Systems, 2017.
+[2] J. L. Ba, J. R. Kiros, and G. E. Hinton. Layer normaliza-
+tion. arXiv:1607.06450v1, 2016.
+[3] S. Hochreiter and J. Schmidhuber. "Long short-term memory,"
+Neural Computation, 9(8):1735–1780, 1997. doi:10.1162/neco.1997.9.8.1735.
+`
+	refs := ParseReferences(text)
+	if len(refs) != 3 {
+		t.Fatalf("got %d references, want 3: %+v", len(refs), refs)
+	}
+
+	r := refs[0]
+	if r.Index != 1 || r.Title != "Attention is all you need" || r.Year != 2017 {
+		t.Errorf("ref 1 = %+v", r)
+	}
+	if strings.Join(r.Authors, "|") != "A. Vaswani|N. Shazeer|N. Parmar" {
+		t.Errorf("ref 1 authors = %q", r.Authors)
+	}
+	if r := refs[1]; r.Title != "Layer normalization" || r.ArxivID != "1607.06450" || r.Year != 2016 {
+		t.Errorf("ref 2 = %+v", r)
+	}
+	if r := refs[2]; r.Title != "Long short-term memory" || r.DOI != "10.1162/neco.1997.9.8.1735" || r.Year != 1997 {
+		t.Errorf("ref 3 = %+v", r)
+	}
+}
+
+func TestParseReferencesAuthorYear(t *testing.T) {
+	text := `Bibliography
+Bahdanau, D., Cho, K., & Bengio, Y. (2015). Neural machine translation by
+jointly learning to align and translate. In ICLR.
+Gehring, J., Auli, M. (2017). Convolutional sequence to sequence learning.
+Proceedings of ICML, 1243-1252.
+`
+	refs := ParseReferences(text)
+	if len(refs) != 2 {
+		t.Fatalf("got %d references, want 2: %+v", len(refs), refs)
+	}
+	r := refs[0]
+	if r.Year != 2015 || r.Title != "Neural machine translation by jointly learning to align and translate" {
+		t.Errorf("ref 1 = %+v", r)
+	}
+	if strings.Join(r.Authors, "|") != "Bahdanau, D.|Cho, K.|Bengio, Y." {
+		t.Errorf("ref 1 authors = %q", r.Authors)
+	}
+	if r := refs[1]; r.Year != 2017 || r.Title != "Convolutional sequence to sequence learning" {
+		t.Errorf("ref 2 = %+v", r)
+	}
+}
+
+func TestReferenceMatcher(t *testing.T) {
+	docs := []*Document{
+		{ID: "lstm", Title: "Long Short-Term Memory", Source: IDSourceDOI, SourceID: "10.1162/NECO.1997.9.8.1735"},
+		{ID: "layernorm", Title: "Layer Normalization", Source: IDSourceArxiv, SourceID: "1607.06450v1"},
+		{ID: "nmt", Title: "Neural Machine Translation by Jointly Learning to Align and Translate"},
+		{ID: "gnmt", Title: "Google's Neural Machine Translation System", Meta: JSONMap{"doi": "10.48550/arxiv.1609.08144"}},
+	}
+	m := NewReferenceMatcher(docs)
+
+	tests := []struct {
+		ref    Reference
+		id, by string
+	}{
+		{Reference{DOI: "10.1162/neco.1997.9.8.1735"}, "lstm", "doi"},
+		{Reference{DOI: "10.48550/arxiv.1609.08144"}, "gnmt", "doi"},
+		{Reference{ArxivID: "1607.06450"}, "layernorm", "arxiv"},
+		{Reference{Title: "Layer normalization."}, "layernorm", "title"},
+		{Reference{Raw: "Bahdanau et al. Neural machine translation by jointly learning to align and translate, ICLR 2015"}, "nmt", "title"},
+		{Reference{Title: "Neural machine translation", Raw: "Neural machine translation"}, "", ""},
+	}
+	for _, tt := range tests {
+		if id, by := m.Match(tt.ref); id != tt.id || by != tt.by {
+			t.Errorf("Match(%+v) = %q, %q, want %q, %q", tt.ref, id, by, tt.id, tt.by)
+		}
+	}
+}
+
+func TestLoadDocumentReferences(t *testing.T) {
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := &Document{Title: "Attention Is All You Need", FullText: samplePaper}
+	if err := s.AddDocument(doc); err != nil {
+		t.Fatal(err)
+	}
+
+	refs, err := LoadDocumentReferences(s, doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs.References) != 3 || refs.References[2].Title != "Long Short-Term Memory" {
+		t.Fatalf("references = %+v", refs.References)
+	}
+	if stored, _ := s.GetDocumentReferences(doc.ID); stored == nil || len(stored.References) != 3 {
+		t.Fatalf("stored references = %+v", stored)
+	}
+
+	// Changed text is parsed again
+	doc.FullText = "Introduction\n\nNo bibliography here.\n"
+	refs, err = LoadDocumentReferences(s, doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs.References) != 0 {
+		t.Errorf("references after text change = %+v", refs.References)
+	}
+
+	if err := s.DeleteDocument(doc.ID); err != nil {
+		t.Fatal(err)
+	}
+	if stored, _ := s.GetDocumentReferences(doc.ID); stored != nil {
+		t.Errorf("references kept after delete: %+v", stored)
+	}
+}
@@ -0,0 +1,298 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RefreshSources are the identifier sources MetadataRefresh re-resolves
+// documents from.
+var RefreshSources = []string{IDSourceArxiv, IDSourceDOI}
+
+// DefaultRefreshBatch is how many documents MetadataRefresh handles
+// between checkpoints.
+const DefaultRefreshBatch = 50
+
+// RefreshIdentifier returns the identifier doc is re-resolved by from
+// source, or "" when it has none: the arXiv ID of arXiv imports, or the
+// document's DOI.
+func RefreshIdentifier(doc *Document, source string) string {
+	switch source {
+	case IDSourceArxiv:
+		if doc.Source != IDSourceArxiv || doc.SourceID == "" {
+			return ""
+		}
+		if src, id, err := ParseIdentifier(doc.SourceID); err == nil && src == IDSourceArxiv {
+			return id
+		}
+		return doc.SourceID
+	case IDSourceDOI:
+		return documentDOI(doc)
+	}
+	return ""
+}
+
+// MetadataChange is one field a refresh changes: title, authors,
+// abstract, or a Meta key as "meta.<key>".
+type MetadataChange struct {
+	Field string `json:"field"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+// RefreshedDocument returns a copy of doc with the resolved metadata
+// applied, and what changed. Values the resolver has none for are kept, so
+// a refresh never blanks a field.
+func RefreshedDocument(doc *Document, meta JSONMap) (*Document, []MetadataChange) {
+	updated := *doc
+	updated.Meta = maps.Clone(doc.Meta)
+	if updated.Meta == nil {
+		updated.Meta = JSONMap{}
+	}
+
+	var changes []MetadataChange
+	if t, ok := meta["title"].(string); ok && t != "" && t != doc.Title {
+		changes = append(changes, MetadataChange{"title", doc.Title, t})
+		updated.Title = t
+	}
+	if a, ok := meta["authors"].([]string); ok && len(a) > 0 && !slices.Equal(a, doc.Authors) {
+		changes = append(changes, MetadataChange{"authors", doc.Authors, a})
+		updated.Authors = slices.Clone(a)
+	}
+	if a, ok := meta["abstract"].(string); ok && a != "" && a != doc.Abstract {
+		changes = append(changes, MetadataChange{"abstract", doc.Abstract, a})
+		updated.Abstract = a
+	}
+
+	keys := slices.Sorted(maps.Keys(meta))
+	for _, k := range keys {
+		switch k {
+		case "title", "authors", "abstract":
+			continue
+		case "doi":
+			// A DOI source already records it
+			if doc.Source == IDSourceDOI {
+				continue
+			}
+		}
+		v := meta[k]
+		// Compare encoded, as stored numbers come back as float64
+		oldJSON, _ := json.Marshal(doc.Meta[k])
+		newJSON, _ := json.Marshal(v)
+		if string(oldJSON) == string(newJSON) {
+			continue
+		}
+		changes = append(changes, MetadataChange{"meta." + k, doc.Meta[k], v})
+		updated.Meta[k] = v
+	}
+	return &updated, changes
+}
+
+// DocumentRefresh is the outcome of refreshing one document: the changes,
+// or the error resolving it.
+type DocumentRefresh struct {
+	DocumentID string           `json:"document_id"`
+	Title      string           `json:"title"`
+	Identifier string           `json:"identifier"`
+	Changes    []MetadataChange `json:"changes,omitempty"`
+	Error      string           `json:"error,omitempty"`
+}
+
+// RefreshCheckpoint records how far a refresh got, so an interrupted or
+// rate-limited run can resume. Documents are refreshed in ID order.
+type RefreshCheckpoint struct {
+	Source    string    `json:"source"`
+	Apply     bool      `json:"apply"`
+	LastID    string    `json:"last_id"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// RefreshCheckpointPath is where a refresh from source keeps its
+// checkpoint: under the cache directory, apart for dry runs and applies.
+func RefreshCheckpointPath(source string, apply bool) (string, error) {
+	dir, err := CacheDir()
+	if err != nil {
+		return "", err
+	}
+	name := source + "-dry-run.json"
+	if apply {
+		name = source + "-apply.json"
+	}
+	return filepath.Join(dir, "refresh", name), nil
+}
+
+// LoadRefreshCheckpoint reads a checkpoint, or returns nil when there is
+// none.
+func LoadRefreshCheckpoint(path string) (*RefreshCheckpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var c RefreshCheckpoint
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("read checkpoint %s: %w", path, err)
+	}
+	return &c, nil
+}
+
+func (c *RefreshCheckpoint) save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// RefreshResult summarizes a refresh run. Documents holds those that
+// changed or failed.
+type RefreshResult struct {
+	Source    string             `json:"source"`
+	Applied   bool               `json:"applied"`
+	Resumed   bool               `json:"resumed"`
+	Checked   int                `json:"checked"`
+	Changed   int                `json:"changed"`
+	Failed    int                `json:"failed"`
+	Remaining int                `json:"remaining"` // left for --resume after a stop
+	Documents []*DocumentRefresh `json:"documents"`
+}
+
+// MetadataRefresh re-resolves the metadata of existing documents from
+// one identifier source. Without Apply it only reports the changes.
+type MetadataRefresh struct {
+	Store  LibraryStore
+	Source string
+	Apply  bool
+	// BatchSize documents are refreshed between checkpoint saves.
+	BatchSize int
+	// Checkpoint is the checkpoint file; "" keeps none.
+	Checkpoint string
+	// Resume skips the documents a saved checkpoint has already covered.
+	Resume bool
+	// Resolve fetches an identifier's metadata; ResolveIdentifier if nil.
+	Resolve func(source, id string) (JSONMap, error)
+	// OnProgress, if set, is called after each batch.
+	OnProgress func(done, total int)
+}
+
+// Run refreshes docs that have an identifier for the source, in ID order.
+// An API that keeps rate limiting stops the run with a *RateLimitError,
+// after saving the checkpoint; other lookup failures are recorded per
+// document and the run goes on. A finished run removes its checkpoint.
+func (r *MetadataRefresh) Run(docs []*Document) (*RefreshResult, error) {
+	if !slices.Contains(RefreshSources, r.Source) {
+		return nil, fmt.Errorf("cannot refresh from %q (use arxiv or doi)", r.Source)
+	}
+	resolve := r.Resolve
+	if resolve == nil {
+		resolve = ResolveIdentifier
+	}
+	batch := r.BatchSize
+	if batch <= 0 {
+		batch = DefaultRefreshBatch
+	}
+
+	var todo []*Document
+	for _, d := range docs {
+		if RefreshIdentifier(d, r.Source) != "" {
+			todo = append(todo, d)
+		}
+	}
+	sort.Slice(todo, func(i, j int) bool { return todo[i].ID < todo[j].ID })
+
+	result := &RefreshResult{Source: r.Source, Applied: r.Apply, Documents: []*DocumentRefresh{}}
+	checkpoint := &RefreshCheckpoint{Source: r.Source, Apply: r.Apply}
+	if r.Resume && r.Checkpoint != "" {
+		saved, err := LoadRefreshCheckpoint(r.Checkpoint)
+		if err != nil {
+			return nil, err
+		}
+		if saved != nil && saved.LastID != "" {
+			checkpoint = saved
+			result.Resumed = true
+			i, _ := slices.BinarySearchFunc(todo, saved.LastID, func(d *Document, id string) int {
+				return strings.Compare(d.ID, id)
+			})
+			if i < len(todo) && todo[i].ID == saved.LastID {
+				i++
+			}
+			todo = todo[i:]
+		}
+	}
+
+	for i, doc := range todo {
+		id := RefreshIdentifier(doc, r.Source)
+		entry := &DocumentRefresh{DocumentID: doc.ID, Title: doc.Title, Identifier: id}
+		meta, err := resolve(r.Source, id)
+		var rateLimited *RateLimitError
+		switch {
+		case errors.As(err, &rateLimited):
+			result.Remaining = len(todo) - i
+			if err := r.saveCheckpoint(checkpoint); err != nil {
+				return result, err
+			}
+			return result, err
+		case err != nil:
+			entry.Error = err.Error()
+			result.Failed++
+			result.Documents = append(result.Documents, entry)
+		default:
+			updated, changes := RefreshedDocument(doc, meta)
+			if len(changes) > 0 {
+				if r.Apply {
+					if err := r.Store.UpdateDocument(updated); err != nil {
+						return result, fmt.Errorf("update %s: %w", doc.ID, err)
+					}
+				}
+				entry.Changes = changes
+				result.Changed++
+				result.Documents = append(result.Documents, entry)
+			}
+		}
+		result.Checked++
+		checkpoint.LastID = doc.ID
+
+		if (i+1)%batch == 0 && i+1 < len(todo) {
+			if err := r.saveCheckpoint(checkpoint); err != nil {
+				return result, err
+			}
+			if r.OnProgress != nil {
+				r.OnProgress(i+1, len(todo))
+			}
+		}
+	}
+
+	if r.Checkpoint != "" {
+		if err := os.Remove(r.Checkpoint); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+func (r *MetadataRefresh) saveCheckpoint(c *RefreshCheckpoint) error {
+	if r.Checkpoint == "" {
+		return nil
+	}
+	c.UpdatedAt = time.Now()
+	if err := c.save(r.Checkpoint); err != nil {
+		return fmt.Errorf("save checkpoint: %w", err)
+	}
+	return nil
+}
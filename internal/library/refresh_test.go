@@ -0,0 +1,108 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+func TestRefreshedDocument(t *testing.T) {
+	doc := &Document{
+		Title:   "Attention is all you ned",
+		Authors: []string{"A. Vaswani"},
+		Meta:    JSONMap{"year": float64(2017), "venue": "NeurIPS"},
+	}
+	meta := JSONMap{
+		"title":    "Attention Is All You Need",
+		"authors":  []string{},
+		"abstract": "The dominant sequence transduction models...",
+		"year":     2017,
+		"url":      "https://arxiv.org/abs/1706.03762",
+	}
+	updated, changes := RefreshedDocument(doc, meta)
+
+	var fields []string
+	for _, c := range changes {
+		fields = append(fields, c.Field)
+	}
+	want := []string{"title", "abstract", "meta.url"}
+	if len(fields) != len(want) {
+		t.Fatalf("changed %q, want %q", fields, want)
+	}
+	for i := range want {
+		if fields[i] != want[i] {
+			t.Fatalf("changed %q, want %q", fields, want)
+		}
+	}
+	if updated.Title != "Attention Is All You Need" || len(updated.Authors) != 1 || updated.Meta["venue"] != "NeurIPS" {
+		t.Errorf("updated = %+v", updated)
+	}
+	if doc.Title != "Attention is all you ned" || doc.Meta["url"] != nil {
+		t.Error("the original document was modified")
+	}
+}
+
+func TestMetadataRefreshResume(t *testing.T) {
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range []*Document{
+		{ID: "a", Title: "old a", Source: IDSourceArxiv, SourceID: "2304.00001"},
+		{ID: "b", Title: "old b", Source: IDSourceArxiv, SourceID: "2304.00002v2"},
+		{ID: "c", Title: "old c", Source: IDSourceArxiv, SourceID: "2304.00003"},
+		{ID: "d", Title: "no identifier", Source: "local"},
+	} {
+		if err := s.AddDocument(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	docs, _ := s.ListDocuments(nil)
+
+	limited := true
+	var looked []string
+	resolve := func(source, id string) (JSONMap, error) {
+		if id == "2304.00002" && limited {
+			return nil, &RateLimitError{Host: "export.arxiv.org"}
+		}
+		looked = append(looked, id)
+		return JSONMap{"title": "new " + id}, nil
+	}
+	r := &MetadataRefresh{
+		Store:      s,
+		Source:     IDSourceArxiv,
+		Apply:      true,
+		BatchSize:  1,
+		Checkpoint: filepath.Join(t.TempDir(), "checkpoint.json"),
+		Resolve:    resolve,
+	}
+
+	result, err := r.Run(docs)
+	var rle *RateLimitError
+	if !errors.As(err, &rle) || result.Checked != 1 || result.Remaining != 2 {
+		t.Fatalf("first run = %+v, %v", result, err)
+	}
+	if cp, _ := LoadRefreshCheckpoint(r.Checkpoint); cp == nil || cp.LastID != "a" {
+		t.Fatalf("checkpoint = %+v", cp)
+	}
+
+	limited, r.Resume = false, true
+	result, err = r.Run(docs)
+	if err != nil || !result.Resumed || result.Checked != 2 || result.Changed != 2 {
+		t.Fatalf("resumed run = %+v, %v", result, err)
+	}
+	if len(looked) != 3 || looked[1] != "2304.00002" {
+		t.Errorf("looked up %q", looked)
+	}
+	if b, _ := s.GetDocument("b"); b.Title != "new 2304.00002" {
+		t.Errorf("b = %q", b.Title)
+	}
+	if cp, _ := LoadRefreshCheckpoint(r.Checkpoint); cp != nil {
+		t.Errorf("checkpoint left after a finished run: %+v", cp)
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Where an identifier was found, from most to least trustworthy.
+const (
+	FoundGiven     = "given"      // supplied by the user
+	FoundFirstPage = "first_page" // near the start of the document's text
+	FoundFileName  = "filename"
+	FoundText      = "text" // further into the text, where it is often a citation
+)
+
+// Confidence that an identifier found in each place is the document's own.
+const (
+	ConfidenceGiven     = 1.0
+	ConfidenceFirstPage = 0.8
+	ConfidenceFileName  = 0.6
+	ConfidenceText      = 0.3
+)
+
+// firstPageChars is how much of a document's text counts as its first
+// page, where publishers print its DOI or arXiv ID.
+const firstPageChars = 4000
+
+var (
+	// A DOI in running text, stopping at spaces, quotes, and brackets
+	textDOIPattern = regexp.MustCompile(`\b10\.\d{4,9}/[^\s"'<>\[\]{}]+`)
+	// arXiv stamps "arXiv:2304.00067v1 [cs.CL]" in the margin of preprints
+	textArxivPattern = regexp.MustCompile(`(?i)\barxiv:\s*(\d{4}\.\d{4,5}|[a-z-]+(?:\.[a-z]{2})?/\d{7})(?:v\d+)?`)
+)
+
+// IdentifierMatch is an identifier found for a document, and the metadata
+// resolved from it.
+type IdentifierMatch struct {
+	Source     string  `json:"source"` // one of the IDSource constants
+	ID         string  `json:"id"`
+	Where      string  `json:"where"`      // one of the Found constants
+	Confidence float64 `json:"confidence"` // 0-1, from where it was found
+	Meta       JSONMap `json:"meta,omitempty"`
+}
+
+// SniffIdentifier looks for a document's DOI or arXiv ID in its text and
+// file name, without any lookups: first on the first page of the text,
+// then in the file name, then in the rest of the text. Either may be
+// empty. It returns nil if there is none.
+func SniffIdentifier(text, path string) *IdentifierMatch {
+	head, rest := text, ""
+	if len(text) > firstPageChars {
+		head, rest = text[:firstPageChars], text[firstPageChars:]
+	}
+	if m := sniffText(head); m != nil {
+		m.Where, m.Confidence = FoundFirstPage, ConfidenceFirstPage
+		return m
+	}
+	if path != "" {
+		if m := sniffFileName(filepath.Base(path)); m != nil {
+			m.Where, m.Confidence = FoundFileName, ConfidenceFileName
+			return m
+		}
+	}
+	if m := sniffText(rest); m != nil {
+		m.Where, m.Confidence = FoundText, ConfidenceText
+		return m
+	}
+	return nil
+}
+
+// sniffText returns the first DOI or arXiv ID in text.
+func sniffText(text string) *IdentifierMatch {
+	var found *IdentifierMatch
+	at := len(text)
+	if loc := textDOIPattern.FindStringIndex(text); loc != nil {
+		if source, id, err := ParseIdentifier(trimDOI(text[loc[0]:loc[1]])); err == nil {
+			found, at = &IdentifierMatch{Source: source, ID: id}, loc[0]
+		}
+	}
+	if m := textArxivPattern.FindStringSubmatchIndex(text); m != nil && m[0] < at {
+		found = &IdentifierMatch{Source: IDSourceArxiv, ID: text[m[2]:m[3]]}
+	}
+	return found
+}
+
+// trimDOI drops punctuation that ends the sentence a DOI is in rather
+// than the DOI, keeping closing brackets that have an opening one in it,
+// as in 10.1002/(SICI)1097-4571.
+func trimDOI(doi string) string {
+	for doi != "" {
+		last := doi[len(doi)-1]
+		switch {
+		case strings.IndexByte(".,;:", last) >= 0:
+		case last == ')' && strings.Count(doi, "(") < strings.Count(doi, ")"):
+		default:
+			return doi
+		}
+		doi = doi[:len(doi)-1]
+	}
+	return doi
+}
+
+// sniffFileName recognizes a file named for its DOI or arXiv ID. File
+// names cannot hold the slash in a DOI, so the first underscore or
+// hyphen after its prefix stands for it, as browsers and reference
+// managers save them ("10.1038_nature12373.pdf").
+func sniffFileName(base string) *IdentifierMatch {
+	name := strings.TrimSuffix(base, filepath.Ext(base))
+	if source, id, err := ParseIdentifier(name); err == nil && (source == IDSourceArxiv || source == IDSourceDOI || source == IDSourceBioRxiv) {
+		return &IdentifierMatch{Source: source, ID: id}
+	}
+	i := strings.Index(name, "10.")
+	if i < 0 {
+		return nil
+	}
+	name = name[i:]
+	if j := strings.IndexAny(name, "_-"); j > 0 && !strings.Contains(name, "/") {
+		name = name[:j] + "/" + name[j+1:]
+	}
+	if source, id, err := ParseIdentifier(trimDOI(name)); err == nil && (source == IDSourceDOI || source == IDSourceBioRxiv) {
+		return &IdentifierMatch{Source: source, ID: id}
+	}
+	return nil
+}
+
+// ResolveMetadata finds a document's identifier and fetches its metadata
+// with ResolveIdentifier. A given identifier, such as one from --doi, is
+// used as is; otherwise SniffIdentifier looks in text and path. It
+// returns nil and no error if no identifier is found, and the match
+// without Meta along with the error if the lookup fails.
+func ResolveMetadata(given, text, path string) (*IdentifierMatch, error) {
+	var match *IdentifierMatch
+	if given != "" {
+		source, id, err := ParseIdentifier(given)
+		if err != nil {
+			return nil, err
+		}
+		match = &IdentifierMatch{Source: source, ID: id, Where: FoundGiven, Confidence: ConfidenceGiven}
+	} else if match = SniffIdentifier(text, path); match == nil {
+		return nil, nil
+	}
+	meta, err := ResolveIdentifier(match.Source, match.ID)
+	if err != nil {
+		return match, err
+	}
+	match.Meta = meta
+	return match, nil
+}
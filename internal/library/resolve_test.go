@@ -0,0 +1,90 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSniffIdentifier(t *testing.T) {
+	body := strings.Repeat("Lorem ipsum dolor sit amet. ", 200)
+	tests := []struct {
+		name, text, path string
+		source, id       string
+		where            string
+	}{
+		{"doi on first page", "Nature 500, 54 (2013). https://doi.org/10.1038/nature12373.\n" + body, "paper.pdf",
+			IDSourceDOI, "10.1038/nature12373", FoundFirstPage},
+		{"arxiv stamp", "arXiv:2304.00067v2 [cs.CL] 3 May 2023\nTitle", "",
+			IDSourceArxiv, "2304.00067", FoundFirstPage},
+		{"earlier of doi and arxiv", "see doi:10.1000/xyz123, arXiv:2304.00067", "",
+			IDSourceDOI, "10.1000/xyz123", FoundFirstPage},
+		{"bracketed doi", "(10.1002/(SICI)1097-4571(199806)49:8<693::AID-ASI4>3.0.CO;2-0)", "",
+			IDSourceDOI, "10.1002/(SICI)1097-4571(199806)49:8", FoundFirstPage},
+		{"biorxiv", "doi: 10.1101/2020.03.01.123456", "", IDSourceBioRxiv, "10.1101/2020.03.01.123456", FoundFirstPage},
+		{"doi file name", body, "/papers/10.1038_nature12373.pdf", IDSourceDOI, "10.1038/nature12373", FoundFileName},
+		{"arxiv file name", "", "/papers/2304.00067v1.pdf", IDSourceArxiv, "2304.00067", FoundFileName},
+		{"citation in body", body + "[12] Smith, J. 10.1145/3292500.", "notes.pdf", IDSourceDOI, "10.1145/3292500", FoundText},
+		{"file name before body", body + " 10.1145/3292500", "10.1038_nature12373.pdf", IDSourceDOI, "10.1038/nature12373", FoundFileName},
+		{"none", "no identifiers here, version 10.5 of the tool", "12345678.pdf", "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := SniffIdentifier(tt.text, tt.path)
+			if tt.source == "" {
+				if m != nil {
+					t.Fatalf("got %+v, want none", m)
+				}
+				return
+			}
+			if m == nil {
+				t.Fatalf("got none, want %s %s", tt.source, tt.id)
+			}
+			if m.Source != tt.source || m.ID != tt.id || m.Where != tt.where {
+				t.Errorf("got %s %s (%s), want %s %s (%s)", m.Source, m.ID, m.Where, tt.source, tt.id, tt.where)
+			}
+		})
+	}
+	if m := SniffIdentifier("doi 10.1000/abc", ""); m.Confidence != ConfidenceFirstPage {
+		t.Errorf("first page confidence = %v", m.Confidence)
+	}
+}
+
+func TestResolveMetadata(t *testing.T) {
+	withMetadataServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/works/10.1038/nature12373" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"message": {"title": ["Nanometre-scale thermometry"], "author": [{"given": "G.", "family": "Kucsko"}]}}`))
+	})
+
+	m, err := ResolveMetadata("doi:10.1038/nature12373", "doi 10.1000/other", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Where != FoundGiven || m.Confidence != ConfidenceGiven || m.Meta["title"] != "Nanometre-scale thermometry" || m.Meta["doi"] != "10.1038/nature12373" {
+		t.Errorf("given: %+v", m)
+	}
+
+	m, err = ResolveMetadata("", "", "10.1038_nature12373.pdf")
+	if err != nil || m.Where != FoundFileName || m.Meta["title"] != "Nanometre-scale thermometry" {
+		t.Errorf("sniffed: %+v, %v", m, err)
+	}
+
+	// A failed lookup still says what was tried
+	m, err = ResolveMetadata("", "doi: 10.1000/missing", "")
+	if err == nil || m == nil || m.ID != "10.1000/missing" || m.Meta != nil {
+		t.Errorf("missing: %+v, %v", m, err)
+	}
+
+	if m, err := ResolveMetadata("", "nothing", "paper.pdf"); m != nil || err != nil {
+		t.Errorf("no identifier: %+v, %v", m, err)
+	}
+	if _, err := ResolveMetadata("not an id", "", ""); err == nil {
+		t.Error("bad given identifier: no error")
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Defaults for ResolverOptions. Crossref's public pool allows 5 requests
+// a second and NCBI 3 without an API key, so 3 keeps clear of both.
+const (
+	DefaultResolverRate    = 3.0
+	DefaultResolverRetries = 3
+)
+
+// maxRetryWait is the longest a request waits before a retry; a longer
+// Retry-After fails the request instead.
+const maxRetryWait = 2 * time.Minute
+
+// retryBaseDelay is the first backoff delay, doubled on each retry; a
+// variable so tests don't wait.
+var retryBaseDelay = time.Second
+
+// ResolverOptions control how the metadata resolvers treat the APIs they
+// query.
+type ResolverOptions struct {
+	// Email is sent as the User-Agent's mailto, which puts Crossref
+	// requests in its faster, more reliable polite pool.
+	Email string
+	// Rate is the most requests a second sent to any one API host; 0 is
+	// unlimited. A lower limit announced by the API takes precedence.
+	Rate float64
+	// Retries is how often a request answered 429 or 5xx is retried, with
+	// exponential backoff or after the response's Retry-After.
+	Retries int
+	// OnRetry, if set, is called before waiting to retry a request.
+	OnRetry func(host, status string, wait time.Duration)
+}
+
+var (
+	resolverOptions = ResolverOptions{Rate: DefaultResolverRate, Retries: DefaultResolverRetries}
+	hostLimiters    = map[string]*hostLimiter{}
+	hostLimitersMu  sync.Mutex
+)
+
+// SetResolverOptions replaces the resolvers' options.
+func SetResolverOptions(o ResolverOptions) {
+	hostLimitersMu.Lock()
+	defer hostLimitersMu.Unlock()
+	resolverOptions = o
+	hostLimiters = map[string]*hostLimiter{}
+}
+
+// RateLimitError is returned when an API keeps refusing requests with 429
+// Too Many Requests. RetryAfter is the wait the API asked for, if any.
+type RateLimitError struct {
+	Host       string
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("%s is rate limiting requests; retry after %s", e.Host, e.RetryAfter)
+	}
+	return fmt.Sprintf("%s is rate limiting requests; try again later", e.Host)
+}
+
+// hostLimiter spaces requests to one host at least interval apart.
+type hostLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func limiterFor(host string) *hostLimiter {
+	hostLimitersMu.Lock()
+	defer hostLimitersMu.Unlock()
+	l := hostLimiters[host]
+	if l == nil {
+		l = &hostLimiter{}
+		if resolverOptions.Rate > 0 {
+			l.interval = time.Duration(float64(time.Second) / resolverOptions.Rate)
+		}
+		hostLimiters[host] = l
+	}
+	return l
+}
+
+func (l *hostLimiter) wait() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	if l.next.After(now) {
+		time.Sleep(l.next.Sub(now))
+		now = l.next
+	}
+	l.next = now.Add(l.interval)
+}
+
+// adapt slows the limiter down to the rate an API announces in
+// X-Rate-Limit-Limit and X-Rate-Limit-Interval, as Crossref does.
+func (l *hostLimiter) adapt(h http.Header) {
+	limit, err := strconv.Atoi(h.Get("X-Rate-Limit-Limit"))
+	if err != nil || limit <= 0 {
+		return
+	}
+	interval, err := time.ParseDuration(h.Get("X-Rate-Limit-Interval"))
+	if err != nil || interval <= 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if per := interval / time.Duration(limit); per > l.interval {
+		l.interval = per
+	}
+}
+
+// retryAfter parses a Retry-After header given in seconds or as a date.
+func retryAfter(h http.Header) time.Duration {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0)
+	}
+	return 0
+}
+
+// fetchMetadataLive GETs rawURL from the network, keeping to the host's
+// rate limit and retrying rate-limited and failed requests.
+func fetchMetadataLive(rawURL string) ([]byte, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	opts := resolverOptions
+	limiter := limiterFor(u.Host)
+	userAgent := "arc-library/1.0"
+	if opts.Email != "" {
+		userAgent += " (mailto:" + opts.Email + ")"
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequest("GET", rawURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("create request: %w", err)
+		}
+		req.Header.Set("User-Agent", userAgent)
+		limiter.wait()
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		limiter.adapt(resp.Header)
+		if resp.StatusCode == http.StatusOK {
+			defer resp.Body.Close()
+			return io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+		}
+		resp.Body.Close()
+
+		retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		if !retryable {
+			return nil, fmt.Errorf("%s", resp.Status)
+		}
+		asked := retryAfter(resp.Header)
+		if attempt >= opts.Retries || asked > maxRetryWait {
+			if resp.StatusCode == http.StatusTooManyRequests {
+				return nil, &RateLimitError{Host: u.Host, RetryAfter: asked}
+			}
+			return nil, fmt.Errorf("%s", resp.Status)
+		}
+		wait := asked
+		if wait == 0 {
+			wait = retryBaseDelay << attempt
+		}
+		if opts.OnRetry != nil {
+			opts.OnRetry(u.Host, resp.Status, wait)
+		}
+		time.Sleep(wait)
+	}
+}
+
+// doWithRetries sends the request newRequest builds, sending a new one
+// while the API answers 429 Too Many Requests, up to retries times. It
+// returns the final response and its body, whatever the status.
+func doWithRetries(client *http.Client, newRequest func() (*http.Request, error), retries int) (*http.Response, []byte, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := newRequest()
+		if err != nil {
+			return nil, nil, fmt.Errorf("create request: %w", err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, nil, err
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+		if resp.StatusCode != http.StatusTooManyRequests {
+			return resp, body, nil
+		}
+		wait := retryAfter(resp.Header)
+		if attempt >= retries || wait > maxRetryWait {
+			return nil, nil, &RateLimitError{Host: req.URL.Host, RetryAfter: wait}
+		}
+		if wait == 0 {
+			wait = retryBaseDelay << attempt
+		}
+		time.Sleep(wait)
+	}
+}
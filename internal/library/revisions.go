@@ -0,0 +1,150 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// DocumentRevision records the metadata fields changed by one document
+// update. Revisions are numbered from 1 per document; revision 0 stands for
+// the document as it was before its first recorded update.
+type DocumentRevision struct {
+	ID         string        `json:"id" yaml:"id"`
+	DocumentID string        `json:"document_id" yaml:"document_id"`
+	Rev        int           `json:"rev" yaml:"rev"`
+	Changes    []FieldChange `json:"changes" yaml:"changes"`
+	CreatedAt  time.Time     `json:"created_at" yaml:"created_at"`
+}
+
+// FieldChange is one changed document field, keyed by its JSON name, with
+// the JSON-encoded values before and after (null when unset).
+type FieldChange struct {
+	Field string          `json:"field" yaml:"field"`
+	Old   json.RawMessage `json:"old" yaml:"old"`
+	New   json.RawMessage `json:"new" yaml:"new"`
+}
+
+// unversionedFields are document fields left out of revisions: identity,
+// bookkeeping timestamps, and extracted full text, which is bulky and can be
+// re-extracted from the file.
+var unversionedFields = map[string]bool{
+	"id":         true,
+	"full_text":  true,
+	"created_at": true,
+	"updated_at": true,
+}
+
+var jsonNull = json.RawMessage("null")
+
+// DiffDocuments returns the versioned fields that differ between old and
+// updated, sorted by field name.
+func DiffDocuments(old, updated *Document) ([]FieldChange, error) {
+	before, err := documentFields(old)
+	if err != nil {
+		return nil, err
+	}
+	after, err := documentFields(updated)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []FieldChange
+	for field := range mergeKeys(before, after) {
+		if unversionedFields[field] {
+			continue
+		}
+		o, n := before[field], after[field]
+		if o == nil {
+			o = jsonNull
+		}
+		if n == nil {
+			n = jsonNull
+		}
+		if !bytes.Equal(o, n) {
+			changes = append(changes, FieldChange{Field: field, Old: o, New: n})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes, nil
+}
+
+// RevertDocument returns doc as it was at revision to, undoing the changes
+// of every later revision in revs. The result keeps doc's unversioned
+// fields; storing it records a new revision, so a revert can be undone too.
+func RevertDocument(doc *Document, revs []*DocumentRevision, to int) (*Document, error) {
+	latest := latestRevision(revs)
+	if to < 0 || to > latest {
+		return nil, fmt.Errorf("revision %d out of range (0-%d)", to, latest)
+	}
+
+	fields, err := documentFields(doc)
+	if err != nil {
+		return nil, err
+	}
+	sorted := append([]*DocumentRevision(nil), revs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Rev > sorted[j].Rev })
+	for _, r := range sorted {
+		if r.Rev <= to {
+			break
+		}
+		for _, c := range r.Changes {
+			fields[c.Field] = c.Old
+		}
+	}
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	var reverted Document
+	if err := json.Unmarshal(data, &reverted); err != nil {
+		return nil, fmt.Errorf("rebuild document: %w", err)
+	}
+	reverted.ID = doc.ID
+	reverted.FullText = doc.FullText
+	reverted.CreatedAt = doc.CreatedAt
+	reverted.UpdatedAt = doc.UpdatedAt
+	return &reverted, nil
+}
+
+func latestRevision(revs []*DocumentRevision) int {
+	latest := 0
+	for _, r := range revs {
+		latest = max(latest, r.Rev)
+	}
+	return latest
+}
+
+func documentFields(doc *Document) (map[string]json.RawMessage, error) {
+	return jsonFields(doc)
+}
+
+// jsonFields returns a record's fields by JSON name, JSON-encoded.
+func jsonFields(v any) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func mergeKeys(a, b map[string]json.RawMessage) map[string]bool {
+	keys := make(map[string]bool, len(a)+len(b))
+	for k := range a {
+		keys[k] = true
+	}
+	for k := range b {
+		keys[k] = true
+	}
+	return keys
+}
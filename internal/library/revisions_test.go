@@ -0,0 +1,83 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+func TestDocumentRevisions(t *testing.T) {
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := &Document{ID: "d1", Title: "Original", Path: "/d1.pdf", Tags: []string{"ml"}, FullText: "body"}
+	if err := s.AddDocument(doc); err != nil {
+		t.Fatal(err)
+	}
+
+	// Rev 1: title and rating; rev 2: tags cleared; a no-op update records nothing
+	doc.Title = "AI-rewritten title"
+	doc.Rating = 4
+	if err := s.UpdateDocument(doc); err != nil {
+		t.Fatal(err)
+	}
+	doc.Tags = nil
+	if err := s.UpdateDocument(doc); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpdateDocument(doc); err != nil {
+		t.Fatal(err)
+	}
+
+	revs, err := s.ListDocumentRevisions("d1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revs) != 2 || revs[0].Rev != 1 || revs[1].Rev != 2 {
+		t.Fatalf("revisions = %+v, want revs 1 and 2", revs)
+	}
+	var fields []string
+	for _, c := range revs[0].Changes {
+		fields = append(fields, c.Field)
+	}
+	if !reflect.DeepEqual(fields, []string{"rating", "title"}) {
+		t.Errorf("rev 1 fields = %v, want [rating title]", fields)
+	}
+
+	current, _ := s.GetDocument("d1")
+	reverted, err := RevertDocument(current, revs, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reverted.Title != "Original" || reverted.Rating != 0 || !reflect.DeepEqual(reverted.Tags, []string{"ml"}) {
+		t.Errorf("revert to 0 = %+v", reverted)
+	}
+	if reverted.FullText != "body" || reverted.CreatedAt != current.CreatedAt {
+		t.Error("revert should keep unversioned fields")
+	}
+
+	reverted, err = RevertDocument(current, revs, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reverted.Title != "AI-rewritten title" || !reflect.DeepEqual(reverted.Tags, []string{"ml"}) {
+		t.Errorf("revert to 1 = %+v", reverted)
+	}
+
+	if _, err := RevertDocument(current, revs, 3); err == nil {
+		t.Error("revert past the latest revision should fail")
+	}
+
+	// Reverting is itself an update, so it can be undone
+	if err := s.UpdateDocument(reverted); err != nil {
+		t.Fatal(err)
+	}
+	if revs, _ := s.ListDocumentRevisions("d1"); len(revs) != 3 {
+		t.Errorf("got %d revisions after revert, want 3", len(revs))
+	}
+}
@@ -0,0 +1,428 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	RegisterExporter(risExporter{})
+	RegisterImporter(risImporter{})
+}
+
+// risExporter writes RIS, the reference standard read by Zotero, EndNote,
+// and Mendeley.
+type risExporter struct{}
+
+func (risExporter) Format() Format {
+	return Format{
+		Name:         "ris",
+		Description:  "RIS for Zotero, EndNote, and Mendeley",
+		Extensions:   []string{".ris"},
+		Capabilities: []string{FormatTags, FormatFiles},
+	}
+}
+
+// risVenueTypes maps Meta["venue_type"] to the RIS reference type, as
+// bibtexEntryTypes does for BibTeX.
+var risVenueTypes = map[string]string{
+	"article":       "JOUR",
+	"journal":       "JOUR",
+	"conference":    "CPAPER",
+	"proceedings":   "CPAPER",
+	"inproceedings": "CPAPER",
+	"workshop":      "CPAPER",
+	"techreport":    "RPRT",
+	"report":        "RPRT",
+	"book":          "BOOK",
+	"chapter":       "CHAP",
+	"incollection":  "CHAP",
+	"thesis":        "THES",
+	"phdthesis":     "THES",
+	"dissertation":  "THES",
+	"mastersthesis": "THES",
+	"preprint":      "UNPB",
+	"misc":          "GEN",
+	"web":           "ELEC",
+}
+
+// risDocumentTypes maps a document type to the RIS type of documents
+// without a venue type. Web articles are web pages, not journal articles.
+var risDocumentTypes = map[DocumentType]string{
+	DocTypePaper:   "JOUR",
+	"":             "JOUR",
+	DocTypeBook:    "BOOK",
+	DocTypeArticle: "ELEC",
+	DocTypeVideo:   "VIDEO",
+	DocTypeRepo:    "COMP",
+}
+
+// RISType returns the reference type a document is exported as, from its
+// Meta["venue_type"] or else its document type.
+func RISType(doc *Document) string {
+	if vt := strings.ToLower(strings.TrimSpace(metaValue("venue_type")(doc))); vt != "" {
+		if t, ok := risVenueTypes[vt]; ok {
+			return t
+		}
+	}
+	if t, ok := risDocumentTypes[doc.Type]; ok {
+		return t
+	}
+	return "GEN"
+}
+
+// risField is a tag of a RIS record: where its values come from, one line
+// each, and for which reference types (all when nil).
+type risField struct {
+	tag    string
+	types  []string
+	values func(*Document) []string
+}
+
+// risFields are the tags RIS export writes after TY, in order.
+var risFields = []risField{
+	{tag: "TI", values: risOne(func(d *Document) string { return d.Title })},
+	{tag: "AU", values: func(d *Document) []string { return d.Authors }},
+	{tag: "PY", values: risOne(func(d *Document) string {
+		if y := DocumentYear(d); y > 0 {
+			return strconv.Itoa(y)
+		}
+		return ""
+	})},
+	{tag: "T2", types: []string{"JOUR", "UNPB"}, values: risOne(metaValue("journal", "venue"))},
+	{tag: "T2", types: []string{"CPAPER", "CHAP"}, values: risOne(metaValue("booktitle", "venue", "journal"))},
+	{tag: "VL", values: risOne(metaValue("volume"))},
+	{tag: "IS", values: risOne(metaValue("issue", "number", "report_number"))},
+	// A book's "pages" from Open Library is its page count, not a range
+	{tag: "SP", types: []string{"JOUR", "CPAPER", "CHAP", "RPRT", "UNPB", "GEN"}, values: risOne(func(d *Document) string {
+		start, _ := pageBounds(metaValue("page", "pages")(d))
+		return start
+	})},
+	{tag: "EP", types: []string{"JOUR", "CPAPER", "CHAP", "RPRT", "UNPB", "GEN"}, values: risOne(func(d *Document) string {
+		_, end := pageBounds(metaValue("page", "pages")(d))
+		return end
+	})},
+	{tag: "PB", types: []string{"THES"}, values: risOne(metaValue("school", "institution", "publisher"))},
+	{tag: "PB", types: []string{"RPRT"}, values: risOne(metaValue("institution", "publisher"))},
+	{tag: "PB", types: []string{"JOUR", "CPAPER", "CHAP", "BOOK", "ELEC", "UNPB", "GEN", "VIDEO", "COMP"}, values: risOne(metaValue("publisher"))},
+	// RIS has one standard number: a book's ISBN, or a serial's ISSN
+	{tag: "SN", types: []string{"BOOK", "CHAP", "THES", "RPRT"}, values: risOne(metaValue("isbn", "issn"))},
+	{tag: "SN", types: []string{"JOUR", "CPAPER", "ELEC", "UNPB", "GEN", "VIDEO", "COMP"}, values: risOne(metaValue("issn", "isbn"))},
+	{tag: "AB", values: risOne(func(d *Document) string { return d.Abstract })},
+	{tag: "DO", values: risOne(documentDOI)},
+	{tag: "UR", values: risOne(func(d *Document) string {
+		if id := documentArxivID(d); id != "" {
+			return "https://arxiv.org/abs/" + id
+		}
+		return ""
+	})},
+	{tag: "UR", values: risOne(metaValue("url"))},
+	{tag: "L1", values: risOne(func(d *Document) string {
+		if d.Path == "" {
+			return ""
+		}
+		return DocumentPath(d)
+	})},
+	{tag: "KW", values: func(d *Document) []string { return d.Tags }},
+}
+
+// risOne turns a single value into a tag's values.
+func risOne(value func(*Document) string) func(*Document) []string {
+	return func(d *Document) []string { return []string{value(d)} }
+}
+
+func (risExporter) Export(w io.Writer, _ LibraryStore, docs []*Document, _ ExportOptions) error {
+	var buf bytes.Buffer
+	for _, doc := range docs {
+		writeRISRecord(&buf, doc)
+		buf.WriteString("\n")
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// writeRISRecord writes doc as a record from TY to ER.
+func writeRISRecord(buf *bytes.Buffer, doc *Document) {
+	risType := RISType(doc)
+	buf.WriteString(fmt.Sprintf("TY  - %s\n", risType))
+	for _, f := range risFields {
+		if f.types != nil && !slices.Contains(f.types, risType) {
+			continue
+		}
+		for _, v := range f.values(doc) {
+			// A value is one line; readers take the next as a new tag
+			if v = strings.Join(strings.Fields(v), " "); v != "" {
+				buf.WriteString(fmt.Sprintf("%s  - %s\n", f.tag, v))
+			}
+		}
+	}
+	buf.WriteString("ER  - \n")
+}
+
+// pageBounds splits a page range such as "12-20" into its first and last
+// page. A single page has no last page.
+func pageBounds(pages string) (start, end string) {
+	if m := pageRangePattern.FindStringSubmatch(pages); m != nil {
+		return m[1], m[2]
+	}
+	return strings.TrimSpace(pages), ""
+}
+
+// risImporter reads the records of a RIS file as documents, metadata only
+// unless a record links a local file with L1.
+type risImporter struct{}
+
+func (risImporter) Format() Format {
+	return Format{
+		Name:         "ris",
+		Description:  "RIS files from Zotero, EndNote, and Mendeley, one document per record",
+		Extensions:   []string{".ris"},
+		Capabilities: []string{FormatTags, FormatFiles, FormatMetadata},
+	}
+}
+
+func (risImporter) Detect(path string, info fs.FileInfo) bool {
+	return !info.IsDir() && strings.EqualFold(filepath.Ext(path), ".ris")
+}
+
+func (risImporter) Import(path string) ([]*Document, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	records, err := ParseRIS(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	docs := make([]*Document, 0, len(records))
+	for _, r := range records {
+		docs = append(docs, r.Document())
+	}
+	return docs, nil
+}
+
+// RISRecord is a record of a RIS file: its values by tag, in file order.
+type RISRecord map[string][]string
+
+// Get returns the first value of the first of tags the record has.
+func (r RISRecord) Get(tags ...string) string {
+	for _, t := range tags {
+		for _, v := range r[t] {
+			if v != "" {
+				return v
+			}
+		}
+	}
+	return ""
+}
+
+var risLinePattern = regexp.MustCompile(`^([A-Z][A-Z0-9])  -(?: (.*))?$`)
+
+// ParseRIS reads the records of a RIS file. Lines that are not tagged
+// continue the value before them, as long abstracts and notes in EndNote
+// exports do. A record not closed by ER at the end of the file is kept.
+func ParseRIS(r io.Reader) ([]RISRecord, error) {
+	var records []RISRecord
+	var rec RISRecord
+	var last string
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimRight(sc.Text(), " \t\r")
+		if n == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		m := risLinePattern.FindStringSubmatch(line)
+		if m == nil {
+			if rec != nil && last != "" && strings.TrimSpace(line) != "" {
+				vals := rec[last]
+				vals[len(vals)-1] += " " + strings.TrimSpace(line)
+			}
+			continue
+		}
+		tag, value := m[1], strings.TrimSpace(m[2])
+		switch {
+		case tag == "TY":
+			if rec != nil {
+				records = append(records, rec)
+			}
+			rec = RISRecord{"TY": {value}}
+		case rec == nil:
+			return nil, fmt.Errorf("line %d: %s before TY", n, tag)
+		case tag == "ER":
+			records = append(records, rec)
+			rec = nil
+		default:
+			rec[tag] = append(rec[tag], value)
+		}
+		last = tag
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if rec != nil {
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// risImportTypes maps a RIS reference type to a document type and, where
+// it says more than the document type, Meta["venue_type"]. Types not
+// listed are other documents.
+var risImportTypes = map[string]struct {
+	docType   DocumentType
+	venueType string
+}{
+	"JOUR":   {DocTypePaper, ""},
+	"JFULL":  {DocTypePaper, ""},
+	"EJOUR":  {DocTypePaper, ""},
+	"CPAPER": {DocTypePaper, "conference"},
+	"CONF":   {DocTypePaper, "conference"},
+	"CHAP":   {DocTypePaper, "chapter"},
+	"ECHAP":  {DocTypePaper, "chapter"},
+	"THES":   {DocTypePaper, "thesis"},
+	"RPRT":   {DocTypePaper, "report"},
+	"UNPB":   {DocTypePaper, "preprint"},
+	"BOOK":   {DocTypeBook, ""},
+	"EBOOK":  {DocTypeBook, ""},
+	"EDBOOK": {DocTypeBook, ""},
+	"ELEC":   {DocTypeArticle, ""},
+	"WEB":    {DocTypeArticle, ""},
+	"BLOG":   {DocTypeArticle, ""},
+	"NEWS":   {DocTypeArticle, ""},
+	"MGZN":   {DocTypeArticle, ""},
+	"VIDEO":  {DocTypeVideo, ""},
+	"MPCT":   {DocTypeVideo, ""},
+	"COMP":   {DocTypeRepo, ""},
+}
+
+var (
+	risYearPattern  = regexp.MustCompile(`\b(\d{4})\b`)
+	risISSNPattern  = regexp.MustCompile(`^\d{4}-?\d{3}[\dXx]$`)
+	risArxivPattern = regexp.MustCompile(`^https?://(?:www\.)?arxiv\.org/(?:abs|pdf)/([^?#]+?)(?:\.pdf)?$`)
+)
+
+// Document returns the document the record describes, in the fields and
+// Meta keys RIS export reads, so a library exported as RIS and imported
+// again comes back the same. Names written "Family, Given" are turned
+// around to the "Given Family" the library uses.
+func (r RISRecord) Document() *Document {
+	risType := strings.ToUpper(r.Get("TY"))
+	doc := &Document{Type: DocTypeOther, Meta: JSONMap{}}
+	t, ok := risImportTypes[risType]
+	if ok {
+		doc.Type = t.docType
+		if t.venueType != "" {
+			doc.Meta["venue_type"] = t.venueType
+		}
+	}
+
+	doc.Title = r.Get("TI", "T1", "CT", "BT")
+	for _, tag := range []string{"AU", "A1"} {
+		for _, name := range r[tag] {
+			if name = risName(name); name != "" {
+				doc.Authors = append(doc.Authors, name)
+			}
+		}
+	}
+	doc.Abstract = r.Get("AB", "N2")
+	for _, kw := range r["KW"] {
+		if kw != "" && !slices.Contains(doc.Tags, kw) {
+			doc.Tags = append(doc.Tags, kw)
+		}
+	}
+
+	setMeta := func(key, value string) {
+		if value != "" {
+			doc.Meta[key] = value
+		}
+	}
+	if m := risYearPattern.FindStringSubmatch(r.Get("PY", "Y1", "DA")); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		doc.Meta["year"] = year
+	}
+	container := r.Get("T2", "JF", "JO", "JA", "J2")
+	switch risType {
+	case "CPAPER", "CONF", "CHAP", "ECHAP":
+		setMeta("booktitle", container)
+	default:
+		setMeta("journal", container)
+	}
+	setMeta("volume", r.Get("VL"))
+	setMeta("issue", r.Get("IS"))
+	if start, end := r.Get("SP"), r.Get("EP"); start != "" && end != "" {
+		doc.Meta["pages"] = start + "-" + end
+	} else {
+		setMeta("pages", pageRangeDash(start))
+	}
+	switch risType {
+	case "THES":
+		setMeta("school", r.Get("PB"))
+	case "RPRT":
+		setMeta("institution", r.Get("PB"))
+	default:
+		setMeta("publisher", r.Get("PB"))
+	}
+	if sn := r.Get("SN"); sn != "" {
+		if risISSNPattern.MatchString(sn) {
+			doc.Meta["issn"] = sn
+		} else {
+			doc.Meta["isbn"] = sn
+		}
+	}
+
+	doi := strings.TrimPrefix(strings.TrimPrefix(r.Get("DO"), "https://doi.org/"), "doi:")
+	var arxiv string
+	for _, u := range r["UR"] {
+		if m := risArxivPattern.FindStringSubmatch(u); m != nil && arxiv == "" {
+			arxiv = m[1]
+		} else if _, ok := doc.Meta["url"]; !ok && u != "" {
+			doc.Meta["url"] = u
+		}
+	}
+	switch {
+	case arxiv != "":
+		doc.Source, doc.SourceID = IDSourceArxiv, arxiv
+		setMeta("doi", doi)
+	case doi != "":
+		doc.Source, doc.SourceID = IDSourceDOI, doi
+	}
+
+	if file := strings.TrimPrefix(r.Get("L1"), "file://"); file != "" && !strings.Contains(file, "://") {
+		doc.Path = file
+	}
+	return doc
+}
+
+// risName turns "Family, Given" around. Names with a suffix, as in
+// "King, Martin Luther, Jr.", are kept as written.
+func risName(name string) string {
+	name = strings.TrimSpace(name)
+	family, given, ok := strings.Cut(name, ",")
+	if !ok || strings.Contains(given, ",") || strings.TrimSpace(given) == "" {
+		return name
+	}
+	return strings.TrimSpace(given) + " " + strings.TrimSpace(family)
+}
+
+// pageRangeDash writes a start page given alone in SP, which EndNote may
+// fill with the whole range, with a plain hyphen.
+func pageRangeDash(pages string) string {
+	if start, end := pageBounds(pages); end != "" {
+		return start + "-" + end
+	}
+	return strings.TrimSpace(pages)
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRISExport(t *testing.T) {
+	docs := []*Document{
+		{ID: "d1", Type: DocTypePaper, Title: "Deep Residual Learning", Authors: []string{"Kaiming He"}, Source: IDSourceDOI, SourceID: "10.1109/CVPR.2016.90",
+			Meta: JSONMap{"year": float64(2016), "journal": "CVPR", "venue_type": "conference", "page": "770–778", "volume": "1", "issn": "1063-6919"}},
+		{ID: "d2", Type: DocTypePaper, Title: "On Learning", Authors: []string{"Ada Lovelace"}, Meta: JSONMap{"venue_type": "thesis", "school": "MIT", "isbn": "978-0-00-000000-2"}},
+		{ID: "d3", Type: DocTypeArticle, Title: "A Post", Meta: JSONMap{"url": "https://example.com/post"}, Abstract: "Line one.\nLine two."},
+		{ID: "d4", Type: DocTypeBook, Title: "SICP", Meta: JSONMap{"pages": 657, "isbn": "0262510871", "publisher": "MIT Press"}},
+		{ID: "d5", Type: DocTypePaper, Title: "Journal Paper", Meta: JSONMap{"journal": "Nature", "volume": "521", "issue": "7553", "pages": "436"}},
+	}
+	var buf bytes.Buffer
+	if err := LookupExporter("ris").Export(&buf, nil, docs, ExportOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	records := strings.Split(strings.TrimSpace(buf.String()), "\n\n")
+	if len(records) != len(docs) {
+		t.Fatalf("%d records, want %d:\n%s", len(records), len(docs), buf.String())
+	}
+	for i, want := range [][]string{
+		{"TY  - CPAPER", "PY  - 2016", "T2  - CVPR", "VL  - 1", "SP  - 770", "EP  - 778", "SN  - 1063-6919", "DO  - 10.1109/CVPR.2016.90"},
+		{"TY  - THES", "PB  - MIT", "SN  - 978-0-00-000000-2"},
+		{"TY  - ELEC", "UR  - https://example.com/post", "AB  - Line one. Line two."},
+		{"TY  - BOOK", "PB  - MIT Press", "SN  - 0262510871"},
+		{"TY  - JOUR", "T2  - Nature", "IS  - 7553", "SP  - 436"},
+	} {
+		for _, w := range want {
+			if !strings.Contains(records[i]+"\n", w+"\n") {
+				t.Errorf("record %d lacks %q:\n%s", i, w, records[i])
+			}
+		}
+	}
+	// A book's page count is not a page range, and no record has an EP
+	// without a range
+	if strings.Contains(records[3], "SP  -") || strings.Contains(records[4], "EP  -") {
+		t.Errorf("unexpected pages:\n%s\n\n%s", records[3], records[4])
+	}
+}
+
+func TestRISRoundTrip(t *testing.T) {
+	docs := []*Document{
+		{Type: DocTypePaper, Title: "Attention Is All You Need", Authors: []string{"Ashish Vaswani", "Noam Shazeer"}, Source: IDSourceArxiv, SourceID: "1706.03762",
+			Tags: []string{"ml", "nlp"}, Abstract: "The dominant sequence transduction models.", Meta: JSONMap{"year": 2017, "doi": "10.48550/arXiv.1706.03762"}},
+		{Type: DocTypePaper, Title: "A Chapter", Authors: []string{"Grace Hopper"}, Meta: JSONMap{"venue_type": "chapter", "booktitle": "Collected Works", "pages": "12-20", "isbn": "9780262510875", "year": 1990}},
+		{Type: DocTypeArticle, Title: "A Post", Meta: JSONMap{"url": "https://example.com/post"}},
+		{Type: DocTypeBook, Title: "SICP", Meta: JSONMap{"publisher": "MIT Press", "isbn": "0262510871"}},
+	}
+	var buf bytes.Buffer
+	if err := LookupExporter("ris").Export(&buf, nil, docs, ExportOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "library.ris")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imp := DetectImporter(path, info)
+	if imp == nil || imp.Format().Name != "ris" || !imp.Format().Has(FormatMetadata) {
+		t.Fatalf("importer for %s = %v", path, imp)
+	}
+	got, err := imp.Import(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(docs) {
+		t.Fatalf("imported %d documents, want %d", len(got), len(docs))
+	}
+	for i, want := range docs {
+		g := got[i]
+		if g.Type != want.Type || g.Title != want.Title || g.Source != want.Source || g.SourceID != want.SourceID || g.Abstract != want.Abstract ||
+			!slices.Equal(g.Authors, want.Authors) || !slices.Equal(g.Tags, want.Tags) {
+			t.Errorf("document %d = %+v, want %+v", i, g, want)
+		}
+		if !reflect.DeepEqual(g.Meta, want.Meta) {
+			t.Errorf("document %d meta = %v, want %v", i, g.Meta, want.Meta)
+		}
+	}
+}
+
+func TestParseRIS(t *testing.T) {
+	// As EndNote writes it: a byte order mark, CRLF line ends, inverted
+	// names, a wrapped abstract, and a record without ER at the end
+	const ris = "\ufeffTY  - JOUR\r\n" +
+		"T1  - Deep learning\r\n" +
+		"A1  - LeCun, Yann\r\n" +
+		"A1  - King, Martin Luther, Jr.\r\n" +
+		"Y1  - 2015/05/28/\r\n" +
+		"JF  - Nature\r\n" +
+		"SP  - 436-444\r\n" +
+		"SN  - 1476-4687\r\n" +
+		"N2  - Deep learning allows\r\n" +
+		"computational models.\r\n" +
+		"DO  - https://doi.org/10.1038/nature14539\r\n" +
+		"UR  - https://www.nature.com/articles/nature14539\r\n" +
+		"L1  - file:///papers/lecun.pdf\r\n" +
+		"ER  - \r\n" +
+		"\r\n" +
+		"TY  - WEB\r\n" +
+		"TI  - Notes\r\n"
+	records, err := ParseRIS(strings.NewReader(ris))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("%d records, want 2", len(records))
+	}
+	doc := records[0].Document()
+	if doc.Title != "Deep learning" || doc.Abstract != "Deep learning allows computational models." || doc.Path != "/papers/lecun.pdf" ||
+		!slices.Equal(doc.Authors, []string{"Yann LeCun", "King, Martin Luther, Jr."}) {
+		t.Errorf("document = %+v", doc)
+	}
+	if doc.Source != IDSourceDOI || doc.SourceID != "10.1038/nature14539" {
+		t.Errorf("source = %s %s", doc.Source, doc.SourceID)
+	}
+	want := JSONMap{"year": 2015, "journal": "Nature", "pages": "436-444", "issn": "1476-4687", "url": "https://www.nature.com/articles/nature14539"}
+	if !reflect.DeepEqual(doc.Meta, want) {
+		t.Errorf("meta = %v, want %v", doc.Meta, want)
+	}
+	if web := records[1].Document(); web.Type != DocTypeArticle || web.Title != "Notes" {
+		t.Errorf("web page = %+v", web)
+	}
+
+	if _, err := ParseRIS(strings.NewReader("TI  - No type\n")); err == nil {
+		t.Error("expected an error for a tag before TY")
+	}
+}
@@ -0,0 +1,502 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Section kinds recognized by DetectSections. Numbered headings with other
+// names are SectionOther; text before the first heading is SectionFront,
+// and text without any headings is a single SectionBody.
+const (
+	SectionFront           = "front"
+	SectionBody            = "body"
+	SectionAbstract        = "abstract"
+	SectionIntroduction    = "introduction"
+	SectionBackground      = "background"
+	SectionMethods         = "methods"
+	SectionResults         = "results"
+	SectionDiscussion      = "discussion"
+	SectionConclusion      = "conclusion"
+	SectionAcknowledgments = "acknowledgments"
+	SectionReferences      = "references"
+	SectionAppendix        = "appendix"
+	SectionOther           = "other"
+)
+
+// ChunkSize is the target length in bytes of the chunks sections are split
+// into; a paragraph longer than twice this is split between sentences.
+const ChunkSize = 1500
+
+// sectionNames maps heading names, lowercased and without numbering, to
+// section kinds.
+var sectionNames = map[string]string{
+	"abstract":                    SectionAbstract,
+	"summary":                     SectionAbstract,
+	"introduction":                SectionIntroduction,
+	"background":                  SectionBackground,
+	"related work":                SectionBackground,
+	"related works":               SectionBackground,
+	"prior work":                  SectionBackground,
+	"literature review":           SectionBackground,
+	"preliminaries":               SectionBackground,
+	"background and related work": SectionBackground,
+	"method":                      SectionMethods,
+	"methods":                     SectionMethods,
+	"methodology":                 SectionMethods,
+	"materials and methods":       SectionMethods,
+	"methods and materials":       SectionMethods,
+	"approach":                    SectionMethods,
+	"our approach":                SectionMethods,
+	"experimental setup":          SectionMethods,
+	"experiments":                 SectionResults,
+	"results":                     SectionResults,
+	"experimental results":        SectionResults,
+	"evaluation":                  SectionResults,
+	"results and discussion":      SectionResults,
+	"discussion":                  SectionDiscussion,
+	"limitations":                 SectionDiscussion,
+	"conclusion":                  SectionConclusion,
+	"conclusions":                 SectionConclusion,
+	"concluding remarks":          SectionConclusion,
+	"conclusion and future work":  SectionConclusion,
+	"conclusions and future work": SectionConclusion,
+	"summary and conclusions":     SectionConclusion,
+	"acknowledgments":             SectionAcknowledgments,
+	"acknowledgements":            SectionAcknowledgments,
+	"acknowledgment":              SectionAcknowledgments,
+	"acknowledgement":             SectionAcknowledgments,
+	"references":                  SectionReferences,
+	"bibliography":                SectionReferences,
+	"works cited":                 SectionReferences,
+	"literature cited":            SectionReferences,
+	"appendix":                    SectionAppendix,
+	"appendices":                  SectionAppendix,
+	"supplementary material":      SectionAppendix,
+	"supplementary information":   SectionAppendix,
+	"supplementary materials":     SectionAppendix,
+}
+
+var (
+	// headingPattern splits a candidate heading line into its numbering
+	// (arabic or roman, top level only) and name.
+	headingPattern = regexp.MustCompile(`^(?:(\d{1,2}|[IVX]{1,4})\.?\s+)?([A-Za-z][A-Za-z &,:'-]{1,60}?)\.?$`)
+	// inlineAbstract matches an abstract label running into its text, as in
+	// "Abstract—We study..." or "ABSTRACT: We study...".
+	inlineAbstract = regexp.MustCompile(`^(?i:abstract)\s*[.:—–-]\s*\S`)
+	// appendixPattern matches lettered appendices: "Appendix A", "A Proofs".
+	appendixPattern = regexp.MustCompile(`^(?i:appendix)(?:\s+[A-Z](?:[.:]?\s.*)?)?$`)
+)
+
+// Section is a span of a document's full text under one heading. Start and
+// End are byte offsets into the text, Start at the heading.
+type Section struct {
+	Kind    string `json:"kind"`
+	Heading string `json:"heading,omitempty"` // as written, without numbering
+	Start   int    `json:"start"`
+	End     int    `json:"end"`
+}
+
+// TextChunk is a run of whole paragraphs within one section, sized for
+// selecting AI context. Section indexes DocumentSections.Sections.
+type TextChunk struct {
+	Section int `json:"section"`
+	Start   int `json:"start"`
+	End     int `json:"end"`
+}
+
+// DocumentSections is the section structure of a document's full text.
+// TextHash identifies the text it was computed from, so stale structures
+// can be recomputed after the text changes.
+type DocumentSections struct {
+	DocumentID string      `json:"document_id"`
+	TextHash   uint64      `json:"text_hash"`
+	Sections   []Section   `json:"sections"`
+	Chunks     []TextChunk `json:"chunks"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+}
+
+// DetectSections splits text into sections at the headings it recognizes:
+// lines naming a known section ("2. Related Work", "METHODS", "Abstract—")
+// and numbered top-level headings whose numbers follow on from the
+// previous one. After the references only known headings count, so
+// numbered reference entries aren't taken for sections.
+func DetectSections(text string) []Section {
+	var sections []Section
+	lastNumber := 0
+	inReferences := false
+
+	offset := 0
+	for _, line := range strings.SplitAfter(text, "\n") {
+		start := offset
+		offset += len(line)
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+
+		kind, heading := "", ""
+		if inlineAbstract.MatchString(trimmed) && len(sections) == 0 {
+			kind, heading = SectionAbstract, trimmed[:len("abstract")]
+		} else if len(trimmed) > 80 {
+			continue
+		} else if appendixPattern.MatchString(trimmed) {
+			kind, heading = SectionAppendix, trimmed
+		} else if m := headingPattern.FindStringSubmatch(trimmed); m != nil {
+			name := strings.TrimSpace(m[2])
+			number := headingNumber(m[1])
+			if k, ok := sectionNames[strings.ToLower(name)]; ok && (number > 0 || !startsLowercase(name)) {
+				kind, heading = k, name
+			} else if number > 0 && !inReferences && number >= lastNumber && number <= lastNumber+2 &&
+				looksLikeHeading(name) {
+				kind, heading = SectionOther, name
+			}
+			if kind != "" && number > 0 {
+				lastNumber = number
+			}
+		}
+		if kind == "" {
+			continue
+		}
+		inReferences = kind == SectionReferences || (inReferences && kind != SectionAppendix)
+
+		if len(sections) == 0 && strings.TrimSpace(text[:start]) != "" {
+			sections = append(sections, Section{Kind: SectionFront, Start: 0})
+		}
+		if n := len(sections); n > 0 {
+			sections[n-1].End = start
+		}
+		sections = append(sections, Section{Kind: kind, Heading: heading, Start: start})
+	}
+
+	if len(sections) == 0 {
+		if strings.TrimSpace(text) == "" {
+			return nil
+		}
+		return []Section{{Kind: SectionBody, Start: 0, End: len(text)}}
+	}
+	sections[len(sections)-1].End = len(text)
+	return sections
+}
+
+// headingNumber returns the value of an arabic or roman section number, or
+// 0 for none.
+func headingNumber(s string) int {
+	if s == "" {
+		return 0
+	}
+	if s[0] >= '0' && s[0] <= '9' {
+		n := 0
+		fmt.Sscan(s, &n)
+		return n
+	}
+	values := map[byte]int{'I': 1, 'V': 5, 'X': 10}
+	n := 0
+	for i := 0; i < len(s); i++ {
+		v := values[s[i]]
+		if i+1 < len(s) && values[s[i+1]] > v {
+			n -= v
+		} else {
+			n += v
+		}
+	}
+	return n
+}
+
+func startsLowercase(s string) bool {
+	for _, r := range s {
+		return unicode.IsLower(r)
+	}
+	return false
+}
+
+// looksLikeHeading reports whether the name of a numbered line reads like
+// a section title rather than a numbered sentence or list item: a few
+// words, capitalized, not ending in punctuation.
+func looksLikeHeading(name string) bool {
+	words := strings.Fields(name)
+	if len(words) == 0 || len(words) > 8 || startsLowercase(name) {
+		return false
+	}
+	return !strings.ContainsAny(name[len(name)-1:], ",:;-")
+}
+
+// ChunkSections splits each section of text into chunks of whole
+// paragraphs of about ChunkSize bytes. Chunks never span sections.
+func ChunkSections(text string, sections []Section) []TextChunk {
+	var chunks []TextChunk
+	for i, sec := range sections {
+		chunkStart, chunkEnd := -1, -1
+		flush := func() {
+			if chunkStart >= 0 {
+				chunks = append(chunks, TextChunk{Section: i, Start: chunkStart, End: chunkEnd})
+				chunkStart = -1
+			}
+		}
+		for _, p := range paragraphs(text, sec.Start, sec.End) {
+			if p[1]-p[0] > 2*ChunkSize {
+				parts := splitSentences(text, p[0], p[1])
+				// Keep a heading or short paragraph with the text that follows
+				if chunkStart >= 0 && parts[0][0]-chunkStart < ChunkSize {
+					parts[0][0] = chunkStart
+					chunkStart = -1
+				}
+				flush()
+				for _, part := range parts {
+					chunks = append(chunks, TextChunk{Section: i, Start: part[0], End: part[1]})
+				}
+				continue
+			}
+			if chunkStart >= 0 && p[1]-chunkStart > ChunkSize {
+				flush()
+			}
+			if chunkStart < 0 {
+				chunkStart = p[0]
+			}
+			chunkEnd = p[1]
+		}
+		flush()
+	}
+	return chunks
+}
+
+// paragraphs returns the [start, end) spans of the blank-line separated
+// paragraphs of text[start:end], trimmed of surrounding whitespace.
+func paragraphs(text string, start, end int) [][2]int {
+	var spans [][2]int
+	pStart := -1
+	lineStart := start
+	for lineStart < end {
+		lineEnd := strings.IndexByte(text[lineStart:end], '\n')
+		if lineEnd < 0 {
+			lineEnd = end
+		} else {
+			lineEnd += lineStart + 1
+		}
+		blank := strings.TrimSpace(text[lineStart:lineEnd]) == ""
+		if blank && pStart >= 0 {
+			spans = append(spans, trimSpan(text, pStart, lineStart))
+			pStart = -1
+		} else if !blank && pStart < 0 {
+			pStart = lineStart
+		}
+		lineStart = lineEnd
+	}
+	if pStart >= 0 {
+		spans = append(spans, trimSpan(text, pStart, end))
+	}
+	return spans
+}
+
+func trimSpan(text string, start, end int) [2]int {
+	for start < end && unicode.IsSpace(rune(text[start])) {
+		start++
+	}
+	for end > start && unicode.IsSpace(rune(text[end-1])) {
+		end--
+	}
+	return [2]int{start, end}
+}
+
+// splitSentences splits text[start:end] into parts of about ChunkSize
+// bytes, breaking after sentence ends or, failing that, at spaces.
+func splitSentences(text string, start, end int) [][2]int {
+	var parts [][2]int
+	for end-start > ChunkSize {
+		window := text[start : start+ChunkSize]
+		cut := strings.LastIndex(window, ". ")
+		if cut < ChunkSize/2 {
+			cut = strings.LastIndexAny(window, " \n")
+		}
+		if cut <= 0 {
+			cut = ChunkSize - 1
+			// Don't cut a UTF-8 sequence in half
+			for cut > 0 && text[start+cut+1]&0xC0 == 0x80 {
+				cut--
+			}
+		}
+		parts = append(parts, trimSpan(text, start, start+cut+1))
+		start += cut + 1
+	}
+	if span := trimSpan(text, start, end); span[1] > span[0] {
+		parts = append(parts, span)
+	}
+	return parts
+}
+
+// ComputeDocumentSections detects the sections of a document's full text
+// and chunks them.
+func ComputeDocumentSections(doc *Document) *DocumentSections {
+	sections := DetectSections(doc.FullText)
+	return &DocumentSections{
+		DocumentID: doc.ID,
+		TextHash:   HashText(doc.FullText),
+		Sections:   sections,
+		Chunks:     ChunkSections(doc.FullText, sections),
+		UpdatedAt:  time.Now(),
+	}
+}
+
+// LoadDocumentSections returns the sections of doc's full text, reusing
+// the stored ones while the text is unchanged and computing and saving
+// them otherwise.
+func LoadDocumentSections(s LibraryStore, doc *Document) (*DocumentSections, error) {
+	secs, err := s.GetDocumentSections(doc.ID)
+	if err != nil {
+		return nil, err
+	}
+	if secs != nil && secs.TextHash == HashText(doc.FullText) {
+		return secs, nil
+	}
+	secs = ComputeDocumentSections(doc)
+	if err := s.SaveDocumentSections(secs); err != nil {
+		return nil, err
+	}
+	return secs, nil
+}
+
+// Find returns the first section of the given kind, or nil. Kinds also
+// match headings case-insensitively, so "Attention" finds an "other"
+// section headed "Attention".
+func (d *DocumentSections) Find(kind string) *Section {
+	for i := range d.Sections {
+		if d.Sections[i].Kind == kind {
+			return &d.Sections[i]
+		}
+	}
+	for i := range d.Sections {
+		if strings.EqualFold(d.Sections[i].Heading, kind) {
+			return &d.Sections[i]
+		}
+	}
+	return nil
+}
+
+// SectionContains reports whether every word of query occurs in the
+// section's text, ignoring case.
+func SectionContains(text string, sec *Section, query string) bool {
+	body := strings.ToLower(text[sec.Start:sec.End])
+	for _, w := range strings.Fields(strings.ToLower(query)) {
+		if !strings.Contains(body, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// contextPriority orders section kinds by how much they say about a
+// document as a whole; kinds missing here are left out of context.
+var contextPriority = map[string]int{
+	SectionAbstract:     0,
+	SectionIntroduction: 1,
+	SectionConclusion:   2,
+	SectionBody:         3,
+	SectionResults:      3,
+	SectionDiscussion:   3,
+	SectionMethods:      4,
+	SectionBackground:   5,
+	SectionOther:        5,
+	SectionFront:        6,
+	SectionAppendix:     7,
+}
+
+// SelectContext picks the parts of text that fit into budget bytes for an
+// AI prompt, in document order and labeled with their section headings.
+// With a query, chunks sharing the most words with it are chosen first;
+// without one, whole sections in order of contextPriority (abstract,
+// introduction, conclusion, ...). References and acknowledgments are never
+// included. A non-empty only restricts the choice to that section, as
+// named for Find.
+func SelectContext(text string, secs *DocumentSections, query, only string, budget int) string {
+	type candidate struct {
+		chunk TextChunk
+		score float64
+	}
+	var allowed func(sec Section) bool
+	if only != "" {
+		target := secs.Find(only)
+		allowed = func(sec Section) bool { return target != nil && sec == *target }
+	} else {
+		allowed = func(sec Section) bool {
+			_, ok := contextPriority[sec.Kind]
+			return ok
+		}
+	}
+
+	terms := map[string]bool{}
+	for _, w := range contextWords(query) {
+		terms[w] = true
+	}
+	var cands []candidate
+	for i, c := range secs.Chunks {
+		sec := secs.Sections[c.Section]
+		if !allowed(sec) {
+			continue
+		}
+		score := -float64(contextPriority[sec.Kind]) - float64(i)/float64(len(secs.Chunks)+1)
+		if len(terms) > 0 {
+			hits := 0
+			for _, w := range contextWords(text[c.Start:c.End]) {
+				if terms[w] {
+					hits++
+				}
+			}
+			score += float64(hits) * 10
+		}
+		cands = append(cands, candidate{c, score})
+	}
+	sort.SliceStable(cands, func(i, j int) bool { return cands[i].score > cands[j].score })
+
+	var picked []TextChunk
+	used := 0
+	for _, c := range cands {
+		n := c.chunk.End - c.chunk.Start
+		if used+n > budget {
+			continue
+		}
+		picked = append(picked, c.chunk)
+		used += n
+	}
+	sort.Slice(picked, func(i, j int) bool { return picked[i].Start < picked[j].Start })
+
+	var b strings.Builder
+	lastSection := -1
+	for _, c := range picked {
+		if c.Section != lastSection {
+			sec := secs.Sections[c.Section]
+			label := sec.Heading
+			if label == "" {
+				label = sec.Kind
+			}
+			fmt.Fprintf(&b, "\n[%s]\n", label)
+			lastSection = c.Section
+		} else {
+			b.WriteString("\n")
+		}
+		b.WriteString(text[c.Start:c.End])
+		b.WriteString("\n")
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// contextWords returns the lowercased words of s of four letters or more,
+// enough to match a question against text without stop words.
+func contextWords(s string) []string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	out := words[:0]
+	for _, w := range words {
+		if len(w) >= 4 {
+			out = append(out, w)
+		}
+	}
+	return out
+}
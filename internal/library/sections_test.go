@@ -0,0 +1,180 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+const samplePaper = `Attention Is All You Need
+Ashish Vaswani, Noam Shazeer
+
+Abstract—The dominant sequence transduction models are based on recurrent networks.
+
+1 Introduction
+
+Recurrent neural networks have been firmly established.
+We propose the Transformer.
+
+2 Background
+
+The goal of reducing sequential computation.
+
+3 Model Architecture
+
+Most competitive neural sequence transduction models have an encoder-decoder structure.
+
+3.1 Encoder and Decoder Stacks
+
+The encoder is composed of a stack of identical layers.
+
+4 Why Self-Attention
+
+Self-attention layers connect all positions with a constant number of operations.
+
+5 Results
+
+The big transformer model outperforms the best previously reported models.
+
+6 Conclusion
+
+In this work, we presented the Transformer.
+
+References
+
+1 Jimmy Lei Ba, Jamie Ryan Kiros. Layer normalization.
+2 Dzmitry Bahdanau. Neural machine translation.
+12 Sepp Hochreiter. Long Short-Term Memory
+
+Appendix A Attention Visualizations
+
+Many of the attention heads exhibit behaviour.
+`
+
+func TestDetectSections(t *testing.T) {
+	sections := DetectSections(samplePaper)
+	var got []string
+	for _, s := range sections {
+		got = append(got, s.Kind+":"+s.Heading)
+	}
+	want := []string{
+		"front:", "abstract:Abstract", "introduction:Introduction", "background:Background",
+		"other:Model Architecture", "other:Why Self-Attention", "results:Results",
+		"conclusion:Conclusion", "references:References", "appendix:Appendix A Attention Visualizations",
+	}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("sections =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	for i, s := range sections {
+		if i > 0 && s.Start != sections[i-1].End {
+			t.Errorf("section %d starts at %d, previous ends at %d", i, s.Start, sections[i-1].End)
+		}
+	}
+	if last := sections[len(sections)-1]; last.End != len(samplePaper) {
+		t.Errorf("last section ends at %d of %d", last.End, len(samplePaper))
+	}
+	if intro := samplePaper[sections[2].Start:sections[2].End]; !strings.Contains(intro, "We propose the Transformer.") || strings.Contains(intro, "sequential") {
+		t.Errorf("introduction = %q", intro)
+	}
+
+	if s := DetectSections("Just a note without headings.\n"); len(s) != 1 || s[0].Kind != SectionBody {
+		t.Errorf("unstructured text = %+v", s)
+	}
+	if s := DetectSections("  \n"); s != nil {
+		t.Errorf("blank text = %+v", s)
+	}
+}
+
+func TestChunkSections(t *testing.T) {
+	para := strings.Repeat("word ", 100) // 500 bytes
+	long := strings.Repeat("A sentence that goes on. ", 200)
+	text := "Introduction\n\n" + para + "\n\n" + para + "\n\n" + para + "\n\n" + para + "\n\nMethods\n\n" + long + "\n"
+	sections := DetectSections(text)
+	chunks := ChunkSections(text, sections)
+
+	var intro, methods int
+	for _, c := range chunks {
+		if c.End-c.Start > 2*ChunkSize {
+			t.Errorf("chunk of %d bytes", c.End-c.Start)
+		}
+		sec := sections[c.Section]
+		if c.Start < sec.Start || c.End > sec.End {
+			t.Errorf("chunk %+v outside its section %+v", c, sec)
+		}
+		switch sec.Kind {
+		case SectionIntroduction:
+			intro++
+		case SectionMethods:
+			methods++
+			if !strings.HasSuffix(text[c.Start:c.End], ".") {
+				t.Errorf("methods chunk not split at a sentence end: %q", text[c.End-20:c.End])
+			}
+		}
+	}
+	// The heading paragraph and four 500-byte paragraphs, 1500 bytes a chunk
+	if intro != 2 || methods < 3 {
+		t.Errorf("%d introduction and %d methods chunks", intro, methods)
+	}
+}
+
+func TestSelectContext(t *testing.T) {
+	doc := &Document{ID: "d", FullText: samplePaper}
+	secs := ComputeDocumentSections(doc)
+
+	ctx := SelectContext(samplePaper, secs, "", "", 300)
+	if !strings.Contains(ctx, "[Abstract]") || !strings.Contains(ctx, "[Introduction]") || strings.Contains(ctx, "Hochreiter") {
+		t.Errorf("summary context = %q", ctx)
+	}
+	if strings.Index(ctx, "[Abstract]") > strings.Index(ctx, "[Introduction]") {
+		t.Errorf("context out of document order: %q", ctx)
+	}
+
+	ctx = SelectContext(samplePaper, secs, "How many operations does self-attention need?", "", 150)
+	if !strings.Contains(ctx, "constant number of operations") {
+		t.Errorf("question context = %q", ctx)
+	}
+
+	ctx = SelectContext(samplePaper, secs, "", "results", 10000)
+	if !strings.HasPrefix(ctx, "[Results]") || strings.Contains(ctx, "Conclusion") {
+		t.Errorf("results-only context = %q", ctx)
+	}
+}
+
+func TestLoadDocumentSections(t *testing.T) {
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := &Document{Title: "T", FullText: samplePaper}
+	if err := s.AddDocument(doc); err != nil {
+		t.Fatal(err)
+	}
+
+	secs, err := LoadDocumentSections(s, doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored, _ := s.GetDocumentSections(doc.ID); stored == nil || len(stored.Sections) != len(secs.Sections) || len(stored.Chunks) == 0 {
+		t.Fatalf("stored sections = %+v", stored)
+	}
+
+	doc.FullText = "Introduction\n\nRewritten.\n"
+	secs, err = LoadDocumentSections(s, doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(secs.Sections) != 1 || secs.Find(SectionIntroduction) == nil {
+		t.Errorf("stale sections not recomputed: %+v", secs.Sections)
+	}
+
+	if err := s.DeleteDocument(doc.ID); err != nil {
+		t.Fatal(err)
+	}
+	if stored, _ := s.GetDocumentSections(doc.ID); stored != nil {
+		t.Error("sections kept after the document was deleted")
+	}
+}
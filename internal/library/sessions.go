@@ -0,0 +1,156 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// FindSession returns a reading session by ID. Sessions are stored per
+// document, so this looks through every document's; it returns an error
+// if there is no such session.
+func FindSession(s LibraryStore, id string) (*ReadingSession, error) {
+	docs, err := s.ListDocuments(nil)
+	if err != nil {
+		return nil, fmt.Errorf("list documents: %w", err)
+	}
+	for _, d := range docs {
+		sessions, err := s.ListSessions(d.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, sess := range sessions {
+			if sess.ID == id {
+				return sess, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("session not found: %s", id)
+}
+
+// LinkOpenSession sets an annotation's SessionID to the reading session
+// open on its document, the most recently started if there are several.
+// An annotation that already names a session, or whose document has none
+// open, is left alone.
+func LinkOpenSession(s LibraryStore, ann *Annotation) error {
+	if ann.SessionID != "" {
+		return nil
+	}
+	sessions, err := s.ListSessions(ann.DocumentID)
+	if err != nil {
+		return err
+	}
+	var open *ReadingSession
+	for _, sess := range sessions {
+		if sess.EndAt.IsZero() && (open == nil || sess.StartAt.After(open.StartAt)) {
+			open = sess
+		}
+	}
+	if open != nil {
+		ann.SessionID = open.ID
+	}
+	return nil
+}
+
+// SessionAnnotations returns the annotations made during a reading
+// session, oldest first: those linked to it, and those linked to no
+// session but made on its document between its start and end. A session
+// still open counts as ending now.
+func SessionAnnotations(s LibraryStore, session *ReadingSession) ([]*Annotation, error) {
+	anns, err := s.GetAnnotations(session.DocumentID)
+	if err != nil {
+		return nil, err
+	}
+	end := session.EndAt
+	if end.IsZero() {
+		end = time.Now()
+	}
+	var made []*Annotation
+	for _, a := range anns {
+		if a.SessionID == session.ID ||
+			a.SessionID == "" && !a.CreatedAt.Before(session.StartAt) && !a.CreatedAt.After(end) {
+			made = append(made, a)
+		}
+	}
+	slices.SortStableFunc(made, func(a, b *Annotation) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return made, nil
+}
+
+// SessionSummary is a reading session with what was annotated during it.
+type SessionSummary struct {
+	Session     *ReadingSession `json:"session"`
+	Title       string          `json:"title"`
+	Minutes     int             `json:"minutes"` // 0 for a session still open
+	Annotations []*Annotation   `json:"annotations"`
+	Types       map[string]int  `json:"types"` // annotations by type
+}
+
+// SummarizeSession gathers a reading session's summary: its document's
+// title, how long it lasted, and its annotations (see SessionAnnotations).
+func SummarizeSession(s LibraryStore, id string) (*SessionSummary, error) {
+	session, err := FindSession(s, id)
+	if err != nil {
+		return nil, err
+	}
+	anns, err := SessionAnnotations(s, session)
+	if err != nil {
+		return nil, err
+	}
+	sum := &SessionSummary{Session: session, Annotations: anns, Types: map[string]int{}}
+	if doc, err := s.GetDocument(session.DocumentID); err == nil && doc != nil {
+		sum.Title = doc.Title
+	}
+	if !session.EndAt.IsZero() {
+		sum.Minutes = int(session.EndAt.Sub(session.StartAt).Round(time.Minute) / time.Minute)
+	}
+	for _, a := range anns {
+		sum.Types[a.Type]++
+	}
+	return sum, nil
+}
+
+// Markdown renders the summary for a note app: a heading with the
+// document and date, the session's details, and its annotations in the
+// order they were made.
+func (sum *SessionSummary) Markdown() string {
+	rs := sum.Session
+	title := sum.Title
+	if title == "" {
+		title = rs.DocumentID
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s, %s\n\n", title, rs.StartAt.Format("2006-01-02 15:04"))
+	var details []string
+	if sum.Minutes > 0 {
+		details = append(details, fmt.Sprintf("%d min", sum.Minutes))
+	} else if rs.EndAt.IsZero() {
+		details = append(details, "in progress")
+	}
+	if rs.PagesRead > 0 {
+		details = append(details, fmt.Sprintf("%d pages", rs.PagesRead))
+	}
+	details = append(details, fmt.Sprintf("%d annotation(s)", len(sum.Annotations)))
+	b.WriteString(strings.Join(details, " · ") + "\n")
+	if rs.Notes != "" {
+		fmt.Fprintf(&b, "\n%s\n", strings.TrimSpace(rs.Notes))
+	}
+	if len(sum.Annotations) > 0 {
+		b.WriteString("\n### Annotations\n\n")
+		for _, a := range sum.Annotations {
+			fmt.Fprintf(&b, "- %s [%s]", a.CreatedAt.Format("15:04"), a.Type)
+			if a.Page > 0 {
+				fmt.Fprintf(&b, " p. %d", a.Page)
+			}
+			if a.Content != "" {
+				fmt.Fprintf(&b, ": %s", oneLine(a.Content))
+			}
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+func TestSessionAnnotations(t *testing.T) {
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := &Document{Title: "Attention"}
+	if err := s.AddDocument(doc); err != nil {
+		t.Fatal(err)
+	}
+
+	// No session open: the annotation stays unlinked
+	before := &Annotation{DocumentID: doc.ID, Type: "note", Content: "before", CreatedAt: time.Now().Add(-time.Hour)}
+	if err := LinkOpenSession(s, before); err != nil || before.SessionID != "" {
+		t.Fatalf("no open session: linked to %q, %v", before.SessionID, err)
+	}
+	if err := s.AddAnnotation(before); err != nil {
+		t.Fatal(err)
+	}
+
+	session, err := s.StartSession(doc.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	linked := &Annotation{DocumentID: doc.ID, Type: "highlight", Content: "linked"}
+	if err := LinkOpenSession(s, linked); err != nil || linked.SessionID != session.ID {
+		t.Fatalf("open session: linked to %q, %v; want %q", linked.SessionID, err, session.ID)
+	}
+	if err := s.AddAnnotation(linked); err != nil {
+		t.Fatal(err)
+	}
+	// Made during the session by something that does not link, such as an import
+	during := &Annotation{DocumentID: doc.ID, Type: "note", Content: "during", CreatedAt: time.Now()}
+	if err := s.AddAnnotation(during); err != nil {
+		t.Fatal(err)
+	}
+	// Linked elsewhere, so not the session's even though made in its window
+	other := &Annotation{DocumentID: doc.ID, Type: "note", Content: "other", SessionID: "session:other", CreatedAt: time.Now()}
+	if err := LinkOpenSession(s, other); err != nil || other.SessionID != "session:other" {
+		t.Fatalf("already linked: relinked to %q, %v", other.SessionID, err)
+	}
+	if err := s.AddAnnotation(other); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.EndSession(session.ID, 3, ""); err != nil {
+		t.Fatal(err)
+	}
+	found, err := FindSession(s, session.ID)
+	if err != nil || found.EndAt.IsZero() {
+		t.Fatalf("FindSession = %+v, %v", found, err)
+	}
+	if _, err := FindSession(s, "session:missing"); err == nil {
+		t.Error("missing session: no error")
+	}
+
+	anns, err := SessionAnnotations(s, found)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, a := range anns {
+		got = append(got, a.Content)
+	}
+	if len(got) != 2 || got[0] != "linked" || got[1] != "during" {
+		t.Errorf("SessionAnnotations = %v, want [linked during]", got)
+	}
+
+	// The link survives the store
+	stored, err := s.GetAnnotations(doc.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range stored {
+		if a.ID == linked.ID && a.SessionID != session.ID {
+			t.Errorf("stored session_id = %q, want %q", a.SessionID, session.ID)
+		}
+	}
+}
+
+func TestSummarizeSession(t *testing.T) {
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := &Document{Title: "Attention Is All You Need"}
+	if err := s.AddDocument(doc); err != nil {
+		t.Fatal(err)
+	}
+	session, err := s.StartSession(doc.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range []*Annotation{
+		{DocumentID: doc.ID, Type: "highlight", Content: "Self-attention\nrelates positions", Page: 2},
+		{DocumentID: doc.ID, Type: "highlight", Content: "Multi-head attention"},
+		{DocumentID: doc.ID, Type: "note", Content: "Compare with RNNs"},
+	} {
+		if err := LinkOpenSession(s, a); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.AddAnnotation(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sum, err := SummarizeSession(s, session.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum.Title != doc.Title || sum.Minutes != 0 || len(sum.Annotations) != 3 ||
+		sum.Types["highlight"] != 2 || sum.Types["note"] != 1 {
+		t.Errorf("summary = %+v", sum)
+	}
+	md := sum.Markdown()
+	for _, want := range []string{
+		"## Attention Is All You Need, ",
+		"in progress · 3 annotation(s)",
+		"[highlight] p. 2: Self-attention relates positions\n",
+		"[note]: Compare with RNNs\n",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown missing %q:\n%s", want, md)
+		}
+	}
+
+	if _, err := SummarizeSession(s, "session:missing"); err == nil {
+		t.Error("missing session: no error")
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultShareTTL is how long a share link works unless told otherwise.
+const DefaultShareTTL = 7 * 24 * time.Hour
+
+// Share is a link that gives access to one document's page, and
+// optionally its file, to whoever has the token, until it expires.
+type Share struct {
+	Token       string    `json:"token"`
+	DocumentID  string    `json:"document_id"`
+	IncludeFile bool      `json:"include_file"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// NewShare returns a share of a document valid for ttl, with a random
+// token.
+func NewShare(documentID string, ttl time.Duration, includeFile bool) (*Share, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("generate share token: %w", err)
+	}
+	now := time.Now()
+	return &Share{
+		Token:       hex.EncodeToString(b),
+		DocumentID:  documentID,
+		IncludeFile: includeFile,
+		ExpiresAt:   now.Add(ttl),
+		CreatedAt:   now,
+	}, nil
+}
+
+// Expired reports whether the share no longer works at now.
+func (sh *Share) Expired(now time.Time) bool {
+	return !now.Before(sh.ExpiresAt)
+}
+
+// ParseShareTTL parses a share lifetime: a Go duration such as 12h, or a
+// number of days or weeks such as 7d or 2w.
+func ParseShareTTL(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	var d time.Duration
+	var err error
+	switch {
+	case strings.HasSuffix(s, "d") || strings.HasSuffix(s, "w"):
+		n, convErr := strconv.Atoi(s[:len(s)-1])
+		err = convErr
+		d = time.Duration(n) * 24 * time.Hour
+		if strings.HasSuffix(s, "w") {
+			d *= 7
+		}
+	default:
+		d, err = time.ParseDuration(s)
+	}
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid TTL %q (use e.g. 12h, 7d, or 2w)", s)
+	}
+	return d, nil
+}
+
+// GetValidShare returns the share with token if it exists and has not
+// expired, or nil.
+func GetValidShare(s LibraryStore, token string, now time.Time) (*Share, error) {
+	if token == "" {
+		return nil, nil
+	}
+	sh, err := s.GetShare(token)
+	if err != nil || sh == nil || sh.Expired(now) {
+		return nil, err
+	}
+	return sh, nil
+}
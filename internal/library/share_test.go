@@ -0,0 +1,86 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+func TestParseShareTTL(t *testing.T) {
+	for in, want := range map[string]time.Duration{
+		"7d":  7 * 24 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+		"36h": 36 * time.Hour,
+	} {
+		if got, err := ParseShareTTL(in); err != nil || got != want {
+			t.Errorf("ParseShareTTL(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "d", "0d", "-1h", "soon"} {
+		if _, err := ParseShareTTL(in); err == nil {
+			t.Errorf("ParseShareTTL(%q): expected an error", in)
+		}
+	}
+}
+
+func TestShares(t *testing.T) {
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := &Document{Title: "Shared"}
+	other := &Document{Title: "Other"}
+	for _, d := range []*Document{doc, other} {
+		if err := s.AddDocument(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	live, err := NewShare(doc.ID, time.Hour, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old, _ := NewShare(doc.ID, time.Hour, false)
+	old.ExpiresAt = time.Now().Add(-time.Minute)
+	keep, _ := NewShare(other.ID, time.Hour, false)
+	for _, sh := range []*Share{live, old, keep} {
+		if err := s.CreateShare(sh); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(live.Token) != 32 || live.Token == old.Token {
+		t.Errorf("tokens %q, %q", live.Token, old.Token)
+	}
+
+	now := time.Now()
+	if sh, err := GetValidShare(s, live.Token, now); err != nil || sh == nil || !sh.IncludeFile {
+		t.Errorf("live share = %+v, %v", sh, err)
+	}
+	if sh, _ := GetValidShare(s, old.Token, now); sh != nil {
+		t.Error("an expired share is still valid")
+	}
+	if sh, _ := GetValidShare(s, "", now); sh != nil {
+		t.Error("an empty token is valid")
+	}
+	if sh, _ := GetValidShare(s, live.Token, live.ExpiresAt); sh != nil {
+		t.Error("a share is valid at its expiry time")
+	}
+
+	if err := s.DeleteShare(keep.Token); err != nil {
+		t.Fatal(err)
+	}
+	if shares, _ := s.ListShares(); len(shares) != 2 || shares[0].Token != old.Token {
+		t.Errorf("shares after revoke = %+v", shares)
+	}
+	// Deleting a document drops its shares
+	if err := s.DeleteDocument(doc.ID); err != nil {
+		t.Fatal(err)
+	}
+	if shares, _ := s.ListShares(); len(shares) != 0 {
+		t.Errorf("shares after delete = %+v", shares)
+	}
+}
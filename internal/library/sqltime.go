@@ -0,0 +1,67 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"database/sql/driver"
+	"strings"
+	"time"
+
+	"modernc.org/sqlite"
+)
+
+// The SQL driver stores a time.Time as its String form, in whatever zone
+// it was in, such as "2025-03-01 09:00:00 -0800 PST". Those strings don't
+// sort in time order across zones, so queries compare times through
+// library_unix_micro, which turns the stored string into microseconds
+// since the epoch, and bind the UnixMicro of the time they compare with.
+// Unlike UnixNano, it is defined for the zero time unset times are stored
+// as.
+
+func init() {
+	sqlite.MustRegisterDeterministicScalarFunction("library_unix_micro", 1, func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		var s string
+		switch v := args[0].(type) {
+		case string:
+			s = v
+		case []byte:
+			s = string(v)
+		case time.Time:
+			return v.UnixMicro(), nil
+		default:
+			return nil, nil
+		}
+		t, ok := parseStoredTime(s)
+		if !ok {
+			return nil, nil
+		}
+		return t.UnixMicro(), nil
+	})
+}
+
+// storedTimeLayouts are the forms times are stored in: the driver's, and
+// the usual SQL and RFC 3339 ones for rows written by other tools.
+var storedTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999 -0700 MST",
+	"2006-01-02 15:04:05.999999999 -0700",
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999-07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// parseStoredTime parses a time as the SQL store keeps it.
+func parseStoredTime(s string) (time.Time, bool) {
+	// time.Now's String carries its monotonic clock reading
+	if i := strings.Index(s, " m="); i >= 0 {
+		s = s[:i]
+	}
+	s = strings.TrimSpace(s)
+	for _, layout := range storedTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
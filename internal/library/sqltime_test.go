@@ -0,0 +1,79 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSQLTimeFiltersAcrossZones(t *testing.T) {
+	s := benchBackends[0].open(t).(*Store)
+
+	// Stored in zones whose strings sort the other way round from the
+	// times: 09:00 in Tokyo is before 20:00 the previous day in New York
+	tokyo := time.FixedZone("JST", 9*3600)
+	newYork := time.FixedZone("EST", -5*3600)
+	cutoff := time.Date(2025, 3, 1, 0, 30, 0, 0, time.UTC)
+	early := &Document{Title: "Early", Path: "/tmp/early.pdf", CreatedAt: time.Date(2025, 3, 1, 9, 0, 0, 0, tokyo)}
+	late := &Document{Title: "Late", Path: "/tmp/late.pdf", CreatedAt: time.Date(2025, 2, 28, 20, 0, 0, 0, newYork)}
+	early.ReadAt, late.ReadAt = early.CreatedAt, late.CreatedAt
+	if err := s.AddDocuments([]*Document{early, late}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AppendAuditEntry(&AuditEntry{Entity: "document", EntityID: early.ID, Action: "add", CreatedAt: early.CreatedAt}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AppendAuditEntry(&AuditEntry{Entity: "document", EntityID: late.ID, Action: "add", CreatedAt: late.CreatedAt}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		opts ListOptions
+		want []string
+	}{
+		{"created after", ListOptions{CreatedAfter: cutoff}, []string{"Late"}},
+		{"created before", ListOptions{CreatedBefore: cutoff}, []string{"Early"}},
+		{"read after", ListOptions{ReadAfter: cutoff.In(tokyo)}, []string{"Late"}},
+		{"read before", ListOptions{ReadBefore: cutoff.In(newYork)}, []string{"Early"}},
+	} {
+		docs, err := s.ListDocuments(&tt.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, d := range docs {
+			got = append(got, d.Title)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	entries, err := s.ListAuditEntries(&AuditListOptions{Since: cutoff})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].EntityID != late.ID {
+		t.Errorf("audit entries since the cutoff: %+v", entries)
+	}
+}
+
+func TestParseStoredTime(t *testing.T) {
+	want := time.Date(2025, 3, 1, 9, 0, 0, 500, time.FixedZone("PST", -8*3600))
+	for _, s := range []string{
+		want.String(),
+		want.String() + " m=+0.012345678",
+		want.Format(time.RFC3339Nano),
+	} {
+		if got, ok := parseStoredTime(s); !ok || !got.Equal(want) {
+			t.Errorf("%q: %v, %v", s, got, ok)
+		}
+	}
+	if _, ok := parseStoredTime("soon"); ok {
+		t.Error("parsed \"soon\"")
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// LibraryStats is a library-wide summary used by dashboards.
+// Series are ordered oldest to newest and always contain every bucket,
+// so empty periods show up as zero rather than gaps.
+type LibraryStats struct {
+	Documents   int `json:"documents"`
+	Collections int `json:"collections"`
+	Annotations int `json:"annotations"`
+	Sessions    int `json:"sessions"`
+	PagesRead   int `json:"pages_read"`
+
+	ByStatus       map[ReadingStatus]int `json:"by_status"`
+	CompletionRate float64               `json:"completion_rate"` // completed / documents, 0-1
+
+	AddedPerMonth  []PeriodCount `json:"added_per_month"`    // last 12 months, key YYYY-MM
+	ReadingPerWeek []PeriodCount `json:"reading_per_week"`   // last 12 weeks, minutes; key is the Monday, YYYY-MM-DD
+	FlashcardsDue  []PeriodCount `json:"flashcards_due"`     // next 14 days, key YYYY-MM-DD; today includes overdue
+	Overdue        int           `json:"flashcards_overdue"` // due before now
+	TopTags        []PeriodCount `json:"top_tags"`           // up to 10, key is the tag
+}
+
+// PeriodCount is one bucket of a stats series.
+type PeriodCount struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+const (
+	statsMonths   = 12
+	statsWeeks    = 12
+	statsDueDays  = 14
+	statsTopTags  = 10
+	statsDayFmt   = "2006-01-02"
+	statsMonthFmt = "2006-01"
+)
+
+// ComputeStats aggregates stats across the whole library relative to now.
+// Per-document lookups that fail are skipped so one bad record does not
+// hide the rest of the dashboard.
+func ComputeStats(s LibraryStore, now time.Time) (*LibraryStats, error) {
+	docs, err := s.ListDocuments(nil)
+	if err != nil {
+		return nil, err
+	}
+	collections, err := s.ListCollections()
+	if err != nil {
+		return nil, err
+	}
+	tags, err := s.ListTags()
+	if err != nil {
+		return nil, err
+	}
+
+	annotations, err := s.CountAnnotations()
+	if err != nil {
+		return nil, err
+	}
+
+	st := &LibraryStats{
+		Documents:   len(docs),
+		Collections: len(collections),
+		Annotations: annotations,
+		ByStatus:    make(map[ReadingStatus]int),
+	}
+
+	// Documents added per month
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	st.AddedPerMonth = make([]PeriodCount, statsMonths)
+	months := make(map[string]int, statsMonths)
+	for i := range st.AddedPerMonth {
+		key := thisMonth.AddDate(0, i-(statsMonths-1), 0).Format(statsMonthFmt)
+		st.AddedPerMonth[i].Key = key
+		months[key] = i
+	}
+
+	// Reading minutes per week, weeks starting Monday
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	thisWeek := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	st.ReadingPerWeek = make([]PeriodCount, statsWeeks)
+	weeks := make(map[string]int, statsWeeks)
+	for i := range st.ReadingPerWeek {
+		key := thisWeek.AddDate(0, 0, 7*(i-(statsWeeks-1))).Format(statsDayFmt)
+		st.ReadingPerWeek[i].Key = key
+		weeks[key] = i
+	}
+
+	for _, d := range docs {
+		status := d.Status
+		if status == "" {
+			status = StatusUnread
+		}
+		st.ByStatus[status]++
+
+		if i, ok := months[d.CreatedAt.In(now.Location()).Format(statsMonthFmt)]; ok {
+			st.AddedPerMonth[i].Count++
+		}
+
+		sessions, err := s.ListSessions(d.ID)
+		if err != nil {
+			continue
+		}
+		st.Sessions += len(sessions)
+		for _, sess := range sessions {
+			st.PagesRead += sess.PagesRead
+			if sess.EndAt.IsZero() {
+				continue
+			}
+			start := sess.StartAt.In(now.Location())
+			day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, now.Location())
+			monday := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+			if i, ok := weeks[monday.Format(statsDayFmt)]; ok {
+				st.ReadingPerWeek[i].Count += int(sess.EndAt.Sub(sess.StartAt).Minutes())
+			}
+		}
+	}
+	if st.Documents > 0 {
+		st.CompletionRate = float64(st.ByStatus[StatusCompleted]) / float64(st.Documents)
+	}
+
+	// Flashcard review load
+	st.FlashcardsDue = make([]PeriodCount, statsDueDays)
+	for i := range st.FlashcardsDue {
+		st.FlashcardsDue[i].Key = today.AddDate(0, 0, i).Format(statsDayFmt)
+	}
+	// Backends without flashcard support simply report no load
+	if cards, err := s.ListFlashcards(nil); err == nil {
+		for _, c := range cards {
+			// Due earlier today is as overdue as due yesterday
+			due := c.DueAt.In(now.Location())
+			if due.Before(now) {
+				st.Overdue++
+				st.FlashcardsDue[0].Count++
+				continue
+			}
+			// Rounded so days shortened or lengthened by DST still count as one
+			day := int(math.Round(time.Date(due.Year(), due.Month(), due.Day(), 0, 0, 0, 0, now.Location()).Sub(today).Hours() / 24))
+			if day < statsDueDays {
+				st.FlashcardsDue[day].Count++
+			}
+		}
+	}
+
+	// Top tags by document count, ties alphabetical
+	for tag, n := range tags {
+		st.TopTags = append(st.TopTags, PeriodCount{Key: tag, Count: n})
+	}
+	sort.Slice(st.TopTags, func(i, j int) bool {
+		if st.TopTags[i].Count != st.TopTags[j].Count {
+			return st.TopTags[i].Count > st.TopTags[j].Count
+		}
+		return st.TopTags[i].Key < st.TopTags[j].Key
+	})
+	if len(st.TopTags) > statsTopTags {
+		st.TopTags = st.TopTags[:statsTopTags]
+	}
+	if st.TopTags == nil {
+		st.TopTags = []PeriodCount{}
+	}
+
+	return st, nil
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+func TestComputeStats(t *testing.T) {
+	kv := store.NewMemoryStore()
+	s, _ := NewKVStore(kv)
+
+	done := &Document{Path: "/tmp/a.pdf", Type: DocTypePaper, Title: "A", Tags: []string{"ml", "nlp"}, Status: StatusCompleted}
+	todo := &Document{Path: "/tmp/b.pdf", Type: DocTypePaper, Title: "B", Tags: []string{"ml"}}
+	for _, d := range []*Document{done, todo} {
+		if err := s.AddDocument(d); err != nil {
+			t.Fatalf("AddDocument: %v", err)
+		}
+	}
+
+	now := time.Now()
+	cards := []*Flashcard{
+		{DocumentID: done.ID, Type: "basic", Front: "overdue", DueAt: now.AddDate(0, 0, -3)},
+		{DocumentID: done.ID, Type: "basic", Front: "just now", DueAt: now.Add(-time.Minute)},
+		{DocumentID: done.ID, Type: "basic", Front: "tomorrow", DueAt: now.AddDate(0, 0, 1)},
+	}
+	for _, c := range cards {
+		if err := s.AddFlashcard(c); err != nil {
+			t.Fatalf("AddFlashcard: %v", err)
+		}
+	}
+
+	st, err := ComputeStats(s, now)
+	if err != nil {
+		t.Fatalf("ComputeStats: %v", err)
+	}
+	if st.Documents != 2 {
+		t.Errorf("Documents: got %d, want 2", st.Documents)
+	}
+	if st.CompletionRate != 0.5 {
+		t.Errorf("CompletionRate: got %v, want 0.5", st.CompletionRate)
+	}
+	if st.ByStatus[StatusUnread] != 1 {
+		t.Errorf("ByStatus[unread]: got %d, want 1", st.ByStatus[StatusUnread])
+	}
+	if n := len(st.AddedPerMonth); n != 12 || st.AddedPerMonth[n-1].Count != 2 {
+		t.Errorf("AddedPerMonth: got %+v, want 12 months ending with 2", st.AddedPerMonth)
+	}
+	if len(st.ReadingPerWeek) != 12 {
+		t.Errorf("ReadingPerWeek: got %d weeks, want 12", len(st.ReadingPerWeek))
+	}
+	if st.Overdue != 2 || st.FlashcardsDue[0].Count != 2 || st.FlashcardsDue[1].Count != 1 {
+		t.Errorf("Flashcard load: overdue %d, due %+v", st.Overdue, st.FlashcardsDue[:2])
+	}
+	if len(st.TopTags) != 2 || st.TopTags[0].Key != "ml" || st.TopTags[0].Count != 2 {
+		t.Errorf("TopTags: got %+v, want ml first with 2", st.TopTags)
+	}
+}
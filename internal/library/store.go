@@ -4,6 +4,7 @@
 package library
 
 import (
+	"bytes"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -11,6 +12,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/mtreilly/arc-library/internal/scheduler"
 )
 
 // Store provides persistence for library data using SQL.
@@ -27,6 +29,18 @@ func NewStore(db *sql.DB) (*Store, error) {
 	return s, nil
 }
 
+// OpenStore returns a library store over db without creating or migrating
+// its schema, for a process that finds another holding the library lock
+// (see LockLibrary) and so may not write.
+func OpenStore(db *sql.DB) *Store {
+	return &Store{db: db}
+}
+
+// Capabilities reports that the SQL store has every optional feature.
+func (s *Store) Capabilities() Capabilities {
+	return Capabilities{Tasks: true, SavedSearches: true}
+}
+
 func (s *Store) initSchema() error {
 	// In Phase 2, we'll add FTS5 table. For now, keep original schema but rename columns
 	schema := `
@@ -132,10 +146,15 @@ func (s *Store) initSchema() error {
 		id TEXT PRIMARY KEY,
 		description TEXT NOT NULL,
 		collection_id TEXT,
+		document_id TEXT,
+		parent_id TEXT,
 		status TEXT NOT NULL DEFAULT 'todo',
 		priority TEXT DEFAULT 'medium',
 		tags TEXT DEFAULT '[]',
+		repeat TEXT,
+		repeat_day INTEGER NOT NULL DEFAULT 0,
 		due_at DATETIME,
+		completed_at DATETIME,
 		created_at DATETIME NOT NULL,
 		updated_at DATETIME NOT NULL,
 		FOREIGN KEY (collection_id) REFERENCES collections(id) ON DELETE SET NULL
@@ -158,41 +177,191 @@ func (s *Store) initSchema() error {
 	);
 
 	CREATE INDEX IF NOT EXISTS idx_saved_searches_name ON saved_searches(name);
+
+	CREATE TABLE IF NOT EXISTS document_revisions (
+		id TEXT PRIMARY KEY,
+		document_id TEXT NOT NULL,
+		rev INTEGER NOT NULL,
+		changes TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		UNIQUE (document_id, rev)
+	);
+
+	CREATE TABLE IF NOT EXISTS document_links (
+		id TEXT PRIMARY KEY,
+		from_id TEXT NOT NULL,
+		to_id TEXT NOT NULL,
+		relation TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		UNIQUE (from_id, to_id, relation),
+		FOREIGN KEY (from_id) REFERENCES documents(id) ON DELETE CASCADE,
+		FOREIGN KEY (to_id) REFERENCES documents(id) ON DELETE CASCADE
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_document_links_to ON document_links(to_id);
+
+	CREATE TABLE IF NOT EXISTS operations (
+		id TEXT PRIMARY KEY,
+		kind TEXT NOT NULL,
+		summary TEXT NOT NULL,
+		data TEXT NOT NULL,
+		created_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS text_signatures (
+		document_id TEXT PRIMARY KEY,
+		text_hash INTEGER NOT NULL,
+		minhash TEXT,
+		updated_at DATETIME NOT NULL,
+		FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS document_sections (
+		document_id TEXT PRIMARY KEY,
+		text_hash INTEGER NOT NULL,
+		data TEXT NOT NULL,
+		updated_at DATETIME NOT NULL,
+		FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS document_references (
+		document_id TEXT PRIMARY KEY,
+		text_hash INTEGER NOT NULL,
+		data TEXT NOT NULL,
+		updated_at DATETIME NOT NULL,
+		FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS zotero_sync (
+		library TEXT PRIMARY KEY,
+		data TEXT NOT NULL,
+		synced_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS field_defs (
+		name TEXT PRIMARY KEY,
+		type TEXT NOT NULL,
+		enum_values TEXT,
+		created_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS shares (
+		token TEXT PRIMARY KEY,
+		document_id TEXT NOT NULL,
+		include_file INTEGER NOT NULL DEFAULT 0,
+		expires_at DATETIME NOT NULL,
+		created_at DATETIME NOT NULL,
+		FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE CASCADE
+	);
+
+	CREATE TABLE IF NOT EXISTS api_tokens (
+		id TEXT PRIMARY KEY,
+		name TEXT NOT NULL UNIQUE,
+		scope TEXT NOT NULL,
+		hash TEXT NOT NULL UNIQUE,
+		created_at DATETIME NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS audit_log (
+		id TEXT PRIMARY KEY,
+		entity TEXT NOT NULL,
+		entity_id TEXT NOT NULL,
+		document_id TEXT,
+		action TEXT NOT NULL,
+		actor TEXT NOT NULL,
+		summary TEXT,
+		fields TEXT,
+		created_at DATETIME NOT NULL
+	);
+	CREATE INDEX IF NOT EXISTS idx_audit_log_created ON audit_log(created_at);
+
+	CREATE TABLE IF NOT EXISTS document_reviews (
+		document_id TEXT PRIMARY KEY,
+		due_at DATETIME NOT NULL,
+		interval INTEGER NOT NULL,
+		ease REAL NOT NULL,
+		reviews INTEGER NOT NULL,
+		last_quality INTEGER NOT NULL,
+		last_review DATETIME NOT NULL,
+		FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE CASCADE
+	);
+	CREATE INDEX IF NOT EXISTS idx_document_reviews_due ON document_reviews(due_at);
+
+	CREATE TABLE IF NOT EXISTS reading_queue (
+		document_id TEXT PRIMARY KEY,
+		position INTEGER NOT NULL,
+		FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE CASCADE
+	);
 	`
 
-	// Full-text search virtual table (FTS5)
+	// Full-text search: one FTS5 index per tokenizer, each document indexed
+	// in the one matching its language (see ftsTable). The Porter stemmer
+	// only suits English, so other languages get plain Unicode word
+	// tokenizing, and CJK scripts, written without spaces, character
+	// trigrams. The indexes replace a single English-only documents_fts.
+	// They are contentless, keeping no copy of the text, which is stored
+	// compressed or in files: documents_fts_ids numbers the documents for
+	// them, and the triggers index full text through library_full_text
+	// (see compress.go).
+	var indexSQL string
+	if err := s.db.QueryRow(`SELECT COALESCE(MAX(sql), '') FROM sqlite_master WHERE name = ?`, ftsTables[0].name).Scan(&indexSQL); err != nil {
+		return err
+	}
+	// Indexes from before contentless ones are rebuilt, and new ones filled
+	rebuild := !strings.Contains(indexSQL, "contentless_delete")
+
 	ftsSchema := `
-	CREATE VIRTUAL TABLE IF NOT EXISTS documents_fts USING fts5(
-		doc_id UNINDEXED,
+	DROP TRIGGER IF EXISTS documents_ai;
+	DROP TRIGGER IF EXISTS documents_ad;
+	DROP TRIGGER IF EXISTS documents_au;
+	DROP TABLE IF EXISTS documents_fts;
+
+	CREATE TABLE IF NOT EXISTS documents_fts_ids (
+		rowid INTEGER PRIMARY KEY,
+		doc_id TEXT NOT NULL UNIQUE
+	);
+	`
+	var insert, remove string
+	for _, t := range ftsTables {
+		if rebuild {
+			ftsSchema += fmt.Sprintf(`
+	DROP TRIGGER IF EXISTS %[1]s_ai;
+	DROP TRIGGER IF EXISTS %[1]s_ad;
+	DROP TRIGGER IF EXISTS %[1]s_au;
+	DROP TABLE IF EXISTS %[1]s;
+	`, t.name)
+		}
+		ftsSchema += fmt.Sprintf(`
+	CREATE VIRTUAL TABLE IF NOT EXISTS %[1]s USING fts5(
 		title,
 		abstract,
 		full_text,
 		tags,
 		notes,
+		tokenize='%[2]s',
 		content='',
-		tokenize='porter'
+		contentless_delete=1
 	);
-
-	-- Triggers to maintain FTS index
-	CREATE TRIGGER IF NOT EXISTS documents_ai AFTER INSERT ON documents BEGIN
-		INSERT INTO documents_fts (doc_id, title, abstract, full_text, tags, notes)
-		VALUES (new.id, new.title, new.abstract, new.full_text, new.tags, new.notes);
+	`, t.name, t.tokenizer)
+		insert += fmt.Sprintf(`
+		INSERT INTO %[1]s (rowid, title, abstract, full_text, tags, notes)
+		SELECT rowid, new.title, new.abstract, library_full_text(new.full_text), new.tags, new.notes
+		FROM documents_fts_ids WHERE doc_id = new.id AND %[2]s;`, t.name, t.condition("new.meta"))
+		remove += fmt.Sprintf(`
+		DELETE FROM %s WHERE rowid = (SELECT rowid FROM documents_fts_ids WHERE doc_id = old.id);`, t.name)
+	}
+	ftsSchema += fmt.Sprintf(`
+	CREATE TRIGGER IF NOT EXISTS documents_fts_ai AFTER INSERT ON documents BEGIN
+		INSERT OR IGNORE INTO documents_fts_ids (doc_id) VALUES (new.id);%[1]s
 	END;
 
-	CREATE TRIGGER IF NOT EXISTS documents_ad AFTER DELETE ON documents BEGIN
-		DELETE FROM documents_fts WHERE doc_id = old.id;
+	CREATE TRIGGER IF NOT EXISTS documents_fts_ad AFTER DELETE ON documents BEGIN%[2]s
+		DELETE FROM documents_fts_ids WHERE doc_id = old.id;
 	END;
 
-	CREATE TRIGGER IF NOT EXISTS documents_au AFTER UPDATE ON documents BEGIN
-		UPDATE documents_fts SET
-			title = new.title,
-			abstract = new.abstract,
-			full_text = new.full_text,
-			tags = new.tags,
-			notes = new.notes
-		WHERE doc_id = old.id;
+	CREATE TRIGGER IF NOT EXISTS documents_fts_au AFTER UPDATE ON documents BEGIN%[2]s%[1]s
 	END;
-	`
+	`, insert, remove)
 
 	// Execute all schema batches
 	_, err := s.db.Exec(schema)
@@ -204,30 +373,190 @@ func (s *Store) initSchema() error {
 		return err
 	}
 	_, err = s.db.Exec(ftsSchema)
+	if err != nil {
+		return err
+	}
+	if rebuild {
+		_, err = s.db.Exec(`INSERT OR IGNORE INTO documents_fts_ids (doc_id) SELECT id FROM documents`)
+		if err != nil {
+			return err
+		}
+		for _, t := range ftsTables {
+			_, err = s.db.Exec(fmt.Sprintf(`
+				INSERT INTO %s (rowid, title, abstract, full_text, tags, notes)
+				SELECT i.rowid, d.title, d.abstract, library_full_text(d.full_text), d.tags, d.notes
+				FROM documents d JOIN documents_fts_ids i ON i.doc_id = d.id WHERE %s
+			`, t.name, t.condition("d.meta")))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Columns added after the first release; CREATE TABLE IF NOT EXISTS
+	// leaves older databases without them
+	if err := s.addColumnIfMissing("tasks", "repeat", "TEXT"); err != nil {
+		return err
+	}
+	if err := s.addColumnIfMissing("tasks", "document_id", "TEXT"); err != nil {
+		return err
+	}
+	if err := s.addColumnIfMissing("tasks", "parent_id", "TEXT"); err != nil {
+		return err
+	}
+	if err := s.addColumnIfMissing("tasks", "completed_at", "DATETIME"); err != nil {
+		return err
+	}
+	if err := s.addColumnIfMissing("tasks", "repeat_day", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := s.addColumnIfMissing("collections", "public", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if err := s.addColumnIfMissing("annotations", "session_id", "TEXT"); err != nil {
+		return err
+	}
+	if err := s.addColumnIfMissing("annotations", "parent_id", "TEXT"); err != nil {
+		return err
+	}
+	if err := s.addColumnIfMissing("flashcard_reviews", "params", "TEXT"); err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_tasks_document ON tasks(document_id);
+		CREATE INDEX IF NOT EXISTS idx_tasks_parent ON tasks(parent_id);
+	`)
 	return err
 }
 
-// AddDocument adds a document to the library.
-func (s *Store) AddDocument(doc *Document) error {
-	if doc.ID == "" {
-		doc.ID = uuid.New().String()
+// ftsTable is a full-text index for the documents in some languages.
+type ftsTable struct {
+	name      string
+	tokenizer string
+	when      string // condition on the language code, formatted with its SQL expression
+}
+
+var ftsTables = []ftsTable{
+	{"documents_fts_en", "porter unicode61 remove_diacritics 2", "%s IN ('', 'en')"},
+	{"documents_fts_cjk", "trigram", "%s IN (" + sqlList(CJKLanguages) + ")"},
+	{"documents_fts_intl", "unicode61 remove_diacritics 2", "%s NOT IN ('', 'en', " + sqlList(CJKLanguages) + ")"},
+}
+
+// condition returns the SQL condition selecting documents for t, given the
+// expression for their meta column. Documents without a language count as
+// English.
+func (t ftsTable) condition(meta string) string {
+	lang := fmt.Sprintf(`COALESCE(CASE WHEN json_valid(%[1]s) THEN json_extract(%[1]s, '$.language') END, '')`, meta)
+	return fmt.Sprintf(t.when, lang)
+}
+
+func sqlList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = "'" + strings.ReplaceAll(v, "'", "''") + "'"
 	}
-	now := time.Now()
-	doc.CreatedAt = now
-	doc.UpdatedAt = now
+	return strings.Join(quoted, ", ")
+}
 
-	authorsJSON, _ := json.Marshal(doc.Authors)
-	tagsJSON, _ := json.Marshal(doc.Tags)
-	metaJSON, _ := json.Marshal(doc.Meta)
+// addColumnIfMissing adds a column to an existing table unless it is already there.
+func (s *Store) addColumnIfMissing(table, column, decl string) error {
+	rows, err := s.db.Query(fmt.Sprintf(`PRAGMA table_info(%s)`, table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
 
-	_, err := s.db.Exec(`
-		INSERT INTO documents (id, type, path, source, source_id, title, authors, abstract, full_text, tags, notes, rating, status, read_at, meta, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, doc.ID, doc.Type, doc.Path, doc.Source, doc.SourceID, doc.Title, string(authorsJSON), doc.Abstract, doc.FullText, string(tagsJSON), doc.Notes, doc.Rating, doc.Status, doc.ReadAt, string(metaJSON), doc.CreatedAt, doc.UpdatedAt)
+	for rows.Next() {
+		var (
+			cid       int
+			name      string
+			colType   string
+			notNull   int
+			dfltValue sql.NullString
+			pk        int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
 
+	_, err = s.db.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, column, decl))
 	return err
 }
 
+// bulkInsertVars caps the parameters of one INSERT, below the 999
+// older SQLite versions allow.
+const bulkInsertVars = 999
+
+// bulkInsert inserts rows into table with multi-row INSERTs, in one
+// transaction: all of them or, on an error, none.
+func (s *Store) bulkInsert(table string, columns []string, rows [][]any) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	row := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")"
+	perInsert := max(1, bulkInsertVars/len(columns))
+	for start := 0; start < len(rows); start += perInsert {
+		chunk := rows[start:min(start+perInsert, len(rows))]
+		args := make([]any, 0, len(chunk)*len(columns))
+		for _, r := range chunk {
+			args = append(args, r...)
+		}
+		query := fmt.Sprintf(`INSERT INTO %s (%s) VALUES %s`, table, strings.Join(columns, ", "),
+			strings.TrimSuffix(strings.Repeat(row+", ", len(chunk)), ", "))
+		if _, err := tx.Exec(query, args...); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+var documentInsertColumns = []string{"id", "type", "path", "source", "source_id", "title", "authors", "abstract", "full_text", "tags", "notes", "rating", "status", "read_at", "meta", "created_at", "updated_at"}
+
+// AddDocument adds a document to the library.
+func (s *Store) AddDocument(doc *Document) error {
+	return s.AddDocuments([]*Document{doc})
+}
+
+// AddDocuments adds documents to the library in one transaction.
+func (s *Store) AddDocuments(docs []*Document) error {
+	now := time.Now()
+	rows := make([][]any, len(docs))
+	for i, doc := range docs {
+		if doc.ID == "" {
+			doc.ID = uuid.New().String()
+		}
+		if doc.CreatedAt.IsZero() {
+			// Restored documents keep their original creation time
+			doc.CreatedAt = now
+		}
+		doc.UpdatedAt = now
+
+		authorsJSON, _ := json.Marshal(doc.Authors)
+		tagsJSON, _ := json.Marshal(doc.Tags)
+		metaJSON, _ := json.Marshal(doc.Meta)
+
+		fullText, err := storedFullText(doc.FullText)
+		if err != nil {
+			return err
+		}
+		rows[i] = []any{doc.ID, doc.Type, doc.Path, doc.Source, doc.SourceID, doc.Title, string(authorsJSON), doc.Abstract, fullText, string(tagsJSON), doc.Notes, doc.Rating, doc.Status, doc.ReadAt, string(metaJSON), doc.CreatedAt, doc.UpdatedAt}
+	}
+	return s.bulkInsert("documents", documentInsertColumns, rows)
+}
+
 // GetDocument retrieves a document by ID.
 func (s *Store) GetDocument(id string) (*Document, error) {
 	row := s.db.QueryRow(`
@@ -277,7 +606,9 @@ func scanDocument(row *sql.Row) (*Document, error) {
 		d.Abstract = abstract.String
 	}
 	if fullText.Valid {
-		d.FullText = fullText.String
+		if d.FullText, err = expandFullText(fullText.String); err != nil {
+			return nil, err
+		}
 	}
 	if notes.Valid {
 		d.Notes = notes.String
@@ -289,13 +620,24 @@ func scanDocument(row *sql.Row) (*Document, error) {
 		d.ReadAt = readAt.Time
 	}
 
-	json.Unmarshal([]byte(authorsJSON), &d.Authors)
-	json.Unmarshal([]byte(tagsJSON), &d.Tags)
-	json.Unmarshal([]byte(metaJSON), &d.Meta)
-
+	if err := unmarshalDocumentFields(&d, authorsJSON, tagsJSON, metaJSON); err != nil {
+		return nil, err
+	}
 	return &d, nil
 }
 
+// unmarshalDocumentFields reads the JSON columns of a document row into
+// d, leaving those it cannot read empty (see unreadable).
+func unmarshalDocumentFields(d *Document, authorsJSON, tagsJSON, metaJSON string) error {
+	if err := unmarshalField("document", d.ID, "authors", []byte(authorsJSON), &d.Authors); err != nil {
+		return err
+	}
+	if err := unmarshalField("document", d.ID, "tags", []byte(tagsJSON), &d.Tags); err != nil {
+		return err
+	}
+	return unmarshalField("document", d.ID, "meta", []byte(metaJSON), &d.Meta)
+}
+
 // ListDocuments returns all documents, optionally filtered.
 func (s *Store) ListDocuments(opts *ListOptions) ([]*Document, error) {
 	var (
@@ -304,13 +646,18 @@ func (s *Store) ListDocuments(opts *ListOptions) ([]*Document, error) {
 	)
 
 	if opts != nil && opts.Search != "" {
-		// Use FTS5 for full-text search
-		query = `
-			SELECT d.id, d.type, d.path, d.source, d.source_id, d.title, d.authors, d.abstract, d.full_text, d.tags, d.notes, d.rating, d.status, d.read_at, d.meta, d.created_at, d.updated_at
-			FROM documents d
-			JOIN documents_fts fts ON d.id = fts.rowid
-			WHERE documents_fts MATCH ?`
-		args = append(args, opts.Search)
+		// Use FTS5 for full-text search, across the per-language indexes
+		query = `SELECT id, type, path, source, source_id, title, authors, abstract, full_text, tags, notes, rating, status, read_at, meta, created_at, updated_at FROM documents
+			WHERE id IN (SELECT doc_id FROM documents_fts_ids WHERE rowid IN (`
+		match := ftsQuery(opts.Search)
+		for i, t := range ftsTables {
+			if i > 0 {
+				query += ` UNION `
+			}
+			query += fmt.Sprintf(`SELECT rowid FROM %[1]s WHERE %[1]s MATCH ?`, t.name)
+			args = append(args, match)
+		}
+		query += `))`
 	} else {
 		query = `SELECT id, type, path, source, source_id, title, authors, abstract, full_text, tags, notes, rating, status, read_at, meta, created_at, updated_at FROM documents WHERE 1=1`
 	}
@@ -328,6 +675,51 @@ func (s *Store) ListDocuments(opts *ListOptions) ([]*Document, error) {
 			query += ` AND type = ?`
 			args = append(args, opts.Type)
 		}
+		if opts.Language != "" {
+			query += ` AND json_extract(meta, '$.language') = ?`
+			args = append(args, strings.ToLower(opts.Language))
+		}
+		if opts.Status != "" {
+			query += ` AND COALESCE(NULLIF(status, ''), 'unread') = ?`
+			args = append(args, opts.Status)
+		} else if opts.HideArchived {
+			query += ` AND COALESCE(status, '') != ?`
+			args = append(args, StatusArchived)
+		}
+		if opts.MinRating > 0 {
+			query += ` AND rating >= ?`
+			args = append(args, opts.MinRating)
+		}
+		if opts.Author != "" {
+			query += ` AND authors LIKE ?`
+			args = append(args, "%"+opts.Author+"%")
+		}
+		for _, f := range opts.Fields {
+			clause, fargs := fieldFilterSQL(f)
+			query += ` AND ` + clause
+			args = append(args, fargs...)
+		}
+		if !opts.CreatedAfter.IsZero() {
+			query += ` AND library_unix_micro(created_at) >= ?`
+			args = append(args, opts.CreatedAfter.UnixMicro())
+		}
+		if !opts.CreatedBefore.IsZero() {
+			query += ` AND library_unix_micro(created_at) < ?`
+			args = append(args, opts.CreatedBefore.UnixMicro())
+		}
+		if !opts.ReadAfter.IsZero() || !opts.ReadBefore.IsZero() {
+			// Unread documents carry a zero read_at rather than NULL
+			query += ` AND library_unix_micro(read_at) > ?`
+			args = append(args, time.Time{}.UnixMicro())
+		}
+		if !opts.ReadAfter.IsZero() {
+			query += ` AND library_unix_micro(read_at) >= ?`
+			args = append(args, opts.ReadAfter.UnixMicro())
+		}
+		if !opts.ReadBefore.IsZero() {
+			query += ` AND library_unix_micro(read_at) < ?`
+			args = append(args, opts.ReadBefore.UnixMicro())
+		}
 	}
 
 	query += ` ORDER BY updated_at DESC`
@@ -362,7 +754,11 @@ func (s *Store) ListDocuments(opts *ListOptions) ([]*Document, error) {
 			d.Abstract = abstract.String
 		}
 		if fullText.Valid {
-			d.FullText = fullText.String
+			if d.FullText, err = expandFullText(fullText.String); err != nil {
+				if err := unreadable("document", d.ID, fmt.Errorf("full text: %w", err)); err != nil {
+					return nil, err
+				}
+			}
 		}
 		if notes.Valid {
 			d.Notes = notes.String
@@ -374,102 +770,299 @@ func (s *Store) ListDocuments(opts *ListOptions) ([]*Document, error) {
 			d.ReadAt = readAt.Time
 		}
 
-		json.Unmarshal([]byte(authorsJSON), &d.Authors)
-		json.Unmarshal([]byte(tagsJSON), &d.Tags)
-		json.Unmarshal([]byte(metaJSON), &d.Meta)
+		if err := unmarshalDocumentFields(&d, authorsJSON, tagsJSON, metaJSON); err != nil {
+			return nil, err
+		}
 
 		docs = append(docs, &d)
 	}
 
-	return docs, nil
+	return docs, rows.Err()
 }
 
 // UpdateDocument updates a document's metadata.
 func (s *Store) UpdateDocument(doc *Document) error {
+	old, err := s.GetDocument(doc.ID)
+	if err != nil {
+		return err
+	}
+
 	doc.UpdatedAt = time.Now()
 
 	authorsJSON, _ := json.Marshal(doc.Authors)
 	tagsJSON, _ := json.Marshal(doc.Tags)
 	metaJSON, _ := json.Marshal(doc.Meta)
+	fullText, err := storedFullText(doc.FullText)
+	if err != nil {
+		return err
+	}
 
-	_, err := s.db.Exec(`
+	_, err = s.db.Exec(`
 		UPDATE documents
-		SET type = ?, title = ?, authors = ?, abstract = ?, full_text = ?, tags = ?, notes = ?, rating = ?, status = ?, read_at = ?, meta = ?, updated_at = ?
+		SET type = ?, path = ?, title = ?, authors = ?, abstract = ?, full_text = ?, tags = ?, notes = ?, rating = ?, status = ?, read_at = ?, meta = ?, updated_at = ?
 		WHERE id = ?
-	`, doc.Type, doc.Title, string(authorsJSON), doc.Abstract, doc.FullText, string(tagsJSON), doc.Notes, doc.Rating, doc.Status, doc.ReadAt, string(metaJSON), doc.UpdatedAt, doc.ID)
+	`, doc.Type, doc.Path, doc.Title, string(authorsJSON), doc.Abstract, fullText, string(tagsJSON), doc.Notes, doc.Rating, doc.Status, doc.ReadAt, string(metaJSON), doc.UpdatedAt, doc.ID)
+	if err != nil || old == nil {
+		return err
+	}
 
-	return err
+	// Diff against what was stored, since not every field is updatable here
+	updated, err := s.GetDocument(doc.ID)
+	if err != nil {
+		return err
+	}
+	return s.recordRevision(old, updated)
 }
 
-// DeleteDocument removes a document from the library.
-func (s *Store) DeleteDocument(id string) error {
-	_, err := s.db.Exec(`DELETE FROM documents WHERE id = ?`, id)
-	return err
+// recordRevision stores the fields changed between old and updated as the
+// document's next revision; nothing is stored when nothing changed.
+func (s *Store) recordRevision(old, updated *Document) error {
+	changes, err := DiffDocuments(old, updated)
+	if err != nil || len(changes) == 0 {
+		return err
+	}
+	changesJSON, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO document_revisions (id, document_id, rev, changes, created_at)
+		SELECT ?, ?, COALESCE(MAX(rev), 0) + 1, ?, ? FROM document_revisions WHERE document_id = ?
+	`, uuid.New().String(), updated.ID, string(changesJSON), updated.UpdatedAt, updated.ID)
+	if err != nil {
+		return fmt.Errorf("record revision: %w", err)
+	}
+	return nil
 }
 
-// Tag operations (now use DocumentID)
+// Compact stores the full text of every document as it is stored now:
+// compressed, or in files with ARC_LIBRARY_FULLTEXT=external, for text
+// stored before compression or under the other setting, and removes the
+// full text files no document refers to. It then vacuums the database to
+// return the space freed.
+func (s *Store) Compact() (*CompactStats, error) {
+	stats := &CompactStats{}
+	var err error
+	if stats.SizeBefore, err = s.databaseSize(); err != nil {
+		return nil, err
+	}
 
-func (s *Store) AddTag(documentID, tag string) error {
-	doc, err := s.GetDocument(documentID)
+	type storedText struct {
+		id  string
+		raw []byte
+	}
+	rows, err := s.db.Query(`SELECT id, CAST(full_text AS BLOB) FROM documents WHERE length(full_text) > 0`)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if doc == nil {
-		return fmt.Errorf("document not found: %s", documentID)
+	var texts []storedText
+	for rows.Next() {
+		var t storedText
+		if err := rows.Scan(&t.id, &t.raw); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		texts = append(texts, t)
 	}
-
-	// Check if tag already exists
-	for _, t := range doc.Tags {
-		if strings.EqualFold(t, tag) {
-			return nil // Already tagged
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	used := make(map[string]bool)
+	for _, t := range texts {
+		text, err := expandFullText(string(t.raw))
+		if err != nil {
+			return stats, fmt.Errorf("%s: %w", t.id, err)
+		}
+		want, encoded, err := encodeFullText(text)
+		if err != nil {
+			return stats, err
+		}
+		// Text whose file is missing reads as empty; its reference is kept
+		if bytes.Equal(want, t.raw) || text == "" {
+			if hash, ok := fullTextRefHash(t.raw); ok {
+				used[hash] = true
+			}
+			continue
+		}
+		if hash, ok := fullTextRefHash(want); ok && encoded {
+			used[hash] = true
 		}
+		var value any = text
+		if encoded {
+			value = want
+		}
+		if _, err := s.db.Exec(`UPDATE documents SET full_text = ? WHERE id = ?`, value, t.id); err != nil {
+			return stats, fmt.Errorf("store full text of %s: %w", t.id, err)
+		}
+		stats.Documents++
+	}
+	if stats.FilesRemoved, err = pruneFullTextFiles(used); err != nil {
+		return stats, err
 	}
 
-	doc.Tags = append(doc.Tags, tag)
-	return s.UpdateDocument(doc)
+	if _, err := s.db.Exec(`VACUUM`); err != nil {
+		return stats, fmt.Errorf("vacuum: %w", err)
+	}
+	if stats.SizeAfter, err = s.databaseSize(); err != nil {
+		return stats, err
+	}
+	err = s.db.QueryRow(`
+		SELECT COALESCE(SUM(length(CAST(library_full_text(full_text) AS BLOB))), 0), COALESCE(SUM(length(CAST(full_text AS BLOB))), 0)
+		FROM documents WHERE full_text IS NOT NULL
+	`).Scan(&stats.FullText, &stats.StoredText)
+	return stats, err
 }
 
-func (s *Store) RemoveTag(documentID, tag string) error {
-	doc, err := s.GetDocument(documentID)
-	if err != nil {
-		return err
+// RebuildIndexes rebuilds the database's indexes with REINDEX. Triggers
+// keep the full-text indexes up to date.
+func (s *Store) RebuildIndexes() (*IndexStats, error) {
+	stats := &IndexStats{}
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM documents`).Scan(&stats.Documents); err != nil {
+		return nil, err
 	}
-	if doc == nil {
-		return fmt.Errorf("document not found: %s", documentID)
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'index'`).Scan(&stats.Indexes); err != nil {
+		return nil, err
 	}
-
-	newTags := make([]string, 0, len(doc.Tags))
-	for _, t := range doc.Tags {
-		if !strings.EqualFold(t, tag) {
-			newTags = append(newTags, t)
-		}
+	if _, err := s.db.Exec(`REINDEX`); err != nil {
+		return nil, err
 	}
+	return stats, nil
+}
 
-	doc.Tags = newTags
-	return s.UpdateDocument(doc)
+// databaseSize returns the size of the database file in bytes.
+func (s *Store) databaseSize() (int64, error) {
+	var size int64
+	err := s.db.QueryRow(`SELECT page_count * page_size FROM pragma_page_count(), pragma_page_size()`).Scan(&size)
+	return size, err
 }
 
-func (s *Store) ListTags() (map[string]int, error) {
-	rows, err := s.db.Query(`SELECT tags FROM documents WHERE tags != '[]' AND tags != ''`)
+func (s *Store) ListDocumentRevisions(documentID string) ([]*DocumentRevision, error) {
+	rows, err := s.db.Query(`
+		SELECT id, document_id, rev, changes, created_at
+		FROM document_revisions WHERE document_id = ? ORDER BY rev
+	`, documentID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	tagCounts := make(map[string]int)
+	var revs []*DocumentRevision
 	for rows.Next() {
-		var tagsJSON string
-		if err := rows.Scan(&tagsJSON); err != nil {
-			continue
+		var r DocumentRevision
+		var changesJSON string
+		if err := rows.Scan(&r.ID, &r.DocumentID, &r.Rev, &changesJSON, &r.CreatedAt); err != nil {
+			return nil, err
 		}
-		var tags []string
-		json.Unmarshal([]byte(tagsJSON), &tags)
-		for _, tag := range tags {
-			tagCounts[tag]++
+		if err := json.Unmarshal([]byte(changesJSON), &r.Changes); err != nil {
+			return nil, fmt.Errorf("unmarshal revision %d: %w", r.Rev, err)
 		}
+		revs = append(revs, &r)
 	}
-
-	return tagCounts, nil
+	return revs, rows.Err()
+}
+
+// DeleteDocument removes a document from the library.
+func (s *Store) DeleteDocument(id string) error {
+	_, err := s.db.Exec(`DELETE FROM documents WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	// Foreign keys are not enforced on every connection, so clear links explicitly.
+	// Revisions are kept so a document restored by undo keeps its history.
+	_, err = s.db.Exec(`DELETE FROM document_links WHERE from_id = ? OR to_id = ?`, id, id)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`DELETE FROM text_signatures WHERE document_id = ?`, id)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`DELETE FROM document_sections WHERE document_id = ?`, id)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`DELETE FROM document_references WHERE document_id = ?`, id)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`DELETE FROM shares WHERE document_id = ?`, id)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`DELETE FROM document_reviews WHERE document_id = ?`, id)
+	return err
+}
+
+// Tag operations (now use DocumentID)
+
+func (s *Store) AddTag(documentID, tag string) error {
+	doc, err := s.GetDocument(documentID)
+	if err != nil {
+		return err
+	}
+	if doc == nil {
+		return fmt.Errorf("%w: %s", ErrDocumentNotFound, documentID)
+	}
+
+	// Check if tag already exists
+	for _, t := range doc.Tags {
+		if strings.EqualFold(t, tag) {
+			return nil // Already tagged
+		}
+	}
+
+	doc.Tags = append(doc.Tags, tag)
+	return s.UpdateDocument(doc)
+}
+
+func (s *Store) RemoveTag(documentID, tag string) error {
+	doc, err := s.GetDocument(documentID)
+	if err != nil {
+		return err
+	}
+	if doc == nil {
+		return fmt.Errorf("%w: %s", ErrDocumentNotFound, documentID)
+	}
+
+	newTags := make([]string, 0, len(doc.Tags))
+	for _, t := range doc.Tags {
+		if !strings.EqualFold(t, tag) {
+			newTags = append(newTags, t)
+		}
+	}
+
+	doc.Tags = newTags
+	return s.UpdateDocument(doc)
+}
+
+func (s *Store) ListTags() (map[string]int, error) {
+	rows, err := s.db.Query(`SELECT id, tags FROM documents WHERE tags != '[]' AND tags != ''`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tagCounts := make(map[string]int)
+	for rows.Next() {
+		var id, tagsJSON string
+		if err := rows.Scan(&id, &tagsJSON); err != nil {
+			if err := unreadable("document", "", err); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		var tags []string
+		if err := unmarshalField("document", id, "tags", []byte(tagsJSON), &tags); err != nil {
+			return nil, err
+		}
+		for _, tag := range tags {
+			tagCounts[tag]++
+		}
+	}
+
+	return tagCounts, nil
 }
 
 // Collection operations (now use DocumentID)
@@ -489,6 +1082,9 @@ func (s *Store) CreateCollection(name, description string) (*Collection, error)
 	`, c.ID, c.Name, c.Description, c.CreatedAt, c.UpdatedAt)
 
 	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return nil, fmt.Errorf("%w: %s", ErrCollectionExists, name)
+		}
 		return nil, err
 	}
 	return c, nil
@@ -496,13 +1092,13 @@ func (s *Store) CreateCollection(name, description string) (*Collection, error)
 
 func (s *Store) GetCollection(idOrName string) (*Collection, error) {
 	row := s.db.QueryRow(`
-		SELECT id, name, description, created_at, updated_at
+		SELECT id, name, description, public, created_at, updated_at
 		FROM collections WHERE id = ? OR name = ?
 	`, idOrName, idOrName)
 
 	var c Collection
 	var desc sql.NullString
-	err := row.Scan(&c.ID, &c.Name, &desc, &c.CreatedAt, &c.UpdatedAt)
+	err := row.Scan(&c.ID, &c.Name, &desc, &c.Public, &c.CreatedAt, &c.UpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -515,18 +1111,24 @@ func (s *Store) GetCollection(idOrName string) (*Collection, error) {
 	}
 
 	// Get document IDs
-	rows, err := s.db.Query(`SELECT document_id FROM collection_documents WHERE collection_id = ?`, c.ID)
+	rows, err := s.db.Query(`SELECT document_id, added_at FROM collection_documents WHERE collection_id = ?`, c.ID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	c.AddedAt = make(map[string]time.Time)
 	for rows.Next() {
 		var docID string
-		if err := rows.Scan(&docID); err != nil {
+		var addedAt time.Time
+		if err := rows.Scan(&docID, &addedAt); err != nil {
+			if err := unreadable("collection", c.ID, fmt.Errorf("member: %w", err)); err != nil {
+				return nil, err
+			}
 			continue
 		}
 		c.DocumentIDs = append(c.DocumentIDs, docID)
+		c.AddedAt[docID] = addedAt
 	}
 
 	return &c, nil
@@ -534,7 +1136,7 @@ func (s *Store) GetCollection(idOrName string) (*Collection, error) {
 
 func (s *Store) ListCollections() ([]*Collection, error) {
 	rows, err := s.db.Query(`
-		SELECT c.id, c.name, c.description, c.created_at, c.updated_at, COUNT(cd.document_id) as doc_count
+		SELECT c.id, c.name, c.description, c.public, c.created_at, c.updated_at, COUNT(cd.document_id) as doc_count
 		FROM collections c
 		LEFT JOIN collection_documents cd ON c.id = cd.collection_id
 		GROUP BY c.id
@@ -550,7 +1152,10 @@ func (s *Store) ListCollections() ([]*Collection, error) {
 		var c Collection
 		var desc sql.NullString
 		var docCount int
-		if err := rows.Scan(&c.ID, &c.Name, &desc, &c.CreatedAt, &c.UpdatedAt, &docCount); err != nil {
+		if err := rows.Scan(&c.ID, &c.Name, &desc, &c.Public, &c.CreatedAt, &c.UpdatedAt, &docCount); err != nil {
+			if err := unreadable("collection", c.ID, err); err != nil {
+				return nil, err
+			}
 			continue
 		}
 		if desc.Valid {
@@ -581,25 +1186,48 @@ func (s *Store) DeleteCollection(id string) error {
 	return err
 }
 
+func (s *Store) SetCollectionPublic(id string, public bool) error {
+	res, err := s.db.Exec(`UPDATE collections SET public = ?, updated_at = ? WHERE id = ?`, public, time.Now(), id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("%w: %s", ErrCollectionNotFound, id)
+	}
+	return nil
+}
+
 // Annotation operations (now use DocumentID)
 
 func (s *Store) AddAnnotation(ann *Annotation) error {
-	if ann.ID == "" {
-		ann.ID = uuid.New().String()
-	}
-	ann.CreatedAt = time.Now()
+	return s.AddAnnotations([]*Annotation{ann})
+}
 
-	_, err := s.db.Exec(`
-		INSERT INTO annotations (id, document_id, type, content, page, position, color, created_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
-	`, ann.ID, ann.DocumentID, ann.Type, ann.Content, ann.Page, ann.Position, ann.Color, ann.CreatedAt)
+var annotationInsertColumns = []string{"id", "document_id", "type", "content", "page", "position", "color", "session_id", "parent_id", "created_at"}
 
-	return err
+// AddAnnotations adds annotations in one transaction. Replies must come
+// with their parents' IDs set.
+func (s *Store) AddAnnotations(anns []*Annotation) error {
+	rows := make([][]any, len(anns))
+	for i, ann := range anns {
+		if err := validateAnnotation(ann); err != nil {
+			return err
+		}
+		if ann.ID == "" {
+			ann.ID = uuid.New().String()
+		}
+		if ann.CreatedAt.IsZero() {
+			// Imported and restored annotations keep their original time
+			ann.CreatedAt = time.Now()
+		}
+		rows[i] = []any{ann.ID, ann.DocumentID, ann.Type, ann.Content, ann.Page, ann.Position, ann.Color, ann.SessionID, ann.ParentID, ann.CreatedAt}
+	}
+	return s.bulkInsert("annotations", annotationInsertColumns, rows)
 }
 
 func (s *Store) GetAnnotations(documentID string) ([]*Annotation, error) {
 	rows, err := s.db.Query(`
-		SELECT id, document_id, type, content, page, position, color, created_at
+		SELECT id, document_id, type, content, page, position, color, session_id, parent_id, created_at
 		FROM annotations WHERE document_id = ? ORDER BY page, created_at
 	`, documentID)
 	if err != nil {
@@ -610,10 +1238,13 @@ func (s *Store) GetAnnotations(documentID string) ([]*Annotation, error) {
 	var annotations []*Annotation
 	for rows.Next() {
 		var a Annotation
-		var content, position, color sql.NullString
+		var content, position, color, sessionID, parentID sql.NullString
 		var page sql.NullInt64
 
-		if err := rows.Scan(&a.ID, &a.DocumentID, &a.Type, &content, &page, &position, &color, &a.CreatedAt); err != nil {
+		if err := rows.Scan(&a.ID, &a.DocumentID, &a.Type, &content, &page, &position, &color, &sessionID, &parentID, &a.CreatedAt); err != nil {
+			if err := unreadable("annotation", a.ID, err); err != nil {
+				return nil, err
+			}
 			continue
 		}
 
@@ -626,6 +1257,8 @@ func (s *Store) GetAnnotations(documentID string) ([]*Annotation, error) {
 		if color.Valid {
 			a.Color = color.String
 		}
+		a.SessionID = sessionID.String
+		a.ParentID = parentID.String
 		if page.Valid {
 			a.Page = int(page.Int64)
 		}
@@ -641,6 +1274,53 @@ func (s *Store) DeleteAnnotation(id string) error {
 	return err
 }
 
+// Document link operations
+
+func (s *Store) AddDocumentLink(l *DocumentLink) error {
+	if l.ID == "" {
+		l.ID = uuid.New().String()
+	}
+	l.CreatedAt = time.Now()
+
+	res, err := s.db.Exec(`
+		INSERT OR IGNORE INTO document_links (id, from_id, to_id, relation, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, l.ID, l.FromID, l.ToID, l.Relation, l.CreatedAt)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("link already exists: %s %s %s", l.FromID, l.Relation, l.ToID)
+	}
+	return nil
+}
+
+func (s *Store) ListDocumentLinks(documentID string) ([]*DocumentLink, error) {
+	rows, err := s.db.Query(`
+		SELECT id, from_id, to_id, relation, created_at
+		FROM document_links WHERE from_id = ? OR to_id = ? ORDER BY created_at
+	`, documentID, documentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var links []*DocumentLink
+	for rows.Next() {
+		var l DocumentLink
+		if err := rows.Scan(&l.ID, &l.FromID, &l.ToID, &l.Relation, &l.CreatedAt); err != nil {
+			return nil, err
+		}
+		links = append(links, &l)
+	}
+	return links, rows.Err()
+}
+
+func (s *Store) DeleteDocumentLink(id string) error {
+	_, err := s.db.Exec(`DELETE FROM document_links WHERE id = ?`, id)
+	return err
+}
+
 // Reading session operations (Phase 1)
 
 func (s *Store) StartSession(documentID string) (*ReadingSession, error) {
@@ -682,35 +1362,87 @@ func (s *Store) ListSessions(documentID string) ([]*ReadingSession, error) {
 	for rows.Next() {
 		var s ReadingSession
 		var endAt sql.NullTime
-		if err := rows.Scan(&s.ID, &s.DocumentID, &s.StartAt, &endAt, &s.PagesRead, &s.Notes); err != nil {
+		// A session still open has no pages or notes yet
+		var pages sql.NullInt64
+		var notes sql.NullString
+		if err := rows.Scan(&s.ID, &s.DocumentID, &s.StartAt, &endAt, &pages, &notes); err != nil {
+			if err := unreadable("session", s.ID, err); err != nil {
+				return nil, err
+			}
 			continue
 		}
 		if endAt.Valid {
 			s.EndAt = endAt.Time
 		}
+		s.PagesRead = int(pages.Int64)
+		s.Notes = notes.String
 		sessions = append(sessions, &s)
 	}
 	return sessions, nil
 }
 
+// Aggregate operations
+
+func (s *Store) CountDocumentsByType() (map[DocumentType]int, error) {
+	rows, err := s.db.Query(`SELECT type, COUNT(*) FROM documents GROUP BY type`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[DocumentType]int)
+	for rows.Next() {
+		var t DocumentType
+		var n int
+		if err := rows.Scan(&t, &n); err != nil {
+			return nil, err
+		}
+		counts[t] = n
+	}
+	return counts, rows.Err()
+}
+
+func (s *Store) CountAnnotations() (int, error) {
+	// Joined so annotations of deleted documents are not counted when
+	// foreign-key cascades are off
+	var n int
+	err := s.db.QueryRow(`
+		SELECT COUNT(*) FROM annotations a JOIN documents d ON d.id = a.document_id
+	`).Scan(&n)
+	return n, err
+}
+
+func (s *Store) SessionTotals() (sessions, pagesRead int, err error) {
+	err = s.db.QueryRow(`
+		SELECT COUNT(*), COALESCE(SUM(r.pages_read), 0)
+		FROM reading_sessions r JOIN documents d ON d.id = r.document_id
+	`).Scan(&sessions, &pagesRead)
+	return sessions, pagesRead, err
+}
+
 // Flashcard operations (Phase 2)
 
 func (s *Store) AddFlashcard(card *Flashcard) error {
-	if card.ID == "" {
-		card.ID = uuid.New().String()
-	}
-	now := time.Now()
-	card.CreatedAt = now
-	card.UpdatedAt = now
+	return s.AddFlashcards([]*Flashcard{card})
+}
 
-	tagsJSON, _ := json.Marshal(card.Tags)
+var flashcardInsertColumns = []string{"id", "document_id", "type", "front", "back", "cloze", "tags", "due_at", "interval", "ease", "last_review", "created_at", "updated_at"}
 
-	_, err := s.db.Exec(`
-		INSERT INTO flashcards (id, document_id, type, front, back, cloze, tags, due_at, interval, ease, last_review, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, card.ID, card.DocumentID, card.Type, card.Front, card.Back, card.Cloze, string(tagsJSON), card.DueAt, card.Interval, card.Ease, card.LastReview, card.CreatedAt, card.UpdatedAt)
+// AddFlashcards adds flashcards in one transaction.
+func (s *Store) AddFlashcards(cards []*Flashcard) error {
+	now := time.Now()
+	rows := make([][]any, len(cards))
+	for i, card := range cards {
+		if card.ID == "" {
+			card.ID = uuid.New().String()
+		}
+		card.CreatedAt = now
+		card.UpdatedAt = now
 
-	return err
+		tagsJSON, _ := json.Marshal(card.Tags)
+		rows[i] = []any{card.ID, card.DocumentID, card.Type, card.Front, card.Back, card.Cloze, string(tagsJSON), card.DueAt.UTC(), card.Interval, card.Ease, card.LastReview, card.CreatedAt, card.UpdatedAt}
+	}
+	return s.bulkInsert("flashcards", flashcardInsertColumns, rows)
 }
 
 func (s *Store) GetFlashcard(id string) (*Flashcard, error) {
@@ -738,10 +1470,14 @@ func scanFlashcard(row *sql.Row) (*Flashcard, error) {
 		c.LastReview = lastReview.Time
 	}
 
-	json.Unmarshal([]byte(tagsJSON), &c.Tags)
+	if err := unmarshalField("flashcard", c.ID, "tags", []byte(tagsJSON), &c.Tags); err != nil {
+		return nil, err
+	}
 	return &c, nil
 }
 
+// scanFlashcardFromRows scans a flashcard row, or returns nil for a row
+// it cannot read (see unreadable).
 func scanFlashcardFromRows(rows *sql.Rows) (*Flashcard, error) {
 	var c Flashcard
 	var tagsJSON string
@@ -749,14 +1485,17 @@ func scanFlashcardFromRows(rows *sql.Rows) (*Flashcard, error) {
 
 	err := rows.Scan(&c.ID, &c.DocumentID, &c.Type, &c.Front, &c.Back, &c.Cloze, &tagsJSON, &c.DueAt, &c.Interval, &c.Ease, &lastReview, &c.CreatedAt, &c.UpdatedAt)
 	if err != nil {
-		return nil, err
+		// Unreadable rather than failed, so listings can skip the card
+		return nil, unreadable("flashcard", c.ID, err)
 	}
 
 	if lastReview.Valid {
 		c.LastReview = lastReview.Time
 	}
 
-	json.Unmarshal([]byte(tagsJSON), &c.Tags)
+	if err := unmarshalField("flashcard", c.ID, "tags", []byte(tagsJSON), &c.Tags); err != nil {
+		return nil, err
+	}
 	return &c, nil
 }
 
@@ -774,8 +1513,8 @@ func (s *Store) ListFlashcards(opts *FlashcardListOptions) ([]*Flashcard, error)
 			args = append(args, "%"+opts.Tag+"%")
 		}
 		if opts.Due {
-			query += ` AND due_at <= ?`
-			args = append(args, time.Now())
+			query += ` AND library_unix_micro(due_at) < ?`
+			args = append(args, dueBy(Now()).UnixMicro())
 		}
 	}
 
@@ -795,9 +1534,11 @@ func (s *Store) ListFlashcards(opts *FlashcardListOptions) ([]*Flashcard, error)
 	for rows.Next() {
 		c, err := scanFlashcardFromRows(rows)
 		if err != nil {
-			continue
+			return nil, err
+		}
+		if c != nil {
+			cards = append(cards, c)
 		}
-		cards = append(cards, c)
 	}
 
 	return cards, nil
@@ -812,7 +1553,7 @@ func (s *Store) UpdateFlashcard(card *Flashcard) error {
 		UPDATE flashcards
 		SET document_id = ?, type = ?, front = ?, back = ?, cloze = ?, tags = ?, due_at = ?, interval = ?, ease = ?, last_review = ?, updated_at = ?
 		WHERE id = ?
-	`, card.DocumentID, card.Type, card.Front, card.Back, card.Cloze, string(tagsJSON), card.DueAt, card.Interval, card.Ease, card.LastReview, card.UpdatedAt, card.ID)
+	`, card.DocumentID, card.Type, card.Front, card.Back, card.Cloze, string(tagsJSON), card.DueAt.UTC(), card.Interval, card.Ease, card.LastReview, card.UpdatedAt, card.ID)
 
 	return err
 }
@@ -830,57 +1571,25 @@ func (s *Store) ReviewFlashcard(id string, quality int) (*Flashcard, error) {
 		return nil, err
 	}
 	if card == nil {
-		return nil, fmt.Errorf("flashcard not found: %s", id)
+		return nil, fmt.Errorf("%w: %s", ErrFlashcardNotFound, id)
 	}
 
-	now := time.Now()
+	now := time.Now().UTC()
 
 	// Capture previous values for review record
 	prevInterval := card.Interval
+	sched := scheduler.Current()
 	prevEase := card.Ease
 	if prevEase == 0 {
-		prevEase = 2.5 // initial default
-	}
-
-	// SM-2 algorithm
-	// quality: 0-5 (0=complete blackout, 5=perfect)
-	// ease: factor by which interval multiplies (start at 2.5, range 1.3-2.5)
-	// interval: days until next review
-
-	ease := prevEase
-
-	// Update ease
-	ease = ease + (0.1 - (float64(5-quality)*(0.08+float64(5-quality)*0.02)))
-	if ease < 1.3 {
-		ease = 1.3
-	}
-	if ease > 2.5 {
-		ease = 2.5
+		prevEase = sched.InitialEase
 	}
 
-	// Calculate new interval
-	var interval int
-	if quality < 3 {
-		// Fail: reset to 1 day
-		interval = 1
-	} else {
-		// Graduating or higher
-		if prevInterval == 0 {
-			// First successful review: interval = 1
-			interval = 1
-		} else if prevInterval == 1 {
-			// Second: interval = 6 days
-			interval = 6
-		} else {
-			// Normal: interval = interval * ease
-			interval = int(float64(prevInterval) * ease)
-		}
-	}
+	interval, ease := sched.Next(prevInterval, prevEase, quality)
 
 	// Update card
 	card.Interval = interval
 	card.Ease = ease
-	card.DueAt = now.AddDate(0, 0, interval)
+	card.DueAt = DueDate(now.In(HomeLocation()), interval)
 	card.LastReview = now
 	card.UpdatedAt = now
 
@@ -897,12 +1606,9 @@ func (s *Store) ReviewFlashcard(id string, quality int) (*Flashcard, error) {
 		ReviewedAt:   now,
 		PrevInterval: prevInterval,
 		PrevEase:     prevEase,
+		Params:       sched.String(),
 	}
-	_, err = s.db.Exec(`
-		INSERT INTO flashcard_reviews (id, flashcard_id, quality, reviewed_at, prev_interval, prev_ease)
-		VALUES (?, ?, ?, ?, ?, ?)
-	`, review.ID, review.FlashcardID, review.Quality, review.ReviewedAt, review.PrevInterval, review.PrevEase)
-	if err != nil {
+	if err := s.AddFlashcardReview(review); err != nil {
 		// Log but don't fail the review
 		fmt.Printf("Warning: could not store review: %v\n", err)
 	}
@@ -910,9 +1616,20 @@ func (s *Store) ReviewFlashcard(id string, quality int) (*Flashcard, error) {
 	return card, nil
 }
 
+func (s *Store) AddFlashcardReview(review *FlashcardReview) error {
+	if review.ID == "" {
+		review.ID = fmt.Sprintf("review:%d", time.Now().UnixNano())
+	}
+	_, err := s.db.Exec(`
+		INSERT INTO flashcard_reviews (id, flashcard_id, quality, reviewed_at, prev_interval, prev_ease, params)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, review.ID, review.FlashcardID, review.Quality, review.ReviewedAt, review.PrevInterval, review.PrevEase, review.Params)
+	return err
+}
+
 func (s *Store) ListFlashcardReviews(flashcardID string) ([]*FlashcardReview, error) {
 	rows, err := s.db.Query(`
-		SELECT id, flashcard_id, quality, reviewed_at, prev_interval, prev_ease
+		SELECT id, flashcard_id, quality, reviewed_at, prev_interval, prev_ease, params
 		FROM flashcard_reviews WHERE flashcard_id = ? ORDER BY reviewed_at DESC
 	`, flashcardID)
 	if err != nil {
@@ -923,9 +1640,15 @@ func (s *Store) ListFlashcardReviews(flashcardID string) ([]*FlashcardReview, er
 	var reviews []*FlashcardReview
 	for rows.Next() {
 		var r FlashcardReview
-		if err := rows.Scan(&r.ID, &r.FlashcardID, &r.Quality, &r.ReviewedAt, &r.PrevInterval, &r.PrevEase); err != nil {
+		// Reviews from before the settings were recorded have none
+		var params sql.NullString
+		if err := rows.Scan(&r.ID, &r.FlashcardID, &r.Quality, &r.ReviewedAt, &r.PrevInterval, &r.PrevEase, &params); err != nil {
+			if err := unreadable("flashcard review", r.ID, err); err != nil {
+				return nil, err
+			}
 			continue
 		}
+		r.Params = params.String
 		reviews = append(reviews, &r)
 	}
 	return reviews, nil
@@ -934,8 +1657,8 @@ func (s *Store) ListFlashcardReviews(flashcardID string) ([]*FlashcardReview, er
 func (s *Store) GetDueFlashcards(now time.Time) ([]*Flashcard, error) {
 	rows, err := s.db.Query(`
 		SELECT id, document_id, type, front, back, cloze, tags, due_at, interval, ease, last_review, created_at, updated_at
-		FROM flashcards WHERE due_at <= ? ORDER BY due_at ASC
-	`, now)
+		FROM flashcards WHERE library_unix_micro(due_at) < ? ORDER BY due_at ASC
+	`, dueBy(now).UnixMicro())
 	if err != nil {
 		return nil, err
 	}
@@ -945,9 +1668,11 @@ func (s *Store) GetDueFlashcards(now time.Time) ([]*Flashcard, error) {
 	for rows.Next() {
 		c, err := scanFlashcardFromRows(rows)
 		if err != nil {
-			continue
+			return nil, err
+		}
+		if c != nil {
+			cards = append(cards, c)
 		}
-		cards = append(cards, c)
 	}
 	return cards, nil
 }
@@ -963,15 +1688,18 @@ func (s *Store) AddTask(t *Task) error {
 
 	tagsJSON, _ := json.Marshal(t.Tags)
 	
-	var dueAt interface{}
+	var dueAt, completedAt interface{}
 	if t.DueAt != nil {
-		dueAt = *t.DueAt
+		dueAt = t.DueAt.UTC()
+	}
+	if t.CompletedAt != nil {
+		completedAt = *t.CompletedAt
 	}
 
 	_, err := s.db.Exec(`
-		INSERT INTO tasks (id, description, collection_id, status, priority, tags, due_at, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, t.ID, t.Description, t.CollectionID, t.Status, t.Priority, string(tagsJSON), dueAt, t.CreatedAt, t.UpdatedAt)
+		INSERT INTO tasks (id, description, collection_id, document_id, parent_id, status, priority, tags, repeat, repeat_day, due_at, completed_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, t.ID, t.Description, t.CollectionID, t.DocumentID, t.ParentID, t.Status, t.Priority, string(tagsJSON), t.Repeat, t.RepeatDay, dueAt, completedAt, t.CreatedAt, t.UpdatedAt)
 	
 	return err
 }
@@ -979,13 +1707,14 @@ func (s *Store) AddTask(t *Task) error {
 func (s *Store) GetTask(id string) (*Task, error) {
 	var t Task
 	var tagsJSON string
+	var documentID, parentID, repeat sql.NullString
 	var dueAt sql.NullTime
 	var completedAt sql.NullTime
 
 	err := s.db.QueryRow(`
-		SELECT id, description, collection_id, status, priority, tags, due_at, created_at, updated_at
+		SELECT id, description, collection_id, document_id, parent_id, status, priority, tags, repeat, repeat_day, due_at, completed_at, created_at, updated_at
 		FROM tasks WHERE id = ?
-	`, id).Scan(&t.ID, &t.Description, &t.CollectionID, &t.Status, &t.Priority, &tagsJSON, &dueAt, &t.CreatedAt, &t.UpdatedAt)
+	`, id).Scan(&t.ID, &t.Description, &t.CollectionID, &documentID, &parentID, &t.Status, &t.Priority, &tagsJSON, &repeat, &t.RepeatDay, &dueAt, &completedAt, &t.CreatedAt, &t.UpdatedAt)
 	
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -994,7 +1723,12 @@ func (s *Store) GetTask(id string) (*Task, error) {
 		return nil, err
 	}
 
-	json.Unmarshal([]byte(tagsJSON), &t.Tags)
+	if err := unmarshalField("task", t.ID, "tags", []byte(tagsJSON), &t.Tags); err != nil {
+		return nil, err
+	}
+	t.DocumentID = documentID.String
+	t.ParentID = parentID.String
+	t.Repeat = repeat.String
 	if dueAt.Valid {
 		t.DueAt = &dueAt.Time
 	}
@@ -1006,7 +1740,7 @@ func (s *Store) GetTask(id string) (*Task, error) {
 }
 
 func (s *Store) ListTasks(opts *TaskListOptions) ([]*Task, error) {
-	query := `SELECT id, description, collection_id, status, priority, tags, due_at, created_at, updated_at FROM tasks WHERE 1=1`
+	query := `SELECT id, description, collection_id, document_id, parent_id, status, priority, tags, repeat, repeat_day, due_at, completed_at, created_at, updated_at FROM tasks WHERE 1=1`
 	var args []any
 
 	if opts != nil {
@@ -1014,10 +1748,26 @@ func (s *Store) ListTasks(opts *TaskListOptions) ([]*Task, error) {
 			query += ` AND collection_id = ?`
 			args = append(args, opts.CollectionID)
 		}
+		if opts.DocumentID != "" {
+			query += ` AND document_id = ?`
+			args = append(args, opts.DocumentID)
+		}
+		if opts.ParentID != "" {
+			query += ` AND parent_id = ?`
+			args = append(args, opts.ParentID)
+		}
 		if opts.Status != "" {
 			query += ` AND status = ?`
 			args = append(args, opts.Status)
 		}
+		if opts.Open {
+			query += ` AND status != ?`
+			args = append(args, TaskDone)
+		}
+		if !opts.DueBefore.IsZero() {
+			query += ` AND library_unix_micro(due_at) < ?`
+			args = append(args, opts.DueBefore.UnixMicro())
+		}
 	}
 
 	query += ` ORDER BY created_at DESC`
@@ -1036,17 +1786,29 @@ func (s *Store) ListTasks(opts *TaskListOptions) ([]*Task, error) {
 	for rows.Next() {
 		var t Task
 		var tagsJSON string
-		var dueAt sql.NullTime
+		var documentID, parentID, repeat sql.NullString
+		var dueAt, completedAt sql.NullTime
 		
-		err := rows.Scan(&t.ID, &t.Description, &t.CollectionID, &t.Status, &t.Priority, &tagsJSON, &dueAt, &t.CreatedAt, &t.UpdatedAt)
+		err := rows.Scan(&t.ID, &t.Description, &t.CollectionID, &documentID, &parentID, &t.Status, &t.Priority, &tagsJSON, &repeat, &t.RepeatDay, &dueAt, &completedAt, &t.CreatedAt, &t.UpdatedAt)
 		if err != nil {
+			if err := unreadable("task", t.ID, err); err != nil {
+				return nil, err
+			}
 			continue
 		}
 		
-		json.Unmarshal([]byte(tagsJSON), &t.Tags)
+		if err := unmarshalField("task", t.ID, "tags", []byte(tagsJSON), &t.Tags); err != nil {
+			return nil, err
+		}
+		t.DocumentID = documentID.String
+		t.ParentID = parentID.String
+		t.Repeat = repeat.String
 		if dueAt.Valid {
 			t.DueAt = &dueAt.Time
 		}
+		if completedAt.Valid {
+			t.CompletedAt = &completedAt.Time
+		}
 		
 		tasks = append(tasks, &t)
 	}
@@ -1058,15 +1820,18 @@ func (s *Store) UpdateTask(t *Task) error {
 	t.UpdatedAt = time.Now()
 	tagsJSON, _ := json.Marshal(t.Tags)
 	
-	var dueAt interface{}
+	var dueAt, completedAt interface{}
 	if t.DueAt != nil {
-		dueAt = *t.DueAt
+		dueAt = t.DueAt.UTC()
+	}
+	if t.CompletedAt != nil {
+		completedAt = *t.CompletedAt
 	}
 
 	_, err := s.db.Exec(`
-		UPDATE tasks SET description = ?, collection_id = ?, status = ?, priority = ?, tags = ?, due_at = ?, updated_at = ?
+		UPDATE tasks SET description = ?, collection_id = ?, document_id = ?, parent_id = ?, status = ?, priority = ?, tags = ?, repeat = ?, repeat_day = ?, due_at = ?, completed_at = ?, updated_at = ?
 		WHERE id = ?
-	`, t.Description, t.CollectionID, t.Status, t.Priority, string(tagsJSON), dueAt, t.UpdatedAt, t.ID)
+	`, t.Description, t.CollectionID, t.DocumentID, t.ParentID, t.Status, t.Priority, string(tagsJSON), t.Repeat, t.RepeatDay, dueAt, completedAt, t.UpdatedAt, t.ID)
 	
 	return err
 }
@@ -1076,6 +1841,529 @@ func (s *Store) DeleteTask(id string) error {
 	return err
 }
 
+// Reading queue operations
+
+func (s *Store) GetReadingQueue() ([]string, error) {
+	rows, err := s.db.Query(`SELECT document_id FROM reading_queue ORDER BY position`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (s *Store) SetReadingQueue(documentIDs []string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM reading_queue`); err != nil {
+		return err
+	}
+	for i, id := range documentIDs {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO reading_queue (document_id, position) VALUES (?, ?)`, id, i); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Operation journal
+
+func (s *Store) RecordOperation(op *Operation) error {
+	if op.ID == "" {
+		op.ID = uuid.New().String()
+	}
+	op.CreatedAt = time.Now()
+
+	_, err := s.db.Exec(`
+		INSERT INTO operations (id, kind, summary, data, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, op.ID, op.Kind, op.Summary, string(op.Data), op.CreatedAt)
+	return err
+}
+
+func (s *Store) ListOperations(limit int) ([]*Operation, error) {
+	query := `SELECT id, kind, summary, data, created_at FROM operations ORDER BY created_at DESC, rowid DESC`
+	if limit > 0 {
+		query += fmt.Sprintf(` LIMIT %d`, limit)
+	}
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ops []*Operation
+	for rows.Next() {
+		var op Operation
+		var data string
+		if err := rows.Scan(&op.ID, &op.Kind, &op.Summary, &data, &op.CreatedAt); err != nil {
+			return nil, err
+		}
+		op.Data = json.RawMessage(data)
+		ops = append(ops, &op)
+	}
+	return ops, rows.Err()
+}
+
+func (s *Store) DeleteOperation(id string) error {
+	_, err := s.db.Exec(`DELETE FROM operations WHERE id = ?`, id)
+	return err
+}
+
+// Text signatures
+
+func (s *Store) ListTextSignatures() ([]*TextSignature, error) {
+	rows, err := s.db.Query(`SELECT document_id, text_hash, minhash, updated_at FROM text_signatures`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sigs []*TextSignature
+	for rows.Next() {
+		var sig TextSignature
+		var hash int64
+		var minhash sql.NullString
+		if err := rows.Scan(&sig.DocumentID, &hash, &minhash, &sig.UpdatedAt); err != nil {
+			return nil, err
+		}
+		// Hashes are stored as their signed bit pattern; SQLite integers are 64-bit signed
+		sig.TextHash = uint64(hash)
+		if minhash.Valid {
+			if err := unmarshalField("text signature", sig.DocumentID, "minhash", []byte(minhash.String), &sig.MinHash); err != nil {
+				return nil, err
+			}
+		}
+		sigs = append(sigs, &sig)
+	}
+	return sigs, rows.Err()
+}
+
+func (s *Store) SaveTextSignature(sig *TextSignature) error {
+	minhash, _ := json.Marshal(sig.MinHash)
+	_, err := s.db.Exec(`
+		INSERT INTO text_signatures (document_id, text_hash, minhash, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(document_id) DO UPDATE SET
+			text_hash = excluded.text_hash,
+			minhash = excluded.minhash,
+			updated_at = excluded.updated_at
+	`, sig.DocumentID, int64(sig.TextHash), string(minhash), sig.UpdatedAt)
+	return err
+}
+
+// Document review schedules
+
+func (s *Store) GetDocumentReview(documentID string) (*DocumentReview, error) {
+	r := &DocumentReview{}
+	err := s.db.QueryRow(`
+		SELECT document_id, due_at, interval, ease, reviews, last_quality, last_review
+		FROM document_reviews WHERE document_id = ?
+	`, documentID).Scan(&r.DocumentID, &r.DueAt, &r.Interval, &r.Ease, &r.Reviews, &r.LastQuality, &r.LastReview)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (s *Store) SaveDocumentReview(r *DocumentReview) error {
+	_, err := s.db.Exec(`
+		INSERT INTO document_reviews (document_id, due_at, interval, ease, reviews, last_quality, last_review)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(document_id) DO UPDATE SET
+			due_at = excluded.due_at,
+			interval = excluded.interval,
+			ease = excluded.ease,
+			reviews = excluded.reviews,
+			last_quality = excluded.last_quality,
+			last_review = excluded.last_review
+	`, r.DocumentID, r.DueAt.UTC(), r.Interval, r.Ease, r.Reviews, r.LastQuality, r.LastReview.UTC())
+	return err
+}
+
+func (s *Store) ListDocumentReviews() ([]*DocumentReview, error) {
+	rows, err := s.db.Query(`
+		SELECT document_id, due_at, interval, ease, reviews, last_quality, last_review
+		FROM document_reviews ORDER BY due_at
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reviews []*DocumentReview
+	for rows.Next() {
+		r := &DocumentReview{}
+		if err := rows.Scan(&r.DocumentID, &r.DueAt, &r.Interval, &r.Ease, &r.Reviews, &r.LastQuality, &r.LastReview); err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, r)
+	}
+	return reviews, rows.Err()
+}
+
+func (s *Store) DeleteDocumentReview(documentID string) error {
+	_, err := s.db.Exec(`DELETE FROM document_reviews WHERE document_id = ?`, documentID)
+	return err
+}
+
+// Document sections, stored as JSON
+
+func (s *Store) GetDocumentSections(documentID string) (*DocumentSections, error) {
+	var data string
+	var hash int64
+	err := s.db.QueryRow(`SELECT text_hash, data FROM document_sections WHERE document_id = ?`, documentID).Scan(&hash, &data)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var secs DocumentSections
+	if err := json.Unmarshal([]byte(data), &secs); err != nil {
+		return nil, fmt.Errorf("unmarshal sections: %w", err)
+	}
+	// Hashes are stored as their signed bit pattern, as for text signatures
+	secs.TextHash = uint64(hash)
+	return &secs, nil
+}
+
+func (s *Store) SaveDocumentSections(secs *DocumentSections) error {
+	data, err := json.Marshal(secs)
+	if err != nil {
+		return fmt.Errorf("marshal sections: %w", err)
+	}
+	_, err = s.db.Exec(`
+		INSERT INTO document_sections (document_id, text_hash, data, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(document_id) DO UPDATE SET
+			text_hash = excluded.text_hash,
+			data = excluded.data,
+			updated_at = excluded.updated_at
+	`, secs.DocumentID, int64(secs.TextHash), string(data), secs.UpdatedAt)
+	return err
+}
+
+// Document references, stored as JSON
+
+func (s *Store) GetDocumentReferences(documentID string) (*DocumentReferences, error) {
+	var data string
+	var hash int64
+	err := s.db.QueryRow(`SELECT text_hash, data FROM document_references WHERE document_id = ?`, documentID).Scan(&hash, &data)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var refs DocumentReferences
+	if err := json.Unmarshal([]byte(data), &refs); err != nil {
+		return nil, fmt.Errorf("unmarshal references: %w", err)
+	}
+	refs.TextHash = uint64(hash)
+	return &refs, nil
+}
+
+func (s *Store) SaveDocumentReferences(refs *DocumentReferences) error {
+	data, err := json.Marshal(refs)
+	if err != nil {
+		return fmt.Errorf("marshal references: %w", err)
+	}
+	_, err = s.db.Exec(`
+		INSERT INTO document_references (document_id, text_hash, data, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(document_id) DO UPDATE SET
+			text_hash = excluded.text_hash,
+			data = excluded.data,
+			updated_at = excluded.updated_at
+	`, refs.DocumentID, int64(refs.TextHash), string(data), refs.UpdatedAt)
+	return err
+}
+
+// Zotero sync state, stored as JSON
+
+func (s *Store) GetZoteroSyncState(library string) (*ZoteroSyncState, error) {
+	var data string
+	err := s.db.QueryRow(`SELECT data FROM zotero_sync WHERE library = ?`, library).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var state ZoteroSyncState
+	if err := json.Unmarshal([]byte(data), &state); err != nil {
+		return nil, fmt.Errorf("unmarshal zotero sync state: %w", err)
+	}
+	return &state, nil
+}
+
+func (s *Store) SaveZoteroSyncState(state *ZoteroSyncState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("marshal zotero sync state: %w", err)
+	}
+	_, err = s.db.Exec(`
+		INSERT INTO zotero_sync (library, data, synced_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(library) DO UPDATE SET
+			data = excluded.data,
+			synced_at = excluded.synced_at
+	`, state.Library, string(data), state.SyncedAt)
+	return err
+}
+
+// Custom field schema
+
+func (s *Store) DefineField(def *FieldDef) error {
+	if def.CreatedAt.IsZero() {
+		def.CreatedAt = time.Now()
+	}
+	values, _ := json.Marshal(def.Values)
+	_, err := s.db.Exec(`
+		INSERT INTO field_defs (name, type, enum_values, created_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET
+			type = excluded.type,
+			enum_values = excluded.enum_values
+	`, def.Name, def.Type, string(values), def.CreatedAt)
+	return err
+}
+
+func (s *Store) ListFields() ([]*FieldDef, error) {
+	rows, err := s.db.Query(`SELECT name, type, enum_values, created_at FROM field_defs ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var defs []*FieldDef
+	for rows.Next() {
+		var def FieldDef
+		var values sql.NullString
+		if err := rows.Scan(&def.Name, &def.Type, &values, &def.CreatedAt); err != nil {
+			return nil, err
+		}
+		if values.Valid {
+			if err := unmarshalField("field", def.Name, "values", []byte(values.String), &def.Values); err != nil {
+				return nil, err
+			}
+		}
+		defs = append(defs, &def)
+	}
+	return defs, rows.Err()
+}
+
+func (s *Store) DeleteField(name string) error {
+	_, err := s.db.Exec(`DELETE FROM field_defs WHERE name = ?`, name)
+	return err
+}
+
+// ftsQuery quotes each word of a search so FTS5 reads punctuation such as
+// the dot in an arXiv ID literally; all words must match.
+func ftsQuery(search string) string {
+	words := strings.Fields(search)
+	for i, w := range words {
+		words[i] = `"` + strings.ReplaceAll(w, `"`, `""`) + `"`
+	}
+	return strings.Join(words, " ")
+}
+
+// fieldFilterSQL renders a custom field condition against the meta column,
+// mirroring FieldFilter.Match: values of the wrong JSON type never match.
+func fieldFilterSQL(f FieldFilter) (string, []any) {
+	path := "$." + f.Field.Name
+	var types string
+	switch f.Field.Type {
+	case FieldInt, FieldFloat:
+		types = `'integer', 'real'`
+	case FieldBool:
+		types = `'true', 'false'`
+	default:
+		types = `'text'`
+	}
+	value := f.Value
+	if b, ok := value.(bool); ok {
+		// json_extract reports JSON booleans as 1 and 0
+		value = 0
+		if b {
+			value = 1
+		}
+	}
+	clause := fmt.Sprintf(`json_type(meta, ?) IN (%s) AND json_extract(meta, ?) %s ?`, types, f.Op)
+	return clause, []any{path, path, value}
+}
+
+// Share links
+
+func (s *Store) CreateShare(sh *Share) error {
+	if sh.CreatedAt.IsZero() {
+		sh.CreatedAt = time.Now()
+	}
+	_, err := s.db.Exec(`
+		INSERT INTO shares (token, document_id, include_file, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, sh.Token, sh.DocumentID, sh.IncludeFile, sh.ExpiresAt, sh.CreatedAt)
+	return err
+}
+
+func (s *Store) GetShare(token string) (*Share, error) {
+	var sh Share
+	err := s.db.QueryRow(`
+		SELECT token, document_id, include_file, expires_at, created_at FROM shares WHERE token = ?
+	`, token).Scan(&sh.Token, &sh.DocumentID, &sh.IncludeFile, &sh.ExpiresAt, &sh.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &sh, nil
+}
+
+func (s *Store) ListShares() ([]*Share, error) {
+	rows, err := s.db.Query(`
+		SELECT token, document_id, include_file, expires_at, created_at FROM shares ORDER BY created_at DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var shares []*Share
+	for rows.Next() {
+		var sh Share
+		if err := rows.Scan(&sh.Token, &sh.DocumentID, &sh.IncludeFile, &sh.ExpiresAt, &sh.CreatedAt); err != nil {
+			return nil, err
+		}
+		shares = append(shares, &sh)
+	}
+	return shares, rows.Err()
+}
+
+func (s *Store) DeleteShare(token string) error {
+	_, err := s.db.Exec(`DELETE FROM shares WHERE token = ?`, token)
+	return err
+}
+
+// API tokens
+
+func (s *Store) CreateAPIToken(t *APIToken) error {
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = time.Now()
+	}
+	_, err := s.db.Exec(`
+		INSERT INTO api_tokens (id, name, scope, hash, created_at) VALUES (?, ?, ?, ?, ?)
+	`, t.ID, t.Name, t.Scope, t.Hash, t.CreatedAt)
+	if err != nil && strings.Contains(err.Error(), "UNIQUE") {
+		return fmt.Errorf("a token named %q already exists", t.Name)
+	}
+	return err
+}
+
+func (s *Store) ListAPITokens() ([]*APIToken, error) {
+	rows, err := s.db.Query(`SELECT id, name, scope, hash, created_at FROM api_tokens ORDER BY created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []*APIToken
+	for rows.Next() {
+		var t APIToken
+		if err := rows.Scan(&t.ID, &t.Name, &t.Scope, &t.Hash, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, &t)
+	}
+	return tokens, rows.Err()
+}
+
+func (s *Store) DeleteAPIToken(id string) error {
+	_, err := s.db.Exec(`DELETE FROM api_tokens WHERE id = ?`, id)
+	return err
+}
+
+// Audit log
+
+func (s *Store) AppendAuditEntry(e *AuditEntry) error {
+	if e.ID == "" {
+		e.ID = fmt.Sprintf("audit:%d", time.Now().UnixNano())
+	}
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+	fieldsJSON, _ := json.Marshal(e.Fields)
+	_, err := s.db.Exec(`
+		INSERT INTO audit_log (id, entity, entity_id, document_id, action, actor, summary, fields, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, e.ID, e.Entity, e.EntityID, e.DocumentID, e.Action, e.Actor, e.Summary, string(fieldsJSON), e.CreatedAt)
+	return err
+}
+
+func (s *Store) ListAuditEntries(opts *AuditListOptions) ([]*AuditEntry, error) {
+	query := `SELECT id, entity, entity_id, COALESCE(document_id, ''), action, actor, COALESCE(summary, ''), COALESCE(fields, ''), created_at FROM audit_log WHERE 1=1`
+	var args []any
+	if opts != nil {
+		if !opts.Since.IsZero() {
+			query += ` AND library_unix_micro(created_at) >= ?`
+			args = append(args, opts.Since.UnixMicro())
+		}
+		if opts.Entity != "" {
+			query += ` AND entity = ?`
+			args = append(args, opts.Entity)
+		}
+		if opts.DocumentID != "" {
+			query += ` AND (document_id = ? OR (entity = 'document' AND entity_id = ?))`
+			args = append(args, opts.DocumentID, opts.DocumentID)
+		}
+		if opts.Actor != "" {
+			query += ` AND actor = ?`
+			args = append(args, opts.Actor)
+		}
+	}
+	query += ` ORDER BY created_at DESC, id DESC`
+	if opts != nil && opts.Limit > 0 {
+		query += fmt.Sprintf(` LIMIT %d`, opts.Limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*AuditEntry
+	for rows.Next() {
+		var e AuditEntry
+		var fieldsJSON string
+		if err := rows.Scan(&e.ID, &e.Entity, &e.EntityID, &e.DocumentID, &e.Action, &e.Actor, &e.Summary, &fieldsJSON, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := unmarshalField("audit entry", e.ID, "fields", []byte(fieldsJSON), &e.Fields); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &e)
+	}
+	return entries, rows.Err()
+}
+
 // SavedSearch operations
 
 func (s *Store) SaveSearch(ss *SavedSearch) error {
@@ -1141,6 +2429,9 @@ func (s *Store) ListSavedSearches() ([]*SavedSearch, error) {
 		var ss SavedSearch
 		err := rows.Scan(&ss.ID, &ss.Name, &ss.Query, &ss.Tag, &ss.Source, &ss.Type, &ss.Description, &ss.CreatedAt, &ss.UpdatedAt)
 		if err != nil {
+			if err := unreadable("saved search", ss.ID, err); err != nil {
+				return nil, err
+			}
 			continue
 		}
 		searches = append(searches, &ss)
@@ -0,0 +1,168 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// StudyLimits cap how many flashcards are studied a day, so a large
+// backlog is worked through over several days instead of all at once.
+// New cards are those never reviewed. 0 means no limit.
+type StudyLimits struct {
+	MaxNew    int `json:"max_new"`
+	MaxReview int `json:"max_review"`
+}
+
+// StudyLimitsFile returns the file the daily limits are kept in:
+// $ARC_LIBRARY_STUDY_LIMITS, or arc-library/study-limits.json under the
+// user config directory.
+func StudyLimitsFile() (string, error) {
+	return configFile("ARC_LIBRARY_STUDY_LIMITS", "study-limits.json")
+}
+
+// LoadStudyLimits reads the limits file. A missing file means no limits.
+func LoadStudyLimits(path string) (StudyLimits, error) {
+	var limits StudyLimits
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return limits, nil
+	}
+	if err != nil {
+		return limits, err
+	}
+	if err := json.Unmarshal(data, &limits); err != nil {
+		return limits, fmt.Errorf("%s: %w", path, err)
+	}
+	return limits, nil
+}
+
+// SaveStudyLimits writes the limits file.
+func SaveStudyLimits(path string, limits StudyLimits) error {
+	data, err := json.MarshalIndent(limits, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	// Write then rename so an interrupted save keeps the old file
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// StudyPlan is the day's allotment of flashcards: what is left of the
+// daily limits after the cards already studied today, filled from the due
+// cards, and the backlog of due cards over the limits.
+type StudyPlan struct {
+	Date        string       `json:"date"` // YYYY-MM-DD
+	Limits      StudyLimits  `json:"limits"`
+	NewDone     int          `json:"new_done"`     // new cards studied today
+	ReviewsDone int          `json:"reviews_done"` // reviews today of cards studied before
+	New         []*Flashcard `json:"new"`          // new cards left for today, oldest first
+	Review      []*Flashcard `json:"review"`       // due cards left for today, longest overdue first
+	Backlog     int          `json:"backlog"`      // due cards over today's limits
+}
+
+// Cards returns the day's remaining cards, reviews before new cards as
+// Anki shows them.
+func (p *StudyPlan) Cards() []*Flashcard {
+	return append(slices.Clip(p.Review), p.New...)
+}
+
+// PlanStudy works out the study plan of the local day containing now.
+// Cards studied today count against the limits whether or not they were
+// due: a card first reviewed today counts as new, and each review today
+// of a card first reviewed earlier counts against MaxReview.
+func PlanStudy(s LibraryStore, limits StudyLimits, now time.Time) (*StudyPlan, error) {
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	plan := &StudyPlan{Date: start.Format("2006-01-02"), Limits: limits}
+
+	cards, err := s.ListFlashcards(&FlashcardListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range cards {
+		// A card not reviewed since the day began has no reviews that day
+		if c.LastReview.Before(start) {
+			continue
+		}
+		reviews, err := s.ListFlashcardReviews(c.ID)
+		if err != nil {
+			continue
+		}
+		first, today := time.Time{}, 0
+		for _, r := range reviews {
+			if first.IsZero() || r.ReviewedAt.Before(first) {
+				first = r.ReviewedAt
+			}
+			if !r.ReviewedAt.Before(start) {
+				today++
+			}
+		}
+		if first.Before(start) {
+			plan.ReviewsDone += today
+		} else if today > 0 {
+			plan.NewDone++
+		}
+	}
+
+	due, err := s.GetDueFlashcards(now)
+	if err != nil {
+		return nil, err
+	}
+	var fresh, review []*Flashcard
+	for _, c := range due {
+		if c.LastReview.IsZero() {
+			fresh = append(fresh, c)
+		} else {
+			review = append(review, c)
+		}
+	}
+	slices.SortStableFunc(fresh, func(a, b *Flashcard) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	slices.SortStableFunc(review, func(a, b *Flashcard) int { return a.DueAt.Compare(b.DueAt) })
+
+	allot := func(cards []*Flashcard, limit, done int) []*Flashcard {
+		if limit > 0 {
+			left := max(limit-done, 0)
+			if len(cards) > left {
+				plan.Backlog += len(cards) - left
+				cards = cards[:left]
+			}
+		}
+		return cards
+	}
+	plan.New = allot(fresh, limits.MaxNew, plan.NewDone)
+	plan.Review = allot(review, limits.MaxReview, plan.ReviewsDone)
+	return plan, nil
+}
+
+// ParseCramSelector turns the argument of "flashcard study --cram" into
+// list options: tag:<tag>, doc:<id> (or document:<id>), or all.
+func ParseCramSelector(sel string) (*FlashcardListOptions, error) {
+	if sel == "all" {
+		return &FlashcardListOptions{}, nil
+	}
+	kind, value, ok := strings.Cut(sel, ":")
+	if !ok || value == "" {
+		return nil, fmt.Errorf("invalid cram selector %q (use tag:<tag>, doc:<id>, or all)", sel)
+	}
+	switch kind {
+	case "tag":
+		return &FlashcardListOptions{Tag: value}, nil
+	case "doc", "document":
+		return &FlashcardListOptions{DocumentID: value}, nil
+	}
+	return nil, fmt.Errorf("invalid cram selector %q (use tag:<tag>, doc:<id>, or all)", sel)
+}
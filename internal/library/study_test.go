@@ -0,0 +1,124 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+func TestStudyLimitsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "study-limits.json")
+	limits, err := LoadStudyLimits(path)
+	if err != nil || limits != (StudyLimits{}) {
+		t.Fatalf("missing file: %+v, %v", limits, err)
+	}
+	want := StudyLimits{MaxNew: 10, MaxReview: 100}
+	if err := SaveStudyLimits(path, want); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := LoadStudyLimits(path); err != nil || got != want {
+		t.Errorf("LoadStudyLimits = %+v, %v; want %+v", got, err, want)
+	}
+}
+
+func TestPlanStudy(t *testing.T) {
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	add := func(front string, created, lastReview time.Time) *Flashcard {
+		t.Helper()
+		c := &Flashcard{Type: "basic", Front: front, Back: "A", DueAt: now.Add(-time.Hour), LastReview: lastReview}
+		if err := s.AddFlashcard(c); err != nil {
+			t.Fatal(err)
+		}
+		// AddFlashcard stamps CreatedAt with the current time
+		c.CreatedAt = created
+		if err := s.UpdateFlashcard(c); err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	week := now.AddDate(0, 0, -7)
+	for i, front := range []string{"new1", "new2", "new3"} {
+		add(front, week.Add(time.Duration(i)*time.Hour), time.Time{})
+	}
+	for i, front := range []string{"rev1", "rev2"} {
+		c := add(front, week, week)
+		c.DueAt = now.AddDate(0, 0, -2+i)
+		if err := s.UpdateFlashcard(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	future := add("future", week, week)
+	future.DueAt = now.AddDate(0, 0, 3)
+	if err := s.UpdateFlashcard(future); err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := PlanStudy(s, StudyLimits{}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.New) != 3 || len(plan.Review) != 2 || plan.Backlog != 0 {
+		t.Fatalf("no limits: %d new, %d review, %d backlog", len(plan.New), len(plan.Review), plan.Backlog)
+	}
+
+	// One new card studied today, and one card studied before reviewed again
+	if _, err := s.ReviewFlashcard(plan.New[0].ID, 4); err != nil {
+		t.Fatal(err)
+	}
+	old := plan.Review[0]
+	if err := s.addReview(&FlashcardReview{ID: "review:old", FlashcardID: old.ID, Quality: 4, ReviewedAt: week}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ReviewFlashcard(old.ID, 4); err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err = PlanStudy(s, StudyLimits{MaxNew: 2, MaxReview: 1}, now.Add(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.NewDone != 1 || plan.ReviewsDone != 1 {
+		t.Errorf("done today: %d new, %d reviews; want 1, 1", plan.NewDone, plan.ReviewsDone)
+	}
+	// Of the two new cards left one fits under MaxNew, and no review fits
+	if len(plan.New) != 1 || plan.New[0].Front != "new2" || len(plan.Review) != 0 || plan.Backlog != 2 {
+		t.Errorf("limited: new %v, %d review, %d backlog; want [new2], 0, 2", fronts(plan.New), len(plan.Review), plan.Backlog)
+	}
+}
+
+func TestParseCramSelector(t *testing.T) {
+	tests := map[string]FlashcardListOptions{
+		"all":          {},
+		"tag:exam":     {Tag: "exam"},
+		"doc:abc":      {DocumentID: "abc"},
+		"document:abc": {DocumentID: "abc"},
+	}
+	for sel, want := range tests {
+		got, err := ParseCramSelector(sel)
+		if err != nil || *got != want {
+			t.Errorf("ParseCramSelector(%q) = %+v, %v; want %+v", sel, got, err, want)
+		}
+	}
+	for _, sel := range []string{"", "exam", "tag:", "author:x"} {
+		if _, err := ParseCramSelector(sel); err == nil {
+			t.Errorf("ParseCramSelector(%q): want error", sel)
+		}
+	}
+}
+
+func fronts(cards []*Flashcard) []string {
+	var out []string
+	for _, c := range cards {
+		out = append(out, c.Front)
+	}
+	return out
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Task statuses every workflow has: new tasks start as todo, and done
+// closes them (triggering recurrence and subtask roll-up).
+const (
+	TaskTodo = "todo"
+	TaskDone = "done"
+)
+
+// DefaultTaskStatuses is the task workflow used when none is configured,
+// in board column order.
+var DefaultTaskStatuses = []string{TaskTodo, "doing", "blocked", TaskDone}
+
+// ParseTaskStatuses parses a comma-separated task workflow such as
+// "todo,doing,review,done". Statuses are lowercased and keep their order;
+// todo and done are required. An empty string yields DefaultTaskStatuses.
+func ParseTaskStatuses(s string) ([]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return DefaultTaskStatuses, nil
+	}
+
+	var statuses []string
+	seen := make(map[string]bool)
+	for _, field := range strings.Split(s, ",") {
+		status := strings.ToLower(strings.TrimSpace(field))
+		if status == "" || seen[status] {
+			continue
+		}
+		if strings.ContainsAny(status, " \t") {
+			return nil, fmt.Errorf("invalid task status %q (no spaces)", status)
+		}
+		seen[status] = true
+		statuses = append(statuses, status)
+	}
+	if !seen[TaskTodo] || !seen[TaskDone] {
+		return nil, fmt.Errorf("task statuses %q must include %q and %q", s, TaskTodo, TaskDone)
+	}
+	return statuses, nil
+}
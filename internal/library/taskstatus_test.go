@@ -0,0 +1,30 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTaskStatuses(t *testing.T) {
+	got, err := ParseTaskStatuses("")
+	if err != nil || !reflect.DeepEqual(got, DefaultTaskStatuses) {
+		t.Errorf("ParseTaskStatuses(\"\") = %v, %v; want defaults", got, err)
+	}
+
+	got, err = ParseTaskStatuses(" Todo, doing,review ,,doing, done")
+	if err != nil {
+		t.Fatalf("ParseTaskStatuses: %v", err)
+	}
+	if want := []string{"todo", "doing", "review", "done"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseTaskStatuses = %v, want %v", got, want)
+	}
+
+	for _, bad := range []string{"doing,done", "todo,doing", "todo,in progress,done"} {
+		if _, err := ParseTaskStatuses(bad); err == nil {
+			t.Errorf("ParseTaskStatuses(%q) should fail", bad)
+		}
+	}
+}
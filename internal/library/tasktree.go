@@ -0,0 +1,81 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+// TaskProgress is the roll-up completion of a parent task's direct subtasks.
+type TaskProgress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+// Complete reports whether every subtask is done. A task without subtasks
+// is never complete by roll-up.
+func (p TaskProgress) Complete() bool {
+	return p.Total > 0 && p.Done == p.Total
+}
+
+// TaskNode is a task with its subtasks, as rendered by "task list --tree".
+type TaskNode struct {
+	*Task
+	Progress *TaskProgress `json:"progress,omitempty"`
+	Subtasks []*TaskNode   `json:"subtasks"`
+}
+
+// RollUpTasks returns the subtask progress of every task in tasks that has
+// at least one subtask, keyed by parent ID.
+func RollUpTasks(tasks []*Task) map[string]TaskProgress {
+	progress := make(map[string]TaskProgress)
+	for _, t := range tasks {
+		if t.ParentID == "" {
+			continue
+		}
+		p := progress[t.ParentID]
+		p.Total++
+		if t.Status == TaskDone {
+			p.Done++
+		}
+		progress[t.ParentID] = p
+	}
+	return progress
+}
+
+// BuildTaskTree arranges tasks into a forest, keeping their input order.
+// Tasks whose parent is not in the list become roots, so a filtered list
+// still shows every task. progress, typically from RollUpTasks over the
+// unfiltered list, is attached to each node that has subtasks.
+func BuildTaskTree(tasks []*Task, progress map[string]TaskProgress) []*TaskNode {
+	nodes := make(map[string]*TaskNode, len(tasks))
+	for _, t := range tasks {
+		n := &TaskNode{Task: t, Subtasks: []*TaskNode{}}
+		if p, ok := progress[t.ID]; ok {
+			n.Progress = &p
+		}
+		nodes[t.ID] = n
+	}
+
+	roots := []*TaskNode{}
+	for _, t := range tasks {
+		n := nodes[t.ID]
+		if parent, ok := nodes[t.ParentID]; ok && !inSubtree(n, parent) {
+			parent.Subtasks = append(parent.Subtasks, n)
+			continue
+		}
+		roots = append(roots, n)
+	}
+	return roots
+}
+
+// inSubtree reports whether target is n or one of its descendants, which
+// guards against parent cycles in hand-edited data.
+func inSubtree(n, target *TaskNode) bool {
+	if n == target {
+		return true
+	}
+	for _, c := range n.Subtasks {
+		if inSubtree(c, target) {
+			return true
+		}
+	}
+	return false
+}
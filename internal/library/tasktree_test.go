@@ -0,0 +1,57 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import "testing"
+
+func TestBuildTaskTree(t *testing.T) {
+	tasks := []*Task{
+		{ID: "review", Description: "Literature review", Status: "todo"},
+		{ID: "p1", ParentID: "review", Description: "Paper 1", Status: "done"},
+		{ID: "p2", ParentID: "review", Description: "Paper 2", Status: "todo"},
+		{ID: "p2-notes", ParentID: "p2", Description: "Notes", Status: "todo"},
+		{ID: "orphan", ParentID: "missing", Description: "Orphan", Status: "todo"},
+	}
+
+	progress := RollUpTasks(tasks)
+	if got := progress["review"]; got != (TaskProgress{Done: 1, Total: 2}) {
+		t.Errorf("review progress = %+v, want 1/2", got)
+	}
+	if progress["review"].Complete() {
+		t.Error("review should not be complete")
+	}
+	if _, ok := progress["p1"]; ok {
+		t.Error("leaf task should have no progress entry")
+	}
+
+	roots := BuildTaskTree(tasks, progress)
+	if len(roots) != 2 || roots[0].ID != "review" || roots[1].ID != "orphan" {
+		t.Fatalf("roots = %v, want [review orphan]", nodeIDs(roots))
+	}
+	if roots[0].Progress == nil || roots[0].Progress.Total != 2 {
+		t.Errorf("review node progress = %+v", roots[0].Progress)
+	}
+	subs := roots[0].Subtasks
+	if len(subs) != 2 || subs[1].ID != "p2" || len(subs[1].Subtasks) != 1 {
+		t.Fatalf("review subtasks = %v", nodeIDs(subs))
+	}
+
+	// A parent cycle still yields every task exactly once
+	cyclic := []*Task{
+		{ID: "a", ParentID: "b"},
+		{ID: "b", ParentID: "a"},
+	}
+	roots = BuildTaskTree(cyclic, nil)
+	if len(roots) != 1 || len(roots[0].Subtasks) != 1 {
+		t.Errorf("cyclic tree = %v", nodeIDs(roots))
+	}
+}
+
+func nodeIDs(nodes []*TaskNode) []string {
+	ids := make([]string, len(nodes))
+	for i, n := range nodes {
+		ids[i] = n.ID
+	}
+	return ids
+}
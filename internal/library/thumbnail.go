@@ -0,0 +1,159 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrNoThumbnail means no cover could be found or rendered for a document.
+var ErrNoThumbnail = errors.New("no thumbnail available")
+
+// ThumbnailWidth is the width in pixels of rendered PDF thumbnails.
+const ThumbnailWidth = 320
+
+// ThumbnailDir returns the managed cache directory for thumbnails:
+// $ARC_LIBRARY_CACHE_DIR/thumbnails, or arc-library/thumbnails under the
+// user cache directory.
+func ThumbnailDir() (string, error) {
+	base, err := CacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "thumbnails"), nil
+}
+
+// Thumbnail returns the path of a JPEG thumbnail for doc, generating it into
+// dir on first use: the first page of a PDF (needs pdftoppm from poppler) or,
+// for documents with an ISBN, the book cover from Open Library. Misses are
+// remembered so later calls return ErrNoThumbnail without retrying; pass
+// refresh to try again.
+func Thumbnail(doc *Document, dir string, refresh bool) (string, error) {
+	name := thumbnailName(doc.ID)
+	path := filepath.Join(dir, name+".jpg")
+	missing := filepath.Join(dir, name+".missing")
+	if refresh {
+		_ = os.Remove(path)
+		_ = os.Remove(missing)
+	} else {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+		if _, err := os.Stat(missing); err == nil {
+			return "", ErrNoThumbnail
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("create thumbnail directory: %w", err)
+	}
+
+	var err error
+	switch {
+	case strings.EqualFold(filepath.Ext(doc.Path), ".pdf"):
+		err = renderPDFThumbnail(DocumentPath(doc), path)
+	case DocumentISBN(doc) != "":
+		err = fetchOpenLibraryCover(DocumentISBN(doc), path)
+	default:
+		err = ErrNoThumbnail
+	}
+	if err != nil {
+		if errors.Is(err, ErrNoThumbnail) {
+			_ = os.WriteFile(missing, nil, 0o644)
+		}
+		return "", err
+	}
+	return path, nil
+}
+
+// thumbnailName returns the file name, without extension, of the
+// thumbnail of the document with the given ID. Bytes that aren't safe in a
+// file name on every platform, such as the colon in the KV store's IDs,
+// are written %XX, so no two IDs share a name.
+func thumbnailName(id string) string {
+	var b strings.Builder
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' && i > 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// DocumentISBN returns the ISBN of a book, taken from an "isbn" source or an
+// isbn entry in Meta (a string or a list of strings), with hyphens removed.
+func DocumentISBN(doc *Document) string {
+	isbn := ""
+	if doc.Source == "isbn" {
+		isbn = doc.SourceID
+	}
+	if isbn == "" {
+		switch v := doc.Meta["isbn"].(type) {
+		case string:
+			isbn = v
+		case []any:
+			if len(v) > 0 {
+				isbn, _ = v[0].(string)
+			}
+		case []string:
+			if len(v) > 0 {
+				isbn = v[0]
+			}
+		}
+	}
+	return strings.NewReplacer("-", "", " ", "").Replace(strings.TrimPrefix(strings.ToLower(isbn), "isbn:"))
+}
+
+// renderPDFThumbnail writes the first page of a PDF to dst as a JPEG.
+func renderPDFThumbnail(pdfPath, dst string) error {
+	if _, err := exec.LookPath("pdftoppm"); err != nil {
+		return fmt.Errorf("pdftoppm not found (is poppler installed?)")
+	}
+	// pdftoppm appends the extension to the output prefix
+	prefix := strings.TrimSuffix(dst, ".jpg")
+	cmd := exec.Command("pdftoppm", "-jpeg", "-singlefile", "-f", "1", "-l", "1",
+		"-scale-to-x", fmt.Sprint(ThumbnailWidth), "-scale-to-y", "-1", pdfPath, prefix)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("pdftoppm failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// fetchOpenLibraryCover downloads the medium-size cover for isbn to dst.
+func fetchOpenLibraryCover(isbn, dst string) error {
+	url := "https://covers.openlibrary.org/b/isbn/" + isbn + "-M.jpg?default=false"
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return fmt.Errorf("fetch cover: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNoThumbnail
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetch cover: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read cover: %w", err)
+	}
+	return os.WriteFile(dst, data, 0o644)
+}
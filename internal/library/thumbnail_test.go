@@ -0,0 +1,59 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDocumentISBN(t *testing.T) {
+	cases := []struct {
+		doc  *Document
+		want string
+	}{
+		{&Document{Source: "isbn", SourceID: "978-0-262-03384-8"}, "9780262033848"},
+		{&Document{Meta: JSONMap{"isbn": "ISBN:0262033844"}}, "0262033844"},
+		{&Document{Meta: JSONMap{"isbn": []any{"9780262033848", "0262033844"}}}, "9780262033848"},
+		{&Document{Source: "arxiv", SourceID: "2304.00067"}, ""},
+	}
+	for _, c := range cases {
+		if got := DocumentISBN(c.doc); got != c.want {
+			t.Errorf("DocumentISBN(%+v) = %q, want %q", c.doc, got, c.want)
+		}
+	}
+}
+
+func TestThumbnailCache(t *testing.T) {
+	dir := t.TempDir()
+
+	// Nothing to render: the miss is remembered
+	note := &Document{ID: "n1", Path: "/notes/n1.md"}
+	if _, err := Thumbnail(note, dir, false); !errors.Is(err, ErrNoThumbnail) {
+		t.Fatalf("Thumbnail(note) error = %v, want ErrNoThumbnail", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "n1.missing")); err != nil {
+		t.Errorf("miss not recorded: %v", err)
+	}
+
+	// A cached thumbnail is returned without regenerating
+	cached := filepath.Join(dir, "p1.jpg")
+	if err := os.WriteFile(cached, []byte("jpeg"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	path, err := Thumbnail(&Document{ID: "p1", Path: "/missing.pdf"}, dir, false)
+	if err != nil || path != cached {
+		t.Errorf("Thumbnail(cached) = %q, %v; want %q", path, err, cached)
+	}
+
+	// IDs that aren't file names are escaped
+	if _, err := Thumbnail(&Document{ID: "doc:1/../x", Path: "/notes/x.md"}, dir, false); !errors.Is(err, ErrNoThumbnail) {
+		t.Fatalf("Thumbnail(doc:1) error = %v, want ErrNoThumbnail", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "doc%3A1%2F..%2Fx.missing")); err != nil {
+		t.Errorf("miss not recorded under an escaped name: %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Due dates are stored in UTC as the start of a day in the home timezone,
+// and "today" is reckoned there too, so cards and tasks come due on the
+// same day wherever the machine's clock is set: traveling does not make
+// them due a day early or late.
+
+var (
+	homeLocation   = time.Local
+	homeLocationMu sync.RWMutex
+)
+
+// HomeLocation returns the timezone due dates are reckoned in.
+func HomeLocation() *time.Location {
+	homeLocationMu.RLock()
+	defer homeLocationMu.RUnlock()
+	return homeLocation
+}
+
+// SetHomeLocation replaces the timezone HomeLocation returns.
+func SetHomeLocation(loc *time.Location) {
+	homeLocationMu.Lock()
+	defer homeLocationMu.Unlock()
+	homeLocation = loc
+}
+
+// HomeTimezoneSetting reads the home timezone from $ARC_LIBRARY_TIMEZONE,
+// an IANA name such as Europe/Berlin, or the system's timezone when unset.
+func HomeTimezoneSetting() (*time.Location, error) {
+	name := os.Getenv("ARC_LIBRARY_TIMEZONE")
+	if name == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ARC_LIBRARY_TIMEZONE %q (expected an IANA name like Europe/Berlin)", name)
+	}
+	return loc, nil
+}
+
+// Now returns the current time in the home timezone, for the functions
+// that take a now and reckon days in its location.
+func Now() time.Time {
+	return time.Now().In(HomeLocation())
+}
+
+// StartOfDay returns midnight of t's day in t's location.
+func StartOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// DueDate returns the start of the day days after from's day, in from's
+// location, as UTC. Counting calendar days rather than 24 hours keeps
+// midnight across DST changes.
+func DueDate(from time.Time, days int) time.Time {
+	y, m, d := from.Date()
+	return time.Date(y, m, d+days, 0, 0, 0, 0, from.Location()).UTC()
+}
+
+// IsDue reports whether something due at dueAt is due on now's day or
+// before, in now's location.
+func IsDue(dueAt, now time.Time) bool {
+	return dueAt.Before(dueBy(now))
+}
+
+// dueBy returns the end of now's day, in UTC: everything due before it is
+// due.
+func dueBy(now time.Time) time.Time {
+	return DueDate(now, 1)
+}
+
+// Overdue reports whether something due at dueAt was due before now's
+// day, in now's location.
+func Overdue(dueAt, now time.Time) bool {
+	return dueAt.Before(StartOfDay(now))
+}
+
+// HomeDate formats t's day in the home timezone as YYYY-MM-DD.
+func HomeDate(t time.Time) string {
+	return t.In(HomeLocation()).Format("2006-01-02")
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+func loadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc
+}
+
+func TestDueDateAcrossDST(t *testing.T) {
+	ny := loadLocation(t, "America/New_York")
+	for _, tc := range []struct {
+		name string
+		from time.Time
+		days int
+		want time.Time
+	}{
+		// Clocks go forward at 2:00 on March 9, 2025: that day has 23 hours
+		{"spring forward", time.Date(2025, 3, 8, 22, 0, 0, 0, ny), 1, time.Date(2025, 3, 9, 5, 0, 0, 0, time.UTC)},
+		{"after spring forward", time.Date(2025, 3, 8, 22, 0, 0, 0, ny), 2, time.Date(2025, 3, 10, 4, 0, 0, 0, time.UTC)},
+		// Clocks go back at 2:00 on November 2, 2025: that day has 25 hours
+		{"fall back", time.Date(2025, 11, 1, 23, 30, 0, 0, ny), 2, time.Date(2025, 11, 3, 5, 0, 0, 0, time.UTC)},
+		{"long interval", time.Date(2025, 2, 1, 12, 0, 0, 0, ny), 60, time.Date(2025, 4, 2, 4, 0, 0, 0, time.UTC)},
+	} {
+		got := DueDate(tc.from, tc.days)
+		if !got.Equal(tc.want) || got.Location() != time.UTC {
+			t.Errorf("%s: DueDate = %s, want %s", tc.name, got, tc.want)
+		}
+		if local := got.In(ny); local.Hour() != 0 || local.Minute() != 0 {
+			t.Errorf("%s: due at %s, not midnight", tc.name, local)
+		}
+	}
+
+	// A card due on the short day is due all of it, and not the evening before
+	due := DueDate(time.Date(2025, 3, 8, 9, 0, 0, 0, ny), 1)
+	if IsDue(due, time.Date(2025, 3, 8, 23, 59, 0, 0, ny)) {
+		t.Error("due the evening before")
+	}
+	if !IsDue(due, time.Date(2025, 3, 9, 0, 30, 0, 0, ny)) || !IsDue(due, time.Date(2025, 3, 9, 23, 59, 0, 0, ny)) {
+		t.Error("not due on its day")
+	}
+	if Overdue(due, time.Date(2025, 3, 9, 23, 59, 0, 0, ny)) || !Overdue(due, time.Date(2025, 3, 10, 0, 0, 0, 0, ny)) {
+		t.Error("overdue on the wrong day")
+	}
+}
+
+func TestDueTodayInHomeTimezone(t *testing.T) {
+	berlin := loadLocation(t, "Europe/Berlin")
+	tokyo := loadLocation(t, "Asia/Tokyo")
+	SetHomeLocation(berlin)
+	t.Cleanup(func() { SetHomeLocation(time.Local) })
+
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	card := &Flashcard{Type: "basic", Front: "Q", Back: "A", DueAt: time.Now()}
+	if err := s.AddFlashcard(card); err != nil {
+		t.Fatal(err)
+	}
+	card, err = s.ReviewFlashcard(card.ID, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if home := card.DueAt.In(berlin); card.DueAt.Location() != time.UTC || home.Hour() != 0 || home.Minute() != 0 {
+		t.Errorf("due at %s (%s at home), want the start of a day in Berlin, in UTC", card.DueAt, home)
+	}
+	if want := StartOfDay(Now()).AddDate(0, 0, card.Interval); !card.DueAt.Equal(want) {
+		t.Errorf("due %s, want %s", card.DueAt, want)
+	}
+
+	// Due on March 13 in Berlin. At 7:00 in Tokyo it is still the 12th at
+	// home, so the card is not due yet, whichever zone the clock is in.
+	due := time.Date(2025, 3, 13, 0, 0, 0, 0, berlin).UTC()
+	traveling := time.Date(2025, 3, 13, 7, 0, 0, 0, tokyo)
+	if IsDue(due, traveling.In(HomeLocation())) {
+		t.Error("due a day early while traveling")
+	}
+	if !IsDue(due, traveling.Add(2*time.Hour).In(HomeLocation())) {
+		t.Error("not due once the day starts at home")
+	}
+	if HomeDate(due) != "2025-03-13" {
+		t.Errorf("HomeDate = %s", HomeDate(due))
+	}
+
+	// Cards scheduled with a time of day are due all of that day
+	later := &Flashcard{Type: "basic", Front: "Q2", Back: "A2", DueAt: time.Date(2025, 3, 12, 21, 0, 0, 0, berlin)}
+	if err := s.AddFlashcard(later); err != nil {
+		t.Fatal(err)
+	}
+	cards, err := s.GetDueFlashcards(time.Date(2025, 3, 12, 8, 0, 0, 0, berlin))
+	if err != nil || len(cards) != 1 || cards[0].ID != later.ID {
+		t.Errorf("due cards = %+v, %v", cards, err)
+	}
+}
+
+func TestNextTaskAcrossDST(t *testing.T) {
+	ny := loadLocation(t, "America/New_York")
+	due := time.Date(2025, 3, 3, 0, 0, 0, 0, ny).UTC()
+	task := &Task{Description: "Weekly review", Repeat: "weekly", DueAt: &due}
+
+	next, err := NextTask(task, time.Date(2025, 3, 5, 12, 0, 0, 0, ny))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := time.Date(2025, 3, 10, 0, 0, 0, 0, ny)
+	if !next.DueAt.Equal(want) || next.DueAt.Location() != time.UTC {
+		t.Errorf("next due %s, want %s (midnight after the clocks change)", next.DueAt, want.UTC())
+	}
+}
+
+func TestHomeTimezoneSetting(t *testing.T) {
+	t.Setenv("ARC_LIBRARY_TIMEZONE", "")
+	if loc, err := HomeTimezoneSetting(); err != nil || loc != time.Local {
+		t.Errorf("unset = %v, %v", loc, err)
+	}
+	t.Setenv("ARC_LIBRARY_TIMEZONE", "Europe/Berlin")
+	if loc, err := HomeTimezoneSetting(); err != nil || loc.String() != "Europe/Berlin" {
+		t.Errorf("Europe/Berlin = %v, %v", loc, err)
+	}
+	t.Setenv("ARC_LIBRARY_TIMEZONE", "Mars/Olympus_Mons")
+	if _, err := HomeTimezoneSetting(); err == nil {
+		t.Error("unknown timezone: no error")
+	}
+}
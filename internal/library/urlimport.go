@@ -0,0 +1,208 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// FilesDir returns where downloaded documents are saved: the library root
+// when ARC_LIBRARY_ROOT is set, so they stay portable, otherwise
+// arc/files under the user data directory ($XDG_DATA_HOME, ~/.local/share,
+// or %LocalAppData%).
+func FilesDir() (string, error) {
+	if root := LibraryRoot(); root != "" {
+		return root, nil
+	}
+	base, err := dataHome()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(base, "arc", "files"), nil
+}
+
+// SaveFile copies r into a new file named name in dir, adding -1, -2, ...
+// before the extension when the name is taken. It returns the file's path.
+func SaveFile(dir, name string, r io.Reader) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("create directory: %w", err)
+	}
+	name = safeFileName(name)
+	ext := filepath.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+
+	for i := 0; ; i++ {
+		p := filepath.Join(dir, name)
+		if i > 0 {
+			p = filepath.Join(dir, fmt.Sprintf("%s-%d%s", stem, i, ext))
+		}
+		f, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if _, err := io.Copy(f, r); err != nil {
+			f.Close()
+			os.Remove(p)
+			return "", err
+		}
+		return p, f.Close()
+	}
+}
+
+var unsafeFileChars = regexp.MustCompile(`[/\\:*?"<>|\x00-\x1f]+`)
+
+// safeFileName strips directories and characters that are invalid in file
+// names on common platforms.
+func safeFileName(name string) string {
+	name = strings.TrimSpace(unsafeFileChars.ReplaceAllString(filepath.Base(name), "_"))
+	if name == "" || name == "." || name == ".." || name == "_" {
+		return "document"
+	}
+	return name
+}
+
+var (
+	arxivAbsPattern  = regexp.MustCompile(`^https?://(?:www\.)?arxiv\.org/(?:abs|pdf)/([^?#]+?)(?:\.pdf)?/?$`)
+	htmlTitlePattern = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+)
+
+// ImportURL fetches rawURL and builds an unsaved document for it. PDFs are
+// saved into dir and become papers with the file as Path; arXiv abstract
+// pages fetch the paper's PDF. Other pages become articles titled after
+// the page. The URL is recorded in Meta["url"].
+func ImportURL(rawURL, dir string) (*Document, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid URL: %s", rawURL)
+	}
+	if err := checkOnline(rawURL); err != nil {
+		return nil, err
+	}
+
+	doc := &Document{Source: "url", Meta: JSONMap{"url": rawURL}}
+	fetch := rawURL
+	if m := arxivAbsPattern.FindStringSubmatch(rawURL); m != nil {
+		doc.Source = "arxiv"
+		doc.SourceID = m[1]
+		fetch = "https://arxiv.org/pdf/" + m[1]
+	}
+
+	req, err := http.NewRequest("GET", fetch, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "arc-library/1.0")
+	client := &http.Client{Timeout: 60 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch %s: %w", fetch, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch %s: %s", fetch, resp.Status)
+	}
+
+	body := bufio.NewReader(resp.Body)
+	head, _ := body.Peek(5)
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType == "application/pdf" || bytes.Equal(head, []byte("%PDF-")) {
+		name := urlFileName(resp)
+		p, err := SaveFile(dir, name, body)
+		if err != nil {
+			return nil, fmt.Errorf("save %s: %w", name, err)
+		}
+		doc.Type = DocTypePaper
+		doc.Path = p
+		doc.Title = strings.TrimSuffix(filepath.Base(name), filepath.Ext(name))
+		if doc.Source == "arxiv" {
+			doc.Title = doc.SourceID
+		}
+		return doc, nil
+	}
+
+	// Only the head is needed for the title
+	page, err := io.ReadAll(io.LimitReader(body, 512<<10))
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", fetch, err)
+	}
+	doc.Type = DocTypeArticle
+	if m := htmlTitlePattern.FindSubmatch(page); m != nil {
+		doc.Title = strings.Join(strings.Fields(html.UnescapeString(string(m[1]))), " ")
+	}
+	if doc.Title == "" {
+		doc.Title = u.Host + u.Path
+	}
+	return doc, nil
+}
+
+// urlFileName names a downloaded PDF after the response's
+// Content-Disposition, or else the last element of the URL path.
+func urlFileName(resp *http.Response) string {
+	name := ""
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil {
+		name = params["filename"]
+	}
+	if name == "" {
+		name = path.Base(resp.Request.URL.Path)
+	}
+	if name == "" || name == "/" || name == "." {
+		name = resp.Request.URL.Host
+	}
+	if !strings.EqualFold(filepath.Ext(name), ".pdf") {
+		name += ".pdf"
+	}
+	return name
+}
+
+// DownloadPDF fetches the PDF at rawURL into dir, naming it name or, when
+// name is empty, after the response. It fails when the response is not a
+// PDF, as open-access links sometimes lead to a landing page instead.
+func DownloadPDF(rawURL, dir, name string) (string, error) {
+	if err := checkOnline(rawURL); err != nil {
+		return "", err
+	}
+	req, err := http.NewRequest("GET", rawURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "arc-library/1.0")
+	client := &http.Client{Timeout: 60 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetch %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetch %s: %s", rawURL, resp.Status)
+	}
+
+	body := bufio.NewReader(resp.Body)
+	if head, _ := body.Peek(5); !bytes.Equal(head, []byte("%PDF-")) {
+		return "", fmt.Errorf("fetch %s: not a PDF", rawURL)
+	}
+	if name == "" {
+		name = urlFileName(resp)
+	}
+	p, err := SaveFile(dir, name, body)
+	if err != nil {
+		return "", fmt.Errorf("save %s: %w", name, err)
+	}
+	return p, nil
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSaveFile(t *testing.T) {
+	dir := t.TempDir()
+	first, err := SaveFile(dir, "../paper.pdf", strings.NewReader("one"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := SaveFile(dir, "paper.pdf", strings.NewReader("two"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != filepath.Join(dir, "paper.pdf") || second != filepath.Join(dir, "paper-1.pdf") {
+		t.Errorf("saved to %s and %s", first, second)
+	}
+}
+
+func TestImportURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/files/report.pdf":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Write([]byte("%PDF-1.4\n%%EOF\n"))
+		case "/post":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte("<html><head><title>\n  Scaling &amp; Laws\n</title></head></html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	dir := t.TempDir()
+
+	doc, err := ImportURL(srv.URL+"/files/report.pdf", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Type != DocTypePaper || doc.Title != "report" || doc.Meta["url"] != srv.URL+"/files/report.pdf" {
+		t.Errorf("pdf document = %+v", doc)
+	}
+	if data, err := os.ReadFile(doc.Path); err != nil || !strings.HasPrefix(string(data), "%PDF") {
+		t.Errorf("saved file: %q, %v", data, err)
+	}
+
+	doc, err = ImportURL(srv.URL+"/post", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Type != DocTypeArticle || doc.Title != "Scaling & Laws" || doc.Path != "" {
+		t.Errorf("page document = %+v", doc)
+	}
+
+	if _, err := ImportURL(srv.URL+"/missing", dir); err == nil {
+		t.Error("expected an error for a missing page")
+	}
+	if _, err := ImportURL("ftp://example.com/x", dir); err == nil {
+		t.Error("expected an error for a non-HTTP URL")
+	}
+}
+
+func TestDownloadPDF(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/landing" {
+			w.Write([]byte("<html>Download here</html>"))
+			return
+		}
+		w.Write([]byte("%PDF-1.4\n%%EOF\n"))
+	}))
+	defer srv.Close()
+	dir := t.TempDir()
+
+	p, err := DownloadPDF(srv.URL+"/x", dir, "vaswani-2017.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(p) != dir || filepath.Base(p) != "vaswani-2017.pdf" {
+		t.Errorf("saved to %s", p)
+	}
+	if _, err := DownloadPDF(srv.URL+"/landing", dir, ""); err == nil {
+		t.Error("expected an error for a landing page")
+	}
+}
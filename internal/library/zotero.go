@@ -0,0 +1,336 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ZoteroAPI is the Zotero Web API base URL.
+const ZoteroAPI = "https://api.zotero.org"
+
+// zoteroBatchSize is the most objects the API accepts per write.
+const zoteroBatchSize = 50
+
+// ErrZoteroLibraryChanged is returned when a write is rejected because the
+// Zotero library changed after it was read.
+var ErrZoteroLibraryChanged = errors.New("zotero library changed during sync")
+
+// ZoteroClient talks to one Zotero library through the Web API (v3).
+type ZoteroClient struct {
+	BaseURL string // defaults to ZoteroAPI
+	APIKey  string
+	Library string // "users/<id>" or "groups/<id>"
+	HTTP    *http.Client
+
+	// Version is the library version reported by the latest response,
+	// needed as the precondition for deletes.
+	Version int
+}
+
+// NewZoteroClient returns a client for library, e.g. "users/12345".
+func NewZoteroClient(apiKey, library string) *ZoteroClient {
+	return &ZoteroClient{
+		BaseURL: ZoteroAPI,
+		APIKey:  apiKey,
+		Library: library,
+		HTTP:    &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// ZoteroItem is a Zotero item's data. Only the fields arc-library syncs are
+// decoded; writes send partial objects, which the API merges.
+type ZoteroItem struct {
+	Key              string          `json:"key"`
+	Version          int             `json:"version"`
+	ItemType         string          `json:"itemType"`
+	Title            string          `json:"title"`
+	Creators         []ZoteroCreator `json:"creators"`
+	AbstractNote     string          `json:"abstractNote"`
+	URL              string          `json:"url"`
+	DOI              string          `json:"DOI"`
+	Date             string          `json:"date"`
+	PublicationTitle string          `json:"publicationTitle"`
+	Tags             []ZoteroTag     `json:"tags"`
+	Collections      []string        `json:"collections"`
+	DateModified     time.Time       `json:"dateModified"`
+	Deleted          zoteroBool      `json:"deleted"` // in the trash
+}
+
+// ZoteroCreator is an item's author, editor, etc. Name is set instead of
+// FirstName and LastName for single-field names such as institutions.
+type ZoteroCreator struct {
+	CreatorType string `json:"creatorType"`
+	FirstName   string `json:"firstName,omitempty"`
+	LastName    string `json:"lastName,omitempty"`
+	Name        string `json:"name,omitempty"`
+}
+
+// ZoteroTag is a tag on a Zotero item.
+type ZoteroTag struct {
+	Tag string `json:"tag"`
+}
+
+// ZoteroCollection is a Zotero collection's data.
+type ZoteroCollection struct {
+	Key     string     `json:"key"`
+	Version int        `json:"version"`
+	Name    string     `json:"name"`
+	Deleted zoteroBool `json:"deleted"`
+}
+
+// ZoteroDeleted lists the keys of objects deleted since a version.
+type ZoteroDeleted struct {
+	Items       []string `json:"items"`
+	Collections []string `json:"collections"`
+}
+
+// ZoteroWriteResult is the outcome of writing one object: its key and new
+// version, or the error the API returned for it.
+type ZoteroWriteResult struct {
+	Key     string
+	Version int
+	Code    int // HTTP status for a failed object, e.g. 412 when it changed remotely
+	Err     error
+}
+
+// zoteroBool decodes the API's deleted flag, which is sent as true or 1.
+type zoteroBool bool
+
+func (b *zoteroBool) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	*b = zoteroBool(s == "true" || s == "1")
+	return nil
+}
+
+// ZoteroKeyLibrary returns the user library an API key belongs to, as
+// "users/<id>".
+func ZoteroKeyLibrary(baseURL, apiKey string) (string, error) {
+	c := NewZoteroClient(apiKey, "")
+	if baseURL != "" {
+		c.BaseURL = baseURL
+	}
+	var key struct {
+		UserID int `json:"userID"`
+	}
+	if _, err := c.do("GET", "/keys/current", nil, nil, &key); err != nil {
+		return "", err
+	}
+	if key.UserID == 0 {
+		return "", fmt.Errorf("zotero: API key has no user library")
+	}
+	return fmt.Sprintf("users/%d", key.UserID), nil
+}
+
+// Items returns the top-level items changed since version, and the library
+// version they were read at.
+func (c *ZoteroClient) Items(since int) ([]*ZoteroItem, int, error) {
+	var items []*ZoteroItem
+	err := c.list("/items/top", since, func(data json.RawMessage) error {
+		var item ZoteroItem
+		if err := json.Unmarshal(data, &item); err != nil {
+			return err
+		}
+		items = append(items, &item)
+		return nil
+	})
+	return items, c.Version, err
+}
+
+// Collections returns the collections changed since version.
+func (c *ZoteroClient) Collections(since int) ([]*ZoteroCollection, int, error) {
+	var colls []*ZoteroCollection
+	err := c.list("/collections", since, func(data json.RawMessage) error {
+		var coll ZoteroCollection
+		if err := json.Unmarshal(data, &coll); err != nil {
+			return err
+		}
+		colls = append(colls, &coll)
+		return nil
+	})
+	return colls, c.Version, err
+}
+
+// list pages through a multi-object endpoint, passing each object's data
+// to add.
+func (c *ZoteroClient) list(path string, since int, add func(json.RawMessage) error) error {
+	const limit = 100
+	for start := 0; ; start += limit {
+		q := url.Values{
+			"since":  {strconv.Itoa(since)},
+			"format": {"json"},
+			"limit":  {strconv.Itoa(limit)},
+			"start":  {strconv.Itoa(start)},
+		}
+		var page []struct {
+			Data json.RawMessage `json:"data"`
+		}
+		resp, err := c.do("GET", c.libraryPath(path), q, nil, &page)
+		if err != nil {
+			return err
+		}
+		for _, obj := range page {
+			if err := add(obj.Data); err != nil {
+				return fmt.Errorf("zotero: decode %s: %w", path, err)
+			}
+		}
+		total, _ := strconv.Atoi(resp.Header.Get("Total-Results"))
+		if len(page) < limit || start+len(page) >= total {
+			return nil
+		}
+	}
+}
+
+// Deleted returns the keys of items and collections deleted since version.
+func (c *ZoteroClient) Deleted(since int) (*ZoteroDeleted, error) {
+	var deleted ZoteroDeleted
+	q := url.Values{"since": {strconv.Itoa(since)}}
+	if _, err := c.do("GET", c.libraryPath("/deleted"), q, nil, &deleted); err != nil {
+		return nil, err
+	}
+	return &deleted, nil
+}
+
+// WriteItems creates or updates items, in batches. Objects with a key and
+// version update that item, failing with code 412 if it has changed since
+// that version. Results are in the order of items.
+func (c *ZoteroClient) WriteItems(items []map[string]any) ([]ZoteroWriteResult, error) {
+	return c.write("/items", items)
+}
+
+// CreateCollections creates collections with the given names.
+func (c *ZoteroClient) CreateCollections(names []string) ([]ZoteroWriteResult, error) {
+	objs := make([]map[string]any, len(names))
+	for i, name := range names {
+		objs[i] = map[string]any{"name": name}
+	}
+	return c.write("/collections", objs)
+}
+
+func (c *ZoteroClient) write(path string, objs []map[string]any) ([]ZoteroWriteResult, error) {
+	results := make([]ZoteroWriteResult, 0, len(objs))
+	for start := 0; start < len(objs); start += zoteroBatchSize {
+		batch := objs[start:min(start+zoteroBatchSize, len(objs))]
+		var resp struct {
+			Successful map[string]struct {
+				Key     string `json:"key"`
+				Version int    `json:"version"`
+			} `json:"successful"`
+			Unchanged map[string]string `json:"unchanged"`
+			Failed    map[string]struct {
+				Key     string `json:"key"`
+				Code    int    `json:"code"`
+				Message string `json:"message"`
+			} `json:"failed"`
+		}
+		if _, err := c.do("POST", c.libraryPath(path), nil, batch, &resp); err != nil {
+			return results, err
+		}
+		for i := range batch {
+			idx := strconv.Itoa(i)
+			var r ZoteroWriteResult
+			if ok, found := resp.Successful[idx]; found {
+				r.Key, r.Version = ok.Key, ok.Version
+			} else if key, found := resp.Unchanged[idx]; found {
+				r.Key = key
+				r.Version, _ = batch[i]["version"].(int)
+			} else if f, found := resp.Failed[idx]; found {
+				r.Key, r.Code, r.Err = f.Key, f.Code, fmt.Errorf("zotero: %s", f.Message)
+			} else {
+				r.Err = fmt.Errorf("zotero: no result for object %d", start+i)
+			}
+			results = append(results, r)
+		}
+	}
+	return results, nil
+}
+
+// DeleteItems deletes items by key. It fails with ErrZoteroLibraryChanged
+// if the library changed since the client's Version.
+func (c *ZoteroClient) DeleteItems(keys []string) error {
+	return c.delete("/items", "itemKey", keys)
+}
+
+// DeleteCollections deletes collections by key, like DeleteItems.
+func (c *ZoteroClient) DeleteCollections(keys []string) error {
+	return c.delete("/collections", "collectionKey", keys)
+}
+
+func (c *ZoteroClient) delete(path, param string, keys []string) error {
+	for start := 0; start < len(keys); start += zoteroBatchSize {
+		batch := keys[start:min(start+zoteroBatchSize, len(keys))]
+		q := url.Values{param: {strings.Join(batch, ",")}}
+		if _, err := c.do("DELETE", c.libraryPath(path), q, nil, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *ZoteroClient) libraryPath(path string) string {
+	return "/" + c.Library + path
+}
+
+// do sends a request, decoding a JSON response into out and recording the
+// library version it reports.
+func (c *ZoteroClient) do(method, path string, q url.Values, body, out any) (*http.Response, error) {
+	u := strings.TrimSuffix(c.BaseURL, "/") + path
+	if len(q) > 0 {
+		u += "?" + q.Encode()
+	}
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		r = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, u, r)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Zotero-API-Version", "3")
+	req.Header.Set("Zotero-API-Key", c.APIKey)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if method == "DELETE" {
+		req.Header.Set("If-Unmodified-Since-Version", strconv.Itoa(c.Version))
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("zotero: %w", err)
+	}
+	defer resp.Body.Close()
+	if v, err := strconv.Atoi(resp.Header.Get("Last-Modified-Version")); err == nil {
+		c.Version = v
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusPreconditionFailed:
+		return resp, ErrZoteroLibraryChanged
+	case resp.StatusCode == http.StatusForbidden:
+		return resp, fmt.Errorf("zotero: access denied; check the API key and its permissions")
+	case resp.StatusCode >= 300:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return resp, fmt.Errorf("zotero: %s %s: %s: %s", method, path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out != nil && resp.StatusCode != http.StatusNoContent {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp, fmt.Errorf("zotero: decode response: %w", err)
+		}
+	}
+	return resp, nil
+}
@@ -0,0 +1,294 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/yourorg/arc-sdk/store"
+)
+
+func TestMergeZoteroFields(t *testing.T) {
+	base := ZoteroFields{Title: "Old", Abstract: "A", Tags: []string{"a", "b"}}
+	local := ZoteroFields{Title: "Old", Abstract: "Local", Tags: []string{"a", "c"}}
+	remote := ZoteroFields{Title: "New", Abstract: "Remote", Tags: []string{"a", "b", "d"}}
+
+	merged, conflicts := MergeZoteroFields(base, local, remote, false)
+	if merged.Title != "New" {
+		t.Errorf("title = %q, want the remote change", merged.Title)
+	}
+	if merged.Abstract != "Remote" || !slices.Equal(conflicts, []string{"abstract"}) {
+		t.Errorf("abstract = %q, conflicts %v; want remote to win a conflict on abstract", merged.Abstract, conflicts)
+	}
+	// b removed locally, c added locally, d added remotely
+	if !slices.Equal(merged.Tags, []string{"a", "c", "d"}) {
+		t.Errorf("tags = %v, want [a c d]", merged.Tags)
+	}
+
+	merged, _ = MergeZoteroFields(base, local, remote, true)
+	if merged.Abstract != "Local" {
+		t.Errorf("abstract = %q, want local to win", merged.Abstract)
+	}
+}
+
+// fakeZotero serves the parts of the Zotero Web API that sync uses, for
+// library users/1.
+type fakeZotero struct {
+	version     int
+	nextKey     int
+	items       map[string]*ZoteroItem
+	collections map[string]*ZoteroCollection
+	deleted     map[string]int // item key -> library version it was deleted at
+}
+
+func newFakeZotero() *fakeZotero {
+	return &fakeZotero{
+		version:     1,
+		items:       make(map[string]*ZoteroItem),
+		collections: make(map[string]*ZoteroCollection),
+		deleted:     make(map[string]int),
+	}
+}
+
+func (f *fakeZotero) key() string {
+	f.nextKey++
+	return fmt.Sprintf("KEY%05d", f.nextKey)
+}
+
+func (f *fakeZotero) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	since, _ := strconv.Atoi(r.URL.Query().Get("since"))
+	reply := func(v any) {
+		w.Header().Set("Last-Modified-Version", strconv.Itoa(f.version))
+		json.NewEncoder(w).Encode(v)
+	}
+	type object struct {
+		Data any `json:"data"`
+	}
+
+	switch r.Method + " " + r.URL.Path {
+	case "GET /users/1/items/top":
+		var page []object
+		for _, item := range f.items {
+			if item.Version > since {
+				page = append(page, object{item})
+			}
+		}
+		w.Header().Set("Total-Results", strconv.Itoa(len(page)))
+		reply(page)
+	case "GET /users/1/collections":
+		var page []object
+		for _, c := range f.collections {
+			if c.Version > since {
+				page = append(page, object{c})
+			}
+		}
+		w.Header().Set("Total-Results", strconv.Itoa(len(page)))
+		reply(page)
+	case "GET /users/1/deleted":
+		var deleted ZoteroDeleted
+		for key, v := range f.deleted {
+			if v > since {
+				deleted.Items = append(deleted.Items, key)
+			}
+		}
+		reply(deleted)
+	case "POST /users/1/items", "POST /users/1/collections":
+		var objs []map[string]any
+		json.NewDecoder(r.Body).Decode(&objs)
+		f.version++
+		successful := map[string]any{}
+		failed := map[string]any{}
+		for i, obj := range objs {
+			idx := strconv.Itoa(i)
+			if strings.HasSuffix(r.URL.Path, "/collections") {
+				c := &ZoteroCollection{Key: f.key(), Version: f.version, Name: obj["name"].(string)}
+				f.collections[c.Key] = c
+				successful[idx] = map[string]any{"key": c.Key, "version": c.Version}
+				continue
+			}
+			key, _ := obj["key"].(string)
+			item := f.items[key]
+			if key == "" {
+				item = &ZoteroItem{Key: f.key()}
+			} else if item == nil || int(obj["version"].(float64)) != item.Version {
+				failed[idx] = map[string]any{"key": key, "code": 412, "message": "Item has been modified"}
+				continue
+			}
+			// Writes merge into the item
+			data, _ := json.Marshal(item)
+			var merged map[string]any
+			json.Unmarshal(data, &merged)
+			for k, v := range obj {
+				merged[k] = v
+			}
+			data, _ = json.Marshal(merged)
+			updated := &ZoteroItem{}
+			json.Unmarshal(data, updated)
+			updated.Version = f.version
+			f.items[updated.Key] = updated
+			successful[idx] = map[string]any{"key": updated.Key, "version": f.version}
+		}
+		reply(map[string]any{"successful": successful, "failed": failed})
+	case "DELETE /users/1/items":
+		if r.Header.Get("If-Unmodified-Since-Version") != strconv.Itoa(f.version) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		f.version++
+		for _, key := range strings.Split(r.URL.Query().Get("itemKey"), ",") {
+			delete(f.items, key)
+			f.deleted[key] = f.version
+		}
+		w.Header().Set("Last-Modified-Version", strconv.Itoa(f.version))
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// edit changes an item as another Zotero client would.
+func (f *fakeZotero) edit(key string, change func(*ZoteroItem)) {
+	f.version++
+	change(f.items[key])
+	f.items[key].Version = f.version
+}
+
+func TestZoteroSync(t *testing.T) {
+	zot := newFakeZotero()
+	zot.collections["COLL1"] = &ZoteroCollection{Key: "COLL1", Version: 1, Name: "Reading"}
+	zot.items["ITEMA"] = &ZoteroItem{
+		Key: "ITEMA", Version: 1, ItemType: "journalArticle", Title: "Attention Is All You Need",
+		Creators:    []ZoteroCreator{{CreatorType: "author", FirstName: "Ashish", LastName: "Vaswani"}},
+		DOI:         "10.5555/attention",
+		Date:        "2017-06-12",
+		Tags:        []ZoteroTag{{Tag: "ml"}},
+		Collections: []string{"COLL1"},
+	}
+	zot.items["ITEMB"] = &ZoteroItem{
+		Key: "ITEMB", Version: 1, ItemType: "webpage", Title: "A Blog Post",
+		URL: "https://example.com/post", Tags: []ZoteroTag{{Tag: "web"}},
+	}
+	srv := httptest.NewServer(zot)
+	defer srv.Close()
+
+	s, err := NewKVStore(store.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.AddDocument(&Document{ID: "local", Type: DocTypeBook, Title: "Local Book", Tags: []string{"x"}})
+	s.AddDocument(&Document{ID: "blog", Type: DocTypeArticle, Title: "A Blog Post", Tags: []string{"mine"},
+		Meta: JSONMap{"url": "https://example.com/post"}})
+
+	client := NewZoteroClient("key", "users/1")
+	client.BaseURL = srv.URL
+	sync := &ZoteroSync{Store: s, Client: client, Policy: ZoteroPreferNewer}
+
+	res, err := sync.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Pulled.Created != 1 || res.Pulled.CollectionsCreated != 1 || res.Pushed.Created != 1 || res.Pushed.Updated != 1 {
+		t.Errorf("first sync = %+v pulled, %+v pushed", res.Pulled, res.Pushed)
+	}
+
+	// ITEMA became a document in the Reading collection
+	docs, _ := s.ListDocuments(nil)
+	if len(docs) != 3 {
+		t.Fatalf("library has %d documents, want 3 (the blog post is linked, not duplicated)", len(docs))
+	}
+	var attention *Document
+	for _, d := range docs {
+		if d.SourceID == "ITEMA" {
+			attention = d
+		}
+	}
+	if attention == nil || attention.Meta["doi"] != "10.5555/attention" || !slices.Equal(attention.Authors, []string{"Ashish Vaswani"}) {
+		t.Fatalf("pulled document = %+v", attention)
+	}
+	reading, _ := s.GetCollection("Reading")
+	if reading == nil || !slices.Contains(reading.DocumentIDs, attention.ID) {
+		t.Errorf("Reading collection = %+v, want it to hold the pulled document", reading)
+	}
+
+	// The linked blog post merged tags both ways; the local book was created
+	blog, _ := s.GetDocument("blog")
+	if !slices.Equal(blog.Tags, []string{"mine", "web"}) {
+		t.Errorf("local blog tags = %v", blog.Tags)
+	}
+	if tags := zoteroItemFields(zot.items["ITEMB"]).Tags; !slices.Equal(tags, []string{"mine", "web"}) {
+		t.Errorf("remote blog tags = %v", tags)
+	}
+	var bookKey string
+	for key, item := range zot.items {
+		if item.Title == "Local Book" {
+			bookKey = key
+			if item.ItemType != "book" {
+				t.Errorf("pushed item type = %q, want book", item.ItemType)
+			}
+		}
+	}
+	if bookKey == "" {
+		t.Fatal("local book was not pushed")
+	}
+
+	// A second sync with no changes does nothing
+	res, err = sync.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Pulled != (ZoteroSyncCounts{}) || res.Pushed != (ZoteroSyncCounts{}) {
+		t.Errorf("idle sync = %+v pulled, %+v pushed", res.Pulled, res.Pushed)
+	}
+
+	// Different fields changed on each side merge
+	attention.Tags = append(attention.Tags, "later")
+	s.UpdateDocument(attention)
+	zot.edit("ITEMA", func(item *ZoteroItem) { item.AbstractNote = "We propose the Transformer." })
+	// The same field changed on both sides conflicts
+	blog.Title = "Local Title"
+	s.UpdateDocument(blog)
+	zot.edit("ITEMB", func(item *ZoteroItem) { item.Title = "Remote Title" })
+	// Deleted here, so deleted there
+	s.DeleteDocument("local")
+
+	sync.Policy = ZoteroPreferRemote
+	res, err = sync.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	attention, _ = s.GetDocument(attention.ID)
+	if attention.Abstract != "We propose the Transformer." {
+		t.Errorf("abstract = %q, want the remote edit", attention.Abstract)
+	}
+	if tags := zoteroItemFields(zot.items["ITEMA"]).Tags; !slices.Equal(tags, []string{"later", "ml"}) {
+		t.Errorf("remote tags = %v, want the local edit", tags)
+	}
+	if len(res.Conflicts) != 1 || res.Conflicts[0].Key != "ITEMB" || res.Conflicts[0].Kept != "remote" {
+		t.Errorf("conflicts = %+v", res.Conflicts)
+	}
+	if blog, _ = s.GetDocument("blog"); blog.Title != "Remote Title" {
+		t.Errorf("blog title = %q, want the remote title", blog.Title)
+	}
+	if zot.items[bookKey] != nil || res.Pushed.Deleted != 1 {
+		t.Errorf("local deletion was not pushed: %+v", res.Pushed)
+	}
+
+	// Deleted in Zotero, so deleted here
+	zot.version++
+	delete(zot.items, "ITEMB")
+	zot.deleted["ITEMB"] = zot.version
+	if res, err = sync.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if blog, _ := s.GetDocument("blog"); blog != nil || res.Pulled.Deleted != 1 {
+		t.Errorf("remote deletion was not pulled: %+v", res.Pulled)
+	}
+}
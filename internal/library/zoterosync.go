@@ -0,0 +1,1057 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package library
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ZoteroConflictPolicy settles a field changed both locally and in Zotero
+// since the last sync.
+type ZoteroConflictPolicy string
+
+const (
+	ZoteroPreferNewer  ZoteroConflictPolicy = "newer" // the side modified last
+	ZoteroPreferLocal  ZoteroConflictPolicy = "local"
+	ZoteroPreferRemote ZoteroConflictPolicy = "remote"
+)
+
+// ParseZoteroConflictPolicy validates a --conflict value.
+func ParseZoteroConflictPolicy(s string) (ZoteroConflictPolicy, error) {
+	switch p := ZoteroConflictPolicy(strings.ToLower(s)); p {
+	case ZoteroPreferNewer, ZoteroPreferLocal, ZoteroPreferRemote:
+		return p, nil
+	}
+	return "", fmt.Errorf("invalid conflict policy %q (want newer, local, or remote)", s)
+}
+
+// ZoteroFields are the document fields kept in sync with a Zotero item.
+// Tags and collections are sorted so field sets compare as sets.
+type ZoteroFields struct {
+	Title       string   `json:"title"`
+	Authors     []string `json:"authors,omitempty"`
+	Abstract    string   `json:"abstract,omitempty"`
+	URL         string   `json:"url,omitempty"`
+	DOI         string   `json:"doi,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Collections []string `json:"collections,omitempty"` // Zotero collection keys
+}
+
+// Equal reports whether f and other hold the same values.
+func (f ZoteroFields) Equal(other ZoteroFields) bool {
+	return f.Title == other.Title && slices.Equal(f.Authors, other.Authors) &&
+		f.Abstract == other.Abstract && f.URL == other.URL && f.DOI == other.DOI &&
+		slices.Equal(f.Tags, other.Tags) && slices.Equal(f.Collections, other.Collections)
+}
+
+// ZoteroSyncState is what the last sync with a Zotero library left behind:
+// the library version to ask for changes since, and for each synced item
+// the fields as they were then, the base for three-way merges.
+type ZoteroSyncState struct {
+	Library     string                             `json:"library"`
+	Version     int                                `json:"version"`
+	Items       map[string]*ZoteroSyncedItem       `json:"items"`       // by Zotero item key
+	Collections map[string]*ZoteroSyncedCollection `json:"collections"` // by Zotero collection key
+	SyncedAt    time.Time                          `json:"synced_at"`
+}
+
+// ZoteroSyncedItem links a Zotero item to a document.
+type ZoteroSyncedItem struct {
+	DocumentID string       `json:"document_id"`
+	Version    int          `json:"version"`
+	ItemType   string       `json:"item_type"`
+	Fields     ZoteroFields `json:"fields"`
+}
+
+// ZoteroSyncedCollection links a Zotero collection to a collection.
+type ZoteroSyncedCollection struct {
+	CollectionID string `json:"collection_id"`
+	Version      int    `json:"version"`
+}
+
+// MergeZoteroFields merges the local and remote changes made since base.
+// A field changed on one side takes that side's value; tags and collections
+// merge entry by entry. A field changed differently on both sides is a
+// conflict, settled by preferLocal. It returns the merged fields and the
+// names of the conflicting ones.
+func MergeZoteroFields(base, local, remote ZoteroFields, preferLocal bool) (ZoteroFields, []string) {
+	var conflicts []string
+	pick := func(name string, b, l, r string) string {
+		switch {
+		case l == r || l == b:
+			return r
+		case r == b:
+			return l
+		}
+		conflicts = append(conflicts, name)
+		if preferLocal {
+			return l
+		}
+		return r
+	}
+
+	merged := ZoteroFields{
+		Title:    pick("title", base.Title, local.Title, remote.Title),
+		Abstract: pick("abstract", base.Abstract, local.Abstract, remote.Abstract),
+		URL:      pick("url", base.URL, local.URL, remote.URL),
+		DOI:      pick("doi", base.DOI, local.DOI, remote.DOI),
+	}
+	switch {
+	case slices.Equal(local.Authors, remote.Authors) || slices.Equal(local.Authors, base.Authors):
+		merged.Authors = remote.Authors
+	case slices.Equal(remote.Authors, base.Authors):
+		merged.Authors = local.Authors
+	default:
+		conflicts = append(conflicts, "authors")
+		merged.Authors = remote.Authors
+		if preferLocal {
+			merged.Authors = local.Authors
+		}
+	}
+	merged.Tags = mergeSet(base.Tags, local.Tags, remote.Tags)
+	merged.Collections = mergeSet(base.Collections, local.Collections, remote.Collections)
+	return merged, conflicts
+}
+
+// mergeSet keeps an entry if the side that changed its membership since
+// base has it, or if neither did and both still have it. The result is
+// sorted.
+func mergeSet(base, local, remote []string) []string {
+	var merged []string
+	for _, v := range append(slices.Clone(local), remote...) {
+		inBase, inLocal := slices.Contains(base, v), slices.Contains(local, v)
+		keep := slices.Contains(remote, v)
+		if inLocal != inBase {
+			keep = inLocal
+		}
+		if keep && !slices.Contains(merged, v) {
+			merged = append(merged, v)
+		}
+	}
+	sort.Strings(merged)
+	return merged
+}
+
+// zoteroLinkBase is the base for merging a document and a Zotero item
+// linked for the first time: each side's empty fields are filled from the
+// other, tags and collections are combined, and fields set differently on
+// both sides conflict.
+func zoteroLinkBase(local, remote ZoteroFields) ZoteroFields {
+	base := func(l, r string) string {
+		if l == "" {
+			return l
+		}
+		if r == "" || l == r {
+			return r
+		}
+		return ""
+	}
+	b := ZoteroFields{
+		Title:    base(local.Title, remote.Title),
+		Abstract: base(local.Abstract, remote.Abstract),
+		URL:      base(local.URL, remote.URL),
+		DOI:      base(local.DOI, remote.DOI),
+	}
+	if len(local.Authors) == 0 || len(remote.Authors) == 0 || slices.Equal(local.Authors, remote.Authors) {
+		b.Authors = local.Authors
+		if len(local.Authors) > 0 {
+			b.Authors = remote.Authors
+		}
+	}
+	for _, t := range local.Tags {
+		if slices.Contains(remote.Tags, t) {
+			b.Tags = append(b.Tags, t)
+		}
+	}
+	for _, c := range local.Collections {
+		if slices.Contains(remote.Collections, c) {
+			b.Collections = append(b.Collections, c)
+		}
+	}
+	return b
+}
+
+// zoteroItemFields reads the synced fields of a Zotero item.
+func zoteroItemFields(item *ZoteroItem) ZoteroFields {
+	f := ZoteroFields{
+		Title:       item.Title,
+		Abstract:    item.AbstractNote,
+		URL:         item.URL,
+		DOI:         item.DOI,
+		Collections: slices.Clone(item.Collections),
+	}
+	for _, c := range item.Creators {
+		name := c.Name
+		if name == "" {
+			name = strings.TrimSpace(c.FirstName + " " + c.LastName)
+		}
+		if name != "" {
+			f.Authors = append(f.Authors, name)
+		}
+	}
+	for _, t := range item.Tags {
+		if !slices.Contains(f.Tags, t.Tag) {
+			f.Tags = append(f.Tags, t.Tag)
+		}
+	}
+	sort.Strings(f.Tags)
+	sort.Strings(f.Collections)
+	return f
+}
+
+// documentZoteroFields reads the synced fields of a document. collections
+// are the Zotero keys of the synced collections it is in.
+func documentZoteroFields(doc *Document, collections []string) ZoteroFields {
+	f := ZoteroFields{
+		Title:       doc.Title,
+		Authors:     slices.Clone(doc.Authors),
+		Abstract:    doc.Abstract,
+		Tags:        slices.Clone(doc.Tags),
+		Collections: slices.Clone(collections),
+	}
+	f.URL, _ = doc.Meta["url"].(string)
+	f.DOI = documentDOI(doc)
+	sort.Strings(f.Tags)
+	sort.Strings(f.Collections)
+	return f
+}
+
+// documentDOI returns a document's DOI, from its source ID for DOI imports
+// or from Meta["doi"].
+func documentDOI(doc *Document) string {
+	if doc.Source == "doi" && doc.SourceID != "" {
+		return doc.SourceID
+	}
+	doi, _ := doc.Meta["doi"].(string)
+	return doi
+}
+
+// applyZoteroFields sets a document's synced fields, except collections,
+// which are memberships rather than document data.
+func applyZoteroFields(doc *Document, f ZoteroFields) {
+	doc.Title = f.Title
+	doc.Authors = slices.Clone(f.Authors)
+	doc.Abstract = f.Abstract
+	doc.Tags = slices.Clone(f.Tags)
+	if doc.Meta == nil {
+		doc.Meta = JSONMap{}
+	}
+	setMeta := func(key, value string) {
+		if value == "" {
+			delete(doc.Meta, key)
+		} else {
+			doc.Meta[key] = value
+		}
+	}
+	setMeta("url", f.URL)
+	if doc.Source == "doi" {
+		doc.SourceID = f.DOI
+	} else {
+		setMeta("doi", f.DOI)
+	}
+}
+
+// zoteroDOITypes are the item types with a DOI field.
+var zoteroDOITypes = []string{"journalArticle", "conferencePaper", "preprint"}
+
+// zoteroPrimaryCreators are the creator types authors map to for item types
+// where it isn't "author".
+var zoteroPrimaryCreators = map[string]string{
+	"videoRecording":  "director",
+	"film":            "director",
+	"computerProgram": "programmer",
+	"podcast":         "podcaster",
+	"presentation":    "presenter",
+}
+
+// zoteroItemPatch builds the write for an item of itemType whose synced
+// fields go from "from" to "to": only the fields that differ, since writes
+// with a key merge into the item.
+func zoteroItemPatch(from, to ZoteroFields, itemType string) map[string]any {
+	obj := map[string]any{}
+	if to.Title != from.Title {
+		obj["title"] = to.Title
+	}
+	if to.Abstract != from.Abstract {
+		obj["abstractNote"] = to.Abstract
+	}
+	if to.URL != from.URL {
+		obj["url"] = to.URL
+	}
+	if to.DOI != from.DOI && slices.Contains(zoteroDOITypes, itemType) {
+		obj["DOI"] = to.DOI
+	}
+	if !slices.Equal(to.Authors, from.Authors) {
+		creatorType := zoteroPrimaryCreators[itemType]
+		if creatorType == "" {
+			creatorType = "author"
+		}
+		creators := make([]ZoteroCreator, 0, len(to.Authors))
+		for _, a := range to.Authors {
+			c := ZoteroCreator{CreatorType: creatorType}
+			if i := strings.LastIndex(a, " "); i > 0 {
+				c.FirstName, c.LastName = a[:i], a[i+1:]
+			} else {
+				c.Name = a
+			}
+			creators = append(creators, c)
+		}
+		obj["creators"] = creators
+	}
+	if !slices.Equal(to.Tags, from.Tags) {
+		tags := make([]ZoteroTag, 0, len(to.Tags))
+		for _, t := range to.Tags {
+			tags = append(tags, ZoteroTag{Tag: t})
+		}
+		obj["tags"] = tags
+	}
+	if !slices.Equal(to.Collections, from.Collections) {
+		obj["collections"] = nonNilStrings(to.Collections)
+	}
+	return obj
+}
+
+func nonNilStrings(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+// zoteroDocTypes maps Zotero item types to document types; others are
+// DocTypeOther.
+var zoteroDocTypes = map[string]DocumentType{
+	"journalArticle":   DocTypePaper,
+	"conferencePaper":  DocTypePaper,
+	"preprint":         DocTypePaper,
+	"report":           DocTypePaper,
+	"thesis":           DocTypePaper,
+	"manuscript":       DocTypePaper,
+	"book":             DocTypeBook,
+	"bookSection":      DocTypeBook,
+	"blogPost":         DocTypeArticle,
+	"webpage":          DocTypeArticle,
+	"magazineArticle":  DocTypeArticle,
+	"newspaperArticle": DocTypeArticle,
+	"forumPost":        DocTypeArticle,
+	"videoRecording":   DocTypeVideo,
+	"film":             DocTypeVideo,
+	"computerProgram":  DocTypeRepo,
+}
+
+// zoteroItemType picks the item type for a document pushed to Zotero.
+func zoteroItemType(doc *Document) string {
+	switch doc.Type {
+	case DocTypePaper:
+		if doc.Source == "arxiv" {
+			return "preprint"
+		}
+		return "journalArticle"
+	case DocTypeBook:
+		return "book"
+	case DocTypeArticle:
+		return "webpage"
+	case DocTypeVideo:
+		return "videoRecording"
+	case DocTypeRepo:
+		return "computerProgram"
+	}
+	return "document"
+}
+
+// zoteroSkippedTypes are item types that aren't documents.
+var zoteroSkippedTypes = []string{"note", "attachment", "annotation"}
+
+var yearPattern = regexp.MustCompile(`\b(1[5-9]|20)\d\d\b`)
+
+// newZoteroDocument builds the document for a Zotero item that has none.
+func newZoteroDocument(item *ZoteroItem) *Document {
+	docType, ok := zoteroDocTypes[item.ItemType]
+	if !ok {
+		docType = DocTypeOther
+	}
+	doc := &Document{
+		Type:     docType,
+		Source:   "zotero",
+		SourceID: item.Key,
+		Status:   StatusUnread,
+		Meta:     JSONMap{},
+	}
+	applyZoteroFields(doc, zoteroItemFields(item))
+	if y := yearPattern.FindString(item.Date); y != "" {
+		doc.Meta["year"], _ = strconv.Atoi(y)
+	}
+	if item.PublicationTitle != "" {
+		doc.Meta["journal"] = item.PublicationTitle
+	}
+	return doc
+}
+
+// ZoteroSync syncs the library with a Zotero library in both directions:
+// items, their tags, and collections. It asks Zotero only for what changed
+// since the last sync, and finds local changes by comparing documents with
+// the fields recorded then.
+type ZoteroSync struct {
+	Store  LibraryStore
+	Client *ZoteroClient
+	Policy ZoteroConflictPolicy
+	DryRun bool // work out the changes without making them on either side
+}
+
+// ZoteroSyncResult reports a sync.
+type ZoteroSyncResult struct {
+	Library   string              `json:"library"`
+	Version   int                 `json:"version"`
+	Pulled    ZoteroSyncCounts    `json:"pulled"` // changes made locally
+	Pushed    ZoteroSyncCounts    `json:"pushed"` // changes made in Zotero
+	Conflicts []ZoteroConflict    `json:"conflicts"`
+	Failed    []ZoteroSyncFailure `json:"failed"`
+}
+
+// ZoteroSyncCounts counts the changes made on one side.
+type ZoteroSyncCounts struct {
+	Created            int `json:"created"`
+	Updated            int `json:"updated"`
+	Deleted            int `json:"deleted"`
+	CollectionsCreated int `json:"collections_created"`
+	CollectionsDeleted int `json:"collections_deleted"`
+}
+
+// ZoteroConflict is an item changed on both sides. Fields lists the fields
+// that conflicted, or "deleted" when one side deleted it; Kept is the side
+// whose values won.
+type ZoteroConflict struct {
+	Key        string   `json:"key"`
+	DocumentID string   `json:"document_id"`
+	Title      string   `json:"title"`
+	Fields     []string `json:"fields"`
+	Kept       string   `json:"kept"` // "local" or "remote"
+}
+
+// ZoteroSyncFailure is an item that could not be synced.
+type ZoteroSyncFailure struct {
+	Key        string `json:"key,omitempty"`
+	DocumentID string `json:"document_id,omitempty"`
+	Title      string `json:"title"`
+	Error      string `json:"error"`
+}
+
+// zoteroWrite is an item write waiting to be sent, with the state to record
+// once it succeeds, and on failure when fallback is set.
+type zoteroWrite struct {
+	obj      map[string]any
+	doc      *Document
+	synced   ZoteroSyncedItem
+	fallback *ZoteroSyncedItem
+	create   bool
+}
+
+// zoteroRun holds one sync's working state.
+type zoteroRun struct {
+	*ZoteroSync
+	state  *ZoteroSyncState
+	result *ZoteroSyncResult
+
+	docs      map[string]*Document // by ID
+	inColls   map[string][]string  // document ID -> synced collection keys
+	collIDs   map[string]string    // collection key -> collection ID
+	linked    map[string]bool      // document IDs with an item
+	handled   map[string]bool      // item keys merged from remote changes
+	writes    []*zoteroWrite
+	deletions []string // item keys to delete in Zotero
+}
+
+// Run syncs and records the new state, unless DryRun is set.
+func (z *ZoteroSync) Run() (*ZoteroSyncResult, error) {
+	state, err := z.Store.GetZoteroSyncState(z.Client.Library)
+	if err != nil {
+		return nil, fmt.Errorf("load sync state: %w", err)
+	}
+	if state == nil {
+		state = &ZoteroSyncState{Library: z.Client.Library}
+	}
+	if state.Items == nil {
+		state.Items = make(map[string]*ZoteroSyncedItem)
+	}
+	if state.Collections == nil {
+		state.Collections = make(map[string]*ZoteroSyncedCollection)
+	}
+
+	// Collections are read first, so the version they were read at is the
+	// earliest and no change made in between is skipped next time
+	remoteColls, version, err := z.Client.Collections(state.Version)
+	if err != nil {
+		return nil, err
+	}
+	remoteItems, _, err := z.Client.Items(state.Version)
+	if err != nil {
+		return nil, err
+	}
+	deleted := &ZoteroDeleted{}
+	if state.Version > 0 {
+		if deleted, err = z.Client.Deleted(state.Version); err != nil {
+			return nil, err
+		}
+	}
+
+	r := &zoteroRun{
+		ZoteroSync: z,
+		state:      state,
+		result:     &ZoteroSyncResult{Library: z.Client.Library, Version: version},
+		handled:    make(map[string]bool),
+	}
+	if err := r.syncCollections(remoteColls, deleted.Collections); err != nil {
+		return nil, err
+	}
+	if err := r.loadDocuments(); err != nil {
+		return nil, err
+	}
+	if err := r.pullItems(remoteItems, deleted.Items); err != nil {
+		return nil, err
+	}
+	if err := r.pushItems(); err != nil {
+		return nil, err
+	}
+
+	state.Version = version
+	state.SyncedAt = time.Now()
+	if !z.DryRun {
+		if err := z.Store.SaveZoteroSyncState(state); err != nil {
+			return nil, fmt.Errorf("save sync state: %w", err)
+		}
+	}
+	return r.result, nil
+}
+
+// syncCollections applies collections created or deleted on either side.
+// Collections are matched by name; renames are not synced.
+func (r *zoteroRun) syncCollections(remote []*ZoteroCollection, deletedKeys []string) error {
+	local, err := r.Store.ListCollections()
+	if err != nil {
+		return err
+	}
+	byID := make(map[string]*Collection, len(local))
+	for _, c := range local {
+		byID[c.ID] = c
+	}
+	mapped := make(map[string]bool)
+	for _, sc := range r.state.Collections {
+		mapped[sc.CollectionID] = true
+	}
+
+	deleteLocal := func(key string) error {
+		sc := r.state.Collections[key]
+		if sc == nil {
+			return nil
+		}
+		delete(r.state.Collections, key)
+		if byID[sc.CollectionID] == nil {
+			return nil
+		}
+		if !r.DryRun {
+			if _, err := DeleteCollectionUndoable(r.Store, sc.CollectionID); err != nil {
+				return fmt.Errorf("delete collection: %w", err)
+			}
+		}
+		delete(byID, sc.CollectionID)
+		r.result.Pulled.CollectionsDeleted++
+		return nil
+	}
+	for _, key := range deletedKeys {
+		if err := deleteLocal(key); err != nil {
+			return err
+		}
+	}
+	for _, rc := range remote {
+		if rc.Deleted {
+			if err := deleteLocal(rc.Key); err != nil {
+				return err
+			}
+			continue
+		}
+		if sc := r.state.Collections[rc.Key]; sc != nil {
+			sc.Version = rc.Version
+			continue
+		}
+		var match *Collection
+		for _, c := range byID {
+			if c.Name == rc.Name && !mapped[c.ID] {
+				match = c
+				break
+			}
+		}
+		if match == nil {
+			match = &Collection{ID: "new:" + rc.Key, Name: rc.Name}
+			if !r.DryRun {
+				if match, err = r.Store.CreateCollection(rc.Name, ""); err != nil {
+					return fmt.Errorf("create collection %q: %w", rc.Name, err)
+				}
+			}
+			byID[match.ID] = match
+			r.result.Pulled.CollectionsCreated++
+		}
+		mapped[match.ID] = true
+		r.state.Collections[rc.Key] = &ZoteroSyncedCollection{CollectionID: match.ID, Version: rc.Version}
+	}
+
+	// Synced collections deleted here
+	var gone []string
+	for key, sc := range r.state.Collections {
+		if byID[sc.CollectionID] == nil {
+			gone = append(gone, key)
+		}
+	}
+	if len(gone) > 0 {
+		sort.Strings(gone)
+		if !r.DryRun {
+			if err := r.Client.DeleteCollections(gone); err != nil {
+				return err
+			}
+		}
+		for _, key := range gone {
+			delete(r.state.Collections, key)
+		}
+		r.result.Pushed.CollectionsDeleted += len(gone)
+	}
+
+	// Collections created here
+	var fresh []*Collection
+	for _, c := range byID {
+		if !mapped[c.ID] {
+			fresh = append(fresh, c)
+		}
+	}
+	sort.Slice(fresh, func(i, j int) bool { return fresh[i].Name < fresh[j].Name })
+	if len(fresh) == 0 {
+		return nil
+	}
+	if r.DryRun {
+		r.result.Pushed.CollectionsCreated += len(fresh)
+		return nil
+	}
+	names := make([]string, len(fresh))
+	for i, c := range fresh {
+		names[i] = c.Name
+	}
+	results, err := r.Client.CreateCollections(names)
+	if err != nil {
+		return err
+	}
+	for i, res := range results {
+		if res.Err != nil {
+			r.result.Failed = append(r.result.Failed, ZoteroSyncFailure{Title: "collection " + fresh[i].Name, Error: res.Err.Error()})
+			continue
+		}
+		r.state.Collections[res.Key] = &ZoteroSyncedCollection{CollectionID: fresh[i].ID, Version: res.Version}
+		r.result.Pushed.CollectionsCreated++
+	}
+	return nil
+}
+
+// loadDocuments indexes the library's documents and their synced
+// collections.
+func (r *zoteroRun) loadDocuments() error {
+	docs, err := r.Store.ListDocuments(nil)
+	if err != nil {
+		return err
+	}
+	r.docs = make(map[string]*Document, len(docs))
+	for _, d := range docs {
+		r.docs[d.ID] = d
+	}
+	r.linked = make(map[string]bool)
+	for _, si := range r.state.Items {
+		r.linked[si.DocumentID] = true
+	}
+
+	// GetCollection, unlike ListCollections, loads members on every backend
+	r.collIDs = make(map[string]string)
+	r.inColls = make(map[string][]string)
+	for key, sc := range r.state.Collections {
+		r.collIDs[key] = sc.CollectionID
+		c, err := r.Store.GetCollection(sc.CollectionID)
+		if err != nil {
+			return err
+		}
+		if c == nil {
+			continue // only created by a dry run
+		}
+		for _, id := range c.DocumentIDs {
+			r.inColls[id] = append(r.inColls[id], key)
+		}
+	}
+	return nil
+}
+
+// localFields reads a document's synced fields, keeping the collections in
+// base that aren't synced (such as trashed ones) so they aren't removed.
+func (r *zoteroRun) localFields(doc *Document, base ZoteroFields) ZoteroFields {
+	colls := slices.Clone(r.inColls[doc.ID])
+	for _, key := range base.Collections {
+		if _, ok := r.collIDs[key]; !ok {
+			colls = append(colls, key)
+		}
+	}
+	return documentZoteroFields(doc, colls)
+}
+
+// preferLocal settles a conflict on doc under the sync's policy.
+func (r *zoteroRun) preferLocal(doc *Document, item *ZoteroItem) bool {
+	switch r.Policy {
+	case ZoteroPreferLocal:
+		return true
+	case ZoteroPreferRemote:
+		return false
+	}
+	return doc.UpdatedAt.After(item.DateModified)
+}
+
+func policySide(preferLocal bool) string {
+	if preferLocal {
+		return "local"
+	}
+	return "remote"
+}
+
+// pullItems applies items changed or deleted in Zotero, queueing writes for
+// merged changes that Zotero doesn't have yet.
+func (r *zoteroRun) pullItems(items []*ZoteroItem, deletedKeys []string) error {
+	for _, item := range items {
+		if item.Deleted {
+			deletedKeys = append(deletedKeys, item.Key)
+		}
+	}
+	for _, key := range deletedKeys {
+		if err := r.pullDeletion(key); err != nil {
+			return err
+		}
+	}
+
+	// Documents not yet linked, for matching new items
+	byDOI := make(map[string]*Document)
+	byURL := make(map[string]*Document)
+	byTitle := make(map[string]*Document)
+	for _, d := range r.docs {
+		if r.linked[d.ID] {
+			continue
+		}
+		if doi := strings.ToLower(documentDOI(d)); doi != "" {
+			byDOI[doi] = d
+		}
+		if u, _ := d.Meta["url"].(string); u != "" {
+			byURL[u] = d
+		}
+		if d.Title != "" {
+			byTitle[strings.ToLower(d.Title)] = d
+		}
+	}
+
+	for _, item := range items {
+		if bool(item.Deleted) || slices.Contains(zoteroSkippedTypes, item.ItemType) {
+			continue
+		}
+		remote := zoteroItemFields(item)
+		si := r.state.Items[item.Key]
+		if si != nil && si.Version == item.Version {
+			continue // written by the last sync
+		}
+		r.handled[item.Key] = true
+
+		if si != nil {
+			doc := r.docs[si.DocumentID]
+			if doc == nil {
+				// Deleted here but changed in Zotero
+				if r.Policy == ZoteroPreferLocal {
+					r.deletions = append(r.deletions, item.Key)
+					r.conflict(item.Key, si.DocumentID, item.Title, []string{"deleted"}, true)
+					continue
+				}
+				r.conflict(item.Key, si.DocumentID, item.Title, []string{"deleted"}, false)
+				delete(r.state.Items, item.Key)
+				if err := r.createDocument(item, remote); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := r.merge(doc, item, si.Fields, remote); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// A new item: link it to the same document if there is one
+		var doc *Document
+		for _, d := range []*Document{byDOI[strings.ToLower(remote.DOI)], byURL[remote.URL], byTitle[strings.ToLower(remote.Title)]} {
+			if d != nil && !r.linked[d.ID] {
+				doc = d
+				break
+			}
+		}
+		if doc == nil {
+			if err := r.createDocument(item, remote); err != nil {
+				return err
+			}
+			continue
+		}
+		r.linked[doc.ID] = true
+		local := r.localFields(doc, remote)
+		if err := r.merge(doc, item, zoteroLinkBase(local, remote), remote); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pullDeletion removes the document of an item deleted in Zotero, unless
+// it changed here since the last sync and the policy keeps local changes;
+// then it stays, unlinked, and is pushed again as a new item.
+func (r *zoteroRun) pullDeletion(key string) error {
+	si := r.state.Items[key]
+	if si == nil {
+		return nil
+	}
+	delete(r.state.Items, key)
+	r.handled[key] = true
+	doc := r.docs[si.DocumentID]
+	if doc == nil {
+		return nil
+	}
+	if !r.localFields(doc, si.Fields).Equal(si.Fields) {
+		keep := r.Policy != ZoteroPreferRemote
+		r.conflict(key, doc.ID, doc.Title, []string{"deleted"}, keep)
+		if keep {
+			delete(r.linked, doc.ID)
+			return nil
+		}
+	}
+	if !r.DryRun {
+		if _, err := DeleteDocumentUndoable(r.Store, doc.ID); err != nil {
+			return fmt.Errorf("delete document %s: %w", doc.ID, err)
+		}
+	}
+	delete(r.docs, doc.ID)
+	delete(r.linked, doc.ID)
+	r.result.Pulled.Deleted++
+	return nil
+}
+
+// createDocument adds the document for a Zotero item.
+func (r *zoteroRun) createDocument(item *ZoteroItem, remote ZoteroFields) error {
+	doc := newZoteroDocument(item)
+	DetectDocumentLanguage(doc, false)
+	if r.DryRun {
+		doc.ID = "new:" + item.Key
+	} else if err := r.Store.AddDocument(doc); err != nil {
+		return fmt.Errorf("add document %q: %w", doc.Title, err)
+	}
+	r.docs[doc.ID] = doc
+	r.linked[doc.ID] = true
+	if err := r.applyCollections(doc, nil, remote.Collections); err != nil {
+		return err
+	}
+	r.state.Items[item.Key] = &ZoteroSyncedItem{DocumentID: doc.ID, Version: item.Version, ItemType: item.ItemType, Fields: remote}
+	r.result.Pulled.Created++
+	return nil
+}
+
+// merge applies the three-way merge of a document and its changed item,
+// queueing a write if Zotero lacks some of the result.
+func (r *zoteroRun) merge(doc *Document, item *ZoteroItem, base, remote ZoteroFields) error {
+	local := r.localFields(doc, base)
+	preferLocal := r.preferLocal(doc, item)
+	merged, conflicts := MergeZoteroFields(base, local, remote, preferLocal)
+	if len(conflicts) > 0 {
+		r.conflict(item.Key, doc.ID, doc.Title, conflicts, preferLocal)
+	}
+
+	if !merged.Equal(local) {
+		before := documentZoteroFields(doc, nil)
+		applyZoteroFields(doc, merged)
+		if !documentZoteroFields(doc, nil).Equal(before) && !r.DryRun {
+			if err := r.Store.UpdateDocument(doc); err != nil {
+				return fmt.Errorf("update document %s: %w", doc.ID, err)
+			}
+		}
+		if err := r.applyCollections(doc, local.Collections, merged.Collections); err != nil {
+			return err
+		}
+		r.result.Pulled.Updated++
+	}
+
+	synced := ZoteroSyncedItem{DocumentID: doc.ID, Version: item.Version, ItemType: item.ItemType, Fields: merged}
+	if merged.Equal(remote) {
+		r.state.Items[item.Key] = &synced
+		return nil
+	}
+	obj := zoteroItemPatch(remote, merged, item.ItemType)
+	obj["key"], obj["version"] = item.Key, item.Version
+	// If the write fails, Zotero still has the remote fields
+	fallback := synced
+	fallback.Fields = remote
+	r.writes = append(r.writes, &zoteroWrite{obj: obj, doc: doc, synced: synced, fallback: &fallback})
+	return nil
+}
+
+// applyCollections moves a document between synced collections.
+func (r *zoteroRun) applyCollections(doc *Document, from, to []string) error {
+	if r.DryRun {
+		return nil
+	}
+	for _, key := range to {
+		if id, ok := r.collIDs[key]; ok && !slices.Contains(from, key) {
+			if err := r.Store.AddToCollection(id, doc.ID); err != nil {
+				return fmt.Errorf("add to collection: %w", err)
+			}
+		}
+	}
+	for _, key := range from {
+		if id, ok := r.collIDs[key]; ok && !slices.Contains(to, key) {
+			if err := r.Store.RemoveFromCollection(id, doc.ID); err != nil {
+				return fmt.Errorf("remove from collection: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *zoteroRun) conflict(key, docID, title string, fields []string, preferLocal bool) {
+	r.result.Conflicts = append(r.result.Conflicts, ZoteroConflict{
+		Key: key, DocumentID: docID, Title: title, Fields: fields, Kept: policySide(preferLocal),
+	})
+}
+
+// pushItems sends local changes to Zotero: edits to linked documents,
+// new documents, and deletions, along with the merges queued by pullItems.
+func (r *zoteroRun) pushItems() error {
+	keys := make([]string, 0, len(r.state.Items))
+	for key := range r.state.Items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		si := r.state.Items[key]
+		if r.handled[key] {
+			continue
+		}
+		doc := r.docs[si.DocumentID]
+		if doc == nil {
+			r.deletions = append(r.deletions, key)
+			continue
+		}
+		local := r.localFields(doc, si.Fields)
+		if local.Equal(si.Fields) {
+			continue
+		}
+		obj := zoteroItemPatch(si.Fields, local, si.ItemType)
+		obj["key"], obj["version"] = key, si.Version
+		synced := *si
+		synced.Fields = local
+		r.writes = append(r.writes, &zoteroWrite{obj: obj, doc: doc, synced: synced})
+	}
+
+	var fresh []*Document
+	for _, d := range r.docs {
+		if !r.linked[d.ID] {
+			fresh = append(fresh, d)
+		}
+	}
+	sort.Slice(fresh, func(i, j int) bool { return fresh[i].CreatedAt.Before(fresh[j].CreatedAt) })
+	for _, doc := range fresh {
+		itemType := zoteroItemType(doc)
+		local := r.localFields(doc, ZoteroFields{})
+		obj := zoteroItemPatch(ZoteroFields{}, local, itemType)
+		obj["itemType"] = itemType
+		r.writes = append(r.writes, &zoteroWrite{
+			obj:    obj,
+			doc:    doc,
+			synced: ZoteroSyncedItem{DocumentID: doc.ID, ItemType: itemType, Fields: local},
+			create: true,
+		})
+	}
+
+	if err := r.sendWrites(); err != nil {
+		return err
+	}
+	return r.sendDeletions()
+}
+
+func (r *zoteroRun) sendWrites() error {
+	count := func(w *zoteroWrite) {
+		if w.create {
+			r.result.Pushed.Created++
+		} else {
+			r.result.Pushed.Updated++
+		}
+	}
+	if r.DryRun {
+		for _, w := range r.writes {
+			count(w)
+		}
+		return nil
+	}
+	if len(r.writes) == 0 {
+		return nil
+	}
+
+	objs := make([]map[string]any, len(r.writes))
+	for i, w := range r.writes {
+		objs[i] = w.obj
+	}
+	results, err := r.Client.WriteItems(objs)
+	if err != nil {
+		return err
+	}
+	for i, res := range results {
+		w := r.writes[i]
+		if res.Err != nil {
+			msg := res.Err.Error()
+			if res.Code == 412 {
+				msg = "changed in Zotero during sync; sync again"
+			}
+			key, _ := w.obj["key"].(string)
+			r.result.Failed = append(r.result.Failed, ZoteroSyncFailure{Key: key, DocumentID: w.doc.ID, Title: w.doc.Title, Error: msg})
+			if w.fallback != nil {
+				r.state.Items[key] = w.fallback
+			}
+			continue
+		}
+		synced := w.synced
+		synced.Version = res.Version
+		r.state.Items[res.Key] = &synced
+		count(w)
+	}
+	return nil
+}
+
+func (r *zoteroRun) sendDeletions() error {
+	if len(r.deletions) == 0 {
+		return nil
+	}
+	if !r.DryRun {
+		err := r.Client.DeleteItems(r.deletions)
+		if errors.Is(err, ErrZoteroLibraryChanged) {
+			// The items stay in the state, so the next sync retries
+			r.result.Failed = append(r.result.Failed, ZoteroSyncFailure{
+				Title: fmt.Sprintf("%d deleted documents", len(r.deletions)),
+				Error: "Zotero library changed during sync; sync again",
+			})
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	for _, key := range r.deletions {
+		delete(r.state.Items, key)
+	}
+	r.result.Pushed.Deleted += len(r.deletions)
+	return nil
+}
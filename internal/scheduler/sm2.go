@@ -0,0 +1,164 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+// Package scheduler schedules spaced-repetition reviews of flashcards and
+// documents with the SM-2 algorithm.
+package scheduler
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Defaults of SM-2 as published.
+const (
+	DefaultInitialEase = 2.5
+	DefaultMinEase     = 1.3
+)
+
+// Config tunes SM-2. The zero MaxEase and Fuzz, and an IntervalModifier
+// of 1, give the published algorithm.
+type Config struct {
+	// InitialEase is the ease of an item never reviewed.
+	InitialEase float64 `json:"initial_ease"`
+	// MinEase and MaxEase bound the ease; a MaxEase of 0 leaves it
+	// unbounded above.
+	MinEase float64 `json:"min_ease"`
+	MaxEase float64 `json:"max_ease"`
+	// LearnSteps are the intervals in days of the first successful
+	// reviews, and after each lapse; later intervals grow by the ease.
+	// SM-2's are 1 and 6.
+	LearnSteps []int `json:"learn_steps"`
+	// IntervalModifier multiplies intervals past the learning steps, to
+	// review more (below 1) or less (above 1) often than SM-2 would. 0
+	// counts as 1.
+	IntervalModifier float64 `json:"interval_modifier"`
+	// Fuzz spreads intervals past the learning steps by up to this
+	// fraction either way, so items learned together do not stay due
+	// together. 0 disables it.
+	Fuzz float64 `json:"fuzz"`
+	// Rand returns numbers in [0, 1) for the fuzz; math/rand/v2 by default.
+	Rand func() float64 `json:"-"`
+}
+
+// Default returns the published SM-2 configuration.
+func Default() Config {
+	return Config{
+		InitialEase:      DefaultInitialEase,
+		MinEase:          DefaultMinEase,
+		LearnSteps:       []int{1, 6},
+		IntervalModifier: 1,
+	}
+}
+
+// String describes the parameters in a stable form, recorded with each
+// review so a schedule can be traced to the settings that made it, as in
+// "sm2 initial=2.5 min=1.3 max=none steps=1,6 modifier=1 fuzz=0".
+func (c Config) String() string {
+	maxEase := "none"
+	if c.MaxEase > 0 {
+		maxEase = strconv.FormatFloat(c.MaxEase, 'g', -1, 64)
+	}
+	steps := make([]string, len(c.LearnSteps))
+	for i, s := range c.LearnSteps {
+		steps[i] = strconv.Itoa(s)
+	}
+	modifier := c.IntervalModifier
+	if modifier == 0 {
+		modifier = 1
+	}
+	return fmt.Sprintf("sm2 initial=%s min=%s max=%s steps=%s modifier=%s fuzz=%s",
+		strconv.FormatFloat(c.InitialEase, 'g', -1, 64), strconv.FormatFloat(c.MinEase, 'g', -1, 64), maxEase,
+		strings.Join(steps, ","), strconv.FormatFloat(modifier, 'g', -1, 64), strconv.FormatFloat(c.Fuzz, 'g', -1, 64))
+}
+
+// Validate reports a configuration that cannot schedule.
+func (c Config) Validate() error {
+	switch {
+	case c.MinEase <= 0:
+		return fmt.Errorf("minimum ease must be positive, got %g", c.MinEase)
+	case c.MaxEase != 0 && c.MaxEase < c.MinEase:
+		return fmt.Errorf("maximum ease %g is below the minimum %g", c.MaxEase, c.MinEase)
+	case c.InitialEase < c.MinEase || c.MaxEase != 0 && c.InitialEase > c.MaxEase:
+		return fmt.Errorf("initial ease %g is outside the allowed range", c.InitialEase)
+	case len(c.LearnSteps) == 0:
+		return fmt.Errorf("at least one learning step is needed")
+	case c.IntervalModifier < 0:
+		return fmt.Errorf("interval modifier must be positive, got %g", c.IntervalModifier)
+	case c.Fuzz < 0 || c.Fuzz >= 1:
+		return fmt.Errorf("fuzz must be from 0 to below 1, got %g", c.Fuzz)
+	}
+	for i, s := range c.LearnSteps {
+		if s < 1 || i > 0 && s <= c.LearnSteps[i-1] {
+			return fmt.Errorf("learning steps must be increasing whole days, got %v", c.LearnSteps)
+		}
+	}
+	return nil
+}
+
+// Next is one SM-2 review: given the previous interval in days (0 for an
+// item never reviewed) and ease, and the quality of the recall from 0
+// (complete blackout) to 5 (perfect), it returns the next interval and
+// ease. A quality below 3 is a lapse and starts the learning steps over.
+// An ease of 0 is taken as InitialEase.
+func (c Config) Next(prevInterval int, prevEase float64, quality int) (interval int, ease float64) {
+	if prevEase == 0 {
+		prevEase = c.InitialEase
+	}
+	q := float64(5 - quality)
+	ease = max(prevEase+(0.1-q*(0.08+q*0.02)), c.MinEase)
+	if c.MaxEase > 0 {
+		ease = min(ease, c.MaxEase)
+	}
+
+	if quality < 3 || prevInterval == 0 {
+		return c.LearnSteps[0], ease
+	}
+	for i, step := range c.LearnSteps[:len(c.LearnSteps)-1] {
+		if prevInterval == step {
+			return c.LearnSteps[i+1], ease
+		}
+	}
+	modifier := c.IntervalModifier
+	if modifier == 0 {
+		modifier = 1
+	}
+	interval = max(int(float64(prevInterval)*ease*modifier), 1)
+	if c.Fuzz > 0 {
+		random := c.Rand
+		if random == nil {
+			random = rand.Float64
+		}
+		// Fuzzed intervals still grow
+		interval = max(int(math.Round(float64(interval)*(1+c.Fuzz*(2*random()-1)))), prevInterval+1)
+	}
+	return interval, ease
+}
+
+var (
+	current   = Default()
+	currentMu sync.RWMutex
+)
+
+// Set replaces the configuration Current returns, after validating it.
+func Set(c Config) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	current = c
+	return nil
+}
+
+// Current returns the configuration reviews are scheduled with: Default
+// unless replaced with Set.
+func Current() Config {
+	currentMu.RLock()
+	defer currentMu.RUnlock()
+	return current
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2025 Arc Engineering
+// SPDX-License-Identifier: MIT
+
+package scheduler
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNext(t *testing.T) {
+	c := Default()
+
+	// Good recalls: the learning steps, then growth by the ease
+	interval, ease := 0, 0.0
+	for i, want := range []int{1, 6, 15, 37} {
+		interval, ease = c.Next(interval, ease, 4)
+		if interval != want || ease != DefaultInitialEase {
+			t.Errorf("review %d: interval %d, ease %g; want %d, %g", i+1, interval, ease, want, DefaultInitialEase)
+		}
+	}
+
+	// Perfect recalls raise the ease past 2.5, as SM-2 has no ceiling
+	if _, ease := c.Next(37, 2.5, 5); math.Abs(ease-2.6) > 1e-9 {
+		t.Errorf("ease after a perfect recall = %g, want 2.6", ease)
+	}
+	// A lapse starts the steps over and lowers the ease, but not below the minimum
+	if interval, ease := c.Next(37, 2.5, 1); interval != 1 || math.Abs(ease-1.96) > 1e-9 {
+		t.Errorf("lapse = %d, %g; want 1, 1.96", interval, ease)
+	}
+	if _, ease := c.Next(6, 1.3, 0); ease != DefaultMinEase {
+		t.Errorf("ease after a blackout at the minimum = %g", ease)
+	}
+
+	// A ceiling caps the ease
+	c.MaxEase = 2.5
+	if _, ease := c.Next(37, 2.5, 5); ease != 2.5 {
+		t.Errorf("capped ease = %g, want 2.5", ease)
+	}
+}
+
+func TestNextLearnSteps(t *testing.T) {
+	c := Default()
+	c.LearnSteps = []int{1, 3, 7}
+	interval := 0
+	for _, want := range []int{1, 3, 7, 17} {
+		interval, _ = c.Next(interval, 2.5, 4)
+		if interval != want {
+			t.Errorf("interval %d, want %d", interval, want)
+		}
+	}
+}
+
+func TestNextFuzz(t *testing.T) {
+	c := Default()
+	c.Fuzz = 0.1
+	for r, want := range map[float64]int{0: 54, 0.5: 60, 0.999: 66} {
+		c.Rand = func() float64 { return r }
+		if interval, _ := c.Next(24, 2.5, 4); interval != want {
+			t.Errorf("rand %g: interval %d, want %d", r, interval, want)
+		}
+	}
+	// Learning steps are not fuzzed, and fuzzed intervals still grow
+	c.Rand = func() float64 { return 0 }
+	if interval, _ := c.Next(1, 2.5, 4); interval != 6 {
+		t.Errorf("step = %d, want 6", interval)
+	}
+	c.Fuzz = 0.5
+	if interval, _ := c.Next(7, 1.3, 3); interval != 8 {
+		t.Errorf("fuzzed down = %d, want 8", interval)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Default().Validate(); err != nil {
+		t.Fatal(err)
+	}
+	for name, change := range map[string]func(*Config){
+		"no steps":          func(c *Config) { c.LearnSteps = nil },
+		"decreasing steps":  func(c *Config) { c.LearnSteps = []int{6, 1} },
+		"ceiling too low":   func(c *Config) { c.MaxEase = 1.2 },
+		"initial above cap": func(c *Config) { c.MaxEase = 2 },
+		"fuzz too large":    func(c *Config) { c.Fuzz = 1 },
+		"negative modifier": func(c *Config) { c.IntervalModifier = -1 },
+		"zero minimum":      func(c *Config) { c.MinEase = 0 },
+	} {
+		c := Default()
+		change(&c)
+		if err := c.Validate(); err == nil {
+			t.Errorf("%s: no error", name)
+		}
+		if err := Set(c); err == nil {
+			t.Errorf("%s: Set accepted it", name)
+		}
+	}
+	if Current().MaxEase != 0 {
+		t.Error("a rejected Set changed the configuration")
+	}
+}
+
+func TestNextIntervalModifier(t *testing.T) {
+	c := Default()
+	c.IntervalModifier = 0.8
+	if interval, _ := c.Next(6, 2.5, 4); interval != 12 {
+		t.Errorf("interval = %d, want 12", interval)
+	}
+	// Learning steps are not modified
+	if interval, _ := c.Next(1, 2.5, 4); interval != 6 {
+		t.Errorf("step = %d, want 6", interval)
+	}
+	c.IntervalModifier = 0
+	if interval, _ := c.Next(6, 2.5, 4); interval != 15 {
+		t.Errorf("zero modifier: interval = %d, want 15", interval)
+	}
+}
+
+func TestConfigString(t *testing.T) {
+	if got, want := Default().String(), "sm2 initial=2.5 min=1.3 max=none steps=1,6 modifier=1 fuzz=0"; got != want {
+		t.Errorf("Default = %q, want %q", got, want)
+	}
+	c := Config{InitialEase: 2.3, MinEase: 1.3, MaxEase: 2.8, LearnSteps: []int{1, 3, 7}, IntervalModifier: 0.9, Fuzz: 0.05}
+	if got, want := c.String(), "sm2 initial=2.3 min=1.3 max=2.8 steps=1,3,7 modifier=0.9 fuzz=0.05"; got != want {
+		t.Errorf("String = %q, want %q", got, want)
+	}
+}
@@ -4,8 +4,10 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"os/user"
 
 	"github.com/mtreilly/arc-library/internal/cmd"
 	"github.com/mtreilly/arc-library/internal/library"
@@ -36,7 +38,7 @@ func main() {
 		// Traditional arc-library with dedicated schema.
 		// If SQLite fails (missing, corrupted, permissions), fall back to in-memory store
 		// so the tool remains operational (statelessly) without persistence.
-		database, err := db.Open(db.DefaultDBPath())
+		database, err := db.Open(library.DatabasePath())
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "WARNING: cannot open SQLite database: %v\n", err)
 			fmt.Fprintln(os.Stderr, "         falling back to in-memory store (no persistence)")
@@ -49,7 +51,19 @@ func main() {
 			libStore = kvStore
 			break
 		}
-		sqlStore, err := library.NewStore(database)
+		// Creating and migrating the schema are writes, made only under the
+		// library lock; while another process holds it, which has done
+		// them, the schema is used as it is
+		var sqlStore *library.Store
+		lock, lockErr := library.LockLibrary(library.LockPath())
+		if errors.Is(lockErr, library.ErrLocked) {
+			sqlStore = library.OpenStore(database)
+		} else {
+			sqlStore, err = library.NewStore(database)
+			if lockErr == nil {
+				lock.Unlock()
+			}
+		}
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "arc-library: failed to init SQL store: %v\n", err)
 			os.Exit(1)
@@ -85,8 +99,39 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Documents read by ID are cached, for the web server and watcher
+	// above all, which look the same ones up again and again
+	size, err := library.DocumentCacheSize()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "WARNING: %v; caching %d documents\n", err, size)
+	}
+	if size > 0 {
+		libStore = library.NewCachedStore(libStore, size)
+	}
+
+	// Every change made through the store goes into the audit log
+	libStore = library.NewAuditedStore(libStore, auditActor())
+
 	root := cmd.NewRootCmd(cfg, libStore)
-	if err := root.Execute(); err != nil {
-		os.Exit(1)
+	err = root.Execute()
+	// Even when the command failed, which skips cobra's post-run hooks
+	cmd.ReportReadErrors()
+	if err != nil {
+		os.Exit(cmd.ExitCode(err))
+	}
+}
+
+// auditActor names who changes the library in this process, for the
+// audit log: ARC_LIBRARY_ACTOR, else the OS user.
+func auditActor() string {
+	if actor := os.Getenv("ARC_LIBRARY_ACTOR"); actor != "" {
+		return actor
+	}
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
 	}
+	return "unknown"
 }